@@ -24,6 +24,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 
 	applicationv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
@@ -61,5 +62,5 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&applicationv1alpha1.Application{}).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("Application", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &applicationv1alpha1.Application{} }, r)))
 }
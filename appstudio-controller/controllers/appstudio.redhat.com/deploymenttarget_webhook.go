@@ -0,0 +1,84 @@
+package appstudioredhatcom
+
+import (
+	"context"
+	"fmt"
+
+	applicationv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var deploymenttargetlog = logf.Log.WithName(logutil.LogLogger_managed_gitops)
+
+// DeploymentTargetValidator validates DeploymentTargets on behalf of the application-api, which does not define
+// a webhook for them.
+//
+// Once a DeploymentTarget is bound to a DeploymentTargetClaim, the cluster that it points to (its API URL) and the
+// claim that it is bound to (its claimRef) may not be changed: otherwise, an environment that is in use could be
+// accidentally re-pointed at a different cluster. To change these fields, the DeploymentTarget must be deleted and
+// recreated.
+type DeploymentTargetValidator struct{}
+
+func (v *DeploymentTargetValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&applicationv1alpha1.DeploymentTarget{}).
+		WithValidator(v).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-deploymenttarget,mutating=false,failurePolicy=fail,sideEffects=None,groups=appstudio.redhat.com,resources=deploymenttargets,verbs=update,versions=v1alpha1,name=vdeploymenttarget.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &DeploymentTargetValidator{}
+
+// ValidateCreate implements webhook.CustomValidator: there are no restrictions on creating a DeploymentTarget.
+func (v *DeploymentTargetValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator: the API URL and claimRef of a bound DeploymentTarget may not change.
+func (v *DeploymentTargetValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+
+	oldDT, ok := oldObj.(*applicationv1alpha1.DeploymentTarget)
+	if !ok {
+		return fmt.Errorf("expected a DeploymentTarget, but received %T", oldObj)
+	}
+
+	newDT, ok := newObj.(*applicationv1alpha1.DeploymentTarget)
+	if !ok {
+		return fmt.Errorf("expected a DeploymentTarget, but received %T", newObj)
+	}
+
+	deploymenttargetlog.Info("validate update", "name", newDT.Name, "namespace", newDT.Namespace)
+
+	return validateDeploymentTargetUpdate(*oldDT, *newDT)
+}
+
+// ValidateDelete implements webhook.CustomValidator: there are no restrictions on deleting a DeploymentTarget.
+func (v *DeploymentTargetValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validateDeploymentTargetUpdate returns an error if the update modifies the API URL or claimRef of a DeploymentTarget
+// that is bound.
+func validateDeploymentTargetUpdate(oldDT, newDT applicationv1alpha1.DeploymentTarget) error {
+
+	if oldDT.Status.Phase != applicationv1alpha1.DeploymentTargetPhase_Bound {
+		return nil
+	}
+
+	if oldDT.Spec.KubernetesClusterCredentials.APIURL != newDT.Spec.KubernetesClusterCredentials.APIURL {
+		return fmt.Errorf("the API URL of DeploymentTarget '%s' cannot be changed while it is bound to DeploymentTargetClaim '%s': delete and recreate the DeploymentTarget instead",
+			newDT.Name, oldDT.Spec.ClaimRef)
+	}
+
+	if oldDT.Spec.ClaimRef != newDT.Spec.ClaimRef {
+		return fmt.Errorf("the claimRef of DeploymentTarget '%s' cannot be changed while it is bound to DeploymentTargetClaim '%s': delete and recreate the DeploymentTarget instead",
+			newDT.Name, oldDT.Spec.ClaimRef)
+	}
+
+	return nil
+}
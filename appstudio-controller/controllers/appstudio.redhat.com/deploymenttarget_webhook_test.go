@@ -0,0 +1,102 @@
+package appstudioredhatcom
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	applicationv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("DeploymentTarget webhook tests", func() {
+
+	Context("Testing DeploymentTargetValidator", func() {
+
+		var (
+			ctx       context.Context
+			validator *DeploymentTargetValidator
+			oldDT     *applicationv1alpha1.DeploymentTarget
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			validator = &DeploymentTargetValidator{}
+
+			oldDT = &applicationv1alpha1.DeploymentTarget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-dt",
+					Namespace: "test-namespace",
+				},
+				Spec: applicationv1alpha1.DeploymentTargetSpec{
+					DeploymentTargetClassName: "test-class",
+					KubernetesClusterCredentials: applicationv1alpha1.DeploymentTargetKubernetesClusterCredentials{
+						APIURL:                   "https://api.test-cluster.com:6443",
+						ClusterCredentialsSecret: "test-secret",
+					},
+					ClaimRef: "test-dtc",
+				},
+				Status: applicationv1alpha1.DeploymentTargetStatus{
+					Phase: applicationv1alpha1.DeploymentTargetPhase_Bound,
+				},
+			}
+		})
+
+		It("should not allow the API URL of a bound DeploymentTarget to be changed", func() {
+			newDT := oldDT.DeepCopy()
+			newDT.Spec.KubernetesClusterCredentials.APIURL = "https://api.other-cluster.com:6443"
+
+			err := validator.ValidateUpdate(ctx, oldDT, newDT)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("API URL"))
+		})
+
+		It("should not allow the claimRef of a bound DeploymentTarget to be changed", func() {
+			newDT := oldDT.DeepCopy()
+			newDT.Spec.ClaimRef = "test-other-dtc"
+
+			err := validator.ValidateUpdate(ctx, oldDT, newDT)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("claimRef"))
+
+			By("verifying the claimRef of a bound DeploymentTarget cannot be cleared")
+			newDT.Spec.ClaimRef = ""
+			err = validator.ValidateUpdate(ctx, oldDT, newDT)
+			Expect(err).ToNot(BeNil())
+		})
+
+		It("should allow other fields of a bound DeploymentTarget to be changed", func() {
+			newDT := oldDT.DeepCopy()
+			newDT.Labels = map[string]string{"test-label": "test-value"}
+			newDT.Spec.KubernetesClusterCredentials.ClusterCredentialsSecret = "test-other-secret"
+
+			Expect(validator.ValidateUpdate(ctx, oldDT, newDT)).To(Succeed())
+		})
+
+		It("should allow the API URL and claimRef of a DeploymentTarget that is not bound to be changed", func() {
+			for _, phase := range []applicationv1alpha1.DeploymentTargetPhase{
+				applicationv1alpha1.DeploymentTargetPhase_Pending,
+				applicationv1alpha1.DeploymentTargetPhase_Available,
+				applicationv1alpha1.DeploymentTargetPhase_Released,
+			} {
+				oldDT.Status.Phase = phase
+
+				newDT := oldDT.DeepCopy()
+				newDT.Spec.KubernetesClusterCredentials.APIURL = "https://api.other-cluster.com:6443"
+				newDT.Spec.ClaimRef = "test-other-dtc"
+
+				Expect(validator.ValidateUpdate(ctx, oldDT, newDT)).To(Succeed())
+			}
+		})
+
+		It("should allow DeploymentTargets to be created and deleted", func() {
+			Expect(validator.ValidateCreate(ctx, oldDT)).To(Succeed())
+			Expect(validator.ValidateDelete(ctx, oldDT)).To(Succeed())
+		})
+
+		It("should return an error if the object is not a DeploymentTarget", func() {
+			err := validator.ValidateUpdate(ctx, &applicationv1alpha1.DeploymentTargetClaim{}, oldDT)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
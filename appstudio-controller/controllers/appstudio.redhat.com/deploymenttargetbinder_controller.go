@@ -19,13 +19,17 @@ package appstudioredhatcom
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/go-logr/logr"
 	applicationv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,10 +40,55 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+const (
+	// DeploymentTargetClaimSelectorAnnotation may be set on a DeploymentTargetClaim that doesn't specify a target, to restrict
+	// the DeploymentTargets that the binder may bind to the claim. The value is a label selector, for example:
+	// 'region=us-east-1,tier in (gold,silver)'. Only DeploymentTargets whose labels match the selector are considered.
+	DeploymentTargetClaimSelectorAnnotation = "appstudio.openshift.io/deploymenttarget-selector"
+
+	// DeploymentTargetClaimBoundBySelectorAnnotation is added by the binder to a DeploymentTargetClaim that was bound to a
+	// DeploymentTarget using a label selector. The value is the selector that was matched.
+	DeploymentTargetClaimBoundBySelectorAnnotation = "appstudio.openshift.io/bound-by-selector"
+
+	// DeploymentTargetClaimBindingPhaseAnnotation is set by the binder to the detailed progress of the binding of the
+	// DeploymentTargetClaim: see DeploymentTargetClaimBindingPhase. The status of the DeploymentTargetClaim is defined by
+	// the application-api, and only contains a Pending/Bound/Lost phase, so the additional detail is exposed as annotations.
+	DeploymentTargetClaimBindingPhaseAnnotation = "appstudio.openshift.io/binding-phase"
+
+	// DeploymentTargetClaimBindingFailureReasonAnnotation is set by the binder to the reason the DeploymentTargetClaim
+	// could not be bound, when the binding phase is Failed. It is removed once the failure is resolved.
+	DeploymentTargetClaimBindingFailureReasonAnnotation = "appstudio.openshift.io/binding-failure-reason"
+
+	// DeploymentTargetClaimBoundTargetAnnotation is set by the binder to the name of the DeploymentTarget that the
+	// DeploymentTargetClaim is bound to, when the binding phase is Bound.
+	DeploymentTargetClaimBoundTargetAnnotation = "appstudio.openshift.io/bound-deploymenttarget"
+)
+
+// DeploymentTargetClaimBindingPhase is the detailed progress of the binding of a DeploymentTargetClaim, which moves from
+// Pending, to Provisioning (if a DeploymentTarget is dynamically provisioned), to Bound or Failed.
+type DeploymentTargetClaimBindingPhase string
+
+const (
+	// DeploymentTargetClaimBindingPhase_Pending: the claim is waiting for a DeploymentTarget to be created or to become available.
+	DeploymentTargetClaimBindingPhase_Pending DeploymentTargetClaimBindingPhase = "Pending"
+
+	// DeploymentTargetClaimBindingPhase_Provisioning: the claim is waiting for a provisioner to create a DeploymentTarget.
+	DeploymentTargetClaimBindingPhase_Provisioning DeploymentTargetClaimBindingPhase = "Provisioning"
+
+	// DeploymentTargetClaimBindingPhase_Bound: the claim is bound to a DeploymentTarget.
+	DeploymentTargetClaimBindingPhase_Bound DeploymentTargetClaimBindingPhase = "Bound"
+
+	// DeploymentTargetClaimBindingPhase_Failed: the claim cannot be bound without user action: see the failure reason annotation.
+	DeploymentTargetClaimBindingPhase_Failed DeploymentTargetClaimBindingPhase = "Failed"
+)
+
 // DeploymentTargetClaimReconciler reconciles a DeploymentTargetClaim object
 type DeploymentTargetClaimReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for the DeploymentTargetClaim, describing the actions of the controller.
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargetclaims,verbs=get;list;watch;update;patch
@@ -47,6 +96,7 @@ type DeploymentTargetClaimReconciler struct {
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargetclaims/finalizers,verbs=update
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargets,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -120,6 +170,8 @@ func (r *DeploymentTargetClaimReconciler) Reconcile(ctx context.Context, req ctr
 						return ctrl.Result{}, err
 					}
 					log.Info("DeploymentTarget is marked to Deleted", "DeploymentTarget", dt.Name)
+					recordNormalEvent(r.Recorder, &dtc, EventReasonDeletingDeploymentTarget,
+						"Deleting DeploymentTarget %s, as the ReclaimPolicy of DeploymentTargetClass %s is Delete", dt.Name, dtcls.Name)
 					return ctrl.Result{}, nil
 
 				} else if dtcls.Spec.ReclaimPolicy == applicationv1alpha1.ReclaimPolicy_Retain {
@@ -133,6 +185,8 @@ func (r *DeploymentTargetClaimReconciler) Reconcile(ctx context.Context, req ctr
 						if err != nil {
 							return ctrl.Result{}, fmt.Errorf("failed to update DeploymentTarget %s in namespace %s to Released status", dt.Name, dt.Namespace)
 						}
+						recordNormalEvent(r.Recorder, &dtc, EventReasonDeploymentTargetReleased,
+							"Released DeploymentTarget %s, as the ReclaimPolicy of DeploymentTargetClass %s is Retain", dt.Name, dtcls.Name)
 						return ctrl.Result{}, nil
 					}
 				} else {
@@ -154,8 +208,14 @@ func (r *DeploymentTargetClaimReconciler) Reconcile(ctx context.Context, req ctr
 	// If the binding is already done, we need to check if the DTC is still bound to a DT
 	// and update the status accordingly
 	if isBindingCompleted(dtc) {
-		if err := handleBoundedDeploymentTargetClaim(ctx, r.Client, dtc, log); err != nil {
+		if err := handleBoundedDeploymentTargetClaim(ctx, r.Client, &dtc, log); err != nil {
 			log.Error(err, "failed to process bounded DeploymentTargetClaim")
+
+			if dtc.Status.Phase == applicationv1alpha1.DeploymentTargetClaimPhase_Lost {
+				recordWarningEvent(r.Recorder, &dtc, EventReasonDeploymentTargetLost,
+					"The DeploymentTarget bound to the DeploymentTargetClaim no longer exists")
+				r.setBindingFailed(ctx, &dtc, "the DeploymentTarget bound to the DeploymentTargetClaim no longer exists", log)
+			}
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -164,6 +224,23 @@ func (r *DeploymentTargetClaimReconciler) Reconcile(ctx context.Context, req ctr
 	// If the user doesn't set the DT, check if there is a matching DT available
 	// or if it needs to be dynamically provisioned.
 	if dtc.Spec.TargetName == "" {
+		if _, err := getDTCLabelSelector(dtc); err != nil {
+			log.Error(err, "DeploymentTargetClaim has an invalid label selector", "annotation", DeploymentTargetClaimSelectorAnnotation)
+			recordWarningEvent(r.Recorder, &dtc, EventReasonInvalidSelector,
+				"The label selector in annotation %s is invalid: %v", DeploymentTargetClaimSelectorAnnotation, err)
+
+			// The user needs to fix the selector: update the DTC status as Pending, and don't requeue.
+			if err := updateDTCStatusPhase(ctx, r.Client, &dtc, applicationv1alpha1.DeploymentTargetClaimPhase_Pending, log); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if err := updateDTCBindingPhase(ctx, r.Client, &dtc, DeploymentTargetClaimBindingPhase_Failed, err.Error(), log); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			return ctrl.Result{}, nil
+		}
+
 		dt, err := findMatchingDTForDTC(ctx, r.Client, dtc)
 		if err != nil {
 			log.Error(err, "failed to find a DeploymentTarget that matches the DeploymentTargetClaim")
@@ -173,13 +250,27 @@ func (r *DeploymentTargetClaimReconciler) Reconcile(ctx context.Context, req ctr
 		// If a best match DT is available bind it to the current DTC.
 		if dt != nil {
 			log.Info("Found a matching DeploymentTarget for DeploymentTargetClaim", "DeploymentTarget", dt.Name)
+
+			// Record the selector that was used to choose the DT: the DTC is updated when it is bound below.
+			if selector, found := dtc.Annotations[DeploymentTargetClaimSelectorAnnotation]; found && !isMarkedForDynamicProvisioning(dtc) {
+				dtc.Annotations[DeploymentTargetClaimBoundBySelectorAnnotation] = selector
+			}
+
 			err = bindDeploymentTargetClaimToTarget(ctx, r.Client, &dtc, dt, true, log)
 			if err != nil {
 				log.Error(err, "failed to bind DeploymentTargetClaim to the DeploymentTarget", "DeploymentTargetName", dt.Name, "Namespace", dt.Name)
+				recordWarningEvent(r.Recorder, &dtc, EventReasonBindFailed,
+					"Unable to bind to DeploymentTarget %s: %v", dt.Name, err)
+				r.setBindingFailed(ctx, &dtc, fmt.Sprintf("unable to bind to DeploymentTarget %s: %v", dt.Name, err), log)
+				return ctrl.Result{}, err
+			}
+
+			if err := updateDTCBindingBound(ctx, r.Client, &dtc, dt.Name, log); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			log.Info("DeploymentTargetClaim bound to DeploymentTarget", "DeploymentTargetName", dt.Name, "Namespace", dt.Namespace)
+			recordNormalEvent(r.Recorder, &dtc, EventReasonBound, "Bound to DeploymentTarget %s", dt.Name)
 
 			return ctrl.Result{}, nil
 		}
@@ -190,6 +281,18 @@ func (r *DeploymentTargetClaimReconciler) Reconcile(ctx context.Context, req ctr
 			return ctrl.Result{}, err
 		}
 		log.Info("Waiting for the DeploymentTarget to be dynamically created by the provisioner")
+		if isMarkedForDynamicProvisioning(dtc) {
+			if dtcBindingPhase(dtc) != DeploymentTargetClaimBindingPhase_Provisioning {
+				recordNormalEvent(r.Recorder, &dtc, EventReasonWaitingForProvisioner,
+					"Waiting for a DeploymentTarget to be dynamically provisioned for DeploymentTargetClass %s", dtc.Spec.DeploymentTargetClassName)
+			}
+
+			if err := updateDTCBindingPhase(ctx, r.Client, &dtc, DeploymentTargetClaimBindingPhase_Provisioning, "", log); err != nil {
+				return ctrl.Result{}, err
+			}
+		} else if err := updateDTCBindingPhase(ctx, r.Client, &dtc, DeploymentTargetClaimBindingPhase_Pending, "", log); err != nil {
+			return ctrl.Result{}, err
+		}
 
 		return ctrl.Result{}, nil
 	}
@@ -205,12 +308,18 @@ func (r *DeploymentTargetClaimReconciler) Reconcile(ctx context.Context, req ctr
 	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(&dt), &dt); err != nil {
 		if apierr.IsNotFound(err) {
 			log.Info("Waiting for DeploymentTarget to be created", "DeploymentTarget", dt.Name, "Namespace", dt.Namespace)
+			recordNormalEvent(r.Recorder, &dtc, EventReasonWaitingForDeploymentTarget,
+				"Waiting for DeploymentTarget %s to be created", dt.Name)
 
 			// Update the DTC status as Pending and wait for DT to be created.
 			if err := updateDTCStatusPhase(ctx, r.Client, &dtc, applicationv1alpha1.DeploymentTargetClaimPhase_Pending, log); err != nil {
 				return ctrl.Result{}, err
 			}
 
+			if err := updateDTCBindingPhase(ctx, r.Client, &dtc, DeploymentTargetClaimBindingPhase_Pending, "", log); err != nil {
+				return ctrl.Result{}, err
+			}
+
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -223,37 +332,66 @@ func (r *DeploymentTargetClaimReconciler) Reconcile(ctx context.Context, req ctr
 			err := bindDeploymentTargetClaimToTarget(ctx, r.Client, &dtc, &dt, false, log)
 			if err != nil {
 				log.Error(err, "failed to bind DeploymentTargetClaim to the DeploymentTarget", "DeploymentTargetName", dt.Name, "Namespace", dt.Name)
+				recordWarningEvent(r.Recorder, &dtc, EventReasonBindFailed,
+					"Unable to bind to DeploymentTarget %s: %v", dt.Name, err)
+				r.setBindingFailed(ctx, &dtc, fmt.Sprintf("unable to bind to DeploymentTarget %s: %v", dt.Name, err), log)
 				return ctrl.Result{}, err
 			}
 		} else {
 			log.Error(nil, "DeploymentTargetClaim wants to claim a DeploymentTarget that is already claimed", "DeploymentTarget", dt.Name)
+			recordWarningEvent(r.Recorder, &dtc, EventReasonDeploymentTargetAlreadyClaimed,
+				"DeploymentTarget %s is already claimed by DeploymentTargetClaim %s", dt.Name, dt.Spec.ClaimRef)
 
 			// Update the DTC status to Pending since the DT is not available
 			if err := updateDTCStatusPhase(ctx, r.Client, &dtc, applicationv1alpha1.DeploymentTargetClaimPhase_Pending, log); err != nil {
 				return ctrl.Result{}, err
 			}
 
+			if err := updateDTCBindingPhase(ctx, r.Client, &dtc, DeploymentTargetClaimBindingPhase_Failed,
+				fmt.Sprintf("DeploymentTarget %s is already claimed by DeploymentTargetClaim %s", dt.Name, dt.Spec.ClaimRef), log); err != nil {
+				return ctrl.Result{}, err
+			}
+
 			return ctrl.Result{}, nil
 		}
 	} else {
 		// At this stage, DT isn't claimed by anyone. The current DTC can try to claim it.
 		if err := doesDTMatchDTC(dt, dtc); err != nil {
 			log.Error(err, "DeploymentTarget does not match the specified DeploymentTargetClaim")
+			recordWarningEvent(r.Recorder, &dtc, EventReasonDeploymentTargetMismatch,
+				"DeploymentTarget %s does not match the DeploymentTargetClaim: %v", dt.Name, err)
+			r.setBindingFailed(ctx, &dtc, err.Error(), log)
 			return ctrl.Result{}, err
 		}
 
 		err := bindDeploymentTargetClaimToTarget(ctx, r.Client, &dtc, &dt, false, log)
 		if err != nil {
 			log.Error(err, "failed to bind DeploymentTargetClaim to the DeploymentTarget", "DeploymentTargetName", dt.Name, "Namespace", dt.Name)
+			recordWarningEvent(r.Recorder, &dtc, EventReasonBindFailed,
+				"Unable to bind to DeploymentTarget %s: %v", dt.Name, err)
+			r.setBindingFailed(ctx, &dtc, fmt.Sprintf("unable to bind to DeploymentTarget %s: %v", dt.Name, err), log)
 			return ctrl.Result{}, err
 		}
 	}
 
+	if err := updateDTCBindingBound(ctx, r.Client, &dtc, dt.Name, log); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	log.Info("DeploymentTargetClaim bound to DeploymentTarget", "DeploymentTargetName", dt.Name, "Namespace", dt.Namespace)
+	recordNormalEvent(r.Recorder, &dtc, EventReasonBound, "Bound to DeploymentTarget %s", dt.Name)
 
 	return ctrl.Result{}, nil
 }
 
+// setBindingFailed sets the binding phase of the DTC to Failed, with the given reason. It is called when the reconcile is
+// already returning an error, so a failure to update the DTC is only logged.
+func (r *DeploymentTargetClaimReconciler) setBindingFailed(ctx context.Context, dtc *applicationv1alpha1.DeploymentTargetClaim, reason string, log logr.Logger) {
+	if err := updateDTCBindingPhase(ctx, r.Client, dtc, DeploymentTargetClaimBindingPhase_Failed, reason, log); err != nil {
+		log.Error(err, "failed to set the binding phase of the DeploymentTargetClaim to Failed")
+	}
+}
+
 // bindDeploymentTargetClaimToTarget binds the given DeploymentTarget to a DeploymentTargetClaim by
 // setting the dtc.spec.targetName to the DT name and adding the "bound-by-controller" annotation to the DTC.
 // It also updates the phase of the DT and DTC to bound.
@@ -317,8 +455,8 @@ func bindDeploymentTargetClaimToTarget(ctx context.Context, k8sClient client.Cli
 
 // handleBoundedDeploymentTargetClaim handles the DTCs that are already bounded i.e have the "bind-complete" annotation.
 // It checks if the DTC is still bound to DTC and updates the status accordingly.
-func handleBoundedDeploymentTargetClaim(ctx context.Context, k8sClient client.Client, dtc applicationv1alpha1.DeploymentTargetClaim, log logr.Logger) error {
-	if !isBindingCompleted(dtc) {
+func handleBoundedDeploymentTargetClaim(ctx context.Context, k8sClient client.Client, dtc *applicationv1alpha1.DeploymentTargetClaim, log logr.Logger) error {
+	if !isBindingCompleted(*dtc) {
 		return nil
 	}
 
@@ -331,7 +469,7 @@ func handleBoundedDeploymentTargetClaim(ctx context.Context, k8sClient client.Cl
 		if dtc.Spec.DeploymentTargetClassName == "" {
 			// If the class name doesn't exist remove the provisioner annotation
 			delete(dtc.Annotations, applicationv1alpha1.AnnTargetProvisioner)
-			if err := k8sClient.Update(ctx, &dtc); err != nil {
+			if err := k8sClient.Update(ctx, dtc); err != nil {
 				return err
 			}
 			log.Info("Deleted the provisioner annotation from DeploymentTargetClaim because the class name was not set", "annotation", applicationv1alpha1.AnnTargetProvisioner)
@@ -339,14 +477,14 @@ func handleBoundedDeploymentTargetClaim(ctx context.Context, k8sClient client.Cl
 			// If the class name exists, but doesn't match the provisioner value
 			// update the annotation with the correct provisioner value.
 			dtc.Annotations[applicationv1alpha1.AnnTargetProvisioner] = string(dtc.Spec.DeploymentTargetClassName)
-			if err := k8sClient.Update(ctx, &dtc); err != nil {
+			if err := k8sClient.Update(ctx, dtc); err != nil {
 				return err
 			}
 			log.Info("Updated the provisioner annotation with the correct class name", "annotation", applicationv1alpha1.AnnTargetProvisioner, "className", string(dtc.Spec.DeploymentTargetClassName))
 		}
 	}
 
-	dt, err := getDTBoundByDTC(ctx, k8sClient, &dtc)
+	dt, err := getDTBoundByDTC(ctx, k8sClient, dtc)
 	if err != nil && !apierr.IsNotFound(err) {
 		return fmt.Errorf("failed to get a DeploymentTarget for the given DeploymentTargetClaim %s", dtc.Name)
 	}
@@ -355,7 +493,7 @@ func handleBoundedDeploymentTargetClaim(ctx context.Context, k8sClient client.Cl
 		log.Info("DeploymentTarget not found for a bounded DeploymentTargetClaim")
 
 		// DeploymentTarget is not found for the DeploymentTargetClaim, so update the status as Lost
-		err := updateDTCStatusPhase(ctx, k8sClient, &dtc, applicationv1alpha1.DeploymentTargetClaimPhase_Lost, log)
+		err := updateDTCStatusPhase(ctx, k8sClient, dtc, applicationv1alpha1.DeploymentTargetClaimPhase_Lost, log)
 		if err != nil {
 			return err
 		}
@@ -370,8 +508,11 @@ func handleBoundedDeploymentTargetClaim(ctx context.Context, k8sClient client.Cl
 		return err
 	}
 
-	return updateDTCStatusPhase(ctx, k8sClient, &dtc, applicationv1alpha1.DeploymentTargetClaimPhase_Bound, log)
+	if err := updateDTCStatusPhase(ctx, k8sClient, dtc, applicationv1alpha1.DeploymentTargetClaimPhase_Bound, log); err != nil {
+		return err
+	}
 
+	return updateDTCBindingBound(ctx, k8sClient, dtc, dt.Name, log)
 }
 
 // handleDynamicDTCProvisioning processes the DeploymentTargetClaim for dynamic provisioning.
@@ -402,6 +543,11 @@ func handleDynamicDTCProvisioning(ctx context.Context, k8sClient client.Client,
 }
 
 // findMatchingDTForDTC tries to find a DT that matches the given DTC in a namespace.
+//
+// If more than one DT matches, the best match is chosen deterministically, similar to how PVs are matched to PVCs:
+// 1. A DT that already has a claim ref to the DTC is preferred.
+// 2. Otherwise, the oldest DT is chosen.
+// 3. Otherwise (DTs with the same creation timestamp), the DT with the lowest name is chosen.
 func findMatchingDTForDTC(ctx context.Context, k8sClient client.Client, dtc applicationv1alpha1.DeploymentTargetClaim) (*applicationv1alpha1.DeploymentTarget, error) {
 	dtList := applicationv1alpha1.DeploymentTargetList{}
 	if err := k8sClient.List(ctx, &dtList, &client.ListOptions{Namespace: dtc.Namespace}); err != nil {
@@ -415,21 +561,60 @@ func findMatchingDTForDTC(ctx context.Context, k8sClient client.Client, dtc appl
 			return dt.Spec.ClaimRef == dtc.Name && doesDTMatchDTC(dt, dtc) == nil
 		}
 	} else {
+		selector, err := getDTCLabelSelector(dtc)
+		if err != nil {
+			return nil, err
+		}
+
 		// Check if there is a matching DT created by the user
 		matcher = func(dt applicationv1alpha1.DeploymentTarget) bool {
-			return doesDTMatchDTC(dt, dtc) == nil
+			return selector.Matches(labels.Set(dt.Labels)) && doesDTMatchDTC(dt, dtc) == nil
 		}
 	}
 
-	var dt *applicationv1alpha1.DeploymentTarget
+	candidates := []*applicationv1alpha1.DeploymentTarget{}
 	for i, d := range dtList.Items {
 		if matcher(d) {
-			dt = &dtList.Items[i]
-			break
+			candidates = append(candidates, &dtList.Items[i])
 		}
 	}
 
-	return dt, nil
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+
+		if aClaimed, bClaimed := a.Spec.ClaimRef == dtc.Name, b.Spec.ClaimRef == dtc.Name; aClaimed != bClaimed {
+			return aClaimed
+		}
+
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+
+		return a.Name < b.Name
+	})
+
+	return candidates[0], nil
+}
+
+// getDTCLabelSelector returns the label selector of the DeploymentTargetClaimSelectorAnnotation annotation of the DTC.
+// If the annotation is not set, a selector that matches everything is returned.
+func getDTCLabelSelector(dtc applicationv1alpha1.DeploymentTargetClaim) (labels.Selector, error) {
+	value, found := dtc.Annotations[DeploymentTargetClaimSelectorAnnotation]
+	if !found {
+		return labels.Everything(), nil
+	}
+
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q in annotation %s of DeploymentTargetClaim %s in namespace %s: %v",
+			value, DeploymentTargetClaimSelectorAnnotation, dtc.Name, dtc.Namespace, err)
+	}
+
+	return selector, nil
 }
 
 // A DT matches a given DTC if it satisfies the below conditions
@@ -468,9 +653,13 @@ func updateDTCStatusPhase(ctx context.Context, k8sClient client.Client, dtc *app
 		return nil
 	}
 
-	dtc.Status.Phase = targetPhase
-
-	if err := k8sClient.Status().Update(ctx, dtc); err != nil {
+	if err := updateStatus(ctx, k8sClient, dtc, func() bool {
+		if dtc.Status.Phase == targetPhase {
+			return false
+		}
+		dtc.Status.Phase = targetPhase
+		return true
+	}); err != nil {
 		return err
 	}
 
@@ -478,14 +667,81 @@ func updateDTCStatusPhase(ctx context.Context, k8sClient client.Client, dtc *app
 	return nil
 }
 
+// updateDTCBindingPhase sets the binding phase and failure reason annotations of the DTC, and updates the DTC if either
+// of them changed. The failure reason is only set for the Failed phase. For the Bound phase, use updateDTCBindingBound.
+func updateDTCBindingPhase(ctx context.Context, k8sClient client.Client, dtc *applicationv1alpha1.DeploymentTargetClaim, phase DeploymentTargetClaimBindingPhase, reason string, log logr.Logger) error {
+	if phase != DeploymentTargetClaimBindingPhase_Failed {
+		reason = ""
+	}
+
+	return updateDTCBindingAnnotations(ctx, k8sClient, dtc, map[string]string{
+		DeploymentTargetClaimBindingPhaseAnnotation:         string(phase),
+		DeploymentTargetClaimBindingFailureReasonAnnotation: reason,
+		DeploymentTargetClaimBoundTargetAnnotation:          "",
+	}, log)
+}
+
+// updateDTCBindingBound sets the binding phase annotation of the DTC to Bound, and the bound DeploymentTarget
+// annotation to the name of the DT, and updates the DTC if either of them changed.
+func updateDTCBindingBound(ctx context.Context, k8sClient client.Client, dtc *applicationv1alpha1.DeploymentTargetClaim, dtName string, log logr.Logger) error {
+	return updateDTCBindingAnnotations(ctx, k8sClient, dtc, map[string]string{
+		DeploymentTargetClaimBindingPhaseAnnotation:         string(DeploymentTargetClaimBindingPhase_Bound),
+		DeploymentTargetClaimBindingFailureReasonAnnotation: "",
+		DeploymentTargetClaimBoundTargetAnnotation:          dtName,
+	}, log)
+}
+
+// updateDTCBindingAnnotations sets the given annotations on the DTC (removing those with an empty value), and updates
+// the DTC only if an annotation changed.
+func updateDTCBindingAnnotations(ctx context.Context, k8sClient client.Client, dtc *applicationv1alpha1.DeploymentTargetClaim, desired map[string]string, log logr.Logger) error {
+	if dtc.Annotations == nil {
+		dtc.Annotations = map[string]string{}
+	}
+
+	updated := false
+	for key, value := range desired {
+		existing, found := dtc.Annotations[key]
+		if value == "" {
+			if found {
+				delete(dtc.Annotations, key)
+				updated = true
+			}
+		} else if !found || existing != value {
+			dtc.Annotations[key] = value
+			updated = true
+		}
+	}
+
+	if !updated {
+		return nil
+	}
+
+	if err := k8sClient.Update(ctx, dtc); err != nil {
+		return err
+	}
+
+	log.Info("Updated the binding phase of DeploymentTargetClaim", "bindingPhase", dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation],
+		"reason", dtc.Annotations[DeploymentTargetClaimBindingFailureReasonAnnotation])
+	return nil
+}
+
+// dtcBindingPhase returns the binding phase of the DTC, from its binding phase annotation, or "" if it is not set.
+func dtcBindingPhase(dtc applicationv1alpha1.DeploymentTargetClaim) DeploymentTargetClaimBindingPhase {
+	return DeploymentTargetClaimBindingPhase(dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation])
+}
+
 func updateDTStatusPhase(ctx context.Context, k8sClient client.Client, dt *applicationv1alpha1.DeploymentTarget, targetPhase applicationv1alpha1.DeploymentTargetPhase, log logr.Logger) error {
 	if dt.Status.Phase == targetPhase {
 		return nil
 	}
 
-	dt.Status.Phase = targetPhase
-
-	if err := k8sClient.Status().Update(ctx, dt); err != nil {
+	if err := updateStatus(ctx, k8sClient, dt, func() bool {
+		if dt.Status.Phase == targetPhase {
+			return false
+		}
+		dt.Status.Phase = targetPhase
+		return true
+	}); err != nil {
 		return err
 	}
 
@@ -615,12 +871,14 @@ func conflictErrWrapper(dtcName, target, dtName, claimRef string) func(string) e
 // SetupWithManager sets up the controller with the Manager.
 func (r *DeploymentTargetClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		Named(sharedutil.ControllerNameDeploymentTargetClaim).
 		For(&applicationv1alpha1.DeploymentTargetClaim{}).
 		Watches(
 			&source.Kind{Type: &applicationv1alpha1.DeploymentTarget{}},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForDeploymentTarget),
-			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Complete(r)
+			// Label changes are required to match DTs against the label selector of DTCs
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		Complete(sharedutil.NewRequeueMetricsMiddleware(sharedutil.ControllerNameDeploymentTargetClaim, sharedutil.NewReconcileTimingMiddleware("DeploymentTargetClaim", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &applicationv1alpha1.DeploymentTargetClaim{} }, r))))
 }
 
 // Map all incoming DT events to corresponding DTC requests to be handled by the Reconciler.
@@ -646,7 +904,7 @@ func (r *DeploymentTargetClaimReconciler) findObjectsForDeploymentTarget(dt clie
 	for _, d := range dtcList.Items {
 		dtc := d
 		// We only want to reconcile for DTs that have a corresponding DTC.
-		if dtc.Spec.TargetName == dt.GetName() || dtObj.Spec.ClaimRef == dtc.Name {
+		if dtc.Spec.TargetName == dt.GetName() || dtObj.Spec.ClaimRef == dtc.Name || doesDTMatchDTCSelector(*dtObj, dtc) {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: client.ObjectKeyFromObject(&dtc),
 			})
@@ -655,3 +913,21 @@ func (r *DeploymentTargetClaimReconciler) findObjectsForDeploymentTarget(dt clie
 
 	return requests
 }
+
+// doesDTMatchDTCSelector returns true if the DTC is waiting to be bound, has a label selector, and the DT labels match that selector.
+func doesDTMatchDTCSelector(dt applicationv1alpha1.DeploymentTarget, dtc applicationv1alpha1.DeploymentTargetClaim) bool {
+	if dtc.Spec.TargetName != "" || isBindingCompleted(dtc) {
+		return false
+	}
+
+	if _, found := dtc.Annotations[DeploymentTargetClaimSelectorAnnotation]; !found {
+		return false
+	}
+
+	selector, err := getDTCLabelSelector(dtc)
+	if err != nil {
+		return false
+	}
+
+	return selector.Matches(labels.Set(dt.Labels))
+}
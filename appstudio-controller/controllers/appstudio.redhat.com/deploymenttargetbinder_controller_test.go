@@ -3,6 +3,7 @@ package appstudioredhatcom
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
@@ -433,6 +434,10 @@ var _ = Describe("Test DeploymentTargetClaimBinderController", func() {
 				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&dtc), &dtc)
 				Expect(err).To(BeNil())
 				Expect(dtc.Status.Phase).To(Equal(appstudiosharedv1.DeploymentTargetClaimPhase_Lost))
+
+				By("check if the binding phase is Failed")
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation]).To(Equal(string(DeploymentTargetClaimBindingPhase_Failed)))
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingFailureReasonAnnotation]).ToNot(BeEmpty())
 			})
 
 			It("should return an error and set the DTC status to Lost if no matching DT is found", func() {
@@ -572,6 +577,11 @@ var _ = Describe("Test DeploymentTargetClaimBinderController", func() {
 				Expect(dtc.Annotations[appstudiosharedv1.AnnTargetProvisioner]).To(Equal(string(dtc.Spec.DeploymentTargetClassName)))
 				Expect(dtc.Status.Phase).To(Equal(appstudiosharedv1.DeploymentTargetClaimPhase_Pending))
 
+				By("verify if the binding phase is Provisioning")
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation]).To(Equal(string(DeploymentTargetClaimBindingPhase_Provisioning)))
+				_, found := dtc.Annotations[DeploymentTargetClaimBoundTargetAnnotation]
+				Expect(found).To(BeFalse())
+
 				By("verify if the bound-by-controller annotation is not set")
 				Expect(dtc.Annotations[appstudiosharedv1.AnnBoundByController]).ToNot(Equal(string(appstudiosharedv1.AnnBinderValueTrue)))
 			})
@@ -634,8 +644,101 @@ var _ = Describe("Test DeploymentTargetClaimBinderController", func() {
 				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&dtc), &dtc)
 				Expect(err).To(BeNil())
 				Expect(dtc.Status.Phase).To(Equal(appstudiosharedv1.DeploymentTargetClaimPhase_Pending))
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation]).To(Equal(string(DeploymentTargetClaimBindingPhase_Pending)))
+
 				// check if the provision annotation is not set
-				Expect(dtc.Annotations).Should(BeNil())
+				_, found := dtc.Annotations[appstudiosharedv1.AnnTargetProvisioner]
+				Expect(found).To(BeFalse())
+			})
+
+			It("should bind the DTC to a DT that matches its label selector", func() {
+				By("create a DTC with a label selector")
+				dtc := getDeploymentTargetClaim(func(dtc *appstudiosharedv1.DeploymentTargetClaim) {
+					dtc.Annotations[DeploymentTargetClaimSelectorAnnotation] = "region=us-east-1"
+				})
+				err := k8sClient.Create(ctx, &dtc)
+				Expect(err).To(BeNil())
+
+				By("create an available DT that doesn't match the selector, and one that does")
+				nonMatchingDT := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Name = "a-dt"
+					dt.Labels = map[string]string{"region": "eu-west-1"}
+					dt.Status.Phase = appstudiosharedv1.DeploymentTargetPhase_Available
+				})
+				err = k8sClient.Create(ctx, &nonMatchingDT)
+				Expect(err).To(BeNil())
+
+				matchingDT := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Name = "b-dt"
+					dt.Labels = map[string]string{"region": "us-east-1"}
+					dt.Status.Phase = appstudiosharedv1.DeploymentTargetPhase_Available
+				})
+				err = k8sClient.Create(ctx, &matchingDT)
+				Expect(err).To(BeNil())
+
+				request := newRequest(dtc.Namespace, dtc.Name)
+				res, err := reconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(ctrl.Result{}))
+
+				By("verify if the DTC is bound to the matching DT, and the bound-by-selector annotation is set")
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&dtc), &dtc)
+				Expect(err).To(BeNil())
+				Expect(dtc.Spec.TargetName).To(Equal(matchingDT.Name))
+				Expect(dtc.Status.Phase).To(Equal(appstudiosharedv1.DeploymentTargetClaimPhase_Bound))
+				Expect(dtc.Annotations[DeploymentTargetClaimBoundBySelectorAnnotation]).To(Equal("region=us-east-1"))
+				Expect(dtc.Annotations[appstudiosharedv1.AnnBoundByController]).To(Equal(appstudiosharedv1.AnnBinderValueTrue))
+
+				By("verify if the binding phase is Bound, and the bound DT is recorded")
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation]).To(Equal(string(DeploymentTargetClaimBindingPhase_Bound)))
+				Expect(dtc.Annotations[DeploymentTargetClaimBoundTargetAnnotation]).To(Equal(matchingDT.Name))
+
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&matchingDT), &matchingDT)
+				Expect(err).To(BeNil())
+				Expect(matchingDT.Status.Phase).To(Equal(appstudiosharedv1.DeploymentTargetPhase_Bound))
+
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&nonMatchingDT), &nonMatchingDT)
+				Expect(err).To(BeNil())
+				Expect(nonMatchingDT.Status.Phase).To(Equal(appstudiosharedv1.DeploymentTargetPhase_Available))
+			})
+
+			It("should mark the DTC as pending if the label selector is invalid", func() {
+				By("create a DTC with an invalid label selector")
+				dtc := getDeploymentTargetClaim(func(dtc *appstudiosharedv1.DeploymentTargetClaim) {
+					dtc.Annotations[DeploymentTargetClaimSelectorAnnotation] = "region in (us-east-1"
+				})
+				err := k8sClient.Create(ctx, &dtc)
+				Expect(err).To(BeNil())
+
+				request := newRequest(dtc.Namespace, dtc.Name)
+				res, err := reconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(ctrl.Result{}))
+
+				By("verify if the DTC is set to pending phase, and isn't marked for dynamic provisioning")
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&dtc), &dtc)
+				Expect(err).To(BeNil())
+				Expect(dtc.Status.Phase).To(Equal(appstudiosharedv1.DeploymentTargetClaimPhase_Pending))
+				_, found := dtc.Annotations[appstudiosharedv1.AnnTargetProvisioner]
+				Expect(found).To(BeFalse())
+
+				By("verify if the binding phase is Failed, with the reason")
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation]).To(Equal(string(DeploymentTargetClaimBindingPhase_Failed)))
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingFailureReasonAnnotation]).To(ContainSubstring("invalid label selector"))
+
+				By("fixing the label selector, and verifying that the failure reason is removed")
+				dtc.Annotations[DeploymentTargetClaimSelectorAnnotation] = "region=us-east-1"
+				err = k8sClient.Update(ctx, &dtc)
+				Expect(err).To(BeNil())
+
+				_, err = reconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&dtc), &dtc)
+				Expect(err).To(BeNil())
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation]).To(Equal(string(DeploymentTargetClaimBindingPhase_Provisioning)))
+				_, found = dtc.Annotations[DeploymentTargetClaimBindingFailureReasonAnnotation]
+				Expect(found).To(BeFalse())
 			})
 		})
 
@@ -749,6 +852,11 @@ var _ = Describe("Test DeploymentTargetClaimBinderController", func() {
 				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&dtc), &dtc)
 				Expect(err).To(BeNil())
 				Expect(dtc.Status.Phase).To(Equal(appstudiosharedv1.DeploymentTargetClaimPhase_Pending))
+
+				By("verify if the binding phase is Failed, as the DT is claimed by another DTC")
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingPhaseAnnotation]).To(Equal(string(DeploymentTargetClaimBindingPhase_Failed)))
+				Expect(dtc.Annotations[DeploymentTargetClaimBindingFailureReasonAnnotation]).To(Equal(
+					fmt.Sprintf("DeploymentTarget %s is already claimed by DeploymentTargetClaim random-dtc", dt.Name)))
 			})
 
 			It("should return an error if the DT doesn't match the DTC", func() {
@@ -1043,6 +1151,83 @@ var _ = Describe("Test DeploymentTargetClaimBinderController", func() {
 				Expect(client.ObjectKeyFromObject(dt)).To(Equal(client.ObjectKeyFromObject(&expected)))
 			})
 
+			It("should only match DTs whose labels match the label selector of the DTC", func() {
+				dtc := getDeploymentTargetClaim(func(dtc *appstudiosharedv1.DeploymentTargetClaim) {
+					dtc.Annotations[DeploymentTargetClaimSelectorAnnotation] = "tier in (gold,silver)"
+				})
+				err := k8sClient.Create(ctx, &dtc)
+				Expect(err).To(BeNil())
+
+				nonMatching := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Name = "a-dt"
+					dt.Labels = map[string]string{"tier": "bronze"}
+				})
+				err = k8sClient.Create(ctx, &nonMatching)
+				Expect(err).To(BeNil())
+
+				dt, err := findMatchingDTForDTC(ctx, k8sClient, dtc)
+				Expect(err).To(BeNil())
+				Expect(dt).To(BeNil())
+
+				expected := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Name = "b-dt"
+					dt.Labels = map[string]string{"tier": "silver"}
+				})
+				err = k8sClient.Create(ctx, &expected)
+				Expect(err).To(BeNil())
+
+				dt, err = findMatchingDTForDTC(ctx, k8sClient, dtc)
+				Expect(err).To(BeNil())
+				Expect(client.ObjectKeyFromObject(dt)).To(Equal(client.ObjectKeyFromObject(&expected)))
+			})
+
+			It("should prefer a DT that claims the DTC, then the oldest DT, then the DT with the lowest name", func() {
+				dtc := getDeploymentTargetClaim()
+
+				now := metav1.Now()
+				older := metav1.NewTime(now.Add(-time.Hour))
+
+				newerDT := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Name = "a-dt"
+					dt.CreationTimestamp = now
+				})
+				olderDT := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Name = "c-dt"
+					dt.CreationTimestamp = older
+				})
+				sameAgeDT := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Name = "b-dt"
+					dt.CreationTimestamp = older
+				})
+				claimingDT := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Name = "d-dt"
+					dt.CreationTimestamp = now
+					dt.Spec.ClaimRef = dtc.Name
+				})
+
+				By("verify that the oldest DT with the lowest name is chosen")
+				fakeClient := fake.NewClientBuilder().WithScheme(reconciler.Scheme).WithObjects(&newerDT, &olderDT, &sameAgeDT).Build()
+				dt, err := findMatchingDTForDTC(ctx, fakeClient, dtc)
+				Expect(err).To(BeNil())
+				Expect(dt.Name).To(Equal(sameAgeDT.Name))
+
+				By("verify that a DT that claims the DTC is preferred")
+				fakeClient = fake.NewClientBuilder().WithScheme(reconciler.Scheme).WithObjects(&newerDT, &olderDT, &sameAgeDT, &claimingDT).Build()
+				dt, err = findMatchingDTForDTC(ctx, fakeClient, dtc)
+				Expect(err).To(BeNil())
+				Expect(dt.Name).To(Equal(claimingDT.Name))
+			})
+
+			It("should return an error if the label selector of the DTC is invalid", func() {
+				dtc := getDeploymentTargetClaim(func(dtc *appstudiosharedv1.DeploymentTargetClaim) {
+					dtc.Annotations[DeploymentTargetClaimSelectorAnnotation] = "!!invalid"
+				})
+
+				dt, err := findMatchingDTForDTC(ctx, k8sClient, dtc)
+				Expect(err).ToNot(BeNil())
+				Expect(dt).To(BeNil())
+			})
+
 			It("no matching DT is found", func() {
 				dtc := getDeploymentTargetClaim()
 				err := k8sClient.Create(ctx, &dtc)
@@ -1140,6 +1325,28 @@ var _ = Describe("Test DeploymentTargetClaimBinderController", func() {
 				}))
 			})
 
+			It("should return a DTC request if the incoming DT matches its label selector", func() {
+				dtc := getDeploymentTargetClaim(func(dtc *appstudiosharedv1.DeploymentTargetClaim) {
+					dtc.Annotations[DeploymentTargetClaimSelectorAnnotation] = "region=us-east-1"
+				})
+				err := k8sClient.Create(ctx, &dtc)
+				Expect(err).To(BeNil())
+
+				dt := getDeploymentTarget(func(dt *appstudiosharedv1.DeploymentTarget) {
+					dt.Labels = map[string]string{"region": "eu-west-1"}
+				})
+
+				reqs := reconciler.findObjectsForDeploymentTarget(&dt)
+				Expect(reqs).To(Equal([]reconcile.Request{}))
+
+				dt.Labels["region"] = "us-east-1"
+
+				reqs = reconciler.findObjectsForDeploymentTarget(&dt)
+				Expect(reqs).To(Equal([]reconcile.Request{
+					newRequest(dtc.Namespace, dtc.Name),
+				}))
+			})
+
 			It("shouldn't return any request if no DTC was found while handling the DT event", func() {
 				dt := getDeploymentTarget()
 
@@ -25,9 +25,11 @@ import (
 	"github.com/codeready-toolchain/toolchain-common/pkg/condition"
 	applicationv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -42,6 +44,9 @@ type DeploymentTargetReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Clock  sharedutil.Clock
+
+	// Recorder emits Kubernetes Events for the DeploymentTarget, describing the actions of the controller.
+	Recorder record.EventRecorder
 }
 
 const (
@@ -56,6 +61,7 @@ const (
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargets/finalizers,verbs=update
 //+kubebuilder:rbac:groups=toolchain.dev.openshift.com,resources=spacerequests,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=toolchain.dev.openshift.com,resources=spacerequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -158,6 +164,8 @@ func (r *DeploymentTargetReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		recordNormalEvent(r.Recorder, &dt, EventReasonDeletingSpaceRequest,
+			"Deleting SpaceRequest %s, as the ReclaimPolicy of DeploymentTargetClass %s is Delete", sr.Name, dtClass.Name)
 
 		return ctrl.Result{Requeue: true}, nil
 	}
@@ -176,11 +184,18 @@ func (r *DeploymentTargetReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if r.Clock.Now().After(sr.GetDeletionTimestamp().Add(time.Minute*2)) &&
 		readyCond.Reason == codereadytoolchainv1alpha1.SpaceTerminatingFailedReason {
 
-		dt.Status.Phase = applicationv1alpha1.DeploymentTargetPhase_Failed
-		if err := r.Client.Status().Update(ctx, &dt); err != nil {
+		if err := updateStatus(ctx, r.Client, &dt, func() bool {
+			if dt.Status.Phase == applicationv1alpha1.DeploymentTargetPhase_Failed {
+				return false
+			}
+			dt.Status.Phase = applicationv1alpha1.DeploymentTargetPhase_Failed
+			return true
+		}); err != nil {
 			return ctrl.Result{}, err
 		}
 		log.Info("The status of DT is updated to Failed", "dtName", dt.Name, "dtNamespace", dt.Namespace)
+		recordWarningEvent(r.Recorder, &dt, EventReasonSpaceRequestTerminationFailed,
+			"SpaceRequest %s was unable to terminate: %s", sr.Name, readyCond.Message)
 		return ctrl.Result{}, nil
 	}
 
@@ -241,6 +256,7 @@ func findMatchingDTClassForDT(ctx context.Context, dt applicationv1alpha1.Deploy
 // SetupWithManager sets up the controller with the Manager.
 func (r *DeploymentTargetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	manager := ctrl.NewControllerManagedBy(mgr).
+		Named(sharedutil.ControllerNameDeploymentTarget).
 		For(&applicationv1alpha1.DeploymentTarget{}).
 		WithEventFilter(predicate.Or(
 			DeploymentTargetDeletePredicate())).
@@ -248,7 +264,7 @@ func (r *DeploymentTargetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&source.Kind{Type: &codereadytoolchainv1alpha1.SpaceRequest{}},
 			handler.EnqueueRequestsFromMapFunc(r.findDeploymentTargetsForSpaceRequests))
 
-	return manager.Complete(r)
+	return manager.Complete(sharedutil.NewRequeueMetricsMiddleware(sharedutil.ControllerNameDeploymentTarget, sharedutil.NewReconcileTimingMiddleware("DeploymentTarget", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &applicationv1alpha1.DeploymentTarget{} }, r))))
 }
 
 func (r *DeploymentTargetReconciler) findDeploymentTargetsForSpaceRequests(sr client.Object) []reconcile.Request {
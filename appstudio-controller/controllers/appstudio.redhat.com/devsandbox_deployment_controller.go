@@ -23,6 +23,7 @@ import (
 
 	codereadytoolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 	applicationv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -220,5 +221,5 @@ func (r *DevsandboxDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) erro
 		For(&codereadytoolchainv1alpha1.SpaceRequest{}).
 		WithEventFilter(predicate.Or(
 			spaceRequestReadyPredicate())).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("DevsandboxDeployment", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &codereadytoolchainv1alpha1.SpaceRequest{} }, r)))
 }
@@ -19,9 +19,13 @@ package appstudioredhatcom
 import (
 	"context"
 	"fmt"
+	"os"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/managed-gitops/appstudio-controller/metrics"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 
@@ -34,6 +38,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -48,6 +53,9 @@ import (
 type EnvironmentReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for the Environment, describing the actions of the controller.
+	Recorder record.EventRecorder
 }
 
 const (
@@ -55,6 +63,38 @@ const (
 	// It is used to identify the Environment that is associated with the secret.
 	// #nosec G101
 	managedEnvironmentSecretLabel = "appstudio.openshift.io/environment-secret"
+
+	// Managed Environment label is added to the GitOpsDeploymentManagedEnvironments that are managed by the Environment
+	// controller, including those that were created before the Environment and then adopted by it.
+	// It is used to identify the Environment that manages the GitOpsDeploymentManagedEnvironment.
+	managedEnvironmentLabel = "appstudio.openshift.io/managed-by-environment"
+
+	// If the deletion protection annotation is set to "true" on an Environment, deletion protection is enabled on the
+	// corresponding GitOpsDeploymentManagedEnvironment (see .spec.deletionProtection).
+	EnvironmentDeletionProtectionAnnotation = "appstudio.openshift.io/deletion-protection"
+
+	// If the disconnected annotation is set to "true" on an Environment, the corresponding GitOpsDeploymentManagedEnvironment
+	// is disconnected (see .spec.disconnected): the cluster credentials are removed from Argo CD, but the deployments to the
+	// Environment are not deleted. Removing the annotation reconnects the Environment.
+	EnvironmentDisconnectedAnnotation = "appstudio.openshift.io/disconnected"
+
+	// EnvironmentNamespaceQuotaCPUAnnotation and EnvironmentNamespaceQuotaMemoryAnnotation are hints for the total CPU/memory
+	// that may be requested by the pods of each namespace that is deployed to, in Kubernetes quantity format (for example,
+	// '2' and '4Gi'). They are copied to .spec.namespaceQuota of the corresponding GitOpsDeploymentManagedEnvironment.
+	EnvironmentNamespaceQuotaCPUAnnotation    = "appstudio.openshift.io/namespace-quota-cpu"
+	EnvironmentNamespaceQuotaMemoryAnnotation = "appstudio.openshift.io/namespace-quota-memory"
+
+	// EnvironmentAllowedRepositoriesAnnotation is a comma-separated list of the Git repository URLs (or glob patterns of
+	// repository URLs) that may be deployed to the Environment. It is copied to .spec.allowedRepositories of the
+	// corresponding GitOpsDeploymentManagedEnvironment.
+	EnvironmentAllowedRepositoriesAnnotation = "appstudio.openshift.io/allowed-repositories"
+
+	// EnvironmentPropagatedMetadataPrefixesEnvVar is a comma-separated list of label/annotation key prefixes. Labels and
+	// annotations of an Environment that begin with one of these prefixes are propagated to the GitOpsDeploymentManagedEnvironment
+	// (and managed Environment secret) that are generated from the Environment.
+	EnvironmentPropagatedMetadataPrefixesEnvVar = "ENVIRONMENT_PROPAGATED_METADATA_PREFIXES"
+
+	defaultEnvironmentPropagatedMetadataPrefixes = "appstudio.openshift.io/"
 )
 
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch;create;update;patch;delete
@@ -65,6 +105,7 @@ const (
 //+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeploymentmanagedenvironments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete;
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -103,6 +144,15 @@ func (r *EnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 
 		// If the Environment resource no longer exists...
+		metrics.SetEnvironmentWaitingForDTCBinding(req.NamespacedName, false)
+		metrics.SetEnvironmentStale(req.NamespacedName, false)
+
+		// While in read-only mode, the GitOpsDeploymentManagedEnvironment is not deleted: the request is requeued until it is disabled.
+		if readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, rClient); err != nil {
+			return ctrl.Result{}, err
+		} else if readOnlyModeEnabled {
+			return readOnlyModeResult(log), nil
+		}
 
 		gitOpsDeplManagedEnv := generateEmptyManagedEnvironment(environment.Name, environment.Namespace)
 
@@ -119,11 +169,21 @@ func (r *EnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			return ctrl.Result{}, fmt.Errorf("unable to retrieve GitOpsDeploymentManagedEnvironment: %v", err)
 		}
 
+		// A GitOpsDeploymentManagedEnvironment that is owned by another resource was never managed by the Environment,
+		// so it is not deleted.
+		envOwnerRef := generateEnvironmentOwnerReference(*environment)
+		if _, ownershipConflict := adoptManagedEnvironment(gitOpsDeplManagedEnv.DeepCopy(), envOwnerRef); ownershipConflict != "" {
+			log.Info("Not deleting GitOpsDeploymentManagedEnvironment, as it is not owned by the Environment", "reason", ownershipConflict)
+			return ctrl.Result{}, nil
+		}
+
 		// The GitOpsDeploymentManagedEnvironment exists, so delete it....
 		if err := rClient.Delete(ctx, &gitOpsDeplManagedEnv); err != nil {
 
 			if !apierr.IsNotFound(err) {
 				log.Error(err, "Unable to delete GitOpsDeploymentManagedEnvironment")
+				recordWarningEvent(r.Recorder, environment, EventReasonManagedEnvironmentFailed,
+					"Unable to delete GitOpsDeploymentManagedEnvironment %s: %v", gitOpsDeplManagedEnv.Name, err)
 				return ctrl.Result{}, fmt.Errorf("unable to delete GitOpsDeploymentMangedEnvironment resource: %v", err)
 			}
 
@@ -135,12 +195,26 @@ func (r *EnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 		log.Info("The GitOpsDeploymentManagedEnvironment corresponding to the Environment resource has been deleted.")
 
+		// As the Environment no longer exists, this Event is only visible via 'kubectl get events'
+		recordNormalEvent(r.Recorder, environment, EventReasonManagedEnvironmentDeleted,
+			"Deleted GitOpsDeploymentManagedEnvironment %s, as the Environment was deleted", gitOpsDeplManagedEnv.Name)
+
 		return ctrl.Result{}, nil
 
 	}
 
+	// While in read-only mode, no GitOpsDeploymentManagedEnvironment is created or updated: the Environment is requeued
+	// until it is disabled.
+	if readOnlyModeEnabled, err := reconcileReadOnlyMode(ctx, rClient, environment, &environment.Status.Conditions); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to reconcile read-only mode of Environment: %v", err)
+	} else if readOnlyModeEnabled {
+		return readOnlyModeResult(log), nil
+	}
+
 	if environment.GetDeploymentTargetClaimName() != "" && environment.Spec.UnstableConfigurationFields != nil {
 		log.Error(nil, "Environment is invalid since it cannot have both DeploymentTargetClaim and credentials configuration set")
+		recordWarningEvent(r.Recorder, environment, EventReasonInvalidEnvironment,
+			"Environment is invalid since it cannot have both DeploymentTargetClaim and credentials configuration set")
 
 		// Update Status.Conditions field of Environment.
 		if err := updateStatusConditionOfEnvironment(ctx, rClient,
@@ -153,10 +227,51 @@ func (r *EnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
+	// If the GitOpsDeploymentManagedEnvironment of the Environment already exists, but is owned by another resource, then
+	// it must not be modified by the Environment controller: the conflict can only be resolved by the user.
+	if ownershipConflict, err := getManagedEnvironmentOwnershipConflict(ctx, rClient, *environment); err != nil {
+		return ctrl.Result{}, err
+
+	} else if ownershipConflict != "" {
+		message := fmt.Sprintf("GitOpsDeploymentManagedEnvironment %s already exists, but %s: it will not be modified by the Environment",
+			generateEmptyManagedEnvironment(environment.Name, environment.Namespace).Name, ownershipConflict)
+		log.Error(nil, message)
+		recordWarningEvent(r.Recorder, environment, EventReasonManagedEnvironmentOwnershipConflict, message)
+
+		if err := updateStatusConditionOfEnvironment(ctx, rClient, message, environment,
+			EnvironmentConditionErrorOccurred, metav1.ConditionTrue, EnvironmentReasonManagedEnvironmentOwnershipConflict, log); err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to update environment status condition. %v", err)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	// If the Environment has a parent Environment, the configuration that it doesn't override is inherited from its ancestors.
+	effectiveEnvironment, invalidHierarchyMessage, err := resolveInheritedConfiguration(ctx, rClient, *environment)
+	if err != nil {
+		return ctrl.Result{}, err
+
+	} else if invalidHierarchyMessage != "" {
+		log.Error(nil, invalidHierarchyMessage)
+		recordWarningEvent(r.Recorder, environment, EventReasonInvalidParentEnvironment, invalidHierarchyMessage)
+
+		if err := updateStatusConditionOfEnvironment(ctx, rClient, invalidHierarchyMessage, environment,
+			EnvironmentConditionErrorOccurred, metav1.ConditionTrue, EnvironmentReasonInvalidParentEnvironment, log); err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to update environment status condition. %v", err)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
 	// generateDesiredResource will return two types of error:
 	// - semanticErrOccurred_dontContinue = true - a error in user input; this does not require re-reconcilition
 	// - err != nil - any other error which does require reconciliation
-	desiredManagedEnv, semanticErrOccurred_dontContinue, err := generateDesiredResource(ctx, *environment, rClient, log)
+	desiredManagedEnv, semanticErrOccurred_dontContinue, err := generateDesiredResource(ctx, effectiveEnvironment, rClient, r.Recorder, log)
+
+	// An Environment that references a DeploymentTargetClaim is waiting for the claim to be bound, until its
+	// GitOpsDeploymentManagedEnvironment can be generated.
+	metrics.SetEnvironmentWaitingForDTCBinding(req.NamespacedName,
+		environment.GetDeploymentTargetClaimName() != "" && desiredManagedEnv == nil)
 
 	// A serious error occurred
 	if err != nil {
@@ -168,6 +283,9 @@ func (r *EnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	if desiredManagedEnv == nil {
+		// An Environment without a GitOpsDeploymentManagedEnvironment has no cluster to reclaim, so is never stale
+		metrics.SetEnvironmentStale(req.NamespacedName, false)
+
 		// Update Status.Conditions field of Environment as false if error is resolved
 		if err := updateConditionErrorAsResolved(ctx, rClient, "", environment, EnvironmentConditionErrorOccurred, metav1.ConditionFalse, EnvironmentReasonErrorOccurred, log); err != nil {
 			return ctrl.Result{}, fmt.Errorf("unable to 'updateConditionErrorAsResolved': %v", err)
@@ -184,9 +302,17 @@ func (r *EnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 			log.Info("Creating GitOpsDeploymentManagedEnvironment", "managedEnv", desiredManagedEnv.Name)
 			if err := rClient.Create(ctx, desiredManagedEnv); err != nil {
+				recordWarningEvent(r.Recorder, environment, EventReasonManagedEnvironmentFailed,
+					"Unable to create GitOpsDeploymentManagedEnvironment %s: %v", desiredManagedEnv.Name, err)
 				return ctrl.Result{}, fmt.Errorf("unable to create new GitOpsDeploymentManagedEnvironment: %v", err)
 			}
 			logutil.LogAPIResourceChangeEvent(desiredManagedEnv.Namespace, desiredManagedEnv.Name, desiredManagedEnv, logutil.ResourceCreated, log)
+			recordNormalEvent(r.Recorder, environment, EventReasonManagedEnvironmentCreated,
+				"Created GitOpsDeploymentManagedEnvironment %s", desiredManagedEnv.Name)
+
+			if environment.GetDeploymentTargetClaimName() != "" {
+				metrics.ObserveEnvironmentDTCBindingDuration(req.NamespacedName, time.Since(environment.CreationTimestamp.Time))
+			}
 
 			// Success: the resource has been created.
 			return ctrl.Result{}, nil
@@ -198,16 +324,38 @@ func (r *EnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// C) The GitOpsDeploymentManagedEnvironment already exists: verify that it is owned by the Environment (adopting it
+	// if it was created without an owner, for example manually before the Environment was created)
+	adopted, ownershipConflict := adoptManagedEnvironment(&currentManagedEnv, desiredManagedEnv.OwnerReferences[0])
+	if ownershipConflict != "" {
+		// The owner of the GitOpsDeploymentManagedEnvironment changed since it was verified above: requeue to report it.
+		return ctrl.Result{}, fmt.Errorf("GitOpsDeploymentManagedEnvironment '%s' is no longer owned by the Environment: %s",
+			currentManagedEnv.Name, ownershipConflict)
+	}
+
 	// Update Status.Conditions field of Environment as false if error is resolved
 	if err := updateConditionErrorAsResolved(ctx, rClient, "", environment, EnvironmentConditionErrorOccurred, metav1.ConditionFalse, EnvironmentReasonErrorOccurred, log); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// C) The GitOpsDeploymentManagedEnvironment already exists, so compare it with the desired state, and update it if different.
-	if reflect.DeepEqual(currentManagedEnv.Spec, desiredManagedEnv.Spec) {
+	// Detect whether the Environment has had no activity for longer than the staleness threshold. As deployments to the
+	// Environment don't trigger a reconcile, the Environment is requeued to detect when it becomes stale.
+	staleRequeueAfter, err := reconcileEnvironmentStaleness(ctx, rClient, r.Recorder, environment, currentManagedEnv, log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
-		// If the spec field is the same, no more work is needed.
-		return ctrl.Result{}, nil
+	// Compare the GitOpsDeploymentManagedEnvironment with the desired state, and update it if different.
+	metadataPrefixes := getPropagatedMetadataPrefixes()
+
+	var labelsChanged, annotationsChanged bool
+	currentManagedEnv.Labels, labelsChanged = reconcilePropagatedMetadata(currentManagedEnv.Labels, desiredManagedEnv.Labels, metadataPrefixes)
+	currentManagedEnv.Annotations, annotationsChanged = reconcilePropagatedMetadata(currentManagedEnv.Annotations, desiredManagedEnv.Annotations, metadataPrefixes)
+
+	if reflect.DeepEqual(currentManagedEnv.Spec, desiredManagedEnv.Spec) && !labelsChanged && !annotationsChanged && !adopted {
+
+		// If the spec field and propagated metadata are the same, no more work is needed.
+		return ctrl.Result{RequeueAfter: staleRequeueAfter}, nil
 	}
 
 	log.Info("Updating GitOpsDeploymentManagedEnvironment as a change was detected", "managedEnv", desiredManagedEnv.Name)
@@ -216,12 +364,93 @@ func (r *EnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	currentManagedEnv.Spec = desiredManagedEnv.Spec
 
 	if err := rClient.Update(ctx, &currentManagedEnv); err != nil {
+		recordWarningEvent(r.Recorder, environment, EventReasonManagedEnvironmentFailed,
+			"Unable to update GitOpsDeploymentManagedEnvironment %s: %v", currentManagedEnv.Name, err)
 		return ctrl.Result{},
 			fmt.Errorf("unable to update existing GitOpsDeploymentManagedEnvironment '%s': %v", currentManagedEnv.Name, err)
 	}
 	logutil.LogAPIResourceChangeEvent(currentManagedEnv.Namespace, currentManagedEnv.Name, currentManagedEnv, logutil.ResourceModified, log)
+	if adopted {
+		recordNormalEvent(r.Recorder, environment, EventReasonManagedEnvironmentAdopted,
+			"Adopted existing GitOpsDeploymentManagedEnvironment %s, which was not owned by the Environment", currentManagedEnv.Name)
+	} else {
+		recordNormalEvent(r.Recorder, environment, EventReasonManagedEnvironmentUpdated,
+			"Updated GitOpsDeploymentManagedEnvironment %s, as the Environment was changed", currentManagedEnv.Name)
+	}
+
+	return ctrl.Result{RequeueAfter: staleRequeueAfter}, nil
+}
 
-	return ctrl.Result{}, nil
+// generateEnvironmentOwnerReference returns the owner reference that is set on the GitOpsDeploymentManagedEnvironment of
+// the Environment.
+func generateEnvironmentOwnerReference(env appstudioshared.Environment) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: managedgitopsv1alpha1.GroupVersion.Group + "/" + managedgitopsv1alpha1.GroupVersion.Version,
+		Kind:       "Environment",
+		Name:       env.Name,
+		UID:        env.UID,
+	}
+}
+
+// getManagedEnvironmentOwnershipConflict returns a non-empty string describing the conflicting owner, if the
+// GitOpsDeploymentManagedEnvironment of the Environment exists and is owned by another resource (see adoptManagedEnvironment).
+func getManagedEnvironmentOwnershipConflict(ctx context.Context, k8sClient client.Client, env appstudioshared.Environment) (string, error) {
+
+	managedEnv := generateEmptyManagedEnvironment(env.Name, env.Namespace)
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv); err != nil {
+		if apierr.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to retrieve existing GitOpsDeploymentManagedEnvironment '%s': %v", managedEnv.Name, err)
+	}
+
+	_, ownershipConflict := adoptManagedEnvironment(&managedEnv, generateEnvironmentOwnerReference(env))
+
+	return ownershipConflict, nil
+}
+
+// adoptManagedEnvironment verifies that an existing GitOpsDeploymentManagedEnvironment is owned by the Environment
+// described by 'envOwnerRef'. If the GitOpsDeploymentManagedEnvironment is not owned by any Environment (for example, it
+// was created manually), or is owned by a previous Environment of the same name, the owner reference of the Environment
+// is added to it (but not yet applied).
+//
+// Returns:
+// - adopted: true if the owner references of the GitOpsDeploymentManagedEnvironment were modified, false otherwise.
+// - ownershipConflict: if non-empty, the GitOpsDeploymentManagedEnvironment is owned by another resource, and so must
+// not be adopted. The string describes the conflicting owner.
+func adoptManagedEnvironment(managedEnv *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	envOwnerRef metav1.OwnerReference) (adopted bool, ownershipConflict string) {
+
+	ownerRefs := []metav1.OwnerReference{}
+
+	for _, ownerRef := range managedEnv.OwnerReferences {
+
+		if ownerRef.Kind == envOwnerRef.Kind && ownerRef.APIVersion == envOwnerRef.APIVersion {
+
+			if ownerRef.Name != envOwnerRef.Name {
+				return false, fmt.Sprintf("it is owned by Environment %s", ownerRef.Name)
+			}
+
+			if ownerRef.UID == envOwnerRef.UID {
+				// Already owned by the Environment
+				return false, ""
+			}
+
+			// The owner reference is to a previous Environment of the same name (which has since been deleted and
+			// recreated): it is replaced with the owner reference of the current Environment.
+			continue
+		}
+
+		if ownerRef.Controller != nil && *ownerRef.Controller {
+			return false, fmt.Sprintf("it is controlled by %s %s", ownerRef.Kind, ownerRef.Name)
+		}
+
+		ownerRefs = append(ownerRefs, ownerRef)
+	}
+
+	managedEnv.OwnerReferences = append(ownerRefs, envOwnerRef)
+
+	return true, ""
 }
 
 const (
@@ -229,6 +458,10 @@ const (
 	SnapshotEnvironmentBindingReasonErrorOccurred    = "ErrorOccurred"
 	EnvironmentConditionErrorOccurred                = "ErrorOccurred"
 	EnvironmentReasonErrorOccurred                   = "ErrorOccurred"
+
+	// EnvironmentReasonManagedEnvironmentOwnershipConflict is the reason of the ErrorOccurred condition of an Environment
+	// whose GitOpsDeploymentManagedEnvironment already exists, and is owned by another resource.
+	EnvironmentReasonManagedEnvironmentOwnershipConflict = "ManagedEnvironmentOwnershipConflict"
 )
 
 // Update .status.conditions field of Environment
@@ -243,15 +476,13 @@ func updateStatusConditionOfEnvironment(ctx context.Context, client client.Clien
 		Reason:  reason,
 	}
 
-	changed, newConditions := insertOrUpdateConditionsInSlice(newCondition, environment.Status.Conditions)
-
-	if changed {
+	if err := updateStatus(ctx, client, environment, func() bool {
+		changed, newConditions := insertOrUpdateConditionsInSlice(newCondition, environment.Status.Conditions)
 		environment.Status.Conditions = newConditions
-
-		if err := client.Status().Update(ctx, environment); err != nil {
-			log.Error(err, "unable to update environment status condition.")
-			return err
-		}
+		return changed
+	}); err != nil {
+		log.Error(err, "unable to update environment status condition.")
+		return err
 	}
 
 	return nil
@@ -296,9 +527,16 @@ func findCondition(conditions []metav1.Condition, conditionType string) (metav1.
 // generateDesiredResource will return two types of error:
 // - semanticErrOccurred_dontContinue = true - a error in user input; this does not require re-reconcilition
 // - err != nil - any other error which does require reconciliation
-func generateDesiredResource(ctx context.Context, env appstudioshared.Environment, k8sClient client.Client, log logr.Logger) (*managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment, bool, error) {
+//
+// Events describing the errors (and the state of the credentials secret) are emitted for the Environment via recorder.
+func generateDesiredResource(ctx context.Context, env appstudioshared.Environment, k8sClient client.Client, recorder record.EventRecorder,
+	log logr.Logger) (*managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment, bool, error) {
 
 	var manageEnvDetails managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec
+
+	// The additional credentials secrets that are merged with the cluster credentials secret, if any
+	var additionalSecretNames []string
+
 	// If the Environment has a reference to the DeploymentTargetClaim, use the credential secret
 	// from the bounded DeploymentTarget.
 	claimName := env.GetDeploymentTargetClaimName()
@@ -314,6 +552,8 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dtc), dtc); err != nil {
 			if apierr.IsNotFound(err) {
 				log.Error(err, "DeploymentTargetClaim not found while generating the desired Environment resource", "expectedDTC", dtc)
+				recordWarningEvent(recorder, &env, EventReasonDeploymentTargetClaimNotFound,
+					"DeploymentTargetClaim %s referenced by the Environment was not found", dtc.Name)
 
 				// Update Status.Conditions field of Environment.
 				if err := updateStatusConditionOfEnvironment(ctx, k8sClient,
@@ -345,7 +585,11 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 		// If the DeploymentTargetClaim is not in bounded phase, return and wait
 		// until it reaches bounded phase.
 		if dtc.Status.Phase != appstudioshared.DeploymentTargetClaimPhase_Bound {
-			log.Info("Waiting until the DeploymentTargetClaim associated with Environment reaches Bounded phase", "DeploymentTargetClaim", dtc.Name)
+			// The Environment is reconciled again when the DeploymentTargetClaim is bound (see the DeploymentTargetClaim watch)
+			logutil.LogRequeueReason(log, logutil.RequeueReasonWaitingForDTCBound, 0,
+				"Waiting until the DeploymentTargetClaim associated with Environment reaches Bounded phase", "DeploymentTargetClaim", dtc.Name)
+			recordNormalEvent(recorder, &env, EventReasonWaitingForDeploymentTargetClaim,
+				"Waiting for DeploymentTargetClaim %s to be bound to a DeploymentTarget", dtc.Name)
 			return nil, false, nil
 		}
 
@@ -354,6 +598,8 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 		if err != nil {
 			if apierr.IsNotFound(err) {
 				log.Error(err, "DeploymentTarget not found for DeploymentTargetClaim", "DeploymentTargetClaim", dtc.Name)
+				recordWarningEvent(recorder, &env, EventReasonDeploymentTargetNotFound,
+					"DeploymentTarget bound to DeploymentTargetClaim %s was not found", dtc.Name)
 
 				// Update Status.Conditions field of Environment.
 				if err := updateStatusConditionOfEnvironment(ctx, k8sClient,
@@ -379,6 +625,8 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 
 		if dt == nil {
 			log.Error(nil, "DeploymentTarget not found for DeploymentTargetClaim", "DeploymentTargetClaim", dtc.Name)
+			recordWarningEvent(recorder, &env, EventReasonDeploymentTargetNotFound,
+				"DeploymentTarget bound to DeploymentTargetClaim %s was not found", dtc.Name)
 
 			// Update Status.Conditions field of Environment.
 			if err := updateStatusConditionOfEnvironment(ctx, k8sClient,
@@ -401,6 +649,7 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 			ClusterCredentialsSecret:   dt.Spec.KubernetesClusterCredentials.ClusterCredentialsSecret,
 			AllowInsecureSkipTLSVerify: dt.Spec.KubernetesClusterCredentials.AllowInsecureSkipTLSVerify,
 		}
+		additionalSecretNames = getAdditionalCredentialsSecrets(dt.Annotations, manageEnvDetails.ClusterCredentialsSecret)
 
 	} else if env.Spec.UnstableConfigurationFields != nil {
 		log.Info("Using the cluster credentials specified in the Environment")
@@ -409,6 +658,7 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 			ClusterCredentialsSecret:   env.Spec.UnstableConfigurationFields.ClusterCredentialsSecret,
 			AllowInsecureSkipTLSVerify: env.Spec.UnstableConfigurationFields.KubernetesClusterCredentials.AllowInsecureSkipTLSVerify,
 		}
+		additionalSecretNames = getAdditionalCredentialsSecrets(env.Annotations, manageEnvDetails.ClusterCredentialsSecret)
 	} else {
 		// Don't process the Environment configuration fields if they are empty
 		log.Info("Environment neither has cluster credentials nor DeploymentTargetClaim configured")
@@ -423,6 +673,16 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 		manageEnvDetails.Namespaces = append(make([]string, 0, size), env.Spec.UnstableConfigurationFields.Namespaces...)
 	}
 
+	manageEnvDetails.DeletionProtection = env.Annotations[EnvironmentDeletionProtectionAnnotation] == "true"
+	manageEnvDetails.Disconnected = env.Annotations[EnvironmentDisconnectedAnnotation] == "true"
+	manageEnvDetails.NamespaceQuota = getNamespaceQuotaOfEnvironment(env)
+	manageEnvDetails.AllowedRepositories = getAllowedRepositoriesOfEnvironment(env)
+
+	// Labels and annotations of the Environment that should be propagated to the generated resources
+	metadataPrefixes := getPropagatedMetadataPrefixes()
+	propagatedLabels := filterPropagatedMetadata(env.Labels, metadataPrefixes)
+	propagatedAnnotations := filterPropagatedMetadata(env.Annotations, metadataPrefixes)
+
 	// 1) Retrieve the secret that the Environment is pointing to
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -455,6 +715,9 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
 		if apierr.IsNotFound(err) {
 
+			recordWarningEvent(recorder, &env, EventReasonCredentialsSecretNotFound,
+				"The secret %s referenced by the Environment was not found", secret.Name)
+
 			// Update Status.Conditions field of Environment.
 			if err := updateStatusConditionOfEnvironment(ctx, k8sClient,
 				"the secret "+secret.Name+" referenced by the Environment resource was not found", &env,
@@ -472,6 +735,10 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 
 			logutil.LogAPIResourceChangeEvent(managedEnvSecret.Namespace, managedEnvSecret.Name, managedEnvSecret, logutil.ResourceDeleted, log)
 
+			// The Environment is requeued with backoff, and is reconciled again when the Secret is created (see the Secret watch)
+			logutil.LogRequeueReason(log, logutil.RequeueReasonSecretNotFound, 0,
+				"The secret referenced by the Environment resource was not found", "secret", secret.Name)
+
 			return nil, true, fmt.Errorf("the secret '%s' referenced by the Environment resource was not found: %v", secret.Name, err)
 		}
 
@@ -489,28 +756,115 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 
 	// We only want to reconcile managed environment secrets for secrets coming from SpaceRequest.
 	// Skip reconciling if the secret is already of type ManagedEnvironment.
-	if claimName != "" && secret.Type != sharedutil.ManagedEnvironmentSecretType {
+	copySecret := claimName != "" && secret.Type != sharedutil.ManagedEnvironmentSecretType
+	secretData := secret.Data
+	sourceSecretNames := secret.Name
+
+	// If additional credentials secrets are referenced, their data is merged with the secret into the managed
+	// Environment secret (see environment_credentials.go).
+	if len(additionalSecretNames) > 0 {
+		credentialsSecrets := []corev1.Secret{*secret}
+		for _, additionalSecretName := range additionalSecretNames {
+			additionalSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      additionalSecretName,
+					Namespace: env.Namespace,
+				},
+			}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&additionalSecret), &additionalSecret); err != nil {
+				if apierr.IsNotFound(err) {
+					recordWarningEvent(recorder, &env, EventReasonCredentialsSecretNotFound,
+						"The additional credentials secret %s referenced by the Environment was not found", additionalSecret.Name)
+
+					// Update Status.Conditions field of Environment.
+					if err := updateStatusConditionOfEnvironment(ctx, k8sClient,
+						"the additional credentials secret "+additionalSecret.Name+" referenced by the Environment resource was not found", &env,
+						EnvironmentConditionErrorOccurred, metav1.ConditionTrue, EnvironmentReasonErrorOccurred, log); err != nil {
+
+						return nil, true, fmt.Errorf("unable to update environment status condition. %v", err)
+					}
+
+					// The Environment is requeued with backoff, and is reconciled again when the Secret is created (see the Secret watch)
+					logutil.LogRequeueReason(log, logutil.RequeueReasonSecretNotFound, 0,
+						"The additional credentials secret referenced by the Environment resource was not found", "secret", additionalSecret.Name)
+
+					return nil, true, fmt.Errorf("the additional credentials secret '%s' referenced by the Environment resource was not found: %v", additionalSecret.Name, err)
+				}
+				return nil, true, fmt.Errorf("unable to retrieve the additional credentials secret '%s': %v", additionalSecret.Name, err)
+			}
+			credentialsSecrets = append(credentialsSecrets, additionalSecret)
+		}
+
+		sourceSecretNames = strings.Join(append([]string{secret.Name}, additionalSecretNames...), ", ")
+
+		mergedData, err := mergeCredentialsSecrets(credentialsSecrets, manageEnvDetails.APIURL, manageEnvDetails.AllowInsecureSkipTLSVerify)
+		if err != nil {
+			recordWarningEvent(recorder, &env, EventReasonCredentialsSecretCopyFailed,
+				"Unable to merge the credentials of secrets %s: %v", sourceSecretNames, err)
+
+			// Update Status.Conditions field of Environment.
+			if err := updateStatusConditionOfEnvironment(ctx, k8sClient,
+				"Unable to merge the credentials secrets referenced by the Environment resource: "+err.Error(), &env,
+				EnvironmentConditionErrorOccurred, metav1.ConditionTrue, EnvironmentReasonErrorOccurred, log); err != nil {
+
+				return nil, true, fmt.Errorf("unable to update environment status condition. %v", err)
+			}
+
+			// The Environment is reconciled again when the secrets are updated (see the Secret watch)
+			return nil, true, nil
+		}
+
+		copySecret = true
+		secretData = mergedData
+	}
+
+	if copySecret {
 		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvSecret), &managedEnvSecret); err != nil {
 			if !apierr.IsNotFound(err) {
 				return nil, false, fmt.Errorf("failed to fetch the secret %s for managed Environment %s: %v", managedEnvSecret.Name, managedEnv.Name, err)
 			}
 
 			// Create a new managed environment secret if it is not found
-			managedEnvSecret.Data = secret.Data
+			managedEnvSecret.Labels, _ = reconcilePropagatedMetadata(managedEnvSecret.Labels, propagatedLabels,
+				metadataPrefixes, managedEnvironmentSecretLabel)
+			managedEnvSecret.Annotations, _ = reconcilePropagatedMetadata(managedEnvSecret.Annotations, propagatedAnnotations,
+				metadataPrefixes, sharedutil.SecretDataHashAnnotation)
+			managedEnvSecret.Annotations = setSecretDataHashAnnotation(managedEnvSecret.Annotations, secretData)
+			managedEnvSecret.Data = secretData
 			if err := k8sClient.Create(ctx, &managedEnvSecret); err != nil {
+				recordWarningEvent(recorder, &env, EventReasonCredentialsSecretCopyFailed,
+					"Unable to copy the credentials of secret %s to secret %s: %v", sourceSecretNames, managedEnvSecret.Name, err)
 				return nil, false, fmt.Errorf("failed to create a secret for managed Environment %s: %v", managedEnv.Name, err)
 			}
 
 			logutil.LogAPIResourceChangeEvent(managedEnvSecret.Namespace, managedEnvSecret.Name, managedEnvSecret, logutil.ResourceCreated, log)
+			recordNormalEvent(recorder, &env, EventReasonCredentialsSecretCopied,
+				"Copied the credentials of secret %s to secret %s", sourceSecretNames, managedEnvSecret.Name)
 		} else {
-			// The managed Environment secret is found. Compare it with the original secret and update if required.
-			if !reflect.DeepEqual(secret.Data, managedEnvSecret.Data) {
-				managedEnvSecret.Data = secret.Data
+			// The managed Environment secret is found. Compare it with the original secret (and the propagated metadata of
+			// the Environment) and update if required.
+			var labelsChanged, annotationsChanged bool
+			managedEnvSecret.Labels, labelsChanged = reconcilePropagatedMetadata(managedEnvSecret.Labels, propagatedLabels,
+				metadataPrefixes, managedEnvironmentSecretLabel)
+			managedEnvSecret.Annotations, annotationsChanged = reconcilePropagatedMetadata(managedEnvSecret.Annotations, propagatedAnnotations,
+				metadataPrefixes, sharedutil.SecretDataHashAnnotation)
+
+			// The hash annotation identifies the version of the credentials in the secret: when the credentials of the
+			// DeploymentTarget are refreshed, both the data and the hash are updated in the same write.
+			hashChanged := managedEnvSecret.Annotations[sharedutil.SecretDataHashAnnotation] != sharedutil.HashSecretData(secretData)
+
+			if !reflect.DeepEqual(secretData, managedEnvSecret.Data) || hashChanged || labelsChanged || annotationsChanged {
+				managedEnvSecret.Annotations = setSecretDataHashAnnotation(managedEnvSecret.Annotations, secretData)
+				managedEnvSecret.Data = secretData
 				if err := k8sClient.Update(ctx, &managedEnvSecret); err != nil {
+					recordWarningEvent(recorder, &env, EventReasonCredentialsSecretCopyFailed,
+						"Unable to copy the credentials of secret %s to secret %s: %v", sourceSecretNames, managedEnvSecret.Name, err)
 					return nil, false, fmt.Errorf("failed to update the secret for managed Environment %s: %v", managedEnv.Name, err)
 				}
 
 				logutil.LogAPIResourceChangeEvent(managedEnvSecret.Namespace, managedEnvSecret.Name, managedEnvSecret, logutil.ResourceModified, log)
+				recordNormalEvent(recorder, &env, EventReasonCredentialsSecretCopied,
+					"Copied the updated credentials of secret %s to secret %s", sourceSecretNames, managedEnvSecret.Name)
 			}
 		}
 		manageEnvDetails.ClusterCredentialsSecret = managedEnvSecret.Name
@@ -522,19 +876,150 @@ func generateDesiredResource(ctx context.Context, env appstudioshared.Environmen
 	}
 
 	// 2) Generate (but don't apply) the corresponding GitOpsDeploymentManagedEnvironment resource
-	managedEnv.OwnerReferences = []metav1.OwnerReference{
-		{
-			APIVersion: managedgitopsv1alpha1.GroupVersion.Group + "/" + managedgitopsv1alpha1.GroupVersion.Version,
-			Kind:       "Environment",
-			Name:       env.Name,
-			UID:        env.UID,
-		},
+	managedEnv.OwnerReferences = []metav1.OwnerReference{generateEnvironmentOwnerReference(env)}
+	managedEnv.Labels = propagatedLabels
+	if managedEnv.Labels == nil {
+		managedEnv.Labels = map[string]string{}
 	}
+	managedEnv.Labels[managedEnvironmentLabel] = env.Name
+	managedEnv.Annotations = propagatedAnnotations
 	managedEnv.Spec = manageEnvDetails
 
 	return &managedEnv, false, nil
 }
 
+// getNamespaceQuotaOfEnvironment returns the namespace quota hints of the Environment, from its annotations, or nil if
+// there are none. The values are validated by the GitOps Service when the GitOpsDeploymentManagedEnvironment is reconciled.
+func getNamespaceQuotaOfEnvironment(env appstudioshared.Environment) *managedgitopsv1alpha1.ManagedEnvironmentNamespaceQuota {
+
+	cpu := strings.TrimSpace(env.Annotations[EnvironmentNamespaceQuotaCPUAnnotation])
+	memory := strings.TrimSpace(env.Annotations[EnvironmentNamespaceQuotaMemoryAnnotation])
+
+	if cpu == "" && memory == "" {
+		return nil
+	}
+
+	return &managedgitopsv1alpha1.ManagedEnvironmentNamespaceQuota{
+		CPU:    cpu,
+		Memory: memory,
+	}
+}
+
+// getAllowedRepositoriesOfEnvironment returns the Git repositories that may be deployed to the Environment, from its
+// annotation, or nil if all repositories are allowed.
+func getAllowedRepositoriesOfEnvironment(env appstudioshared.Environment) []string {
+
+	var res []string
+	for _, allowedRepository := range strings.Split(env.Annotations[EnvironmentAllowedRepositoriesAnnotation], ",") {
+		if allowedRepository = strings.TrimSpace(allowedRepository); allowedRepository != "" {
+			res = append(res, allowedRepository)
+		}
+	}
+	return res
+}
+
+// getPropagatedMetadataPrefixes returns the label/annotation key prefixes that should be propagated from an Environment
+// to its generated resources, from the ENVIRONMENT_PROPAGATED_METADATA_PREFIXES env var (if set).
+// - If the env var is set to an empty value, no labels/annotations are propagated.
+func getPropagatedMetadataPrefixes() []string {
+
+	value, exists := os.LookupEnv(EnvironmentPropagatedMetadataPrefixesEnvVar)
+	if !exists {
+		value = defaultEnvironmentPropagatedMetadataPrefixes
+	}
+
+	res := []string{}
+	for _, prefix := range strings.Split(value, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			res = append(res, prefix)
+		}
+	}
+	return res
+}
+
+// hasPropagatedMetadataPrefix returns true if the label/annotation key begins with one of the prefixes, false otherwise.
+func hasPropagatedMetadataPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterPropagatedMetadata returns the labels/annotations whose keys begin with one of the prefixes, or nil if there are none.
+func filterPropagatedMetadata(metadata map[string]string, prefixes []string) map[string]string {
+
+	var res map[string]string
+
+	for key, value := range metadata {
+		if key == managedEnvironmentSecretLabel || key == managedEnvironmentLabel || !hasPropagatedMetadataPrefix(key, prefixes) {
+			continue
+		}
+		if res == nil {
+			res = map[string]string{}
+		}
+		res[key] = value
+	}
+
+	return res
+}
+
+// reconcilePropagatedMetadata updates the labels/annotations of a generated resource ('current'), such that the
+// keys which begin with one of the prefixes match 'desired'. Other keys (including 'ignoredKeys') are not modified.
+//
+// Returns the updated labels/annotations, and true if they were changed, false otherwise.
+func reconcilePropagatedMetadata(current map[string]string, desired map[string]string, prefixes []string, ignoredKeys ...string) (map[string]string, bool) {
+
+	isIgnored := func(key string) bool {
+		for _, ignoredKey := range ignoredKeys {
+			if key == ignoredKey {
+				return true
+			}
+		}
+		return false
+	}
+
+	changed := false
+
+	// Remove propagated keys that are no longer present on the Environment
+	for key := range current {
+		if isIgnored(key) || !hasPropagatedMetadataPrefix(key, prefixes) {
+			continue
+		}
+		if _, exists := desired[key]; !exists {
+			delete(current, key)
+			changed = true
+		}
+	}
+
+	// Add/update the propagated keys of the Environment
+	for key, value := range desired {
+		if isIgnored(key) {
+			continue
+		}
+		if existingValue, exists := current[key]; exists && existingValue == value {
+			continue
+		}
+		if current == nil {
+			current = map[string]string{}
+		}
+		current[key] = value
+		changed = true
+	}
+
+	return current, changed
+}
+
+// setSecretDataHashAnnotation sets the hash of the data of the secret (see sharedutil.HashSecretData) on its annotations.
+func setSecretDataHashAnnotation(annotations map[string]string, data map[string][]byte) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[sharedutil.SecretDataHashAnnotation] = sharedutil.HashSecretData(data)
+	return annotations
+}
+
 func generateManagedEnvSecretName(envName string) string {
 	return fmt.Sprintf("managed-environment-secret-%s", envName)
 }
@@ -552,7 +1037,13 @@ func generateEmptyManagedEnvironment(environmentName string, environmentNamespac
 // SetupWithManager sets up the controller with the Manager.
 func (r *EnvironmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		Named(sharedutil.ControllerNameEnvironment).
 		For(&appstudioshared.Environment{}).
+		Watches(
+			&source.Kind{Type: &appstudioshared.Environment{}},
+			handler.EnqueueRequestsFromMapFunc(r.findDescendantsOfEnvironment),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
 		Watches(
 			&source.Kind{Type: &corev1.Secret{}},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSecret),
@@ -573,7 +1064,7 @@ func (r *EnvironmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForGitOpsDeploymentManagedEnvironment),
 			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
 		).
-		Complete(r)
+		Complete(sharedutil.NewRequeueMetricsMiddleware(sharedutil.ControllerNameEnvironment, sharedutil.NewReconcileTimingMiddleware("Environment", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &appstudioshared.Environment{} }, r))))
 }
 
 // findObjectsForGitOpsDeploymentManagedEnvironment maps an incoming GitOpsDeploymentManagedEnvironment event to the
@@ -705,8 +1196,9 @@ func (r *EnvironmentReconciler) findObjectsForSecret(secret client.Object) []rec
 		return []reconcile.Request{}
 	}
 
-	// Filter secrets to avoid unnecessary API calls on them.
-	if secretObj.Type != corev1.SecretTypeOpaque && secretObj.Type != sharedutil.ManagedEnvironmentSecretType {
+	// Filter secrets to avoid unnecessary API calls on them. TLS secrets may be referenced as additional credentials secrets.
+	if secretObj.Type != corev1.SecretTypeOpaque && secretObj.Type != sharedutil.ManagedEnvironmentSecretType &&
+		secretObj.Type != corev1.SecretTypeTLS {
 		return []reconcile.Request{}
 	}
 
@@ -745,6 +1237,18 @@ func (r *EnvironmentReconciler) findObjectsForSecret(secret client.Object) []rec
 	for i := 0; i < len(envList.Items); i++ {
 		env := envList.Items[i]
 
+		// Reconcile for secrets that are merged into the managed Environment secret of an Environment that doesn't use a DTC.
+		if env.Spec.UnstableConfigurationFields != nil {
+			primarySecretName := env.Spec.UnstableConfigurationFields.ClusterCredentialsSecret
+			additionalSecretNames := getAdditionalCredentialsSecrets(env.Annotations, primarySecretName)
+			if len(additionalSecretNames) > 0 && (primarySecretName == secret.GetName() || containsString(additionalSecretNames, secret.GetName())) {
+				envRequests = append(envRequests, reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(&env),
+				})
+				continue
+			}
+		}
+
 		// 1. Find the DTC that is associated with the Environment
 		dtcName := env.GetDeploymentTargetClaimName()
 		if dtcName == "" {
@@ -772,7 +1276,8 @@ func (r *EnvironmentReconciler) findObjectsForSecret(secret client.Object) []rec
 		}
 
 		// 3. We only want to reconcile for secrets that are part of the DT configured for a given Environment.
-		if dt.Spec.KubernetesClusterCredentials.ClusterCredentialsSecret == secret.GetName() {
+		if dt.Spec.KubernetesClusterCredentials.ClusterCredentialsSecret == secret.GetName() ||
+			containsString(getAdditionalCredentialsSecrets(dt.Annotations, ""), secret.GetName()) {
 			envRequests = append(envRequests, reconcile.Request{
 				NamespacedName: client.ObjectKeyFromObject(&env),
 			})
@@ -2,12 +2,16 @@ package appstudioredhatcom
 
 import (
 	"context"
+	"os"
 	"reflect"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
 
+	"github.com/redhat-appstudio/managed-gitops/appstudio-controller/metrics"
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 
@@ -16,6 +20,8 @@ import (
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -29,6 +35,7 @@ var _ = Describe("Environment controller tests", func() {
 	var k8sClient client.Client
 	var reconciler EnvironmentReconciler
 	var apiNamespace corev1.Namespace
+	var recorder *record.FakeRecorder
 
 	Context("Reconcile function call tests", func() {
 
@@ -51,9 +58,12 @@ var _ = Describe("Environment controller tests", func() {
 				WithObjects(namespace, argocdNamespace, kubesystemNamespace).
 				Build()
 
+			recorder = record.NewFakeRecorder(20)
+
 			reconciler = EnvironmentReconciler{
-				Client: k8sClient,
-				Scheme: scheme,
+				Client:   k8sClient,
+				Scheme:   scheme,
+				Recorder: recorder,
 			}
 
 		})
@@ -127,6 +137,9 @@ var _ = Describe("Environment controller tests", func() {
 				"ManagedEnvironment should match the Environment")
 			Expect(managedEnvCR.Spec.ClusterResources).To(Equal(env.Spec.UnstableConfigurationFields.ClusterResources),
 				"ManagedEnvironment should match the Environment")
+
+			By("verifying that an Event was emitted for the created GitOpsDeploymentManagedEnvironment")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Normal " + EventReasonManagedEnvironmentCreated)))
 		}
 
 		It("should create a GitOpsDeploymentManagedEnvironment, if the Environment is created where AllowInsecureSkipTLSVerify field is true", func() {
@@ -336,6 +349,9 @@ var _ = Describe("Environment controller tests", func() {
 			}
 			_, err = reconciler.Reconcile(ctx, req)
 			Expect(err).ToNot(BeNil())
+
+			By("verifying that a Warning Event was emitted for the missing Secret")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning " + EventReasonCredentialsSecretNotFound)))
 		})
 
 		It("should not return an error if the Environment does not container UnstableConfigurationFields", func() {
@@ -533,12 +549,20 @@ var _ = Describe("Environment controller tests", func() {
 			err = k8sClient.Create(ctx, &env)
 			Expect(err).To(BeNil())
 
+			bindingDuration := &dto.Metric{}
+			Expect(metrics.EnvironmentDTCBindingDuration.Write(bindingDuration)).To(Succeed())
+			bindingSampleCount := bindingDuration.GetHistogram().GetSampleCount()
+
 			By("reconcile and verify if a ManagedEnvironment is created with the right credentials")
 			req := newRequest(env.Namespace, env.Name)
 			res, err := reconciler.Reconcile(ctx, req)
 			Expect(err).To(BeNil())
 			Expect(res).To(Equal(reconcile.Result{}))
 
+			By("verify that the time to bind the DTC was recorded")
+			Expect(metrics.EnvironmentDTCBindingDuration.Write(bindingDuration)).To(Succeed())
+			Expect(bindingDuration.GetHistogram().GetSampleCount()).To(Equal(bindingSampleCount + 1))
+
 			By("verify if a new managed-environment secret is created")
 			managedEnvSecret := corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
@@ -554,6 +578,7 @@ var _ = Describe("Environment controller tests", func() {
 			Expect(managedEnvSecret.OwnerReferences[0].Name).To(Equal(env.Name))
 			Expect(managedEnvSecret.OwnerReferences[0].UID).To(Equal(env.UID))
 			Expect(managedEnvSecret.GetLabels()[managedEnvironmentSecretLabel]).To(Equal(env.Name))
+			Expect(managedEnvSecret.GetAnnotations()[sharedutil.SecretDataHashAnnotation]).To(Equal(sharedutil.HashSecretData(clusterSecret.Data)))
 
 			managedEnvCR := generateEmptyManagedEnvironment(env.Name, req.Namespace)
 			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
@@ -578,6 +603,7 @@ var _ = Describe("Environment controller tests", func() {
 			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvSecret), &managedEnvSecret)
 			Expect(err).To(BeNil())
 			Expect(reflect.DeepEqual(managedEnvSecret.Data, clusterSecret.Data)).To(BeTrue())
+			Expect(managedEnvSecret.GetAnnotations()[sharedutil.SecretDataHashAnnotation]).To(Equal(sharedutil.HashSecretData(clusterSecret.Data)))
 
 			By("delete the credential secret and verify if the managed-environment secret is deleted")
 			err = k8sClient.Delete(ctx, &clusterSecret)
@@ -647,6 +673,21 @@ var _ = Describe("Environment controller tests", func() {
 			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
 			Expect(err).ToNot(BeNil())
 			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			By("verify that the Environment is counted as waiting for the DTC to be bound, until it is deleted")
+			metrics.TestOnly_resetEnvironmentsWaitingForDTCBinding()
+			res, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(reconcile.Result{}))
+			Expect(testutil.ToFloat64(metrics.EnvironmentsWaitingForDTCBinding)).To(Equal(float64(1)))
+
+			err = k8sClient.Delete(ctx, &env)
+			Expect(err).To(BeNil())
+
+			res, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(reconcile.Result{}))
+			Expect(testutil.ToFloat64(metrics.EnvironmentsWaitingForDTCBinding)).To(Equal(float64(0)))
 		})
 
 		It("should return an error if the DeploymentTarget is not found", func() {
@@ -885,6 +926,518 @@ var _ = Describe("Environment controller tests", func() {
 			Expect(managedEnvCR.Spec.ClusterResources).To(BeFalse())
 		})
 
+		It("should enable deletion protection on the GitOpsDeploymentManagedEnvironment, if the Environment has the deletion protection annotation", func() {
+			var err error
+
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-my-managed-env-secret",
+					Namespace: apiNamespace.Name,
+				},
+				Type: sharedutil.ManagedEnvironmentSecretType,
+				Data: map[string][]byte{
+					"kubeconfig": ([]byte)("{}"),
+				},
+			}
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			env := appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-env",
+					Namespace: apiNamespace.Name,
+					Annotations: map[string]string{
+						EnvironmentDeletionProtectionAnnotation: "true",
+					},
+				},
+				Spec: appstudioshared.EnvironmentSpec{
+					DisplayName:        "my-environment",
+					DeploymentStrategy: appstudioshared.DeploymentStrategy_Manual,
+					Configuration:      appstudioshared.EnvironmentConfiguration{},
+					UnstableConfigurationFields: &appstudioshared.UnstableEnvironmentConfiguration{
+						KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+							TargetNamespace:          "my-target-namespace",
+							APIURL:                   "https://my-api-url",
+							ClusterCredentialsSecret: secret.Name,
+						},
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, &env)
+			Expect(err).To(BeNil())
+
+			req := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      env.Name,
+					Namespace: env.Namespace,
+				},
+			}
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			managedEnvCR := generateEmptyManagedEnvironment(env.Name, req.Namespace)
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Spec.DeletionProtection).To(BeTrue())
+
+			By("removing the annotation from the Environment, which should disable deletion protection")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&env), &env)
+			Expect(err).To(BeNil())
+			env.Annotations = nil
+			err = k8sClient.Update(ctx, &env)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Spec.DeletionProtection).To(BeFalse())
+		})
+
+		It("should disconnect the GitOpsDeploymentManagedEnvironment, if the Environment has the disconnected annotation", func() {
+			var err error
+
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-my-managed-env-secret",
+					Namespace: apiNamespace.Name,
+				},
+				Type: sharedutil.ManagedEnvironmentSecretType,
+				Data: map[string][]byte{
+					"kubeconfig": ([]byte)("{}"),
+				},
+			}
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			env := appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-env",
+					Namespace: apiNamespace.Name,
+					Annotations: map[string]string{
+						EnvironmentDisconnectedAnnotation: "true",
+					},
+				},
+				Spec: appstudioshared.EnvironmentSpec{
+					DisplayName:        "my-environment",
+					DeploymentStrategy: appstudioshared.DeploymentStrategy_Manual,
+					Configuration:      appstudioshared.EnvironmentConfiguration{},
+					UnstableConfigurationFields: &appstudioshared.UnstableEnvironmentConfiguration{
+						KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+							TargetNamespace:          "my-target-namespace",
+							APIURL:                   "https://my-api-url",
+							ClusterCredentialsSecret: secret.Name,
+						},
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, &env)
+			Expect(err).To(BeNil())
+
+			req := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      env.Name,
+					Namespace: env.Namespace,
+				},
+			}
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			managedEnvCR := generateEmptyManagedEnvironment(env.Name, req.Namespace)
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Spec.Disconnected).To(BeTrue())
+
+			By("removing the annotation from the Environment, which should reconnect the GitOpsDeploymentManagedEnvironment")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&env), &env)
+			Expect(err).To(BeNil())
+			env.Annotations = nil
+			err = k8sClient.Update(ctx, &env)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Spec.Disconnected).To(BeFalse())
+		})
+
+		It("should set the namespace quota of the GitOpsDeploymentManagedEnvironment, from the namespace quota annotations of the Environment", func() {
+			var err error
+
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-my-managed-env-secret",
+					Namespace: apiNamespace.Name,
+				},
+				Type: sharedutil.ManagedEnvironmentSecretType,
+				Data: map[string][]byte{
+					"kubeconfig": ([]byte)("{}"),
+				},
+			}
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			env := appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-env",
+					Namespace: apiNamespace.Name,
+					Annotations: map[string]string{
+						EnvironmentNamespaceQuotaCPUAnnotation:    "2",
+						EnvironmentNamespaceQuotaMemoryAnnotation: "4Gi",
+					},
+				},
+				Spec: appstudioshared.EnvironmentSpec{
+					DisplayName:        "my-environment",
+					DeploymentStrategy: appstudioshared.DeploymentStrategy_Manual,
+					Configuration:      appstudioshared.EnvironmentConfiguration{},
+					UnstableConfigurationFields: &appstudioshared.UnstableEnvironmentConfiguration{
+						KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+							TargetNamespace:          "my-target-namespace",
+							APIURL:                   "https://my-api-url",
+							ClusterCredentialsSecret: secret.Name,
+						},
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, &env)
+			Expect(err).To(BeNil())
+
+			req := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      env.Name,
+					Namespace: env.Namespace,
+				},
+			}
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			managedEnvCR := generateEmptyManagedEnvironment(env.Name, req.Namespace)
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Spec.NamespaceQuota).To(Equal(&managedgitopsv1alpha1.ManagedEnvironmentNamespaceQuota{
+				CPU:    "2",
+				Memory: "4Gi",
+			}))
+
+			By("removing the annotations from the Environment, which should remove the namespace quota")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&env), &env)
+			Expect(err).To(BeNil())
+			env.Annotations = nil
+			err = k8sClient.Update(ctx, &env)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Spec.NamespaceQuota).To(BeNil())
+		})
+
+		It("should set the allowed repositories of the GitOpsDeploymentManagedEnvironment, from the allowed repositories annotation of the Environment", func() {
+			var err error
+
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-my-managed-env-secret",
+					Namespace: apiNamespace.Name,
+				},
+				Type: sharedutil.ManagedEnvironmentSecretType,
+				Data: map[string][]byte{
+					"kubeconfig": ([]byte)("{}"),
+				},
+			}
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			env := appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-env",
+					Namespace: apiNamespace.Name,
+					Annotations: map[string]string{
+						EnvironmentAllowedRepositoriesAnnotation: "https://github.com/my-org/*, https://github.com/other-org/my-repo,",
+					},
+				},
+				Spec: appstudioshared.EnvironmentSpec{
+					DisplayName:        "my-environment",
+					DeploymentStrategy: appstudioshared.DeploymentStrategy_Manual,
+					Configuration:      appstudioshared.EnvironmentConfiguration{},
+					UnstableConfigurationFields: &appstudioshared.UnstableEnvironmentConfiguration{
+						KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+							TargetNamespace:          "my-target-namespace",
+							APIURL:                   "https://my-api-url",
+							ClusterCredentialsSecret: secret.Name,
+						},
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, &env)
+			Expect(err).To(BeNil())
+
+			req := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      env.Name,
+					Namespace: env.Namespace,
+				},
+			}
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			managedEnvCR := generateEmptyManagedEnvironment(env.Name, req.Namespace)
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Spec.AllowedRepositories).To(Equal([]string{"https://github.com/my-org/*", "https://github.com/other-org/my-repo"}))
+
+			By("removing the annotation from the Environment, which should allow all repositories")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&env), &env)
+			Expect(err).To(BeNil())
+			env.Annotations = nil
+			err = k8sClient.Update(ctx, &env)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Spec.AllowedRepositories).To(BeEmpty())
+		})
+
+		It("should propagate the allow-listed labels and annotations of the Environment to the GitOpsDeploymentManagedEnvironment", func() {
+			var err error
+
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-my-managed-env-secret",
+					Namespace: apiNamespace.Name,
+				},
+				Type: sharedutil.ManagedEnvironmentSecretType,
+				Data: map[string][]byte{
+					"kubeconfig": ([]byte)("{}"),
+				},
+			}
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			env := appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-env",
+					Namespace: apiNamespace.Name,
+					Labels: map[string]string{
+						"appstudio.openshift.io/team": "my-team",
+						"some-other-label":            "value",
+					},
+					Annotations: map[string]string{
+						"appstudio.openshift.io/cost-center": "1234",
+						"some-other-annotation":              "value",
+					},
+				},
+				Spec: appstudioshared.EnvironmentSpec{
+					DisplayName:        "my-environment",
+					DeploymentStrategy: appstudioshared.DeploymentStrategy_Manual,
+					Configuration:      appstudioshared.EnvironmentConfiguration{},
+					UnstableConfigurationFields: &appstudioshared.UnstableEnvironmentConfiguration{
+						KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+							TargetNamespace:          "my-target-namespace",
+							APIURL:                   "https://my-api-url",
+							ClusterCredentialsSecret: secret.Name,
+						},
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, &env)
+			Expect(err).To(BeNil())
+
+			req := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      env.Name,
+					Namespace: env.Namespace,
+				},
+			}
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			managedEnvCR := generateEmptyManagedEnvironment(env.Name, req.Namespace)
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Labels).To(Equal(map[string]string{
+				"appstudio.openshift.io/team": "my-team",
+				managedEnvironmentLabel:       env.Name,
+			}))
+			Expect(managedEnvCR.Annotations).To(Equal(map[string]string{"appstudio.openshift.io/cost-center": "1234"}))
+
+			By("adding a label to the GitOpsDeploymentManagedEnvironment that is not managed by the Environment controller")
+			managedEnvCR.Labels["user-label"] = "value"
+			err = k8sClient.Update(ctx, &managedEnvCR)
+			Expect(err).To(BeNil())
+
+			By("updating and removing the propagated labels/annotations of the Environment")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&env), &env)
+			Expect(err).To(BeNil())
+			env.Labels["appstudio.openshift.io/team"] = "my-other-team"
+			delete(env.Annotations, "appstudio.openshift.io/cost-center")
+			err = k8sClient.Update(ctx, &env)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(managedEnvCR.Labels).To(Equal(map[string]string{
+				"appstudio.openshift.io/team": "my-other-team",
+				"user-label":                  "value",
+				managedEnvironmentLabel:       env.Name,
+			}))
+			Expect(managedEnvCR.Annotations).To(BeEmpty())
+		})
+
+		Context("Test adoption of a GitOpsDeploymentManagedEnvironment that already exists", func() {
+
+			var env appstudioshared.Environment
+			var req ctrl.Request
+
+			BeforeEach(func() {
+				secret := corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-my-managed-env-secret",
+						Namespace: apiNamespace.Name,
+					},
+					Type: sharedutil.ManagedEnvironmentSecretType,
+					Data: map[string][]byte{
+						"kubeconfig": ([]byte)("{}"),
+					},
+				}
+				err := k8sClient.Create(ctx, &secret)
+				Expect(err).To(BeNil())
+
+				env = appstudioshared.Environment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-env",
+						Namespace: apiNamespace.Name,
+						UID:       "my-env-uid",
+					},
+					Spec: appstudioshared.EnvironmentSpec{
+						DisplayName:        "my-environment",
+						DeploymentStrategy: appstudioshared.DeploymentStrategy_Manual,
+						Configuration:      appstudioshared.EnvironmentConfiguration{},
+						UnstableConfigurationFields: &appstudioshared.UnstableEnvironmentConfiguration{
+							KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+								TargetNamespace:          "my-target-namespace",
+								APIURL:                   "https://my-api-url",
+								ClusterCredentialsSecret: secret.Name,
+							},
+						},
+					},
+				}
+				err = k8sClient.Create(ctx, &env)
+				Expect(err).To(BeNil())
+
+				req = ctrl.Request{NamespacedName: types.NamespacedName{Name: env.Name, Namespace: env.Namespace}}
+			})
+
+			createExistingManagedEnv := func(ownerRefs []metav1.OwnerReference) managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment {
+				managedEnvCR := generateEmptyManagedEnvironment(env.Name, env.Namespace)
+				managedEnvCR.OwnerReferences = ownerRefs
+				managedEnvCR.Spec = managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec{
+					APIURL:                   "https://my-manually-created-api-url",
+					ClusterCredentialsSecret: "my-manually-created-secret",
+				}
+				err := k8sClient.Create(ctx, &managedEnvCR)
+				Expect(err).To(BeNil())
+				return managedEnvCR
+			}
+
+			It("should adopt a GitOpsDeploymentManagedEnvironment that was created without an owner", func() {
+
+				managedEnvCR := createExistingManagedEnv(nil)
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).To(BeNil())
+
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+				Expect(err).To(BeNil())
+				Expect(managedEnvCR.OwnerReferences).To(Equal([]metav1.OwnerReference{generateEnvironmentOwnerReference(env)}))
+				Expect(managedEnvCR.Labels).To(HaveKeyWithValue(managedEnvironmentLabel, env.Name))
+				Expect(managedEnvCR.Spec.APIURL).To(Equal("https://my-api-url"))
+
+				Expect(recorder.Events).To(Receive(ContainSubstring("Normal " + EventReasonCredentialsSecretCopied)))
+				Expect(recorder.Events).To(Receive(ContainSubstring("Normal " + EventReasonManagedEnvironmentAdopted)))
+
+				By("reconciling again, and verifying the GitOpsDeploymentManagedEnvironment is not adopted again")
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).To(BeNil())
+				Expect(recorder.Events).ToNot(Receive())
+			})
+
+			It("should replace the owner reference of a previous Environment with the same name, and keep other owner references", func() {
+
+				otherOwnerRef := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "my-config-map", UID: "my-config-map-uid"}
+				staleOwnerRef := generateEnvironmentOwnerReference(env)
+				staleOwnerRef.UID = "previous-env-uid"
+
+				managedEnvCR := createExistingManagedEnv([]metav1.OwnerReference{otherOwnerRef, staleOwnerRef})
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).To(BeNil())
+
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+				Expect(err).To(BeNil())
+				Expect(managedEnvCR.OwnerReferences).To(Equal([]metav1.OwnerReference{otherOwnerRef, generateEnvironmentOwnerReference(env)}))
+			})
+
+			DescribeTable("should not modify a GitOpsDeploymentManagedEnvironment that is owned by another resource, and set a condition on the Environment",
+				func(ownerRef metav1.OwnerReference) {
+
+					managedEnvCR := createExistingManagedEnv([]metav1.OwnerReference{ownerRef})
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(BeNil())
+
+					err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+					Expect(err).To(BeNil())
+					Expect(managedEnvCR.OwnerReferences).To(Equal([]metav1.OwnerReference{ownerRef}))
+					Expect(managedEnvCR.Labels).ToNot(HaveKey(managedEnvironmentLabel))
+					Expect(managedEnvCR.Spec.APIURL).To(Equal("https://my-manually-created-api-url"))
+
+					Expect(recorder.Events).To(Receive(ContainSubstring("Warning " + EventReasonManagedEnvironmentOwnershipConflict)))
+
+					err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&env), &env)
+					Expect(err).To(BeNil())
+					cond, present := findCondition(env.Status.Conditions, EnvironmentConditionErrorOccurred)
+					Expect(present).To(BeTrue())
+					Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+					Expect(cond.Reason).To(Equal(EnvironmentReasonManagedEnvironmentOwnershipConflict))
+
+					By("deleting the Environment, and verifying the GitOpsDeploymentManagedEnvironment is not deleted")
+					err = k8sClient.Delete(ctx, &env)
+					Expect(err).To(BeNil())
+
+					_, err = reconciler.Reconcile(ctx, req)
+					Expect(err).To(BeNil())
+
+					err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)
+					Expect(err).To(BeNil())
+				},
+				Entry("owned by another Environment", metav1.OwnerReference{
+					APIVersion: managedgitopsv1alpha1.GroupVersion.Group + "/" + managedgitopsv1alpha1.GroupVersion.Version,
+					Kind:       "Environment",
+					Name:       "my-other-env",
+					UID:        "my-other-env-uid",
+				}),
+				Entry("controlled by another resource", metav1.OwnerReference{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Name:       "my-config-map",
+					UID:        "my-config-map-uid",
+					Controller: &[]bool{true}[0],
+				}),
+			)
+		})
+
 		Context("Test findObjectsForDeploymentTargetClaim function", func() {
 			It("should map requests if matching Environments are found", func() {
 				dtc := appstudioshared.DeploymentTargetClaim{
@@ -1221,6 +1774,116 @@ var _ = Describe("Environment controller tests", func() {
 				Expect(reqs).To(Equal([]reconcile.Request{}))
 			})
 		})
+
+		Context("Test merging of multiple credentials secrets", func() {
+
+			var primarySecret, additionalSecret corev1.Secret
+			var env appstudioshared.Environment
+
+			BeforeEach(func() {
+				primarySecret = corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-token-secret",
+						Namespace: apiNamespace.Name,
+					},
+					Type: corev1.SecretTypeOpaque,
+					Data: map[string][]byte{
+						"token":  []byte("my-token"),
+						"ca.crt": []byte("primary-ca"),
+					},
+				}
+				Expect(k8sClient.Create(ctx, &primarySecret)).To(Succeed())
+
+				additionalSecret = corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-cert-secret",
+						Namespace: apiNamespace.Name,
+					},
+					Type: corev1.SecretTypeTLS,
+					Data: map[string][]byte{
+						"ca.crt":  []byte("additional-ca"),
+						"tls.crt": []byte("my-cert"),
+						"tls.key": []byte("my-key"),
+					},
+				}
+
+				env = appstudioshared.Environment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-env",
+						Namespace: apiNamespace.Name,
+						Annotations: map[string]string{
+							AdditionalCredentialsSecretsAnnotation: additionalSecret.Name,
+						},
+					},
+					Spec: appstudioshared.EnvironmentSpec{
+						DisplayName:        "my-environment",
+						DeploymentStrategy: appstudioshared.DeploymentStrategy_Manual,
+						UnstableConfigurationFields: &appstudioshared.UnstableEnvironmentConfiguration{
+							KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+								TargetNamespace:          "my-target-namespace",
+								APIURL:                   "https://my-api-url",
+								ClusterCredentialsSecret: primarySecret.Name,
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, &env)).To(Succeed())
+			})
+
+			It("should merge the credentials secrets into the managed Environment secret, with the primary secret taking precedence", func() {
+				Expect(k8sClient.Create(ctx, &additionalSecret)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&env)})
+				Expect(err).To(BeNil())
+
+				managedEnvCR := generateEmptyManagedEnvironment(env.Name, env.Namespace)
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR)).To(Succeed())
+				Expect(managedEnvCR.Spec.ClusterCredentialsSecret).To(Equal(generateManagedEnvSecretName(env.Name)))
+
+				managedEnvSecret := corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      managedEnvCR.Spec.ClusterCredentialsSecret,
+						Namespace: env.Namespace,
+					},
+				}
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvSecret), &managedEnvSecret)).To(Succeed())
+				Expect(managedEnvSecret.Type).To(Equal(sharedutil.ManagedEnvironmentSecretType))
+				Expect(string(managedEnvSecret.Data["token"])).To(Equal("my-token"))
+				Expect(string(managedEnvSecret.Data["ca.crt"])).To(Equal("primary-ca"))
+				Expect(string(managedEnvSecret.Data["tls.crt"])).To(Equal("my-cert"))
+
+				By("verifying a kubeconfig was generated from the merged credentials")
+				kubeconfig, err := clientcmd.Load(managedEnvSecret.Data["kubeconfig"])
+				Expect(err).To(BeNil())
+				Expect(kubeconfig.Clusters[kubeconfig.CurrentContext].Server).To(Equal("https://my-api-url"))
+				Expect(kubeconfig.AuthInfos[kubeconfig.CurrentContext].Token).To(Equal("my-token"))
+				Expect(string(kubeconfig.AuthInfos[kubeconfig.CurrentContext].ClientKeyData)).To(Equal("my-key"))
+
+				By("updating the additional secret, and verifying the managed Environment secret is updated")
+				additionalSecret.Data["tls.crt"] = []byte("my-new-cert")
+				Expect(k8sClient.Update(ctx, &additionalSecret)).To(Succeed())
+
+				Expect(reconciler.findObjectsForSecret(&additionalSecret)).To(Equal([]reconcile.Request{
+					{NamespacedName: client.ObjectKeyFromObject(&env)},
+				}))
+
+				_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&env)})
+				Expect(err).To(BeNil())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvSecret), &managedEnvSecret)).To(Succeed())
+				Expect(string(managedEnvSecret.Data["tls.crt"])).To(Equal("my-new-cert"))
+			})
+
+			It("should set an error condition on the Environment if an additional secret doesn't exist", func() {
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&env)})
+				Expect(err).ToNot(BeNil())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&env), &env)).To(Succeed())
+				Expect(env.Status.Conditions).To(HaveLen(1))
+				Expect(env.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+				Expect(env.Status.Conditions[0].Message).To(ContainSubstring(additionalSecret.Name))
+			})
+		})
 	})
 
 	Context("Unit tests of non-reconcile functions", func() {
@@ -1425,4 +2088,44 @@ var _ = Describe("Environment controller tests", func() {
 		)
 
 	})
+
+	Context("Test propagation of Environment labels and annotations", func() {
+
+		It("should read the propagated prefixes from the environment variable, if set", func() {
+			DeferCleanup(os.Unsetenv, EnvironmentPropagatedMetadataPrefixesEnvVar)
+
+			Expect(getPropagatedMetadataPrefixes()).To(Equal([]string{"appstudio.openshift.io/"}))
+
+			Expect(os.Setenv(EnvironmentPropagatedMetadataPrefixesEnvVar, "example.com/, team.example.com/")).To(Succeed())
+			Expect(getPropagatedMetadataPrefixes()).To(Equal([]string{"example.com/", "team.example.com/"}))
+
+			Expect(os.Setenv(EnvironmentPropagatedMetadataPrefixesEnvVar, "")).To(Succeed())
+			Expect(getPropagatedMetadataPrefixes()).To(BeEmpty())
+		})
+
+		DescribeTable("reconcilePropagatedMetadata should only modify the keys with a propagated prefix",
+			func(current map[string]string, desired map[string]string, expected map[string]string, expectedChanged bool) {
+				res, changed := reconcilePropagatedMetadata(current, desired, []string{"appstudio.openshift.io/"}, managedEnvironmentSecretLabel)
+				Expect(changed).To(Equal(expectedChanged))
+				Expect(res).To(Equal(expected))
+			},
+			Entry("nil current and desired", nil, nil, nil, false),
+			Entry("keys are added to nil current",
+				nil,
+				map[string]string{"appstudio.openshift.io/team": "a"},
+				map[string]string{"appstudio.openshift.io/team": "a"}, true),
+			Entry("keys are updated, and keys without a propagated prefix are preserved",
+				map[string]string{"appstudio.openshift.io/team": "a", "other": "b"},
+				map[string]string{"appstudio.openshift.io/team": "c"},
+				map[string]string{"appstudio.openshift.io/team": "c", "other": "b"}, true),
+			Entry("keys no longer in desired are removed, but ignored keys are preserved",
+				map[string]string{"appstudio.openshift.io/team": "a", managedEnvironmentSecretLabel: "env"},
+				nil,
+				map[string]string{managedEnvironmentSecretLabel: "env"}, true),
+			Entry("no change when the propagated keys already match",
+				map[string]string{"appstudio.openshift.io/team": "a", "other": "b"},
+				map[string]string{"appstudio.openshift.io/team": "a"},
+				map[string]string{"appstudio.openshift.io/team": "a", "other": "b"}, false),
+		)
+	})
 })
@@ -0,0 +1,132 @@
+package appstudioredhatcom
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Multiple cluster credentials secrets:
+//
+// Some clusters require credentials that are split across several secrets: for example, a client certificate in one
+// secret, and a token and CA bundle in another. The credentials secret of an Environment (or DeploymentTarget) may be
+// supplemented with additional secrets, by listing them in the AdditionalCredentialsSecretsAnnotation.
+//
+// The data of the secrets is merged into the single managed Environment secret that is generated for the Environment,
+// with the following precedence:
+// - The keys of the primary credentials secret (.clusterCredentialsSecret) take precedence over the additional secrets.
+// - The keys of an additional secret take precedence over the additional secrets that are listed after it.
+// - If none of the secrets contain a 'kubeconfig' key, a kubeconfig is generated from the API URL of the Environment
+//   and the well-known 'token', 'ca.crt', 'tls.crt' and 'tls.key' keys of the merged data.
+
+const (
+	// AdditionalCredentialsSecretsAnnotation is a comma-separated list of the names of secrets, in the namespace of the
+	// Environment, whose credentials are merged with the cluster credentials secret. It is read from the DeploymentTarget
+	// when the Environment uses a DeploymentTargetClaim, and from the Environment otherwise.
+	// #nosec G101
+	AdditionalCredentialsSecretsAnnotation = "appstudio.openshift.io/additional-credentials-secrets"
+
+	credentialsKubeconfigKey = "kubeconfig"
+	credentialsTokenKey      = "token"
+	credentialsCAKey         = "ca.crt"
+	credentialsCertKey       = "tls.crt"
+	credentialsCertKeyKey    = "tls.key"
+
+	// generatedKubeconfigName is the name of the cluster, user and context of a generated kubeconfig
+	generatedKubeconfigName = "managed-environment"
+)
+
+// getAdditionalCredentialsSecrets returns the names of the additional credentials secrets in the given annotations, in
+// order of precedence, or nil if there are none. The name of the primary secret, and duplicates, are ignored.
+func getAdditionalCredentialsSecrets(annotations map[string]string, primarySecretName string) []string {
+
+	var res []string
+	for _, secretName := range strings.Split(annotations[AdditionalCredentialsSecretsAnnotation], ",") {
+		secretName = strings.TrimSpace(secretName)
+		if secretName == "" || secretName == primarySecretName || containsString(res, secretName) {
+			continue
+		}
+		res = append(res, secretName)
+	}
+	return res
+}
+
+// mergeCredentialsSecretData merges the data of the given secrets, which are in order of precedence: if more than one
+// secret contains a key, the value of the first secret is used.
+func mergeCredentialsSecretData(secrets []corev1.Secret) map[string][]byte {
+
+	res := map[string][]byte{}
+	for _, secret := range secrets {
+		for key, value := range secret.Data {
+			if _, exists := res[key]; !exists {
+				res[key] = value
+			}
+		}
+	}
+	return res
+}
+
+// generateKubeconfigFromCredentials generates a kubeconfig for the given API URL, from the token, CA bundle and client
+// certificate in the credentials data. At least a token, or a client certificate and key, is required.
+func generateKubeconfigFromCredentials(apiURL string, allowInsecureSkipTLSVerify bool, data map[string][]byte) ([]byte, error) {
+
+	authInfo := clientcmdapi.NewAuthInfo()
+	authInfo.Token = string(data[credentialsTokenKey])
+	authInfo.ClientCertificateData = data[credentialsCertKey]
+	authInfo.ClientKeyData = data[credentialsCertKeyKey]
+
+	if (len(authInfo.ClientCertificateData) == 0) != (len(authInfo.ClientKeyData) == 0) {
+		return nil, fmt.Errorf("the credentials must contain both '%s' and '%s', or neither", credentialsCertKey, credentialsCertKeyKey)
+	}
+
+	if authInfo.Token == "" && len(authInfo.ClientCertificateData) == 0 {
+		return nil, fmt.Errorf("the credentials must contain either '%s', '%s', or '%s' and '%s'", credentialsKubeconfigKey,
+			credentialsTokenKey, credentialsCertKey, credentialsCertKeyKey)
+	}
+
+	cluster := clientcmdapi.NewCluster()
+	cluster.Server = apiURL
+	cluster.CertificateAuthorityData = data[credentialsCAKey]
+	cluster.InsecureSkipTLSVerify = allowInsecureSkipTLSVerify && len(cluster.CertificateAuthorityData) == 0
+
+	kubeContext := clientcmdapi.NewContext()
+	kubeContext.Cluster = generatedKubeconfigName
+	kubeContext.AuthInfo = generatedKubeconfigName
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[generatedKubeconfigName] = cluster
+	config.AuthInfos[generatedKubeconfigName] = authInfo
+	config.Contexts[generatedKubeconfigName] = kubeContext
+	config.CurrentContext = generatedKubeconfigName
+
+	return clientcmd.Write(*config)
+}
+
+// mergeCredentialsSecrets returns the data of the managed Environment secret, from the given credentials secrets (in
+// order of precedence). If the merged data does not contain a kubeconfig, one is generated for the given API URL.
+func mergeCredentialsSecrets(secrets []corev1.Secret, apiURL string, allowInsecureSkipTLSVerify bool) (map[string][]byte, error) {
+
+	res := mergeCredentialsSecretData(secrets)
+
+	if len(res[credentialsKubeconfigKey]) == 0 {
+		kubeconfig, err := generateKubeconfigFromCredentials(apiURL, allowInsecureSkipTLSVerify, res)
+		if err != nil {
+			return nil, err
+		}
+		res[credentialsKubeconfigKey] = kubeconfig
+	}
+
+	return res, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
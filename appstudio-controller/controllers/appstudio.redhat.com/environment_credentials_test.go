@@ -0,0 +1,73 @@
+package appstudioredhatcom
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var _ = Describe("Environment credentials secrets tests", func() {
+
+	Context("Testing getAdditionalCredentialsSecrets function", func() {
+
+		DescribeTable("should return the additional secrets, in order, without the primary secret or duplicates",
+			func(annotation string, expected []string) {
+				annotations := map[string]string{AdditionalCredentialsSecretsAnnotation: annotation}
+				Expect(getAdditionalCredentialsSecrets(annotations, "primary")).To(Equal(expected))
+			},
+			Entry("no secrets", "", nil),
+			Entry("a single secret", "cert", []string{"cert"}),
+			Entry("multiple secrets, with whitespace", " cert , token,", []string{"cert", "token"}),
+			Entry("the primary secret and duplicates", "primary,cert,cert", []string{"cert"}),
+		)
+	})
+
+	Context("Testing mergeCredentialsSecrets function", func() {
+
+		It("should use the value of the first secret that contains a key", func() {
+			secrets := []corev1.Secret{
+				{Data: map[string][]byte{"kubeconfig": []byte("primary-kubeconfig")}},
+				{Data: map[string][]byte{"kubeconfig": []byte("other-kubeconfig"), "ca.crt": []byte("first-ca")}},
+				{Data: map[string][]byte{"ca.crt": []byte("second-ca")}},
+			}
+
+			data, err := mergeCredentialsSecrets(secrets, "https://my-api-url", false)
+			Expect(err).To(BeNil())
+			Expect(data).To(Equal(map[string][]byte{
+				"kubeconfig": []byte("primary-kubeconfig"),
+				"ca.crt":     []byte("first-ca"),
+			}))
+		})
+
+		It("should generate a kubeconfig from a token and CA bundle, if there is no kubeconfig", func() {
+			secrets := []corev1.Secret{
+				{Data: map[string][]byte{"token": []byte("my-token")}},
+				{Data: map[string][]byte{"ca.crt": []byte("my-ca")}},
+			}
+
+			data, err := mergeCredentialsSecrets(secrets, "https://my-api-url", true)
+			Expect(err).To(BeNil())
+
+			kubeconfig, err := clientcmd.Load(data["kubeconfig"])
+			Expect(err).To(BeNil())
+			cluster := kubeconfig.Clusters[kubeconfig.CurrentContext]
+			Expect(cluster.Server).To(Equal("https://my-api-url"))
+			Expect(string(cluster.CertificateAuthorityData)).To(Equal("my-ca"))
+			Expect(cluster.InsecureSkipTLSVerify).To(BeFalse(), "a CA bundle should be used instead of skipping TLS verification")
+			Expect(kubeconfig.AuthInfos[kubeconfig.CurrentContext].Token).To(Equal("my-token"))
+		})
+
+		It("should return an error if the credentials are incomplete", func() {
+			_, err := mergeCredentialsSecrets([]corev1.Secret{
+				{Data: map[string][]byte{"ca.crt": []byte("my-ca")}},
+			}, "https://my-api-url", false)
+			Expect(err).ToNot(BeNil())
+
+			_, err = mergeCredentialsSecrets([]corev1.Secret{
+				{Data: map[string][]byte{"tls.crt": []byte("my-cert")}},
+			}, "https://my-api-url", false)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,159 @@
+package appstudioredhatcom
+
+import (
+	"context"
+	"fmt"
+
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Environment hierarchy:
+//
+// An Environment may reference a parent Environment (in the same namespace) via .spec.parentEnvironment. The cluster
+// configuration of the Environment is then inherited from its parent (and, transitively, from the ancestors of its
+// parent), unless the Environment overrides it:
+// - The cluster credentials (API URL, credentials secret, and 'allowInsecureSkipTLSVerify') are inherited as a unit,
+//   unless the Environment specifies its own API URL.
+// - The namespaces (and 'clusterResources') are inherited as a unit, unless the Environment specifies its own
+//   namespaces, or enables cluster resources.
+//
+// Each Environment still generates its own GitOpsDeploymentManagedEnvironment, from the merged configuration.
+// Environments that use a DeploymentTargetClaim do not inherit any configuration.
+
+const (
+	// maxEnvironmentHierarchyDepth is the maximum number of ancestors of an Environment.
+	maxEnvironmentHierarchyDepth = 10
+
+	// EnvironmentReasonInvalidParentEnvironment is the reason of the ErrorOccurred condition of an Environment whose
+	// parent Environment does not exist, or whose ancestors form a cycle.
+	EnvironmentReasonInvalidParentEnvironment = "InvalidParentEnvironment"
+)
+
+// resolveInheritedConfiguration returns a copy of the Environment, whose .spec.unstableConfigurationFields are merged
+// with those inherited from its ancestors.
+//
+// Returns a non-empty message if the hierarchy of the Environment is invalid: this is a user error, which reconciling
+// again will not fix (the Environment is reconciled again when one of its ancestors changes).
+func resolveInheritedConfiguration(ctx context.Context, k8sClient client.Client, env appstudioshared.Environment) (appstudioshared.Environment, string, error) {
+
+	if env.Spec.ParentEnvironment == "" || env.GetDeploymentTargetClaimName() != "" {
+		return env, "", nil
+	}
+
+	merged := env.Spec.UnstableConfigurationFields.DeepCopy()
+
+	visited := map[string]bool{env.Name: true}
+
+	for parentName := env.Spec.ParentEnvironment; parentName != ""; {
+
+		if visited[parentName] {
+			return env, fmt.Sprintf("the parent Environments of Environment '%s' form a cycle, via Environment '%s'", env.Name, parentName), nil
+		}
+
+		if len(visited) > maxEnvironmentHierarchyDepth {
+			return env, fmt.Sprintf("Environment '%s' has more than %d ancestor Environments", env.Name, maxEnvironmentHierarchyDepth), nil
+		}
+		visited[parentName] = true
+
+		parent := appstudioshared.Environment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      parentName,
+				Namespace: env.Namespace,
+			},
+		}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&parent), &parent); err != nil {
+			if apierr.IsNotFound(err) {
+				return env, fmt.Sprintf("the parent Environment '%s' of Environment '%s' was not found", parentName, env.Name), nil
+			}
+			return env, "", fmt.Errorf("unable to retrieve parent Environment '%s': %v", parentName, err)
+		}
+
+		merged = mergeEnvironmentConfiguration(merged, parent.Spec.UnstableConfigurationFields)
+
+		parentName = parent.Spec.ParentEnvironment
+	}
+
+	res := *env.DeepCopy()
+	res.Spec.UnstableConfigurationFields = merged
+
+	return res, "", nil
+}
+
+// mergeEnvironmentConfiguration returns the configuration of a child Environment, with the fields that it does not
+// override inherited from the configuration of its parent. Neither parameter is modified.
+func mergeEnvironmentConfiguration(child *appstudioshared.UnstableEnvironmentConfiguration,
+	parent *appstudioshared.UnstableEnvironmentConfiguration) *appstudioshared.UnstableEnvironmentConfiguration {
+
+	if parent == nil {
+		return child.DeepCopy()
+	}
+
+	if child == nil {
+		return parent.DeepCopy()
+	}
+
+	res := child.DeepCopy()
+
+	if res.APIURL == "" {
+		res.APIURL = parent.APIURL
+		res.ClusterCredentialsSecret = parent.ClusterCredentialsSecret
+		res.AllowInsecureSkipTLSVerify = parent.AllowInsecureSkipTLSVerify
+	}
+
+	if len(res.Namespaces) == 0 && !res.ClusterResources {
+		res.Namespaces = append([]string{}, parent.Namespaces...)
+		res.ClusterResources = parent.ClusterResources
+	}
+
+	return res
+}
+
+// findDescendantsOfEnvironment maps an incoming Environment event to the requests of the Environments that inherit
+// their configuration from it (its children, and their descendants).
+func (r *EnvironmentReconciler) findDescendantsOfEnvironment(env client.Object) []reconcile.Request {
+	ctx := context.Background()
+	handlerLog := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops)
+
+	envList := &appstudioshared.EnvironmentList{}
+	if err := r.Client.List(ctx, envList, &client.ListOptions{Namespace: env.GetNamespace()}); err != nil {
+		handlerLog.Error(err, "failed to list Environments in the Environment mapping function")
+		return []reconcile.Request{}
+	}
+
+	childrenOfEnvironment := map[string][]string{}
+	for _, item := range envList.Items {
+		if item.Spec.ParentEnvironment != "" {
+			childrenOfEnvironment[item.Spec.ParentEnvironment] = append(childrenOfEnvironment[item.Spec.ParentEnvironment], item.Name)
+		}
+	}
+
+	envRequests := []reconcile.Request{}
+
+	visited := map[string]bool{env.GetName(): true}
+	queue := []string{env.GetName()}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, child := range childrenOfEnvironment[current] {
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			queue = append(queue, child)
+
+			envRequests = append(envRequests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Namespace: env.GetNamespace(), Name: child},
+			})
+		}
+	}
+
+	return envRequests
+}
@@ -0,0 +1,218 @@
+package appstudioredhatcom
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Environment hierarchy tests", func() {
+
+	Context("Testing inheritance of the configuration of a parent Environment", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+		var reconciler EnvironmentReconciler
+		var namespace string
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				apiNamespace,
+				err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			err = appstudioshared.AddToScheme(scheme)
+			Expect(err).To(BeNil())
+
+			namespace = apiNamespace.Name
+
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "parent-env-secret",
+					Namespace: namespace,
+				},
+				Type: sharedutil.ManagedEnvironmentSecretType,
+				Data: map[string][]byte{
+					"kubeconfig": ([]byte)("{}"),
+				},
+			}
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace, &secret).
+				Build()
+
+			reconciler = EnvironmentReconciler{
+				Client:   k8sClient,
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(20),
+			}
+		})
+
+		newEnvironment := func(name string, parentName string, config *appstudioshared.UnstableEnvironmentConfiguration) *appstudioshared.Environment {
+			env := &appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: appstudioshared.EnvironmentSpec{
+					DisplayName:                 name,
+					DeploymentStrategy:          appstudioshared.DeploymentStrategy_Manual,
+					ParentEnvironment:           parentName,
+					UnstableConfigurationFields: config,
+				},
+			}
+			Expect(k8sClient.Create(ctx, env)).To(Succeed())
+			return env
+		}
+
+		parentConfig := func() *appstudioshared.UnstableEnvironmentConfiguration {
+			return &appstudioshared.UnstableEnvironmentConfiguration{
+				KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+					APIURL:                     "https://parent-api-url",
+					ClusterCredentialsSecret:   "parent-env-secret",
+					AllowInsecureSkipTLSVerify: true,
+					Namespaces:                 []string{"parent-namespace"},
+				},
+			}
+		}
+
+		// reconcileEnvironment reconciles the Environment, and then refreshes it from the client
+		reconcileEnvironment := func(env *appstudioshared.Environment) {
+			_, err := reconciler.Reconcile(ctx, newRequest(env.Namespace, env.Name))
+			Expect(err).To(BeNil())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(env), env)).To(Succeed())
+		}
+
+		It("should generate a GitOpsDeploymentManagedEnvironment for a child Environment from the configuration of its ancestors", func() {
+
+			newEnvironment("grandparent", "", parentConfig())
+			newEnvironment("parent", "grandparent", nil)
+			child := newEnvironment("child", "parent", nil)
+
+			reconcileEnvironment(child)
+
+			managedEnv := generateEmptyManagedEnvironment(child.Name, child.Namespace)
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+			Expect(managedEnv.Spec.APIURL).To(Equal("https://parent-api-url"))
+			Expect(managedEnv.Spec.ClusterCredentialsSecret).To(Equal("parent-env-secret"))
+			Expect(managedEnv.Spec.AllowInsecureSkipTLSVerify).To(BeTrue())
+			Expect(managedEnv.Spec.Namespaces).To(Equal([]string{"parent-namespace"}))
+			Expect(managedEnv.OwnerReferences).To(HaveLen(1))
+			Expect(managedEnv.OwnerReferences[0].Name).To(Equal(child.Name))
+		})
+
+		It("should not inherit the configuration that the child Environment overrides", func() {
+
+			newEnvironment("parent", "", parentConfig())
+			child := newEnvironment("child", "parent", &appstudioshared.UnstableEnvironmentConfiguration{
+				KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+					Namespaces: []string{"child-namespace-1", "child-namespace-2"},
+				},
+			})
+
+			reconcileEnvironment(child)
+
+			managedEnv := generateEmptyManagedEnvironment(child.Name, child.Namespace)
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+			Expect(managedEnv.Spec.APIURL).To(Equal("https://parent-api-url"))
+			Expect(managedEnv.Spec.Namespaces).To(Equal([]string{"child-namespace-1", "child-namespace-2"}))
+		})
+
+		It("should report an error if the parent Environment does not exist", func() {
+
+			child := newEnvironment("child", "parent", nil)
+
+			reconcileEnvironment(child)
+
+			condition := meta.FindStatusCondition(child.Status.Conditions, EnvironmentConditionErrorOccurred)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Reason).To(Equal(EnvironmentReasonInvalidParentEnvironment))
+			Expect(condition.Message).To(ContainSubstring("was not found"))
+		})
+
+		It("should report an error if the parent Environments form a cycle", func() {
+
+			newEnvironment("env-a", "env-b", parentConfig())
+			envB := newEnvironment("env-b", "env-a", nil)
+
+			reconcileEnvironment(envB)
+
+			condition := meta.FindStatusCondition(envB.Status.Conditions, EnvironmentConditionErrorOccurred)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Reason).To(Equal(EnvironmentReasonInvalidParentEnvironment))
+			Expect(condition.Message).To(ContainSubstring("cycle"))
+
+			managedEnv := generateEmptyManagedEnvironment(envB.Name, envB.Namespace)
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).ToNot(Succeed())
+		})
+
+		It("should map an Environment event to the requests of its descendants", func() {
+
+			parent := newEnvironment("parent", "", parentConfig())
+			newEnvironment("child", "parent", nil)
+			newEnvironment("grandchild", "child", nil)
+			newEnvironment("unrelated", "", nil)
+
+			requests := reconciler.findDescendantsOfEnvironment(parent)
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: namespace, Name: "child"}},
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: namespace, Name: "grandchild"}},
+			))
+		})
+	})
+
+	Context("Testing mergeEnvironmentConfiguration", func() {
+
+		It("should inherit the cluster credentials and namespaces as units", func() {
+
+			parent := &appstudioshared.UnstableEnvironmentConfiguration{
+				KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+					APIURL:                     "https://parent-api-url",
+					ClusterCredentialsSecret:   "parent-secret",
+					AllowInsecureSkipTLSVerify: true,
+					Namespaces:                 []string{"parent-namespace"},
+				},
+			}
+
+			child := &appstudioshared.UnstableEnvironmentConfiguration{
+				KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+					APIURL:                   "https://child-api-url",
+					ClusterCredentialsSecret: "child-secret",
+					ClusterResources:         true,
+				},
+			}
+
+			merged := mergeEnvironmentConfiguration(child, parent)
+			Expect(merged.APIURL).To(Equal("https://child-api-url"))
+			Expect(merged.ClusterCredentialsSecret).To(Equal("child-secret"))
+			Expect(merged.AllowInsecureSkipTLSVerify).To(BeFalse())
+			Expect(merged.ClusterResources).To(BeTrue())
+			Expect(merged.Namespaces).To(BeEmpty())
+
+			By("verifying the parameters were not modified")
+			Expect(child.Namespaces).To(BeNil())
+			Expect(parent.APIURL).To(Equal("https://parent-api-url"))
+
+			Expect(mergeEnvironmentConfiguration(nil, parent)).To(Equal(parent))
+			Expect(mergeEnvironmentConfiguration(child, nil)).To(Equal(child))
+		})
+	})
+})
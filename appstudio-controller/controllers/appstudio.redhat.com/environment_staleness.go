@@ -0,0 +1,178 @@
+package appstudioredhatcom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/appstudio-controller/metrics"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Stale Environments:
+//
+// An Environment is stale if its GitOpsDeploymentManagedEnvironment has had no successful deployments, and no successful
+// connection, for longer than the staleness threshold (ENVIRONMENT_STALE_AFTER_DAYS). Sandbox platforms may use the
+// Stale condition of the Environment (or the gitops_environments_stale metric) to reclaim the clusters of unused Environments.
+//
+// The last activity of an Environment is the most recent of:
+// - the creation of the Environment
+// - the time the connection to the cluster of the GitOpsDeploymentManagedEnvironment was last (re)initialized successfully
+// - the time a GitOpsDeployment that targets the GitOpsDeploymentManagedEnvironment last successfully deployed a revision
+//
+// Staleness detection is disabled if ENVIRONMENT_STALE_AFTER_DAYS is not set (or is 0).
+
+const (
+	// EnvironmentStaleAfterDaysEnvVar is the number of days without activity after which an Environment is stale.
+	EnvironmentStaleAfterDaysEnvVar = "ENVIRONMENT_STALE_AFTER_DAYS"
+
+	// EnvironmentConditionStale is True if the Environment has had no activity for longer than the staleness threshold.
+	EnvironmentConditionStale = "Stale"
+
+	EnvironmentReasonNoRecentActivity = "NoRecentActivity"
+	EnvironmentReasonRecentActivity   = "RecentActivity"
+
+	// staleEnvironmentRecheckInterval is how often a stale Environment is checked for new activity: deployments to an
+	// Environment don't trigger a reconcile of it.
+	staleEnvironmentRecheckInterval = 1 * time.Hour
+)
+
+// getEnvironmentStaleAfter returns the duration without activity after which an Environment is stale, or 0 if staleness
+// detection is disabled.
+func getEnvironmentStaleAfter(log logr.Logger) time.Duration {
+
+	value := os.Getenv(EnvironmentStaleAfterDaysEnvVar)
+	if value == "" {
+		return 0
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		log.Error(err, "invalid value of "+EnvironmentStaleAfterDaysEnvVar+": staleness detection is disabled", "value", value)
+		return 0
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// getLastEnvironmentActivity returns the time of the last activity of the Environment: see the description of stale
+// Environments, above.
+func getLastEnvironmentActivity(ctx context.Context, k8sClient client.Client, env appstudioshared.Environment,
+	managedEnv managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) (time.Time, error) {
+
+	lastActivity := env.CreationTimestamp.Time
+
+	for _, condition := range managedEnv.Status.Conditions {
+		if condition.Type == managedgitopsv1alpha1.ManagedEnvironmentStatusConnectionInitializationSucceeded &&
+			condition.Status == metav1.ConditionTrue && condition.LastTransitionTime.After(lastActivity) {
+
+			lastActivity = condition.LastTransitionTime.Time
+		}
+	}
+
+	gitopsDeplList := managedgitopsv1alpha1.GitOpsDeploymentList{}
+	if err := k8sClient.List(ctx, &gitopsDeplList, &client.ListOptions{Namespace: managedEnv.Namespace}); err != nil {
+		return time.Time{}, fmt.Errorf("unable to list GitOpsDeployments of namespace '%s': %v", managedEnv.Namespace, err)
+	}
+
+	for _, gitopsDepl := range gitopsDeplList.Items {
+
+		if gitopsDepl.Spec.Destination.Environment != managedEnv.Name {
+			continue
+		}
+
+		// The history only contains revisions that were successfully deployed
+		for _, entry := range gitopsDepl.Status.History {
+			if entry.DeployedAt.After(lastActivity) {
+				lastActivity = entry.DeployedAt.Time
+			}
+		}
+	}
+
+	return lastActivity, nil
+}
+
+// reconcileEnvironmentStaleness sets the Stale condition of the Environment, based on the last activity of its
+// GitOpsDeploymentManagedEnvironment, and emits an Event when the Environment becomes stale (or active again).
+//
+// Returns the duration after which the Environment should be reconciled again, to detect whether it has become stale
+// (or 0, if staleness detection is disabled).
+func reconcileEnvironmentStaleness(ctx context.Context, k8sClient client.Client, recorder record.EventRecorder,
+	env *appstudioshared.Environment, managedEnv managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment, log logr.Logger) (time.Duration, error) {
+
+	envKey := client.ObjectKeyFromObject(env)
+
+	staleAfter := getEnvironmentStaleAfter(log)
+	if staleAfter == 0 {
+		metrics.SetEnvironmentStale(envKey, false)
+
+		if _, present := findCondition(env.Status.Conditions, EnvironmentConditionStale); present {
+			if err := updateStatus(ctx, k8sClient, env, func() bool {
+				meta.RemoveStatusCondition(&env.Status.Conditions, EnvironmentConditionStale)
+				return true
+			}); err != nil {
+				return 0, fmt.Errorf("unable to remove Stale condition of Environment: %v", err)
+			}
+		}
+		return 0, nil
+	}
+
+	lastActivity, err := getLastEnvironmentActivity(ctx, k8sClient, *env, managedEnv)
+	if err != nil {
+		return 0, err
+	}
+
+	inactiveFor := time.Since(lastActivity)
+	stale := inactiveFor >= staleAfter
+
+	previousCondition, _ := findCondition(env.Status.Conditions, EnvironmentConditionStale)
+	wasStale := previousCondition.Status == metav1.ConditionTrue
+
+	newCondition := metav1.Condition{
+		Type:    EnvironmentConditionStale,
+		Status:  metav1.ConditionFalse,
+		Reason:  EnvironmentReasonRecentActivity,
+		Message: fmt.Sprintf("The Environment has had a successful deployment or connection within the last %d days", int(staleAfter.Hours()/24)),
+	}
+	if stale {
+		newCondition.Status = metav1.ConditionTrue
+		newCondition.Reason = EnvironmentReasonNoRecentActivity
+		newCondition.Message = fmt.Sprintf("The Environment has had no successful deployment or connection since %s",
+			lastActivity.UTC().Format(time.RFC3339))
+	}
+
+	if err := updateStatus(ctx, k8sClient, env, func() bool {
+		changed, newConditions := insertOrUpdateConditionsInSlice(newCondition, env.Status.Conditions)
+		env.Status.Conditions = newConditions
+		return changed
+	}); err != nil {
+		return 0, fmt.Errorf("unable to update Stale condition of Environment: %v", err)
+	}
+
+	metrics.SetEnvironmentStale(envKey, stale)
+
+	if stale && !wasStale {
+		log.Info("Environment is stale", "lastActivity", lastActivity)
+		recordWarningEvent(recorder, env, EventReasonEnvironmentStale, "%s", newCondition.Message)
+
+	} else if !stale && wasStale {
+		log.Info("Environment is no longer stale", "lastActivity", lastActivity)
+		recordNormalEvent(recorder, env, EventReasonEnvironmentActive,
+			"The Environment is no longer stale: it has had a successful deployment or connection since %s", lastActivity.UTC().Format(time.RFC3339))
+	}
+
+	if stale {
+		return staleEnvironmentRecheckInterval, nil
+	}
+
+	// Reconcile again once the Environment would become stale (unless there is new activity in the meantime)
+	return staleAfter - inactiveFor, nil
+}
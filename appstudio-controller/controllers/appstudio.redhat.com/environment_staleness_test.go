@@ -0,0 +1,169 @@
+package appstudioredhatcom
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"github.com/redhat-appstudio/managed-gitops/appstudio-controller/metrics"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Environment staleness tests", func() {
+
+	ctx := context.Background()
+
+	var k8sClient client.Client
+	var recorder *record.FakeRecorder
+	var env *appstudioshared.Environment
+	var managedEnv managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment
+
+	daysAgo := func(days int) metav1.Time {
+		return metav1.NewTime(time.Now().Add(-time.Duration(days) * 24 * time.Hour))
+	}
+
+	BeforeEach(func() {
+		scheme, argocdNamespace, kubesystemNamespace, namespace, err := tests.GenericTestSetup()
+		Expect(err).To(BeNil())
+
+		err = appstudioshared.AddToScheme(scheme)
+		Expect(err).To(BeNil())
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(namespace, argocdNamespace, kubesystemNamespace).
+			Build()
+
+		recorder = record.NewFakeRecorder(20)
+
+		env = &appstudioshared.Environment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "my-env",
+				Namespace:         namespace.Name,
+				CreationTimestamp: daysAgo(60),
+			},
+		}
+		Expect(k8sClient.Create(ctx, env)).To(Succeed())
+
+		managedEnv = generateEmptyManagedEnvironment(env.Name, env.Namespace)
+		managedEnv.Status.Conditions = []metav1.Condition{{
+			Type:               managedgitopsv1alpha1.ManagedEnvironmentStatusConnectionInitializationSucceeded,
+			Status:             metav1.ConditionTrue,
+			Reason:             string(managedgitopsv1alpha1.ConditionReasonSucceeded),
+			LastTransitionTime: daysAgo(45),
+		}}
+
+		metrics.TestOnly_resetStaleEnvironments()
+
+		os.Setenv(EnvironmentStaleAfterDaysEnvVar, "30")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(EnvironmentStaleAfterDaysEnvVar)
+	})
+
+	createGitOpsDeployment := func(name string, destinationEnvironment string, deployedAt metav1.Time) {
+		gitopsDepl := &managedgitopsv1alpha1.GitOpsDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: env.Namespace,
+			},
+			Spec: managedgitopsv1alpha1.GitOpsDeploymentSpec{
+				Destination: managedgitopsv1alpha1.ApplicationDestination{
+					Environment: destinationEnvironment,
+				},
+			},
+			Status: managedgitopsv1alpha1.GitOpsDeploymentStatus{
+				History: []managedgitopsv1alpha1.DeploymentHistoryEntry{{
+					Revision:   "abc123",
+					DeployedAt: deployedAt,
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, gitopsDepl)).To(Succeed())
+	}
+
+	It("should set the Stale condition, and emit an Event, if there has been no activity for longer than the threshold", func() {
+
+		// A deployment to another environment is not activity of this Environment
+		createGitOpsDeployment("other-gitopsdepl", "other-managed-env", daysAgo(1))
+
+		requeueAfter, err := reconcileEnvironmentStaleness(ctx, k8sClient, recorder, env, managedEnv, log.FromContext(ctx))
+		Expect(err).To(BeNil())
+		Expect(requeueAfter).To(Equal(staleEnvironmentRecheckInterval))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(env), env)).To(Succeed())
+		condition := meta.FindStatusCondition(env.Status.Conditions, EnvironmentConditionStale)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(EnvironmentReasonNoRecentActivity))
+
+		Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonEnvironmentStale)))
+		Expect(testutil.ToFloat64(metrics.StaleEnvironments)).To(Equal(float64(1)))
+
+		By("reconciling again, no additional Event should be emitted")
+		_, err = reconcileEnvironmentStaleness(ctx, k8sClient, recorder, env, managedEnv, log.FromContext(ctx))
+		Expect(err).To(BeNil())
+		Expect(recorder.Events).NotTo(Receive())
+
+		By("deploying to the Environment, it should no longer be stale")
+		createGitOpsDeployment("my-gitopsdepl", managedEnv.Name, daysAgo(2))
+
+		requeueAfter, err = reconcileEnvironmentStaleness(ctx, k8sClient, recorder, env, managedEnv, log.FromContext(ctx))
+		Expect(err).To(BeNil())
+		Expect(requeueAfter).To(BeNumerically("~", 28*24*time.Hour, time.Minute),
+			"the Environment should be requeued when it would next become stale")
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(env), env)).To(Succeed())
+		condition = meta.FindStatusCondition(env.Status.Conditions, EnvironmentConditionStale)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(EnvironmentReasonRecentActivity))
+
+		Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonEnvironmentActive)))
+		Expect(testutil.ToFloat64(metrics.StaleEnvironments)).To(Equal(float64(0)))
+	})
+
+	It("should not set the Stale condition if the connection to the cluster was recently initialized", func() {
+
+		managedEnv.Status.Conditions[0].LastTransitionTime = daysAgo(5)
+
+		requeueAfter, err := reconcileEnvironmentStaleness(ctx, k8sClient, recorder, env, managedEnv, log.FromContext(ctx))
+		Expect(err).To(BeNil())
+		Expect(requeueAfter).To(BeNumerically("~", 25*24*time.Hour, time.Minute))
+
+		condition := meta.FindStatusCondition(env.Status.Conditions, EnvironmentConditionStale)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should remove the Stale condition if staleness detection is disabled", func() {
+
+		_, err := reconcileEnvironmentStaleness(ctx, k8sClient, recorder, env, managedEnv, log.FromContext(ctx))
+		Expect(err).To(BeNil())
+		Expect(meta.FindStatusCondition(env.Status.Conditions, EnvironmentConditionStale)).NotTo(BeNil())
+
+		os.Unsetenv(EnvironmentStaleAfterDaysEnvVar)
+
+		requeueAfter, err := reconcileEnvironmentStaleness(ctx, k8sClient, recorder, env, managedEnv, log.FromContext(ctx))
+		Expect(err).To(BeNil())
+		Expect(requeueAfter).To(BeZero())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(env), env)).To(Succeed())
+		Expect(meta.FindStatusCondition(env.Status.Conditions, EnvironmentConditionStale)).To(BeNil())
+		Expect(testutil.ToFloat64(metrics.StaleEnvironments)).To(Equal(float64(0)))
+	})
+})
@@ -0,0 +1,69 @@
+package appstudioredhatcom
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of the Kubernetes Events that are emitted by the Environment, DeploymentTargetClaim and DeploymentTarget
+// controllers. The Events allow users to see what the controllers have done (or are waiting for) via
+// 'kubectl describe', without needing access to the controller logs.
+const (
+	// Environment
+
+	EventReasonInvalidEnvironment                  = "InvalidEnvironment"
+	EventReasonManagedEnvironmentCreated           = "ManagedEnvironmentCreated"
+	EventReasonManagedEnvironmentUpdated           = "ManagedEnvironmentUpdated"
+	EventReasonManagedEnvironmentDeleted           = "ManagedEnvironmentDeleted"
+	EventReasonManagedEnvironmentFailed            = "ManagedEnvironmentFailed"
+	EventReasonManagedEnvironmentAdopted           = "ManagedEnvironmentAdopted"
+	EventReasonManagedEnvironmentOwnershipConflict = "ManagedEnvironmentOwnershipConflict"
+	EventReasonCredentialsSecretNotFound           = "CredentialsSecretNotFound"
+	EventReasonCredentialsSecretCopied             = "CredentialsSecretCopied"
+	EventReasonCredentialsSecretCopyFailed         = "CredentialsSecretCopyFailed"
+	EventReasonDeploymentTargetClaimNotFound       = "DeploymentTargetClaimNotFound"
+	EventReasonWaitingForDeploymentTargetClaim     = "WaitingForDeploymentTargetClaim"
+	EventReasonDeploymentTargetNotFound            = "DeploymentTargetNotFound"
+	EventReasonInvalidParentEnvironment            = "InvalidParentEnvironment"
+	EventReasonEnvironmentStale                    = "EnvironmentStale"
+	EventReasonEnvironmentActive                   = "EnvironmentActive"
+
+	// DeploymentTargetClaim
+
+	EventReasonBound                          = "Bound"
+	EventReasonBindFailed                     = "BindFailed"
+	EventReasonInvalidSelector                = "InvalidSelector"
+	EventReasonWaitingForDeploymentTarget     = "WaitingForDeploymentTarget"
+	EventReasonWaitingForProvisioner          = "WaitingForProvisioner"
+	EventReasonDeploymentTargetAlreadyClaimed = "DeploymentTargetAlreadyClaimed"
+	EventReasonDeploymentTargetMismatch       = "DeploymentTargetMismatch"
+	EventReasonDeletingDeploymentTarget       = "DeletingDeploymentTarget"
+	EventReasonDeploymentTargetReleased       = "DeploymentTargetReleased"
+	EventReasonDeploymentTargetLost           = "DeploymentTargetLost"
+
+	// DeploymentTarget
+
+	EventReasonDeletingSpaceRequest          = "DeletingSpaceRequest"
+	EventReasonSpaceRequestTerminationFailed = "SpaceRequestTerminationFailed"
+)
+
+// recordEvent emits a Kubernetes Event of the given type (corev1.EventTypeNormal or corev1.EventTypeWarning) for the object.
+// - If recorder is nil (for example, in unit tests that do not verify Events), no Event is emitted.
+func recordEvent(recorder record.EventRecorder, object runtime.Object, eventType string, reason string, messageFmt string, args ...interface{}) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
+
+// recordWarningEvent emits a Kubernetes Event of type Warning for the object. See recordEvent.
+func recordWarningEvent(recorder record.EventRecorder, object runtime.Object, reason string, messageFmt string, args ...interface{}) {
+	recordEvent(recorder, object, corev1.EventTypeWarning, reason, messageFmt, args...)
+}
+
+// recordNormalEvent emits a Kubernetes Event of type Normal for the object. See recordEvent.
+func recordNormalEvent(recorder record.EventRecorder, object runtime.Object, reason string, messageFmt string, args ...interface{}) {
+	recordEvent(recorder, object, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
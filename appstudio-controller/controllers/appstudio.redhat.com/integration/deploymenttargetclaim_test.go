@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("DeploymentTargetClaim controller integration tests", func() {
+
+	var ctx context.Context
+	var namespace string
+
+	var dtcls appstudioshared.DeploymentTargetClass
+	var dt appstudioshared.DeploymentTarget
+	var dtc appstudioshared.DeploymentTargetClaim
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = createTestNamespace(ctx)
+
+		secret := createClusterCredentialsSecret(ctx, "my-dt-credentials", namespace)
+
+		// DeploymentTargetClasses are cluster scoped, so each test uses a class of its own
+		dtcls = appstudioshared.DeploymentTargetClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "class-" + namespace,
+			},
+			Spec: appstudioshared.DeploymentTargetClassSpec{
+				Provisioner:   appstudioshared.Provisioner_Devsandbox,
+				ReclaimPolicy: appstudioshared.ReclaimPolicy_Retain,
+			},
+		}
+
+		dt = appstudioshared.DeploymentTarget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-dt",
+				Namespace: namespace,
+			},
+			Spec: appstudioshared.DeploymentTargetSpec{
+				DeploymentTargetClassName: appstudioshared.DeploymentTargetClassName(dtcls.Name),
+				KubernetesClusterCredentials: appstudioshared.DeploymentTargetKubernetesClusterCredentials{
+					APIURL:                   "https://api.my-dt-cluster.com:6443",
+					ClusterCredentialsSecret: secret.Name,
+					DefaultNamespace:         "my-dt-namespace",
+				},
+			},
+		}
+
+		dtc = appstudioshared.DeploymentTargetClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-dtc",
+				Namespace: namespace,
+			},
+			Spec: appstudioshared.DeploymentTargetClaimSpec{
+				DeploymentTargetClassName: appstudioshared.DeploymentTargetClassName(dtcls.Name),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &dtcls))).To(Succeed())
+	})
+
+	// createAndBindDTC creates the DeploymentTargetClass, DeploymentTarget and DeploymentTargetClaim, and verifies
+	// the claim is bound to the target.
+	//
+	// The DeploymentTarget is created before the claim, so that the claim is bound to it, rather than marked for
+	// dynamic provisioning (the provisioner is not run by these tests).
+	createAndBindDTC := func(timeout interface{}) {
+		Expect(k8sClient.Create(ctx, &dtcls)).To(Succeed())
+		Expect(k8sClient.Create(ctx, &dt)).To(Succeed())
+		Expect(k8sClient.Create(ctx, &dtc)).To(Succeed())
+
+		By("verifying the DeploymentTargetClaim is bound to the DeploymentTarget")
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&dtc), &dtc)).To(Succeed())
+			g.Expect(dtc.Spec.TargetName).To(Equal(dt.Name))
+			g.Expect(dtc.Status.Phase).To(Equal(appstudioshared.DeploymentTargetClaimPhase_Bound))
+			g.Expect(dtc.Annotations).To(HaveKeyWithValue(appstudioshared.AnnBindCompleted, appstudioshared.AnnBinderValueTrue))
+			g.Expect(dtc.Finalizers).To(ContainElement(appstudioshared.FinalizerBinder))
+
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&dt), &dt)).To(Succeed())
+			g.Expect(dt.Status.Phase).To(Equal(appstudioshared.DeploymentTargetPhase_Bound))
+		}, timeout, pollingInterval).Should(Succeed())
+	}
+
+	// verifyEnvironmentUsesDT creates an Environment that targets the DeploymentTargetClaim, and verifies its
+	// GitOpsDeploymentManagedEnvironment uses the cluster credentials of the bound DeploymentTarget.
+	verifyEnvironmentUsesDT := func(timeout interface{}) {
+		env := buildEnvironment("staging", namespace)
+		env.Spec.Configuration.Target = appstudioshared.EnvironmentTarget{
+			DeploymentTargetClaim: appstudioshared.DeploymentTargetClaimConfig{
+				ClaimName: dtc.Name,
+			},
+		}
+		Expect(k8sClient.Create(ctx, &env)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			managedEnv, err := getManagedEnvironmentOfEnvironment(ctx, env)
+			g.Expect(err).To(BeNil())
+			g.Expect(managedEnv.Spec.APIURL).To(Equal(dt.Spec.KubernetesClusterCredentials.APIURL))
+			g.Expect(managedEnv.Spec.ClusterCredentialsSecret).To(Equal(dt.Spec.KubernetesClusterCredentials.ClusterCredentialsSecret))
+		}, timeout, pollingInterval).Should(Succeed())
+	}
+
+	It("should bind a DeploymentTargetClaim to a matching DeploymentTarget, and generate a GitOpsDeploymentManagedEnvironment for the Environment that targets it", func() {
+		createAndBindDTC(eventuallyTimeout)
+
+		By("verifying the Environment that targets the DeploymentTargetClaim uses the credentials of the DeploymentTarget")
+		verifyEnvironmentUsesDT(eventuallyTimeout)
+	})
+
+	It("should release the DeploymentTarget when the DeploymentTargetClaim is deleted, if the ReclaimPolicy is Retain", func() {
+		createAndBindDTC(eventuallyTimeout)
+
+		By("deleting the DeploymentTargetClaim")
+		Expect(k8sClient.Delete(ctx, &dtc)).To(Succeed())
+
+		By("verifying the DeploymentTargetClaim is deleted, and the DeploymentTarget is released")
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&dtc), &dtc)
+			return apierr.IsNotFound(err)
+		}, eventuallyTimeout, pollingInterval).Should(BeTrue())
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&dt), &dt)).To(Succeed())
+			g.Expect(dt.Status.Phase).To(Equal(appstudioshared.DeploymentTargetPhase_Released))
+		}, eventuallyTimeout, pollingInterval).Should(Succeed())
+	})
+
+	Context("with failure injection", func() {
+
+		BeforeEach(func() {
+			enableFailureInjection(10)
+		})
+
+		It("should eventually bind the DeploymentTargetClaim, and generate a GitOpsDeploymentManagedEnvironment, when K8s requests fail", func() {
+			createAndBindDTC(eventuallyTimeoutWithFailures)
+
+			// The Environment is only created once the claim is bound: the Environment controller is notified of
+			// changes to the claim via a mapping function, which doesn't retry if its K8s request fails.
+			verifyEnvironmentUsesDT(eventuallyTimeoutWithFailures)
+		})
+	})
+})
@@ -0,0 +1,6 @@
+// Package integration contains the integration tests of the appstudio-controller: the Environment,
+// DeploymentTargetClaim and SnapshotEnvironmentBinding controllers (and the namespace-scoped cache) are run against a
+// local API server (via envtest), so that their interactions can be tested end to end without a cluster.
+//
+// The tests require the envtest binaries: they are run by 'make test', which sets KUBEBUILDER_ASSETS.
+package integration
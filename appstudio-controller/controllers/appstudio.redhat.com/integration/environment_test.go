@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Environment controller integration tests", func() {
+
+	var ctx context.Context
+	var namespace string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = createTestNamespace(ctx)
+	})
+
+	// verifyManagedEnvironmentLifecycle creates an Environment with cluster credentials, and verifies the
+	// GitOpsDeploymentManagedEnvironment of the Environment is created, updated and deleted along with it.
+	verifyManagedEnvironmentLifecycle := func(timeout interface{}) {
+		secret := createClusterCredentialsSecret(ctx, "my-cluster-credentials", namespace)
+
+		env := buildEnvironmentWithCredentials("staging", namespace, "https://api.my-cluster.com:6443", secret.Name)
+		Expect(k8sClient.Create(ctx, &env)).To(Succeed())
+
+		By("verifying the GitOpsDeploymentManagedEnvironment is created from the credentials of the Environment")
+		Eventually(func(g Gomega) {
+			managedEnv, err := getManagedEnvironmentOfEnvironment(ctx, env)
+			g.Expect(err).To(BeNil())
+			g.Expect(managedEnv.Spec.APIURL).To(Equal(env.Spec.UnstableConfigurationFields.APIURL))
+			g.Expect(managedEnv.Spec.ClusterCredentialsSecret).To(Equal(secret.Name))
+
+			g.Expect(managedEnv.OwnerReferences).To(HaveLen(1))
+			g.Expect(managedEnv.OwnerReferences[0].UID).To(Equal(env.UID))
+		}, timeout, pollingInterval).Should(Succeed())
+
+		By("updating the API URL of the Environment")
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&env), &env); err != nil {
+				return err
+			}
+			env.Spec.UnstableConfigurationFields.APIURL = "https://api.my-other-cluster.com:6443"
+			return k8sClient.Update(ctx, &env)
+		}, timeout, pollingInterval).Should(Succeed())
+
+		By("verifying the GitOpsDeploymentManagedEnvironment is updated")
+		Eventually(func(g Gomega) {
+			managedEnv, err := getManagedEnvironmentOfEnvironment(ctx, env)
+			g.Expect(err).To(BeNil())
+			g.Expect(managedEnv.Spec.APIURL).To(Equal("https://api.my-other-cluster.com:6443"))
+		}, timeout, pollingInterval).Should(Succeed())
+
+		By("deleting the Environment, and verifying the GitOpsDeploymentManagedEnvironment is deleted")
+		Expect(k8sClient.Delete(ctx, &env)).To(Succeed())
+
+		Eventually(func() bool {
+			_, err := getManagedEnvironmentOfEnvironment(ctx, env)
+			return apierr.IsNotFound(err)
+		}, timeout, pollingInterval).Should(BeTrue())
+	}
+
+	It("should create, update and delete the GitOpsDeploymentManagedEnvironment of an Environment with cluster credentials", func() {
+		verifyManagedEnvironmentLifecycle(eventuallyTimeout)
+	})
+
+	It("should not create a GitOpsDeploymentManagedEnvironment for an Environment without cluster credentials", func() {
+		env := buildEnvironment("staging", namespace)
+		Expect(k8sClient.Create(ctx, &env)).To(Succeed())
+
+		Consistently(func() bool {
+			_, err := getManagedEnvironmentOfEnvironment(ctx, env)
+			return apierr.IsNotFound(err)
+		}, "5s", pollingInterval).Should(BeTrue())
+	})
+
+	Context("with failure injection", func() {
+
+		BeforeEach(func() {
+			enableFailureInjection(10)
+		})
+
+		It("should eventually create, update and delete the GitOpsDeploymentManagedEnvironment, when K8s requests fail", func() {
+			verifyManagedEnvironmentLifecycle(eventuallyTimeoutWithFailures)
+		})
+	})
+})
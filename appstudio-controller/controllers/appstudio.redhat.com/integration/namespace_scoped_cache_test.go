@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appstudiocontroller "github.com/redhat-appstudio/managed-gitops/appstudio-controller/controllers/appstudio.redhat.com"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Namespace-scoped informer integration tests", func() {
+
+	const watchedNamespaceLabel = "managed-gitops.redhat.com/integration-test-watched"
+
+	var ctx context.Context
+	var mgrClient client.Client
+
+	BeforeEach(func() {
+		var cancelManager context.CancelFunc
+		ctx, cancelManager = context.WithCancel(context.Background())
+		DeferCleanup(cancelManager)
+
+		Expect(os.Setenv(appstudiocontroller.WatchNamespaceLabelSelectorEnvVar, watchedNamespaceLabel+"=true")).To(Succeed())
+		DeferCleanup(func() {
+			Expect(os.Unsetenv(appstudiocontroller.WatchNamespaceLabelSelectorEnvVar)).To(Succeed())
+		})
+
+		newCache, err := appstudiocontroller.GetNamespaceScopedNewCacheFunc(logf.FromContext(ctx))
+		Expect(err).To(BeNil())
+		Expect(newCache).NotTo(BeNil())
+
+		// A separate manager is used, as the manager of the suite watches every namespace
+		mgr, err := ctrl.NewManager(testEnv.Config, ctrl.Options{
+			Scheme:                 k8sClient.Scheme(),
+			NewCache:               newCache,
+			MetricsBindAddress:     "0",
+			HealthProbeBindAddress: "0",
+		})
+		Expect(err).To(BeNil())
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(ctx)).To(Succeed())
+		}()
+		Expect(mgr.GetCache().WaitForCacheSync(ctx)).To(BeTrue())
+
+		mgrClient = mgr.GetClient()
+	})
+
+	// setNamespaceWatched adds or removes the label of the namespace which matches the label selector
+	setNamespaceWatched := func(namespaceName string, watched bool) {
+		Eventually(func() error {
+			namespace := corev1.Namespace{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: namespaceName}, &namespace); err != nil {
+				return err
+			}
+			if watched {
+				if namespace.Labels == nil {
+					namespace.Labels = map[string]string{}
+				}
+				namespace.Labels[watchedNamespaceLabel] = "true"
+			} else {
+				delete(namespace.Labels, watchedNamespaceLabel)
+			}
+			return k8sClient.Update(ctx, &namespace)
+		}, eventuallyTimeout, pollingInterval).Should(Succeed())
+	}
+
+	It("should watch the Secrets of a namespace that is labeled after the manager has started, until the label is removed", func() {
+
+		namespace := createTestNamespace(ctx)
+		secret := createClusterCredentialsSecret(ctx, "my-cluster-credentials", namespace)
+
+		By("verifying the Secret cannot be read, as its namespace does not match the selector")
+		Expect(mgrClient.Get(ctx, client.ObjectKeyFromObject(&secret), &corev1.Secret{})).ToNot(Succeed())
+
+		By("labeling the namespace, after the manager has started")
+		setNamespaceWatched(namespace, true)
+
+		By("verifying the Secret is now read from the cache, without restarting the manager")
+		Eventually(func() error {
+			return mgrClient.Get(ctx, client.ObjectKeyFromObject(&secret), &corev1.Secret{})
+		}, eventuallyTimeout, pollingInterval).Should(Succeed())
+
+		By("verifying a Secret created after the namespace was labeled is also read from the cache")
+		otherSecret := createClusterCredentialsSecret(ctx, "my-other-cluster-credentials", namespace)
+		Eventually(func() error {
+			return mgrClient.Get(ctx, client.ObjectKeyFromObject(&otherSecret), &corev1.Secret{})
+		}, eventuallyTimeout, pollingInterval).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			secretList := corev1.SecretList{}
+			g.Expect(mgrClient.List(ctx, &secretList, client.InNamespace(namespace))).To(Succeed())
+			g.Expect(secretList.Items).To(HaveLen(2))
+		}, eventuallyTimeout, pollingInterval).Should(Succeed())
+
+		By("removing the label of the namespace, and verifying its Secrets are no longer watched")
+		setNamespaceWatched(namespace, false)
+
+		Eventually(func() error {
+			return mgrClient.Get(ctx, client.ObjectKeyFromObject(&secret), &corev1.Secret{})
+		}, eventuallyTimeout, pollingInterval).ShouldNot(Succeed())
+	})
+})
@@ -0,0 +1,200 @@
+package integration
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	appstudiocontroller "github.com/redhat-appstudio/managed-gitops/appstudio-controller/controllers/appstudio.redhat.com"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("SnapshotEnvironmentBinding controller integration tests", func() {
+
+	const (
+		applicationName = "my-app"
+		componentName   = "component-a"
+		gitOpsRepoURL   = "https://github.com/redhat-appstudio/managed-gitops"
+		gitOpsRepoPath  = "resources/test-data/sample-gitops-repository/components/componentA/overlays/staging"
+	)
+
+	var ctx context.Context
+	var namespace string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = createTestNamespace(ctx)
+
+		snapshot := appstudioshared.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-snapshot",
+				Namespace: namespace,
+			},
+			Spec: appstudioshared.SnapshotSpec{
+				Application:        applicationName,
+				DisplayName:        "my-snapshot",
+				DisplayDescription: "my snapshot",
+				Components: []appstudioshared.SnapshotComponent{
+					{Name: componentName, ContainerImage: "quay.io/my-org/component-a:latest"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &snapshot)).To(Succeed())
+	})
+
+	// createBinding creates a SnapshotEnvironmentBinding of the Snapshot to the Environment, and sets the status of its
+	// components, as the Application Service would once the GitOps repository is generated.
+	createBinding := func(envName string, timeout interface{}) appstudioshared.SnapshotEnvironmentBinding {
+		binding := appstudioshared.SnapshotEnvironmentBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-binding",
+				Namespace: namespace,
+				Labels: map[string]string{
+					"appstudio.application": applicationName,
+					"appstudio.environment": envName,
+				},
+			},
+			Spec: appstudioshared.SnapshotEnvironmentBindingSpec{
+				Application: applicationName,
+				Environment: envName,
+				Snapshot:    "my-snapshot",
+				Components: []appstudioshared.BindingComponent{
+					{
+						Name:          componentName,
+						Configuration: appstudioshared.BindingComponentConfiguration{Replicas: 1},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &binding)).To(Succeed())
+
+		// The controller updates the status of the binding too, so retry on conflict
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&binding), &binding); err != nil {
+				return err
+			}
+			binding.Status.Components = []appstudioshared.BindingComponentStatus{
+				{
+					Name: componentName,
+					GitOpsRepository: appstudioshared.BindingComponentGitOpsRepository{
+						URL:                gitOpsRepoURL,
+						Branch:             "main",
+						Path:               gitOpsRepoPath,
+						GeneratedResources: []string{},
+						CommitID:           "fdhyqtw",
+					},
+				},
+			}
+			return k8sClient.Status().Update(ctx, &binding)
+		}, timeout, pollingInterval).Should(Succeed())
+
+		return binding
+	}
+
+	// getGitOpsDeploymentOfBinding returns the GitOpsDeployment generated for the component of the binding
+	getGitOpsDeploymentOfBinding := func(binding appstudioshared.SnapshotEnvironmentBinding) (managedgitopsv1alpha1.GitOpsDeployment, error) {
+		gitopsDeployment := managedgitopsv1alpha1.GitOpsDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      appstudiocontroller.GenerateBindingGitOpsDeploymentName(binding, componentName),
+				Namespace: binding.Namespace,
+			},
+		}
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&gitopsDeployment), &gitopsDeployment)
+		return gitopsDeployment, err
+	}
+
+	// verifyGitOpsDeploymentOfBinding verifies a GitOpsDeployment is generated for the component of the binding, and
+	// is reported in the status of the binding.
+	verifyGitOpsDeploymentOfBinding := func(binding appstudioshared.SnapshotEnvironmentBinding,
+		expectedDestination managedgitopsv1alpha1.ApplicationDestination, timeout interface{}) {
+
+		Eventually(func(g Gomega) {
+			gitopsDeployment, err := getGitOpsDeploymentOfBinding(binding)
+			g.Expect(err).To(BeNil())
+			g.Expect(gitopsDeployment.Spec.Source.RepoURL).To(Equal(gitOpsRepoURL))
+			g.Expect(gitopsDeployment.Spec.Source.Path).To(Equal(gitOpsRepoPath))
+			g.Expect(gitopsDeployment.Spec.Destination).To(Equal(expectedDestination))
+
+			g.Expect(gitopsDeployment.OwnerReferences).To(HaveLen(1))
+			g.Expect(gitopsDeployment.OwnerReferences[0].UID).To(Equal(binding.UID))
+
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&binding), &binding)).To(Succeed())
+			g.Expect(binding.Status.GitOpsDeployments).To(ContainElement(appstudioshared.BindingStatusGitOpsDeployment{
+				ComponentName:    componentName,
+				GitOpsDeployment: gitopsDeployment.Name,
+			}))
+		}, timeout, pollingInterval).Should(Succeed())
+	}
+
+	It("should generate a GitOpsDeployment for each component of a binding to an Environment without cluster credentials", func() {
+		env := buildEnvironment("staging", namespace)
+		Expect(k8sClient.Create(ctx, &env)).To(Succeed())
+
+		binding := createBinding(env.Name, eventuallyTimeout)
+
+		verifyGitOpsDeploymentOfBinding(binding, managedgitopsv1alpha1.ApplicationDestination{}, eventuallyTimeout)
+	})
+
+	It("should only generate the GitOpsDeployments of a binding to an Environment with cluster credentials, once the GitOpsDeploymentManagedEnvironment is connected", func() {
+		secret := createClusterCredentialsSecret(ctx, "my-cluster-credentials", namespace)
+
+		env := buildEnvironmentWithCredentials("staging", namespace, "https://api.my-cluster.com:6443", secret.Name)
+		Expect(k8sClient.Create(ctx, &env)).To(Succeed())
+
+		binding := createBinding(env.Name, eventuallyTimeout)
+
+		By("verifying the binding reports the GitOpsDeploymentManagedEnvironment is not yet connected")
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&binding), &binding)).To(Succeed())
+
+			condition := meta.FindStatusCondition(binding.Status.BindingConditions,
+				appstudiocontroller.SnapshotEnvironmentBindingConditionManagedEnvironmentConnected)
+			g.Expect(condition).ToNot(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			g.Expect(condition.Reason).To(Equal(appstudiocontroller.SnapshotEnvironmentBindingReasonManagedEnvironmentNotConnected))
+		}, eventuallyTimeout, pollingInterval).Should(Succeed())
+
+		_, err := getGitOpsDeploymentOfBinding(binding)
+		Expect(err).ToNot(BeNil())
+
+		By("reporting the GitOpsDeploymentManagedEnvironment as connected, as the backend would")
+		Eventually(func() error {
+			managedEnv, err := getManagedEnvironmentOfEnvironment(ctx, env)
+			if err != nil {
+				return err
+			}
+			meta.SetStatusCondition(&managedEnv.Status.Conditions, metav1.Condition{
+				Type:   managedgitopsv1alpha1.ManagedEnvironmentStatusConnectionInitializationSucceeded,
+				Status: metav1.ConditionTrue,
+				Reason: string(managedgitopsv1alpha1.ConditionReasonSucceeded),
+			})
+			return k8sClient.Status().Update(ctx, &managedEnv)
+		}, eventuallyTimeout, pollingInterval).Should(Succeed())
+
+		By("verifying the GitOpsDeployment targets the GitOpsDeploymentManagedEnvironment of the Environment")
+		verifyGitOpsDeploymentOfBinding(binding, managedgitopsv1alpha1.ApplicationDestination{
+			Environment: "managed-environment-" + env.Name,
+			Namespace:   env.Spec.UnstableConfigurationFields.TargetNamespace,
+		}, eventuallyTimeout)
+	})
+
+	Context("with failure injection", func() {
+
+		BeforeEach(func() {
+			enableFailureInjection(10)
+		})
+
+		It("should eventually generate the GitOpsDeployments of a binding, when K8s requests fail", func() {
+			env := buildEnvironment("staging", namespace)
+			Expect(k8sClient.Create(ctx, &env)).To(Succeed())
+
+			binding := createBinding(env.Name, eventuallyTimeoutWithFailures)
+
+			verifyGitOpsDeploymentOfBinding(binding, managedgitopsv1alpha1.ApplicationDestination{}, eventuallyTimeoutWithFailures)
+		})
+	})
+})
@@ -0,0 +1,218 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	appstudiocontroller "github.com/redhat-appstudio/managed-gitops/appstudio-controller/controllers/appstudio.redhat.com"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+const (
+	// applicationAPIModule contains the CRDs of the AppStudio API (Environment, SnapshotEnvironmentBinding, etc)
+	applicationAPIModule = "github.com/redhat-appstudio/application-api"
+
+	// unreliableClientFailureRateEnvVar is read by the ChaosClient, to decide the % of K8s requests to fail
+	unreliableClientFailureRateEnvVar = "UNRELIABLE_CLIENT_FAILURE_RATE"
+
+	// eventuallyTimeout is the time the controllers are given to react to a change
+	eventuallyTimeout = 30 * time.Second
+
+	// eventuallyTimeoutWithFailures is the time the controllers are given to react to a change, when K8s requests fail
+	eventuallyTimeoutWithFailures = 2 * time.Minute
+
+	pollingInterval = 250 * time.Millisecond
+)
+
+var (
+	k8sClient client.Client
+	testEnv   *envtest.Environment
+	cancel    context.CancelFunc
+)
+
+func TestIntegration(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "appstudio-controller Integration Suite")
+}
+
+var _ = BeforeSuite(func() {
+	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+
+	applicationAPIDir, err := getModuleDir(applicationAPIModule)
+	Expect(err).To(BeNil())
+
+	By("bootstrapping test environment")
+	testEnv = &envtest.Environment{
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "..", "..", "backend-shared", "config", "crd", "bases"),
+			filepath.Join(applicationAPIDir, "config", "crd", "bases"),
+		},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	cfg, err := testEnv.Start()
+	Expect(err).To(BeNil())
+	Expect(cfg).NotTo(BeNil())
+
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(appstudioshared.AddToScheme(scheme)).To(Succeed())
+	Expect(managedgitopsv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     "0",
+		HealthProbeBindAddress: "0",
+	})
+	Expect(err).To(BeNil())
+
+	// The controllers use a ChaosClient, which only fails requests while the failure rate env var is set: see
+	// enableFailureInjection.
+	Expect((&appstudiocontroller.EnvironmentReconciler{
+		Client:   &sharedutil.ChaosClient{InnerClient: mgr.GetClient()},
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("environment-controller"),
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	Expect((&appstudiocontroller.DeploymentTargetClaimReconciler{
+		Client:   &sharedutil.ChaosClient{InnerClient: mgr.GetClient()},
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("deploymenttargetclaim-controller"),
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	Expect((&appstudiocontroller.SnapshotEnvironmentBindingReconciler{
+		Client: &sharedutil.ChaosClient{InnerClient: mgr.GetClient()},
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(context.Background())
+
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(ctx)).To(Succeed())
+	}()
+
+	// The tests use a separate client, which reads directly from the API server, rather than from the cache of the manager
+	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
+	Expect(err).To(BeNil())
+	Expect(k8sClient).NotTo(BeNil())
+})
+
+var _ = AfterSuite(func() {
+	By("tearing down the test environment")
+	if cancel != nil {
+		cancel()
+	}
+	Expect(testEnv.Stop()).To(Succeed())
+})
+
+// getModuleDir returns the directory of the given module in the module cache
+func getModuleDir(modulePath string) (string, error) {
+	// #nosec G204 -- the module path is a constant
+	output, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", modulePath).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// createTestNamespace creates a new namespace for a test, so that the resources of each test are isolated.
+//
+// envtest does not run the namespace controller, so the namespace (and its contents) are never actually deleted.
+func createTestNamespace(ctx context.Context) string {
+	namespace := corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "integration-test-",
+		},
+	}
+	Expect(k8sClient.Create(ctx, &namespace)).To(Succeed())
+	return namespace.Name
+}
+
+// enableFailureInjection causes the given % of the K8s requests of the controllers to fail, until the end of the test
+func enableFailureInjection(failureRate int) {
+	Expect(os.Setenv(unreliableClientFailureRateEnvVar, strconv.Itoa(failureRate))).To(Succeed())
+
+	DeferCleanup(func() {
+		Expect(os.Unsetenv(unreliableClientFailureRateEnvVar)).To(Succeed())
+	})
+}
+
+// createClusterCredentialsSecret creates a secret containing (fake) credentials of a cluster
+func createClusterCredentialsSecret(ctx context.Context, name, namespace string) corev1.Secret {
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: sharedutil.ManagedEnvironmentSecretType,
+		Data: map[string][]byte{
+			"kubeconfig": []byte("fake-kubeconfig"),
+		},
+	}
+	Expect(k8sClient.Create(ctx, &secret)).To(Succeed())
+	return secret
+}
+
+// buildEnvironment returns an Environment that is deployed to the cluster the GitOps Service is running on
+func buildEnvironment(name, namespace string) appstudioshared.Environment {
+	return appstudioshared.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: appstudioshared.EnvironmentSpec{
+			DisplayName:        name,
+			DeploymentStrategy: appstudioshared.DeploymentStrategy_AppStudioAutomated,
+			Tags:               []string{},
+			Configuration: appstudioshared.EnvironmentConfiguration{
+				Env: []appstudioshared.EnvVarPair{},
+			},
+		},
+	}
+}
+
+// buildEnvironmentWithCredentials returns an Environment that is deployed to the cluster of the given credentials
+func buildEnvironmentWithCredentials(name, namespace, apiURL, secretName string) appstudioshared.Environment {
+	env := buildEnvironment(name, namespace)
+	env.Spec.UnstableConfigurationFields = &appstudioshared.UnstableEnvironmentConfiguration{
+		KubernetesClusterCredentials: appstudioshared.KubernetesClusterCredentials{
+			TargetNamespace:          "my-target-namespace",
+			APIURL:                   apiURL,
+			ClusterCredentialsSecret: secretName,
+		},
+	}
+	return env
+}
+
+// getManagedEnvironmentOfEnvironment returns the GitOpsDeploymentManagedEnvironment that is generated for the Environment
+func getManagedEnvironmentOfEnvironment(ctx context.Context, env appstudioshared.Environment) (managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment, error) {
+	managedEnv := managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "managed-environment-" + env.Name,
+			Namespace: env.Namespace,
+		},
+	}
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+	return managedEnv, err
+}
@@ -0,0 +1,686 @@
+package appstudioredhatcom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Namespace-scoped informers:
+//
+// By default, the appstudio-controller watches Secrets, DeploymentTargets and DeploymentTargetClaims in every namespace
+// of the cluster. On large clusters with thousands of namespaces (most of which do not belong to a member-cluster
+// workspace), the cluster-wide Secret informer in particular uses a large amount of memory.
+//
+// If WATCH_NAMESPACE_LABEL_SELECTOR is set to a label selector (for example, 'toolchain.dev.openshift.com/type=tenant'),
+// the informers of these kinds are restricted to the namespaces that match the selector: all other kinds are still
+// watched in every namespace.
+// - Namespaces are watched: a cache is started for a namespace when it is created (or labeled) to match the selector,
+// and stopped when it is deleted (or no longer matches the selector), so no restart is required.
+// - Secrets, DeploymentTargets and DeploymentTargetClaims cannot be read from namespaces that do not match the selector.
+
+const (
+	// WatchNamespaceLabelSelectorEnvVar is the label selector of the namespaces in which Secrets, DeploymentTargets and
+	// DeploymentTargetClaims are watched. If it is not set, they are watched in every namespace.
+	WatchNamespaceLabelSelectorEnvVar = "WATCH_NAMESPACE_LABEL_SELECTOR"
+)
+
+// namespaceScopedKinds returns the objects of the kinds that are only watched in the namespaces that match
+// WATCH_NAMESPACE_LABEL_SELECTOR.
+func namespaceScopedKinds() []client.Object {
+	return []client.Object{
+		&corev1.Secret{},
+		&appstudioshared.DeploymentTarget{},
+		&appstudioshared.DeploymentTargetClaim{},
+	}
+}
+
+// GetNamespaceScopedNewCacheFunc returns the function which the manager should use to create its cache, or nil if
+// WATCH_NAMESPACE_LABEL_SELECTOR is not set (in which case the default cache should be used).
+func GetNamespaceScopedNewCacheFunc(log logr.Logger) (cache.NewCacheFunc, error) {
+
+	value := strings.TrimSpace(os.Getenv(WatchNamespaceLabelSelectorEnvVar))
+	if value == "" {
+		return nil, nil
+	}
+
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of %s: %v", WatchNamespaceLabelSelectorEnvVar, err)
+	}
+
+	log.Info("Secrets, DeploymentTargets and DeploymentTargetClaims are only watched in the namespaces matching "+WatchNamespaceLabelSelectorEnvVar,
+		"selector", selector.String())
+
+	return newNamespaceScopedCacheFunc(selector, log), nil
+}
+
+// newNamespaceScopedCacheFunc returns a cache.NewCacheFunc for a cache that watches the namespace-scoped kinds only in
+// the namespaces that match the selector, and all other kinds in every namespace.
+func newNamespaceScopedCacheFunc(selector labels.Selector, log logr.Logger) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+
+		scopedGVKs := map[schema.GroupVersionKind]bool{}
+		for _, obj := range namespaceScopedKinds() {
+			gvk, err := apiutil.GVKForObject(obj, opts.Scheme)
+			if err != nil {
+				return nil, err
+			}
+			scopedGVKs[gvk] = true
+		}
+
+		// The namespace-scoped kinds are never requested from the cluster-wide cache, and so are never watched cluster-wide
+		clusterWideCache, err := cache.New(config, opts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create cluster-wide cache: %v", err)
+		}
+
+		newNamespaceCache := func(namespace string) (cache.Cache, error) {
+			namespaceOpts := opts
+			namespaceOpts.Namespace = namespace
+			return cache.New(config, namespaceOpts)
+		}
+
+		namespacedCache, err := newDynamicNamespacedCache(clusterWideCache, selector, opts.Scheme, newNamespaceCache, log)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create namespace-scoped cache: %v", err)
+		}
+
+		return &namespaceScopedCache{
+			Cache:           clusterWideCache,
+			namespacedCache: namespacedCache,
+			scheme:          opts.Scheme,
+			scopedGVKs:      scopedGVKs,
+		}, nil
+	}
+}
+
+// namespaceScopedCache is a cache.Cache which delegates the namespace-scoped kinds to a cache of the watched namespaces,
+// and all other kinds to a cluster-wide cache.
+type namespaceScopedCache struct {
+	// Cache is the cluster-wide cache, used for all kinds other than the namespace-scoped kinds
+	cache.Cache
+
+	// namespacedCache only contains the objects of the watched namespaces (see dynamicNamespacedCache)
+	namespacedCache cache.Cache
+
+	scheme     *runtime.Scheme
+	scopedGVKs map[schema.GroupVersionKind]bool
+}
+
+var _ cache.Cache = &namespaceScopedCache{}
+
+// cacheForGVK returns the cache which contains the objects of the given kind.
+func (c *namespaceScopedCache) cacheForGVK(gvk schema.GroupVersionKind) cache.Cache {
+	// Lists are stored in the same cache as the kind of their items
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	if c.scopedGVKs[gvk] {
+		return c.namespacedCache
+	}
+	return c.Cache
+}
+
+// cacheForObject returns the cache which contains the objects of the kind of the given object (or list).
+func (c *namespaceScopedCache) cacheForObject(obj runtime.Object) (cache.Cache, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return c.cacheForGVK(gvk), nil
+}
+
+func (c *namespaceScopedCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	objCache, err := c.cacheForObject(obj)
+	if err != nil {
+		return err
+	}
+	return objCache.Get(ctx, key, obj, opts...)
+}
+
+func (c *namespaceScopedCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listCache, err := c.cacheForObject(list)
+	if err != nil {
+		return err
+	}
+	return listCache.List(ctx, list, opts...)
+}
+
+func (c *namespaceScopedCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	objCache, err := c.cacheForObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	return objCache.GetInformer(ctx, obj)
+}
+
+func (c *namespaceScopedCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	return c.cacheForGVK(gvk).GetInformerForKind(ctx, gvk)
+}
+
+func (c *namespaceScopedCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	objCache, err := c.cacheForObject(obj)
+	if err != nil {
+		return err
+	}
+	return objCache.IndexField(ctx, obj, field, extractValue)
+}
+
+// Start runs both caches until the context is closed, or until either of them fails.
+func (c *namespaceScopedCache) Start(ctx context.Context) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, 2)
+	go func() { errCh <- c.Cache.Start(ctx) }()
+	go func() { errCh <- c.namespacedCache.Start(ctx) }()
+
+	// Once either cache stops, stop the other, and return the first error
+	err := <-errCh
+	cancel()
+	if secondErr := <-errCh; err == nil {
+		err = secondErr
+	}
+
+	return err
+}
+
+func (c *namespaceScopedCache) WaitForCacheSync(ctx context.Context) bool {
+	return c.Cache.WaitForCacheSync(ctx) && c.namespacedCache.WaitForCacheSync(ctx)
+}
+
+// dynamicNamespacedCache is a cache.Cache of the namespace-scoped kinds, which contains a cache for each namespace that
+// matches the label selector. Namespaces are watched via the cluster-wide cache, so that the cache of a namespace is
+// started when the namespace is created (or labeled), and stopped when it is deleted (or unlabeled).
+type dynamicNamespacedCache struct {
+	selector labels.Selector
+	scheme   *runtime.Scheme
+	log      logr.Logger
+
+	// clusterWideCache is used to watch and list namespaces
+	clusterWideCache cache.Cache
+
+	// namespaceInformer is the Namespace informer of the cluster-wide cache
+	namespaceInformer cache.Informer
+
+	// newNamespaceCache creates the (not yet started) cache of a single namespace
+	newNamespaceCache func(namespace string) (cache.Cache, error)
+
+	// mutex protects the fields below
+	mutex sync.Mutex
+
+	// ctx is the context that the cache was started with: it is nil until Start is called
+	ctx context.Context
+
+	// namespaceCaches contains the cache of each namespace matching the selector, by namespace name
+	namespaceCaches map[string]*namespaceCacheEntry
+
+	// informers contains the informers that have been requested, by GVK: these are added to the cache of each
+	// namespace, when it is created
+	informers map[schema.GroupVersionKind]*dynamicNamespacedInformer
+
+	// fieldIndexes contains the field indexes that have been requested: these are added to the cache of each namespace,
+	// when it is created
+	fieldIndexes []fieldIndex
+}
+
+var _ cache.Cache = &dynamicNamespacedCache{}
+
+// namespaceCacheEntry is the cache of a single namespace.
+type namespaceCacheEntry struct {
+	cache.Cache
+
+	// cancel stops the cache: it is nil until the cache is started
+	cancel context.CancelFunc
+}
+
+// fieldIndex contains the parameters of a call to IndexField.
+type fieldIndex struct {
+	obj          client.Object
+	field        string
+	extractValue client.IndexerFunc
+}
+
+// newDynamicNamespacedCache returns a dynamicNamespacedCache, which watches namespaces via the (not yet started)
+// cluster-wide cache.
+func newDynamicNamespacedCache(clusterWideCache cache.Cache, selector labels.Selector, scheme *runtime.Scheme,
+	newNamespaceCache func(namespace string) (cache.Cache, error), log logr.Logger) (*dynamicNamespacedCache, error) {
+
+	res := &dynamicNamespacedCache{
+		selector:          selector,
+		scheme:            scheme,
+		log:               log,
+		clusterWideCache:  clusterWideCache,
+		newNamespaceCache: newNamespaceCache,
+		namespaceCaches:   map[string]*namespaceCacheEntry{},
+		informers:         map[schema.GroupVersionKind]*dynamicNamespacedInformer{},
+	}
+
+	// The cluster-wide cache has not been started, so this does not block
+	namespaceInformer, err := clusterWideCache.GetInformer(context.Background(), &corev1.Namespace{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get namespace informer: %v", err)
+	}
+	namespaceInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			res.handleNamespaceEvent(obj, false)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			res.handleNamespaceEvent(newObj, false)
+		},
+		DeleteFunc: func(obj interface{}) {
+			res.handleNamespaceEvent(obj, true)
+		},
+	})
+	res.namespaceInformer = namespaceInformer
+
+	return res, nil
+}
+
+// handleNamespaceEvent adds the cache of the namespace, if it matches the selector, or otherwise removes it.
+func (c *dynamicNamespacedCache) handleNamespaceEvent(obj interface{}, deleted bool) {
+
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	namespace, ok := obj.(client.Object)
+	if !ok {
+		c.log.Error(nil, "SEVERE: unexpected object in namespace event", "object", fmt.Sprintf("%T", obj))
+		return
+	}
+
+	if deleted || !c.selector.Matches(labels.Set(namespace.GetLabels())) {
+		c.removeNamespace(namespace.GetName())
+		return
+	}
+
+	if err := c.addNamespace(namespace.GetName()); err != nil {
+		c.log.Error(err, "unable to watch namespace", "namespace", namespace.GetName())
+	}
+}
+
+// addNamespace creates the cache of the namespace (if it doesn't already exist), with the informers and field indexes
+// that have been requested so far, and starts it (if this cache has been started).
+func (c *dynamicNamespacedCache) addNamespace(namespace string) error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.namespaceCaches[namespace]; exists {
+		return nil
+	}
+
+	namespaceCache, err := c.newNamespaceCache(namespace)
+	if err != nil {
+		return fmt.Errorf("unable to create cache of namespace '%s': %v", namespace, err)
+	}
+
+	// The cache of the namespace has not been started, so none of these block
+	ctx := context.Background()
+
+	for _, index := range c.fieldIndexes {
+		if err := namespaceCache.IndexField(ctx, index.obj, index.field, index.extractValue); err != nil {
+			return fmt.Errorf("unable to add index of field '%s' to cache of namespace '%s': %v", index.field, namespace, err)
+		}
+	}
+
+	for gvk, informer := range c.informers {
+		namespaceInformer, err := namespaceCache.GetInformerForKind(ctx, gvk)
+		if err != nil {
+			return fmt.Errorf("unable to get informer of %s in namespace '%s': %v", gvk.Kind, namespace, err)
+		}
+		if err := informer.addNamespaceInformer(namespace, namespaceInformer); err != nil {
+			return fmt.Errorf("unable to configure informer of %s in namespace '%s': %v", gvk.Kind, namespace, err)
+		}
+	}
+
+	entry := &namespaceCacheEntry{Cache: namespaceCache}
+	c.namespaceCaches[namespace] = entry
+
+	if c.ctx != nil {
+		c.startNamespaceCache(namespace, entry)
+	}
+
+	c.log.Info("Watching namespace, as it matches "+WatchNamespaceLabelSelectorEnvVar, "namespace", namespace)
+
+	return nil
+}
+
+// removeNamespace stops and removes the cache of the namespace, if it exists.
+func (c *dynamicNamespacedCache) removeNamespace(namespace string) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, exists := c.namespaceCaches[namespace]
+	if !exists {
+		return
+	}
+
+	if entry.cancel != nil {
+		entry.cancel()
+	}
+	delete(c.namespaceCaches, namespace)
+
+	for _, informer := range c.informers {
+		informer.removeNamespaceInformer(namespace)
+	}
+
+	c.log.Info("No longer watching namespace, as it was deleted or no longer matches "+WatchNamespaceLabelSelectorEnvVar, "namespace", namespace)
+}
+
+// startNamespaceCache starts the cache of a namespace, until it is removed or this cache is stopped. The caller must
+// hold the mutex.
+func (c *dynamicNamespacedCache) startNamespaceCache(namespace string, entry *namespaceCacheEntry) {
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	entry.cancel = cancel
+
+	go func() {
+		if err := entry.Start(ctx); err != nil {
+			c.log.Error(err, "unable to start cache of namespace", "namespace", namespace)
+		}
+	}()
+}
+
+// cacheOfNamespace returns the cache of the namespace, or an error if the namespace is not watched.
+func (c *dynamicNamespacedCache) cacheOfNamespace(namespace string) (cache.Cache, error) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, exists := c.namespaceCaches[namespace]
+	if !exists {
+		return nil, fmt.Errorf("namespace '%s' is not watched, as it does not match %s", namespace, WatchNamespaceLabelSelectorEnvVar)
+	}
+	return entry.Cache, nil
+}
+
+// allNamespaceCaches returns the caches of all the watched namespaces.
+func (c *dynamicNamespacedCache) allNamespaceCaches() []cache.Cache {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := []cache.Cache{}
+	for _, entry := range c.namespaceCaches {
+		res = append(res, entry.Cache)
+	}
+	return res
+}
+
+func (c *dynamicNamespacedCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	namespaceCache, err := c.cacheOfNamespace(key.Namespace)
+	if err != nil {
+		return err
+	}
+	return namespaceCache.Get(ctx, key, obj, opts...)
+}
+
+// List lists the objects of the given namespace, or (if no namespace is given) of all watched namespaces.
+func (c *dynamicNamespacedCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	if listOpts.Namespace != corev1.NamespaceAll {
+		namespaceCache, err := c.cacheOfNamespace(listOpts.Namespace)
+		if err != nil {
+			return err
+		}
+		return namespaceCache.List(ctx, list, opts...)
+	}
+
+	allItems := []runtime.Object{}
+	var resourceVersion string
+
+	for _, namespaceCache := range c.allNamespaceCaches() {
+		namespaceList := list.DeepCopyObject().(client.ObjectList)
+		if err := namespaceCache.List(ctx, namespaceList, &listOpts); err != nil {
+			return err
+		}
+		items, err := apimeta.ExtractList(namespaceList)
+		if err != nil {
+			return err
+		}
+		allItems = append(allItems, items...)
+		resourceVersion = namespaceList.GetResourceVersion()
+
+		if listOpts.Limit > 0 {
+			// Only read as many items as requested
+			listOpts.Limit -= int64(len(items))
+			if listOpts.Limit <= 0 {
+				break
+			}
+		}
+	}
+
+	list.SetResourceVersion(resourceVersion)
+	return apimeta.SetList(list, allItems)
+}
+
+func (c *dynamicNamespacedCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetInformerForKind(ctx, gvk)
+}
+
+// GetInformerForKind returns an informer that receives the events of the kind in all watched namespaces, including
+// those that are watched afterwards.
+func (c *dynamicNamespacedCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if informer, exists := c.informers[gvk]; exists {
+		return informer, nil
+	}
+
+	informer := &dynamicNamespacedInformer{namespaceInformers: map[string]cache.Informer{}}
+	for namespace, entry := range c.namespaceCaches {
+		namespaceInformer, err := entry.GetInformerForKind(ctx, gvk)
+		if err != nil {
+			return nil, err
+		}
+		if err := informer.addNamespaceInformer(namespace, namespaceInformer); err != nil {
+			return nil, err
+		}
+	}
+	c.informers[gvk] = informer
+
+	return informer, nil
+}
+
+// IndexField adds the field index to the caches of all watched namespaces, including those that are watched afterwards.
+func (c *dynamicNamespacedCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.fieldIndexes = append(c.fieldIndexes, fieldIndex{obj: obj, field: field, extractValue: extractValue})
+
+	for _, entry := range c.namespaceCaches {
+		if err := entry.IndexField(ctx, obj, field, extractValue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start starts the caches of the watched namespaces (including those that are watched afterwards), and blocks until the
+// context is closed.
+func (c *dynamicNamespacedCache) Start(ctx context.Context) error {
+
+	c.mutex.Lock()
+	if c.ctx != nil {
+		c.mutex.Unlock()
+		return fmt.Errorf("namespace-scoped cache was already started")
+	}
+	c.ctx = ctx
+	for namespace, entry := range c.namespaceCaches {
+		c.startNamespaceCache(namespace, entry)
+	}
+	c.mutex.Unlock()
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// WaitForCacheSync waits for the namespaces to be listed, and then for the caches of the matching namespaces to sync.
+func (c *dynamicNamespacedCache) WaitForCacheSync(ctx context.Context) bool {
+
+	if !toolscache.WaitForCacheSync(ctx.Done(), c.namespaceInformer.HasSynced) {
+		return false
+	}
+
+	// The event handler may not yet have been called for every namespace that has been listed, so add them here
+	var namespaceList corev1.NamespaceList
+	if err := c.clusterWideCache.List(ctx, &namespaceList, client.MatchingLabelsSelector{Selector: c.selector}); err != nil {
+		c.log.Error(err, "unable to list namespaces matching "+WatchNamespaceLabelSelectorEnvVar)
+		return false
+	}
+	for _, namespace := range namespaceList.Items {
+		if err := c.addNamespace(namespace.Name); err != nil {
+			c.log.Error(err, "unable to watch namespace", "namespace", namespace.Name)
+			return false
+		}
+	}
+
+	for _, namespaceCache := range c.allNamespaceCaches() {
+		if !namespaceCache.WaitForCacheSync(ctx) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dynamicNamespacedInformer is a cache.Informer of a single kind, in all of the namespaces watched by a
+// dynamicNamespacedCache. The event handlers and indexers that are added to it are also added to the informers of
+// namespaces that are watched afterwards.
+type dynamicNamespacedInformer struct {
+	mutex sync.Mutex
+
+	handlers []eventHandler
+	indexers []toolscache.Indexers
+
+	// namespaceInformers contains the informer of each watched namespace, by namespace name
+	namespaceInformers map[string]cache.Informer
+}
+
+var _ cache.Informer = &dynamicNamespacedInformer{}
+
+// eventHandler contains the parameters of a call to AddEventHandler, or AddEventHandlerWithResyncPeriod.
+type eventHandler struct {
+	handler toolscache.ResourceEventHandler
+
+	// resyncPeriod is nil if the handler uses the default resync period of the informer
+	resyncPeriod *time.Duration
+}
+
+func (e eventHandler) addTo(informer cache.Informer) {
+	if e.resyncPeriod == nil {
+		informer.AddEventHandler(e.handler)
+	} else {
+		informer.AddEventHandlerWithResyncPeriod(e.handler, *e.resyncPeriod)
+	}
+}
+
+func (i *dynamicNamespacedInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	i.addEventHandler(eventHandler{handler: handler})
+}
+
+func (i *dynamicNamespacedInformer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler, resyncPeriod time.Duration) {
+	i.addEventHandler(eventHandler{handler: handler, resyncPeriod: &resyncPeriod})
+}
+
+func (i *dynamicNamespacedInformer) addEventHandler(handler eventHandler) {
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.handlers = append(i.handlers, handler)
+	for _, namespaceInformer := range i.namespaceInformers {
+		handler.addTo(namespaceInformer)
+	}
+}
+
+func (i *dynamicNamespacedInformer) AddIndexers(indexers toolscache.Indexers) error {
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.indexers = append(i.indexers, indexers)
+	for _, namespaceInformer := range i.namespaceInformers {
+		if err := namespaceInformer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HasSynced returns true if the informers of all watched namespaces have synced.
+func (i *dynamicNamespacedInformer) HasSynced() bool {
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, namespaceInformer := range i.namespaceInformers {
+		if !namespaceInformer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// addNamespaceInformer adds the event handlers and indexers that have been added so far to the informer of a newly
+// watched namespace.
+func (i *dynamicNamespacedInformer) addNamespaceInformer(namespace string, namespaceInformer cache.Informer) error {
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, indexers := range i.indexers {
+		if err := namespaceInformer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	for _, handler := range i.handlers {
+		handler.addTo(namespaceInformer)
+	}
+	i.namespaceInformers[namespace] = namespaceInformer
+
+	return nil
+}
+
+// removeNamespaceInformer removes the informer of a namespace that is no longer watched.
+func (i *dynamicNamespacedInformer) removeNamespaceInformer(namespace string) {
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	delete(i.namespaceInformers, namespace)
+}
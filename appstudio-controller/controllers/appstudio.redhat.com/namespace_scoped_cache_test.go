@@ -0,0 +1,187 @@
+package appstudioredhatcom
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Namespace-scoped informer tests", func() {
+
+	ctx := context.Background()
+
+	var scheme *runtime.Scheme
+
+	BeforeEach(func() {
+		var err error
+		scheme, _, _, _, err = tests.GenericTestSetup()
+		Expect(err).To(BeNil())
+
+		err = appstudioshared.AddToScheme(scheme)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(WatchNamespaceLabelSelectorEnvVar)
+	})
+
+	It("should use the default cache if WATCH_NAMESPACE_LABEL_SELECTOR is not set", func() {
+		os.Unsetenv(WatchNamespaceLabelSelectorEnvVar)
+
+		newCache, err := GetNamespaceScopedNewCacheFunc(log.FromContext(ctx))
+		Expect(err).To(BeNil())
+		Expect(newCache).To(BeNil())
+	})
+
+	It("should return an error if WATCH_NAMESPACE_LABEL_SELECTOR is not a valid label selector", func() {
+		os.Setenv(WatchNamespaceLabelSelectorEnvVar, "toolchain.dev.openshift.com/type in (tenant")
+
+		newCache, err := GetNamespaceScopedNewCacheFunc(log.FromContext(ctx))
+		Expect(err).ToNot(BeNil())
+		Expect(newCache).To(BeNil())
+	})
+
+	It("should watch the namespaces that match the label selector, including those that are created or labeled after the cache has started", func() {
+
+		newNamespace := func(name string, namespaceLabels map[string]string) *corev1.Namespace {
+			return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: namespaceLabels}}
+		}
+		tenantLabels := map[string]string{"toolchain.dev.openshift.com/type": "tenant"}
+
+		selector, err := labels.Parse("toolchain.dev.openshift.com/type=tenant")
+		Expect(err).To(BeNil())
+
+		clusterWideCache := &informertest.FakeInformers{Scheme: scheme}
+
+		namespaceCaches := map[string]*informertest.FakeInformers{}
+		newNamespaceCache := func(namespace string) (cache.Cache, error) {
+			namespaceCaches[namespace] = &informertest.FakeInformers{Scheme: scheme}
+			return namespaceCaches[namespace], nil
+		}
+
+		dynamicCache, err := newDynamicNamespacedCache(clusterWideCache, selector, scheme, newNamespaceCache, log.FromContext(ctx))
+		Expect(err).To(BeNil())
+
+		By("adding an event handler for Secrets, before the cache is started (as a controller would)")
+		secretInformer, err := dynamicCache.GetInformer(ctx, &corev1.Secret{})
+		Expect(err).To(BeNil())
+
+		secretEvents := []string{}
+		secretInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				secretEvents = append(secretEvents, obj.(*corev1.Secret).Namespace)
+			},
+		})
+
+		cacheCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(dynamicCache.Start(cacheCtx)).To(Succeed())
+		}()
+		Eventually(func() bool {
+			dynamicCache.mutex.Lock()
+			defer dynamicCache.mutex.Unlock()
+			return dynamicCache.ctx != nil
+		}).Should(BeTrue())
+
+		namespaceInformer, err := clusterWideCache.FakeInformerFor(&corev1.Namespace{})
+		Expect(err).To(BeNil())
+
+		// addSecret simulates the creation of a Secret in the cache of the namespace
+		addSecret := func(namespace string) {
+			fakeSecretInformer, err := namespaceCaches[namespace].FakeInformerFor(&corev1.Secret{})
+			Expect(err).To(BeNil())
+			fakeSecretInformer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: namespace}})
+		}
+
+		isWatched := func(namespace string) bool {
+			_, err := dynamicCache.cacheOfNamespace(namespace)
+			return err == nil
+		}
+
+		By("creating a namespace that matches the selector, and one that doesn't")
+		namespaceInformer.Add(newNamespace("workspace-a", tenantLabels))
+		namespaceInformer.Add(newNamespace("kube-public", nil))
+
+		Expect(isWatched("workspace-a")).To(BeTrue())
+		Expect(isWatched("kube-public")).To(BeFalse())
+
+		addSecret("workspace-a")
+		Expect(secretEvents).To(Equal([]string{"workspace-a"}))
+
+		By("labeling an existing namespace, so that it matches the selector")
+		namespaceInformer.Add(newNamespace("workspace-b", nil))
+		Expect(isWatched("workspace-b")).To(BeFalse())
+
+		namespaceInformer.Update(newNamespace("workspace-b", nil), newNamespace("workspace-b", tenantLabels))
+		Expect(isWatched("workspace-b")).To(BeTrue())
+
+		By("verifying the event handler receives the events of the newly watched namespace")
+		addSecret("workspace-b")
+		Expect(secretEvents).To(Equal([]string{"workspace-a", "workspace-b"}))
+
+		By("removing the label of a namespace, and deleting the other")
+		namespaceInformer.Update(newNamespace("workspace-a", tenantLabels), newNamespace("workspace-a", nil))
+		Expect(isWatched("workspace-a")).To(BeFalse())
+
+		namespaceInformer.Delete(newNamespace("workspace-b", tenantLabels))
+		Expect(isWatched("workspace-b")).To(BeFalse())
+
+		err = dynamicCache.Get(ctx, types.NamespacedName{Namespace: "workspace-a", Name: "my-secret"}, &corev1.Secret{})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("should only use the namespace-scoped cache for Secrets, DeploymentTargets and DeploymentTargetClaims", func() {
+
+		clusterWideCache := &informertest.FakeInformers{Scheme: scheme}
+		namespacedCache := &informertest.FakeInformers{Scheme: scheme}
+
+		scopedCache := &namespaceScopedCache{
+			Cache:           clusterWideCache,
+			namespacedCache: namespacedCache,
+			scheme:          scheme,
+			scopedGVKs:      map[schema.GroupVersionKind]bool{},
+		}
+		for _, obj := range namespaceScopedKinds() {
+			gvk, err := apiutil.GVKForObject(obj, scheme)
+			Expect(err).To(BeNil())
+			scopedCache.scopedGVKs[gvk] = true
+		}
+
+		for _, obj := range []runtime.Object{
+			&corev1.Secret{}, &corev1.SecretList{},
+			&appstudioshared.DeploymentTarget{}, &appstudioshared.DeploymentTargetList{},
+			&appstudioshared.DeploymentTargetClaim{}, &appstudioshared.DeploymentTargetClaimList{},
+		} {
+			objCache, err := scopedCache.cacheForObject(obj)
+			Expect(err).To(BeNil())
+			Expect(objCache).To(BeIdenticalTo(namespacedCache))
+		}
+
+		for _, obj := range []runtime.Object{
+			&corev1.ConfigMap{}, &corev1.Namespace{},
+			&appstudioshared.Environment{}, &appstudioshared.EnvironmentList{},
+		} {
+			objCache, err := scopedCache.cacheForObject(obj)
+			Expect(err).To(BeNil())
+			Expect(objCache).To(BeIdenticalTo(clusterWideCache))
+		}
+	})
+})
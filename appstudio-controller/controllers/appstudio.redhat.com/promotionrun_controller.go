@@ -150,9 +150,11 @@ func (r *PromotionRunReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	if promotionRun.Status.State != appstudioshared.PromotionRunState_Active {
-		promotionRun.Status.State = appstudioshared.PromotionRunState_Active
 
-		if err := rClient.Status().Update(ctx, promotionRun); err != nil {
+		if err := updateStatus(ctx, rClient, promotionRun, func() bool {
+			promotionRun.Status.State = appstudioshared.PromotionRunState_Active
+			return true
+		}); err != nil {
 			log.Error(err, "unable to update PromotionRun state: "+promotionRun.Name)
 			return ctrl.Result{}, fmt.Errorf("unable to update PromotionRun state: %v", err)
 		}
@@ -236,13 +238,15 @@ func (r *PromotionRunReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		logutil.LogAPIResourceChangeEvent(promotionRun.Namespace, promotionRun.Name, promotionRun, logutil.ResourceModified, log)
 		log.Info("Updating Binding: " + binding.Name + " to target the Snapshot: " + promotionRun.Spec.Snapshot)
 
-		// Set the time when of first reconcilation on a particular PromotionRun if not set already. This will be used later to check for time out of Promotion.
-		if promotionRun.Status.PromotionStartTime.IsZero() {
-			promotionRun.Status.PromotionStartTime = metav1.Now()
-		}
+		if err := updateStatus(ctx, rClient, promotionRun, func() bool {
+			// Set the time when of first reconcilation on a particular PromotionRun if not set already. This will be used later to check for time out of Promotion.
+			if promotionRun.Status.PromotionStartTime.IsZero() {
+				promotionRun.Status.PromotionStartTime = metav1.Now()
+			}
 
-		promotionRun.Status.ActiveBindings = []string{binding.Name}
-		if err := rClient.Status().Update(ctx, promotionRun); err != nil {
+			promotionRun.Status.ActiveBindings = []string{binding.Name}
+			return true
+		}); err != nil {
 			log.Error(err, "unable to update PromotionRun active binding: "+promotionRun.Name)
 
 			// Update Status.Conditions field.
@@ -492,48 +496,49 @@ func (r *PromotionRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appstudioshared.PromotionRun{}).
 		Owns(&appstudioshared.SnapshotEnvironmentBinding{}).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("PromotionRun", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &appstudioshared.PromotionRun{} }, r)))
 }
 
 // Update Status.Environment.Status field.
 func updateStatusEnvironmentStatus(ctx context.Context, client client.Client, displayStatus string, promotionRun *appstudioshared.PromotionRun,
 	status appstudioshared.PromotionRunEnvironmentStatusField, log logr.Logger) error {
 
-	targetEnvIndex, targetEnvStep := -1, 0
+	if err := updateStatus(ctx, client, promotionRun, func() bool {
+		targetEnvIndex, targetEnvStep := -1, 0
 
-	// Check if EnvironmentStatus for given Environment is already present.
-	for i, envStatus := range promotionRun.Status.EnvironmentStatus {
-		// Find the Index in array having status for given environment.
-		if envStatus.EnvironmentName == promotionRun.Spec.ManualPromotion.TargetEnvironment {
-			targetEnvIndex = i
-			break
+		// Check if EnvironmentStatus for given Environment is already present.
+		for i, envStatus := range promotionRun.Status.EnvironmentStatus {
+			// Find the Index in array having status for given environment.
+			if envStatus.EnvironmentName == promotionRun.Spec.ManualPromotion.TargetEnvironment {
+				targetEnvIndex = i
+				break
+			}
 		}
-	}
 
-	// If given environment is not present already then create new else update existing one.
-	if targetEnvIndex == -1 {
+		// If given environment is not present already then create new else update existing one.
+		if targetEnvIndex == -1 {
 
-		// Find the max Step and Index available
-		for _, j := range promotionRun.Status.EnvironmentStatus {
-			if j.Step > targetEnvIndex {
-				targetEnvStep = j.Step
+			// Find the max Step and Index available
+			for _, j := range promotionRun.Status.EnvironmentStatus {
+				if j.Step > targetEnvIndex {
+					targetEnvStep = j.Step
+				}
 			}
-		}
-
-		promotionRun.Status.EnvironmentStatus = append(promotionRun.Status.EnvironmentStatus,
-			appstudioshared.PromotionRunEnvironmentStatus{
-				Step:            targetEnvStep + 1,
-				EnvironmentName: promotionRun.Spec.ManualPromotion.TargetEnvironment,
-				DisplayStatus:   displayStatus,
-				Status:          status,
-			})
-	} else {
-		// Status for given environment exists, just update it.
-		promotionRun.Status.EnvironmentStatus[targetEnvIndex].DisplayStatus = displayStatus
-		promotionRun.Status.EnvironmentStatus[targetEnvIndex].Status = status
-	}
 
-	if err := client.Status().Update(ctx, promotionRun); err != nil {
+			promotionRun.Status.EnvironmentStatus = append(promotionRun.Status.EnvironmentStatus,
+				appstudioshared.PromotionRunEnvironmentStatus{
+					Step:            targetEnvStep + 1,
+					EnvironmentName: promotionRun.Spec.ManualPromotion.TargetEnvironment,
+					DisplayStatus:   displayStatus,
+					Status:          status,
+				})
+		} else {
+			// Status for given environment exists, just update it.
+			promotionRun.Status.EnvironmentStatus[targetEnvIndex].DisplayStatus = displayStatus
+			promotionRun.Status.EnvironmentStatus[targetEnvIndex].Status = status
+		}
+		return true
+	}); err != nil {
 		return err
 	}
 	logutil.LogAPIResourceChangeEvent(promotionRun.Namespace, promotionRun.Name, promotionRun, logutil.ResourceModified, log)
@@ -546,45 +551,46 @@ func updateStatusConditions(ctx context.Context, client client.Client, message s
 	promotionRun *appstudioshared.PromotionRun, conditionType appstudioshared.PromotionRunConditionType,
 	status appstudioshared.PromotionRunConditionStatus, reason appstudioshared.PromotionRunReasonType) error {
 
-	// Check if condition with same type is already set, if Yes then check if content is same,
-	// if Yes then update only LastProbeTime else update all fields in existing element.
-	// If element with same type is not present then append new element.
-	index := -1
-	for i, Condition := range promotionRun.Status.Conditions {
-		if Condition.Type == conditionType {
-			index = i
-			break
+	if err := updateStatus(ctx, client, promotionRun, func() bool {
+		// Check if condition with same type is already set, if Yes then check if content is same,
+		// if Yes then update only LastProbeTime else update all fields in existing element.
+		// If element with same type is not present then append new element.
+		index := -1
+		for i, Condition := range promotionRun.Status.Conditions {
+			if Condition.Type == conditionType {
+				index = i
+				break
+			}
 		}
-	}
 
-	now := metav1.Now()
-
-	if index == -1 {
-		promotionRun.Status.Conditions = append(promotionRun.Status.Conditions,
-			appstudioshared.PromotionRunCondition{
-				Type:               conditionType,
-				Message:            message,
-				LastProbeTime:      now,
-				LastTransitionTime: &now,
-				Status:             status,
-				Reason:             reason,
-			})
-	} else {
-		if promotionRun.Status.Conditions[index].Message == message &&
-			promotionRun.Status.Conditions[index].Reason == reason &&
-			promotionRun.Status.Conditions[index].Status == status {
-
-			promotionRun.Status.Conditions[index].LastProbeTime = now
+		now := metav1.Now()
+
+		if index == -1 {
+			promotionRun.Status.Conditions = append(promotionRun.Status.Conditions,
+				appstudioshared.PromotionRunCondition{
+					Type:               conditionType,
+					Message:            message,
+					LastProbeTime:      now,
+					LastTransitionTime: &now,
+					Status:             status,
+					Reason:             reason,
+				})
 		} else {
-			promotionRun.Status.Conditions[index].Reason = reason
-			promotionRun.Status.Conditions[index].Message = message
-			promotionRun.Status.Conditions[index].LastProbeTime = now
-			promotionRun.Status.Conditions[index].LastTransitionTime = &now
-			promotionRun.Status.Conditions[index].Status = status
+			if promotionRun.Status.Conditions[index].Message == message &&
+				promotionRun.Status.Conditions[index].Reason == reason &&
+				promotionRun.Status.Conditions[index].Status == status {
+
+				promotionRun.Status.Conditions[index].LastProbeTime = now
+			} else {
+				promotionRun.Status.Conditions[index].Reason = reason
+				promotionRun.Status.Conditions[index].Message = message
+				promotionRun.Status.Conditions[index].LastProbeTime = now
+				promotionRun.Status.Conditions[index].LastTransitionTime = &now
+				promotionRun.Status.Conditions[index].Status = status
+			}
 		}
-	}
-
-	if err := client.Status().Update(ctx, promotionRun); err != nil {
+		return true
+	}); err != nil {
 		return err
 	}
 
@@ -0,0 +1,43 @@
+package appstudioredhatcom
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// While the GitOps Service is in read-only mode (see sharedutil.IsReadOnlyModeEnabled), the Environment and
+// SnapshotEnvironmentBinding controllers do not create or update the resources they generate. Instead, the
+// ServiceDegraded condition is set on the resource, and the request is requeued until read-only mode is disabled.
+
+// reconcileReadOnlyMode sets the ServiceDegraded condition of the resource (in 'conditions', which must be a field of
+// 'obj') to True if the GitOps Service is in read-only mode, or to False once it is disabled.
+//
+// Returns true if the GitOps Service is in read-only mode, in which case the resource should not be reconciled.
+func reconcileReadOnlyMode(ctx context.Context, k8sClient client.Client, obj client.Object, conditions *[]metav1.Condition) (bool, error) {
+
+	readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, k8sClient)
+	if err != nil {
+		return false, err
+	}
+
+	if err := updateStatus(ctx, k8sClient, obj, func() bool {
+		return sharedutil.SetServiceDegradedCondition(conditions, readOnlyModeEnabled)
+	}); err != nil {
+		return false, fmt.Errorf("unable to update ServiceDegraded condition: %v", err)
+	}
+
+	return readOnlyModeEnabled, nil
+}
+
+// readOnlyModeResult is the result of a request that was not processed, as the GitOps Service is in read-only mode.
+func readOnlyModeResult(log logr.Logger) ctrl.Result {
+	return logutil.RequeueWithReason(log, ctrl.Result{RequeueAfter: sharedutil.ReadOnlyModeRequeueInterval},
+		logutil.RequeueReasonReadOnlyMode, "GitOps Service is in read-only mode: the request will be processed once read-only mode is disabled")
+}
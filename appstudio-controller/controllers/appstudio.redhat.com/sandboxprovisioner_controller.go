@@ -22,6 +22,7 @@ import (
 
 	codereadytoolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 	applicationv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 
 	apierr "k8s.io/apimachinery/pkg/api/errors"
@@ -211,5 +212,5 @@ func (r *SandboxProvisionerReconciler) SetupWithManager(mgr ctrl.Manager) error
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&applicationv1alpha1.DeploymentTargetClaim{}).
 		WithEventFilter(DTCPendingDynamicProvisioningBySandbox()).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("SandboxProvisioner", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &applicationv1alpha1.DeploymentTargetClaim{} }, r)))
 }
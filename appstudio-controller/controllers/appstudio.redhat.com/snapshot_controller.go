@@ -20,6 +20,7 @@ import (
 	"context"
 
 	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -59,5 +60,5 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 func (r *SnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appstudioshared.Snapshot{}).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("Snapshot", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &appstudioshared.Snapshot{} }, r)))
 }
@@ -20,17 +20,23 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"path"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	apibackend "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	corev1 "k8s.io/api/core/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,8 +45,13 @@ import (
 	"k8s.io/utils/pointer"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
@@ -50,6 +61,80 @@ const (
 	applicationLabelKey = appstudioLabelKey + "/application"
 	componentLabelKey   = appstudioLabelKey + "/component"
 	environmentLabelKey = appstudioLabelKey + "/environment"
+
+	// SnapshotEnvironmentBindingSyncOrderAnnotation may be set on a SnapshotEnvironmentBinding to declare the order in which
+	// its components are deployed, as a comma-separated list of 'component=wave' pairs, for example: "database=0,config=0,backend=1"
+	// - Components of a lower wave are deployed before components of a higher wave. Components that are not listed are in wave 0.
+	// - The wave is set as the Argo CD sync wave annotation of the GitOpsDeployment (and thus of the Argo CD Application) of the component.
+	// - The GitOpsDeployments of a wave are only created/updated once the GitOpsDeployments of all previous waves are synced and healthy.
+	SnapshotEnvironmentBindingSyncOrderAnnotation = appstudioLabelKey + "/sync-order"
+
+	// SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation may be set on a SnapshotEnvironmentBinding to the name of a
+	// ConfigMap (in the namespace of the binding) that contains environment-specific Helm values for its components.
+	// - The '<component>.values.yaml' key of the ConfigMap is set as the Helm values of the GitOpsDeployment of that component.
+	// - The binding is reconciled again when the ConfigMap changes.
+	SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation = appstudioLabelKey + "/config-overlay-configmap"
+
+	// SnapshotEnvironmentBindingConfigOverlayPathAnnotation may be set on a SnapshotEnvironmentBinding to a path that is
+	// relative to the GitOps repository path of each of its components, and which contains environment-specific configuration:
+	// - If the path is a '.yaml'/'.yml' file, it is used as a Helm value file of the GitOpsDeployments.
+	// - Otherwise, the path is a Kustomize overlay directory (for example, with patches for the replicas/hostnames of
+	//   the environment), which the GitOpsDeployments deploy instead of the GitOps repository path of the component.
+	SnapshotEnvironmentBindingConfigOverlayPathAnnotation = appstudioLabelKey + "/config-overlay-path"
+
+	// configOverlayHelmValuesKeySuffix is the suffix of the keys of the config overlay ConfigMap, after the component name
+	configOverlayHelmValuesKeySuffix = ".values.yaml"
+
+	// SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation is set on a SnapshotEnvironmentBinding to the number of
+	// times that a retry of a failed write to the GitOps repository has been requested. Updating the annotation causes
+	// application-service to reconcile the binding again, and thus to retry the write.
+	SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation = appstudioLabelKey + "/gitops-repo-write-retry"
+
+	// gitOpsRepoWriteRetryMinDelay and gitOpsRepoWriteRetryMaxDelay bound the (exponentially increasing) delay between
+	// retries of a failed write to the GitOps repository
+	gitOpsRepoWriteRetryMinDelay = 30 * time.Second
+	gitOpsRepoWriteRetryMaxDelay = 10 * time.Minute
+
+	// SnapshotEnvironmentBindingDeployedImagesAnnotation is set on a SnapshotEnvironmentBinding to the container images
+	// that are deployed to the Environment, as a comma-separated list of 'component=image' pairs, where the image is the
+	// digest-pinned reference from the Snapshot, for example: "backend=quay.io/org/backend@sha256:1a2b..."
+	// - The status of the binding is defined by the application-api, so the deployed images are recorded in this annotation.
+	// - The entry of a component is only updated once its GitOpsDeployment is synced and healthy at the expected commit:
+	//   while a new Snapshot is being deployed, the entry continues to report the image that was previously deployed.
+	// - Components whose container image in the Snapshot is not pinned to a digest have no entry.
+	SnapshotEnvironmentBindingDeployedImagesAnnotation = appstudioLabelKey + "/deployed-images"
+)
+
+// Preflight conditions: these are set in .status.bindingConditions of the SnapshotEnvironmentBinding, and report whether
+// the resources that the binding depends on are ready. GitOpsDeployments are only generated once all the checks pass.
+const (
+	// SnapshotEnvironmentBindingConditionEnvironmentAvailable reports whether the Environment of the binding exists
+	SnapshotEnvironmentBindingConditionEnvironmentAvailable = "EnvironmentAvailable"
+	SnapshotEnvironmentBindingReasonEnvironmentFound        = "EnvironmentFound"
+	SnapshotEnvironmentBindingReasonEnvironmentNotFound     = "EnvironmentNotFound"
+
+	// SnapshotEnvironmentBindingConditionManagedEnvironmentConnected reports whether the GitOps Service is able to connect
+	// to the cluster of the Environment. It is only set for Environments that target a GitOpsDeploymentManagedEnvironment.
+	SnapshotEnvironmentBindingConditionManagedEnvironmentConnected = "ManagedEnvironmentConnected"
+	SnapshotEnvironmentBindingReasonManagedEnvironmentConnected    = "ManagedEnvironmentConnected"
+	SnapshotEnvironmentBindingReasonManagedEnvironmentNotFound     = "ManagedEnvironmentNotFound"
+	SnapshotEnvironmentBindingReasonManagedEnvironmentNotConnected = "ManagedEnvironmentNotConnected"
+
+	// SnapshotEnvironmentBindingConditionSnapshotComponentsAvailable reports whether the Snapshot of the binding exists,
+	// and contains a container image for each of the components of the binding.
+	SnapshotEnvironmentBindingConditionSnapshotComponentsAvailable = "SnapshotComponentsAvailable"
+	SnapshotEnvironmentBindingReasonSnapshotComponentsAvailable    = "SnapshotComponentsAvailable"
+	SnapshotEnvironmentBindingReasonSnapshotNotFound               = "SnapshotNotFound"
+	SnapshotEnvironmentBindingReasonSnapshotComponentsMissing      = "SnapshotComponentsMissing"
+)
+
+const (
+	// SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed reports whether application-service was unable to write
+	// the changes of the binding to the GitOps repository (for example, due to an authentication failure, or a merge
+	// conflict). The message contains the error of the Git provider, and when the write will next be retried.
+	SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed = "GitOpsRepoWriteFailed"
+	SnapshotEnvironmentBindingReasonGitOpsRepoWriteFailed    = "GitOpsRepoWriteFailed"
+	SnapshotEnvironmentBindingReasonGitOpsRepoWriteSucceeded = "GitOpsRepoWriteSucceeded"
 )
 
 // SnapshotEnvironmentBindingReconciler reconciles a SnapshotEnvironmentBinding object
@@ -66,8 +151,11 @@ type SnapshotEnvironmentBindingReconciler struct {
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotenvironmentbindings/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotenvironmentbindings/finalizers,verbs=update
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshots,verbs=get;list;watch
+//+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeploymentmanagedenvironments,verbs=get;list;watch
 //+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -93,14 +181,26 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 	}
 
 	if err := rClient.Get(ctx, req.NamespacedName, binding); err != nil {
-		// Binding doesn't exist: it was deleted.
-		// Owner refs will ensure the GitOpsDeployments are deleted, so no work to do.
-		return ctrl.Result{}, nil
+		if apierr.IsNotFound(err) {
+			// Binding doesn't exist: it was deleted.
+			// Owner refs will ensure the GitOpsDeployments are deleted, so no work to do.
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("unable to retrieve SnapshotEnvironmentBinding: %v", err)
 	}
 
-	// Make a copy of the original SnapshotEnvironmentBinding, so we can compare it with the updated value, to see
-	// if our reconciliation changed the resource at all.
-	originalBinding := *binding.DeepCopy()
+	// While in read-only mode, no GitOpsDeployments are created or updated: the binding is requeued until it is disabled.
+	if readOnlyModeEnabled, err := reconcileReadOnlyMode(ctx, rClient, binding, &binding.Status.BindingConditions); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to reconcile read-only mode of SnapshotEnvironmentBinding: %v", err)
+	} else if readOnlyModeEnabled {
+		return readOnlyModeResult(log), nil
+	}
+
+	// Once the Environment of an ephemeral binding has been torn down, it is no longer deployed to (see EphemeralBindingReconciler)
+	if isEphemeralBindingTornDown(*binding) {
+		log.Info("Not reconciling SnapshotEnvironmentBinding, as its ephemeral Environment has been torn down")
+		return ctrl.Result{}, nil
+	}
 
 	environment := appstudioshared.Environment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -110,12 +210,33 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 	}
 	if err := rClient.Get(ctx, client.ObjectKeyFromObject(&environment), &environment); err != nil {
 		if apierr.IsNotFound(err) {
+
+			// The binding is reconciled again when the Environment is created, so just report the missing Environment.
+			log.Info("Environment referenced by SnapshotEnvironmentBinding does not exist", "environment", environment.Name)
+
+			if err := updateBindingConditionOfSEB(ctx, rClient,
+				"Environment '"+environment.Name+"' referenced by the SnapshotEnvironmentBinding does not exist", binding,
+				SnapshotEnvironmentBindingConditionEnvironmentAvailable, metav1.ConditionFalse, SnapshotEnvironmentBindingReasonEnvironmentNotFound, log); err != nil {
+
+				log.Error(err, "unable to update snapshotEnvironmentBinding status condition.")
+				return ctrl.Result{}, fmt.Errorf("unable to update snapshotEnvironmentBinding status condition. %v", err)
+			}
+
 			return ctrl.Result{}, nil
 		} else {
 			return ctrl.Result{}, fmt.Errorf("unable to retrieve Environment '%s' referenced by Binding: %v", environment.Name, err)
 		}
 	}
 
+	// Verify that the target ManagedEnvironment and the Snapshot are ready: the conditions are reported on every reconcile,
+	// but GitOpsDeployments are only prevented from being generated (below) if the checks did not pass. The binding is
+	// reconciled again when either of them changes.
+	preflightChecksPassed, err := runBindingPreflightChecks(ctx, binding, environment, rClient, log)
+	if err != nil {
+		log.Error(err, "unable to run the preflight checks of SnapshotEnvironmentBinding")
+		return ctrl.Result{}, fmt.Errorf("unable to run the preflight checks of SnapshotEnvironmentBinding: %w", err)
+	}
+
 	// Don't reconcile the binding if the application-service component indicated via the binding.status field
 	// that there were issues with the GitOps repository, or if the GitOps repository isn't ready
 	// yet.
@@ -123,21 +244,25 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 	if len(binding.Status.GitOpsRepoConditions) > 0 {
 		if binding.Status.GitOpsRepoConditions[len(binding.Status.GitOpsRepoConditions)-1].Status == metav1.ConditionFalse {
 			// if the SnapshotEventBinding GitOps Repo Conditions status is false - return;
-			// since there was an unexpected issue with refreshing/syncing the GitOps repository
-			log.V(logutil.LogLevel_Debug).Info("Can not Reconcile Binding '" + binding.Name + "', since GitOps Repo Conditions status is false.")
-
-			// Update .status.bindingConditions field EnvironmentBinding with the error
-			if err := updateBindingConditionOfSEB(ctx, rClient,
-				"Can not Reconcile Binding '"+binding.Name+"', since GitOps Repo Conditions status is false.", binding,
-				SnapshotEnvironmentBindingConditionErrorOccurred, metav1.ConditionTrue, SnapshotEnvironmentBindingReasonErrorOccurred, log); err != nil {
-
-				log.Error(err, "unable to update snapshotEnvironmentBinding status condition.")
-				return ctrl.Result{}, fmt.Errorf("unable to update snapshotEnvironmentBinding status condition. %v", err)
+			// since there was an unexpected issue with writing to the GitOps repository
+			log.Info("Can not Reconcile Binding '" + binding.Name + "', since the write to the GitOps repository failed.")
+
+			// Report the failure in the GitOpsRepoWriteFailed condition, and retry the write once it is due
+			retryAfter, err := handleGitOpsRepoWriteFailure(ctx, binding, rClient, log)
+			if err != nil {
+				log.Error(err, "unable to handle the failed write to the GitOps repository of SnapshotEnvironmentBinding")
+				return ctrl.Result{}, fmt.Errorf("unable to handle the failed write to the GitOps repository of SnapshotEnvironmentBinding: %v", err)
 			}
 
-			return ctrl.Result{}, nil
+			return logutil.RequeueWithReason(log, ctrl.Result{RequeueAfter: retryAfter}, logutil.RequeueReasonGitOpsRepoWriteFailed,
+				"Waiting to retry the write to the GitOps repository of SnapshotEnvironmentBinding"), nil
 		} else if binding.Status.GitOpsRepoConditions[len(binding.Status.GitOpsRepoConditions)-1].Status == metav1.ConditionTrue {
 
+			if err := clearGitOpsRepoWriteFailure(ctx, binding, rClient, log); err != nil {
+				log.Error(err, "unable to clear the failed write to the GitOps repository of SnapshotEnvironmentBinding")
+				return ctrl.Result{}, fmt.Errorf("unable to clear the failed write to the GitOps repository of SnapshotEnvironmentBinding: %v", err)
+			}
+
 			// if the SnapshotEventBinding GitOps Repo Conditions status is true update the
 			// binding condition status to false
 			if err := updateBindingConditionOfSEB(ctx, rClient,
@@ -171,6 +296,45 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 
 		// if length of the Binding component status is 0 and there is no issue with the GitOps Repo Conditions;
 		// the Application Service controller has not synced the GitOps repository yet, return and requeue.
+		return logutil.RequeueWithReason(log, ctrl.Result{}, logutil.RequeueReasonWaitingForComponentStatus,
+			"Waiting for the Application Service controller to set the component status of SnapshotEnvironmentBinding"), nil
+	}
+
+	if !preflightChecksPassed {
+		log.Info("Preflight checks of SnapshotEnvironmentBinding did not pass, GitOpsDeployments will not be generated")
+		return ctrl.Result{}, nil
+	}
+
+	// map: componentName (string) -> sync wave of that component, as declared by the user
+	componentSyncWaves, err := parseComponentSyncWaves(*binding)
+	if err != nil {
+
+		// Update Status.Conditions field of environmentBinding.
+		if err := updateBindingConditionOfSEB(ctx, rClient, err.Error(), binding, SnapshotEnvironmentBindingConditionErrorOccurred, metav1.ConditionTrue, SnapshotEnvironmentBindingReasonErrorOccurred, log); err != nil {
+			log.Error(err, "unable to update snapshotEnvironmentBinding status condition.")
+			return ctrl.Result{}, fmt.Errorf("unable to update snapshotEnvironmentBinding status condition. %v", err)
+		}
+
+		log.Error(err, "invalid sync order annotation on SnapshotEnvironmentBinding")
+		return ctrl.Result{}, nil
+	}
+
+	// The environment-specific configuration of the components, if the binding references any
+	configOverlay, userError, err := getConfigOverlayOfBinding(ctx, *binding, rClient)
+	if err != nil {
+		log.Error(err, "unable to retrieve the config overlay of SnapshotEnvironmentBinding")
+		return ctrl.Result{}, fmt.Errorf("unable to retrieve the config overlay of SnapshotEnvironmentBinding: %w", err)
+
+	} else if userError != "" {
+
+		// Update Status.Conditions field of environmentBinding: the binding is reconciled again when the annotations
+		// of the binding, or the ConfigMap, change.
+		if err := updateBindingConditionOfSEB(ctx, rClient, userError, binding, SnapshotEnvironmentBindingConditionErrorOccurred, metav1.ConditionTrue, SnapshotEnvironmentBindingReasonErrorOccurred, log); err != nil {
+			log.Error(err, "unable to update snapshotEnvironmentBinding status condition.")
+			return ctrl.Result{}, fmt.Errorf("unable to update snapshotEnvironmentBinding status condition. %v", err)
+		}
+
+		log.Info("Invalid config overlay on SnapshotEnvironmentBinding: " + userError)
 		return ctrl.Result{}, nil
 	}
 
@@ -192,15 +356,22 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 			return ctrl.Result{}, nil
 		}
 
-		var err error
-		expectedDeployments[component.Name], err = generateExpectedGitOpsDeployment(component, *binding, environment, log)
+		expectedDeployment, err := generateExpectedGitOpsDeployment(component, *binding, environment, log)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
 		}
+
+		if syncWave, exists := componentSyncWaves[component.Name]; exists {
+			expectedDeployment.Annotations = map[string]string{argosharedutil.ArgoCDSyncWaveAnnotation: strconv.Itoa(syncWave)}
+		}
+
+		configOverlay.applyToGitOpsDeployment(component.Name, &expectedDeployment)
+
+		expectedDeployments[component.Name] = expectedDeployment
 	}
 
 	// Delete any existing deployments which don't have a matching component
-	err := deleteUnmatchedDeployments(ctx, *binding, expectedDeployments, rClient, log)
+	err = deleteUnmatchedDeployments(ctx, *binding, expectedDeployments, rClient, log)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -208,57 +379,82 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 	var statusField []appstudioshared.BindingStatusGitOpsDeployment
 	var allErrors error
 
-	// For each deployment, check if it exists, and if it has the expected content.
+	// The components whose GitOpsDeployment is synced and healthy at the expected commit
+	var deployedComponents []string
+
+	// The number of components that are waiting for the components of a previous sync wave to be deployed
+	pendingComponents := 0
+	waitingForPreviousWave := false
+
+	// For each deployment, in order of sync wave, check if it exists, and if it has the expected content.
 	// - If not, create/update it.
-	for componentName, expectedGitOpsDeployment := range expectedDeployments {
+	// - The GitOpsDeployments of a wave are not processed until all the GitOpsDeployments of the previous waves are deployed:
+	//   since the binding owns the GitOpsDeployments, it will be reconciled again when their status changes.
+	for _, componentNames := range groupComponentsBySyncWave(expectedDeployments, componentSyncWaves) {
 
-		if err := processExpectedGitOpsDeployment(ctx, expectedGitOpsDeployment, *binding, rClient, log); err != nil {
+		if waitingForPreviousWave {
+			pendingComponents += len(componentNames)
+			continue
+		}
 
-			errorMessage := fmt.Sprintf("error occurred while processing expected GitOpsDeployment '%s' for SnapshotEnvironmentBinding",
-				expectedGitOpsDeployment.Name)
-			log.Error(err, errorMessage)
+		waveDeployed := true
 
-			// Combine all errors that occurred in the loop
-			if allErrors == nil {
-				allErrors = fmt.Errorf("%s, error: %w", errorMessage, err)
-			} else {
-				allErrors = fmt.Errorf("%s.\n%s, error: %w", allErrors.Error(), errorMessage, err)
-			}
-		} else {
-			// If no error, provide status
-			deployment := apibackend.GitOpsDeployment{}
-			newStatusEntry := appstudioshared.BindingStatusGitOpsDeployment{
-				ComponentName:    componentName,
-				GitOpsDeployment: expectedGitOpsDeployment.Name,
+		// The components of a wave are processed concurrently: the results are in the same order as componentNames.
+		for _, result := range processExpectedGitOpsDeploymentsOfWave(ctx, componentNames, expectedDeployments, *binding, rClient, log) {
+
+			if result.err != nil {
+				// Combine all errors that occurred in the loop
+				if allErrors == nil {
+					allErrors = result.err
+				} else {
+					allErrors = fmt.Errorf("%s.\n%w", allErrors.Error(), result.err)
+				}
+				waveDeployed = false
+				continue
 			}
-			// gosec check doesn't allow taking the address of a loop variable. Here, reassign the loop variable.
-			expectedGitOpsDeployment := expectedGitOpsDeployment
-			if err := rClient.Get(ctx, client.ObjectKeyFromObject(&expectedGitOpsDeployment), &deployment); err == nil {
-				newStatusEntry.GitOpsDeploymentSyncStatus = string(deployment.Status.Sync.Status)
-				newStatusEntry.GitOpsDeploymentHealthStatus = string(deployment.Status.Health.Status)
-				newStatusEntry.GitOpsDeploymentCommitID = deployment.Status.Sync.Revision
+
+			if !result.deployed {
+				waveDeployed = false
 			} else {
-				log.Error(err, "unable to get the deployment for "+componentName)
+				deployedComponents = append(deployedComponents, result.statusEntry.ComponentName)
 			}
-			statusField = append(statusField, newStatusEntry)
+			statusField = append(statusField, *result.statusEntry)
+		}
+
+		if !waveDeployed {
+			waitingForPreviousWave = true
 		}
 	}
 
+	if pendingComponents > 0 {
+		// The binding is reconciled again when the GitOpsDeployments of the previous sync wave are updated
+		logutil.LogRequeueReason(log, logutil.RequeueReasonWaitingForPreviousSyncWave, 0,
+			"Waiting for the components of a previous sync wave to be deployed", "pendingComponents", pendingComponents)
+	}
+
 	// Update the status field with statusField vars (even if an error occurred)
-	binding.Status.GitOpsDeployments = statusField
-	if err := addComponentDeploymentCondition(ctx, binding, rClient, log); err != nil {
+	desiredBinding := binding.DeepCopy()
+	desiredBinding.Status.GitOpsDeployments = statusField
+	if err := addComponentDeploymentCondition(ctx, desiredBinding, pendingComponents, rClient, log); err != nil {
 		log.Error(err, "unable to update component deployment condition for Binding "+binding.Name)
 		return ctrl.Result{}, fmt.Errorf("unable to update component deployment condition for SnapshotEnvironmentBinding. Error: %w", err)
 	}
 
-	// If our update logic did not modify the binding at all, there is no need to all update.
-	if reflect.DeepEqual(binding, originalBinding) {
-		log.V(logutil.LogLevel_Debug).Info("Skipping update of SnapshotEnvironmentBinding, as the resource did not change.")
-		return ctrl.Result{}, nil
-	}
+	if err := updateStatus(ctx, rClient, binding, func() bool {
+		// If our update logic did not modify the status at all, there is no need to update.
+		if reflect.DeepEqual(binding.Status.GitOpsDeployments, desiredBinding.Status.GitOpsDeployments) &&
+			reflect.DeepEqual(binding.Status.ComponentDeploymentConditions, desiredBinding.Status.ComponentDeploymentConditions) {
+
+			log.V(logutil.LogLevel_Debug).Info("Skipping update of SnapshotEnvironmentBinding, as the status did not change.")
+			return false
+		}
+
+		log.Info("Updating SnapshotEnvironmentBinding status")
+		binding.Status.GitOpsDeployments = desiredBinding.Status.GitOpsDeployments
+		binding.Status.ComponentDeploymentConditions = desiredBinding.Status.ComponentDeploymentConditions
+		return true
 
-	log.Info("Updating SnapshotEnvironmentBinding status")
-	if err := rClient.Status().Update(ctx, binding); err != nil {
+	}); err != nil {
 		if apierr.IsNotFound(err) {
 			return ctrl.Result{}, nil
 		}
@@ -267,6 +463,15 @@ func (r *SnapshotEnvironmentBindingReconciler) Reconcile(ctx context.Context, re
 		return ctrl.Result{}, fmt.Errorf("unable to update SnapshotEnvironmentBinding status. Error: %w", err)
 	}
 
+	if err := updateDeployedImagesOfBinding(ctx, binding, deployedComponents, rClient, log); err != nil {
+		if apierr.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		log.Error(err, "unable to update the deployed images of SnapshotEnvironmentBinding")
+		return ctrl.Result{}, fmt.Errorf("unable to update the deployed images of SnapshotEnvironmentBinding. Error: %w", err)
+	}
+
 	if allErrors != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, fmt.Errorf("unable to process expected GitOpsDeployment: %w", allErrors)
 	}
@@ -338,8 +543,10 @@ func deleteUnmatchedDeployments(ctx context.Context, binding appstudioshared.Sna
 	return nil
 }
 
-func addComponentDeploymentCondition(ctx context.Context, binding *appstudioshared.SnapshotEnvironmentBinding, c client.Client, log logr.Logger) error {
-	total := len(binding.Status.GitOpsDeployments)
+// addComponentDeploymentCondition sets the component deployment condition of the binding, based on the status of its GitOpsDeployments.
+// 'pendingComponents' is the number of components whose GitOpsDeployments are waiting for a previous sync wave to be deployed.
+func addComponentDeploymentCondition(ctx context.Context, binding *appstudioshared.SnapshotEnvironmentBinding, pendingComponents int, c client.Client, log logr.Logger) error {
+	total := len(binding.Status.GitOpsDeployments) + pendingComponents
 	synced := 0
 	for _, deploymentStatus := range binding.Status.GitOpsDeployments {
 		deployment := apibackend.GitOpsDeployment{}
@@ -383,6 +590,106 @@ func addComponentDeploymentCondition(ctx context.Context, binding *appstudioshar
 	return nil
 }
 
+// maxConcurrentComponentProcessing is the maximum number of components of a SnapshotEnvironmentBinding whose
+// GitOpsDeployments are created/updated concurrently.
+var maxConcurrentComponentProcessing = 8
+
+// componentProcessingResult is the result of processing the expected GitOpsDeployment of a single component.
+type componentProcessingResult struct {
+	// statusEntry is the status of the GitOpsDeployment of the component: nil if an error occurred.
+	statusEntry *appstudioshared.BindingStatusGitOpsDeployment
+
+	// deployed is true if the GitOpsDeployment is synced and healthy, at the expected revision.
+	deployed bool
+
+	err error
+}
+
+// processExpectedGitOpsDeploymentsOfWave creates/updates the expected GitOpsDeployments of the given components, with
+// at most maxConcurrentComponentProcessing components being processed at a time.
+//
+// An error (or panic) while processing a component does not prevent the other components from being processed: the
+// error is returned in the result of that component. The results are in the same order as 'componentNames'.
+func processExpectedGitOpsDeploymentsOfWave(ctx context.Context, componentNames []string, expectedDeployments map[string]apibackend.GitOpsDeployment,
+	binding appstudioshared.SnapshotEnvironmentBinding, k8sClient client.Client, log logr.Logger) []componentProcessingResult {
+
+	results := make([]componentProcessingResult, len(componentNames))
+
+	concurrency := maxConcurrentComponentProcessing
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	wg := sync.WaitGroup{}
+	for i := range componentNames {
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			componentName := componentNames[i]
+
+			var result componentProcessingResult
+			isPanic, err := sharedutil.CatchPanic(func() error {
+				result = processComponentOfBinding(ctx, componentName, expectedDeployments[componentName], binding, k8sClient, log)
+				return nil
+			})
+			if isPanic {
+				result = componentProcessingResult{
+					err: fmt.Errorf("error occurred while processing expected GitOpsDeployment '%s' for SnapshotEnvironmentBinding, error: %w",
+						expectedDeployments[componentName].Name, err),
+				}
+			}
+
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// processComponentOfBinding creates/updates the expected GitOpsDeployment of a component, and returns its status.
+func processComponentOfBinding(ctx context.Context, componentName string, expectedGitOpsDeployment apibackend.GitOpsDeployment,
+	binding appstudioshared.SnapshotEnvironmentBinding, k8sClient client.Client, log logr.Logger) componentProcessingResult {
+
+	if err := processExpectedGitOpsDeployment(ctx, expectedGitOpsDeployment, binding, k8sClient, log); err != nil {
+
+		errorMessage := fmt.Sprintf("error occurred while processing expected GitOpsDeployment '%s' for SnapshotEnvironmentBinding",
+			expectedGitOpsDeployment.Name)
+		log.Error(err, errorMessage)
+
+		return componentProcessingResult{err: fmt.Errorf("%s, error: %w", errorMessage, err)}
+	}
+
+	// If no error, provide status
+	result := componentProcessingResult{
+		statusEntry: &appstudioshared.BindingStatusGitOpsDeployment{
+			ComponentName:    componentName,
+			GitOpsDeployment: expectedGitOpsDeployment.Name,
+		},
+	}
+
+	deployment := apibackend.GitOpsDeployment{}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&expectedGitOpsDeployment), &deployment); err != nil {
+		log.Error(err, "unable to get the deployment for "+componentName)
+		return result
+	}
+
+	result.statusEntry.GitOpsDeploymentSyncStatus = string(deployment.Status.Sync.Status)
+	result.statusEntry.GitOpsDeploymentHealthStatus = string(deployment.Status.Health.Status)
+	result.statusEntry.GitOpsDeploymentCommitID = deployment.Status.Sync.Revision
+	result.deployed = isGitOpsDeploymentDeployed(deployment, expectedGitOpsDeployment, getCommitIDOfComponent(binding, componentName))
+
+	return result
+}
+
 const (
 	errDuplicateKeysFound     = "duplicate component keys found in status field"
 	errMissingTargetNamespace = "TargetNamespace field of Environment was empty"
@@ -412,9 +719,12 @@ func processExpectedGitOpsDeployment(ctx context.Context, expectedGitopsDeployme
 		return nil
 	}
 
+	expectedSyncWave := expectedGitopsDeployment.Annotations[argosharedutil.ArgoCDSyncWaveAnnotation]
+
 	// GitOpsDeployment already exists, so compare it with what we expect
 	if reflect.DeepEqual(expectedGitopsDeployment.Spec, actualGitOpsDeployment.Spec) &&
-		areAppStudioLabelsEqualBetweenMaps(expectedGitopsDeployment.ObjectMeta.Labels, actualGitOpsDeployment.ObjectMeta.Labels) {
+		areAppStudioLabelsEqualBetweenMaps(expectedGitopsDeployment.ObjectMeta.Labels, actualGitOpsDeployment.ObjectMeta.Labels) &&
+		expectedSyncWave == actualGitOpsDeployment.Annotations[argosharedutil.ArgoCDSyncWaveAnnotation] {
 		// B) The GitOpsDeployment is exactly as expected, so return
 		return nil
 	}
@@ -426,6 +736,16 @@ func processExpectedGitOpsDeployment(ctx context.Context, expectedGitopsDeployme
 	// not affecting any of the other user-added, non-appstudio labels on the GitOpDeployment
 	actualGitOpsDeployment.Labels = updateMapWithExpectedAppStudioLabels(actualGitOpsDeployment.Labels, expectedGitopsDeployment.Labels)
 
+	// Ensure the sync wave annotation matches the sync order of the binding, without affecting any other annotations
+	if expectedSyncWave != "" {
+		if actualGitOpsDeployment.Annotations == nil {
+			actualGitOpsDeployment.Annotations = map[string]string{}
+		}
+		actualGitOpsDeployment.Annotations[argosharedutil.ArgoCDSyncWaveAnnotation] = expectedSyncWave
+	} else {
+		delete(actualGitOpsDeployment.Annotations, argosharedutil.ArgoCDSyncWaveAnnotation)
+	}
+
 	if err := k8sClient.Update(ctx, &actualGitOpsDeployment); err != nil {
 		log.Error(err, "unable to update actualGitOpsDeployment: "+actualGitOpsDeployment.Name+" for Binding: "+binding.Name)
 		return fmt.Errorf("unable to update actualGitOpsDeployment '%s', for Binding:%s, Error: %w", actualGitOpsDeployment.Name, binding.Name, err)
@@ -537,6 +857,185 @@ func generateExpectedGitOpsDeployment(component appstudioshared.BindingComponent
 	return res, nil
 }
 
+// parseComponentSyncWaves parses the sync order annotation of the binding (see SnapshotEnvironmentBindingSyncOrderAnnotation),
+// returning a map of component name to sync wave. Returns an empty map if the annotation is not set.
+func parseComponentSyncWaves(binding appstudioshared.SnapshotEnvironmentBinding) (map[string]int, error) {
+
+	res := map[string]int{}
+
+	syncOrder, exists := binding.Annotations[SnapshotEnvironmentBindingSyncOrderAnnotation]
+	if !exists || strings.TrimSpace(syncOrder) == "" {
+		return res, nil
+	}
+
+	for _, entry := range strings.Split(syncOrder, ",") {
+
+		componentName, waveString, found := strings.Cut(strings.TrimSpace(entry), "=")
+		componentName = strings.TrimSpace(componentName)
+		if !found || componentName == "" {
+			return nil, fmt.Errorf("invalid entry '%s' in %s annotation: expected 'component=wave'", entry, SnapshotEnvironmentBindingSyncOrderAnnotation)
+		}
+
+		wave, err := strconv.Atoi(strings.TrimSpace(waveString))
+		if err != nil {
+			return nil, fmt.Errorf("invalid wave for component '%s' in %s annotation: expected an integer", componentName, SnapshotEnvironmentBindingSyncOrderAnnotation)
+		}
+
+		if _, exists := res[componentName]; exists {
+			return nil, fmt.Errorf("component '%s' is specified more than once in %s annotation", componentName, SnapshotEnvironmentBindingSyncOrderAnnotation)
+		}
+
+		res[componentName] = wave
+	}
+
+	return res, nil
+}
+
+// bindingConfigOverlay is the environment-specific configuration of the components of a binding: see
+// SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation and SnapshotEnvironmentBindingConfigOverlayPathAnnotation.
+type bindingConfigOverlay struct {
+	// path is relative to the GitOps repository path of each component (may be empty)
+	path string
+
+	// map: componentName (string) -> Helm values of that component, from the config overlay ConfigMap
+	componentHelmValues map[string]string
+}
+
+// getConfigOverlayOfBinding returns the config overlay referenced by the annotations of the binding. If the annotations
+// are invalid, or reference a ConfigMap that does not exist, a user error is returned, which should be reported on the binding.
+func getConfigOverlayOfBinding(ctx context.Context, binding appstudioshared.SnapshotEnvironmentBinding,
+	k8sClient client.Client) (bindingConfigOverlay, string, error) {
+
+	res := bindingConfigOverlay{
+		componentHelmValues: map[string]string{},
+	}
+
+	if overlayPath := strings.TrimSpace(binding.Annotations[SnapshotEnvironmentBindingConfigOverlayPathAnnotation]); overlayPath != "" {
+
+		// The path must remain within the GitOps repository path of the component
+		cleanPath := path.Clean(overlayPath)
+		if path.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+			return bindingConfigOverlay{}, fmt.Sprintf("invalid path '%s' in %s annotation: the path must be relative to the GitOps repository path of the components, and may not contain '..'",
+				overlayPath, SnapshotEnvironmentBindingConfigOverlayPathAnnotation), nil
+		}
+		res.path = cleanPath
+	}
+
+	configMapName := strings.TrimSpace(binding.Annotations[SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation])
+	if configMapName == "" {
+		return res, "", nil
+	}
+
+	configMap := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName,
+			Namespace: binding.Namespace,
+		},
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&configMap), &configMap); err != nil {
+		if apierr.IsNotFound(err) {
+			return bindingConfigOverlay{}, fmt.Sprintf("ConfigMap '%s' referenced by the %s annotation does not exist",
+				configMapName, SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation), nil
+		}
+		return bindingConfigOverlay{}, "", fmt.Errorf("unable to retrieve config overlay ConfigMap '%s': %w", configMapName, err)
+	}
+
+	for key, value := range configMap.Data {
+		if componentName := strings.TrimSuffix(key, configOverlayHelmValuesKeySuffix); componentName != key && componentName != "" {
+			res.componentHelmValues[componentName] = value
+		}
+	}
+
+	return res, "", nil
+}
+
+// applyToGitOpsDeployment renders the config overlay of the component into the source of its GitOpsDeployment
+func (overlay bindingConfigOverlay) applyToGitOpsDeployment(componentName string, deployment *apibackend.GitOpsDeployment) {
+
+	helm := apibackend.ApplicationSourceHelm{}
+
+	if overlay.path != "" {
+		if extension := path.Ext(overlay.path); extension == ".yaml" || extension == ".yml" {
+			// Helm value files are relative to the source path, which is the GitOps repository path of the component
+			helm.ValueFiles = []string{overlay.path}
+		} else {
+			deployment.Spec.Source.Path = path.Join(deployment.Spec.Source.Path, overlay.path)
+		}
+	}
+
+	if values, exists := overlay.componentHelmValues[componentName]; exists {
+		helm.Values = values
+	}
+
+	if len(helm.ValueFiles) > 0 || helm.Values != "" {
+		deployment.Spec.Source.Helm = &helm
+	}
+}
+
+// groupComponentsBySyncWave returns the names of the components of 'expectedDeployments', grouped by sync wave, in ascending
+// order of sync wave. Components without a sync wave are in wave 0. Within a wave, the components are sorted by name.
+func groupComponentsBySyncWave(expectedDeployments map[string]apibackend.GitOpsDeployment, componentSyncWaves map[string]int) [][]string {
+
+	componentsByWave := map[int][]string{}
+	for componentName := range expectedDeployments {
+		wave := componentSyncWaves[componentName]
+		componentsByWave[wave] = append(componentsByWave[wave], componentName)
+	}
+
+	waves := []int{}
+	for wave := range componentsByWave {
+		waves = append(waves, wave)
+	}
+	sort.Ints(waves)
+
+	res := [][]string{}
+	for _, wave := range waves {
+		componentNames := componentsByWave[wave]
+		sort.Strings(componentNames)
+		res = append(res, componentNames)
+	}
+
+	return res
+}
+
+// isGitOpsDeploymentDeployed returns true if Argo CD has reconciled the expected source of the GitOpsDeployment, the
+// expected revision has been synced, and the GitOpsDeployment is synced and healthy.
+//
+// Since a new Snapshot reuses the same GitOps repository path, the synced revision must be compared to determine whether
+// the content of the new Snapshot has been deployed:
+// - If the commit of the component is known (expectedCommitID), the synced revision must be that commit.
+// - Otherwise, the synced revision must be the commit that the target revision (branch) currently resolves to.
+// - If neither is known, the synced revision cannot be verified, and only the reconciled source is compared.
+func isGitOpsDeploymentDeployed(deployment apibackend.GitOpsDeployment, expectedDeployment apibackend.GitOpsDeployment, expectedCommitID string) bool {
+
+	if deployment.Status.Sync.Status != apibackend.SyncStatusCodeSynced ||
+		deployment.Status.Health.Status != apibackend.HeathStatusCodeHealthy ||
+		deployment.Status.ReconciledState.Source.RepoURL != expectedDeployment.Spec.Source.RepoURL ||
+		deployment.Status.ReconciledState.Source.Path != expectedDeployment.Spec.Source.Path {
+		return false
+	}
+
+	expectedRevision := expectedCommitID
+	if expectedRevision == "" {
+		expectedRevision = deployment.Status.ResolvedRevision
+	}
+
+	return expectedRevision == "" || deployment.Status.Sync.Revision == expectedRevision
+}
+
+// getCommitIDOfComponent returns the commit of the GitOps repository that contains the resources of the component, as
+// reported in the status of the binding, or "" if it is not known.
+func getCommitIDOfComponent(binding appstudioshared.SnapshotEnvironmentBinding, componentName string) string {
+
+	for _, component := range binding.Status.Components {
+		if component.Name == componentName {
+			return component.GitOpsRepository.CommitID
+		}
+	}
+
+	return ""
+}
+
 // Sets the given label on the given GitopsDeployment.  Returns an error if the length of the label value
 // is greater than the limit of 63 characters, else returns nil
 func setLabel(deployment *apibackend.GitOpsDeployment, key, value string) error {
@@ -548,13 +1047,477 @@ func setLabel(deployment *apibackend.GitOpsDeployment, key, value string) error
 	return nil
 }
 
+// handleGitOpsRepoWriteFailure reports the failure of application-service to write the changes of the binding to the
+// GitOps repository (as indicated by the last of the binding's GitOps repository conditions) in the GitOpsRepoWriteFailed
+// condition, and requests a retry of the write once the retry delay has elapsed.
+//
+// Returns the length of time until the next retry is due.
+func handleGitOpsRepoWriteFailure(ctx context.Context, binding *appstudioshared.SnapshotEnvironmentBinding,
+	k8sClient client.Client, log logr.Logger) (time.Duration, error) {
+
+	failedCondition := binding.Status.GitOpsRepoConditions[len(binding.Status.GitOpsRepoConditions)-1]
+
+	providerError := failedCondition.Message
+	if providerError == "" {
+		providerError = "unknown error (reason: '" + failedCondition.Reason + "')"
+	}
+
+	retries := getGitOpsRepoWriteRetries(*binding)
+	retryDelay := getGitOpsRepoWriteRetryDelay(*binding, retries)
+
+	message := fmt.Sprintf("Unable to write to the GitOps repository of SnapshotEnvironmentBinding '%s': %s. Retry %d of the write will be attempted after %v.",
+		binding.Name, providerError, retries+1, retryDelay)
+
+	if err := updateBindingConditionOfSEB(ctx, k8sClient, message, binding, SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed,
+		metav1.ConditionTrue, SnapshotEnvironmentBindingReasonGitOpsRepoWriteFailed, log); err != nil {
+		return 0, err
+	}
+
+	// The condition only transitions when its message changes, which includes the number of the retry: so the last
+	// transition time is the time at which the previous retry was requested (or at which the write first failed).
+	condition := meta.FindStatusCondition(binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed)
+	if condition == nil {
+		return 0, fmt.Errorf("condition '%s' was not found after it was updated", SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed)
+	}
+
+	if remaining := time.Until(condition.LastTransitionTime.Add(retryDelay)); remaining > 0 {
+		return remaining, nil
+	}
+
+	if binding.Annotations == nil {
+		binding.Annotations = map[string]string{}
+	}
+	binding.Annotations[SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation] = strconv.Itoa(retries + 1)
+
+	if err := k8sClient.Update(ctx, binding); err != nil {
+		return 0, fmt.Errorf("unable to request a retry of the write to the GitOps repository: %v", err)
+	}
+	log.Info("Requested a retry of the write to the GitOps repository of SnapshotEnvironmentBinding", "retry", retries+1)
+
+	return getGitOpsRepoWriteRetryDelay(*binding, retries+1), nil
+}
+
+// clearGitOpsRepoWriteFailure sets the GitOpsRepoWriteFailed condition to false (if it was previously set), and removes
+// the retry annotation from the binding, once application-service has successfully written to the GitOps repository.
+func clearGitOpsRepoWriteFailure(ctx context.Context, binding *appstudioshared.SnapshotEnvironmentBinding,
+	k8sClient client.Client, log logr.Logger) error {
+
+	if _, exists := binding.Annotations[SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation]; exists {
+		delete(binding.Annotations, SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation)
+
+		if err := k8sClient.Update(ctx, binding); err != nil {
+			return fmt.Errorf("unable to remove the GitOps repository write retry annotation: %v", err)
+		}
+	}
+
+	if meta.FindStatusCondition(binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed) == nil {
+		// The write has never failed, so there is nothing to report
+		return nil
+	}
+
+	return updateBindingConditionOfSEB(ctx, k8sClient, "", binding, SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed,
+		metav1.ConditionFalse, SnapshotEnvironmentBindingReasonGitOpsRepoWriteSucceeded, log)
+}
+
+// getGitOpsRepoWriteRetries returns the number of retries of the write to the GitOps repository that have been requested,
+// from the annotation of the binding.
+func getGitOpsRepoWriteRetries(binding appstudioshared.SnapshotEnvironmentBinding) int {
+
+	retries, err := strconv.Atoi(binding.Annotations[SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation])
+	if err != nil || retries < 0 {
+		return 0
+	}
+
+	return retries
+}
+
+// getGitOpsRepoWriteRetryDelay returns the delay before the given retry of the write to the GitOps repository: the delay
+// doubles on each retry, up to a maximum, plus up to 10% of jitter.
+//
+// The jitter spreads out the retries of bindings that failed at the same time (for example, during an outage of the Git
+// provider). It is derived from the UID of the binding, rather than chosen at random, so that the same delay (and thus
+// the same condition message) is calculated on each reconcile.
+func getGitOpsRepoWriteRetryDelay(binding appstudioshared.SnapshotEnvironmentBinding, retries int) time.Duration {
+
+	delay := gitOpsRepoWriteRetryMinDelay
+	for i := 0; i < retries && delay < gitOpsRepoWriteRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > gitOpsRepoWriteRetryMaxDelay {
+		delay = gitOpsRepoWriteRetryMaxDelay
+	}
+
+	hash := sha256.Sum256([]byte(string(binding.UID) + "/" + strconv.Itoa(retries)))
+	jitter := time.Duration(float64(delay) * 0.10 * (float64(hash[0]) / 255))
+
+	return (delay + jitter).Round(time.Second)
+}
+
+// runBindingPreflightChecks verifies that the ManagedEnvironment targeted by the Environment (if any) is connected, and
+// that the Snapshot of the binding contains a container image for each component of the binding. The result of each
+// check (and the EnvironmentAvailable condition, as the Environment exists) is reported as a condition of the binding:
+// the conditions are written in a single status update, and only if one of them changed.
+//
+// Returns true if all the checks passed.
+func runBindingPreflightChecks(ctx context.Context, binding *appstudioshared.SnapshotEnvironmentBinding,
+	environment appstudioshared.Environment, k8sClient client.Client, log logr.Logger) (bool, error) {
+
+	checksPassed := true
+
+	conditions := []metav1.Condition{{
+		Type:   SnapshotEnvironmentBindingConditionEnvironmentAvailable,
+		Status: metav1.ConditionTrue,
+		Reason: SnapshotEnvironmentBindingReasonEnvironmentFound,
+	}}
+
+	// Only Environments with cluster credentials are deployed via a GitOpsDeploymentManagedEnvironment: see generateExpectedGitOpsDeployment
+	targetsManagedEnvironment := environment.Spec.UnstableConfigurationFields != nil
+	if targetsManagedEnvironment {
+
+		status, reason, message, err := checkManagedEnvironmentConnected(ctx, environment, k8sClient)
+		if err != nil {
+			return false, err
+		}
+
+		conditions = append(conditions, metav1.Condition{
+			Type:    SnapshotEnvironmentBindingConditionManagedEnvironmentConnected,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		})
+
+		checksPassed = checksPassed && status == metav1.ConditionTrue
+	}
+
+	status, reason, message, err := checkSnapshotComponentsAvailable(ctx, *binding, k8sClient)
+	if err != nil {
+		return false, err
+	}
+
+	conditions = append(conditions, metav1.Condition{
+		Type:    SnapshotEnvironmentBindingConditionSnapshotComponentsAvailable,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+
+	checksPassed = checksPassed && status == metav1.ConditionTrue
+
+	updated := false
+	if err := updateStatus(ctx, k8sClient, binding, func() bool {
+
+		changed := false
+
+		for _, condition := range conditions {
+			conditionChanged, newConditions := insertOrUpdateConditionsInSlice(condition, binding.Status.BindingConditions)
+			binding.Status.BindingConditions = newConditions
+			changed = changed || conditionChanged
+		}
+
+		// If the Environment no longer targets a ManagedEnvironment, the condition no longer applies
+		if !targetsManagedEnvironment &&
+			meta.FindStatusCondition(binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionManagedEnvironmentConnected) != nil {
+
+			meta.RemoveStatusCondition(&binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionManagedEnvironmentConnected)
+			changed = true
+		}
+
+		updated = changed
+		return changed
+	}); err != nil {
+		log.Error(err, "unable to update the preflight conditions of SnapshotEnvironmentBinding")
+		return false, err
+	}
+
+	if updated {
+		log.Info("updated the preflight conditions of SnapshotEnvironmentBinding")
+	}
+
+	return checksPassed, nil
+}
+
+// checkManagedEnvironmentConnected returns the status, reason and message of the ManagedEnvironmentConnected condition,
+// based on the GitOpsDeploymentManagedEnvironment that is generated for the Environment by the Environment controller.
+func checkManagedEnvironmentConnected(ctx context.Context, environment appstudioshared.Environment,
+	k8sClient client.Client) (metav1.ConditionStatus, string, string, error) {
+
+	managedEnv := generateEmptyManagedEnvironment(environment.Name, environment.Namespace)
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv); err != nil {
+		if apierr.IsNotFound(err) {
+			return metav1.ConditionFalse, SnapshotEnvironmentBindingReasonManagedEnvironmentNotFound,
+				fmt.Sprintf("GitOpsDeploymentManagedEnvironment '%s' of Environment '%s' does not exist", managedEnv.Name, environment.Name), nil
+		}
+		return "", "", "", fmt.Errorf("unable to retrieve GitOpsDeploymentManagedEnvironment '%s': %w", managedEnv.Name, err)
+	}
+
+	for _, condition := range managedEnv.Status.Conditions {
+		if condition.Type != apibackend.ManagedEnvironmentStatusConnectionInitializationSucceeded {
+			continue
+		}
+
+		if condition.Status == metav1.ConditionTrue {
+			return metav1.ConditionTrue, SnapshotEnvironmentBindingReasonManagedEnvironmentConnected, "", nil
+		}
+
+		return metav1.ConditionFalse, SnapshotEnvironmentBindingReasonManagedEnvironmentNotConnected,
+			fmt.Sprintf("GitOpsDeploymentManagedEnvironment '%s' of Environment '%s' is not connected: %s", managedEnv.Name, environment.Name, condition.Message), nil
+	}
+
+	// The backend has not yet attempted to connect to the cluster
+	return metav1.ConditionFalse, SnapshotEnvironmentBindingReasonManagedEnvironmentNotConnected,
+		fmt.Sprintf("GitOpsDeploymentManagedEnvironment '%s' of Environment '%s' is not yet connected", managedEnv.Name, environment.Name), nil
+}
+
+// checkSnapshotComponentsAvailable returns the status, reason and message of the SnapshotComponentsAvailable condition.
+func checkSnapshotComponentsAvailable(ctx context.Context, binding appstudioshared.SnapshotEnvironmentBinding,
+	k8sClient client.Client) (metav1.ConditionStatus, string, string, error) {
+
+	snapshot := appstudioshared.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      binding.Spec.Snapshot,
+			Namespace: binding.Namespace,
+		},
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&snapshot), &snapshot); err != nil {
+		if apierr.IsNotFound(err) {
+			return metav1.ConditionFalse, SnapshotEnvironmentBindingReasonSnapshotNotFound,
+				fmt.Sprintf("Snapshot '%s' referenced by the SnapshotEnvironmentBinding does not exist", snapshot.Name), nil
+		}
+		return "", "", "", fmt.Errorf("unable to retrieve Snapshot '%s': %w", snapshot.Name, err)
+	}
+
+	// map: component name -> container image of that component in the Snapshot
+	snapshotImages := map[string]string{}
+	for _, snapshotComponent := range snapshot.Spec.Components {
+		snapshotImages[snapshotComponent.Name] = snapshotComponent.ContainerImage
+	}
+
+	missingComponents := []string{}
+	for _, component := range binding.Spec.Components {
+		if snapshotImages[component.Name] == "" {
+			missingComponents = append(missingComponents, component.Name)
+		}
+	}
+
+	if len(missingComponents) > 0 {
+		return metav1.ConditionFalse, SnapshotEnvironmentBindingReasonSnapshotComponentsMissing,
+			fmt.Sprintf("Snapshot '%s' does not contain a container image for components: %s", snapshot.Name, strings.Join(missingComponents, ", ")), nil
+	}
+
+	return metav1.ConditionTrue, SnapshotEnvironmentBindingReasonSnapshotComponentsAvailable, "", nil
+}
+
+// getSnapshotImageDigests returns the digest-pinned container images of the components in the Snapshot of the binding.
+// Components whose container image is not pinned to a digest (for example, a tag reference) are not included.
+func getSnapshotImageDigests(ctx context.Context, binding appstudioshared.SnapshotEnvironmentBinding,
+	k8sClient client.Client) (map[string]string, error) {
+
+	snapshot := appstudioshared.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      binding.Spec.Snapshot,
+			Namespace: binding.Namespace,
+		},
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&snapshot), &snapshot); err != nil {
+		return nil, fmt.Errorf("unable to retrieve Snapshot '%s': %w", snapshot.Name, err)
+	}
+
+	// map: component name -> digest-pinned container image of that component in the Snapshot
+	res := map[string]string{}
+	for _, snapshotComponent := range snapshot.Spec.Components {
+		if isImagePinnedToDigest(snapshotComponent.ContainerImage) {
+			res[snapshotComponent.Name] = snapshotComponent.ContainerImage
+		}
+	}
+
+	return res, nil
+}
+
+// isImagePinnedToDigest returns true if the container image reference is of the form '(name)@(algorithm):(digest)'
+func isImagePinnedToDigest(image string) bool {
+	index := strings.LastIndex(image, "@")
+	if index <= 0 {
+		return false
+	}
+
+	algorithm, digest, found := strings.Cut(image[index+1:], ":")
+	return found && algorithm != "" && digest != ""
+}
+
+// parseDeployedImages parses the deployed images annotation of the binding (see SnapshotEnvironmentBindingDeployedImagesAnnotation)
+// into a map of component name -> deployed container image.
+func parseDeployedImages(binding appstudioshared.SnapshotEnvironmentBinding) map[string]string {
+
+	res := map[string]string{}
+
+	for _, entry := range strings.Split(binding.Annotations[SnapshotEnvironmentBindingDeployedImagesAnnotation], ",") {
+		componentName, image, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || componentName == "" || image == "" {
+			continue
+		}
+		res[componentName] = image
+	}
+
+	return res
+}
+
+// formatDeployedImages is the inverse of parseDeployedImages: the entries are sorted by component name, so that the
+// value of the annotation only changes when the deployed images change.
+func formatDeployedImages(deployedImages map[string]string) string {
+
+	componentNames := make([]string, 0, len(deployedImages))
+	for componentName := range deployedImages {
+		componentNames = append(componentNames, componentName)
+	}
+	sort.Strings(componentNames)
+
+	entries := make([]string, 0, len(componentNames))
+	for _, componentName := range componentNames {
+		entries = append(entries, componentName+"="+deployedImages[componentName])
+	}
+
+	return strings.Join(entries, ",")
+}
+
+// updateDeployedImagesOfBinding records the container images of the Snapshot that are deployed by the given components
+// in the deployed images annotation of the binding, and removes the entries of components that are no longer in the binding.
+func updateDeployedImagesOfBinding(ctx context.Context, binding *appstudioshared.SnapshotEnvironmentBinding,
+	deployedComponents []string, k8sClient client.Client, log logr.Logger) error {
+
+	deployedImages := parseDeployedImages(*binding)
+
+	// Remove the components that are no longer part of the binding
+	bindingComponents := map[string]bool{}
+	for _, component := range binding.Status.Components {
+		bindingComponents[component.Name] = true
+	}
+	for componentName := range deployedImages {
+		if !bindingComponents[componentName] {
+			delete(deployedImages, componentName)
+		}
+	}
+
+	if len(deployedComponents) > 0 {
+		snapshotImages, err := getSnapshotImageDigests(ctx, *binding, k8sClient)
+		if err != nil {
+			return err
+		}
+
+		for _, componentName := range deployedComponents {
+			if image, exists := snapshotImages[componentName]; exists {
+				deployedImages[componentName] = image
+			} else {
+				// The image that is now deployed is not pinned to a digest, so the previous entry is no longer accurate
+				delete(deployedImages, componentName)
+			}
+		}
+	}
+
+	newValue := formatDeployedImages(deployedImages)
+	if newValue == binding.Annotations[SnapshotEnvironmentBindingDeployedImagesAnnotation] {
+		return nil
+	}
+
+	if newValue == "" {
+		delete(binding.Annotations, SnapshotEnvironmentBindingDeployedImagesAnnotation)
+	} else {
+		if binding.Annotations == nil {
+			binding.Annotations = map[string]string{}
+		}
+		binding.Annotations[SnapshotEnvironmentBindingDeployedImagesAnnotation] = newValue
+	}
+
+	if err := k8sClient.Update(ctx, binding); err != nil {
+		return err
+	}
+	log.Info("Updated the deployed images of SnapshotEnvironmentBinding", "deployedImages", newValue)
+
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *SnapshotEnvironmentBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		Named(sharedutil.ControllerNameSnapshotEnvironmentBinding).
 		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
 		For(&appstudioshared.SnapshotEnvironmentBinding{}).
 		Owns(&apibackend.GitOpsDeployment{}).
-		Complete(r)
+		// The Environment, ManagedEnvironment and Snapshot of a binding are watched to re-run its preflight checks
+		Watches(
+			&source.Kind{Type: &appstudioshared.Environment{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForEnvironment),
+		).
+		Watches(
+			&source.Kind{Type: &apibackend.GitOpsDeploymentManagedEnvironment{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForManagedEnvironment),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&source.Kind{Type: &appstudioshared.Snapshot{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSnapshot),
+		).
+		// The config overlay ConfigMap of a binding is watched to re-render the GitOpsDeployments of the binding
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForConfigMap),
+		).
+		Complete(sharedutil.NewRequeueMetricsMiddleware(sharedutil.ControllerNameSnapshotEnvironmentBinding, sharedutil.NewReconcileTimingMiddleware("SnapshotEnvironmentBinding", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &appstudioshared.SnapshotEnvironmentBinding{} }, r))))
+}
+
+// findObjectsForEnvironment maps an Environment to the SnapshotEnvironmentBindings that target it
+func (r *SnapshotEnvironmentBindingReconciler) findObjectsForEnvironment(env client.Object) []reconcile.Request {
+	return r.findBindingsInNamespace(env.GetNamespace(), func(binding appstudioshared.SnapshotEnvironmentBinding) bool {
+		return binding.Spec.Environment == env.GetName()
+	})
+}
+
+// findObjectsForManagedEnvironment maps a GitOpsDeploymentManagedEnvironment to the SnapshotEnvironmentBindings that
+// target the Environment it was generated for
+func (r *SnapshotEnvironmentBindingReconciler) findObjectsForManagedEnvironment(managedEnv client.Object) []reconcile.Request {
+	return r.findBindingsInNamespace(managedEnv.GetNamespace(), func(binding appstudioshared.SnapshotEnvironmentBinding) bool {
+		return generateEmptyManagedEnvironment(binding.Spec.Environment, binding.Namespace).Name == managedEnv.GetName()
+	})
+}
+
+// findObjectsForSnapshot maps a Snapshot to the SnapshotEnvironmentBindings that reference it
+func (r *SnapshotEnvironmentBindingReconciler) findObjectsForSnapshot(snapshot client.Object) []reconcile.Request {
+	return r.findBindingsInNamespace(snapshot.GetNamespace(), func(binding appstudioshared.SnapshotEnvironmentBinding) bool {
+		return binding.Spec.Snapshot == snapshot.GetName()
+	})
+}
+
+// findObjectsForConfigMap maps a ConfigMap to the SnapshotEnvironmentBindings that reference it as their config overlay
+func (r *SnapshotEnvironmentBindingReconciler) findObjectsForConfigMap(configMap client.Object) []reconcile.Request {
+	return r.findBindingsInNamespace(configMap.GetNamespace(), func(binding appstudioshared.SnapshotEnvironmentBinding) bool {
+		return strings.TrimSpace(binding.Annotations[SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation]) == configMap.GetName()
+	})
+}
+
+// findBindingsInNamespace returns a request for each SnapshotEnvironmentBinding of the namespace that matches the filter
+func (r *SnapshotEnvironmentBindingReconciler) findBindingsInNamespace(namespace string,
+	filter func(binding appstudioshared.SnapshotEnvironmentBinding) bool) []reconcile.Request {
+
+	ctx := context.Background()
+	handlerLog := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops)
+
+	bindingList := appstudioshared.SnapshotEnvironmentBindingList{}
+	if err := r.List(ctx, &bindingList, &client.ListOptions{Namespace: namespace}); err != nil {
+		handlerLog.Error(err, "failed to list SnapshotEnvironmentBindings in the SnapshotEnvironmentBinding mapping function")
+		return []reconcile.Request{}
+	}
+
+	bindingRequests := []reconcile.Request{}
+	for i := range bindingList.Items {
+		binding := bindingList.Items[i]
+		if filter(binding) {
+			bindingRequests = append(bindingRequests, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(&binding),
+			})
+		}
+	}
+
+	return bindingRequests
 }
 
 // updateMapWithExpectedAppStudioLabels ensures that the appstudio labels in the generated GitOpsDeployment are the same as defined in
@@ -665,15 +1628,18 @@ func updateBindingConditionOfSEB(ctx context.Context, client client.Client, mess
 		Reason:  reason,
 	}
 
-	changed, newConditions := insertOrUpdateConditionsInSlice(newCondition, binding.Status.BindingConditions)
-
-	if changed {
+	updated := false
+	if err := updateStatus(ctx, client, binding, func() bool {
+		changed, newConditions := insertOrUpdateConditionsInSlice(newCondition, binding.Status.BindingConditions)
 		binding.Status.BindingConditions = newConditions
+		updated = changed
+		return changed
+	}); err != nil {
+		log.Error(err, "unable to update .status.bindingCondition of SEB")
+		return err
+	}
 
-		if err := client.Status().Update(ctx, binding); err != nil {
-			log.Error(err, "unable to update .status.bindingCondition of SEB")
-			return err
-		}
+	if updated {
 		log.Info("updated .status.bindingCondition of SnapshotEnvironmentBinding")
 	}
 
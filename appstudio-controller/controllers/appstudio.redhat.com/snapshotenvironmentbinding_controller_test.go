@@ -12,6 +12,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,6 +21,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 
 	appstudiosharedv1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	apibackend "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
@@ -72,6 +74,23 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 			err = k8sClient.Create(ctx, &environment)
 			Expect(err).To(BeNil())
 
+			// Create the Snapshot referenced by the binding
+			snapshot := appstudiosharedv1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-snapshot",
+					Namespace: apiNamespace.Name,
+				},
+				Spec: appstudiosharedv1.SnapshotSpec{
+					Application: "new-demo-app",
+					Components: []appstudiosharedv1.SnapshotComponent{
+						{Name: "component-a", ContainerImage: "quay.io/my-org/component-a:latest"},
+						{Name: "component-b", ContainerImage: "quay.io/my-org/component-b:latest"},
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, &snapshot)
+			Expect(err).To(BeNil())
+
 			bindingReconciler = SnapshotEnvironmentBindingReconciler{Client: k8sClient, Scheme: scheme}
 
 			// Create SnapshotEnvironmentBinding CR.
@@ -470,10 +489,12 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 
 		})
 
-		It("Should return error if Status.GitOpsRepoConditions Status is set to False in Binding object.", func() {
+		It("Should report a GitOpsRepoWriteFailed condition if Status.GitOpsRepoConditions Status is set to False in Binding object.", func() {
 			binding.Status.GitOpsRepoConditions = []metav1.Condition{
 				{
-					Status: metav1.ConditionFalse,
+					Status:  metav1.ConditionFalse,
+					Reason:  "GitOpsRepoPushFailed",
+					Message: "authentication required",
 				},
 			}
 
@@ -482,10 +503,96 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 			Expect(err).To(BeNil())
 
 			// Trigger Reconciler
+			res, err := bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			By("verifying the reconcile is requeued once the retry is due")
+			retryDelay := getGitOpsRepoWriteRetryDelay(*binding, 0)
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(res.RequeueAfter).To(BeNumerically("<=", retryDelay))
+
+			By("verifying the condition contains the error of the Git provider, and when the write will be retried")
+			condition := checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding,
+				SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed, metav1.ConditionTrue, SnapshotEnvironmentBindingReasonGitOpsRepoWriteFailed)
+			Expect(condition.Message).To(Equal(fmt.Sprintf("Unable to write to the GitOps repository of SnapshotEnvironmentBinding "+
+				"'appa-staging-binding': authentication required. Retry 1 of the write will be attempted after %v.", retryDelay)))
+
+			Expect(meta.FindStatusCondition(binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionErrorOccurred)).To(BeNil())
+			Expect(binding.Annotations).ToNot(HaveKey(SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation))
+		})
+
+		It("Should request a retry of the write to the GitOps repository, once the retry delay has elapsed", func() {
+			binding.Status.GitOpsRepoConditions = []metav1.Condition{
+				{
+					Status:  metav1.ConditionFalse,
+					Message: "merge conflict",
+				},
+			}
+
+			err := bindingReconciler.Create(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			By("simulating the passage of time, by moving the last transition time of the condition into the past")
+			err = bindingReconciler.Get(ctx, client.ObjectKeyFromObject(binding), binding)
+			Expect(err).To(BeNil())
+			for i := range binding.Status.BindingConditions {
+				if binding.Status.BindingConditions[i].Type == SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed {
+					binding.Status.BindingConditions[i].LastTransitionTime = metav1.NewTime(time.Now().Add(-gitOpsRepoWriteRetryMaxDelay * 2))
+				}
+			}
+			err = bindingReconciler.Status().Update(ctx, binding)
+			Expect(err).To(BeNil())
+
+			res, err := bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(Equal(getGitOpsRepoWriteRetryDelay(*binding, 1)))
+
+			By("verifying the retry was requested via the annotation of the binding")
+			err = bindingReconciler.Get(ctx, client.ObjectKeyFromObject(binding), binding)
+			Expect(err).To(BeNil())
+			Expect(binding.Annotations[SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation]).To(Equal("1"))
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			condition := checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding,
+				SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed, metav1.ConditionTrue, SnapshotEnvironmentBindingReasonGitOpsRepoWriteFailed)
+			Expect(condition.Message).To(ContainSubstring("merge conflict. Retry 2 of the write"))
+
+			By("verifying the failure is cleared, once the write succeeds")
+			binding.Status.GitOpsRepoConditions = []metav1.Condition{
+				{
+					Status: metav1.ConditionTrue,
+				},
+			}
+			err = bindingReconciler.Status().Update(ctx, binding)
+			Expect(err).To(BeNil())
+
 			_, err = bindingReconciler.Reconcile(ctx, request)
 			Expect(err).To(BeNil())
 
-			checkStatusConditionOfEnvironmentBinding(ctx, bindingReconciler.Client, binding, "Can not Reconcile Binding 'appa-staging-binding', since GitOps Repo Conditions status is false.", metav1.ConditionTrue)
+			checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding,
+				SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed, metav1.ConditionFalse, SnapshotEnvironmentBindingReasonGitOpsRepoWriteSucceeded)
+			Expect(binding.Annotations).ToNot(HaveKey(SnapshotEnvironmentBindingGitOpsRepoWriteRetryAnnotation))
+		})
+
+		It("should increase the delay between retries of the write to the GitOps repository, up to a maximum", func() {
+			binding.UID = "test-binding-uid"
+
+			previousDelay := time.Duration(0)
+			for retries := 0; retries < 5; retries++ {
+				delay := getGitOpsRepoWriteRetryDelay(*binding, retries)
+				Expect(delay).To(BeNumerically(">", previousDelay))
+				Expect(delay).To(Equal(getGitOpsRepoWriteRetryDelay(*binding, retries)), "the delay should be deterministic")
+				previousDelay = delay
+			}
+
+			delay := getGitOpsRepoWriteRetryDelay(*binding, 100)
+			Expect(delay).To(BeNumerically(">=", gitOpsRepoWriteRetryMaxDelay))
+			Expect(delay).To(BeNumerically("<=", gitOpsRepoWriteRetryMaxDelay+gitOpsRepoWriteRetryMaxDelay/10+time.Second))
 		})
 
 		It("should update the previous binding condition status if there is no longer a repo condition error", func() {
@@ -504,7 +611,8 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 			_, err = bindingReconciler.Reconcile(ctx, request)
 			Expect(err).To(BeNil())
 
-			checkStatusConditionOfEnvironmentBinding(ctx, bindingReconciler.Client, binding, "Can not Reconcile Binding 'appa-staging-binding', since GitOps Repo Conditions status is false.", metav1.ConditionTrue)
+			checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding,
+				SnapshotEnvironmentBindingConditionGitOpsRepoWriteFailed, metav1.ConditionTrue, SnapshotEnvironmentBindingReasonGitOpsRepoWriteFailed)
 
 			By("update the repo condition and verify if the binding condition is updated")
 			binding.Status.GitOpsRepoConditions = []metav1.Condition{
@@ -561,6 +669,178 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 
 		})
 
+		It("should set an error condition if the sync order annotation of the binding is invalid", func() {
+
+			By("creating an SnapshotEnvironmentBinding with an invalid sync order annotation")
+			binding.Annotations = map[string]string{SnapshotEnvironmentBindingSyncOrderAnnotation: "component-a=first"}
+
+			err := bindingReconciler.Create(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			checkStatusConditionOfEnvironmentBinding(ctx, bindingReconciler.Client, binding,
+				"invalid wave for component 'component-a' in "+SnapshotEnvironmentBindingSyncOrderAnnotation+" annotation: expected an integer", metav1.ConditionTrue)
+
+			By("verifying no GitOpsDeployment was created")
+			gitopsDeployment := &apibackend.GitOpsDeployment{}
+			err = bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: GenerateBindingGitOpsDeploymentName(*binding, "component-a")}, gitopsDeployment)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should only create the GitOpsDeployments of a sync wave once those of the previous waves are deployed", func() {
+
+			By("creating an SnapshotEnvironmentBinding with component-a in wave 0, and component-b in wave 1")
+			binding.Annotations = map[string]string{SnapshotEnvironmentBindingSyncOrderAnnotation: "component-b=1"}
+			binding.Status.Components = append(binding.Status.Components, appstudiosharedv1.BindingComponentStatus{
+				Name: "component-b",
+				GitOpsRepository: appstudiosharedv1.BindingComponentGitOpsRepository{
+					URL:    "https://github.com/redhat-appstudio/managed-gitops",
+					Branch: "main",
+					Path:   "resources/test-data/sample-gitops-repository/components/componentB/overlays/staging",
+				},
+			})
+
+			err := bindingReconciler.Create(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			By("verifying only the GitOpsDeployment of wave 0 was created")
+			gitopsDeploymentA := &apibackend.GitOpsDeployment{}
+			err = bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: GenerateBindingGitOpsDeploymentName(*binding, "component-a")}, gitopsDeploymentA)
+			Expect(err).To(BeNil())
+			_, exists := gitopsDeploymentA.Annotations[argosharedutil.ArgoCDSyncWaveAnnotation]
+			Expect(exists).To(BeFalse(), "components that are not in the sync order annotation should not have a sync wave")
+
+			gitopsDeploymentB := &apibackend.GitOpsDeployment{}
+			gitopsDeploymentBKey := types.NamespacedName{Namespace: binding.Namespace, Name: GenerateBindingGitOpsDeploymentName(*binding, "component-b")}
+			err = bindingReconciler.Get(ctx, gitopsDeploymentBKey, gitopsDeploymentB)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			err = bindingReconciler.Get(ctx, client.ObjectKeyFromObject(binding), binding)
+			Expect(err).To(BeNil())
+			Expect(binding.Status.GitOpsDeployments).To(HaveLen(1))
+			Expect(binding.Status.ComponentDeploymentConditions).To(HaveLen(1))
+			Expect(binding.Status.ComponentDeploymentConditions[0].Message).To(Equal("0 of 2 components deployed"))
+
+			By("simulating Argo CD reporting a previously deployed revision of the GitOpsDeployment of wave 0")
+			gitopsDeploymentA.Status.Sync.Status = apibackend.SyncStatusCodeSynced
+			gitopsDeploymentA.Status.Health.Status = apibackend.HeathStatusCodeHealthy
+			gitopsDeploymentA.Status.ReconciledState.Source.RepoURL = gitopsDeploymentA.Spec.Source.RepoURL
+			gitopsDeploymentA.Status.ReconciledState.Source.Path = gitopsDeploymentA.Spec.Source.Path
+			gitopsDeploymentA.Status.Sync.Revision = "previous-commit"
+			gitopsDeploymentA.Status.ResolvedRevision = "new-commit"
+			err = bindingReconciler.Status().Update(ctx, gitopsDeploymentA)
+			Expect(err).To(BeNil())
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			By("verifying the GitOpsDeployment of wave 1 was not created, as the new revision of wave 0 is not yet deployed")
+			err = bindingReconciler.Get(ctx, gitopsDeploymentBKey, gitopsDeploymentB)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			By("simulating Argo CD deploying the new revision of the GitOpsDeployment of wave 0")
+			err = bindingReconciler.Get(ctx, client.ObjectKeyFromObject(gitopsDeploymentA), gitopsDeploymentA)
+			Expect(err).To(BeNil())
+			gitopsDeploymentA.Status.Sync.Revision = "new-commit"
+			err = bindingReconciler.Status().Update(ctx, gitopsDeploymentA)
+			Expect(err).To(BeNil())
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			By("verifying the GitOpsDeployment of wave 1 was created, with its sync wave")
+			err = bindingReconciler.Get(ctx, gitopsDeploymentBKey, gitopsDeploymentB)
+			Expect(err).To(BeNil())
+			Expect(gitopsDeploymentB.Annotations[argosharedutil.ArgoCDSyncWaveAnnotation]).To(Equal("1"))
+
+			err = bindingReconciler.Get(ctx, client.ObjectKeyFromObject(binding), binding)
+			Expect(err).To(BeNil())
+			Expect(binding.Status.GitOpsDeployments).To(HaveLen(2))
+		})
+
+		It("should process the components of a binding concurrently, and report their status in order of component name", func() {
+
+			originalMaxConcurrency := maxConcurrentComponentProcessing
+			maxConcurrentComponentProcessing = 3
+			DeferCleanup(func() {
+				maxConcurrentComponentProcessing = originalMaxConcurrency
+			})
+
+			By("creating a SnapshotEnvironmentBinding with more components than the maximum concurrency")
+			for i := 0; i < 10; i++ {
+				binding.Status.Components = append(binding.Status.Components, appstudiosharedv1.BindingComponentStatus{
+					Name: fmt.Sprintf("component-z%d", i),
+					GitOpsRepository: appstudiosharedv1.BindingComponentGitOpsRepository{
+						URL:    "https://github.com/redhat-appstudio/managed-gitops",
+						Branch: "main",
+						Path:   "resources/test-data/sample-gitops-repository/components/componentA/overlays/staging",
+					},
+				})
+			}
+
+			err := bindingReconciler.Create(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			err = bindingReconciler.Get(ctx, client.ObjectKeyFromObject(binding), binding)
+			Expect(err).To(BeNil())
+			Expect(binding.Status.GitOpsDeployments).To(HaveLen(11))
+
+			for i, deploymentStatus := range binding.Status.GitOpsDeployments {
+				if i > 0 {
+					Expect(deploymentStatus.ComponentName > binding.Status.GitOpsDeployments[i-1].ComponentName).To(BeTrue())
+				}
+
+				gitopsDeployment := &apibackend.GitOpsDeployment{}
+				err = bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: deploymentStatus.GitOpsDeployment}, gitopsDeployment)
+				Expect(err).To(BeNil())
+			}
+		})
+
+		It("should continue processing the other components of a wave, if an error occurs processing one of them", func() {
+
+			expectedDeployments := map[string]apibackend.GitOpsDeployment{}
+			for _, componentName := range []string{"component-a", "component-b", "component-c"} {
+				expectedDeployments[componentName] = apibackend.GitOpsDeployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      GenerateBindingGitOpsDeploymentName(*binding, componentName),
+						Namespace: binding.Namespace,
+					},
+				}
+			}
+
+			By("using an invalid (empty) name for the GitOpsDeployment of component-b, so that it cannot be created")
+			invalidDeployment := expectedDeployments["component-b"]
+			invalidDeployment.Name = ""
+			expectedDeployments["component-b"] = invalidDeployment
+
+			results := processExpectedGitOpsDeploymentsOfWave(ctx, []string{"component-a", "component-b", "component-c"},
+				expectedDeployments, *binding, bindingReconciler.Client, log.FromContext(ctx))
+			Expect(results).To(HaveLen(3))
+
+			Expect(results[0].err).To(BeNil())
+			Expect(results[0].statusEntry.ComponentName).To(Equal("component-a"))
+
+			Expect(results[1].err).ToNot(BeNil())
+			Expect(results[1].statusEntry).To(BeNil())
+
+			Expect(results[2].err).To(BeNil())
+			Expect(results[2].statusEntry.ComponentName).To(Equal("component-c"))
+
+			for _, componentName := range []string{"component-a", "component-c"} {
+				gitopsDeployment := &apibackend.GitOpsDeployment{}
+				err := bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: expectedDeployments[componentName].Name}, gitopsDeployment)
+				Expect(err).To(BeNil())
+			}
+		})
+
 		It("should verify that if the Environment contains configuration information, that it is included in the generate GitOpsDeployment", func() {
 
 			By("creating an Environment with valid configuration fields")
@@ -574,6 +854,9 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 			err := bindingReconciler.Client.Update(ctx, &environment)
 			Expect(err).To(BeNil())
 
+			By("creating the connected ManagedEnvironment of the Environment")
+			createManagedEnvironmentOfEnvironment(ctx, bindingReconciler.Client, environment, metav1.ConditionTrue)
+
 			By("creating default Binding")
 			err = bindingReconciler.Client.Create(ctx, binding)
 			Expect(err).To(BeNil())
@@ -626,6 +909,293 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 
 		})
 
+		Context("Config overlays", func() {
+
+			getGitOpsDeploymentOfComponentA := func() *apibackend.GitOpsDeployment {
+				gitopsDeployment := &apibackend.GitOpsDeployment{}
+				err := bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace,
+					Name: GenerateBindingGitOpsDeploymentName(*binding, "component-a")}, gitopsDeployment)
+				Expect(err).To(BeNil())
+				return gitopsDeployment
+			}
+
+			It("should render the Helm values of the config overlay ConfigMap into the GitOpsDeployment, and update it when the ConfigMap changes", func() {
+
+				By("creating a ConfigMap with Helm values for component-a, and a binding that references it")
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "staging-overlay",
+						Namespace: binding.Namespace,
+					},
+					Data: map[string]string{
+						"component-a.values.yaml": "replicaCount: 3\n",
+						"component-b.values.yaml": "replicaCount: 1\n",
+					},
+				}
+				err := bindingReconciler.Create(ctx, configMap)
+				Expect(err).To(BeNil())
+
+				binding.Annotations = map[string]string{SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation: configMap.Name}
+				err = bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				gitopsDeployment := getGitOpsDeploymentOfComponentA()
+				Expect(gitopsDeployment.Spec.Source.Helm).ToNot(BeNil())
+				Expect(gitopsDeployment.Spec.Source.Helm.Values).To(Equal("replicaCount: 3\n"))
+				Expect(gitopsDeployment.Spec.Source.Path).To(Equal(binding.Status.Components[0].GitOpsRepository.Path))
+
+				By("verifying a change of the ConfigMap is mapped to the binding")
+				Expect(bindingReconciler.findObjectsForConfigMap(configMap)).To(Equal([]reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(binding)}}))
+
+				By("updating the Helm values of component-a, and verifying the GitOpsDeployment is updated")
+				configMap.Data["component-a.values.yaml"] = "replicaCount: 5\n"
+				err = bindingReconciler.Update(ctx, configMap)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				gitopsDeployment = getGitOpsDeploymentOfComponentA()
+				Expect(gitopsDeployment.Spec.Source.Helm.Values).To(Equal("replicaCount: 5\n"))
+			})
+
+			It("should set an error condition, and not generate any GitOpsDeployments, if the config overlay ConfigMap does not exist", func() {
+
+				binding.Annotations = map[string]string{SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation: "missing-configmap"}
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				checkStatusConditionOfEnvironmentBinding(ctx, bindingReconciler.Client, binding,
+					"ConfigMap 'missing-configmap' referenced by the "+SnapshotEnvironmentBindingConfigOverlayConfigMapAnnotation+" annotation does not exist", metav1.ConditionTrue)
+
+				gitopsDeployment := &apibackend.GitOpsDeployment{}
+				err = bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: GenerateBindingGitOpsDeploymentName(*binding, "component-a")}, gitopsDeployment)
+				Expect(apierr.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should deploy the Kustomize overlay directory of the config overlay path, relative to the path of the component", func() {
+
+				binding.Annotations = map[string]string{SnapshotEnvironmentBindingConfigOverlayPathAnnotation: "env-config/"}
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				gitopsDeployment := getGitOpsDeploymentOfComponentA()
+				Expect(gitopsDeployment.Spec.Source.Path).To(Equal(binding.Status.Components[0].GitOpsRepository.Path + "/env-config"))
+				Expect(gitopsDeployment.Spec.Source.Helm).To(BeNil())
+			})
+
+			It("should use a config overlay path that is a YAML file as a Helm value file", func() {
+
+				binding.Annotations = map[string]string{SnapshotEnvironmentBindingConfigOverlayPathAnnotation: "values-staging.yaml"}
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				gitopsDeployment := getGitOpsDeploymentOfComponentA()
+				Expect(gitopsDeployment.Spec.Source.Path).To(Equal(binding.Status.Components[0].GitOpsRepository.Path))
+				Expect(gitopsDeployment.Spec.Source.Helm).ToNot(BeNil())
+				Expect(gitopsDeployment.Spec.Source.Helm.ValueFiles).To(Equal([]string{"values-staging.yaml"}))
+			})
+
+			It("should set an error condition if the config overlay path is outside of the path of the components", func() {
+
+				binding.Annotations = map[string]string{SnapshotEnvironmentBindingConfigOverlayPathAnnotation: "../production"}
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				checkStatusConditionOfEnvironmentBinding(ctx, bindingReconciler.Client, binding,
+					"invalid path '../production' in "+SnapshotEnvironmentBindingConfigOverlayPathAnnotation+" annotation: the path must be relative to the GitOps repository path of the components, and may not contain '..'", metav1.ConditionTrue)
+			})
+		})
+
+		Context("Preflight checks", func() {
+
+			expectNoGitOpsDeployment := func() {
+				gitopsDeployment := &apibackend.GitOpsDeployment{}
+				err := bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace,
+					Name: GenerateBindingGitOpsDeploymentName(*binding, "component-a")}, gitopsDeployment)
+				Expect(apierr.IsNotFound(err)).To(BeTrue())
+			}
+
+			It("should report that the Environment is missing, and not generate any GitOpsDeployments", func() {
+				By("creating a binding that targets an Environment that doesn't exist")
+				binding.Spec.Environment = "does-not-exist"
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionEnvironmentAvailable,
+					metav1.ConditionFalse, SnapshotEnvironmentBindingReasonEnvironmentNotFound)
+				expectNoGitOpsDeployment()
+			})
+
+			It("should report the Environment, and Snapshot components, as available when they are", func() {
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionEnvironmentAvailable,
+					metav1.ConditionTrue, SnapshotEnvironmentBindingReasonEnvironmentFound)
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionSnapshotComponentsAvailable,
+					metav1.ConditionTrue, SnapshotEnvironmentBindingReasonSnapshotComponentsAvailable)
+
+				By("verifying the ManagedEnvironment condition is not set, as the Environment has no cluster credentials")
+				Expect(meta.FindStatusCondition(binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionManagedEnvironmentConnected)).To(BeNil())
+			})
+
+			It("should report that the Snapshot is missing, and not generate any GitOpsDeployments", func() {
+				binding.Spec.Snapshot = "does-not-exist"
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionSnapshotComponentsAvailable,
+					metav1.ConditionFalse, SnapshotEnvironmentBindingReasonSnapshotNotFound)
+				expectNoGitOpsDeployment()
+			})
+
+			It("should report the components that have no image in the Snapshot, and not generate any GitOpsDeployments", func() {
+				binding.Spec.Components = append(binding.Spec.Components, appstudiosharedv1.BindingComponent{Name: "component-c"})
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				condition := checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionSnapshotComponentsAvailable,
+					metav1.ConditionFalse, SnapshotEnvironmentBindingReasonSnapshotComponentsMissing)
+				Expect(condition.Message).To(Equal("Snapshot 'my-snapshot' does not contain a container image for components: component-c"))
+				expectNoGitOpsDeployment()
+			})
+
+			It("should only generate GitOpsDeployments once the ManagedEnvironment of the Environment is connected", func() {
+				By("updating the Environment with cluster credentials")
+				environment.Spec.UnstableConfigurationFields = &appstudiosharedv1.UnstableEnvironmentConfiguration{
+					KubernetesClusterCredentials: appstudiosharedv1.KubernetesClusterCredentials{
+						TargetNamespace:          "my-target-namespace",
+						APIURL:                   "my-api-url",
+						ClusterCredentialsSecret: "secret",
+					},
+				}
+				err := bindingReconciler.Client.Update(ctx, &environment)
+				Expect(err).To(BeNil())
+
+				err = bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				By("reconciling before the ManagedEnvironment exists")
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionManagedEnvironmentConnected,
+					metav1.ConditionFalse, SnapshotEnvironmentBindingReasonManagedEnvironmentNotFound)
+				expectNoGitOpsDeployment()
+
+				By("reconciling when the ManagedEnvironment is unable to connect")
+				managedEnv := createManagedEnvironmentOfEnvironment(ctx, bindingReconciler.Client, environment, metav1.ConditionFalse)
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionManagedEnvironmentConnected,
+					metav1.ConditionFalse, SnapshotEnvironmentBindingReasonManagedEnvironmentNotConnected)
+				expectNoGitOpsDeployment()
+
+				By("reconciling once the ManagedEnvironment is connected")
+				managedEnv.Status.Conditions[0].Status = metav1.ConditionTrue
+				err = bindingReconciler.Client.Status().Update(ctx, &managedEnv)
+				Expect(err).To(BeNil())
+
+				_, err = bindingReconciler.Reconcile(ctx, request)
+				Expect(err).To(BeNil())
+
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionManagedEnvironmentConnected,
+					metav1.ConditionTrue, SnapshotEnvironmentBindingReasonManagedEnvironmentConnected)
+
+				gitopsDeployment := &apibackend.GitOpsDeployment{}
+				err = bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace,
+					Name: GenerateBindingGitOpsDeploymentName(*binding, "component-a")}, gitopsDeployment)
+				Expect(err).To(BeNil())
+			})
+
+			It("should write all the preflight conditions in a single status update, and not update an unchanged status", func() {
+				By("updating the Environment with cluster credentials, so that all the preflight conditions apply")
+				environment.Spec.UnstableConfigurationFields = &appstudiosharedv1.UnstableEnvironmentConfiguration{
+					KubernetesClusterCredentials: appstudiosharedv1.KubernetesClusterCredentials{
+						TargetNamespace:          "my-target-namespace",
+						APIURL:                   "my-api-url",
+						ClusterCredentialsSecret: "secret",
+					},
+				}
+				err := bindingReconciler.Client.Update(ctx, &environment)
+				Expect(err).To(BeNil())
+
+				createManagedEnvironmentOfEnvironment(ctx, bindingReconciler.Client, environment, metav1.ConditionTrue)
+
+				err = bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				countingClient := &statusWriteCountingClient{Client: bindingReconciler.Client}
+
+				checksPassed, err := runBindingPreflightChecks(ctx, binding, environment, countingClient, log.FromContext(ctx))
+				Expect(err).To(BeNil())
+				Expect(checksPassed).To(BeTrue())
+				Expect(countingClient.statusWrites).To(Equal(1))
+
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionEnvironmentAvailable,
+					metav1.ConditionTrue, SnapshotEnvironmentBindingReasonEnvironmentFound)
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionManagedEnvironmentConnected,
+					metav1.ConditionTrue, SnapshotEnvironmentBindingReasonManagedEnvironmentConnected)
+				checkPreflightConditionOfBinding(ctx, bindingReconciler.Client, binding, SnapshotEnvironmentBindingConditionSnapshotComponentsAvailable,
+					metav1.ConditionTrue, SnapshotEnvironmentBindingReasonSnapshotComponentsAvailable)
+
+				By("running the preflight checks again, with no changes")
+				checksPassed, err = runBindingPreflightChecks(ctx, binding, environment, countingClient, log.FromContext(ctx))
+				Expect(err).To(BeNil())
+				Expect(checksPassed).To(BeTrue())
+				Expect(countingClient.statusWrites).To(Equal(1))
+			})
+
+			It("should map changes of the Environment, ManagedEnvironment and Snapshot to the bindings that reference them", func() {
+				err := bindingReconciler.Create(ctx, binding)
+				Expect(err).To(BeNil())
+
+				expected := []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(binding)}}
+
+				Expect(bindingReconciler.findObjectsForEnvironment(&environment)).To(Equal(expected))
+
+				managedEnv := generateEmptyManagedEnvironment(environment.Name, environment.Namespace)
+				Expect(bindingReconciler.findObjectsForManagedEnvironment(&managedEnv)).To(Equal(expected))
+
+				snapshot := appstudiosharedv1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "my-snapshot", Namespace: binding.Namespace}}
+				Expect(bindingReconciler.findObjectsForSnapshot(&snapshot)).To(Equal(expected))
+
+				By("verifying unrelated resources are not mapped to the binding")
+				snapshot.Name = "another-snapshot"
+				Expect(bindingReconciler.findObjectsForSnapshot(&snapshot)).To(BeEmpty())
+			})
+		})
+
 		It("should append ASEB label with key `appstudio.openshift.io` into the GitopsDeployment Label", func() {
 			By("updating binding.ObjectMeta.Labels with appstudio.openshift.io label")
 			binding.ObjectMeta.Labels[appstudioLabelKey] = "testing"
@@ -929,6 +1499,23 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 			err = k8sClient.Create(ctx, &environment)
 			Expect(err).To(BeNil())
 
+			// Create the Snapshot referenced by the binding
+			snapshot := appstudiosharedv1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-snapshot",
+					Namespace: apiNamespace.Name,
+				},
+				Spec: appstudiosharedv1.SnapshotSpec{
+					Application: "new-demo-app",
+					Components: []appstudiosharedv1.SnapshotComponent{
+						{Name: "component-a", ContainerImage: "quay.io/my-org/component-a:latest"},
+						{Name: "component-b", ContainerImage: "quay.io/my-org/component-b:latest"},
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, &snapshot)
+			Expect(err).To(BeNil())
+
 			bindingReconciler = SnapshotEnvironmentBindingReconciler{Client: k8sClient, Scheme: scheme}
 
 			// Create SnapshotEnvironmentBinding CR.
@@ -1215,6 +1802,64 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 			Expect(binding.Status.ComponentDeploymentConditions[0].Reason).To(Equal(appstudiosharedv1.ComponentDeploymentConditionCommitsSynced))
 			Expect(binding.Status.ComponentDeploymentConditions[0].Message).To(Equal("2 of 2 components deployed"))
 		})
+
+		It("records the image digests of the Snapshot that are deployed for each component, once the component is deployed", func() {
+
+			By("pinning the image of component-a in the Snapshot to a digest")
+			componentAImage := "quay.io/my-org/component-a@sha256:0123456789abcdef"
+			snapshot := &appstudiosharedv1.Snapshot{}
+			err := bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.Spec.Snapshot}, snapshot)
+			Expect(err).To(BeNil())
+			snapshot.Spec.Components[0].ContainerImage = componentAImage
+			err = bindingReconciler.Update(ctx, snapshot)
+			Expect(err).To(BeNil())
+
+			err = bindingReconciler.Create(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			By("verifying no images are recorded before the components are deployed")
+			err = bindingReconciler.Get(ctx, request.NamespacedName, binding)
+			Expect(err).To(BeNil())
+			_, exists := binding.Annotations[SnapshotEnvironmentBindingDeployedImagesAnnotation]
+			Expect(exists).To(BeFalse())
+
+			By("simulating Argo CD deploying the GitOpsDeployments of both components")
+			for _, componentName := range []string{"component-a", "component-b"} {
+				deployment := &apibackend.GitOpsDeployment{}
+				err = bindingReconciler.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: GenerateBindingGitOpsDeploymentName(*binding, componentName)}, deployment)
+				Expect(err).To(BeNil())
+				deployment.Status.Sync.Status = apibackend.SyncStatusCodeSynced
+				deployment.Status.Health.Status = apibackend.HeathStatusCodeHealthy
+				deployment.Status.ReconciledState.Source.RepoURL = deployment.Spec.Source.RepoURL
+				deployment.Status.ReconciledState.Source.Path = deployment.Spec.Source.Path
+				err = bindingReconciler.Status().Update(ctx, deployment)
+				Expect(err).To(BeNil())
+			}
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			By("verifying the digest of component-a is recorded, but not the image of component-b, which is not pinned to a digest")
+			err = bindingReconciler.Get(ctx, request.NamespacedName, binding)
+			Expect(err).To(BeNil())
+			Expect(binding.Annotations[SnapshotEnvironmentBindingDeployedImagesAnnotation]).To(Equal("component-a=" + componentAImage))
+
+			By("removing component-a from the binding, and verifying its entry is removed")
+			binding.Status.Components = binding.Status.Components[1:]
+			err = bindingReconciler.Status().Update(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = bindingReconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			err = bindingReconciler.Get(ctx, request.NamespacedName, binding)
+			Expect(err).To(BeNil())
+			_, exists = binding.Annotations[SnapshotEnvironmentBindingDeployedImagesAnnotation]
+			Expect(exists).To(BeFalse())
+		})
 	})
 
 	Context("verify functions that are used to ensure that GitOpsDeployments generated by the Binding controller contain "+
@@ -1508,6 +2153,78 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler Tests", func() {
 			}}, false),
 		)
 
+		DescribeTable("verify parseComponentSyncWaves parses the sync order annotation of the binding",
+			func(annotations map[string]string, expectedSyncWaves map[string]int, expectError bool) {
+
+				binding := appstudiosharedv1.SnapshotEnvironmentBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "my-seb",
+						Namespace:   apiNamespace.Name,
+						Annotations: annotations,
+					},
+				}
+
+				syncWaves, err := parseComponentSyncWaves(binding)
+				if expectError {
+					Expect(err).ToNot(BeNil())
+					return
+				}
+				Expect(err).To(BeNil())
+				Expect(syncWaves).To(Equal(expectedSyncWaves))
+			},
+			Entry("no annotation", nil, map[string]int{}, false),
+			Entry("empty annotation", map[string]string{SnapshotEnvironmentBindingSyncOrderAnnotation: ""}, map[string]int{}, false),
+			Entry("valid annotation", map[string]string{SnapshotEnvironmentBindingSyncOrderAnnotation: "database=0, config=-1,backend = 2"},
+				map[string]int{"database": 0, "config": -1, "backend": 2}, false),
+			Entry("entry without a wave", map[string]string{SnapshotEnvironmentBindingSyncOrderAnnotation: "database"}, nil, true),
+			Entry("entry without a component", map[string]string{SnapshotEnvironmentBindingSyncOrderAnnotation: "=1"}, nil, true),
+			Entry("non-integer wave", map[string]string{SnapshotEnvironmentBindingSyncOrderAnnotation: "database=one"}, nil, true),
+			Entry("duplicate component", map[string]string{SnapshotEnvironmentBindingSyncOrderAnnotation: "database=0,database=1"}, nil, true),
+		)
+
+		DescribeTable("verify isImagePinnedToDigest only accepts container images that are pinned to a digest",
+			func(image string, expected bool) {
+				Expect(isImagePinnedToDigest(image)).To(Equal(expected))
+			},
+			Entry("digest", "quay.io/my-org/my-component@sha256:0123456789abcdef", true),
+			Entry("tag and digest", "quay.io/my-org/my-component:v1@sha256:0123456789abcdef", true),
+			Entry("tag", "quay.io/my-org/my-component:v1", false),
+			Entry("registry with port", "localhost:5000/my-component", false),
+			Entry("empty digest", "quay.io/my-org/my-component@sha256:", false),
+			Entry("empty", "", false),
+		)
+
+		It("verify the deployed images annotation is parsed, and formatted in order of component name", func() {
+
+			deployedImages := map[string]string{
+				"frontend": "quay.io/my-org/frontend@sha256:abc",
+				"backend":  "quay.io/my-org/backend@sha256:def",
+			}
+
+			value := formatDeployedImages(deployedImages)
+			Expect(value).To(Equal("backend=quay.io/my-org/backend@sha256:def,frontend=quay.io/my-org/frontend@sha256:abc"))
+
+			binding := appstudiosharedv1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{SnapshotEnvironmentBindingDeployedImagesAnnotation: value},
+				},
+			}
+			Expect(parseDeployedImages(binding)).To(Equal(deployedImages))
+		})
+
+		It("verify groupComponentsBySyncWave groups components by ascending sync wave", func() {
+
+			expectedDeployments := map[string]apibackend.GitOpsDeployment{
+				"backend":  {},
+				"frontend": {},
+				"database": {},
+				"config":   {},
+			}
+
+			res := groupComponentsBySyncWave(expectedDeployments, map[string]int{"database": -1, "frontend": 2, "config": 0})
+			Expect(res).To(Equal([][]string{{"database"}, {"backend", "config"}, {"frontend"}}))
+		})
+
 	})
 
 })
@@ -1536,3 +2253,68 @@ func checkStatusConditionOfEnvironmentBinding(ctx context.Context, rClient clien
 		}
 	}
 }
+
+// statusWriteCountingClient is a client that counts the writes to the status subresource of objects
+type statusWriteCountingClient struct {
+	client.Client
+	statusWrites int
+}
+
+func (c *statusWriteCountingClient) Status() client.StatusWriter {
+	return &statusWriteCountingStatusWriter{StatusWriter: c.Client.Status(), parent: c}
+}
+
+type statusWriteCountingStatusWriter struct {
+	client.StatusWriter
+	parent *statusWriteCountingClient
+}
+
+func (w *statusWriteCountingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.parent.statusWrites++
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *statusWriteCountingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.parent.statusWrites++
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// checkPreflightConditionOfBinding verifies the binding has a condition of the given type, with the given status and reason
+func checkPreflightConditionOfBinding(ctx context.Context, rClient client.Client, binding *appstudiosharedv1.SnapshotEnvironmentBinding,
+	conditionType string, status metav1.ConditionStatus, reason string) metav1.Condition {
+
+	err := rClient.Get(ctx, client.ObjectKeyFromObject(binding), binding)
+	Expect(err).To(BeNil())
+
+	condition := meta.FindStatusCondition(binding.Status.BindingConditions, conditionType)
+	Expect(condition).ToNot(BeNil())
+	Expect(condition.Status).To(Equal(status))
+	Expect(condition.Reason).To(Equal(reason))
+
+	return *condition
+}
+
+// createManagedEnvironmentOfEnvironment creates the GitOpsDeploymentManagedEnvironment of the Environment, with the given connection status
+func createManagedEnvironmentOfEnvironment(ctx context.Context, rClient client.Client, environment appstudiosharedv1.Environment,
+	connectionStatus metav1.ConditionStatus) apibackend.GitOpsDeploymentManagedEnvironment {
+
+	managedEnv := generateEmptyManagedEnvironment(environment.Name, environment.Namespace)
+	err := rClient.Create(ctx, &managedEnv)
+	Expect(err).To(BeNil())
+
+	reason := apibackend.ConditionReasonSucceeded
+	if connectionStatus != metav1.ConditionTrue {
+		reason = apibackend.ConditionReasonUnableToCreateClient
+	}
+
+	managedEnv.Status.Conditions = []metav1.Condition{{
+		Type:               apibackend.ManagedEnvironmentStatusConnectionInitializationSucceeded,
+		Status:             connectionStatus,
+		Reason:             string(reason),
+		LastTransitionTime: metav1.Now(),
+	}}
+	err = rClient.Status().Update(ctx, &managedEnv)
+	Expect(err).To(BeNil())
+
+	return managedEnv
+}
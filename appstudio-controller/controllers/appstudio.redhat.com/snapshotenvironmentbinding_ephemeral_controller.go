@@ -0,0 +1,383 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appstudioredhatcom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// An ephemeral SnapshotEnvironmentBinding deploys a Snapshot to a short-lived test environment, which is provisioned for
+// the binding, and torn down once the integration tests of the Snapshot have completed (or the TTL of the binding expires):
+//
+//  1. Provisioning: a DeploymentTargetClaim of the requested DeploymentTargetClass is created for the binding, along with
+//     the Environment of the binding, which targets the DeploymentTargetClaim. The DeploymentTargetClaim binder provisions
+//     a DeploymentTarget for the claim, and the Environment controller then generates the GitOpsDeploymentManagedEnvironment.
+//  2. Deploying: the SnapshotEnvironmentBinding controller deploys the components of the Snapshot to the Environment.
+//  3. AwaitingTestResult: once all components are deployed, the integration tests are expected to run against the
+//     Environment, and to report the result by setting the test result annotation on the binding.
+//  4. TornDown: once the test result is reported, or the TTL expires, the GitOpsDeployments, Environment and
+//     DeploymentTargetClaim of the binding are deleted. The binding itself is not deleted, so that the outcome remains visible.
+//
+// The current phase is reported as the reason of the EphemeralEnvironment condition of the binding.
+const (
+	// SnapshotEnvironmentBindingEphemeralAnnotation may be set on a SnapshotEnvironmentBinding to the name of a
+	// DeploymentTargetClass, to make the binding ephemeral: the Environment of the binding is provisioned from that class.
+	SnapshotEnvironmentBindingEphemeralAnnotation = appstudioLabelKey + "/ephemeral-deployment-target-class"
+
+	// SnapshotEnvironmentBindingEphemeralTTLAnnotation may be set on an ephemeral SnapshotEnvironmentBinding to the
+	// maximum lifetime of its Environment (as a Go duration, for example "90m"), from the creation of the binding.
+	// Defaults to defaultEphemeralBindingTTL.
+	SnapshotEnvironmentBindingEphemeralTTLAnnotation = appstudioLabelKey + "/ephemeral-ttl"
+
+	// SnapshotEnvironmentBindingTestResultAnnotation is set on an ephemeral SnapshotEnvironmentBinding (for example, by
+	// the integration service) to the result of the integration tests: either "passed" or "failed". Setting the
+	// annotation tears down the Environment of the binding.
+	SnapshotEnvironmentBindingTestResultAnnotation = appstudioLabelKey + "/integration-test-result"
+
+	EphemeralBindingTestResultPassed = "passed"
+	EphemeralBindingTestResultFailed = "failed"
+
+	defaultEphemeralBindingTTL = 2 * time.Hour
+)
+
+const (
+	// SnapshotEnvironmentBindingConditionEphemeralEnvironment reports the phase of the Environment of an ephemeral binding.
+	// The status is true while the components are deployed to the Environment, and it is waiting for the test result.
+	SnapshotEnvironmentBindingConditionEphemeralEnvironment     = "EphemeralEnvironment"
+	SnapshotEnvironmentBindingReasonEphemeralProvisioning       = "Provisioning"
+	SnapshotEnvironmentBindingReasonEphemeralDeploying          = "Deploying"
+	SnapshotEnvironmentBindingReasonEphemeralAwaitingTestResult = "AwaitingTestResult"
+	SnapshotEnvironmentBindingReasonEphemeralTornDown           = "TornDown"
+	SnapshotEnvironmentBindingReasonEphemeralInvalid            = "Invalid"
+)
+
+// EphemeralBindingReconciler provisions and tears down the Environments of ephemeral SnapshotEnvironmentBindings.
+type EphemeralBindingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Clock  sharedutil.Clock
+}
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotenvironmentbindings,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotenvironmentbindings/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargetclaims,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeployments,verbs=get;list;watch;delete
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.0/pkg/reconcile
+func (r *EphemeralBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("name", req.Name, "namespace", req.Namespace, "component", "ephemeralBindingReconcile")
+
+	binding := &appstudioshared.SnapshotEnvironmentBinding{}
+	if err := r.Client.Get(ctx, req.NamespacedName, binding); err != nil {
+		if apierr.IsNotFound(err) {
+			// Owner refs will ensure the Environment and DeploymentTargetClaim of the binding are deleted.
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("unable to retrieve SnapshotEnvironmentBinding: %v", err)
+	}
+
+	if !isEphemeralBinding(*binding) || binding.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// 1) Tear down the Environment, if the test result has been reported, or the TTL has expired
+	if isEphemeralBindingTornDown(*binding) {
+		// The resources are deleted again, in case a previous teardown did not complete
+		return ctrl.Result{}, teardownEphemeralBinding(ctx, binding, "", r.Client, log)
+	}
+
+	ttl, err := getEphemeralBindingTTL(*binding)
+	if err != nil {
+		// The binding is reconciled again when the annotation is fixed
+		return ctrl.Result{}, updateBindingConditionOfSEB(ctx, r.Client, err.Error(), binding,
+			SnapshotEnvironmentBindingConditionEphemeralEnvironment, metav1.ConditionFalse, SnapshotEnvironmentBindingReasonEphemeralInvalid, log)
+	}
+
+	if testResult := strings.TrimSpace(binding.Annotations[SnapshotEnvironmentBindingTestResultAnnotation]); testResult != "" {
+		return ctrl.Result{}, teardownEphemeralBinding(ctx, binding, fmt.Sprintf("the integration tests reported the result '%s'", testResult), r.Client, log)
+	}
+
+	expiresAt := binding.CreationTimestamp.Add(ttl)
+	if !r.Clock.Now().Before(expiresAt) {
+		return ctrl.Result{}, teardownEphemeralBinding(ctx, binding, fmt.Sprintf("its TTL of %v expired", ttl), r.Client, log)
+	}
+
+	// 2) Ensure the DeploymentTargetClaim and Environment of the binding exist
+	dtc, err := ensureEphemeralDeploymentTargetClaim(ctx, *binding, r.Client, log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if userError, err := ensureEphemeralEnvironment(ctx, *binding, *dtc, r.Client, log); err != nil {
+		return ctrl.Result{}, err
+
+	} else if userError != "" {
+		if err := updateBindingConditionOfSEB(ctx, r.Client, userError, binding,
+			SnapshotEnvironmentBindingConditionEphemeralEnvironment, metav1.ConditionFalse, SnapshotEnvironmentBindingReasonEphemeralInvalid, log); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: expiresAt.Sub(r.Clock.Now())}, nil
+	}
+
+	// 3) Report the current phase of the Environment
+	status, reason, message := getEphemeralBindingPhase(*binding, *dtc)
+	if err := updateBindingConditionOfSEB(ctx, r.Client, message, binding,
+		SnapshotEnvironmentBindingConditionEphemeralEnvironment, status, reason, log); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The binding is reconciled again when the TTL expires
+	return ctrl.Result{RequeueAfter: expiresAt.Sub(r.Clock.Now())}, nil
+}
+
+// isEphemeralBinding returns true if the binding is ephemeral (see SnapshotEnvironmentBindingEphemeralAnnotation).
+func isEphemeralBinding(binding appstudioshared.SnapshotEnvironmentBinding) bool {
+	return strings.TrimSpace(binding.Annotations[SnapshotEnvironmentBindingEphemeralAnnotation]) != ""
+}
+
+// isEphemeralBindingTornDown returns true if the Environment of an ephemeral binding has been torn down: no more
+// GitOpsDeployments should be generated for the binding.
+func isEphemeralBindingTornDown(binding appstudioshared.SnapshotEnvironmentBinding) bool {
+	condition := meta.FindStatusCondition(binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionEphemeralEnvironment)
+	return isEphemeralBinding(binding) && condition != nil && condition.Reason == SnapshotEnvironmentBindingReasonEphemeralTornDown
+}
+
+// getEphemeralBindingTTL returns the TTL of an ephemeral binding, from SnapshotEnvironmentBindingEphemeralTTLAnnotation.
+func getEphemeralBindingTTL(binding appstudioshared.SnapshotEnvironmentBinding) (time.Duration, error) {
+
+	value := strings.TrimSpace(binding.Annotations[SnapshotEnvironmentBindingEphemeralTTLAnnotation])
+	if value == "" {
+		return defaultEphemeralBindingTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid value '%s' in %s annotation: expected a positive duration, for example '90m'",
+			value, SnapshotEnvironmentBindingEphemeralTTLAnnotation)
+	}
+
+	return ttl, nil
+}
+
+// generateEphemeralDeploymentTargetClaimName returns the name of the DeploymentTargetClaim of an ephemeral binding.
+func generateEphemeralDeploymentTargetClaimName(binding appstudioshared.SnapshotEnvironmentBinding) string {
+	return binding.Spec.Environment + "-ephemeral"
+}
+
+// generateBindingOwnerReference returns an owner reference to the binding, for the resources that are generated for it.
+func generateBindingOwnerReference(binding appstudioshared.SnapshotEnvironmentBinding) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         appstudioshared.GroupVersion.String(),
+		Kind:               "SnapshotEnvironmentBinding",
+		Name:               binding.Name,
+		UID:                binding.UID,
+		BlockOwnerDeletion: pointer.Bool(true),
+		Controller:         pointer.Bool(true),
+	}
+}
+
+// isOwnedByBinding returns true if the object has an owner reference to the binding, false otherwise.
+func isOwnedByBinding(obj client.Object, binding appstudioshared.SnapshotEnvironmentBinding) bool {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.Kind == "SnapshotEnvironmentBinding" && ownerRef.UID == binding.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureEphemeralDeploymentTargetClaim creates the DeploymentTargetClaim of an ephemeral binding, if it doesn't exist,
+// and returns it.
+func ensureEphemeralDeploymentTargetClaim(ctx context.Context, binding appstudioshared.SnapshotEnvironmentBinding,
+	k8sClient client.Client, log logr.Logger) (*appstudioshared.DeploymentTargetClaim, error) {
+
+	dtc := &appstudioshared.DeploymentTargetClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      generateEphemeralDeploymentTargetClaimName(binding),
+			Namespace: binding.Namespace,
+		},
+	}
+
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dtc), dtc); err == nil {
+		return dtc, nil
+	} else if !apierr.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to retrieve DeploymentTargetClaim '%s' of ephemeral SnapshotEnvironmentBinding: %v", dtc.Name, err)
+	}
+
+	dtc.OwnerReferences = []metav1.OwnerReference{generateBindingOwnerReference(binding)}
+	dtc.Spec.DeploymentTargetClassName = appstudioshared.DeploymentTargetClassName(
+		strings.TrimSpace(binding.Annotations[SnapshotEnvironmentBindingEphemeralAnnotation]))
+
+	if err := k8sClient.Create(ctx, dtc); err != nil {
+		return nil, fmt.Errorf("unable to create DeploymentTargetClaim '%s' of ephemeral SnapshotEnvironmentBinding: %v", dtc.Name, err)
+	}
+	logutil.LogAPIResourceChangeEvent(dtc.Namespace, dtc.Name, dtc, logutil.ResourceCreated, log)
+
+	return dtc, nil
+}
+
+// ensureEphemeralEnvironment creates the Environment of an ephemeral binding, targeting its DeploymentTargetClaim, if
+// it doesn't exist. If an Environment of that name already exists, but was not created for the binding, a non-empty
+// user error is returned: the existing Environment is not modified.
+func ensureEphemeralEnvironment(ctx context.Context, binding appstudioshared.SnapshotEnvironmentBinding,
+	dtc appstudioshared.DeploymentTargetClaim, k8sClient client.Client, log logr.Logger) (string, error) {
+
+	environment := &appstudioshared.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      binding.Spec.Environment,
+			Namespace: binding.Namespace,
+		},
+	}
+
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(environment), environment); err == nil {
+		if !isOwnedByBinding(environment, binding) {
+			return fmt.Sprintf("Environment '%s' already exists, and was not created for the ephemeral SnapshotEnvironmentBinding",
+				environment.Name), nil
+		}
+		return "", nil
+
+	} else if !apierr.IsNotFound(err) {
+		return "", fmt.Errorf("unable to retrieve Environment '%s' of ephemeral SnapshotEnvironmentBinding: %v", environment.Name, err)
+	}
+
+	environment.OwnerReferences = []metav1.OwnerReference{generateBindingOwnerReference(binding)}
+	environment.Spec = appstudioshared.EnvironmentSpec{
+		DisplayName:        binding.Spec.Environment,
+		DeploymentStrategy: appstudioshared.DeploymentStrategy_Manual,
+		Configuration: appstudioshared.EnvironmentConfiguration{
+			Target: appstudioshared.EnvironmentTarget{
+				DeploymentTargetClaim: appstudioshared.DeploymentTargetClaimConfig{
+					ClaimName: dtc.Name,
+				},
+			},
+		},
+	}
+
+	if err := k8sClient.Create(ctx, environment); err != nil {
+		return "", fmt.Errorf("unable to create Environment '%s' of ephemeral SnapshotEnvironmentBinding: %v", environment.Name, err)
+	}
+	logutil.LogAPIResourceChangeEvent(environment.Namespace, environment.Name, environment, logutil.ResourceCreated, log)
+
+	return "", nil
+}
+
+// getEphemeralBindingPhase returns the status, reason and message of the EphemeralEnvironment condition of a binding
+// whose Environment has not been torn down.
+func getEphemeralBindingPhase(binding appstudioshared.SnapshotEnvironmentBinding,
+	dtc appstudioshared.DeploymentTargetClaim) (metav1.ConditionStatus, string, string) {
+
+	if dtc.Status.Phase != appstudioshared.DeploymentTargetClaimPhase_Bound {
+		return metav1.ConditionFalse, SnapshotEnvironmentBindingReasonEphemeralProvisioning,
+			fmt.Sprintf("Waiting for DeploymentTargetClaim '%s' to be bound to a DeploymentTarget", dtc.Name)
+	}
+
+	for _, condition := range binding.Status.ComponentDeploymentConditions {
+		if condition.Type == appstudioshared.ComponentDeploymentConditionAllComponentsDeployed && condition.Status == metav1.ConditionTrue {
+			return metav1.ConditionTrue, SnapshotEnvironmentBindingReasonEphemeralAwaitingTestResult,
+				fmt.Sprintf("All components are deployed: waiting for the integration test result in the %s annotation",
+					SnapshotEnvironmentBindingTestResultAnnotation)
+		}
+	}
+
+	return metav1.ConditionFalse, SnapshotEnvironmentBindingReasonEphemeralDeploying,
+		fmt.Sprintf("Deploying the components of Snapshot '%s' to Environment '%s'", binding.Spec.Snapshot, binding.Spec.Environment)
+}
+
+// teardownEphemeralBinding marks the Environment of an ephemeral binding as torn down (with 'cause' as the reason, if
+// non-empty), and then deletes the GitOpsDeployments, Environment and DeploymentTargetClaim that were created for the binding.
+func teardownEphemeralBinding(ctx context.Context, binding *appstudioshared.SnapshotEnvironmentBinding, cause string,
+	k8sClient client.Client, log logr.Logger) error {
+
+	// The condition is set first, so that the SnapshotEnvironmentBinding controller no longer generates GitOpsDeployments
+	if cause != "" {
+		log.Info("Tearing down the Environment of ephemeral SnapshotEnvironmentBinding", "cause", cause)
+
+		if err := updateBindingConditionOfSEB(ctx, k8sClient, "The Environment was torn down, as "+cause, binding,
+			SnapshotEnvironmentBindingConditionEphemeralEnvironment, metav1.ConditionFalse, SnapshotEnvironmentBindingReasonEphemeralTornDown, log); err != nil {
+			return err
+		}
+	}
+
+	if err := deleteUnmatchedDeployments(ctx, *binding, nil, k8sClient, log); err != nil {
+		return fmt.Errorf("unable to delete GitOpsDeployments of ephemeral SnapshotEnvironmentBinding: %v", err)
+	}
+
+	objectsToDelete := []client.Object{
+		&appstudioshared.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: binding.Spec.Environment, Namespace: binding.Namespace},
+		},
+		&appstudioshared.DeploymentTargetClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: generateEphemeralDeploymentTargetClaimName(*binding), Namespace: binding.Namespace},
+		},
+	}
+
+	for _, obj := range objectsToDelete {
+
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierr.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("unable to retrieve '%s' of ephemeral SnapshotEnvironmentBinding: %v", obj.GetName(), err)
+		}
+
+		// Only the resources that were created for the binding are deleted
+		if !isOwnedByBinding(obj, *binding) || obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		if err := k8sClient.Delete(ctx, obj); err != nil && !apierr.IsNotFound(err) {
+			return fmt.Errorf("unable to delete '%s' of ephemeral SnapshotEnvironmentBinding: %v", obj.GetName(), err)
+		}
+		logutil.LogAPIResourceChangeEvent(obj.GetNamespace(), obj.GetName(), obj, logutil.ResourceDeleted, log)
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *EphemeralBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("ephemeralsnapshotenvironmentbinding").
+		For(&appstudioshared.SnapshotEnvironmentBinding{}).
+		// The phase of the binding is updated when its DeploymentTargetClaim is bound
+		Owns(&appstudioshared.DeploymentTargetClaim{}).
+		Complete(sharedutil.NewReconcileTimingMiddleware("EphemeralSnapshotEnvironmentBinding", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &appstudioshared.SnapshotEnvironmentBinding{} }, r)))
+}
@@ -0,0 +1,257 @@
+package appstudioredhatcom
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appstudiosharedv1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	apibackend "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Ephemeral SnapshotEnvironmentBinding Reconciler Tests", func() {
+
+	Context("Testing EphemeralBindingReconciler", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+		var binding *appstudiosharedv1.SnapshotEnvironmentBinding
+		var reconciler EphemeralBindingReconciler
+		var request reconcile.Request
+		var createdAt time.Time
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				apiNamespace,
+				err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			err = appstudiosharedv1.AddToScheme(scheme)
+			Expect(err).To(BeNil())
+
+			createdAt = time.Now().Truncate(time.Second)
+
+			binding = &appstudiosharedv1.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "my-binding",
+					Namespace:         apiNamespace.Name,
+					UID:               "my-binding-uid",
+					CreationTimestamp: metav1.NewTime(createdAt),
+					Annotations: map[string]string{
+						SnapshotEnvironmentBindingEphemeralAnnotation:    "isolation-level-namespace",
+						SnapshotEnvironmentBindingEphemeralTTLAnnotation: "1h",
+					},
+				},
+				Spec: appstudiosharedv1.SnapshotEnvironmentBindingSpec{
+					Application: "my-app",
+					Environment: "my-ephemeral-env",
+					Snapshot:    "my-snapshot",
+				},
+			}
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace, binding).
+				Build()
+
+			reconciler = EphemeralBindingReconciler{
+				Client: k8sClient,
+				Scheme: scheme,
+				Clock:  sharedutil.NewMockClock(createdAt.Add(10 * time.Minute)),
+			}
+
+			request = newRequest(binding.Namespace, binding.Name)
+		})
+
+		getEphemeralCondition := func() *metav1.Condition {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(binding), binding)
+			Expect(err).To(BeNil())
+			return meta.FindStatusCondition(binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionEphemeralEnvironment)
+		}
+
+		It("should provision the Environment of the binding, and report the phase until the test result is reported", func() {
+
+			res, err := reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(Equal(50 * time.Minute))
+
+			By("verifying the DeploymentTargetClaim and Environment were created for the binding")
+			dtc := appstudiosharedv1.DeploymentTargetClaim{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: "my-ephemeral-env-ephemeral"}, &dtc)
+			Expect(err).To(BeNil())
+			Expect(string(dtc.Spec.DeploymentTargetClassName)).To(Equal("isolation-level-namespace"))
+			Expect(isOwnedByBinding(&dtc, *binding)).To(BeTrue())
+
+			environment := appstudiosharedv1.Environment{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: binding.Spec.Environment}, &environment)
+			Expect(err).To(BeNil())
+			Expect(environment.GetDeploymentTargetClaimName()).To(Equal(dtc.Name))
+			Expect(isOwnedByBinding(&environment, *binding)).To(BeTrue())
+
+			condition := getEphemeralCondition()
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Reason).To(Equal(SnapshotEnvironmentBindingReasonEphemeralProvisioning))
+
+			By("binding the DeploymentTargetClaim, and verifying the binding is deploying")
+			dtc.Status.Phase = appstudiosharedv1.DeploymentTargetClaimPhase_Bound
+			err = k8sClient.Update(ctx, &dtc)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+			Expect(getEphemeralCondition().Reason).To(Equal(SnapshotEnvironmentBindingReasonEphemeralDeploying))
+
+			By("deploying all the components, and verifying the binding is waiting for the test result")
+			binding.Status.ComponentDeploymentConditions = []metav1.Condition{{
+				Type:   appstudiosharedv1.ComponentDeploymentConditionAllComponentsDeployed,
+				Status: metav1.ConditionTrue,
+				Reason: appstudiosharedv1.ComponentDeploymentConditionCommitsSynced,
+			}}
+			err = k8sClient.Status().Update(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+			condition = getEphemeralCondition()
+			Expect(condition.Reason).To(Equal(SnapshotEnvironmentBindingReasonEphemeralAwaitingTestResult))
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("reporting the test result, and verifying the Environment is torn down")
+			deployment := apibackend.GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "my-binding-my-component",
+					Namespace:       binding.Namespace,
+					OwnerReferences: []metav1.OwnerReference{generateBindingOwnerReference(*binding)},
+					Labels: map[string]string{
+						applicationLabelKey: binding.Spec.Application,
+						environmentLabelKey: binding.Spec.Environment,
+						componentLabelKey:   "my-component",
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, &deployment)
+			Expect(err).To(BeNil())
+
+			binding.Annotations[SnapshotEnvironmentBindingTestResultAnnotation] = EphemeralBindingTestResultPassed
+			err = k8sClient.Update(ctx, binding)
+			Expect(err).To(BeNil())
+
+			res, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(BeZero())
+
+			condition = getEphemeralCondition()
+			Expect(condition.Reason).To(Equal(SnapshotEnvironmentBindingReasonEphemeralTornDown))
+			Expect(condition.Message).To(ContainSubstring("passed"))
+			Expect(isEphemeralBindingTornDown(*binding)).To(BeTrue())
+
+			for _, obj := range []client.Object{&deployment, &environment, &dtc} {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+				Expect(apierr.IsNotFound(err)).To(BeTrue())
+			}
+		})
+
+		It("should tear down the Environment of the binding once its TTL has expired", func() {
+
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			reconciler.Clock = sharedutil.NewMockClock(createdAt.Add(time.Hour))
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			condition := getEphemeralCondition()
+			Expect(condition.Reason).To(Equal(SnapshotEnvironmentBindingReasonEphemeralTornDown))
+			Expect(condition.Message).To(ContainSubstring("TTL"))
+
+			environment := appstudiosharedv1.Environment{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: binding.Spec.Environment}, &environment)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			By("verifying the Environment is not provisioned again")
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: binding.Spec.Environment}, &environment)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should not modify or delete an Environment that was not created for the binding", func() {
+
+			environment := appstudiosharedv1.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      binding.Spec.Environment,
+					Namespace: binding.Namespace,
+				},
+				Spec: appstudiosharedv1.EnvironmentSpec{
+					DisplayName:        "my-existing-environment",
+					DeploymentStrategy: appstudiosharedv1.DeploymentStrategy_Manual,
+				},
+			}
+			err := k8sClient.Create(ctx, &environment)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			condition := getEphemeralCondition()
+			Expect(condition.Reason).To(Equal(SnapshotEnvironmentBindingReasonEphemeralInvalid))
+
+			binding.Annotations[SnapshotEnvironmentBindingTestResultAnnotation] = EphemeralBindingTestResultFailed
+			err = k8sClient.Update(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+			Expect(getEphemeralCondition().Reason).To(Equal(SnapshotEnvironmentBindingReasonEphemeralTornDown))
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&environment), &environment)
+			Expect(err).To(BeNil())
+			Expect(environment.Spec.DisplayName).To(Equal("my-existing-environment"))
+		})
+
+		It("should report an invalid TTL annotation", func() {
+
+			binding.Annotations[SnapshotEnvironmentBindingEphemeralTTLAnnotation] = "not-a-duration"
+			err := k8sClient.Update(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+
+			condition := getEphemeralCondition()
+			Expect(condition.Reason).To(Equal(SnapshotEnvironmentBindingReasonEphemeralInvalid))
+			Expect(condition.Message).To(ContainSubstring(SnapshotEnvironmentBindingEphemeralTTLAnnotation))
+		})
+
+		It("should not reconcile bindings that are not ephemeral", func() {
+
+			delete(binding.Annotations, SnapshotEnvironmentBindingEphemeralAnnotation)
+			err := k8sClient.Update(ctx, binding)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).To(BeNil())
+			Expect(getEphemeralCondition()).To(BeNil())
+
+			environment := appstudiosharedv1.Environment{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: binding.Spec.Environment}, &environment)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,37 @@
+package appstudioredhatcom
+
+import (
+	"context"
+
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateStatus patches the status of 'obj' with the changes that 'mutate' makes to it. 'mutate' applies the desired change
+// to 'obj', and returns false if no change is required (in which case no patch is sent).
+//
+// The patch includes the resourceVersion of 'obj' (an optimistic lock), so that a concurrent change to a list of the status
+// (such as the conditions) is not overwritten. On a conflict, the latest version of 'obj' is retrieved, and 'mutate' is
+// applied to it again: 'mutate' should therefore only set the fields of the status that the caller is responsible for.
+func updateStatus(ctx context.Context, k8sClient client.Client, obj client.Object, mutate func() bool) error {
+
+	firstAttempt := true
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+
+		if !firstAttempt {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+		}
+		firstAttempt = false
+
+		base := obj.DeepCopyObject().(client.Object)
+
+		if !mutate() {
+			return nil
+		}
+
+		return k8sClient.Status().Patch(ctx, obj, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+	})
+}
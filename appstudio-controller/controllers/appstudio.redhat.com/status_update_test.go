@@ -0,0 +1,159 @@
+package appstudioredhatcom
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appstudioshared "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Status update tests", func() {
+
+	Context("Testing updateStatus, with concurrent writers", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				namespace,
+				err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			err = appstudioshared.AddToScheme(scheme)
+			Expect(err).To(BeNil())
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, argocdNamespace, kubesystemNamespace).
+				Build()
+		})
+
+		// addConditionAsOtherWriter adds a condition to the status of the resource, from a separate copy of it, so that
+		// any copy of the resource that was retrieved before is out of date.
+		addConditionAsOtherWriter := func(obj client.Object, conditions func(client.Object) *[]metav1.Condition) {
+			otherCopy := obj.DeepCopyObject().(client.Object)
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), otherCopy)).To(Succeed())
+
+			meta.SetStatusCondition(conditions(otherCopy), metav1.Condition{
+				Type:    "OtherWriterCondition",
+				Status:  metav1.ConditionTrue,
+				Reason:  "OtherWriter",
+				Message: "set by another writer",
+			})
+			Expect(k8sClient.Status().Update(ctx, otherCopy)).To(Succeed())
+		}
+
+		It("should keep the changes of another writer, when updating the status condition of an out of date Environment", func() {
+			environment := &appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-environment",
+					Namespace: "gitops-service-argocd",
+				},
+			}
+			Expect(k8sClient.Create(ctx, environment)).To(Succeed())
+
+			addConditionAsOtherWriter(environment, func(obj client.Object) *[]metav1.Condition {
+				return &obj.(*appstudioshared.Environment).Status.Conditions
+			})
+
+			By("updating the status condition from the out of date copy of the Environment")
+			err := updateStatusConditionOfEnvironment(ctx, k8sClient, "an error occurred", environment,
+				EnvironmentConditionErrorOccurred, metav1.ConditionTrue, EnvironmentReasonErrorOccurred, log.FromContext(ctx))
+			Expect(err).To(BeNil())
+
+			By("verifying both conditions are set on the Environment")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(environment), environment)).To(Succeed())
+			Expect(meta.FindStatusCondition(environment.Status.Conditions, "OtherWriterCondition")).ToNot(BeNil())
+
+			errorCondition := meta.FindStatusCondition(environment.Status.Conditions, EnvironmentConditionErrorOccurred)
+			Expect(errorCondition).ToNot(BeNil())
+			Expect(errorCondition.Message).To(Equal("an error occurred"))
+		})
+
+		It("should keep the changes of another writer, when updating the binding condition of an out of date SnapshotEnvironmentBinding", func() {
+			binding := &appstudioshared.SnapshotEnvironmentBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-binding",
+					Namespace: "gitops-service-argocd",
+				},
+			}
+			Expect(k8sClient.Create(ctx, binding)).To(Succeed())
+
+			addConditionAsOtherWriter(binding, func(obj client.Object) *[]metav1.Condition {
+				return &obj.(*appstudioshared.SnapshotEnvironmentBinding).Status.BindingConditions
+			})
+
+			By("updating the binding condition from the out of date copy of the SnapshotEnvironmentBinding")
+			err := updateBindingConditionOfSEB(ctx, k8sClient, "an error occurred", binding,
+				SnapshotEnvironmentBindingConditionErrorOccurred, metav1.ConditionTrue, SnapshotEnvironmentBindingReasonErrorOccurred, log.FromContext(ctx))
+			Expect(err).To(BeNil())
+
+			By("verifying both conditions are set on the SnapshotEnvironmentBinding")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(binding), binding)).To(Succeed())
+			Expect(meta.FindStatusCondition(binding.Status.BindingConditions, "OtherWriterCondition")).ToNot(BeNil())
+			Expect(meta.FindStatusCondition(binding.Status.BindingConditions, SnapshotEnvironmentBindingConditionErrorOccurred)).ToNot(BeNil())
+		})
+
+		It("should not update the resource, if the mutate function made no changes", func() {
+			environment := &appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-environment",
+					Namespace: "gitops-service-argocd",
+				},
+			}
+			Expect(k8sClient.Create(ctx, environment)).To(Succeed())
+			resourceVersion := environment.ResourceVersion
+
+			err := updateStatus(ctx, k8sClient, environment, func() bool {
+				return false
+			})
+			Expect(err).To(BeNil())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(environment), environment)).To(Succeed())
+			Expect(environment.ResourceVersion).To(Equal(resourceVersion))
+		})
+
+		It("should apply the change to the latest version of the resource, when the first patch conflicts", func() {
+			environment := &appstudioshared.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-environment",
+					Namespace: "gitops-service-argocd",
+				},
+			}
+			Expect(k8sClient.Create(ctx, environment)).To(Succeed())
+
+			addConditionAsOtherWriter(environment, func(obj client.Object) *[]metav1.Condition {
+				return &obj.(*appstudioshared.Environment).Status.Conditions
+			})
+
+			attempts := 0
+			err := updateStatus(ctx, k8sClient, environment, func() bool {
+				attempts++
+				meta.SetStatusCondition(&environment.Status.Conditions, metav1.Condition{
+					Type:   "MyCondition",
+					Status: metav1.ConditionTrue,
+					Reason: "MyReason",
+				})
+				return true
+			})
+			Expect(err).To(BeNil())
+
+			By("verifying the change was applied again, after the out of date copy conflicted")
+			Expect(attempts).To(Equal(2))
+			Expect(meta.FindStatusCondition(environment.Status.Conditions, "OtherWriterCondition")).ToNot(BeNil())
+			Expect(meta.FindStatusCondition(environment.Status.Conditions, "MyCondition")).ToNot(BeNil())
+		})
+	})
+})
@@ -17,13 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"strings"
 
 	codereadytoolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -33,7 +32,6 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	crzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	applicationv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
@@ -42,6 +40,7 @@ import (
 	appstudioredhatcomcontrollers "github.com/redhat-appstudio/managed-gitops/appstudio-controller/controllers/appstudio.redhat.com"
 
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/health"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	//+kubebuilder:scaffold:imports
 )
@@ -74,17 +73,17 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&profilerAddr, "profiler-address", ":6062", "The address for serving pprof profiles")
 
-	opts := crzap.Options{
-		TimeEncoder: zapcore.ISO8601TimeEncoder,
-		ZapOpts: []zap.Option{
-			zap.WithCaller(true),
-		},
-	}
+	opts := logutil.NewJSONLoggerOptions()
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(crzap.New(crzap.UseFlagOptions(&opts)))
 
+	if err := logutil.ConfigureAuditSinksFromEnv(setupLog); err != nil {
+		setupLog.Error(err, "unable to configure audit sinks")
+		os.Exit(1)
+	}
+
 	if sharedutil.IsProfilingEnabled() {
 		setupLog.Info("Starting pprof profiler server", "address", profilerAddr)
 		go sharedutil.StartProfilers(profilerAddr)
@@ -99,11 +98,20 @@ func main() {
 		return
 	}
 
+	// Restrict the Secret, DeploymentTarget and DeploymentTargetClaim informers to the labeled namespaces, if configured
+	newCache, err := appstudioredhatcomcontrollers.GetNamespaceScopedNewCacheFunc(setupLog)
+	if err != nil {
+		setupLog.Error(err, "unable to configure namespace-scoped informers")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
+		NewCache:               newCache,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
+		HealthProbeBindAddress: "0", // served by healthServer, below
+		NewClient:              sharedutil.ReconcileTimingNewClientFunc(),
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "53746cb8.redhat.com",
 	})
@@ -140,9 +148,18 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "SnapshotEnvironmentBinding")
 		os.Exit(1)
 	}
-	if err = (&appstudioredhatcomcontrollers.EnvironmentReconciler{
+	if err = (&appstudioredhatcomcontrollers.EphemeralBindingReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Clock:  sharedutil.NewClock(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "EphemeralSnapshotEnvironmentBinding")
+		os.Exit(1)
+	}
+	if err = (&appstudioredhatcomcontrollers.EnvironmentReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("environment-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Environment")
 		os.Exit(1)
@@ -171,20 +188,27 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Environment")
 			os.Exit(1)
 		}
+
+		if err = (&appstudioredhatcomcontrollers.DeploymentTargetValidator{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DeploymentTarget")
+			os.Exit(1)
+		}
 	}
 
 	if err = (&appstudioredhatcomcontrollers.DeploymentTargetClaimReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("deploymenttargetclaim-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DeploymentTargetClaim")
 		os.Exit(1)
 	}
 
 	if err = (&appstudioredhatcomcontrollers.DeploymentTargetReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Clock:  sharedutil.NewClock(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("deploymenttarget-controller"),
+		Clock:    sharedutil.NewClock(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DeploymentTarget")
 		os.Exit(1)
@@ -208,12 +232,13 @@ func main() {
 
 	//+kubebuilder:scaffold:builder
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
-	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
+	// The health probe endpoints are served by healthServer (rather than by the manager), as they report a JSON body
+	// describing the result of each check.
+	healthServer := health.NewServer(probeAddr, setupLog.WithName("health"))
+	healthServer.Healthz.AddCheck("ping", func(context.Context) error { return nil })
+	healthServer.Readyz.AddCheck("informer-cache", health.CacheSyncCheck(mgr.GetCache()))
+	if err := mgr.Add(healthServer); err != nil {
+		setupLog.Error(err, "unable to set up health checks")
 		os.Exit(1)
 	}
 
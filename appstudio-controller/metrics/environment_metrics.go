@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DeploymentTargetClaim binding metrics:
+//
+// The GitOpsDeploymentManagedEnvironment of an Environment that references a DeploymentTargetClaim can only be
+// generated once the claim is bound to a DeploymentTarget, and the cluster credentials of the DeploymentTarget have
+// been copied to the managed environment secret. The time this takes is recorded, so that SLOs can be defined for the
+// provisioning of Environments.
+
+var (
+	// EnvironmentDTCBindingDuration is the time from when an Environment that references a DeploymentTargetClaim was
+	// created, to when its GitOpsDeploymentManagedEnvironment was created.
+	EnvironmentDTCBindingDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "gitops_environment_dtc_binding_duration_seconds",
+			Help:    "Time from the creation of an Environment that references a DeploymentTargetClaim, to the creation of its GitOpsDeploymentManagedEnvironment, in seconds",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		},
+	)
+
+	// EnvironmentsWaitingForDTCBinding is the number of Environments that are waiting for their DeploymentTargetClaim
+	// to be bound, before their GitOpsDeploymentManagedEnvironment can be generated.
+	EnvironmentsWaitingForDTCBinding = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gitops_environments_waiting_for_dtc_binding",
+			Help: "Number of Environments that are waiting for their DeploymentTargetClaim to be bound",
+		},
+	)
+)
+
+// Stale Environment metrics:
+//
+// An Environment is stale if its GitOpsDeploymentManagedEnvironment has had no successful deployments or connection for
+// longer than the staleness threshold of the Environment controller. Sandbox platforms may alert on, and reclaim, the
+// clusters of stale Environments.
+
+var (
+	// StaleEnvironments is the number of Environments that are stale.
+	StaleEnvironments = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gitops_environments_stale",
+			Help: "Number of Environments whose GitOpsDeploymentManagedEnvironment has had no successful deployments or connection for longer than the staleness threshold",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(EnvironmentDTCBindingDuration, EnvironmentsWaitingForDTCBinding, StaleEnvironments)
+}
+
+var (
+	// waitingEnvironmentsMutex protects waitingEnvironments, as the Environment controller may run multiple reconciles concurrently
+	waitingEnvironmentsMutex sync.Mutex
+
+	// waitingEnvironments is the set of Environments that are waiting for their DeploymentTargetClaim to be bound
+	waitingEnvironments = map[types.NamespacedName]bool{}
+)
+
+// SetEnvironmentWaitingForDTCBinding records whether an Environment is waiting for its DeploymentTargetClaim to be
+// bound. Environments that were deleted, or no longer reference a DeploymentTargetClaim, should be set as not waiting.
+func SetEnvironmentWaitingForDTCBinding(env types.NamespacedName, waiting bool) {
+	waitingEnvironmentsMutex.Lock()
+	defer waitingEnvironmentsMutex.Unlock()
+
+	if waiting {
+		waitingEnvironments[env] = true
+	} else {
+		delete(waitingEnvironments, env)
+	}
+
+	EnvironmentsWaitingForDTCBinding.Set(float64(len(waitingEnvironments)))
+}
+
+// ObserveEnvironmentDTCBindingDuration records the time it took to generate the GitOpsDeploymentManagedEnvironment of
+// an Environment that references a DeploymentTargetClaim, from the creation of the Environment. The Environment is
+// no longer waiting for its DeploymentTargetClaim to be bound.
+func ObserveEnvironmentDTCBindingDuration(env types.NamespacedName, duration time.Duration) {
+	SetEnvironmentWaitingForDTCBinding(env, false)
+	EnvironmentDTCBindingDuration.Observe(duration.Seconds())
+}
+
+// TestOnly_resetEnvironmentsWaitingForDTCBinding clears the set of Environments that are waiting for their
+// DeploymentTargetClaim to be bound.
+func TestOnly_resetEnvironmentsWaitingForDTCBinding() {
+	waitingEnvironmentsMutex.Lock()
+	defer waitingEnvironmentsMutex.Unlock()
+
+	waitingEnvironments = map[types.NamespacedName]bool{}
+	EnvironmentsWaitingForDTCBinding.Set(0)
+}
+
+var (
+	// staleEnvironmentsMutex protects staleEnvironments, as the Environment controller may run multiple reconciles concurrently
+	staleEnvironmentsMutex sync.Mutex
+
+	// staleEnvironments is the set of Environments that are stale
+	staleEnvironments = map[types.NamespacedName]bool{}
+)
+
+// SetEnvironmentStale records whether an Environment is stale. Environments that were deleted should be set as not stale.
+func SetEnvironmentStale(env types.NamespacedName, stale bool) {
+	staleEnvironmentsMutex.Lock()
+	defer staleEnvironmentsMutex.Unlock()
+
+	if stale {
+		staleEnvironments[env] = true
+	} else {
+		delete(staleEnvironments, env)
+	}
+
+	StaleEnvironments.Set(float64(len(staleEnvironments)))
+}
+
+// TestOnly_resetStaleEnvironments clears the set of Environments that are stale.
+func TestOnly_resetStaleEnvironments() {
+	staleEnvironmentsMutex.Lock()
+	defer staleEnvironmentsMutex.Unlock()
+
+	staleEnvironments = map[types.NamespacedName]bool{}
+	StaleEnvironments.Set(0)
+}
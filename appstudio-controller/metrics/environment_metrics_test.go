@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Environment DeploymentTargetClaim binding metrics", func() {
+
+	BeforeEach(func() {
+		TestOnly_resetEnvironmentsWaitingForDTCBinding()
+	})
+
+	getSampleCount := func() uint64 {
+		metric := &dto.Metric{}
+		Expect(EnvironmentDTCBindingDuration.Write(metric)).To(Succeed())
+		return metric.GetHistogram().GetSampleCount()
+	}
+
+	It("should count each Environment that is waiting for its DeploymentTargetClaim once", func() {
+
+		envA := types.NamespacedName{Namespace: "test-namespace", Name: "env-a"}
+		envB := types.NamespacedName{Namespace: "test-namespace", Name: "env-b"}
+
+		SetEnvironmentWaitingForDTCBinding(envA, true)
+		SetEnvironmentWaitingForDTCBinding(envA, true)
+		SetEnvironmentWaitingForDTCBinding(envB, true)
+		Expect(testutil.ToFloat64(EnvironmentsWaitingForDTCBinding)).To(Equal(float64(2)))
+
+		SetEnvironmentWaitingForDTCBinding(envB, false)
+		Expect(testutil.ToFloat64(EnvironmentsWaitingForDTCBinding)).To(Equal(float64(1)))
+	})
+
+	It("should observe the binding duration, and no longer count the Environment as waiting", func() {
+
+		env := types.NamespacedName{Namespace: "test-namespace", Name: "env"}
+
+		SetEnvironmentWaitingForDTCBinding(env, true)
+		sampleCount := getSampleCount()
+
+		ObserveEnvironmentDTCBindingDuration(env, 45*time.Second)
+
+		Expect(getSampleCount()).To(Equal(sampleCount + 1))
+		Expect(testutil.ToFloat64(EnvironmentsWaitingForDTCBinding)).To(Equal(float64(0)))
+	})
+})
+
+var _ = Describe("Stale Environment metrics", func() {
+
+	BeforeEach(func() {
+		TestOnly_resetStaleEnvironments()
+	})
+
+	It("should count each stale Environment once, until it is no longer stale", func() {
+
+		envA := types.NamespacedName{Namespace: "test-namespace", Name: "env-a"}
+		envB := types.NamespacedName{Namespace: "test-namespace", Name: "env-b"}
+
+		SetEnvironmentStale(envA, true)
+		SetEnvironmentStale(envA, true)
+		SetEnvironmentStale(envB, true)
+		Expect(testutil.ToFloat64(StaleEnvironments)).To(Equal(float64(2)))
+
+		SetEnvironmentStale(envA, false)
+		Expect(testutil.ToFloat64(StaleEnvironments)).To(Equal(float64(1)))
+
+		SetEnvironmentStale(envA, false)
+		Expect(testutil.ToFloat64(StaleEnvironments)).To(Equal(float64(1)))
+	})
+})
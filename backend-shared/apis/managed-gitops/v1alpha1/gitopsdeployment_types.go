@@ -17,7 +17,11 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // GitOpsDeploymentSpec defines the desired state of GitOpsDeployment
@@ -37,9 +41,232 @@ type GitOpsDeploymentSpec struct {
 	// - Manual: Argo CD should never be told to resynchronize. Instead, synchronize operations will be triggered via GitOpsDeploymentSyncRun operations only.
 	// - See `GitOpsDeploymentSpecType*`
 	//
+	// If empty, the 'type' value of the 'gitopsdeployment-defaults' ConfigMap of the namespace is used (if it exists),
+	// otherwise the GitOpsDeployment is Manual.
+	//
 	// Note: This is somewhat of a placeholder for more advanced logic that can be implemented in the future.
 	// For an example of this type of logic, see the 'syncPolicy' field of Argo CD Application.
-	Type string `json:"type"`
+	Type string `json:"type,omitempty"`
+
+	// CommonLabels are labels that are added to every resource deployed by the GitOpsDeployment, for example, to
+	// identify the workspace/application of the resources to cost and policy tooling.
+	// These are set via the 'commonLabels' option of Argo CD's Kustomize support, and thus require the source path to
+	// contain a Kustomization.
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// CommonAnnotations are annotations that are added to every resource deployed by the GitOpsDeployment.
+	// As with CommonLabels, these require the source path to contain a Kustomization.
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+
+	// DeletionPolicy controls what happens to the resources deployed by the GitOpsDeployment, when it is deleted:
+	// - Foreground: the resources are deleted, and the GitOpsDeployment is only removed once they have been deleted.
+	// - Background: the GitOpsDeployment is removed immediately, and the resources are deleted in the background.
+	// - Orphan: the resources are left on the cluster (they are no longer managed by the GitOps Service).
+	// - See `GitOpsDeploymentDeletionPolicy*`
+	//
+	// If empty, the resources are deleted in the background.
+	DeletionPolicy GitOpsDeploymentDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// ResourceHealthChecks are custom health checks, written in Lua, for kinds of resources deployed by the GitOpsDeployment
+	// whose health Argo CD cannot otherwise assess (for example, Knative Services).
+	// See https://argo-cd.readthedocs.io/en/stable/operator-manual/health/#custom-health-checks
+	//
+	// The health checks are added to the configuration of the Argo CD instance of the GitOpsDeployment. As a health check
+	// applies to every resource of its group/kind in the Argo CD instance, it is not added if the Argo CD instance, or
+	// another GitOpsDeployment, already defines a different health check for the same group/kind.
+	ResourceHealthChecks []ResourceHealthCheck `json:"resourceHealthChecks,omitempty"`
+}
+
+// ResourceHealthCheck is a custom Argo CD health check, for the resources of a group/kind.
+type ResourceHealthCheck struct {
+	// Group is the API group of the resources (empty for the core API group)
+	Group string `json:"group,omitempty"`
+
+	// Kind is the kind of the resources
+	Kind string `json:"kind"`
+
+	// Check is the Lua script that returns the health of a resource
+	Check string `json:"check"`
+}
+
+// MaxResourceHealthCheckLength is the maximum length (in bytes) of the Lua script of a ResourceHealthCheck.
+const MaxResourceHealthCheckLength = 16 * 1024
+
+// GitOpsDeploymentDeletionPolicy controls whether the resources deployed by a GitOpsDeployment are deleted along with it.
+type GitOpsDeploymentDeletionPolicy string
+
+const (
+	GitOpsDeploymentDeletionPolicy_Foreground GitOpsDeploymentDeletionPolicy = "Foreground"
+	GitOpsDeploymentDeletionPolicy_Background GitOpsDeploymentDeletionPolicy = "Background"
+	GitOpsDeploymentDeletionPolicy_Orphan     GitOpsDeploymentDeletionPolicy = "Orphan"
+)
+
+// IsValid returns true if the deletion policy is empty, or one of the supported deletion policies, false otherwise.
+func (policy GitOpsDeploymentDeletionPolicy) IsValid() bool {
+	switch policy {
+	case "", GitOpsDeploymentDeletionPolicy_Foreground, GitOpsDeploymentDeletionPolicy_Background, GitOpsDeploymentDeletionPolicy_Orphan:
+		return true
+	}
+	return false
+}
+
+// RequiresDeletionFinalizer returns true if the GitOpsDeployment must not be removed until the Argo CD Application has
+// been deleted: with Foreground, until its resources have been deleted, and with Orphan, until Argo CD has been told to
+// leave the resources behind.
+func (policy GitOpsDeploymentDeletionPolicy) RequiresDeletionFinalizer() bool {
+	return policy == GitOpsDeploymentDeletionPolicy_Foreground || policy == GitOpsDeploymentDeletionPolicy_Orphan
+}
+
+// ValidateCommonMetadata returns an error if .spec.commonLabels or .spec.commonAnnotations contain a key or value
+// that is not a valid Kubernetes label/annotation.
+func (spec GitOpsDeploymentSpec) ValidateCommonMetadata() error {
+	return validateMetadata(spec.CommonLabels, ".spec.commonLabels", spec.CommonAnnotations, ".spec.commonAnnotations")
+}
+
+// ValidateManagedNamespaceMetadata returns an error if .spec.syncPolicy.managedNamespaceMetadata contains a key or value
+// that is not a valid Kubernetes label/annotation, or if it is set without the 'CreateNamespace=true' sync option (in
+// which case Argo CD would silently ignore it).
+func (spec GitOpsDeploymentSpec) ValidateManagedNamespaceMetadata() error {
+
+	if spec.SyncPolicy == nil || spec.SyncPolicy.ManagedNamespaceMetadata == nil {
+		return nil
+	}
+	metadata := spec.SyncPolicy.ManagedNamespaceMetadata
+
+	if err := validateMetadata(metadata.Labels, ".spec.syncPolicy.managedNamespaceMetadata.labels",
+		metadata.Annotations, ".spec.syncPolicy.managedNamespaceMetadata.annotations"); err != nil {
+		return err
+	}
+
+	createNamespace := false
+	for _, syncOption := range spec.SyncPolicy.SyncOptions {
+		if syncOption.Name() == SyncOptions_CreateNamespace_true.Name() {
+			createNamespace = syncOption == SyncOptions_CreateNamespace_true
+		}
+	}
+	if !createNamespace {
+		return fmt.Errorf(".spec.syncPolicy.managedNamespaceMetadata requires the '%s' sync option in .spec.syncPolicy.syncOptions",
+			SyncOptions_CreateNamespace_true)
+	}
+
+	return nil
+}
+
+// ValidateSourceDirectory returns an error if .spec.source.directory is set along with another type of source (the
+// Helm options, or .spec.commonLabels/.spec.commonAnnotations, which require a Kustomization), or if it contains an
+// invalid Jsonnet variable.
+func (spec GitOpsDeploymentSpec) ValidateSourceDirectory() error {
+
+	directory := spec.Source.Directory
+	if directory == nil {
+		return nil
+	}
+
+	if spec.Source.Helm != nil {
+		return fmt.Errorf(".spec.source.directory and .spec.source.helm cannot both be set")
+	}
+
+	if len(spec.CommonLabels) > 0 || len(spec.CommonAnnotations) > 0 {
+		return fmt.Errorf(".spec.source.directory cannot be set along with .spec.commonLabels or .spec.commonAnnotations, which require a Kustomization")
+	}
+
+	if directory.Jsonnet == nil {
+		return nil
+	}
+
+	if err := validateJsonnetVars(directory.Jsonnet.ExtVars, ".spec.source.directory.jsonnet.extVars"); err != nil {
+		return err
+	}
+
+	return validateJsonnetVars(directory.Jsonnet.TLAs, ".spec.source.directory.jsonnet.tlas")
+}
+
+// ValidateResourceHealthChecks returns an error if .spec.resourceHealthChecks contains an invalid group/kind, an empty or
+// overly long check, or more than one health check for the same group/kind.
+func (spec GitOpsDeploymentSpec) ValidateResourceHealthChecks() error {
+
+	groupKinds := map[string]bool{}
+
+	for _, healthCheck := range spec.ResourceHealthChecks {
+
+		if healthCheck.Group != "" {
+			if errs := validation.IsDNS1123Subdomain(healthCheck.Group); len(errs) > 0 {
+				return fmt.Errorf("the group '%s' in .spec.resourceHealthChecks is not a valid API group: %s", healthCheck.Group, strings.Join(errs, ", "))
+			}
+		}
+
+		if !isValidKind(healthCheck.Kind) {
+			return fmt.Errorf("the kind '%s' in .spec.resourceHealthChecks is not a valid kind", healthCheck.Kind)
+		}
+
+		groupKind := healthCheck.Group + "/" + healthCheck.Kind
+
+		if strings.TrimSpace(healthCheck.Check) == "" {
+			return fmt.Errorf("the check of '%s' in .spec.resourceHealthChecks must be non-empty", groupKind)
+		}
+
+		if len(healthCheck.Check) > MaxResourceHealthCheckLength {
+			return fmt.Errorf("the check of '%s' in .spec.resourceHealthChecks must not be longer than %d bytes", groupKind, MaxResourceHealthCheckLength)
+		}
+
+		if groupKinds[groupKind] {
+			return fmt.Errorf("more than one health check is defined for '%s' in .spec.resourceHealthChecks", groupKind)
+		}
+		groupKinds[groupKind] = true
+	}
+
+	return nil
+}
+
+// isValidKind returns true if the kind begins with a letter, and contains only letters and digits, false otherwise.
+func isValidKind(kind string) bool {
+	if kind == "" {
+		return false
+	}
+	for i, c := range kind {
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !isLetter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func validateJsonnetVars(vars []JsonnetVar, field string) error {
+
+	names := map[string]bool{}
+
+	for _, jsonnetVar := range vars {
+		if strings.TrimSpace(jsonnetVar.Name) == "" {
+			return fmt.Errorf("the name of each variable in %s must be non-empty", field)
+		}
+		if names[jsonnetVar.Name] {
+			return fmt.Errorf("the variable '%s' is defined more than once in %s", jsonnetVar.Name, field)
+		}
+		names[jsonnetVar.Name] = true
+	}
+
+	return nil
+}
+
+func validateMetadata(labels map[string]string, labelsField string, annotations map[string]string, annotationsField string) error {
+
+	for key, value := range labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("the key '%s' in %s is not a valid label key: %s", key, labelsField, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("the value of '%s' in %s is not a valid label value: %s", key, labelsField, strings.Join(errs, ", "))
+		}
+	}
+
+	for key := range annotations {
+		if errs := validation.IsQualifiedName(strings.ToLower(key)); len(errs) > 0 {
+			return fmt.Errorf("the key '%s' in %s is not a valid annotation key: %s", key, annotationsField, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
 }
 
 // ApplicationSource contains all required information about the source of an application
@@ -52,6 +279,61 @@ type ApplicationSource struct {
 	// In case of Git, this can be commit, tag, or branch. If omitted, will equal to HEAD.
 	// In case of Helm, this is a semver tag for the Chart's version.
 	TargetRevision string `json:"targetRevision,omitempty"`
+
+	// Helm holds the options of a Helm chart source: it may be set to deploy the chart with environment-specific values.
+	Helm *ApplicationSourceHelm `json:"helm,omitempty"`
+
+	// Directory holds the options of a source that is a plain directory of manifests (or Jsonnet files): it may be set to
+	// deploy the manifests of nested directories, for example, of a mono-repo.
+	Directory *ApplicationSourceDirectory `json:"directory,omitempty"`
+}
+
+// ApplicationSourceDirectory holds the options of an application source that is a plain directory of manifests
+type ApplicationSourceDirectory struct {
+	// Recurse, if true, deploys the manifests of all the subdirectories of .spec.source.path, rather than only the
+	// manifests of the path itself.
+	Recurse bool `json:"recurse,omitempty"`
+
+	// Include is a glob pattern (for example, '*.yaml' or '{config.yaml,env-*.yaml}') of the files to deploy. The pattern
+	// is matched against the path of the file, relative to .spec.source.path.
+	Include string `json:"include,omitempty"`
+
+	// Exclude is a glob pattern of the files not to deploy. It takes precedence over Include.
+	Exclude string `json:"exclude,omitempty"`
+
+	// Jsonnet holds the options used to evaluate the Jsonnet files of the directory.
+	Jsonnet *ApplicationSourceJsonnet `json:"jsonnet,omitempty"`
+}
+
+// ApplicationSourceJsonnet holds the options used to evaluate the Jsonnet files of an application source
+type ApplicationSourceJsonnet struct {
+	// ExtVars is a list of Jsonnet external variables, which are read with 'std.extVar(name)'
+	ExtVars []JsonnetVar `json:"extVars,omitempty"`
+
+	// TLAs is a list of Jsonnet top-level arguments, which are passed to the top-level function of each Jsonnet file
+	TLAs []JsonnetVar `json:"tlas,omitempty"`
+
+	// Libs is a list of additional Jsonnet library search paths, relative to the root of the repository
+	Libs []string `json:"libs,omitempty"`
+}
+
+// JsonnetVar is a Jsonnet external variable or top-level argument
+type JsonnetVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+
+	// Code, if true, evaluates Value as Jsonnet code, rather than as a string
+	Code bool `json:"code,omitempty"`
+}
+
+// ApplicationSourceHelm holds the options of an application source that is a Helm chart
+type ApplicationSourceHelm struct {
+	// ValueFiles is a list of Helm value files to use when generating a template. The paths are relative to .spec.source.path.
+	ValueFiles []string `json:"valueFiles,omitempty"`
+
+	// Values are Helm values (as a YAML block) to use when generating a template. These take precedence over the
+	// values of ValueFiles.
+	Values string `json:"values,omitempty"`
 }
 
 // ApplicationDestination holds information about the application's destination
@@ -74,15 +356,84 @@ type SyncOption string
 const (
 	SyncOptions_CreateNamespace_true  SyncOption = "CreateNamespace=true"
 	SyncOptions_CreateNamespace_false SyncOption = "CreateNamespace=false"
+
+	SyncOptions_ServerSideApply_true  SyncOption = "ServerSideApply=true"
+	SyncOptions_ServerSideApply_false SyncOption = "ServerSideApply=false"
+
+	SyncOptions_Validate_true  SyncOption = "Validate=true"
+	SyncOptions_Validate_false SyncOption = "Validate=false"
+
+	SyncOptions_ApplyOutOfSyncOnly_true  SyncOption = "ApplyOutOfSyncOnly=true"
+	SyncOptions_ApplyOutOfSyncOnly_false SyncOption = "ApplyOutOfSyncOnly=false"
 )
 
+// IsSupported returns true if the sync option is one of the sync options supported by GitOpsDeployment, false otherwise.
+func (syncOption SyncOption) IsSupported() bool {
+	switch syncOption {
+	case SyncOptions_CreateNamespace_true, SyncOptions_CreateNamespace_false,
+		SyncOptions_ServerSideApply_true, SyncOptions_ServerSideApply_false,
+		SyncOptions_Validate_true, SyncOptions_Validate_false,
+		SyncOptions_ApplyOutOfSyncOnly_true, SyncOptions_ApplyOutOfSyncOnly_false:
+		return true
+	}
+	return false
+}
+
+// Name returns the name of the sync option, without its value: for example, 'CreateNamespace' for 'CreateNamespace=true'.
+func (syncOption SyncOption) Name() string {
+	name, _, _ := strings.Cut(string(syncOption), "=")
+	return name
+}
+
+// ConflictingSyncOption returns the first sync option of the list which specifies a different value for the same option
+// as an earlier sync option (for example, 'CreateNamespace=false' after 'CreateNamespace=true'), or "" if there are none.
+func (syncOptions SyncOptions) ConflictingSyncOption() SyncOption {
+
+	valueByName := map[string]SyncOption{}
+
+	for _, syncOption := range syncOptions {
+		if existing, exists := valueByName[syncOption.Name()]; exists && existing != syncOption {
+			return syncOption
+		}
+		valueByName[syncOption.Name()] = syncOption
+	}
+
+	return ""
+}
+
 type SyncPolicy struct {
 	// Options allow you to specify whole app sync-options.
 	// This option may be empty, if and when it is empty it is considered that there are no SyncOptions present.
 	SyncOptions SyncOptions `json:"syncOptions,omitempty"`
+
+	// ManagedNamespaceMetadata contains the labels and annotations that are set on the destination namespace, when it
+	// is created by Argo CD (via the 'CreateNamespace=true' sync option): for example, to set the pod security admission
+	// level of the namespace. This is set via Argo CD's 'syncPolicy.managedNamespaceMetadata'.
+	ManagedNamespaceMetadata *ManagedNamespaceMetadata `json:"managedNamespaceMetadata,omitempty"`
+
+	// Automated controls the automated sync of the GitOpsDeployment. If set, and .spec.type is empty (with no default
+	// type for the namespace), the GitOpsDeployment is automated.
+	// If not set, automated GitOpsDeployments both prune and self-heal.
+	Automated *SyncPolicyAutomated `json:"automated,omitempty"`
 }
 type SyncOptions []SyncOption
 
+// SyncPolicyAutomated controls the behaviour of automated sync.
+type SyncPolicyAutomated struct {
+	// Prune specifies whether resources that are no longer defined in the GitOps repository are deleted during an automated sync.
+	Prune bool `json:"prune,omitempty"`
+
+	// SelfHeal specifies whether resources that differ from the GitOps repository (for example, because they were
+	// modified on the cluster) are synchronized again.
+	SelfHeal bool `json:"selfHeal,omitempty"`
+}
+
+// ManagedNamespaceMetadata contains the labels and annotations of a namespace that is created by Argo CD.
+type ManagedNamespaceMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
 const (
 	GitOpsDeploymentSpecType_Automated = "automated"
 	GitOpsDeploymentSpecType_Manual    = "manual"
@@ -146,6 +497,151 @@ type GitOpsDeploymentStatus struct {
 
 	// ReconciledState contains the last version of the GitOpsDeployment resource that the ArgoCD Controller reconciled
 	ReconciledState ReconciledState `json:"reconciledState"`
+
+	// LastSync contains information about the last sync operation of the GitOpsDeployment
+	LastSync *LastSyncStatus `json:"lastSync,omitempty"`
+
+	// ResolvedRevision is the commit SHA that .spec.source.targetRevision (a branch, tag, or commit SHA) currently
+	// resolves to, in the Git repository. If the target revision does not exist in the repository, the
+	// 'InvalidTargetRevision' condition is set.
+	ResolvedRevision string `json:"resolvedRevision,omitempty"`
+
+	// History contains the most recent revisions that were successfully deployed by the GitOpsDeployment, from newest
+	// to oldest (at most GitOpsDeploymentHistoryLimit entries are reported).
+	History []DeploymentHistoryEntry `json:"history,omitempty"`
+
+	// DiffPreview is a summary of the changes that a sync of the GitOpsDeployment would make. It is only generated when
+	// requested, by setting the 'managed-gitops.redhat.com/diff-preview' annotation to a new request ID.
+	DiffPreview *DiffPreviewStatus `json:"diffPreview,omitempty"`
+
+	// ResourceEstimate is an estimate of the compute resources requested by the running Pods of the GitOpsDeployment.
+	// It is only reported if resource estimates are enabled in the cluster-agent.
+	ResourceEstimate *ResourceEstimateStatus `json:"resourceEstimate,omitempty"`
+
+	// OperationState contains the details of the operation that is currently in progress for the GitOpsDeployment, if
+	// any. It is unset once the operation completes (see LastSync for the result of the last sync operation).
+	OperationState *OperationStateStatus `json:"operationState,omitempty"`
+}
+
+// OperationStateType is the type of an operation that is in progress for a GitOpsDeployment.
+type OperationStateType string
+
+const (
+	// OperationStateTypeSync is a sync operation of Argo CD that was not requested via the GitOps Service, for
+	// example, one that was started by the automated sync policy of the GitOpsDeployment.
+	OperationStateTypeSync OperationStateType = "Sync"
+
+	// OperationStateTypeSyncRun is a sync operation that was requested by a GitOpsDeploymentSyncRun, and started by the
+	// cluster-agent when it processed the corresponding Operation of the backend.
+	OperationStateTypeSyncRun OperationStateType = "SyncRun"
+)
+
+// OperationStateStatus contains the details of an operation that is in progress for a GitOpsDeployment.
+type OperationStateStatus struct {
+	// Type is the type of the operation: 'Sync' or 'SyncRun'
+	Type OperationStateType `json:"type"`
+
+	// StartedAt is the time the operation started
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// Message is a human-readable description of the progress of the operation, as reported by Argo CD
+	Message string `json:"message,omitempty"`
+
+	// EstimatedCompletionAt is a hint of when the operation will complete, based on how long the previous sync of the
+	// GitOpsDeployment took. It is unset if there is no previous sync. The operation may complete earlier or later.
+	EstimatedCompletionAt *metav1.Time `json:"estimatedCompletionAt,omitempty"`
+}
+
+// ResourceEstimateStatus is the sum of the CPU and memory requests of the running Pods deployed by the GitOpsDeployment.
+type ResourceEstimateStatus struct {
+	// CollectedAt is the time the estimate was collected
+	CollectedAt *metav1.Time `json:"collectedAt,omitempty"`
+
+	// CPURequests is the sum of the CPU requests of the Pods, as a quantity (for example, '1500m')
+	CPURequests string `json:"cpuRequests,omitempty"`
+
+	// MemoryRequests is the sum of the memory requests of the Pods, as a quantity (for example, '2Gi')
+	MemoryRequests string `json:"memoryRequests,omitempty"`
+
+	// Pods is the number of Pods whose requests are included in the estimate. At most 100 Pods are included: see TotalPods.
+	Pods int `json:"pods"`
+
+	// TotalPods is the number of running Pods of the GitOpsDeployment
+	TotalPods int `json:"totalPods"`
+
+	// Error is non-empty if the estimate could not be collected
+	Error string `json:"error,omitempty"`
+}
+
+// DiffPreviewStatus is a summary of the changes that a sync of the GitOpsDeployment would make, between the live state of
+// its resources and their target state in the Git repository.
+type DiffPreviewStatus struct {
+	// RequestID is the value of the 'managed-gitops.redhat.com/diff-preview' annotation that the preview was generated for
+	RequestID string `json:"requestID"`
+
+	// GeneratedAt is the time the preview was generated
+	GeneratedAt *metav1.Time `json:"generatedAt,omitempty"`
+
+	// Resources are the resources that a sync would create, update or delete. At most 100 resources are reported: see TotalResources.
+	Resources []ResourceDiffSummary `json:"resources,omitempty"`
+
+	// TotalResources is the number of resources that a sync would create, update or delete
+	TotalResources int `json:"totalResources"`
+
+	// Error is non-empty if the preview could not be generated
+	Error string `json:"error,omitempty"`
+}
+
+// ResourceDiffSummary summarizes the changes that a sync of the GitOpsDeployment would make to one of its resources.
+type ResourceDiffSummary struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+
+	// Action is one of: 'Create', 'Update', 'Delete'
+	Action string `json:"action"`
+
+	// ChangedFields is the number of fields of the resource that would be added, changed or removed
+	ChangedFields int `json:"changedFields"`
+}
+
+// GitOpsDeploymentHistoryLimit is the maximum number of entries that are reported in .status.history
+const GitOpsDeploymentHistoryLimit = 10
+
+// DeploymentHistoryEntry describes a revision that was successfully deployed by the GitOpsDeployment.
+type DeploymentHistoryEntry struct {
+	// Revision is the revision (e.g. Git commit SHA) that was deployed
+	Revision string `json:"revision"`
+
+	// Images is the list of container images that were deployed by the revision, if known
+	Images []string `json:"images,omitempty"`
+
+	// Initiator is who/what triggered the sync operation that deployed the revision, if known. See LastSyncStatus.Initiator.
+	Initiator string `json:"initiator,omitempty"`
+
+	// DeployedAt is the time the revision was deployed
+	DeployedAt metav1.Time `json:"deployedAt"`
+}
+
+// LastSyncStatus contains information about the last sync operation of the GitOpsDeployment: what initiated it,
+// when it started and finished, and the revision that was synced.
+type LastSyncStatus struct {
+	// Initiator is who/what triggered the sync operation:
+	// - 'automated': the automated sync policy of the GitOpsDeployment
+	// - 'webhook': the automated sync policy of the GitOpsDeployment, in response to a Git webhook event
+	// - 'GitOpsDeploymentSyncRun/(name)': the GitOpsDeploymentSyncRun with the given name
+	// - 'user/(username)': a user, outside of the GitOps Service (for example, via the Argo CD Web UI)
+	Initiator string `json:"initiator,omitempty"`
+
+	// StartedAt is the time the sync operation started
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// FinishedAt is the time the sync operation completed (unset if the sync operation is still in progress)
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+
+	// Revision is the revision (e.g. Git commit SHA) that was synced
+	Revision string `json:"revision,omitempty"`
 }
 
 // HealthStatus contains information about the currently observed health state of an application or resource
@@ -219,6 +715,18 @@ type GitOpsDeploymentConditionType string
 const (
 	GitOpsDeploymentConditionSyncError     GitOpsDeploymentConditionType = "SyncError"
 	GitOpsDeploymentConditionErrorOccurred GitOpsDeploymentConditionType = "ErrorOccurred"
+
+	// GitOpsDeploymentConditionInvalidTargetRevision is set if .spec.source.targetRevision is not a branch, tag, or
+	// commit SHA of the Git repository.
+	GitOpsDeploymentConditionInvalidTargetRevision GitOpsDeploymentConditionType = "InvalidTargetRevision"
+
+	// GitOpsDeploymentConditionUnsupportedAPIGroups is set if the GitOpsDeployment deploys resources of API groups that
+	// are not available on the target cluster of its environment.
+	GitOpsDeploymentConditionUnsupportedAPIGroups GitOpsDeploymentConditionType = "UnsupportedAPIGroups"
+
+	// GitOpsDeploymentConditionServiceDegraded is set while the GitOps Service is in read-only mode (for example, during
+	// database maintenance): changes to the GitOpsDeployment are not processed until read-only mode is disabled.
+	GitOpsDeploymentConditionServiceDegraded GitOpsDeploymentConditionType = "ServiceDegraded"
 )
 
 // GitOpsConditionStatus is a type which represents possible comparison results
@@ -239,6 +747,20 @@ type GitOpsDeploymentReasonType string
 const (
 	GitopsDeploymentReasonSyncError     GitOpsDeploymentReasonType = "SyncError"
 	GitopsDeploymentReasonErrorOccurred GitOpsDeploymentReasonType = "ErrorOccurred"
+
+	GitopsDeploymentReasonInvalidTargetRevision GitOpsDeploymentReasonType = "InvalidTargetRevision"
+	GitopsDeploymentReasonUnsupportedAPIGroups  GitOpsDeploymentReasonType = "UnsupportedAPIGroups"
+
+	// GitopsDeploymentReasonInstanceCapacityExceeded is the reason of the ErrorOccurred condition, if the Argo CD
+	// instances that are able to deploy the GitOpsDeployment have all reached their capacity.
+	GitopsDeploymentReasonInstanceCapacityExceeded GitOpsDeploymentReasonType = "InstanceCapacityExceeded"
+
+	// GitopsDeploymentReasonRepositoryNotAllowed is the reason of the ErrorOccurred condition, if the repository of the
+	// GitOpsDeployment is not in the .spec.allowedRepositories of the managed environment it targets.
+	GitopsDeploymentReasonRepositoryNotAllowed GitOpsDeploymentReasonType = "RepositoryNotAllowed"
+
+	GitopsDeploymentReasonReadOnlyMode         GitOpsDeploymentReasonType = "ReadOnlyMode"
+	GitopsDeploymentReasonReadOnlyModeDisabled GitOpsDeploymentReasonType = "ReadOnlyModeDisabled"
 )
 
 const (
@@ -248,6 +770,7 @@ const (
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Sync Status",type=string,JSONPath=`.status.sync.status`
 // +kubebuilder:printcolumn:name="Health Status",type=string,JSONPath=`.status.health.status`
 
@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,10 +41,49 @@ func (r *GitOpsDeployment) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 var _ webhook.Defaulter = &GitOpsDeployment{}
 
+const (
+	// DefaultSyncPolicyEnvVar configures the sync policy that is set on GitOpsDeployments that do not specify
+	// .spec.syncPolicy, so that their sync behaviour is consistent across an install (rather than falling back to
+	// the defaults of Argo CD). One of:
+	// - "automated": automated sync, which self-heals, but does not prune resources
+	// - "automated-prune": automated sync, which self-heals and prunes resources
+	// - "none" (or unset): .spec.syncPolicy is not defaulted
+	DefaultSyncPolicyEnvVar = "GITOPSDEPLOYMENT_DEFAULT_SYNC_POLICY"
+
+	DefaultSyncPolicy_Automated      = "automated"
+	DefaultSyncPolicy_AutomatedPrune = "automated-prune"
+	DefaultSyncPolicy_None           = "none"
+)
+
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *GitOpsDeployment) Default() {
 	gitopsdeploymentlog.Info("default", "name", r.Name)
 
+	if r.Spec.SyncPolicy != nil {
+		return
+	}
+
+	if automated := defaultSyncPolicyAutomated(); automated != nil {
+		r.Spec.SyncPolicy = &SyncPolicy{Automated: automated}
+	}
+}
+
+// defaultSyncPolicyAutomated returns the automated sync policy configured by DefaultSyncPolicyEnvVar, or nil if
+// .spec.syncPolicy should not be defaulted.
+func defaultSyncPolicyAutomated() *SyncPolicyAutomated {
+
+	switch value := strings.TrimSpace(os.Getenv(DefaultSyncPolicyEnvVar)); value {
+	case DefaultSyncPolicy_Automated:
+		return &SyncPolicyAutomated{SelfHeal: true, Prune: false}
+	case DefaultSyncPolicy_AutomatedPrune:
+		return &SyncPolicyAutomated{SelfHeal: true, Prune: true}
+	case "", DefaultSyncPolicy_None:
+		return nil
+	default:
+		gitopsdeploymentlog.Error(nil, "unrecognized value of env var: the sync policy of the GitOpsDeployment is not defaulted",
+			"envVar", DefaultSyncPolicyEnvVar, "value", value)
+		return nil
+	}
 }
 
 //+kubebuilder:webhook:path=/validate-managed-gitops-redhat-com-v1alpha1-gitopsdeployment,mutating=false,failurePolicy=fail,sideEffects=None,groups=managed-gitops.redhat.com,resources=gitopsdeployments,verbs=create;update,versions=v1alpha1,name=vgitopsdeployment.kb.io,admissionReviewVersions=v1
@@ -80,8 +121,8 @@ func (r *GitOpsDeployment) ValidateDelete() error {
 
 func (r *GitOpsDeployment) ValidateGitOpsDeployment() error {
 
-	// Check whether Type is manual or automated
-	if !(r.Spec.Type == GitOpsDeploymentSpecType_Automated || r.Spec.Type == GitOpsDeploymentSpecType_Manual) {
+	// Check whether Type is manual or automated (or empty, in which case the namespace default is used)
+	if !(r.Spec.Type == "" || r.Spec.Type == GitOpsDeploymentSpecType_Automated || r.Spec.Type == GitOpsDeploymentSpecType_Manual) {
 		return fmt.Errorf("spec type must be manual or automated")
 	}
 
@@ -89,12 +130,35 @@ func (r *GitOpsDeployment) ValidateGitOpsDeployment() error {
 	if r.Spec.SyncPolicy != nil {
 		for _, syncOptionString := range r.Spec.SyncPolicy.SyncOptions {
 
-			if !(syncOptionString == SyncOptions_CreateNamespace_true ||
-				syncOptionString == SyncOptions_CreateNamespace_false) {
+			if !syncOptionString.IsSupported() {
 				return fmt.Errorf("the specified sync option in .spec.syncPolicy.syncOptions is either mispelled or is not supported by GitOpsDeployment")
 			}
 
 		}
+
+		if conflicting := r.Spec.SyncPolicy.SyncOptions.ConflictingSyncOption(); conflicting != "" {
+			return fmt.Errorf("the sync option '%s' in .spec.syncPolicy.syncOptions conflicts with another value of the same option", conflicting.Name())
+		}
+	}
+
+	if err := r.Spec.ValidateCommonMetadata(); err != nil {
+		return err
+	}
+
+	if err := r.Spec.ValidateManagedNamespaceMetadata(); err != nil {
+		return err
+	}
+
+	if err := r.Spec.ValidateSourceDirectory(); err != nil {
+		return err
+	}
+
+	if err := r.Spec.ValidateResourceHealthChecks(); err != nil {
+		return err
+	}
+
+	if !r.Spec.DeletionPolicy.IsValid() {
+		return fmt.Errorf("spec deletionPolicy must be Foreground, Background or Orphan")
 	}
 
 	return nil
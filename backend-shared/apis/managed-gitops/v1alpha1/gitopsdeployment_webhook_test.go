@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"context"
+	"os"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -70,6 +71,243 @@ var _ = Describe("GitOpsDeployment validation webhook", func() {
 
 	})
 
+	Context("Create  GitOpsDeployment CR with conflicting .spec.syncPolicy.syncOptions field", func() {
+		It("Should fail with error saying the sync option conflicts with another value of the same option", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				SyncOptions: SyncOptions{
+					SyncOptions_ServerSideApply_true,
+					SyncOptions_Validate_false,
+					SyncOptions_ServerSideApply_false,
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("the sync option 'ServerSideApply' in .spec.syncPolicy.syncOptions conflicts with another value of the same option"))
+
+		})
+
+	})
+
+	Context("Create  GitOpsDeployment CR with invalid .spec.commonLabels field", func() {
+		It("Should fail with error saying the label key is not valid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.CommonLabels = map[string]string{
+				"invalid key!": "my-value",
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("the key 'invalid key!' in .spec.commonLabels is not a valid label key"))
+		})
+
+		It("Should fail with error saying the label value is not valid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.CommonLabels = map[string]string{
+				"my-label": "invalid value!",
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("the value of 'my-label' in .spec.commonLabels is not a valid label value"))
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with invalid .spec.commonAnnotations field", func() {
+		It("Should fail with error saying the annotation key is not valid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.CommonAnnotations = map[string]string{
+				"invalid/key/": "any value is valid!",
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("the key 'invalid/key/' in .spec.commonAnnotations is not a valid annotation key"))
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with invalid .spec.syncPolicy.managedNamespaceMetadata field", func() {
+		It("Should fail with error saying the label key is not valid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				SyncOptions: SyncOptions{SyncOptions_CreateNamespace_true},
+				ManagedNamespaceMetadata: &ManagedNamespaceMetadata{
+					Labels: map[string]string{"invalid key!": "restricted"},
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("the key 'invalid key!' in .spec.syncPolicy.managedNamespaceMetadata.labels is not a valid label key"))
+		})
+
+		It("Should fail with error saying the CreateNamespace=true sync option is required", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				ManagedNamespaceMetadata: &ManagedNamespaceMetadata{
+					Labels: map[string]string{"pod-security.kubernetes.io/enforce": "restricted"},
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(".spec.syncPolicy.managedNamespaceMetadata requires the 'CreateNamespace=true' sync option"))
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with valid .spec.syncPolicy.managedNamespaceMetadata", func() {
+		It("Should succeed", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				SyncOptions: SyncOptions{SyncOptions_CreateNamespace_true},
+				ManagedNamespaceMetadata: &ManagedNamespaceMetadata{
+					Labels:      map[string]string{"pod-security.kubernetes.io/enforce": "restricted"},
+					Annotations: map[string]string{"example.com/owner": "team a"},
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with invalid .spec.source.directory field", func() {
+		It("Should fail with error saying the directory and Helm options cannot both be set", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source.Directory = &ApplicationSourceDirectory{Recurse: true}
+			gitopsDepl.Spec.Source.Helm = &ApplicationSourceHelm{ValueFiles: []string{"values.yaml"}}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(".spec.source.directory and .spec.source.helm cannot both be set"))
+		})
+
+		It("Should fail with error saying a Jsonnet variable is defined more than once", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source.Directory = &ApplicationSourceDirectory{
+				Jsonnet: &ApplicationSourceJsonnet{
+					TLAs: []JsonnetVar{{Name: "env", Value: "staging"}, {Name: "env", Value: "production"}},
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("the variable 'env' is defined more than once in .spec.source.directory.jsonnet.tlas"))
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with valid .spec.source.directory", func() {
+		It("Should succeed", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source.Directory = &ApplicationSourceDirectory{
+				Recurse: true,
+				Exclude: "test/*",
+				Jsonnet: &ApplicationSourceJsonnet{
+					ExtVars: []JsonnetVar{{Name: "env", Value: "staging"}},
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with invalid .spec.resourceHealthChecks field", func() {
+		It("Should fail with error saying the kind is not valid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.ResourceHealthChecks = []ResourceHealthCheck{{Group: "example.com", Kind: "my-widget", Check: "return {}"}}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("the kind 'my-widget' in .spec.resourceHealthChecks is not a valid kind"))
+		})
+
+		It("Should fail with error saying more than one health check is defined for the group/kind", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.ResourceHealthChecks = []ResourceHealthCheck{
+				{Group: "example.com", Kind: "Widget", Check: "return {}"},
+				{Group: "example.com", Kind: "Widget", Check: "return {status = 'Healthy'}"},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("more than one health check is defined for 'example.com/Widget' in .spec.resourceHealthChecks"))
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with valid .spec.resourceHealthChecks", func() {
+		It("Should succeed", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.ResourceHealthChecks = []ResourceHealthCheck{
+				{Group: "example.com", Kind: "Widget", Check: "return {status = 'Healthy'}"},
+				{Kind: "ConfigMap", Check: "return {status = 'Healthy'}"},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with invalid .spec.deletionPolicy field", func() {
+		It("Should fail with error saying the deletion policy must be Foreground, Background or Orphan", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.DeletionPolicy = "Cascade"
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("spec deletionPolicy must be Foreground, Background or Orphan"))
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with valid .spec.commonLabels and .spec.commonAnnotations", func() {
+		It("Should succeed", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.CommonLabels = map[string]string{
+				"appstudio.redhat.com/workspace": "my-workspace",
+			}
+			gitopsDepl.Spec.CommonAnnotations = map[string]string{
+				"example.com/cost-center": "any value is valid!",
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("Create  GitOpsDeployment CR with all supported .spec.syncPolicy.syncOptions", func() {
+		It("Should succeed", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				SyncOptions: SyncOptions{
+					SyncOptions_CreateNamespace_true,
+					SyncOptions_ServerSideApply_true,
+					SyncOptions_Validate_false,
+					SyncOptions_ApplyOutOfSyncOnly_true,
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).To(BeNil())
+		})
+
+	})
+
 	Context("Update  GitOpsDeployment CR with invalid .spec.Type field", func() {
 		It("Should fail with error saying spec type must be manual or automated", func() {
 			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
@@ -115,4 +353,47 @@ var _ = Describe("GitOpsDeployment validation webhook", func() {
 
 		})
 	})
+
+	Context("Default .spec.syncPolicy of GitOpsDeployment CR", func() {
+
+		AfterEach(func() {
+			Expect(os.Unsetenv(DefaultSyncPolicyEnvVar)).To(Succeed())
+		})
+
+		It("Should set an automated sync policy which self-heals but does not prune, if .spec.syncPolicy is omitted", func() {
+			Expect(os.Setenv(DefaultSyncPolicyEnvVar, DefaultSyncPolicy_Automated)).To(Succeed())
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).To(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy.Automated).To(Equal(&SyncPolicyAutomated{SelfHeal: true, Prune: false}))
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).To(BeNil())
+		})
+
+		It("Should not modify .spec.syncPolicy, if it is specified", func() {
+			Expect(os.Setenv(DefaultSyncPolicyEnvVar, DefaultSyncPolicy_AutomatedPrune)).To(Succeed())
+
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{SyncOptions: SyncOptions{SyncOptions_CreateNamespace_true}}
+			gitopsDepl.Default()
+			Expect(gitopsDepl.Spec.SyncPolicy.Automated).To(BeNil())
+
+			gitopsDepl.Spec.SyncPolicy = nil
+			gitopsDepl.Default()
+			Expect(gitopsDepl.Spec.SyncPolicy.Automated).To(Equal(&SyncPolicyAutomated{SelfHeal: true, Prune: true}))
+		})
+
+		It("Should not set .spec.syncPolicy, if the default sync policy is not configured", func() {
+			gitopsDepl.Default()
+			Expect(gitopsDepl.Spec.SyncPolicy).To(BeNil())
+
+			Expect(os.Setenv(DefaultSyncPolicyEnvVar, "not-a-sync-policy")).To(Succeed())
+			gitopsDepl.Default()
+			Expect(gitopsDepl.Spec.SyncPolicy).To(BeNil())
+		})
+	})
 })
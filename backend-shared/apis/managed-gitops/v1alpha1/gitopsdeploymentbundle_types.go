@@ -0,0 +1,147 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// MaxGitOpsDeploymentBundleSize is the maximum number of GitOpsDeployments in a GitOpsDeploymentBundle
+	MaxGitOpsDeploymentBundleSize = 100
+
+	// GitOpsDeploymentBundleConditionApplied indicates whether all the GitOpsDeployments of the bundle have been applied
+	GitOpsDeploymentBundleConditionApplied = "Applied"
+
+	GitOpsDeploymentBundleReasonApplied       = "Applied"
+	GitOpsDeploymentBundleReasonInvalidSpec   = "InvalidSpec"
+	GitOpsDeploymentBundleReasonNameConflict  = "NameConflict"
+	GitOpsDeploymentBundleReasonErrorOccurred = "ErrorOccurred"
+)
+
+// GitOpsDeploymentBundleSpec defines the desired state of GitOpsDeploymentBundle
+type GitOpsDeploymentBundleSpec struct {
+	// Deployments are the GitOpsDeployments of the bundle, which are created (or updated) in the namespace of the bundle.
+	// GitOpsDeployments that are removed from the bundle are deleted.
+	Deployments []GitOpsDeploymentBundleItem `json:"deployments"`
+}
+
+// GitOpsDeploymentBundleItem is a GitOpsDeployment of a GitOpsDeploymentBundle
+type GitOpsDeploymentBundleItem struct {
+	// Name of the GitOpsDeployment
+	Name string `json:"name"`
+
+	// Labels (optional) are added to the GitOpsDeployment
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations (optional) are added to the GitOpsDeployment
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Spec of the GitOpsDeployment
+	Spec GitOpsDeploymentSpec `json:"spec"`
+}
+
+// GitOpsDeploymentBundleStatus defines the observed state of GitOpsDeploymentBundle
+type GitOpsDeploymentBundleStatus struct {
+	// ObservedGeneration is the .metadata.generation of the bundle that was most recently processed
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Deployments are the names of the GitOpsDeployments that were applied from the bundle
+	Deployments []string `json:"deployments,omitempty"`
+
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ValidateGitOpsDeploymentBundle returns an error describing all the invalid GitOpsDeployments of the bundle, or nil
+// if every GitOpsDeployment is valid: either all the GitOpsDeployments of the bundle are applied, or none are.
+func (r *GitOpsDeploymentBundle) ValidateGitOpsDeploymentBundle() error {
+
+	if len(r.Spec.Deployments) == 0 {
+		return fmt.Errorf(".spec.deployments must contain at least one GitOpsDeployment")
+	}
+
+	if len(r.Spec.Deployments) > MaxGitOpsDeploymentBundleSize {
+		return fmt.Errorf(".spec.deployments must not contain more than %d GitOpsDeployments", MaxGitOpsDeploymentBundleSize)
+	}
+
+	errs := []string{}
+	names := map[string]bool{}
+
+	for _, item := range r.Spec.Deployments {
+
+		if nameErrs := validation.IsDNS1123Subdomain(item.Name); len(nameErrs) > 0 {
+			errs = append(errs, fmt.Sprintf("'%s': the name is not valid: %s", item.Name, strings.Join(nameErrs, ", ")))
+			continue
+		}
+
+		if names[item.Name] {
+			errs = append(errs, fmt.Sprintf("'%s': the name is used by more than one GitOpsDeployment", item.Name))
+			continue
+		}
+		names[item.Name] = true
+
+		if err := validateMetadata(item.Labels, "labels", item.Annotations, "annotations"); err != nil {
+			errs = append(errs, fmt.Sprintf("'%s': %v", item.Name, err))
+			continue
+		}
+
+		gitopsDepl := GitOpsDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: item.Name, Namespace: r.Namespace},
+			Spec:       item.Spec,
+		}
+		if err := gitopsDepl.ValidateGitOpsDeployment(); err != nil {
+			errs = append(errs, fmt.Sprintf("'%s': %v", item.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid GitOpsDeployments in .spec.deployments: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// GitOpsDeploymentBundle is a set of GitOpsDeployments that are submitted together, for example by a CI pipeline that
+// deploys many components. The bundle is validated as a whole: if any of its GitOpsDeployments is invalid (or conflicts
+// with a GitOpsDeployment that is not part of the bundle), none of them are applied.
+type GitOpsDeploymentBundle struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitOpsDeploymentBundleSpec   `json:"spec,omitempty"`
+	Status GitOpsDeploymentBundleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitOpsDeploymentBundleList contains a list of GitOpsDeploymentBundle
+type GitOpsDeploymentBundleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitOpsDeploymentBundle `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitOpsDeploymentBundle{}, &GitOpsDeploymentBundleList{})
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var gitopsdeploymentbundlelog = logf.Log.WithName(logutil.LogLogger_managed_gitops)
+
+func (r *GitOpsDeploymentBundle) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-managed-gitops-redhat-com-v1alpha1-gitopsdeploymentbundle,mutating=false,failurePolicy=fail,sideEffects=None,groups=managed-gitops.redhat.com,resources=gitopsdeploymentbundles,verbs=create;update,versions=v1alpha1,name=vgitopsdeploymentbundle.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &GitOpsDeploymentBundle{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *GitOpsDeploymentBundle) ValidateCreate() error {
+	gitopsdeploymentbundlelog.Info("validate create", "name", r.Name)
+
+	return r.ValidateGitOpsDeploymentBundle()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *GitOpsDeploymentBundle) ValidateUpdate(old runtime.Object) error {
+	gitopsdeploymentbundlelog.Info("validate update", "name", r.Name)
+
+	return r.ValidateGitOpsDeploymentBundle()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *GitOpsDeploymentBundle) ValidateDelete() error {
+	gitopsdeploymentbundlelog.Info("validate delete", "name", r.Name)
+
+	return nil
+}
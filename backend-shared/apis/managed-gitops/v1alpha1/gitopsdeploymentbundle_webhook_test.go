@@ -0,0 +1,96 @@
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	//+kubebuilder:scaffold:imports
+)
+
+var _ = Describe("GitOpsDeploymentBundle validation webhook", func() {
+	var namespace *corev1.Namespace
+	var bundle *GitOpsDeploymentBundle
+	var ctx context.Context
+
+	newBundleItem := func(name string) GitOpsDeploymentBundleItem {
+		return GitOpsDeploymentBundleItem{
+			Name: name,
+			Spec: GitOpsDeploymentSpec{
+				Source: ApplicationSource{
+					RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+					Path:    "resources/test-data/sample-gitops-repository/environments/overlays/dev",
+				},
+				Type: GitOpsDeploymentSpecType_Automated,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+
+		ctx = context.Background()
+
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-bundle",
+				UID:  uuid.NewUUID(),
+			},
+			Spec: corev1.NamespaceSpec{},
+		}
+		if err := k8sClient.Create(ctx, namespace); err != nil {
+			Expect(apierr.IsAlreadyExists(err)).To(BeTrue())
+		}
+
+		bundle = &GitOpsDeploymentBundle{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-bundle",
+				Namespace: namespace.Name,
+			},
+			Spec: GitOpsDeploymentBundleSpec{
+				Deployments: []GitOpsDeploymentBundleItem{newBundleItem("component-a"), newBundleItem("component-b")},
+			},
+		}
+	})
+
+	Context("Create GitOpsDeploymentBundle CR with invalid GitOpsDeployments", func() {
+		It("Should fail with an error describing every invalid GitOpsDeployment", func() {
+			bundle.Spec.Deployments[0].Spec.Type = "sometimes"
+			bundle.Spec.Deployments[1].Spec.DeletionPolicy = "Cascade"
+
+			err := k8sClient.Create(ctx, bundle)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("'component-a': spec type must be manual or automated"))
+			Expect(err.Error()).Should(ContainSubstring("'component-b': spec deletionPolicy must be Foreground, Background or Orphan"))
+		})
+
+		It("Should fail with error saying the name is used by more than one GitOpsDeployment", func() {
+			bundle.Spec.Deployments = append(bundle.Spec.Deployments, newBundleItem("component-a"))
+
+			err := k8sClient.Create(ctx, bundle)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("'component-a': the name is used by more than one GitOpsDeployment"))
+		})
+
+		It("Should fail with error saying a label key is not valid", func() {
+			bundle.Spec.Deployments[0].Labels = map[string]string{"not a valid key": "value"}
+
+			err := k8sClient.Create(ctx, bundle)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("the key 'not a valid key' in labels is not a valid label key"))
+		})
+	})
+
+	Context("Create GitOpsDeploymentBundle CR with valid GitOpsDeployments", func() {
+		It("Should succeed", func() {
+			err := k8sClient.Create(ctx, bundle)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), bundle)
+			Expect(err).To(BeNil())
+		})
+	})
+})
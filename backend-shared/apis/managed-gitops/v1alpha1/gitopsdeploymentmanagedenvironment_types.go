@@ -17,11 +17,54 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
 	ManagedEnvironmentStatusConnectionInitializationSucceeded = "ConnectionInitializationSucceeded"
+
+	// ManagedEnvironmentStatusDeletionBlocked is set on a managed environment with deletion protection enabled, when
+	// it has been deleted but is still targeted by one or more GitOpsDeployments.
+	ManagedEnvironmentStatusDeletionBlocked = "DeletionBlocked"
+
+	// ManagedEnvironmentDeletionProtectionFinalizer is added to managed environments that have .spec.deletionProtection
+	// set, and is only removed once no GitOpsDeployments target the managed environment.
+	ManagedEnvironmentDeletionProtectionFinalizer = "managed-gitops.redhat.com/deletion-protection"
+
+	// ManagedEnvironmentShareableClusterSecretLabel must be set to "true" on an Argo CD cluster secret, in order for it to be
+	// referenced by the .spec.argoCDClusterSecret field of a managed environment.
+	ManagedEnvironmentShareableClusterSecretLabel = "managed-gitops.redhat.com/shareable-cluster-secret"
+
+	// ManagedEnvironmentServiceAccountAnnotation may be set on a managed environment to the name of a ServiceAccount, as
+	// '(name)' or '(namespace)/(name)' (the namespace defaults to 'kube-system'). If set, the GitOps Service uses the
+	// (admin) kubeconfig of .spec.credentialsSecret to create that ServiceAccount on the cluster, grant it access, and
+	// mint a token for it, which Argo CD then uses to deploy: this is equivalent to 'argocd cluster add'.
+	ManagedEnvironmentServiceAccountAnnotation = "managed-gitops.redhat.com/service-account"
+
+	// ManagedEnvironmentServiceAccountDefaultNamespace is the namespace of the ServiceAccount of the
+	// ManagedEnvironmentServiceAccountAnnotation annotation, if no namespace is specified.
+	ManagedEnvironmentServiceAccountDefaultNamespace = "kube-system"
+)
+
+// Well-known keys of .spec.clusterLabels, which describe the topology of a managed environment. Other keys may also be used.
+const (
+	// ManagedEnvironmentClusterLabelRegion is the region (or data center) of the cluster, for example: 'us-east-1'
+	ManagedEnvironmentClusterLabelRegion = "region"
+
+	// ManagedEnvironmentClusterLabelCloud is the cloud provider of the cluster, for example: 'aws', 'gcp', 'azure' or 'on-premise'
+	ManagedEnvironmentClusterLabelCloud = "cloud"
+
+	// ManagedEnvironmentClusterLabelTier is the tier of the cluster, for example: 'development', 'staging' or 'production'
+	ManagedEnvironmentClusterLabelTier = "tier"
+
+	// MaxManagedEnvironmentClusterLabels is the maximum number of labels that may be specified in .spec.clusterLabels
+	MaxManagedEnvironmentClusterLabels = 20
 )
 
 // The GitOpsDeploymentManagedEnvironment CR describes a remote cluster which the GitOps Service will deploy to, via Argo CD.
@@ -34,7 +77,20 @@ type GitOpsDeploymentManagedEnvironmentSpec struct {
 	APIURL string `json:"apiURL"`
 
 	// ClusterCredentialsSecret is a reference to a Secret that contains cluster connection details. The cluster details should be in the form of a kubeconfig file.
-	ClusterCredentialsSecret string `json:"credentialsSecret"`
+	//
+	// Either this field, or .spec.argoCDClusterSecret, must be specified.
+	ClusterCredentialsSecret string `json:"credentialsSecret,omitempty"`
+
+	// ArgoCDClusterSecret is the name of an existing Argo CD cluster secret, in the namespace of the Argo CD instance used by the
+	// GitOps Service: for example, a cluster that an administrator added via 'argocd cluster add'. The cluster connection details
+	// (and ServiceAccount bearer token) are read from that Secret, rather than from a kubeconfig.
+	//
+	// Optional, defaults to empty. Cannot be used with .spec.credentialsSecret or .spec.createNewServiceAccount.
+	//
+	// - The Argo CD cluster secret must have the 'managed-gitops.redhat.com/shareable-cluster-secret: "true"' label, which indicates
+	//   that an administrator has allowed it to be referenced by managed environments.
+	// - The server of the Argo CD cluster secret must match .spec.apiURL.
+	ArgoCDClusterSecret string `json:"argoCDClusterSecret,omitempty"`
 
 	// AllowInsecureSkipTLSVerify controls whether Argo CD will accept a Kubernetes API URL with untrusted-TLS certificate.
 	// Optional: If true, the GitOps Service will allow Argo CD to connect to the specified cluster even if it is using an invalid or self-signed TLS certificate.
@@ -72,6 +128,194 @@ type GitOpsDeploymentManagedEnvironmentSpec struct {
 	//
 	// Optional, default to false.
 	ClusterResources bool `json:"clusterResources,omitempty"`
+
+	// DeletionProtection controls whether the managed environment may be deleted while it is still targeted by GitOpsDeployments.
+	//
+	// Optional, default to false.
+	//
+	// - If true, deletion of the managed environment will be blocked until all GitOpsDeployments that target it
+	//   have been deleted (or no longer target it). The blocking GitOpsDeployments are reported in the 'DeletionBlocked' condition.
+	// - If false, the managed environment is deleted immediately, and the GitOpsDeployments that target it will report an error.
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// Disconnected may be set to true to temporarily stop Argo CD from connecting to the cluster, for example while the
+	// cluster credentials are revoked.
+	//
+	// Optional, default to false.
+	//
+	// - If true, the Argo CD cluster secret of the managed environment is removed, and the GitOps Service no longer connects
+	//   to the cluster. The GitOpsDeployments that target the managed environment are retained (with an Unknown health and
+	//   sync status), and the resources they deployed are NOT deleted from the cluster.
+	// - Once set back to false, the cluster credentials are re-read from the Secret, and the Argo CD cluster secret is recreated.
+	Disconnected bool `json:"disconnected,omitempty"`
+
+	// RotateCredentialsRequestedAt may be set (or updated) to the current time to request that the GitOps Service rotate the
+	// credentials of the managed environment: the Secret is re-read, and Argo CD is updated to use the new credentials.
+	//
+	// Optional, defaults to nil.
+	//
+	// - Credentials are rotated whenever the value of this field changes. This allows the credentials of the Secret to be
+	//   rotated without recreating the GitOpsDeploymentManagedEnvironment.
+	RotateCredentialsRequestedAt *metav1.Time `json:"rotateCredentialsRequestedAt,omitempty"`
+
+	// NamespaceQuota contains hints for the amount of CPU/memory that each namespace of the managed environment, that
+	// is deployed to by the GitOps Service, should be limited to.
+	//
+	// Optional, defaults to nil (no quota).
+	//
+	// - If the cluster-agent is configured to do so, it will create a ResourceQuota in each namespace that is deployed to,
+	//   with these values. Otherwise, this field is informational only.
+	NamespaceQuota *ManagedEnvironmentNamespaceQuota `json:"namespaceQuota,omitempty"`
+
+	// ProxyURL is the URL of an HTTP(S) proxy (for example, 'http://proxy.example.com:3128') that the GitOps Service and
+	// Argo CD should use to connect to the cluster, for example, when the cluster is behind a corporate proxy.
+	//
+	// Optional, defaults to empty.
+	//
+	// - If empty, the proxy configured by the HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables of the GitOps Service
+	//   (and Argo CD) components is used, if any.
+	// - If set, it overrides those environment variables (including NO_PROXY) for connections to this cluster.
+	// - If you are familiar with Argo CD: this field is equivalent to the 'proxyUrl' field of the Argo CD cluster secret config.
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// ClusterLabels are labels that describe the topology of the cluster, for example: its region ('region: us-east-1'),
+	// cloud provider ('cloud: aws') and tier ('tier: production'). See the ManagedEnvironmentClusterLabel* constants for
+	// the well-known keys, but other keys may also be used.
+	//
+	// Optional, defaults to empty.
+	//
+	// - The labels are stored in the database with the managed environment, so that managed environments may be listed
+	//   (and grouped) by their topology, for example by placement policies and the UI.
+	// - Keys and values must be valid Kubernetes label keys and values, and at most 20 labels may be specified.
+	ClusterLabels map[string]string `json:"clusterLabels,omitempty"`
+
+	// AllowedRepositories restricts the Git repositories that GitOpsDeployments may deploy from, to this managed environment.
+	// Each entry is either a repository URL (for example, 'https://github.com/my-org/my-repo'), or a glob pattern of
+	// repository URLs (for example, 'https://github.com/my-org/*').
+	//
+	// Optional, defaults to empty. If empty, GitOpsDeployments may deploy from any repository.
+	//
+	// - Repository URLs are compared case-insensitively, and ignoring a trailing '/' or '.git' suffix.
+	// - Patterns use the syntax of Go's 'path.Match': '*' does not match a '/', so 'https://github.com/my-org/*' matches
+	//   the repositories of the 'my-org' organization only.
+	// - A GitOpsDeployment that targets this managed environment, but whose .spec.source.repoURL is not allowed, is not
+	//   deployed: it reports an ErrorOccurred condition with reason 'RepositoryNotAllowed'.
+	AllowedRepositories []string `json:"allowedRepositories,omitempty"`
+}
+
+// ValidateProxyURL returns an error if .spec.proxyURL is non-empty, and is not an absolute http, https or socks5 URL.
+func (spec GitOpsDeploymentManagedEnvironmentSpec) ValidateProxyURL() error {
+
+	if spec.ProxyURL == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(spec.ProxyURL)
+	if err != nil {
+		return fmt.Errorf("proxy url is not a valid URL: %v", err)
+	}
+
+	if (proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5") || proxyURL.Host == "" {
+		return fmt.Errorf("proxy url must start with http://, https:// or socks5://, and specify a host")
+	}
+
+	return nil
+}
+
+// ValidateClusterLabels returns an error if .spec.clusterLabels has too many labels, or a label with an invalid key or value.
+func (spec GitOpsDeploymentManagedEnvironmentSpec) ValidateClusterLabels() error {
+
+	if len(spec.ClusterLabels) > MaxManagedEnvironmentClusterLabels {
+		return fmt.Errorf("cluster labels must not contain more than %d labels", MaxManagedEnvironmentClusterLabels)
+	}
+
+	for key, value := range spec.ClusterLabels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("cluster label key '%s' is invalid: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("cluster label value '%s' of key '%s' is invalid: %s", value, key, strings.Join(errs, "; "))
+		}
+	}
+
+	return nil
+}
+
+// ValidateAllowedRepositories returns an error if an entry of .spec.allowedRepositories is empty, or is not a valid pattern.
+func (spec GitOpsDeploymentManagedEnvironmentSpec) ValidateAllowedRepositories() error {
+
+	for _, allowedRepository := range spec.AllowedRepositories {
+		if strings.TrimSpace(allowedRepository) == "" {
+			return fmt.Errorf("allowed repositories must not contain an empty entry")
+		}
+		if _, err := path.Match(normalizeRepositoryURL(allowedRepository), ""); err != nil {
+			return fmt.Errorf("allowed repository '%s' is not a valid pattern: %v", allowedRepository, err)
+		}
+	}
+
+	return nil
+}
+
+// IsRepositoryAllowed returns true if GitOpsDeployments may deploy from the given repository URL to the managed
+// environment: that is, if .spec.allowedRepositories is empty, or the URL matches one of its entries.
+func (spec GitOpsDeploymentManagedEnvironmentSpec) IsRepositoryAllowed(repoURL string) bool {
+
+	if len(spec.AllowedRepositories) == 0 {
+		return true
+	}
+
+	normalizedRepoURL := normalizeRepositoryURL(repoURL)
+
+	for _, allowedRepository := range spec.AllowedRepositories {
+		// An invalid pattern never matches (invalid patterns are rejected by the webhook)
+		if match, err := path.Match(normalizeRepositoryURL(allowedRepository), normalizedRepoURL); err == nil && match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeRepositoryURL returns the repository URL in lowercase, without surrounding whitespace, or a trailing '/' or '.git'
+func normalizeRepositoryURL(repoURL string) string {
+	res := strings.ToLower(strings.TrimSpace(repoURL))
+	res = strings.TrimSuffix(res, "/")
+	res = strings.TrimSuffix(res, ".git")
+	return res
+}
+
+// ParseManagedEnvironmentServiceAccountAnnotation returns the namespace and name of the ServiceAccount of the
+// 'managed-gitops.redhat.com/service-account' annotation value, or an error if it is not a valid '(name)' or '(namespace)/(name)'.
+func ParseManagedEnvironmentServiceAccountAnnotation(value string) (string, string, error) {
+
+	namespace, name := ManagedEnvironmentServiceAccountDefaultNamespace, value
+	if strings.Contains(value, "/") {
+		namespace, name, _ = strings.Cut(value, "/")
+	}
+
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", "", fmt.Errorf("service account annotation '%s' has an invalid namespace: %s", value, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", "", fmt.Errorf("service account annotation '%s' has an invalid name: %s", value, strings.Join(errs, "; "))
+	}
+
+	return namespace, name, nil
+}
+
+// ManagedEnvironmentNamespaceQuota describes the resources that a namespace of a managed environment should be limited to.
+// The values use the Kubernetes resource quantity format, for example: '500m' or '2' (CPU), '512Mi' or '4Gi' (memory).
+type ManagedEnvironmentNamespaceQuota struct {
+
+	// CPU is the total amount of CPU that may be requested by the pods of the namespace.
+	//
+	// Optional, defaults to empty (no limit).
+	CPU string `json:"cpu,omitempty"`
+
+	// Memory is the total amount of memory that may be requested by the pods of the namespace.
+	//
+	// Optional, defaults to empty (no limit).
+	Memory string `json:"memory,omitempty"`
 }
 
 type AllowInsecureSkipTLSVerify bool
@@ -87,6 +331,15 @@ const (
 // GitOpsDeploymentManagedEnvironmentStatus defines the observed state of GitOpsDeploymentManagedEnvironment
 type GitOpsDeploymentManagedEnvironmentStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// KubernetesVersion is the version of Kubernetes running on the target cluster (for example, 'v1.25.3'), as
+	// discovered when the GitOps Service last connected to it.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// APIGroups is the sorted list of API groups available on the target cluster (not including the core API group),
+	// as discovered when the GitOps Service last connected to it. GitOpsDeployments that deploy resources of other API
+	// groups to this environment will have an UnsupportedAPIGroups condition.
+	APIGroups []string `json:"apiGroups,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -119,6 +372,13 @@ const (
 	ConditionReasonInvalidNamespaceList               ManagedEnvironmentConditionReason = "InvalidNamespaceList"
 	ConditionReasonUnableToRetrieveRestConfig         ManagedEnvironmentConditionReason = "UnableToRetrieveRestConfig"
 	ConditionReasonUnknownError                       ManagedEnvironmentConditionReason = "UnknownError"
+	ConditionReasonTargetedByGitOpsDeployments        ManagedEnvironmentConditionReason = "TargetedByGitOpsDeployments"
+	ConditionReasonInvalidNamespaceQuota              ManagedEnvironmentConditionReason = "InvalidNamespaceQuota"
+	ConditionReasonInvalidArgoCDClusterSecret         ManagedEnvironmentConditionReason = "InvalidArgoCDClusterSecret"
+	ConditionReasonDisconnected                       ManagedEnvironmentConditionReason = "Disconnected"
+	ConditionReasonInvalidProxyURL                    ManagedEnvironmentConditionReason = "InvalidProxyURL"
+	ConditionReasonInvalidClusterLabels               ManagedEnvironmentConditionReason = "InvalidClusterLabels"
+	ConditionReasonInvalidServiceAccountAnnotation    ManagedEnvironmentConditionReason = "InvalidServiceAccountAnnotation"
 )
 
 //+kubebuilder:object:root=true
@@ -91,5 +91,26 @@ func (r *GitOpsDeploymentManagedEnvironment) ValidateGitOpsDeploymentManagedEnv(
 		}
 	}
 
+	if err := r.Spec.ValidateProxyURL(); err != nil {
+		return err
+	}
+
+	if err := r.Spec.ValidateClusterLabels(); err != nil {
+		return err
+	}
+
+	if err := r.Spec.ValidateAllowedRepositories(); err != nil {
+		return err
+	}
+
+	if serviceAccount, exists := r.Annotations[ManagedEnvironmentServiceAccountAnnotation]; exists {
+		if _, _, err := ParseManagedEnvironmentServiceAccountAnnotation(serviceAccount); err != nil {
+			return err
+		}
+		if r.Spec.ArgoCDClusterSecret != "" {
+			return fmt.Errorf("the %s annotation cannot be used with argoCDClusterSecret", ManagedEnvironmentServiceAccountAnnotation)
+		}
+	}
+
 	return nil
 }
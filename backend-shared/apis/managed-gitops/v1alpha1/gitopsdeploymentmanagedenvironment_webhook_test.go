@@ -78,4 +78,61 @@ var _ = Describe("GitOpsDeploymentManagedEnvironment validation webhook", func()
 		})
 	})
 
+	Context("Create GitOpsDeploymentManagedEnvironment CR with an invalid .spec.proxyURL", func() {
+		It("Should fail with error saying the proxy url must start with http://, https:// or socks5://", func() {
+
+			managedEnv.Name = "my-managed-env-proxy"
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+			managedEnv.Spec.ProxyURL = "proxy.example.com:3128"
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("proxy url must start with http://, https:// or socks5://"))
+		})
+	})
+
+	Context("Create GitOpsDeploymentManagedEnvironment CR with invalid .spec.clusterLabels", func() {
+		It("Should fail with error saying the cluster label is invalid", func() {
+
+			managedEnv.Name = "my-managed-env-cluster-labels"
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+			managedEnv.Spec.ClusterLabels = map[string]string{
+				ManagedEnvironmentClusterLabelRegion: "us-east-1",
+				ManagedEnvironmentClusterLabelTier:   "not a valid label value",
+			}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("cluster label value 'not a valid label value' of key 'tier' is invalid"))
+		})
+	})
+
+	Context("Create GitOpsDeploymentManagedEnvironment CR with invalid .spec.allowedRepositories", func() {
+		It("Should fail with error saying the allowed repository is not a valid pattern", func() {
+
+			managedEnv.Name = "my-managed-env-allowed-repositories"
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+			managedEnv.Spec.AllowedRepositories = []string{"https://github.com/my-org/*", "https://github.com/[my-org/*"}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("allowed repository 'https://github.com/[my-org/*' is not a valid pattern"))
+		})
+	})
+
+	Context("Create GitOpsDeploymentManagedEnvironment CR with an invalid service account annotation", func() {
+		It("Should fail with error saying the service account annotation has an invalid name", func() {
+
+			managedEnv.Name = "my-managed-env-service-account"
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+			managedEnv.Annotations = map[string]string{
+				ManagedEnvironmentServiceAccountAnnotation: "my-namespace/My_Deployer",
+			}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("service account annotation 'my-namespace/My_Deployer' has an invalid name"))
+		})
+	})
+
 })
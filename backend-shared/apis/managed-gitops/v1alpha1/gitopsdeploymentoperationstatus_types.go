@@ -0,0 +1,71 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperationResourceRef identifies the API resource, in the same namespace, that an Operation was created for
+type OperationResourceRef struct {
+	// Kind of the resource, for example 'GitOpsDeployment'
+	Kind string `json:"kind"`
+
+	// Name of the resource
+	Name string `json:"name"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitOpsDeploymentOperationStatus is a read-only copy of the state of the latest Operation of an API resource (such as a
+// GitOpsDeployment), which is maintained by the GitOps Service in the namespace of that resource. It allows users to see
+// whether their change is queued, in progress, or has failed, without access to the Argo CD namespace.
+//
+// Only details that are safe to share with the user are included: for example, the IDs of the database rows, and the
+// namespace of the Argo CD instance, are not.
+type GitOpsDeploymentOperationStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// ResourceRef is the resource that the Operation was created for
+	ResourceRef OperationResourceRef `json:"resourceRef"`
+
+	// State of the Operation: one of Waiting, In_Progress, Completed, Failed
+	State string `json:"state"`
+
+	// Message contains a human-readable description of the state, for example the reason the Operation failed
+	Message string `json:"message,omitempty"`
+
+	// CreatedOn is the time the Operation was created (queued)
+	CreatedOn metav1.Time `json:"createdOn,omitempty"`
+
+	// LastStateUpdate is the time the state of the Operation last changed
+	LastStateUpdate metav1.Time `json:"lastStateUpdate,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitOpsDeploymentOperationStatusList contains a list of GitOpsDeploymentOperationStatus
+type GitOpsDeploymentOperationStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitOpsDeploymentOperationStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitOpsDeploymentOperationStatus{}, &GitOpsDeploymentOperationStatusList{})
+}
@@ -19,8 +19,11 @@ package v1alpha1
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // GitOpsDeploymentRepositoryCredentialSpec defines the desired state of GitOpsDeploymentRepositoryCredential
@@ -34,6 +37,23 @@ type GitOpsDeploymentRepositoryCredentialSpec struct {
 	// Reference to a K8s Secret in the namespace that contains repository credentials (Git username/password, as of this writing)
 	// Required field
 	Secret string `json:"secret"`
+
+	// Scope (optional) restricts the GitOpsDeployments that may use the repository credential. If not set, the credential
+	// is used by any Argo CD Application of the Argo CD instance that deploys from the repository.
+	Scope *RepositoryCredentialScope `json:"scope,omitempty"`
+}
+
+// RepositoryCredentialScope restricts the GitOpsDeployments that may use a repository credential: a GitOpsDeployment
+// is in scope if it is in one of the namespaces, and matches the deployment selector.
+type RepositoryCredentialScope struct {
+
+	// Namespaces are the namespaces of the GitOpsDeployments that may use the credential. If empty, only the
+	// GitOpsDeployments in the namespace of the credential may use it.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// DeploymentSelector (optional) selects, by label, the GitOpsDeployments that may use the credential.
+	// If not set, all the GitOpsDeployments of the namespaces may use it.
+	DeploymentSelector *metav1.LabelSelector `json:"deploymentSelector,omitempty"`
 }
 
 // ErrorOccurred / ValidRepositoryURL / ValidRepositoryCredential
@@ -122,3 +142,66 @@ func findConditionIndex(conditions []metav1.Condition, t string) int {
 	}
 	return -1
 }
+
+// ValidateScope returns an error if a namespace of .spec.scope is not a valid namespace name, or if the deployment
+// selector is invalid.
+func (spec GitOpsDeploymentRepositoryCredentialSpec) ValidateScope() error {
+
+	if spec.Scope == nil {
+		return nil
+	}
+
+	for _, namespace := range spec.Scope.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("scope namespace '%s' is invalid: %s", namespace, strings.Join(errs, "; "))
+		}
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(spec.Scope.DeploymentSelector); err != nil {
+		return fmt.Errorf("scope deployment selector is invalid: %v", err)
+	}
+
+	return nil
+}
+
+// IsDeploymentInScope returns true if the GitOpsDeployment is in the .spec.scope of the repository credential, and
+// deploys from its repository. A credential without a scope does not have any GitOpsDeployments in scope.
+func (r *GitOpsDeploymentRepositoryCredential) IsDeploymentInScope(gitopsDeployment GitOpsDeployment) bool {
+
+	if r.Spec.Scope == nil || !r.IsForRepository(gitopsDeployment.Spec.Source.RepoURL) {
+		return false
+	}
+
+	namespaces := r.Spec.Scope.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{r.Namespace}
+	}
+
+	namespaceInScope := false
+	for _, namespace := range namespaces {
+		if namespace == gitopsDeployment.Namespace {
+			namespaceInScope = true
+			break
+		}
+	}
+	if !namespaceInScope {
+		return false
+	}
+
+	if r.Spec.Scope.DeploymentSelector == nil {
+		return true
+	}
+
+	// An invalid selector never matches (invalid selectors are rejected by the webhook)
+	selector, err := metav1.LabelSelectorAsSelector(r.Spec.Scope.DeploymentSelector)
+	if err != nil {
+		return false
+	}
+
+	return selector.Matches(labels.Set(gitopsDeployment.Labels))
+}
+
+// IsForRepository returns true if the repository credential is for the given repository URL.
+func (r *GitOpsDeploymentRepositoryCredential) IsForRepository(repoURL string) bool {
+	return normalizeRepositoryURL(r.Spec.Repository) == normalizeRepositoryURL(repoURL)
+}
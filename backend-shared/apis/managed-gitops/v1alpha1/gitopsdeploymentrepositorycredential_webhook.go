@@ -91,5 +91,9 @@ func (r *GitOpsDeploymentRepositoryCredential) ValidateGitOpsDeploymentRepoCred(
 		}
 	}
 
+	if err := r.Spec.ValidateScope(); err != nil {
+		return err
+	}
+
 	return nil
 }
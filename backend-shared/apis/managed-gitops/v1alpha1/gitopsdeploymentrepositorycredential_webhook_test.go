@@ -79,4 +79,34 @@ var _ = Describe("GitOpsDeploymentRepositoryCredential validation webhook", func
 		})
 	})
 
+	Context("Create GitOpsDeploymentRepositoryCredential CR with an invalid .spec.scope", func() {
+		It("Should fail with error saying the scope namespace is invalid", func() {
+
+			repoCredentialCr.Spec.Repository = "https://test-private-url"
+			repoCredentialCr.Spec.Scope = &RepositoryCredentialScope{
+				Namespaces: []string{"valid-namespace", "Invalid_Namespace"},
+			}
+
+			err := k8sClient.Create(ctx, repoCredentialCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("scope namespace 'Invalid_Namespace' is invalid"))
+		})
+
+		It("Should fail with error saying the scope deployment selector is invalid", func() {
+
+			repoCredentialCr.Spec.Repository = "https://test-private-url"
+			repoCredentialCr.Spec.Scope = &RepositoryCredentialScope{
+				DeploymentSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "team", Operator: "NotAnOperator", Values: []string{"a"}},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, repoCredentialCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("scope deployment selector is invalid"))
+		})
+	})
+
 })
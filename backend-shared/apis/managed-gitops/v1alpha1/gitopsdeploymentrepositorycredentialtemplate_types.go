@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitOpsDeploymentRepositoryCredentialTemplateSpec defines the desired state of GitOpsDeploymentRepositoryCredentialTemplate
+type GitOpsDeploymentRepositoryCredentialTemplateSpec struct {
+
+	// RepositoryPrefix is the URL prefix (HTTPS url, or SSH string) of the Git repositories that the credentials are used for.
+	// For example, 'https://github.com/my-org/' matches every Git repository of the my-org GitHub organization.
+	// Required field
+	RepositoryPrefix string `json:"repositoryPrefix"`
+
+	// Reference to a K8s Secret in the namespace that contains repository credentials (Git username/password, or SSH private key)
+	// Required field
+	Secret string `json:"secret"`
+}
+
+// ErrorOccurred
+const (
+	GitOpsDeploymentRepositoryCredentialTemplateConditionErrorOccurred = "ErrorOccurred"
+)
+
+// GitOpsDeploymentRepositoryCredentialTemplateStatus defines the observed state of GitOpsDeploymentRepositoryCredentialTemplate
+type GitOpsDeploymentRepositoryCredentialTemplateStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// GitOpsDeploymentRepositoryCredentialTemplate is the Schema for the gitopsdeploymentrepositorycredentialtemplates API.
+// Unlike a GitOpsDeploymentRepositoryCredential, which contains the credentials of a single Git repository, the
+// credentials of a template are used for every Git repository whose URL starts with the repository prefix (for example,
+// for every repository of a GitHub organization). If a repository also has a GitOpsDeploymentRepositoryCredential,
+// the credentials of the GitOpsDeploymentRepositoryCredential are used instead.
+type GitOpsDeploymentRepositoryCredentialTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitOpsDeploymentRepositoryCredentialTemplateSpec   `json:"spec,omitempty"`
+	Status GitOpsDeploymentRepositoryCredentialTemplateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitOpsDeploymentRepositoryCredentialTemplateList contains a list of GitOpsDeploymentRepositoryCredentialTemplate
+type GitOpsDeploymentRepositoryCredentialTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitOpsDeploymentRepositoryCredentialTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitOpsDeploymentRepositoryCredentialTemplate{}, &GitOpsDeploymentRepositoryCredentialTemplateList{})
+}
+
+// IsForRepository returns true if the URL of the given repository starts with the repository prefix of the template.
+func (r *GitOpsDeploymentRepositoryCredentialTemplate) IsForRepository(repoURL string) bool {
+	prefix := strings.ToLower(strings.TrimSpace(r.Spec.RepositoryPrefix))
+	if prefix == "" {
+		return false
+	}
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(repoURL)), prefix)
+}
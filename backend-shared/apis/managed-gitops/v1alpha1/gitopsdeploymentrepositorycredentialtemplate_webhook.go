@@ -0,0 +1,86 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var gitopsdeploymentrepositorycredentialtemplatelog = logf.Log.WithName(logutil.LogLogger_managed_gitops)
+
+func (r *GitOpsDeploymentRepositoryCredentialTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-managed-gitops-redhat-com-v1alpha1-gitopsdeploymentrepositorycredentialtemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=managed-gitops.redhat.com,resources=gitopsdeploymentrepositorycredentialtemplates,verbs=create;update,versions=v1alpha1,name=vgitopsdeploymentrepositorycredentialtemplate.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &GitOpsDeploymentRepositoryCredentialTemplate{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *GitOpsDeploymentRepositoryCredentialTemplate) ValidateCreate() error {
+	gitopsdeploymentrepositorycredentialtemplatelog.Info("validate create", "name", r.Name)
+
+	return r.ValidateGitOpsDeploymentRepoCredTemplate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *GitOpsDeploymentRepositoryCredentialTemplate) ValidateUpdate(old runtime.Object) error {
+	gitopsdeploymentrepositorycredentialtemplatelog.Info("validate update", "name", r.Name)
+
+	return r.ValidateGitOpsDeploymentRepoCredTemplate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *GitOpsDeploymentRepositoryCredentialTemplate) ValidateDelete() error {
+	gitopsdeploymentrepositorycredentialtemplatelog.Info("validate delete", "name", r.Name)
+
+	return nil
+}
+
+// ValidateGitOpsDeploymentRepoCredTemplate returns an error if the repository prefix is not an ssh:// or https:// URL
+// that includes a host: a template for every repository of every host would send the credentials to any Git server.
+func (r *GitOpsDeploymentRepositoryCredentialTemplate) ValidateGitOpsDeploymentRepoCredTemplate() error {
+
+	if r.Spec.RepositoryPrefix == "" {
+		return fmt.Errorf("repository prefix must not be empty")
+	}
+
+	apiURL, err := url.ParseRequestURI(r.Spec.RepositoryPrefix)
+	if err != nil {
+		return fmt.Errorf(err.Error())
+	}
+
+	if !(apiURL.Scheme == "https" || apiURL.Scheme == "ssh") {
+		return fmt.Errorf("repository prefix must begin with ssh:// or https://")
+	}
+
+	if apiURL.Host == "" {
+		return fmt.Errorf("repository prefix must include the host of the Git repositories")
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	//+kubebuilder:scaffold:imports
+)
+
+var _ = Describe("GitOpsDeploymentRepositoryCredentialTemplate validation webhook", func() {
+	var namespace *corev1.Namespace
+	var repoCredTemplate *GitOpsDeploymentRepositoryCredentialTemplate
+	var ctx context.Context
+
+	BeforeEach(func() {
+
+		ctx = context.Background()
+
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-repo-cred-template",
+				UID:  uuid.NewUUID(),
+			},
+			Spec: corev1.NamespaceSpec{},
+		}
+		if err := k8sClient.Create(ctx, namespace); err != nil {
+			Expect(apierr.IsAlreadyExists(err)).To(BeTrue())
+		}
+
+		repoCredTemplate = &GitOpsDeploymentRepositoryCredentialTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-repo-template",
+				Namespace: namespace.Name,
+			},
+			Spec: GitOpsDeploymentRepositoryCredentialTemplateSpec{
+				Secret: "test-secret",
+			},
+		}
+	})
+
+	Context("Create GitOpsDeploymentRepositoryCredentialTemplate CR with invalid repository prefix", func() {
+		It("Should fail with error saying repository prefix must begin with ssh:// or https://", func() {
+
+			repoCredTemplate.Spec.RepositoryPrefix = "smtp://github.com/my-org/"
+			err := k8sClient.Create(ctx, repoCredTemplate)
+
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("repository prefix must begin with ssh:// or https://"))
+		})
+
+		It("Should fail with error saying repository prefix must include the host", func() {
+
+			repoCredTemplate.Spec.RepositoryPrefix = "https:///my-org/"
+			err := k8sClient.Create(ctx, repoCredTemplate)
+
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("repository prefix must include the host"))
+		})
+	})
+
+	Context("Update GitOpsDeploymentRepositoryCredentialTemplate CR with invalid repository prefix", func() {
+		It("Should fail with error saying repository prefix must begin with ssh:// or https://", func() {
+
+			repoCredTemplate.Spec.RepositoryPrefix = "https://github.com/my-org/"
+
+			err := k8sClient.Create(ctx, repoCredTemplate)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(repoCredTemplate), repoCredTemplate)
+			Expect(err).To(Succeed())
+
+			repoCredTemplate.Spec.RepositoryPrefix = "smtp://github.com/my-org/"
+			err = k8sClient.Update(ctx, repoCredTemplate)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).Should(ContainSubstring("repository prefix must begin with ssh:// or https://"))
+
+			err = k8sClient.Delete(context.Background(), repoCredTemplate)
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("Check whether a repository matches the repository prefix of a template", func() {
+		It("Should match only the repositories that start with the prefix", func() {
+
+			repoCredTemplate.Spec.RepositoryPrefix = "https://github.com/my-org/"
+
+			Expect(repoCredTemplate.IsForRepository("https://github.com/my-org/my-repo")).To(BeTrue())
+			Expect(repoCredTemplate.IsForRepository("https://GitHub.com/my-org/my-repo.git")).To(BeTrue())
+			Expect(repoCredTemplate.IsForRepository("https://github.com/other-org/my-repo")).To(BeFalse())
+			Expect(repoCredTemplate.IsForRepository("https://github.com/my-organization/my-repo")).To(BeFalse())
+		})
+	})
+})
@@ -17,6 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,11 +30,122 @@ type GitOpsDeploymentSyncRunSpec struct {
 
 	// Optional: If specified, tells the GitOps Service to deploy a particular git commit SHA
 	RevisionID string `json:"revisionID,omitempty"`
+
+	// Optional: If specified, only the given resources of the GitOpsDeployment are synchronized (rather than all of them).
+	// For example, this may be used to resync a single failing Deployment, without re-applying the whole application.
+	Resources []SyncRunResource `json:"resources,omitempty"`
+
+	// Optional: If specified, a failed sync operation is retried by Argo CD (with backoff), up to the given limit.
+	// For example, this may be used to recover from transient failures, such as an admission webhook timing out,
+	// without creating a new GitOpsDeploymentSyncRun.
+	RetryPolicy *SyncRunRetryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// SyncRunRetryPolicy describes how a failed sync operation of a GitOpsDeploymentSyncRun is retried.
+// It corresponds to the retry strategy of an Argo CD sync operation.
+type SyncRunRetryPolicy struct {
+	// Limit is the maximum number of times the sync operation is retried, after the initial attempt fails. Must be at least 1.
+	Limit int64 `json:"limit"`
+
+	// Backoff controls how long to wait between retries. If not specified, the Argo CD defaults are used.
+	Backoff *SyncRunRetryBackoff `json:"backoff,omitempty"`
+}
+
+// SyncRunRetryBackoff is the backoff strategy between retries of a sync operation.
+type SyncRunRetryBackoff struct {
+	// Duration is the time to wait before the first retry, for example '5s' or '2m'. Defaults to '5s'.
+	Duration string `json:"duration,omitempty"`
+
+	// Factor is the multiplier applied to the duration after each failed retry. Must be at least 1. Defaults to 2.
+	Factor *int64 `json:"factor,omitempty"`
+
+	// MaxDuration is the maximum time to wait between retries, for example '3m'. Defaults to '3m'.
+	MaxDuration string `json:"maxDuration,omitempty"`
+}
+
+// ValidateRetryPolicy returns an error if .spec.retryPolicy is specified, and has a limit less than 1, a backoff
+// duration that is not a positive duration, or a backoff factor less than 1.
+func (spec GitOpsDeploymentSyncRunSpec) ValidateRetryPolicy() error {
+
+	if spec.RetryPolicy == nil {
+		return nil
+	}
+
+	if spec.RetryPolicy.Limit < 1 {
+		return fmt.Errorf("retry policy limit must be at least 1")
+	}
+
+	backoff := spec.RetryPolicy.Backoff
+	if backoff == nil {
+		return nil
+	}
+
+	for fieldName, value := range map[string]string{"duration": backoff.Duration, "maxDuration": backoff.MaxDuration} {
+		if value == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+			return fmt.Errorf("retry policy backoff %s '%s' must be a positive duration, for example '5s' or '2m'", fieldName, value)
+		}
+	}
+
+	if backoff.Factor != nil && *backoff.Factor < 1 {
+		return fmt.Errorf("retry policy backoff factor must be at least 1")
+	}
+
+	return nil
+}
+
+// SyncRunResource identifies a resource of a GitOpsDeployment, to be synchronized by a GitOpsDeploymentSyncRun
+type SyncRunResource struct {
+	// Group of the resource, for example 'apps'. Empty for resources of the core API group.
+	Group string `json:"group,omitempty"`
+
+	// Kind of the resource, for example 'Deployment'
+	Kind string `json:"kind"`
+
+	// Name of the resource
+	Name string `json:"name"`
+
+	// Namespace of the resource. Empty for cluster-scoped resources.
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // GitOpsDeploymentSyncRunStatus defines the observed state of GitOpsDeploymentSyncRun
 type GitOpsDeploymentSyncRunStatus struct {
 	Conditions []GitOpsDeploymentSyncRunCondition `json:"conditions,omitempty"`
+
+	// Hooks contains the status of the resource hooks (for example, PreSync and PostSync Jobs) that were run by the sync
+	// operation of the GitOpsDeploymentSyncRun. For hook Jobs that failed, the tail of the logs of the Job is included, so
+	// that failed hooks (for example, database migrations) may be debugged without access to Argo CD.
+	Hooks []SyncRunHookStatus `json:"hooks,omitempty"`
+}
+
+// SyncRunHookStatus is the status of a resource hook that was run by the sync operation of a GitOpsDeploymentSyncRun
+type SyncRunHookStatus struct {
+	// Group of the hook resource, for example 'batch'. Empty for resources of the core API group.
+	Group string `json:"group,omitempty"`
+
+	// Kind of the hook resource, for example 'Job'
+	Kind string `json:"kind"`
+
+	// Name of the hook resource
+	Name string `json:"name"`
+
+	// Namespace of the hook resource
+	Namespace string `json:"namespace,omitempty"`
+
+	// HookType is when the hook is run: one of 'PreSync', 'Sync', 'PostSync', 'SyncFail', or 'Skip'
+	HookType string `json:"hookType"`
+
+	// Phase of the hook: one of 'Running', 'Terminating', 'Succeeded', 'Failed', or 'Error'
+	Phase string `json:"phase,omitempty"`
+
+	// Message contains details of the phase of the hook, as reported by Argo CD
+	Message string `json:"message,omitempty"`
+
+	// LogTail contains the last lines of the logs of the Pods of the hook, if it is a Job that failed
+	LogTail string `json:"logTail,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -68,6 +182,9 @@ type SyncRunReasonType string
 
 const (
 	SyncRunReasonErrorOccurred GitOpsDeploymentReasonType = "ErrorOccurred"
+
+	SyncRunReasonReadOnlyMode         SyncRunReasonType = "ReadOnlyMode"
+	SyncRunReasonReadOnlyModeDisabled SyncRunReasonType = "ReadOnlyModeDisabled"
 )
 
 // GitOpsDeploymentConditionType represents type of GitOpsDeployment condition.
@@ -75,6 +192,10 @@ type SyncRunConditionType string
 
 const (
 	GitOpsDeploymentSyncRunConditionErrorOccurred SyncRunConditionType = "ErrorOccurred"
+
+	// GitOpsDeploymentSyncRunConditionServiceDegraded is set while the GitOps Service is in read-only mode: the
+	// GitOpsDeploymentSyncRun is not processed until read-only mode is disabled.
+	GitOpsDeploymentSyncRunConditionServiceDegraded SyncRunConditionType = "ServiceDegraded"
 )
 
 //+kubebuilder:object:root=true
@@ -57,6 +57,10 @@ func (r *GitOpsDeploymentSyncRun) ValidateCreate() error {
 		return fmt.Errorf("name should not be zyxwvutsrqponmlkjihgfedcba-abcdefghijklmnoqrstuvwxyz")
 	}
 
+	if err := r.Spec.ValidateRetryPolicy(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -64,6 +68,10 @@ func (r *GitOpsDeploymentSyncRun) ValidateCreate() error {
 func (r *GitOpsDeploymentSyncRun) ValidateUpdate(old runtime.Object) error {
 	gitopsdeploymentsyncrunlog.Info("validate update", "name", r.Name)
 
+	if err := r.Spec.ValidateRetryPolicy(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -55,4 +55,42 @@ var _ = Describe("GitOpsDeploymentSyncRun validation webhook", func() {
 		})
 	})
 
+	Context("Create GitOpsDeploymentSyncRun CR with an invalid retry policy", func() {
+		It("Should fail with error saying the retry policy limit must be at least 1", func() {
+			gitopsDeplSyncRunCr.Name = "syncrun-invalid-retry-limit"
+			gitopsDeplSyncRunCr.Spec.RetryPolicy = &SyncRunRetryPolicy{Limit: 0}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("retry policy limit must be at least 1"))
+		})
+
+		It("Should fail with error saying the retry policy backoff duration must be a positive duration", func() {
+			gitopsDeplSyncRunCr.Name = "syncrun-invalid-retry-backoff"
+			gitopsDeplSyncRunCr.Spec.RetryPolicy = &SyncRunRetryPolicy{
+				Limit:   3,
+				Backoff: &SyncRunRetryBackoff{Duration: "five seconds"},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("retry policy backoff duration 'five seconds' must be a positive duration"))
+		})
+
+		It("Should succeed with a valid retry policy", func() {
+			factor := int64(2)
+			gitopsDeplSyncRunCr.Name = "syncrun-valid-retry-policy"
+			gitopsDeplSyncRunCr.Spec.RetryPolicy = &SyncRunRetryPolicy{
+				Limit:   3,
+				Backoff: &SyncRunRetryBackoff{Duration: "5s", Factor: &factor, MaxDuration: "3m"},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Delete(ctx, gitopsDeplSyncRunCr)
+			Expect(err).To(BeNil())
+		})
+	})
+
 })
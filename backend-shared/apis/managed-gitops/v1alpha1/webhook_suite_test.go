@@ -115,6 +115,12 @@ var _ = BeforeSuite(func() {
 	err = (&GitOpsDeploymentManagedEnvironment{}).SetupWebhookWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = (&GitOpsDeploymentBundle{}).SetupWebhookWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = (&GitOpsDeploymentRepositoryCredentialTemplate{}).SetupWebhookWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
 	//+kubebuilder:scaffold:webhook
 
 	go func() {
@@ -44,6 +44,16 @@ func (in *ApplicationDestination) DeepCopy() *ApplicationDestination {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationSource) DeepCopyInto(out *ApplicationSource) {
 	*out = *in
+	if in.Helm != nil {
+		in, out := &in.Helm, &out.Helm
+		*out = new(ApplicationSourceHelm)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Directory != nil {
+		in, out := &in.Directory, &out.Directory
+		*out = new(ApplicationSourceDirectory)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSource.
@@ -56,6 +66,121 @@ func (in *ApplicationSource) DeepCopy() *ApplicationSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationSourceDirectory) DeepCopyInto(out *ApplicationSourceDirectory) {
+	*out = *in
+	if in.Jsonnet != nil {
+		in, out := &in.Jsonnet, &out.Jsonnet
+		*out = new(ApplicationSourceJsonnet)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSourceDirectory.
+func (in *ApplicationSourceDirectory) DeepCopy() *ApplicationSourceDirectory {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationSourceDirectory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationSourceHelm) DeepCopyInto(out *ApplicationSourceHelm) {
+	*out = *in
+	if in.ValueFiles != nil {
+		in, out := &in.ValueFiles, &out.ValueFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSourceHelm.
+func (in *ApplicationSourceHelm) DeepCopy() *ApplicationSourceHelm {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationSourceHelm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationSourceJsonnet) DeepCopyInto(out *ApplicationSourceJsonnet) {
+	*out = *in
+	if in.ExtVars != nil {
+		in, out := &in.ExtVars, &out.ExtVars
+		*out = make([]JsonnetVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLAs != nil {
+		in, out := &in.TLAs, &out.TLAs
+		*out = make([]JsonnetVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.Libs != nil {
+		in, out := &in.Libs, &out.Libs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSourceJsonnet.
+func (in *ApplicationSourceJsonnet) DeepCopy() *ApplicationSourceJsonnet {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationSourceJsonnet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentHistoryEntry) DeepCopyInto(out *DeploymentHistoryEntry) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.DeployedAt.DeepCopyInto(&out.DeployedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentHistoryEntry.
+func (in *DeploymentHistoryEntry) DeepCopy() *DeploymentHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiffPreviewStatus) DeepCopyInto(out *DiffPreviewStatus) {
+	*out = *in
+	if in.GeneratedAt != nil {
+		in, out := &in.GeneratedAt, &out.GeneratedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceDiffSummary, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiffPreviewStatus.
+func (in *DiffPreviewStatus) DeepCopy() *DiffPreviewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiffPreviewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitOpsDeployment) DeepCopyInto(out *GitOpsDeployment) {
 	*out = *in
@@ -83,6 +208,144 @@ func (in *GitOpsDeployment) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentBundle) DeepCopyInto(out *GitOpsDeploymentBundle) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentBundle.
+func (in *GitOpsDeploymentBundle) DeepCopy() *GitOpsDeploymentBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitOpsDeploymentBundle) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentBundleItem) DeepCopyInto(out *GitOpsDeploymentBundleItem) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentBundleItem.
+func (in *GitOpsDeploymentBundleItem) DeepCopy() *GitOpsDeploymentBundleItem {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentBundleItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentBundleList) DeepCopyInto(out *GitOpsDeploymentBundleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GitOpsDeploymentBundle, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentBundleList.
+func (in *GitOpsDeploymentBundleList) DeepCopy() *GitOpsDeploymentBundleList {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentBundleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitOpsDeploymentBundleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentBundleSpec) DeepCopyInto(out *GitOpsDeploymentBundleSpec) {
+	*out = *in
+	if in.Deployments != nil {
+		in, out := &in.Deployments, &out.Deployments
+		*out = make([]GitOpsDeploymentBundleItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentBundleSpec.
+func (in *GitOpsDeploymentBundleSpec) DeepCopy() *GitOpsDeploymentBundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentBundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentBundleStatus) DeepCopyInto(out *GitOpsDeploymentBundleStatus) {
+	*out = *in
+	if in.Deployments != nil {
+		in, out := &in.Deployments, &out.Deployments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentBundleStatus.
+func (in *GitOpsDeploymentBundleStatus) DeepCopy() *GitOpsDeploymentBundleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentBundleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitOpsDeploymentCondition) DeepCopyInto(out *GitOpsDeploymentCondition) {
 	*out = *in
@@ -217,20 +480,207 @@ func (in *GitOpsDeploymentManagedEnvironmentSpec) DeepCopyInto(out *GitOpsDeploy
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RotateCredentialsRequestedAt != nil {
+		in, out := &in.RotateCredentialsRequestedAt, &out.RotateCredentialsRequestedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.NamespaceQuota != nil {
+		in, out := &in.NamespaceQuota, &out.NamespaceQuota
+		*out = new(ManagedEnvironmentNamespaceQuota)
+		**out = **in
+	}
+	if in.ClusterLabels != nil {
+		in, out := &in.ClusterLabels, &out.ClusterLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AllowedRepositories != nil {
+		in, out := &in.AllowedRepositories, &out.AllowedRepositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentManagedEnvironmentSpec.
+func (in *GitOpsDeploymentManagedEnvironmentSpec) DeepCopy() *GitOpsDeploymentManagedEnvironmentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentManagedEnvironmentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentManagedEnvironmentStatus) DeepCopyInto(out *GitOpsDeploymentManagedEnvironmentStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.APIGroups != nil {
+		in, out := &in.APIGroups, &out.APIGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentManagedEnvironmentStatus.
+func (in *GitOpsDeploymentManagedEnvironmentStatus) DeepCopy() *GitOpsDeploymentManagedEnvironmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentManagedEnvironmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentOperationStatus) DeepCopyInto(out *GitOpsDeploymentOperationStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.ResourceRef = in.ResourceRef
+	in.CreatedOn.DeepCopyInto(&out.CreatedOn)
+	in.LastStateUpdate.DeepCopyInto(&out.LastStateUpdate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentOperationStatus.
+func (in *GitOpsDeploymentOperationStatus) DeepCopy() *GitOpsDeploymentOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitOpsDeploymentOperationStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentOperationStatusList) DeepCopyInto(out *GitOpsDeploymentOperationStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GitOpsDeploymentOperationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentOperationStatusList.
+func (in *GitOpsDeploymentOperationStatusList) DeepCopy() *GitOpsDeploymentOperationStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentOperationStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitOpsDeploymentOperationStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentRepositoryCredential) DeepCopyInto(out *GitOpsDeploymentRepositoryCredential) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredential.
+func (in *GitOpsDeploymentRepositoryCredential) DeepCopy() *GitOpsDeploymentRepositoryCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentRepositoryCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitOpsDeploymentRepositoryCredential) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentRepositoryCredentialList) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GitOpsDeploymentRepositoryCredential, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialList.
+func (in *GitOpsDeploymentRepositoryCredentialList) DeepCopy() *GitOpsDeploymentRepositoryCredentialList {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsDeploymentRepositoryCredentialList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitOpsDeploymentRepositoryCredentialList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsDeploymentRepositoryCredentialSpec) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialSpec) {
+	*out = *in
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(RepositoryCredentialScope)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentManagedEnvironmentSpec.
-func (in *GitOpsDeploymentManagedEnvironmentSpec) DeepCopy() *GitOpsDeploymentManagedEnvironmentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialSpec.
+func (in *GitOpsDeploymentRepositoryCredentialSpec) DeepCopy() *GitOpsDeploymentRepositoryCredentialSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GitOpsDeploymentManagedEnvironmentSpec)
+	out := new(GitOpsDeploymentRepositoryCredentialSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitOpsDeploymentManagedEnvironmentStatus) DeepCopyInto(out *GitOpsDeploymentManagedEnvironmentStatus) {
+func (in *GitOpsDeploymentRepositoryCredentialStatus) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -241,18 +691,18 @@ func (in *GitOpsDeploymentManagedEnvironmentStatus) DeepCopyInto(out *GitOpsDepl
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentManagedEnvironmentStatus.
-func (in *GitOpsDeploymentManagedEnvironmentStatus) DeepCopy() *GitOpsDeploymentManagedEnvironmentStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialStatus.
+func (in *GitOpsDeploymentRepositoryCredentialStatus) DeepCopy() *GitOpsDeploymentRepositoryCredentialStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GitOpsDeploymentManagedEnvironmentStatus)
+	out := new(GitOpsDeploymentRepositoryCredentialStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitOpsDeploymentRepositoryCredential) DeepCopyInto(out *GitOpsDeploymentRepositoryCredential) {
+func (in *GitOpsDeploymentRepositoryCredentialTemplate) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -260,18 +710,18 @@ func (in *GitOpsDeploymentRepositoryCredential) DeepCopyInto(out *GitOpsDeployme
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredential.
-func (in *GitOpsDeploymentRepositoryCredential) DeepCopy() *GitOpsDeploymentRepositoryCredential {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialTemplate.
+func (in *GitOpsDeploymentRepositoryCredentialTemplate) DeepCopy() *GitOpsDeploymentRepositoryCredentialTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(GitOpsDeploymentRepositoryCredential)
+	out := new(GitOpsDeploymentRepositoryCredentialTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GitOpsDeploymentRepositoryCredential) DeepCopyObject() runtime.Object {
+func (in *GitOpsDeploymentRepositoryCredentialTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -279,31 +729,31 @@ func (in *GitOpsDeploymentRepositoryCredential) DeepCopyObject() runtime.Object
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitOpsDeploymentRepositoryCredentialList) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialList) {
+func (in *GitOpsDeploymentRepositoryCredentialTemplateList) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]GitOpsDeploymentRepositoryCredential, len(*in))
+		*out = make([]GitOpsDeploymentRepositoryCredentialTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialList.
-func (in *GitOpsDeploymentRepositoryCredentialList) DeepCopy() *GitOpsDeploymentRepositoryCredentialList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialTemplateList.
+func (in *GitOpsDeploymentRepositoryCredentialTemplateList) DeepCopy() *GitOpsDeploymentRepositoryCredentialTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(GitOpsDeploymentRepositoryCredentialList)
+	out := new(GitOpsDeploymentRepositoryCredentialTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GitOpsDeploymentRepositoryCredentialList) DeepCopyObject() runtime.Object {
+func (in *GitOpsDeploymentRepositoryCredentialTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -311,22 +761,22 @@ func (in *GitOpsDeploymentRepositoryCredentialList) DeepCopyObject() runtime.Obj
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitOpsDeploymentRepositoryCredentialSpec) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialSpec) {
+func (in *GitOpsDeploymentRepositoryCredentialTemplateSpec) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialTemplateSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialSpec.
-func (in *GitOpsDeploymentRepositoryCredentialSpec) DeepCopy() *GitOpsDeploymentRepositoryCredentialSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialTemplateSpec.
+func (in *GitOpsDeploymentRepositoryCredentialTemplateSpec) DeepCopy() *GitOpsDeploymentRepositoryCredentialTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GitOpsDeploymentRepositoryCredentialSpec)
+	out := new(GitOpsDeploymentRepositoryCredentialTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitOpsDeploymentRepositoryCredentialStatus) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialStatus) {
+func (in *GitOpsDeploymentRepositoryCredentialTemplateStatus) DeepCopyInto(out *GitOpsDeploymentRepositoryCredentialTemplateStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -337,12 +787,12 @@ func (in *GitOpsDeploymentRepositoryCredentialStatus) DeepCopyInto(out *GitOpsDe
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialStatus.
-func (in *GitOpsDeploymentRepositoryCredentialStatus) DeepCopy() *GitOpsDeploymentRepositoryCredentialStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentRepositoryCredentialTemplateStatus.
+func (in *GitOpsDeploymentRepositoryCredentialTemplateStatus) DeepCopy() *GitOpsDeploymentRepositoryCredentialTemplateStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GitOpsDeploymentRepositoryCredentialStatus)
+	out := new(GitOpsDeploymentRepositoryCredentialTemplateStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -365,13 +815,32 @@ func (in *GitOpsDeploymentSource) DeepCopy() *GitOpsDeploymentSource {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitOpsDeploymentSpec) DeepCopyInto(out *GitOpsDeploymentSpec) {
 	*out = *in
-	out.Source = in.Source
+	in.Source.DeepCopyInto(&out.Source)
 	out.Destination = in.Destination
 	if in.SyncPolicy != nil {
 		in, out := &in.SyncPolicy, &out.SyncPolicy
 		*out = new(SyncPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceHealthChecks != nil {
+		in, out := &in.ResourceHealthChecks, &out.ResourceHealthChecks
+		*out = make([]ResourceHealthCheck, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentSpec.
@@ -404,6 +873,33 @@ func (in *GitOpsDeploymentStatus) DeepCopyInto(out *GitOpsDeploymentStatus) {
 		}
 	}
 	out.ReconciledState = in.ReconciledState
+	if in.LastSync != nil {
+		in, out := &in.LastSync, &out.LastSync
+		*out = new(LastSyncStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]DeploymentHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DiffPreview != nil {
+		in, out := &in.DiffPreview, &out.DiffPreview
+		*out = new(DiffPreviewStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceEstimate != nil {
+		in, out := &in.ResourceEstimate, &out.ResourceEstimate
+		*out = new(ResourceEstimateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OperationState != nil {
+		in, out := &in.OperationState, &out.OperationState
+		*out = new(OperationStateStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentStatus.
@@ -421,7 +917,7 @@ func (in *GitOpsDeploymentSyncRun) DeepCopyInto(out *GitOpsDeploymentSyncRun) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -497,6 +993,16 @@ func (in *GitOpsDeploymentSyncRunList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitOpsDeploymentSyncRunSpec) DeepCopyInto(out *GitOpsDeploymentSyncRunSpec) {
 	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]SyncRunResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(SyncRunRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentSyncRunSpec.
@@ -519,6 +1025,11 @@ func (in *GitOpsDeploymentSyncRunStatus) DeepCopyInto(out *GitOpsDeploymentSyncR
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]SyncRunHookStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsDeploymentSyncRunStatus.
@@ -546,6 +1057,88 @@ func (in *HealthStatus) DeepCopy() *HealthStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonnetVar) DeepCopyInto(out *JsonnetVar) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonnetVar.
+func (in *JsonnetVar) DeepCopy() *JsonnetVar {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonnetVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LastSyncStatus) DeepCopyInto(out *LastSyncStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LastSyncStatus.
+func (in *LastSyncStatus) DeepCopy() *LastSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LastSyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedEnvironmentNamespaceQuota) DeepCopyInto(out *ManagedEnvironmentNamespaceQuota) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedEnvironmentNamespaceQuota.
+func (in *ManagedEnvironmentNamespaceQuota) DeepCopy() *ManagedEnvironmentNamespaceQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedEnvironmentNamespaceQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedNamespaceMetadata) DeepCopyInto(out *ManagedNamespaceMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedNamespaceMetadata.
+func (in *ManagedNamespaceMetadata) DeepCopy() *ManagedNamespaceMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedNamespaceMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Operation) DeepCopyInto(out *Operation) {
 	*out = *in
@@ -605,6 +1198,21 @@ func (in *OperationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationResourceRef) DeepCopyInto(out *OperationResourceRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationResourceRef.
+func (in *OperationResourceRef) DeepCopy() *OperationResourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationResourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperationSpec) DeepCopyInto(out *OperationSpec) {
 	*out = *in
@@ -620,6 +1228,29 @@ func (in *OperationSpec) DeepCopy() *OperationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStateStatus) DeepCopyInto(out *OperationStateStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.EstimatedCompletionAt != nil {
+		in, out := &in.EstimatedCompletionAt, &out.EstimatedCompletionAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStateStatus.
+func (in *OperationStateStatus) DeepCopy() *OperationStateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperationStatus) DeepCopyInto(out *OperationStatus) {
 	*out = *in
@@ -652,6 +1283,80 @@ func (in *ReconciledState) DeepCopy() *ReconciledState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryCredentialScope) DeepCopyInto(out *RepositoryCredentialScope) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeploymentSelector != nil {
+		in, out := &in.DeploymentSelector, &out.DeploymentSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryCredentialScope.
+func (in *RepositoryCredentialScope) DeepCopy() *RepositoryCredentialScope {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryCredentialScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceDiffSummary) DeepCopyInto(out *ResourceDiffSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceDiffSummary.
+func (in *ResourceDiffSummary) DeepCopy() *ResourceDiffSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceDiffSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceEstimateStatus) DeepCopyInto(out *ResourceEstimateStatus) {
+	*out = *in
+	if in.CollectedAt != nil {
+		in, out := &in.CollectedAt, &out.CollectedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceEstimateStatus.
+func (in *ResourceEstimateStatus) DeepCopy() *ResourceEstimateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceEstimateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceHealthCheck) DeepCopyInto(out *ResourceHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceHealthCheck.
+func (in *ResourceHealthCheck) DeepCopy() *ResourceHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceStatus) DeepCopyInto(out *ResourceStatus) {
 	*out = *in
@@ -699,6 +1404,16 @@ func (in *SyncPolicy) DeepCopyInto(out *SyncPolicy) {
 		*out = make(SyncOptions, len(*in))
 		copy(*out, *in)
 	}
+	if in.ManagedNamespaceMetadata != nil {
+		in, out := &in.ManagedNamespaceMetadata, &out.ManagedNamespaceMetadata
+		*out = new(ManagedNamespaceMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Automated != nil {
+		in, out := &in.Automated, &out.Automated
+		*out = new(SyncPolicyAutomated)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncPolicy.
@@ -711,6 +1426,91 @@ func (in *SyncPolicy) DeepCopy() *SyncPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncPolicyAutomated) DeepCopyInto(out *SyncPolicyAutomated) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncPolicyAutomated.
+func (in *SyncPolicyAutomated) DeepCopy() *SyncPolicyAutomated {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncPolicyAutomated)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRunHookStatus) DeepCopyInto(out *SyncRunHookStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRunHookStatus.
+func (in *SyncRunHookStatus) DeepCopy() *SyncRunHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRunHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRunResource) DeepCopyInto(out *SyncRunResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRunResource.
+func (in *SyncRunResource) DeepCopy() *SyncRunResource {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRunResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRunRetryBackoff) DeepCopyInto(out *SyncRunRetryBackoff) {
+	*out = *in
+	if in.Factor != nil {
+		in, out := &in.Factor, &out.Factor
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRunRetryBackoff.
+func (in *SyncRunRetryBackoff) DeepCopy() *SyncRunRetryBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRunRetryBackoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRunRetryPolicy) DeepCopyInto(out *SyncRunRetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(SyncRunRetryBackoff)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRunRetryPolicy.
+func (in *SyncRunRetryPolicy) DeepCopy() *SyncRunRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRunRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncStatus) DeepCopyInto(out *SyncStatus) {
 	*out = *in
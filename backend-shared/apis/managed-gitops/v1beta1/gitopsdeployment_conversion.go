@@ -0,0 +1,304 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// Conversion of GitOpsDeployment between v1beta1 and v1alpha1 (the hub, and the storage version of the CRD):
+//
+// | v1alpha1                                      | v1beta1                                         |
+// |-----------------------------------------------|-------------------------------------------------|
+// | .spec.source.targetRevision                   | .spec.source.revision                           |
+// | .spec.destination.environment                 | .spec.destination.managedEnvironment            |
+// | .spec.type ('automated', 'manual')            | .spec.syncPolicy.mode ('Automated', 'Manual')   |
+// | .spec.syncPolicy.syncOptions ('Name=value')   | .spec.syncPolicy.syncOptions.name (true, false) |
+// | .spec.commonLabels, .spec.commonAnnotations   | .spec.commonMetadata.labels, .annotations       |
+//
+// The other fields, and the status, are unchanged.
+//
+// Not every v1alpha1 sync policy can be represented exactly in v1beta1: for example, the order of the sync options
+// list is lost. So that a v1alpha1 GitOpsDeployment is not modified when a v1beta1 client reads and then updates it, the
+// v1alpha1 sync policy is saved in the V1Alpha1SyncPolicyAnnotation of the v1beta1 GitOpsDeployment, and is restored
+// on conversion back to v1alpha1, unless the v1beta1 sync policy was changed in the meantime.
+
+// V1Alpha1SyncPolicyAnnotation contains the v1alpha1 '.spec.type' and '.spec.syncPolicy' of a v1beta1 GitOpsDeployment
+// (as JSON), if they can't be converted to v1beta1 without loss. It is never set on a v1alpha1 GitOpsDeployment.
+const V1Alpha1SyncPolicyAnnotation = "managed-gitops.redhat.com/v1alpha1-sync-policy"
+
+// v1alpha1SyncPolicy is the value of the V1Alpha1SyncPolicyAnnotation
+type v1alpha1SyncPolicy struct {
+	Type       string               `json:"type,omitempty"`
+	SyncPolicy *v1alpha1.SyncPolicy `json:"syncPolicy,omitempty"`
+}
+
+var _ conversion.Convertible = &GitOpsDeployment{}
+
+// ConvertTo converts this GitOpsDeployment to the hub (v1alpha1) version.
+func (src *GitOpsDeployment) ConvertTo(dstRaw conversion.Hub) error {
+
+	dst, ok := dstRaw.(*v1alpha1.GitOpsDeployment)
+	if !ok {
+		return fmt.Errorf("unexpected type '%T' of GitOpsDeployment conversion hub", dstRaw)
+	}
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dst.Spec = convertSpecToV1alpha1(src.Spec)
+	src.Status.DeepCopyInto(&dst.Status)
+
+	savedSyncPolicy, exists := dst.Annotations[V1Alpha1SyncPolicyAnnotation]
+	if !exists {
+		return nil
+	}
+
+	delete(dst.Annotations, V1Alpha1SyncPolicyAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	// Restore the saved v1alpha1 sync policy, but only if it is still equivalent to the v1beta1 sync policy: if it is
+	// not, the sync policy was changed by a v1beta1 client, and the change takes precedence.
+	var original v1alpha1SyncPolicy
+	if err := json.Unmarshal([]byte(savedSyncPolicy), &original); err != nil {
+		// The annotation was modified: ignore it, as the v1beta1 sync policy is already converted.
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(convertSyncPolicyFromV1alpha1(original.Type, original.SyncPolicy), src.Spec.SyncPolicy) {
+		dst.Spec.Type = original.Type
+		dst.Spec.SyncPolicy = original.SyncPolicy
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the hub (v1alpha1) version to this version.
+func (dst *GitOpsDeployment) ConvertFrom(srcRaw conversion.Hub) error {
+
+	src, ok := srcRaw.(*v1alpha1.GitOpsDeployment)
+	if !ok {
+		return fmt.Errorf("unexpected type '%T' of GitOpsDeployment conversion hub", srcRaw)
+	}
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dst.Spec = convertSpecFromV1alpha1(src.Spec)
+	src.Status.DeepCopyInto(&dst.Status)
+
+	delete(dst.Annotations, V1Alpha1SyncPolicyAnnotation)
+
+	// Save the v1alpha1 sync policy, if it would not be restored exactly by converting the v1beta1 sync policy back
+	roundTripType, roundTripSyncPolicy := convertSyncPolicyToV1alpha1(dst.Spec.SyncPolicy)
+	if roundTripType == src.Spec.Type && equality.Semantic.DeepEqual(roundTripSyncPolicy, src.Spec.SyncPolicy) {
+		return nil
+	}
+
+	savedSyncPolicy, err := json.Marshal(v1alpha1SyncPolicy{Type: src.Spec.Type, SyncPolicy: src.Spec.SyncPolicy})
+	if err != nil {
+		return fmt.Errorf("unable to marshal v1alpha1 sync policy of GitOpsDeployment '%s': %v", src.Name, err)
+	}
+
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[V1Alpha1SyncPolicyAnnotation] = string(savedSyncPolicy)
+
+	return nil
+}
+
+func convertSpecToV1alpha1(spec GitOpsDeploymentSpec) v1alpha1.GitOpsDeploymentSpec {
+
+	res := v1alpha1.GitOpsDeploymentSpec{
+		Source: v1alpha1.ApplicationSource{
+			RepoURL:        spec.Source.RepoURL,
+			Path:           spec.Source.Path,
+			TargetRevision: spec.Source.Revision,
+			Helm:           spec.Source.Helm.DeepCopy(),
+			Directory:      spec.Source.Directory.DeepCopy(),
+		},
+		Destination: v1alpha1.ApplicationDestination{
+			Environment: spec.Destination.ManagedEnvironment,
+			Namespace:   spec.Destination.Namespace,
+		},
+		DeletionPolicy: spec.DeletionPolicy,
+	}
+
+	res.Type, res.SyncPolicy = convertSyncPolicyToV1alpha1(spec.SyncPolicy)
+
+	if spec.CommonMetadata != nil {
+		commonMetadata := spec.CommonMetadata.DeepCopy()
+		res.CommonLabels = commonMetadata.Labels
+		res.CommonAnnotations = commonMetadata.Annotations
+	}
+
+	if spec.ResourceHealthChecks != nil {
+		res.ResourceHealthChecks = make([]v1alpha1.ResourceHealthCheck, len(spec.ResourceHealthChecks))
+		copy(res.ResourceHealthChecks, spec.ResourceHealthChecks)
+	}
+
+	return res
+}
+
+func convertSpecFromV1alpha1(spec v1alpha1.GitOpsDeploymentSpec) GitOpsDeploymentSpec {
+
+	res := GitOpsDeploymentSpec{
+		Source: ApplicationSource{
+			RepoURL:   spec.Source.RepoURL,
+			Path:      spec.Source.Path,
+			Revision:  spec.Source.TargetRevision,
+			Helm:      spec.Source.Helm.DeepCopy(),
+			Directory: spec.Source.Directory.DeepCopy(),
+		},
+		Destination: ApplicationDestination{
+			ManagedEnvironment: spec.Destination.Environment,
+			Namespace:          spec.Destination.Namespace,
+		},
+		SyncPolicy:     convertSyncPolicyFromV1alpha1(spec.Type, spec.SyncPolicy),
+		DeletionPolicy: spec.DeletionPolicy,
+	}
+
+	if len(spec.CommonLabels) > 0 || len(spec.CommonAnnotations) > 0 {
+		commonMetadata := CommonMetadata{Labels: spec.CommonLabels, Annotations: spec.CommonAnnotations}
+		res.CommonMetadata = commonMetadata.DeepCopy()
+	}
+
+	if spec.ResourceHealthChecks != nil {
+		res.ResourceHealthChecks = make([]v1alpha1.ResourceHealthCheck, len(spec.ResourceHealthChecks))
+		copy(res.ResourceHealthChecks, spec.ResourceHealthChecks)
+	}
+
+	return res
+}
+
+// convertSyncPolicyToV1alpha1 returns the v1alpha1 '.spec.type' and '.spec.syncPolicy' of the given v1beta1 sync policy.
+func convertSyncPolicyToV1alpha1(syncPolicy *SyncPolicy) (string, *v1alpha1.SyncPolicy) {
+
+	if syncPolicy == nil {
+		return "", nil
+	}
+
+	var specType string
+	switch syncPolicy.Mode {
+	case SyncMode_Automated:
+		specType = v1alpha1.GitOpsDeploymentSpecType_Automated
+	case SyncMode_Manual:
+		specType = v1alpha1.GitOpsDeploymentSpecType_Manual
+	default:
+		specType = string(syncPolicy.Mode)
+	}
+
+	var syncOptions v1alpha1.SyncOptions
+	if syncPolicy.SyncOptions != nil {
+		syncOptions = appendSyncOption(syncOptions, syncPolicy.SyncOptions.CreateNamespace,
+			v1alpha1.SyncOptions_CreateNamespace_true, v1alpha1.SyncOptions_CreateNamespace_false)
+		syncOptions = appendSyncOption(syncOptions, syncPolicy.SyncOptions.ServerSideApply,
+			v1alpha1.SyncOptions_ServerSideApply_true, v1alpha1.SyncOptions_ServerSideApply_false)
+		syncOptions = appendSyncOption(syncOptions, syncPolicy.SyncOptions.Validate,
+			v1alpha1.SyncOptions_Validate_true, v1alpha1.SyncOptions_Validate_false)
+		syncOptions = appendSyncOption(syncOptions, syncPolicy.SyncOptions.ApplyOutOfSyncOnly,
+			v1alpha1.SyncOptions_ApplyOutOfSyncOnly_true, v1alpha1.SyncOptions_ApplyOutOfSyncOnly_false)
+	}
+
+	if syncPolicy.Automated == nil && syncPolicy.ManagedNamespaceMetadata == nil && len(syncOptions) == 0 {
+		// In v1alpha1, the mode is not part of the sync policy
+		return specType, nil
+	}
+
+	return specType, &v1alpha1.SyncPolicy{
+		SyncOptions:              syncOptions,
+		ManagedNamespaceMetadata: syncPolicy.ManagedNamespaceMetadata.DeepCopy(),
+		Automated:                syncPolicy.Automated.DeepCopy(),
+	}
+}
+
+// convertSyncPolicyFromV1alpha1 returns the v1beta1 sync policy of the given v1alpha1 '.spec.type' and '.spec.syncPolicy'.
+// Sync options which are not supported are ignored, as are sync options which conflict with an earlier sync option.
+func convertSyncPolicyFromV1alpha1(specType string, syncPolicy *v1alpha1.SyncPolicy) *SyncPolicy {
+
+	res := SyncPolicy{}
+
+	switch specType {
+	case v1alpha1.GitOpsDeploymentSpecType_Automated:
+		res.Mode = SyncMode_Automated
+	case v1alpha1.GitOpsDeploymentSpecType_Manual:
+		res.Mode = SyncMode_Manual
+	default:
+		res.Mode = SyncMode(specType)
+	}
+
+	if syncPolicy != nil {
+		res.Automated = syncPolicy.Automated.DeepCopy()
+		res.ManagedNamespaceMetadata = syncPolicy.ManagedNamespaceMetadata.DeepCopy()
+
+		syncOptions := SyncOptions{}
+		for _, syncOption := range syncPolicy.SyncOptions {
+
+			if !syncOption.IsSupported() {
+				continue
+			}
+			_, value, _ := strings.Cut(string(syncOption), "=")
+			enabled := value == "true"
+
+			var field **bool
+			switch syncOption.Name() {
+			case v1alpha1.SyncOptions_CreateNamespace_true.Name():
+				field = &syncOptions.CreateNamespace
+			case v1alpha1.SyncOptions_ServerSideApply_true.Name():
+				field = &syncOptions.ServerSideApply
+			case v1alpha1.SyncOptions_Validate_true.Name():
+				field = &syncOptions.Validate
+			case v1alpha1.SyncOptions_ApplyOutOfSyncOnly_true.Name():
+				field = &syncOptions.ApplyOutOfSyncOnly
+			default:
+				continue
+			}
+
+			if *field == nil {
+				*field = &enabled
+			}
+		}
+
+		if syncOptions != (SyncOptions{}) {
+			res.SyncOptions = &syncOptions
+		}
+	}
+
+	if res.Mode == "" && res.Automated == nil && res.SyncOptions == nil && res.ManagedNamespaceMetadata == nil {
+		return nil
+	}
+
+	return &res
+}
+
+// appendSyncOption appends the v1alpha1 sync option for the given value of a v1beta1 sync option, if it is set.
+func appendSyncOption(syncOptions v1alpha1.SyncOptions, value *bool, trueOption v1alpha1.SyncOption, falseOption v1alpha1.SyncOption) v1alpha1.SyncOptions {
+
+	if value == nil {
+		return syncOptions
+	}
+
+	if *value {
+		return append(syncOptions, trueOption)
+	}
+	return append(syncOptions, falseOption)
+}
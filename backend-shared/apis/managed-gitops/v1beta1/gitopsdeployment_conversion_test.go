@@ -0,0 +1,278 @@
+package v1beta1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("GitOpsDeployment conversion tests", func() {
+
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
+
+	newV1alpha1GitOpsDeployment := func(modify func(spec *v1alpha1.GitOpsDeploymentSpec)) *v1alpha1.GitOpsDeployment {
+		res := &v1alpha1.GitOpsDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "my-gitops-depl",
+				Namespace:   "my-namespace",
+				Labels:      map[string]string{"my-label": "my-value"},
+				Annotations: map[string]string{"my-annotation": "my-value"},
+			},
+			Spec: v1alpha1.GitOpsDeploymentSpec{
+				Source: v1alpha1.ApplicationSource{
+					RepoURL:        "https://github.com/redhat-appstudio/managed-gitops",
+					Path:           "resources/test-data/sample-gitops-repository/environments/overlays/dev",
+					TargetRevision: "main",
+				},
+			},
+			Status: v1alpha1.GitOpsDeploymentStatus{
+				Sync:             v1alpha1.SyncStatus{Status: v1alpha1.SyncStatusCodeSynced},
+				Health:           v1alpha1.HealthStatus{Status: v1alpha1.HeathStatusCodeHealthy},
+				ResolvedRevision: "c1d2e3f4",
+			},
+		}
+		if modify != nil {
+			modify(&res.Spec)
+		}
+		return res
+	}
+
+	Context("Testing conversion from v1alpha1 to v1beta1", func() {
+
+		It("should convert each field to its v1beta1 equivalent", func() {
+
+			alpha := newV1alpha1GitOpsDeployment(func(spec *v1alpha1.GitOpsDeploymentSpec) {
+				spec.Source.Helm = &v1alpha1.ApplicationSourceHelm{ValueFiles: []string{"values-dev.yaml"}}
+				spec.Destination = v1alpha1.ApplicationDestination{Environment: "my-env", Namespace: "my-target-namespace"}
+				spec.Type = v1alpha1.GitOpsDeploymentSpecType_Automated
+				spec.SyncPolicy = &v1alpha1.SyncPolicy{
+					SyncOptions: v1alpha1.SyncOptions{v1alpha1.SyncOptions_CreateNamespace_true, v1alpha1.SyncOptions_Validate_false},
+					Automated:   &v1alpha1.SyncPolicyAutomated{Prune: true},
+				}
+				spec.CommonLabels = map[string]string{"cost-center": "1234"}
+				spec.DeletionPolicy = v1alpha1.GitOpsDeploymentDeletionPolicy_Foreground
+			})
+
+			beta := &GitOpsDeployment{}
+			Expect(beta.ConvertFrom(alpha)).To(Succeed())
+
+			Expect(beta.ObjectMeta).To(Equal(alpha.ObjectMeta), "the metadata should be unchanged, as the conversion is lossless")
+			Expect(beta.Spec).To(Equal(GitOpsDeploymentSpec{
+				Source: ApplicationSource{
+					RepoURL:  alpha.Spec.Source.RepoURL,
+					Path:     alpha.Spec.Source.Path,
+					Revision: "main",
+					Helm:     &v1alpha1.ApplicationSourceHelm{ValueFiles: []string{"values-dev.yaml"}},
+				},
+				Destination: ApplicationDestination{ManagedEnvironment: "my-env", Namespace: "my-target-namespace"},
+				SyncPolicy: &SyncPolicy{
+					Mode:        SyncMode_Automated,
+					Automated:   &v1alpha1.SyncPolicyAutomated{Prune: true},
+					SyncOptions: &SyncOptions{CreateNamespace: boolPtr(true), Validate: boolPtr(false)},
+				},
+				CommonMetadata: &CommonMetadata{Labels: map[string]string{"cost-center": "1234"}},
+				DeletionPolicy: v1alpha1.GitOpsDeploymentDeletionPolicy_Foreground,
+			}))
+			Expect(beta.Status).To(Equal(alpha.Status))
+
+			By("verifying the v1alpha1 GitOpsDeployment was not modified by the conversion")
+			Expect(alpha).To(Equal(newV1alpha1GitOpsDeployment(func(spec *v1alpha1.GitOpsDeploymentSpec) {
+				spec.Source.Helm = &v1alpha1.ApplicationSourceHelm{ValueFiles: []string{"values-dev.yaml"}}
+				spec.Destination = v1alpha1.ApplicationDestination{Environment: "my-env", Namespace: "my-target-namespace"}
+				spec.Type = v1alpha1.GitOpsDeploymentSpecType_Automated
+				spec.SyncPolicy = &v1alpha1.SyncPolicy{
+					SyncOptions: v1alpha1.SyncOptions{v1alpha1.SyncOptions_CreateNamespace_true, v1alpha1.SyncOptions_Validate_false},
+					Automated:   &v1alpha1.SyncPolicyAutomated{Prune: true},
+				}
+				spec.CommonLabels = map[string]string{"cost-center": "1234"}
+				spec.DeletionPolicy = v1alpha1.GitOpsDeploymentDeletionPolicy_Foreground
+			})))
+		})
+	})
+
+	Context("Testing v1alpha1 -> v1beta1 -> v1alpha1 round trips", func() {
+
+		DescribeTable("should convert the GitOpsDeployment back to the same v1alpha1 GitOpsDeployment",
+			func(modify func(spec *v1alpha1.GitOpsDeploymentSpec), expectAnnotation bool) {
+
+				alpha := newV1alpha1GitOpsDeployment(modify)
+
+				beta := &GitOpsDeployment{}
+				Expect(beta.ConvertFrom(alpha)).To(Succeed())
+				Expect(beta.Annotations).To(HaveKey("my-annotation"))
+				if expectAnnotation {
+					Expect(beta.Annotations).To(HaveKey(V1Alpha1SyncPolicyAnnotation))
+				} else {
+					Expect(beta.Annotations).ToNot(HaveKey(V1Alpha1SyncPolicyAnnotation))
+				}
+
+				roundTrip := &v1alpha1.GitOpsDeployment{}
+				Expect(beta.ConvertTo(roundTrip)).To(Succeed())
+				Expect(roundTrip).To(Equal(alpha))
+			},
+			Entry("with only the required fields", nil, false),
+			Entry("with every field set", func(spec *v1alpha1.GitOpsDeploymentSpec) {
+				spec.Source.Directory = &v1alpha1.ApplicationSourceDirectory{
+					Recurse: true,
+					Jsonnet: &v1alpha1.ApplicationSourceJsonnet{ExtVars: []v1alpha1.JsonnetVar{{Name: "env", Value: "dev"}}},
+				}
+				spec.Destination = v1alpha1.ApplicationDestination{Environment: "my-env", Namespace: "my-target-namespace"}
+				spec.Type = v1alpha1.GitOpsDeploymentSpecType_Manual
+				spec.SyncPolicy = &v1alpha1.SyncPolicy{
+					SyncOptions: v1alpha1.SyncOptions{
+						v1alpha1.SyncOptions_CreateNamespace_true, v1alpha1.SyncOptions_ServerSideApply_false,
+						v1alpha1.SyncOptions_Validate_true, v1alpha1.SyncOptions_ApplyOutOfSyncOnly_true,
+					},
+					ManagedNamespaceMetadata: &v1alpha1.ManagedNamespaceMetadata{
+						Labels: map[string]string{"pod-security.kubernetes.io/enforce": "restricted"},
+					},
+					Automated: &v1alpha1.SyncPolicyAutomated{Prune: true, SelfHeal: true},
+				}
+				spec.CommonLabels = map[string]string{"cost-center": "1234"}
+				spec.CommonAnnotations = map[string]string{"owner": "my-team"}
+				spec.DeletionPolicy = v1alpha1.GitOpsDeploymentDeletionPolicy_Orphan
+				spec.ResourceHealthChecks = []v1alpha1.ResourceHealthCheck{{Group: "serving.knative.dev", Kind: "Service", Check: "return {}"}}
+			}, false),
+			Entry("with only a type, and no sync policy", func(spec *v1alpha1.GitOpsDeploymentSpec) {
+				spec.Type = v1alpha1.GitOpsDeploymentSpecType_Automated
+			}, false),
+			Entry("with sync options that are not in the v1beta1 order", func(spec *v1alpha1.GitOpsDeploymentSpec) {
+				spec.SyncPolicy = &v1alpha1.SyncPolicy{
+					SyncOptions: v1alpha1.SyncOptions{v1alpha1.SyncOptions_Validate_false, v1alpha1.SyncOptions_CreateNamespace_true},
+				}
+			}, true),
+			Entry("with duplicate sync options", func(spec *v1alpha1.GitOpsDeploymentSpec) {
+				spec.SyncPolicy = &v1alpha1.SyncPolicy{
+					SyncOptions: v1alpha1.SyncOptions{v1alpha1.SyncOptions_CreateNamespace_true, v1alpha1.SyncOptions_CreateNamespace_true},
+				}
+			}, true),
+			Entry("with an empty sync policy", func(spec *v1alpha1.GitOpsDeploymentSpec) {
+				spec.SyncPolicy = &v1alpha1.SyncPolicy{}
+			}, true),
+		)
+	})
+
+	Context("Testing v1beta1 -> v1alpha1 -> v1beta1 round trips", func() {
+
+		It("should convert the GitOpsDeployment back to the same v1beta1 GitOpsDeployment", func() {
+
+			beta := &GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gitops-depl", Namespace: "my-namespace"},
+				Spec: GitOpsDeploymentSpec{
+					Source: ApplicationSource{
+						RepoURL:  "https://github.com/redhat-appstudio/managed-gitops",
+						Path:     "resources/test-data/sample-gitops-repository/environments/overlays/dev",
+						Revision: "v1.0.0",
+					},
+					Destination: ApplicationDestination{ManagedEnvironment: "my-env"},
+					SyncPolicy: &SyncPolicy{
+						Mode:      SyncMode_Automated,
+						Automated: &v1alpha1.SyncPolicyAutomated{SelfHeal: true},
+						SyncOptions: &SyncOptions{
+							CreateNamespace:    boolPtr(true),
+							ServerSideApply:    boolPtr(true),
+							Validate:           boolPtr(false),
+							ApplyOutOfSyncOnly: boolPtr(false),
+						},
+						ManagedNamespaceMetadata: &v1alpha1.ManagedNamespaceMetadata{
+							Annotations: map[string]string{"openshift.io/node-selector": "env=dev"},
+						},
+					},
+					CommonMetadata: &CommonMetadata{
+						Labels:      map[string]string{"cost-center": "1234"},
+						Annotations: map[string]string{"owner": "my-team"},
+					},
+				},
+				Status: v1alpha1.GitOpsDeploymentStatus{ResolvedRevision: "c1d2e3f4"},
+			}
+
+			alpha := &v1alpha1.GitOpsDeployment{}
+			Expect(beta.ConvertTo(alpha)).To(Succeed())
+			Expect(alpha.Spec.Type).To(Equal(v1alpha1.GitOpsDeploymentSpecType_Automated))
+			Expect(alpha.Spec.Source.TargetRevision).To(Equal("v1.0.0"))
+			Expect(alpha.Spec.Destination.Environment).To(Equal("my-env"))
+			Expect(alpha.Spec.SyncPolicy.SyncOptions).To(Equal(v1alpha1.SyncOptions{
+				v1alpha1.SyncOptions_CreateNamespace_true, v1alpha1.SyncOptions_ServerSideApply_true,
+				v1alpha1.SyncOptions_Validate_false, v1alpha1.SyncOptions_ApplyOutOfSyncOnly_false,
+			}))
+			Expect(alpha.Spec.CommonLabels).To(Equal(map[string]string{"cost-center": "1234"}))
+			Expect(alpha.Spec.CommonAnnotations).To(Equal(map[string]string{"owner": "my-team"}))
+
+			roundTrip := &GitOpsDeployment{}
+			Expect(roundTrip.ConvertFrom(alpha)).To(Succeed())
+			Expect(roundTrip).To(Equal(beta))
+		})
+
+		It("should convert a sync policy that only contains a mode to a v1alpha1 type, without a sync policy", func() {
+
+			beta := &GitOpsDeployment{Spec: GitOpsDeploymentSpec{SyncPolicy: &SyncPolicy{Mode: SyncMode_Manual}}}
+
+			alpha := &v1alpha1.GitOpsDeployment{}
+			Expect(beta.ConvertTo(alpha)).To(Succeed())
+			Expect(alpha.Spec.Type).To(Equal(v1alpha1.GitOpsDeploymentSpecType_Manual))
+			Expect(alpha.Spec.SyncPolicy).To(BeNil())
+
+			roundTrip := &GitOpsDeployment{}
+			Expect(roundTrip.ConvertFrom(alpha)).To(Succeed())
+			Expect(roundTrip).To(Equal(beta))
+		})
+	})
+
+	Context("Testing the v1alpha1 sync policy annotation", func() {
+
+		var alpha *v1alpha1.GitOpsDeployment
+
+		BeforeEach(func() {
+			alpha = newV1alpha1GitOpsDeployment(func(spec *v1alpha1.GitOpsDeploymentSpec) {
+				spec.SyncPolicy = &v1alpha1.SyncPolicy{
+					SyncOptions: v1alpha1.SyncOptions{v1alpha1.SyncOptions_Validate_false, v1alpha1.SyncOptions_CreateNamespace_true},
+				}
+			})
+		})
+
+		It("should restore the v1alpha1 sync policy when a v1beta1 client updates another field", func() {
+
+			beta := &GitOpsDeployment{}
+			Expect(beta.ConvertFrom(alpha)).To(Succeed())
+			Expect(beta.Annotations).To(HaveKey(V1Alpha1SyncPolicyAnnotation))
+
+			beta.Spec.Source.Revision = "v2.0.0"
+
+			updated := &v1alpha1.GitOpsDeployment{}
+			Expect(beta.ConvertTo(updated)).To(Succeed())
+			Expect(updated.Annotations).ToNot(HaveKey(V1Alpha1SyncPolicyAnnotation))
+			Expect(updated.Spec.Source.TargetRevision).To(Equal("v2.0.0"))
+			Expect(updated.Spec.SyncPolicy).To(Equal(alpha.Spec.SyncPolicy))
+		})
+
+		It("should use the v1beta1 sync policy when a v1beta1 client updates the sync policy", func() {
+
+			beta := &GitOpsDeployment{}
+			Expect(beta.ConvertFrom(alpha)).To(Succeed())
+
+			beta.Spec.SyncPolicy.SyncOptions.Validate = nil
+
+			updated := &v1alpha1.GitOpsDeployment{}
+			Expect(beta.ConvertTo(updated)).To(Succeed())
+			Expect(updated.Annotations).ToNot(HaveKey(V1Alpha1SyncPolicyAnnotation))
+			Expect(updated.Spec.SyncPolicy).To(Equal(&v1alpha1.SyncPolicy{
+				SyncOptions: v1alpha1.SyncOptions{v1alpha1.SyncOptions_CreateNamespace_true},
+			}))
+		})
+
+		It("should ignore an annotation that can't be parsed", func() {
+
+			beta := &GitOpsDeployment{}
+			Expect(beta.ConvertFrom(alpha)).To(Succeed())
+			beta.Annotations[V1Alpha1SyncPolicyAnnotation] = "not-json"
+
+			updated := &v1alpha1.GitOpsDeployment{}
+			Expect(beta.ConvertTo(updated)).To(Succeed())
+			Expect(updated.Annotations).ToNot(HaveKey(V1Alpha1SyncPolicyAnnotation))
+			Expect(updated.Spec.SyncPolicy.SyncOptions).To(ConsistOf(alpha.Spec.SyncPolicy.SyncOptions))
+		})
+	})
+})
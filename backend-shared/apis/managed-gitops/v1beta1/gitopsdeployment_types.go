@@ -0,0 +1,175 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitOpsDeploymentSpec defines the desired state of GitOpsDeployment
+type GitOpsDeploymentSpec struct {
+	Source ApplicationSource `json:"source"`
+
+	// Destination is a reference to a target namespace/cluster to deploy to.
+	// This field may be empty: if it is empty, it is assumed that the destination
+	// is the same namespace as the GitOpsDeployment CR.
+	Destination ApplicationDestination `json:"destination,omitempty"`
+
+	// SyncPolicy controls when and how a sync will be performed.
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+
+	// CommonMetadata contains the labels and annotations that are added to every resource deployed by the
+	// GitOpsDeployment, for example, to identify the workspace/application of the resources to cost and policy tooling.
+	// These are set via the 'commonLabels' and 'commonAnnotations' options of Argo CD's Kustomize support, and thus
+	// require the source path to contain a Kustomization.
+	CommonMetadata *CommonMetadata `json:"commonMetadata,omitempty"`
+
+	// DeletionPolicy controls what happens to the resources deployed by the GitOpsDeployment, when it is deleted:
+	// - Foreground: the resources are deleted, and the GitOpsDeployment is only removed once they have been deleted.
+	// - Background: the GitOpsDeployment is removed immediately, and the resources are deleted in the background.
+	// - Orphan: the resources are left on the cluster (they are no longer managed by the GitOps Service).
+	//
+	// If empty, the resources are deleted in the background.
+	DeletionPolicy v1alpha1.GitOpsDeploymentDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// ResourceHealthChecks are custom health checks, written in Lua, for kinds of resources deployed by the GitOpsDeployment
+	// whose health Argo CD cannot otherwise assess (for example, Knative Services).
+	// See https://argo-cd.readthedocs.io/en/stable/operator-manual/health/#custom-health-checks
+	ResourceHealthChecks []v1alpha1.ResourceHealthCheck `json:"resourceHealthChecks,omitempty"`
+}
+
+// ApplicationSource contains all required information about the source of an application
+type ApplicationSource struct {
+	// RepoURL is the URL to the repository (Git or Helm) that contains the application manifests
+	RepoURL string `json:"repoURL"`
+
+	// Path is a directory path within the Git repository, and is only valid for applications sourced from Git.
+	Path string `json:"path"`
+
+	// Revision is the revision of the source to sync the application to (v1alpha1: '.spec.source.targetRevision').
+	// In case of Git, this can be commit, tag, or branch. If omitted, will equal to HEAD.
+	// In case of Helm, this is a semver tag for the Chart's version.
+	Revision string `json:"revision,omitempty"`
+
+	// Helm holds the options of a Helm chart source: it may be set to deploy the chart with environment-specific values.
+	Helm *v1alpha1.ApplicationSourceHelm `json:"helm,omitempty"`
+
+	// Directory holds the options of a source that is a plain directory of manifests (or Jsonnet files): it may be set to
+	// deploy the manifests of nested directories, for example, of a mono-repo.
+	Directory *v1alpha1.ApplicationSourceDirectory `json:"directory,omitempty"`
+}
+
+// ApplicationDestination holds information about the application's destination
+type ApplicationDestination struct {
+	// ManagedEnvironment is the name of the GitOpsDeploymentManagedEnvironment to deploy to
+	// (v1alpha1: '.spec.destination.environment'). If empty, the resources are deployed to the API namespace.
+	ManagedEnvironment string `json:"managedEnvironment,omitempty"`
+
+	// The namespace will only be set for namespace-scoped resources that have not set a value for .metadata.namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SyncMode controls whether a GitOpsDeployment is synchronized automatically.
+type SyncMode string
+
+const (
+	// SyncMode_Automated: whenever a new commit occurs in the GitOps repository, or the Argo CD Application is out of
+	// sync, Argo CD should be told to (re)synchronize.
+	SyncMode_Automated SyncMode = "Automated"
+
+	// SyncMode_Manual: Argo CD should never be told to resynchronize. Instead, synchronize operations will be triggered
+	// via GitOpsDeploymentSyncRun operations only.
+	SyncMode_Manual SyncMode = "Manual"
+)
+
+// SyncPolicy controls when and how a sync will be performed.
+type SyncPolicy struct {
+	// Mode is either 'Automated' or 'Manual' (v1alpha1: '.spec.type').
+	//
+	// If empty, the 'type' value of the 'gitopsdeployment-defaults' ConfigMap of the namespace is used (if it exists),
+	// otherwise the GitOpsDeployment is Automated if .spec.syncPolicy.automated is set, and Manual if not.
+	// +kubebuilder:validation:Enum=Automated;Manual
+	Mode SyncMode `json:"mode,omitempty"`
+
+	// Automated controls the automated sync of the GitOpsDeployment.
+	// If not set, automated GitOpsDeployments both prune and self-heal.
+	Automated *v1alpha1.SyncPolicyAutomated `json:"automated,omitempty"`
+
+	// SyncOptions are the Argo CD sync options of the GitOpsDeployment. A sync option that is not set uses the Argo CD
+	// default.
+	SyncOptions *SyncOptions `json:"syncOptions,omitempty"`
+
+	// ManagedNamespaceMetadata contains the labels and annotations that are set on the destination namespace, when it
+	// is created by Argo CD (via the 'createNamespace' sync option): for example, to set the pod security admission
+	// level of the namespace. This is set via Argo CD's 'syncPolicy.managedNamespaceMetadata'.
+	ManagedNamespaceMetadata *v1alpha1.ManagedNamespaceMetadata `json:"managedNamespaceMetadata,omitempty"`
+}
+
+// SyncOptions are the Argo CD sync options supported by GitOpsDeployment. In v1alpha1, these are a list of
+// 'Name=value' strings (for example, 'CreateNamespace=true').
+type SyncOptions struct {
+	// CreateNamespace specifies whether Argo CD creates the destination namespace, if it does not exist.
+	CreateNamespace *bool `json:"createNamespace,omitempty"`
+
+	// ServerSideApply specifies whether Argo CD uses server-side apply, rather than client-side apply.
+	ServerSideApply *bool `json:"serverSideApply,omitempty"`
+
+	// Validate specifies whether Argo CD validates the resources (as with 'kubectl apply --validate').
+	Validate *bool `json:"validate,omitempty"`
+
+	// ApplyOutOfSyncOnly specifies whether Argo CD only applies the resources that are out of sync.
+	ApplyOutOfSyncOnly *bool `json:"applyOutOfSyncOnly,omitempty"`
+}
+
+// CommonMetadata contains the labels and annotations that are added to every resource deployed by a GitOpsDeployment.
+type CommonMetadata struct {
+	// Labels are added to every resource deployed by the GitOpsDeployment (v1alpha1: '.spec.commonLabels').
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to every resource deployed by the GitOpsDeployment (v1alpha1: '.spec.commonAnnotations').
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Sync Status",type=string,JSONPath=`.status.sync.status`
+// +kubebuilder:printcolumn:name="Health Status",type=string,JSONPath=`.status.health.status`
+
+// GitOpsDeployment is the Schema for the gitopsdeployments API
+type GitOpsDeployment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GitOpsDeploymentSpec `json:"spec,omitempty"`
+
+	// Status is unchanged from v1alpha1.
+	Status v1alpha1.GitOpsDeploymentStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitOpsDeploymentList contains a list of GitOpsDeployment
+type GitOpsDeploymentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitOpsDeployment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitOpsDeployment{}, &GitOpsDeploymentList{})
+}
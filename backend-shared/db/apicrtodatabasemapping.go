@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 func (dbq *PostgreSQLDatabaseQueries) DeleteAPICRToDatabaseMapping(ctx context.Context, obj *APICRToDatabaseMapping) (int, error) {
@@ -54,6 +55,8 @@ func (dbq *PostgreSQLDatabaseQueries) CreateAPICRToDatabaseMapping(ctx context.C
 		return err
 	}
 
+	obj.Created_on = time.Now()
+
 	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
 	if err != nil {
 		return fmt.Errorf("error on inserting APICRToDatabaseMapping %v", err)
@@ -147,6 +150,36 @@ func (dbq *PostgreSQLDatabaseQueries) ListAPICRToDatabaseMappingByAPINamespaceAn
 	return nil
 }
 
+// ListAPICRToDatabaseMappingByNamespaceUID lists all APICRToDatabaseMappings of API resources that are in the namespace with the given UID
+func (dbq *PostgreSQLDatabaseQueries) ListAPICRToDatabaseMappingByNamespaceUID(ctx context.Context, namespaceUID string,
+	apiCRToDBMappingParam *[]APICRToDatabaseMapping) error {
+
+	if err := validateQueryParamsEntity(apiCRToDBMappingParam, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("ListAPICRToDatabaseMappingByNamespaceUID",
+		"NamespaceUID", namespaceUID,
+	); err != nil {
+		return err
+	}
+
+	var dbResults []APICRToDatabaseMapping
+
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("atdbm.api_resource_namespace_uid = ?", namespaceUID).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListAPICRToDatabaseMappingByNamespaceUID: %v", err)
+	}
+
+	*apiCRToDBMappingParam = dbResults
+
+	return nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllAPICRToDatabaseMappings(ctx context.Context, mappings *[]APICRToDatabaseMapping) error {
 
 	if err := validateUnsafeQueryParamsNoPK(dbq); err != nil {
@@ -242,3 +275,32 @@ func (dbq *PostgreSQLDatabaseQueries) GetAPICRToDatabaseMappingBatch(ctx context
 		Context(ctx).
 		Select()
 }
+
+// ListAPICRToDatabaseMappingsOlderThan returns (up to 'limit') APICRToDatabaseMappings of the given API resource type that
+// were created before 'createdBefore', ordered by seq_id. Only rows with a seq_id greater than 'afterSeqID' are returned,
+// so that the caller may page through the results, even while deleting the rows it has processed.
+func (dbq *PostgreSQLDatabaseQueries) ListAPICRToDatabaseMappingsOlderThan(ctx context.Context, resourceType APICRToDatabaseMapping_ResourceType,
+	createdBefore time.Time, afterSeqID int64, limit int, apiCRToDatabaseMappings *[]APICRToDatabaseMapping) error {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("ListAPICRToDatabaseMappingsOlderThan", "resourceType", string(resourceType)); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(apiCRToDatabaseMappings).
+		Where("atdbm.api_resource_type = ?", resourceType).
+		Where("atdbm.created_on < ?", createdBefore).
+		Where("atdbm.seq_id > ?", afterSeqID).
+		Order("seq_id ASC").
+		Limit(limit).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving APICRToDatabaseMappings older than %v: %v", createdBefore, err)
+	}
+
+	return nil
+}
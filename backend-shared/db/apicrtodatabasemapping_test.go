@@ -3,6 +3,7 @@ package db_test
 import (
 	"context"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -40,6 +41,8 @@ var _ = Describe("Apicrtodatabasemapping Tests", func() {
 
 			err = dbq.GetDatabaseMappingForAPICR(ctx, &fetchRow)
 			Expect(err).To(BeNil())
+			Expect(fetchRow.Created_on.After(time.Now().Add(time.Minute*-5))).To(BeTrue(), "Created on should be within the last 5 minutes")
+			item.Created_on = fetchRow.Created_on
 			Expect(fetchRow).Should(Equal(item))
 
 			var items []db.APICRToDatabaseMapping
@@ -48,6 +51,15 @@ var _ = Describe("Apicrtodatabasemapping Tests", func() {
 			Expect(err).To(BeNil())
 			Expect(items[0]).Should(Equal(item))
 
+			err = dbq.ListAPICRToDatabaseMappingByNamespaceUID(ctx, item.NamespaceUID, &items)
+			Expect(err).To(BeNil())
+			Expect(items).Should(HaveLen(1))
+			Expect(items[0]).Should(Equal(item))
+
+			err = dbq.ListAPICRToDatabaseMappingByNamespaceUID(ctx, "test-other-namespace-uid", &items)
+			Expect(err).To(BeNil())
+			Expect(items).Should(BeEmpty())
+
 			rowsAffected, err := dbq.DeleteAPICRToDatabaseMapping(ctx, &fetchRow)
 			Expect(err).To(BeNil())
 			Expect(rowsAffected).To(Equal((1)))
@@ -66,5 +78,61 @@ var _ = Describe("Apicrtodatabasemapping Tests", func() {
 			Expect(db.IsMaxLengthError(err)).To(Equal(true))
 
 		})
+
+		It("Should list the APICRToDatabaseMappings of a type that were created before a given time, in batches", func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx := context.Background()
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+
+			createMapping := func(uid string, resourceType db.APICRToDatabaseMapping_ResourceType, relationType db.APICRToDatabaseMapping_DBRelationType) db.APICRToDatabaseMapping {
+				item := db.APICRToDatabaseMapping{
+					APIResourceType:      resourceType,
+					APIResourceUID:       uid,
+					APIResourceName:      uid,
+					APIResourceNamespace: "test-k8s-namespace",
+					NamespaceUID:         "test-namespace-uid",
+					DBRelationType:       relationType,
+					DBRelationKey:        "test-key-" + uid,
+				}
+				Expect(dbq.CreateAPICRToDatabaseMapping(ctx, &item)).To(Succeed())
+				return item
+			}
+
+			first := createMapping("test-uid-1", db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun, db.APICRToDatabaseMapping_DBRelationType_SyncOperation)
+			second := createMapping("test-uid-2", db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun, db.APICRToDatabaseMapping_DBRelationType_SyncOperation)
+			createMapping("test-uid-3", db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential, db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential)
+
+			createdBefore := time.Now()
+
+			createMapping("test-uid-4", db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun, db.APICRToDatabaseMapping_DBRelationType_SyncOperation)
+
+			By("listing only the mappings of the given type, created before the given time, in batches")
+			var items []db.APICRToDatabaseMapping
+			err = dbq.ListAPICRToDatabaseMappingsOlderThan(ctx, db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun, createdBefore, 0, 1, &items)
+			Expect(err).To(BeNil())
+			Expect(items).To(HaveLen(1))
+			Expect(items[0].APIResourceUID).To(Equal(first.APIResourceUID))
+
+			afterSeqID := items[0].SeqID
+			items = []db.APICRToDatabaseMapping{}
+			err = dbq.ListAPICRToDatabaseMappingsOlderThan(ctx, db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun, createdBefore, afterSeqID, 1, &items)
+			Expect(err).To(BeNil())
+			Expect(items).To(HaveLen(1))
+			Expect(items[0].APIResourceUID).To(Equal(second.APIResourceUID))
+
+			afterSeqID = items[0].SeqID
+			items = []db.APICRToDatabaseMapping{}
+			err = dbq.ListAPICRToDatabaseMappingsOlderThan(ctx, db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun, createdBefore, afterSeqID, 1, &items)
+			Expect(err).To(BeNil())
+			Expect(items).To(BeEmpty())
+
+			By("returning an error if the resource type is empty")
+			err = dbq.ListAPICRToDatabaseMappingsOlderThan(ctx, "", createdBefore, 0, 1, &items)
+			Expect(err).ToNot(BeNil())
+		})
 	})
 })
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/go-pg/pg/v10/orm"
 )
 
 func (dbq *PostgreSQLDatabaseQueries) CheckedGetApplicationById(ctx context.Context, application *Application, ownerId string) error {
@@ -40,6 +42,8 @@ func (dbq *PostgreSQLDatabaseQueries) CheckedGetApplicationById(ctx context.Cont
 	}
 
 	// Ensure there is a cluster access for this user, and the application's managed env and engine instance
+	// - An ApplicationOwner row does not grant access on its own: the owners of an Application are used for
+	//   attribution, and an owner whose cluster access has been removed must not retain access to the Application.
 	if err := dbq.GetClusterAccessByPrimaryKey(ctx,
 		&ClusterAccess{Clusteraccess_user_id: ownerId,
 			Clusteraccess_managed_environment_id:    applicationResult.Managed_environment_id,
@@ -89,6 +93,57 @@ func (dbq *PostgreSQLDatabaseQueries) GetApplicationById(ctx context.Context, ap
 	return nil
 }
 
+// GetTenantApplicationById retrieves the Application, only if it is owned by the given tenant: that is, if it is
+// referenced by a DeploymentToApplicationMapping in the tenant's namespace, or (if the tenant has a ClusterUser) it has
+// an ApplicationOwner row for the tenant's ClusterUser. An Application of another tenant is reported as not found.
+func (dbq *PostgreSQLDatabaseQueries) GetTenantApplicationById(ctx context.Context, application *Application, tenant Tenant) error {
+
+	if err := validateQueryParamsEntity(application, dbq); err != nil {
+		return err
+	}
+
+	if IsEmpty(application.Application_id) {
+		return fmt.Errorf("application_Id is nil")
+	}
+
+	if IsEmpty(tenant.NamespaceUID) {
+		return fmt.Errorf("tenant namespace UID is nil")
+	}
+
+	var results []Application
+
+	if err := dbq.dbConnection.Model(&results).
+		Where("application.application_id = ?", application.Application_id).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("EXISTS (SELECT 1 FROM deploymenttoapplicationmapping AS dta "+
+				"WHERE dta.application_id = application.application_id AND dta.namespace_uid = ?)", tenant.NamespaceUID)
+
+			if !IsEmpty(tenant.ClusterUserID) {
+				q = q.WhereOr("EXISTS (SELECT 1 FROM applicationowner AS appo "+
+					"WHERE appo.applicationowner_application_id = application.application_id AND appo.applicationowner_user_id = ?)",
+					tenant.ClusterUserID)
+			}
+			return q, nil
+		}).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving Application of tenant: %v", err)
+	}
+
+	if len(results) == 0 {
+		return NewResultNotFoundError(fmt.Sprintf("Application '%s'", application.Application_id))
+	}
+
+	if len(results) > 1 {
+		return fmt.Errorf("multiple results found on retrieving Application: %v", application.Application_id)
+	}
+
+	*application = results[0]
+
+	return nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) CheckedCreateApplication(ctx context.Context, obj *Application, ownerId string) error {
 
 	if err := validateQueryParamsEntity(obj, dbq); err != nil {
@@ -250,7 +305,7 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateApplication(ctx context.Context, obj
 		return err
 	}
 
-	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
+	result, err := dbq.dbConnection.Model(obj).WherePK().Value("version", "version + 1").Returning("version").Context(ctx).Update()
 	if err != nil {
 		return fmt.Errorf("error on updating application %v", err)
 	}
@@ -263,6 +318,104 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateApplication(ctx context.Context, obj
 
 }
 
+// CompareAndSwapApplication updates the Application row, but only if the version of the row in the database is still
+// equal to obj.Version (that is, the row has not been updated since obj was read). On success, obj.Version is set to
+// the new version of the row.
+//
+// Returns an error matched by IsConflictError if the row was updated by another caller: the caller should re-read the
+// row, re-apply its changes, and try again. Returns an error matched by IsResultNotFoundError if the row does not exist.
+func (dbq *PostgreSQLDatabaseQueries) CompareAndSwapApplication(ctx context.Context, obj *Application) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("CompareAndSwapApplication",
+		"Application_id", obj.Application_id,
+		"Engine_instance_inst_id", obj.Engine_instance_inst_id,
+		"Spec_field", obj.Spec_field,
+		"Name", obj.Name); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	expectedVersion := obj.Version
+	obj.Version = expectedVersion + 1
+
+	result, err := dbq.dbConnection.Model(obj).WherePK().Where("version = ?", expectedVersion).Context(ctx).Update()
+	if err != nil {
+		obj.Version = expectedVersion
+		return fmt.Errorf("error on compare-and-swap of application: %v", err)
+	}
+
+	if result.RowsAffected() == 1 {
+		return nil
+	}
+	obj.Version = expectedVersion
+
+	// No rows were updated: either the row doesn't exist, or its version has changed since it was read.
+	exists, err := dbq.dbConnection.Model(&Application{}).
+		Where("application_id = ?", obj.Application_id).
+		Context(ctx).
+		Exists()
+	if err != nil {
+		return fmt.Errorf("error on checking existence of application: %v", err)
+	}
+	if !exists {
+		return NewResultNotFoundError(fmt.Sprintf("application '%s'", obj.Application_id))
+	}
+
+	return fmt.Errorf("%w: application '%s' is no longer at version %d", ErrConflict, obj.Application_id, expectedVersion)
+}
+
+// SoftDeleteApplicationById marks the Application as deleted, by setting its 'deleted_on' field to the current time.
+// The row is otherwise unchanged, and may be restored with RestoreApplicationById. Returns the number of rows updated,
+// which is 0 if the Application does not exist, or was already soft-deleted.
+func (dbq *PostgreSQLDatabaseQueries) SoftDeleteApplicationById(ctx context.Context, id string) (int, error) {
+
+	if err := validateQueryParams(id, dbq); err != nil {
+		return 0, err
+	}
+
+	result, err := dbq.dbConnection.Model(&Application{}).
+		Set("deleted_on = ?", time.Now()).
+		Set("version = version + 1").
+		Where("application_id = ?", id).
+		Where("deleted_on IS NULL").
+		Context(ctx).
+		Update()
+	if err != nil {
+		return 0, fmt.Errorf("error on soft-deleting application: %v", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// RestoreApplicationById reverses SoftDeleteApplicationById, by setting the 'deleted_on' field of the Application to
+// null. Returns the number of rows updated, which is 0 if the Application does not exist, or was not soft-deleted.
+func (dbq *PostgreSQLDatabaseQueries) RestoreApplicationById(ctx context.Context, id string) (int, error) {
+
+	if err := validateQueryParams(id, dbq); err != nil {
+		return 0, err
+	}
+
+	result, err := dbq.dbConnection.Model(&Application{}).
+		Set("deleted_on = NULL").
+		Set("version = version + 1").
+		Where("application_id = ?", id).
+		Where("deleted_on IS NOT NULL").
+		Context(ctx).
+		Update()
+	if err != nil {
+		return 0, fmt.Errorf("error on restoring application: %v", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
 // RemoveManagedEnvironmentFromAllApplications update the 'managed_environment_id' field to null
 // for all Applications that reference a specific managed environment. This function is used while
 // deleting a managed environment.
@@ -315,6 +468,38 @@ func (dbq *PostgreSQLDatabaseQueries) ListApplicationsForManagedEnvironment(ctx
 
 }
 
+// ListApplicationsForGitopsEngineInstance returns a list of all Applications that are deployed by the specified
+// GitopsEngineInstance row
+func (dbq *PostgreSQLDatabaseQueries) ListApplicationsForGitopsEngineInstance(ctx context.Context,
+	gitopsEngineInstanceID string, applications *[]Application) (int, error) {
+
+	if err := validateQueryParams(gitopsEngineInstanceID, dbq); err != nil {
+		return 0, err
+	}
+
+	err := dbq.dbConnection.Model(applications).Context(ctx).Where("engine_instance_inst_id = ?", gitopsEngineInstanceID).
+		Order("seq_id ASC").Select()
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve applications with gitops engine instance id: %v", err)
+	}
+
+	return len(*applications), nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) CountApplicationsForGitopsEngineInstance(ctx context.Context, gitopsEngineInstanceID string) (int, error) {
+
+	if err := validateQueryParams(gitopsEngineInstanceID, dbq); err != nil {
+		return 0, err
+	}
+
+	count, err := dbq.dbConnection.Model((*Application)(nil)).Context(ctx).Where("engine_instance_inst_id = ?", gitopsEngineInstanceID).Count()
+	if err != nil {
+		return 0, fmt.Errorf("unable to count applications with gitops engine instance id: %v", err)
+	}
+
+	return count, nil
+}
+
 // Get applications in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 // For example if you want applications starting from 51-150 then set the limit to 100 and offset to 50.
 func (dbq *PostgreSQLDatabaseQueries) GetApplicationBatch(ctx context.Context, applications *[]Application, limit, offSet int) error {
@@ -348,6 +533,7 @@ func (obj *Application) GetAsLogKeyValues() []interface{} {
 		"engineInstanceID", obj.Engine_instance_inst_id,
 		"managedEnvironmentID", obj.Managed_environment_id,
 		"applicationName", obj.Name,
-		"applicationSpecField", obj.Spec_field}
+		"applicationSpecField", obj.Spec_field,
+		"applicationSoftDeleted", obj.IsSoftDeleted()}
 
 }
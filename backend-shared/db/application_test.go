@@ -136,4 +136,169 @@ var _ = Describe("Application Test", func() {
 		Expect(err).To(BeNil())
 		Expect(len(listOfApplicationsFromDB)).To(Equal(3))
 	})
+
+	It("Should only update an Application with CompareAndSwapApplication if its version is unchanged", func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx := context.Background()
+		dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+		defer dbq.CloseDatabase()
+
+		_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		application := db.Application{
+			Application_id:          "test-my-application-cas",
+			Name:                    "my-application",
+			Spec_field:              "{}",
+			Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+			Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+		}
+		err = dbq.CreateApplication(ctx, &application)
+		Expect(err).To(BeNil())
+		Expect(application.Version).To(Equal(int64(0)))
+
+		By("reading the same row twice, as two concurrent callers would")
+		first := db.Application{Application_id: application.Application_id}
+		Expect(dbq.GetApplicationById(ctx, &first)).To(Succeed())
+		second := db.Application{Application_id: application.Application_id}
+		Expect(dbq.GetApplicationById(ctx, &second)).To(Succeed())
+
+		By("updating the row from the first copy, which should succeed and increment the version")
+		first.Spec_field = "{\"first\": true}"
+		err = dbq.CompareAndSwapApplication(ctx, &first)
+		Expect(err).To(BeNil())
+		Expect(first.Version).To(Equal(int64(1)))
+
+		By("updating the row from the stale second copy, which should fail with a conflict")
+		second.Spec_field = "{\"second\": true}"
+		err = dbq.CompareAndSwapApplication(ctx, &second)
+		Expect(db.IsConflictError(err)).To(BeTrue())
+		Expect(second.Version).To(Equal(int64(0)))
+
+		fromDB := db.Application{Application_id: application.Application_id}
+		Expect(dbq.GetApplicationById(ctx, &fromDB)).To(Succeed())
+		Expect(fromDB.Spec_field).To(Equal(first.Spec_field))
+
+		By("re-reading and retrying, which should succeed")
+		Expect(dbq.GetApplicationById(ctx, &second)).To(Succeed())
+		second.Spec_field = "{\"second\": true}"
+		err = dbq.CompareAndSwapApplication(ctx, &second)
+		Expect(err).To(BeNil())
+		Expect(second.Version).To(Equal(int64(2)))
+
+		By("verifying that UpdateApplication and SoftDeleteApplicationById also increment the version")
+		err = dbq.UpdateApplication(ctx, &second)
+		Expect(err).To(BeNil())
+		Expect(second.Version).To(Equal(int64(3)))
+
+		_, err = dbq.SoftDeleteApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		err = dbq.CompareAndSwapApplication(ctx, &second)
+		Expect(db.IsConflictError(err)).To(BeTrue())
+
+		By("returning a not found error if the row doesn't exist")
+		rowsAffected, err := dbq.DeleteApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		err = dbq.CompareAndSwapApplication(ctx, &second)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+		Expect(db.IsConflictError(err)).To(BeFalse())
+	})
+
+	It("Should soft-delete and restore an Application", func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx := context.Background()
+		dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+		defer dbq.CloseDatabase()
+
+		_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		application := db.Application{
+			Application_id:          "test-my-application",
+			Name:                    "my-application",
+			Spec_field:              "{}",
+			Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+			Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+		}
+		err = dbq.CreateApplication(ctx, &application)
+		Expect(err).To(BeNil())
+		Expect(application.IsSoftDeleted()).To(BeFalse())
+
+		By("soft-deleting the Application")
+		rowsUpdated, err := dbq.SoftDeleteApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsUpdated).To(Equal(1))
+
+		err = dbq.GetApplicationById(ctx, &application)
+		Expect(err).To(BeNil())
+		Expect(application.IsSoftDeleted()).To(BeTrue())
+		Expect(application.Deleted_on.After(time.Now().Add(time.Minute * -5))).To(BeTrue())
+
+		By("verifying that soft-deleting an already soft-deleted Application is a no-op")
+		rowsUpdated, err = dbq.SoftDeleteApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsUpdated).To(Equal(0))
+
+		By("restoring the Application")
+		rowsUpdated, err = dbq.RestoreApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsUpdated).To(Equal(1))
+
+		err = dbq.GetApplicationById(ctx, &application)
+		Expect(err).To(BeNil())
+		Expect(application.IsSoftDeleted()).To(BeFalse())
+
+		rowsUpdated, err = dbq.RestoreApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsUpdated).To(Equal(0))
+
+		rowsUpdated, err = dbq.SoftDeleteApplicationById(ctx, "does-not-exist")
+		Expect(err).To(BeNil())
+		Expect(rowsUpdated).To(Equal(0))
+	})
+
+	It("Should list the Applications of a GitopsEngineInstance", func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx := context.Background()
+		dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+		defer dbq.CloseDatabase()
+
+		_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		for _, applicationID := range []string{"test-my-application-1", "test-my-application-2"} {
+			application := db.Application{
+				Application_id:          applicationID,
+				Name:                    applicationID,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbq.CreateApplication(ctx, &application)
+			Expect(err).To(BeNil())
+		}
+
+		var applications []db.Application
+		count, err := dbq.ListApplicationsForGitopsEngineInstance(ctx, gitopsEngineInstance.Gitopsengineinstance_id, &applications)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+		Expect(applications[0].Application_id).To(Equal("test-my-application-1"))
+		Expect(applications[1].Application_id).To(Equal("test-my-application-2"))
+
+		applications = []db.Application{}
+		count, err = dbq.ListApplicationsForGitopsEngineInstance(ctx, "does-not-exist", &applications)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(0))
+	})
 })
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllApplicationOwners(ctx context.Context, applicationOwners *[]ApplicationOwner) error {
+
+	if err := validateUnsafeQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(applicationOwners).Context(ctx).Select(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) GetApplicationOwnerByPrimaryKey(ctx context.Context, obj *ApplicationOwner) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("GetApplicationOwnerByPrimaryKey",
+		"ApplicationID", obj.ApplicationID,
+		"ClusterUserID", obj.ClusterUserID); err != nil {
+		return err
+	}
+
+	var dbResults []ApplicationOwner
+
+	err := dbq.dbConnection.Model(&dbResults).
+		Where("applicationowner_application_id = ?", obj.ApplicationID).
+		Where("applicationowner_user_id = ?", obj.ClusterUserID).
+		Context(ctx).Select()
+
+	if err != nil {
+		return fmt.Errorf("unable to retrieve ApplicationOwner in GetApplicationOwnerByPrimaryKey: %v", err)
+	}
+
+	if len(dbResults) == 0 {
+		return NewResultNotFoundError("No results for ApplicationOwner")
+	}
+
+	if len(dbResults) != 1 {
+		return fmt.Errorf("unexpected number of results for GetApplicationOwnerByPrimaryKey")
+	}
+
+	*obj = dbResults[0]
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) CreateApplicationOwner(ctx context.Context, obj *ApplicationOwner) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("CreateApplicationOwner",
+		"ApplicationID", obj.ApplicationID,
+		"ClusterUserID", obj.ClusterUserID); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
+	if err != nil {
+		return fmt.Errorf("error on inserting application owner: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) DeleteApplicationOwner(ctx context.Context, applicationID string, clusterUserID string) (int, error) {
+
+	if err := validateQueryParams(applicationID, dbq); err != nil {
+		return 0, err
+	}
+
+	if IsEmpty(clusterUserID) {
+		return 0, fmt.Errorf("primary key is empty")
+	}
+
+	result := &ApplicationOwner{}
+
+	deleteResult, err := dbq.dbConnection.Model(result).
+		Where("applicationowner_application_id = ?", applicationID).
+		Where("applicationowner_user_id = ?", clusterUserID).
+		Context(ctx).
+		Delete()
+	if err != nil {
+		return 0, fmt.Errorf("error on deleting application owner: %v", err)
+	}
+
+	return deleteResult.RowsAffected(), nil
+}
+
+// ListApplicationOwnersByApplicationID returns the owners of an Application, from oldest to newest.
+func (dbq *PostgreSQLDatabaseQueries) ListApplicationOwnersByApplicationID(ctx context.Context, applicationID string, applicationOwners *[]ApplicationOwner) error {
+
+	if err := validateQueryParams(applicationID, dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(applicationOwners).
+		Where("appo.applicationowner_application_id = ?", applicationID).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListApplicationOwnersByApplicationID: %v", err)
+	}
+
+	return nil
+}
+
+// ListApplicationOwnersByClusterUserID returns the Applications that are owned by a user, from oldest to newest.
+func (dbq *PostgreSQLDatabaseQueries) ListApplicationOwnersByClusterUserID(ctx context.Context, clusterUserID string, applicationOwners *[]ApplicationOwner) error {
+
+	if err := validateQueryParams(clusterUserID, dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(applicationOwners).
+		Where("appo.applicationowner_user_id = ?", clusterUserID).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListApplicationOwnersByClusterUserID: %v", err)
+	}
+
+	return nil
+}
+
+var _ AppScopedDisposableResource = &ApplicationOwner{}
+
+func (obj *ApplicationOwner) DisposeAppScoped(ctx context.Context, dbq ApplicationScopedQueries) error {
+	if dbq == nil {
+		return fmt.Errorf("missing database interface in ApplicationOwner dispose")
+	}
+
+	_, err := dbq.DeleteApplicationOwner(ctx, obj.ApplicationID, obj.ClusterUserID)
+	return err
+}
+
+// GetAsLogKeyValues returns an []interface that can be passed to log.Info(...).
+// e.g. log.Info("Creating database resource", obj.GetAsLogKeyValues()...)
+func (obj *ApplicationOwner) GetAsLogKeyValues() []interface{} {
+	if obj == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{"applicationID", obj.ApplicationID,
+		"userID", obj.ClusterUserID}
+}
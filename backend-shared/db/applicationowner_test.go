@@ -0,0 +1,166 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("ApplicationOwner Tests", func() {
+
+	var (
+		ctx                context.Context
+		dbq                db.AllDatabaseQueries
+		application        db.Application
+		firstClusterUser   db.ClusterUser
+		secondClusterUser  db.ClusterUser
+		managedEnvironment *db.ManagedEnvironment
+	)
+
+	BeforeEach(func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+
+		var engineInstance *db.GitopsEngineInstance
+		_, managedEnvironment, _, engineInstance, _, err = db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		application = db.Application{
+			Application_id:          "test-my-application",
+			Name:                    "my-application",
+			Spec_field:              "{}",
+			Engine_instance_inst_id: engineInstance.Gitopsengineinstance_id,
+			Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+		}
+		err = dbq.CreateApplication(ctx, &application)
+		Expect(err).To(BeNil())
+
+		firstClusterUser = db.ClusterUser{
+			Clusteruser_id: "test-application-owner-1",
+			User_name:      "test-application-owner-1",
+		}
+		err = dbq.CreateClusterUser(ctx, &firstClusterUser)
+		Expect(err).To(BeNil())
+
+		secondClusterUser = db.ClusterUser{
+			Clusteruser_id: "test-application-owner-2",
+			User_name:      "test-application-owner-2",
+		}
+		err = dbq.CreateClusterUser(ctx, &secondClusterUser)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		dbq.CloseDatabase()
+	})
+
+	It("should create, get, list and delete ApplicationOwners", func() {
+
+		By("associating both users with the same Application")
+		for _, clusterUser := range []db.ClusterUser{firstClusterUser, secondClusterUser} {
+			applicationOwner := db.ApplicationOwner{
+				ApplicationID: application.Application_id,
+				ClusterUserID: clusterUser.Clusteruser_id,
+			}
+			err := dbq.CreateApplicationOwner(ctx, &applicationOwner)
+			Expect(err).To(BeNil())
+		}
+
+		fetchRow := db.ApplicationOwner{
+			ApplicationID: application.Application_id,
+			ClusterUserID: firstClusterUser.Clusteruser_id,
+		}
+		err := dbq.GetApplicationOwnerByPrimaryKey(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.Created_on.IsZero()).To(BeFalse())
+
+		By("verifying a duplicate ApplicationOwner cannot be created")
+		duplicate := db.ApplicationOwner{
+			ApplicationID: application.Application_id,
+			ClusterUserID: firstClusterUser.Clusteruser_id,
+		}
+		err = dbq.CreateApplicationOwner(ctx, &duplicate)
+		Expect(err).ToNot(BeNil())
+
+		var applicationOwners []db.ApplicationOwner
+		err = dbq.ListApplicationOwnersByApplicationID(ctx, application.Application_id, &applicationOwners)
+		Expect(err).To(BeNil())
+		Expect(applicationOwners).To(HaveLen(2))
+		Expect(applicationOwners[0].ClusterUserID).To(Equal(firstClusterUser.Clusteruser_id))
+		Expect(applicationOwners[1].ClusterUserID).To(Equal(secondClusterUser.Clusteruser_id))
+
+		applicationOwners = []db.ApplicationOwner{}
+		err = dbq.ListApplicationOwnersByClusterUserID(ctx, secondClusterUser.Clusteruser_id, &applicationOwners)
+		Expect(err).To(BeNil())
+		Expect(applicationOwners).To(HaveLen(1))
+		Expect(applicationOwners[0].ApplicationID).To(Equal(application.Application_id))
+
+		By("deleting one of the owners")
+		rowsAffected, err := dbq.DeleteApplicationOwner(ctx, application.Application_id, firstClusterUser.Clusteruser_id)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		err = dbq.GetApplicationOwnerByPrimaryKey(ctx, &fetchRow)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+		By("deleting the Application, which should delete its remaining owners")
+		rowsAffected, err = dbq.DeleteApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		applicationOwners = []db.ApplicationOwner{}
+		err = dbq.ListApplicationOwnersByClusterUserID(ctx, secondClusterUser.Clusteruser_id, &applicationOwners)
+		Expect(err).To(BeNil())
+		Expect(applicationOwners).To(BeEmpty())
+	})
+
+	It("should not create an ApplicationOwner with empty or too long fields", func() {
+
+		applicationOwner := db.ApplicationOwner{
+			ApplicationID: application.Application_id,
+		}
+		err := dbq.CreateApplicationOwner(ctx, &applicationOwner)
+		Expect(err).ToNot(BeNil())
+
+		applicationOwner.ClusterUserID = strings.Repeat("abc", 100)
+		err = dbq.CreateApplicationOwner(ctx, &applicationOwner)
+		Expect(err).ToNot(BeNil())
+		Expect(db.IsMaxLengthError(err)).To(BeTrue())
+	})
+
+	It("should not grant access to an Application to owners without a ClusterAccess, via CheckedGetApplicationById", func() {
+
+		By("verifying an owner of the Application, without a ClusterAccess, is denied access")
+		applicationOwner := db.ApplicationOwner{
+			ApplicationID: application.Application_id,
+			ClusterUserID: secondClusterUser.Clusteruser_id,
+		}
+		err := dbq.CreateApplicationOwner(ctx, &applicationOwner)
+		Expect(err).To(BeNil())
+
+		fetchApplication := db.Application{Application_id: application.Application_id}
+		err = dbq.CheckedGetApplicationById(ctx, &fetchApplication, secondClusterUser.Clusteruser_id)
+		Expect(db.IsAccessDeniedError(err)).To(BeTrue())
+
+		By("verifying the owner is allowed access once they have a ClusterAccess")
+		clusterAccess := db.ClusterAccess{
+			Clusteraccess_user_id:                   secondClusterUser.Clusteruser_id,
+			Clusteraccess_managed_environment_id:    application.Managed_environment_id,
+			Clusteraccess_gitops_engine_instance_id: application.Engine_instance_inst_id,
+		}
+		err = dbq.CreateClusterAccess(ctx, &clusterAccess)
+		Expect(err).To(BeNil())
+
+		err = dbq.CheckedGetApplicationById(ctx, &fetchApplication, secondClusterUser.Clusteruser_id)
+		Expect(err).To(BeNil())
+		Expect(fetchApplication.Name).To(Equal(application.Name))
+	})
+})
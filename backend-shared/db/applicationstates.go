@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+
+	"github.com/go-pg/pg/v10"
 )
 
 const (
@@ -55,6 +57,10 @@ func (dbq *PostgreSQLDatabaseQueries) CreateApplicationState(ctx context.Context
 		return err
 	}
 
+	if err := validateApplicationStateHealthAndSyncStatus(obj); err != nil {
+		return err
+	}
+
 	if err := validateFieldLength(obj); err != nil {
 		return err
 	}
@@ -95,6 +101,10 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateApplicationState(ctx context.Context
 		return err
 	}
 
+	if err := validateApplicationStateHealthAndSyncStatus(obj); err != nil {
+		return err
+	}
+
 	if err := validateFieldLength(obj); err != nil {
 		return err
 	}
@@ -122,6 +132,146 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateApplicationState(ctx context.Context
 	return nil
 }
 
+// UpdateApplicationStateHealthOnly updates only the health and message columns of an existing ApplicationState row.
+//
+// This should be used when only the health of an Application has changed, to avoid rewriting the (potentially large)
+// resources column of the row.
+func (dbq *PostgreSQLDatabaseQueries) UpdateApplicationStateHealthOnly(ctx context.Context, obj *ApplicationState) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("UpdateApplicationStateHealthOnly",
+		"Applicationstate_application_id", obj.Applicationstate_application_id,
+		"Health", obj.Health); err != nil {
+		return err
+	}
+
+	if _, err := ParseApplicationStateHealth(obj.Health); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	result, err := dbq.dbConnection.Model(obj).Context(ctx).
+		Column("health", "message").
+		Where("Applicationstate_application_id = ?", obj.Applicationstate_application_id).Update()
+	if err != nil {
+		return fmt.Errorf("error on updating health of application state %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("%s: %d", ErrorUnexpectedNumberOfRowsAffected, result.RowsAffected())
+	}
+
+	return nil
+}
+
+// UpsertApplicationStates creates or updates the given ApplicationState rows, in a single statement.
+//
+// This should be used when a large number of ApplicationStates are written at once (for example, when the
+// ApplicationStates are written in batches by the cluster-agent), rather than creating/updating each row with its
+// own statement. The resources column of an existing row is only rewritten if it has changed.
+//
+// The ApplicationStates must have different IDs, and the corresponding Application rows must exist.
+func (dbq *PostgreSQLDatabaseQueries) UpsertApplicationStates(ctx context.Context, applicationStates []ApplicationState) error {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if len(applicationStates) == 0 {
+		return nil
+	}
+
+	ids := map[string]bool{}
+
+	for idx := range applicationStates {
+		obj := &applicationStates[idx]
+
+		if err := isEmptyValues("UpsertApplicationStates",
+			"Applicationstate_application_id", obj.Applicationstate_application_id,
+			"Health", obj.Health,
+			"Sync_Status", obj.Sync_Status,
+			"ReconciledState", obj.ReconciledState); err != nil {
+			return err
+		}
+
+		// A row may only be updated once by a single statement
+		if ids[obj.Applicationstate_application_id] {
+			return fmt.Errorf("duplicate ApplicationState in upsert: '%s'", obj.Applicationstate_application_id)
+		}
+		ids[obj.Applicationstate_application_id] = true
+
+		if err := validateApplicationStateHealthAndSyncStatus(obj); err != nil {
+			return err
+		}
+
+		if err := validateFieldLength(obj); err != nil {
+			return err
+		}
+
+		noOfBytesInObj := binary.Size(obj.Resources)
+		maxSize := DbFieldMap["ApplicationStateResourcesLength"]
+		if noOfBytesInObj > maxSize {
+			return fmt.Errorf("resources value of '%s' exceeds maximum size: max: %d, actual: %d",
+				obj.Applicationstate_application_id, maxSize, noOfBytesInObj)
+		}
+	}
+
+	query := dbq.dbConnection.Model(&applicationStates).Context(ctx).
+		OnConflict("(applicationstate_application_id) DO UPDATE")
+
+	for _, column := range []string{"health", "sync_status", "message", "revision", "reconciled_state", "sync_error", "last_sync",
+		"resolved_revision", "revision_error", "diff_preview", "resource_estimate", "sync_hooks",
+		"operation_state"} {
+		query = query.Set(column + " = EXCLUDED." + column)
+	}
+
+	// Keep the existing (potentially large) resources value if it is unchanged, to avoid rewriting it
+	query = query.Set("resources = CASE WHEN applicationstate.resources IS DISTINCT FROM EXCLUDED.resources " +
+		"THEN EXCLUDED.resources ELSE applicationstate.resources END")
+
+	result, err := query.Insert()
+	if err != nil {
+		return fmt.Errorf("error on upserting application states: %v", err)
+	}
+
+	if result.RowsAffected() != len(applicationStates) {
+		return fmt.Errorf("%s: %d", ErrorUnexpectedNumberOfRowsAffected, result.RowsAffected())
+	}
+
+	return nil
+}
+
+// GetApplicationStateResourcesSize returns the total size, in bytes, of the resources column of the ApplicationStates
+// of the given Applications, as stored by the database (after compression).
+func (dbq *PostgreSQLDatabaseQueries) GetApplicationStateResourcesSize(ctx context.Context, applicationIDs []string) (int, error) {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return 0, err
+	}
+
+	if len(applicationIDs) == 0 {
+		return 0, nil
+	}
+
+	var size int
+	if err := dbq.dbConnection.Model(&ApplicationState{}).
+		ColumnExpr("COALESCE(SUM(pg_column_size(resources)), 0)").
+		Where("applicationstate_application_id IN (?)", pg.In(applicationIDs)).
+		Context(ctx).
+		Select(&size); err != nil {
+
+		return 0, fmt.Errorf("error on retrieving size of ApplicationState resources: %v", err)
+	}
+
+	return size, nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) GetApplicationStateById(ctx context.Context, obj *ApplicationState) error {
 
 	if err := validateQueryParamsEntity(obj, dbq); err != nil {
@@ -164,3 +314,56 @@ func (app *ApplicationState) DisposeAppScoped(ctx context.Context, dbq Applicati
 
 	return err
 }
+
+// IsValid returns true if the health status is one of the known Argo CD health status codes, false otherwise.
+func (health ApplicationStateHealth) IsValid() bool {
+	switch health {
+	case ApplicationStateHealth_Healthy, ApplicationStateHealth_Progressing, ApplicationStateHealth_Degraded,
+		ApplicationStateHealth_Suspended, ApplicationStateHealth_Missing, ApplicationStateHealth_Unknown:
+		return true
+	}
+	return false
+}
+
+// ParseApplicationStateHealth returns the ApplicationStateHealth of the given value, or an error if the value is not
+// a known Argo CD health status code.
+func ParseApplicationStateHealth(value string) (ApplicationStateHealth, error) {
+	health := ApplicationStateHealth(value)
+	if !health.IsValid() {
+		return ApplicationStateHealth_Unknown, fmt.Errorf("invalid ApplicationState health value: '%s'", value)
+	}
+	return health, nil
+}
+
+// IsValid returns true if the sync status is one of the known Argo CD sync status codes, false otherwise.
+func (syncStatus ApplicationStateSyncStatus) IsValid() bool {
+	switch syncStatus {
+	case ApplicationStateSyncStatus_Synced, ApplicationStateSyncStatus_OutOfSync, ApplicationStateSyncStatus_Unknown:
+		return true
+	}
+	return false
+}
+
+// ParseApplicationStateSyncStatus returns the ApplicationStateSyncStatus of the given value, or an error if the value is not
+// a known Argo CD sync status code.
+func ParseApplicationStateSyncStatus(value string) (ApplicationStateSyncStatus, error) {
+	syncStatus := ApplicationStateSyncStatus(value)
+	if !syncStatus.IsValid() {
+		return ApplicationStateSyncStatus_Unknown, fmt.Errorf("invalid ApplicationState sync status value: '%s'", value)
+	}
+	return syncStatus, nil
+}
+
+// validateApplicationStateHealthAndSyncStatus returns an error if the health or sync status of the ApplicationState are not valid.
+func validateApplicationStateHealthAndSyncStatus(obj *ApplicationState) error {
+
+	if _, err := ParseApplicationStateHealth(obj.Health); err != nil {
+		return err
+	}
+
+	if _, err := ParseApplicationStateSyncStatus(obj.Sync_Status); err != nil {
+		return err
+	}
+
+	return nil
+}
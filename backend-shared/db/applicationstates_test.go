@@ -61,6 +61,25 @@ var _ = Describe("ApplicationStates Tests", func() {
 			Expect(err).To(BeNil())
 			Expect(fetchObj).Should(Equal(applicationState))
 
+			By("updating only the health of the ApplicationState, and verifying the other fields are unchanged")
+			healthOnlyUpdate := &db.ApplicationState{
+				Applicationstate_application_id: application.Application_id,
+				Health:                          string(db.ApplicationStateHealth_Degraded),
+				Message:                         "test-message",
+			}
+			err = dbq.UpdateApplicationStateHealthOnly(ctx, healthOnlyUpdate)
+			Expect(err).To(BeNil())
+
+			err = dbq.GetApplicationStateById(ctx, fetchObj)
+			Expect(err).To(BeNil())
+			applicationState.Health = healthOnlyUpdate.Health
+			applicationState.Message = healthOnlyUpdate.Message
+			Expect(fetchObj).Should(Equal(applicationState))
+
+			healthOnlyUpdate.Health = "Unhealthy"
+			err = dbq.UpdateApplicationStateHealthOnly(ctx, healthOnlyUpdate)
+			Expect(err).NotTo(BeNil())
+
 			rowsAffected, err := dbq.DeleteApplicationStateById(ctx, fetchObj.Applicationstate_application_id)
 			Expect(err).To(BeNil())
 			Expect(rowsAffected).To(Equal(1))
@@ -73,5 +92,178 @@ var _ = Describe("ApplicationStates Tests", func() {
 			err = dbq.CreateApplicationState(ctx, applicationState)
 			Expect(err).NotTo(BeNil())
 		})
+
+		It("Should reject ApplicationStates with invalid health or sync status values", func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx := context.Background()
+
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+			_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			application := &db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "my-application",
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+
+			err = dbq.CreateApplication(ctx, application)
+			Expect(err).To(BeNil())
+
+			applicationState := &db.ApplicationState{
+				Applicationstate_application_id: application.Application_id,
+				Health:                          "Unhealthy",
+				Sync_Status:                     string(db.ApplicationStateSyncStatus_Synced),
+				Resources:                       make([]byte, 10),
+				ReconciledState:                 "test-reconciledState",
+			}
+
+			By("verifying an invalid health value is rejected on create")
+			err = dbq.CreateApplicationState(ctx, applicationState)
+			Expect(err).NotTo(BeNil())
+
+			applicationState.Health = string(db.ApplicationStateHealth_Healthy)
+			applicationState.Sync_Status = "Sync"
+
+			By("verifying an invalid sync status value is rejected on create")
+			err = dbq.CreateApplicationState(ctx, applicationState)
+			Expect(err).NotTo(BeNil())
+
+			applicationState.Sync_Status = string(db.ApplicationStateSyncStatus_Synced)
+			err = dbq.CreateApplicationState(ctx, applicationState)
+			Expect(err).To(BeNil())
+
+			By("verifying an invalid health value is rejected on update")
+			applicationState.Health = "Unhealthy"
+			err = dbq.UpdateApplicationState(ctx, applicationState)
+			Expect(err).NotTo(BeNil())
+
+			fetchObj := &db.ApplicationState{
+				Applicationstate_application_id: application.Application_id,
+			}
+			err = dbq.GetApplicationStateById(ctx, fetchObj)
+			Expect(err).To(BeNil())
+			Expect(fetchObj.Health).To(Equal(string(db.ApplicationStateHealth_Healthy)))
+		})
+
+		It("Should create and update ApplicationStates in a single upsert", func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx := context.Background()
+
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+			_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			applicationStates := []db.ApplicationState{}
+			for _, id := range []string{"test-my-application-1", "test-my-application-2"} {
+				application := &db.Application{
+					Application_id:          id,
+					Name:                    id,
+					Spec_field:              "{}",
+					Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+					Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+				}
+				err = dbq.CreateApplication(ctx, application)
+				Expect(err).To(BeNil())
+
+				applicationStates = append(applicationStates, db.ApplicationState{
+					Applicationstate_application_id: id,
+					Health:                          string(db.ApplicationStateHealth_Progressing),
+					Sync_Status:                     string(db.ApplicationStateSyncStatus_OutOfSync),
+					Resources:                       []byte(`[]`),
+					ReconciledState:                 "test-reconciledState",
+				})
+			}
+
+			By("creating the first ApplicationState, so that the upsert both updates and creates a row")
+			err = dbq.CreateApplicationState(ctx, &applicationStates[0])
+			Expect(err).To(BeNil())
+
+			applicationStates[0].Health = string(db.ApplicationStateHealth_Healthy)
+			applicationStates[0].Sync_Status = string(db.ApplicationStateSyncStatus_Synced)
+			applicationStates[0].Resources = []byte(`[{"kind": "Deployment"}]`)
+
+			err = dbq.UpsertApplicationStates(ctx, applicationStates)
+			Expect(err).To(BeNil())
+
+			for _, applicationState := range applicationStates {
+				fetchObj := &db.ApplicationState{
+					Applicationstate_application_id: applicationState.Applicationstate_application_id,
+				}
+				err = dbq.GetApplicationStateById(ctx, fetchObj)
+				Expect(err).To(BeNil())
+				Expect(*fetchObj).Should(Equal(applicationState))
+			}
+
+			By("verifying duplicate ApplicationStates are rejected")
+			err = dbq.UpsertApplicationStates(ctx, []db.ApplicationState{applicationStates[0], applicationStates[0]})
+			Expect(err).NotTo(BeNil())
+
+			By("verifying an invalid health value is rejected")
+			applicationStates[1].Health = "Unhealthy"
+			err = dbq.UpsertApplicationStates(ctx, applicationStates)
+			Expect(err).NotTo(BeNil())
+
+			By("verifying an empty list is a no-op")
+			err = dbq.UpsertApplicationStates(ctx, []db.ApplicationState{})
+			Expect(err).To(BeNil())
+
+			By("verifying the size of the resources of the ApplicationStates is returned")
+			size, err := dbq.GetApplicationStateResourcesSize(ctx, []string{applicationStates[0].Applicationstate_application_id})
+			Expect(err).To(BeNil())
+			Expect(size).To(BeNumerically(">", 0))
+
+			totalSize, err := dbq.GetApplicationStateResourcesSize(ctx, []string{applicationStates[0].Applicationstate_application_id,
+				applicationStates[1].Applicationstate_application_id})
+			Expect(err).To(BeNil())
+			Expect(totalSize).To(BeNumerically(">", size))
+
+			size, err = dbq.GetApplicationStateResourcesSize(ctx, []string{"test-application-does-not-exist"})
+			Expect(err).To(BeNil())
+			Expect(size).To(Equal(0))
+		})
+	})
+
+	Context("Test parsing of ApplicationState health and sync status values", func() {
+
+		DescribeTable("ParseApplicationStateHealth",
+			func(value string, expectedHealth db.ApplicationStateHealth, expectError bool) {
+				health, err := db.ParseApplicationStateHealth(value)
+				Expect(health).To(Equal(expectedHealth))
+				Expect(err != nil).To(Equal(expectError))
+			},
+			Entry("Healthy", "Healthy", db.ApplicationStateHealth_Healthy, false),
+			Entry("Progressing", "Progressing", db.ApplicationStateHealth_Progressing, false),
+			Entry("Degraded", "Degraded", db.ApplicationStateHealth_Degraded, false),
+			Entry("Suspended", "Suspended", db.ApplicationStateHealth_Suspended, false),
+			Entry("Missing", "Missing", db.ApplicationStateHealth_Missing, false),
+			Entry("Unknown", "Unknown", db.ApplicationStateHealth_Unknown, false),
+			Entry("empty", "", db.ApplicationStateHealth_Unknown, true),
+			Entry("invalid", "Unhealthy", db.ApplicationStateHealth_Unknown, true),
+			Entry("wrong case", "healthy", db.ApplicationStateHealth_Unknown, true),
+		)
+
+		DescribeTable("ParseApplicationStateSyncStatus",
+			func(value string, expectedSyncStatus db.ApplicationStateSyncStatus, expectError bool) {
+				syncStatus, err := db.ParseApplicationStateSyncStatus(value)
+				Expect(syncStatus).To(Equal(expectedSyncStatus))
+				Expect(err != nil).To(Equal(expectError))
+			},
+			Entry("Synced", "Synced", db.ApplicationStateSyncStatus_Synced, false),
+			Entry("OutOfSync", "OutOfSync", db.ApplicationStateSyncStatus_OutOfSync, false),
+			Entry("Unknown", "Unknown", db.ApplicationStateSyncStatus_Unknown, false),
+			Entry("empty", "", db.ApplicationStateSyncStatus_Unknown, true),
+			Entry("invalid", "Sync", db.ApplicationStateSyncStatus_Unknown, true),
+		)
 	})
 })
@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"sort"
+
+	"github.com/go-pg/pg/v10"
 )
 
 func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllClusterAccess(ctx context.Context, clusterAccess *[]ClusterAccess) error {
@@ -143,6 +146,55 @@ func (dbq *PostgreSQLDatabaseQueries) ListClusterAccessesByManagedEnvironmentID(
 	return nil
 }
 
+// DeleteClusterAccessesByManagedEnvironmentID deletes all the ClusterAccess rows that reference the ManagedEnvironment, in
+// a single transaction, and returns the IDs of the ClusterUsers whose access was removed (sorted, without duplicates).
+//
+// The ManagedEnvironment row is locked for the duration of the transaction: a ClusterAccess row that references the
+// ManagedEnvironment can thus not be created while the existing rows are deleted (the foreign key check of the insert
+// waits for the lock), ensuring that no ClusterAccess row remains that would prevent the ManagedEnvironment row from
+// being deleted afterwards.
+func (dbq *PostgreSQLDatabaseQueries) DeleteClusterAccessesByManagedEnvironmentID(ctx context.Context, managedEnvironmentID string) ([]string, error) {
+
+	if err := validateQueryParams(managedEnvironmentID, dbq); err != nil {
+		return nil, err
+	}
+
+	var deletedClusterAccesses []ClusterAccess
+
+	if err := dbq.dbConnection.RunInTransaction(ctx, func(tx *pg.Tx) error {
+
+		managedEnvironment := ManagedEnvironment{Managedenvironment_id: managedEnvironmentID}
+		if err := tx.Model(&managedEnvironment).WherePK().For("UPDATE").Context(ctx).Select(); err != nil {
+			return fmt.Errorf("error on locking ManagedEnvironment '%s': %v", managedEnvironmentID, err)
+		}
+
+		if _, err := tx.Model(&deletedClusterAccesses).
+			Where("clusteraccess_managed_environment_id = ?", managedEnvironmentID).
+			Returning("*").
+			Context(ctx).
+			Delete(); err != nil {
+			return fmt.Errorf("error on deleting ClusterAccesses of ManagedEnvironment '%s': %v", managedEnvironmentID, err)
+		}
+
+		return nil
+
+	}); err != nil {
+		return nil, err
+	}
+
+	userIDs := []string{}
+	userIDSet := map[string]bool{}
+	for _, clusterAccess := range deletedClusterAccesses {
+		if !userIDSet[clusterAccess.Clusteraccess_user_id] {
+			userIDSet[clusterAccess.Clusteraccess_user_id] = true
+			userIDs = append(userIDs, clusterAccess.Clusteraccess_user_id)
+		}
+	}
+	sort.Strings(userIDs)
+
+	return userIDs, nil
+}
+
 // Get ClusterAccess in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 // For example if you want ClusterAccess starting from 51-150 then set the limit to 100 and offset to 50.
 func (dbq *PostgreSQLDatabaseQueries) GetClusterAccessBatch(ctx context.Context, clusterAccess *[]ClusterAccess, limit, offSet int) error {
@@ -95,5 +95,56 @@ var _ = Describe("ClusterAccess Tests", func() {
 			err = dbq.CreateClusterAccess(ctx, &clusterAccess)
 			Expect(db.IsMaxLengthError(err)).To(BeTrue())
 		})
+
+		It("Should delete all the ClusterAccesses of a ManagedEnvironment, and return the affected users", func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+
+			ctx := context.Background()
+
+			_, managedEnvironment, _, gitopsEngineInstance, clusterAccess, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			By("creating a second user with access to the ManagedEnvironment")
+			secondUser := db.ClusterUser{
+				Clusteruser_id: "test-second-user",
+				User_name:      "test-second-user",
+			}
+			err = dbq.CreateClusterUser(ctx, &secondUser)
+			Expect(err).To(BeNil())
+
+			err = dbq.CreateClusterAccess(ctx, &db.ClusterAccess{
+				Clusteraccess_user_id:                   secondUser.Clusteruser_id,
+				Clusteraccess_managed_environment_id:    managedEnvironment.Managedenvironment_id,
+				Clusteraccess_gitops_engine_instance_id: gitopsEngineInstance.Gitopsengineinstance_id,
+			})
+			Expect(err).To(BeNil())
+
+			var clusterAccesses []db.ClusterAccess
+			err = dbq.ListClusterAccessesByManagedEnvironmentID(ctx, managedEnvironment.Managedenvironment_id, &clusterAccesses)
+			Expect(err).To(BeNil())
+			Expect(clusterAccesses).To(HaveLen(2))
+
+			affectedUserIDs, err := dbq.DeleteClusterAccessesByManagedEnvironmentID(ctx, managedEnvironment.Managedenvironment_id)
+			Expect(err).To(BeNil())
+			Expect(affectedUserIDs).To(ConsistOf(clusterAccess.Clusteraccess_user_id, secondUser.Clusteruser_id))
+
+			err = dbq.ListClusterAccessesByManagedEnvironmentID(ctx, managedEnvironment.Managedenvironment_id, &clusterAccesses)
+			Expect(err).To(BeNil())
+			Expect(clusterAccesses).To(BeEmpty())
+
+			By("verifying the ManagedEnvironment can then be deleted")
+			rowsAffected, err := dbq.DeleteManagedEnvironmentById(ctx, managedEnvironment.Managedenvironment_id)
+			Expect(err).To(BeNil())
+			Expect(rowsAffected).To(Equal(1))
+
+			By("verifying an error is returned if the ManagedEnvironment does not exist")
+			_, err = dbq.DeleteClusterAccessesByManagedEnvironmentID(ctx, managedEnvironment.Managedenvironment_id)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+		})
 	})
 })
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllClusterCredentials(ctx context.Context, clusterCredentials *[]ClusterCredentials) error {
@@ -190,6 +191,32 @@ func (dbq *PostgreSQLDatabaseQueries) GetClusterCredentialsBatch(ctx context.Con
 		Select()
 }
 
+// ListUnreferencedClusterCredentials returns (up to 'limit') ClusterCredentials rows that were created before 'createdBefore',
+// and that are not referenced by any ManagedEnvironment or GitopsEngineCluster row, ordered by seq_id. Only rows with a
+// seq_id greater than 'afterSeqID' are returned, so that the caller may page through the results.
+func (dbq *PostgreSQLDatabaseQueries) ListUnreferencedClusterCredentials(ctx context.Context, createdBefore time.Time, afterSeqID int64,
+	limit int, clusterCredentials *[]ClusterCredentials) error {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(clusterCredentials).
+		Where("cc.created_on < ?", createdBefore).
+		Where("cc.seq_id > ?", afterSeqID).
+		Where("NOT EXISTS (SELECT 1 FROM managedenvironment me WHERE me.clustercredentials_id = cc.clustercredentials_cred_id)").
+		Where("NOT EXISTS (SELECT 1 FROM gitopsenginecluster gec WHERE gec.clustercredentials_id = cc.clustercredentials_cred_id)").
+		Order("seq_id ASC").
+		Limit(limit).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving unreferenced ClusterCredentials: %v", err)
+	}
+
+	return nil
+}
+
 // A user should only be able to get cluster credentials if:
 // - they have access to a gitops engine instance on that cluster.
 // - they have access to a managed environment using those credentials
@@ -299,5 +326,7 @@ func (obj *ClusterCredentials) GetAsLogKeyValues() []interface{} {
 	return []interface{}{"host", obj.Host, "kube-config-length", len(obj.Kube_config),
 		"kube-config-context", len(obj.Kube_config_context), "serviceaccount_ns", obj.Serviceaccount_ns,
 		"serviceaccount-bearer-token-length", len(obj.Serviceaccount_bearer_token), "cluster_resources", obj.ClusterResources,
-		"cluster_namespaces", obj.Namespaces}
+		"cluster_namespaces", obj.Namespaces, "rotation_requested_at", obj.Rotation_requested_at, "namespace_quota_cpu", obj.Namespace_quota_cpu,
+		"namespace_quota_memory", obj.Namespace_quota_memory, "argocd_cluster_secret", obj.Argocd_cluster_secret,
+		"proxy_url", obj.Proxy_url, "secret_data_hash", obj.Secret_data_hash}
 }
@@ -42,5 +42,69 @@ var _ = Describe("ClusterCredentials Tests", func() {
 			err = dbq.GetClusterCredentialsById(ctx, &fetchedCluster)
 			Expect(true).To(Equal(db.IsResultNotFoundError(err)))
 		})
+
+		It("Should list only the ClusterCredentials that are not referenced, and were created before the given time", func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx := context.Background()
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+
+			createdOn := time.Now().Add(-2 * time.Hour)
+
+			newClusterCredentials := func(id string) db.ClusterCredentials {
+				clusterCreds := db.ClusterCredentials{
+					Clustercredentials_cred_id:  id,
+					Host:                        "test-host",
+					Kube_config:                 "test-kube_config",
+					Kube_config_context:         "test-kube_config_context",
+					Serviceaccount_bearer_token: "test-serviceaccount_bearer_token",
+					Serviceaccount_ns:           "test-serviceaccount_ns",
+					Created_on:                  createdOn,
+				}
+				Expect(dbq.CreateClusterCredentials(ctx, &clusterCreds)).To(Succeed())
+				return clusterCreds
+			}
+
+			unreferenced := newClusterCredentials("test-unreferenced-creds")
+			referencedByManagedEnv := newClusterCredentials("test-managed-env-creds")
+			referencedByEngineCluster := newClusterCredentials("test-engine-cluster-creds")
+
+			Expect(dbq.CreateManagedEnvironment(ctx, &db.ManagedEnvironment{
+				Managedenvironment_id: "test-managed-env",
+				Clustercredentials_id: referencedByManagedEnv.Clustercredentials_cred_id,
+				Name:                  "test-managed-env",
+			})).To(Succeed())
+
+			Expect(dbq.CreateGitopsEngineCluster(ctx, &db.GitopsEngineCluster{
+				Gitopsenginecluster_id: "test-engine-cluster",
+				Clustercredentials_id:  referencedByEngineCluster.Clustercredentials_cred_id,
+			})).To(Succeed())
+
+			listUnreferencedIDs := func(createdBefore time.Time, afterSeqID int64) []string {
+				var clusterCredentials []db.ClusterCredentials
+				Expect(dbq.ListUnreferencedClusterCredentials(ctx, createdBefore, afterSeqID, 100, &clusterCredentials)).To(Succeed())
+
+				res := []string{}
+				for _, clusterCreds := range clusterCredentials {
+					res = append(res, clusterCreds.Clustercredentials_cred_id)
+				}
+				return res
+			}
+
+			ids := listUnreferencedIDs(time.Now().Add(-time.Hour), 0)
+			Expect(ids).To(ContainElement(unreferenced.Clustercredentials_cred_id))
+			Expect(ids).ToNot(ContainElement(referencedByManagedEnv.Clustercredentials_cred_id))
+			Expect(ids).ToNot(ContainElement(referencedByEngineCluster.Clustercredentials_cred_id))
+
+			By("not listing ClusterCredentials created after the given time")
+			Expect(listUnreferencedIDs(createdOn.Add(-time.Minute), 0)).ToNot(ContainElement(unreferenced.Clustercredentials_cred_id))
+
+			By("not listing ClusterCredentials with a seq_id that is not after the given seq_id")
+			Expect(dbq.GetClusterCredentialsById(ctx, &unreferenced)).To(Succeed())
+			Expect(listUnreferencedIDs(time.Now().Add(-time.Hour), unreferenced.SeqID)).ToNot(ContainElement(unreferenced.Clustercredentials_cred_id))
+		})
 	})
 })
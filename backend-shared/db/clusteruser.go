@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
 )
 
 // Set the Special Cluster User details.
@@ -197,6 +200,142 @@ func (dbq *PostgreSQLDatabaseQueries) GetClusterUserBatch(ctx context.Context, c
 		Select()
 }
 
+// DeactivateClusterUser marks the ClusterUser as deactivated (if it is not already), and deletes the ClusterAccess rows of
+// the user, in a single transaction: the user no longer has access to any ManagedEnvironment. Returns the number of
+// ClusterAccess rows that were deleted.
+//
+// The RepositoryCredentials and Applications of the user are not deleted: the caller should delete them (informing the
+// cluster-agent via Operations), before purging the user with PurgeClusterUser.
+func (dbq *PostgreSQLDatabaseQueries) DeactivateClusterUser(ctx context.Context, clusterUserID string) (int, error) {
+
+	if err := validateQueryParams(clusterUserID, dbq); err != nil {
+		return 0, err
+	}
+
+	if clusterUserID == SpecialClusterUserName {
+		return 0, fmt.Errorf("the special cluster user may not be deactivated")
+	}
+
+	var clusterAccessesDeleted int
+
+	if err := dbq.dbConnection.RunInTransaction(ctx, func(tx *pg.Tx) error {
+
+		updateResult, err := tx.Model(&ClusterUser{}).
+			Set("deactivated_on = ?", time.Now()).
+			Where("clusteruser_id = ?", clusterUserID).
+			Where("deactivated_on IS NULL").
+			Context(ctx).
+			Update()
+		if err != nil {
+			return fmt.Errorf("error on deactivating ClusterUser '%s': %v", clusterUserID, err)
+		}
+
+		if updateResult.RowsAffected() == 0 {
+			// Either the user doesn't exist, or it was already deactivated: only the former is an error
+			count, err := tx.Model(&ClusterUser{}).Where("clusteruser_id = ?", clusterUserID).Context(ctx).Count()
+			if err != nil {
+				return fmt.Errorf("error on retrieving ClusterUser '%s': %v", clusterUserID, err)
+			}
+			if count == 0 {
+				return NewResultNotFoundError(fmt.Sprintf("no results found for DeactivateClusterUser: '%s'", clusterUserID))
+			}
+		}
+
+		deleteResult, err := tx.Model(&ClusterAccess{}).
+			Where("clusteraccess_user_id = ?", clusterUserID).
+			Context(ctx).
+			Delete()
+		if err != nil {
+			return fmt.Errorf("error on deleting ClusterAccesses of ClusterUser '%s': %v", clusterUserID, err)
+		}
+
+		clusterAccessesDeleted = deleteResult.RowsAffected()
+
+		return nil
+
+	}); err != nil {
+		return 0, err
+	}
+
+	return clusterAccessesDeleted, nil
+}
+
+// PurgeClusterUser deletes a deactivated ClusterUser, along with the database rows that still reference it: its
+// ClusterAccess and Operation rows (KeyStore and ApplicationOwner rows are deleted by cascade). The RepositoryCredentials
+// of the user must have been deleted beforehand, as the cluster-agent must be informed of their deletion.
+//
+// Returns the number of ClusterUser rows deleted: 0 if the user doesn't exist (for example, it was already purged).
+func (dbq *PostgreSQLDatabaseQueries) PurgeClusterUser(ctx context.Context, clusterUserID string) (int, error) {
+
+	if err := validateQueryParams(clusterUserID, dbq); err != nil {
+		return 0, err
+	}
+
+	var clusterUsersDeleted int
+
+	if err := dbq.dbConnection.RunInTransaction(ctx, func(tx *pg.Tx) error {
+
+		var dbResults []ClusterUser
+		if err := tx.Model(&dbResults).
+			Where("cu.clusteruser_id = ?", clusterUserID).
+			For("UPDATE").
+			Context(ctx).
+			Select(); err != nil {
+			return fmt.Errorf("error on locking ClusterUser '%s': %v", clusterUserID, err)
+		}
+
+		if len(dbResults) == 0 {
+			// The user doesn't exist, or was already purged
+			return nil
+		}
+
+		clusterUser := dbResults[0]
+		if clusterUser.Deactivated_on == nil {
+			return fmt.Errorf("ClusterUser '%s' must be deactivated before it is purged", clusterUserID)
+		}
+
+		repositoryCredentialsCount, err := tx.Model(&RepositoryCredentials{}).
+			Where("repo_cred_user_id = ?", clusterUserID).
+			Context(ctx).
+			Count()
+		if err != nil {
+			return fmt.Errorf("error on counting RepositoryCredentials of ClusterUser '%s': %v", clusterUserID, err)
+		}
+		if repositoryCredentialsCount > 0 {
+			return fmt.Errorf("ClusterUser '%s' still has %d RepositoryCredentials, which must be deleted before it is purged",
+				clusterUserID, repositoryCredentialsCount)
+		}
+
+		if _, err := tx.Model(&ClusterAccess{}).
+			Where("clusteraccess_user_id = ?", clusterUserID).
+			Context(ctx).
+			Delete(); err != nil {
+			return fmt.Errorf("error on deleting ClusterAccesses of ClusterUser '%s': %v", clusterUserID, err)
+		}
+
+		if _, err := tx.Model(&Operation{}).
+			Where("operation_owner_user_id = ?", clusterUserID).
+			Context(ctx).
+			Delete(); err != nil {
+			return fmt.Errorf("error on deleting Operations of ClusterUser '%s': %v", clusterUserID, err)
+		}
+
+		deleteResult, err := tx.Model(&clusterUser).WherePK().Context(ctx).Delete()
+		if err != nil {
+			return fmt.Errorf("error on deleting ClusterUser '%s': %v", clusterUserID, err)
+		}
+
+		clusterUsersDeleted = deleteResult.RowsAffected()
+
+		return nil
+
+	}); err != nil {
+		return 0, err
+	}
+
+	return clusterUsersDeleted, nil
+}
+
 var _ DisposableResource = &ClusterUser{}
 
 func (obj *ClusterUser) Dispose(ctx context.Context, dbq DatabaseQueries) error {
@@ -215,5 +354,5 @@ func (obj *ClusterUser) GetAsLogKeyValues() []interface{} {
 		return []interface{}{}
 	}
 
-	return []interface{}{"clusteruser_id", obj.Clusteruser_id, "user_name", obj.User_name}
+	return []interface{}{"clusteruser_id", obj.Clusteruser_id, "user_name", obj.User_name, "deactivated", obj.Deactivated_on != nil}
 }
@@ -64,4 +64,102 @@ var _ = Describe("ClusterUser Tests", func() {
 			Expect(rowsAffected).Should(Equal(1))
 		})
 	})
+
+	Context("It should deactivate and purge a ClusterUser", func() {
+		It("Should revoke the ClusterAccess of a deactivated user, and only purge it once its RepositoryCredentials are deleted", func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx := context.Background()
+
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+
+			_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			user := &db.ClusterUser{
+				Clusteruser_id: "test-offboarded-user-id",
+				User_name:      "test-offboarded-user-name",
+			}
+			Expect(dbq.CreateClusterUser(ctx, user)).To(Succeed())
+
+			clusterAccess := db.ClusterAccess{
+				Clusteraccess_user_id:                   user.Clusteruser_id,
+				Clusteraccess_managed_environment_id:    managedEnvironment.Managedenvironment_id,
+				Clusteraccess_gitops_engine_instance_id: gitopsEngineInstance.Gitopsengineinstance_id,
+			}
+			Expect(dbq.CreateClusterAccess(ctx, &clusterAccess)).To(Succeed())
+
+			repositoryCredentials := db.RepositoryCredentials{
+				RepositoryCredentialsID: "test-offboarded-repo-cred-id",
+				UserID:                  user.Clusteruser_id,
+				PrivateURL:              "https://test-private-url",
+				AuthUsername:            "test-auth-username",
+				AuthPassword:            "test-auth-password",
+				SecretObj:               "test-secret-obj",
+				EngineClusterID:         gitopsEngineInstance.Gitopsengineinstance_id,
+			}
+			Expect(dbq.CreateRepositoryCredentials(ctx, &repositoryCredentials)).To(Succeed())
+
+			operation := db.Operation{
+				Operation_id:            "test-offboarded-operation-id",
+				Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+				Resource_id:             repositoryCredentials.RepositoryCredentialsID,
+				Resource_type:           db.OperationResourceType_RepositoryCredentials,
+				State:                   db.OperationState_Completed,
+				Operation_owner_user_id: user.Clusteruser_id,
+			}
+			Expect(dbq.CreateOperation(ctx, &operation, operation.Operation_owner_user_id)).To(Succeed())
+
+			By("purging the user before it is deactivated, which should fail")
+			_, err = dbq.PurgeClusterUser(ctx, user.Clusteruser_id)
+			Expect(err).NotTo(BeNil())
+
+			By("deactivating the user, which should delete its ClusterAccess")
+			clusterAccessesDeleted, err := dbq.DeactivateClusterUser(ctx, user.Clusteruser_id)
+			Expect(err).To(BeNil())
+			Expect(clusterAccessesDeleted).To(Equal(1))
+
+			Expect(dbq.GetClusterUserById(ctx, user)).To(Succeed())
+			Expect(user.Deactivated_on).NotTo(BeNil())
+			Expect(db.IsResultNotFoundError(dbq.GetClusterAccessByPrimaryKey(ctx, &clusterAccess))).To(BeTrue())
+
+			By("deactivating the user again, which should not change when it was deactivated")
+			deactivatedOn := *user.Deactivated_on
+			clusterAccessesDeleted, err = dbq.DeactivateClusterUser(ctx, user.Clusteruser_id)
+			Expect(err).To(BeNil())
+			Expect(clusterAccessesDeleted).To(Equal(0))
+			Expect(dbq.GetClusterUserById(ctx, user)).To(Succeed())
+			Expect(user.Deactivated_on.Equal(deactivatedOn)).To(BeTrue())
+
+			_, err = dbq.DeactivateClusterUser(ctx, "test-user-that-does-not-exist")
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			By("purging the user while it still has RepositoryCredentials, which should fail")
+			var userRepositoryCredentials []db.RepositoryCredentials
+			Expect(dbq.ListRepositoryCredentialsByClusterUserID(ctx, user.Clusteruser_id, &userRepositoryCredentials)).To(Succeed())
+			Expect(userRepositoryCredentials).To(HaveLen(1))
+			Expect(userRepositoryCredentials[0].AuthPassword).To(Equal(repositoryCredentials.AuthPassword))
+
+			_, err = dbq.PurgeClusterUser(ctx, user.Clusteruser_id)
+			Expect(err).NotTo(BeNil())
+
+			By("purging the user once its RepositoryCredentials are deleted, which should also delete its Operations")
+			_, err = dbq.DeleteRepositoryCredentialsByID(ctx, repositoryCredentials.RepositoryCredentialsID)
+			Expect(err).To(BeNil())
+
+			rowsAffected, err := dbq.PurgeClusterUser(ctx, user.Clusteruser_id)
+			Expect(err).To(BeNil())
+			Expect(rowsAffected).To(Equal(1))
+
+			Expect(db.IsResultNotFoundError(dbq.GetClusterUserById(ctx, user))).To(BeTrue())
+			Expect(db.IsResultNotFoundError(dbq.GetOperationById(ctx, &operation))).To(BeTrue())
+
+			rowsAffected, err = dbq.PurgeClusterUser(ctx, user.Clusteruser_id)
+			Expect(err).To(BeNil())
+			Expect(rowsAffected).To(Equal(0))
+		})
+	})
 })
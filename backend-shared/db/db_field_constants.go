@@ -14,15 +14,24 @@ const (
 	ClusterCredentialsServiceaccountBearerTokenLength                       = 2048
 	ClusterCredentialsServiceaccountNsLength                                = 128
 	ClusterCredentialsNamespacesLength                                      = 4096
+	ClusterCredentialsRotationRequestedAtLength                             = 64
+	ClusterCredentialsNamespaceQuotaCpuLength                               = 64
+	ClusterCredentialsNamespaceQuotaMemoryLength                            = 64
+	ClusterCredentialsArgocdClusterSecretLength                             = 253
+	ClusterCredentialsProxyUrlLength                                        = 512
+	ClusterCredentialsSecretDataHashLength                                  = 64
 	GitopsEngineClusterGitopsengineclusterIDLength                          = 48
 	GitopsEngineInstanceGitopsengineinstanceIDLength                        = 48
 	GitopsEngineInstanceNamespaceNameLength                                 = 48
 	GitopsEngineInstanceNamespaceUIDLength                                  = 48
 	GitopsEngineClusterClustercredentialsIDLength                           = 48
 	GitopsEngineInstanceEngineclusterIDLength                               = 48
+	GitopsEngineInstanceArgocdVersionLength                                 = 64
 	ManagedEnvironmentManagedenvironmentIDLength                            = 48
 	ManagedEnvironmentNameLength                                            = 256
 	ManagedEnvironmentClustercredentialsIDLength                            = 48
+	ManagedEnvironmentKubernetesVersionLength                               = 64
+	ManagedEnvironmentApiGroupsLength                                       = 8192
 	ClusterUserClusteruserIDLength                                          = 48
 	ClusterUserUserNameLength                                               = 256
 	ClusterAccessClusteraccessUserIDLength                                  = 48
@@ -35,6 +44,8 @@ const (
 	OperationResourceTypeLength                                             = 32
 	OperationStateLength                                                    = 30
 	OperationHumanReadableStateLength                                       = 1024
+	OperationProcessingOwnerLength                                          = 128
+	OperationResumeTokenLength                                              = 160
 	ApplicationApplicationIDLength                                          = 48
 	ApplicationNameLength                                                   = 256
 	ApplicationSpecFieldLength                                              = 16384
@@ -47,11 +58,19 @@ const (
 	ApplicationStateSyncStatusLength                                        = 30
 	ApplicationStateReconciledStateLength                                   = 4096
 	ApplicationStateSyncErrorLength                                         = 4096
+	ApplicationStateLastSyncLength                                          = 2048
+	ApplicationStateResolvedRevisionLength                                  = 1024
+	ApplicationStateRevisionErrorLength                                     = 4096
+	ApplicationStateDiffPreviewLength                                       = 16384
+	ApplicationStateResourceEstimateLength                                  = 2048
+	ApplicationStateSyncHooksLength                                         = 16384
+	ApplicationStateOperationStateLength                                    = 2048
 	DeploymentToApplicationMappingDeploymenttoapplicationmappingUIDIDLength = 48
 	DeploymentToApplicationMappingNameLength                                = 256
 	DeploymentToApplicationMappingNamespaceLength                           = 96
 	DeploymentToApplicationMappingNamespaceUIDLength                        = 48
 	DeploymentToApplicationMappingApplicationIDLength                       = 48
+	DeploymentToApplicationMappingRestoredFromDeploymentUIDLength           = 48
 	KubernetesToDBResourceMappingKubernetesResourceTypeLength               = 64
 	KubernetesToDBResourceMappingKubernetesResourceUIDLength                = 64
 	KubernetesToDBResourceMappingDbRelationTypeLength                       = 64
@@ -68,14 +87,43 @@ const (
 	SyncOperationDeploymentNameLength                                       = 256
 	SyncOperationRevisionLength                                             = 256
 	SyncOperationDesiredStateLength                                         = 16
+	SyncOperationInitiatorLength                                            = 512
+	SyncOperationResourcesLength                                            = 4096
+	SyncOperationRetryBackoffDurationLength                                 = 32
+	SyncOperationRetryBackoffMaxDurationLength                              = 32
 	RepositoryCredentialsRepositorycredentialsIDLength                      = 48
 	RepositoryCredentialsRepoCredUserIDLength                               = 48
 	RepositoryCredentialsRepoCredURLLength                                  = 512
 	RepositoryCredentialsRepoCredUserLength                                 = 256
-	RepositoryCredentialsRepoCredPassLength                                 = 1024
-	RepositoryCredentialsRepoCredSshLength                                  = 1024
+	RepositoryCredentialsRepoCredPassLength                                 = 2048
+	RepositoryCredentialsRepoCredSshLength                                  = 2048
 	RepositoryCredentialsRepoCredSecretLength                               = 48
 	RepositoryCredentialsRepoCredEngineIDLength                             = 48
+	RepositoryCredentialsRepoCredProjectLength                              = 128
+	KeyStoreKeyStoreIDLength                                                = 48
+	KeyStoreClusterUserIDLength                                             = 48
+	KeyStoreWrappedKeyLength                                                = 256
+	KeyStoreMasterKeyIDLength                                               = 64
+	ApplicationOwnerApplicationIDLength                                     = 48
+	ApplicationOwnerClusterUserIDLength                                     = 48
+	FeatureFlagFeatureFlagIDLength                                          = 48
+	FeatureFlagNameLength                                                   = 64
+	FeatureFlagNamespaceLength                                              = 63
+	DeploymentHistoryDeploymentHistoryIDLength                              = 48
+	DeploymentHistoryApplicationIDLength                                    = 48
+	DeploymentHistoryRevisionLength                                         = 256
+	DeploymentHistoryImagesLength                                           = 4096
+	DeploymentHistoryInitiatorLength                                        = 512
+	NamespaceOffboardingNamespaceUIDLength                                  = 48
+	NamespaceOffboardingNamespaceNameLength                                 = 63
+	NamespaceOffboardingStepLength                                          = 32
+	MigrationOperationNamespaceUIDLength                                    = 48
+	MigrationOperationNamespaceNameLength                                   = 63
+	MigrationOperationSourceGitopsEngineInstanceIDLength                    = 48
+	MigrationOperationTargetGitopsEngineInstanceIDLength                    = 48
+	MigrationOperationStepLength                                            = 32
+	TaskCheckpointTaskNameLength                                            = 128
+	TaskCheckpointCheckpointLength                                          = 1024
 )
 
 // TruncateVarchar converts string to "str..." if chars is > maxLength
@@ -115,6 +163,12 @@ var DbFieldMap = map[string]int{
 	"ClusterCredentialsServiceaccountBearerTokenLength":                       ClusterCredentialsServiceaccountBearerTokenLength,
 	"ClusterCredentialsServiceaccountNsLength":                                ClusterCredentialsServiceaccountNsLength,
 	"ClusterCredentialsNamespacesLength":                                      ClusterCredentialsNamespacesLength,
+	"ClusterCredentialsRotationRequestedAtLength":                             ClusterCredentialsRotationRequestedAtLength,
+	"ClusterCredentialsNamespaceQuotaCpuLength":                               ClusterCredentialsNamespaceQuotaCpuLength,
+	"ClusterCredentialsNamespaceQuotaMemoryLength":                            ClusterCredentialsNamespaceQuotaMemoryLength,
+	"ClusterCredentialsArgocdClusterSecretLength":                             ClusterCredentialsArgocdClusterSecretLength,
+	"ClusterCredentialsProxyUrlLength":                                        ClusterCredentialsProxyUrlLength,
+	"ClusterCredentialsSecretDataHashLength":                                  ClusterCredentialsSecretDataHashLength,
 	"GitopsEngineClusterGitopsengineclusterIDLength":                          GitopsEngineClusterGitopsengineclusterIDLength,
 	"GitopsEngineInstanceGitopsengineinstanceIDLength":                        GitopsEngineInstanceGitopsengineinstanceIDLength,
 	"GitopsEngineInstanceNamespaceNameLength":                                 GitopsEngineInstanceNamespaceNameLength,
@@ -122,9 +176,12 @@ var DbFieldMap = map[string]int{
 	"GitopsEngineClusterClustercredentialsIDLength":                           GitopsEngineClusterClustercredentialsIDLength,
 	"GitopsEngineInstanceEngineclusterIDLength":                               GitopsEngineInstanceEngineclusterIDLength,
 	"GitopsEngineInstanceEngineClusterIDLength":                               GitopsEngineInstanceEngineclusterIDLength,
+	"GitopsEngineInstanceArgocdVersionLength":                                 GitopsEngineInstanceArgocdVersionLength,
 	"ManagedEnvironmentManagedenvironmentIDLength":                            ManagedEnvironmentManagedenvironmentIDLength,
 	"ManagedEnvironmentNameLength":                                            ManagedEnvironmentNameLength,
 	"ManagedEnvironmentClustercredentialsIDLength":                            ManagedEnvironmentClustercredentialsIDLength,
+	"ManagedEnvironmentKubernetesVersionLength":                               ManagedEnvironmentKubernetesVersionLength,
+	"ManagedEnvironmentApiGroupsLength":                                       ManagedEnvironmentApiGroupsLength,
 	"ClusterUserClusteruserIDLength":                                          ClusterUserClusteruserIDLength,
 	"ClusterUserUserNameLength":                                               ClusterUserUserNameLength,
 	"ClusterAccessClusteraccessUserIDLength":                                  ClusterAccessClusteraccessUserIDLength,
@@ -137,6 +194,8 @@ var DbFieldMap = map[string]int{
 	"OperationResourceTypeLength":                                             OperationResourceTypeLength,
 	"OperationStateLength":                                                    OperationStateLength,
 	"OperationHumanReadableStateLength":                                       OperationHumanReadableStateLength,
+	"OperationProcessingOwnerLength":                                          OperationProcessingOwnerLength,
+	"OperationResumeTokenLength":                                              OperationResumeTokenLength,
 	"ApplicationApplicationIDLength":                                          ApplicationApplicationIDLength,
 	"ApplicationNameLength":                                                   ApplicationNameLength,
 	"ApplicationSpecFieldLength":                                              ApplicationSpecFieldLength,
@@ -150,6 +209,13 @@ var DbFieldMap = map[string]int{
 	"ApplicationStateResourcesLength":                                         262144, /*Size is defined here because table doesn't have byte Array limit.*/
 	"ApplicationStateReconciledStateLength":                                   ApplicationStateReconciledStateLength,
 	"ApplicationStateSyncErrorLength":                                         ApplicationStateSyncErrorLength,
+	"ApplicationStateLastSyncLength":                                          ApplicationStateLastSyncLength,
+	"ApplicationStateResolvedRevisionLength":                                  ApplicationStateResolvedRevisionLength,
+	"ApplicationStateRevisionErrorLength":                                     ApplicationStateRevisionErrorLength,
+	"ApplicationStateDiffPreviewLength":                                       ApplicationStateDiffPreviewLength,
+	"ApplicationStateResourceEstimateLength":                                  ApplicationStateResourceEstimateLength,
+	"ApplicationStateSyncHooksLength":                                         ApplicationStateSyncHooksLength,
+	"ApplicationStateOperationStateLength":                                    ApplicationStateOperationStateLength,
 	"DeploymentToApplicationMappingDeploymenttoapplicationmappingUIDIDLength": DeploymentToApplicationMappingDeploymenttoapplicationmappingUIDIDLength,
 	"DeploymentToApplicationMappingNameLength":                                DeploymentToApplicationMappingNameLength,
 	"DeploymentToApplicationMappingDeploymentNameLength":                      DeploymentToApplicationMappingNameLength,
@@ -157,6 +223,7 @@ var DbFieldMap = map[string]int{
 	"DeploymentToApplicationMappingDeploymentNamespaceLength":                 DeploymentToApplicationMappingNamespaceLength,
 	"DeploymentToApplicationMappingNamespaceUIDLength":                        DeploymentToApplicationMappingNamespaceUIDLength,
 	"DeploymentToApplicationMappingApplicationIDLength":                       DeploymentToApplicationMappingApplicationIDLength,
+	"DeploymentToApplicationMappingRestoredFromDeploymentUIDLength":           DeploymentToApplicationMappingRestoredFromDeploymentUIDLength,
 	"KubernetesToDBResourceMappingKubernetesResourceTypeLength":               KubernetesToDBResourceMappingKubernetesResourceTypeLength,
 	"KubernetesToDBResourceMappingKubernetesResourceUIDLength":                KubernetesToDBResourceMappingKubernetesResourceUIDLength,
 	"KubernetesToDBResourceMappingDbRelationTypeLength":                       KubernetesToDBResourceMappingDbRelationTypeLength,
@@ -184,6 +251,10 @@ var DbFieldMap = map[string]int{
 	"SyncOperationDeploymentNameFieldLength":                                  SyncOperationDeploymentNameLength,
 	"SyncOperationRevisionLength":                                             SyncOperationRevisionLength,
 	"SyncOperationDesiredStateLength":                                         SyncOperationDesiredStateLength,
+	"SyncOperationInitiatorLength":                                            SyncOperationInitiatorLength,
+	"SyncOperationResourcesLength":                                            SyncOperationResourcesLength,
+	"SyncOperationRetryBackoffDurationLength":                                 SyncOperationRetryBackoffDurationLength,
+	"SyncOperationRetryBackoffMaxDurationLength":                              SyncOperationRetryBackoffMaxDurationLength,
 	"RepositoryCredentialsRepositorycredentialsIDLength":                      RepositoryCredentialsRepositorycredentialsIDLength,
 	"RepositoryCredentialsRepoCredUserIDLength":                               RepositoryCredentialsRepoCredUserIDLength,
 	"RepositoryCredentialsRepoCredURLLength":                                  RepositoryCredentialsRepoCredURLLength,
@@ -192,6 +263,31 @@ var DbFieldMap = map[string]int{
 	"RepositoryCredentialsRepoCredSshLength":                                  RepositoryCredentialsRepoCredSshLength,
 	"RepositoryCredentialsRepoCredSecretLength":                               RepositoryCredentialsRepoCredSecretLength,
 	"RepositoryCredentialsRepoCredEngineIDLength":                             RepositoryCredentialsRepoCredEngineIDLength,
+	"RepositoryCredentialsRepoCredProjectLength":                              RepositoryCredentialsRepoCredProjectLength,
+	"KeyStoreKeyStoreIDLength":                                                KeyStoreKeyStoreIDLength,
+	"KeyStoreClusterUserIDLength":                                             KeyStoreClusterUserIDLength,
+	"KeyStoreWrappedKeyLength":                                                KeyStoreWrappedKeyLength,
+	"KeyStoreMasterKeyIDLength":                                               KeyStoreMasterKeyIDLength,
+	"ApplicationOwnerApplicationIDLength":                                     ApplicationOwnerApplicationIDLength,
+	"ApplicationOwnerClusterUserIDLength":                                     ApplicationOwnerClusterUserIDLength,
+	"FeatureFlagFeatureFlagIDLength":                                          FeatureFlagFeatureFlagIDLength,
+	"FeatureFlagNameLength":                                                   FeatureFlagNameLength,
+	"FeatureFlagNamespaceLength":                                              FeatureFlagNamespaceLength,
+	"DeploymentHistoryDeploymentHistoryIDLength":                              DeploymentHistoryDeploymentHistoryIDLength,
+	"DeploymentHistoryApplicationIDLength":                                    DeploymentHistoryApplicationIDLength,
+	"DeploymentHistoryRevisionLength":                                         DeploymentHistoryRevisionLength,
+	"DeploymentHistoryImagesLength":                                           DeploymentHistoryImagesLength,
+	"DeploymentHistoryInitiatorLength":                                        DeploymentHistoryInitiatorLength,
+	"NamespaceOffboardingNamespaceUIDLength":                                  NamespaceOffboardingNamespaceUIDLength,
+	"NamespaceOffboardingNamespaceNameLength":                                 NamespaceOffboardingNamespaceNameLength,
+	"NamespaceOffboardingStepLength":                                          NamespaceOffboardingStepLength,
+	"MigrationOperationNamespaceUIDLength":                                    MigrationOperationNamespaceUIDLength,
+	"MigrationOperationNamespaceNameLength":                                   MigrationOperationNamespaceNameLength,
+	"MigrationOperationSourceGitopsEngineInstanceIDLength":                    MigrationOperationSourceGitopsEngineInstanceIDLength,
+	"MigrationOperationTargetGitopsEngineInstanceIDLength":                    MigrationOperationTargetGitopsEngineInstanceIDLength,
+	"MigrationOperationStepLength":                                            MigrationOperationStepLength,
+	"TaskCheckpointTaskNameLength":                                            TaskCheckpointTaskNameLength,
+	"TaskCheckpointCheckpointLength":                                          TaskCheckpointCheckpointLength,
 }
 
 // Get value of constants based on constant variable name given as String.
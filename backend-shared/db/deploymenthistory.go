@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+func (dbq *PostgreSQLDatabaseQueries) CreateDeploymentHistory(ctx context.Context, obj *DeploymentHistory) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if dbq.allowTestUuids {
+		if IsEmpty(obj.DeploymentHistoryID) {
+			obj.DeploymentHistoryID = "test-" + generateUuid()
+		}
+	} else {
+		if !IsEmpty(obj.DeploymentHistoryID) {
+			return fmt.Errorf("primary key should be empty")
+		}
+
+		obj.DeploymentHistoryID = generateUuid()
+	}
+
+	if err := isEmptyValues("CreateDeploymentHistory",
+		"ApplicationID", obj.ApplicationID); err != nil {
+		return err
+	}
+
+	if obj.DeployedAt.IsZero() {
+		return fmt.Errorf("deployedAt field should not be empty")
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	obj.Created_on = time.Now()
+
+	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
+	if err != nil {
+		return fmt.Errorf("error on inserting deployment history: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
+// ListDeploymentHistoryByApplicationID returns the revisions deployed by an Application, from newest to oldest.
+// - If limit is greater than 0, at most 'limit' rows (the most recent) are returned.
+func (dbq *PostgreSQLDatabaseQueries) ListDeploymentHistoryByApplicationID(ctx context.Context, applicationID string, limit int,
+	deploymentHistory *[]DeploymentHistory) error {
+
+	if err := validateQueryParams(applicationID, dbq); err != nil {
+		return err
+	}
+
+	query := dbq.dbConnection.Model(deploymentHistory).
+		Where("dh.deploymenthistory_application_id = ?", applicationID).
+		Order("argocd_history_id DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Context(ctx).Select(); err != nil {
+		return fmt.Errorf("error on retrieving ListDeploymentHistoryByApplicationID: %v", err)
+	}
+
+	return nil
+}
+
+// CountDeploymentHistoryByApplicationIDsSince returns the number of revisions deployed by the given Applications, at
+// or after 'since'.
+func (dbq *PostgreSQLDatabaseQueries) CountDeploymentHistoryByApplicationIDsSince(ctx context.Context, applicationIDs []string,
+	since time.Time) (int, error) {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return 0, err
+	}
+
+	if len(applicationIDs) == 0 {
+		return 0, nil
+	}
+
+	count, err := dbq.dbConnection.Model(&DeploymentHistory{}).
+		Where("dh.deploymenthistory_application_id IN (?)", pg.In(applicationIDs)).
+		Where("dh.deployed_at >= ?", since).
+		Context(ctx).
+		Count()
+
+	if err != nil {
+		return 0, fmt.Errorf("error on counting deployment history: %v", err)
+	}
+
+	return count, nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) DeleteDeploymentHistoryById(ctx context.Context, id string) (int, error) {
+
+	if err := validateQueryParams(id, dbq); err != nil {
+		return 0, err
+	}
+
+	result := &DeploymentHistory{}
+
+	deleteResult, err := dbq.dbConnection.Model(result).
+		Where("dh.deploymenthistory_id = ?", id).
+		Context(ctx).
+		Delete()
+
+	if err != nil {
+		return 0, fmt.Errorf("error on deleting deployment history: %v", err)
+	}
+
+	return deleteResult.RowsAffected(), nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllDeploymentHistory(ctx context.Context, deploymentHistory *[]DeploymentHistory) error {
+
+	if err := validateUnsafeQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	return dbq.dbConnection.Model(deploymentHistory).Order("seq_id ASC").Context(ctx).Select()
+}
+
+var _ AppScopedDisposableResource = &DeploymentHistory{}
+
+func (obj *DeploymentHistory) DisposeAppScoped(ctx context.Context, dbq ApplicationScopedQueries) error {
+	if dbq == nil {
+		return fmt.Errorf("missing database interface in DeploymentHistory dispose")
+	}
+
+	_, err := dbq.DeleteDeploymentHistoryById(ctx, obj.DeploymentHistoryID)
+	return err
+}
+
+// GetAsLogKeyValues returns an []interface that can be passed to log.Info(...).
+// e.g. log.Info("Creating database resource", obj.GetAsLogKeyValues()...)
+func (obj *DeploymentHistory) GetAsLogKeyValues() []interface{} {
+	if obj == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{"deploymentHistoryID", obj.DeploymentHistoryID,
+		"applicationID", obj.ApplicationID,
+		"argoCDHistoryID", obj.ArgoCDHistoryID,
+		"revision", obj.Revision,
+		"initiator", obj.Initiator}
+}
@@ -0,0 +1,167 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("DeploymentHistory Tests", func() {
+
+	var (
+		ctx         context.Context
+		dbq         db.AllDatabaseQueries
+		application db.Application
+	)
+
+	BeforeEach(func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+
+		_, managedEnvironment, _, engineInstance, _, err := db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		application = db.Application{
+			Application_id:          "test-my-application",
+			Name:                    "my-application",
+			Spec_field:              "{}",
+			Engine_instance_inst_id: engineInstance.Gitopsengineinstance_id,
+			Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+		}
+		err = dbq.CreateApplication(ctx, &application)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		dbq.CloseDatabase()
+	})
+
+	It("should create, list and delete DeploymentHistory", func() {
+
+		deployedAt := time.Now().Add(-1 * time.Hour)
+
+		By("creating three entries, one for each sync of the Application")
+		for i := int64(1); i <= 3; i++ {
+			entry := db.DeploymentHistory{
+				ApplicationID:   application.Application_id,
+				ArgoCDHistoryID: i,
+				Revision:        fmt.Sprintf("test-revision-%d", i),
+				Images:          "quay.io/org/app:v1,quay.io/org/sidecar:v1",
+				Initiator:       db.SyncOperation_Initiator_Automated,
+				DeployedAt:      deployedAt.Add(time.Duration(i) * time.Minute),
+			}
+			err := dbq.CreateDeploymentHistory(ctx, &entry)
+			Expect(err).To(BeNil())
+			Expect(entry.DeploymentHistoryID).ToNot(BeEmpty())
+		}
+
+		By("verifying an entry with the same Argo CD history ID cannot be created for the Application")
+		duplicate := db.DeploymentHistory{
+			ApplicationID:   application.Application_id,
+			ArgoCDHistoryID: 2,
+			DeployedAt:      deployedAt,
+		}
+		err := dbq.CreateDeploymentHistory(ctx, &duplicate)
+		Expect(err).ToNot(BeNil())
+
+		By("listing the entries, newest first")
+		var deploymentHistory []db.DeploymentHistory
+		err = dbq.ListDeploymentHistoryByApplicationID(ctx, application.Application_id, 0, &deploymentHistory)
+		Expect(err).To(BeNil())
+		Expect(deploymentHistory).To(HaveLen(3))
+		Expect(deploymentHistory[0].ArgoCDHistoryID).To(Equal(int64(3)))
+		Expect(deploymentHistory[2].ArgoCDHistoryID).To(Equal(int64(1)))
+		Expect(deploymentHistory[0].Images).To(Equal("quay.io/org/app:v1,quay.io/org/sidecar:v1"))
+		Expect(deploymentHistory[0].Initiator).To(Equal(db.SyncOperation_Initiator_Automated))
+
+		By("limiting the number of entries that are returned")
+		deploymentHistory = []db.DeploymentHistory{}
+		err = dbq.ListDeploymentHistoryByApplicationID(ctx, application.Application_id, 2, &deploymentHistory)
+		Expect(err).To(BeNil())
+		Expect(deploymentHistory).To(HaveLen(2))
+		Expect(deploymentHistory[0].ArgoCDHistoryID).To(Equal(int64(3)))
+		Expect(deploymentHistory[1].ArgoCDHistoryID).To(Equal(int64(2)))
+
+		By("deleting an entry")
+		rowsAffected, err := dbq.DeleteDeploymentHistoryById(ctx, deploymentHistory[0].DeploymentHistoryID)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		By("deleting the Application, which should delete its remaining entries")
+		rowsAffected, err = dbq.DeleteApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		deploymentHistory = []db.DeploymentHistory{}
+		err = dbq.ListDeploymentHistoryByApplicationID(ctx, application.Application_id, 0, &deploymentHistory)
+		Expect(err).To(BeNil())
+		Expect(deploymentHistory).To(BeEmpty())
+	})
+
+	It("should count the DeploymentHistory of Applications since a given time", func() {
+
+		now := time.Now()
+
+		for i := int64(1); i <= 3; i++ {
+			entry := db.DeploymentHistory{
+				ApplicationID:   application.Application_id,
+				ArgoCDHistoryID: i,
+				Revision:        fmt.Sprintf("test-revision-%d", i),
+				DeployedAt:      now.Add(-time.Duration(i) * 10 * time.Hour),
+			}
+			err := dbq.CreateDeploymentHistory(ctx, &entry)
+			Expect(err).To(BeNil())
+		}
+
+		count, err := dbq.CountDeploymentHistoryByApplicationIDsSince(ctx, []string{application.Application_id}, now.Add(-24*time.Hour))
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+
+		count, err = dbq.CountDeploymentHistoryByApplicationIDsSince(ctx, []string{application.Application_id, "test-other-application"},
+			now.Add(-48*time.Hour))
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(3))
+
+		count, err = dbq.CountDeploymentHistoryByApplicationIDsSince(ctx, []string{"test-other-application"}, now.Add(-48*time.Hour))
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(0))
+
+		count, err = dbq.CountDeploymentHistoryByApplicationIDsSince(ctx, []string{}, now.Add(-48*time.Hour))
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(0))
+	})
+
+	It("should not create DeploymentHistory with empty or too long fields", func() {
+
+		entry := db.DeploymentHistory{
+			DeployedAt: time.Now(),
+		}
+		err := dbq.CreateDeploymentHistory(ctx, &entry)
+		Expect(err).ToNot(BeNil())
+
+		entry = db.DeploymentHistory{
+			ApplicationID: application.Application_id,
+		}
+		err = dbq.CreateDeploymentHistory(ctx, &entry)
+		Expect(err).ToNot(BeNil())
+
+		entry = db.DeploymentHistory{
+			ApplicationID: application.Application_id,
+			Revision:      strings.Repeat("abc", 100),
+			DeployedAt:    time.Now(),
+		}
+		err = dbq.CreateDeploymentHistory(ctx, &entry)
+		Expect(err).ToNot(BeNil())
+		Expect(db.IsMaxLengthError(err)).To(BeTrue())
+	})
+})
@@ -23,8 +23,7 @@ func (dbq *PostgreSQLDatabaseQueries) ListDeploymentToApplicationMappingByNamesp
 
 	var dbResults []DeploymentToApplicationMapping
 
-	// TODO: GITOPSRVCE-68 - PERF - Add index for this
-
+	// Uses the idx_dtam_namespace_uid_seq_id index
 	if err := dbq.dbConnection.Model(&dbResults).
 		Where("dta.namespace_uid = ?", namespaceUID).
 		Context(ctx).
@@ -38,6 +37,45 @@ func (dbq *PostgreSQLDatabaseQueries) ListDeploymentToApplicationMappingByNamesp
 	return nil
 }
 
+// ListDeploymentToApplicationMappingsForNamespaceUID lists a page of the DTAMs that are in a namespace with the given UID,
+// using keyset pagination on seq_id: unlike an offset, the page is not affected by DTAMs that were deleted from (or
+// added to) the previous pages since they were listed.
+func (dbq *PostgreSQLDatabaseQueries) ListDeploymentToApplicationMappingsForNamespaceUID(ctx context.Context, namespaceUID string,
+	afterSeqID int64, limit int, deplToAppMappingParam *[]DeploymentToApplicationMapping) error {
+
+	if err := validateQueryParamsEntity(deplToAppMappingParam, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("ListDeploymentToApplicationMappingsForNamespaceUID",
+		"NamespaceUID", namespaceUID,
+	); err != nil {
+		return err
+	}
+
+	if limit <= 0 {
+		return fmt.Errorf("invalid limit for ListDeploymentToApplicationMappingsForNamespaceUID: %d", limit)
+	}
+
+	var dbResults []DeploymentToApplicationMapping
+
+	// Uses the idx_dtam_namespace_uid_seq_id index
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("dta.namespace_uid = ?", namespaceUID).
+		Where("dta.seq_id > ?", afterSeqID).
+		Order("seq_id ASC").
+		Limit(limit).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListDeploymentToApplicationMappingsForNamespaceUID: %v", err)
+	}
+
+	*deplToAppMappingParam = dbResults
+
+	return nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) ListDeploymentToApplicationMappingByNamespaceAndName(ctx context.Context, deploymentName string,
 	deploymentNamespace string, namespaceUID string, deplToAppMappingParam *[]DeploymentToApplicationMapping) error {
 
@@ -128,5 +128,52 @@ var _ = Describe("DeploymentToApplicationMapping Tests", func() {
 			Expect(err).To(BeNil())
 			Expect(len(listOfDeploymentToApplicationMappingFromDB)).To(Equal(3))
 		})
+
+		It("Should list the DeploymentToApplicationMappings of a namespace in pages", func() {
+			defer dbq.CloseDatabase()
+
+			// Create entries in another namespace, which should not be listed
+			otherNamespaceDTAM := *deploymentToApplicationMapping
+			otherNamespaceDTAM.NamespaceUID = "test-other-namespace"
+			createAppAndDtamEntry(ctx, dbq, application, &otherNamespaceDTAM)
+
+			// One entry was created in BeforeEach
+			for i := 0; i < 4; i++ {
+				createAppAndDtamEntry(ctx, dbq, application, deploymentToApplicationMapping)
+			}
+
+			var allDTAMs []db.DeploymentToApplicationMapping
+			var afterSeqID int64
+			for {
+				var page []db.DeploymentToApplicationMapping
+				err := dbq.ListDeploymentToApplicationMappingsForNamespaceUID(ctx, "demo-namespace", afterSeqID, 2, &page)
+				Expect(err).To(BeNil())
+				Expect(len(page)).To(BeNumerically("<=", 2))
+
+				if len(page) == 0 {
+					break
+				}
+
+				By("deleting an entry of a page that was listed, which should not affect the next page")
+				_, err = dbq.DeleteDeploymentToApplicationMappingByDeplId(ctx, page[0].Deploymenttoapplicationmapping_uid_id)
+				Expect(err).To(BeNil())
+
+				allDTAMs = append(allDTAMs, page...)
+				afterSeqID = page[len(page)-1].SeqID
+			}
+
+			Expect(allDTAMs).To(HaveLen(5))
+			for i := range allDTAMs {
+				Expect(allDTAMs[i].NamespaceUID).To(Equal("demo-namespace"))
+				if i > 0 {
+					Expect(allDTAMs[i].SeqID).To(BeNumerically(">", allDTAMs[i-1].SeqID))
+				}
+			}
+
+			By("verifying the limit must be positive")
+			var page []db.DeploymentToApplicationMapping
+			err := dbq.ListDeploymentToApplicationMappingsForNamespaceUID(ctx, "demo-namespace", 0, 0, &page)
+			Expect(err).ToNot(BeNil())
+		})
 	})
 })
@@ -0,0 +1,345 @@
+package db
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Sensitive columns of RepositoryCredentials rows (password and SSH key) are encrypted using envelope encryption:
+// - Each ClusterUser has a data key (a random AES-256 key), stored in the KeyStore table.
+// - Each data key is encrypted ('wrapped') with the master key, which is only available to the GitOps Service via an
+//   environment variable, and is never stored in the database.
+// - Encrypted column values contain the ID of the KeyStore row whose data key encrypted them.
+//
+// If no master key is configured, the columns are stored unencrypted. Unencrypted values are always readable, so
+// encryption may be enabled on an existing database: values are encrypted the next time their row is updated.
+
+const (
+	// MasterKeyEnvVar is the environment variable containing the master key, which is used to wrap the data keys of
+	// the KeyStore table. If it is not set, RepositoryCredentials are stored unencrypted.
+	MasterKeyEnvVar = "DB_ENCRYPTION_MASTER_KEY"
+
+	// PreviousMasterKeysEnvVar is the environment variable containing a comma-separated list of master keys that were
+	// previously in use. Data keys that were wrapped with these keys can still be unwrapped, until they are rewrapped
+	// with the current master key by RotateMasterKeyOfKeyStores.
+	PreviousMasterKeysEnvVar = "DB_ENCRYPTION_PREVIOUS_MASTER_KEYS"
+
+	// encryptedValuePrefix is the prefix of encrypted column values, which have the format:
+	// 'enc:v1:(KeyStore ID):(base64-encoded nonce and ciphertext)'
+	encryptedValuePrefix = "enc:v1:"
+)
+
+// currentMasterKey returns the master key that new data keys are wrapped with, or "" if encryption is disabled.
+func currentMasterKey() string {
+	return os.Getenv(MasterKeyEnvVar)
+}
+
+// findMasterKey returns the current or previous master key with the given ID.
+func findMasterKey(masterKeyID string) (string, error) {
+
+	masterKeys := []string{currentMasterKey()}
+	masterKeys = append(masterKeys, strings.Split(os.Getenv(PreviousMasterKeysEnvVar), ",")...)
+
+	for _, masterKey := range masterKeys {
+		masterKey = strings.TrimSpace(masterKey)
+		if masterKey != "" && getMasterKeyID(masterKey) == masterKeyID {
+			return masterKey, nil
+		}
+	}
+
+	return "", fmt.Errorf("master key '%s' is not configured: it must be set in either %s or %s",
+		masterKeyID, MasterKeyEnvVar, PreviousMasterKeysEnvVar)
+}
+
+// getMasterKeyID returns an identifier of the master key, which may be stored in the database.
+func getMasterKeyID(masterKey string) string {
+	id := sha256.Sum256([]byte("managed-gitops-master-key-id:" + masterKey))
+	return hex.EncodeToString(id[:8])
+}
+
+// newDataKey returns a new random AES-256 key.
+func newDataKey() ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("unable to generate data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// wrapDataKey encrypts the data key with the master key.
+func wrapDataKey(dataKey []byte, masterKey string) (string, error) {
+	masterKeyBytes := sha256.Sum256([]byte(masterKey))
+	return sealValue(masterKeyBytes[:], dataKey)
+}
+
+// unwrapDataKey decrypts the data key of the KeyStore row, with the master key it was wrapped with.
+func unwrapDataKey(keyStore KeyStore) ([]byte, error) {
+
+	masterKey, err := findMasterKey(keyStore.MasterKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	masterKeyBytes := sha256.Sum256([]byte(masterKey))
+
+	dataKey, err := openValue(masterKeyBytes[:], keyStore.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unwrap data key of KeyStore '%s': %w", keyStore.KeyStoreID, err)
+	}
+
+	return dataKey, nil
+}
+
+// sealValue encrypts the value with the key (AES-256-GCM), and returns the base64-encoded nonce and ciphertext.
+func sealValue(key []byte, value []byte) (string, error) {
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, value, nil)), nil
+}
+
+// openValue reverses sealValue.
+func openValue(key []byte, value string) ([]byte, error) {
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted value is too short")
+	}
+
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// isEncryptedValue returns true if the column value was encrypted by encryptValue, false if it is plaintext.
+func isEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+// keyStoreIDOfEncryptedValue returns the ID of the KeyStore row whose data key encrypted the value.
+func keyStoreIDOfEncryptedValue(value string) (string, error) {
+
+	keyStoreID, _, found := strings.Cut(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	if !found || keyStoreID == "" {
+		return "", errors.New("encrypted value is not in the expected format")
+	}
+
+	return keyStoreID, nil
+}
+
+// dataKeyCache caches the unwrapped data keys of KeyStore rows, for the duration of a single query, so that
+// a list of rows owned by the same user only requires the KeyStore row to be read once.
+// - map: KeyStore ID -> data key
+type dataKeyCache map[string][]byte
+
+// getDataKey returns the unwrapped data key of the KeyStore row with the given ID.
+func (dbq *PostgreSQLDatabaseQueries) getDataKey(ctx context.Context, keyStoreID string, cache dataKeyCache) ([]byte, error) {
+
+	if dataKey, exists := cache[keyStoreID]; exists {
+		return dataKey, nil
+	}
+
+	keyStore := KeyStore{KeyStoreID: keyStoreID}
+	if err := dbq.GetKeyStoreById(ctx, &keyStore); err != nil {
+		return nil, fmt.Errorf("unable to retrieve KeyStore '%s': %w", keyStoreID, err)
+	}
+
+	dataKey, err := unwrapDataKey(keyStore)
+	if err != nil {
+		return nil, err
+	}
+
+	cache[keyStoreID] = dataKey
+
+	return dataKey, nil
+}
+
+// getOrCreateActiveDataKey returns the active KeyStore row (and unwrapped data key) of the user, creating one if the
+// user does not yet have a data key, or if the active data key is wrapped with a master key that is no longer configured.
+func (dbq *PostgreSQLDatabaseQueries) getOrCreateActiveDataKey(ctx context.Context, clusterUserID string, cache dataKeyCache) (KeyStore, []byte, error) {
+
+	var keyStore KeyStore
+	err := dbq.GetActiveKeyStoreForClusterUser(ctx, clusterUserID, &keyStore)
+	if err == nil {
+		if dataKey, err := dbq.getDataKey(ctx, keyStore.KeyStoreID, cache); err == nil {
+			return keyStore, dataKey, nil
+		}
+	} else if !IsResultNotFoundError(err) {
+		return KeyStore{}, nil, err
+	}
+
+	return dbq.createDataKey(ctx, clusterUserID, cache)
+}
+
+// createDataKey creates a new KeyStore row for the user, which becomes the active data key of the user.
+func (dbq *PostgreSQLDatabaseQueries) createDataKey(ctx context.Context, clusterUserID string, cache dataKeyCache) (KeyStore, []byte, error) {
+
+	masterKey := currentMasterKey()
+	if masterKey == "" {
+		return KeyStore{}, nil, fmt.Errorf("unable to create data key: %s is not set", MasterKeyEnvVar)
+	}
+
+	dataKey, err := newDataKey()
+	if err != nil {
+		return KeyStore{}, nil, err
+	}
+
+	wrappedKey, err := wrapDataKey(dataKey, masterKey)
+	if err != nil {
+		return KeyStore{}, nil, fmt.Errorf("unable to wrap data key: %w", err)
+	}
+
+	keyStore := KeyStore{
+		ClusterUserID: clusterUserID,
+		WrappedKey:    wrappedKey,
+		MasterKeyID:   getMasterKeyID(masterKey),
+	}
+	if err := dbq.CreateKeyStore(ctx, &keyStore); err != nil {
+		return KeyStore{}, nil, err
+	}
+
+	cache[keyStore.KeyStoreID] = dataKey
+
+	return keyStore, dataKey, nil
+}
+
+// encryptRepositoryCredentials encrypts the password and SSH key of the RepositoryCredentials with the active data key
+// of the user that owns it. The values are not modified if no master key is configured.
+func (dbq *PostgreSQLDatabaseQueries) encryptRepositoryCredentials(ctx context.Context, obj *RepositoryCredentials) error {
+
+	if currentMasterKey() == "" {
+		return nil
+	}
+
+	if (obj.AuthPassword == "" || isEncryptedValue(obj.AuthPassword)) && (obj.AuthSSHKey == "" || isEncryptedValue(obj.AuthSSHKey)) {
+		return nil
+	}
+
+	keyStore, dataKey, err := dbq.getOrCreateActiveDataKey(ctx, obj.UserID, dataKeyCache{})
+	if err != nil {
+		return fmt.Errorf("unable to retrieve data key of user '%s': %w", obj.UserID, err)
+	}
+
+	fields := []struct {
+		name          string
+		value         *string
+		maximumLength int
+	}{
+		{"AuthPassword", &obj.AuthPassword, RepositoryCredentialsRepoCredPassLength},
+		{"AuthSSHKey", &obj.AuthSSHKey, RepositoryCredentialsRepoCredSshLength},
+	}
+
+	for _, field := range fields {
+		if *field.value == "" || isEncryptedValue(*field.value) {
+			continue
+		}
+
+		sealed, err := sealValue(dataKey, []byte(*field.value))
+		if err != nil {
+			return fmt.Errorf("unable to encrypt repository credentials: %w", err)
+		}
+
+		encryptedValue := encryptedValuePrefix + keyStore.KeyStoreID + ":" + sealed
+
+		// The encrypted value is about 4/3 the length of the plaintext (plus the prefix, nonce and tag), so a plaintext
+		// that fits in the column may not fit once it is encrypted.
+		if len(encryptedValue) > field.maximumLength {
+			return fmt.Errorf("%v value exceeds maximum size when encrypted: max: %d, actual: %d (plaintext: %d)",
+				field.name, field.maximumLength, len(encryptedValue), len(*field.value))
+		}
+
+		*field.value = encryptedValue
+	}
+
+	return nil
+}
+
+// decryptRepositoryCredentials decrypts the password and SSH key of the RepositoryCredentials, if they are encrypted.
+func (dbq *PostgreSQLDatabaseQueries) decryptRepositoryCredentials(ctx context.Context, obj *RepositoryCredentials, cache dataKeyCache) error {
+
+	for _, field := range []*string{&obj.AuthPassword, &obj.AuthSSHKey} {
+		if !isEncryptedValue(*field) {
+			continue
+		}
+
+		keyStoreID, err := keyStoreIDOfEncryptedValue(*field)
+		if err != nil {
+			return fmt.Errorf("unable to decrypt repository credentials '%s': %w", obj.RepositoryCredentialsID, err)
+		}
+
+		dataKey, err := dbq.getDataKey(ctx, keyStoreID, cache)
+		if err != nil {
+			return fmt.Errorf("unable to decrypt repository credentials '%s': %w", obj.RepositoryCredentialsID, err)
+		}
+
+		plaintext, err := openValue(dataKey, strings.TrimPrefix(*field, encryptedValuePrefix+keyStoreID+":"))
+		if err != nil {
+			return fmt.Errorf("unable to decrypt repository credentials '%s': %w", obj.RepositoryCredentialsID, err)
+		}
+
+		*field = string(plaintext)
+	}
+
+	return nil
+}
+
+// decryptRepositoryCredentialsList decrypts the password and SSH key of each of the RepositoryCredentials.
+//
+// Rows that cannot be decrypted (for example, because their KeyStore row was deleted, or was wrapped with a master key
+// that is no longer configured) are logged and removed from the list, so that a single unreadable row does not
+// prevent the other rows from being listed.
+func (dbq *PostgreSQLDatabaseQueries) decryptRepositoryCredentialsList(ctx context.Context, repositoryCredentials *[]RepositoryCredentials) {
+
+	cache := dataKeyCache{}
+
+	decrypted := (*repositoryCredentials)[:0]
+
+	for i := range *repositoryCredentials {
+		repoCred := (*repositoryCredentials)[i]
+
+		if err := dbq.decryptRepositoryCredentials(ctx, &repoCred, cache); err != nil {
+			log.FromContext(ctx).Error(err, "skipping RepositoryCredentials that could not be decrypted",
+				"repositoryCredentialsID", repoCred.RepositoryCredentialsID)
+			continue
+		}
+
+		decrypted = append(decrypted, repoCred)
+	}
+
+	*repositoryCredentials = decrypted
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (dbq *PostgreSQLDatabaseQueries) CreateFeatureFlag(ctx context.Context, obj *FeatureFlag) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if dbq.allowTestUuids {
+		if IsEmpty(obj.FeatureFlagID) {
+			obj.FeatureFlagID = "test-" + generateUuid()
+		}
+	} else {
+		if !IsEmpty(obj.FeatureFlagID) {
+			return fmt.Errorf("primary key should be empty")
+		}
+
+		obj.FeatureFlagID = generateUuid()
+	}
+
+	if err := isEmptyValues("CreateFeatureFlag",
+		"Name", obj.Name); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	obj.Created_on = time.Now()
+
+	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
+	if err != nil {
+		return fmt.Errorf("error on inserting feature flag: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) GetFeatureFlagById(ctx context.Context, obj *FeatureFlag) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if IsEmpty(obj.FeatureFlagID) {
+		return fmt.Errorf("feature flag id is empty")
+	}
+
+	var dbResults []FeatureFlag
+
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("ff.featureflag_id = ?", obj.FeatureFlagID).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving GetFeatureFlagById: %v", err)
+	}
+
+	if len(dbResults) >= 2 {
+		return fmt.Errorf("multiple results returned from GetFeatureFlagById")
+	}
+
+	if len(dbResults) == 0 {
+		return NewResultNotFoundError("no results found for GetFeatureFlagById")
+	}
+
+	*obj = dbResults[0]
+
+	return nil
+}
+
+// GetFeatureFlagByNameAndNamespace retrieves the flag with the given name and namespace: an empty namespace
+// retrieves the installation-wide flag.
+func (dbq *PostgreSQLDatabaseQueries) GetFeatureFlagByNameAndNamespace(ctx context.Context, obj *FeatureFlag) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if IsEmpty(obj.Name) {
+		return fmt.Errorf("feature flag name is empty")
+	}
+
+	var dbResults []FeatureFlag
+
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("ff.featureflag_name = ?", obj.Name).
+		Where("ff.featureflag_namespace = ?", obj.Namespace).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving GetFeatureFlagByNameAndNamespace: %v", err)
+	}
+
+	if len(dbResults) >= 2 {
+		return fmt.Errorf("multiple results returned from GetFeatureFlagByNameAndNamespace")
+	}
+
+	if len(dbResults) == 0 {
+		return NewResultNotFoundError("no results found for GetFeatureFlagByNameAndNamespace")
+	}
+
+	*obj = dbResults[0]
+
+	return nil
+}
+
+// ListFeatureFlags returns all the FeatureFlag rows, both installation-wide and namespace-scoped.
+func (dbq *PostgreSQLDatabaseQueries) ListFeatureFlags(ctx context.Context, featureFlags *[]FeatureFlag) error {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(featureFlags).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListFeatureFlags: %v", err)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) UpdateFeatureFlag(ctx context.Context, obj *FeatureFlag) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("UpdateFeatureFlag",
+		"FeatureFlagID", obj.FeatureFlagID,
+		"Name", obj.Name); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
+	if err != nil {
+		return fmt.Errorf("error on updating feature flag: %v, %v", err, obj.FeatureFlagID)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d, %v", result.RowsAffected(), obj.FeatureFlagID)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) DeleteFeatureFlagById(ctx context.Context, id string) (int, error) {
+
+	if err := validateQueryParams(id, dbq); err != nil {
+		return 0, err
+	}
+
+	result := &FeatureFlag{}
+
+	deleteResult, err := dbq.dbConnection.Model(result).
+		Where("ff.featureflag_id = ?", id).
+		Context(ctx).
+		Delete()
+
+	if err != nil {
+		return 0, fmt.Errorf("error on deleting feature flag: %v", err)
+	}
+
+	return deleteResult.RowsAffected(), nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllFeatureFlags(ctx context.Context, featureFlags *[]FeatureFlag) error {
+
+	if err := validateUnsafeQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	return dbq.dbConnection.Model(featureFlags).Order("seq_id ASC").Context(ctx).Select()
+}
+
+var _ DisposableResource = &FeatureFlag{}
+
+func (obj *FeatureFlag) Dispose(ctx context.Context, dbq DatabaseQueries) error {
+	if dbq == nil {
+		return fmt.Errorf("missing database interface in FeatureFlag dispose")
+	}
+
+	_, err := dbq.DeleteFeatureFlagById(ctx, obj.FeatureFlagID)
+	return err
+}
+
+// GetAsLogKeyValues returns an []interface that can be passed to log.Info(...).
+// e.g. log.Info("Creating database resource", obj.GetAsLogKeyValues()...)
+func (obj *FeatureFlag) GetAsLogKeyValues() []interface{} {
+	if obj == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{"featureFlagID", obj.FeatureFlagID,
+		"name", obj.Name,
+		"namespace", obj.Namespace,
+		"enabled", obj.Enabled}
+}
@@ -0,0 +1,120 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("FeatureFlag Tests", func() {
+
+	var (
+		ctx context.Context
+		dbq db.AllDatabaseQueries
+	)
+
+	BeforeEach(func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		dbq.CloseDatabase()
+	})
+
+	It("should create, get, update, list and delete FeatureFlags", func() {
+
+		installationFlag := db.FeatureFlag{
+			Name:    "test-flag",
+			Enabled: true,
+		}
+		err := dbq.CreateFeatureFlag(ctx, &installationFlag)
+		Expect(err).To(BeNil())
+
+		namespaceFlag := db.FeatureFlag{
+			Name:      "test-flag",
+			Namespace: "test-namespace",
+			Enabled:   false,
+		}
+		err = dbq.CreateFeatureFlag(ctx, &namespaceFlag)
+		Expect(err).To(BeNil())
+
+		By("verifying a flag with the same name and namespace cannot be created")
+		duplicate := db.FeatureFlag{
+			Name:      "test-flag",
+			Namespace: "test-namespace",
+		}
+		err = dbq.CreateFeatureFlag(ctx, &duplicate)
+		Expect(err).ToNot(BeNil())
+
+		fetchRow := db.FeatureFlag{FeatureFlagID: installationFlag.FeatureFlagID}
+		err = dbq.GetFeatureFlagById(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.Name).To(Equal("test-flag"))
+		Expect(fetchRow.Namespace).To(BeEmpty())
+		Expect(fetchRow.Enabled).To(BeTrue())
+
+		By("retrieving the installation-wide and namespace-scoped flags by name and namespace")
+		fetchRow = db.FeatureFlag{Name: "test-flag"}
+		err = dbq.GetFeatureFlagByNameAndNamespace(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.FeatureFlagID).To(Equal(installationFlag.FeatureFlagID))
+
+		fetchRow = db.FeatureFlag{Name: "test-flag", Namespace: "test-namespace"}
+		err = dbq.GetFeatureFlagByNameAndNamespace(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.FeatureFlagID).To(Equal(namespaceFlag.FeatureFlagID))
+		Expect(fetchRow.Enabled).To(BeFalse())
+
+		fetchRow = db.FeatureFlag{Name: "test-flag", Namespace: "other-namespace"}
+		err = dbq.GetFeatureFlagByNameAndNamespace(ctx, &fetchRow)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+		By("disabling the installation-wide flag")
+		installationFlag.Enabled = false
+		err = dbq.UpdateFeatureFlag(ctx, &installationFlag)
+		Expect(err).To(BeNil())
+
+		var featureFlags []db.FeatureFlag
+		err = dbq.ListFeatureFlags(ctx, &featureFlags)
+		Expect(err).To(BeNil())
+		Expect(featureFlags).To(ContainElement(WithTransform(func(featureFlag db.FeatureFlag) string {
+			return featureFlag.FeatureFlagID
+		}, Equal(namespaceFlag.FeatureFlagID))))
+
+		fetchRow = db.FeatureFlag{FeatureFlagID: installationFlag.FeatureFlagID}
+		err = dbq.GetFeatureFlagById(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.Enabled).To(BeFalse())
+
+		By("deleting the flags")
+		for _, featureFlag := range []db.FeatureFlag{installationFlag, namespaceFlag} {
+			rowsAffected, err := dbq.DeleteFeatureFlagById(ctx, featureFlag.FeatureFlagID)
+			Expect(err).To(BeNil())
+			Expect(rowsAffected).To(Equal(1))
+		}
+
+		err = dbq.GetFeatureFlagByNameAndNamespace(ctx, &db.FeatureFlag{Name: "test-flag"})
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+	})
+
+	It("should not create a FeatureFlag with an empty or too long name", func() {
+
+		featureFlag := db.FeatureFlag{}
+		err := dbq.CreateFeatureFlag(ctx, &featureFlag)
+		Expect(err).ToNot(BeNil())
+
+		featureFlag = db.FeatureFlag{Name: strings.Repeat("abc", 100)}
+		err = dbq.CreateFeatureFlag(ctx, &featureFlag)
+		Expect(err).ToNot(BeNil())
+		Expect(db.IsMaxLengthError(err)).To(BeTrue())
+	})
+})
@@ -186,6 +186,52 @@ func (dbq *PostgreSQLDatabaseQueries) CreateGitopsEngineInstance(ctx context.Con
 
 }
 
+func (dbq *PostgreSQLDatabaseQueries) UpdateGitopsEngineInstanceMaxApplications(ctx context.Context, gitopsEngineInstanceID string, maxApplications int) error {
+
+	if err := validateQueryParams(gitopsEngineInstanceID, dbq); err != nil {
+		return err
+	}
+
+	if maxApplications < 0 {
+		return fmt.Errorf("max applications should not be negative: %d", maxApplications)
+	}
+
+	result, err := dbq.dbConnection.Model(&GitopsEngineInstance{}).Set("max_applications = ?", maxApplications).
+		Where("gei.gitopsengineinstance_id = ?", gitopsEngineInstanceID).Context(ctx).Update()
+	if err != nil {
+		return fmt.Errorf("error on updating max applications of gitops engine instance: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) UpdateGitopsEngineInstanceArgoCDVersion(ctx context.Context, gitopsEngineInstanceID string, argoCDVersion string) error {
+
+	if err := validateQueryParams(gitopsEngineInstanceID, dbq); err != nil {
+		return err
+	}
+
+	if len(argoCDVersion) > GitopsEngineInstanceArgocdVersionLength {
+		return fmt.Errorf("Argocd_version value exceeds maximum size: max: %d, actual: %d", GitopsEngineInstanceArgocdVersionLength, len(argoCDVersion))
+	}
+
+	result, err := dbq.dbConnection.Model(&GitopsEngineInstance{}).Set("argocd_version = ?", argoCDVersion).
+		Where("gei.gitopsengineinstance_id = ?", gitopsEngineInstanceID).Context(ctx).Update()
+	if err != nil {
+		return fmt.Errorf("error on updating Argo CD version of gitops engine instance: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) CheckedDeleteGitopsEngineInstanceById(ctx context.Context, id string, ownerId string) (int, error) {
 
 	return dbq.internalDeleteGitopsEngineInstanceById(ctx, id, ownerId, false)
@@ -168,4 +168,75 @@ var _ = Describe("Gitopsengineinstance Test", func() {
 		}
 
 	})
+
+	It("Should update the max applications of a GitopsEngineInstance, and count its Applications", func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx := context.Background()
+		dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+		defer dbq.CloseDatabase()
+
+		_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+		Expect(gitopsEngineInstance.Max_applications).To(Equal(0), "instances should have no limit by default")
+
+		count, err := dbq.CountApplicationsForGitopsEngineInstance(ctx, gitopsEngineInstance.Gitopsengineinstance_id)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(0))
+
+		for i := 0; i < 2; i++ {
+			application := db.Application{
+				Application_id:          "test-my-application-" + string(uuid.NewUUID()),
+				Name:                    "my-application",
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbq.CreateApplication(ctx, &application)
+			Expect(err).To(BeNil())
+		}
+
+		count, err = dbq.CountApplicationsForGitopsEngineInstance(ctx, gitopsEngineInstance.Gitopsengineinstance_id)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+
+		err = dbq.UpdateGitopsEngineInstanceMaxApplications(ctx, gitopsEngineInstance.Gitopsengineinstance_id, 5)
+		Expect(err).To(BeNil())
+
+		err = dbq.GetGitopsEngineInstanceById(ctx, gitopsEngineInstance)
+		Expect(err).To(BeNil())
+		Expect(gitopsEngineInstance.Max_applications).To(Equal(5))
+
+		err = dbq.UpdateGitopsEngineInstanceMaxApplications(ctx, gitopsEngineInstance.Gitopsengineinstance_id, -1)
+		Expect(err).ToNot(BeNil())
+
+		err = dbq.UpdateGitopsEngineInstanceMaxApplications(ctx, "does-not-exist", 5)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("Should update the Argo CD version of a GitopsEngineInstance", func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx := context.Background()
+		dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+		defer dbq.CloseDatabase()
+
+		_, _, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+		Expect(gitopsEngineInstance.Argocd_version).To(BeEmpty())
+
+		err = dbq.UpdateGitopsEngineInstanceArgoCDVersion(ctx, gitopsEngineInstance.Gitopsengineinstance_id, "v2.6.3")
+		Expect(err).To(BeNil())
+
+		err = dbq.GetGitopsEngineInstanceById(ctx, gitopsEngineInstance)
+		Expect(err).To(BeNil())
+		Expect(gitopsEngineInstance.Argocd_version).To(Equal("v2.6.3"))
+
+		err = dbq.UpdateGitopsEngineInstanceArgoCDVersion(ctx, gitopsEngineInstance.Gitopsengineinstance_id, strings.Repeat("a", db.GitopsEngineInstanceArgocdVersionLength+1))
+		Expect(err).ToNot(BeNil())
+	})
 })
@@ -180,7 +180,7 @@ var _ = Describe("Test to verify update/delete operations are not globally scope
 			applicationStateSecond = &db.ApplicationState{
 				Applicationstate_application_id: applicationSecond.Application_id,
 				Health:                          "Progressing",
-				Sync_Status:                     "Sync",
+				Sync_Status:                     "OutOfSync",
 				Resources:                       make([]byte, 10),
 				ReconciledState:                 "test-reconciledState",
 				SyncError:                       "test-sync-error",
@@ -193,7 +193,7 @@ var _ = Describe("Test to verify update/delete operations are not globally scope
 			err = dbq.GetApplicationStateById(ctx, applicationStateSecond)
 			Expect(err).To(BeNil())
 
-			Expect(applicationStateSecond.Sync_Status).Should(Equal("Sync"))
+			Expect(applicationStateSecond.Sync_Status).Should(Equal("OutOfSync"))
 			Expect(applicationStateFirst.Sync_Status).ShouldNot(Equal(applicationStateSecond.Sync_Status))
 
 			rowsAffected, err := dbq.DeleteApplicationStateById(ctx, applicationStateSecond.Applicationstate_application_id)
@@ -0,0 +1,346 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+func (dbq *PostgreSQLDatabaseQueries) CreateKeyStore(ctx context.Context, obj *KeyStore) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if dbq.allowTestUuids {
+		if IsEmpty(obj.KeyStoreID) {
+			obj.KeyStoreID = "test-" + generateUuid()
+		}
+	} else {
+		if !IsEmpty(obj.KeyStoreID) {
+			return fmt.Errorf("primary key should be empty")
+		}
+
+		obj.KeyStoreID = generateUuid()
+	}
+
+	if err := isEmptyValues("CreateKeyStore",
+		"ClusterUserID", obj.ClusterUserID,
+		"WrappedKey", obj.WrappedKey,
+		"MasterKeyID", obj.MasterKeyID); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	obj.Created_on = time.Now()
+
+	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
+	if err != nil {
+		return fmt.Errorf("error on inserting keystore: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) GetKeyStoreById(ctx context.Context, obj *KeyStore) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if IsEmpty(obj.KeyStoreID) {
+		return fmt.Errorf("keystore id is empty")
+	}
+
+	var dbResults []KeyStore
+
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("ks.keystore_id = ?", obj.KeyStoreID).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving GetKeyStoreById: %v", err)
+	}
+
+	if len(dbResults) >= 2 {
+		return fmt.Errorf("multiple results returned from GetKeyStoreById")
+	}
+
+	if len(dbResults) == 0 {
+		return NewResultNotFoundError("no results found for GetKeyStoreById")
+	}
+
+	*obj = dbResults[0]
+
+	return nil
+}
+
+// GetActiveKeyStoreForClusterUser returns the most recently created KeyStore row of the user, which contains the data
+// key that is used to encrypt new values.
+func (dbq *PostgreSQLDatabaseQueries) GetActiveKeyStoreForClusterUser(ctx context.Context, clusterUserID string, obj *KeyStore) error {
+
+	if err := validateQueryParams(clusterUserID, dbq); err != nil {
+		return err
+	}
+
+	var dbResults []KeyStore
+
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("ks.keystore_user_id = ?", clusterUserID).
+		Order("seq_id DESC").
+		Limit(1).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving GetActiveKeyStoreForClusterUser: %v", err)
+	}
+
+	if len(dbResults) == 0 {
+		return NewResultNotFoundError("no results found for GetActiveKeyStoreForClusterUser")
+	}
+
+	*obj = dbResults[0]
+
+	return nil
+}
+
+// ListKeyStoresForClusterUser returns all the KeyStore rows of the user, from oldest to newest.
+func (dbq *PostgreSQLDatabaseQueries) ListKeyStoresForClusterUser(ctx context.Context, clusterUserID string, keyStores *[]KeyStore) error {
+
+	if err := validateQueryParams(clusterUserID, dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(keyStores).
+		Where("ks.keystore_user_id = ?", clusterUserID).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListKeyStoresForClusterUser: %v", err)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) UpdateKeyStore(ctx context.Context, obj *KeyStore) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("UpdateKeyStore",
+		"KeyStoreID", obj.KeyStoreID,
+		"ClusterUserID", obj.ClusterUserID,
+		"WrappedKey", obj.WrappedKey,
+		"MasterKeyID", obj.MasterKeyID); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
+	if err != nil {
+		return fmt.Errorf("error on updating keystore: %v, %v", err, obj.KeyStoreID)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d, %v", result.RowsAffected(), obj.KeyStoreID)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) DeleteKeyStoreById(ctx context.Context, id string) (int, error) {
+
+	if err := validateQueryParams(id, dbq); err != nil {
+		return 0, err
+	}
+
+	result := &KeyStore{}
+
+	deleteResult, err := dbq.dbConnection.Model(result).
+		Where("ks.keystore_id = ?", id).
+		Context(ctx).
+		Delete()
+
+	if err != nil {
+		return 0, fmt.Errorf("error on deleting keystore: %v", err)
+	}
+
+	return deleteResult.RowsAffected(), nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllKeyStores(ctx context.Context, keyStores *[]KeyStore) error {
+
+	if err := validateUnsafeQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	return dbq.dbConnection.Model(keyStores).Order("seq_id ASC").Context(ctx).Select()
+}
+
+// RotateMasterKeyOfKeyStores rewraps the data keys of all KeyStore rows that were wrapped with a previous master key
+// (listed in the PreviousMasterKeysEnvVar env var), with the current master key. Returns the number of rows rewrapped.
+//
+// The values encrypted with the data keys are unchanged: once this function has returned, the previous master key
+// may be removed from the environment.
+func (dbq *PostgreSQLDatabaseQueries) RotateMasterKeyOfKeyStores(ctx context.Context) (int, error) {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return 0, err
+	}
+
+	masterKey := currentMasterKey()
+	if masterKey == "" {
+		return 0, fmt.Errorf("unable to rotate master key: %s is not set", MasterKeyEnvVar)
+	}
+	masterKeyID := getMasterKeyID(masterKey)
+
+	var keyStores []KeyStore
+	if err := dbq.dbConnection.Model(&keyStores).
+		Where("ks.master_key_id != ?", masterKeyID).
+		Context(ctx).
+		Select(); err != nil {
+
+		return 0, fmt.Errorf("error on retrieving KeyStores to rotate: %v", err)
+	}
+
+	rotated := 0
+	for i := range keyStores {
+		keyStore := keyStores[i]
+
+		dataKey, err := unwrapDataKey(keyStore)
+		if err != nil {
+			return rotated, err
+		}
+
+		if keyStore.WrappedKey, err = wrapDataKey(dataKey, masterKey); err != nil {
+			return rotated, fmt.Errorf("unable to wrap data key of KeyStore '%s': %w", keyStore.KeyStoreID, err)
+		}
+		keyStore.MasterKeyID = masterKeyID
+
+		if err := dbq.UpdateKeyStore(ctx, &keyStore); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+
+	return rotated, nil
+}
+
+// RotateDataKeyOfClusterUser creates a new data key for the user, re-encrypts the RepositoryCredentials of the user with
+// the new key, and then deletes the previous data keys of the user that are no longer used by any encrypted value.
+//
+// Note: a previous data key is only deleted if no RepositoryCredentials reference it; if a concurrent update of a
+// RepositoryCredentials row used the previous key, that key is kept, and will be deleted by the next rotation.
+func (dbq *PostgreSQLDatabaseQueries) RotateDataKeyOfClusterUser(ctx context.Context, clusterUserID string) error {
+
+	if err := validateQueryParams(clusterUserID, dbq); err != nil {
+		return err
+	}
+
+	cache := dataKeyCache{}
+
+	newKeyStore, _, err := dbq.createDataKey(ctx, clusterUserID, cache)
+	if err != nil {
+		return err
+	}
+
+	var repositoryCredentials []RepositoryCredentials
+	if err := dbq.dbConnection.Model(&repositoryCredentials).
+		Where("rc.repo_cred_user_id = ?", clusterUserID).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving RepositoryCredentials of user '%s': %v", clusterUserID, err)
+	}
+
+	for i := range repositoryCredentials {
+		repoCred := repositoryCredentials[i]
+
+		if err := dbq.decryptRepositoryCredentials(ctx, &repoCred, cache); err != nil {
+			return err
+		}
+
+		// UpdateRepositoryCredentials encrypts the values with the new (active) data key of the user
+		if err := dbq.UpdateRepositoryCredentials(ctx, &repoCred); err != nil {
+			return fmt.Errorf("unable to re-encrypt repository credentials '%s': %w", repoCred.RepositoryCredentialsID, err)
+		}
+	}
+
+	var keyStores []KeyStore
+	if err := dbq.ListKeyStoresForClusterUser(ctx, clusterUserID, &keyStores); err != nil {
+		return err
+	}
+
+	for _, keyStore := range keyStores {
+		if keyStore.KeyStoreID == newKeyStore.KeyStoreID {
+			continue
+		}
+
+		if err := dbq.deleteKeyStoreIfUnreferenced(ctx, keyStore.KeyStoreID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteKeyStoreIfUnreferenced deletes the KeyStore row, if no RepositoryCredentials values are encrypted with its
+// data key. The references are counted, and the row deleted, in a single transaction, with the KeyStore row locked:
+// a data key is thus never deleted based on a count that is out of date.
+func (dbq *PostgreSQLDatabaseQueries) deleteKeyStoreIfUnreferenced(ctx context.Context, keyStoreID string) error {
+
+	return dbq.dbConnection.RunInTransaction(ctx, func(tx *pg.Tx) error {
+
+		keyStore := KeyStore{KeyStoreID: keyStoreID}
+		if err := tx.Model(&keyStore).WherePK().For("UPDATE").Context(ctx).Select(); err != nil {
+			if IsResultNotFoundError(err) {
+				// Already deleted
+				return nil
+			}
+			return fmt.Errorf("error on locking KeyStore '%s': %v", keyStoreID, err)
+		}
+
+		encryptedValuePattern := encryptedValuePrefix + keyStoreID + ":%"
+
+		references, err := tx.Model(&RepositoryCredentials{}).
+			Where("rc.repo_cred_pass LIKE ? OR rc.repo_cred_ssh LIKE ?", encryptedValuePattern, encryptedValuePattern).
+			Context(ctx).
+			Count()
+		if err != nil {
+			return fmt.Errorf("error on counting references to KeyStore '%s': %v", keyStoreID, err)
+		}
+
+		if references > 0 {
+			return nil
+		}
+
+		if _, err := tx.Model(&keyStore).WherePK().Context(ctx).Delete(); err != nil {
+			return fmt.Errorf("error on deleting KeyStore '%s': %v", keyStoreID, err)
+		}
+
+		return nil
+	})
+}
+
+func (obj *KeyStore) Dispose(ctx context.Context, dbq DatabaseQueries) error {
+	if dbq == nil {
+		return fmt.Errorf("missing database interface in KeyStore dispose")
+	}
+
+	_, err := dbq.DeleteKeyStoreById(ctx, obj.KeyStoreID)
+	return err
+}
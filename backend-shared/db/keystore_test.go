@@ -0,0 +1,313 @@
+package db_test
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("KeyStore Tests", func() {
+
+	const (
+		masterKey         = "test-master-key"
+		previousMasterKey = "test-previous-master-key"
+	)
+
+	var (
+		err                  error
+		ctx                  context.Context
+		dbq                  db.AllDatabaseQueries
+		clusterUser          *db.ClusterUser
+		otherClusterUser     *db.ClusterUser
+		gitopsEngineInstance *db.GitopsEngineInstance
+	)
+
+	// getRawRepositoryCredentials returns the RepositoryCredentials row as it is stored in the database, without decryption
+	getRawRepositoryCredentials := func(id string) db.RepositoryCredentials {
+		dbConn, err := db.ConnectToDatabaseWithPort(true, db.DEFAULT_PORT)
+		Expect(err).To(BeNil())
+		defer dbConn.Close()
+
+		raw := db.RepositoryCredentials{RepositoryCredentialsID: id}
+		Expect(dbConn.Model(&raw).WherePK().Context(ctx).Select()).To(Succeed())
+		return raw
+	}
+
+	newRepositoryCredentials := func(id string, user *db.ClusterUser) db.RepositoryCredentials {
+		return db.RepositoryCredentials{
+			RepositoryCredentialsID: id,
+			UserID:                  user.Clusteruser_id,
+			PrivateURL:              "https://test-private-url",
+			AuthUsername:            "test-auth-username",
+			AuthPassword:            "test-auth-password",
+			AuthSSHKey:              "test-auth-ssh-key",
+			SecretObj:               "test-secret-obj",
+			EngineClusterID:         gitopsEngineInstance.Gitopsengineinstance_id,
+		}
+	}
+
+	BeforeEach(func() {
+		Expect(os.Setenv(db.MasterKeyEnvVar, masterKey)).To(Succeed())
+		Expect(os.Unsetenv(db.PreviousMasterKeysEnvVar)).To(Succeed())
+
+		err = db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		_, _, _, gitopsEngineInstance, _, err = db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		clusterUser = &db.ClusterUser{
+			Clusteruser_id: "test-keystore-user-id",
+			User_name:      "test-keystore-user",
+		}
+		Expect(dbq.CreateClusterUser(ctx, clusterUser)).To(Succeed())
+
+		otherClusterUser = &db.ClusterUser{
+			Clusteruser_id: "test-keystore-other-user-id",
+			User_name:      "test-keystore-other-user",
+		}
+		Expect(dbq.CreateClusterUser(ctx, otherClusterUser)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		// The rows are deleted here, rather than by SetupForTestingDBGinkgo, as the rows of a test that deleted a data key
+		// (or that ran with a different master key) cannot be decrypted, and are thus not listed.
+		for _, id := range []string{"test-keystore-repo-cred", "test-keystore-other-repo-cred"} {
+			_, err := dbq.DeleteRepositoryCredentialsByID(ctx, id)
+			Expect(err).To(BeNil())
+		}
+
+		Expect(os.Unsetenv(db.MasterKeyEnvVar)).To(Succeed())
+		Expect(os.Unsetenv(db.PreviousMasterKeysEnvVar)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		// Remove the rows that were encrypted by the test, while the master key is still set
+		Expect(db.SetupForTestingDBGinkgo()).To(Succeed())
+
+		Expect(os.Unsetenv(db.MasterKeyEnvVar)).To(Succeed())
+		Expect(os.Unsetenv(db.PreviousMasterKeysEnvVar)).To(Succeed())
+
+		dbq.CloseDatabase()
+	})
+
+	It("should encrypt the password and SSH key of RepositoryCredentials with a data key of the user", func() {
+
+		repoCred := newRepositoryCredentials("test-keystore-repo-cred", clusterUser)
+		Expect(dbq.CreateRepositoryCredentials(ctx, &repoCred)).To(Succeed())
+
+		By("verifying the caller's object still contains the plaintext values")
+		Expect(repoCred.AuthPassword).To(Equal("test-auth-password"))
+		Expect(repoCred.AuthSSHKey).To(Equal("test-auth-ssh-key"))
+
+		By("verifying the values are encrypted in the database, with the active data key of the user")
+		var keyStore db.KeyStore
+		Expect(dbq.GetActiveKeyStoreForClusterUser(ctx, clusterUser.Clusteruser_id, &keyStore)).To(Succeed())
+
+		raw := getRawRepositoryCredentials(repoCred.RepositoryCredentialsID)
+		Expect(raw.AuthPassword).To(HavePrefix("enc:v1:" + keyStore.KeyStoreID + ":"))
+		Expect(raw.AuthSSHKey).To(HavePrefix("enc:v1:" + keyStore.KeyStoreID + ":"))
+		Expect(raw.AuthPassword).ToNot(ContainSubstring("test-auth-password"))
+		Expect(raw.AuthUsername).To(Equal("test-auth-username"))
+
+		By("verifying the data key is not stored in plaintext")
+		Expect(keyStore.WrappedKey).ToNot(BeEmpty())
+		Expect(keyStore.MasterKeyID).ToNot(ContainSubstring(masterKey))
+
+		By("verifying the values are decrypted when read")
+		fetched, err := dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).To(BeNil())
+		Expect(fetched.AuthPassword).To(Equal("test-auth-password"))
+		Expect(fetched.AuthSSHKey).To(Equal("test-auth-ssh-key"))
+
+		var batch []db.RepositoryCredentials
+		Expect(dbq.GetRepositoryCredentialsBatch(ctx, &batch, 1000, 0)).To(Succeed())
+		Expect(batch).To(ContainElement(fetched))
+
+		By("verifying the values are re-encrypted on update")
+		fetched.AuthPassword = "test-updated-password"
+		Expect(dbq.UpdateRepositoryCredentials(ctx, &fetched)).To(Succeed())
+		Expect(fetched.AuthPassword).To(Equal("test-updated-password"))
+
+		raw = getRawRepositoryCredentials(repoCred.RepositoryCredentialsID)
+		Expect(raw.AuthPassword).To(HavePrefix("enc:v1:" + keyStore.KeyStoreID + ":"))
+
+		fetched, err = dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).To(BeNil())
+		Expect(fetched.AuthPassword).To(Equal("test-updated-password"))
+
+		By("verifying the values cannot be read without the master key")
+		Expect(os.Unsetenv(db.MasterKeyEnvVar)).To(Succeed())
+		_, err = dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).ToNot(BeNil())
+		Expect(os.Setenv(db.MasterKeyEnvVar, masterKey)).To(Succeed())
+	})
+
+	It("should use a different data key for each user", func() {
+
+		repoCred := newRepositoryCredentials("test-keystore-repo-cred", clusterUser)
+		Expect(dbq.CreateRepositoryCredentials(ctx, &repoCred)).To(Succeed())
+
+		otherRepoCred := newRepositoryCredentials("test-keystore-other-repo-cred", otherClusterUser)
+		Expect(dbq.CreateRepositoryCredentials(ctx, &otherRepoCred)).To(Succeed())
+
+		var keyStore, otherKeyStore db.KeyStore
+		Expect(dbq.GetActiveKeyStoreForClusterUser(ctx, clusterUser.Clusteruser_id, &keyStore)).To(Succeed())
+		Expect(dbq.GetActiveKeyStoreForClusterUser(ctx, otherClusterUser.Clusteruser_id, &otherKeyStore)).To(Succeed())
+		Expect(keyStore.KeyStoreID).ToNot(Equal(otherKeyStore.KeyStoreID))
+		Expect(keyStore.WrappedKey).ToNot(Equal(otherKeyStore.WrappedKey))
+
+		By("verifying the values of one user cannot be read if the data key of that user is removed")
+		_, err = dbq.DeleteKeyStoreById(ctx, keyStore.KeyStoreID)
+		Expect(err).To(BeNil())
+
+		_, err = dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).ToNot(BeNil())
+
+		fetched, err := dbq.GetRepositoryCredentialsByID(ctx, otherRepoCred.RepositoryCredentialsID)
+		Expect(err).To(BeNil())
+		Expect(fetched.AuthPassword).To(Equal("test-auth-password"))
+
+		By("verifying the values that cannot be decrypted are skipped when listing, rather than failing the list")
+		var repositoryCredentials []db.RepositoryCredentials
+		Expect(dbq.UnsafeListAllRepositoryCredentials(ctx, &repositoryCredentials)).To(Succeed())
+
+		listedIDs := []string{}
+		for _, listed := range repositoryCredentials {
+			listedIDs = append(listedIDs, listed.RepositoryCredentialsID)
+		}
+		Expect(listedIDs).To(ContainElement(otherRepoCred.RepositoryCredentialsID))
+		Expect(listedIDs).ToNot(ContainElement(repoCred.RepositoryCredentialsID))
+	})
+
+	It("should return a max length error if a value does not fit in its column once it is encrypted", func() {
+
+		repoCred := newRepositoryCredentials("test-keystore-repo-cred", clusterUser)
+		repoCred.AuthSSHKey = strings.Repeat("a", db.RepositoryCredentialsRepoCredSshLength-100)
+
+		err := dbq.CreateRepositoryCredentials(ctx, &repoCred)
+		Expect(err).ToNot(BeNil())
+		Expect(db.IsMaxLengthError(err)).To(BeTrue())
+
+		By("verifying a value that fits once encrypted is accepted")
+		repoCred.AuthSSHKey = strings.Repeat("a", 1024)
+		Expect(dbq.CreateRepositoryCredentials(ctx, &repoCred)).To(Succeed())
+
+		fetched, err := dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).To(BeNil())
+		Expect(fetched.AuthSSHKey).To(Equal(repoCred.AuthSSHKey))
+	})
+
+	It("should read values that were stored before encryption was enabled", func() {
+
+		Expect(os.Unsetenv(db.MasterKeyEnvVar)).To(Succeed())
+
+		repoCred := newRepositoryCredentials("test-keystore-repo-cred", clusterUser)
+		Expect(dbq.CreateRepositoryCredentials(ctx, &repoCred)).To(Succeed())
+		Expect(getRawRepositoryCredentials(repoCred.RepositoryCredentialsID).AuthPassword).To(Equal("test-auth-password"))
+
+		Expect(os.Setenv(db.MasterKeyEnvVar, masterKey)).To(Succeed())
+
+		fetched, err := dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).To(BeNil())
+		Expect(fetched.AuthPassword).To(Equal("test-auth-password"))
+
+		Expect(dbq.UpdateRepositoryCredentials(ctx, &fetched)).To(Succeed())
+		Expect(getRawRepositoryCredentials(repoCred.RepositoryCredentialsID).AuthPassword).To(HavePrefix("enc:v1:"))
+	})
+
+	It("should rotate the data key of a user, and remove the previous data key", func() {
+
+		repoCred := newRepositoryCredentials("test-keystore-repo-cred", clusterUser)
+		Expect(dbq.CreateRepositoryCredentials(ctx, &repoCred)).To(Succeed())
+
+		otherRepoCred := newRepositoryCredentials("test-keystore-other-repo-cred", otherClusterUser)
+		Expect(dbq.CreateRepositoryCredentials(ctx, &otherRepoCred)).To(Succeed())
+
+		var previousKeyStore, otherKeyStore db.KeyStore
+		Expect(dbq.GetActiveKeyStoreForClusterUser(ctx, clusterUser.Clusteruser_id, &previousKeyStore)).To(Succeed())
+		Expect(dbq.GetActiveKeyStoreForClusterUser(ctx, otherClusterUser.Clusteruser_id, &otherKeyStore)).To(Succeed())
+
+		Expect(dbq.RotateDataKeyOfClusterUser(ctx, clusterUser.Clusteruser_id)).To(Succeed())
+
+		var keyStores []db.KeyStore
+		Expect(dbq.ListKeyStoresForClusterUser(ctx, clusterUser.Clusteruser_id, &keyStores)).To(Succeed())
+		Expect(keyStores).To(HaveLen(1))
+		Expect(keyStores[0].KeyStoreID).ToNot(Equal(previousKeyStore.KeyStoreID))
+
+		raw := getRawRepositoryCredentials(repoCred.RepositoryCredentialsID)
+		Expect(raw.AuthPassword).To(HavePrefix("enc:v1:" + keyStores[0].KeyStoreID + ":"))
+		Expect(raw.AuthSSHKey).To(HavePrefix("enc:v1:" + keyStores[0].KeyStoreID + ":"))
+
+		fetched, err := dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).To(BeNil())
+		Expect(fetched.AuthPassword).To(Equal("test-auth-password"))
+		Expect(fetched.AuthSSHKey).To(Equal("test-auth-ssh-key"))
+
+		By("verifying the data key of the other user is unchanged")
+		Expect(dbq.GetKeyStoreById(ctx, &db.KeyStore{KeyStoreID: otherKeyStore.KeyStoreID})).To(Succeed())
+		Expect(getRawRepositoryCredentials(otherRepoCred.RepositoryCredentialsID).AuthPassword).
+			To(HavePrefix("enc:v1:" + otherKeyStore.KeyStoreID + ":"))
+	})
+
+	It("should rewrap the data keys with the current master key, when the master key is rotated", func() {
+
+		Expect(os.Setenv(db.MasterKeyEnvVar, previousMasterKey)).To(Succeed())
+
+		repoCred := newRepositoryCredentials("test-keystore-repo-cred", clusterUser)
+		Expect(dbq.CreateRepositoryCredentials(ctx, &repoCred)).To(Succeed())
+		rawBeforeRotation := getRawRepositoryCredentials(repoCred.RepositoryCredentialsID)
+
+		var keyStore db.KeyStore
+		Expect(dbq.GetActiveKeyStoreForClusterUser(ctx, clusterUser.Clusteruser_id, &keyStore)).To(Succeed())
+
+		By("setting the new master key, and moving the previous master key to the list of previous keys")
+		Expect(os.Setenv(db.MasterKeyEnvVar, masterKey)).To(Succeed())
+		Expect(os.Setenv(db.PreviousMasterKeysEnvVar, "some-other-key, "+previousMasterKey)).To(Succeed())
+
+		fetched, err := dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).To(BeNil())
+		Expect(fetched.AuthPassword).To(Equal("test-auth-password"))
+
+		rotated, err := dbq.RotateMasterKeyOfKeyStores(ctx)
+		Expect(err).To(BeNil())
+		Expect(rotated).To(BeNumerically(">=", 1))
+
+		rotatedKeyStore := db.KeyStore{KeyStoreID: keyStore.KeyStoreID}
+		Expect(dbq.GetKeyStoreById(ctx, &rotatedKeyStore)).To(Succeed())
+		Expect(rotatedKeyStore.MasterKeyID).ToNot(Equal(keyStore.MasterKeyID))
+		Expect(rotatedKeyStore.WrappedKey).ToNot(Equal(keyStore.WrappedKey))
+
+		By("verifying the encrypted values are unchanged, and readable without the previous master key")
+		Expect(getRawRepositoryCredentials(repoCred.RepositoryCredentialsID).AuthPassword).To(Equal(rawBeforeRotation.AuthPassword))
+
+		Expect(os.Unsetenv(db.PreviousMasterKeysEnvVar)).To(Succeed())
+
+		fetched, err = dbq.GetRepositoryCredentialsByID(ctx, repoCred.RepositoryCredentialsID)
+		Expect(err).To(BeNil())
+		Expect(fetched.AuthPassword).To(Equal("test-auth-password"))
+		Expect(fetched.AuthSSHKey).To(Equal("test-auth-ssh-key"))
+
+		By("verifying no data keys remain that are wrapped with a previous master key")
+		rotated, err = dbq.RotateMasterKeyOfKeyStores(ctx)
+		Expect(err).To(BeNil())
+		Expect(rotated).To(Equal(0))
+	})
+
+	It("should return an error when rotating the master key, if no master key is set", func() {
+		Expect(os.Unsetenv(db.MasterKeyEnvVar)).To(Succeed())
+
+		_, err := dbq.RotateMasterKeyOfKeyStores(ctx)
+		Expect(err).ToNot(BeNil())
+		Expect(strings.Contains(err.Error(), db.MasterKeyEnvVar)).To(BeTrue())
+	})
+})
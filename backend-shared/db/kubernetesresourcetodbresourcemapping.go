@@ -35,6 +35,7 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateKubernetesResourceUIDForKubernetesTo
 		Where("ktdbrm.kubernetes_resource_type = ?", obj.KubernetesResourceType).
 		Where("ktdbrm.db_relation_key = ?", obj.DBRelationKey).
 		Where("ktdbrm.db_relation_type = ?", obj.DBRelationType).
+		Context(ctx).
 		Update()
 	if err != nil {
 		return fmt.Errorf("error on updating KubernetesToDBResourceMapping: %v, %s", err, obj.asString())
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 )
 
@@ -82,6 +83,44 @@ func (dbq *PostgreSQLDatabaseQueries) ListManagedEnvironmentForClusterCredential
 	return nil
 }
 
+func (dbq *PostgreSQLDatabaseQueries) ListManagedEnvironmentsByClusterLabelsAndOwnerId(ctx context.Context, clusterLabels map[string]string, ownerId string, managedEnvironments *[]ManagedEnvironment) error {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if IsEmpty(ownerId) {
+		return fmt.Errorf("owner id for ListManagedEnvironmentsByClusterLabelsAndOwnerId is empty")
+	}
+
+	if clusterLabels == nil {
+		clusterLabels = map[string]string{}
+	}
+
+	clusterLabelsJSON, err := json.Marshal(clusterLabels)
+	if err != nil {
+		return fmt.Errorf("unable to marshal cluster labels: %v", err)
+	}
+
+	var result []ManagedEnvironment
+
+	// A sub-query is used (rather than a join), so that a managed environment is only returned once, even if the owner
+	// has access to it via multiple GitOpsEngineInstances.
+	if err := dbq.dbConnection.Model(&result).
+		Where("COALESCE(me.cluster_labels, '{}'::jsonb) @> ?::jsonb", string(clusterLabelsJSON)).
+		Where("EXISTS (SELECT 1 FROM clusteraccess AS ca WHERE ca.clusteraccess_managed_environment_id = me.managedenvironment_id AND ca.clusteraccess_user_id = ?)", ownerId).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ManagedEnvironments by cluster labels: %v", err)
+	}
+
+	*managedEnvironments = result
+
+	return nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) GetManagedEnvironmentById(ctx context.Context, managedEnvironment *ManagedEnvironment) error {
 
 	if err := validateQueryParamsEntity(managedEnvironment, dbq); err != nil {
@@ -212,7 +251,7 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateManagedEnvironment(ctx context.Conte
 		return err
 	}
 
-	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
+	result, err := dbq.dbConnection.Model(obj).WherePK().Value("version", "version + 1").Returning("version").Context(ctx).Update()
 	if err != nil {
 		return fmt.Errorf("error on updating operation: %v, %v", err, obj.Managedenvironment_id)
 	}
@@ -225,6 +264,57 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateManagedEnvironment(ctx context.Conte
 
 }
 
+// CompareAndSwapManagedEnvironment updates the ManagedEnvironment row, but only if the version of the row in the database
+// is still equal to obj.Version (that is, the row has not been updated since obj was read). On success, obj.Version is
+// set to the new version of the row.
+//
+// Returns an error matched by IsConflictError if the row was updated by another caller: the caller should re-read the
+// row, re-apply its changes, and try again. Returns an error matched by IsResultNotFoundError if the row does not exist.
+func (dbq *PostgreSQLDatabaseQueries) CompareAndSwapManagedEnvironment(ctx context.Context, obj *ManagedEnvironment) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("CompareAndSwapManagedEnvironment",
+		"Managedenvironment_id", obj.Managedenvironment_id,
+		"Clustercredentials_id", obj.Clustercredentials_id); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	expectedVersion := obj.Version
+	obj.Version = expectedVersion + 1
+
+	result, err := dbq.dbConnection.Model(obj).WherePK().Where("version = ?", expectedVersion).Context(ctx).Update()
+	if err != nil {
+		obj.Version = expectedVersion
+		return fmt.Errorf("error on compare-and-swap of managed environment: %v, %v", err, obj.Managedenvironment_id)
+	}
+
+	if result.RowsAffected() == 1 {
+		return nil
+	}
+	obj.Version = expectedVersion
+
+	// No rows were updated: either the row doesn't exist, or its version has changed since it was read.
+	exists, err := dbq.dbConnection.Model(&ManagedEnvironment{}).
+		Where("managedenvironment_id = ?", obj.Managedenvironment_id).
+		Context(ctx).
+		Exists()
+	if err != nil {
+		return fmt.Errorf("error on checking existence of managed environment: %v, %v", err, obj.Managedenvironment_id)
+	}
+	if !exists {
+		return NewResultNotFoundError(fmt.Sprintf("managed environment '%s'", obj.Managedenvironment_id))
+	}
+
+	return fmt.Errorf("%w: managed environment '%s' is no longer at version %d", ErrConflict, obj.Managedenvironment_id, expectedVersion)
+}
+
 func (obj *ManagedEnvironment) Dispose(ctx context.Context, dbq DatabaseQueries) error {
 	if dbq == nil {
 		return fmt.Errorf("missing database interface in ManagedEnvironment dispose")
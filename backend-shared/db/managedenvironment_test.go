@@ -75,6 +75,65 @@ var _ = Describe("Managedenvironment Test", func() {
 
 	})
 
+	It("Should only update a ManagedEnvironment with CompareAndSwapManagedEnvironment if its version is unchanged", func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx := context.Background()
+		dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+		defer dbq.CloseDatabase()
+
+		clusterCredentials := db.ClusterCredentials{
+			Clustercredentials_cred_id:  "test-cluster-creds-cas",
+			Host:                        "host",
+			Kube_config:                 "kube-config",
+			Kube_config_context:         "kube-config-context",
+			Serviceaccount_bearer_token: "serviceaccount_bearer_token",
+			Serviceaccount_ns:           "Serviceaccount_ns",
+		}
+		err = dbq.CreateClusterCredentials(ctx, &clusterCredentials)
+		Expect(err).To(BeNil())
+
+		managedEnvironment := db.ManagedEnvironment{
+			Managedenvironment_id: "test-managed-env-cas",
+			Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+			Name:                  "my-env",
+		}
+		err = dbq.CreateManagedEnvironment(ctx, &managedEnvironment)
+		Expect(err).To(BeNil())
+
+		first := db.ManagedEnvironment{Managedenvironment_id: managedEnvironment.Managedenvironment_id}
+		Expect(dbq.GetManagedEnvironmentById(ctx, &first)).To(Succeed())
+		second := db.ManagedEnvironment{Managedenvironment_id: managedEnvironment.Managedenvironment_id}
+		Expect(dbq.GetManagedEnvironmentById(ctx, &second)).To(Succeed())
+
+		first.Name = "first-name"
+		err = dbq.CompareAndSwapManagedEnvironment(ctx, &first)
+		Expect(err).To(BeNil())
+		Expect(first.Version).To(Equal(int64(1)))
+
+		second.Name = "second-name"
+		err = dbq.CompareAndSwapManagedEnvironment(ctx, &second)
+		Expect(db.IsConflictError(err)).To(BeTrue())
+
+		fromDB := db.ManagedEnvironment{Managedenvironment_id: managedEnvironment.Managedenvironment_id}
+		Expect(dbq.GetManagedEnvironmentById(ctx, &fromDB)).To(Succeed())
+		Expect(fromDB.Name).To(Equal("first-name"))
+		Expect(fromDB.Version).To(Equal(int64(1)))
+
+		err = dbq.UpdateManagedEnvironment(ctx, &fromDB)
+		Expect(err).To(BeNil())
+		Expect(fromDB.Version).To(Equal(int64(2)))
+
+		rowsAffected, err := dbq.DeleteManagedEnvironmentById(ctx, managedEnvironment.Managedenvironment_id)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		err = dbq.CompareAndSwapManagedEnvironment(ctx, &fromDB)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+	})
+
 	It("Should List all the ManagedEnvironment entries", func() {
 
 		err := db.SetupForTestingDBGinkgo()
@@ -121,4 +180,92 @@ var _ = Describe("Managedenvironment Test", func() {
 
 	})
 
+	It("Should List the ManagedEnvironment entries that have the given cluster labels, that the owner has access to", func() {
+
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx := context.Background()
+		dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+		defer dbq.CloseDatabase()
+
+		clusterCredentials, _, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		testClusterUser := &db.ClusterUser{
+			Clusteruser_id: "test-user-1",
+			User_name:      "test-user-1",
+		}
+		err = dbq.CreateClusterUser(ctx, testClusterUser)
+		Expect(err).To(BeNil())
+
+		managedEnvironments := []db.ManagedEnvironment{
+			{
+				Managedenvironment_id: "test-managed-env-prod-us",
+				Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+				Name:                  "prod us env",
+				Cluster_labels:        map[string]string{"region": "us-east-1", "cloud": "aws", "tier": "production"},
+			},
+			{
+				Managedenvironment_id: "test-managed-env-prod-eu",
+				Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+				Name:                  "prod eu env",
+				Cluster_labels:        map[string]string{"region": "eu-west-1", "cloud": "aws", "tier": "production"},
+			},
+			{
+				Managedenvironment_id: "test-managed-env-no-labels",
+				Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+				Name:                  "env without labels",
+			},
+		}
+		for i := range managedEnvironments {
+			err = dbq.CreateManagedEnvironment(ctx, &managedEnvironments[i])
+			Expect(err).To(BeNil())
+
+			err = dbq.CreateClusterAccess(ctx, &db.ClusterAccess{
+				Clusteraccess_user_id:                   testClusterUser.Clusteruser_id,
+				Clusteraccess_managed_environment_id:    managedEnvironments[i].Managedenvironment_id,
+				Clusteraccess_gitops_engine_instance_id: gitopsEngineInstance.Gitopsengineinstance_id,
+			})
+			Expect(err).To(BeNil())
+		}
+
+		listIDs := func(clusterLabels map[string]string, ownerId string) []string {
+			var result []db.ManagedEnvironment
+			err := dbq.ListManagedEnvironmentsByClusterLabelsAndOwnerId(ctx, clusterLabels, ownerId, &result)
+			Expect(err).To(BeNil())
+
+			ids := []string{}
+			for _, managedEnv := range result {
+				ids = append(ids, managedEnv.Managedenvironment_id)
+			}
+			return ids
+		}
+
+		By("listing by a label that is shared by multiple managed environments")
+		Expect(listIDs(map[string]string{"tier": "production"}, testClusterUser.Clusteruser_id)).
+			To(ConsistOf("test-managed-env-prod-us", "test-managed-env-prod-eu"))
+
+		By("listing by multiple labels, which must all match")
+		Expect(listIDs(map[string]string{"tier": "production", "region": "eu-west-1"}, testClusterUser.Clusteruser_id)).
+			To(ConsistOf("test-managed-env-prod-eu"))
+
+		By("listing by a label value that doesn't match any managed environment")
+		Expect(listIDs(map[string]string{"cloud": "gcp"}, testClusterUser.Clusteruser_id)).To(BeEmpty())
+
+		By("listing without labels, which should return all the managed environments of the owner")
+		Expect(listIDs(nil, testClusterUser.Clusteruser_id)).
+			To(ConsistOf("test-managed-env-prod-us", "test-managed-env-prod-eu", "test-managed-env-no-labels"))
+
+		By("listing as a user that doesn't have access to the managed environments")
+		Expect(listIDs(map[string]string{"tier": "production"}, "test-user-without-access")).To(BeEmpty())
+
+		By("ensuring the cluster labels are returned with the managed environment")
+		managedEnv := db.ManagedEnvironment{Managedenvironment_id: "test-managed-env-prod-us"}
+		err = dbq.GetManagedEnvironmentById(ctx, &managedEnv)
+		Expect(err).To(BeNil())
+		Expect(managedEnv.Cluster_labels).To(Equal(managedEnvironments[0].Cluster_labels))
+	})
+
 })
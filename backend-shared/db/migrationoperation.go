@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (dbq *PostgreSQLDatabaseQueries) CreateMigrationOperation(ctx context.Context, obj *MigrationOperation) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("CreateMigrationOperation",
+		"NamespaceUID", obj.NamespaceUID,
+		"NamespaceName", obj.NamespaceName,
+		"SourceGitopsEngineInstanceID", obj.SourceGitopsEngineInstanceID,
+		"TargetGitopsEngineInstanceID", obj.TargetGitopsEngineInstanceID,
+		"Step", obj.Step); err != nil {
+		return err
+	}
+
+	if obj.SourceGitopsEngineInstanceID == obj.TargetGitopsEngineInstanceID {
+		return fmt.Errorf("source and target GitopsEngineInstance of a migration operation must be different: %s", obj.SourceGitopsEngineInstanceID)
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	obj.Created_on = time.Now()
+	obj.Step_started_on = obj.Created_on
+
+	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
+	if err != nil {
+		return fmt.Errorf("error on inserting migration operation: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) GetMigrationOperationByNamespaceUID(ctx context.Context, obj *MigrationOperation) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if IsEmpty(obj.NamespaceUID) {
+		return fmt.Errorf("migration operation namespace uid is empty")
+	}
+
+	var dbResults []MigrationOperation
+
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("mo.namespace_uid = ?", obj.NamespaceUID).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving GetMigrationOperationByNamespaceUID: %v", err)
+	}
+
+	if len(dbResults) >= 2 {
+		return fmt.Errorf("multiple results returned from GetMigrationOperationByNamespaceUID")
+	}
+
+	if len(dbResults) == 0 {
+		return NewResultNotFoundError("no results found for GetMigrationOperationByNamespaceUID")
+	}
+
+	*obj = dbResults[0]
+
+	return nil
+}
+
+// ListMigrationOperations returns all the MigrationOperation rows, that is, every namespace whose Applications are in the
+// process of being migrated, from the oldest to the newest.
+func (dbq *PostgreSQLDatabaseQueries) ListMigrationOperations(ctx context.Context, migrationOperations *[]MigrationOperation) error {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(migrationOperations).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListMigrationOperations: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateMigrationOperation updates the step of the migration operation: the source and target GitopsEngineInstances of a
+// migration operation do not change.
+func (dbq *PostgreSQLDatabaseQueries) UpdateMigrationOperation(ctx context.Context, obj *MigrationOperation) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("UpdateMigrationOperation",
+		"NamespaceUID", obj.NamespaceUID,
+		"Step", obj.Step); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	obj.Step_started_on = time.Now()
+
+	result, err := dbq.dbConnection.Model(obj).
+		Column("step", "step_started_on").
+		WherePK().
+		Context(ctx).
+		Update()
+	if err != nil {
+		return fmt.Errorf("error on updating migration operation: %v, %v", err, obj.NamespaceUID)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d, %v", result.RowsAffected(), obj.NamespaceUID)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) DeleteMigrationOperationByNamespaceUID(ctx context.Context, namespaceUID string) (int, error) {
+
+	if err := validateQueryParams(namespaceUID, dbq); err != nil {
+		return 0, err
+	}
+
+	result := &MigrationOperation{}
+
+	deleteResult, err := dbq.dbConnection.Model(result).
+		Where("mo.namespace_uid = ?", namespaceUID).
+		Context(ctx).
+		Delete()
+
+	if err != nil {
+		return 0, fmt.Errorf("error on deleting migration operation: %v", err)
+	}
+
+	return deleteResult.RowsAffected(), nil
+}
+
+var _ DisposableResource = &MigrationOperation{}
+
+func (obj *MigrationOperation) Dispose(ctx context.Context, dbq DatabaseQueries) error {
+	if dbq == nil {
+		return fmt.Errorf("missing database interface in MigrationOperation dispose")
+	}
+
+	_, err := dbq.DeleteMigrationOperationByNamespaceUID(ctx, obj.NamespaceUID)
+	return err
+}
+
+// GetAsLogKeyValues returns an []interface that can be passed to log.Info(...).
+// e.g. log.Info("Creating database resource", obj.GetAsLogKeyValues()...)
+func (obj *MigrationOperation) GetAsLogKeyValues() []interface{} {
+	if obj == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{"namespaceUID", obj.NamespaceUID,
+		"namespaceName", obj.NamespaceName,
+		"sourceGitopsEngineInstanceID", obj.SourceGitopsEngineInstanceID,
+		"targetGitopsEngineInstanceID", obj.TargetGitopsEngineInstanceID,
+		"step", obj.Step}
+}
@@ -0,0 +1,111 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("MigrationOperation Tests", func() {
+
+	var (
+		ctx            context.Context
+		dbq            db.AllDatabaseQueries
+		sourceInstance *db.GitopsEngineInstance
+		targetInstance *db.GitopsEngineInstance
+	)
+
+	BeforeEach(func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+
+		var engineCluster *db.GitopsEngineCluster
+		_, _, engineCluster, sourceInstance, _, err = db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		targetInstance = &db.GitopsEngineInstance{
+			Gitopsengineinstance_id: "test-target-instance-id",
+			Namespace_name:          "gitops-service-argocd-2",
+			Namespace_uid:           "test-target-instance-namespace-uid",
+			EngineCluster_id:        engineCluster.Gitopsenginecluster_id,
+		}
+		Expect(dbq.CreateGitopsEngineInstance(ctx, targetInstance)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		dbq.CloseDatabase()
+	})
+
+	It("should create, get, update, list and delete MigrationOperations", func() {
+
+		migrationOperation := db.MigrationOperation{
+			NamespaceUID:                 "test-namespace-uid",
+			NamespaceName:                "test-namespace",
+			SourceGitopsEngineInstanceID: sourceInstance.Gitopsengineinstance_id,
+			TargetGitopsEngineInstanceID: targetInstance.Gitopsengineinstance_id,
+			Step:                         "createontarget",
+		}
+		err := dbq.CreateMigrationOperation(ctx, &migrationOperation)
+		Expect(err).To(BeNil())
+
+		By("verifying a migration operation of the same namespace cannot be created")
+		duplicate := migrationOperation
+		err = dbq.CreateMigrationOperation(ctx, &duplicate)
+		Expect(err).ToNot(BeNil())
+
+		fetchRow := db.MigrationOperation{NamespaceUID: migrationOperation.NamespaceUID}
+		err = dbq.GetMigrationOperationByNamespaceUID(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.NamespaceName).To(Equal("test-namespace"))
+		Expect(fetchRow.SourceGitopsEngineInstanceID).To(Equal(sourceInstance.Gitopsengineinstance_id))
+		Expect(fetchRow.TargetGitopsEngineInstanceID).To(Equal(targetInstance.Gitopsengineinstance_id))
+		Expect(fetchRow.Step).To(Equal("createontarget"))
+		Expect(fetchRow.Step_started_on.IsZero()).To(BeFalse())
+
+		By("updating the step, which also updates the time the step started")
+		previousStepStartedOn := fetchRow.Step_started_on
+		fetchRow.Step = "deletefromsource"
+		err = dbq.UpdateMigrationOperation(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+
+		var migrationOperations []db.MigrationOperation
+		err = dbq.ListMigrationOperations(ctx, &migrationOperations)
+		Expect(err).To(BeNil())
+		Expect(migrationOperations).To(HaveLen(1))
+		Expect(migrationOperations[0].Step).To(Equal("deletefromsource"))
+		Expect(migrationOperations[0].Step_started_on.Before(previousStepStartedOn)).To(BeFalse())
+
+		By("verifying the source and target instances must be different")
+		sameInstance := db.MigrationOperation{
+			NamespaceUID:                 "test-other-namespace-uid",
+			NamespaceName:                "test-other-namespace",
+			SourceGitopsEngineInstanceID: sourceInstance.Gitopsengineinstance_id,
+			TargetGitopsEngineInstanceID: sourceInstance.Gitopsengineinstance_id,
+			Step:                         "createontarget",
+		}
+		err = dbq.CreateMigrationOperation(ctx, &sameInstance)
+		Expect(err).ToNot(BeNil())
+
+		By("verifying the field lengths are validated")
+		tooLong := sameInstance
+		tooLong.TargetGitopsEngineInstanceID = targetInstance.Gitopsengineinstance_id
+		tooLong.NamespaceName = strings.Repeat("abc", 30)
+		err = dbq.CreateMigrationOperation(ctx, &tooLong)
+		Expect(db.IsMaxLengthError(err)).To(BeTrue())
+
+		rowsAffected, err := dbq.DeleteMigrationOperationByNamespaceUID(ctx, migrationOperation.NamespaceUID)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		err = dbq.GetMigrationOperationByNamespaceUID(ctx, &fetchRow)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+	})
+})
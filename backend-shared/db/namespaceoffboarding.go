@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (dbq *PostgreSQLDatabaseQueries) CreateNamespaceOffboarding(ctx context.Context, obj *NamespaceOffboarding) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("CreateNamespaceOffboarding",
+		"NamespaceUID", obj.NamespaceUID,
+		"NamespaceName", obj.NamespaceName,
+		"Step", obj.Step); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	obj.Created_on = time.Now()
+
+	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
+	if err != nil {
+		return fmt.Errorf("error on inserting namespace offboarding: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) GetNamespaceOffboardingByNamespaceUID(ctx context.Context, obj *NamespaceOffboarding) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if IsEmpty(obj.NamespaceUID) {
+		return fmt.Errorf("namespace offboarding namespace uid is empty")
+	}
+
+	var dbResults []NamespaceOffboarding
+
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("nso.namespace_uid = ?", obj.NamespaceUID).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving GetNamespaceOffboardingByNamespaceUID: %v", err)
+	}
+
+	if len(dbResults) >= 2 {
+		return fmt.Errorf("multiple results returned from GetNamespaceOffboardingByNamespaceUID")
+	}
+
+	if len(dbResults) == 0 {
+		return NewResultNotFoundError("no results found for GetNamespaceOffboardingByNamespaceUID")
+	}
+
+	*obj = dbResults[0]
+
+	return nil
+}
+
+// ListNamespaceOffboardings returns all the NamespaceOffboarding rows, that is, every namespace that is in the process
+// of being offboarded, from the oldest to the newest.
+func (dbq *PostgreSQLDatabaseQueries) ListNamespaceOffboardings(ctx context.Context, namespaceOffboardings *[]NamespaceOffboarding) error {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(namespaceOffboardings).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListNamespaceOffboardings: %v", err)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) UpdateNamespaceOffboarding(ctx context.Context, obj *NamespaceOffboarding) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("UpdateNamespaceOffboarding",
+		"NamespaceUID", obj.NamespaceUID,
+		"NamespaceName", obj.NamespaceName,
+		"Step", obj.Step); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
+	if err != nil {
+		return fmt.Errorf("error on updating namespace offboarding: %v, %v", err, obj.NamespaceUID)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d, %v", result.RowsAffected(), obj.NamespaceUID)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) DeleteNamespaceOffboardingByNamespaceUID(ctx context.Context, namespaceUID string) (int, error) {
+
+	if err := validateQueryParams(namespaceUID, dbq); err != nil {
+		return 0, err
+	}
+
+	result := &NamespaceOffboarding{}
+
+	deleteResult, err := dbq.dbConnection.Model(result).
+		Where("nso.namespace_uid = ?", namespaceUID).
+		Context(ctx).
+		Delete()
+
+	if err != nil {
+		return 0, fmt.Errorf("error on deleting namespace offboarding: %v", err)
+	}
+
+	return deleteResult.RowsAffected(), nil
+}
+
+var _ DisposableResource = &NamespaceOffboarding{}
+
+func (obj *NamespaceOffboarding) Dispose(ctx context.Context, dbq DatabaseQueries) error {
+	if dbq == nil {
+		return fmt.Errorf("missing database interface in NamespaceOffboarding dispose")
+	}
+
+	_, err := dbq.DeleteNamespaceOffboardingByNamespaceUID(ctx, obj.NamespaceUID)
+	return err
+}
+
+// GetAsLogKeyValues returns an []interface that can be passed to log.Info(...).
+// e.g. log.Info("Creating database resource", obj.GetAsLogKeyValues()...)
+func (obj *NamespaceOffboarding) GetAsLogKeyValues() []interface{} {
+	if obj == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{"namespaceUID", obj.NamespaceUID,
+		"namespaceName", obj.NamespaceName,
+		"step", obj.Step}
+}
@@ -0,0 +1,80 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("NamespaceOffboarding Tests", func() {
+
+	var (
+		ctx context.Context
+		dbq db.AllDatabaseQueries
+	)
+
+	BeforeEach(func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		dbq.CloseDatabase()
+	})
+
+	It("should create, get, update, list and delete NamespaceOffboardings", func() {
+
+		offboarding := db.NamespaceOffboarding{
+			NamespaceUID:  "test-namespace-uid",
+			NamespaceName: "test-namespace",
+			Step:          "applications",
+		}
+		err := dbq.CreateNamespaceOffboarding(ctx, &offboarding)
+		Expect(err).To(BeNil())
+
+		By("verifying an offboarding of the same namespace cannot be created")
+		duplicate := offboarding
+		err = dbq.CreateNamespaceOffboarding(ctx, &duplicate)
+		Expect(err).ToNot(BeNil())
+
+		fetchRow := db.NamespaceOffboarding{NamespaceUID: offboarding.NamespaceUID}
+		err = dbq.GetNamespaceOffboardingByNamespaceUID(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.NamespaceName).To(Equal("test-namespace"))
+		Expect(fetchRow.Step).To(Equal("applications"))
+
+		By("updating the step")
+		fetchRow.Step = "managedenvironments"
+		err = dbq.UpdateNamespaceOffboarding(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+
+		var offboardings []db.NamespaceOffboarding
+		err = dbq.ListNamespaceOffboardings(ctx, &offboardings)
+		Expect(err).To(BeNil())
+		Expect(offboardings).To(ContainElement(HaveField("Step", "managedenvironments")))
+
+		By("verifying the field lengths are validated")
+		tooLong := db.NamespaceOffboarding{
+			NamespaceUID:  "test-other-namespace-uid",
+			NamespaceName: strings.Repeat("abc", 30),
+			Step:          "applications",
+		}
+		err = dbq.CreateNamespaceOffboarding(ctx, &tooLong)
+		Expect(db.IsMaxLengthError(err)).To(BeTrue())
+
+		rowsAffected, err := dbq.DeleteNamespaceOffboardingByNamespaceUID(ctx, offboarding.NamespaceUID)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		err = dbq.GetNamespaceOffboardingByNamespaceUID(ctx, &fetchRow)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+	})
+})
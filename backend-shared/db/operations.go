@@ -297,9 +297,79 @@ func (dbq *PostgreSQLDatabaseQueries) ListOperationsToBeGarbageCollected(ctx con
 	return nil
 }
 
+// ReleaseInProgressOperationsOfGitopsEngineCluster returns the In_Progress operations that target the Argo CD instances
+// of the given GitOpsEngineCluster, and that are being processed by a cluster-agent replica other than 'processingOwner',
+// to the Waiting state, so that they are processed again. Returns the number of operations that were released.
+func (dbq *PostgreSQLDatabaseQueries) ReleaseInProgressOperationsOfGitopsEngineCluster(ctx context.Context, gitopsEngineClusterID string, processingOwner string) (int, error) {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return 0, err
+	}
+
+	if err := isEmptyValues("ReleaseInProgressOperationsOfGitopsEngineCluster",
+		"gitopsEngineClusterID", gitopsEngineClusterID,
+		"processingOwner", processingOwner); err != nil {
+		return 0, err
+	}
+
+	operation := Operation{}
+
+	result, err := dbq.dbConnection.Model(&operation).
+		Set("state = ?", OperationState_Waiting).
+		Set("processing_owner = ?", nil).
+		Set("last_state_update = ?", time.Now()).
+		Where("state = ?", OperationState_In_Progress).
+		Where("processing_owner IS DISTINCT FROM ?", processingOwner).
+		Where("instance_id IN (SELECT gitopsengineinstance_id FROM gitopsengineinstance WHERE enginecluster_id = ?)", gitopsEngineClusterID).
+		Context(ctx).
+		Update()
+	if err != nil {
+		return 0, fmt.Errorf("error on releasing in progress operations: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// CheckpointInProgressOperationsOfProcessingOwner returns the In_Progress operations that are being processed by the
+// given cluster-agent replica to the Waiting state, with the given resume token, so that they are resumed by the next
+// replica. Returns the number of operations that were checkpointed.
+func (dbq *PostgreSQLDatabaseQueries) CheckpointInProgressOperationsOfProcessingOwner(ctx context.Context, processingOwner string, resumeToken string) (int, error) {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return 0, err
+	}
+
+	if err := isEmptyValues("CheckpointInProgressOperationsOfProcessingOwner",
+		"processingOwner", processingOwner,
+		"resumeToken", resumeToken); err != nil {
+		return 0, err
+	}
+
+	if len(resumeToken) > OperationResumeTokenLength {
+		return 0, fmt.Errorf("resume token is longer than %d characters", OperationResumeTokenLength)
+	}
+
+	operation := Operation{}
+
+	result, err := dbq.dbConnection.Model(&operation).
+		Set("state = ?", OperationState_Waiting).
+		Set("processing_owner = ?", nil).
+		Set("resume_token = ?", resumeToken).
+		Set("last_state_update = ?", time.Now()).
+		Where("state = ?", OperationState_In_Progress).
+		Where("processing_owner = ?", processingOwner).
+		Context(ctx).
+		Update()
+	if err != nil {
+		return 0, fmt.Errorf("error on checkpointing in progress operations: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) CountTotalOperationDBRows(ctx context.Context, operation *Operation) (int, error) {
 
-	count, err := dbq.dbConnection.Model(operation).Count()
+	count, err := dbq.dbConnection.Model(operation).Context(ctx).Count()
 	if err != nil {
 		return 0, fmt.Errorf("error on counting total number of operation: %w", err)
 	}
@@ -321,6 +391,7 @@ func (dbq *PostgreSQLDatabaseQueries) CountOperationDBRowsByState(ctx context.Co
 		ColumnExpr("count(*) AS row_count").
 		Group("state").
 		Order("row_count DESC").
+		Context(ctx).
 		Select(&res)
 
 	if err != nil {
@@ -330,6 +401,43 @@ func (dbq *PostgreSQLDatabaseQueries) CountOperationDBRowsByState(ctx context.Co
 	return res, nil
 }
 
+// OperationCountForInstance is the number of Operations of a GitopsEngineInstance in a given state, as returned by
+// CountOperationsByStateForInstance.
+type OperationCountForInstance struct {
+	InstanceID string
+	RowCount   int
+}
+
+// CountOperationsByStateForInstance returns the number of operations in the given state, for each GitopsEngineInstance
+// that has at least one operation in that state. The operations are aggregated by the database, so this is cheap
+// enough to call periodically.
+func (dbq *PostgreSQLDatabaseQueries) CountOperationsByStateForInstance(ctx context.Context, state OperationState) ([]OperationCountForInstance, error) {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return nil, err
+	}
+
+	if err := isEmptyValues("CountOperationsByStateForInstance", "state", string(state)); err != nil {
+		return nil, err
+	}
+
+	var res []OperationCountForInstance
+	err := dbq.dbConnection.Model(&Operation{}).
+		Column("instance_id").
+		ColumnExpr("count(*) AS row_count").
+		Where("state = ?", state).
+		Group("instance_id").
+		Order("row_count DESC").
+		Context(ctx).
+		Select(&res)
+
+	if err != nil {
+		return nil, fmt.Errorf("error on counting number of operations of each GitopsEngineInstance in state '%s': %w", state, err)
+	}
+
+	return res, nil
+}
+
 // Get operations in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 // For example if you want operations starting from 51-150 then set the limit to 100 and offset to 50.
 func (dbq *PostgreSQLDatabaseQueries) GetOperationBatch(ctx context.Context, operations *[]Operation, limit, offSet int) error {
@@ -17,6 +17,7 @@ var _ = Describe("Operations Test", func() {
 	var seq = 101
 
 	var (
+		gitopsEngineCluster  *db.GitopsEngineCluster
 		gitopsEngineInstance *db.GitopsEngineInstance
 		dbq                  db.AllDatabaseQueries
 		testClusterUser      = &db.ClusterUser{
@@ -35,7 +36,7 @@ var _ = Describe("Operations Test", func() {
 		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
 		Expect(err).To(BeNil())
 
-		_, _, _, gitopsEngineInstance, _, err = db.CreateSampleData(dbq)
+		_, _, gitopsEngineCluster, gitopsEngineInstance, _, err = db.CreateSampleData(dbq)
 		Expect(err).To(BeNil())
 
 		err = dbq.CreateClusterUser(ctx, testClusterUser)
@@ -195,6 +196,100 @@ var _ = Describe("Operations Test", func() {
 		})
 
 	})
+
+	Context("release in progress operations of a GitOpsEngineCluster", func() {
+
+		createOperation := func(id string, state db.OperationState, processingOwner string) *db.Operation {
+			operation := &db.Operation{
+				Operation_id:            id,
+				Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+				Resource_id:             "test-fake-resource-id",
+				Resource_type:           "GitopsEngineInstance",
+				Operation_owner_user_id: testClusterUser.Clusteruser_id,
+			}
+			Expect(dbq.CreateOperation(ctx, operation, operation.Operation_owner_user_id)).To(Succeed())
+
+			operation.State = state
+			operation.Processing_owner = processingOwner
+			Expect(dbq.UpdateOperation(ctx, operation)).To(Succeed())
+
+			return operation
+		}
+
+		It("should return the In_Progress operations of other cluster-agent replicas to Waiting", func() {
+			previousLeaderOperation := createOperation("test-operation-previous-leader", db.OperationState_In_Progress, "previous-leader")
+			unownedOperation := createOperation("test-operation-unowned", db.OperationState_In_Progress, "")
+			currentLeaderOperation := createOperation("test-operation-current-leader", db.OperationState_In_Progress, "current-leader")
+			completedOperation := createOperation("test-operation-completed", db.OperationState_Completed, "previous-leader")
+
+			released, err := dbq.ReleaseInProgressOperationsOfGitopsEngineCluster(ctx, gitopsEngineCluster.Gitopsenginecluster_id, "current-leader")
+			Expect(err).To(BeNil())
+			Expect(released).To(Equal(2))
+
+			for _, operation := range []*db.Operation{previousLeaderOperation, unownedOperation} {
+				Expect(dbq.GetOperationById(ctx, operation)).To(Succeed())
+				Expect(operation.State).To(Equal(db.OperationState_Waiting))
+				Expect(operation.Processing_owner).To(BeEmpty())
+			}
+
+			By("verifying the operations of the current leader, and completed operations, are unchanged")
+			Expect(dbq.GetOperationById(ctx, currentLeaderOperation)).To(Succeed())
+			Expect(currentLeaderOperation.State).To(Equal(db.OperationState_In_Progress))
+			Expect(currentLeaderOperation.Processing_owner).To(Equal("current-leader"))
+
+			Expect(dbq.GetOperationById(ctx, completedOperation)).To(Succeed())
+			Expect(completedOperation.State).To(Equal(db.OperationState_Completed))
+		})
+
+		It("should not release the operations of other GitOpsEngineClusters", func() {
+			operation := createOperation("test-operation-previous-leader", db.OperationState_In_Progress, "previous-leader")
+
+			released, err := dbq.ReleaseInProgressOperationsOfGitopsEngineCluster(ctx, "test-another-engine-cluster", "current-leader")
+			Expect(err).To(BeNil())
+			Expect(released).To(Equal(0))
+
+			Expect(dbq.GetOperationById(ctx, operation)).To(Succeed())
+			Expect(operation.State).To(Equal(db.OperationState_In_Progress))
+		})
+
+		It("should checkpoint only the In_Progress operations of the given cluster-agent replica, with a resume token", func() {
+			ownOperation := createOperation("test-operation-own", db.OperationState_In_Progress, "current-leader")
+			otherOperation := createOperation("test-operation-other", db.OperationState_In_Progress, "previous-leader")
+			completedOperation := createOperation("test-operation-completed", db.OperationState_Completed, "current-leader")
+
+			checkpointed, err := dbq.CheckpointInProgressOperationsOfProcessingOwner(ctx, "current-leader", "current-leader_resume-token")
+			Expect(err).To(BeNil())
+			Expect(checkpointed).To(Equal(1))
+
+			Expect(dbq.GetOperationById(ctx, ownOperation)).To(Succeed())
+			Expect(ownOperation.State).To(Equal(db.OperationState_Waiting))
+			Expect(ownOperation.Processing_owner).To(BeEmpty())
+			Expect(ownOperation.Resume_token).To(Equal("current-leader_resume-token"))
+
+			for _, operation := range []*db.Operation{otherOperation, completedOperation} {
+				Expect(dbq.GetOperationById(ctx, operation)).To(Succeed())
+				Expect(operation.Resume_token).To(BeEmpty())
+			}
+			Expect(otherOperation.State).To(Equal(db.OperationState_In_Progress))
+			Expect(completedOperation.State).To(Equal(db.OperationState_Completed))
+		})
+
+		It("should count the operations in the given state, for each GitopsEngineInstance", func() {
+			createOperation("test-operation-waiting-1", db.OperationState_Waiting, "")
+			createOperation("test-operation-waiting-2", db.OperationState_Waiting, "")
+			createOperation("test-operation-in-progress", db.OperationState_In_Progress, "current-leader")
+
+			counts, err := dbq.CountOperationsByStateForInstance(ctx, db.OperationState_Waiting)
+			Expect(err).To(BeNil())
+			Expect(counts).To(Equal([]db.OperationCountForInstance{
+				{InstanceID: gitopsEngineInstance.Gitopsengineinstance_id, RowCount: 2},
+			}))
+
+			counts, err = dbq.CountOperationsByStateForInstance(ctx, db.OperationState_Failed)
+			Expect(err).To(BeNil())
+			Expect(counts).To(BeEmpty())
+		})
+	})
 })
 
 func readyForGarbageCollection() types.GomegaMatcher {
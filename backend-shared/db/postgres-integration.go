@@ -58,6 +58,10 @@ func ConnectToDatabaseWithPort(verbose bool, port int) (*pg.DB, error) {
 		return nil, fmt.Errorf("%v, unable to connect to database: Host:'%s' User:'%s' DB:'%s' ", err, opts.Addr, opts.User, opts.Database)
 	}
 
+	// The deadline hook is added first, so that its AfterQuery runs last: the other hooks see the original error.
+	db.AddQueryHook(NewQueryDeadlineHook())
+	db.AddQueryHook(NewQueryMetricsHook())
+
 	if verbose {
 		db.AddQueryHook(pgdebug.DebugHook{
 			// Print all queries.
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -57,6 +58,10 @@ type UnsafeDatabaseQueries interface {
 	UnsafeListAllKubernetesResourceToDBResourceMapping(ctx context.Context, kubernetesToDBResourceMapping *[]KubernetesToDBResourceMapping) error
 	UnsafeListAllAPICRToDatabaseMappings(ctx context.Context, mappings *[]APICRToDatabaseMapping) error
 	UnsafeListAllRepositoryCredentials(ctx context.Context, repositoryCredentials *[]RepositoryCredentials) error
+	UnsafeListAllKeyStores(ctx context.Context, keyStores *[]KeyStore) error
+	UnsafeListAllApplicationOwners(ctx context.Context, applicationOwners *[]ApplicationOwner) error
+	UnsafeListAllFeatureFlags(ctx context.Context, featureFlags *[]FeatureFlag) error
+	UnsafeListAllDeploymentHistory(ctx context.Context, deploymentHistory *[]DeploymentHistory) error
 }
 
 type AllDatabaseQueries interface {
@@ -130,6 +135,10 @@ type DatabaseQueries interface {
 	// Get ClusterCredentials in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 	GetClusterCredentialsBatch(ctx context.Context, clusterCredentials *[]ClusterCredentials, limit, offSet int) error
 
+	// ListUnreferencedClusterCredentials returns (up to 'limit') ClusterCredentials, created before 'createdBefore' and with a
+	// seq_id greater than 'afterSeqID', that are not referenced by any ManagedEnvironment or GitopsEngineCluster.
+	ListUnreferencedClusterCredentials(ctx context.Context, createdBefore time.Time, afterSeqID int64, limit int, clusterCredentials *[]ClusterCredentials) error
+
 	// Get Operation in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 	GetOperationBatch(ctx context.Context, operations *[]Operation, limit, offSet int) error
 
@@ -149,6 +158,10 @@ type DatabaseQueries interface {
 	GetDeploymentToApplicationMappingBatch(ctx context.Context, deploymentToApplicationMappings *[]DeploymentToApplicationMapping, limit, offSet int) error
 
 	UpdateManagedEnvironment(ctx context.Context, obj *ManagedEnvironment) error
+
+	// CompareAndSwapManagedEnvironment updates the ManagedEnvironment row only if its version is unchanged since it was
+	// read, otherwise returns an error matched by IsConflictError.
+	CompareAndSwapManagedEnvironment(ctx context.Context, obj *ManagedEnvironment) error
 	DeleteGitopsEngineInstanceById(ctx context.Context, id string) (int, error)
 
 	// Delete ManagedEnvironment row by ID
@@ -158,6 +171,11 @@ type DatabaseQueries interface {
 	CheckedListAllGitopsEngineInstancesForGitopsEngineClusterIdAndOwnerId(ctx context.Context, engineClusterId string, ownerId string, gitopsEngineInstancesParam *[]GitopsEngineInstance) error
 	CheckedListClusterCredentialsByHost(ctx context.Context, hostName string, clusterCredentials *[]ClusterCredentials, ownerId string) error
 	ListManagedEnvironmentForClusterCredentialsAndOwnerId(ctx context.Context, clusterCredentialId string, ownerId string, managedEnvironments *[]ManagedEnvironment) error
+
+	// ListManagedEnvironmentsByClusterLabelsAndOwnerId returns the ManagedEnvironments that the owner has access to, which
+	// have all of the given cluster labels (and may have others). If no labels are given, all the ManagedEnvironments that
+	// the owner has access to are returned.
+	ListManagedEnvironmentsByClusterLabelsAndOwnerId(ctx context.Context, clusterLabels map[string]string, ownerId string, managedEnvironments *[]ManagedEnvironment) error
 	CheckedListGitopsEngineClusterByCredentialId(ctx context.Context, credentialId string, engineClustersParam *[]GitopsEngineCluster, ownerId string) error
 
 	// RemoveManagedEnvironmentFromAllApplications update the 'managed_environment_id' field to null
@@ -171,9 +189,28 @@ type DatabaseQueries interface {
 
 	ListClusterAccessesByManagedEnvironmentID(ctx context.Context, managedEnvironmentID string, clusterAccesses *[]ClusterAccess) error
 
+	// DeleteClusterAccessesByManagedEnvironmentID deletes all the ClusterAccess rows that reference the ManagedEnvironment, in
+	// a single transaction, and returns the IDs of the ClusterUsers whose access was removed. The ManagedEnvironment row is
+	// locked during the transaction, so that no new ClusterAccess row can reference it in the meantime.
+	DeleteClusterAccessesByManagedEnvironmentID(ctx context.Context, managedEnvironmentID string) ([]string, error)
+
 	// ListApplicationsForManagedEnvironment returns a list of all Applications that reference the specified ManagedEnvironment row
 	ListApplicationsForManagedEnvironment(ctx context.Context, managedEnvironmentID string, applications *[]Application) (int, error)
 
+	// ListApplicationsForGitopsEngineInstance returns a list of all Applications that are deployed by the specified GitopsEngineInstance row
+	ListApplicationsForGitopsEngineInstance(ctx context.Context, gitopsEngineInstanceID string, applications *[]Application) (int, error)
+
+	// CountApplicationsForGitopsEngineInstance returns the number of Applications that are deployed by the specified GitopsEngineInstance row
+	CountApplicationsForGitopsEngineInstance(ctx context.Context, gitopsEngineInstanceID string) (int, error)
+
+	// UpdateGitopsEngineInstanceMaxApplications sets the maximum number of Applications that may be assigned to the
+	// specified GitopsEngineInstance row (0 for no limit).
+	UpdateGitopsEngineInstanceMaxApplications(ctx context.Context, gitopsEngineInstanceID string, maxApplications int) error
+
+	// UpdateGitopsEngineInstanceArgoCDVersion sets the version of Argo CD of the specified GitopsEngineInstance row
+	// (empty if the version is not known).
+	UpdateGitopsEngineInstanceArgoCDVersion(ctx context.Context, gitopsEngineInstanceID string, argoCDVersion string) error
+
 	// ListGitopsEngineInstancesForCluster lists the GitOpsEngineInstances that are on the given GitOpsEngineCluster
 	ListGitopsEngineInstancesForCluster(ctx context.Context, gitopsEngineCluster GitopsEngineCluster, gitopsEngineInstances *[]GitopsEngineInstance) error
 
@@ -189,8 +226,55 @@ type DatabaseQueries interface {
 		RowCount int
 	}, error)
 
+	// CountOperationsByStateForInstance returns the number of operations in the given state, for each GitopsEngineInstance
+	// that has at least one operation in that state.
+	CountOperationsByStateForInstance(ctx context.Context, state OperationState) ([]OperationCountForInstance, error)
+
 	// Get KubernetesToDBResourceMapping in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offset'.
 	GetKubernetesToDBResourceMappingBatch(ctx context.Context, k8sToDBResourceMapping *[]KubernetesToDBResourceMapping, limit, offset int) error
+
+	CreateKeyStore(ctx context.Context, obj *KeyStore) error
+	GetKeyStoreById(ctx context.Context, obj *KeyStore) error
+	UpdateKeyStore(ctx context.Context, obj *KeyStore) error
+	DeleteKeyStoreById(ctx context.Context, id string) (int, error)
+
+	// GetActiveKeyStoreForClusterUser returns the KeyStore row containing the data key that is used to encrypt new values of the user
+	GetActiveKeyStoreForClusterUser(ctx context.Context, clusterUserID string, obj *KeyStore) error
+
+	// ListKeyStoresForClusterUser returns all the KeyStore rows of the user, from oldest to newest
+	ListKeyStoresForClusterUser(ctx context.Context, clusterUserID string, keyStores *[]KeyStore) error
+
+	// RotateMasterKeyOfKeyStores rewraps the data keys that were wrapped with a previous master key, with the current master key.
+	RotateMasterKeyOfKeyStores(ctx context.Context) (int, error)
+
+	// RotateDataKeyOfClusterUser replaces the data key of the user, re-encrypting the values that were encrypted with the previous key(s).
+	RotateDataKeyOfClusterUser(ctx context.Context, clusterUserID string) error
+
+	// ListApplicationOwnersByClusterUserID returns the Applications that are owned by a user, from oldest to newest.
+	ListApplicationOwnersByClusterUserID(ctx context.Context, clusterUserID string, applicationOwners *[]ApplicationOwner) error
+
+	// ListRepositoryCredentialsByClusterUserID returns the RepositoryCredentials that are owned by a user, from oldest to newest.
+	ListRepositoryCredentialsByClusterUserID(ctx context.Context, clusterUserID string, repositoryCredentials *[]RepositoryCredentials) error
+
+	// DeactivateClusterUser marks the ClusterUser as deactivated, and deletes its ClusterAccess rows, in a single transaction.
+	// Returns the number of ClusterAccess rows that were deleted.
+	DeactivateClusterUser(ctx context.Context, clusterUserID string) (int, error)
+
+	// PurgeClusterUser deletes a deactivated ClusterUser, and the database rows that still reference it. The RepositoryCredentials
+	// of the user must have been deleted beforehand. Returns the number of ClusterUser rows deleted.
+	PurgeClusterUser(ctx context.Context, clusterUserID string) (int, error)
+
+	CreateFeatureFlag(ctx context.Context, obj *FeatureFlag) error
+	GetFeatureFlagById(ctx context.Context, obj *FeatureFlag) error
+	UpdateFeatureFlag(ctx context.Context, obj *FeatureFlag) error
+	DeleteFeatureFlagById(ctx context.Context, id string) (int, error)
+
+	// GetFeatureFlagByNameAndNamespace retrieves the flag with the given name and namespace: an empty namespace
+	// retrieves the installation-wide flag.
+	GetFeatureFlagByNameAndNamespace(ctx context.Context, obj *FeatureFlag) error
+
+	// ListFeatureFlags returns all the FeatureFlag rows, both installation-wide and namespace-scoped.
+	ListFeatureFlags(ctx context.Context, featureFlags *[]FeatureFlag) error
 }
 
 // ApplicationScopedQueries are the set of database queries that act on application DB resources:
@@ -230,6 +314,14 @@ type ApplicationScopedQueries interface {
 	// ListOperationsToBeGarbageCollected returns 'Failed'/'Completed' operations with a non-zero garbage collection expiration time
 	ListOperationsToBeGarbageCollected(ctx context.Context, operations *[]Operation) error
 
+	// ReleaseInProgressOperationsOfGitopsEngineCluster returns the In_Progress operations of a GitOpsEngineCluster that are
+	// not being processed by 'processingOwner' to Waiting: for example, the operations of a cluster-agent that was the leader.
+	ReleaseInProgressOperationsOfGitopsEngineCluster(ctx context.Context, gitopsEngineClusterID string, processingOwner string) (int, error)
+
+	// CheckpointInProgressOperationsOfProcessingOwner returns the In_Progress operations that are being processed by
+	// 'processingOwner' to Waiting, with the given resume token: for example, on shutdown of that cluster-agent replica.
+	CheckpointInProgressOperationsOfProcessingOwner(ctx context.Context, processingOwner string, resumeToken string) (int, error)
+
 	CreateSyncOperation(ctx context.Context, obj *SyncOperation) error
 	GetSyncOperationById(ctx context.Context, syncOperation *SyncOperation) error
 	DeleteSyncOperationById(ctx context.Context, id string) (int, error)
@@ -238,23 +330,88 @@ type ApplicationScopedQueries interface {
 	CreateApplication(ctx context.Context, obj *Application) error
 	CheckedCreateApplication(ctx context.Context, obj *Application, ownerId string) error
 	GetApplicationById(ctx context.Context, application *Application) error
+
+	// GetTenantApplicationById retrieves the Application only if it is owned by the tenant, otherwise the Application is
+	// reported as not found. Prefer a TenantScopedQueries (see NewTenantScopedQueries) to calling this directly.
+	GetTenantApplicationById(ctx context.Context, application *Application, tenant Tenant) error
+
 	UpdateApplication(ctx context.Context, obj *Application) error
+
+	// CompareAndSwapApplication updates the Application row only if its version is unchanged since it was read,
+	// otherwise returns an error matched by IsConflictError.
+	CompareAndSwapApplication(ctx context.Context, obj *Application) error
+
 	DeleteApplicationById(ctx context.Context, id string) (int, error)
 	CheckedDeleteApplicationById(ctx context.Context, id string, ownerId string) (int, error)
 
+	// SoftDeleteApplicationById marks the Application as deleted, without deleting the row: see Application.Deleted_on.
+	SoftDeleteApplicationById(ctx context.Context, id string) (int, error)
+
+	// RestoreApplicationById restores an Application that was soft-deleted by SoftDeleteApplicationById.
+	RestoreApplicationById(ctx context.Context, id string) (int, error)
+
 	// Get applications in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 	GetApplicationBatch(ctx context.Context, applications *[]Application, limit, offSet int) error
 
+	CreateApplicationOwner(ctx context.Context, obj *ApplicationOwner) error
+	GetApplicationOwnerByPrimaryKey(ctx context.Context, obj *ApplicationOwner) error
+	DeleteApplicationOwner(ctx context.Context, applicationID string, clusterUserID string) (int, error)
+
+	// ListApplicationOwnersByApplicationID returns the owners of an Application, from oldest to newest.
+	ListApplicationOwnersByApplicationID(ctx context.Context, applicationID string, applicationOwners *[]ApplicationOwner) error
+
+	CreateDeploymentHistory(ctx context.Context, obj *DeploymentHistory) error
+	DeleteDeploymentHistoryById(ctx context.Context, id string) (int, error)
+
+	// ListDeploymentHistoryByApplicationID returns the revisions deployed by an Application, from newest to oldest.
+	// - If limit is greater than 0, at most 'limit' rows (the most recent) are returned.
+	ListDeploymentHistoryByApplicationID(ctx context.Context, applicationID string, limit int, deploymentHistory *[]DeploymentHistory) error
+
+	// CountDeploymentHistoryByApplicationIDsSince returns the number of revisions deployed by the given Applications, at or after 'since'.
+	CountDeploymentHistoryByApplicationIDsSince(ctx context.Context, applicationIDs []string, since time.Time) (int, error)
+
+	CreateNamespaceOffboarding(ctx context.Context, obj *NamespaceOffboarding) error
+	GetNamespaceOffboardingByNamespaceUID(ctx context.Context, obj *NamespaceOffboarding) error
+	UpdateNamespaceOffboarding(ctx context.Context, obj *NamespaceOffboarding) error
+	DeleteNamespaceOffboardingByNamespaceUID(ctx context.Context, namespaceUID string) (int, error)
+
+	// ListNamespaceOffboardings returns every namespace that is in the process of being offboarded, from oldest to newest.
+	ListNamespaceOffboardings(ctx context.Context, namespaceOffboardings *[]NamespaceOffboarding) error
+
+	CreateMigrationOperation(ctx context.Context, obj *MigrationOperation) error
+	GetMigrationOperationByNamespaceUID(ctx context.Context, obj *MigrationOperation) error
+	UpdateMigrationOperation(ctx context.Context, obj *MigrationOperation) error
+	DeleteMigrationOperationByNamespaceUID(ctx context.Context, namespaceUID string) (int, error)
+
+	// ListMigrationOperations returns every namespace whose Applications are in the process of being migrated, from oldest to newest.
+	ListMigrationOperations(ctx context.Context, migrationOperations *[]MigrationOperation) error
+
+	CreateTaskCheckpoint(ctx context.Context, obj *TaskCheckpoint) error
+	GetTaskCheckpointByTaskName(ctx context.Context, obj *TaskCheckpoint) error
+	UpdateTaskCheckpoint(ctx context.Context, obj *TaskCheckpoint) error
+	DeleteTaskCheckpointByTaskName(ctx context.Context, taskName string) (int, error)
+
+	// ListTaskCheckpoints returns the progress of every task that has not yet completed, from oldest to newest.
+	ListTaskCheckpoints(ctx context.Context, taskCheckpoints *[]TaskCheckpoint) error
+
 	CreateAPICRToDatabaseMapping(ctx context.Context, obj *APICRToDatabaseMapping) error
 
 	// Get APICRToDatabaseMapping in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 	GetAPICRToDatabaseMappingBatch(ctx context.Context, apiCRToDatabaseMapping *[]APICRToDatabaseMapping, limit, offSet int) error
 
+	// ListAPICRToDatabaseMappingsOlderThan returns (up to 'limit') APICRToDatabaseMappings of the given API resource type,
+	// created before 'createdBefore' and with a seq_id greater than 'afterSeqID'.
+	ListAPICRToDatabaseMappingsOlderThan(ctx context.Context, resourceType APICRToDatabaseMapping_ResourceType, createdBefore time.Time,
+		afterSeqID int64, limit int, apiCRToDatabaseMappings *[]APICRToDatabaseMapping) error
+
 	// ListAPICRToDatabaseMappingByAPINamespaceAndName returns the DBRelationKey for a given type/name/namespace/namespace uid/db-relation-type query
 	ListAPICRToDatabaseMappingByAPINamespaceAndName(ctx context.Context, apiCRResourceType APICRToDatabaseMapping_ResourceType,
 		crName string, crNamespace string, crNamespaceUID string, dbRelationType APICRToDatabaseMapping_DBRelationType,
 		apiCRToDBMappingParam *[]APICRToDatabaseMapping) error
 
+	// ListAPICRToDatabaseMappingByNamespaceUID lists all APICRToDatabaseMappings of API resources that are in the namespace with the given UID
+	ListAPICRToDatabaseMappingByNamespaceUID(ctx context.Context, namespaceUID string, apiCRToDBMappingParam *[]APICRToDatabaseMapping) error
+
 	GetDatabaseMappingForAPICR(ctx context.Context, obj *APICRToDatabaseMapping) error
 	DeleteAPICRToDatabaseMapping(ctx context.Context, obj *APICRToDatabaseMapping) (int, error)
 
@@ -265,6 +422,11 @@ type ApplicationScopedQueries interface {
 	// ListDeploymentToApplicationMappingByNamespaceUID lists all DTAMs that are in a namespace with the given UID
 	ListDeploymentToApplicationMappingByNamespaceUID(ctx context.Context, namespaceUID string, deplToAppMappingParam *[]DeploymentToApplicationMapping) error
 
+	// ListDeploymentToApplicationMappingsForNamespaceUID lists a page of (at most 'limit') DTAMs that are in a namespace with
+	// the given UID, ordered by seq_id, beginning after the DTAM with seq_id 'afterSeqID'. To list the next page, pass the
+	// seq_id of the last DTAM of the page. The first page begins after seq_id 0.
+	ListDeploymentToApplicationMappingsForNamespaceUID(ctx context.Context, namespaceUID string, afterSeqID int64, limit int, deplToAppMappingParam *[]DeploymentToApplicationMapping) error
+
 	DeleteDeploymentToApplicationMappingByDeplId(ctx context.Context, id string) (int, error)
 	DeleteDeploymentToApplicationMappingByNamespaceAndName(ctx context.Context, deploymentName string, deploymentNamespace string, namespaceUID string) (int, error)
 
@@ -275,6 +437,16 @@ type ApplicationScopedQueries interface {
 	GetApplicationStateById(ctx context.Context, obj *ApplicationState) error
 	CreateApplicationState(ctx context.Context, obj *ApplicationState) error
 	UpdateApplicationState(ctx context.Context, obj *ApplicationState) error
+
+	// UpdateApplicationStateHealthOnly updates only the health and message fields of an existing ApplicationState.
+	UpdateApplicationStateHealthOnly(ctx context.Context, obj *ApplicationState) error
+
+	// UpsertApplicationStates creates or updates the given ApplicationStates, in a single statement.
+	UpsertApplicationStates(ctx context.Context, applicationStates []ApplicationState) error
+
+	// GetApplicationStateResourcesSize returns the total size, in bytes, of the resources column of the ApplicationStates
+	// of the given Applications.
+	GetApplicationStateResourcesSize(ctx context.Context, applicationIDs []string) (int, error)
 	DeleteApplicationStateById(ctx context.Context, id string) (int, error)
 
 	GetManagedEnvironmentById(ctx context.Context, managedEnvironment *ManagedEnvironment) error
@@ -288,6 +460,13 @@ type ApplicationScopedQueries interface {
 
 type CloseableQueries interface {
 	CloseDatabase()
+
+	// Ping returns an error if the database is not reachable.
+	Ping(ctx context.Context) error
+
+	// GetSchemaTables returns the tables and columns of the live database, for comparison with the database types.
+	// See 'VerifyDatabaseSchema' for details.
+	GetSchemaTables(ctx context.Context) (SchemaTables, error)
 }
 
 var _ UnsafeDatabaseQueries = &PostgreSQLDatabaseQueries{}
@@ -429,6 +608,15 @@ func NewUnsafePostgresDBQueriesWithPort(verbose bool, allowTestUuids bool, port
 	return dbq, nil
 }
 
+func (dbq *PostgreSQLDatabaseQueries) Ping(ctx context.Context) error {
+
+	if dbq.dbConnection == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+
+	return dbq.dbConnection.Ping(ctx)
+}
+
 func (dbq *PostgreSQLDatabaseQueries) CloseDatabase() {
 
 	if dbq.dbConnection != nil && dbq.allowClose {
@@ -454,6 +642,15 @@ func IsAccessDeniedError(errorParam error) bool {
 	return strings.Contains(errorParam.Error(), "results found, but access denied")
 }
 
+// ErrConflict is returned (wrapped) by the compare-and-swap update functions, when the row was modified by another
+// caller after it was read.
+var ErrConflict = errors.New("row was modified concurrently")
+
+// IsConflictError returns true if the error was caused by a concurrent modification of a row: see ErrConflict.
+func IsConflictError(errorParam error) bool {
+	return errors.Is(errorParam, ErrConflict)
+}
+
 // NewResultNotFoundError returns an error that will be matched by IsResultNotFoundError
 func NewResultNotFoundError(errString string) error {
 	return fmt.Errorf("%s: no rows in result set", errString)
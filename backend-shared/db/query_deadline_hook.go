@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DBQueryTimeoutEnvVar is the environment variable that may be used to configure the default timeout (in
+	// milliseconds) of database queries whose context does not have a deadline. Set to 0 to disable the default timeout.
+	DBQueryTimeoutEnvVar = "DB_QUERY_TIMEOUT_MS"
+
+	defaultQueryTimeout = 30 * time.Second
+)
+
+// queryDeadlineCancelKey is the key of the cancel function of the default timeout, in the stash of the query event
+type queryDeadlineCancelKey struct{}
+
+// QueryDeadlineHook is a go-pg query hook that ensures the context deadline and cancellation of database queries are
+// enforced:
+//   - Queries whose context does not have a deadline are given a deadline of DefaultTimeout, so that a query cannot
+//     block its caller (for example, a reconcile) indefinitely.
+//   - Queries whose context is already cancelled, or past its deadline, are not sent to the database.
+//   - Queries that fail because their context was cancelled, or its deadline was exceeded, return an error wrapping
+//     context.Canceled, or ErrDeadlineExceeded.
+//
+// go-pg cancels the query on the database (via a Postgres cancel request) once the context of the query is done.
+type QueryDeadlineHook struct {
+	// DefaultTimeout is the timeout of queries whose context does not have a deadline. If 0, no timeout is applied.
+	DefaultTimeout time.Duration
+}
+
+var _ pg.QueryHook = (*QueryDeadlineHook)(nil)
+
+// ErrDeadlineExceeded is returned (wrapped) by database queries that did not complete before the deadline of their
+// context (or, if their context has no deadline, before the default query timeout).
+var ErrDeadlineExceeded = errors.New("database query did not complete before the deadline")
+
+// IsDeadlineExceededError returns true if the error was caused by a database query exceeding its deadline: see
+// ErrDeadlineExceeded.
+func IsDeadlineExceededError(errorParam error) bool {
+	if errorParam == nil {
+		return false
+	}
+	// The error message is also checked, as callers may not have wrapped the error with %w
+	return errors.Is(errorParam, ErrDeadlineExceeded) || strings.Contains(errorParam.Error(), ErrDeadlineExceeded.Error())
+}
+
+// NewQueryDeadlineHook returns a QueryDeadlineHook, with the default timeout read from the DB_QUERY_TIMEOUT_MS
+// environment variable (if set and valid), otherwise using the default timeout.
+func NewQueryDeadlineHook() QueryDeadlineHook {
+
+	hook := QueryDeadlineHook{DefaultTimeout: defaultQueryTimeout}
+
+	if isEnvExist(DBQueryTimeoutEnvVar) {
+		timeoutMs, err := strconv.Atoi(os.Getenv(DBQueryTimeoutEnvVar))
+		if err != nil || timeoutMs < 0 {
+			log.FromContext(context.Background()).V(logutil.LogLevel_Warn).Info("invalid value for "+DBQueryTimeoutEnvVar+
+				", using the default query timeout", "value", os.Getenv(DBQueryTimeoutEnvVar), "default", defaultQueryTimeout.String())
+		} else {
+			hook.DefaultTimeout = time.Duration(timeoutMs) * time.Millisecond
+		}
+	}
+
+	return hook
+}
+
+func (h QueryDeadlineHook) BeforeQuery(ctx context.Context, evt *pg.QueryEvent) (context.Context, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return ctx, newQueryContextError(err, err)
+	}
+
+	if _, hasDeadline := ctx.Deadline(); hasDeadline || h.DefaultTimeout <= 0 {
+		return ctx, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.DefaultTimeout)
+
+	if evt.Stash == nil {
+		evt.Stash = map[interface{}]interface{}{}
+	}
+	evt.Stash[queryDeadlineCancelKey{}] = cancel
+
+	return ctx, nil
+}
+
+func (h QueryDeadlineHook) AfterQuery(ctx context.Context, evt *pg.QueryEvent) error {
+
+	var ctxErr error
+	if ctx != nil {
+		// The context error must be read before the default timeout is cancelled, below
+		ctxErr = ctx.Err()
+	}
+
+	if cancel, ok := evt.Stash[queryDeadlineCancelKey{}].(context.CancelFunc); ok {
+		cancel()
+	}
+
+	if evt.Err == nil || ctxErr == nil {
+		return nil
+	}
+
+	return newQueryContextError(ctxErr, evt.Err)
+}
+
+// newQueryContextError returns the error of a query that failed because its context is done: ErrDeadlineExceeded if
+// the deadline of the context was exceeded, otherwise context.Canceled.
+func newQueryContextError(ctxErr error, queryErr error) error {
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrDeadlineExceeded, queryErr)
+	}
+	return fmt.Errorf("%w: %v", ctxErr, queryErr)
+}
@@ -0,0 +1,119 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("Query deadline hook tests", func() {
+
+	Context("Test QueryDeadlineHook", func() {
+
+		It("should apply the default timeout to queries whose context has no deadline", func() {
+
+			hook := db.QueryDeadlineHook{DefaultTimeout: time.Minute}
+
+			evt := &pg.QueryEvent{StartTime: time.Now()}
+			ctx, err := hook.BeforeQuery(context.Background(), evt)
+			Expect(err).To(BeNil())
+
+			deadline, hasDeadline := ctx.Deadline()
+			Expect(hasDeadline).To(BeTrue())
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), 5*time.Second))
+
+			By("verifying the timeout is cancelled once the query has completed")
+			Expect(hook.AfterQuery(ctx, evt)).To(Succeed())
+			Expect(ctx.Err()).To(Equal(context.Canceled))
+		})
+
+		It("should not modify the deadline of a context that already has one", func() {
+
+			hook := db.QueryDeadlineHook{DefaultTimeout: time.Minute}
+
+			parentCtx, cancel := context.WithTimeout(context.Background(), time.Hour)
+			defer cancel()
+
+			evt := &pg.QueryEvent{StartTime: time.Now()}
+			ctx, err := hook.BeforeQuery(parentCtx, evt)
+			Expect(err).To(BeNil())
+			Expect(ctx).To(Equal(parentCtx))
+			Expect(hook.AfterQuery(ctx, evt)).To(Succeed())
+		})
+
+		It("should not apply a timeout if the default timeout is 0", func() {
+
+			hook := db.QueryDeadlineHook{}
+
+			evt := &pg.QueryEvent{StartTime: time.Now()}
+			ctx, err := hook.BeforeQuery(context.Background(), evt)
+			Expect(err).To(BeNil())
+
+			_, hasDeadline := ctx.Deadline()
+			Expect(hasDeadline).To(BeFalse())
+		})
+
+		It("should not run queries whose context is already done", func() {
+
+			hook := db.QueryDeadlineHook{DefaultTimeout: time.Minute}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := hook.BeforeQuery(ctx, &pg.QueryEvent{StartTime: time.Now()})
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			Expect(db.IsDeadlineExceededError(err)).To(BeFalse())
+		})
+
+		It("should return ErrDeadlineExceeded if the query failed because its deadline was exceeded", func() {
+
+			hook := db.QueryDeadlineHook{DefaultTimeout: time.Millisecond}
+
+			evt := &pg.QueryEvent{StartTime: time.Now()}
+			ctx, err := hook.BeforeQuery(context.Background(), evt)
+			Expect(err).To(BeNil())
+
+			<-ctx.Done()
+
+			evt.Err = fmt.Errorf("simulated error")
+			err = hook.AfterQuery(ctx, evt)
+			Expect(err).ToNot(BeNil())
+			Expect(db.IsDeadlineExceededError(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("simulated error"))
+
+			By("verifying the error is also matched when wrapped with %v")
+			Expect(db.IsDeadlineExceededError(fmt.Errorf("unable to retrieve application: %v", err))).To(BeTrue())
+		})
+
+		It("should not modify the error of a query whose context is not done", func() {
+
+			hook := db.QueryDeadlineHook{DefaultTimeout: time.Minute}
+
+			evt := &pg.QueryEvent{StartTime: time.Now()}
+			ctx, err := hook.BeforeQuery(context.Background(), evt)
+			Expect(err).To(BeNil())
+
+			evt.Err = fmt.Errorf("simulated error")
+			Expect(hook.AfterQuery(ctx, evt)).To(Succeed())
+			Expect(db.IsDeadlineExceededError(evt.Err)).To(BeFalse())
+		})
+
+		It("should read the default timeout from the environment", func() {
+
+			DeferCleanup(os.Unsetenv, db.DBQueryTimeoutEnvVar)
+
+			Expect(os.Setenv(db.DBQueryTimeoutEnvVar, "1500")).To(Succeed())
+			Expect(db.NewQueryDeadlineHook().DefaultTimeout).To(Equal(1500 * time.Millisecond))
+
+			Expect(os.Setenv(db.DBQueryTimeoutEnvVar, "not-a-number")).To(Succeed())
+			Expect(db.NewQueryDeadlineHook().DefaultTimeout).To(Equal(30 * time.Second))
+		})
+	})
+})
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/prometheus/client_golang/prometheus"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DBSlowQueryThresholdEnvVar is the environment variable that may be used to configure the duration (in milliseconds)
+	// above which a database query is logged as a slow query. Set to 0 to disable slow query logging.
+	DBSlowQueryThresholdEnvVar = "DB_SLOW_QUERY_THRESHOLD_MS"
+
+	defaultSlowQueryThreshold = 500 * time.Millisecond
+
+	// unknownQueryName is used as the query name of queries for which the operation or table cannot be determined
+	unknownQueryName = "unknown"
+)
+
+var (
+	DBQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gitops_db_query_duration_seconds",
+			Help:    "Duration of database queries, by query name",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"query"},
+	)
+
+	DBQueryRowsAffected = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gitops_db_query_rows_affected",
+			Help:    "Number of rows affected (or returned) by database queries, by query name",
+			Buckets: []float64{0, 1, 5, 10, 50, 100, 500, 1000, 5000},
+		},
+		[]string{"query"},
+	)
+
+	DBQueryErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gitops_db_query_errors_total",
+			Help: "Number of database queries that returned an error, by query name",
+		},
+		[]string{"query"},
+	)
+
+	// queryOperationRegex matches the SQL operation at the start of a query, e.g. 'SELECT'
+	queryOperationRegex = regexp.MustCompile(`^\s*(?i:(SELECT|INSERT|UPDATE|DELETE|WITH))\b`)
+
+	// queryTableRegex matches the (first) table that a query operates on, e.g. 'FROM "application"'
+	queryTableRegex = regexp.MustCompile(`(?i:\b(?:FROM|INTO|UPDATE)\s+)"?([a-zA-Z_][a-zA-Z0-9_]*)"?`)
+)
+
+func init() {
+	metrics.Registry.MustRegister(DBQueryDuration, DBQueryRowsAffected, DBQueryErrors)
+}
+
+// QueryMetricsHook is a go-pg query hook that records the duration, rows affected, and errors of database queries
+// as Prometheus metrics, and logs queries that take longer than SlowQueryThreshold.
+//
+// Queries are identified by a query name of the form '(operation)_(table)', e.g. 'select_application', so that the
+// number of metric labels is bounded by the number of tables in the database.
+type QueryMetricsHook struct {
+	// SlowQueryThreshold is the duration above which a query is logged as a slow query. If 0, slow queries are not logged.
+	SlowQueryThreshold time.Duration
+}
+
+var _ pg.QueryHook = (*QueryMetricsHook)(nil)
+
+// NewQueryMetricsHook returns a QueryMetricsHook, with the slow query threshold read from the DB_SLOW_QUERY_THRESHOLD_MS
+// environment variable (if set and valid), otherwise using the default threshold.
+func NewQueryMetricsHook() QueryMetricsHook {
+
+	hook := QueryMetricsHook{SlowQueryThreshold: defaultSlowQueryThreshold}
+
+	if isEnvExist(DBSlowQueryThresholdEnvVar) {
+		thresholdMs, err := strconv.Atoi(os.Getenv(DBSlowQueryThresholdEnvVar))
+		if err != nil || thresholdMs < 0 {
+			log.FromContext(context.Background()).V(logutil.LogLevel_Warn).Info("invalid value for "+DBSlowQueryThresholdEnvVar+
+				", using the default slow query threshold", "value", os.Getenv(DBSlowQueryThresholdEnvVar), "default", defaultSlowQueryThreshold.String())
+		} else {
+			hook.SlowQueryThreshold = time.Duration(thresholdMs) * time.Millisecond
+		}
+	}
+
+	return hook
+}
+
+func (h QueryMetricsHook) BeforeQuery(ctx context.Context, evt *pg.QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h QueryMetricsHook) AfterQuery(ctx context.Context, evt *pg.QueryEvent) error {
+
+	duration := time.Since(evt.StartTime)
+
+	// If the query cannot be rendered, the metrics are still recorded, using the 'unknown' query name.
+	unformattedQuery, _ := evt.UnformattedQuery()
+
+	queryName := GetQueryName(string(unformattedQuery))
+
+	DBQueryDuration.WithLabelValues(queryName).Observe(duration.Seconds())
+
+	if evt.Err != nil {
+		DBQueryErrors.WithLabelValues(queryName).Inc()
+	} else if evt.Result != nil {
+		DBQueryRowsAffected.WithLabelValues(queryName).Observe(float64(evt.Result.RowsAffected()))
+	}
+
+	if h.SlowQueryThreshold > 0 && duration > h.SlowQueryThreshold {
+		log.FromContext(ctx).V(logutil.LogLevel_Warn).Info("slow database query", "query", queryName, "duration", duration.String(),
+			"threshold", h.SlowQueryThreshold.String(), "sql", string(unformattedQuery))
+	}
+
+	return nil
+}
+
+// GetQueryName returns the name of a query, of the form '(operation)_(table)', e.g. 'select_application'.
+// - Parameters of the query are not included in the name, so the (unformatted) query may be used.
+// - If the operation or table cannot be determined, 'unknown' is used in their place.
+func GetQueryName(query string) string {
+
+	operation := unknownQueryName
+	if match := queryOperationRegex.FindStringSubmatch(query); len(match) == 2 {
+		operation = strings.ToLower(match[1])
+	}
+
+	table := unknownQueryName
+	if match := queryTableRegex.FindStringSubmatch(query); len(match) == 2 {
+		table = strings.ToLower(match[1])
+	}
+
+	return operation + "_" + table
+}
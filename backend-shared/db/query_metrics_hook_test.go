@@ -0,0 +1,68 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("Query metrics hook tests", func() {
+
+	Context("Test GetQueryName", func() {
+
+		DescribeTable("should return the operation and table of the query",
+			func(query string, expectedName string) {
+				Expect(db.GetQueryName(query)).To(Equal(expectedName))
+			},
+			Entry("select", `SELECT "application"."application_id" FROM "application" AS "application" WHERE (application_id = ?)`, "select_application"),
+			Entry("insert", `INSERT INTO "operation" ("operation_id", "state") VALUES (?, ?)`, "insert_operation"),
+			Entry("update", `UPDATE "syncoperation" AS "syncoperation" SET "desired_state" = ?`, "update_syncoperation"),
+			Entry("delete", `DELETE FROM "applicationstate" AS "applicationstate" WHERE (app_id = ?)`, "delete_applicationstate"),
+			Entry("lower case, without quotes", `select 1 from clusteruser`, "select_clusteruser"),
+			Entry("unknown operation and table", `SELECT 1`, "select_unknown"),
+			Entry("empty query", ``, "unknown_unknown"),
+		)
+	})
+
+	Context("Test QueryMetricsHook", func() {
+
+		It("should count the queries that return an error, by query name", func() {
+
+			hook := db.QueryMetricsHook{SlowQueryThreshold: time.Minute}
+
+			query := `DELETE FROM "gitopsengineinstance" WHERE (gitopsengineinstance_id = ?)`
+			queryName := db.GetQueryName(query)
+
+			errorsBefore := testutil.ToFloat64(db.DBQueryErrors.WithLabelValues(queryName))
+
+			evt := &pg.QueryEvent{StartTime: time.Now(), Query: query}
+			Expect(hook.AfterQuery(context.Background(), evt)).To(Succeed())
+			Expect(testutil.ToFloat64(db.DBQueryErrors.WithLabelValues(queryName))).To(Equal(errorsBefore))
+
+			evt = &pg.QueryEvent{StartTime: time.Now(), Query: query, Err: fmt.Errorf("simulated error")}
+			Expect(hook.AfterQuery(context.Background(), evt)).To(Succeed())
+			Expect(testutil.ToFloat64(db.DBQueryErrors.WithLabelValues(queryName))).To(Equal(errorsBefore + 1))
+		})
+
+		It("should read the slow query threshold from the environment", func() {
+
+			DeferCleanup(os.Unsetenv, db.DBSlowQueryThresholdEnvVar)
+
+			Expect(os.Setenv(db.DBSlowQueryThresholdEnvVar, "250")).To(Succeed())
+			Expect(db.NewQueryMetricsHook().SlowQueryThreshold).To(Equal(250 * time.Millisecond))
+
+			Expect(os.Setenv(db.DBSlowQueryThresholdEnvVar, "0")).To(Succeed())
+			Expect(db.NewQueryMetricsHook().SlowQueryThreshold).To(Equal(time.Duration(0)))
+
+			Expect(os.Setenv(db.DBSlowQueryThresholdEnvVar, "not-a-number")).To(Succeed())
+			Expect(db.NewQueryMetricsHook().SlowQueryThreshold).To(Equal(500 * time.Millisecond))
+		})
+	})
+})
@@ -34,6 +34,14 @@ func (dbq *PostgreSQLDatabaseQueries) CreateRepositoryCredentials(ctx context.Co
 
 	obj.Created_on = time.Now()
 
+	// The password and SSH key are encrypted in the database, but the caller's object should keep the plaintext values
+	plaintextPassword, plaintextSSHKey := obj.AuthPassword, obj.AuthSSHKey
+	defer func() { obj.AuthPassword, obj.AuthSSHKey = plaintextPassword, plaintextSSHKey }()
+
+	if err := dbq.encryptRepositoryCredentials(ctx, obj); err != nil {
+		return fmt.Errorf("%v: %w", errCreateRepositoryCredentials, err)
+	}
+
 	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
 	if err != nil {
 		return fmt.Errorf("%v: %w", errCreateRepositoryCredentials, err)
@@ -75,6 +83,10 @@ func (dbq *PostgreSQLDatabaseQueries) GetRepositoryCredentialsByID(ctx context.C
 		return obj, fmt.Errorf("%v: %w", errGetRepositoryCredentials, err)
 	}
 
+	if err = dbq.decryptRepositoryCredentials(ctx, &obj, dataKeyCache{}); err != nil {
+		return obj, fmt.Errorf("%v: %w", errGetRepositoryCredentials, err)
+	}
+
 	return obj, nil
 }
 
@@ -86,6 +98,13 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateRepositoryCredentials(ctx context.Co
 		return err
 	}
 
+	plaintextPassword, plaintextSSHKey := obj.AuthPassword, obj.AuthSSHKey
+	defer func() { obj.AuthPassword, obj.AuthSSHKey = plaintextPassword, plaintextSSHKey }()
+
+	if err := dbq.encryptRepositoryCredentials(ctx, obj); err != nil {
+		return fmt.Errorf("%v: %w", errUpdateRepositoryCredentials, err)
+	}
+
 	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
 	if err != nil {
 		return fmt.Errorf("%v: %w", errUpdateRepositoryCredentials, err)
@@ -110,6 +129,29 @@ func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllRepositoryCredentials(ctx con
 		return err
 	}
 
+	dbq.decryptRepositoryCredentialsList(ctx, repositoryCredentials)
+
+	return nil
+}
+
+// ListRepositoryCredentialsByClusterUserID returns the RepositoryCredentials that are owned by a user, from oldest to newest.
+func (dbq *PostgreSQLDatabaseQueries) ListRepositoryCredentialsByClusterUserID(ctx context.Context, clusterUserID string, repositoryCredentials *[]RepositoryCredentials) error {
+
+	if err := validateQueryParams(clusterUserID, dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(repositoryCredentials).
+		Where("rc.repo_cred_user_id = ?", clusterUserID).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("%v: %w", errGetRepositoryCredentials, err)
+	}
+
+	dbq.decryptRepositoryCredentialsList(ctx, repositoryCredentials)
+
 	return nil
 }
 
@@ -125,11 +167,18 @@ func (obj *RepositoryCredentials) Dispose(ctx context.Context, dbq DatabaseQueri
 // Get RepositoryCredentials in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 // For example if you want RepositoryCredentials starting from 51-150 then set the limit to 100 and offset to 50.
 func (dbq *PostgreSQLDatabaseQueries) GetRepositoryCredentialsBatch(ctx context.Context, repositoryCredentials *[]RepositoryCredentials, limit, offSet int) error {
-	return dbq.dbConnection.
+	err := dbq.dbConnection.
 		Model(repositoryCredentials).
 		Order("seq_id ASC").
 		Limit(limit).   // Batch size
 		Offset(offSet). // offset+1 is starting point of batch
 		Context(ctx).
 		Select()
+	if err != nil {
+		return err
+	}
+
+	dbq.decryptRepositoryCredentialsList(ctx, repositoryCredentials)
+
+	return nil
 }
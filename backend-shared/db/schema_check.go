@@ -0,0 +1,394 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The schema check compares the columns of the database types of this package (as described by their 'pg' struct
+// tags) with the columns of the database schema, either as defined in 'db-schema.sql', or as reported by a live
+// database. Without this check, a drift between the two (for example, a column that was added to a struct but not to
+// a migration) only surfaces as a runtime error of the queries that use the column.
+
+// ColumnType is the type of a database column, normalized so that the type of a struct field can be compared with the
+// type of the corresponding column of the database schema (for example, both 'VARCHAR (48)' and 'character varying'
+// are ColumnTypeVarchar).
+type ColumnType string
+
+const (
+	ColumnTypeVarchar   ColumnType = "varchar"
+	ColumnTypeInteger   ColumnType = "integer"
+	ColumnTypeBoolean   ColumnType = "boolean"
+	ColumnTypeTimestamp ColumnType = "timestamp"
+	ColumnTypeJSONB     ColumnType = "jsonb"
+	ColumnTypeBytea     ColumnType = "bytea"
+)
+
+// SchemaColumn is a single column of a database table.
+type SchemaColumn struct {
+	Type ColumnType
+
+	// Origin is where the column is defined, for use in error messages: for example, 'db.Application.Name',
+	// or 'db-schema.sql:298'
+	Origin string
+}
+
+// SchemaTables contains the columns of each database table: table name -> column name -> column.
+// Table and column names are lowercase.
+type SchemaTables map[string]map[string]SchemaColumn
+
+// DisableDBSchemaCheckEnv, if set to 'true', disables the check of the live database schema on startup.
+const DisableDBSchemaCheckEnv = "DISABLE_DB_SCHEMA_CHECK"
+
+// databaseTypes contains a pointer to each type of types.go that is stored in a database table.
+// - When you add a new database type to types.go, you must also add it here.
+var databaseTypes = []any{
+	&GitopsEngineCluster{},
+	&GitopsEngineInstance{},
+	&ManagedEnvironment{},
+	&ClusterCredentials{},
+	&ClusterUser{},
+	&ClusterAccess{},
+	&Operation{},
+	&Application{},
+	&ApplicationState{},
+	&DeploymentToApplicationMapping{},
+	&APICRToDatabaseMapping{},
+	&KubernetesToDBResourceMapping{},
+	&SyncOperation{},
+	&RepositoryCredentials{},
+	&KeyStore{},
+	&ApplicationOwner{},
+	&FeatureFlag{},
+	&DeploymentHistory{},
+	&NamespaceOffboarding{},
+	&MigrationOperation{},
+	&TaskCheckpoint{},
+}
+
+// DatabaseTypeNames returns the name of each type of this package that is stored in a database table.
+func DatabaseTypeNames() []string {
+	res := []string{}
+	for _, databaseType := range databaseTypes {
+		res = append(res, reflect.TypeOf(databaseType).Elem().Name())
+	}
+	return res
+}
+
+// GetSchemaTablesOfDatabaseTypes returns the tables and columns that are expected by the 'pg' struct tags of the
+// database types of this package.
+func GetSchemaTablesOfDatabaseTypes() (SchemaTables, error) {
+
+	res := SchemaTables{}
+
+	for _, databaseType := range databaseTypes {
+
+		structType := reflect.TypeOf(databaseType).Elem()
+
+		tableName := ""
+		columns := map[string]SchemaColumn{}
+
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+
+			tag, exists := field.Tag.Lookup("pg")
+			if !exists || tag == "-" {
+				continue
+			}
+			tagValues := strings.Split(tag, ",")
+
+			// The name of the table is the value of the 'pg' tag of the 'tableName' field, e.g. 'managedenvironment,alias:me'
+			if field.Name == "tableName" {
+				tableName = strings.ToLower(tagValues[0])
+				continue
+			}
+
+			origin := "db." + structType.Name() + "." + field.Name
+
+			columnName := strings.ToLower(tagValues[0])
+			if columnName == "" {
+				return nil, fmt.Errorf("%s: the 'pg' struct tag does not specify a column name", origin)
+			}
+
+			columnType, err := columnTypeOfStructField(field, tagValues[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", origin, err)
+			}
+
+			columns[columnName] = SchemaColumn{Type: columnType, Origin: origin}
+		}
+
+		if tableName == "" {
+			return nil, fmt.Errorf("db.%s: the struct does not have a 'tableName' field with a 'pg' struct tag", structType.Name())
+		}
+
+		res[tableName] = columns
+	}
+
+	return res, nil
+}
+
+// columnTypeOfStructField returns the column type of a struct field: either the type from the 'type:' option of its
+// 'pg' struct tag, or else the type that corresponds to the Go type of the field.
+func columnTypeOfStructField(field reflect.StructField, tagOptions []string) (ColumnType, error) {
+
+	for _, tagOption := range tagOptions {
+		if strings.HasPrefix(tagOption, "type:") {
+			return normalizeSQLColumnType(strings.TrimPrefix(tagOption, "type:")), nil
+		}
+	}
+
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType == reflect.TypeOf(time.Time{}) {
+		return ColumnTypeTimestamp, nil
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return ColumnTypeVarchar, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ColumnTypeInteger, nil
+	case reflect.Bool:
+		return ColumnTypeBoolean, nil
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			return ColumnTypeBytea, nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to determine the column type of Go type '%s': specify it with the 'type:' option of the 'pg' struct tag", field.Type)
+}
+
+// normalizeSQLColumnType converts a SQL column type, either as written in 'db-schema.sql' (e.g. 'VARCHAR (48)',
+// 'serial') or as reported by the 'data_type' of 'information_schema.columns' (e.g. 'character varying'), to a
+// ColumnType. Types that are not recognized are returned as-is (lowercase), so that they are reported as a mismatch.
+func normalizeSQLColumnType(sqlType string) ColumnType {
+
+	sqlType = strings.ToLower(strings.TrimSpace(sqlType))
+
+	// Remove the size of the type, e.g. 'varchar (48)' -> 'varchar'
+	if index := strings.Index(sqlType, "("); index != -1 {
+		sqlType = strings.TrimSpace(sqlType[:index])
+	}
+
+	switch sqlType {
+	case "varchar", "character varying", "text":
+		return ColumnTypeVarchar
+	case "int", "integer", "smallint", "bigint", "serial", "bigserial":
+		return ColumnTypeInteger
+	case "boolean", "bool":
+		return ColumnTypeBoolean
+	case "timestamp", "timestamp without time zone", "timestamptz", "timestamp with time zone":
+		return ColumnTypeTimestamp
+	case "jsonb":
+		return ColumnTypeJSONB
+	case "bytea":
+		return ColumnTypeBytea
+	}
+
+	return ColumnType(sqlType)
+}
+
+// ParseDBSchemaTables returns the tables and columns that are created by the 'CREATE TABLE' statements of the given
+// database schema (the contents of 'db-schema.sql'). 'fileName' is used as the origin of the returned columns.
+func ParseDBSchemaTables(dbSchema string, fileName string) (SchemaTables, error) {
+
+	res := SchemaTables{}
+
+	var tableName string
+	var columns map[string]SchemaColumn
+
+	for lineIndex, line := range strings.Split(dbSchema, "\n") {
+
+		// Remove comments
+		if index := strings.Index(line, "--"); index != -1 {
+			line = line[:index]
+		}
+		line = strings.TrimSpace(line)
+
+		fields := strings.Fields(line)
+
+		if columns == nil {
+			// Look for the beginning of the next table, e.g. 'CREATE TABLE Application ('
+			if len(fields) >= 3 && strings.EqualFold(fields[0], "CREATE") && strings.EqualFold(fields[1], "TABLE") {
+				tableName = strings.ToLower(strings.TrimSuffix(fields[2], "("))
+				columns = map[string]SchemaColumn{}
+			}
+			continue
+		}
+
+		// The end of the table definition
+		if strings.HasPrefix(line, ");") || line == ")" {
+			res[tableName] = columns
+			columns = nil
+			continue
+		}
+
+		if len(fields) < 2 {
+			continue
+		}
+
+		// Table constraints are not columns
+		switch strings.ToUpper(fields[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK":
+			continue
+		}
+
+		columnType := fields[1]
+		if len(fields) > 2 && strings.HasPrefix(fields[2], "(") {
+			// e.g. 'VARCHAR (48)': the size is not part of the column type
+			columnType += fields[2]
+		}
+
+		columns[strings.ToLower(fields[0])] = SchemaColumn{
+			Type:   normalizeSQLColumnType(strings.TrimSuffix(columnType, ",")),
+			Origin: fmt.Sprintf("%s:%d", fileName, lineIndex+1),
+		}
+	}
+
+	if columns != nil {
+		return nil, fmt.Errorf("%s: the definition of table '%s' is not terminated", fileName, tableName)
+	}
+
+	return res, nil
+}
+
+// CompareSchemaTables returns a description of each difference between the tables and columns expected by the database
+// types ('expected'), and the tables and columns of a database schema ('actual'), described by 'actualName'. If
+// 'allowAdditional' is true, tables and columns that are only present in 'actual' are not reported: for example, a live
+// database may contain columns that were added by a newer version of the schema.
+//
+// The differences are sorted by table and column, and an empty slice is returned if there are no differences.
+func CompareSchemaTables(expected SchemaTables, actual SchemaTables, actualName string, allowAdditional bool) []string {
+
+	res := []string{}
+
+	for _, tableName := range sortedKeys(expected, actual) {
+
+		expectedColumns, expectedExists := expected[tableName]
+		actualColumns, actualExists := actual[tableName]
+
+		if !actualExists {
+			res = append(res, fmt.Sprintf("table '%s': not present in %s", tableName, actualName))
+			continue
+		}
+		if !expectedExists {
+			if !allowAdditional {
+				res = append(res, fmt.Sprintf("table '%s': present in %s, but not used by any database type", tableName, actualName))
+			}
+			continue
+		}
+
+		for _, columnName := range sortedKeys(expectedColumns, actualColumns) {
+
+			expectedColumn, expectedExists := expectedColumns[columnName]
+			actualColumn, actualExists := actualColumns[columnName]
+
+			if !actualExists {
+				res = append(res, fmt.Sprintf("table '%s': column '%s' of %s is not present in %s",
+					tableName, columnName, expectedColumn.Origin, actualName))
+
+			} else if !expectedExists {
+				if !allowAdditional {
+					res = append(res, fmt.Sprintf("table '%s': column '%s' (%s) is not a field of any database type",
+						tableName, columnName, actualColumn.Origin))
+				}
+
+			} else if expectedColumn.Type != actualColumn.Type {
+				res = append(res, fmt.Sprintf("table '%s': column '%s' has type '%s' in %s, but type '%s' in %s (%s)",
+					tableName, columnName, expectedColumn.Type, expectedColumn.Origin, actualColumn.Type, actualName, actualColumn.Origin))
+			}
+		}
+	}
+
+	return res
+}
+
+// VerifyDatabaseSchema returns an error describing each difference between the tables and columns expected by the
+// database types, and those of the live database. Tables and columns that are only present in the database are
+// allowed, as they may have been added by a newer version of the schema.
+//
+// The check is skipped if the DISABLE_DB_SCHEMA_CHECK environment variable is 'true'.
+func VerifyDatabaseSchema(ctx context.Context, dbq CloseableQueries) error {
+
+	if strings.EqualFold(os.Getenv(DisableDBSchemaCheckEnv), "true") {
+		return nil
+	}
+
+	expected, err := GetSchemaTablesOfDatabaseTypes()
+	if err != nil {
+		return err
+	}
+
+	actual, err := dbq.GetSchemaTables(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve the schema of the database: %v", err)
+	}
+
+	if differences := CompareSchemaTables(expected, actual, "the database", true); len(differences) > 0 {
+		return fmt.Errorf("the schema of the database does not match the database types:\n- %s", strings.Join(differences, "\n- "))
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) GetSchemaTables(ctx context.Context) (SchemaTables, error) {
+
+	if dbq.dbConnection == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+
+	var columns []struct {
+		TableName  string `pg:"table_name"`
+		ColumnName string `pg:"column_name"`
+		DataType   string `pg:"data_type"`
+	}
+
+	if _, err := dbq.dbConnection.QueryContext(ctx, &columns,
+		"SELECT table_name, column_name, data_type FROM information_schema.columns WHERE table_schema = current_schema()"); err != nil {
+		return nil, err
+	}
+
+	res := SchemaTables{}
+	for _, column := range columns {
+		tableName := strings.ToLower(column.TableName)
+		if _, exists := res[tableName]; !exists {
+			res[tableName] = map[string]SchemaColumn{}
+		}
+		res[tableName][strings.ToLower(column.ColumnName)] = SchemaColumn{
+			Type:   normalizeSQLColumnType(column.DataType),
+			Origin: "data_type '" + column.DataType + "'",
+		}
+	}
+
+	return res, nil
+}
+
+// sortedKeys returns the keys of both maps, sorted and without duplicates.
+func sortedKeys[T any](a map[string]T, b map[string]T) []string {
+
+	keys := map[string]bool{}
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+
+	res := make([]string, 0, len(keys))
+	for key := range keys {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+
+	return res
+}
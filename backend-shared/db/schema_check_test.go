@@ -0,0 +1,139 @@
+package db_test
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("Schema Check Test", func() {
+
+	Context("Comparing the database types with db-schema.sql", func() {
+
+		It("should include every struct of types.go that has a table name", func() {
+
+			fileSet := token.NewFileSet()
+			file, err := parser.ParseFile(fileSet, "types.go", nil, 0)
+			Expect(err).To(BeNil())
+
+			structsWithTableName := []string{}
+			ast.Inspect(file, func(node ast.Node) bool {
+				typeSpec, ok := node.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					return true
+				}
+				for _, field := range structType.Fields.List {
+					for _, name := range field.Names {
+						if name.Name == "tableName" {
+							structsWithTableName = append(structsWithTableName, typeSpec.Name.Name)
+						}
+					}
+				}
+				return true
+			})
+
+			Expect(structsWithTableName).ToNot(BeEmpty())
+			Expect(db.DatabaseTypeNames()).To(ConsistOf(structsWithTableName))
+		})
+
+		It("should match the tables and columns of db-schema.sql", func() {
+
+			expected, err := db.GetSchemaTablesOfDatabaseTypes()
+			Expect(err).To(BeNil())
+
+			dbSchema, err := os.ReadFile("../../db-schema.sql")
+			Expect(err).To(BeNil())
+
+			actual, err := db.ParseDBSchemaTables(string(dbSchema), "db-schema.sql")
+			Expect(err).To(BeNil())
+
+			Expect(db.CompareSchemaTables(expected, actual, "db-schema.sql", false)).To(BeEmpty())
+		})
+
+		It("should report each difference between the database types and the schema", func() {
+
+			expected := db.SchemaTables{
+				"application": {
+					"application_id": {Type: db.ColumnTypeVarchar, Origin: "db.Application.Application_id"},
+					"name":           {Type: db.ColumnTypeVarchar, Origin: "db.Application.Name"},
+					"version":        {Type: db.ColumnTypeInteger, Origin: "db.Application.Version"},
+				},
+				"featureflag": {
+					"enabled": {Type: db.ColumnTypeBoolean, Origin: "db.FeatureFlag.Enabled"},
+				},
+			}
+
+			actual, err := db.ParseDBSchemaTables(strings.Join([]string{
+				"-- Comments are ignored: CREATE TABLE NotATable (",
+				"CREATE TABLE Application (",
+				"	application_id VARCHAR ( 48 ) NOT NULL UNIQUE PRIMARY KEY,",
+				"	version VARCHAR(32) NOT NULL, -- a comment",
+				"	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,",
+				"	CONSTRAINT fk_id FOREIGN KEY (application_id) REFERENCES Other(id),",
+				"	PRIMARY KEY(application_id)",
+				");",
+				"CREATE TABLE Other (",
+				"	id serial",
+				");",
+			}, "\n"), "db-schema.sql")
+			Expect(err).To(BeNil())
+
+			Expect(actual).To(HaveLen(2))
+			Expect(actual["application"]).To(HaveLen(3))
+			Expect(actual["application"]["created_on"]).To(Equal(db.SchemaColumn{Type: db.ColumnTypeTimestamp, Origin: "db-schema.sql:5"}))
+			Expect(actual["other"]["id"].Type).To(Equal(db.ColumnTypeInteger))
+
+			Expect(db.CompareSchemaTables(expected, actual, "db-schema.sql", false)).To(Equal([]string{
+				"table 'application': column 'created_on' (db-schema.sql:5) is not a field of any database type",
+				"table 'application': column 'name' of db.Application.Name is not present in db-schema.sql",
+				"table 'application': column 'version' has type 'integer' in db.Application.Version, but type 'varchar' in db-schema.sql (db-schema.sql:4)",
+				"table 'featureflag': not present in db-schema.sql",
+				"table 'other': present in db-schema.sql, but not used by any database type",
+			}))
+
+			By("verifying that additional tables and columns are not reported, if they are allowed")
+			Expect(db.CompareSchemaTables(expected, actual, "the database", true)).To(Equal([]string{
+				"table 'application': column 'name' of db.Application.Name is not present in the database",
+				"table 'application': column 'version' has type 'integer' in db.Application.Version, but type 'varchar' in the database (db-schema.sql:4)",
+				"table 'featureflag': not present in the database",
+			}))
+		})
+
+		It("should return an error if a table definition is not terminated", func() {
+
+			_, err := db.ParseDBSchemaTables("CREATE TABLE Application (\n	application_id VARCHAR (48)\n", "db-schema.sql")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("Comparing the database types with the live database", func() {
+
+		It("should match the tables and columns of the database", func() {
+
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+
+			tables, err := dbq.GetSchemaTables(context.Background())
+			Expect(err).To(BeNil())
+			Expect(tables["application"]["spec_field"].Type).To(Equal(db.ColumnTypeVarchar))
+			Expect(tables["applicationstate"]["resources"].Type).To(Equal(db.ColumnTypeBytea))
+
+			Expect(db.VerifyDatabaseSchema(context.Background(), dbq)).To(Succeed())
+		})
+	})
+})
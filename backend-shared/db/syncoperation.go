@@ -11,6 +11,23 @@ const (
 	SyncOperation_DesiredState_Terminated = "Terminated"
 )
 
+// Values of the SyncOperation.Initiator field, and of the 'lastSync.initiator' field of the GitOpsDeployment status
+const (
+	// SyncOperation_Initiator_Automated indicates the sync was triggered by the automated sync policy of the GitOpsDeployment
+	SyncOperation_Initiator_Automated = "automated"
+
+	// SyncOperation_Initiator_Webhook indicates the sync was triggered by the automated sync policy of the GitOpsDeployment,
+	// in response to a Git webhook event (a commit pushed to the repository)
+	SyncOperation_Initiator_Webhook = "webhook"
+
+	// SyncOperation_Initiator_SyncRunPrefix is followed by the name of the GitOpsDeploymentSyncRun that triggered the sync
+	SyncOperation_Initiator_SyncRunPrefix = "GitOpsDeploymentSyncRun/"
+
+	// SyncOperation_Initiator_UserPrefix is followed by the name of the user that triggered the sync outside of the
+	// GitOps Service, for example via the Argo CD Web UI/CLI.
+	SyncOperation_Initiator_UserPrefix = "user/"
+)
+
 func (dbq *PostgreSQLDatabaseQueries) GetSyncOperationById(ctx context.Context, syncOperation *SyncOperation) error {
 
 	if err := validateQueryParamsEntity(syncOperation, dbq); err != nil {
@@ -158,7 +175,7 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateSyncOperationRemoveApplicationField(
 		Application_id: applicationId,
 	}
 
-	res, err := dbq.dbConnection.Model(&operation).Set("application_id = ?", nil).Where("application_id = ?", applicationId).Update()
+	res, err := dbq.dbConnection.Model(&operation).Set("application_id = ?", nil).Where("application_id = ?", applicationId).Context(ctx).Update()
 
 	if err != nil {
 		return 0, err
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (dbq *PostgreSQLDatabaseQueries) CreateTaskCheckpoint(ctx context.Context, obj *TaskCheckpoint) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("CreateTaskCheckpoint",
+		"TaskName", obj.TaskName,
+		"Checkpoint", obj.Checkpoint); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	obj.Created_on = time.Now()
+	obj.Last_updated = obj.Created_on
+
+	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
+	if err != nil {
+		return fmt.Errorf("error on inserting task checkpoint: %v", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d", result.RowsAffected())
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) GetTaskCheckpointByTaskName(ctx context.Context, obj *TaskCheckpoint) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if IsEmpty(obj.TaskName) {
+		return fmt.Errorf("task checkpoint task name is empty")
+	}
+
+	var dbResults []TaskCheckpoint
+
+	if err := dbq.dbConnection.Model(&dbResults).
+		Where("tc.task_name = ?", obj.TaskName).
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving GetTaskCheckpointByTaskName: %v", err)
+	}
+
+	if len(dbResults) >= 2 {
+		return fmt.Errorf("multiple results returned from GetTaskCheckpointByTaskName")
+	}
+
+	if len(dbResults) == 0 {
+		return NewResultNotFoundError("no results found for GetTaskCheckpointByTaskName")
+	}
+
+	*obj = dbResults[0]
+
+	return nil
+}
+
+// ListTaskCheckpoints returns all the TaskCheckpoint rows, that is, the progress of every task that has not yet
+// completed, from the oldest to the newest.
+func (dbq *PostgreSQLDatabaseQueries) ListTaskCheckpoints(ctx context.Context, taskCheckpoints *[]TaskCheckpoint) error {
+
+	if err := validateQueryParamsNoPK(dbq); err != nil {
+		return err
+	}
+
+	if err := dbq.dbConnection.Model(taskCheckpoints).
+		Order("seq_id ASC").
+		Context(ctx).
+		Select(); err != nil {
+
+		return fmt.Errorf("error on retrieving ListTaskCheckpoints: %v", err)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) UpdateTaskCheckpoint(ctx context.Context, obj *TaskCheckpoint) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("UpdateTaskCheckpoint",
+		"TaskName", obj.TaskName,
+		"Checkpoint", obj.Checkpoint); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	obj.Last_updated = time.Now()
+
+	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
+	if err != nil {
+		return fmt.Errorf("error on updating task checkpoint: %v, %v", err, obj.TaskName)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d, %v", result.RowsAffected(), obj.TaskName)
+	}
+
+	return nil
+}
+
+func (dbq *PostgreSQLDatabaseQueries) DeleteTaskCheckpointByTaskName(ctx context.Context, taskName string) (int, error) {
+
+	if err := validateQueryParams(taskName, dbq); err != nil {
+		return 0, err
+	}
+
+	result := &TaskCheckpoint{}
+
+	deleteResult, err := dbq.dbConnection.Model(result).
+		Where("tc.task_name = ?", taskName).
+		Context(ctx).
+		Delete()
+
+	if err != nil {
+		return 0, fmt.Errorf("error on deleting task checkpoint: %v", err)
+	}
+
+	return deleteResult.RowsAffected(), nil
+}
+
+var _ DisposableResource = &TaskCheckpoint{}
+
+func (obj *TaskCheckpoint) Dispose(ctx context.Context, dbq DatabaseQueries) error {
+	if dbq == nil {
+		return fmt.Errorf("missing database interface in TaskCheckpoint dispose")
+	}
+
+	_, err := dbq.DeleteTaskCheckpointByTaskName(ctx, obj.TaskName)
+	return err
+}
+
+// GetAsLogKeyValues returns an []interface that can be passed to log.Info(...).
+// e.g. log.Info("Creating database resource", obj.GetAsLogKeyValues()...)
+func (obj *TaskCheckpoint) GetAsLogKeyValues() []interface{} {
+	if obj == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{"taskName", obj.TaskName,
+		"checkpoint", obj.Checkpoint}
+}
@@ -0,0 +1,82 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("TaskCheckpoint Tests", func() {
+
+	var (
+		ctx context.Context
+		dbq db.AllDatabaseQueries
+	)
+
+	BeforeEach(func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		dbq.CloseDatabase()
+	})
+
+	It("should create, get, update, list and delete TaskCheckpoints", func() {
+
+		checkpoint := db.TaskCheckpoint{
+			TaskName:   "test-task",
+			Checkpoint: `{"step":"first"}`,
+		}
+		err := dbq.CreateTaskCheckpoint(ctx, &checkpoint)
+		Expect(err).To(BeNil())
+
+		By("verifying a checkpoint of the same task cannot be created")
+		duplicate := checkpoint
+		err = dbq.CreateTaskCheckpoint(ctx, &duplicate)
+		Expect(err).ToNot(BeNil())
+
+		fetchRow := db.TaskCheckpoint{TaskName: checkpoint.TaskName}
+		err = dbq.GetTaskCheckpointByTaskName(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.Checkpoint).To(Equal(`{"step":"first"}`))
+
+		By("updating the checkpoint")
+		previousUpdate := fetchRow.Last_updated
+		fetchRow.Checkpoint = `{"step":"second","cursor":100}`
+		err = dbq.UpdateTaskCheckpoint(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+
+		var checkpoints []db.TaskCheckpoint
+		err = dbq.ListTaskCheckpoints(ctx, &checkpoints)
+		Expect(err).To(BeNil())
+		Expect(checkpoints).To(ContainElement(HaveField("Checkpoint", `{"step":"second","cursor":100}`)))
+
+		err = dbq.GetTaskCheckpointByTaskName(ctx, &fetchRow)
+		Expect(err).To(BeNil())
+		Expect(fetchRow.Last_updated).ToNot(BeTemporally("<", previousUpdate))
+
+		By("verifying the field lengths are validated")
+		tooLong := db.TaskCheckpoint{
+			TaskName:   "test-other-task",
+			Checkpoint: strings.Repeat("a", db.TaskCheckpointCheckpointLength+1),
+		}
+		err = dbq.CreateTaskCheckpoint(ctx, &tooLong)
+		Expect(db.IsMaxLengthError(err)).To(BeTrue())
+
+		rowsAffected, err := dbq.DeleteTaskCheckpointByTaskName(ctx, checkpoint.TaskName)
+		Expect(err).To(BeNil())
+		Expect(rowsAffected).To(Equal(1))
+
+		err = dbq.GetTaskCheckpointByTaskName(ctx, &fetchRow)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,312 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Tenant identifies the owner of a set of GitOpsDeployments (and their Applications): the API namespace containing
+// the GitOpsDeployments, and the ClusterUser of that namespace.
+type Tenant struct {
+	// NamespaceUID is the UID (.metadata.uid) of the API namespace. Required.
+	NamespaceUID string
+
+	// ClusterUserID is the ClusterUser of the API namespace. Optional: if empty, only Applications that are referenced
+	// by a DeploymentToApplicationMapping in the namespace are accessible, and new Applications may not be created.
+	ClusterUserID string
+}
+
+// TenantScopedQueries are ApplicationScopedQueries that may only access the Applications (and the ApplicationStates,
+// DeploymentToApplicationMappings, and DeploymentHistory of those Applications) that are owned by a single tenant.
+//
+// An Application is owned by a tenant if it is referenced by a DeploymentToApplicationMapping in the tenant's namespace,
+// or if it has an ApplicationOwner row for the tenant's ClusterUser. The ownership check is part of the database query
+// (see GetTenantApplicationById), so that a code path in the backend cannot accidentally read (or modify) the Applications
+// of another tenant: an Application of another tenant is reported as not found.
+//
+// The queries for other database resources (for example, Operations) are not scoped.
+type TenantScopedQueries interface {
+	ApplicationScopedQueries
+
+	// GetTenant returns the tenant that the queries are scoped to.
+	GetTenant() Tenant
+}
+
+// NewTenantScopedQueries returns TenantScopedQueries that wrap 'dbQueries', and are scoped to 'tenant'.
+func NewTenantScopedQueries(dbQueries ApplicationScopedQueries, tenant Tenant) (TenantScopedQueries, error) {
+
+	if dbQueries == nil {
+		return nil, fmt.Errorf("database queries are nil in NewTenantScopedQueries")
+	}
+
+	if IsEmpty(tenant.NamespaceUID) {
+		return nil, fmt.Errorf("tenant namespace UID is required in NewTenantScopedQueries")
+	}
+
+	// Avoid wrapping the queries twice, if they are already scoped to the same tenant
+	if tenantQueries, ok := dbQueries.(*tenantScopedQueries); ok {
+		if tenantQueries.tenant != tenant {
+			return nil, fmt.Errorf("database queries are already scoped to a different tenant")
+		}
+		return tenantQueries, nil
+	}
+
+	return &tenantScopedQueries{ApplicationScopedQueries: dbQueries, tenant: tenant}, nil
+}
+
+var _ TenantScopedQueries = &tenantScopedQueries{}
+
+type tenantScopedQueries struct {
+	ApplicationScopedQueries
+
+	tenant Tenant
+}
+
+func (t *tenantScopedQueries) GetTenant() Tenant {
+	return t.tenant
+}
+
+// isOwnedByTenant returns true if the Application is owned by the tenant, or false if it doesn't exist or is owned by
+// another tenant.
+func (t *tenantScopedQueries) isOwnedByTenant(ctx context.Context, applicationID string) (bool, error) {
+
+	application := Application{Application_id: applicationID}
+	if err := t.ApplicationScopedQueries.GetTenantApplicationById(ctx, &application, t.tenant); err != nil {
+		if IsResultNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// verifyOwnedByTenant returns a not found error if the Application is not owned by the tenant.
+func (t *tenantScopedQueries) verifyOwnedByTenant(ctx context.Context, applicationID string) error {
+
+	owned, err := t.isOwnedByTenant(ctx, applicationID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return NewResultNotFoundError(fmt.Sprintf("Application '%s'", applicationID))
+	}
+
+	return nil
+}
+
+// verifyNamespaceOfTenant returns an error if the namespace UID is not the tenant's namespace.
+func (t *tenantScopedQueries) verifyNamespaceOfTenant(namespaceUID string) error {
+	if namespaceUID != t.tenant.NamespaceUID {
+		return NewAccessDeniedError(fmt.Sprintf("namespace '%s' is not the namespace of the tenant", namespaceUID))
+	}
+	return nil
+}
+
+// CreateApplication creates the Application, and an ApplicationOwner row for the tenant's ClusterUser.
+func (t *tenantScopedQueries) CreateApplication(ctx context.Context, obj *Application) error {
+
+	if IsEmpty(t.tenant.ClusterUserID) {
+		return fmt.Errorf("tenant cluster user is required to create an Application")
+	}
+
+	if err := t.ApplicationScopedQueries.CreateApplication(ctx, obj); err != nil {
+		return err
+	}
+
+	applicationOwner := ApplicationOwner{
+		ApplicationID: obj.Application_id,
+		ClusterUserID: t.tenant.ClusterUserID,
+	}
+	if err := t.ApplicationScopedQueries.CreateApplicationOwner(ctx, &applicationOwner); err != nil {
+		return fmt.Errorf("unable to create owner of Application '%s': %v", obj.Application_id, err)
+	}
+
+	return nil
+}
+
+func (t *tenantScopedQueries) CheckedCreateApplication(ctx context.Context, obj *Application, ownerId string) error {
+	if ownerId != t.tenant.ClusterUserID {
+		return NewAccessDeniedError(fmt.Sprintf("cluster user '%s' is not the cluster user of the tenant", ownerId))
+	}
+	return t.CreateApplication(ctx, obj)
+}
+
+func (t *tenantScopedQueries) GetApplicationById(ctx context.Context, application *Application) error {
+	return t.ApplicationScopedQueries.GetTenantApplicationById(ctx, application, t.tenant)
+}
+
+func (t *tenantScopedQueries) GetTenantApplicationById(ctx context.Context, application *Application, tenant Tenant) error {
+	if tenant != t.tenant {
+		return NewAccessDeniedError("tenant does not match the tenant of the queries")
+	}
+	return t.GetApplicationById(ctx, application)
+}
+
+func (t *tenantScopedQueries) UpdateApplication(ctx context.Context, obj *Application) error {
+	if err := t.verifyOwnedByTenant(ctx, obj.Application_id); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.UpdateApplication(ctx, obj)
+}
+
+func (t *tenantScopedQueries) CompareAndSwapApplication(ctx context.Context, obj *Application) error {
+	if err := t.verifyOwnedByTenant(ctx, obj.Application_id); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.CompareAndSwapApplication(ctx, obj)
+}
+
+// DeleteApplicationById deletes the Application, if it is owned by the tenant. Returns 0 if it is not.
+func (t *tenantScopedQueries) DeleteApplicationById(ctx context.Context, id string) (int, error) {
+	if owned, err := t.isOwnedByTenant(ctx, id); err != nil || !owned {
+		return 0, err
+	}
+	return t.ApplicationScopedQueries.DeleteApplicationById(ctx, id)
+}
+
+func (t *tenantScopedQueries) CheckedDeleteApplicationById(ctx context.Context, id string, ownerId string) (int, error) {
+	if owned, err := t.isOwnedByTenant(ctx, id); err != nil || !owned {
+		return 0, err
+	}
+	return t.ApplicationScopedQueries.CheckedDeleteApplicationById(ctx, id, ownerId)
+}
+
+func (t *tenantScopedQueries) SoftDeleteApplicationById(ctx context.Context, id string) (int, error) {
+	if owned, err := t.isOwnedByTenant(ctx, id); err != nil || !owned {
+		return 0, err
+	}
+	return t.ApplicationScopedQueries.SoftDeleteApplicationById(ctx, id)
+}
+
+func (t *tenantScopedQueries) RestoreApplicationById(ctx context.Context, id string) (int, error) {
+	if owned, err := t.isOwnedByTenant(ctx, id); err != nil || !owned {
+		return 0, err
+	}
+	return t.ApplicationScopedQueries.RestoreApplicationById(ctx, id)
+}
+
+// GetApplicationBatch is not supported, as it would return the Applications of all tenants.
+func (t *tenantScopedQueries) GetApplicationBatch(ctx context.Context, applications *[]Application, limit, offSet int) error {
+	return fmt.Errorf("GetApplicationBatch is not supported by tenant-scoped queries")
+}
+
+func (t *tenantScopedQueries) ListApplicationOwnersByApplicationID(ctx context.Context, applicationID string,
+	applicationOwners *[]ApplicationOwner) error {
+
+	if err := t.verifyOwnedByTenant(ctx, applicationID); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.ListApplicationOwnersByApplicationID(ctx, applicationID, applicationOwners)
+}
+
+func (t *tenantScopedQueries) ListDeploymentHistoryByApplicationID(ctx context.Context, applicationID string, limit int,
+	deploymentHistory *[]DeploymentHistory) error {
+
+	if err := t.verifyOwnedByTenant(ctx, applicationID); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.ListDeploymentHistoryByApplicationID(ctx, applicationID, limit, deploymentHistory)
+}
+
+func (t *tenantScopedQueries) UpdateSyncOperationRemoveApplicationField(ctx context.Context, applicationId string) (int, error) {
+	if owned, err := t.isOwnedByTenant(ctx, applicationId); err != nil || !owned {
+		return 0, err
+	}
+	return t.ApplicationScopedQueries.UpdateSyncOperationRemoveApplicationField(ctx, applicationId)
+}
+
+func (t *tenantScopedQueries) GetApplicationStateById(ctx context.Context, obj *ApplicationState) error {
+	if err := t.verifyOwnedByTenant(ctx, obj.Applicationstate_application_id); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.GetApplicationStateById(ctx, obj)
+}
+
+func (t *tenantScopedQueries) DeleteApplicationStateById(ctx context.Context, id string) (int, error) {
+	if owned, err := t.isOwnedByTenant(ctx, id); err != nil || !owned {
+		return 0, err
+	}
+	return t.ApplicationScopedQueries.DeleteApplicationStateById(ctx, id)
+}
+
+func (t *tenantScopedQueries) CreateDeploymentToApplicationMapping(ctx context.Context, obj *DeploymentToApplicationMapping) error {
+	if err := t.verifyNamespaceOfTenant(obj.NamespaceUID); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.CreateDeploymentToApplicationMapping(ctx, obj)
+}
+
+// GetDeploymentToApplicationMappingByDeplId retrieves the DeploymentToApplicationMapping, if it is in the tenant's namespace.
+func (t *tenantScopedQueries) GetDeploymentToApplicationMappingByDeplId(ctx context.Context,
+	deplToAppMappingParam *DeploymentToApplicationMapping) error {
+
+	deplToAppMapping := DeploymentToApplicationMapping{
+		Deploymenttoapplicationmapping_uid_id: deplToAppMappingParam.Deploymenttoapplicationmapping_uid_id,
+	}
+	if err := t.ApplicationScopedQueries.GetDeploymentToApplicationMappingByDeplId(ctx, &deplToAppMapping); err != nil {
+		return err
+	}
+
+	if deplToAppMapping.NamespaceUID != t.tenant.NamespaceUID {
+		return NewResultNotFoundError(fmt.Sprintf("DeploymentToApplicationMapping '%s'",
+			deplToAppMappingParam.Deploymenttoapplicationmapping_uid_id))
+	}
+
+	*deplToAppMappingParam = deplToAppMapping
+
+	return nil
+}
+
+func (t *tenantScopedQueries) ListDeploymentToApplicationMappingByNamespaceAndName(ctx context.Context, deploymentName string,
+	deploymentNamespace string, namespaceUID string, deplToAppMappingParam *[]DeploymentToApplicationMapping) error {
+
+	if err := t.verifyNamespaceOfTenant(namespaceUID); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.ListDeploymentToApplicationMappingByNamespaceAndName(ctx, deploymentName,
+		deploymentNamespace, namespaceUID, deplToAppMappingParam)
+}
+
+func (t *tenantScopedQueries) ListDeploymentToApplicationMappingByNamespaceUID(ctx context.Context, namespaceUID string,
+	deplToAppMappingParam *[]DeploymentToApplicationMapping) error {
+
+	if err := t.verifyNamespaceOfTenant(namespaceUID); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.ListDeploymentToApplicationMappingByNamespaceUID(ctx, namespaceUID, deplToAppMappingParam)
+}
+
+func (t *tenantScopedQueries) ListDeploymentToApplicationMappingsForNamespaceUID(ctx context.Context, namespaceUID string,
+	afterSeqID int64, limit int, deplToAppMappingParam *[]DeploymentToApplicationMapping) error {
+
+	if err := t.verifyNamespaceOfTenant(namespaceUID); err != nil {
+		return err
+	}
+	return t.ApplicationScopedQueries.ListDeploymentToApplicationMappingsForNamespaceUID(ctx, namespaceUID, afterSeqID, limit, deplToAppMappingParam)
+}
+
+// DeleteDeploymentToApplicationMappingByDeplId deletes the DeploymentToApplicationMapping, if it is in the tenant's
+// namespace. Returns 0 if it is not.
+func (t *tenantScopedQueries) DeleteDeploymentToApplicationMappingByDeplId(ctx context.Context, id string) (int, error) {
+
+	deplToAppMapping := DeploymentToApplicationMapping{Deploymenttoapplicationmapping_uid_id: id}
+	if err := t.GetDeploymentToApplicationMappingByDeplId(ctx, &deplToAppMapping); err != nil {
+		if IsResultNotFoundError(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return t.ApplicationScopedQueries.DeleteDeploymentToApplicationMappingByDeplId(ctx, id)
+}
+
+func (t *tenantScopedQueries) DeleteDeploymentToApplicationMappingByNamespaceAndName(ctx context.Context, deploymentName string,
+	deploymentNamespace string, namespaceUID string) (int, error) {
+
+	if err := t.verifyNamespaceOfTenant(namespaceUID); err != nil {
+		return 0, err
+	}
+	return t.ApplicationScopedQueries.DeleteDeploymentToApplicationMappingByNamespaceAndName(ctx, deploymentName,
+		deploymentNamespace, namespaceUID)
+}
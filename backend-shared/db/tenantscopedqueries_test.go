@@ -0,0 +1,187 @@
+package db_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("TenantScopedQueries Tests", func() {
+
+	const (
+		tenantNamespaceUID      = "test-tenant-namespace-uid"
+		otherTenantNamespaceUID = "test-other-tenant-namespace-uid"
+	)
+
+	var (
+		ctx                context.Context
+		dbq                db.AllDatabaseQueries
+		clusterUser        db.ClusterUser
+		engineInstance     *db.GitopsEngineInstance
+		managedEnvironment *db.ManagedEnvironment
+		tenantQueries      db.TenantScopedQueries
+	)
+
+	// createApplicationOfNamespace creates an Application, and a DeploymentToApplicationMapping that references it from
+	// a GitOpsDeployment in the namespace.
+	createApplicationOfNamespace := func(applicationID string, namespaceUID string) db.Application {
+		application := db.Application{
+			Application_id:          applicationID,
+			Name:                    applicationID,
+			Spec_field:              "{}",
+			Engine_instance_inst_id: engineInstance.Gitopsengineinstance_id,
+			Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+		}
+		Expect(dbq.CreateApplication(ctx, &application)).To(Succeed())
+
+		Expect(dbq.CreateDeploymentToApplicationMapping(ctx, &db.DeploymentToApplicationMapping{
+			Deploymenttoapplicationmapping_uid_id: "dtam-" + applicationID,
+			DeploymentName:                        applicationID,
+			DeploymentNamespace:                   "my-namespace",
+			NamespaceUID:                          namespaceUID,
+			Application_id:                        application.Application_id,
+		})).To(Succeed())
+
+		return application
+	}
+
+	BeforeEach(func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+
+		_, managedEnvironment, _, engineInstance, _, err = db.CreateSampleData(dbq)
+		Expect(err).To(BeNil())
+
+		clusterUser = db.ClusterUser{
+			Clusteruser_id: "test-tenant-user",
+			User_name:      "test-tenant-user",
+		}
+		Expect(dbq.CreateClusterUser(ctx, &clusterUser)).To(Succeed())
+
+		tenantQueries, err = db.NewTenantScopedQueries(dbq, db.Tenant{
+			NamespaceUID:  tenantNamespaceUID,
+			ClusterUserID: clusterUser.Clusteruser_id,
+		})
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		dbq.CloseDatabase()
+	})
+
+	It("should require the namespace UID of the tenant", func() {
+		_, err := db.NewTenantScopedQueries(dbq, db.Tenant{ClusterUserID: clusterUser.Clusteruser_id})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("should only retrieve the Applications and ApplicationStates of the tenant", func() {
+		tenantApplication := createApplicationOfNamespace("test-tenant-application", tenantNamespaceUID)
+		otherApplication := createApplicationOfNamespace("test-other-tenant-application", otherTenantNamespaceUID)
+
+		for _, application := range []db.Application{tenantApplication, otherApplication} {
+			Expect(dbq.CreateApplicationState(ctx, &db.ApplicationState{
+				Applicationstate_application_id: application.Application_id,
+				Health:                          string(db.ApplicationStateHealth_Healthy),
+				Sync_Status:                     string(db.ApplicationStateSyncStatus_Synced),
+				ReconciledState:                 "test-reconciledState",
+			})).To(Succeed())
+		}
+
+		By("retrieving the Application and ApplicationState of the tenant")
+		application := db.Application{Application_id: tenantApplication.Application_id}
+		Expect(tenantQueries.GetApplicationById(ctx, &application)).To(Succeed())
+		Expect(application.Name).To(Equal(tenantApplication.Name))
+
+		applicationState := db.ApplicationState{Applicationstate_application_id: tenantApplication.Application_id}
+		Expect(tenantQueries.GetApplicationStateById(ctx, &applicationState)).To(Succeed())
+
+		By("reporting the Application and ApplicationState of the other tenant as not found")
+		application = db.Application{Application_id: otherApplication.Application_id}
+		err := tenantQueries.GetApplicationById(ctx, &application)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+		applicationState = db.ApplicationState{Applicationstate_application_id: otherApplication.Application_id}
+		err = tenantQueries.GetApplicationStateById(ctx, &applicationState)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+		dtam := db.DeploymentToApplicationMapping{Deploymenttoapplicationmapping_uid_id: "dtam-" + otherApplication.Application_id}
+		err = tenantQueries.GetDeploymentToApplicationMappingByDeplId(ctx, &dtam)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+		dtams := []db.DeploymentToApplicationMapping{}
+		err = tenantQueries.ListDeploymentToApplicationMappingByNamespaceUID(ctx, otherTenantNamespaceUID, &dtams)
+		Expect(db.IsAccessDeniedError(err)).To(BeTrue())
+	})
+
+	It("should not modify or delete the Applications of another tenant", func() {
+		otherApplication := createApplicationOfNamespace("test-other-tenant-application", otherTenantNamespaceUID)
+
+		otherApplication.Spec_field = "{\"modified\": true}"
+		err := tenantQueries.UpdateApplication(ctx, &otherApplication)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+		rowsDeleted, err := tenantQueries.DeleteDeploymentToApplicationMappingByDeplId(ctx, "dtam-"+otherApplication.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsDeleted).To(Equal(0))
+
+		rowsDeleted, err = tenantQueries.DeleteApplicationById(ctx, otherApplication.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsDeleted).To(Equal(0))
+
+		application := db.Application{Application_id: otherApplication.Application_id}
+		Expect(dbq.GetApplicationById(ctx, &application)).To(Succeed())
+		Expect(application.Spec_field).To(Equal("{}"))
+	})
+
+	It("should create an ApplicationOwner with each Application, so that the Application remains accessible once its DeploymentToApplicationMapping is deleted", func() {
+		application := db.Application{
+			Application_id:          "test-tenant-application",
+			Name:                    "test-tenant-application",
+			Spec_field:              "{}",
+			Engine_instance_inst_id: engineInstance.Gitopsengineinstance_id,
+			Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+		}
+		Expect(tenantQueries.CreateApplication(ctx, &application)).To(Succeed())
+
+		applicationOwner := db.ApplicationOwner{ApplicationID: application.Application_id, ClusterUserID: clusterUser.Clusteruser_id}
+		Expect(dbq.GetApplicationOwnerByPrimaryKey(ctx, &applicationOwner)).To(Succeed())
+
+		dtam := db.DeploymentToApplicationMapping{
+			Deploymenttoapplicationmapping_uid_id: "dtam-" + application.Application_id,
+			DeploymentName:                        application.Name,
+			DeploymentNamespace:                   "my-namespace",
+			NamespaceUID:                          tenantNamespaceUID,
+			Application_id:                        application.Application_id,
+		}
+		Expect(tenantQueries.CreateDeploymentToApplicationMapping(ctx, &dtam)).To(Succeed())
+
+		By("deleting the DeploymentToApplicationMapping, and then the Application, as when a GitOpsDeployment is deleted")
+		rowsDeleted, err := tenantQueries.DeleteDeploymentToApplicationMappingByDeplId(ctx, dtam.Deploymenttoapplicationmapping_uid_id)
+		Expect(err).To(BeNil())
+		Expect(rowsDeleted).To(Equal(1))
+
+		rowsDeleted, err = tenantQueries.DeleteApplicationById(ctx, application.Application_id)
+		Expect(err).To(BeNil())
+		Expect(rowsDeleted).To(Equal(1))
+	})
+
+	It("should not create the DeploymentToApplicationMappings of another namespace", func() {
+		application := createApplicationOfNamespace("test-tenant-application", tenantNamespaceUID)
+
+		err := tenantQueries.CreateDeploymentToApplicationMapping(ctx, &db.DeploymentToApplicationMapping{
+			Deploymenttoapplicationmapping_uid_id: "test-other-dtam",
+			DeploymentName:                        "other",
+			DeploymentNamespace:                   "other-namespace",
+			NamespaceUID:                          otherTenantNamespaceUID,
+			Application_id:                        application.Application_id,
+		})
+		Expect(db.IsAccessDeniedError(err)).To(BeTrue())
+	})
+})
@@ -47,6 +47,14 @@ type GitopsEngineInstance struct {
 	// -- Reference to the Argo CD cluster containing the instance
 	// -- Foreign key to: GitopsEngineCluster.gitopsenginecluster_id
 	EngineCluster_id string `pg:"enginecluster_id"`
+
+	// -- The maximum number of Applications that may be assigned to the instance: once reached, new Applications are
+	// -- assigned to another instance on the same cluster (if one has capacity). 0 if the instance has no limit.
+	Max_applications int `pg:"max_applications,use_zero"`
+
+	// -- The version of Argo CD of the instance (for example, 'v2.6.3'), as last detected by the cluster-agent. Empty if
+	// -- the version is not known. Features that the version does not support are skipped (see argocd.GetArgoCDCapabilities).
+	Argocd_version string `pg:"argocd_version"`
 }
 
 // ManagedEnvironment is an environment (eg a user's cluster, or a subset of that cluster) that they want to deploy applications to, using Argo CD
@@ -66,6 +74,26 @@ type ManagedEnvironment struct {
 
 	// -- Created_on field will tell us how old resources are
 	Created_on time.Time `pg:"created_on"`
+
+	// -- Version is incremented on every update of the row, and is used by CompareAndSwapManagedEnvironment to detect
+	// -- concurrent modification.
+	Version int64 `pg:"version,use_zero"`
+
+	// -- Kubernetes_version is the version of Kubernetes on the cluster (for example, 'v1.25.3'), as discovered when
+	// -- the GitOps Service last connected to it. Empty if not yet discovered.
+	Kubernetes_version string `pg:"kubernetes_version"`
+
+	// -- Api_groups is a sorted, comma-separated list of the API groups available on the cluster (not including the
+	// -- core API group), as discovered when the GitOps Service last connected to it. Empty if not yet discovered.
+	Api_groups string `pg:"api_groups"`
+
+	// -- Disconnected is true if the user has disconnected the managed environment: the Argo CD cluster secret of the
+	// -- managed environment is removed, but the Applications that target it are retained.
+	Disconnected bool `pg:"disconnected,use_zero"`
+
+	// -- Cluster_labels are the labels that describe the topology of the cluster (for example, its region, cloud and tier),
+	// -- from .spec.clusterLabels of the GitOpsDeploymentManagedEnvironment. Stored as a JSONB object: nil if there are no labels.
+	Cluster_labels map[string]string `pg:"cluster_labels,type:jsonb"`
 }
 
 // ClusterCredentials contains the credentials required to access a K8s cluster.
@@ -124,6 +152,32 @@ type ClusterCredentials struct {
 
 	// -- Created_on field will tell us how old resources are
 	Created_on time.Time `pg:"created_on"`
+
+	// -- Optional: the value of .spec.rotateCredentialsRequestedAt of the GitOpsDeploymentManagedEnvironment (in RFC3339 format),
+	// -- at the time these cluster credentials were created. Used to detect when the user has requested a credential rotation.
+	Rotation_requested_at string `pg:"rotation_requested_at"`
+
+	// -- Optional: the CPU quota hint for the namespaces deployed to with these cluster credentials (Kubernetes quantity format).
+	// -- - Corresponds to .spec.namespaceQuota.cpu of the GitOpsDeploymentManagedEnvironment.
+	Namespace_quota_cpu string `pg:"namespace_quota_cpu"`
+
+	// -- Optional: the memory quota hint for the namespaces deployed to with these cluster credentials (Kubernetes quantity format).
+	// -- - Corresponds to .spec.namespaceQuota.memory of the GitOpsDeploymentManagedEnvironment.
+	Namespace_quota_memory string `pg:"namespace_quota_memory"`
+
+	// -- Optional: the name of the existing Argo CD cluster secret that these cluster credentials were read from.
+	// -- - Corresponds to .spec.argoCDClusterSecret of the GitOpsDeploymentManagedEnvironment.
+	Argocd_cluster_secret string `pg:"argocd_cluster_secret"`
+
+	// -- Optional: the URL of the HTTP(S) proxy to connect to the cluster through. If empty, the proxy environment
+	// -- variables (HTTPS_PROXY/NO_PROXY) of the component connecting to the cluster are used.
+	// -- - Corresponds to .spec.proxyURL of the GitOpsDeploymentManagedEnvironment.
+	Proxy_url string `pg:"proxy_url"`
+
+	// -- Optional: a hash of the data of the Secret that these cluster credentials were read from (see
+	// -- sharedutil.HashSecretData). Used to detect when the contents of the Secret have changed (for example, when a
+	// -- token is refreshed), so that the credentials may be rotated.
+	Secret_data_hash string `pg:"secret_data_hash"`
 }
 
 // ClusterUser is an individual user/customer
@@ -139,6 +193,10 @@ type ClusterUser struct {
 
 	// -- Created_on field will tell us how old resources are
 	Created_on time.Time `pg:"created_on"`
+
+	// Deactivated_on is set when the ClusterUser has been deactivated (offboarded), and is nil otherwise. A deactivated
+	// user has no ClusterAccess, and may be purged once its RepositoryCredentials and Applications have been deleted.
+	Deactivated_on *time.Time `pg:"deactivated_on"`
 }
 
 type ClusterAccess struct {
@@ -238,6 +296,13 @@ type Operation struct {
 
 	// -- Amount of time to wait in seconds after last_state_update for a completed/failed operation to be garbage collected.
 	GC_expiration_time int `pg:"gc_expiration_time"`
+
+	// The identity of the cluster-agent replica that is processing the operation, while it is In_Progress.
+	Processing_owner string `pg:"processing_owner"`
+
+	// Set when an In_Progress operation is returned to Waiting during the graceful shutdown of the cluster-agent replica
+	// that was processing it. It is cleared once the operation is resumed.
+	Resume_token string `pg:"resume_token"`
 }
 
 // Application represents an Argo CD Application CR within an Argo CD namespace.
@@ -269,8 +334,45 @@ type Application struct {
 
 	// -- Created_on field will tell us how old resources are
 	Created_on time.Time `pg:"created_on"`
+
+	// Deleted_on is set when the Application has been soft-deleted (its GitOpsDeployment was deleted), and is nil otherwise.
+	// A soft-deleted Application may be restored until the soft-delete grace period has elapsed, after which it is
+	// deleted by the database reconciler.
+	Deleted_on *time.Time `pg:"deleted_on"`
+
+	// Version is incremented on every update of the row, and is used by CompareAndSwapApplication to detect
+	// concurrent modification.
+	Version int64 `pg:"version,use_zero"`
 }
 
+// IsSoftDeleted returns true if the Application has been soft-deleted, false otherwise.
+func (obj *Application) IsSoftDeleted() bool {
+	return obj != nil && obj.Deleted_on != nil
+}
+
+// ApplicationStateHealth is the Argo CD health status of an Application, as stored in the 'health' column of ApplicationState.
+// - The values correspond to the Argo CD health status codes.
+type ApplicationStateHealth string
+
+const (
+	ApplicationStateHealth_Healthy     ApplicationStateHealth = "Healthy"
+	ApplicationStateHealth_Progressing ApplicationStateHealth = "Progressing"
+	ApplicationStateHealth_Degraded    ApplicationStateHealth = "Degraded"
+	ApplicationStateHealth_Suspended   ApplicationStateHealth = "Suspended"
+	ApplicationStateHealth_Missing     ApplicationStateHealth = "Missing"
+	ApplicationStateHealth_Unknown     ApplicationStateHealth = "Unknown"
+)
+
+// ApplicationStateSyncStatus is the Argo CD sync status of an Application, as stored in the 'sync_status' column of ApplicationState.
+// - The values correspond to the Argo CD sync status codes.
+type ApplicationStateSyncStatus string
+
+const (
+	ApplicationStateSyncStatus_Synced    ApplicationStateSyncStatus = "Synced"
+	ApplicationStateSyncStatus_OutOfSync ApplicationStateSyncStatus = "OutOfSync"
+	ApplicationStateSyncStatus_Unknown   ApplicationStateSyncStatus = "Unknown"
+)
+
 // ApplicationState is the Argo CD health/sync state of the Application
 type ApplicationState struct {
 
@@ -280,7 +382,7 @@ type ApplicationState struct {
 	// -- Foreign key to Application.application_id
 	Applicationstate_application_id string `pg:"applicationstate_application_id,pk"`
 
-	// -- Possible values:
+	// -- Possible values (see ApplicationStateHealth):
 	// -- * Healthy
 	// -- * Progressing
 	// -- * Degraded
@@ -289,7 +391,7 @@ type ApplicationState struct {
 	// -- * Unknown
 	Health string `pg:"health"`
 
-	// -- Possible values:
+	// -- Possible values (see ApplicationStateSyncStatus):
 	// -- * Synced
 	// -- * OutOfSync
 	// -- * Unknown
@@ -299,6 +401,7 @@ type ApplicationState struct {
 
 	Revision string `pg:"revision"`
 
+	// Resources is the gzip-compressed YAML of the Argo CD Application's .status.resources field
 	Resources []byte `pg:"resources"`
 
 	// -- human_readable_health ( 512 ) NOT NULL,
@@ -307,6 +410,34 @@ type ApplicationState struct {
 
 	ReconciledState string `pg:"reconciled_state"`
 	SyncError       string `pg:"sync_error"`
+
+	// LastSync is a JSON string, containing details of the last sync operation of the Argo CD Application (who/what
+	// initiated it, when it started/finished, and the revision it synced to). See fauxargocd.FauxLastSync.
+	LastSync string `pg:"last_sync"`
+
+	// ResolvedRevision is the commit SHA that the Argo CD Application's .spec.source.targetRevision (branch, tag, or
+	// commit) resolved to, in the Git repository.
+	ResolvedRevision string `pg:"resolved_revision"`
+
+	// RevisionError is non-empty if the .spec.source.targetRevision of the Argo CD Application could not be found in
+	// the Git repository (for example, due to a typo in a branch name).
+	RevisionError string `pg:"revision_error"`
+
+	// DiffPreview is a JSON string, containing a summary of the changes that a sync of the Argo CD Application would make,
+	// generated on request (see argosharedutil.DiffPreviewAnnotation). See fauxargocd.FauxDiffPreview.
+	DiffPreview string `pg:"diff_preview"`
+
+	// ResourceEstimate is a JSON string, containing an estimate of the CPU and memory requested by the Pods of the Argo CD
+	// Application. Only collected if resource estimates are enabled in the cluster-agent. See fauxargocd.FauxResourceEstimate.
+	ResourceEstimate string `pg:"resource_estimate"`
+
+	// SyncHooks is a JSON string, containing the status of the resource hooks that were run by the last sync operation of
+	// the Argo CD Application, if it was requested by a GitOpsDeploymentSyncRun. See fauxargocd.FauxSyncHooks.
+	SyncHooks string `pg:"sync_hooks"`
+
+	// OperationState is a JSON string, containing the details of the sync operation of the Argo CD Application that is
+	// currently in progress, or empty if no sync operation is in progress. See fauxargocd.FauxOperationState.
+	OperationState string `pg:"operation_state"`
 }
 
 // DeploymentToApplicationMapping represents relationship from GitOpsDeployment CR in the namespace, to an Application table row
@@ -339,6 +470,11 @@ type DeploymentToApplicationMapping struct {
 	// -- Foreign key to: Application.Application_id
 	Application_id string `pg:"application_id"`
 
+	// RestoredFromDeploymentUID is the UID of the deleted GitOpsDeployment whose soft-deleted Application was restored for
+	// this GitOpsDeployment, or empty if the Application was created for this GitOpsDeployment.
+	// - The name of the Argo CD Application is generated from this UID, rather than the UID of this GitOpsDeployment.
+	RestoredFromDeploymentUID string `pg:"restored_from_uid"`
+
 	SeqID int64 `pg:"seq_id"`
 }
 
@@ -346,9 +482,10 @@ type DeploymentToApplicationMapping struct {
 type APICRToDatabaseMapping_ResourceType string
 
 const (
-	APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment   APICRToDatabaseMapping_ResourceType = "GitOpsDeploymentManagedEnvironment"
-	APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun              APICRToDatabaseMapping_ResourceType = "GitOpsDeploymentSyncRun"
-	APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential APICRToDatabaseMapping_ResourceType = "GitOpsDeploymentRepositoryCredential"
+	APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment           APICRToDatabaseMapping_ResourceType = "GitOpsDeploymentManagedEnvironment"
+	APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun                      APICRToDatabaseMapping_ResourceType = "GitOpsDeploymentSyncRun"
+	APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential         APICRToDatabaseMapping_ResourceType = "GitOpsDeploymentRepositoryCredential"
+	APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate APICRToDatabaseMapping_ResourceType = "GitOpsDeploymentRepositoryCredentialTemplate"
 )
 
 // APICRToDatabaseMapping_DBRelationType: see 'db-schema.sql' for a description of these values.
@@ -383,6 +520,9 @@ type APICRToDatabaseMapping struct {
 	DBRelationKey  string                                `pg:"db_relation_key"`
 
 	SeqID int64 `pg:"seq_id"`
+
+	// -- Created_on field will tell us how old resources are
+	Created_on time.Time `pg:"created_on"`
 }
 
 // KubernetesToDBResourceMapping represents a generic relationship between Kubernetes CR <-> Database table
@@ -434,6 +574,23 @@ type SyncOperation struct {
 
 	DesiredState string `pg:"desired_state"`
 
+	// Initiator describes who/what requested the sync operation. See SyncOperation_Initiator_* constants.
+	Initiator string `pg:"initiator"`
+
+	// Resources is a JSON string, containing the list of resources of the Application to sync (see
+	// fauxargocd.FauxSyncOperationResource). If empty, all the resources of the Application are synced.
+	Resources string `pg:"resources"`
+
+	// RetryLimit is the maximum number of times that a failed sync operation is retried by Argo CD (from the
+	// .spec.retryPolicy of the GitOpsDeploymentSyncRun). If 0, the sync operation is not retried.
+	RetryLimit int64 `pg:"retry_limit,use_zero"`
+
+	// RetryBackoffDuration, RetryBackoffFactor and RetryBackoffMaxDuration are the backoff between retries. If empty/0,
+	// the Argo CD defaults are used.
+	RetryBackoffDuration    string `pg:"retry_backoff_duration"`
+	RetryBackoffFactor      int64  `pg:"retry_backoff_factor,use_zero"`
+	RetryBackoffMaxDuration string `pg:"retry_backoff_max_duration"`
+
 	Created_on time.Time `pg:"created_on"`
 }
 
@@ -491,6 +648,15 @@ type RepositoryCredentials struct {
 	// -- Foreign key to: GitopsEngineInstance.Gitopsengineinstance_id
 	EngineClusterID string `pg:"repo_cred_engine_id,notnull"`
 
+	// Project is the name of the Argo CD AppProject that the credentials are scoped to, if the GitOpsDeploymentRepositoryCredential
+	// has a .spec.scope: the credentials are then only used by the Argo CD Applications of the AppProject. If empty, the
+	// credentials are used by any Argo CD Application (of the GitOps Engine instance) that deploys from the PrivateURL repo.
+	Project string `pg:"repo_cred_project"`
+
+	// IsTemplate is true if the row is a credential template (from a GitOpsDeploymentRepositoryCredentialTemplate): PrivateURL
+	// is then a URL prefix, and the credentials are used for every Git repository whose URL starts with that prefix.
+	IsTemplate bool `pg:"repo_cred_template,use_zero"`
+
 	// SeqID is used only for debugging purposes. It helps us to keep track of the order that rows are created.
 	SeqID int64 `pg:"seq_id"`
 
@@ -527,6 +693,205 @@ outer_for:
 	return nil
 }
 
+// KeyStore contains a data key of a ClusterUser, which is used to encrypt the sensitive fields (password and SSH key)
+// of the RepositoryCredentials rows owned by that user. The data key is itself encrypted ('wrapped') with the master key,
+// so that the compromise of a single data key only exposes the credentials of a single tenant.
+//
+// A ClusterUser may have more than one KeyStore row: the most recently created row is the active key, used for new
+// encryptions, while older rows are kept until no encrypted value references them (see RotateDataKeyOfClusterUser).
+type KeyStore struct {
+
+	//lint:ignore U1000 used by go-pg
+	tableName struct{} `pg:"keystore,alias:ks"` //nolint
+
+	// KeyStoreID is the primary key, an auto-generated random UID. Encrypted values contain the ID of the KeyStore
+	// row whose data key was used to encrypt them.
+	KeyStoreID string `pg:"keystore_id,pk,notnull"`
+
+	// ClusterUserID is the user that owns the data key
+	// -- Foreign key to: ClusterUser.Clusteruser_id
+	ClusterUserID string `pg:"keystore_user_id,notnull"`
+
+	// WrappedKey is the base64-encoded data key, encrypted with the master key identified by MasterKeyID
+	WrappedKey string `pg:"wrapped_key,notnull"`
+
+	// MasterKeyID identifies the master key that WrappedKey was encrypted with (but is not derived from the key
+	// material in a way that allows the key to be recovered)
+	MasterKeyID string `pg:"master_key_id,notnull"`
+
+	// SeqID is used to determine the most recently created KeyStore row of a user
+	SeqID int64 `pg:"seq_id"`
+
+	// -- Created_on field will tell us how old resources are
+	Created_on time.Time `pg:"created_on"`
+}
+
+// ApplicationOwner associates a ClusterUser with an Application that the user owns. An Application may have more than
+// one owner, for example, the members of a shared workspace that deploy the same GitOpsDeployment: each owner is
+// allowed to access the Application (see CheckedGetApplicationById).
+type ApplicationOwner struct {
+
+	//lint:ignore U1000 used by go-pg
+	tableName struct{} `pg:"applicationowner,alias:appo"` //nolint
+
+	// ApplicationID is the Application that is owned
+	// -- Foreign key to: Application.Application_id
+	ApplicationID string `pg:"applicationowner_application_id,pk,notnull"`
+
+	// ClusterUserID is the user that owns the Application
+	// -- Foreign key to: ClusterUser.Clusteruser_id
+	ClusterUserID string `pg:"applicationowner_user_id,pk,notnull"`
+
+	SeqID int64 `pg:"seq_id"`
+
+	// -- Created_on field will tell us how old resources are
+	Created_on time.Time `pg:"created_on"`
+}
+
+// FeatureFlag toggles a behaviour of the GitOps Service components, either for the whole installation (Namespace is
+// empty), or for a single namespace. A namespace-scoped flag takes precedence over the installation-wide flag of the
+// same name. See the 'featureflags' package for the client that consults these rows.
+type FeatureFlag struct {
+
+	//lint:ignore U1000 used by go-pg
+	tableName struct{} `pg:"featureflag,alias:ff"` //nolint
+
+	FeatureFlagID string `pg:"featureflag_id,pk"`
+
+	// Name is the name of the flag, for example 'batched-operations'
+	Name string `pg:"featureflag_name,notnull"`
+
+	// Namespace is the namespace that the flag applies to, or empty if it applies to the whole installation
+	Namespace string `pg:"featureflag_namespace,use_zero"`
+
+	// Enabled is whether the behaviour is enabled
+	Enabled bool `pg:"enabled,use_zero"`
+
+	SeqID int64 `pg:"seq_id"`
+
+	// -- Created_on field will tell us how old resources are
+	Created_on time.Time `pg:"created_on"`
+}
+
+// DeploymentHistory records a revision that was successfully deployed (synced) by Argo CD for an Application: the rows
+// of an Application thus describe what has been running in its environment over time.
+// - DeploymentHistory rows are written by the cluster-agent, from the '.status.history' field of the Argo CD Application.
+type DeploymentHistory struct {
+
+	//lint:ignore U1000 used by go-pg
+	tableName struct{} `pg:"deploymenthistory,alias:dh"` //nolint
+
+	// DeploymentHistoryID is the primary key, an auto-generated random UID
+	DeploymentHistoryID string `pg:"deploymenthistory_id,pk"`
+
+	// ApplicationID is the Application that the revision was deployed by
+	// -- Foreign key to: Application.Application_id
+	ApplicationID string `pg:"deploymenthistory_application_id,notnull"`
+
+	// ArgoCDHistoryID is the ID of the corresponding entry in the '.status.history' field of the Argo CD Application.
+	// The IDs of an Argo CD Application's history increase with each sync.
+	ArgoCDHistoryID int64 `pg:"argocd_history_id,use_zero"`
+
+	// Revision is the Git commit SHA that was deployed
+	Revision string `pg:"revision"`
+
+	// Images is a comma-separated list of the container images that were deployed by the revision, if known
+	Images string `pg:"images"`
+
+	// Initiator is who/what initiated the sync that deployed the revision, if known. See SyncOperation_Initiator_* constants.
+	Initiator string `pg:"initiator"`
+
+	// DeployedAt is when the sync that deployed the revision completed
+	DeployedAt time.Time `pg:"deployed_at"`
+
+	SeqID int64 `pg:"seq_id"`
+
+	// -- Created_on field will tell us how old resources are
+	Created_on time.Time `pg:"created_on"`
+}
+
+// NamespaceOffboarding records the progress of the offboarding of a namespace: the deletion of all the database rows
+// (Applications, SyncOperations, RepositoryCredentials, ManagedEnvironments, and the rows that map them to API resources)
+// of the namespace. The row is created when offboarding begins, updated as each step completes, and deleted once the
+// offboarding is complete, which allows an offboarding that was interrupted (for example, by a restart) to be resumed.
+type NamespaceOffboarding struct {
+
+	//lint:ignore U1000 used by go-pg
+	tableName struct{} `pg:"namespaceoffboarding,alias:nso"` //nolint
+
+	// NamespaceUID is the UID of the namespace that is being offboarded, and is the primary key
+	NamespaceUID string `pg:"namespace_uid,pk"`
+
+	// NamespaceName is the name of the namespace that is being offboarded
+	NamespaceName string `pg:"namespace_name"`
+
+	// Step is the offboarding step that is currently in progress: the steps before it have completed.
+	Step string `pg:"step"`
+
+	SeqID int64 `pg:"seq_id"`
+
+	// -- Created_on field will tell us how old resources are
+	Created_on time.Time `pg:"created_on"`
+}
+
+// MigrationOperation records the progress of the migration of the Applications of a namespace, from one GitopsEngineInstance
+// (Argo CD instance) to another. The row is created when the migration begins, updated as each step completes, and deleted
+// once the migration is complete, which allows a migration that was interrupted (for example, by a restart) to be resumed.
+type MigrationOperation struct {
+
+	//lint:ignore U1000 used by go-pg
+	tableName struct{} `pg:"migrationoperation,alias:mo"` //nolint
+
+	// NamespaceUID is the UID of the namespace whose Applications are being migrated, and is the primary key
+	NamespaceUID string `pg:"namespace_uid,pk"`
+
+	// NamespaceName is the name of the namespace whose Applications are being migrated
+	NamespaceName string `pg:"namespace_name"`
+
+	// SourceGitopsEngineInstanceID is the GitopsEngineInstance that the Applications are being migrated from
+	// -- Foreign key to: GitopsEngineInstance.Gitopsengineinstance_id
+	SourceGitopsEngineInstanceID string `pg:"source_gitopsengineinstance_id"`
+
+	// TargetGitopsEngineInstanceID is the GitopsEngineInstance that the Applications are being migrated to
+	// -- Foreign key to: GitopsEngineInstance.Gitopsengineinstance_id
+	TargetGitopsEngineInstanceID string `pg:"target_gitopsengineinstance_id"`
+
+	// Step is the migration step that is currently in progress: the steps before it have completed.
+	Step string `pg:"step"`
+
+	// Step_started_on is when the step that is currently in progress began
+	Step_started_on time.Time `pg:"step_started_on"`
+
+	SeqID int64 `pg:"seq_id"`
+
+	// -- Created_on field will tell us how old resources are
+	Created_on time.Time `pg:"created_on"`
+}
+
+// TaskCheckpoint records the progress of a long-running maintenance task (for example, a cleanup of orphaned database
+// rows), so that a task that was interrupted (for example, by a restart) can resume from where it stopped, rather than
+// starting again from the beginning. The row is created when the task first records its progress, updated as the task
+// progresses, and deleted once the task is complete. See the 'dbutil.TaskCheckpointer' helper.
+type TaskCheckpoint struct {
+
+	//lint:ignore U1000 used by go-pg
+	tableName struct{} `pg:"taskcheckpoint,alias:tc"` //nolint
+
+	// TaskName is the unique name of the task, and is the primary key
+	TaskName string `pg:"task_name,pk"`
+
+	// Checkpoint is the progress of the task: its format is defined by the task (see 'dbutil.TaskProgress')
+	Checkpoint string `pg:"checkpoint"`
+
+	// Last_updated is when the progress of the task was last recorded
+	Last_updated time.Time `pg:"last_updated"`
+
+	SeqID int64 `pg:"seq_id"`
+
+	// -- Created_on field will tell us how old resources are
+	Created_on time.Time `pg:"created_on"`
+}
+
 func (o Operation) GetGCExpirationTime() time.Duration {
 	return time.Duration(o.GC_expiration_time) * time.Second
 }
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"os"
 	"strconv"
+	"time"
 )
 
 var _ DatabaseQueries = &ChaosDBClient{}
@@ -94,6 +95,26 @@ func (cdb *ChaosDBClient) ListOperationsToBeGarbageCollected(ctx context.Context
 
 }
 
+func (cdb *ChaosDBClient) ReleaseInProgressOperationsOfGitopsEngineCluster(ctx context.Context, gitopsEngineClusterID string, processingOwner string) (int, error) {
+
+	if err := shouldSimulateFailure("ReleaseInProgressOperationsOfGitopsEngineCluster", gitopsEngineClusterID, processingOwner); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.ReleaseInProgressOperationsOfGitopsEngineCluster(ctx, gitopsEngineClusterID, processingOwner)
+
+}
+
+func (cdb *ChaosDBClient) CheckpointInProgressOperationsOfProcessingOwner(ctx context.Context, processingOwner string, resumeToken string) (int, error) {
+
+	if err := shouldSimulateFailure("CheckpointInProgressOperationsOfProcessingOwner", processingOwner, resumeToken); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.CheckpointInProgressOperationsOfProcessingOwner(ctx, processingOwner, resumeToken)
+
+}
+
 func (cdb *ChaosDBClient) GetOperationBatch(ctx context.Context, operations *[]Operation, limit, offSet int) error {
 
 	if err := shouldSimulateFailure("GetOperationBatch", operations, limit, offSet); err != nil {
@@ -182,6 +203,16 @@ func (cdb *ChaosDBClient) GetApplicationById(ctx context.Context, application *A
 
 }
 
+func (cdb *ChaosDBClient) GetTenantApplicationById(ctx context.Context, application *Application, tenant Tenant) error {
+
+	if err := shouldSimulateFailure("GetTenantApplicationById", application, tenant); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetTenantApplicationById(ctx, application, tenant)
+
+}
+
 func (cdb *ChaosDBClient) UpdateApplication(ctx context.Context, obj *Application) error {
 
 	if err := shouldSimulateFailure("UpdateApplication", obj); err != nil {
@@ -192,6 +223,16 @@ func (cdb *ChaosDBClient) UpdateApplication(ctx context.Context, obj *Applicatio
 
 }
 
+func (cdb *ChaosDBClient) CompareAndSwapApplication(ctx context.Context, obj *Application) error {
+
+	if err := shouldSimulateFailure("CompareAndSwapApplication", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CompareAndSwapApplication(ctx, obj)
+
+}
+
 func (cdb *ChaosDBClient) DeleteApplicationById(ctx context.Context, id string) (int, error) {
 
 	if err := shouldSimulateFailure("DeleteApplicationById", id); err != nil {
@@ -212,6 +253,26 @@ func (cdb *ChaosDBClient) CheckedDeleteApplicationById(ctx context.Context, id s
 
 }
 
+func (cdb *ChaosDBClient) SoftDeleteApplicationById(ctx context.Context, id string) (int, error) {
+
+	if err := shouldSimulateFailure("SoftDeleteApplicationById", id); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.SoftDeleteApplicationById(ctx, id)
+
+}
+
+func (cdb *ChaosDBClient) RestoreApplicationById(ctx context.Context, id string) (int, error) {
+
+	if err := shouldSimulateFailure("RestoreApplicationById", id); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.RestoreApplicationById(ctx, id)
+
+}
+
 func (cdb *ChaosDBClient) GetApplicationBatch(ctx context.Context, applications *[]Application, limit, offSet int) error {
 
 	if err := shouldSimulateFailure("GetApplicationBatch", applications, limit, offSet); err != nil {
@@ -305,6 +366,16 @@ func (cdb *ChaosDBClient) ListDeploymentToApplicationMappingByNamespaceUID(ctx c
 
 }
 
+func (cdb *ChaosDBClient) ListDeploymentToApplicationMappingsForNamespaceUID(ctx context.Context, namespaceUID string, afterSeqID int64, limit int, deplToAppMappingParam *[]DeploymentToApplicationMapping) error {
+
+	if err := shouldSimulateFailure("ListDeploymentToApplicationMappingsForNamespaceUID", namespaceUID, afterSeqID, limit, deplToAppMappingParam); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListDeploymentToApplicationMappingsForNamespaceUID(ctx, namespaceUID, afterSeqID, limit, deplToAppMappingParam)
+
+}
+
 func (cdb *ChaosDBClient) DeleteDeploymentToApplicationMappingByDeplId(ctx context.Context, id string) (int, error) {
 
 	if err := shouldSimulateFailure("DeleteDeploymentToApplicationMappingByDeplId", id); err != nil {
@@ -365,6 +436,26 @@ func (cdb *ChaosDBClient) UpdateApplicationState(ctx context.Context, obj *Appli
 
 }
 
+func (cdb *ChaosDBClient) UpdateApplicationStateHealthOnly(ctx context.Context, obj *ApplicationState) error {
+
+	if err := shouldSimulateFailure("UpdateApplicationStateHealthOnly", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateApplicationStateHealthOnly(ctx, obj)
+
+}
+
+func (cdb *ChaosDBClient) UpsertApplicationStates(ctx context.Context, applicationStates []ApplicationState) error {
+
+	if err := shouldSimulateFailure("UpsertApplicationStates", applicationStates); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpsertApplicationStates(ctx, applicationStates)
+
+}
+
 func (cdb *ChaosDBClient) DeleteApplicationStateById(ctx context.Context, id string) (int, error) {
 
 	if err := shouldSimulateFailure("DeleteApplicationStateById", id); err != nil {
@@ -779,6 +870,16 @@ func (cdb *ChaosDBClient) GetClusterCredentialsBatch(ctx context.Context, cluste
 	return cdb.InnerClient.GetClusterCredentialsBatch(ctx, clusterCredentials, limit, offSet)
 }
 
+func (cdb *ChaosDBClient) ListUnreferencedClusterCredentials(ctx context.Context, createdBefore time.Time, afterSeqID int64,
+	limit int, clusterCredentials *[]ClusterCredentials) error {
+
+	if err := shouldSimulateFailure("ListUnreferencedClusterCredentials", createdBefore, afterSeqID, limit, clusterCredentials); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListUnreferencedClusterCredentials(ctx, createdBefore, afterSeqID, limit, clusterCredentials)
+}
+
 func (cdb *ChaosDBClient) GetDeploymentToApplicationMappingByApplicationId(ctx context.Context, deplToAppMappingParam *DeploymentToApplicationMapping) error {
 
 	if err := shouldSimulateFailure("GetDeploymentToApplicationMappingByApplicationId", deplToAppMappingParam); err != nil {
@@ -809,6 +910,16 @@ func (cdb *ChaosDBClient) UpdateManagedEnvironment(ctx context.Context, obj *Man
 
 }
 
+func (cdb *ChaosDBClient) CompareAndSwapManagedEnvironment(ctx context.Context, obj *ManagedEnvironment) error {
+
+	if err := shouldSimulateFailure("CompareAndSwapManagedEnvironment", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CompareAndSwapManagedEnvironment(ctx, obj)
+
+}
+
 func (cdb *ChaosDBClient) DeleteGitopsEngineInstanceById(ctx context.Context, id string) (int, error) {
 
 	if err := shouldSimulateFailure("DeleteGitopsEngineInstanceById", id); err != nil {
@@ -857,6 +968,16 @@ func (cdb *ChaosDBClient) ListManagedEnvironmentForClusterCredentialsAndOwnerId(
 
 }
 
+func (cdb *ChaosDBClient) ListManagedEnvironmentsByClusterLabelsAndOwnerId(ctx context.Context, clusterLabels map[string]string, ownerId string, managedEnvironments *[]ManagedEnvironment) error {
+
+	if err := shouldSimulateFailure("ListManagedEnvironmentsByClusterLabelsAndOwnerId", clusterLabels, ownerId, managedEnvironments); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListManagedEnvironmentsByClusterLabelsAndOwnerId(ctx, clusterLabels, ownerId, managedEnvironments)
+
+}
+
 func (cdb *ChaosDBClient) CheckedListGitopsEngineClusterByCredentialId(ctx context.Context, credentialId string, engineClustersParam *[]GitopsEngineCluster, ownerId string) error {
 
 	if err := shouldSimulateFailure("CheckedListGitopsEngineClusterByCredentialId", credentialId, engineClustersParam, ownerId); err != nil {
@@ -887,6 +1008,16 @@ func (cdb *ChaosDBClient) ListClusterAccessesByManagedEnvironmentID(ctx context.
 
 }
 
+func (cdb *ChaosDBClient) DeleteClusterAccessesByManagedEnvironmentID(ctx context.Context, managedEnvironmentID string) ([]string, error) {
+
+	if err := shouldSimulateFailure("DeleteClusterAccessesByManagedEnvironmentID", managedEnvironmentID); err != nil {
+		return nil, err
+	}
+
+	return cdb.InnerClient.DeleteClusterAccessesByManagedEnvironmentID(ctx, managedEnvironmentID)
+
+}
+
 func (cdb *ChaosDBClient) GetClusterAccessBatch(ctx context.Context, clusterAccess *[]ClusterAccess, limit, offSet int) error {
 
 	if err := shouldSimulateFailure("GetClusterAccessBatch", clusterAccess, limit, offSet); err != nil {
@@ -906,6 +1037,43 @@ func (cdb *ChaosDBClient) ListApplicationsForManagedEnvironment(ctx context.Cont
 
 }
 
+func (cdb *ChaosDBClient) ListApplicationsForGitopsEngineInstance(ctx context.Context, gitopsEngineInstanceID string, applications *[]Application) (int, error) {
+
+	if err := shouldSimulateFailure("ListApplicationsForGitopsEngineInstance", gitopsEngineInstanceID, applications); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.ListApplicationsForGitopsEngineInstance(ctx, gitopsEngineInstanceID, applications)
+
+}
+
+func (cdb *ChaosDBClient) CountApplicationsForGitopsEngineInstance(ctx context.Context, gitopsEngineInstanceID string) (int, error) {
+
+	if err := shouldSimulateFailure("CountApplicationsForGitopsEngineInstance", gitopsEngineInstanceID); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.CountApplicationsForGitopsEngineInstance(ctx, gitopsEngineInstanceID)
+}
+
+func (cdb *ChaosDBClient) UpdateGitopsEngineInstanceMaxApplications(ctx context.Context, gitopsEngineInstanceID string, maxApplications int) error {
+
+	if err := shouldSimulateFailure("UpdateGitopsEngineInstanceMaxApplications", gitopsEngineInstanceID, maxApplications); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateGitopsEngineInstanceMaxApplications(ctx, gitopsEngineInstanceID, maxApplications)
+}
+
+func (cdb *ChaosDBClient) UpdateGitopsEngineInstanceArgoCDVersion(ctx context.Context, gitopsEngineInstanceID string, argoCDVersion string) error {
+
+	if err := shouldSimulateFailure("UpdateGitopsEngineInstanceArgoCDVersion", gitopsEngineInstanceID, argoCDVersion); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateGitopsEngineInstanceArgoCDVersion(ctx, gitopsEngineInstanceID, argoCDVersion)
+}
+
 func (cdb *ChaosDBClient) CheckedListAllGitopsEngineInstancesForGitopsEngineClusterIdAndOwnerId(ctx context.Context, engineClusterId string, ownerId string, gitopsEngineInstancesParam *[]GitopsEngineInstance) error {
 
 	if err := shouldSimulateFailure("CheckedListAllGitopsEngineInstancesForGitopsEngineClusterIdAndOwnerId", engineClusterId, ownerId, gitopsEngineInstancesParam); err != nil {
@@ -924,6 +1092,16 @@ func (cdb *ChaosDBClient) GetAPICRToDatabaseMappingBatch(ctx context.Context, ap
 	return cdb.InnerClient.GetAPICRToDatabaseMappingBatch(ctx, apiCRToDatabaseMapping, limit, offSet)
 }
 
+func (cdb *ChaosDBClient) ListAPICRToDatabaseMappingsOlderThan(ctx context.Context, resourceType APICRToDatabaseMapping_ResourceType,
+	createdBefore time.Time, afterSeqID int64, limit int, apiCRToDatabaseMappings *[]APICRToDatabaseMapping) error {
+
+	if err := shouldSimulateFailure("ListAPICRToDatabaseMappingsOlderThan", resourceType, createdBefore, afterSeqID, limit, apiCRToDatabaseMappings); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListAPICRToDatabaseMappingsOlderThan(ctx, resourceType, createdBefore, afterSeqID, limit, apiCRToDatabaseMappings)
+}
+
 func (cdb *ChaosDBClient) UpdateKubernetesResourceUIDForKubernetesToDBResourceMapping(ctx context.Context, obj *KubernetesToDBResourceMapping) error {
 	if err := shouldSimulateFailure("UpdateKubernetesResourceUIDForKubernetesToDBResourceMapping", obj); err != nil {
 		return err
@@ -961,6 +1139,14 @@ func (cdb *ChaosDBClient) CountOperationDBRowsByState(ctx context.Context, obj *
 	return cdb.InnerClient.CountOperationDBRowsByState(ctx, obj)
 }
 
+func (cdb *ChaosDBClient) CountOperationsByStateForInstance(ctx context.Context, state OperationState) ([]OperationCountForInstance, error) {
+	if err := shouldSimulateFailure("CountOperationsByStateForInstance", state); err != nil {
+		return nil, err
+	}
+
+	return cdb.InnerClient.CountOperationsByStateForInstance(ctx, state)
+}
+
 func (cdb *ChaosDBClient) GetKubernetesToDBResourceMappingBatch(ctx context.Context, k8sToDBResourceMapping *[]KubernetesToDBResourceMapping, limit, offset int) error {
 	if err := shouldSimulateFailure("GetKubernetesToDBResourceMappingBatch", limit, offset); err != nil {
 		return err
@@ -973,6 +1159,24 @@ func (cdb *ChaosDBClient) CloseDatabase() {
 	cdb.InnerClient.CloseDatabase()
 }
 
+func (cdb *ChaosDBClient) Ping(ctx context.Context) error {
+
+	if err := shouldSimulateFailure("Ping"); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.Ping(ctx)
+}
+
+func (cdb *ChaosDBClient) GetSchemaTables(ctx context.Context) (SchemaTables, error) {
+
+	if err := shouldSimulateFailure("GetSchemaTables"); err != nil {
+		return nil, err
+	}
+
+	return cdb.InnerClient.GetSchemaTables(ctx)
+}
+
 func shouldSimulateFailure(apiType string, obj ...interface{}) error {
 
 	if !isEnvExist("UNRELIABLE_DB_FAILURE_RATE") {
@@ -1004,3 +1208,392 @@ func shouldSimulateFailure(apiType string, obj ...interface{}) error {
 
 	return nil
 }
+
+func (cdb *ChaosDBClient) CreateKeyStore(ctx context.Context, obj *KeyStore) error {
+
+	if err := shouldSimulateFailure("CreateKeyStore", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CreateKeyStore(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) GetKeyStoreById(ctx context.Context, obj *KeyStore) error {
+
+	if err := shouldSimulateFailure("GetKeyStoreById", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetKeyStoreById(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) UpdateKeyStore(ctx context.Context, obj *KeyStore) error {
+
+	if err := shouldSimulateFailure("UpdateKeyStore", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateKeyStore(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) DeleteKeyStoreById(ctx context.Context, id string) (int, error) {
+
+	if err := shouldSimulateFailure("DeleteKeyStoreById", id); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.DeleteKeyStoreById(ctx, id)
+}
+
+func (cdb *ChaosDBClient) GetActiveKeyStoreForClusterUser(ctx context.Context, clusterUserID string, obj *KeyStore) error {
+
+	if err := shouldSimulateFailure("GetActiveKeyStoreForClusterUser", clusterUserID, obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetActiveKeyStoreForClusterUser(ctx, clusterUserID, obj)
+}
+
+func (cdb *ChaosDBClient) ListKeyStoresForClusterUser(ctx context.Context, clusterUserID string, keyStores *[]KeyStore) error {
+
+	if err := shouldSimulateFailure("ListKeyStoresForClusterUser", clusterUserID, keyStores); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListKeyStoresForClusterUser(ctx, clusterUserID, keyStores)
+}
+
+func (cdb *ChaosDBClient) RotateMasterKeyOfKeyStores(ctx context.Context) (int, error) {
+
+	if err := shouldSimulateFailure("RotateMasterKeyOfKeyStores"); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.RotateMasterKeyOfKeyStores(ctx)
+}
+
+func (cdb *ChaosDBClient) RotateDataKeyOfClusterUser(ctx context.Context, clusterUserID string) error {
+
+	if err := shouldSimulateFailure("RotateDataKeyOfClusterUser", clusterUserID); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.RotateDataKeyOfClusterUser(ctx, clusterUserID)
+}
+
+func (cdb *ChaosDBClient) CreateApplicationOwner(ctx context.Context, obj *ApplicationOwner) error {
+
+	if err := shouldSimulateFailure("CreateApplicationOwner", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CreateApplicationOwner(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) GetApplicationOwnerByPrimaryKey(ctx context.Context, obj *ApplicationOwner) error {
+
+	if err := shouldSimulateFailure("GetApplicationOwnerByPrimaryKey", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetApplicationOwnerByPrimaryKey(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) DeleteApplicationOwner(ctx context.Context, applicationID string, clusterUserID string) (int, error) {
+
+	if err := shouldSimulateFailure("DeleteApplicationOwner", applicationID, clusterUserID); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.DeleteApplicationOwner(ctx, applicationID, clusterUserID)
+}
+
+func (cdb *ChaosDBClient) ListApplicationOwnersByApplicationID(ctx context.Context, applicationID string, applicationOwners *[]ApplicationOwner) error {
+
+	if err := shouldSimulateFailure("ListApplicationOwnersByApplicationID", applicationID, applicationOwners); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListApplicationOwnersByApplicationID(ctx, applicationID, applicationOwners)
+}
+
+func (cdb *ChaosDBClient) ListApplicationOwnersByClusterUserID(ctx context.Context, clusterUserID string, applicationOwners *[]ApplicationOwner) error {
+
+	if err := shouldSimulateFailure("ListApplicationOwnersByClusterUserID", clusterUserID, applicationOwners); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListApplicationOwnersByClusterUserID(ctx, clusterUserID, applicationOwners)
+}
+
+func (cdb *ChaosDBClient) ListRepositoryCredentialsByClusterUserID(ctx context.Context, clusterUserID string, repositoryCredentials *[]RepositoryCredentials) error {
+
+	if err := shouldSimulateFailure("ListRepositoryCredentialsByClusterUserID", clusterUserID, repositoryCredentials); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListRepositoryCredentialsByClusterUserID(ctx, clusterUserID, repositoryCredentials)
+}
+
+func (cdb *ChaosDBClient) DeactivateClusterUser(ctx context.Context, clusterUserID string) (int, error) {
+
+	if err := shouldSimulateFailure("DeactivateClusterUser", clusterUserID); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.DeactivateClusterUser(ctx, clusterUserID)
+}
+
+func (cdb *ChaosDBClient) PurgeClusterUser(ctx context.Context, clusterUserID string) (int, error) {
+
+	if err := shouldSimulateFailure("PurgeClusterUser", clusterUserID); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.PurgeClusterUser(ctx, clusterUserID)
+}
+
+func (cdb *ChaosDBClient) CreateFeatureFlag(ctx context.Context, obj *FeatureFlag) error {
+
+	if err := shouldSimulateFailure("CreateFeatureFlag", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CreateFeatureFlag(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) GetFeatureFlagById(ctx context.Context, obj *FeatureFlag) error {
+
+	if err := shouldSimulateFailure("GetFeatureFlagById", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetFeatureFlagById(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) GetFeatureFlagByNameAndNamespace(ctx context.Context, obj *FeatureFlag) error {
+
+	if err := shouldSimulateFailure("GetFeatureFlagByNameAndNamespace", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetFeatureFlagByNameAndNamespace(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) UpdateFeatureFlag(ctx context.Context, obj *FeatureFlag) error {
+
+	if err := shouldSimulateFailure("UpdateFeatureFlag", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateFeatureFlag(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) DeleteFeatureFlagById(ctx context.Context, id string) (int, error) {
+
+	if err := shouldSimulateFailure("DeleteFeatureFlagById", id); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.DeleteFeatureFlagById(ctx, id)
+}
+
+func (cdb *ChaosDBClient) ListFeatureFlags(ctx context.Context, featureFlags *[]FeatureFlag) error {
+
+	if err := shouldSimulateFailure("ListFeatureFlags", featureFlags); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListFeatureFlags(ctx, featureFlags)
+}
+
+func (cdb *ChaosDBClient) CreateDeploymentHistory(ctx context.Context, obj *DeploymentHistory) error {
+
+	if err := shouldSimulateFailure("CreateDeploymentHistory", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CreateDeploymentHistory(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) DeleteDeploymentHistoryById(ctx context.Context, id string) (int, error) {
+
+	if err := shouldSimulateFailure("DeleteDeploymentHistoryById", id); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.DeleteDeploymentHistoryById(ctx, id)
+}
+
+func (cdb *ChaosDBClient) ListDeploymentHistoryByApplicationID(ctx context.Context, applicationID string, limit int,
+	deploymentHistory *[]DeploymentHistory) error {
+
+	if err := shouldSimulateFailure("ListDeploymentHistoryByApplicationID", applicationID, limit, deploymentHistory); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListDeploymentHistoryByApplicationID(ctx, applicationID, limit, deploymentHistory)
+}
+
+func (cdb *ChaosDBClient) CountDeploymentHistoryByApplicationIDsSince(ctx context.Context, applicationIDs []string,
+	since time.Time) (int, error) {
+
+	if err := shouldSimulateFailure("CountDeploymentHistoryByApplicationIDsSince", applicationIDs, since); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.CountDeploymentHistoryByApplicationIDsSince(ctx, applicationIDs, since)
+}
+
+func (cdb *ChaosDBClient) GetApplicationStateResourcesSize(ctx context.Context, applicationIDs []string) (int, error) {
+
+	if err := shouldSimulateFailure("GetApplicationStateResourcesSize", applicationIDs); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.GetApplicationStateResourcesSize(ctx, applicationIDs)
+}
+
+func (cdb *ChaosDBClient) ListAPICRToDatabaseMappingByNamespaceUID(ctx context.Context, namespaceUID string, apiCRToDBMappingParam *[]APICRToDatabaseMapping) error {
+
+	if err := shouldSimulateFailure("ListAPICRToDatabaseMappingByNamespaceUID", namespaceUID, apiCRToDBMappingParam); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListAPICRToDatabaseMappingByNamespaceUID(ctx, namespaceUID, apiCRToDBMappingParam)
+}
+
+func (cdb *ChaosDBClient) CreateNamespaceOffboarding(ctx context.Context, obj *NamespaceOffboarding) error {
+
+	if err := shouldSimulateFailure("CreateNamespaceOffboarding", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CreateNamespaceOffboarding(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) GetNamespaceOffboardingByNamespaceUID(ctx context.Context, obj *NamespaceOffboarding) error {
+
+	if err := shouldSimulateFailure("GetNamespaceOffboardingByNamespaceUID", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetNamespaceOffboardingByNamespaceUID(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) UpdateNamespaceOffboarding(ctx context.Context, obj *NamespaceOffboarding) error {
+
+	if err := shouldSimulateFailure("UpdateNamespaceOffboarding", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateNamespaceOffboarding(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) DeleteNamespaceOffboardingByNamespaceUID(ctx context.Context, namespaceUID string) (int, error) {
+
+	if err := shouldSimulateFailure("DeleteNamespaceOffboardingByNamespaceUID", namespaceUID); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.DeleteNamespaceOffboardingByNamespaceUID(ctx, namespaceUID)
+}
+
+func (cdb *ChaosDBClient) ListNamespaceOffboardings(ctx context.Context, namespaceOffboardings *[]NamespaceOffboarding) error {
+
+	if err := shouldSimulateFailure("ListNamespaceOffboardings", namespaceOffboardings); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListNamespaceOffboardings(ctx, namespaceOffboardings)
+}
+
+func (cdb *ChaosDBClient) CreateMigrationOperation(ctx context.Context, obj *MigrationOperation) error {
+
+	if err := shouldSimulateFailure("CreateMigrationOperation", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CreateMigrationOperation(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) GetMigrationOperationByNamespaceUID(ctx context.Context, obj *MigrationOperation) error {
+
+	if err := shouldSimulateFailure("GetMigrationOperationByNamespaceUID", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetMigrationOperationByNamespaceUID(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) UpdateMigrationOperation(ctx context.Context, obj *MigrationOperation) error {
+
+	if err := shouldSimulateFailure("UpdateMigrationOperation", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateMigrationOperation(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) DeleteMigrationOperationByNamespaceUID(ctx context.Context, namespaceUID string) (int, error) {
+
+	if err := shouldSimulateFailure("DeleteMigrationOperationByNamespaceUID", namespaceUID); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.DeleteMigrationOperationByNamespaceUID(ctx, namespaceUID)
+}
+
+func (cdb *ChaosDBClient) ListMigrationOperations(ctx context.Context, migrationOperations *[]MigrationOperation) error {
+
+	if err := shouldSimulateFailure("ListMigrationOperations", migrationOperations); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListMigrationOperations(ctx, migrationOperations)
+}
+
+func (cdb *ChaosDBClient) CreateTaskCheckpoint(ctx context.Context, obj *TaskCheckpoint) error {
+
+	if err := shouldSimulateFailure("CreateTaskCheckpoint", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.CreateTaskCheckpoint(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) GetTaskCheckpointByTaskName(ctx context.Context, obj *TaskCheckpoint) error {
+
+	if err := shouldSimulateFailure("GetTaskCheckpointByTaskName", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.GetTaskCheckpointByTaskName(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) UpdateTaskCheckpoint(ctx context.Context, obj *TaskCheckpoint) error {
+
+	if err := shouldSimulateFailure("UpdateTaskCheckpoint", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateTaskCheckpoint(ctx, obj)
+}
+
+func (cdb *ChaosDBClient) DeleteTaskCheckpointByTaskName(ctx context.Context, taskName string) (int, error) {
+
+	if err := shouldSimulateFailure("DeleteTaskCheckpointByTaskName", taskName); err != nil {
+		return 0, err
+	}
+
+	return cdb.InnerClient.DeleteTaskCheckpointByTaskName(ctx, taskName)
+}
+
+func (cdb *ChaosDBClient) ListTaskCheckpoints(ctx context.Context, taskCheckpoints *[]TaskCheckpoint) error {
+
+	if err := shouldSimulateFailure("ListTaskCheckpoints", taskCheckpoints); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.ListTaskCheckpoints(ctx, taskCheckpoints)
+}
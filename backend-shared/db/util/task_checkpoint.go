@@ -0,0 +1,131 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+// Resumable long-running tasks:
+//
+// Long-running maintenance tasks (for example, the cleanup of orphaned database rows) process rows in batches, over
+// many minutes. If the task is interrupted (for example, by a restart of the pod), it would otherwise start again from
+// the beginning on its next run, and may never complete on a large database if it is interrupted often.
+//
+// A TaskCheckpointer persists the progress of a task in the TaskCheckpoint table, after each batch:
+// - When the task starts, it loads the progress of its previous run (if that run was interrupted), and resumes from it.
+// - When the task completes, its checkpoint is deleted, so that its next run starts from the beginning.
+//
+// Each batch must be idempotent, as the batch that was in progress when the task was interrupted is processed again.
+
+// TaskProgress is the progress of a long-running task: the step that is in progress (the steps before it have
+// completed), and the position within that step (for example, the offset or seq_id of the next batch of rows).
+type TaskProgress struct {
+	Step   string `json:"step,omitempty"`
+	Cursor int64  `json:"cursor,omitempty"`
+}
+
+// TaskCheckpointer loads and persists the progress of a long-running task. It is not thread safe: each run of a task
+// should use its own TaskCheckpointer.
+type TaskCheckpointer struct {
+	dbQueries db.DatabaseQueries
+	taskName  string
+
+	// progress is the most recently loaded or saved progress of the task
+	progress TaskProgress
+
+	// exists is true if the TaskCheckpoint row of the task exists in the database
+	exists bool
+}
+
+// NewTaskCheckpointer loads the progress of the given task, as persisted by a previous run of the task that did not
+// complete. If there is no such run, the progress is empty (the task starts from the beginning).
+func NewTaskCheckpointer(ctx context.Context, dbQueries db.DatabaseQueries, taskName string) (*TaskCheckpointer, error) {
+
+	res := &TaskCheckpointer{
+		dbQueries: dbQueries,
+		taskName:  taskName,
+	}
+
+	taskCheckpoint := db.TaskCheckpoint{TaskName: taskName}
+	if err := dbQueries.GetTaskCheckpointByTaskName(ctx, &taskCheckpoint); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return res, nil
+		}
+		return nil, fmt.Errorf("unable to retrieve checkpoint of task '%s': %v", taskName, err)
+	}
+
+	res.exists = true
+
+	if err := json.Unmarshal([]byte(taskCheckpoint.Checkpoint), &res.progress); err != nil {
+		// The checkpoint is only an optimization: if it can't be parsed, the task starts again from the beginning.
+		res.progress = TaskProgress{}
+		return res, nil
+	}
+
+	return res, nil
+}
+
+// Progress returns the progress of the task, as most recently loaded or saved.
+func (c *TaskCheckpointer) Progress() TaskProgress {
+	return c.progress
+}
+
+// IsResumed returns true if a previous run of the task was interrupted, and its progress was loaded.
+func (c *TaskCheckpointer) IsResumed() bool {
+	return c.exists && c.progress != TaskProgress{}
+}
+
+// Save persists the progress of the task, which will be resumed from if the task is interrupted.
+func (c *TaskCheckpointer) Save(ctx context.Context, progress TaskProgress) error {
+
+	checkpointBytes, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("unable to marshal checkpoint of task '%s': %v", c.taskName, err)
+	}
+
+	taskCheckpoint := db.TaskCheckpoint{
+		TaskName:   c.taskName,
+		Checkpoint: string(checkpointBytes),
+	}
+
+	if c.exists {
+		if err := c.dbQueries.GetTaskCheckpointByTaskName(ctx, &taskCheckpoint); err != nil {
+			return fmt.Errorf("unable to retrieve checkpoint of task '%s': %v", c.taskName, err)
+		}
+		taskCheckpoint.Checkpoint = string(checkpointBytes)
+
+		if err := c.dbQueries.UpdateTaskCheckpoint(ctx, &taskCheckpoint); err != nil {
+			return fmt.Errorf("unable to update checkpoint of task '%s': %v", c.taskName, err)
+		}
+
+	} else {
+		if err := c.dbQueries.CreateTaskCheckpoint(ctx, &taskCheckpoint); err != nil {
+			return fmt.Errorf("unable to create checkpoint of task '%s': %v", c.taskName, err)
+		}
+		c.exists = true
+	}
+
+	c.progress = progress
+
+	return nil
+}
+
+// Complete deletes the checkpoint of the task, so that the next run of the task starts from the beginning.
+func (c *TaskCheckpointer) Complete(ctx context.Context) error {
+
+	if !c.exists {
+		return nil
+	}
+
+	if _, err := c.dbQueries.DeleteTaskCheckpointByTaskName(ctx, c.taskName); err != nil {
+		return fmt.Errorf("unable to delete checkpoint of task '%s': %v", c.taskName, err)
+	}
+
+	c.exists = false
+	c.progress = TaskProgress{}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("TaskCheckpointer Tests", func() {
+
+	var (
+		ctx context.Context
+		dbq db.AllDatabaseQueries
+	)
+
+	const taskName = "test-long-running-task"
+
+	BeforeEach(func() {
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		ctx = context.Background()
+
+		dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		dbq.CloseDatabase()
+	})
+
+	It("should resume a task from its saved progress, until the task completes", func() {
+
+		By("starting a task that has no checkpoint")
+		checkpointer, err := NewTaskCheckpointer(ctx, dbq, taskName)
+		Expect(err).To(BeNil())
+		Expect(checkpointer.IsResumed()).To(BeFalse())
+		Expect(checkpointer.Progress()).To(Equal(TaskProgress{}))
+
+		Expect(checkpointer.Save(ctx, TaskProgress{Step: "first", Cursor: 100})).To(Succeed())
+		Expect(checkpointer.Save(ctx, TaskProgress{Step: "second", Cursor: 200})).To(Succeed())
+
+		By("simulating a restart, and verifying the task resumes from the most recently saved progress")
+		checkpointer, err = NewTaskCheckpointer(ctx, dbq, taskName)
+		Expect(err).To(BeNil())
+		Expect(checkpointer.IsResumed()).To(BeTrue())
+		Expect(checkpointer.Progress()).To(Equal(TaskProgress{Step: "second", Cursor: 200}))
+
+		By("completing the task, and verifying the next run starts from the beginning")
+		Expect(checkpointer.Complete(ctx)).To(Succeed())
+
+		taskCheckpoint := db.TaskCheckpoint{TaskName: taskName}
+		err = dbq.GetTaskCheckpointByTaskName(ctx, &taskCheckpoint)
+		Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+		checkpointer, err = NewTaskCheckpointer(ctx, dbq, taskName)
+		Expect(err).To(BeNil())
+		Expect(checkpointer.IsResumed()).To(BeFalse())
+	})
+
+	It("should start from the beginning if the checkpoint can't be parsed", func() {
+
+		Expect(dbq.CreateTaskCheckpoint(ctx, &db.TaskCheckpoint{TaskName: taskName, Checkpoint: "not-json"})).To(Succeed())
+
+		checkpointer, err := NewTaskCheckpointer(ctx, dbq, taskName)
+		Expect(err).To(BeNil())
+		Expect(checkpointer.IsResumed()).To(BeFalse())
+
+		By("verifying the progress can still be saved, and the task completed")
+		Expect(checkpointer.Save(ctx, TaskProgress{Step: "first"})).To(Succeed())
+		Expect(checkpointer.Complete(ctx)).To(Succeed())
+	})
+})
@@ -321,6 +321,76 @@ func SetupForTestingDBGinkgo() error {
 		}
 	}
 
+	var featureFlags []FeatureFlag
+	err = dbq.UnsafeListAllFeatureFlags(ctx, &featureFlags)
+	Expect(err).To(BeNil())
+
+	for _, featureFlag := range featureFlags {
+		if strings.HasPrefix(featureFlag.FeatureFlagID, "test-") {
+			rowsAffected, err := dbq.DeleteFeatureFlagById(ctx, featureFlag.FeatureFlagID)
+			Expect(err).To(BeNil())
+			if err == nil {
+				Expect(rowsAffected).Should(Equal(1))
+			}
+		}
+	}
+
+	var namespaceOffboardings []NamespaceOffboarding
+	err = dbq.ListNamespaceOffboardings(ctx, &namespaceOffboardings)
+	Expect(err).To(BeNil())
+
+	for _, namespaceOffboarding := range namespaceOffboardings {
+		if strings.HasPrefix(namespaceOffboarding.NamespaceUID, "test-") {
+			rowsAffected, err := dbq.DeleteNamespaceOffboardingByNamespaceUID(ctx, namespaceOffboarding.NamespaceUID)
+			Expect(err).To(BeNil())
+			if err == nil {
+				Expect(rowsAffected).Should(Equal(1))
+			}
+		}
+	}
+
+	var migrationOperations []MigrationOperation
+	err = dbq.ListMigrationOperations(ctx, &migrationOperations)
+	Expect(err).To(BeNil())
+
+	for _, migrationOperation := range migrationOperations {
+		if strings.HasPrefix(migrationOperation.NamespaceUID, "test-") {
+			rowsAffected, err := dbq.DeleteMigrationOperationByNamespaceUID(ctx, migrationOperation.NamespaceUID)
+			Expect(err).To(BeNil())
+			if err == nil {
+				Expect(rowsAffected).Should(Equal(1))
+			}
+		}
+	}
+
+	var taskCheckpoints []TaskCheckpoint
+	err = dbq.ListTaskCheckpoints(ctx, &taskCheckpoints)
+	Expect(err).To(BeNil())
+
+	for _, taskCheckpoint := range taskCheckpoints {
+		if strings.HasPrefix(taskCheckpoint.TaskName, "test-") {
+			rowsAffected, err := dbq.DeleteTaskCheckpointByTaskName(ctx, taskCheckpoint.TaskName)
+			Expect(err).To(BeNil())
+			if err == nil {
+				Expect(rowsAffected).Should(Equal(1))
+			}
+		}
+	}
+
+	var deploymentHistory []DeploymentHistory
+	err = dbq.UnsafeListAllDeploymentHistory(ctx, &deploymentHistory)
+	Expect(err).To(BeNil())
+
+	for _, entry := range deploymentHistory {
+		if strings.HasPrefix(entry.DeploymentHistoryID, "test-") {
+			rowsAffected, err := dbq.DeleteDeploymentHistoryById(ctx, entry.DeploymentHistoryID)
+			Expect(err).To(BeNil())
+			if err == nil {
+				Expect(rowsAffected).Should(Equal(1))
+			}
+		}
+	}
+
 	var applicationStates []ApplicationState
 	err = dbq.UnsafeListAllApplicationStates(ctx, &applicationStates)
 	Expect(err).To(BeNil())
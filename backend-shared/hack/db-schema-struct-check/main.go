@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+// db-schema-struct-check verifies that the tables and columns of 'db-schema.sql' match the 'pg' struct tags of the
+// database types of 'backend-shared/db/types.go', and prints each difference if they do not.
+//
+// With '--print', the tables and columns expected by the database types are printed instead.
+
+const (
+	DBSchemaRelativeFileLocation = "../db-schema.sql"
+)
+
+func main() {
+	printExpected := flag.Bool("print", false, "print the tables and columns expected by the database types, rather than verifying db-schema.sql")
+	flag.Parse()
+
+	expected, err := db.GetSchemaTablesOfDatabaseTypes()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if *printExpected {
+		printSchemaTables(expected)
+		return
+	}
+
+	dbSchemaContents, err := os.ReadFile(filepath.Clean(DBSchemaRelativeFileLocation))
+	if err != nil {
+		exitWithError(err)
+	}
+
+	actual, err := db.ParseDBSchemaTables(string(dbSchemaContents), "db-schema.sql")
+	if err != nil {
+		exitWithError(err)
+	}
+
+	differences := db.CompareSchemaTables(expected, actual, "db-schema.sql", false)
+	if len(differences) > 0 {
+		fmt.Println("db-schema.sql is not in sync with the database types of backend-shared/db/types.go:")
+		for _, difference := range differences {
+			fmt.Println("- " + difference)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Println("db-schema.sql is in sync with the database types of backend-shared/db/types.go")
+}
+
+func printSchemaTables(tables db.SchemaTables) {
+
+	tableNames := []string{}
+	for tableName := range tables {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		fmt.Println(tableName)
+
+		columnNames := []string{}
+		for columnName := range tables[tableName] {
+			columnNames = append(columnNames, columnName)
+		}
+		sort.Strings(columnNames)
+
+		for _, columnName := range columnNames {
+			column := tables[tableName][columnName]
+			fmt.Printf("\t%s %s\t(%s)\n", columnName, column.Type, column.Origin)
+		}
+	}
+}
+
+func exitWithError(err error) {
+	fmt.Println(err)
+	os.Exit(1)
+}
@@ -15,3 +15,4 @@ BACKEND_SHARED_DIR=$ROOTPATH/backend-shared
 cd ${BACKEND_SHARED_DIR}
 
 go run ./hack/db-schema-sync-check
+go run ./hack/db-schema-struct-check
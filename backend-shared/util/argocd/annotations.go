@@ -0,0 +1,130 @@
+package argocd
+
+import (
+	"strconv"
+	"strings"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+)
+
+// ArgoCDSyncWaveAnnotation is the Argo CD sync wave annotation. If it is set on a GitOpsDeployment, it is copied to the
+// corresponding Argo CD Application, so that Applications of lower waves are synchronized before those of higher waves.
+const ArgoCDSyncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+
+// DiffPreviewAnnotation may be set on a GitOpsDeployment to request a preview of the changes that a sync would make. It
+// is copied to the corresponding Argo CD Application, and the cluster-agent then stores a summary of the Argo CD diff
+// of the Application in .status.diffPreview of the GitOpsDeployment.
+// - The value is an arbitrary request ID: a new preview is generated each time the value changes.
+const DiffPreviewAnnotation = "managed-gitops.redhat.com/diff-preview"
+
+// DeletionPolicyAnnotation is set on the Argo CD Application of a GitOpsDeployment that specifies .spec.deletionPolicy.
+// The cluster-agent reads it when deleting the Argo CD Application, to determine whether Argo CD should delete the
+// resources of the Application (in the foreground or background), or leave them on the cluster.
+const DeletionPolicyAnnotation = "managed-gitops.redhat.com/deletion-policy"
+
+const (
+	// ArgoCDResourcesFinalizer causes Argo CD to delete the resources of an Application in the background, when it is deleted
+	ArgoCDResourcesFinalizer = "resources-finalizer.argocd.argoproj.io/background"
+
+	// ArgoCDForegroundResourcesFinalizer causes Argo CD to delete the resources of an Application (waiting for the
+	// deletion of their dependents) before the Application is deleted
+	ArgoCDForegroundResourcesFinalizer = "resources-finalizer.argocd.argoproj.io"
+)
+
+// GenerateArgoCDApplicationAnnotations returns the annotations that should be set on the Argo CD Application of a
+// GitOpsDeployment, based on the annotations of the GitOpsDeployment:
+// - Argo CD Notifications subscription annotations (see GenerateArgoCDNotificationAnnotations)
+// - Argo CD sync wave annotation, if it contains a valid integer
+// - Diff preview annotation, if it is non-empty
+//
+// Returns nil if there are no such annotations.
+func GenerateArgoCDApplicationAnnotations(gitopsDeploymentAnnotations map[string]string) map[string]string {
+
+	res := GenerateArgoCDNotificationAnnotations(gitopsDeploymentAnnotations)
+
+	if syncWave, exists := gitopsDeploymentAnnotations[ArgoCDSyncWaveAnnotation]; exists {
+
+		syncWave = strings.TrimSpace(syncWave)
+
+		if _, err := strconv.Atoi(syncWave); err == nil {
+			if res == nil {
+				res = map[string]string{}
+			}
+			res[ArgoCDSyncWaveAnnotation] = syncWave
+		}
+	}
+
+	if requestID := strings.TrimSpace(gitopsDeploymentAnnotations[DiffPreviewAnnotation]); requestID != "" {
+		if res == nil {
+			res = map[string]string{}
+		}
+		res[DiffPreviewAnnotation] = requestID
+	}
+
+	return res
+}
+
+// IsManagedArgoCDApplicationAnnotation returns true if the annotation key is an annotation of the Argo CD Application that
+// is managed by the GitOps Service (generated by GenerateArgoCDApplicationAnnotations), false otherwise.
+func IsManagedArgoCDApplicationAnnotation(key string) bool {
+	return IsArgoCDNotificationAnnotation(key) || key == ArgoCDSyncWaveAnnotation || key == DiffPreviewAnnotation ||
+		key == DeletionPolicyAnnotation || key == ResourceHealthChecksAnnotation
+}
+
+// SetDeletionPolicyAnnotation returns the annotations of an Argo CD Application, with DeletionPolicyAnnotation set to the
+// deletion policy (or removed, if the deletion policy is empty).
+func SetDeletionPolicyAnnotation(annotations map[string]string, deletionPolicy string) map[string]string {
+
+	if deletionPolicy == "" {
+		if _, exists := annotations[DeletionPolicyAnnotation]; !exists {
+			return annotations
+		}
+	}
+
+	res := map[string]string{}
+	for key, value := range annotations {
+		res[key] = value
+	}
+
+	if deletionPolicy == "" {
+		delete(res, DeletionPolicyAnnotation)
+		if len(res) == 0 {
+			return nil
+		}
+	} else {
+		res[DeletionPolicyAnnotation] = deletionPolicy
+	}
+
+	return res
+}
+
+// GetResourcesFinalizerOfDeletionPolicy returns the Argo CD resources finalizer that corresponds to the deletion policy
+// annotation of an Argo CD Application (see DeletionPolicyAnnotation), or "" if the resources of the Application should
+// not be deleted along with it.
+func GetResourcesFinalizerOfDeletionPolicy(annotations map[string]string) string {
+	switch annotations[DeletionPolicyAnnotation] {
+	case string(managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy_Orphan):
+		return ""
+	case string(managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy_Foreground):
+		return ArgoCDForegroundResourcesFinalizer
+	default:
+		return ArgoCDResourcesFinalizer
+	}
+}
+
+// SetResourcesFinalizer returns the finalizers of an Argo CD Application, with any Argo CD resources finalizer replaced by
+// the given resources finalizer (or removed, if it is ""). The other finalizers are kept.
+func SetResourcesFinalizer(finalizers []string, resourcesFinalizer string) []string {
+
+	var res []string
+	for _, finalizer := range finalizers {
+		if finalizer != ArgoCDResourcesFinalizer && finalizer != ArgoCDForegroundResourcesFinalizer {
+			res = append(res, finalizer)
+		}
+	}
+	if resourcesFinalizer != "" {
+		res = append(res, resourcesFinalizer)
+	}
+
+	return res
+}
@@ -0,0 +1,140 @@
+package argocd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+)
+
+var _ = Describe("Test Argo CD Application annotation utility functions", func() {
+
+	Context("Test GenerateArgoCDApplicationAnnotations", func() {
+
+		It("should return nil when there are no managed annotations", func() {
+			Expect(GenerateArgoCDApplicationAnnotations(nil)).To(BeNil())
+			Expect(GenerateArgoCDApplicationAnnotations(map[string]string{"some-annotation": "value"})).To(BeNil())
+		})
+
+		It("should copy a valid sync wave, and ignore an invalid one", func() {
+			Expect(GenerateArgoCDApplicationAnnotations(map[string]string{
+				ArgoCDSyncWaveAnnotation: "-1",
+			})).To(Equal(map[string]string{ArgoCDSyncWaveAnnotation: "-1"}))
+
+			Expect(GenerateArgoCDApplicationAnnotations(map[string]string{
+				ArgoCDSyncWaveAnnotation: "first",
+			})).To(BeNil())
+		})
+
+		It("should copy a non-empty diff preview request ID", func() {
+			Expect(GenerateArgoCDApplicationAnnotations(map[string]string{
+				DiffPreviewAnnotation: " request-1 ",
+			})).To(Equal(map[string]string{DiffPreviewAnnotation: "request-1"}))
+
+			Expect(GenerateArgoCDApplicationAnnotations(map[string]string{
+				DiffPreviewAnnotation: "",
+			})).To(BeNil())
+		})
+
+		It("should combine the sync wave and notification annotations", func() {
+			res := GenerateArgoCDApplicationAnnotations(map[string]string{
+				ArgoCDSyncWaveAnnotation:     "2",
+				NotificationsAnnotationSlack: "my-channel",
+			})
+			Expect(res).To(HaveLen(3))
+			Expect(res).To(HaveKeyWithValue(ArgoCDSyncWaveAnnotation, "2"))
+
+			for key := range res {
+				Expect(IsManagedArgoCDApplicationAnnotation(key)).To(BeTrue())
+			}
+		})
+	})
+
+	Context("Test SetDeletionPolicyAnnotation", func() {
+
+		It("should set the deletion policy, without modifying the input", func() {
+			input := map[string]string{ArgoCDSyncWaveAnnotation: "1"}
+
+			res := SetDeletionPolicyAnnotation(input, "Orphan")
+			Expect(res).To(Equal(map[string]string{ArgoCDSyncWaveAnnotation: "1", DeletionPolicyAnnotation: "Orphan"}))
+			Expect(input).To(HaveLen(1))
+			Expect(IsManagedArgoCDApplicationAnnotation(DeletionPolicyAnnotation)).To(BeTrue())
+		})
+
+		It("should remove the annotation when the deletion policy is empty", func() {
+			Expect(SetDeletionPolicyAnnotation(nil, "")).To(BeNil())
+			Expect(SetDeletionPolicyAnnotation(map[string]string{DeletionPolicyAnnotation: "Foreground"}, "")).To(BeNil())
+			Expect(SetDeletionPolicyAnnotation(map[string]string{
+				DeletionPolicyAnnotation: "Foreground",
+				ArgoCDSyncWaveAnnotation: "1",
+			}, "")).To(Equal(map[string]string{ArgoCDSyncWaveAnnotation: "1"}))
+		})
+
+		It("should return the resources finalizer of the deletion policy", func() {
+
+			annotationsWithPolicy := func(policy managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy) map[string]string {
+				return map[string]string{DeletionPolicyAnnotation: string(policy)}
+			}
+
+			Expect(GetResourcesFinalizerOfDeletionPolicy(nil)).To(Equal(ArgoCDResourcesFinalizer))
+			Expect(GetResourcesFinalizerOfDeletionPolicy(annotationsWithPolicy(managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy_Background))).
+				To(Equal(ArgoCDResourcesFinalizer))
+			Expect(GetResourcesFinalizerOfDeletionPolicy(annotationsWithPolicy(managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy_Foreground))).
+				To(Equal(ArgoCDForegroundResourcesFinalizer))
+			Expect(GetResourcesFinalizerOfDeletionPolicy(annotationsWithPolicy(managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy_Orphan))).
+				To(BeEmpty())
+		})
+
+		It("should replace the resources finalizer, and keep the other finalizers", func() {
+			Expect(SetResourcesFinalizer(nil, "")).To(BeNil())
+			Expect(SetResourcesFinalizer([]string{"other", ArgoCDResourcesFinalizer}, ArgoCDForegroundResourcesFinalizer)).
+				To(Equal([]string{"other", ArgoCDForegroundResourcesFinalizer}))
+			Expect(SetResourcesFinalizer([]string{ArgoCDForegroundResourcesFinalizer, "other"}, "")).To(Equal([]string{"other"}))
+		})
+	})
+
+	Context("Test SetResourceHealthChecksAnnotation", func() {
+
+		It("should set the health checks sorted by group/kind, and read them back", func() {
+			healthChecks := []ResourceHealthCheck{
+				{Group: "example.com", Kind: "Widget", Check: "hs = {}\nreturn hs"},
+				{Kind: "ConfigMap", Check: "return {status = 'Healthy'}"},
+			}
+			input := map[string]string{ArgoCDSyncWaveAnnotation: "1"}
+
+			res, err := SetResourceHealthChecksAnnotation(input, healthChecks)
+			Expect(err).To(BeNil())
+			Expect(res).To(HaveKey(ArgoCDSyncWaveAnnotation))
+			Expect(res).To(HaveKey(ResourceHealthChecksAnnotation))
+			Expect(input).To(HaveLen(1))
+			Expect(IsManagedArgoCDApplicationAnnotation(ResourceHealthChecksAnnotation)).To(BeTrue())
+
+			decoded, err := GetResourceHealthChecksFromAnnotations(res)
+			Expect(err).To(BeNil())
+			Expect(decoded).To(Equal([]ResourceHealthCheck{healthChecks[1], healthChecks[0]}))
+			Expect(decoded[0].GroupKind()).To(Equal("ConfigMap"))
+			Expect(decoded[1].GroupKind()).To(Equal("example.com_Widget"))
+		})
+
+		It("should remove the annotation when there are no health checks", func() {
+			res, err := SetResourceHealthChecksAnnotation(nil, nil)
+			Expect(err).To(BeNil())
+			Expect(res).To(BeNil())
+
+			res, err = SetResourceHealthChecksAnnotation(map[string]string{
+				ResourceHealthChecksAnnotation: "[]",
+				ArgoCDSyncWaveAnnotation:       "1",
+			}, nil)
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(map[string]string{ArgoCDSyncWaveAnnotation: "1"}))
+
+			decoded, err := GetResourceHealthChecksFromAnnotations(res)
+			Expect(err).To(BeNil())
+			Expect(decoded).To(BeNil())
+		})
+
+		It("should return an error if the annotation is not valid JSON", func() {
+			_, err := GetResourceHealthChecksFromAnnotations(map[string]string{ResourceHealthChecksAnnotation: "{"})
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,169 @@
+package argocd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The spec field of an Application row is compared with the Argo CD Application generated from it (and with the spec
+// field generated from the GitOpsDeployment) to decide whether an Operation is needed. Comparing the raw YAML would
+// report a difference for Applications that are semantically identical, for example where the keys are ordered
+// differently, or where a field is set to its default value in one, and omitted from the other.
+//
+// So, before comparing, both sides are normalized:
+// - the keys of each object are sorted (which is how they are marshalled by sigs.k8s.io/yaml).
+// - fields that are set to their default value are stripped: null, "", false, 0, and empty lists. The fields of an
+//   Argo CD Application are all 'omitempty', so Argo CD treats these values the same as an unset field.
+//
+// Empty objects are NOT stripped: the presence of an object may be meaningful, even if it has no fields. For example,
+// 'syncPolicy.automated: {}' enables automated sync.
+
+// NormalizeApplicationSpecField returns the canonical form of the given Argo CD Application YAML (for example, the
+// spec field of an Application row).
+func NormalizeApplicationSpecField(specField string) (string, error) {
+
+	normalized, err := normalizeYAML([]byte(specField))
+	if err != nil {
+		return "", err
+	}
+
+	resBytes, err := yaml.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal normalized Application: %v", err)
+	}
+
+	return string(resBytes), nil
+}
+
+// AreApplicationSpecFieldsEquivalent returns true if the given Argo CD Application YAMLs are semantically identical:
+// that is, if they are the same once normalized.
+func AreApplicationSpecFieldsEquivalent(specFieldA string, specFieldB string) (bool, error) {
+
+	// Skip normalizing, in the common case where the spec fields are identical
+	if specFieldA == specFieldB {
+		return true, nil
+	}
+
+	normalizedA, err := normalizeYAML([]byte(specFieldA))
+	if err != nil {
+		return false, err
+	}
+
+	normalizedB, err := normalizeYAML([]byte(specFieldB))
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(normalizedA, normalizedB), nil
+}
+
+// AreApplicationFieldsEquivalent returns true if the given fields of Argo CD Applications (for example, the
+// .spec.source field of each) are semantically identical: that is, if they are the same once normalized.
+//
+// The fields may be of different types, for example an Argo CD ApplicationSource, and the equivalent fauxargocd type.
+func AreApplicationFieldsEquivalent(fieldA interface{}, fieldB interface{}) (bool, error) {
+
+	normalizedA, err := normalizeField(fieldA)
+	if err != nil {
+		return false, err
+	}
+
+	normalizedB, err := normalizeField(fieldB)
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(normalizedA, normalizedB), nil
+}
+
+func normalizeField(field interface{}) (interface{}, error) {
+
+	jsonBytes, err := json.Marshal(field)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal Application field: %v", err)
+	}
+
+	return normalizeJSON(jsonBytes)
+}
+
+func normalizeYAML(yamlBytes []byte) (interface{}, error) {
+
+	jsonBytes, err := yaml.YAMLToJSON(yamlBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse Application YAML: %v", err)
+	}
+
+	return normalizeJSON(jsonBytes)
+}
+
+func normalizeJSON(jsonBytes []byte) (interface{}, error) {
+
+	var value interface{}
+
+	// Numbers are decoded as json.Number, rather than float64, so that large integers are compared exactly
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("unable to decode Application: %v", err)
+	}
+
+	normalized, _ := normalizeValue(value)
+
+	return normalized, nil
+}
+
+// normalizeValue returns the normalized form of a decoded JSON value, and whether the value is set to a default value
+// (and should thus be stripped from its parent object).
+func normalizeValue(value interface{}) (interface{}, bool) {
+
+	switch typedValue := value.(type) {
+
+	case nil:
+		return nil, true
+
+	case string:
+		return typedValue, typedValue == ""
+
+	case bool:
+		return typedValue, !typedValue
+
+	case json.Number:
+		// A non-numeric json.Number can't occur, as it was produced by the decoder
+		if floatValue, err := typedValue.Float64(); err == nil && floatValue == 0 {
+			return json.Number("0"), true
+		}
+		return typedValue, false
+
+	case []interface{}:
+		if len(typedValue) == 0 {
+			return typedValue, true
+		}
+
+		// The order of list items is meaningful (for example, of sync options, and Helm value files), so is preserved,
+		// and items are never stripped.
+		res := make([]interface{}, 0, len(typedValue))
+		for _, item := range typedValue {
+			normalizedItem, _ := normalizeValue(item)
+			res = append(res, normalizedItem)
+		}
+		return res, false
+
+	case map[string]interface{}:
+		res := map[string]interface{}{}
+		for key, fieldValue := range typedValue {
+			normalizedFieldValue, isDefault := normalizeValue(fieldValue)
+			if !isDefault {
+				res[key] = normalizedFieldValue
+			}
+		}
+		// Empty objects are not stripped: see the comment at the top of this file.
+		return res, false
+
+	default:
+		return typedValue, false
+	}
+}
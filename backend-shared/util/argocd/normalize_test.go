@@ -0,0 +1,154 @@
+package argocd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+)
+
+var _ = Describe("Test Argo CD Application normalization utility functions", func() {
+
+	Context("Test AreApplicationSpecFieldsEquivalent", func() {
+
+		specField := `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: my-app
+  namespace: gitops-service-argocd
+spec:
+  destination:
+    name: in-cluster
+    namespace: my-namespace
+  project: default
+  source:
+    path: environments/overlays/dev
+    repoURL: https://github.com/redhat-appstudio/managed-gitops
+  syncPolicy:
+    automated:
+      prune: true
+    syncOptions:
+    - PrunePropagationPolicy=background
+    - CreateNamespace=true
+`
+
+		It("should consider spec fields equivalent, if they only differ in key order and default values", func() {
+			reordered := `kind: Application
+apiVersion: argoproj.io/v1alpha1
+metadata:
+  namespace: gitops-service-argocd
+  name: my-app
+  annotations: null
+spec:
+  project: default
+  source:
+    repoURL: https://github.com/redhat-appstudio/managed-gitops
+    path: environments/overlays/dev
+    targetRevision: ""
+    helm:
+      valueFiles: []
+  destination:
+    namespace: my-namespace
+    name: in-cluster
+  syncPolicy:
+    syncOptions:
+    - PrunePropagationPolicy=background
+    - CreateNamespace=true
+    automated:
+      selfHeal: false
+      prune: true
+`
+			equivalent, err := AreApplicationSpecFieldsEquivalent(specField, reordered)
+			Expect(err).To(BeNil())
+			Expect(equivalent).To(BeTrue())
+
+			normalized, err := NormalizeApplicationSpecField(specField)
+			Expect(err).To(BeNil())
+			normalizedReordered, err := NormalizeApplicationSpecField(reordered)
+			Expect(err).To(BeNil())
+			Expect(normalized).To(Equal(normalizedReordered))
+		})
+
+		It("should not consider spec fields equivalent, if they differ in a value", func() {
+			modified := `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: my-app
+  namespace: gitops-service-argocd
+spec:
+  destination:
+    name: in-cluster
+    namespace: my-other-namespace
+  project: default
+  source:
+    path: environments/overlays/dev
+    repoURL: https://github.com/redhat-appstudio/managed-gitops
+  syncPolicy:
+    automated:
+      prune: true
+    syncOptions:
+    - PrunePropagationPolicy=background
+    - CreateNamespace=true
+`
+			equivalent, err := AreApplicationSpecFieldsEquivalent(specField, modified)
+			Expect(err).To(BeNil())
+			Expect(equivalent).To(BeFalse())
+		})
+
+		It("should not consider spec fields equivalent, if they differ in the order of a list", func() {
+			reorderedSyncOptions := `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: my-app
+  namespace: gitops-service-argocd
+spec:
+  destination:
+    name: in-cluster
+    namespace: my-namespace
+  project: default
+  source:
+    path: environments/overlays/dev
+    repoURL: https://github.com/redhat-appstudio/managed-gitops
+  syncPolicy:
+    automated:
+      prune: true
+    syncOptions:
+    - CreateNamespace=true
+    - PrunePropagationPolicy=background
+`
+			equivalent, err := AreApplicationSpecFieldsEquivalent(specField, reorderedSyncOptions)
+			Expect(err).To(BeNil())
+			Expect(equivalent).To(BeFalse())
+		})
+
+		It("should not strip empty objects, as their presence is meaningful", func() {
+			automatedSync := "spec:\n  syncPolicy:\n    automated: {}\n"
+			manualSync := "spec:\n  syncPolicy: {}\n"
+
+			equivalent, err := AreApplicationSpecFieldsEquivalent(automatedSync, manualSync)
+			Expect(err).To(BeNil())
+			Expect(equivalent).To(BeFalse())
+		})
+
+		It("should return an error if a spec field is not valid YAML", func() {
+			_, err := AreApplicationSpecFieldsEquivalent(specField, "spec: [")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("Test AreApplicationFieldsEquivalent", func() {
+
+		It("should consider fields of different types equivalent, if they are the same once normalized", func() {
+			equivalent, err := AreApplicationFieldsEquivalent(
+				fauxargocd.SyncPolicy{SyncOptions: fauxargocd.SyncOptions{}},
+				map[string]interface{}{"syncOptions": nil, "retry": nil})
+			Expect(err).To(BeNil())
+			Expect(equivalent).To(BeTrue())
+
+			equivalent, err = AreApplicationFieldsEquivalent(
+				fauxargocd.ApplicationDestination{Name: "in-cluster"},
+				fauxargocd.ApplicationDestination{Name: "in-cluster", Namespace: "my-namespace"})
+			Expect(err).To(BeNil())
+			Expect(equivalent).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package argocd
+
+import (
+	"sort"
+	"strings"
+)
+
+// Users may request Argo CD Notifications for a GitOpsDeployment, by adding the following annotations to the GitOpsDeployment.
+// These user-friendly annotations are translated into the 'notifications.argoproj.io/subscribe.(trigger).(service)'
+// annotations that are understood by Argo CD Notifications, and set on the generated Argo CD Application.
+//
+// Example:
+//
+//	metadata:
+//	  annotations:
+//	    notifications.managed-gitops.redhat.com/slack: "my-channel;my-other-channel"
+//	    notifications.managed-gitops.redhat.com/webhook: "my-webhook"
+//	    notifications.managed-gitops.redhat.com/triggers: "on-sync-failed,on-deployed"
+const (
+	// NotificationsAnnotationSlack is a semicolon-separated list of Slack channels to notify.
+	NotificationsAnnotationSlack = "notifications.managed-gitops.redhat.com/slack"
+
+	// NotificationsAnnotationWebhook is a comma-separated list of webhook services (as defined in the
+	// 'argocd-notifications-cm' ConfigMap of the Argo CD instance) to notify.
+	NotificationsAnnotationWebhook = "notifications.managed-gitops.redhat.com/webhook"
+
+	// NotificationsAnnotationTriggers is a comma-separated list of Argo CD Notifications triggers that the
+	// above targets should be subscribed to. Optional: defaults to DefaultNotificationTriggers.
+	NotificationsAnnotationTriggers = "notifications.managed-gitops.redhat.com/triggers"
+
+	// ArgoCDNotificationsSubscribeAnnotationPrefix is the prefix of the Argo CD Notifications subscription annotations
+	// on an Argo CD Application.
+	ArgoCDNotificationsSubscribeAnnotationPrefix = "notifications.argoproj.io/subscribe."
+)
+
+// DefaultNotificationTriggers are the Argo CD Notifications triggers that are used when the user has not specified any.
+var DefaultNotificationTriggers = []string{"on-sync-failed", "on-health-degraded"}
+
+// GenerateArgoCDNotificationAnnotations translates the user-friendly notification annotations of a GitOpsDeployment
+// into the corresponding Argo CD Notifications subscription annotations of an Argo CD Application.
+//
+// Returns nil if no notification targets were specified.
+func GenerateArgoCDNotificationAnnotations(gitopsDeploymentAnnotations map[string]string) map[string]string {
+
+	slackChannels := splitAndTrim(gitopsDeploymentAnnotations[NotificationsAnnotationSlack], ";")
+	webhooks := splitAndTrim(gitopsDeploymentAnnotations[NotificationsAnnotationWebhook], ",")
+
+	if len(slackChannels) == 0 && len(webhooks) == 0 {
+		return nil
+	}
+
+	triggers := splitAndTrim(gitopsDeploymentAnnotations[NotificationsAnnotationTriggers], ",")
+	if len(triggers) == 0 {
+		triggers = DefaultNotificationTriggers
+	}
+
+	res := map[string]string{}
+
+	for _, trigger := range triggers {
+
+		if len(slackChannels) > 0 {
+			res[ArgoCDNotificationsSubscribeAnnotationPrefix+trigger+".slack"] = strings.Join(slackChannels, ";")
+		}
+
+		// Argo CD Notifications webhooks are their own service, and do not require a recipient.
+		for _, webhook := range webhooks {
+			res[ArgoCDNotificationsSubscribeAnnotationPrefix+trigger+"."+webhook] = ""
+		}
+	}
+
+	return res
+}
+
+// IsArgoCDNotificationAnnotation returns true if the annotation key is an Argo CD Notifications subscription annotation.
+func IsArgoCDNotificationAnnotation(key string) bool {
+	return strings.HasPrefix(key, ArgoCDNotificationsSubscribeAnnotationPrefix)
+}
+
+// splitAndTrim splits the value by separator, and returns the sorted, non-empty, de-duplicated, trimmed results.
+func splitAndTrim(value string, separator string) []string {
+
+	var res []string
+
+	found := map[string]bool{}
+
+	for _, item := range strings.Split(value, separator) {
+		item = strings.TrimSpace(item)
+		if item == "" || found[item] {
+			continue
+		}
+		found[item] = true
+		res = append(res, item)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
@@ -0,0 +1,42 @@
+package argocd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test Argo CD notification utility functions", func() {
+
+	Context("Test GenerateArgoCDNotificationAnnotations", func() {
+
+		It("should return nil when no notification targets are specified", func() {
+			Expect(GenerateArgoCDNotificationAnnotations(nil)).To(BeNil())
+			Expect(GenerateArgoCDNotificationAnnotations(map[string]string{
+				NotificationsAnnotationTriggers: "on-deployed",
+			})).To(BeNil())
+		})
+
+		It("should subscribe slack channels to the default triggers", func() {
+			res := GenerateArgoCDNotificationAnnotations(map[string]string{
+				NotificationsAnnotationSlack: "channel-b; channel-a;channel-b",
+			})
+			Expect(res).To(Equal(map[string]string{
+				"notifications.argoproj.io/subscribe.on-sync-failed.slack":     "channel-a;channel-b",
+				"notifications.argoproj.io/subscribe.on-health-degraded.slack": "channel-a;channel-b",
+			}))
+		})
+
+		It("should subscribe webhooks to the user-specified triggers", func() {
+			res := GenerateArgoCDNotificationAnnotations(map[string]string{
+				NotificationsAnnotationWebhook:  "my-hook",
+				NotificationsAnnotationTriggers: "on-deployed",
+			})
+			Expect(res).To(Equal(map[string]string{
+				"notifications.argoproj.io/subscribe.on-deployed.my-hook": "",
+			}))
+			for key := range res {
+				Expect(IsArgoCDNotificationAnnotation(key)).To(BeTrue())
+			}
+		})
+	})
+})
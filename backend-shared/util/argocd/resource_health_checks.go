@@ -0,0 +1,82 @@
+package argocd
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// ResourceHealthChecksAnnotation is set on the Argo CD Application of a GitOpsDeployment that specifies
+// .spec.resourceHealthChecks, and contains the health checks as JSON. The cluster-agent merges the health checks of the
+// Argo CD Applications of an Argo CD instance into the configuration of the instance.
+const ResourceHealthChecksAnnotation = "managed-gitops.redhat.com/resource-health-checks"
+
+// ResourceHealthCheck is a custom Argo CD health check (in Lua), for the resources of a group/kind.
+type ResourceHealthCheck struct {
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind"`
+	Check string `json:"check"`
+}
+
+// GroupKind returns the group/kind of the health check, in the format used by the keys of the Argo CD
+// 'resource.customizations.health.(group_kind)' configuration.
+func (healthCheck ResourceHealthCheck) GroupKind() string {
+	if healthCheck.Group == "" {
+		return healthCheck.Kind
+	}
+	return healthCheck.Group + "_" + healthCheck.Kind
+}
+
+// SetResourceHealthChecksAnnotation returns the annotations of an Argo CD Application, with ResourceHealthChecksAnnotation
+// set to the health checks (or removed, if there are none). The health checks are sorted by group/kind, so that the
+// value of the annotation only changes when the health checks change.
+func SetResourceHealthChecksAnnotation(annotations map[string]string, healthChecks []ResourceHealthCheck) (map[string]string, error) {
+
+	if len(healthChecks) == 0 {
+		if _, exists := annotations[ResourceHealthChecksAnnotation]; !exists {
+			return annotations, nil
+		}
+	}
+
+	res := map[string]string{}
+	for key, value := range annotations {
+		res[key] = value
+	}
+
+	if len(healthChecks) == 0 {
+		delete(res, ResourceHealthChecksAnnotation)
+		if len(res) == 0 {
+			return nil, nil
+		}
+		return res, nil
+	}
+
+	sortedHealthChecks := append([]ResourceHealthCheck{}, healthChecks...)
+	sort.Slice(sortedHealthChecks, func(i, j int) bool {
+		return sortedHealthChecks[i].GroupKind() < sortedHealthChecks[j].GroupKind()
+	})
+
+	value, err := json.Marshal(sortedHealthChecks)
+	if err != nil {
+		return nil, err
+	}
+	res[ResourceHealthChecksAnnotation] = string(value)
+
+	return res, nil
+}
+
+// GetResourceHealthChecksFromAnnotations returns the health checks of the ResourceHealthChecksAnnotation annotation of
+// an Argo CD Application, or nil if it is not set.
+func GetResourceHealthChecksFromAnnotations(annotations map[string]string) ([]ResourceHealthCheck, error) {
+
+	value, exists := annotations[ResourceHealthChecksAnnotation]
+	if !exists || value == "" {
+		return nil, nil
+	}
+
+	var res []ResourceHealthCheck
+	if err := json.Unmarshal([]byte(value), &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
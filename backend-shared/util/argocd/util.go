@@ -2,9 +2,12 @@ package argocd
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"k8s.io/client-go/rest"
 )
 
 const (
@@ -13,6 +16,10 @@ const (
 	// ArgoCDDefaultDestinationInCluster is 'in-cluster' which is the spec destination value that Argo CD recognizes
 	// as indicating that Argo CD should deploy to the local cluster (the cluster that Argo CD is installed on).
 	ArgoCDDefaultDestinationInCluster = "in-cluster"
+
+	// ArgoCDApplicationDatabaseIDLabel is the label of the Argo CD Applications generated by the GitOps Service, which
+	// contains the ID of the Application row that the Argo CD Application was generated from.
+	ArgoCDApplicationDatabaseIDLabel = "databaseID"
 )
 
 // GenerateArgoCDClusterSecretName generates the name of the Argo CD cluster secret (and the name of the server within Argo CD).
@@ -20,10 +27,53 @@ func GenerateArgoCDClusterSecretName(managedEnv db.ManagedEnvironment) string {
 	return managedEnvPrefix + managedEnv.Managedenvironment_id
 }
 
+// GenerateRestConfigForClusterCredentials returns a rest.Config for connecting to the cluster of the cluster credentials,
+// using the same fields that are set in the Argo CD cluster secret of the credentials: the bearer token, and whether
+// TLS verification is skipped. As with the cluster secret, no CA data is set, so the certificate of the cluster is
+// verified using the system root CAs.
+func GenerateRestConfigForClusterCredentials(clusterCreds db.ClusterCredentials) *rest.Config {
+
+	restConfig := &rest.Config{
+		Host:        clusterCreds.Host,
+		BearerToken: clusterCreds.Serviceaccount_bearer_token,
+	}
+
+	restConfig.Insecure = clusterCreds.AllowInsecureSkipTLSVerify
+
+	// If the cluster credentials don't specify a proxy, client-go uses the proxy of the HTTPS_PROXY/NO_PROXY environment variables
+	if clusterCreds.Proxy_url != "" {
+		restConfig.Proxy = ProxyFuncForURL(clusterCreds.Proxy_url)
+	}
+
+	return restConfig
+}
+
+// ProxyFuncForURL returns a proxy function (for use in a rest.Config or http.Transport) that sends all requests via the
+// proxy URL. If the proxy URL is invalid, the proxy function returns an error, so that requests fail rather than
+// bypassing the proxy.
+func ProxyFuncForURL(proxyURL string) func(*http.Request) (*url.URL, error) {
+
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil {
+		return func(*http.Request) (*url.URL, error) {
+			return nil, fmt.Errorf("invalid proxy url: %v", err)
+		}
+	}
+
+	return http.ProxyURL(parsedURL)
+}
+
 func GenerateArgoCDApplicationName(gitopsDeploymentCRUID string) string {
 	return "gitopsdepl-" + string(gitopsDeploymentCRUID)
 }
 
+// GenerateArgoCDRepositoryCredentialProjectName generates the name of the Argo CD AppProject that the repository
+// credentials of a scoped GitOpsDeploymentRepositoryCredential are restricted to. Only the Argo CD Applications of the
+// AppProject may use the credentials.
+func GenerateArgoCDRepositoryCredentialProjectName(repositoryCredentialCRUID string) string {
+	return "gitops-repo-cred-" + repositoryCredentialCRUID
+}
+
 // ConvertArgoCDClusterSecretNameToManagedIdDatabaseRowId takes the name of an Argo CD cluster secret as input.
 // This name should correspond to the name of a Secret resource in the Argo CD namespace, which contains
 // cluster credentials.
@@ -59,7 +109,12 @@ func ConvertArgoCDClusterSecretNameToManagedIdDatabaseRowId(argoCDClusterSecretN
 type ClusterSecretTLSClientConfigJSON struct {
 	Insecure bool `json:"insecure"`
 }
+
 type ClusterSecretConfigJSON struct {
 	BearerToken     string                           `json:"bearerToken"`
 	TLSClientConfig ClusterSecretTLSClientConfigJSON `json:"tlsClientConfig"`
+
+	// ProxyUrl is the URL of the proxy that Argo CD connects to the cluster through (if empty, Argo CD uses the proxy
+	// environment variables of its own components)
+	ProxyUrl string `json:"proxyUrl,omitempty"`
 }
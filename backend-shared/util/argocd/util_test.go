@@ -1,8 +1,11 @@
 package argocd
 
 import (
+	"net/http"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 )
 
 var _ = Describe("Test Argo CD utility functions", func() {
@@ -47,4 +50,43 @@ var _ = Describe("Test Argo CD utility functions", func() {
 			})
 		})
 	})
+
+	Context("Test GenerateRestConfigForClusterCredentials", func() {
+
+		It("should only set the fields that are set in the Argo CD cluster secret", func() {
+			restConfig := GenerateRestConfigForClusterCredentials(db.ClusterCredentials{
+				Host:                        "https://my-api-url",
+				Serviceaccount_bearer_token: "my-token",
+				AllowInsecureSkipTLSVerify:  true,
+			})
+
+			Expect(restConfig.Host).To(Equal("https://my-api-url"))
+			Expect(restConfig.BearerToken).To(Equal("my-token"))
+			Expect(restConfig.Insecure).To(BeTrue())
+			Expect(restConfig.Proxy).To(BeNil(), "the proxy of the environment variables should be used")
+		})
+
+		It("should connect via the proxy of the cluster credentials, if set", func() {
+			restConfig := GenerateRestConfigForClusterCredentials(db.ClusterCredentials{
+				Host:      "https://my-api-url",
+				Proxy_url: "http://my-proxy:3128",
+			})
+			Expect(restConfig.Proxy).ToNot(BeNil())
+
+			req, err := http.NewRequest(http.MethodGet, "https://my-api-url/api", nil)
+			Expect(err).To(BeNil())
+
+			proxyURL, err := restConfig.Proxy(req)
+			Expect(err).To(BeNil())
+			Expect(proxyURL.String()).To(Equal("http://my-proxy:3128"))
+		})
+
+		It("should return an error from the proxy function, if the proxy URL is invalid", func() {
+			req, err := http.NewRequest(http.MethodGet, "https://my-api-url/api", nil)
+			Expect(err).To(BeNil())
+
+			_, err = ProxyFuncForURL("http://my-proxy:port")(req)
+			Expect(err).ToNot(BeNil())
+		})
+	})
 })
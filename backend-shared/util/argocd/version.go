@@ -0,0 +1,108 @@
+package argocd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Argo CD version compatibility:
+//
+// The cluster-agent detects the version of each Argo CD instance on startup (and periodically thereafter), and records it
+// in the GitopsEngineInstance row of the instance. Features that require a newer version of Argo CD than the one that is
+// installed are then skipped when generating the Argo CD Applications of the instance, rather than producing an
+// Application that Argo CD would reject (or silently ignore).
+//
+// If the version of an instance is not known (for example, it has not yet been detected, or could not be parsed), all
+// features are assumed to be available.
+
+// MinimumSupportedArgoCDVersion is the oldest version of Argo CD that the GitOps Service supports.
+var MinimumSupportedArgoCDVersion = ArgoCDVersion{Major: 2, Minor: 4}
+
+// argoCDVersionRegex matches versions of the form 'v2.6.3', '2.6.3', 'v2.6' and 'v2.6.3-rc1' (for example, as found in
+// the image tag of an Argo CD component). The major and minor versions are required.
+var argoCDVersionRegex = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?(?:[-+].*)?$`)
+
+// ArgoCDVersion is the (semantic) version of an Argo CD instance.
+type ArgoCDVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseArgoCDVersion parses an Argo CD version, for example 'v2.6.3'.
+func ParseArgoCDVersion(version string) (ArgoCDVersion, error) {
+
+	match := argoCDVersionRegex.FindStringSubmatch(version)
+	if match == nil {
+		return ArgoCDVersion{}, fmt.Errorf("'%s' is not a valid Argo CD version", version)
+	}
+
+	res := ArgoCDVersion{}
+	var err error
+	if res.Major, err = strconv.Atoi(match[1]); err != nil {
+		return ArgoCDVersion{}, fmt.Errorf("'%s' is not a valid Argo CD version: %v", version, err)
+	}
+	if res.Minor, err = strconv.Atoi(match[2]); err != nil {
+		return ArgoCDVersion{}, fmt.Errorf("'%s' is not a valid Argo CD version: %v", version, err)
+	}
+	if match[3] != "" {
+		if res.Patch, err = strconv.Atoi(match[3]); err != nil {
+			return ArgoCDVersion{}, fmt.Errorf("'%s' is not a valid Argo CD version: %v", version, err)
+		}
+	}
+
+	return res, nil
+}
+
+// String returns the version in the form 'v(major).(minor).(patch)'.
+func (v ArgoCDVersion) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// IsAtLeast returns true if the version is the same as, or newer than, the given version.
+func (v ArgoCDVersion) IsAtLeast(other ArgoCDVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+// IsArgoCDVersionSupported returns false if the version of an Argo CD instance (as recorded in its GitopsEngineInstance
+// row) is older than MinimumSupportedArgoCDVersion. Versions that are not known are assumed to be supported.
+func IsArgoCDVersionSupported(version string) bool {
+
+	parsedVersion, err := ParseArgoCDVersion(version)
+	if err != nil {
+		return true
+	}
+
+	return parsedVersion.IsAtLeast(MinimumSupportedArgoCDVersion)
+}
+
+// ArgoCDCapabilities are the features of Argo CD, used by the GitOps Service, that are not available in every supported
+// version of Argo CD.
+type ArgoCDCapabilities struct {
+
+	// ServerSideApply is true if the 'ServerSideApply' sync option is supported (Argo CD v2.5+)
+	ServerSideApply bool
+}
+
+// GetArgoCDCapabilities returns the capabilities of an Argo CD instance, from its version (as recorded in its
+// GitopsEngineInstance row). If the version is not known, all capabilities are assumed to be available.
+func GetArgoCDCapabilities(version string) ArgoCDCapabilities {
+
+	parsedVersion, err := ParseArgoCDVersion(version)
+	if err != nil {
+		return ArgoCDCapabilities{
+			ServerSideApply: true,
+		}
+	}
+
+	return ArgoCDCapabilities{
+		ServerSideApply: parsedVersion.IsAtLeast(ArgoCDVersion{Major: 2, Minor: 5}),
+	}
+}
@@ -0,0 +1,51 @@
+package argocd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test Argo CD version utility functions", func() {
+
+	Context("Test ParseArgoCDVersion", func() {
+
+		DescribeTable("should parse valid versions, and reject invalid ones",
+			func(version string, expected ArgoCDVersion, valid bool) {
+				res, err := ParseArgoCDVersion(version)
+				if !valid {
+					Expect(err).ToNot(BeNil())
+					return
+				}
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(expected))
+			},
+			Entry("full version", "v2.6.3", ArgoCDVersion{Major: 2, Minor: 6, Patch: 3}, true),
+			Entry("version without prefix", "2.5.10", ArgoCDVersion{Major: 2, Minor: 5, Patch: 10}, true),
+			Entry("version without patch", "v2.7", ArgoCDVersion{Major: 2, Minor: 7}, true),
+			Entry("pre-release version", "v2.8.0-rc1", ArgoCDVersion{Major: 2, Minor: 8}, true),
+			Entry("empty version", "", ArgoCDVersion{}, false),
+			Entry("image digest", "sha256:0123456789abcdef", ArgoCDVersion{}, false),
+			Entry("non-version tag", "latest", ArgoCDVersion{}, false),
+		)
+	})
+
+	Context("Test IsArgoCDVersionSupported and GetArgoCDCapabilities", func() {
+
+		It("should not support versions older than the minimum supported version", func() {
+			Expect(IsArgoCDVersionSupported("v2.3.17")).To(BeFalse())
+			Expect(IsArgoCDVersionSupported("v2.4.0")).To(BeTrue())
+			Expect(IsArgoCDVersionSupported("v3.0.0")).To(BeTrue())
+		})
+
+		It("should only report the capabilities that the version supports", func() {
+			Expect(GetArgoCDCapabilities("v2.4.28").ServerSideApply).To(BeFalse())
+			Expect(GetArgoCDCapabilities("v2.5.0").ServerSideApply).To(BeTrue())
+		})
+
+		It("should assume unknown versions are supported, with all capabilities", func() {
+			Expect(IsArgoCDVersionSupported("")).To(BeTrue())
+			Expect(GetArgoCDCapabilities("").ServerSideApply).To(BeTrue())
+			Expect(GetArgoCDCapabilities("latest").ServerSideApply).To(BeTrue())
+		})
+	})
+})
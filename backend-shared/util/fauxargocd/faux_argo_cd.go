@@ -1,5 +1,7 @@
 package fauxargocd
 
+import "time"
+
 // This package is a partial clone of the Argo CD Application Go struct, containing a subset of the Application CR
 // fields.
 //
@@ -16,6 +18,10 @@ type FauxApplication struct {
 type FauxObjectMeta struct {
 	Name      string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
 	Namespace string `json:"namespace,omitempty" protobuf:"bytes,3,opt,name=namespace"`
+
+	// Annotations to be set on the Application. The 'yaml' tag ensures the field is omitted when empty, as the
+	// spec field is generated via 'gopkg.in/yaml.v2'.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty" protobuf:"bytes,12,rep,name=annotations"`
 }
 
 type FauxTypeMeta struct {
@@ -49,6 +55,61 @@ type ApplicationSource struct {
 	// In case of Git, this can be commit, tag, or branch. If omitted, will equal to HEAD.
 	// In case of Helm, this is a semver tag for the Chart's version.
 	TargetRevision string `json:"targetRevision,omitempty" protobuf:"bytes,4,opt,name=targetRevision"`
+
+	// Kustomize holds kustomize specific options. The 'yaml' tag ensures the field is omitted when empty, as the
+	// spec field is generated via 'gopkg.in/yaml.v2'.
+	Kustomize *ApplicationSourceKustomize `json:"kustomize,omitempty" yaml:"kustomize,omitempty" protobuf:"bytes,8,opt,name=kustomize"`
+
+	// Helm holds helm specific options
+	Helm *ApplicationSourceHelm `json:"helm,omitempty" yaml:"helm,omitempty" protobuf:"bytes,7,opt,name=helm"`
+
+	// Directory holds path/directory specific options
+	Directory *ApplicationSourceDirectory `json:"directory,omitempty" yaml:"directory,omitempty" protobuf:"bytes,10,opt,name=directory"`
+}
+
+// ApplicationSourceDirectory holds options for applications of type plain YAML or Jsonnet
+type ApplicationSourceDirectory struct {
+	// Recurse specifies whether to scan a directory recursively for manifests
+	Recurse bool `json:"recurse,omitempty" yaml:"recurse,omitempty" protobuf:"bytes,1,opt,name=recurse"`
+	// Jsonnet holds options specific to Jsonnet
+	Jsonnet *ApplicationSourceJsonnet `json:"jsonnet,omitempty" yaml:"jsonnet,omitempty" protobuf:"bytes,2,opt,name=jsonnet"`
+	// Exclude contains a glob pattern to match paths against that should be explicitly excluded from being used during manifest generation
+	Exclude string `json:"exclude,omitempty" yaml:"exclude,omitempty" protobuf:"bytes,3,opt,name=exclude"`
+	// Include contains a glob pattern to match paths against that should be explicitly included during manifest generation
+	Include string `json:"include,omitempty" yaml:"include,omitempty" protobuf:"bytes,4,opt,name=include"`
+}
+
+// ApplicationSourceJsonnet holds options specific to applications of type Jsonnet
+type ApplicationSourceJsonnet struct {
+	// ExtVars is a list of Jsonnet External Variables
+	ExtVars []JsonnetVar `json:"extVars,omitempty" yaml:"extVars,omitempty" protobuf:"bytes,1,opt,name=extVars"`
+	// TLAS is a list of Jsonnet Top-level Arguments
+	TLAs []JsonnetVar `json:"tlas,omitempty" yaml:"tlas,omitempty" protobuf:"bytes,2,opt,name=tlas"`
+	// Additional library search dirs
+	Libs []string `json:"libs,omitempty" yaml:"libs,omitempty" protobuf:"bytes,3,opt,name=libs"`
+}
+
+// JsonnetVar represents a variable to be passed to jsonnet during manifest generation
+type JsonnetVar struct {
+	Name  string `json:"name" yaml:"name" protobuf:"bytes,1,opt,name=name"`
+	Value string `json:"value" yaml:"value" protobuf:"bytes,2,opt,name=value"`
+	Code  bool   `json:"code,omitempty" yaml:"code,omitempty" protobuf:"bytes,3,opt,name=code"`
+}
+
+// ApplicationSourceHelm holds helm specific options
+type ApplicationSourceHelm struct {
+	// ValuesFiles is a list of Helm value files to use when generating a template
+	ValueFiles []string `json:"valueFiles,omitempty" yaml:"valueFiles,omitempty" protobuf:"bytes,1,opt,name=valueFiles"`
+	// Values specifies Helm values to be passed to helm template, typically defined as a block
+	Values string `json:"values,omitempty" yaml:"values,omitempty" protobuf:"bytes,4,opt,name=values"`
+}
+
+// ApplicationSourceKustomize holds options specific to an Application source specific to Kustomize
+type ApplicationSourceKustomize struct {
+	// CommonLabels is a list of additional labels to add to rendered manifests
+	CommonLabels map[string]string `json:"commonLabels,omitempty" yaml:"commonLabels,omitempty" protobuf:"bytes,3,opt,name=commonLabels"`
+	// CommonAnnotations is a list of additional annotations to add to rendered manifests
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty" yaml:"commonAnnotations,omitempty" protobuf:"bytes,5,opt,name=commonAnnotations"`
 }
 
 // ApplicationDestination holds information about the application's destination
@@ -72,6 +133,115 @@ type FauxComparedTo struct {
 	Destination ApplicationDestination `json:"destination"`
 }
 
+// FauxLastSync contains the details of the last sync operation of an Argo CD Application, based on the Application's
+// .status.operationState field.
+type FauxLastSync struct {
+	// Initiator is who/what triggered the sync operation (see db.SyncOperation_Initiator_* constants)
+	Initiator string `json:"initiator,omitempty"`
+	// StartedAt is the time the sync operation started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// FinishedAt is the time the sync operation completed
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// Revision is the revision that was synced
+	Revision string `json:"revision,omitempty"`
+}
+
+// FauxDiffPreview is a summary of the changes that a sync of an Argo CD Application would make, generated by the
+// cluster-agent when requested via the diff preview annotation.
+type FauxDiffPreview struct {
+	// RequestID is the value of the diff preview annotation that the preview was generated for
+	RequestID string `json:"requestID"`
+	// GeneratedAt is the time the preview was generated
+	GeneratedAt *time.Time `json:"generatedAt,omitempty"`
+	// Resources are the resources of the Application that would be changed by a sync
+	Resources []FauxResourceDiff `json:"resources,omitempty"`
+	// TotalResources is the number of resources that would be changed, which may exceed len(Resources) if the preview was truncated
+	TotalResources int `json:"totalResources"`
+	// Error is non-empty if the diff could not be retrieved from Argo CD
+	Error string `json:"error,omitempty"`
+}
+
+// FauxResourceEstimate is an estimate of the compute resources requested by the Pods of an Argo CD Application,
+// collected by the cluster-agent from the resource tree of the Application (when resource estimates are enabled).
+type FauxResourceEstimate struct {
+	// CollectedAt is the time the estimate was collected
+	CollectedAt *time.Time `json:"collectedAt,omitempty"`
+	// Revision is the synced revision of the Application when the estimate was collected
+	Revision string `json:"revision,omitempty"`
+	// CPURequests is the sum of the CPU requests of the running Pods, as a resource.Quantity string (for example, '1500m')
+	CPURequests string `json:"cpuRequests,omitempty"`
+	// MemoryRequests is the sum of the memory requests of the running Pods, as a resource.Quantity string (for example, '2Gi')
+	MemoryRequests string `json:"memoryRequests,omitempty"`
+	// Pods is the number of Pods whose requests are included in the estimate
+	Pods int `json:"pods"`
+	// TotalPods is the number of running Pods of the Application, which may exceed Pods if the estimate was truncated
+	TotalPods int `json:"totalPods"`
+	// Error is non-empty if the estimate could not be collected from Argo CD
+	Error string `json:"error,omitempty"`
+}
+
+// FauxSyncHooks contains the status of the resource hooks (for example, PreSync and PostSync Jobs) that were run by the
+// last sync operation of an Argo CD Application, based on the Application's .status.operationState.syncResult field.
+type FauxSyncHooks struct {
+	// Initiator is who/what triggered the sync operation (see db.SyncOperation_Initiator_* constants)
+	Initiator string `json:"initiator,omitempty"`
+	// StartedAt is the time the sync operation started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// Hooks are the hooks that were run by the sync operation, in the order reported by Argo CD
+	Hooks []FauxSyncHook `json:"hooks,omitempty"`
+}
+
+// FauxOperationState contains the details of the sync operation of an Argo CD Application that is in progress, based
+// on the Application's .status.operationState field.
+type FauxOperationState struct {
+	// Type is the type of the operation (see managedgitopsv1alpha1.OperationStateType)
+	Type string `json:"type"`
+	// StartedAt is the time the operation started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// Message is the message of the operation, as reported by Argo CD
+	Message string `json:"message,omitempty"`
+	// EstimatedCompletionAt is an estimate of when the operation will complete, based on the duration of the previous
+	// sync of the Application (unset if there is no previous sync)
+	EstimatedCompletionAt *time.Time `json:"estimatedCompletionAt,omitempty"`
+}
+
+// FauxSyncHook is the status of a resource hook that was run by a sync operation of an Argo CD Application.
+type FauxSyncHook struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// HookType is the type of the hook, for example 'PreSync' or 'PostSync'
+	HookType string `json:"hookType"`
+	// Phase is the phase of the hook, for example 'Running', 'Succeeded', or 'Failed'
+	Phase string `json:"phase,omitempty"`
+	// Message is the message of the hook, as reported by Argo CD
+	Message string `json:"message,omitempty"`
+	// LogTail contains the last lines of the logs of a hook Job that failed (or the reason they could not be retrieved)
+	LogTail string `json:"logTail,omitempty"`
+}
+
+// FauxResourceDiff summarizes the changes that a sync of an Argo CD Application would make to one of its resources.
+type FauxResourceDiff struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Action is one of: 'Create', 'Update', 'Delete'
+	Action string `json:"action"`
+	// ChangedFields is the number of fields of the resource that would be added, changed, or removed
+	ChangedFields int `json:"changedFields"`
+}
+
+// FauxSyncOperationResource identifies a resource of an Argo CD Application, to be synced by a selective sync operation.
+// This is based on Argo CD's SyncOperationResource.
+type FauxSyncOperationResource struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
 // SyncPolicy controls when a sync will be performed in response to updates in git
 type SyncPolicy struct {
 	// Automated will keep an application synced to the target revision
@@ -80,6 +250,14 @@ type SyncPolicy struct {
 	SyncOptions SyncOptions `json:"syncOptions,omitempty" protobuf:"bytes,2,opt,name=syncOptions"`
 	// Retry controls failed sync retry behavior
 	Retry *RetryStrategy `json:"retry,omitempty" protobuf:"bytes,3,opt,name=retry"`
+	// ManagedNamespaceMetadata controls metadata in the given namespace (if CreateNamespace=true)
+	ManagedNamespaceMetadata *ManagedNamespaceMetadata `json:"managedNamespaceMetadata,omitempty" protobuf:"bytes,4,opt,name=managedNamespaceMetadata"`
+}
+
+// ManagedNamespaceMetadata contains the labels and annotations of the namespace created by Argo CD
+type ManagedNamespaceMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty" protobuf:"bytes,1,opt,name=labels"`
+	Annotations map[string]string `json:"annotations,omitempty" protobuf:"bytes,2,opt,name=annotations"`
 }
 
 // SyncPolicyAutomated controls the behavior of an automated sync
@@ -0,0 +1,127 @@
+package featureflags
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Feature flags allow behaviours of the GitOps Service components to be toggled at runtime, without redeploying the
+// components. Flags are stored in the FeatureFlag database table, and may be set either:
+// - for the whole installation (the FeatureFlag row has an empty namespace), or
+// - for a single namespace, in which case the namespace-scoped row takes precedence over the installation-wide row.
+//
+// If a flag is set in neither, the default value that is passed by the caller is used.
+//
+// Rather than querying the database each time a flag is checked, the Client caches the contents of the table, and
+// refreshes the cache once it is older than the refresh interval: thus a change to a flag may take up to the refresh
+// interval to be observed by the components.
+
+const (
+	// DefaultRefreshInterval is the maximum age of the cached flags, before they are re-read from the database.
+	DefaultRefreshInterval = 1 * time.Minute
+)
+
+// Name is the name of a feature flag.
+type Name string
+
+const (
+	// NewNamingStrategy enables the new strategy for naming generated resources.
+	NewNamingStrategy Name = "new-naming-strategy"
+
+	// BatchedOperations enables batching of the Operations that are created for a set of resources.
+	BatchedOperations Name = "batched-operations"
+)
+
+// flagKey identifies a FeatureFlag row in the cache: an empty namespace is the installation-wide flag.
+type flagKey struct {
+	name      Name
+	namespace string
+}
+
+// Client returns the value of feature flags, from a cache of the FeatureFlag database table.
+type Client struct {
+	dbQueries       db.DatabaseQueries
+	refreshInterval time.Duration
+
+	// mutex protects the fields below it
+	mutex       sync.Mutex
+	flags       map[flagKey]bool
+	lastRefresh time.Time
+
+	// now may be replaced by unit tests
+	now func() time.Time
+}
+
+// NewClient returns a Client which reads the feature flags from the database, caching them for refreshInterval.
+// A refreshInterval of 0 uses DefaultRefreshInterval.
+func NewClient(dbQueries db.DatabaseQueries, refreshInterval time.Duration) *Client {
+
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+
+	return &Client{
+		dbQueries:       dbQueries,
+		refreshInterval: refreshInterval,
+		flags:           map[flagKey]bool{},
+		now:             time.Now,
+	}
+}
+
+// IsEnabled returns whether the flag is enabled for the namespace: the namespace-scoped value is used if one is set,
+// otherwise the installation-wide value, otherwise defaultValue. An empty namespace returns the installation-wide value.
+//
+// If the flags could not be read from the database, the previously cached values (if any) continue to be used.
+func (c *Client) IsEnabled(ctx context.Context, name Name, namespace string, defaultValue bool) bool {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.lastRefresh.IsZero() || c.now().Sub(c.lastRefresh) >= c.refreshInterval {
+		c.refreshLocked(ctx)
+	}
+
+	if namespace != "" {
+		if enabled, exists := c.flags[flagKey{name: name, namespace: namespace}]; exists {
+			return enabled
+		}
+	}
+
+	if enabled, exists := c.flags[flagKey{name: name}]; exists {
+		return enabled
+	}
+
+	return defaultValue
+}
+
+// Invalidate causes the flags to be re-read from the database on the next call to IsEnabled.
+func (c *Client) Invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.lastRefresh = time.Time{}
+}
+
+// refreshLocked replaces the cached flags with the contents of the database. The caller must hold the mutex.
+func (c *Client) refreshLocked(ctx context.Context) {
+
+	// Update the refresh time even on failure, so that an unavailable database is not queried on every call.
+	c.lastRefresh = c.now()
+
+	var featureFlags []db.FeatureFlag
+	if err := c.dbQueries.ListFeatureFlags(ctx, &featureFlags); err != nil {
+		log.FromContext(ctx).Error(err, "unable to refresh feature flags, the previously cached values will be used")
+		return
+	}
+
+	flags := map[flagKey]bool{}
+	for _, featureFlag := range featureFlags {
+		flags[flagKey{name: Name(featureFlag.Name), namespace: featureFlag.Namespace}] = featureFlag.Enabled
+	}
+
+	c.flags = flags
+}
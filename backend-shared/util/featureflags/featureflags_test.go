@@ -0,0 +1,121 @@
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+// mockFeatureFlagQueries implements only the ListFeatureFlags function of DatabaseQueries, which is the only function
+// used by the Client.
+type mockFeatureFlagQueries struct {
+	db.DatabaseQueries
+
+	featureFlags []db.FeatureFlag
+	err          error
+	listCalls    int
+}
+
+func (m *mockFeatureFlagQueries) ListFeatureFlags(ctx context.Context, featureFlags *[]db.FeatureFlag) error {
+	m.listCalls++
+
+	if m.err != nil {
+		return m.err
+	}
+
+	*featureFlags = append(*featureFlags, m.featureFlags...)
+	return nil
+}
+
+var _ = Describe("Feature flags client tests", func() {
+
+	var (
+		ctx         context.Context
+		dbQueries   *mockFeatureFlagQueries
+		client      *Client
+		currentTime time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		dbQueries = &mockFeatureFlagQueries{
+			featureFlags: []db.FeatureFlag{
+				{Name: string(BatchedOperations), Enabled: true},
+				{Name: string(BatchedOperations), Namespace: "disabled-namespace", Enabled: false},
+				{Name: string(NewNamingStrategy), Namespace: "enabled-namespace", Enabled: true},
+			},
+		}
+
+		currentTime = time.Now()
+		client = NewClient(dbQueries, time.Minute)
+		client.now = func() time.Time { return currentTime }
+	})
+
+	It("should prefer the namespace-scoped value, then the installation-wide value, then the default", func() {
+
+		By("verifying the installation-wide value is used, when the namespace does not override it")
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", false)).To(BeTrue())
+		Expect(client.IsEnabled(ctx, BatchedOperations, "other-namespace", false)).To(BeTrue())
+
+		By("verifying the namespace-scoped value takes precedence over the installation-wide value")
+		Expect(client.IsEnabled(ctx, BatchedOperations, "disabled-namespace", true)).To(BeFalse())
+
+		By("verifying the default is used when the flag is not set for the installation or the namespace")
+		Expect(client.IsEnabled(ctx, NewNamingStrategy, "enabled-namespace", false)).To(BeTrue())
+		Expect(client.IsEnabled(ctx, NewNamingStrategy, "other-namespace", false)).To(BeFalse())
+		Expect(client.IsEnabled(ctx, NewNamingStrategy, "other-namespace", true)).To(BeTrue())
+		Expect(client.IsEnabled(ctx, NewNamingStrategy, "", false)).To(BeFalse())
+	})
+
+	It("should cache the flags, until the refresh interval has elapsed", func() {
+
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", false)).To(BeTrue())
+		Expect(dbQueries.listCalls).To(Equal(1))
+
+		By("changing the flag in the database, and verifying the cached value is still used")
+		dbQueries.featureFlags[0].Enabled = false
+
+		currentTime = currentTime.Add(30 * time.Second)
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", false)).To(BeTrue())
+		Expect(dbQueries.listCalls).To(Equal(1))
+
+		By("verifying the new value is read once the refresh interval has elapsed")
+		currentTime = currentTime.Add(30 * time.Second)
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", true)).To(BeFalse())
+		Expect(dbQueries.listCalls).To(Equal(2))
+
+		By("verifying Invalidate causes the flags to be re-read")
+		dbQueries.featureFlags[0].Enabled = true
+		client.Invalidate()
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", false)).To(BeTrue())
+		Expect(dbQueries.listCalls).To(Equal(3))
+	})
+
+	It("should continue to use the cached flags if the database cannot be read", func() {
+
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", false)).To(BeTrue())
+
+		dbQueries.err = fmt.Errorf("simulated database error")
+		currentTime = currentTime.Add(time.Minute)
+
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", false)).To(BeTrue())
+		Expect(dbQueries.listCalls).To(Equal(2))
+
+		By("verifying the database is not queried again until the refresh interval has elapsed")
+		Expect(client.IsEnabled(ctx, BatchedOperations, "disabled-namespace", true)).To(BeFalse())
+		Expect(dbQueries.listCalls).To(Equal(2))
+	})
+
+	It("should use the default value if the flags have never been read", func() {
+
+		dbQueries.err = fmt.Errorf("simulated database error")
+
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", false)).To(BeFalse())
+		Expect(client.IsEnabled(ctx, BatchedOperations, "", true)).To(BeTrue())
+	})
+})
@@ -0,0 +1,257 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The health endpoints of the GitOps Service components (/healthz and /readyz) aggregate the result of a set of checks
+// (for example, database connectivity) into a JSON response body, for example:
+//
+//	{
+//	  "status": "failed",
+//	  "checks": [
+//	    { "name": "database", "status": "ok", "durationMs": 2 },
+//	    { "name": "informer-cache", "status": "failed", "error": "informer caches have not synced", "durationMs": 1000 }
+//	  ]
+//	}
+//
+// The HTTP status code is 200 if all checks succeeded, and 503 otherwise, so that the endpoints may be used for rollout gating.
+
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+
+	// DefaultCheckTimeout is the maximum amount of time each check may take, before it is considered failed.
+	DefaultCheckTimeout = 5 * time.Second
+)
+
+// Checker returns nil if the checked dependency is healthy, or an error describing why it is not.
+type Checker func(ctx context.Context) error
+
+// CheckResult is the result of a single check, within a Report.
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report is the JSON response body of a health endpoint.
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Handler is an http.Handler that runs a set of named checks, and responds with a Report.
+type Handler struct {
+	mutex   sync.RWMutex
+	checks  map[string]Checker
+	timeout time.Duration
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		checks:  map[string]Checker{},
+		timeout: DefaultCheckTimeout,
+	}
+}
+
+// AddCheck adds a check to the handler. A check with the same name is replaced.
+func (h *Handler) AddCheck(name string, checker Checker) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.checks[name] = checker
+}
+
+// Run runs all the checks of the handler concurrently, and returns the Report of the results.
+func (h *Handler) Run(ctx context.Context) Report {
+
+	h.mutex.RLock()
+	checks := make(map[string]Checker, len(h.checks))
+	for name, checker := range h.checks {
+		checks[name] = checker
+	}
+	h.mutex.RUnlock()
+
+	results := make([]CheckResult, 0, len(checks))
+	resultsMutex := sync.Mutex{}
+
+	wg := sync.WaitGroup{}
+	for name, checker := range checks {
+		wg.Add(1)
+
+		go func(name string, checker Checker) {
+			defer wg.Done()
+
+			result := runCheck(ctx, name, checker, h.timeout)
+
+			resultsMutex.Lock()
+			defer resultsMutex.Unlock()
+			results = append(results, result)
+		}(name, checker)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFailed
+		}
+	}
+
+	return report
+}
+
+func runCheck(ctx context.Context, name string, checker Checker, timeout time.Duration) (result CheckResult) {
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result = CheckResult{Name: name, Status: StatusOK}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status = StatusFailed
+			result.Error = fmt.Sprintf("panic: %v", r)
+		}
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	if err := checker(ctx); err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+
+	report := h.Run(req.Context())
+
+	statusCode := http.StatusOK
+	if report.Status != StatusOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// Server serves the /healthz (liveness) and /readyz (readiness) endpoints of a component. It implements the
+// controller-runtime Runnable interface, so that it may be added to (and run by) the controller manager.
+type Server struct {
+	Address string
+	Healthz *Handler
+	Readyz  *Handler
+	Log     logr.Logger
+}
+
+func NewServer(address string, log logr.Logger) *Server {
+	return &Server{
+		Address: address,
+		Healthz: NewHandler(),
+		Readyz:  NewHandler(),
+		Log:     log,
+	}
+}
+
+// Start serves the health endpoints until the context is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", s.Healthz)
+	mux.Handle("/readyz", s.Readyz)
+
+	listener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return fmt.Errorf("unable to listen on health probe address '%s': %v", s.Address, err)
+	}
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.Log.Error(err, "unable to shut down health probe server")
+		}
+	}()
+
+	s.Log.Info("Starting health probe server", "address", s.Address)
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// NeedLeaderElection returns false, as the health endpoints must be served by all replicas, not just the leader.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// DatabasePinger is implemented by the database queries of the GitOps Service (db.DatabaseQueries)
+type DatabasePinger interface {
+	Ping(ctx context.Context) error
+}
+
+// DatabaseCheck checks that the database is reachable.
+func DatabaseCheck(dbQueries DatabasePinger) Checker {
+	return func(ctx context.Context) error {
+		if err := dbQueries.Ping(ctx); err != nil {
+			return fmt.Errorf("unable to connect to database: %v", err)
+		}
+		return nil
+	}
+}
+
+// CacheSyncCheck checks that the informer caches of the controller manager have synced.
+func CacheSyncCheck(informerCache cache.Cache) Checker {
+	return func(ctx context.Context) error {
+		if !informerCache.WaitForCacheSync(ctx) {
+			return errors.New("informer caches have not synced")
+		}
+		return nil
+	}
+}
+
+// ListCheck checks that the given resources can be listed from the Kubernetes API. For example, this may be used to verify
+// that the Argo CD Application API is reachable. The reader should not be cached (e.g. the manager's API reader).
+func ListCheck(reader client.Reader, newList func() client.ObjectList, opts ...client.ListOption) Checker {
+	return func(ctx context.Context) error {
+		list := newList()
+		if err := reader.List(ctx, list, append(opts, client.Limit(1))...); err != nil {
+			return fmt.Errorf("unable to list %T: %v", list, err)
+		}
+		return nil
+	}
+}
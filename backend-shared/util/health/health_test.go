@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health endpoint tests", func() {
+
+	Context("Test Handler", func() {
+
+		serve := func(handler *Handler) (int, Report) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			var report Report
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &report)).To(Succeed())
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			return recorder.Code, report
+		}
+
+		It("should return 200 and a report of each check, if all checks succeed", func() {
+			handler := NewHandler()
+			handler.AddCheck("database", func(ctx context.Context) error { return nil })
+			handler.AddCheck("informer-cache", func(ctx context.Context) error { return nil })
+
+			code, report := serve(handler)
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(report.Status).To(Equal(StatusOK))
+			Expect(report.Checks).To(HaveLen(2))
+			Expect(report.Checks[0].Name).To(Equal("database"))
+			Expect(report.Checks[0].Status).To(Equal(StatusOK))
+			Expect(report.Checks[1].Name).To(Equal("informer-cache"))
+		})
+
+		It("should return 503 and the error of the failed check, if any check fails", func() {
+			handler := NewHandler()
+			handler.AddCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+			handler.AddCheck("informer-cache", func(ctx context.Context) error { return nil })
+
+			code, report := serve(handler)
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(report.Status).To(Equal(StatusFailed))
+			Expect(report.Checks[0].Status).To(Equal(StatusFailed))
+			Expect(report.Checks[0].Error).To(Equal("connection refused"))
+			Expect(report.Checks[1].Status).To(Equal(StatusOK))
+		})
+
+		It("should fail a check that does not complete within the timeout, or that panics", func() {
+			handler := NewHandler()
+			handler.timeout = 10 * time.Millisecond
+			handler.AddCheck("slow", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			handler.AddCheck("panic", func(ctx context.Context) error { panic("unexpected") })
+
+			code, report := serve(handler)
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(report.Checks[0].Name).To(Equal("panic"))
+			Expect(report.Checks[0].Error).To(ContainSubstring("unexpected"))
+			Expect(report.Checks[1].Name).To(Equal("slow"))
+			Expect(report.Checks[1].Error).To(ContainSubstring("deadline exceeded"))
+		})
+
+		It("should return 200 if there are no checks", func() {
+			code, report := serve(NewHandler())
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(report.Checks).To(BeEmpty())
+		})
+	})
+
+	Context("Test DatabaseCheck", func() {
+		It("should return the error of the database ping", func() {
+			Expect(DatabaseCheck(fakePinger{})(context.Background())).To(Succeed())
+
+			err := DatabaseCheck(fakePinger{err: errors.New("timeout")})(context.Background())
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("timeout"))
+		})
+	})
+})
+
+type fakePinger struct {
+	err error
+}
+
+func (f fakePinger) Ping(ctx context.Context) error {
+	return f.err
+}
@@ -0,0 +1,371 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Audit event forwarding:
+//
+// Every API resource change that is logged via LogAPIResourceChangeEvent is also forwarded (as an AuditEvent) to the
+// registered audit sinks, so that the changes made by the GitOps Service controllers may be consumed by external
+// systems (for example, a SIEM).
+//
+// - Audit sinks are configured via environment variables: see ConfigureAuditSinksFromEnv.
+// - Each sink has its own queue and goroutine, so that a slow (or unavailable) sink does not block the controllers, or
+//   the other sinks.
+// - Failed deliveries are retried with exponential backoff. If the queue of a sink is full, or the event could not be
+//   delivered after the maximum number of attempts, the event is dropped and a warning is logged.
+
+const (
+	// AuditSinksEnvVar is a comma-separated list of the audit sinks that audit events should be forwarded to.
+	// Supported values: 'stdout', 'cloudevents', 'kafka'. If empty, audit events are not forwarded.
+	AuditSinksEnvVar = "AUDIT_SINKS"
+
+	// AuditCloudEventsURLEnvVar is the URL of the HTTP endpoint that CloudEvents are sent to, by the 'cloudevents' sink.
+	AuditCloudEventsURLEnvVar = "AUDIT_CLOUDEVENTS_URL"
+
+	// AuditKafkaRESTProxyURLEnvVar is the URL of the Kafka REST proxy that records are produced to, by the 'kafka' sink.
+	AuditKafkaRESTProxyURLEnvVar = "AUDIT_KAFKA_REST_PROXY_URL"
+
+	// AuditKafkaTopicEnvVar is the Kafka topic that records are produced to, by the 'kafka' sink.
+	AuditKafkaTopicEnvVar = "AUDIT_KAFKA_TOPIC"
+
+	// AuditEventSourceEnvVar is the source of the audit events (for example, the name of the component): if not set,
+	// 'managed-gitops' is used.
+	AuditEventSourceEnvVar = "AUDIT_EVENT_SOURCE"
+
+	AuditSinkType_Stdout      = "stdout"
+	AuditSinkType_CloudEvents = "cloudevents"
+	AuditSinkType_Kafka       = "kafka"
+
+	defaultAuditEventSource = "managed-gitops"
+
+	auditSinkQueueSize         = 1000
+	auditSinkMaxAttempts       = 5
+	auditSinkInitialBackoff    = 200 * time.Millisecond
+	auditSinkMaxBackoff        = 10 * time.Second
+	auditSinkHTTPClientTimeout = 10 * time.Second
+)
+
+// AuditEvent describes a change to an API resource, made by a GitOps Service controller.
+type AuditEvent struct {
+	// ID uniquely identifies the event
+	ID string `json:"id"`
+	// Time is the time at which the change was made
+	Time time.Time `json:"time"`
+	// Source is the component that made the change
+	Source string `json:"source"`
+
+	ChangeType ResourceChangeType `json:"changeType"`
+
+	// ResourceType is the Go type of the resource, for example '*v1alpha1.GitOpsDeployment'
+	ResourceType string `json:"resourceType"`
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+
+	// Object is the JSON representation of the resource (with the data of Secrets removed)
+	Object json.RawMessage `json:"object"`
+}
+
+// AuditSink delivers audit events to an external system.
+type AuditSink interface {
+	// Name returns the name of the sink, for logging
+	Name() string
+
+	// Send delivers the event to the external system. An error is returned if the delivery should be retried.
+	Send(ctx context.Context, event AuditEvent) error
+}
+
+var (
+	auditSinksMutex sync.RWMutex
+	auditSinks      []*auditSinkWorker
+)
+
+type auditSinkWorker struct {
+	sink  AuditSink
+	queue chan AuditEvent
+	stop  chan struct{}
+}
+
+// RegisterAuditSink adds a sink that all subsequent audit events will be forwarded to.
+func RegisterAuditSink(sink AuditSink) {
+
+	worker := &auditSinkWorker{
+		sink:  sink,
+		queue: make(chan AuditEvent, auditSinkQueueSize),
+		stop:  make(chan struct{}),
+	}
+
+	go worker.run()
+
+	auditSinksMutex.Lock()
+	defer auditSinksMutex.Unlock()
+	auditSinks = append(auditSinks, worker)
+}
+
+// resetAuditSinks stops and removes all the registered audit sinks. Only used by unit tests.
+func resetAuditSinks() {
+	auditSinksMutex.Lock()
+	defer auditSinksMutex.Unlock()
+
+	for _, worker := range auditSinks {
+		close(worker.stop)
+	}
+	auditSinks = nil
+}
+
+// ConfigureAuditSinksFromEnv registers the audit sinks that are listed in the AUDIT_SINKS env var (if any).
+func ConfigureAuditSinksFromEnv(log logr.Logger) error {
+
+	httpClient := &http.Client{Timeout: auditSinkHTTPClientTimeout}
+
+	for _, sinkType := range strings.Split(os.Getenv(AuditSinksEnvVar), ",") {
+
+		switch sinkType = strings.TrimSpace(sinkType); sinkType {
+		case "":
+			continue
+
+		case AuditSinkType_Stdout:
+			RegisterAuditSink(&StdoutAuditSink{})
+
+		case AuditSinkType_CloudEvents:
+			sinkURL := os.Getenv(AuditCloudEventsURLEnvVar)
+			if _, err := url.ParseRequestURI(sinkURL); err != nil {
+				return fmt.Errorf("invalid value for %s: '%s'", AuditCloudEventsURLEnvVar, sinkURL)
+			}
+			RegisterAuditSink(&CloudEventsAuditSink{URL: sinkURL, Client: httpClient})
+
+		case AuditSinkType_Kafka:
+			proxyURL, topic := os.Getenv(AuditKafkaRESTProxyURLEnvVar), os.Getenv(AuditKafkaTopicEnvVar)
+			if _, err := url.ParseRequestURI(proxyURL); err != nil {
+				return fmt.Errorf("invalid value for %s: '%s'", AuditKafkaRESTProxyURLEnvVar, proxyURL)
+			}
+			if topic == "" {
+				return fmt.Errorf("%s must be set when the '%s' audit sink is used", AuditKafkaTopicEnvVar, AuditSinkType_Kafka)
+			}
+			RegisterAuditSink(&KafkaRESTProxyAuditSink{URL: proxyURL, Topic: topic, Client: httpClient})
+
+		default:
+			return fmt.Errorf("unsupported audit sink in %s: '%s'", AuditSinksEnvVar, sinkType)
+		}
+
+		log.Info("Audit events will be forwarded to audit sink", "auditSink", sinkType)
+	}
+
+	return nil
+}
+
+// forwardAuditEvent adds an audit event for the resource change to the queue of each registered audit sink.
+func forwardAuditEvent(resourceNamespace string, resourceName string, resource any, resourceChangeType ResourceChangeType,
+	jsonRepresentation []byte, log logr.Logger) {
+
+	auditSinksMutex.RLock()
+	defer auditSinksMutex.RUnlock()
+
+	if len(auditSinks) == 0 {
+		return
+	}
+
+	event := newAuditEvent(resourceNamespace, resourceName, resource, resourceChangeType, jsonRepresentation)
+
+	for _, worker := range auditSinks {
+		select {
+		case worker.queue <- event:
+		default:
+			log.V(LogLevel_Warn).Info("audit sink queue is full: the audit event was dropped", "auditSink", worker.sink.Name(),
+				"eventID", event.ID)
+		}
+	}
+}
+
+func newAuditEvent(resourceNamespace string, resourceName string, resource any, resourceChangeType ResourceChangeType,
+	jsonRepresentation []byte) AuditEvent {
+
+	source := os.Getenv(AuditEventSourceEnvVar)
+	if source == "" {
+		source = defaultAuditEventSource
+	}
+
+	return AuditEvent{
+		ID:           uuid.NewString(),
+		Time:         time.Now().UTC(),
+		Source:       source,
+		ChangeType:   resourceChangeType,
+		ResourceType: fmt.Sprintf("%T", resource),
+		Namespace:    resourceNamespace,
+		Name:         resourceName,
+		Object:       jsonRepresentation,
+	}
+}
+
+func (w *auditSinkWorker) run() {
+
+	log := log.FromContext(context.Background()).
+		WithName(LogLogger_managed_gitops).
+		WithValues("auditSink", w.sink.Name())
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case event := <-w.queue:
+			w.deliver(event, log)
+		}
+	}
+}
+
+// deliver sends the event to the sink, retrying with exponential backoff on failure.
+func (w *auditSinkWorker) deliver(event AuditEvent, log logr.Logger) {
+
+	backoff := auditSinkInitialBackoff
+
+	for attempt := 1; ; attempt++ {
+
+		err := w.sink.Send(context.Background(), event)
+		if err == nil {
+			return
+		}
+
+		if attempt >= auditSinkMaxAttempts {
+			log.V(LogLevel_Warn).Info("unable to deliver audit event: the audit event was dropped", "eventID", event.ID,
+				"attempts", attempt, "error", err.Error())
+			return
+		}
+
+		log.V(LogLevel_Debug).Info("unable to deliver audit event, retrying", "eventID", event.ID, "attempt", attempt,
+			"error", err.Error())
+
+		select {
+		case <-w.stop:
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > auditSinkMaxBackoff {
+			backoff = auditSinkMaxBackoff
+		}
+	}
+}
+
+// StdoutAuditSink writes each audit event to stdout, as a single line of JSON.
+type StdoutAuditSink struct{}
+
+func (s *StdoutAuditSink) Name() string {
+	return AuditSinkType_Stdout
+}
+
+func (s *StdoutAuditSink) Send(ctx context.Context, event AuditEvent) error {
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, string(eventJSON))
+	return err
+}
+
+// CloudEventsAuditSink sends each audit event to an HTTP endpoint, as a CloudEvent (in binary content mode).
+type CloudEventsAuditSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *CloudEventsAuditSink) Name() string {
+	return AuditSinkType_CloudEvents
+}
+
+func (s *CloudEventsAuditSink) Send(ctx context.Context, event AuditEvent) error {
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(eventJSON))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("ce-specversion", "1.0")
+	req.Header.Set("ce-id", event.ID)
+	req.Header.Set("ce-source", event.Source)
+	req.Header.Set("ce-type", "com.redhat.managed-gitops.resource."+strings.ToLower(string(event.ChangeType)))
+	req.Header.Set("ce-time", event.Time.Format(time.RFC3339Nano))
+	req.Header.Set("ce-subject", event.Namespace+"/"+event.Name)
+
+	return doAuditSinkRequest(s.Client, req)
+}
+
+// KafkaRESTProxyAuditSink produces each audit event as a record to a Kafka topic, via a Kafka REST proxy (v2 API).
+// The key of each record is the namespace/name of the resource, so that the events of a resource are kept in order.
+type KafkaRESTProxyAuditSink struct {
+	URL    string
+	Topic  string
+	Client *http.Client
+}
+
+func (s *KafkaRESTProxyAuditSink) Name() string {
+	return AuditSinkType_Kafka
+}
+
+func (s *KafkaRESTProxyAuditSink) Send(ctx context.Context, event AuditEvent) error {
+
+	type kafkaRecord struct {
+		Key   string     `json:"key"`
+		Value AuditEvent `json:"value"`
+	}
+
+	body, err := json.Marshal(struct {
+		Records []kafkaRecord `json:"records"`
+	}{
+		Records: []kafkaRecord{{Key: event.Namespace + "/" + event.Name, Value: event}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(s.URL, "/")+"/topics/"+url.PathEscape(s.Topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+
+	return doAuditSinkRequest(s.Client, req)
+}
+
+// doAuditSinkRequest sends the request, and returns an error if the request failed or the response was not successful.
+func doAuditSinkRequest(client *http.Client, req *http.Request) error {
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status code from %s: %d", req.URL.String(), resp.StatusCode)
+	}
+
+	return nil
+}
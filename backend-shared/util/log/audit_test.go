@@ -0,0 +1,203 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// fakeAuditSink records the events it receives, and fails the first 'failures' deliveries.
+type fakeAuditSink struct {
+	mutex    sync.Mutex
+	failures int
+	attempts int
+	events   []AuditEvent
+}
+
+func (s *fakeAuditSink) Name() string {
+	return "fake"
+}
+
+func (s *fakeAuditSink) Send(ctx context.Context, event AuditEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.attempts++
+	if s.attempts <= s.failures {
+		return fmt.Errorf("simulated failure")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *fakeAuditSink) receivedEvents() []AuditEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]AuditEvent{}, s.events...)
+}
+
+var _ = Describe("Audit sink tests", func() {
+
+	var secret *corev1.Secret
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "my-namespace"},
+			Data:       map[string][]byte{"password": []byte("do-not-forward")},
+		}
+		DeferCleanup(resetAuditSinks)
+	})
+
+	Context("Test LogAPIResourceChangeEvent", func() {
+
+		It("should forward the resource change to the registered audit sinks, without the data of Secrets", func() {
+			sink := &fakeAuditSink{}
+			RegisterAuditSink(sink)
+
+			LogAPIResourceChangeEvent(secret.Namespace, secret.Name, secret, ResourceCreated, log.FromContext(context.Background()))
+
+			Eventually(sink.receivedEvents, "5s", "50ms").Should(HaveLen(1))
+
+			event := sink.receivedEvents()[0]
+			Expect(event.ID).ToNot(BeEmpty())
+			Expect(event.ChangeType).To(Equal(ResourceCreated))
+			Expect(event.Namespace).To(Equal(secret.Namespace))
+			Expect(event.Name).To(Equal(secret.Name))
+			Expect(event.ResourceType).To(Equal("*v1.Secret"))
+			Expect(string(event.Object)).ToNot(ContainSubstring("password"))
+		})
+
+		It("should retry the delivery of an audit event that fails", func() {
+			sink := &fakeAuditSink{failures: 2}
+			RegisterAuditSink(sink)
+
+			LogAPIResourceChangeEvent(secret.Namespace, secret.Name, secret, ResourceModified, log.FromContext(context.Background()))
+
+			Eventually(sink.receivedEvents, "5s", "50ms").Should(HaveLen(1))
+			Expect(sink.receivedEvents()[0].ChangeType).To(Equal(ResourceModified))
+		})
+	})
+
+	Context("Test HTTP audit sinks", func() {
+
+		var (
+			requests chan *http.Request
+			bodies   chan []byte
+			server   *httptest.Server
+		)
+
+		BeforeEach(func() {
+			requests = make(chan *http.Request, 1)
+			bodies = make(chan []byte, 1)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				requests <- r
+				bodies <- body
+				w.WriteHeader(http.StatusOK)
+			}))
+			DeferCleanup(server.Close)
+		})
+
+		event := AuditEvent{
+			ID:         "my-id",
+			Time:       time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			Source:     "backend",
+			ChangeType: ResourceDeleted,
+			Namespace:  "my-namespace",
+			Name:       "my-resource",
+			Object:     json.RawMessage(`{}`),
+		}
+
+		It("should send the audit event as a CloudEvent", func() {
+			sink := &CloudEventsAuditSink{URL: server.URL, Client: server.Client()}
+			Expect(sink.Send(context.Background(), event)).To(Succeed())
+
+			req := <-requests
+			Expect(req.Method).To(Equal(http.MethodPost))
+			Expect(req.Header.Get("ce-specversion")).To(Equal("1.0"))
+			Expect(req.Header.Get("ce-id")).To(Equal("my-id"))
+			Expect(req.Header.Get("ce-source")).To(Equal("backend"))
+			Expect(req.Header.Get("ce-type")).To(Equal("com.redhat.managed-gitops.resource.deleted"))
+			Expect(req.Header.Get("ce-subject")).To(Equal("my-namespace/my-resource"))
+
+			received := AuditEvent{}
+			Expect(json.Unmarshal(<-bodies, &received)).To(Succeed())
+			Expect(received).To(Equal(event))
+		})
+
+		It("should produce the audit event as a Kafka record, via the REST proxy", func() {
+			sink := &KafkaRESTProxyAuditSink{URL: server.URL + "/", Topic: "audit", Client: server.Client()}
+			Expect(sink.Send(context.Background(), event)).To(Succeed())
+
+			req := <-requests
+			Expect(req.URL.Path).To(Equal("/topics/audit"))
+			Expect(req.Header.Get("Content-Type")).To(Equal("application/vnd.kafka.json.v2+json"))
+
+			received := struct {
+				Records []struct {
+					Key   string     `json:"key"`
+					Value AuditEvent `json:"value"`
+				} `json:"records"`
+			}{}
+			Expect(json.Unmarshal(<-bodies, &received)).To(Succeed())
+			Expect(received.Records).To(HaveLen(1))
+			Expect(received.Records[0].Key).To(Equal("my-namespace/my-resource"))
+			Expect(received.Records[0].Value).To(Equal(event))
+		})
+
+		It("should return an error if the response is not successful", func() {
+			failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer failingServer.Close()
+
+			sink := &CloudEventsAuditSink{URL: failingServer.URL, Client: failingServer.Client()}
+			Expect(sink.Send(context.Background(), event)).ToNot(Succeed())
+		})
+	})
+
+	Context("Test ConfigureAuditSinksFromEnv", func() {
+
+		BeforeEach(func() {
+			for _, envVar := range []string{AuditSinksEnvVar, AuditCloudEventsURLEnvVar, AuditKafkaRESTProxyURLEnvVar, AuditKafkaTopicEnvVar} {
+				DeferCleanup(os.Unsetenv, envVar)
+			}
+		})
+
+		It("should not register any sinks if the env var is not set", func() {
+			Expect(ConfigureAuditSinksFromEnv(log.FromContext(context.Background()))).To(Succeed())
+			Expect(auditSinks).To(BeEmpty())
+		})
+
+		It("should register the configured sinks", func() {
+			Expect(os.Setenv(AuditSinksEnvVar, "stdout, cloudevents,kafka")).To(Succeed())
+			Expect(os.Setenv(AuditCloudEventsURLEnvVar, "http://cloudevents.example.com")).To(Succeed())
+			Expect(os.Setenv(AuditKafkaRESTProxyURLEnvVar, "http://kafka-rest.example.com")).To(Succeed())
+			Expect(os.Setenv(AuditKafkaTopicEnvVar, "audit")).To(Succeed())
+
+			Expect(ConfigureAuditSinksFromEnv(log.FromContext(context.Background()))).To(Succeed())
+			Expect(auditSinks).To(HaveLen(3))
+		})
+
+		It("should return an error for an unsupported sink, or a sink with missing configuration", func() {
+			Expect(os.Setenv(AuditSinksEnvVar, "syslog")).To(Succeed())
+			Expect(ConfigureAuditSinksFromEnv(log.FromContext(context.Background()))).ToNot(Succeed())
+
+			Expect(os.Setenv(AuditSinksEnvVar, AuditSinkType_Kafka)).To(Succeed())
+			Expect(os.Setenv(AuditKafkaRESTProxyURLEnvVar, "http://kafka-rest.example.com")).To(Succeed())
+			Expect(ConfigureAuditSinksFromEnv(log.FromContext(context.Background()))).ToNot(Succeed())
+		})
+	})
+})
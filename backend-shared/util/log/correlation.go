@@ -0,0 +1,149 @@
+package util
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	crzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// LogCorrelationIDKey is the key of the correlation ID in structured log output.
+	//
+	// The correlation ID allows the logs of the backend and cluster-agent to be correlated (for example, in Loki): it is
+	// the UID of the API resource (GitOpsDeployment, GitOpsDeploymentSyncRun, etc) that triggered the work, followed by
+	// the ID of the Operation that was created to perform that work on the cluster-agent (if any): '(uid)/(operation id)'
+	LogCorrelationIDKey = "correlationID"
+
+	// CorrelationIDAnnotation is set by the backend on Operation CRs, so that the cluster-agent may log using the
+	// same correlation ID as the backend.
+	CorrelationIDAnnotation = "managed-gitops.redhat.com/correlation-id"
+)
+
+type correlationIDContextKey struct{}
+
+// NewJSONLoggerOptions returns the logger options shared by all the GitOps Service controllers, so that the logs of the
+// GitOps Service components may be correlated (for example, in Loki):
+// - structured JSON log output, even in development mode ('--zap-devel'), with the same field names in all components
+// - ISO8601 timestamps, and the caller of each log statement
+//
+// The options may be further customized via command line flags, using BindFlags (for example, '--zap-encoder=console').
+func NewJSONLoggerOptions() crzap.Options {
+	return crzap.Options{
+		NewEncoder: newJSONEncoder,
+		EncoderConfigOptions: []crzap.EncoderConfigOption{
+			func(encoderConfig *zapcore.EncoderConfig) {
+				encoderConfig.TimeKey = "time"
+				encoderConfig.EncodeDuration = zapcore.StringDurationEncoder
+			},
+		},
+		TimeEncoder: zapcore.ISO8601TimeEncoder,
+		ZapOpts: []zap.Option{
+			zap.WithCaller(true),
+		},
+	}
+}
+
+// newJSONEncoder returns a JSON log encoder, using the production encoder configuration of zap as a base.
+func newJSONEncoder(opts ...crzap.EncoderConfigOption) zapcore.Encoder {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	for _, opt := range opts {
+		opt(&encoderConfig)
+	}
+	return zapcore.NewJSONEncoder(encoderConfig)
+}
+
+// GenerateCorrelationID returns the correlation ID of work that was triggered by the API resource with the given UID.
+func GenerateCorrelationID(resourceUID types.UID) string {
+	return string(resourceUID)
+}
+
+// GenerateOperationCorrelationID returns the correlation ID of an Operation, created as part of the work with the given correlation ID.
+// - If the parent correlation ID is empty, the Operation ID is returned.
+func GenerateOperationCorrelationID(parentCorrelationID string, operationID string) string {
+	if parentCorrelationID == "" {
+		return operationID
+	}
+	return parentCorrelationID + "/" + operationID
+}
+
+// CorrelationIDOfObject returns the correlation ID of a resource: the value of the CorrelationIDAnnotation annotation, if set,
+// otherwise a correlation ID generated from the UID of the resource.
+func CorrelationIDOfObject(obj client.Object) string {
+	if correlationID, exists := obj.GetAnnotations()[CorrelationIDAnnotation]; exists && correlationID != "" {
+		return correlationID
+	}
+	return GenerateCorrelationID(obj.GetUID())
+}
+
+// SetCorrelationIDAnnotation sets the CorrelationIDAnnotation annotation of a resource, so that the work triggered by the
+// resource (for example, the reconciliation of an Argo CD Application created by an Operation) is logged using the
+// correlation ID of the work that created/updated the resource.
+// - If the correlation ID is empty, the resource is not modified.
+func SetCorrelationIDAnnotation(obj client.Object, correlationID string) {
+	if correlationID == "" {
+		return
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[CorrelationIDAnnotation] = correlationID
+	obj.SetAnnotations(annotations)
+}
+
+// ContextWithCorrelationID returns a copy of the context containing the correlation ID. The logger of the context
+// (as returned by log.FromContext) will include the correlation ID in all log statements.
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	if correlationID == "" {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, correlationIDContextKey{}, correlationID)
+
+	return log.IntoContext(ctx, log.FromContext(ctx).WithValues(LogCorrelationIDKey, correlationID))
+}
+
+// CorrelationIDFromContext returns the correlation ID of the context, or "" if the context does not contain one.
+func CorrelationIDFromContext(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDContextKey{}).(string)
+	return correlationID
+}
+
+// NewCorrelationIDMiddleware wraps a reconciler, so that the context passed to the reconciler contains the correlation ID
+// of the resource being reconciled.
+// - newObject should return an empty instance of the resource type that is reconciled by the reconciler.
+// - If the resource cannot be retrieved (for example, because it was deleted), the reconciler is called without a correlation ID.
+func NewCorrelationIDMiddleware(k8sClient client.Client, newObject func() client.Object, inner reconcile.Reconciler) reconcile.Reconciler {
+	return &correlationIDMiddleware{
+		k8sClient: k8sClient,
+		newObject: newObject,
+		inner:     inner,
+	}
+}
+
+type correlationIDMiddleware struct {
+	k8sClient client.Client
+	newObject func() client.Object
+	inner     reconcile.Reconciler
+}
+
+func (m *correlationIDMiddleware) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+
+	obj := m.newObject()
+
+	if err := m.k8sClient.Get(ctx, req.NamespacedName, obj); err == nil {
+		ctx = ContextWithCorrelationID(ctx, CorrelationIDOfObject(obj))
+	} else if !apierr.IsNotFound(err) {
+		log.FromContext(ctx).V(LogLevel_Debug).Info("unable to retrieve resource to determine its correlation ID", "error", err.Error())
+	}
+
+	return m.inner.Reconcile(ctx, req)
+}
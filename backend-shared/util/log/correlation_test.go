@@ -0,0 +1,129 @@
+package util
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcilerFunc is a reconcile.Reconciler that calls a function
+type reconcilerFunc func(ctx context.Context, req reconcile.Request) (reconcile.Result, error)
+
+func (f reconcilerFunc) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	return f(ctx, req)
+}
+
+var _ = Describe("Correlation ID tests", func() {
+
+	Context("Test GenerateOperationCorrelationID", func() {
+
+		It("should append the Operation ID to the parent correlation ID", func() {
+			Expect(GenerateOperationCorrelationID("my-uid", "my-operation")).To(Equal("my-uid/my-operation"))
+		})
+
+		It("should return the Operation ID if there is no parent correlation ID", func() {
+			Expect(GenerateOperationCorrelationID("", "my-operation")).To(Equal("my-operation"))
+		})
+	})
+
+	Context("Test CorrelationIDOfObject", func() {
+
+		It("should return the UID of the resource, if the resource has no correlation ID annotation", func() {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{UID: "my-uid"}}
+			Expect(CorrelationIDOfObject(configMap)).To(Equal("my-uid"))
+		})
+
+		It("should return the correlation ID annotation of the resource, if set", func() {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				UID:         "my-uid",
+				Annotations: map[string]string{CorrelationIDAnnotation: "parent-uid/my-operation"},
+			}}
+			Expect(CorrelationIDOfObject(configMap)).To(Equal("parent-uid/my-operation"))
+		})
+	})
+
+	Context("Test SetCorrelationIDAnnotation", func() {
+
+		It("should set the correlation ID annotation, preserving the other annotations", func() {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"other": "value"}}}
+			SetCorrelationIDAnnotation(configMap, "parent-uid/my-operation")
+			Expect(configMap.Annotations).To(Equal(map[string]string{"other": "value", CorrelationIDAnnotation: "parent-uid/my-operation"}))
+			Expect(CorrelationIDOfObject(configMap)).To(Equal("parent-uid/my-operation"))
+		})
+
+		It("should not modify the resource if the correlation ID is empty", func() {
+			configMap := &corev1.ConfigMap{}
+			SetCorrelationIDAnnotation(configMap, "")
+			Expect(configMap.Annotations).To(BeNil())
+		})
+	})
+
+	Context("Test ContextWithCorrelationID", func() {
+
+		It("should store the correlation ID in the context", func() {
+			ctx := ContextWithCorrelationID(context.Background(), "my-uid")
+			Expect(CorrelationIDFromContext(ctx)).To(Equal("my-uid"))
+		})
+
+		It("should not modify the context if the correlation ID is empty", func() {
+			ctx := context.Background()
+			Expect(ContextWithCorrelationID(ctx, "")).To(Equal(ctx))
+			Expect(CorrelationIDFromContext(ctx)).To(Equal(""))
+		})
+	})
+
+	Context("Test NewCorrelationIDMiddleware", func() {
+
+		var configMap *corev1.ConfigMap
+
+		BeforeEach(func() {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-config-map",
+					Namespace: "my-namespace",
+					UID:       "my-uid",
+				},
+			}
+		})
+
+		callMiddleware := func(objs ...*corev1.ConfigMap) string {
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			for _, obj := range objs {
+				builder = builder.WithObjects(obj)
+			}
+
+			correlationID := ""
+			inner := reconcilerFunc(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				correlationID = CorrelationIDFromContext(ctx)
+				return reconcile.Result{}, nil
+			})
+
+			middleware := NewCorrelationIDMiddleware(builder.Build(), func() client.Object { return &corev1.ConfigMap{} }, inner)
+
+			_, err := middleware.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: configMap.Namespace, Name: configMap.Name}})
+			Expect(err).To(BeNil())
+
+			return correlationID
+		}
+
+		It("should call the reconciler with the correlation ID of the resource", func() {
+			Expect(callMiddleware(configMap)).To(Equal("my-uid"))
+		})
+
+		It("should call the reconciler without a correlation ID, if the resource doesn't exist", func() {
+			Expect(callMiddleware()).To(Equal(""))
+		})
+	})
+})
@@ -29,6 +29,8 @@ const (
 	ResourceDeleted  ResourceChangeType = "Deleted"
 )
 
+// LogAPIResourceChangeEvent logs a change to an API resource (with the 'audit' key), and forwards the change to the
+// registered audit sinks (see RegisterAuditSink).
 func LogAPIResourceChangeEvent(resourceNamespace string, resourceName string, resource any, resourceChangeType ResourceChangeType, log logr.Logger) {
 	log = log.WithValues("audit", "true")
 
@@ -53,4 +55,6 @@ func LogAPIResourceChangeEvent(resourceNamespace string, resourceName string, re
 
 	log.Info(fmt.Sprintf("API Resource changed: %s", string(resourceChangeType)), "namespace",
 		resourceNamespace, "name", resourceName, "object", string(jsonRepresentation))
+
+	forwardAuditEvent(resourceNamespace, resourceName, resource, resourceChangeType, jsonRepresentation, log)
 }
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// LogRequeueReasonKey is the key of the requeue reason in structured log output: the logs of all the GitOps Service
+	// controllers use the same key, so that reconciles may be grouped by what they are waiting on (for example, in Loki).
+	LogRequeueReasonKey = "requeueReason"
+
+	// LogRequeueAfterKey is the key of the delay after which the request is requeued. A delay of 0 indicates that
+	// the request is not requeued after a delay: either it is requeued with backoff (on error), or it is waiting for a
+	// watched resource to change.
+	LogRequeueAfterKey = "requeueAfter"
+)
+
+// RequeueReason is a machine-readable reason for which a reconcile did not complete its work, and is requeued (or is
+// waiting for a watched resource to change).
+type RequeueReason string
+
+const (
+	// RequeueReasonWaitingForDTCBound: the DeploymentTargetClaim of an Environment is not yet bound to a DeploymentTarget.
+	RequeueReasonWaitingForDTCBound RequeueReason = "WaitingForDTCBound"
+
+	// RequeueReasonSecretNotFound: a Secret referenced by the resource does not exist (yet).
+	RequeueReasonSecretNotFound RequeueReason = "SecretNotFound"
+
+	// RequeueReasonArgoOperationInProgress: an Argo CD operation (for example, a sync) is in progress on the Application.
+	RequeueReasonArgoOperationInProgress RequeueReason = "ArgoOperationInProgress"
+
+	// RequeueReasonWaitingForComponentStatus: the component status of a SnapshotEnvironmentBinding has not yet been set
+	// by the application-service.
+	RequeueReasonWaitingForComponentStatus RequeueReason = "WaitingForComponentStatus"
+
+	// RequeueReasonWaitingForPreviousSyncWave: the components of a previous sync wave of a SnapshotEnvironmentBinding
+	// are not yet deployed.
+	RequeueReasonWaitingForPreviousSyncWave RequeueReason = "WaitingForPreviousSyncWave"
+
+	// RequeueReasonGitOpsRepoWriteFailed: the write to the GitOps repository of a SnapshotEnvironmentBinding failed,
+	// and is retried after a delay.
+	RequeueReasonGitOpsRepoWriteFailed RequeueReason = "GitOpsRepoWriteFailed"
+
+	// RequeueReasonWaitingForDeletionProtection: the deletion of a resource is blocked by deletion protection, until
+	// the resources that reference it are deleted.
+	RequeueReasonWaitingForDeletionProtection RequeueReason = "WaitingForDeletionProtection"
+
+	// RequeueReasonRevisionResolutionPending: the refs of the Git repository of an Argo CD Application are being
+	// retrieved in the background.
+	RequeueReasonRevisionResolutionPending RequeueReason = "RevisionResolutionPending"
+
+	// RequeueReasonReadOnlyMode: the GitOps Service is in read-only mode, so the request is not processed until
+	// read-only mode is disabled.
+	RequeueReasonReadOnlyMode RequeueReason = "ReadOnlyMode"
+
+	// RequeueReasonNamespaceMigrationInProgress: the Applications of a Namespace are being migrated to another
+	// GitOpsEngineInstance, and the migration is waiting (for example, for the Argo CD Applications to be healthy).
+	RequeueReasonNamespaceMigrationInProgress RequeueReason = "NamespaceMigrationInProgress"
+)
+
+// LogRequeueReason logs the reason for which a reconcile is requeued after 'requeueAfter' (or is waiting for a watched
+// resource to change, if 0), with the same keys in all controllers.
+func LogRequeueReason(log logr.Logger, reason RequeueReason, requeueAfter time.Duration, msg string, keysAndValues ...any) {
+	log.Info(msg, append([]any{LogRequeueReasonKey, string(reason), LogRequeueAfterKey, requeueAfter}, keysAndValues...)...)
+}
+
+// RequeueWithReason logs the reason for which a reconcile is requeued (see LogRequeueReason), and returns the result
+// unmodified, so that it may be returned directly from Reconcile.
+func RequeueWithReason(log logr.Logger, result reconcile.Result, reason RequeueReason, msg string, keysAndValues ...any) reconcile.Result {
+	LogRequeueReason(log, reason, result.RequeueAfter, msg, keysAndValues...)
+	return result
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Requeue reason tests", func() {
+
+	Context("Test RequeueWithReason", func() {
+
+		It("should log the requeue reason with consistent keys, and return the result unmodified", func() {
+			logLines := []string{}
+			log := funcr.New(func(prefix, args string) {
+				logLines = append(logLines, args)
+			}, funcr.Options{})
+
+			result := RequeueWithReason(log, reconcile.Result{RequeueAfter: 5 * time.Second}, RequeueReasonSecretNotFound,
+				"Waiting for Secret", "secret", "my-secret")
+
+			Expect(result).To(Equal(reconcile.Result{RequeueAfter: 5 * time.Second}))
+			Expect(logLines).To(HaveLen(1))
+			Expect(logLines[0]).To(ContainSubstring(`"msg"="Waiting for Secret"`))
+			Expect(logLines[0]).To(ContainSubstring(`"` + LogRequeueReasonKey + `"="SecretNotFound"`))
+			Expect(logLines[0]).To(ContainSubstring(`"` + LogRequeueAfterKey + `"="5s"`))
+			Expect(logLines[0]).To(ContainSubstring(`"secret"="my-secret"`))
+		})
+	})
+})
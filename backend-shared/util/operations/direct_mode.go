@@ -0,0 +1,329 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+)
+
+// Direct mode:
+//
+// For local development and demos, against a single cluster (for example, kind) on which Argo CD is installed, the
+// backend may be run in direct mode (with the '--direct-mode' flag), in which case the cluster-agent is not required to
+// deploy GitOpsDeployments:
+// - The backend passes the flag to CreateOperationWithDirectMode, which (for an Application row) creates the Operation
+//   row, and then applies the Argo CD Application of the Application row itself, from the same spec field that the
+//   cluster-agent would apply.
+// - The Operation row is then marked as Completed (or Failed, if the Argo CD Application could not be applied), so that
+//   anything waiting on the Operation row does not time out. No Operation CR is created, and so CleanupOperation only
+//   deletes the Operation row.
+//
+// Direct mode only applies to Application Operations that are created by the application event loop and the namespace
+// migrator: the Operations of other resources (for example, of GitOpsDeploymentSyncRuns, and the cluster secrets of
+// GitOpsDeploymentManagedEnvironments), and those created by the database reconciler, are still created, and are only
+// processed if the cluster-agent is running. Likewise, the status of GitOpsDeployments is reported by the
+// cluster-agent, so it is not updated in direct mode.
+
+const (
+	// directModeDeleteTimeout is how long to wait for Argo CD to delete an Argo CD Application (and its resources)
+	directModeDeleteTimeout = 2 * time.Minute
+)
+
+var argoCDApplicationGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}
+
+// isDirectModeOperation returns true if the Operation should be applied directly, rather than by the cluster-agent.
+func isDirectModeOperation(directMode bool, dbOperation db.Operation) bool {
+	return directMode && dbOperation.Resource_type == db.OperationResourceType_Application
+}
+
+// applyOperationDirectly creates the Operation row of an Application Operation, applies the Argo CD Application of the
+// Application row (retrying until expireTime), and then marks the Operation row as Completed, or Failed. The returned
+// Operation CR is not created.
+func applyOperationDirectly(ctx context.Context, dbOperationParam db.Operation, clusterUserID string, argoCDNamespace string,
+	dbQueries db.ApplicationScopedQueries, gitopsEngineClient client.Client, expireTime time.Time,
+	log logr.Logger) (*managedgitopsv1alpha1.Operation, *db.Operation, error) {
+
+	log = log.WithValues("applicationID", dbOperationParam.Resource_id, "argoCDNamespace", argoCDNamespace, "directMode", true)
+
+	if argoCDNamespace == "" {
+		return nil, nil, fmt.Errorf("Invalid Operation namespace")
+	}
+
+	dbOperation := db.Operation{
+		Instance_id:             dbOperationParam.Instance_id,
+		Resource_id:             dbOperationParam.Resource_id,
+		Resource_type:           dbOperationParam.Resource_type,
+		Operation_owner_user_id: clusterUserID,
+		Created_on:              time.Now(),
+		Last_state_update:       time.Now(),
+		State:                   db.OperationState_Waiting,
+		Human_readable_state:    "",
+	}
+
+	if err := dbQueries.CreateOperation(ctx, &dbOperation, clusterUserID); err != nil {
+		log.Error(err, "Unable to create Operation database row")
+		return nil, nil, err
+	}
+	log = log.WithValues("operationID", dbOperation.Operation_id)
+
+	backoff := sharedutil.ExponentialBackoff{Factor: 1.5, Min: time.Millisecond * 500, Max: time.Second * 5, Jitter: true}
+
+	var applyErr error
+	for {
+		if applyErr = applyArgoCDApplicationDirectly(ctx, dbOperationParam, argoCDNamespace, dbQueries, gitopsEngineClient, log); applyErr == nil {
+			break
+		}
+		log.Error(applyErr, "unable to apply Argo CD Application in direct mode")
+
+		if time.Now().After(expireTime) {
+			break
+		}
+
+		backoff.DelayOnFail(ctx)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	// Mark the Operation row as Completed/Failed, as the cluster-agent would on processing the Operation
+	dbOperation.Last_state_update = time.Now()
+	if applyErr == nil {
+		dbOperation.State = db.OperationState_Completed
+	} else {
+		dbOperation.State = db.OperationState_Failed
+		dbOperation.Human_readable_state = db.TruncateVarchar(applyErr.Error(), db.OperationHumanReadableStateLength)
+	}
+
+	if err := dbQueries.UpdateOperation(ctx, &dbOperation); err != nil {
+		log.Error(err, "unable to update state of Operation database row")
+		return nil, nil, err
+	}
+
+	if applyErr != nil {
+		return nil, nil, applyErr
+	}
+
+	operation := managedgitopsv1alpha1.Operation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GenerateOperationCRName(dbOperation),
+			Namespace: argoCDNamespace,
+		},
+		Spec: managedgitopsv1alpha1.OperationSpec{
+			OperationID: dbOperation.Operation_id,
+		},
+	}
+
+	return &operation, &dbOperation, nil
+}
+
+// applyArgoCDApplicationDirectly creates, updates or deletes the Argo CD Application of an Application row, so that it
+// matches the row, as the cluster-agent does on processing an Application Operation.
+func applyArgoCDApplicationDirectly(ctx context.Context, dbOperation db.Operation, argoCDNamespace string,
+	dbQueries db.ApplicationScopedQueries, k8sClient client.Client, log logr.Logger) error {
+
+	dbApplication := db.Application{Application_id: dbOperation.Resource_id}
+
+	if err := dbQueries.GetApplicationById(ctx, &dbApplication); err != nil {
+		if db.IsResultNotFoundError(err) {
+			// The Application row no longer exists, so delete its Argo CD Application (and resources)
+			return deleteArgoCDApplicationsDirectly(ctx, dbOperation.Resource_id, argoCDNamespace, false, k8sClient, log)
+		}
+		return fmt.Errorf("unable to retrieve Application '%s': %v", dbOperation.Resource_id, err)
+	}
+
+	if dbApplication.Engine_instance_inst_id != dbOperation.Instance_id {
+		// The Application was migrated to another GitOpsEngineInstance, whose Argo CD Application now manages the
+		// resources: so they are orphaned, rather than deleted.
+		return deleteArgoCDApplicationsDirectly(ctx, dbApplication.Application_id, argoCDNamespace, true, k8sClient, log)
+	}
+
+	if dbApplication.Managed_environment_id == "" {
+		// As with the cluster-agent, the Argo CD Application is not created until the row has a managed environment
+		return nil
+	}
+
+	desired, err := generateArgoCDApplicationFromSpecField(dbApplication, argoCDNamespace)
+	if err != nil {
+		// There's likely nothing that can be done to fix this, so no error is returned (which would be retried)
+		log.Error(err, "SEVERE: unable to generate Argo CD Application from application spec field")
+		return nil
+	}
+	logutil.SetCorrelationIDAnnotation(desired, logutil.CorrelationIDFromContext(ctx))
+
+	if destinationName, _, _ := unstructured.NestedString(desired.Object, "spec", "destination", "name"); destinationName != argosharedutil.ArgoCDDefaultDestinationInCluster {
+		log.Info("Warning: in direct mode, the cluster secret of the managed environment of the Application is only created if the cluster-agent is running",
+			"destinationName", destinationName)
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(argoCDApplicationGVK)
+
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+
+		if !apierr.IsNotFound(err) {
+			return fmt.Errorf("unable to retrieve Argo CD Application '%s': %v", desired.GetName(), err)
+		}
+
+		if err := k8sClient.Create(ctx, desired); err != nil {
+			return fmt.Errorf("unable to create Argo CD Application '%s': %v", desired.GetName(), err)
+		}
+		logutil.LogAPIResourceChangeEvent(desired.GetNamespace(), desired.GetName(), desired, logutil.ResourceCreated, log)
+
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+
+	if spec, exists := desired.Object["spec"]; exists {
+		updated.Object["spec"] = spec
+	} else {
+		delete(updated.Object, "spec")
+	}
+
+	labels := updated.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[argosharedutil.ArgoCDApplicationDatabaseIDLabel] = dbApplication.Application_id
+	updated.SetLabels(labels)
+
+	// Replace the annotations that are managed by the GitOps Service, leaving any others
+	annotations := map[string]string{}
+	for key, value := range updated.GetAnnotations() {
+		if !argosharedutil.IsManagedArgoCDApplicationAnnotation(key) {
+			annotations[key] = value
+		}
+	}
+	for key, value := range desired.GetAnnotations() {
+		annotations[key] = value
+	}
+	updated.SetAnnotations(annotations)
+
+	if reflect.DeepEqual(updated.Object, existing.Object) {
+		log.V(logutil.LogLevel_Debug).Info("no changes detected in Argo CD Application, so no update needed")
+		return nil
+	}
+
+	if err := k8sClient.Update(ctx, updated); err != nil {
+		return fmt.Errorf("unable to update Argo CD Application '%s': %v", updated.GetName(), err)
+	}
+	logutil.LogAPIResourceChangeEvent(updated.GetNamespace(), updated.GetName(), updated, logutil.ResourceModified, log)
+
+	return nil
+}
+
+// generateArgoCDApplicationFromSpecField returns the Argo CD Application of an Application row, from its spec field.
+func generateArgoCDApplicationFromSpecField(dbApplication db.Application, argoCDNamespace string) (*unstructured.Unstructured, error) {
+
+	res := &unstructured.Unstructured{}
+
+	if err := yaml.Unmarshal([]byte(dbApplication.Spec_field), &res.Object); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal spec field of Application '%s': %v", dbApplication.Application_id, err)
+	}
+	if res.Object == nil {
+		res.Object = map[string]interface{}{}
+	}
+
+	res.SetGroupVersionKind(argoCDApplicationGVK)
+	res.SetName(dbApplication.Name)
+	res.SetNamespace(argoCDNamespace)
+	res.SetLabels(map[string]string{argosharedutil.ArgoCDApplicationDatabaseIDLabel: dbApplication.Application_id})
+
+	return res, nil
+}
+
+// deleteArgoCDApplicationsDirectly deletes the Argo CD Applications of an Application row, and waits for Argo CD to
+// delete them. The resources of the Argo CD Applications are deleted as specified by their deletion policy annotation,
+// unless orphanResources is true.
+func deleteArgoCDApplicationsDirectly(ctx context.Context, applicationID string, argoCDNamespace string, orphanResources bool,
+	k8sClient client.Client, log logr.Logger) error {
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(argoCDApplicationGVK.GroupVersion().WithKind(argoCDApplicationGVK.Kind + "List"))
+
+	if err := k8sClient.List(ctx, list, client.InNamespace(argoCDNamespace),
+		client.MatchingLabels{argosharedutil.ArgoCDApplicationDatabaseIDLabel: applicationID}); err != nil {
+		return fmt.Errorf("unable to list Argo CD Applications of Application '%s': %v", applicationID, err)
+	}
+
+	for idx := range list.Items {
+		app := list.Items[idx]
+
+		if app.GetDeletionTimestamp() == nil {
+
+			// Ensure the resources finalizer of the deletion policy of the Argo CD Application is set (and no other)
+			resourcesFinalizer := ""
+			if !orphanResources {
+				resourcesFinalizer = argosharedutil.GetResourcesFinalizerOfDeletionPolicy(app.GetAnnotations())
+			}
+			finalizers := argosharedutil.SetResourcesFinalizer(app.GetFinalizers(), resourcesFinalizer)
+
+			if !reflect.DeepEqual(finalizers, app.GetFinalizers()) {
+				app.SetFinalizers(finalizers)
+				if err := k8sClient.Update(ctx, &app); err != nil {
+					return fmt.Errorf("unable to update finalizers of Argo CD Application '%s': %v", app.GetName(), err)
+				}
+			}
+
+			if err := k8sClient.Delete(ctx, &app); err != nil && !apierr.IsNotFound(err) {
+				return fmt.Errorf("unable to delete Argo CD Application '%s': %v", app.GetName(), err)
+			}
+			logutil.LogAPIResourceChangeEvent(app.GetNamespace(), app.GetName(), &app, logutil.ResourceDeleted, log)
+		}
+
+		if err := waitForArgoCDApplicationToBeDeleted(ctx, app, k8sClient); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitForArgoCDApplicationToBeDeleted waits for Argo CD to delete the resources of the Argo CD Application (if requested by
+// its finalizer), and thus for the Argo CD Application to be deleted.
+func waitForArgoCDApplicationToBeDeleted(ctx context.Context, app unstructured.Unstructured, k8sClient client.Client) error {
+
+	backoff := sharedutil.ExponentialBackoff{Factor: 2, Min: time.Millisecond * 200, Max: time.Second * 10, Jitter: true}
+
+	expireTime := time.Now().Add(directModeDeleteTimeout)
+
+	for {
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(argoCDApplicationGVK)
+
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&app), current); err != nil {
+			if apierr.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("unable to retrieve Argo CD Application '%s': %v", app.GetName(), err)
+		}
+
+		if time.Now().After(expireTime) {
+			return fmt.Errorf("Argo CD Application '%s' was not deleted within %v", app.GetName(), directModeDeleteTimeout)
+		}
+
+		backoff.DelayOnFail(ctx)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context is Done() while waiting for Argo CD Application '%s' to be deleted", app.GetName())
+		default:
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package operations
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Testing CreateOperation function in direct mode.", func() {
+	Context("Testing CreateOperation function in direct mode.", func() {
+
+		var ctx context.Context
+		var dbq db.AllDatabaseQueries
+		var k8sClient client.Client
+		var application db.Application
+		var gitopsEngineInstance *db.GitopsEngineInstance
+
+		// The resources are orphaned on deletion, as the fake client never removes the Argo CD resources finalizer
+		const specField = `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  annotations:
+    managed-gitops.redhat.com/deletion-policy: Orphan
+  name: my-application
+  namespace: gitops-service-argocd
+spec:
+  destination:
+    name: in-cluster
+    namespace: my-namespace
+  project: default
+  source:
+    path: environments/overlays/dev
+    repoURL: https://github.com/redhat-appstudio/managed-gitops
+    targetRevision: HEAD
+`
+
+		BeforeEach(func() {
+			scheme, argocdNamespace, kubesystemNamespace, workspace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			err = db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(workspace, argocdNamespace, kubesystemNamespace).
+				Build()
+
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			var managedEnvironment *db.ManagedEnvironment
+			_, managedEnvironment, _, gitopsEngineInstance, _, err = db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			application = db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "my-application",
+				Spec_field:              specField,
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbq.CreateApplication(ctx, &application)
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			dbq.CloseDatabase()
+		})
+
+		createOperation := func() (*managedgitopsv1alpha1.Operation, *db.Operation) {
+			dbOperationInput := db.Operation{
+				Instance_id:   application.Engine_instance_inst_id,
+				Resource_id:   application.Application_id,
+				Resource_type: db.OperationResourceType_Application,
+			}
+
+			k8sOperation, dbOperation, err := CreateOperationWithDirectMode(ctx, true, true, dbOperationInput, "test-user",
+				gitopsEngineInstance.Namespace_name, dbq, k8sClient, log.FromContext(ctx))
+			Expect(err).To(BeNil())
+			Expect(k8sOperation).NotTo(BeNil())
+			Expect(dbOperation).NotTo(BeNil())
+			Expect(dbOperation.Operation_id).NotTo(BeEmpty())
+
+			By("marking the Operation row as completed, so that waiters do not time out")
+			operationRow := db.Operation{Operation_id: dbOperation.Operation_id}
+			Expect(dbq.GetOperationById(ctx, &operationRow)).To(Succeed())
+			Expect(operationRow.State).To(Equal(db.OperationState_Completed))
+
+			operationList := &managedgitopsv1alpha1.OperationList{}
+			Expect(k8sClient.List(ctx, operationList)).To(Succeed())
+			Expect(operationList.Items).To(BeEmpty(), "no Operation CR should be created in direct mode")
+
+			err = CleanupOperation(ctx, *dbOperation, *k8sOperation, dbq, k8sClient, true, log.FromContext(ctx))
+			Expect(err).To(BeNil())
+
+			err = dbq.GetOperationById(ctx, &operationRow)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			return k8sOperation, dbOperation
+		}
+
+		getArgoCDApplication := func() (*unstructured.Unstructured, error) {
+			argoCDApplication := &unstructured.Unstructured{}
+			argoCDApplication.SetGroupVersionKind(argoCDApplicationGVK)
+			argoCDApplication.SetName(application.Name)
+			argoCDApplication.SetNamespace(gitopsEngineInstance.Namespace_name)
+
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(argoCDApplication), argoCDApplication)
+			return argoCDApplication, err
+		}
+
+		It("should create, update and delete the Argo CD Application of an Application row, without creating Operation CRs", func() {
+
+			By("creating the Argo CD Application, from the spec field of the Application row")
+			createOperation()
+
+			argoCDApplication, err := getArgoCDApplication()
+			Expect(err).To(BeNil())
+			Expect(argoCDApplication.GetLabels()[argosharedutil.ArgoCDApplicationDatabaseIDLabel]).To(Equal(application.Application_id))
+
+			repoURL, _, _ := unstructured.NestedString(argoCDApplication.Object, "spec", "source", "repoURL")
+			Expect(repoURL).To(Equal("https://github.com/redhat-appstudio/managed-gitops"))
+
+			By("updating the Argo CD Application, when the spec field of the Application row changes")
+			application.Spec_field = specField + "  syncPolicy:\n    automated:\n      prune: true\n      selfHeal: true\n"
+			Expect(dbq.UpdateApplication(ctx, &application)).To(Succeed())
+
+			createOperation()
+
+			argoCDApplication, err = getArgoCDApplication()
+			Expect(err).To(BeNil())
+			prune, _, _ := unstructured.NestedBool(argoCDApplication.Object, "spec", "syncPolicy", "automated", "prune")
+			Expect(prune).To(BeTrue())
+
+			By("deleting the Argo CD Application, when the Application row is deleted")
+			_, err = dbq.DeleteApplicationById(ctx, application.Application_id)
+			Expect(err).To(BeNil())
+
+			createOperation()
+
+			_, err = getArgoCDApplication()
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should not apply the Argo CD Application directly when direct mode is disabled", func() {
+			dbOperationInput := db.Operation{
+				Instance_id:   application.Engine_instance_inst_id,
+				Resource_id:   application.Application_id,
+				Resource_type: db.OperationResourceType_Application,
+			}
+
+			k8sOperation, dbOperation, err := CreateOperation(ctx, false, dbOperationInput, "test-user",
+				gitopsEngineInstance.Namespace_name, dbq, k8sClient, log.FromContext(ctx))
+			Expect(err).To(BeNil())
+			Expect(dbOperation.Operation_id).NotTo(BeEmpty())
+
+			_, err = getArgoCDApplication()
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			Expect(CleanupOperation(ctx, *dbOperation, *k8sOperation, dbq, k8sClient, true, log.FromContext(ctx))).To(Succeed())
+		})
+	})
+})
@@ -34,6 +34,17 @@ func CreateOperation(ctx context.Context, waitForOperation bool, dbOperationPara
 	operationNamespace string, dbQueries db.ApplicationScopedQueries, gitopsEngineClient client.Client,
 	l logr.Logger) (*managedgitopsv1alpha1.Operation, *db.Operation, error) {
 
+	return CreateOperationWithDirectMode(ctx, false, waitForOperation, dbOperationParam, clusterUserID, operationNamespace,
+		dbQueries, gitopsEngineClient, l)
+}
+
+// CreateOperationWithDirectMode is CreateOperation, except that if directMode is true, the Argo CD Application of an
+// Application Operation is applied by the caller, rather than by the cluster-agent: see the description of direct mode,
+// in direct_mode.go.
+func CreateOperationWithDirectMode(ctx context.Context, directMode bool, waitForOperation bool, dbOperationParam db.Operation,
+	clusterUserID string, operationNamespace string, dbQueries db.ApplicationScopedQueries, gitopsEngineClient client.Client,
+	l logr.Logger) (*managedgitopsv1alpha1.Operation, *db.Operation, error) {
+
 	backoff := sharedutil.ExponentialBackoff{Factor: 1.5, Min: time.Millisecond * 500, Max: time.Second * 5, Jitter: true}
 
 	var (
@@ -44,6 +55,12 @@ func CreateOperation(ctx context.Context, waitForOperation bool, dbOperationPara
 
 	// Try for up 1 minute
 	expireTime := time.Now().Add(1 * time.Minute)
+
+	if isDirectModeOperation(directMode, dbOperationParam) {
+		// In direct mode, the Argo CD Application is applied by the backend, rather than by the cluster-agent
+		return applyOperationDirectly(ctx, dbOperationParam, clusterUserID, operationNamespace, dbQueries, gitopsEngineClient, expireTime, l)
+	}
+
 outer_for:
 	for {
 
@@ -159,11 +176,19 @@ func createOperationInternal(ctx context.Context, waitForOperation bool, dbOpera
 	}
 	l.Info("Created Operation database row", "Operation DB ID", dbOperation.Operation_id)
 
+	// The correlation ID of the Operation is derived from the correlation ID of the work that created it (if any), and
+	// is passed to the cluster-agent via an annotation, so that the logs of both components can be correlated.
+	correlationID := logutil.GenerateOperationCorrelationID(logutil.CorrelationIDFromContext(ctx), dbOperation.Operation_id)
+	l = l.WithValues(logutil.LogCorrelationIDKey, correlationID)
+
 	// Create K8s operation
 	operation := managedgitopsv1alpha1.Operation{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      GenerateOperationCRName(dbOperation),
 			Namespace: operationNamespace,
+			Annotations: map[string]string{
+				logutil.CorrelationIDAnnotation: correlationID,
+			},
 		},
 		Spec: managedgitopsv1alpha1.OperationSpec{
 			OperationID: dbOperation.Operation_id,
@@ -172,7 +197,7 @@ func createOperationInternal(ctx context.Context, waitForOperation bool, dbOpera
 
 	// Set annotation as an identifier for Operations created by Namespace Reconciler.
 	if clusterUserID == db.SpecialClusterUserName {
-		operation.Annotations = map[string]string{IdentifierKey: IdentifierValue}
+		operation.Annotations[IdentifierKey] = IdentifierValue
 	}
 
 	if err := gitopsEngineClient.Create(ctx, &operation, &client.CreateOptions{}); err != nil {
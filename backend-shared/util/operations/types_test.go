@@ -9,6 +9,7 @@ import (
 	operation "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,7 +45,7 @@ var _ = Describe("Testing CreateOperation function.", func() {
 			err = db.SetupForTestingDBGinkgo()
 			Expect(err).To(BeNil())
 
-			ctx = context.Background()
+			ctx = logutil.ContextWithCorrelationID(context.Background(), "my-correlation-id")
 			log := log.FromContext(ctx)
 
 			k8sClientOuter := fake.NewClientBuilder().
@@ -84,6 +85,8 @@ var _ = Describe("Testing CreateOperation function.", func() {
 			Expect(err).To(BeNil())
 			Expect(k8sOperationFirst).NotTo(BeNil())
 			Expect(dbOperationFirst).NotTo(BeNil())
+			Expect(k8sOperationFirst.Annotations[logutil.CorrelationIDAnnotation]).To(Equal("my-correlation-id/"+dbOperationFirst.Operation_id),
+				"the Operation CR should include the correlation ID of the context, followed by the Operation ID")
 
 			// Try to recreate same Operation it should return existing one.
 			k8sOperationSecond, dbOperationSecond, err = CreateOperation(ctx, false, dbOperationInput, "test-user", gitopsEngineInstance.Namespace_name, dbq, k8sClient, log)
@@ -23,14 +23,18 @@ func IsProfilingEnabled() bool {
 	return strings.ToLower(val) == "true"
 }
 
-// StartProfilers starts a pprof profiling server at the given address.
+// StartProfilers starts a pprof profiling server at the given address. The server also serves the reconcile timings
+// (see reconcile_timing.go) at '/debug/reconciles', and so reconcile timing is enabled.
 func StartProfilers(addr string) {
+	EnableReconcileTiming()
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
 	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/reconciles", ReconcileTimingHandler)
 
 	// #nosec G114
 	log.Fatal(http.ListenAndServe(addr, mux))
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Read-only mode:
+//
+// During a maintenance window of the database, the GitOps Service may be placed in read-only mode, by creating a
+// ConfigMap named ReadOnlyModeConfigMapName in the namespace of the GitOps Service, with 'enabled: "true"':
+//
+//	apiVersion: v1
+//	kind: ConfigMap
+//	metadata:
+//	  name: gitops-service-read-only-mode
+//	  namespace: gitops
+//	data:
+//	  enabled: "true"
+//
+// In read-only mode, the controllers of the backend and appstudio-controller do not create or update database rows, or
+// the resources they generate (Argo CD Applications, GitOpsDeployments, etc). Instead, the resources they reconcile are
+// given a ServiceDegraded condition, and are requeued until read-only mode is disabled. The status of existing
+// GitOpsDeployments continues to be updated.
+
+const (
+	// ReadOnlyModeConfigMapName is the name of the ConfigMap that enables read-only mode.
+	ReadOnlyModeConfigMapName = "gitops-service-read-only-mode"
+
+	// ReadOnlyModeConfigMapEnabledKey is the key of the ConfigMap that enables read-only mode, if set to "true".
+	ReadOnlyModeConfigMapEnabledKey = "enabled"
+
+	// ReadOnlyModeNamespaceEnvVar may be set to the namespace of the read-only mode ConfigMap, if the GitOps Service is
+	// not installed in DefaultReadOnlyModeNamespace.
+	ReadOnlyModeNamespaceEnvVar = "READ_ONLY_MODE_NAMESPACE"
+
+	DefaultReadOnlyModeNamespace = "gitops"
+
+	// ReadOnlyModeRequeueInterval is how often a resource is reconciled again while read-only mode is enabled.
+	ReadOnlyModeRequeueInterval = 30 * time.Second
+
+	// ConditionTypeServiceDegraded is set to True on the resources that are not reconciled, while in read-only mode.
+	ConditionTypeServiceDegraded = "ServiceDegraded"
+
+	// ConditionReasonReadOnlyMode is the reason of the ServiceDegraded condition, while in read-only mode.
+	ConditionReasonReadOnlyMode = "ReadOnlyMode"
+
+	// ConditionReasonReadOnlyModeDisabled is the reason of the ServiceDegraded condition, once read-only mode is disabled.
+	ConditionReasonReadOnlyModeDisabled = "ReadOnlyModeDisabled"
+
+	// ReadOnlyModeConditionMessage is the message of the ServiceDegraded condition, while in read-only mode.
+	ReadOnlyModeConditionMessage = "The GitOps Service is in read-only mode for maintenance: changes to this resource will be processed once maintenance is complete"
+)
+
+// GetReadOnlyModeNamespace returns the namespace of the read-only mode ConfigMap.
+func GetReadOnlyModeNamespace() string {
+	if namespace := strings.TrimSpace(os.Getenv(ReadOnlyModeNamespaceEnvVar)); namespace != "" {
+		return namespace
+	}
+	return DefaultReadOnlyModeNamespace
+}
+
+// IsReadOnlyModeEnabled returns true if the GitOps Service is in read-only mode: the read-only mode ConfigMap exists,
+// and is enabled.
+func IsReadOnlyModeEnabled(ctx context.Context, k8sClient client.Client) (bool, error) {
+
+	configMap := corev1.ConfigMap{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: GetReadOnlyModeNamespace(), Name: ReadOnlyModeConfigMapName}, &configMap); err != nil {
+		if apierr.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to retrieve read-only mode ConfigMap: %v", err)
+	}
+
+	return strings.EqualFold(configMap.Data[ReadOnlyModeConfigMapEnabledKey], "true"), nil
+}
+
+// SetServiceDegradedCondition sets the ServiceDegraded condition to True if read-only mode is enabled. If read-only mode
+// is disabled, an existing ServiceDegraded condition is set to False (resources that never had the condition are not
+// given it).
+//
+// Returns true if the conditions were modified.
+func SetServiceDegradedCondition(conditions *[]metav1.Condition, readOnlyModeEnabled bool) bool {
+
+	existing := meta.FindStatusCondition(*conditions, ConditionTypeServiceDegraded)
+
+	var desired metav1.Condition
+	if readOnlyModeEnabled {
+		desired = metav1.Condition{
+			Type:    ConditionTypeServiceDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  ConditionReasonReadOnlyMode,
+			Message: ReadOnlyModeConditionMessage,
+		}
+	} else {
+		if existing == nil || existing.Status == metav1.ConditionFalse {
+			return false
+		}
+		desired = metav1.Condition{
+			Type:   ConditionTypeServiceDegraded,
+			Status: metav1.ConditionFalse,
+			Reason: ConditionReasonReadOnlyModeDisabled,
+		}
+	}
+
+	if existing != nil && existing.Status == desired.Status && existing.Reason == desired.Reason && existing.Message == desired.Message {
+		return false
+	}
+
+	meta.SetStatusCondition(conditions, desired)
+	return true
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Read-only mode tests", func() {
+
+	Context("Test IsReadOnlyModeEnabled", func() {
+
+		var scheme *runtime.Scheme
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		})
+
+		It("should return false if the ConfigMap does not exist", func() {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+			readOnlyModeEnabled, err := IsReadOnlyModeEnabled(context.Background(), k8sClient)
+			Expect(err).To(BeNil())
+			Expect(readOnlyModeEnabled).To(BeFalse())
+		})
+
+		It("should return whether the ConfigMap enables read-only mode", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: ReadOnlyModeConfigMapName, Namespace: GetReadOnlyModeNamespace()},
+				Data:       map[string]string{ReadOnlyModeConfigMapEnabledKey: "true"},
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+			readOnlyModeEnabled, err := IsReadOnlyModeEnabled(context.Background(), k8sClient)
+			Expect(err).To(BeNil())
+			Expect(readOnlyModeEnabled).To(BeTrue())
+
+			configMap.Data[ReadOnlyModeConfigMapEnabledKey] = "false"
+			Expect(k8sClient.Update(context.Background(), configMap)).To(Succeed())
+
+			readOnlyModeEnabled, err = IsReadOnlyModeEnabled(context.Background(), k8sClient)
+			Expect(err).To(BeNil())
+			Expect(readOnlyModeEnabled).To(BeFalse())
+		})
+	})
+
+	Context("Test SetServiceDegradedCondition", func() {
+
+		It("should not add the condition to a resource that never had it, while read-only mode is disabled", func() {
+			conditions := []metav1.Condition{}
+			Expect(SetServiceDegradedCondition(&conditions, false)).To(BeFalse())
+			Expect(conditions).To(BeEmpty())
+		})
+
+		It("should set the condition to True in read-only mode, and to False once disabled", func() {
+			conditions := []metav1.Condition{}
+
+			Expect(SetServiceDegradedCondition(&conditions, true)).To(BeTrue())
+			condition := meta.FindStatusCondition(conditions, ConditionTypeServiceDegraded)
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(ConditionReasonReadOnlyMode))
+
+			By("not modifying the condition when it is already set")
+			Expect(SetServiceDegradedCondition(&conditions, true)).To(BeFalse())
+
+			Expect(SetServiceDegradedCondition(&conditions, false)).To(BeTrue())
+			condition = meta.FindStatusCondition(conditions, ConditionTypeServiceDegraded)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(ConditionReasonReadOnlyModeDisabled))
+
+			Expect(SetServiceDegradedCondition(&conditions, false)).To(BeFalse())
+		})
+	})
+})
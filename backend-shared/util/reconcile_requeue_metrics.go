@@ -0,0 +1,128 @@
+package util
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Controller requeue metrics allow SRE dashboards to show which resource types are backlogged:
+//
+// - Controllers are given an explicit name (via 'Named()' of the controller builder), so that controller-runtime's
+//   workqueue metrics (for example, 'workqueue_depth' and 'workqueue_retries_total') are labeled with a stable 'name'.
+// - Reconcilers are wrapped with NewRequeueMetricsMiddleware, which records the number of times each reconcile was
+//   requeued, and the number of consecutive times each object has been retried (without an annotation on the object).
+//
+// As with the workqueue rate limiter, a reconcile is 'retried' if it returned an error, or asked to be requeued without
+// a delay. Reconciles that asked to be requeued after a delay (for example, for a periodic check) are counted in
+// ControllerRequeues, but are not retries.
+
+// The names of the controllers whose workqueue and requeue metrics are reported.
+const (
+	ControllerNameEnvironment                = "environment"
+	ControllerNameDeploymentTargetClaim      = "deploymenttargetclaim"
+	ControllerNameDeploymentTarget           = "deploymenttarget"
+	ControllerNameSnapshotEnvironmentBinding = "snapshotenvironmentbinding"
+	ControllerNameGitOpsDeployment           = "gitopsdeployment"
+	ControllerNameGitOpsDeploymentBundle     = "gitopsdeploymentbundle"
+)
+
+// The values of the 'reason' label of ControllerRequeues
+const (
+	requeueReasonError        = "error"
+	requeueReasonRequeue      = "requeue"
+	requeueReasonRequeueAfter = "requeue_after"
+)
+
+var (
+	ControllerRequeues = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gitops_controller_requeues_total",
+			Help: "Number of reconciles that were requeued, by controller and reason (error, requeue or requeue_after)",
+		},
+		[]string{"controller", "reason"},
+	)
+
+	ControllerRetryingObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gitops_controller_retrying_objects",
+			Help: "Number of objects whose most recent reconcile is being retried, by controller",
+		},
+		[]string{"controller"},
+	)
+
+	ControllerObjectTimesRequeued = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gitops_controller_object_times_requeued",
+			Help: "Number of consecutive times the reconcile of an object has been retried. Objects are only reported while they are being retried.",
+		},
+		[]string{"controller", "namespace", "name"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(ControllerRequeues, ControllerRetryingObjects, ControllerObjectTimesRequeued)
+}
+
+// NewRequeueMetricsMiddleware wraps a reconciler, so that the requeues of its reconciles are recorded under the given
+// controller name (see ControllerName* constants).
+func NewRequeueMetricsMiddleware(controllerName string, inner reconcile.Reconciler) reconcile.Reconciler {
+	return &requeueMetricsMiddleware{
+		controllerName: controllerName,
+		inner:          inner,
+		timesRequeued:  map[types.NamespacedName]int{},
+	}
+}
+
+type requeueMetricsMiddleware struct {
+	controllerName string
+	inner          reconcile.Reconciler
+
+	// mutex protects timesRequeued, as a controller may run multiple reconciles concurrently
+	mutex sync.Mutex
+
+	// timesRequeued is the number of consecutive times the reconcile of each object has been retried. Objects are
+	// removed once they are reconciled without a retry.
+	timesRequeued map[types.NamespacedName]int
+}
+
+func (m *requeueMetricsMiddleware) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+
+	res, err := m.inner.Reconcile(ctx, req)
+
+	retried := false
+
+	if err != nil {
+		ControllerRequeues.WithLabelValues(m.controllerName, requeueReasonError).Inc()
+		retried = true
+
+	} else if res.RequeueAfter > 0 {
+		ControllerRequeues.WithLabelValues(m.controllerName, requeueReasonRequeueAfter).Inc()
+
+	} else if res.Requeue {
+		ControllerRequeues.WithLabelValues(m.controllerName, requeueReasonRequeue).Inc()
+		retried = true
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if retried {
+		m.timesRequeued[req.NamespacedName]++
+		ControllerObjectTimesRequeued.WithLabelValues(m.controllerName, req.Namespace, req.Name).
+			Set(float64(m.timesRequeued[req.NamespacedName]))
+
+	} else if _, exists := m.timesRequeued[req.NamespacedName]; exists {
+		// Only report objects while they are being retried, to bound the number of time series
+		delete(m.timesRequeued, req.NamespacedName)
+		ControllerObjectTimesRequeued.DeleteLabelValues(m.controllerName, req.Namespace, req.Name)
+	}
+
+	ControllerRetryingObjects.WithLabelValues(m.controllerName).Set(float64(len(m.timesRequeued)))
+
+	return res, err
+}
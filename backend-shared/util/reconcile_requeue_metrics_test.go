@@ -0,0 +1,68 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Reconcile Requeue Metrics Unit Tests", func() {
+
+	Context("Requeue metrics middleware", func() {
+
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "my-namespace", Name: "my-object"}}
+
+		It("should record the requeues of each reconcile, and the number of times each object has been retried", func() {
+
+			var result reconcile.Result
+			var resultErr error
+
+			middleware := NewRequeueMetricsMiddleware("test-requeues", reconcileFunc(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				return result, resultErr
+			}))
+
+			By("returning an error, and then asking to be requeued, and verifying both are retries of the object")
+			resultErr = errors.New("some error")
+			_, err := middleware.Reconcile(context.Background(), request)
+			Expect(err).To(Equal(resultErr))
+
+			resultErr = nil
+			result = reconcile.Result{Requeue: true}
+			_, err = middleware.Reconcile(context.Background(), request)
+			Expect(err).To(BeNil())
+
+			Expect(testutil.ToFloat64(ControllerRequeues.WithLabelValues("test-requeues", requeueReasonError))).To(Equal(float64(1)))
+			Expect(testutil.ToFloat64(ControllerRequeues.WithLabelValues("test-requeues", requeueReasonRequeue))).To(Equal(float64(1)))
+			Expect(testutil.ToFloat64(ControllerObjectTimesRequeued.WithLabelValues("test-requeues", "my-namespace", "my-object"))).To(Equal(float64(2)))
+			Expect(testutil.ToFloat64(ControllerRetryingObjects.WithLabelValues("test-requeues"))).To(Equal(float64(1)))
+
+			By("asking to be requeued after a delay, and verifying the object is no longer reported as being retried")
+			result = reconcile.Result{RequeueAfter: time.Minute}
+			_, err = middleware.Reconcile(context.Background(), request)
+			Expect(err).To(BeNil())
+
+			Expect(testutil.ToFloat64(ControllerRequeues.WithLabelValues("test-requeues", requeueReasonRequeueAfter))).To(Equal(float64(1)))
+			Expect(testutil.ToFloat64(ControllerRetryingObjects.WithLabelValues("test-requeues"))).To(Equal(float64(0)))
+			Expect(testutil.CollectAndCount(ControllerObjectTimesRequeued)).To(Equal(0))
+		})
+
+		It("should not record a requeue for a successful reconcile", func() {
+
+			middleware := NewRequeueMetricsMiddleware("test-success", reconcileFunc(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			}))
+
+			_, err := middleware.Reconcile(context.Background(), request)
+			Expect(err).To(BeNil())
+
+			Expect(testutil.ToFloat64(ControllerRequeues.WithLabelValues("test-success", requeueReasonError))).To(Equal(float64(0)))
+			Expect(testutil.ToFloat64(ControllerRetryingObjects.WithLabelValues("test-success"))).To(Equal(float64(0)))
+		})
+	})
+})
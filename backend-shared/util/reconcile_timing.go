@@ -0,0 +1,424 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reconcile timing records, when profiling is enabled (see profiling.go), how long each reconcile of each reconciler took,
+// broken down by how long was spent in K8s client calls (Get/List/Create/Update/Patch/Delete) and in external calls
+// (for example, handing off an event to an event loop). This may be used to diagnose slow reconciles in production.
+//
+// - Reconcilers are wrapped with NewReconcileTimingMiddleware, which records the total duration of each reconcile.
+// - The K8s client of the manager is wrapped (see ReconcileTimingNewClientFunc), and records the duration of each client
+//   call made with the context of a reconcile.
+// - Other calls may be recorded with TimeReconcileCall.
+//
+// The totals of each reconciler, and the reconciles that were slower than a threshold (in a ring buffer, so that only
+// the most recent are kept), are served as JSON at '/debug/reconciles' by the profiling server.
+
+// The types of call that are recorded for a reconcile.
+const (
+	ReconcileCallGet      = "Get"
+	ReconcileCallList     = "List"
+	ReconcileCallCreate   = "Create"
+	ReconcileCallUpdate   = "Update"
+	ReconcileCallPatch    = "Patch"
+	ReconcileCallDelete   = "Delete"
+	ReconcileCallExternal = "External"
+)
+
+const (
+	// reconcileTimingSlowThresholdEnv may be set to the duration (in milliseconds) above which a reconcile is recorded
+	// as slow. Defaults to defaultReconcileTimingSlowThreshold.
+	reconcileTimingSlowThresholdEnv = "RECONCILE_TIMING_SLOW_THRESHOLD_MS"
+
+	defaultReconcileTimingSlowThreshold = 1 * time.Second
+
+	// slowReconcilesCapacity is the number of slow reconciles that are kept: once full, the oldest is replaced.
+	slowReconcilesCapacity = 100
+)
+
+// ReconcileCallTiming is the number of calls of a given type, and the total time spent in those calls.
+type ReconcileCallTiming struct {
+	Count      int     `json:"count"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// ReconcileTiming is the timing breakdown of a single reconcile.
+type ReconcileTiming struct {
+	Reconciler string                          `json:"reconciler"`
+	Request    string                          `json:"request"`
+	Started    time.Time                       `json:"started"`
+	DurationMs float64                         `json:"durationMs"`
+	Error      string                          `json:"error,omitempty"`
+	Calls      map[string]*ReconcileCallTiming `json:"calls"`
+}
+
+// reconcileInProgress is the timing of a reconcile that is in progress: calls may be recorded concurrently, so
+// the timing is guarded by a mutex.
+type reconcileInProgress struct {
+	mutex  sync.Mutex
+	timing ReconcileTiming
+}
+
+// ReconcilerTimingTotals is the total timing breakdown of all the reconciles of a reconciler.
+type ReconcilerTimingTotals struct {
+	Reconciler    string                          `json:"reconciler"`
+	Reconciles    int                             `json:"reconciles"`
+	DurationMs    float64                         `json:"durationMs"`
+	MaxDurationMs float64                         `json:"maxDurationMs"`
+	Calls         map[string]*ReconcileCallTiming `json:"calls"`
+}
+
+// ReconcileTimingReport is the JSON response served at '/debug/reconciles'.
+type ReconcileTimingReport struct {
+	SlowThresholdMs int64                    `json:"slowThresholdMs"`
+	Reconcilers     []ReconcilerTimingTotals `json:"reconcilers"`
+	// SlowReconciles are the most recent reconciles that were slower than the threshold, slowest first.
+	SlowReconciles []ReconcileTiming `json:"slowReconciles"`
+}
+
+type reconcileTimingContextKey struct{}
+
+// reconcileTimingRecorder records the totals of each reconciler, and a ring buffer of the most recent slow reconciles.
+type reconcileTimingRecorder struct {
+	mutex         sync.Mutex
+	slowThreshold time.Duration
+	totals        map[string]*ReconcilerTimingTotals
+	slow          []ReconcileTiming
+	// nextSlow is the index of slow that the next slow reconcile is written to, once slow is full
+	nextSlow int
+}
+
+var (
+	// reconcileTimingEnabled is non-zero if reconcile timing is enabled (an int32, for use with sync/atomic)
+	reconcileTimingEnabled int32
+
+	defaultReconcileTimingRecorder = newReconcileTimingRecorder(reconcileTimingSlowThresholdFromEnv())
+)
+
+func newReconcileTimingRecorder(slowThreshold time.Duration) *reconcileTimingRecorder {
+	return &reconcileTimingRecorder{
+		slowThreshold: slowThreshold,
+		totals:        map[string]*ReconcilerTimingTotals{},
+		slow:          []ReconcileTiming{},
+	}
+}
+
+func reconcileTimingSlowThresholdFromEnv() time.Duration {
+	if val, err := strconv.Atoi(os.Getenv(reconcileTimingSlowThresholdEnv)); err == nil && val >= 0 {
+		return time.Duration(val) * time.Millisecond
+	}
+	return defaultReconcileTimingSlowThreshold
+}
+
+// EnableReconcileTiming enables the recording of reconcile timings. It is called when the profiling server is started.
+func EnableReconcileTiming() {
+	atomic.StoreInt32(&reconcileTimingEnabled, 1)
+}
+
+func isReconcileTimingEnabled() bool {
+	return atomic.LoadInt32(&reconcileTimingEnabled) != 0
+}
+
+// NewReconcileTimingMiddleware wraps a reconciler, so that the duration of each reconcile (and of the calls made during
+// it) is recorded under the given reconciler name, when reconcile timing is enabled.
+func NewReconcileTimingMiddleware(reconcilerName string, inner reconcile.Reconciler) reconcile.Reconciler {
+	return &reconcileTimingMiddleware{
+		reconcilerName: reconcilerName,
+		recorder:       defaultReconcileTimingRecorder,
+		inner:          inner,
+	}
+}
+
+type reconcileTimingMiddleware struct {
+	reconcilerName string
+	recorder       *reconcileTimingRecorder
+	inner          reconcile.Reconciler
+}
+
+func (m *reconcileTimingMiddleware) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+
+	if !isReconcileTimingEnabled() {
+		return m.inner.Reconcile(ctx, req)
+	}
+
+	inProgress := &reconcileInProgress{
+		timing: ReconcileTiming{
+			Reconciler: m.reconcilerName,
+			Request:    req.String(),
+			Started:    time.Now(),
+			Calls:      map[string]*ReconcileCallTiming{},
+		},
+	}
+
+	res, err := m.inner.Reconcile(context.WithValue(ctx, reconcileTimingContextKey{}, inProgress), req)
+
+	duration := time.Since(inProgress.timing.Started)
+
+	inProgress.mutex.Lock()
+	timing := inProgress.timing
+	timing.Calls = copyCallTimings(inProgress.timing.Calls)
+	inProgress.mutex.Unlock()
+
+	timing.DurationMs = durationToMilliseconds(duration)
+	if err != nil {
+		timing.Error = err.Error()
+	}
+
+	m.recorder.record(timing, duration)
+
+	return res, err
+}
+
+// TimeReconcileCall starts timing a call of the given type, made during the reconcile of the context: the returned function
+// should be called once the call completes. If the context is not that of a reconcile that is being timed, nothing is recorded.
+//
+// For example: 'defer TimeReconcileCall(ctx, ReconcileCallExternal)()'
+func TimeReconcileCall(ctx context.Context, callType string) func() {
+
+	inProgress, _ := ctx.Value(reconcileTimingContextKey{}).(*reconcileInProgress)
+	if inProgress == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		duration := time.Since(start)
+
+		inProgress.mutex.Lock()
+		defer inProgress.mutex.Unlock()
+		addCallTiming(inProgress.timing.Calls, callType, 1, durationToMilliseconds(duration))
+	}
+}
+
+func addCallTiming(calls map[string]*ReconcileCallTiming, callType string, count int, durationMs float64) {
+	callTiming, exists := calls[callType]
+	if !exists {
+		callTiming = &ReconcileCallTiming{}
+		calls[callType] = callTiming
+	}
+	callTiming.Count += count
+	callTiming.DurationMs += durationMs
+}
+
+// record adds a completed reconcile to the totals of its reconciler, and to the slow reconciles if it was slower than the threshold.
+func (r *reconcileTimingRecorder) record(timing ReconcileTiming, duration time.Duration) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	totals, exists := r.totals[timing.Reconciler]
+	if !exists {
+		totals = &ReconcilerTimingTotals{
+			Reconciler: timing.Reconciler,
+			Calls:      map[string]*ReconcileCallTiming{},
+		}
+		r.totals[timing.Reconciler] = totals
+	}
+	totals.Reconciles++
+	totals.DurationMs += timing.DurationMs
+	if timing.DurationMs > totals.MaxDurationMs {
+		totals.MaxDurationMs = timing.DurationMs
+	}
+	for callType, callTiming := range timing.Calls {
+		addCallTiming(totals.Calls, callType, callTiming.Count, callTiming.DurationMs)
+	}
+
+	if duration < r.slowThreshold {
+		return
+	}
+
+	if len(r.slow) < slowReconcilesCapacity {
+		r.slow = append(r.slow, timing)
+		return
+	}
+	r.slow[r.nextSlow] = timing
+	r.nextSlow = (r.nextSlow + 1) % slowReconcilesCapacity
+}
+
+// report returns a copy of the recorded timings.
+func (r *reconcileTimingRecorder) report() ReconcileTimingReport {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	res := ReconcileTimingReport{
+		SlowThresholdMs: r.slowThreshold.Milliseconds(),
+		Reconcilers:     []ReconcilerTimingTotals{},
+		SlowReconciles:  []ReconcileTiming{},
+	}
+
+	for _, totals := range r.totals {
+		totalsCopy := *totals
+		totalsCopy.Calls = copyCallTimings(totals.Calls)
+		res.Reconcilers = append(res.Reconcilers, totalsCopy)
+	}
+	sort.Slice(res.Reconcilers, func(i, j int) bool {
+		return res.Reconcilers[i].Reconciler < res.Reconcilers[j].Reconciler
+	})
+
+	for _, timing := range r.slow {
+		timing.Calls = copyCallTimings(timing.Calls)
+		res.SlowReconciles = append(res.SlowReconciles, timing)
+	}
+	sort.SliceStable(res.SlowReconciles, func(i, j int) bool {
+		return res.SlowReconciles[i].DurationMs > res.SlowReconciles[j].DurationMs
+	})
+
+	return res
+}
+
+// durationToMilliseconds returns the duration in (fractional) milliseconds, as calls that are served from the informer
+// cache may take much less than a millisecond.
+func durationToMilliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
+
+func copyCallTimings(calls map[string]*ReconcileCallTiming) map[string]*ReconcileCallTiming {
+	res := make(map[string]*ReconcileCallTiming, len(calls))
+	for callType, callTiming := range calls {
+		callTimingCopy := *callTiming
+		res[callType] = &callTimingCopy
+	}
+	return res
+}
+
+// ReconcileTimingHandler serves the recorded reconcile timings as JSON.
+func ReconcileTimingHandler(w http.ResponseWriter, req *http.Request) {
+
+	body, err := json.MarshalIndent(defaultReconcileTimingRecorder.report(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// ReconcileTimingNewClientFunc returns a function that may be used as the NewClient option of a controller manager, so
+// that the calls made by reconcilers with the client of the manager are timed. If profiling is not enabled, nil is
+// returned, and so the default client is used.
+func ReconcileTimingNewClientFunc() cluster.NewClientFunc {
+
+	if !IsProfilingEnabled() {
+		return nil
+	}
+
+	return func(cache cache.Cache, config *rest.Config, options client.Options, uncachedObjects ...client.Object) (client.Client, error) {
+		innerClient, err := cluster.DefaultNewClient(cache, config, options, uncachedObjects...)
+		if err != nil {
+			return nil, err
+		}
+		return &ReconcileTimingClient{InnerClient: innerClient}, nil
+	}
+}
+
+var (
+	_ client.Client = &ReconcileTimingClient{}
+)
+
+// ReconcileTimingClient is a K8s client that records the duration of each call made with the context of a reconcile that
+// is being timed (see NewReconcileTimingMiddleware).
+type ReconcileTimingClient struct {
+	InnerClient client.Client
+}
+
+// Get retrieves an obj for the given object key from the Kubernetes Cluster.
+func (tc *ReconcileTimingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallGet)()
+
+	return tc.InnerClient.Get(ctx, key, obj, opts...)
+}
+
+// List retrieves list of objects for a given namespace and list options.
+func (tc *ReconcileTimingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallList)()
+
+	return tc.InnerClient.List(ctx, list, opts...)
+}
+
+// Create saves the object obj in the Kubernetes cluster.
+func (tc *ReconcileTimingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallCreate)()
+
+	return tc.InnerClient.Create(ctx, obj, opts...)
+}
+
+// Delete deletes the given obj from Kubernetes cluster.
+func (tc *ReconcileTimingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallDelete)()
+
+	return tc.InnerClient.Delete(ctx, obj, opts...)
+}
+
+// Update updates the given obj in the Kubernetes cluster.
+func (tc *ReconcileTimingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallUpdate)()
+
+	return tc.InnerClient.Update(ctx, obj, opts...)
+}
+
+// Patch patches the given obj in the Kubernetes cluster.
+func (tc *ReconcileTimingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallPatch)()
+
+	return tc.InnerClient.Patch(ctx, obj, patch, opts...)
+}
+
+// DeleteAllOf deletes all objects of the given type matching the given options.
+func (tc *ReconcileTimingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallDelete)()
+
+	return tc.InnerClient.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Status returns a client for the status subresource, whose calls are also timed.
+func (tc *ReconcileTimingClient) Status() client.StatusWriter {
+	return &reconcileTimingStatusWriter{innerWriter: tc.InnerClient.Status()}
+}
+
+// Scheme returns the scheme this client is using.
+func (tc *ReconcileTimingClient) Scheme() *runtime.Scheme {
+	return tc.InnerClient.Scheme()
+}
+
+// RESTMapper returns the rest this client is using.
+func (tc *ReconcileTimingClient) RESTMapper() meta.RESTMapper {
+	return tc.InnerClient.RESTMapper()
+}
+
+type reconcileTimingStatusWriter struct {
+	innerWriter client.StatusWriter
+}
+
+// Update updates the fields corresponding to the status subresource for the given obj.
+func (sw *reconcileTimingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallUpdate)()
+
+	return sw.innerWriter.Update(ctx, obj, opts...)
+}
+
+// Patch patches the given object's subresource.
+func (sw *reconcileTimingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	defer TimeReconcileCall(ctx, ReconcileCallPatch)()
+
+	return sw.innerWriter.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,174 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileFunc allows a function to be used as a reconcile.Reconciler
+type reconcileFunc func(ctx context.Context, req reconcile.Request) (reconcile.Result, error)
+
+func (f reconcileFunc) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	return f(ctx, req)
+}
+
+var _ = Describe("Reconcile Timing Unit Tests", func() {
+
+	Context("Reconcile timing middleware and client", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+		var recorder *reconcileTimingRecorder
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			EnableReconcileTiming()
+
+			recorder = newReconcileTimingRecorder(50 * time.Millisecond)
+
+			k8sClient = &ReconcileTimingClient{
+				InnerClient: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-config-map", Namespace: "my-namespace"},
+				}).Build(),
+			}
+		})
+
+		newMiddleware := func(reconcilerName string, inner reconcileFunc) reconcile.Reconciler {
+			return &reconcileTimingMiddleware{
+				reconcilerName: reconcilerName,
+				recorder:       recorder,
+				inner:          inner,
+			}
+		}
+
+		It("should record the calls made during each reconcile, in the totals of the reconciler", func() {
+
+			middleware := newMiddleware("ConfigMap", func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+
+				configMap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, req.NamespacedName, configMap); err != nil {
+					return reconcile.Result{}, err
+				}
+
+				configMap.Data = map[string]string{"key": "value"}
+				if err := k8sClient.Update(ctx, configMap); err != nil {
+					return reconcile.Result{}, err
+				}
+
+				configMapList := &corev1.ConfigMapList{}
+				if err := k8sClient.List(ctx, configMapList); err != nil {
+					return reconcile.Result{}, err
+				}
+
+				stopTiming := TimeReconcileCall(ctx, ReconcileCallExternal)
+				time.Sleep(5 * time.Millisecond)
+				stopTiming()
+
+				return reconcile.Result{}, nil
+			})
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "my-config-map", Namespace: "my-namespace"}}
+			for i := 0; i < 2; i++ {
+				_, err := middleware.Reconcile(ctx, req)
+				Expect(err).To(BeNil())
+			}
+
+			report := recorder.report()
+			Expect(report.Reconcilers).To(HaveLen(1))
+
+			totals := report.Reconcilers[0]
+			Expect(totals.Reconciler).To(Equal("ConfigMap"))
+			Expect(totals.Reconciles).To(Equal(2))
+			Expect(totals.Calls).To(HaveLen(4))
+			for _, callType := range []string{ReconcileCallGet, ReconcileCallUpdate, ReconcileCallList, ReconcileCallExternal} {
+				Expect(totals.Calls).To(HaveKey(callType))
+				Expect(totals.Calls[callType].Count).To(Equal(2))
+			}
+			Expect(totals.Calls[ReconcileCallExternal].DurationMs).To(BeNumerically(">=", 10))
+			Expect(totals.DurationMs).To(BeNumerically(">=", totals.Calls[ReconcileCallExternal].DurationMs))
+
+			By("verifying the reconciles were not recorded as slow, as they were faster than the threshold")
+			Expect(report.SlowReconciles).To(BeEmpty())
+		})
+
+		It("should only keep the most recent slow reconciles, and report them slowest first", func() {
+
+			// The first reconcile is the slowest, and so should be replaced once the ring buffer is full
+			reconciles := 0
+			middleware := newMiddleware("Slow", func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				reconciles++
+				if reconciles == 1 {
+					time.Sleep(100 * time.Millisecond)
+				} else {
+					time.Sleep(50 * time.Millisecond)
+				}
+				return reconcile.Result{}, fmt.Errorf("reconcile %d failed", reconciles)
+			})
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "my-config-map", Namespace: "my-namespace"}}
+			_, err := middleware.Reconcile(ctx, req)
+			Expect(err).ToNot(BeNil())
+
+			report := recorder.report()
+			Expect(report.SlowReconciles).To(HaveLen(1))
+			Expect(report.SlowReconciles[0].Request).To(Equal("my-namespace/my-config-map"))
+			Expect(report.SlowReconciles[0].Error).To(Equal("reconcile 1 failed"))
+
+			for i := 0; i < slowReconcilesCapacity; i++ {
+				_, err := middleware.Reconcile(ctx, req)
+				Expect(err).ToNot(BeNil())
+			}
+
+			report = recorder.report()
+			Expect(report.SlowReconciles).To(HaveLen(slowReconcilesCapacity))
+			for _, slowReconcile := range report.SlowReconciles {
+				Expect(slowReconcile.Error).ToNot(Equal("reconcile 1 failed"))
+			}
+			for i := 1; i < len(report.SlowReconciles); i++ {
+				Expect(report.SlowReconciles[i-1].DurationMs).To(BeNumerically(">=", report.SlowReconciles[i].DurationMs))
+			}
+		})
+
+		It("should serve the recorded timings as JSON", func() {
+
+			middleware := NewReconcileTimingMiddleware("ServedAsJSON", reconcileFunc(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, k8sClient.Get(ctx, req.NamespacedName, &corev1.ConfigMap{})
+			}))
+
+			_, err := middleware.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "my-config-map", Namespace: "my-namespace"}})
+			Expect(err).To(BeNil())
+
+			responseRecorder := httptest.NewRecorder()
+			ReconcileTimingHandler(responseRecorder, httptest.NewRequest("GET", "/debug/reconciles", nil))
+			Expect(responseRecorder.Code).To(Equal(200))
+			Expect(responseRecorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var report ReconcileTimingReport
+			Expect(json.Unmarshal(responseRecorder.Body.Bytes(), &report)).To(Succeed())
+
+			var totals *ReconcilerTimingTotals
+			for i := range report.Reconcilers {
+				if report.Reconcilers[i].Reconciler == "ServedAsJSON" {
+					totals = &report.Reconcilers[i]
+				}
+			}
+			Expect(totals).ToNot(BeNil())
+			Expect(totals.Reconciles).To(Equal(1))
+			Expect(totals.Calls[ReconcileCallGet].Count).To(Equal(1))
+		})
+	})
+})
@@ -22,6 +22,8 @@ const (
 	ArgoCDManagerServiceAccountPrefix         = "argocd-manager-"
 	ArgoCDManagerClusterRoleNamePrefix        = "argocd-manager-cluster-role-"
 	ArgoCDManagerClusterRoleBindingNamePrefix = "argocd-manager-cluster-role-binding-"
+	ArgoCDManagerRoleNamePrefix               = "argocd-manager-role-"
+	ArgoCDManagerRoleBindingNamePrefix        = "argocd-manager-role-binding-"
 )
 
 var (
@@ -92,6 +94,38 @@ func InstallServiceAccount(ctx context.Context, k8sClient client.Client, uuid st
 	return token, sa, nil
 }
 
+// InstallNamedServiceAccount creates the ServiceAccount with the given name and namespace (if it doesn't exist), grants it
+// access to the cluster, and returns a new bearer token for it. This is equivalent to the 'argocd cluster add' command:
+// - If 'namespaces' is empty, the ServiceAccount is bound to a ClusterRole with full access to the cluster.
+// - Otherwise, the ServiceAccount is bound to a Role with full access, in each of the namespaces.
+func InstallNamedServiceAccount(ctx context.Context, k8sClient client.Client, uuid string, serviceAccountName string, serviceAccountNS string,
+	namespaces []string, log logr.Logger) (string, *corev1.ServiceAccount, error) {
+
+	sa, err := getOrCreateServiceAccount(ctx, k8sClient, serviceAccountName, serviceAccountNS, log)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create or update service account: %v, error: %w", serviceAccountName, err)
+	}
+
+	if len(namespaces) == 0 {
+		if err := createOrUpdateClusterRoleAndRoleBinding(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS, log); err != nil {
+			return "", nil, fmt.Errorf("unable to create or update role and cluster role binding: %w", err)
+		}
+	} else {
+		for _, namespace := range namespaces {
+			if err := createOrUpdateRoleAndRoleBinding(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS, namespace, log); err != nil {
+				return "", nil, fmt.Errorf("unable to create or update role and role binding in namespace '%s': %w", namespace, err)
+			}
+		}
+	}
+
+	token, err := getOrCreateServiceAccountBearerToken(ctx, k8sClient, serviceAccountName, serviceAccountNS, log)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, sa, nil
+}
+
 // getOrCreateServiceAccountBearerToken returns a token if there is an existing token secret for a service account.
 // If the token secret is missing, it creates a new secret and attach it to the service account
 func getOrCreateServiceAccountBearerToken(ctx context.Context, k8sClient client.Client, serviceAccountName string,
@@ -256,6 +290,87 @@ func createOrUpdateClusterRoleAndRoleBinding(ctx context.Context, uuid string, k
 	return nil
 }
 
+// createOrUpdateRoleAndRoleBinding creates (or updates) a Role with full access to 'namespace', and binds it to the ServiceAccount.
+func createOrUpdateRoleAndRoleBinding(ctx context.Context, uuid string, k8sClient client.Client,
+	serviceAccountName string, serviceAccountNamespace string, namespace string, log logr.Logger) error {
+
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ArgoCDManagerRoleNamePrefix + uuid,
+			Namespace: namespace,
+		},
+	}
+	update := true
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(role), role); err != nil {
+		if !apierr.IsNotFound(err) {
+			return fmt.Errorf("unable to get role: %w", err)
+		}
+		update = false
+	}
+
+	role.Rules = ArgoCDManagerNamespacePolicyRules
+
+	roleLog := log.WithValues("name", role.Name, "namespace", role.Namespace)
+
+	if update {
+		if err := k8sClient.Update(ctx, role); err != nil {
+			roleLog.Error(err, "Unable to update Role")
+			return fmt.Errorf("unable to update role: %w", err)
+		}
+		logutil.LogAPIResourceChangeEvent(role.Namespace, role.Name, role, logutil.ResourceModified, roleLog)
+	} else {
+		if err := k8sClient.Create(ctx, role); err != nil {
+			roleLog.Error(err, "Unable to create Role")
+			return fmt.Errorf("unable to create role: %w", err)
+		}
+		logutil.LogAPIResourceChangeEvent(role.Namespace, role.Name, role, logutil.ResourceCreated, roleLog)
+	}
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ArgoCDManagerRoleBindingNamePrefix + uuid,
+			Namespace: namespace,
+		},
+	}
+	update = true
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(roleBinding), roleBinding); err != nil {
+		if !apierr.IsNotFound(err) {
+			return fmt.Errorf("unable to get role binding: %w", err)
+		}
+		update = false
+	}
+
+	roleBinding.RoleRef = rbacv1.RoleRef{
+		APIGroup: "rbac.authorization.k8s.io",
+		Kind:     "Role",
+		Name:     role.Name,
+	}
+
+	roleBinding.Subjects = []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      serviceAccountName,
+		Namespace: serviceAccountNamespace,
+	}}
+
+	roleBindingLog := log.WithValues("name", roleBinding.Name, "namespace", roleBinding.Namespace)
+
+	if update {
+		if err := k8sClient.Update(ctx, roleBinding); err != nil {
+			roleBindingLog.Error(err, "Unable to update RoleBinding")
+			return fmt.Errorf("unable to update role binding: %w", err)
+		}
+		logutil.LogAPIResourceChangeEvent(roleBinding.Namespace, roleBinding.Name, roleBinding, logutil.ResourceModified, roleBindingLog)
+	} else {
+		if err := k8sClient.Create(ctx, roleBinding); err != nil {
+			roleBindingLog.Error(err, "Unable to create RoleBinding")
+			return fmt.Errorf("unable to create role binding: %w", err)
+		}
+		logutil.LogAPIResourceChangeEvent(roleBinding.Namespace, roleBinding.Name, roleBinding, logutil.ResourceCreated, roleBindingLog)
+	}
+
+	return nil
+}
+
 func generateClientFromClusterServiceAccount(configParam *rest.Config, bearerToken string) (client.Client, error) {
 
 	newConfig := *configParam
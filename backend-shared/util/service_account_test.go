@@ -8,10 +8,12 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -178,4 +180,38 @@ var _ = Describe("Service Account Tests", func() {
 			})
 		})
 	})
+
+	Context("Namespace-scoped role and role binding test", func() {
+
+		ctx := context.Background()
+		log := log.FromContext(ctx)
+
+		It("should create a Role and RoleBinding for the ServiceAccount in the namespace, and update them if they already exist", func() {
+
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+			err := createOrUpdateRoleAndRoleBinding(ctx, "my-uuid", k8sClient, "my-deployer", "my-sa-namespace", "my-namespace", log)
+			Expect(err).To(BeNil())
+
+			role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerRoleNamePrefix + "my-uuid", Namespace: "my-namespace"}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(role), role)
+			Expect(err).To(BeNil())
+			Expect(role.Rules).To(Equal(ArgoCDManagerNamespacePolicyRules))
+
+			roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerRoleBindingNamePrefix + "my-uuid", Namespace: "my-namespace"}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(roleBinding), roleBinding)
+			Expect(err).To(BeNil())
+			Expect(roleBinding.RoleRef.Kind).To(Equal("Role"))
+			Expect(roleBinding.RoleRef.Name).To(Equal(role.Name))
+			Expect(roleBinding.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "my-deployer", Namespace: "my-sa-namespace"}}))
+
+			By("calling it again with a different ServiceAccount, which should update the RoleBinding")
+			err = createOrUpdateRoleAndRoleBinding(ctx, "my-uuid", k8sClient, "my-other-deployer", "my-sa-namespace", "my-namespace", log)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(roleBinding), roleBinding)
+			Expect(err).To(BeNil())
+			Expect(roleBinding.Subjects[0].Name).To(Equal("my-other-deployer"))
+		})
+	})
 })
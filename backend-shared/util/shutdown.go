@@ -0,0 +1,189 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Graceful shutdown:
+//
+// When a backend or cluster-agent pod is stopped (for example, during a rollout), the manager cancels the context of
+// its runnables. Rather than exiting with work half-done, each component drains its in-flight work before exiting:
+// - New work is no longer accepted: events received while draining are dropped. This is safe, as the controllers
+//   reconcile every resource when the next replica starts, which regenerates the events.
+// - The work that is in progress is given up to ShutdownDrainTimeout to complete.
+// - Work that has not completed by then is checkpointed (where supported), so that it is resumed by the next replica.
+//
+// The manager must be given a GracefulShutdownTimeout greater than the drain timeout, see ManagerGracefulShutdownTimeout.
+
+const (
+	// ShutdownDrainTimeoutEnvVar may be set to the number of seconds that in-flight work is given to complete, on shutdown.
+	ShutdownDrainTimeoutEnvVar = "SHUTDOWN_DRAIN_TIMEOUT"
+
+	// DefaultShutdownDrainTimeout is the drain timeout, if ShutdownDrainTimeoutEnvVar is not set.
+	DefaultShutdownDrainTimeout = 30 * time.Second
+
+	// shutdownCheckpointTimeout is the time given to checkpoint the work that was not drained, after the drain timeout.
+	shutdownCheckpointTimeout = 10 * time.Second
+)
+
+// ShutdownDrainTimeout returns the value of the ShutdownDrainTimeoutEnvVar env var, or DefaultShutdownDrainTimeout.
+func ShutdownDrainTimeout(logger logr.Logger) time.Duration {
+	drainTimeout := os.Getenv(ShutdownDrainTimeoutEnvVar)
+	if drainTimeout == "" {
+		return DefaultShutdownDrainTimeout
+	}
+	value, err := strconv.Atoi(drainTimeout)
+	if err != nil || value < 0 {
+		msg := fmt.Sprintf("value of env var %s must be a non-negative int", ShutdownDrainTimeoutEnvVar)
+		logger.Error(err, msg)
+		return DefaultShutdownDrainTimeout
+	}
+	return time.Duration(value) * time.Second
+}
+
+// ManagerGracefulShutdownTimeout returns the GracefulShutdownTimeout of the manager: the drain timeout, plus the time
+// to checkpoint the work that was not drained.
+func ManagerGracefulShutdownTimeout(logger logr.Logger) *time.Duration {
+	res := ShutdownDrainTimeout(logger) + shutdownCheckpointTimeout
+	return &res
+}
+
+// WorkTracker tracks the units of work that are in progress, so that they can be drained on shutdown.
+type WorkTracker struct {
+	mutex sync.Mutex
+
+	// inFlight is the number of units of work that have begun, but not yet ended
+	inFlight int
+
+	// draining is closed once Drain is called: no new work may then begin.
+	draining chan struct{}
+
+	// drained is closed once draining, and no work is in flight.
+	drained chan struct{}
+}
+
+func NewWorkTracker() *WorkTracker {
+	return &WorkTracker{
+		draining: make(chan struct{}),
+		drained:  make(chan struct{}),
+	}
+}
+
+// Begin should be called before a unit of work begins. Returns false if the tracker is draining, in which case the work
+// should not be started (and End should not be called).
+func (t *WorkTracker) Begin() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.isDrainingLocked() {
+		return false
+	}
+
+	t.inFlight++
+	return true
+}
+
+// End should be called once a unit of work, for which Begin returned true, has ended.
+func (t *WorkTracker) End() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.inFlight--
+
+	if t.inFlight == 0 && t.isDrainingLocked() {
+		close(t.drained)
+	}
+}
+
+// IsDraining returns true once Drain has been called.
+func (t *WorkTracker) IsDraining() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.isDrainingLocked()
+}
+
+func (t *WorkTracker) isDrainingLocked() bool {
+	select {
+	case <-t.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// Drain prevents new work from beginning, and waits for the work in flight to end. Returns the number of units of work
+// still in flight, and an error, if the context is cancelled before the work ended.
+func (t *WorkTracker) Drain(ctx context.Context) (int, error) {
+
+	t.mutex.Lock()
+	if !t.isDrainingLocked() {
+		close(t.draining)
+		if t.inFlight == 0 {
+			close(t.drained)
+		}
+	}
+	t.mutex.Unlock()
+
+	select {
+	case <-t.drained:
+		return 0, nil
+	case <-ctx.Done():
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		return t.inFlight, fmt.Errorf("work did not complete before the drain timeout: %w", ctx.Err())
+	}
+}
+
+// ShutdownDrainer is a manager runnable that drains the work of a component, once the manager is stopped.
+type ShutdownDrainer struct {
+	// Name of the component, for logging
+	Name string
+
+	// Drain should stop accepting new work, and wait for the work in progress to complete (until the context is cancelled).
+	Drain func(ctx context.Context) error
+
+	// Checkpoint (optional) is called after Drain, to checkpoint any work that did not complete, so that it may be resumed.
+	Checkpoint func(ctx context.Context) error
+
+	Log logr.Logger
+}
+
+// Start blocks until the manager is stopped, then drains the component.
+func (d *ShutdownDrainer) Start(ctx context.Context) error {
+
+	<-ctx.Done()
+
+	log := d.Log.WithValues("component", d.Name)
+
+	drainTimeout := ShutdownDrainTimeout(log)
+	log.Info("Draining in-flight work before shutdown", "drainTimeout", drainTimeout)
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	if err := d.Drain(drainCtx); err != nil {
+		log.Error(err, "unable to drain in-flight work before shutdown")
+	} else {
+		log.Info("Drained in-flight work before shutdown")
+	}
+
+	if d.Checkpoint != nil {
+		checkpointCtx, cancel := context.WithTimeout(context.Background(), shutdownCheckpointTimeout)
+		defer cancel()
+
+		if err := d.Checkpoint(checkpointCtx); err != nil {
+			log.Error(err, "unable to checkpoint in-flight work before shutdown")
+		}
+	}
+
+	// An error is not returned, as it would only be logged by the manager
+	return nil
+}
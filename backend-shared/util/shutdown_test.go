@@ -0,0 +1,77 @@
+package util
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Graceful shutdown tests", func() {
+
+	Context("Testing WorkTracker", func() {
+
+		It("should wait for the work in flight to end, and not allow new work to begin, once draining", func() {
+
+			tracker := NewWorkTracker()
+			Expect(tracker.Begin()).To(BeTrue())
+			Expect(tracker.Begin()).To(BeTrue())
+
+			drainResult := make(chan error, 1)
+			go func() {
+				_, err := tracker.Drain(context.Background())
+				drainResult <- err
+			}()
+
+			Eventually(tracker.IsDraining).Should(BeTrue())
+			Expect(tracker.Begin()).To(BeFalse())
+
+			tracker.End()
+			Consistently(drainResult, "200ms").ShouldNot(Receive())
+
+			tracker.End()
+			Eventually(drainResult).Should(Receive(BeNil()))
+		})
+
+		It("should return the work still in flight, if the context is cancelled before it ends", func() {
+
+			tracker := NewWorkTracker()
+			Expect(tracker.Begin()).To(BeTrue())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			inFlight, err := tracker.Drain(ctx)
+			Expect(err).ToNot(BeNil())
+			Expect(inFlight).To(Equal(1))
+		})
+
+		It("should drain immediately if no work is in flight", func() {
+
+			tracker := NewWorkTracker()
+			inFlight, err := tracker.Drain(context.Background())
+			Expect(err).To(BeNil())
+			Expect(inFlight).To(Equal(0))
+		})
+	})
+
+	Context("Testing ShutdownDrainTimeout", func() {
+
+		It("should return the value of the env var, or the default if it is not set or invalid", func() {
+
+			defer os.Unsetenv(ShutdownDrainTimeoutEnvVar)
+			log := logger.FromContext(context.Background())
+
+			Expect(ShutdownDrainTimeout(log)).To(Equal(DefaultShutdownDrainTimeout))
+
+			os.Setenv(ShutdownDrainTimeoutEnvVar, "90")
+			Expect(ShutdownDrainTimeout(log)).To(Equal(90 * time.Second))
+
+			os.Setenv(ShutdownDrainTimeoutEnvVar, "not-a-number")
+			Expect(ShutdownDrainTimeout(log)).To(Equal(DefaultShutdownDrainTimeout))
+		})
+	})
+})
@@ -0,0 +1,271 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The task queue runs keyed tasks on a bounded number of worker goroutines, for code that needs to run work in the
+// background outside of a controller (for example, periodic cleanup of the database).
+//
+// Tasks with the same key are de-duplicated:
+// - If a task is added while a task with the same key is waiting to run, the tasks are collapsed: only the most
+//   recently added task runs (in the position of the waiting task in the queue).
+// - If a task is added while a task with the same key is running, it is run once the running task completes: at most
+//   one task with a given key runs at a time, and the task that is added is not lost, as it may be acting on state
+//   that changed after the running task read it.
+//
+// Because of this de-duplication, tasks that are added to the task queue must be idempotent.
+//
+// Unlike the task retry loop (see task_retry_loop.go), a task that fails is not retried: the error is logged, and
+// reported in the metrics of the queue. A task that needs to run again should add itself back to the queue (for
+// example, with AddAfter).
+
+// TaskQueueFunc is a task that runs in a TaskQueue.
+type TaskQueueFunc func(ctx context.Context) error
+
+// TaskQueue is a queue of keyed tasks, which are run concurrently by a bounded number of workers.
+type TaskQueue struct {
+	// name is the name of the queue, reported in the logs and as the label of the metrics of the queue
+	name string
+
+	log logr.Logger
+
+	mutex sync.Mutex
+
+	// workAvailable is signalled when a task is added to waitingKeys
+	workAvailable *sync.Cond
+
+	// waitingKeys is the keys of the waiting tasks, in the order in which they are run
+	waitingKeys []string
+
+	// waitingTasks is a map of the key of a waiting task -> the task
+	waitingTasks map[string]TaskQueueFunc
+
+	// runningKeys is the set of keys of the tasks that are currently running
+	runningKeys map[string]bool
+
+	// pendingTasks is a map of the key of a running task -> a task with the same key that was added while it was running
+	pendingTasks map[string]TaskQueueFunc
+}
+
+const (
+	taskQueueLabel = "queue"
+)
+
+var (
+	TaskQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gitops_task_queue_depth",
+			Help: "Number of tasks that are waiting to run, by task queue",
+		},
+		[]string{taskQueueLabel},
+	)
+
+	TaskQueueActiveTasks = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gitops_task_queue_active_tasks",
+			Help: "Number of tasks that are running, by task queue",
+		},
+		[]string{taskQueueLabel},
+	)
+
+	TaskQueueTasksAdded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gitops_task_queue_tasks_added_total",
+			Help: "Number of tasks that were added, by task queue",
+		},
+		[]string{taskQueueLabel},
+	)
+
+	TaskQueueTasksDeduplicated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gitops_task_queue_tasks_deduplicated_total",
+			Help: "Number of tasks that were collapsed into another task with the same key, by task queue",
+		},
+		[]string{taskQueueLabel},
+	)
+
+	TaskQueueTaskFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gitops_task_queue_task_failures_total",
+			Help: "Number of tasks that returned an error or panicked, by task queue",
+		},
+		[]string{taskQueueLabel},
+	)
+
+	TaskQueueTaskDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gitops_task_queue_task_duration_seconds",
+			Help:    "Duration of the tasks, by task queue",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 900},
+		},
+		[]string{taskQueueLabel},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(TaskQueueDepth, TaskQueueActiveTasks, TaskQueueTasksAdded, TaskQueueTasksDeduplicated,
+		TaskQueueTaskFailures, TaskQueueTaskDuration)
+}
+
+// NewTaskQueue returns a TaskQueue with the given name, and starts the given number of workers to run its tasks.
+func NewTaskQueue(name string, workers int) *TaskQueue {
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	res := &TaskQueue{
+		name:         name,
+		log:          log.FromContext(context.Background()).WithName("task-queue").WithValues("task-queue-name", name),
+		waitingKeys:  []string{},
+		waitingTasks: map[string]TaskQueueFunc{},
+		runningKeys:  map[string]bool{},
+		pendingTasks: map[string]TaskQueueFunc{},
+	}
+	res.workAvailable = sync.NewCond(&res.mutex)
+
+	for i := 0; i < workers; i++ {
+		go res.runWorker()
+	}
+
+	return res
+}
+
+// Add queues the task with the given key, or collapses it into the waiting task with the same key.
+func (q *TaskQueue) Add(key string, task TaskQueueFunc) {
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	TaskQueueTasksAdded.WithLabelValues(q.name).Inc()
+
+	if _, exists := q.waitingTasks[key]; exists {
+		// Replace the waiting task, but keep its position in the queue
+		q.waitingTasks[key] = task
+		TaskQueueTasksDeduplicated.WithLabelValues(q.name).Inc()
+		q.log.V(logutil.LogLevel_Debug).Info("collapsed task into waiting task", "taskKey", key)
+		return
+	}
+
+	if q.runningKeys[key] {
+		if _, exists := q.pendingTasks[key]; exists {
+			TaskQueueTasksDeduplicated.WithLabelValues(q.name).Inc()
+		}
+		// The task is queued once the running task with the same key completes
+		q.pendingTasks[key] = task
+		return
+	}
+
+	q.enqueue(key, task)
+}
+
+// AddAfter adds the task with the given key to the queue, once the given delay has elapsed.
+func (q *TaskQueue) AddAfter(key string, delay time.Duration, task TaskQueueFunc) {
+
+	if delay <= 0 {
+		q.Add(key, task)
+		return
+	}
+
+	time.AfterFunc(delay, func() {
+		q.Add(key, task)
+	})
+}
+
+// Len returns the number of tasks that are waiting to run.
+func (q *TaskQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.waitingKeys)
+}
+
+// enqueue adds the task to the end of the queue: the caller must hold the mutex.
+func (q *TaskQueue) enqueue(key string, task TaskQueueFunc) {
+	q.waitingKeys = append(q.waitingKeys, key)
+	q.waitingTasks[key] = task
+	TaskQueueDepth.WithLabelValues(q.name).Set(float64(len(q.waitingKeys)))
+
+	q.workAvailable.Signal()
+}
+
+// runWorker runs waiting tasks, one at a time, forever.
+func (q *TaskQueue) runWorker() {
+	for {
+		key, task := q.next()
+
+		q.runTask(key, task)
+
+		q.complete(key)
+	}
+}
+
+// next blocks until a task is waiting, then removes it from the queue and marks its key as running.
+func (q *TaskQueue) next() (string, TaskQueueFunc) {
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for len(q.waitingKeys) == 0 {
+		q.workAvailable.Wait()
+	}
+
+	key := q.waitingKeys[0]
+	q.waitingKeys = q.waitingKeys[1:]
+
+	task := q.waitingTasks[key]
+	delete(q.waitingTasks, key)
+
+	q.runningKeys[key] = true
+
+	TaskQueueDepth.WithLabelValues(q.name).Set(float64(len(q.waitingKeys)))
+	TaskQueueActiveTasks.WithLabelValues(q.name).Set(float64(len(q.runningKeys)))
+
+	return key, task
+}
+
+// complete marks the key as no longer running, and queues the task with the same key that was added while it was running (if any).
+func (q *TaskQueue) complete(key string) {
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delete(q.runningKeys, key)
+	TaskQueueActiveTasks.WithLabelValues(q.name).Set(float64(len(q.runningKeys)))
+
+	if pendingTask, exists := q.pendingTasks[key]; exists {
+		delete(q.pendingTasks, key)
+		q.enqueue(key, pendingTask)
+	}
+}
+
+// runTask runs the task, recovering from (and reporting) any panic.
+func (q *TaskQueue) runTask(key string, task TaskQueueFunc) {
+
+	start := time.Now()
+
+	var taskErr error
+	isPanic, panicErr := CatchPanic(func() error {
+		taskErr = task(context.Background())
+		return nil
+	})
+	if isPanic {
+		taskErr = panicErr
+	}
+
+	TaskQueueTaskDuration.WithLabelValues(q.name).Observe(time.Since(start).Seconds())
+
+	if taskErr != nil {
+		TaskQueueTaskFailures.WithLabelValues(q.name).Inc()
+		q.log.Error(taskErr, "task failed", "taskKey", key)
+	}
+}
@@ -0,0 +1,176 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Task Queue Unit Tests", func() {
+
+	Context("Add Test", func() {
+
+		It("should run tasks with different keys concurrently, up to the number of workers", func() {
+
+			const workers = 3
+
+			taskQueue := NewTaskQueue("test-concurrency", workers)
+
+			var mutex sync.Mutex
+			running, maxRunning := 0, 0
+
+			wg := sync.WaitGroup{}
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				taskQueue.Add(fmt.Sprintf("task-%d", i), func(ctx context.Context) error {
+					defer wg.Done()
+
+					mutex.Lock()
+					running++
+					if running > maxRunning {
+						maxRunning = running
+					}
+					mutex.Unlock()
+
+					time.Sleep(20 * time.Millisecond)
+
+					mutex.Lock()
+					running--
+					mutex.Unlock()
+					return nil
+				})
+			}
+			wg.Wait()
+
+			Expect(maxRunning).To(BeNumerically(">", 1))
+			Expect(maxRunning).To(BeNumerically("<=", workers))
+		})
+
+		It("should collapse a task into the waiting task with the same key, and only run the most recently added task", func() {
+
+			taskQueue := NewTaskQueue("test-collapse", 1)
+
+			// Block the only worker, so that the following tasks are waiting
+			blockWorker := make(chan struct{})
+			taskQueue.Add("blocking-task", func(ctx context.Context) error {
+				<-blockWorker
+				return nil
+			})
+			Eventually(taskQueue.Len).Should(Equal(0))
+
+			var mutex sync.Mutex
+			tasksRun := []string{}
+			addTask := func(key string, value string) {
+				taskQueue.Add(key, func(ctx context.Context) error {
+					mutex.Lock()
+					defer mutex.Unlock()
+					tasksRun = append(tasksRun, value)
+					return nil
+				})
+			}
+
+			addTask("a", "a-1")
+			addTask("b", "b-1")
+			addTask("a", "a-2")
+			Expect(taskQueue.Len()).To(Equal(2))
+			Expect(testutil.ToFloat64(TaskQueueTasksDeduplicated.WithLabelValues("test-collapse"))).To(Equal(float64(1)))
+
+			close(blockWorker)
+
+			Eventually(func() []string {
+				mutex.Lock()
+				defer mutex.Unlock()
+				return append([]string{}, tasksRun...)
+			}).Should(Equal([]string{"a-2", "b-1"}))
+		})
+
+		It("should run a task that is added while a task with the same key is running, once the running task completes", func() {
+
+			taskQueue := NewTaskQueue("test-running", 2)
+
+			var mutex sync.Mutex
+			running, maxRunning, runs := 0, 0, 0
+
+			blockTask := make(chan struct{})
+			task := func(ctx context.Context) error {
+				mutex.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mutex.Unlock()
+
+				<-blockTask
+
+				mutex.Lock()
+				running--
+				runs++
+				mutex.Unlock()
+				return nil
+			}
+
+			taskQueue.Add("a", task)
+			Eventually(func() int {
+				mutex.Lock()
+				defer mutex.Unlock()
+				return running
+			}).Should(Equal(1))
+
+			// The task is not queued while the task with the same key is running
+			taskQueue.Add("a", task)
+			Consistently(taskQueue.Len, "100ms").Should(Equal(0))
+
+			close(blockTask)
+
+			Eventually(func() int {
+				mutex.Lock()
+				defer mutex.Unlock()
+				return runs
+			}).Should(Equal(2))
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			Expect(maxRunning).To(Equal(1), "at most one task with a given key should run at a time")
+		})
+
+		It("should recover from a task that panics, and continue to run tasks", func() {
+
+			taskQueue := NewTaskQueue("test-panic", 1)
+
+			taskQueue.Add("panic-task", func(ctx context.Context) error {
+				panic("test panic")
+			})
+
+			taskRun := make(chan struct{})
+			taskQueue.Add("other-task", func(ctx context.Context) error {
+				close(taskRun)
+				return nil
+			})
+
+			Eventually(taskRun).Should(BeClosed())
+			Expect(testutil.ToFloat64(TaskQueueTaskFailures.WithLabelValues("test-panic"))).To(Equal(float64(1)))
+		})
+	})
+
+	Context("AddAfter Test", func() {
+
+		It("should only run the task once the delay has elapsed", func() {
+
+			taskQueue := NewTaskQueue("test-add-after", 1)
+
+			start := time.Now()
+			taskRunAfter := make(chan time.Duration, 1)
+			taskQueue.AddAfter("delayed-task", 200*time.Millisecond, func(ctx context.Context) error {
+				taskRunAfter <- time.Since(start)
+				return nil
+			})
+
+			Eventually(taskRunAfter).Should(Receive(BeNumerically(">=", 200*time.Millisecond)))
+		})
+	})
+})
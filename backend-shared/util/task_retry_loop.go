@@ -93,6 +93,27 @@ func (loop *TaskRetryLoop) AddTaskIfNotPresent(name string, task RetryableTask,
 	}
 }
 
+// Drain stops the task retry loop from starting new tasks (waiting tasks, and tasks added or retried after this call,
+// are dropped), and waits for the active tasks to complete, or for the context to be cancelled. This is used on
+// shutdown: see ShutdownDrainer.
+func (loop *TaskRetryLoop) Drain(ctx context.Context) error {
+
+	drained := make(chan struct{})
+
+	select {
+	case loop.inputChan <- taskRetryLoopMessage{msgType: taskRetryLoop_drain, payload: taskRetryMessage_drain{drained: drained}}:
+	case <-ctx.Done():
+		return fmt.Errorf("unable to drain task retry loop '%s': %w", loop.debugName, ctx.Err())
+	}
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("active tasks of task retry loop '%s' did not complete: %w", loop.debugName, ctx.Err())
+	}
+}
+
 type taskRetryMessageType string
 
 const (
@@ -100,6 +121,7 @@ const (
 	taskRetryLoop_removeTask    taskRetryMessageType = "removeTask"
 	taskRetryLoop_workCompleted taskRetryMessageType = "workCompleted"
 	taskRetryLoop_tick          taskRetryMessageType = "tick"
+	taskRetryLoop_drain         taskRetryMessageType = "drain"
 )
 
 const (
@@ -120,6 +142,11 @@ type taskRetryMessage_removeTask struct {
 	name string
 }
 
+type taskRetryMessage_drain struct {
+	// drained is closed once there are no active tasks
+	drained chan struct{}
+}
+
 type taskRetryMessage_workCompleted struct {
 	name        string
 	shouldRetry bool
@@ -223,8 +250,19 @@ func internalTaskRetryLoop(inputChan chan taskRetryLoopMessage, debugName string
 
 	nextReportActiveTasks := time.Now().Add(ReportActiveTasksEveryXMinutes)
 
+	// Once draining, no new tasks are started, and the drained channels are closed once there are no active tasks
+	draining := false
+	drainedChans := []chan struct{}{}
+
 	for {
 
+		if draining && len(activeTaskMap) == 0 && len(drainedChans) > 0 {
+			for _, drained := range drainedChans {
+				close(drained)
+			}
+			drainedChans = []chan struct{}{}
+		}
+
 		// Every X minutes, report how many tasks are in progress, and how many are waiting. This allows us
 		// to identify bottenecks in task retry queues.
 		if time.Now().After(nextReportActiveTasks) {
@@ -235,7 +273,7 @@ func internalTaskRetryLoop(inputChan chan taskRetryLoopMessage, debugName string
 		}
 
 		// Queue more running tasks if we have resources
-		if waitingTaskContainer.isWorkAvailable() && len(activeTaskMap) < maxActiveRunners {
+		if !draining && waitingTaskContainer.isWorkAvailable() && len(activeTaskMap) < maxActiveRunners {
 
 			updatedWaitingTasks := []waitingTaskEntry{}
 
@@ -301,6 +339,11 @@ func internalTaskRetryLoop(inputChan chan taskRetryLoopMessage, debugName string
 				continue
 			}
 
+			if draining {
+				log.V(logutil.LogLevel_Debug).Info("Task retry loop is draining: dropping new task", "taskName", addTaskMsg.name)
+				continue
+			}
+
 			newWaitingTaskEntry := waitingTaskEntry{
 				name:    addTaskMsg.name,
 				task:    addTaskMsg.task,
@@ -355,7 +398,7 @@ func internalTaskRetryLoop(inputChan chan taskRetryLoopMessage, debugName string
 
 			log.V(logutil.LogLevel_Debug).Info("Task retry loop: task completed '"+taskEntry.name+"'", "shouldRetry", workCompletedMsg.shouldRetry)
 
-			if workCompletedMsg.shouldRetry {
+			if workCompletedMsg.shouldRetry && !draining {
 				log.V(logutil.LogLevel_Debug).Info("Adding failed task '" + taskEntry.name + "' to retry list")
 
 				nextScheduledRetryTime := time.Now().Add(taskEntry.backoff.IncreaseAndReturnNewDuration())
@@ -373,6 +416,26 @@ func internalTaskRetryLoop(inputChan chan taskRetryLoopMessage, debugName string
 		} else if msg.msgType == taskRetryLoop_tick {
 			// no processing required.
 			continue
+
+		} else if msg.msgType == taskRetryLoop_drain {
+
+			drainMsg, ok := (msg.payload).(taskRetryMessage_drain)
+			if !ok {
+				log.Error(nil, "SEVERE: unexpected message payload for drain")
+				continue
+			}
+
+			if !draining {
+				log.Info("Task retry loop is draining", "waitingTasks", len(waitingTaskContainer.waitingTasks), "activeTasks", len(activeTaskMap))
+			}
+
+			draining = true
+			drainedChans = append(drainedChans, drainMsg.drained)
+
+			// Drop the waiting tasks: only the active tasks are allowed to complete
+			waitingTaskContainer.waitingTasks = []waitingTaskEntry{}
+			waitingTaskContainer.waitingTasksByName = map[string]any{}
+			continue
 		} else {
 			log.Error(nil, "SEVERE: unexpected message type: "+string(msg.msgType))
 			continue
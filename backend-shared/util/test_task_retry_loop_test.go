@@ -196,6 +196,51 @@ var _ = Describe("Task Retry Loop Unit Tests", func() {
 		})
 	})
 
+	Context("Drain Test", func() {
+
+		It("should wait for the active tasks to complete, and not start new tasks, once draining", func() {
+
+			taskRetryLoop := NewTaskRetryLoop("test-name")
+			backoff := ExponentialBackoff{Factor: 2, Min: time.Duration(100 * time.Microsecond), Max: time.Duration(1 * time.Second), Jitter: true}
+
+			activeTask := &mockBlockingTask{started: make(chan struct{}), release: make(chan struct{})}
+			taskRetryLoop.AddTaskIfNotPresent("active-task", activeTask, backoff)
+			Eventually(activeTask.started).Should(BeClosed())
+
+			By("draining the task retry loop, while the task is active")
+			drainResult := make(chan error, 1)
+			go func() {
+				drainResult <- taskRetryLoop.Drain(context.Background())
+			}()
+			Consistently(drainResult, "500ms").ShouldNot(Receive())
+
+			By("adding a task while draining, which should not be started")
+			newTask := &mockBlockingTask{started: make(chan struct{}), release: make(chan struct{})}
+			taskRetryLoop.AddTaskIfNotPresent("new-task", newTask, backoff)
+
+			By("completing the active task, which should complete the drain")
+			close(activeTask.release)
+			Eventually(drainResult).Should(Receive(BeNil()))
+			Consistently(newTask.started, "500ms").ShouldNot(BeClosed())
+		})
+
+		It("should return an error if the active tasks do not complete before the context is cancelled", func() {
+
+			taskRetryLoop := NewTaskRetryLoop("test-name")
+
+			activeTask := &mockBlockingTask{started: make(chan struct{}), release: make(chan struct{})}
+			defer close(activeTask.release)
+			taskRetryLoop.AddTaskIfNotPresent("active-task", activeTask, ExponentialBackoff{Factor: 2, Min: time.Duration(100 * time.Microsecond), Max: time.Duration(1 * time.Second), Jitter: true})
+			Eventually(activeTask.started).Should(BeClosed())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			err := taskRetryLoop.Drain(ctx)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
 })
 
 // mockTestTaskCounter counts the number of calls to performTask, so that we can verify it is called a certain amount of itmes.
@@ -257,6 +302,18 @@ func (event *mockTestTaskEvent) PerformTask(taskContext context.Context) (bool,
 	return event.shouldTaskFail, nil
 }
 
+// mockBlockingTask closes 'started' when it is run, and then blocks until 'release' is closed.
+type mockBlockingTask struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (event *mockBlockingTask) PerformTask(taskContext context.Context) (bool, error) {
+	close(event.started)
+	<-event.release
+	return false, nil
+}
+
 type mockEmptyTask struct {
 }
 
@@ -2,14 +2,19 @@ package util
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,6 +25,18 @@ const (
 	ArgoCDDefaultDestinationInCluster = "in-cluster"
 
 	SelfHealIntervalEnVar = "SELF_HEAL_INTERVAL" // Interval in minutes between self-healing runs
+
+	// ApplicationSoftDeleteGracePeriodEnvVar is the time in minutes that the Application of a deleted GitOpsDeployment
+	// is soft-deleted for, before it is deleted. Soft-delete is disabled if not set (or 0).
+	ApplicationSoftDeleteGracePeriodEnvVar = "APPLICATION_SOFT_DELETE_GRACE_PERIOD"
+
+	// NamespaceOffboardAnnotation may be set to "true" on a Namespace, to offboard it: all of the database rows of the
+	// Namespace are deleted, and events for API resources in the Namespace are no longer processed.
+	NamespaceOffboardAnnotation = "managed-gitops.redhat.com/offboard"
+
+	// NamespaceMigrateToGitOpsEngineInstanceAnnotation may be set on a Namespace to the ID of a GitopsEngineInstance, to
+	// migrate all of the Applications of the Namespace to that Argo CD instance.
+	NamespaceMigrateToGitOpsEngineInstanceAnnotation = "managed-gitops.redhat.com/migrate-to-gitops-engine-instance"
 )
 
 // #nosec G101
@@ -29,8 +46,32 @@ const (
 	ArgoCDSecretRepoTypeValue     = "repository"                     // Secret type for Repository Secret
 
 	ManagedEnvironmentSecretType = "managed-gitops.redhat.com/managed-environment"
+
+	// SecretDataHashAnnotation is set on generated Secrets (for example, the managed environment Secret generated from a
+	// DeploymentTarget) to the hash of their data: see HashSecretData.
+	SecretDataHashAnnotation = "managed-gitops.redhat.com/secret-data-hash"
 )
 
+// HashSecretData returns a (hex-encoded SHA-256) hash of the data of a Secret, which changes whenever the data of the
+// Secret changes, for example, when the token of a cluster is refreshed. The hash is independent of the order of the keys.
+func HashSecretData(data map[string][]byte) string {
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		// Length-prefix each key and value, so that different data cannot produce the same input to the hash
+		fmt.Fprintf(hash, "%d:%s%d:", len(key), key, len(data[key]))
+		hash.Write(data[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 // ExponentialBackoff: the more times in a row something fails, the longer we wait.
 type ExponentialBackoff struct {
 	Factor float64
@@ -174,3 +215,30 @@ func SelfHealInterval(defaultValue time.Duration, logger logr.Logger) time.Durat
 	}
 	return time.Duration(value) * time.Minute
 }
+
+// ApplicationSoftDeleteGracePeriod returns the value of the ApplicationSoftDeleteGracePeriodEnvVar env var, or 0 if
+// soft-delete of Applications is disabled.
+func ApplicationSoftDeleteGracePeriod(logger logr.Logger) time.Duration {
+	gracePeriod := os.Getenv(ApplicationSoftDeleteGracePeriodEnvVar)
+	if gracePeriod == "" {
+		return 0
+	}
+	value, err := strconv.Atoi(gracePeriod)
+	if err != nil || value < 0 {
+		msg := fmt.Sprintf("value of env var %s must be a non-negative int", ApplicationSoftDeleteGracePeriodEnvVar)
+		logger.Error(err, msg)
+		return 0
+	}
+	return time.Duration(value) * time.Minute
+}
+
+// IsNamespaceBeingOffboarded returns true if the Namespace has been annotated to be offboarded: see NamespaceOffboardAnnotation.
+func IsNamespaceBeingOffboarded(namespace corev1.Namespace) bool {
+	return namespace.Annotations[NamespaceOffboardAnnotation] == "true"
+}
+
+// GetNamespaceMigrationTarget returns the ID of the GitopsEngineInstance that the Applications of the Namespace should be
+// migrated to, or "" if no migration was requested: see NamespaceMigrateToGitOpsEngineInstanceAnnotation.
+func GetNamespaceMigrationTarget(namespace corev1.Namespace) string {
+	return strings.TrimSpace(namespace.Annotations[NamespaceMigrateToGitOpsEngineInstanceAnnotation])
+}
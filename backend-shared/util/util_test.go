@@ -50,4 +50,18 @@ var _ = Describe("General Util Unit Tests", func() {
 			})
 		})
 	})
+
+	Context("Testing the HashSecretData() function", func() {
+
+		It("Should return the same hash for the same data, and a different hash when the data changes", func() {
+			data := map[string][]byte{"kubeconfig": []byte("token-1"), "namespace": []byte("my-ns")}
+
+			hash := HashSecretData(data)
+			Expect(hash).To(HaveLen(64))
+			Expect(HashSecretData(map[string][]byte{"namespace": []byte("my-ns"), "kubeconfig": []byte("token-1")})).To(Equal(hash))
+
+			Expect(HashSecretData(map[string][]byte{"kubeconfig": []byte("token-2"), "namespace": []byte("my-ns")})).ToNot(Equal(hash))
+			Expect(HashSecretData(map[string][]byte{"kubeconfig": []byte("token-1my-ns")})).ToNot(Equal(hash))
+		})
+	})
 })
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+const (
+	// bundleVersion should be incremented whenever the format of the bundle changes in a way that is not backwards compatible.
+	bundleVersion = 1
+
+	// CredentialsRedacted indicates that the sensitive credential fields of the bundle were removed on export.
+	CredentialsRedacted = "redacted"
+
+	// CredentialsEncrypted indicates that the sensitive credential fields of the bundle were encrypted on export, with
+	// the key from the encryption key environment variable.
+	CredentialsEncrypted = "encrypted"
+)
+
+// Bundle is a portable JSON representation of all the rows of the GitOps Service database.
+//
+// The sensitive fields of ClusterCredentials (kubeconfig and ServiceAccount bearer token) and RepositoryCredentials
+// (password and SSH key) are never exported in plaintext: they are either redacted or encrypted (see 'Credentials').
+type Bundle struct {
+	Version    int       `json:"version"`
+	ExportedOn time.Time `json:"exportedOn"`
+
+	// Credentials is either CredentialsRedacted or CredentialsEncrypted
+	Credentials string `json:"credentials"`
+
+	ClusterUsers                    []db.ClusterUser                    `json:"clusterUsers"`
+	ClusterCredentials              []db.ClusterCredentials             `json:"clusterCredentials"`
+	GitopsEngineClusters            []db.GitopsEngineCluster            `json:"gitopsEngineClusters"`
+	GitopsEngineInstances           []db.GitopsEngineInstance           `json:"gitopsEngineInstances"`
+	ManagedEnvironments             []db.ManagedEnvironment             `json:"managedEnvironments"`
+	ClusterAccess                   []db.ClusterAccess                  `json:"clusterAccess"`
+	Applications                    []db.Application                    `json:"applications"`
+	ApplicationOwners               []db.ApplicationOwner               `json:"applicationOwners"`
+	ApplicationStates               []db.ApplicationState               `json:"applicationStates"`
+	DeploymentHistory               []db.DeploymentHistory              `json:"deploymentHistory"`
+	DeploymentToApplicationMappings []db.DeploymentToApplicationMapping `json:"deploymentToApplicationMappings"`
+	Operations                      []db.Operation                      `json:"operations"`
+	SyncOperations                  []db.SyncOperation                  `json:"syncOperations"`
+	RepositoryCredentials           []db.RepositoryCredentials          `json:"repositoryCredentials"`
+	KubernetesToDBResourceMappings  []db.KubernetesToDBResourceMapping  `json:"kubernetesToDBResourceMappings"`
+	APICRToDatabaseMappings         []db.APICRToDatabaseMapping         `json:"apiCRToDatabaseMappings"`
+	FeatureFlags                    []db.FeatureFlag                    `json:"featureFlags"`
+}
+
+// exportBundle reads all the rows of the database into a Bundle. If encryptionKey is empty, the sensitive credential
+// fields are redacted, otherwise they are encrypted with the key.
+func exportBundle(ctx context.Context, dbQueries db.UnsafeDatabaseQueries, encryptionKey string) (*Bundle, error) {
+
+	bundle := &Bundle{
+		Version:    bundleVersion,
+		ExportedOn: time.Now(),
+	}
+
+	listFns := []struct {
+		name string
+		fn   func() error
+	}{
+		{"ClusterUser", func() error { return dbQueries.UnsafeListAllClusterUsers(ctx, &bundle.ClusterUsers) }},
+		{"ClusterCredentials", func() error { return dbQueries.UnsafeListAllClusterCredentials(ctx, &bundle.ClusterCredentials) }},
+		{"GitopsEngineCluster", func() error { return dbQueries.UnsafeListAllGitopsEngineClusters(ctx, &bundle.GitopsEngineClusters) }},
+		{"GitopsEngineInstance", func() error { return dbQueries.UnsafeListAllGitopsEngineInstances(ctx, &bundle.GitopsEngineInstances) }},
+		{"ManagedEnvironment", func() error { return dbQueries.UnsafeListAllManagedEnvironments(ctx, &bundle.ManagedEnvironments) }},
+		{"ClusterAccess", func() error { return dbQueries.UnsafeListAllClusterAccess(ctx, &bundle.ClusterAccess) }},
+		{"Application", func() error { return dbQueries.UnsafeListAllApplications(ctx, &bundle.Applications) }},
+		{"ApplicationOwner", func() error { return dbQueries.UnsafeListAllApplicationOwners(ctx, &bundle.ApplicationOwners) }},
+		{"ApplicationState", func() error { return dbQueries.UnsafeListAllApplicationStates(ctx, &bundle.ApplicationStates) }},
+		{"DeploymentHistory", func() error { return dbQueries.UnsafeListAllDeploymentHistory(ctx, &bundle.DeploymentHistory) }},
+		{"DeploymentToApplicationMapping", func() error {
+			return dbQueries.UnsafeListAllDeploymentToApplicationMapping(ctx, &bundle.DeploymentToApplicationMappings)
+		}},
+		{"Operation", func() error { return dbQueries.UnsafeListAllOperations(ctx, &bundle.Operations) }},
+		{"SyncOperation", func() error { return dbQueries.UnsafeListAllSyncOperations(ctx, &bundle.SyncOperations) }},
+		{"RepositoryCredentials", func() error {
+			return dbQueries.UnsafeListAllRepositoryCredentials(ctx, &bundle.RepositoryCredentials)
+		}},
+		{"KubernetesToDBResourceMapping", func() error {
+			return dbQueries.UnsafeListAllKubernetesResourceToDBResourceMapping(ctx, &bundle.KubernetesToDBResourceMappings)
+		}},
+		{"APICRToDatabaseMapping", func() error {
+			return dbQueries.UnsafeListAllAPICRToDatabaseMappings(ctx, &bundle.APICRToDatabaseMappings)
+		}},
+		{"FeatureFlag", func() error { return dbQueries.UnsafeListAllFeatureFlags(ctx, &bundle.FeatureFlags) }},
+	}
+
+	for _, listFn := range listFns {
+		if err := listFn.fn(); err != nil {
+			return nil, fmt.Errorf("unable to list %s rows: %w", listFn.name, err)
+		}
+	}
+
+	if err := protectCredentials(bundle, encryptionKey); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// importBundle inserts all the rows of the Bundle into the database, within a single transaction. The database is
+// expected to be empty (for example, a newly created database, with only the database migrations applied).
+func importBundle(ctx context.Context, dbConn *pg.DB, bundle *Bundle, encryptionKey string) error {
+
+	if bundle.Version != bundleVersion {
+		return fmt.Errorf("unsupported bundle version %d, expected %d", bundle.Version, bundleVersion)
+	}
+
+	if err := unprotectCredentials(bundle, encryptionKey); err != nil {
+		return err
+	}
+
+	return dbConn.RunInTransaction(ctx, func(tx *pg.Tx) error {
+
+		// The rows are inserted in an order that satisfies the foreign key constraints of the tables.
+		models := []struct {
+			name  string
+			table string
+			model any
+			len   int
+		}{
+			{"ClusterUser", "clusteruser", &bundle.ClusterUsers, len(bundle.ClusterUsers)},
+			{"ClusterCredentials", "clustercredentials", &bundle.ClusterCredentials, len(bundle.ClusterCredentials)},
+			{"GitopsEngineCluster", "gitopsenginecluster", &bundle.GitopsEngineClusters, len(bundle.GitopsEngineClusters)},
+			{"GitopsEngineInstance", "gitopsengineinstance", &bundle.GitopsEngineInstances, len(bundle.GitopsEngineInstances)},
+			{"ManagedEnvironment", "managedenvironment", &bundle.ManagedEnvironments, len(bundle.ManagedEnvironments)},
+			{"ClusterAccess", "clusteraccess", &bundle.ClusterAccess, len(bundle.ClusterAccess)},
+			{"Application", "application", &bundle.Applications, len(bundle.Applications)},
+			{"ApplicationOwner", "applicationowner", &bundle.ApplicationOwners, len(bundle.ApplicationOwners)},
+			{"ApplicationState", "", &bundle.ApplicationStates, len(bundle.ApplicationStates)},
+			{"DeploymentHistory", "deploymenthistory", &bundle.DeploymentHistory, len(bundle.DeploymentHistory)},
+			{"DeploymentToApplicationMapping", "deploymenttoapplicationmapping", &bundle.DeploymentToApplicationMappings,
+				len(bundle.DeploymentToApplicationMappings)},
+			{"Operation", "operation", &bundle.Operations, len(bundle.Operations)},
+			{"SyncOperation", "syncoperation", &bundle.SyncOperations, len(bundle.SyncOperations)},
+			{"RepositoryCredentials", "repositorycredentials", &bundle.RepositoryCredentials, len(bundle.RepositoryCredentials)},
+			{"KubernetesToDBResourceMapping", "kubernetestodbresourcemapping", &bundle.KubernetesToDBResourceMappings,
+				len(bundle.KubernetesToDBResourceMappings)},
+			{"APICRToDatabaseMapping", "apicrtodatabasemapping", &bundle.APICRToDatabaseMappings, len(bundle.APICRToDatabaseMappings)},
+			{"FeatureFlag", "featureflag", &bundle.FeatureFlags, len(bundle.FeatureFlags)},
+		}
+
+		for _, m := range models {
+
+			count, err := tx.ModelContext(ctx, m.model).Count()
+			if err != nil {
+				return fmt.Errorf("unable to count existing %s rows: %w", m.name, err)
+			}
+			if count != 0 {
+				return fmt.Errorf("the target database is not empty: found %d existing %s rows", count, m.name)
+			}
+
+			if m.len == 0 {
+				continue
+			}
+
+			if _, err := tx.ModelContext(ctx, m.model).Insert(); err != nil {
+				return fmt.Errorf("unable to insert %s rows: %w", m.name, err)
+			}
+
+			// The seq_id values are preserved on import, so the sequence must be advanced past them (ApplicationState has no seq_id).
+			if m.table != "" {
+				if _, err := tx.ExecContext(ctx, "SELECT setval(pg_get_serial_sequence(?, 'seq_id'), (SELECT MAX(seq_id) FROM ?))",
+					m.table, pg.Ident(m.table)); err != nil {
+					return fmt.Errorf("unable to update seq_id sequence of %s: %w", m.name, err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// protectCredentials redacts (if encryptionKey is empty) or encrypts the sensitive credential fields of the bundle.
+func protectCredentials(bundle *Bundle, encryptionKey string) error {
+
+	protect := func(value string) (string, error) {
+		if encryptionKey == "" || value == "" {
+			return "", nil
+		}
+		return encryptValue(value, encryptionKey)
+	}
+
+	bundle.Credentials = CredentialsRedacted
+	if encryptionKey != "" {
+		bundle.Credentials = CredentialsEncrypted
+	}
+
+	return forEachCredentialField(bundle, protect)
+}
+
+// unprotectCredentials decrypts the sensitive credential fields of the bundle, if they were encrypted on export.
+//
+// Redacted credentials are imported as empty: the backend will reacquire the cluster credentials from the
+// GitOpsDeploymentManagedEnvironment Secret once it detects that it is no longer able to connect with them.
+func unprotectCredentials(bundle *Bundle, encryptionKey string) error {
+
+	switch bundle.Credentials {
+	case CredentialsRedacted:
+		return nil
+	case CredentialsEncrypted:
+		if encryptionKey == "" {
+			return errors.New("the bundle contains encrypted credentials, but no encryption key was provided")
+		}
+	default:
+		return fmt.Errorf("unsupported bundle credentials value '%s'", bundle.Credentials)
+	}
+
+	return forEachCredentialField(bundle, func(value string) (string, error) {
+		if value == "" {
+			return "", nil
+		}
+		return decryptValue(value, encryptionKey)
+	})
+}
+
+// forEachCredentialField replaces each sensitive credential field of the bundle with the result of 'fn'.
+func forEachCredentialField(bundle *Bundle, fn func(string) (string, error)) error {
+
+	var err error
+
+	for i := range bundle.ClusterCredentials {
+		clusterCreds := &bundle.ClusterCredentials[i]
+
+		if clusterCreds.Kube_config, err = fn(clusterCreds.Kube_config); err != nil {
+			return fmt.Errorf("unable to process kube_config of cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+		}
+		if clusterCreds.Serviceaccount_bearer_token, err = fn(clusterCreds.Serviceaccount_bearer_token); err != nil {
+			return fmt.Errorf("unable to process bearer token of cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+		}
+	}
+
+	for i := range bundle.RepositoryCredentials {
+		repoCreds := &bundle.RepositoryCredentials[i]
+
+		if repoCreds.AuthPassword, err = fn(repoCreds.AuthPassword); err != nil {
+			return fmt.Errorf("unable to process password of repository credentials '%s': %w", repoCreds.RepositoryCredentialsID, err)
+		}
+		if repoCreds.AuthSSHKey, err = fn(repoCreds.AuthSSHKey); err != nil {
+			return fmt.Errorf("unable to process SSH key of repository credentials '%s': %w", repoCreds.RepositoryCredentialsID, err)
+		}
+	}
+
+	return nil
+}
+
+// newGCM returns an AES-256-GCM cipher, using the SHA-256 hash of the encryption key as the AES key.
+func newGCM(encryptionKey string) (cipher.AEAD, error) {
+
+	key := sha256.Sum256([]byte(encryptionKey))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encryptValue encrypts the value with the encryption key, and returns the base64-encoded nonce and ciphertext.
+func encryptValue(value string, encryptionKey string) (string, error) {
+
+	gcm, err := newGCM(encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+// decryptValue reverses encryptValue.
+func decryptValue(value string, encryptionKey string) (string, error) {
+
+	gcm, err := newGCM(encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt value, the encryption key may be incorrect: %w", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("DB bundle credential tests", func() {
+
+	var bundle *Bundle
+
+	BeforeEach(func() {
+		bundle = &Bundle{
+			Version: bundleVersion,
+			ClusterCredentials: []db.ClusterCredentials{
+				{
+					Clustercredentials_cred_id:  "test-cluster-creds",
+					Host:                        "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443",
+					Kube_config:                 "my-kube-config",
+					Serviceaccount_bearer_token: "my-bearer-token",
+				},
+			},
+			RepositoryCredentials: []db.RepositoryCredentials{
+				{
+					RepositoryCredentialsID: "test-repo-creds",
+					AuthUsername:            "my-user",
+					AuthPassword:            "my-password",
+				},
+			},
+		}
+	})
+
+	It("should redact the credentials if no encryption key is provided", func() {
+		Expect(protectCredentials(bundle, "")).To(Succeed())
+
+		Expect(bundle.Credentials).To(Equal(CredentialsRedacted))
+		Expect(bundle.ClusterCredentials[0].Kube_config).To(BeEmpty())
+		Expect(bundle.ClusterCredentials[0].Serviceaccount_bearer_token).To(BeEmpty())
+		Expect(bundle.ClusterCredentials[0].Host).ToNot(BeEmpty())
+		Expect(bundle.RepositoryCredentials[0].AuthPassword).To(BeEmpty())
+		Expect(bundle.RepositoryCredentials[0].AuthUsername).To(Equal("my-user"))
+
+		By("verifying that redacted credentials are imported as empty")
+		Expect(unprotectCredentials(bundle, "")).To(Succeed())
+		Expect(bundle.ClusterCredentials[0].Kube_config).To(BeEmpty())
+	})
+
+	It("should encrypt the credentials if an encryption key is provided, and decrypt them with the same key", func() {
+		Expect(protectCredentials(bundle, "my-key")).To(Succeed())
+
+		Expect(bundle.Credentials).To(Equal(CredentialsEncrypted))
+		Expect(bundle.ClusterCredentials[0].Kube_config).ToNot(BeEmpty())
+		Expect(bundle.ClusterCredentials[0].Kube_config).ToNot(Equal("my-kube-config"))
+		Expect(bundle.RepositoryCredentials[0].AuthPassword).ToNot(Equal("my-password"))
+		Expect(bundle.RepositoryCredentials[0].AuthSSHKey).To(BeEmpty(), "empty values should remain empty")
+
+		Expect(unprotectCredentials(bundle, "my-key")).To(Succeed())
+		Expect(bundle.ClusterCredentials[0].Kube_config).To(Equal("my-kube-config"))
+		Expect(bundle.ClusterCredentials[0].Serviceaccount_bearer_token).To(Equal("my-bearer-token"))
+		Expect(bundle.RepositoryCredentials[0].AuthPassword).To(Equal("my-password"))
+	})
+
+	It("should fail to decrypt the credentials with a missing or incorrect key", func() {
+		Expect(protectCredentials(bundle, "my-key")).To(Succeed())
+
+		Expect(unprotectCredentials(bundle, "")).ToNot(Succeed())
+		Expect(unprotectCredentials(bundle, "not-my-key")).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+// EncryptionKeyEnvVar is the environment variable containing the key used to encrypt/decrypt the credentials of the bundle.
+// If it is not set on export, the credentials are redacted from the bundle.
+const EncryptionKeyEnvVar = "DB_BUNDLE_ENCRYPTION_KEY"
+
+// db-bundle exports all the rows of the GitOps Service database to a portable JSON bundle, and imports that bundle into
+// a fresh database. This can be used for disaster recovery, or to migrate between Postgres instances.
+//
+// The database to connect to is configured using the same environment variables as the GitOps Service
+// (DB_ADDR, DB_PASS, POSTGRESQL_DATABASE).
+//
+// Usage:
+//
+//	db-bundle export --file (bundle.json)
+//	db-bundle import --file (bundle.json)
+func main() {
+
+	if len(os.Args) < 2 {
+		printUsageAndExit()
+	}
+
+	flagSet := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	file := flagSet.String("file", "", "path of the JSON bundle file")
+	port := flagSet.Int("port", db.DEFAULT_PORT, "port of the Postgres database")
+	verbose := flagSet.Bool("verbose", false, "log all database queries")
+
+	if err := flagSet.Parse(os.Args[2:]); err != nil || *file == "" {
+		printUsageAndExit()
+	}
+
+	encryptionKey := os.Getenv(EncryptionKeyEnvVar)
+
+	var err error
+
+	switch os.Args[1] {
+	case "export":
+		err = runExport(*file, *port, *verbose, encryptionKey)
+	case "import":
+		err = runImport(*file, *port, *verbose, encryptionKey)
+	default:
+		printUsageAndExit()
+	}
+
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+func runExport(file string, port int, verbose bool, encryptionKey string) error {
+
+	dbQueries, err := db.NewUnsafePostgresDBQueriesWithPort(verbose, false, port)
+	if err != nil {
+		return fmt.Errorf("unable to connect to database: %w", err)
+	}
+	defer dbQueries.CloseDatabase()
+
+	if encryptionKey == "" {
+		fmt.Printf("* %s is not set: credentials will be redacted from the bundle.\n", EncryptionKeyEnvVar)
+	}
+
+	bundle, err := exportBundle(context.Background(), dbQueries, encryptionKey)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal bundle: %w", err)
+	}
+
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("unable to write bundle to '%s': %w", file, err)
+	}
+
+	fmt.Println("* Exported database to", file)
+
+	return nil
+}
+
+func runImport(file string, port int, verbose bool, encryptionKey string) error {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read bundle from '%s': %w", file, err)
+	}
+
+	bundle := &Bundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return fmt.Errorf("unable to unmarshal bundle: %w", err)
+	}
+
+	dbConn, err := db.ConnectToDatabaseWithPort(verbose, port)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	if err := importBundle(context.Background(), dbConn, bundle, encryptionKey); err != nil {
+		return err
+	}
+
+	if bundle.Credentials == CredentialsRedacted {
+		fmt.Println("* The bundle credentials were redacted: cluster and repository credentials will be reacquired from their " +
+			"corresponding Secrets, when the GitOpsDeploymentManagedEnvironment/GitOpsDeploymentRepositoryCredential resources are next reconciled.")
+	}
+
+	fmt.Println("* Imported", file, "into database")
+
+	return nil
+}
+
+func printUsageAndExit() {
+	fmt.Println("Usage: db-bundle (export|import) --file (bundle.json) [--port (port)] [--verbose]")
+	fmt.Printf("Set the %s environment variable to encrypt (on export) or decrypt (on import) the bundle credentials.\n", EncryptionKeyEnvVar)
+	os.Exit(1)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
+	"gopkg.in/yaml.v2"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runList prints a table of all the rows of the given resource type.
+func runList(ctx context.Context, dbQueries db.AllDatabaseQueries, resource string, state db.OperationState, out io.Writer) error {
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	switch resource {
+	case "applications", "application":
+		var applications []db.Application
+		if err := dbQueries.UnsafeListAllApplications(ctx, &applications); err != nil {
+			return fmt.Errorf("unable to list Applications: %w", err)
+		}
+
+		fmt.Fprintln(w, "ID\tARGO CD APPLICATION\tMANAGED ENVIRONMENT\tCREATED")
+		for _, application := range applications {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", application.Application_id, application.Name,
+				valueOrNone(application.Managed_environment_id), formatTime(application.Created_on))
+		}
+
+	case "operations", "operation":
+		var operationList []db.Operation
+		if err := dbQueries.UnsafeListAllOperations(ctx, &operationList); err != nil {
+			return fmt.Errorf("unable to list Operations: %w", err)
+		}
+
+		fmt.Fprintln(w, "ID\tRESOURCE TYPE\tRESOURCE ID\tSTATE\tCREATED\tLAST STATE UPDATE")
+		for _, operation := range operationList {
+			if state != "" && operation.State != state {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", operation.Operation_id, operation.Resource_type, operation.Resource_id,
+				operation.State, formatTime(operation.Created_on), formatTime(operation.Last_state_update))
+		}
+
+	case "managedenvironments", "managedenvironment":
+		var managedEnvironments []db.ManagedEnvironment
+		if err := dbQueries.UnsafeListAllManagedEnvironments(ctx, &managedEnvironments); err != nil {
+			return fmt.Errorf("unable to list ManagedEnvironments: %w", err)
+		}
+
+		fmt.Fprintln(w, "ID\tNAME\tCLUSTER CREDENTIALS\tCREATED")
+		for _, managedEnvironment := range managedEnvironments {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", managedEnvironment.Managedenvironment_id, managedEnvironment.Name,
+				managedEnvironment.Clustercredentials_id, formatTime(managedEnvironment.Created_on))
+		}
+
+	default:
+		return fmt.Errorf("unsupported resource type '%s'", resource)
+	}
+
+	return nil
+}
+
+// runGet prints the details of a single row of the given resource type.
+func runGet(ctx context.Context, dbQueries db.AllDatabaseQueries, resource string, id string, out io.Writer) error {
+
+	switch resource {
+	case "application":
+		application := db.Application{Application_id: id}
+		if err := dbQueries.GetApplicationById(ctx, &application); err != nil {
+			return fmt.Errorf("unable to retrieve Application '%s': %w", id, err)
+		}
+		return printApplication(ctx, dbQueries, application, out)
+
+	case "operation":
+		operation := db.Operation{Operation_id: id}
+		if err := dbQueries.GetOperationById(ctx, &operation); err != nil {
+			return fmt.Errorf("unable to retrieve Operation '%s': %w", id, err)
+		}
+		return printJSON(out, operation)
+
+	case "managedenvironment":
+		managedEnvironment := db.ManagedEnvironment{Managedenvironment_id: id}
+		if err := dbQueries.GetManagedEnvironmentById(ctx, &managedEnvironment); err != nil {
+			return fmt.Errorf("unable to retrieve ManagedEnvironment '%s': %w", id, err)
+		}
+
+		var applications []db.Application
+		if _, err := dbQueries.ListApplicationsForManagedEnvironment(ctx, id, &applications); err != nil {
+			return fmt.Errorf("unable to list the Applications of ManagedEnvironment '%s': %w", id, err)
+		}
+
+		applicationIDs := []string{}
+		for _, application := range applications {
+			applicationIDs = append(applicationIDs, application.Application_id)
+		}
+
+		return printJSON(out, struct {
+			ManagedEnvironment db.ManagedEnvironment
+			ApplicationIDs     []string
+		}{managedEnvironment, applicationIDs})
+
+	default:
+		return fmt.Errorf("unsupported resource type '%s'", resource)
+	}
+}
+
+// printApplication prints an Application row, with its decoded spec field, and its ApplicationState (if any).
+func printApplication(ctx context.Context, dbQueries db.AllDatabaseQueries, application db.Application, out io.Writer) error {
+
+	specField, err := decodeSpecField(application.Spec_field)
+	if err != nil {
+		return err
+	}
+
+	var applicationState *db.ApplicationState
+	appState := db.ApplicationState{Applicationstate_application_id: application.Application_id}
+	if err := dbQueries.GetApplicationStateById(ctx, &appState); err == nil {
+		// The resources are compressed, and are not useful when troubleshooting from the command line
+		appState.Resources = nil
+		applicationState = &appState
+	} else if !db.IsResultNotFoundError(err) {
+		return fmt.Errorf("unable to retrieve ApplicationState of Application '%s': %w", application.Application_id, err)
+	}
+
+	// The spec field is replaced by its decoded value
+	application.Spec_field = ""
+
+	return printJSON(out, struct {
+		Application      db.Application
+		SpecField        fauxargocd.FauxApplication
+		ApplicationState *db.ApplicationState
+	}{application, specField, applicationState})
+}
+
+// decodeSpecField decodes the YAML contents of the spec field of an Application row into an Argo CD Application.
+func decodeSpecField(specField string) (fauxargocd.FauxApplication, error) {
+
+	var application fauxargocd.FauxApplication
+	if err := yaml.Unmarshal([]byte(specField), &application); err != nil {
+		return fauxargocd.FauxApplication{}, fmt.Errorf("unable to decode spec field: %w", err)
+	}
+
+	return application, nil
+}
+
+// runTrace maps a GitOpsDeployment to its Argo CD Application, or an Argo CD Application back to its GitOpsDeployment.
+func runTrace(ctx context.Context, dbQueries db.AllDatabaseQueries, resource string, name string, out io.Writer) error {
+
+	var dtams []db.DeploymentToApplicationMapping
+	if err := dbQueries.UnsafeListAllDeploymentToApplicationMapping(ctx, &dtams); err != nil {
+		return fmt.Errorf("unable to list DeploymentToApplicationMappings: %w", err)
+	}
+
+	var applications []db.Application
+	if err := dbQueries.UnsafeListAllApplications(ctx, &applications); err != nil {
+		return fmt.Errorf("unable to list Applications: %w", err)
+	}
+
+	// map: Application_id -> Application row
+	applicationsByID := map[string]db.Application{}
+	for _, application := range applications {
+		applicationsByID[application.Application_id] = application
+	}
+
+	// One row per matching DeploymentToApplicationMapping
+	rows := []string{}
+
+	switch resource {
+	case "gitopsdeployment":
+		namespace, deploymentName, ok := strings.Cut(name, "/")
+		if !ok {
+			return fmt.Errorf("expected a GitOpsDeployment in the format (namespace)/(name), but got '%s'", name)
+		}
+
+		for _, dtam := range dtams {
+			if dtam.DeploymentNamespace != namespace || dtam.DeploymentName != deploymentName {
+				continue
+			}
+			rows = append(rows, fmt.Sprintf("%s/%s\t%s\t%s\t%s", dtam.DeploymentNamespace, dtam.DeploymentName,
+				dtam.Deploymenttoapplicationmapping_uid_id, dtam.Application_id, valueOrNone(applicationsByID[dtam.Application_id].Name)))
+		}
+
+	case "application":
+		for _, dtam := range dtams {
+			application, exists := applicationsByID[dtam.Application_id]
+			if !exists || application.Name != name {
+				continue
+			}
+			rows = append(rows, fmt.Sprintf("%s/%s\t%s\t%s\t%s", dtam.DeploymentNamespace, dtam.DeploymentName,
+				dtam.Deploymenttoapplicationmapping_uid_id, dtam.Application_id, application.Name))
+		}
+
+	default:
+		return fmt.Errorf("unsupported resource type '%s'", resource)
+	}
+
+	if len(rows) == 0 {
+		return fmt.Errorf("no %s '%s' was found in the database", resource, name)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "GITOPSDEPLOYMENT\tGITOPSDEPLOYMENT UID\tAPPLICATION ID\tARGO CD APPLICATION")
+	for _, row := range rows {
+		fmt.Fprintln(w, row)
+	}
+
+	return nil
+}
+
+// requeueOperation resets an Operation to Waiting (if it has completed), and recreates its Operation resource, so that
+// the cluster-agent processes the Operation again.
+func requeueOperation(ctx context.Context, dbQueries db.AllDatabaseQueries, k8sClient client.Client, id string, out io.Writer) error {
+
+	operation := db.Operation{Operation_id: id}
+	if err := dbQueries.GetOperationById(ctx, &operation); err != nil {
+		return fmt.Errorf("unable to retrieve Operation '%s': %w", id, err)
+	}
+
+	gitopsEngineInstance := db.GitopsEngineInstance{Gitopsengineinstance_id: operation.Instance_id}
+	if err := dbQueries.GetGitopsEngineInstanceById(ctx, &gitopsEngineInstance); err != nil {
+		return fmt.Errorf("unable to retrieve GitopsEngineInstance '%s' of Operation: %w", operation.Instance_id, err)
+	}
+
+	if operation.State == db.OperationState_Completed || operation.State == db.OperationState_Failed {
+		operation.State = db.OperationState_Waiting
+		operation.Human_readable_state = ""
+		operation.Last_state_update = time.Now()
+
+		if err := dbQueries.UpdateOperation(ctx, &operation); err != nil {
+			return fmt.Errorf("unable to update state of Operation '%s': %w", id, err)
+		}
+		fmt.Fprintln(out, "* Reset state of Operation", id, "to", db.OperationState_Waiting)
+	}
+
+	operationCR := &managedgitopsv1alpha1.Operation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      operations.GenerateOperationCRName(operation),
+			Namespace: gitopsEngineInstance.Namespace_name,
+		},
+	}
+
+	// The cluster-agent only processes an Operation when its resource is created/updated, so recreate it.
+	if err := k8sClient.Delete(ctx, operationCR); err != nil && !apierr.IsNotFound(err) {
+		return fmt.Errorf("unable to delete Operation resource '%s': %w", operationCR.Name, err)
+	}
+
+	operationCR = &managedgitopsv1alpha1.Operation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      operationCR.Name,
+			Namespace: operationCR.Namespace,
+		},
+		Spec: managedgitopsv1alpha1.OperationSpec{
+			OperationID: operation.Operation_id,
+		},
+	}
+	if err := k8sClient.Create(ctx, operationCR); err != nil {
+		return fmt.Errorf("unable to create Operation resource '%s': %w", operationCR.Name, err)
+	}
+
+	fmt.Fprintln(out, "* Recreated Operation resource", operationCR.Namespace+"/"+operationCR.Name)
+
+	return nil
+}
+
+// runRotate rotates the keys used to encrypt RepositoryCredentials: either the master key that wraps all the data keys
+// ('masterkey'), or the data key of a single ClusterUser ('datakey').
+func runRotate(ctx context.Context, dbQueries db.AllDatabaseQueries, resource string, args []string, out io.Writer) error {
+
+	switch {
+	case resource == "masterkey" && len(args) == 0:
+		rotated, err := dbQueries.RotateMasterKeyOfKeyStores(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to rotate master key (%d data keys were rewrapped): %w", rotated, err)
+		}
+		fmt.Fprintln(out, "* Rewrapped", rotated, "data keys with the current master key")
+
+	case resource == "datakey" && len(args) == 1:
+		clusterUserID := args[0]
+		if err := dbQueries.RotateDataKeyOfClusterUser(ctx, clusterUserID); err != nil {
+			return fmt.Errorf("unable to rotate data key of ClusterUser '%s': %w", clusterUserID, err)
+		}
+		fmt.Fprintln(out, "* Rotated data key of ClusterUser", clusterUserID)
+
+	default:
+		return fmt.Errorf("unsupported rotate arguments: expected 'masterkey', or 'datakey (cluster user id)'")
+	}
+
+	return nil
+}
+
+func printJSON(out io.Writer, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal output: %w", err)
+	}
+
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "<none>"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"gopkg.in/yaml.v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("gitopsctl command tests", func() {
+
+	Context("decodeSpecField", func() {
+
+		It("should decode the spec field of an Application row", func() {
+			specField, err := yaml.Marshal(fauxargocd.FauxApplication{
+				FauxObjectMeta: fauxargocd.FauxObjectMeta{
+					Name:      "my-application",
+					Namespace: "gitops-service-argocd",
+				},
+				Spec: fauxargocd.FauxApplicationSpec{
+					Source: fauxargocd.ApplicationSource{
+						RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+					},
+				},
+			})
+			Expect(err).To(BeNil())
+
+			application, err := decodeSpecField(string(specField))
+			Expect(err).To(BeNil())
+			Expect(application.Name).To(Equal("my-application"))
+			Expect(application.Spec.Source.RepoURL).To(Equal("https://github.com/redhat-appstudio/managed-gitops"))
+		})
+
+		It("should return an error if the spec field is not valid YAML", func() {
+			_, err := decodeSpecField("{ not valid")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("commands which read from and write to the database", func() {
+
+		var ctx context.Context
+		var dbq db.AllDatabaseQueries
+		var gitopsEngineInstance *db.GitopsEngineInstance
+		var application db.Application
+		var operation db.Operation
+
+		BeforeEach(func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			var managedEnvironment *db.ManagedEnvironment
+			var clusterAccess *db.ClusterAccess
+			_, managedEnvironment, _, gitopsEngineInstance, clusterAccess, err = db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			specField, err := yaml.Marshal(fauxargocd.FauxApplication{
+				FauxObjectMeta: fauxargocd.FauxObjectMeta{Name: "my-application"},
+			})
+			Expect(err).To(BeNil())
+
+			application = db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "my-application",
+				Spec_field:              string(specField),
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(dbq.CreateApplication(ctx, &application)).To(Succeed())
+
+			dtam := db.DeploymentToApplicationMapping{
+				Deploymenttoapplicationmapping_uid_id: "test-my-gitopsdeployment-uid",
+				Application_id:                        application.Application_id,
+				DeploymentName:                        "my-gitopsdeployment",
+				DeploymentNamespace:                   "my-namespace",
+				NamespaceUID:                          "test-my-namespace-uid",
+			}
+			Expect(dbq.CreateDeploymentToApplicationMapping(ctx, &dtam)).To(Succeed())
+
+			operation = db.Operation{
+				Operation_id:            "test-operation-completed",
+				Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+				Resource_id:             application.Application_id,
+				Resource_type:           db.OperationResourceType_Application,
+				State:                   db.OperationState_Completed,
+				Operation_owner_user_id: clusterAccess.Clusteraccess_user_id,
+				Last_state_update:       time.Now(),
+			}
+			Expect(dbq.CreateOperation(ctx, &operation, operation.Operation_owner_user_id)).To(Succeed())
+
+			waitingOperation := db.Operation{
+				Operation_id:            "test-operation-waiting",
+				Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+				Resource_id:             application.Application_id,
+				Resource_type:           db.OperationResourceType_Application,
+				State:                   db.OperationState_Waiting,
+				Operation_owner_user_id: clusterAccess.Clusteraccess_user_id,
+				Last_state_update:       time.Now(),
+			}
+			Expect(dbq.CreateOperation(ctx, &waitingOperation, waitingOperation.Operation_owner_user_id)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			dbq.CloseDatabase()
+		})
+
+		It("should only list the operations in the requested state", func() {
+			out := &bytes.Buffer{}
+			Expect(runList(ctx, dbq, "operations", db.OperationState_Waiting, out)).To(Succeed())
+
+			Expect(out.String()).To(ContainSubstring("test-operation-waiting"))
+			Expect(out.String()).ToNot(ContainSubstring("test-operation-completed"))
+		})
+
+		It("should print an Application with its decoded spec field", func() {
+			out := &bytes.Buffer{}
+			Expect(runGet(ctx, dbq, "application", application.Application_id, out)).To(Succeed())
+
+			Expect(out.String()).To(ContainSubstring(application.Application_id))
+			Expect(out.String()).To(ContainSubstring(`"name": "my-application"`))
+		})
+
+		It("should trace a GitOpsDeployment to its Argo CD Application, and back", func() {
+			out := &bytes.Buffer{}
+			Expect(runTrace(ctx, dbq, "gitopsdeployment", "my-namespace/my-gitopsdeployment", out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring(application.Application_id))
+			Expect(out.String()).To(ContainSubstring(application.Name))
+
+			out = &bytes.Buffer{}
+			Expect(runTrace(ctx, dbq, "application", application.Name, out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("my-namespace/my-gitopsdeployment"))
+
+			By("returning an error if the GitOpsDeployment does not exist")
+			Expect(runTrace(ctx, dbq, "gitopsdeployment", "my-namespace/does-not-exist", &bytes.Buffer{})).ToNot(Succeed())
+		})
+
+		It("should reset a completed Operation to Waiting, and recreate its Operation resource", func() {
+			scheme, _, _, _, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+			Expect(requeueOperation(ctx, dbq, k8sClient, operation.Operation_id, &bytes.Buffer{})).To(Succeed())
+
+			By("verifying the Operation row is Waiting")
+			Expect(dbq.GetOperationById(ctx, &operation)).To(Succeed())
+			Expect(operation.State).To(Equal(db.OperationState_Waiting))
+
+			By("verifying the Operation resource was created in the namespace of the GitOps engine instance")
+			operationCR := &managedgitopsv1alpha1.Operation{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{
+				Namespace: gitopsEngineInstance.Namespace_name,
+				Name:      operations.GenerateOperationCRName(operation),
+			}, operationCR)).To(Succeed())
+			Expect(operationCR.Spec.OperationID).To(Equal(operation.Operation_id))
+
+			By("requeueing again, when the Operation resource already exists")
+			Expect(requeueOperation(ctx, dbq, k8sClient, operation.Operation_id, &bytes.Buffer{})).To(Succeed())
+		})
+
+		It("should rotate the master key and data keys, only when a master key is set", func() {
+			Expect(os.Unsetenv(db.MasterKeyEnvVar)).To(Succeed())
+			Expect(runRotate(ctx, dbq, "masterkey", []string{}, &bytes.Buffer{})).ToNot(Succeed())
+
+			Expect(os.Setenv(db.MasterKeyEnvVar, "test-master-key")).To(Succeed())
+			defer func() {
+				Expect(os.Unsetenv(db.MasterKeyEnvVar)).To(Succeed())
+			}()
+
+			out := &bytes.Buffer{}
+			Expect(runRotate(ctx, dbq, "masterkey", []string{}, out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("Rewrapped"))
+
+			out = &bytes.Buffer{}
+			Expect(runRotate(ctx, dbq, "datakey", []string{operation.Operation_owner_user_id}, out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring(operation.Operation_owner_user_id))
+
+			By("returning an error if the arguments are not valid")
+			Expect(runRotate(ctx, dbq, "datakey", []string{}, &bytes.Buffer{})).ToNot(Succeed())
+		})
+	})
+})
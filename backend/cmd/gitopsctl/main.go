@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// gitopsctl is a command line tool for operators of the GitOps Service, to inspect the contents of the GitOps Service
+// database, and to perform common troubleshooting actions, without needing to write SQL queries by hand.
+//
+// The database to connect to is configured using the same environment variables as the GitOps Service
+// (DB_ADDR, DB_PASS, POSTGRESQL_DATABASE). Commands that modify Kubernetes resources use the current kubeconfig context.
+//
+// Usage:
+//
+//	gitopsctl list (applications|operations|managedenvironments) [--state (operation state)]
+//	gitopsctl get (application|operation|managedenvironment) (id)
+//	gitopsctl trace gitopsdeployment (namespace)/(name)
+//	gitopsctl trace application (Argo CD Application name)
+//	gitopsctl requeue operation (id)
+//	gitopsctl rotate masterkey
+//	gitopsctl rotate datakey (cluster user id)
+func main() {
+
+	if len(os.Args) < 3 {
+		printUsageAndExit()
+	}
+
+	flagSet := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	port := flagSet.Int("port", db.DEFAULT_PORT, "port of the Postgres database")
+	verbose := flagSet.Bool("verbose", false, "log all database queries")
+	state := flagSet.String("state", "", "only list operations in this state (Waiting, In_Progress, Completed, Failed)")
+
+	args, err := parseInterspersedFlags(flagSet, os.Args[2:])
+	if err != nil {
+		printUsageAndExit()
+	}
+
+	command, resource := os.Args[1], args[0]
+	args = args[1:]
+
+	dbQueries, err := db.NewUnsafePostgresDBQueriesWithPort(*verbose, false, *port)
+	if err != nil {
+		fmt.Println("Error: unable to connect to database:", err)
+		os.Exit(1)
+	}
+	defer dbQueries.CloseDatabase()
+
+	ctx := context.Background()
+	out := os.Stdout
+
+	switch {
+	case command == "list" && len(args) == 0:
+		err = runList(ctx, dbQueries, resource, db.OperationState(*state), out)
+
+	case command == "get" && len(args) == 1:
+		err = runGet(ctx, dbQueries, resource, args[0], out)
+
+	case command == "trace" && len(args) == 1:
+		err = runTrace(ctx, dbQueries, resource, args[0], out)
+
+	case command == "requeue" && resource == "operation" && len(args) == 1:
+		var k8sClient client.Client
+		if k8sClient, err = getKubernetesClient(); err == nil {
+			err = requeueOperation(ctx, dbQueries, k8sClient, args[0], out)
+		}
+
+	case command == "rotate":
+		err = runRotate(ctx, dbQueries, resource, args, out)
+
+	default:
+		printUsageAndExit()
+	}
+
+	if err != nil {
+		fmt.Println("Error:", err)
+		dbQueries.CloseDatabase()
+		os.Exit(1)
+	}
+}
+
+// parseInterspersedFlags parses the flags in 'args', which may appear before, between, or after the positional
+// arguments, and returns the positional arguments.
+func parseInterspersedFlags(flagSet *flag.FlagSet, args []string) ([]string, error) {
+
+	positionalArgs := []string{}
+
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if flagSet.NArg() == 0 {
+			break
+		}
+
+		positionalArgs = append(positionalArgs, flagSet.Arg(0))
+		args = flagSet.Args()[1:]
+	}
+
+	if len(positionalArgs) == 0 {
+		return nil, fmt.Errorf("missing resource argument")
+	}
+
+	return positionalArgs, nil
+}
+
+// getKubernetesClient returns a client for the cluster of the current kubeconfig context, which is able to
+// read/write Operation resources.
+func getKubernetesClient() (client.Client, error) {
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kubeconfig: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := managedgitopsv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	return client.New(config, client.Options{Scheme: scheme})
+}
+
+func printUsageAndExit() {
+	fmt.Println("Usage:")
+	fmt.Println("  gitopsctl list (applications|operations|managedenvironments) [--state (operation state)]")
+	fmt.Println("  gitopsctl get (application|operation|managedenvironment) (id)")
+	fmt.Println("  gitopsctl trace gitopsdeployment (namespace)/(name)")
+	fmt.Println("  gitopsctl trace application (Argo CD Application name)")
+	fmt.Println("  gitopsctl requeue operation (id)")
+	fmt.Println("  gitopsctl rotate masterkey")
+	fmt.Println("  gitopsctl rotate datakey (cluster user id)")
+	fmt.Println("Flags: [--port (port)] [--verbose]")
+	os.Exit(1)
+}
@@ -25,12 +25,16 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/application_event_loop"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/preprocess_event_loop"
 )
@@ -47,12 +51,13 @@ type GitOpsDeploymentReconciler struct {
 //+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeployments/finalizers,verbs=update
 //+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=operations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *GitOpsDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 
-	_ = log.FromContext(ctx).
+	log := log.FromContext(ctx).
 		WithName(logutil.LogLogger_managed_gitops)
 
 	rClient := sharedutil.IfEnabledSimulateUnreliableClient(r.Client)
@@ -66,7 +71,28 @@ func (r *GitOpsDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	// The database rows of a Namespace that is being offboarded are deleted by the NamespaceReconciler, so events for
+	// its resources are not processed.
+	if sharedutil.IsNamespaceBeingOffboarded(namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	// While in read-only mode, no database rows are created or updated: the request is requeued until it is disabled.
+	readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, rClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := updateGitOpsDeploymentServiceDegradedCondition(ctx, rClient, req, readOnlyModeEnabled); err != nil {
+		return ctrl.Result{}, err
+	}
+	if readOnlyModeEnabled {
+		return readOnlyModeResult(log), nil
+	}
+
+	// The hand-off to the preprocess event loop blocks while the event loop is busy, so it is timed as an external call.
+	stopTiming := sharedutil.TimeReconcileCall(ctx, sharedutil.ReconcileCallExternal)
 	r.PreprocessEventLoop.EventReceived(req, eventlooptypes.GitOpsDeploymentTypeName, rClient, eventlooptypes.DeploymentModified, string(namespace.UID))
+	stopTiming()
 
 	return ctrl.Result{}, nil
 }
@@ -74,7 +100,97 @@ func (r *GitOpsDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 // SetupWithManager sets up the controller with the Manager.
 func (r *GitOpsDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		Named(sharedutil.ControllerNameGitOpsDeployment).
+		// Annotation changes are also processed, as some annotations are copied to the Argo CD Application (for example,
+		// the diff preview request ID)
 		For(&managedgitopsv1alpha1.GitOpsDeployment{},
-			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Complete(r)
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		// The defaults ConfigMap of a namespace is watched, so that GitOpsDeployments that use its values are updated when it changes
+		Watches(
+			&source.Kind{Type: &v1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForDefaultsConfigMap),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isDefaultsConfigMap)),
+		).
+		// GitOpsDeploymentRepositoryCredentials are watched, so that the Argo CD Applications of the GitOpsDeployments in
+		// the scope of a credential are moved into (or out of) the Argo CD project of the credential, when the scope changes.
+		Watches(
+			&source.Kind{Type: &managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForRepositoryCredential),
+		).
+		Complete(sharedutil.NewRequeueMetricsMiddleware(sharedutil.ControllerNameGitOpsDeployment, sharedutil.NewReconcileTimingMiddleware("GitOpsDeployment", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &managedgitopsv1alpha1.GitOpsDeployment{} }, r))))
+}
+
+// isDefaultsConfigMap returns true if the object is the GitOpsDeployment defaults ConfigMap of its namespace.
+func isDefaultsConfigMap(obj client.Object) bool {
+	return obj.GetName() == application_event_loop.GitOpsDeploymentDefaultsConfigMapName
+}
+
+// findObjectsForDefaultsConfigMap maps the defaults ConfigMap of a namespace to all the GitOpsDeployments in that namespace
+func (r *GitOpsDeploymentReconciler) findObjectsForDefaultsConfigMap(configMap client.Object) []reconcile.Request {
+
+	ctx := context.Background()
+	handlerLog := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops)
+
+	if !isDefaultsConfigMap(configMap) {
+		return []reconcile.Request{}
+	}
+
+	gitopsDeplList := managedgitopsv1alpha1.GitOpsDeploymentList{}
+	if err := r.List(ctx, &gitopsDeplList, &client.ListOptions{Namespace: configMap.GetNamespace()}); err != nil {
+		handlerLog.Error(err, "failed to list GitOpsDeployments in the defaults ConfigMap mapping function")
+		return []reconcile.Request{}
+	}
+
+	gitopsDeplRequests := []reconcile.Request{}
+	for i := range gitopsDeplList.Items {
+		gitopsDeplRequests = append(gitopsDeplRequests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&gitopsDeplList.Items[i]),
+		})
+	}
+
+	return gitopsDeplRequests
+}
+
+// findObjectsForRepositoryCredential maps a GitOpsDeploymentRepositoryCredential to all the GitOpsDeployments in the
+// namespace of the credential, and in the namespaces of its scope (if any).
+func (r *GitOpsDeploymentReconciler) findObjectsForRepositoryCredential(obj client.Object) []reconcile.Request {
+
+	ctx := context.Background()
+	handlerLog := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops)
+
+	repoCred, ok := obj.(*managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential)
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	namespaces := []string{repoCred.Namespace}
+	if repoCred.Spec.Scope != nil {
+		namespaces = append(namespaces, repoCred.Spec.Scope.Namespaces...)
+	}
+
+	gitopsDeplRequests := []reconcile.Request{}
+	visited := map[string]bool{}
+	for _, namespace := range namespaces {
+
+		if visited[namespace] {
+			continue
+		}
+		visited[namespace] = true
+
+		gitopsDeplList := managedgitopsv1alpha1.GitOpsDeploymentList{}
+		if err := r.List(ctx, &gitopsDeplList, &client.ListOptions{Namespace: namespace}); err != nil {
+			handlerLog.Error(err, "failed to list GitOpsDeployments in the GitOpsDeploymentRepositoryCredential mapping function", "namespace", namespace)
+			continue
+		}
+
+		for i := range gitopsDeplList.Items {
+			gitopsDeplRequests = append(gitopsDeplRequests, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(&gitopsDeplList.Items[i]),
+			})
+		}
+	}
+
+	return gitopsDeplRequests
 }
@@ -0,0 +1,127 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedgitops
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/application_event_loop"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("GitOpsDeployment Controller Test", func() {
+
+	Context("findObjectsForDefaultsConfigMap", func() {
+
+		var reconciler GitOpsDeploymentReconciler
+
+		BeforeEach(func() {
+			scheme, _, _, _, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			newGitOpsDeployment := func(name string, namespace string) *managedgitopsv1alpha1.GitOpsDeployment {
+				return &managedgitopsv1alpha1.GitOpsDeployment{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				}
+			}
+
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				newGitOpsDeployment("my-gitops-depl", "my-user"),
+				newGitOpsDeployment("my-other-gitops-depl", "my-user"),
+				newGitOpsDeployment("my-gitops-depl", "another-user"),
+			).Build()
+
+			reconciler = GitOpsDeploymentReconciler{Client: k8sClient, Scheme: scheme}
+		})
+
+		It("should return every GitOpsDeployment in the namespace of the defaults ConfigMap", func() {
+			requests := reconciler.findObjectsForDefaultsConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      application_event_loop.GitOpsDeploymentDefaultsConfigMapName,
+					Namespace: "my-user",
+				},
+			})
+
+			Expect(requests).To(HaveLen(2))
+			Expect(requests).To(ContainElements(
+				HaveField("NamespacedName", types.NamespacedName{Namespace: "my-user", Name: "my-gitops-depl"}),
+				HaveField("NamespacedName", types.NamespacedName{Namespace: "my-user", Name: "my-other-gitops-depl"}),
+			))
+		})
+
+		It("should ignore other ConfigMaps", func() {
+			requests := reconciler.findObjectsForDefaultsConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "some-other-configmap",
+					Namespace: "my-user",
+				},
+			})
+
+			Expect(requests).To(BeEmpty())
+		})
+	})
+
+	Context("findObjectsForRepositoryCredential", func() {
+
+		var reconciler GitOpsDeploymentReconciler
+
+		BeforeEach(func() {
+			scheme, _, _, _, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			newGitOpsDeployment := func(name string, namespace string) *managedgitopsv1alpha1.GitOpsDeployment {
+				return &managedgitopsv1alpha1.GitOpsDeployment{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				}
+			}
+
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				newGitOpsDeployment("my-gitops-depl", "my-user"),
+				newGitOpsDeployment("my-gitops-depl", "another-user"),
+				newGitOpsDeployment("my-gitops-depl", "unrelated-user"),
+			).Build()
+
+			reconciler = GitOpsDeploymentReconciler{Client: k8sClient, Scheme: scheme}
+		})
+
+		It("should return every GitOpsDeployment in the namespace of the credential, and in the namespaces of its scope", func() {
+			repoCred := &managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-repo-cred", Namespace: "my-user"},
+			}
+
+			requests := reconciler.findObjectsForRepositoryCredential(repoCred)
+			Expect(requests).To(ConsistOf(
+				HaveField("NamespacedName", types.NamespacedName{Namespace: "my-user", Name: "my-gitops-depl"}),
+			))
+
+			repoCred.Spec.Scope = &managedgitopsv1alpha1.RepositoryCredentialScope{
+				Namespaces: []string{"my-user", "another-user"},
+			}
+
+			requests = reconciler.findObjectsForRepositoryCredential(repoCred)
+			Expect(requests).To(ConsistOf(
+				HaveField("NamespacedName", types.NamespacedName{Namespace: "my-user", Name: "my-gitops-depl"}),
+				HaveField("NamespacedName", types.NamespacedName{Namespace: "another-user", Name: "my-gitops-depl"}),
+			))
+		})
+	})
+})
@@ -0,0 +1,269 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedgitops
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/go-logr/logr"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+)
+
+// GitOpsDeploymentBundleReconciler reconciles a GitOpsDeploymentBundle object, by creating, updating and deleting the
+// GitOpsDeployments of the bundle.
+//
+// The GitOpsDeployments of a bundle are applied all-or-nothing: if any of them is invalid, or has the name of a
+// GitOpsDeployment that is not owned by the bundle, no changes are made and the Applied condition of the bundle is set
+// to False. The GitOpsDeployments are owned by the bundle, and so are deleted along with it.
+type GitOpsDeploymentBundleReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeploymentbundles,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeploymentbundles/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeploymentbundles/finalizers,verbs=update
+
+// Reconcile applies the GitOpsDeployments of the GitOpsDeploymentBundle.
+func (r *GitOpsDeploymentBundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops)
+
+	rClient := sharedutil.IfEnabledSimulateUnreliableClient(r.Client)
+
+	bundle := &managedgitopsv1alpha1.GitOpsDeploymentBundle{}
+	if err := rClient.Get(ctx, req.NamespacedName, bundle); err != nil {
+		if apierr.IsNotFound(err) {
+			// The GitOpsDeployments of a deleted bundle are garbage collected, via their owner reference.
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if bundle.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	namespace := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: req.Namespace,
+		},
+	}
+	if err := rClient.Get(ctx, client.ObjectKeyFromObject(&namespace), &namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The GitOpsDeployments of a Namespace that is being offboarded are not processed, so none are created.
+	if sharedutil.IsNamespaceBeingOffboarded(namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	// The webhook already rejects an invalid bundle, but it may be disabled.
+	if err := bundle.ValidateGitOpsDeploymentBundle(); err != nil {
+		return ctrl.Result{}, updateBundleStatus(ctx, rClient, bundle, nil, metav1.ConditionFalse,
+			managedgitopsv1alpha1.GitOpsDeploymentBundleReasonInvalidSpec, err.Error())
+	}
+
+	gitopsDeplList := managedgitopsv1alpha1.GitOpsDeploymentList{}
+	if err := rClient.List(ctx, &gitopsDeplList, &client.ListOptions{Namespace: bundle.Namespace}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	existingGitOpsDepls := map[string]managedgitopsv1alpha1.GitOpsDeployment{}
+	for _, gitopsDepl := range gitopsDeplList.Items {
+		existingGitOpsDepls[gitopsDepl.Name] = gitopsDepl
+	}
+
+	// Check every GitOpsDeployment of the bundle before any are applied, so that a conflict does not leave the bundle
+	// partially applied.
+	conflicts := []string{}
+	for _, item := range bundle.Spec.Deployments {
+		if existing, exists := existingGitOpsDepls[item.Name]; exists && !metav1.IsControlledBy(&existing, bundle) {
+			conflicts = append(conflicts, item.Name)
+		}
+	}
+	if len(conflicts) > 0 {
+		message := fmt.Sprintf("the GitOpsDeployments '%s' already exist, and are not part of the bundle", strings.Join(conflicts, "', '"))
+		return ctrl.Result{}, updateBundleStatus(ctx, rClient, bundle, nil, metav1.ConditionFalse,
+			managedgitopsv1alpha1.GitOpsDeploymentBundleReasonNameConflict, message)
+	}
+
+	applied := []string{}
+	for _, item := range bundle.Spec.Deployments {
+
+		var existing *managedgitopsv1alpha1.GitOpsDeployment
+		if gitopsDepl, exists := existingGitOpsDepls[item.Name]; exists {
+			existing = &gitopsDepl
+		}
+
+		if err := r.applyBundleItem(ctx, rClient, bundle, item, existing, log); err != nil {
+			if statusErr := updateBundleStatus(ctx, rClient, bundle, applied, metav1.ConditionFalse,
+				managedgitopsv1alpha1.GitOpsDeploymentBundleReasonErrorOccurred,
+				fmt.Sprintf("unable to apply GitOpsDeployment '%s': %v", item.Name, err)); statusErr != nil {
+				log.Error(statusErr, "unable to update status of GitOpsDeploymentBundle")
+			}
+			return ctrl.Result{}, err
+		}
+		applied = append(applied, item.Name)
+	}
+
+	// Delete the GitOpsDeployments that were removed from the bundle
+	inBundle := map[string]bool{}
+	for _, name := range applied {
+		inBundle[name] = true
+	}
+	for idx := range gitopsDeplList.Items {
+		gitopsDepl := gitopsDeplList.Items[idx] // To avoid "Implicit memory aliasing in for loop." error.
+
+		if inBundle[gitopsDepl.Name] || !metav1.IsControlledBy(&gitopsDepl, bundle) || gitopsDepl.DeletionTimestamp != nil {
+			continue
+		}
+
+		if err := rClient.Delete(ctx, &gitopsDepl); err != nil && !apierr.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("unable to delete GitOpsDeployment '%s' that was removed from the bundle: %v", gitopsDepl.Name, err)
+		}
+		logutil.LogAPIResourceChangeEvent(gitopsDepl.Namespace, gitopsDepl.Name, gitopsDepl, logutil.ResourceDeleted, log)
+	}
+
+	sort.Strings(applied)
+
+	return ctrl.Result{}, updateBundleStatus(ctx, rClient, bundle, applied, metav1.ConditionTrue,
+		managedgitopsv1alpha1.GitOpsDeploymentBundleReasonApplied,
+		fmt.Sprintf("%d GitOpsDeployments were applied", len(applied)))
+}
+
+// applyBundleItem creates the GitOpsDeployment of the bundle item, or updates the existing GitOpsDeployment if it differs.
+func (r *GitOpsDeploymentBundleReconciler) applyBundleItem(ctx context.Context, k8sClient client.Client,
+	bundle *managedgitopsv1alpha1.GitOpsDeploymentBundle, item managedgitopsv1alpha1.GitOpsDeploymentBundleItem,
+	existing *managedgitopsv1alpha1.GitOpsDeployment, log logr.Logger) error {
+
+	// The spec is defaulted as it would be by the mutating webhook, so that it can be compared with the existing spec.
+	desired := managedgitopsv1alpha1.GitOpsDeployment{Spec: *item.Spec.DeepCopy()}
+	desired.Default()
+
+	if existing == nil {
+		gitopsDepl := &managedgitopsv1alpha1.GitOpsDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        item.Name,
+				Namespace:   bundle.Namespace,
+				Labels:      item.Labels,
+				Annotations: item.Annotations,
+			},
+			Spec: desired.Spec,
+		}
+		if err := controllerutil.SetControllerReference(bundle, gitopsDepl, r.Scheme); err != nil {
+			return err
+		}
+
+		if err := k8sClient.Create(ctx, gitopsDepl); err != nil {
+			return err
+		}
+		logutil.LogAPIResourceChangeEvent(gitopsDepl.Namespace, gitopsDepl.Name, gitopsDepl, logutil.ResourceCreated, log)
+		return nil
+	}
+
+	gitopsDepl := existing.DeepCopy()
+
+	modified := false
+	if !reflect.DeepEqual(gitopsDepl.Spec, desired.Spec) {
+		gitopsDepl.Spec = desired.Spec
+		modified = true
+	}
+
+	// Labels and annotations of the bundle item are added, without removing those added by other tools.
+	for key, value := range item.Labels {
+		if gitopsDepl.Labels[key] != value {
+			if gitopsDepl.Labels == nil {
+				gitopsDepl.Labels = map[string]string{}
+			}
+			gitopsDepl.Labels[key] = value
+			modified = true
+		}
+	}
+	for key, value := range item.Annotations {
+		if gitopsDepl.Annotations[key] != value {
+			if gitopsDepl.Annotations == nil {
+				gitopsDepl.Annotations = map[string]string{}
+			}
+			gitopsDepl.Annotations[key] = value
+			modified = true
+		}
+	}
+
+	if !modified {
+		return nil
+	}
+
+	if err := k8sClient.Update(ctx, gitopsDepl); err != nil {
+		return err
+	}
+	logutil.LogAPIResourceChangeEvent(gitopsDepl.Namespace, gitopsDepl.Name, gitopsDepl, logutil.ResourceModified, log)
+
+	return nil
+}
+
+// updateBundleStatus sets the Applied condition of the GitOpsDeploymentBundle. The list of applied GitOpsDeployments
+// is only replaced if 'applied' is non-nil.
+func updateBundleStatus(ctx context.Context, k8sClient client.Client, bundle *managedgitopsv1alpha1.GitOpsDeploymentBundle,
+	applied []string, status metav1.ConditionStatus, reason string, message string) error {
+
+	meta.SetStatusCondition(&bundle.Status.Conditions, metav1.Condition{
+		Type:               managedgitopsv1alpha1.GitOpsDeploymentBundleConditionApplied,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: bundle.Generation,
+	})
+	bundle.Status.ObservedGeneration = bundle.Generation
+
+	if applied != nil {
+		bundle.Status.Deployments = applied
+	}
+
+	return k8sClient.Status().Update(ctx, bundle)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GitOpsDeploymentBundleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(sharedutil.ControllerNameGitOpsDeploymentBundle).
+		For(&managedgitopsv1alpha1.GitOpsDeploymentBundle{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Changes to (or deletion of) the GitOpsDeployments of the bundle are reverted
+		Owns(&managedgitopsv1alpha1.GitOpsDeployment{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(sharedutil.NewRequeueMetricsMiddleware(sharedutil.ControllerNameGitOpsDeploymentBundle, sharedutil.NewReconcileTimingMiddleware("GitOpsDeploymentBundle", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &managedgitopsv1alpha1.GitOpsDeploymentBundle{} }, r))))
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedgitops
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("GitOpsDeploymentBundle Controller Test", func() {
+
+	Context("Reconcile", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+		var reconciler GitOpsDeploymentBundleReconciler
+		var bundle *managedgitopsv1alpha1.GitOpsDeploymentBundle
+		var namespace string
+
+		newBundleItem := func(name string, path string) managedgitopsv1alpha1.GitOpsDeploymentBundleItem {
+			return managedgitopsv1alpha1.GitOpsDeploymentBundleItem{
+				Name:   name,
+				Labels: map[string]string{"component": name},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentSpec{
+					Source: managedgitopsv1alpha1.ApplicationSource{
+						RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+						Path:    path,
+					},
+					Type: managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated,
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			scheme, argocdNamespace, kubesystemNamespace, apiNamespace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			namespace = apiNamespace.Name
+
+			bundle = &managedgitopsv1alpha1.GitOpsDeploymentBundle{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "my-bundle",
+					Namespace:  namespace,
+					UID:        "bundle-uid",
+					Generation: 1,
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentBundleSpec{
+					Deployments: []managedgitopsv1alpha1.GitOpsDeploymentBundleItem{
+						newBundleItem("component-a", "environments/overlays/dev"),
+						newBundleItem("component-b", "environments/overlays/staging"),
+					},
+				},
+			}
+
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace, bundle).Build()
+
+			reconciler = GitOpsDeploymentBundleReconciler{Client: k8sClient, Scheme: scheme}
+		})
+
+		reconcileBundle := func() {
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(bundle)})
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(bundle), bundle)).To(Succeed())
+		}
+
+		getGitOpsDeployment := func(name string) (*managedgitopsv1alpha1.GitOpsDeployment, error) {
+			gitopsDepl := &managedgitopsv1alpha1.GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			}
+			return gitopsDepl, k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+		}
+
+		It("should create the GitOpsDeployments of the bundle, owned by the bundle", func() {
+			reconcileBundle()
+
+			for _, item := range bundle.Spec.Deployments {
+				gitopsDepl, err := getGitOpsDeployment(item.Name)
+				Expect(err).To(BeNil())
+				Expect(gitopsDepl.Spec).To(Equal(item.Spec))
+				Expect(gitopsDepl.Labels).To(HaveKeyWithValue("component", item.Name))
+				Expect(metav1.IsControlledBy(gitopsDepl, bundle)).To(BeTrue())
+			}
+
+			condition := meta.FindStatusCondition(bundle.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentBundleConditionApplied)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(bundle.Status.Deployments).To(Equal([]string{"component-a", "component-b"}))
+		})
+
+		It("should update modified GitOpsDeployments, and delete those removed from the bundle", func() {
+			reconcileBundle()
+
+			bundle.Spec.Deployments = []managedgitopsv1alpha1.GitOpsDeploymentBundleItem{
+				newBundleItem("component-a", "environments/overlays/production"),
+			}
+			Expect(k8sClient.Update(ctx, bundle)).To(Succeed())
+
+			reconcileBundle()
+
+			gitopsDepl, err := getGitOpsDeployment("component-a")
+			Expect(err).To(BeNil())
+			Expect(gitopsDepl.Spec.Source.Path).To(Equal("environments/overlays/production"))
+
+			_, err = getGitOpsDeployment("component-b")
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			Expect(bundle.Status.Deployments).To(Equal([]string{"component-a"}))
+		})
+
+		It("should not apply any GitOpsDeployment, if one conflicts with a GitOpsDeployment that is not part of the bundle", func() {
+			existing := &managedgitopsv1alpha1.GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "component-b", Namespace: namespace},
+			}
+			Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+
+			reconcileBundle()
+
+			_, err := getGitOpsDeployment("component-a")
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			condition := meta.FindStatusCondition(bundle.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentBundleConditionApplied)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentBundleReasonNameConflict))
+			Expect(condition.Message).To(ContainSubstring("component-b"))
+		})
+
+		It("should not apply any GitOpsDeployment, if one is invalid", func() {
+			bundle.Spec.Deployments[1].Spec.Type = "sometimes"
+			Expect(k8sClient.Update(ctx, bundle)).To(Succeed())
+
+			reconcileBundle()
+
+			_, err := getGitOpsDeployment("component-a")
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			condition := meta.FindStatusCondition(bundle.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentBundleConditionApplied)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Reason).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentBundleReasonInvalidSpec))
+		})
+	})
+})
@@ -19,6 +19,7 @@ package managedgitops
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,6 +37,9 @@ import (
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/preprocess_event_loop"
 )
 
+// deletionProtectionRequeueInterval is how often a managed environment whose deletion is blocked by deletion protection is re-reconciled.
+const deletionProtectionRequeueInterval = 30 * time.Second
+
 // GitOpsDeploymentManagedEnvironmentReconciler reconciles a GitOpsDeploymentManagedEnvironment object
 type GitOpsDeploymentManagedEnvironmentReconciler struct {
 	client.Client
@@ -54,7 +58,7 @@ type GitOpsDeploymentManagedEnvironmentReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.2/pkg/reconcile
 func (r *GitOpsDeploymentManagedEnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 
-	_ = log.FromContext(ctx).
+	log := log.FromContext(ctx).
 		WithName(logutil.LogLogger_managed_gitops)
 
 	rClient := sharedutil.IfEnabledSimulateUnreliableClient(r.Client)
@@ -71,8 +75,39 @@ func (r *GitOpsDeploymentManagedEnvironmentReconciler) Reconcile(ctx context.Con
 		return ctrl.Result{}, fmt.Errorf("unable to retrieve namespace: %v", err)
 	}
 
+	// The database rows of a Namespace that is being offboarded are deleted by the NamespaceReconciler, so events for
+	// its resources are not processed.
+	if sharedutil.IsNamespaceBeingOffboarded(namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	// While in read-only mode, no database rows are created or updated: the request is requeued until it is disabled.
+	readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, rClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := updateManagedEnvironmentServiceDegradedCondition(ctx, rClient, req, readOnlyModeEnabled); err != nil {
+		return ctrl.Result{}, err
+	}
+	if readOnlyModeEnabled {
+		return readOnlyModeResult(log), nil
+	}
+
 	r.PreprocessEventLoopProcessor.callPreprocessEventLoopForManagedEnvironment(req, rClient, namespace)
 
+	// If deletion of the managed environment is blocked by deletion protection, we requeue so that the GitOpsDeployments
+	// targeting the managed environment are periodically re-checked: the finalizer is removed once there are none left.
+	managedEnv := managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{}
+	if err := rClient.Get(ctx, req.NamespacedName, &managedEnv); err == nil && managedEnv.DeletionTimestamp != nil {
+		for _, finalizer := range managedEnv.Finalizers {
+			if finalizer == managedgitopsv1alpha1.ManagedEnvironmentDeletionProtectionFinalizer {
+				return logutil.RequeueWithReason(log, ctrl.Result{RequeueAfter: deletionProtectionRequeueInterval},
+					logutil.RequeueReasonWaitingForDeletionProtection,
+					"Waiting for the GitOpsDeployments that target the managed environment to be deleted"), nil
+			}
+		}
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -104,5 +139,5 @@ func (r *GitOpsDeploymentManagedEnvironmentReconciler) SetupWithManager(mgr ctrl
 		For(&managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{},
 			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		// Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("GitOpsDeploymentManagedEnvironment", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{} }, r)))
 }
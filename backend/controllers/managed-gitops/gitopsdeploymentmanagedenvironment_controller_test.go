@@ -21,8 +21,10 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -83,6 +85,45 @@ var _ = Describe("GitOpsDeploymentManagedEnvironment Controller Test", func() {
 
 		})
 
+		It("does not process a managed-env while in read-only mode, and sets the ServiceDegraded condition", func() {
+			ctx := context.Background()
+
+			secret := createSecretForManagedEnv("my-secret", true, *namespace, k8sClient)
+			managedEnv := createManagedEnvTargetingSecret("managed-env1", secret, *namespace, k8sClient)
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: managedEnv.Namespace, Name: managedEnv.Name}}
+
+			By("enabling read-only mode")
+			readOnlyModeConfigMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: sharedutil.ReadOnlyModeConfigMapName, Namespace: sharedutil.GetReadOnlyModeNamespace()},
+				Data:       map[string]string{sharedutil.ReadOnlyModeConfigMapEnabledKey: "true"},
+			}
+			Expect(k8sClient.Create(ctx, readOnlyModeConfigMap)).To(Succeed())
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(Equal(sharedutil.ReadOnlyModeRequeueInterval))
+			Expect(mockProcessor.requestsReceived).To(BeEmpty())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+			condition := meta.FindStatusCondition(managedEnv.Status.Conditions, sharedutil.ConditionTypeServiceDegraded)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(sharedutil.ConditionReasonReadOnlyMode))
+
+			By("disabling read-only mode, and ensuring the request is processed")
+			Expect(k8sClient.Delete(ctx, readOnlyModeConfigMap)).To(Succeed())
+
+			result, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(BeZero())
+			Expect(mockProcessor.requestsReceived).To(HaveLen(1))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+			condition = meta.FindStatusCondition(managedEnv.Status.Conditions, sharedutil.ConditionTypeServiceDegraded)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		})
+
 	})
 })
 
@@ -54,7 +54,7 @@ type GitOpsDeploymentRepositoryCredentialReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
 func (r *GitOpsDeploymentRepositoryCredentialReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 
-	_ = log.FromContext(ctx).
+	log := log.FromContext(ctx).
 		WithName(logutil.LogLogger_managed_gitops)
 
 	rClient := sharedutil.IfEnabledSimulateUnreliableClient(r.Client)
@@ -68,8 +68,29 @@ func (r *GitOpsDeploymentRepositoryCredentialReconciler) Reconcile(ctx context.C
 		return ctrl.Result{}, err
 	}
 
+	// The database rows of a Namespace that is being offboarded are deleted by the NamespaceReconciler, so events for
+	// its resources are not processed.
+	if sharedutil.IsNamespaceBeingOffboarded(namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	// While in read-only mode, no database rows are created or updated: the request is requeued until it is disabled.
+	readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, rClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := updateRepositoryCredentialServiceDegradedCondition(ctx, rClient, req, readOnlyModeEnabled); err != nil {
+		return ctrl.Result{}, err
+	}
+	if readOnlyModeEnabled {
+		return readOnlyModeResult(log), nil
+	}
+
+	// The hand-off to the preprocess event loop blocks while the event loop is busy, so it is timed as an external call.
+	stopTiming := sharedutil.TimeReconcileCall(ctx, sharedutil.ReconcileCallExternal)
 	r.PreprocessEventLoop.EventReceived(req, eventlooptypes.GitOpsDeploymentRepositoryCredentialTypeName, rClient,
 		eventlooptypes.RepositoryCredentialModified, string(namespace.UID))
+	stopTiming()
 
 	return ctrl.Result{}, nil
 }
@@ -79,5 +100,5 @@ func (r *GitOpsDeploymentRepositoryCredentialReconciler) SetupWithManager(mgr ct
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{},
 			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("GitOpsDeploymentRepositoryCredential", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{} }, r)))
 }
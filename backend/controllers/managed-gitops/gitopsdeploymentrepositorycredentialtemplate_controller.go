@@ -0,0 +1,101 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedgitops
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
+	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/preprocess_event_loop"
+)
+
+// GitOpsDeploymentRepositoryCredentialTemplateReconciler reconciles a GitOpsDeploymentRepositoryCredentialTemplate object
+type GitOpsDeploymentRepositoryCredentialTemplateReconciler struct {
+	client.Client
+	Scheme              *runtime.Scheme
+	PreprocessEventLoop *preprocess_event_loop.PreprocessEventLoop
+}
+
+//+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeploymentrepositorycredentialtemplates,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeploymentrepositorycredentialtemplates/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=managed-gitops.redhat.com,resources=gitopsdeploymentrepositorycredentialtemplates/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
+func (r *GitOpsDeploymentRepositoryCredentialTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops)
+
+	rClient := sharedutil.IfEnabledSimulateUnreliableClient(r.Client)
+
+	namespace := corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: req.Namespace,
+		},
+	}
+	if err := rClient.Get(ctx, client.ObjectKeyFromObject(&namespace), &namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The database rows of a Namespace that is being offboarded are deleted by the NamespaceReconciler, so events for
+	// its resources are not processed.
+	if sharedutil.IsNamespaceBeingOffboarded(namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	// While in read-only mode, no database rows are created or updated: the request is requeued until it is disabled.
+	readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, rClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if readOnlyModeEnabled {
+		return readOnlyModeResult(log), nil
+	}
+
+	// The hand-off to the preprocess event loop blocks while the event loop is busy, so it is timed as an external call.
+	stopTiming := sharedutil.TimeReconcileCall(ctx, sharedutil.ReconcileCallExternal)
+	r.PreprocessEventLoop.EventReceived(req, eventlooptypes.GitOpsDeploymentRepositoryCredentialTemplateTypeName, rClient,
+		eventlooptypes.RepositoryCredentialTemplateModified, string(namespace.UID))
+	stopTiming()
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GitOpsDeploymentRepositoryCredentialTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(sharedutil.NewReconcileTimingMiddleware("GitOpsDeploymentRepositoryCredentialTemplate", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate{} }, r)))
+}
@@ -50,7 +50,7 @@ type GitOpsDeploymentSyncRunReconciler struct {
 // move the current state of the cluster closer to the desired state.
 func (r *GitOpsDeploymentSyncRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 
-	_ = log.FromContext(ctx).
+	log := log.FromContext(ctx).
 		WithName(logutil.LogLogger_managed_gitops)
 
 	rClient := sharedutil.IfEnabledSimulateUnreliableClient(r.Client)
@@ -64,7 +64,28 @@ func (r *GitOpsDeploymentSyncRunReconciler) Reconcile(ctx context.Context, req c
 		return ctrl.Result{}, err
 	}
 
+	// The database rows of a Namespace that is being offboarded are deleted by the NamespaceReconciler, so events for
+	// its resources are not processed.
+	if sharedutil.IsNamespaceBeingOffboarded(namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	// While in read-only mode, no database rows are created or updated: the request is requeued until it is disabled.
+	readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, rClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := updateSyncRunServiceDegradedCondition(ctx, rClient, req, readOnlyModeEnabled); err != nil {
+		return ctrl.Result{}, err
+	}
+	if readOnlyModeEnabled {
+		return readOnlyModeResult(log), nil
+	}
+
+	// The hand-off to the preprocess event loop blocks while the event loop is busy, so it is timed as an external call.
+	stopTiming := sharedutil.TimeReconcileCall(ctx, sharedutil.ReconcileCallExternal)
 	r.PreprocessEventLoop.EventReceived(req, eventlooptypes.GitOpsDeploymentSyncRunTypeName, rClient, eventlooptypes.SyncRunModified, string(namespace.UID))
+	stopTiming()
 
 	return ctrl.Result{}, nil
 }
@@ -74,5 +95,5 @@ func (r *GitOpsDeploymentSyncRunReconciler) SetupWithManager(mgr ctrl.Manager) e
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&managedgitopsv1alpha1.GitOpsDeploymentSyncRun{},
 			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("GitOpsDeploymentSyncRun", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &managedgitopsv1alpha1.GitOpsDeploymentSyncRun{} }, r)))
 }
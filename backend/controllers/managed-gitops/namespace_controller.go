@@ -0,0 +1,172 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedgitops
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// NamespaceOffboarder deletes all the database rows of a Namespace.
+type NamespaceOffboarder interface {
+	OffboardNamespace(ctx context.Context, namespaceUID string, namespaceName string, log logr.Logger) error
+}
+
+// NamespaceMigrator migrates the Applications of a Namespace to another GitOpsEngineInstance.
+type NamespaceMigrator interface {
+	// MigrateNamespace returns true once the migration is complete, or false if it should be called again later.
+	MigrateNamespace(ctx context.Context, namespaceUID string, namespaceName string, targetGitopsEngineInstanceID string, log logr.Logger) (bool, error)
+}
+
+// namespaceMigrationRequeueInterval is the interval after which a Namespace is requeued, while its migration is in progress.
+const namespaceMigrationRequeueInterval = 30 * time.Second
+
+// NamespaceReconciler offboards a Namespace when it is deleted, or when it is annotated with the offboard annotation.
+// Likewise, it migrates the Applications of a Namespace when it is annotated with the migrate annotation.
+type NamespaceReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	Offboarder NamespaceOffboarder
+	Migrator   NamespaceMigrator
+}
+
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("namespace", req.Name)
+
+	rClient := sharedutil.IfEnabledSimulateUnreliableClient(r.Client)
+
+	namespace := corev1.Namespace{}
+	if err := rClient.Get(ctx, req.NamespacedName, &namespace); err != nil {
+		if apierr.IsNotFound(err) {
+			// The Namespace was deleted before we could process it: it is instead offboarded by the database
+			// reconciler, which cleans up the rows of API resources that no longer exist.
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !isNamespaceToOffboard(&namespace) && !isNamespaceToMigrate(&namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	// While in read-only mode, no database rows are modified: the request is requeued until it is disabled.
+	readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, rClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if readOnlyModeEnabled {
+		return readOnlyModeResult(log), nil
+	}
+
+	if !isNamespaceToOffboard(&namespace) {
+		return r.migrateNamespace(ctx, namespace, log)
+	}
+
+	log.Info("Offboarding Namespace")
+
+	if err := r.Offboarder.OffboardNamespace(ctx, string(namespace.UID), namespace.Name, log); err != nil {
+		log.Error(err, "unable to offboard Namespace")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// migrateNamespace migrates the Applications of the Namespace to the GitOpsEngineInstance of the migrate annotation,
+// requeuing the Namespace until the migration is complete.
+func (r *NamespaceReconciler) migrateNamespace(ctx context.Context, namespace corev1.Namespace, l logr.Logger) (ctrl.Result, error) {
+
+	targetGitopsEngineInstanceID := sharedutil.GetNamespaceMigrationTarget(namespace)
+
+	log := l.WithValues("targetGitopsEngineInstanceID", targetGitopsEngineInstanceID)
+
+	complete, err := r.Migrator.MigrateNamespace(ctx, string(namespace.UID), namespace.Name, targetGitopsEngineInstanceID, log)
+	if err != nil {
+		log.Error(err, "unable to migrate Namespace")
+		return ctrl.Result{}, err
+	}
+
+	if !complete {
+		return logutil.RequeueWithReason(log, ctrl.Result{RequeueAfter: namespaceMigrationRequeueInterval},
+			logutil.RequeueReasonNamespaceMigrationInProgress, "Namespace migration is in progress"), nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// isNamespaceToMigrate returns true if the Namespace has been annotated to migrate its Applications to another
+// GitOpsEngineInstance.
+func isNamespaceToMigrate(obj client.Object) bool {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return false
+	}
+
+	return sharedutil.GetNamespaceMigrationTarget(*namespace) != ""
+}
+
+// isNamespaceToOffboard returns true if the Namespace is being deleted, or has been annotated to be offboarded.
+func isNamespaceToOffboard(obj client.Object) bool {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return false
+	}
+
+	return namespace.DeletionTimestamp != nil || sharedutil.IsNamespaceBeingOffboarded(*namespace)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Namespace{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return isNamespaceToOffboard(e.Object) || isNamespaceToMigrate(e.Object)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return isNamespaceToOffboard(e.ObjectNew) || isNamespaceToMigrate(e.ObjectNew)
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return isNamespaceToOffboard(e.Object) || isNamespaceToMigrate(e.Object)
+			},
+		}).
+		Complete(sharedutil.NewReconcileTimingMiddleware("Namespace", r))
+}
@@ -0,0 +1,166 @@
+package managedgitops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/backend/condition"
+)
+
+// While the GitOps Service is in read-only mode (see sharedutil.IsReadOnlyModeEnabled), the controllers do not pass
+// events to the preprocess event loop, so no database rows (or Argo CD resources) are created or updated. Instead,
+// the ServiceDegraded condition is set on the resource, and the request is requeued until read-only mode is disabled.
+//
+// The status of GitOpsDeployments is updated as usual, by the application event loop.
+
+// readOnlyModeResult is the result of a request that was not processed, as the GitOps Service is in read-only mode.
+func readOnlyModeResult(log logr.Logger) ctrl.Result {
+	return logutil.RequeueWithReason(log, ctrl.Result{RequeueAfter: sharedutil.ReadOnlyModeRequeueInterval},
+		logutil.RequeueReasonReadOnlyMode, "GitOps Service is in read-only mode: the request will be processed once read-only mode is disabled")
+}
+
+// updateGitOpsDeploymentServiceDegradedCondition sets the ServiceDegraded condition of the GitOpsDeployment (if it
+// exists) to True while in read-only mode, or to False once read-only mode is disabled (if the condition was set).
+func updateGitOpsDeploymentServiceDegradedCondition(ctx context.Context, k8sClient client.Client, req ctrl.Request, readOnlyModeEnabled bool) error {
+
+	gitopsDeployment := &managedgitopsv1alpha1.GitOpsDeployment{}
+	if err := k8sClient.Get(ctx, req.NamespacedName, gitopsDeployment); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve GitOpsDeployment: %v", err)
+	}
+
+	status, reason, message := managedgitopsv1alpha1.GitOpsConditionStatusTrue, managedgitopsv1alpha1.GitopsDeploymentReasonReadOnlyMode,
+		sharedutil.ReadOnlyModeConditionMessage
+	if !readOnlyModeEnabled {
+		status, reason, message = managedgitopsv1alpha1.GitOpsConditionStatusFalse, managedgitopsv1alpha1.GitopsDeploymentReasonReadOnlyModeDisabled, ""
+	}
+
+	conditionManager := condition.NewConditionManager()
+	if conditionManager.HasCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionServiceDegraded) {
+		if existing, _ := conditionManager.FindCondition(&gitopsDeployment.Status.Conditions,
+			managedgitopsv1alpha1.GitOpsDeploymentConditionServiceDegraded); existing.Status == status && existing.Reason == reason {
+			return nil
+		}
+	} else if !readOnlyModeEnabled {
+		return nil
+	}
+
+	conditionManager.SetCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionServiceDegraded,
+		status, reason, message)
+
+	if err := k8sClient.Status().Update(ctx, gitopsDeployment); err != nil {
+		return fmt.Errorf("unable to update ServiceDegraded condition of GitOpsDeployment: %v", err)
+	}
+
+	return nil
+}
+
+// updateSyncRunServiceDegradedCondition sets the ServiceDegraded condition of the GitOpsDeploymentSyncRun (if it
+// exists) to True while in read-only mode, or to False once read-only mode is disabled (if the condition was set).
+func updateSyncRunServiceDegradedCondition(ctx context.Context, k8sClient client.Client, req ctrl.Request, readOnlyModeEnabled bool) error {
+
+	syncRun := &managedgitopsv1alpha1.GitOpsDeploymentSyncRun{}
+	if err := k8sClient.Get(ctx, req.NamespacedName, syncRun); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve GitOpsDeploymentSyncRun: %v", err)
+	}
+
+	desired := managedgitopsv1alpha1.GitOpsDeploymentSyncRunCondition{
+		Type:    managedgitopsv1alpha1.GitOpsDeploymentSyncRunConditionServiceDegraded,
+		Status:  managedgitopsv1alpha1.GitOpsConditionStatusTrue,
+		Reason:  managedgitopsv1alpha1.SyncRunReasonReadOnlyMode,
+		Message: sharedutil.ReadOnlyModeConditionMessage,
+	}
+	if !readOnlyModeEnabled {
+		desired.Status = managedgitopsv1alpha1.GitOpsConditionStatusFalse
+		desired.Reason = managedgitopsv1alpha1.SyncRunReasonReadOnlyModeDisabled
+		desired.Message = ""
+	}
+
+	var existing *managedgitopsv1alpha1.GitOpsDeploymentSyncRunCondition
+	for i := range syncRun.Status.Conditions {
+		if syncRun.Status.Conditions[i].Type == desired.Type {
+			existing = &syncRun.Status.Conditions[i]
+			break
+		}
+	}
+
+	if existing == nil {
+		if !readOnlyModeEnabled {
+			return nil
+		}
+		syncRun.Status.Conditions = append(syncRun.Status.Conditions, desired)
+		existing = &syncRun.Status.Conditions[len(syncRun.Status.Conditions)-1]
+
+	} else if existing.Status == desired.Status && existing.Reason == desired.Reason {
+		return nil
+	}
+
+	now := metav1.Now()
+	existing.Status, existing.Reason, existing.Message, existing.LastTransitionTime = desired.Status, desired.Reason, desired.Message, &now
+
+	if err := k8sClient.Status().Update(ctx, syncRun); err != nil {
+		return fmt.Errorf("unable to update ServiceDegraded condition of GitOpsDeploymentSyncRun: %v", err)
+	}
+
+	return nil
+}
+
+// updateManagedEnvironmentServiceDegradedCondition sets the ServiceDegraded condition of the
+// GitOpsDeploymentManagedEnvironment (if it exists), as above.
+func updateManagedEnvironmentServiceDegradedCondition(ctx context.Context, k8sClient client.Client, req ctrl.Request, readOnlyModeEnabled bool) error {
+
+	managedEnv := &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{}
+	if err := k8sClient.Get(ctx, req.NamespacedName, managedEnv); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve GitOpsDeploymentManagedEnvironment: %v", err)
+	}
+
+	if !sharedutil.SetServiceDegradedCondition(&managedEnv.Status.Conditions, readOnlyModeEnabled) {
+		return nil
+	}
+
+	if err := k8sClient.Status().Update(ctx, managedEnv); err != nil {
+		return fmt.Errorf("unable to update ServiceDegraded condition of GitOpsDeploymentManagedEnvironment: %v", err)
+	}
+
+	return nil
+}
+
+// updateRepositoryCredentialServiceDegradedCondition sets the ServiceDegraded condition of the
+// GitOpsDeploymentRepositoryCredential (if it exists), as above.
+func updateRepositoryCredentialServiceDegradedCondition(ctx context.Context, k8sClient client.Client, req ctrl.Request, readOnlyModeEnabled bool) error {
+
+	repositoryCredential := &managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{}
+	if err := k8sClient.Get(ctx, req.NamespacedName, repositoryCredential); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve GitOpsDeploymentRepositoryCredential: %v", err)
+	}
+
+	if !sharedutil.SetServiceDegradedCondition(&repositoryCredential.Status.Conditions, readOnlyModeEnabled) {
+		return nil
+	}
+
+	if err := k8sClient.Status().Update(ctx, repositoryCredential); err != nil {
+		return fmt.Errorf("unable to update ServiceDegraded condition of GitOpsDeploymentRepositoryCredential: %v", err)
+	}
+
+	return nil
+}
@@ -57,7 +57,7 @@ func (r *SecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, nil
 	}
 
-	_ = log.FromContext(ctx).
+	log := log.FromContext(ctx).
 		WithName(logutil.LogLogger_managed_gitops)
 
 	rClient := sharedutil.IfEnabledSimulateUnreliableClient(r.Client)
@@ -103,6 +103,16 @@ func (r *SecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 	// 2) If Secret is referenced by any ManagedEnvs, process those ManagedEnvs
 	if len(managedEnvsFound) > 0 {
+
+		// While in read-only mode, no database rows are created or updated: the request is requeued until it is disabled.
+		readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, rClient)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if readOnlyModeEnabled {
+			return readOnlyModeResult(log), nil
+		}
+
 		namespace := corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: req.Namespace,
@@ -151,7 +161,7 @@ func (r *SecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Secret{}).
 		WithEventFilter(filterManagedEnvSecrets()).
-		Complete(r)
+		Complete(sharedutil.NewReconcileTimingMiddleware("Secret", logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &corev1.Secret{} }, r)))
 }
 
 func filterManagedEnvSecrets() predicate.Predicate {
@@ -9,6 +9,7 @@ import (
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -37,6 +38,10 @@ const (
 	// disableDeploymentStatusTickLogging disables logging of events related to the deployment status.
 	// - These events tend to be noisy, and this will help reduce this noise when debugging.
 	disableDeploymentStatusTickLogging = false
+
+	// eventCoalescingThreshold is the number of events waiting for a runner, at or above which new events are coalesced
+	// into equivalent waiting events: see queueEvent.
+	eventCoalescingThreshold = 5
 )
 
 // RequestMessage is a message sent to the Application Event Loop by the Workspace Event loop.
@@ -81,6 +86,10 @@ type ApplicationEventQueueLoop struct {
 
 	// Client is a K8s client for accessing GitOps service resources
 	Client client.Client
+
+	// DirectMode is true if the Argo CD Applications of the GitOpsDeployment are applied by the backend, rather than by
+	// the cluster-agent (see the description of direct mode in the operations package).
+	DirectMode bool
 }
 
 // StartApplicationEventQueueLoop will start the Application Event Loop for the GitOpsDeployment referenced
@@ -94,7 +103,7 @@ func StartApplicationEventQueueLoop(ctx context.Context, aeqlParam ApplicationEv
 		aeqlParam.GitopsDeploymentNamespace,
 		aeqlParam.WorkspaceID,
 		aeqlParam.SharedResourceEventLoop,
-		defaultApplicationEventRunnerFactory{}, // use the default factory
+		defaultApplicationEventRunnerFactory{directMode: aeqlParam.DirectMode}, // use the default factory
 	)
 }
 
@@ -150,6 +159,11 @@ func applicationEventQueueLoop(ctx context.Context, k8sClient client.Client,
 		gitopsDeploymentNamespace, workspaceID, "sync-operation")
 	syncOperationEventRunnerShutdown := false
 
+	// Events that are still waiting when the loop ends are never processed, so they no longer count as waiting
+	defer func() {
+		metrics.AddApplicationEventsWaiting(-(len(waitingDeploymentEvents) + len(waitingSyncOperationEvents)))
+	}()
+
 	// Start the ticker, which will -- every X seconds -- instruct the GitOpsDeployment CR fields to update
 	startNewStatusUpdateTimer(ctx, k8sClient, input, log)
 
@@ -206,7 +220,7 @@ out:
 			if eventLoopMessage.ReqResource == eventlooptypes.GitOpsDeploymentTypeName {
 
 				if !deploymentEventRunnerShutdown {
-					waitingDeploymentEvents = append(waitingDeploymentEvents, &newEvent)
+					waitingDeploymentEvents = queueEvent(waitingDeploymentEvents, &newEvent, log)
 				} else {
 					log.V(logutil.LogLevel_Debug).Info("Ignoring post-shutdown deployment event")
 				}
@@ -214,14 +228,14 @@ out:
 			} else if eventLoopMessage.ReqResource == eventlooptypes.GitOpsDeploymentSyncRunTypeName {
 
 				if !syncOperationEventRunnerShutdown {
-					waitingSyncOperationEvents = append(waitingSyncOperationEvents, &newEvent)
+					waitingSyncOperationEvents = queueEvent(waitingSyncOperationEvents, &newEvent, log)
 				} else {
 					log.V(logutil.LogLevel_Debug).Info("Ignoring post-shutdown sync operation event")
 				}
 			} else if eventLoopMessage.ReqResource == eventlooptypes.GitOpsDeploymentManagedEnvironmentTypeName {
 
 				if !deploymentEventRunnerShutdown {
-					waitingDeploymentEvents = append(waitingDeploymentEvents, &newEvent)
+					waitingDeploymentEvents = queueEvent(waitingDeploymentEvents, &newEvent, log)
 				} else {
 					log.V(logutil.LogLevel_Debug).Info("Ignoring post-shutdown managed environment event")
 				}
@@ -229,7 +243,7 @@ out:
 			} else if eventLoopMessage.EventType == eventlooptypes.UpdateDeploymentStatusTick {
 
 				if !deploymentEventRunnerShutdown {
-					waitingDeploymentEvents = append(waitingDeploymentEvents, &newEvent)
+					waitingDeploymentEvents = queueEvent(waitingDeploymentEvents, &newEvent, log)
 				} else {
 					log.V(logutil.LogLevel_Debug).Info("Ignoring post-shutdown deployment event")
 				}
@@ -322,6 +336,7 @@ out:
 
 			activeDeploymentEvent = waitingDeploymentEvents[0]
 			waitingDeploymentEvents = waitingDeploymentEvents[1:]
+			metrics.AddApplicationEventsWaiting(-1)
 
 			// Send the work to the runner
 			if !(activeDeploymentEvent.Message.Event.EventType == eventlooptypes.UpdateDeploymentStatusTick &&
@@ -346,6 +361,7 @@ out:
 
 			activeSyncOperationEvent = waitingSyncOperationEvents[0]
 			waitingSyncOperationEvents = waitingSyncOperationEvents[1:]
+			metrics.AddApplicationEventsWaiting(-1)
 
 			// Send the work to the runner
 			syncOperationEventRunner <- activeSyncOperationEvent.Message.Event
@@ -367,6 +383,37 @@ out:
 	}
 }
 
+// queueEvent appends the new event to the events that are waiting for a runner, and returns the result.
+//
+// A misbehaving client that updates a resource in a loop could otherwise queue events faster than they can be processed.
+// So, once eventCoalescingThreshold events are waiting, the new event is instead dropped if an equivalent event (of the
+// same type, for the same resource) is already waiting: the runners reconcile the current state of the resource, rather
+// than the change that caused the event, so the waiting event will also handle the change of the new event.
+func queueEvent(waitingEvents []*RequestMessage, newEvent *RequestMessage, log logr.Logger) []*RequestMessage {
+
+	if len(waitingEvents) >= eventCoalescingThreshold {
+		for _, waitingEvent := range waitingEvents {
+			if isEquivalentEvent(waitingEvent.Message.Event, newEvent.Message.Event) {
+				log.V(logutil.LogLevel_Debug).Info("Coalesced event into an equivalent waiting event", "waitingEvents", len(waitingEvents))
+				metrics.IncreaseApplicationEventsCoalesced(string(newEvent.Message.Event.EventType))
+				return waitingEvents
+			}
+		}
+	}
+
+	metrics.AddApplicationEventsWaiting(1)
+	return append(waitingEvents, newEvent)
+}
+
+// isEquivalentEvent returns true if both events are of the same type, and for the same resource
+func isEquivalentEvent(a *eventlooptypes.EventLoopEvent, b *eventlooptypes.EventLoopEvent) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	return a.EventType == b.EventType && a.ReqResource == b.ReqResource && a.Request == b.Request && a.WorkspaceID == b.WorkspaceID
+}
+
 // startNewStatusUpdateTimer will send a timer tick message to the application event loop in X seconds.
 // This tick informs the runner that it needs to update the status field of the Deployment.
 func startNewStatusUpdateTimer(ctx context.Context, k8sClient client.Client, input chan RequestMessage,
@@ -406,15 +453,17 @@ type applicationEventRunnerFactory interface {
 }
 
 type defaultApplicationEventRunnerFactory struct {
+	// directMode is passed to the application event runners that are started: see ApplicationEventQueueLoop
+	directMode bool
 }
 
 var _ applicationEventRunnerFactory = defaultApplicationEventRunnerFactory{}
 
 // createNewApplicationEventLoopRunner is a simple wrapper around the default function.
-func (defaultApplicationEventRunnerFactory) createNewApplicationEventLoopRunner(informWorkCompleteChan chan RequestMessage,
+func (d defaultApplicationEventRunnerFactory) createNewApplicationEventLoopRunner(informWorkCompleteChan chan RequestMessage,
 	sharedResourceEventLoop *shared_resource_loop.SharedResourceEventLoop,
 	gitopsDeplName string, gitopsDeplNamespace string, workspaceID string, debugContext string) chan *eventlooptypes.EventLoopEvent {
 
 	return startNewApplicationEventLoopRunner(informWorkCompleteChan, sharedResourceEventLoop, gitopsDeplName, gitopsDeplNamespace,
-		workspaceID, debugContext)
+		workspaceID, debugContext, d.directMode)
 }
@@ -7,9 +7,12 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -170,6 +173,50 @@ var _ = Describe("ApplicationEventLoop Test", func() {
 		})
 	})
 
+	Context("Coalescing of waiting events", func() {
+
+		newDeploymentEvent := func(name string) *RequestMessage {
+			return &RequestMessage{
+				Message: eventlooptypes.EventLoopMessage{
+					MessageType: eventlooptypes.ApplicationEventLoopMessageType_Event,
+					Event: &eventlooptypes.EventLoopEvent{
+						EventType:   eventlooptypes.DeploymentModified,
+						Request:     reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "my-namespace", Name: name}},
+						ReqResource: eventlooptypes.GitOpsDeploymentTypeName,
+						WorkspaceID: "my-workspace-id",
+					},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			metrics.ClearMetrics()
+		})
+
+		It("should only coalesce equivalent events, once the number of waiting events reaches the threshold", func() {
+			log := log.FromContext(context.Background())
+
+			waitingEvents := []*RequestMessage{}
+
+			By("queuing equivalent events, below the threshold")
+			for i := 0; i < eventCoalescingThreshold; i++ {
+				waitingEvents = queueEvent(waitingEvents, newDeploymentEvent("my-deployment"), log)
+			}
+			Expect(waitingEvents).To(HaveLen(eventCoalescingThreshold))
+
+			By("queuing an equivalent event, at the threshold")
+			waitingEvents = queueEvent(waitingEvents, newDeploymentEvent("my-deployment"), log)
+			Expect(waitingEvents).To(HaveLen(eventCoalescingThreshold))
+			Expect(testutil.ToFloat64(metrics.ApplicationEventsCoalesced.WithLabelValues(string(eventlooptypes.DeploymentModified)))).To(Equal(float64(1)))
+
+			By("queuing an event for another resource, at the threshold")
+			waitingEvents = queueEvent(waitingEvents, newDeploymentEvent("my-other-deployment"), log)
+			Expect(waitingEvents).To(HaveLen(eventCoalescingThreshold + 1))
+
+			Expect(testutil.ToFloat64(metrics.ApplicationEventsWaiting)).To(Equal(float64(eventCoalescingThreshold + 1)))
+		})
+	})
+
 })
 
 // mockApplicationEventLoopRunnerFactory which returns a pre-provided channel, rather than starting a new goroutine.
@@ -13,6 +13,7 @@ import (
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
 	corev1 "k8s.io/api/core/v1"
@@ -61,15 +62,31 @@ import (
 // For more information on how events are distributed between goroutines by event loop, see:
 // https://miro.com/app/board/o9J_lgiqJAs=/?moveToWidget=3458764514216218600&cot=14
 
+// runnerWorkTracker tracks the events that application event runners are processing, so that they may complete before
+// the backend exits: see DrainApplicationEventRunners.
+var runnerWorkTracker = sharedutil.NewWorkTracker()
+
+// DrainApplicationEventRunners is called on shutdown of the backend: application event runners stop processing new events
+// (and stop retrying failed events), and the events that are being processed are given until the context is cancelled
+// to complete. The events that were not processed are generated again by the controllers of the next backend replica.
+func DrainApplicationEventRunners(ctx context.Context) error {
+
+	if inFlight, err := runnerWorkTracker.Drain(ctx); err != nil {
+		return fmt.Errorf("%d events were still being processed by application event runners: %w", inFlight, err)
+	}
+
+	return nil
+}
+
 func startNewApplicationEventLoopRunner(informWorkCompleteChan chan RequestMessage,
 	sharedResourceEventLoop *shared_resource_loop.SharedResourceEventLoop,
-	gitopsDeplName string, gitopsDeplNamespace, workspaceID string, debugContext string) chan *eventlooptypes.EventLoopEvent {
+	gitopsDeplName string, gitopsDeplNamespace, workspaceID string, debugContext string, directMode bool) chan *eventlooptypes.EventLoopEvent {
 
 	inputChannel := make(chan *eventlooptypes.EventLoopEvent)
 
 	go func() {
 		applicationEventLoopRunner(inputChannel, informWorkCompleteChan, sharedResourceEventLoop, gitopsDeplName, gitopsDeplNamespace,
-			workspaceID, debugContext)
+			workspaceID, debugContext, directMode)
 	}()
 
 	return inputChannel
@@ -79,7 +96,7 @@ func startNewApplicationEventLoopRunner(informWorkCompleteChan chan RequestMessa
 func applicationEventLoopRunner(inputChannel chan *eventlooptypes.EventLoopEvent,
 	informWorkCompleteChan chan RequestMessage,
 	sharedResourceEventLoop *shared_resource_loop.SharedResourceEventLoop, gitopsDeploymentName string,
-	gitopsDeploymentNamespace string, namespaceID string, debugContext string) {
+	gitopsDeploymentNamespace string, namespaceID string, debugContext string, directMode bool) {
 
 	outerContext := context.Background()
 	log := log.FromContext(outerContext).
@@ -120,6 +137,12 @@ func applicationEventLoopRunner(inputChannel chan *eventlooptypes.EventLoopEvent
 			default:
 			}
 
+			// Break if the backend is shutting down: the event is processed by the next backend replica
+			if !runnerWorkTracker.Begin() {
+				log.Info("Skipping event, as the backend is shutting down", "event", eventlooptypes.StringEventLoopEvent(newEvent))
+				break inner_for
+			}
+
 			_, err := sharedutil.CatchPanic(func() error {
 
 				action := applicationEventLoopRunner_Action{
@@ -130,6 +153,7 @@ func applicationEventLoopRunner(inputChannel chan *eventlooptypes.EventLoopEvent
 					log:                     log,
 					workspaceID:             namespaceID,
 					k8sClientFactory:        shared_resource_loop.DefaultK8sClientFactory{},
+					directMode:              directMode,
 				}
 
 				var err error
@@ -179,6 +203,8 @@ func applicationEventLoopRunner(inputChannel chan *eventlooptypes.EventLoopEvent
 
 			})
 
+			runnerWorkTracker.End()
+
 			if err == nil {
 				break inner_for
 			} else {
@@ -262,7 +288,13 @@ func handleManagedEnvironmentModified_shouldInformGitOpsDeployment(ctx context.C
 		if len(managedEnvironmentDBIDs) > 0 {
 			dtams := []db.DeploymentToApplicationMapping{}
 
-			if err := dbQueries.ListDeploymentToApplicationMappingByNamespaceAndName(ctx,
+			// Scope the database queries to the Applications of the namespace: the Applications of other namespaces are not accessible.
+			tenantDBQueries, err := db.NewTenantScopedQueries(dbQueries, db.Tenant{NamespaceUID: string(gitopsDeplNamespace.UID)})
+			if err != nil {
+				return false, err
+			}
+
+			if err := tenantDBQueries.ListDeploymentToApplicationMappingByNamespaceAndName(ctx,
 				gitopsDeployment.Name, managedEnvEvent.Request.Namespace, string(gitopsDeplNamespace.UID), &dtams); err != nil {
 				return false, fmt.Errorf("unable to list DeploymentToApplicationMappings for namespace '%s': %v",
 					managedEnvEvent.Request.Namespace, managedEnvEvent.Request.Name)
@@ -274,7 +306,7 @@ func handleManagedEnvironmentModified_shouldInformGitOpsDeployment(ctx context.C
 					Application_id: deplToAppMapping.Application_id,
 				}
 
-				if err := dbQueries.GetApplicationById(ctx, &appl); err != nil {
+				if err := tenantDBQueries.GetApplicationById(ctx, &appl); err != nil {
 					if db.IsResultNotFoundError(err) {
 						continue
 					} else {
@@ -347,6 +379,7 @@ func handleManagedEnvironmentModified(ctx context.Context, expectedResourceName
 			log:                     action.log,
 			workspaceID:             action.workspaceID,
 			k8sClientFactory:        shared_resource_loop.DefaultK8sClientFactory{},
+			directMode:              action.directMode,
 		}
 
 		signalledShutdown, err := handleDeploymentModified(ctx, newEvent, newAction, dbQueries, log)
@@ -382,9 +415,15 @@ func handleDeploymentModified(ctx context.Context, newEvent *eventlooptypes.Even
 	conditionManager := condition.NewConditionManager()
 	adapter := newGitOpsDeploymentAdapter(gitopsDepl, log, newEvent.Client, conditionManager, ctx)
 
-	// Plug any conditions based on the "err" msg
+	// Plug any conditions based on the "err" msg: errors that have a more specific reason (for example, when no Argo CD
+	// instance has the capacity to deploy the GitOpsDeployment) report it in the condition.
+	reason := managedgitopsv1alpha1.GitopsDeploymentReasonErrorOccurred
+	if conditionError, ok := err.(gitopserrors.ConditionError); ok && conditionError.ConditionReason() != "" {
+		reason = managedgitopsv1alpha1.GitOpsDeploymentReasonType(conditionError.ConditionReason())
+	}
+
 	if setConditionError := adapter.setGitOpsDeploymentCondition(managedgitopsv1alpha1.GitOpsDeploymentConditionErrorOccurred,
-		managedgitopsv1alpha1.GitopsDeploymentReasonErrorOccurred, err); setConditionError != nil {
+		reason, err); setConditionError != nil {
 		return false, setConditionError
 	}
 
@@ -426,4 +465,8 @@ type applicationEventLoopRunner_Action struct {
 
 	// k8sClientFactory enabled the creation of K8s API clients to target various environments
 	k8sClientFactory shared_resource_loop.SRLK8sClientFactory
+
+	// directMode is true if the Argo CD Applications of Application rows are applied by the backend, rather than by the
+	// cluster-agent: see operations.CreateOperationWithDirectMode
+	directMode bool
 }
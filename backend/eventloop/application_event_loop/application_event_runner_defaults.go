@@ -0,0 +1,161 @@
+package application_event_loop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GitOpsDeployment defaults:
+//
+// A platform team may create a ConfigMap named 'gitopsdeployment-defaults' in a namespace, to supply default values
+// for the GitOpsDeployments of that namespace that do not specify them. For example:
+//
+//	apiVersion: v1
+//	kind: ConfigMap
+//	metadata:
+//	  name: gitopsdeployment-defaults
+//	data:
+//	  type: automated                          # used if .spec.type is empty
+//	  syncOptions: CreateNamespace=true        # used if .spec.syncPolicy is not set, or only sets .automated (comma-separated)
+//	  destination.environment: my-managed-env  # used if .spec.destination is empty
+//	  destination.namespace: my-namespace      # used if .spec.destination is empty
+//	  labels: |                                # added to the GitOpsDeployment, for labels it does not already have
+//	    team: my-team
+//
+// The spec defaults are applied by the backend when processing the GitOpsDeployment: they are not written back
+// to the GitOpsDeployment resource. The labels are added to the GitOpsDeployment resource.
+const (
+	GitOpsDeploymentDefaultsConfigMapName = "gitopsdeployment-defaults"
+
+	GitOpsDeploymentDefaultsKeyType                   = "type"
+	GitOpsDeploymentDefaultsKeySyncOptions            = "syncOptions"
+	GitOpsDeploymentDefaultsKeyDestinationEnvironment = "destination.environment"
+	GitOpsDeploymentDefaultsKeyDestinationNamespace   = "destination.namespace"
+	GitOpsDeploymentDefaultsKeyLabels                 = "labels"
+)
+
+// gitOpsDeploymentDefaults are the default values read from the GitOpsDeployment defaults ConfigMap of a namespace.
+type gitOpsDeploymentDefaults struct {
+	deploymentType string
+	syncOptions    managedgitopsv1alpha1.SyncOptions
+	destination    managedgitopsv1alpha1.ApplicationDestination
+	labels         map[string]string
+}
+
+// getGitOpsDeploymentDefaults reads the GitOpsDeployment defaults ConfigMap from the namespace.
+// - Returns empty defaults if the ConfigMap doesn't exist.
+// - Returns a user error if the ConfigMap contains invalid values.
+func getGitOpsDeploymentDefaults(ctx context.Context, k8sClient client.Client, namespace string) (gitOpsDeploymentDefaults, gitopserrors.UserError) {
+
+	res := gitOpsDeploymentDefaults{}
+
+	configMap := &corev1.ConfigMap{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: GitOpsDeploymentDefaultsConfigMapName}, configMap); err != nil {
+		if apierr.IsNotFound(err) {
+			return res, nil
+		}
+		userError := fmt.Sprintf("unable to retrieve the '%s' ConfigMap of the namespace, due to an unknown error", GitOpsDeploymentDefaultsConfigMapName)
+		return res, gitopserrors.NewUserDevError(userError, err)
+	}
+
+	invalidValueErr := func(key string, reason string) gitopserrors.UserError {
+		userError := fmt.Sprintf("the '%s' value of the '%s' ConfigMap is invalid: %s", key, GitOpsDeploymentDefaultsConfigMapName, reason)
+		return gitopserrors.NewUserDevError(userError, fmt.Errorf(userError))
+	}
+
+	if deploymentType := strings.TrimSpace(configMap.Data[GitOpsDeploymentDefaultsKeyType]); deploymentType != "" {
+		if deploymentType != managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated &&
+			deploymentType != managedgitopsv1alpha1.GitOpsDeploymentSpecType_Manual {
+			return res, invalidValueErr(GitOpsDeploymentDefaultsKeyType, "type must be manual or automated")
+		}
+		res.deploymentType = deploymentType
+	}
+
+	for _, syncOption := range strings.Split(configMap.Data[GitOpsDeploymentDefaultsKeySyncOptions], ",") {
+		if syncOption = strings.TrimSpace(syncOption); syncOption != "" {
+			res.syncOptions = append(res.syncOptions, managedgitopsv1alpha1.SyncOption(syncOption))
+		}
+	}
+	if userErr := checkValidSyncOption(res.syncOptions); userErr != nil {
+		return res, invalidValueErr(GitOpsDeploymentDefaultsKeySyncOptions, userErr.UserError())
+	}
+
+	res.destination.Environment = strings.TrimSpace(configMap.Data[GitOpsDeploymentDefaultsKeyDestinationEnvironment])
+	res.destination.Namespace = strings.TrimSpace(configMap.Data[GitOpsDeploymentDefaultsKeyDestinationNamespace])
+
+	if labels := configMap.Data[GitOpsDeploymentDefaultsKeyLabels]; strings.TrimSpace(labels) != "" {
+		if err := yaml.Unmarshal([]byte(labels), &res.labels); err != nil {
+			return res, invalidValueErr(GitOpsDeploymentDefaultsKeyLabels, "labels must be a YAML map of label keys to values")
+		}
+	}
+
+	return res, nil
+}
+
+// applyDefaultsToSpec sets the fields of the GitOpsDeployment spec that are not specified, to their default values.
+// If the type of the GitOpsDeployment is not specified (and there is no default), the GitOpsDeployment is Automated if
+// it specifies .spec.syncPolicy.automated, otherwise it is Manual.
+func (defaults gitOpsDeploymentDefaults) applyDefaultsToSpec(gitopsDepl *managedgitopsv1alpha1.GitOpsDeployment) {
+
+	if gitopsDepl.Spec.Type == "" {
+		gitopsDepl.Spec.Type = defaults.deploymentType
+		if gitopsDepl.Spec.Type == "" {
+			if gitopsDepl.Spec.SyncPolicy != nil && gitopsDepl.Spec.SyncPolicy.Automated != nil {
+				gitopsDepl.Spec.Type = managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated
+			} else {
+				gitopsDepl.Spec.Type = managedgitopsv1alpha1.GitOpsDeploymentSpecType_Manual
+			}
+		}
+	}
+
+	// A sync policy that only specifies automated sync may have been set by the mutating webhook (see
+	// managedgitopsv1alpha1.DefaultSyncPolicyEnvVar), so the default sync options are also used in that case.
+	if len(defaults.syncOptions) > 0 && (gitopsDepl.Spec.SyncPolicy == nil || isOnlyAutomatedSyncPolicy(*gitopsDepl.Spec.SyncPolicy)) {
+		if gitopsDepl.Spec.SyncPolicy == nil {
+			gitopsDepl.Spec.SyncPolicy = &managedgitopsv1alpha1.SyncPolicy{}
+		}
+		gitopsDepl.Spec.SyncPolicy.SyncOptions = append(managedgitopsv1alpha1.SyncOptions{}, defaults.syncOptions...)
+	}
+
+	if gitopsDepl.Spec.Destination == (managedgitopsv1alpha1.ApplicationDestination{}) {
+		gitopsDepl.Spec.Destination = defaults.destination
+	}
+}
+
+// isOnlyAutomatedSyncPolicy returns true if the sync policy specifies automated sync, and nothing else.
+func isOnlyAutomatedSyncPolicy(syncPolicy managedgitopsv1alpha1.SyncPolicy) bool {
+	return syncPolicy.Automated != nil && syncPolicy.SyncOptions == nil && syncPolicy.ManagedNamespaceMetadata == nil
+}
+
+// applyDefaultLabels adds the default labels to the GitOpsDeployment resource, for label keys that the GitOpsDeployment
+// doesn't already have. The GitOpsDeployment resource is updated, if any labels were added.
+func (defaults gitOpsDeploymentDefaults) applyDefaultLabels(ctx context.Context, k8sClient client.Client,
+	gitopsDepl *managedgitopsv1alpha1.GitOpsDeployment) error {
+
+	updated := false
+
+	for key, value := range defaults.labels {
+		if _, exists := gitopsDepl.Labels[key]; exists {
+			continue
+		}
+		if gitopsDepl.Labels == nil {
+			gitopsDepl.Labels = map[string]string{}
+		}
+		gitopsDepl.Labels[key] = value
+		updated = true
+	}
+
+	if !updated {
+		return nil
+	}
+
+	return k8sClient.Update(ctx, gitopsDepl)
+}
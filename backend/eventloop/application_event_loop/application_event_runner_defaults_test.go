@@ -0,0 +1,162 @@
+package application_event_loop
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("GitOpsDeployment defaults tests", func() {
+
+	Context("Test GitOpsDeployment defaults of a namespace", func() {
+
+		var (
+			ctx        context.Context
+			k8sClient  client.Client
+			workspace  *corev1.Namespace
+			gitopsDepl *managedgitopsv1alpha1.GitOpsDeployment
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			scheme, argocdNamespace, kubesystemNamespace, workspaceNamespace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+			workspace = workspaceNamespace
+
+			gitopsDepl = &managedgitopsv1alpha1.GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-gitops-depl",
+					Namespace: workspace.Name,
+					Labels:    map[string]string{"team": "my-team"},
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentSpec{
+					Source: managedgitopsv1alpha1.ApplicationSource{
+						RepoURL: "https://github.com/abc-org/abc-repo",
+						Path:    "/abc-path",
+					},
+				},
+			}
+
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(argocdNamespace, kubesystemNamespace, workspace, gitopsDepl).Build()
+		})
+
+		createDefaultsConfigMap := func(data map[string]string) {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      GitOpsDeploymentDefaultsConfigMapName,
+					Namespace: workspace.Name,
+				},
+				Data: data,
+			}
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+		}
+
+		It("should default to a Manual GitOpsDeployment if the namespace has no defaults", func() {
+			defaults, userErr := getGitOpsDeploymentDefaults(ctx, k8sClient, workspace.Name)
+			Expect(userErr).To(BeNil())
+
+			defaults.applyDefaultsToSpec(gitopsDepl)
+			Expect(gitopsDepl.Spec.Type).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentSpecType_Manual))
+			Expect(gitopsDepl.Spec.SyncPolicy).To(BeNil())
+			Expect(gitopsDepl.Spec.Destination).To(Equal(managedgitopsv1alpha1.ApplicationDestination{}))
+		})
+
+		It("should apply the defaults of the namespace to the fields that the GitOpsDeployment doesn't specify", func() {
+			createDefaultsConfigMap(map[string]string{
+				GitOpsDeploymentDefaultsKeyType:                   managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated,
+				GitOpsDeploymentDefaultsKeySyncOptions:            string(managedgitopsv1alpha1.SyncOptions_CreateNamespace_true),
+				GitOpsDeploymentDefaultsKeyDestinationEnvironment: "my-managed-env",
+				GitOpsDeploymentDefaultsKeyDestinationNamespace:   "my-namespace",
+			})
+
+			defaults, userErr := getGitOpsDeploymentDefaults(ctx, k8sClient, workspace.Name)
+			Expect(userErr).To(BeNil())
+
+			defaults.applyDefaultsToSpec(gitopsDepl)
+			Expect(gitopsDepl.Spec.Type).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated))
+			Expect(gitopsDepl.Spec.SyncPolicy.SyncOptions).To(Equal(managedgitopsv1alpha1.SyncOptions{
+				managedgitopsv1alpha1.SyncOptions_CreateNamespace_true}))
+			Expect(gitopsDepl.Spec.Destination).To(Equal(managedgitopsv1alpha1.ApplicationDestination{
+				Environment: "my-managed-env",
+				Namespace:   "my-namespace",
+			}))
+		})
+
+		It("should not override the fields that the GitOpsDeployment specifies", func() {
+			createDefaultsConfigMap(map[string]string{
+				GitOpsDeploymentDefaultsKeyType:                 managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated,
+				GitOpsDeploymentDefaultsKeySyncOptions:          string(managedgitopsv1alpha1.SyncOptions_CreateNamespace_true),
+				GitOpsDeploymentDefaultsKeyDestinationNamespace: "my-namespace",
+			})
+
+			gitopsDepl.Spec.Type = managedgitopsv1alpha1.GitOpsDeploymentSpecType_Manual
+			gitopsDepl.Spec.SyncPolicy = &managedgitopsv1alpha1.SyncPolicy{}
+			gitopsDepl.Spec.Destination.Namespace = "other-namespace"
+
+			defaults, userErr := getGitOpsDeploymentDefaults(ctx, k8sClient, workspace.Name)
+			Expect(userErr).To(BeNil())
+
+			defaults.applyDefaultsToSpec(gitopsDepl)
+			Expect(gitopsDepl.Spec.Type).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentSpecType_Manual))
+			Expect(gitopsDepl.Spec.SyncPolicy.SyncOptions).To(BeEmpty())
+			Expect(gitopsDepl.Spec.Destination.Namespace).To(Equal("other-namespace"))
+		})
+
+		It("should default to an Automated GitOpsDeployment, with the default sync options, if it only specifies .spec.syncPolicy.automated", func() {
+			createDefaultsConfigMap(map[string]string{
+				GitOpsDeploymentDefaultsKeySyncOptions: string(managedgitopsv1alpha1.SyncOptions_CreateNamespace_true),
+			})
+
+			gitopsDepl.Spec.SyncPolicy = &managedgitopsv1alpha1.SyncPolicy{
+				Automated: &managedgitopsv1alpha1.SyncPolicyAutomated{SelfHeal: true},
+			}
+
+			defaults, userErr := getGitOpsDeploymentDefaults(ctx, k8sClient, workspace.Name)
+			Expect(userErr).To(BeNil())
+
+			defaults.applyDefaultsToSpec(gitopsDepl)
+			Expect(gitopsDepl.Spec.Type).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated))
+			Expect(gitopsDepl.Spec.SyncPolicy.SyncOptions).To(Equal(managedgitopsv1alpha1.SyncOptions{
+				managedgitopsv1alpha1.SyncOptions_CreateNamespace_true}))
+			Expect(gitopsDepl.Spec.SyncPolicy.Automated).To(Equal(&managedgitopsv1alpha1.SyncPolicyAutomated{SelfHeal: true}))
+		})
+
+		It("should add the default labels that the GitOpsDeployment doesn't have", func() {
+			createDefaultsConfigMap(map[string]string{
+				GitOpsDeploymentDefaultsKeyLabels: "team: platform-team\nenvironment: production\n",
+			})
+
+			defaults, userErr := getGitOpsDeploymentDefaults(ctx, k8sClient, workspace.Name)
+			Expect(userErr).To(BeNil())
+
+			Expect(defaults.applyDefaultLabels(ctx, k8sClient, gitopsDepl)).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)).To(Succeed())
+			Expect(gitopsDepl.Labels).To(Equal(map[string]string{
+				"team":        "my-team",
+				"environment": "production",
+			}))
+		})
+
+		DescribeTable("should return a user error if the defaults are invalid",
+			func(key string, value string) {
+				createDefaultsConfigMap(map[string]string{key: value})
+
+				_, userErr := getGitOpsDeploymentDefaults(ctx, k8sClient, workspace.Name)
+				Expect(userErr).ToNot(BeNil())
+				Expect(userErr.UserError()).To(ContainSubstring(GitOpsDeploymentDefaultsConfigMapName))
+			},
+			Entry("invalid type", GitOpsDeploymentDefaultsKeyType, "sometimes"),
+			Entry("invalid sync option", GitOpsDeploymentDefaultsKeySyncOptions, "CreateNamespace=true,Prune=maybe"),
+			Entry("invalid labels", GitOpsDeploymentDefaultsKeyLabels, "- not-a-map"),
+		)
+	})
+})
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
@@ -31,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 type deploymentModifiedResult string
@@ -43,6 +46,9 @@ const (
 	deploymentModifiedResult_NoChange deploymentModifiedResult = "noChangeInApp"
 
 	prunePropagationPolicy = "PrunePropagationPolicy=background"
+
+	// defaultArgoCDProject is the Argo CD AppProject of Applications whose repository credentials are not scoped
+	defaultArgoCDProject = "default"
 )
 
 // This file is responsible for processing events related to GitOpsDeployment CR.
@@ -90,6 +96,15 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 		return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(userError, devError)
 	}
 
+	// Scope the database queries to the Applications of the namespace: the Applications of other namespaces are not accessible.
+	tenantDBQueries, err := db.NewTenantScopedQueries(dbQueries, db.Tenant{
+		NamespaceUID:  eventlooptypes.GetWorkspaceIDFromNamespaceID(gitopsDeplNamespace),
+		ClusterUserID: clusterUser.Clusteruser_id,
+	})
+	if err != nil {
+		return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewDevOnlyError(err)
+	}
+
 	// 1) Retrieve the GitOpsDeployment from the namespace
 	gitopsDeployment := &managedgitopsv1alpha1.GitOpsDeployment{}
 	{
@@ -105,7 +120,27 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 				a.log.Error(err, "unable to locate object in handleDeploymentModified", "request", gitopsDeploymentKey)
 				return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(userError, err)
 			}
+		} else {
+			// Include the correlation ID of the GitOpsDeployment in the logs of the work we do for it, and in the Operations we create
+			correlationID := logutil.CorrelationIDOfObject(gitopsDeployment)
+			ctx = logutil.ContextWithCorrelationID(ctx, correlationID)
+			a.log = a.log.WithValues(logutil.LogCorrelationIDKey, correlationID)
+		}
+	}
+
+	if !isGitOpsDeploymentDeleted(gitopsDeployment) {
+		// Apply the GitOpsDeployment defaults of the namespace (if any) to the fields that the GitOpsDeployment doesn't specify
+		defaults, userErr := getGitOpsDeploymentDefaults(ctx, a.workspaceClient, deplNamespace)
+		if userErr != nil {
+			return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed, userErr
+		}
+
+		if err := defaults.applyDefaultLabels(ctx, a.workspaceClient, gitopsDeployment); err != nil {
+			userError := "unable to add the default labels of the namespace to the GitOpsDeployment"
+			return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(userError, err)
 		}
+
+		defaults.applyDefaultsToSpec(gitopsDeployment)
 	}
 
 	if !isGitOpsDeploymentDeleted(gitopsDeployment) {
@@ -124,6 +159,14 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 		}
 	}
 
+	// Ensure the GitOpsDeployment is not removed before its Argo CD Application is deleted, if its deletion policy requires it
+	if !isGitOpsDeploymentDeleted(gitopsDeployment) && gitopsDeployment.Spec.DeletionPolicy.RequiresDeletionFinalizer() {
+		if err := addFinalizerIfNotExist(ctx, a.workspaceClient, gitopsDeployment, managedgitopsv1alpha1.DeletionFinalizer); err != nil {
+			userError := "unable to add the deletion finalizer to the GitOpsDeployment"
+			return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(userError, err)
+		}
+	}
+
 	// Update the list of GitOpsDeployments that we use to generate metrics
 	if !isGitOpsDeploymentDeleted(gitopsDeployment) {
 		metrics.AddOrUpdateGitOpsDeployment(deplName, deplNamespace, string(gitopsDeplNamespace.UID))
@@ -133,7 +176,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 
 	// 2) Look for any DTAMs that point(ed) to a K8s resource with the same name and namespace as this request
 	var deplToAppMappingList []db.DeploymentToApplicationMapping
-	if err := dbQueries.ListDeploymentToApplicationMappingByNamespaceAndName(ctx, deplName, deplNamespace,
+	if err := tenantDBQueries.ListDeploymentToApplicationMappingByNamespaceAndName(ctx, deplName, deplNamespace,
 		eventlooptypes.GetWorkspaceIDFromNamespaceID(gitopsDeplNamespace), &deplToAppMappingList); err != nil {
 
 		userError := "unable to retrieve GitOpsDeployment metadata from the database, due to an unknown error"
@@ -162,12 +205,27 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 		}
 	}
 
+	// 3b) If the GitOpsDeployment was deleted and then recreated, within the soft-delete grace period, then restore the
+	// soft-deleted Application of the deleted GitOpsDeployment, rather than creating a new Application.
+	if !isGitOpsDeploymentDeleted(gitopsDeployment) && currentDeplToAppMapping == nil && len(oldDeplToAppMappings) > 0 {
+
+		restoredDeplToAppMapping, remainingDeplToAppMappings, err := a.restoreSoftDeletedApplication(ctx, *gitopsDeployment,
+			oldDeplToAppMappings, tenantDBQueries)
+		if err != nil {
+			userError := "unable to restore the previously deleted GitOpsDeployment, due to an unknown error"
+			return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(userError, err)
+		}
+
+		currentDeplToAppMapping = restoredDeplToAppMapping
+		oldDeplToAppMappings = remainingDeplToAppMappings
+	}
+
 	// 4) Clean up any old GitOpsDeployments that have the same name/namespace as this resource, but that no longer exist
 	successfulCleanup := signalledShutdown_true
 	if len(oldDeplToAppMappings) > 0 || isGitOpsDeploymentDeleted(gitopsDeployment) {
 
 		var deleteErr gitopserrors.UserError
-		successfulCleanup, deleteErr = a.handleDeleteGitOpsDeplEvent(ctx, gitopsDeployment, clusterUser, &oldDeplToAppMappings, dbQueries)
+		successfulCleanup, deleteErr = a.handleDeleteGitOpsDeplEvent(ctx, gitopsDeployment, clusterUser, &oldDeplToAppMappings, tenantDBQueries)
 		if deleteErr != nil {
 			return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed, deleteErr
 		}
@@ -184,7 +242,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 			// then this is the first time we have seen the GitOpsDepl CR.
 			// Create it in the DB and create the operation.
 			application, gitopsEngineInstance, deplModifiedResult, err :=
-				a.handleNewGitOpsDeplEvent(ctx, *gitopsDeployment, clusterUser, dbQueries)
+				a.handleNewGitOpsDeplEvent(ctx, *gitopsDeployment, clusterUser, tenantDBQueries)
 
 			// Since the GitOpsDeployment still exists, don't signal shutdown
 			return signalledShutdown_false, application, gitopsEngineInstance, deplModifiedResult, err
@@ -193,7 +251,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 
 			// 5b) if both exist: it's an update (or a no-op)
 			application, gitopsEngineInstance, deplModifiedResult, err := a.handleUpdatedGitOpsDeplEvent(ctx, currentDeplToAppMapping,
-				*gitopsDeployment, clusterUser, dbQueries)
+				*gitopsDeployment, clusterUser, tenantDBQueries)
 
 			// Since the GitOpsDeployment still exists, don't signal shutdown
 			return signalledShutdown_false, application, gitopsEngineInstance, deplModifiedResult, err
@@ -220,7 +278,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 //   - error is non-nil, if an error occurred
 func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.Context,
 	gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment, clusterUser *db.ClusterUser,
-	dbQueries db.ApplicationScopedQueries) (*db.Application, *db.GitopsEngineInstance, deploymentModifiedResult, gitopserrors.UserError) {
+	dbQueries db.TenantScopedQueries) (*db.Application, *db.GitopsEngineInstance, deploymentModifiedResult, gitopserrors.UserError) {
 
 	a.log.Info("Received GitOpsDeployment event for a new GitOpsDeployment resource")
 	gitopsDeplNamespace := corev1.Namespace{}
@@ -256,6 +314,28 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewDevOnlyError(fmt.Errorf("engine instance is nil when reconciling new GitOpsDeployment"))
 	}
 
+	if uerr := a.verifyRepositoryIsAllowedByManagedEnvironment(ctx, gitopsDeployment); uerr != nil {
+		return nil, nil, deploymentModifiedResult_Failed, uerr
+	}
+
+	project, err := a.getArgoCDProjectForGitOpsDeployment(ctx, gitopsDeployment)
+	if err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewDevOnlyError(err)
+	}
+
+	// Assign the new Application to an Argo CD instance that has not reached its capacity: this is the instance of the
+	// ManagedEnvironment, unless it is full, in which case another instance on the same cluster is used.
+	var managedEnvID string
+	if managedEnv != nil {
+		managedEnvID = managedEnv.Managedenvironment_id
+	}
+	engineInstance, uerr := a.sharedResourceEventLoop.AssignGitopsEngineInstanceForNewApplication(ctx, *engineInstance, *clusterUser,
+		managedEnvID, a.workspaceClient, gitopsDeplNamespace, a.log)
+	if uerr != nil {
+		a.log.Error(uerr.DevError(), "unable to assign a GitOpsEngineInstance to the new GitOpsDeployment")
+		return nil, nil, deploymentModifiedResult_Failed, uerr
+	}
+
 	appName := argosharedutil.GenerateArgoCDApplicationName(string(gitopsDeployment.UID))
 
 	// If the user specified a value, always use it. If not, use the API resource namespace (but only in the workspace target case)
@@ -272,6 +352,16 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(userError, devError)
 	}
 
+	if err := gitopsDeployment.Spec.ValidateResourceHealthChecks(); err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(err.Error(), err)
+	}
+
+	applicationAnnotations, err := generateArgoCDApplicationAnnotations(gitopsDeployment)
+	if err != nil {
+		return nil, nil, deploymentModifiedResult_Failed,
+			gitopserrors.NewDevOnlyError(fmt.Errorf("unable to generate the annotations of the Argo CD Application: %v", err))
+	}
+
 	specFieldInput := argoCDSpecInput{
 		crName:               appName,
 		crNamespace:          engineInstance.Namespace_name,
@@ -282,7 +372,29 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 		sourcePath:           gitopsDeployment.Spec.Source.Path,
 		sourceTargetRevision: gitopsDeployment.Spec.Source.TargetRevision,
 		// syncOptions:       if non-empty, it gets updated below.
-		automated: strings.EqualFold(gitopsDeployment.Spec.Type, managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated),
+		automated:         strings.EqualFold(gitopsDeployment.Spec.Type, managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated),
+		annotations:       applicationAnnotations,
+		commonLabels:      gitopsDeployment.Spec.CommonLabels,
+		commonAnnotations: gitopsDeployment.Spec.CommonAnnotations,
+		project:           project,
+	}
+
+	if gitopsDeployment.Spec.Source.Helm != nil {
+		specFieldInput.helmValueFiles = gitopsDeployment.Spec.Source.Helm.ValueFiles
+		specFieldInput.helmValues = gitopsDeployment.Spec.Source.Helm.Values
+	}
+	specFieldInput.directory = convertSourceDirectoryToArgoCD(gitopsDeployment.Spec.Source.Directory)
+
+	if err := gitopsDeployment.Spec.ValidateCommonMetadata(); err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(err.Error(), err)
+	}
+
+	if err := gitopsDeployment.Spec.ValidateSourceDirectory(); err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(err.Error(), err)
+	}
+
+	if err := gitopsDeployment.Spec.ValidateManagedNamespaceMetadata(); err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(err.Error(), err)
 	}
 
 	if gitopsDeployment.Spec.SyncPolicy != nil && len(gitopsDeployment.Spec.SyncPolicy.SyncOptions) != 0 {
@@ -292,8 +404,19 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 			return nil, nil, deploymentModifiedResult_Failed, userErr
 		}
 
-		specFieldInput.syncOptions = managedgitopsv1alpha1.SyncOptionToStringSlice(gitopsDeployment.Spec.SyncPolicy.SyncOptions)
+		syncOptions := removeUnsupportedSyncOptions(gitopsDeployment.Spec.SyncPolicy.SyncOptions, engineInstance.Argocd_version, a.log)
+		specFieldInput.syncOptions = managedgitopsv1alpha1.SyncOptionToStringSlice(syncOptions)
+
+	}
+
+	if gitopsDeployment.Spec.SyncPolicy != nil && gitopsDeployment.Spec.SyncPolicy.ManagedNamespaceMetadata != nil {
+		specFieldInput.managedNamespaceLabels = gitopsDeployment.Spec.SyncPolicy.ManagedNamespaceMetadata.Labels
+		specFieldInput.managedNamespaceAnnotations = gitopsDeployment.Spec.SyncPolicy.ManagedNamespaceMetadata.Annotations
+	}
 
+	if gitopsDeployment.Spec.SyncPolicy != nil && gitopsDeployment.Spec.SyncPolicy.Automated != nil {
+		specFieldInput.disablePrune = !gitopsDeployment.Spec.SyncPolicy.Automated.Prune
+		specFieldInput.disableSelfHeal = !gitopsDeployment.Spec.SyncPolicy.Automated.SelfHeal
 	}
 
 	specFieldText, err := createSpecField(specFieldInput)
@@ -314,6 +437,7 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 		Spec_field:              specFieldText,
 	}
 
+	// The tenant-scoped queries also create the ApplicationOwner of the Application, for the cluster user of the namespace
 	if err := dbQueries.CreateApplication(ctx, &application); err != nil {
 		a.log.Error(err, "Unable to create application", application.GetAsLogKeyValues()...)
 
@@ -356,7 +480,7 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 		err = fmt.Errorf("gitopsengineinstance namespace is nil, expected non-nil:  %s", engineInstance.Gitopsengineinstance_id)
 		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewDevOnlyError(err)
 	}
-	k8sOperation, dbOperation, err := operations.CreateOperation(ctx, waitForOperation, dbOperationInput,
+	k8sOperation, dbOperation, err := operations.CreateOperationWithDirectMode(ctx, a.directMode, waitForOperation, dbOperationInput,
 		clusterUser.Clusteruser_id, engineInstance.Namespace_name, dbQueries, gitopsEngineClient, a.log)
 	if err != nil {
 		a.log.Error(err, "could not create operation", "namespace", engineInstance.Namespace_name)
@@ -380,7 +504,7 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 // - error is non-nil, if an error occurred
 func (a applicationEventLoopRunner_Action) handleDeleteGitOpsDeplEvent(ctx context.Context, gitopsDepl *managedgitopsv1alpha1.GitOpsDeployment, clusterUser *db.ClusterUser,
 	deplToAppMappingList *[]db.DeploymentToApplicationMapping,
-	dbQueries db.ApplicationScopedQueries) (bool, gitopserrors.UserError) {
+	dbQueries db.TenantScopedQueries) (bool, gitopserrors.UserError) {
 
 	if deplToAppMappingList == nil || clusterUser == nil { // sanity check
 		return false, gitopserrors.NewDevOnlyError(fmt.Errorf("required parameter should not be nil in handleDelete: %v %v", deplToAppMappingList, clusterUser))
@@ -396,6 +520,11 @@ func (a applicationEventLoopRunner_Action) handleDeleteGitOpsDeplEvent(ctx conte
 		return false, gitopserrors.NewUserDevError(userError, devError)
 	}
 
+	// The Applications of a deleted GitOpsDeployment are soft-deleted (if enabled), unless the GitOpsDeployment requires
+	// its resources to be deleted before it is: see handleSoftDeletedGitOpsDeploymentEntry.
+	softDelete := sharedutil.ApplicationSoftDeleteGracePeriod(a.log) > 0 && isGitOpsDeploymentDeleted(gitopsDepl) &&
+		(gitopsDepl == nil || !controllerutil.ContainsFinalizer(gitopsDepl, managedgitopsv1alpha1.DeletionFinalizer))
+
 	var allErrors error
 
 	signalShutdown := true
@@ -406,7 +535,7 @@ func (a applicationEventLoopRunner_Action) handleDeleteGitOpsDeplEvent(ctx conte
 		deplToAppMapping := (*deplToAppMappingList)[idx]
 
 		// Clean up the database entries
-		itemSignalledShutdown, err := a.cleanOldGitOpsDeploymentEntry(ctx, &deplToAppMapping, clusterUser, apiNamespace, dbQueries)
+		itemSignalledShutdown, err := a.cleanOldGitOpsDeploymentEntry(ctx, &deplToAppMapping, gitopsDepl, clusterUser, apiNamespace, softDelete, dbQueries)
 		if err != nil {
 			// If we were unable to fully clean up a gitopsdeployment, then don't shutdown the goroutine
 			signalShutdown = false
@@ -437,6 +566,24 @@ func (a applicationEventLoopRunner_Action) handleDeleteGitOpsDeplEvent(ctx conte
 
 }
 
+func addFinalizerIfNotExist(ctx context.Context, k8sClient client.Client, gitopsDepl *managedgitopsv1alpha1.GitOpsDeployment, finalizer string) error {
+	if controllerutil.ContainsFinalizer(gitopsDepl, finalizer) {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl); err != nil {
+			return err
+		}
+
+		if controllerutil.AddFinalizer(gitopsDepl, finalizer) {
+			return k8sClient.Update(ctx, gitopsDepl)
+		}
+
+		return nil
+	})
+}
+
 func removeFinalizerIfExist(ctx context.Context, k8sClient client.Client, gitopsDepl *managedgitopsv1alpha1.GitOpsDeployment, finalizer string) error {
 	if gitopsDepl == nil {
 		return nil
@@ -510,6 +657,108 @@ func (a applicationEventLoopRunner_Action) reconcileManagedEnvironmentOfGitOpsDe
 	return sharedResourceRes.ManagedEnv, sharedResourceRes.GitopsEngineInstance, destinationName, nil
 }
 
+// verifyRepositoryIsAllowedByManagedEnvironment returns an error if the GitOpsDeployment targets a managed environment
+// whose .spec.allowedRepositories does not allow the repository of the GitOpsDeployment.
+func (a applicationEventLoopRunner_Action) verifyRepositoryIsAllowedByManagedEnvironment(ctx context.Context,
+	gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment) gitopserrors.UserError {
+
+	if gitopsDeployment.Spec.Destination.Environment == "" {
+		// Deployments to the workspace are not restricted
+		return nil
+	}
+
+	managedEnvCR := managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gitopsDeployment.Spec.Destination.Environment,
+			Namespace: gitopsDeployment.Namespace,
+		},
+	}
+	if err := a.workspaceClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvCR), &managedEnvCR); err != nil {
+		if apierr.IsNotFound(err) {
+			// The managed environment was deleted after it was reconciled: this is reported when the deployment is processed.
+			return nil
+		}
+		return gitopserrors.NewDevOnlyError(fmt.Errorf("unable to retrieve managed environment '%s' of GitOpsDeployment: %v",
+			managedEnvCR.Name, err))
+	}
+
+	if managedEnvCR.Spec.IsRepositoryAllowed(gitopsDeployment.Spec.Source.RepoURL) {
+		return nil
+	}
+
+	userError := fmt.Sprintf("the repository '%s' is not allowed by the managed environment '%s': it must match one of the allowed repositories: %s",
+		gitopsDeployment.Spec.Source.RepoURL, managedEnvCR.Name, strings.Join(managedEnvCR.Spec.AllowedRepositories, ", "))
+	devError := fmt.Errorf("repository '%s' of GitOpsDeployment is not in the allowed repositories of managed environment '%s'",
+		gitopsDeployment.Spec.Source.RepoURL, managedEnvCR.Name)
+
+	return gitopserrors.NewUserConditionError(userError, devError, string(managedgitopsv1alpha1.GitopsDeploymentReasonRepositoryNotAllowed))
+}
+
+// getArgoCDProjectForGitOpsDeployment returns the Argo CD AppProject of the Argo CD Application of the GitOpsDeployment:
+// this is the AppProject of a scoped GitOpsDeploymentRepositoryCredential that the GitOpsDeployment is in the scope
+// of (if any), so that Argo CD may use the credentials for the Application. See selectArgoCDProjectForGitOpsDeployment.
+func (a applicationEventLoopRunner_Action) getArgoCDProjectForGitOpsDeployment(ctx context.Context,
+	gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment) (string, error) {
+
+	// Scoped credentials may be defined in other namespaces, so list the credentials of all namespaces
+	var repoCredList managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialList
+	if err := a.workspaceClient.List(ctx, &repoCredList); err != nil {
+		return "", fmt.Errorf("unable to list GitOpsDeploymentRepositoryCredentials: %v", err)
+	}
+
+	return selectArgoCDProjectForGitOpsDeployment(gitopsDeployment, repoCredList.Items), nil
+}
+
+// selectArgoCDProjectForGitOpsDeployment returns the Argo CD AppProject of the Argo CD Application of the GitOpsDeployment,
+// given the GitOpsDeploymentRepositoryCredentials of the cluster:
+//   - If a scoped credential in the namespace of the GitOpsDeployment has the GitOpsDeployment in scope, the AppProject
+//     of that credential is used (the first, by name, if there are multiple).
+//   - Otherwise, if an unscoped credential in the namespace of the GitOpsDeployment is for the repository of the
+//     GitOpsDeployment, the 'default' AppProject is used: credentials of the namespace always take precedence.
+//   - Otherwise, if a scoped credential in another namespace has the GitOpsDeployment in scope, the AppProject of that
+//     credential is used (the first, by namespace and name, if there are multiple).
+//   - Otherwise, the 'default' AppProject is used.
+func selectArgoCDProjectForGitOpsDeployment(gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment,
+	repoCreds []managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential) string {
+
+	sort.Slice(repoCreds, func(i, j int) bool {
+		if repoCreds[i].Namespace != repoCreds[j].Namespace {
+			return repoCreds[i].Namespace < repoCreds[j].Namespace
+		}
+		return repoCreds[i].Name < repoCreds[j].Name
+	})
+
+	var sameNamespaceRepoCredFound bool
+	var otherNamespaceProject string
+
+	for _, repoCred := range repoCreds {
+
+		if repoCred.DeletionTimestamp != nil {
+			continue
+		}
+
+		if repoCred.Namespace == gitopsDeployment.Namespace {
+
+			if repoCred.IsDeploymentInScope(gitopsDeployment) {
+				return argosharedutil.GenerateArgoCDRepositoryCredentialProjectName(string(repoCred.UID))
+			}
+
+			if repoCred.Spec.Scope == nil && repoCred.IsForRepository(gitopsDeployment.Spec.Source.RepoURL) {
+				sameNamespaceRepoCredFound = true
+			}
+
+		} else if otherNamespaceProject == "" && repoCred.IsDeploymentInScope(gitopsDeployment) {
+			otherNamespaceProject = argosharedutil.GenerateArgoCDRepositoryCredentialProjectName(string(repoCred.UID))
+		}
+	}
+
+	if sameNamespaceRepoCredFound || otherNamespaceProject == "" {
+		return defaultArgoCDProject
+	}
+
+	return otherNamespaceProject
+}
+
 // handleUpdatedGitOpsDeplEvent handles GitOpsDeployment events where the user has updated an existing GitOpsDeployment resource.
 // In this case, we need to ensure the Application row in the database is consistent with what the user has provided
 // in the GitOpsDeployment.
@@ -522,7 +771,7 @@ func (a applicationEventLoopRunner_Action) reconcileManagedEnvironmentOfGitOpsDe
 // - error is non-nil, if an error occurred
 func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx context.Context, deplToAppMapping *db.DeploymentToApplicationMapping,
 	gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment, clusterUser *db.ClusterUser,
-	dbQueries db.ApplicationScopedQueries) (*db.Application, *db.GitopsEngineInstance, deploymentModifiedResult, gitopserrors.UserError) {
+	dbQueries db.TenantScopedQueries) (*db.Application, *db.GitopsEngineInstance, deploymentModifiedResult, gitopserrors.UserError) {
 	if deplToAppMapping == nil || gitopsDeployment.UID == "" || clusterUser == nil {
 		return nil, nil, deploymentModifiedResult_Failed,
 			gitopserrors.NewDevOnlyError(fmt.Errorf("unexpected nil param in handleUpdatedGitOpsDeplEvent: %v %v %v",
@@ -569,9 +818,19 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(userError, devError)
 	}
 
-	if engineInstance == nil {
+	if uerr := a.verifyRepositoryIsAllowedByManagedEnvironment(ctx, gitopsDeployment); uerr != nil {
+		return nil, nil, deploymentModifiedResult_Failed, uerr
+	}
+
+	project, err := a.getArgoCDProjectForGitOpsDeployment(ctx, gitopsDeployment)
+	if err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewDevOnlyError(err)
+	}
+
+	if engineInstance == nil || engineInstance.Gitopsengineinstance_id != application.Engine_instance_inst_id {
 		// If engineInstance from reconcileManagedEnvironmentOfGitOpsDeployment is nil, instead get the engine instance from
-		// the application.
+		// the application. Likewise, if the Application was assigned to a different instance (because the instance of the
+		// ManagedEnvironment had reached its capacity when the Application was created), it remains on that instance.
 		engineInstance = &db.GitopsEngineInstance{
 			Gitopsengineinstance_id: application.Engine_instance_inst_id,
 		}
@@ -582,7 +841,13 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 	}
 
 	// Sanity check that the application.name matches the expected value set in handleCreateGitOpsEvent
-	expectedAppName := argosharedutil.GenerateArgoCDApplicationName(string(gitopsDeployment.UID))
+	// - An Application restored by restoreSoftDeletedApplication keeps the name generated from the UID of the deleted
+	//   GitOpsDeployment, which is recorded in the DTAM.
+	appNameUID := string(gitopsDeployment.UID)
+	if deplToAppMapping.RestoredFromDeploymentUID != "" {
+		appNameUID = deplToAppMapping.RestoredFromDeploymentUID
+	}
+	expectedAppName := argosharedutil.GenerateArgoCDApplicationName(appNameUID)
 	if expectedAppName != application.Name {
 		log.Error(nil, "SEVERE: The name of the Argo CD Application CR should remain constant")
 		return nil, nil, deploymentModifiedResult_Failed,
@@ -603,6 +868,16 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(userError, devError)
 	}
 
+	if err := gitopsDeployment.Spec.ValidateResourceHealthChecks(); err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(err.Error(), err)
+	}
+
+	applicationAnnotations, err := generateArgoCDApplicationAnnotations(gitopsDeployment)
+	if err != nil {
+		return nil, nil, deploymentModifiedResult_Failed,
+			gitopserrors.NewDevOnlyError(fmt.Errorf("unable to generate the annotations of the Argo CD Application: %v", err))
+	}
+
 	specFieldInput := argoCDSpecInput{
 		crName:               application.Name,
 		crNamespace:          engineInstance.Namespace_name,
@@ -612,7 +887,29 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 		sourcePath:           gitopsDeployment.Spec.Source.Path,
 		sourceTargetRevision: gitopsDeployment.Spec.Source.TargetRevision,
 		// syncOptions:       if non-empty, it gets updated below.
-		automated: strings.EqualFold(gitopsDeployment.Spec.Type, managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated),
+		automated:         strings.EqualFold(gitopsDeployment.Spec.Type, managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated),
+		annotations:       applicationAnnotations,
+		commonLabels:      gitopsDeployment.Spec.CommonLabels,
+		commonAnnotations: gitopsDeployment.Spec.CommonAnnotations,
+		project:           project,
+	}
+
+	if gitopsDeployment.Spec.Source.Helm != nil {
+		specFieldInput.helmValueFiles = gitopsDeployment.Spec.Source.Helm.ValueFiles
+		specFieldInput.helmValues = gitopsDeployment.Spec.Source.Helm.Values
+	}
+	specFieldInput.directory = convertSourceDirectoryToArgoCD(gitopsDeployment.Spec.Source.Directory)
+
+	if err := gitopsDeployment.Spec.ValidateCommonMetadata(); err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(err.Error(), err)
+	}
+
+	if err := gitopsDeployment.Spec.ValidateSourceDirectory(); err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(err.Error(), err)
+	}
+
+	if err := gitopsDeployment.Spec.ValidateManagedNamespaceMetadata(); err != nil {
+		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewUserDevError(err.Error(), err)
 	}
 
 	if gitopsDeployment.Spec.SyncPolicy != nil && len(gitopsDeployment.Spec.SyncPolicy.SyncOptions) != 0 {
@@ -620,7 +917,18 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 			return nil, nil, deploymentModifiedResult_Failed, err
 		}
 
-		specFieldInput.syncOptions = managedgitopsv1alpha1.SyncOptionToStringSlice(gitopsDeployment.Spec.SyncPolicy.SyncOptions)
+		syncOptions := removeUnsupportedSyncOptions(gitopsDeployment.Spec.SyncPolicy.SyncOptions, engineInstance.Argocd_version, a.log)
+		specFieldInput.syncOptions = managedgitopsv1alpha1.SyncOptionToStringSlice(syncOptions)
+	}
+
+	if gitopsDeployment.Spec.SyncPolicy != nil && gitopsDeployment.Spec.SyncPolicy.ManagedNamespaceMetadata != nil {
+		specFieldInput.managedNamespaceLabels = gitopsDeployment.Spec.SyncPolicy.ManagedNamespaceMetadata.Labels
+		specFieldInput.managedNamespaceAnnotations = gitopsDeployment.Spec.SyncPolicy.ManagedNamespaceMetadata.Annotations
+	}
+
+	if gitopsDeployment.Spec.SyncPolicy != nil && gitopsDeployment.Spec.SyncPolicy.Automated != nil {
+		specFieldInput.disablePrune = !gitopsDeployment.Spec.SyncPolicy.Automated.Prune
+		specFieldInput.disableSelfHeal = !gitopsDeployment.Spec.SyncPolicy.Automated.SelfHeal
 	}
 	shouldUpdateApplication := false
 
@@ -632,7 +940,16 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 			return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewDevOnlyError(err)
 		}
 
-		if specFieldResult == application.Spec_field {
+		// Compare the normalized spec fields, so that semantically identical spec fields (for example, that only differ
+		// in key order, or in fields that are set to their default value) don't cause an update of the Application.
+		specFieldUnchanged, err := argosharedutil.AreApplicationSpecFieldsEquivalent(specFieldResult, application.Spec_field)
+		if err != nil {
+			// The existing spec field can't be parsed, so replace it with the generated spec field
+			log.Error(err, "Unable to compare the generated spec field with the spec field of the Application DB entry")
+			specFieldUnchanged = false
+		}
+
+		if specFieldUnchanged {
 			log.Info("Processed GitOpsDeployment event: No spec change detected between Application DB entry and GitOpsDeployment CR")
 			// No change required: the application database entry is consistent with the gitopsdepl CR
 		} else {
@@ -685,7 +1002,7 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 		err = fmt.Errorf("gitopsengineinstance namespace is nil, expected non-nil:  %s", engineInstance.Gitopsengineinstance_id)
 		return nil, nil, deploymentModifiedResult_Failed, gitopserrors.NewDevOnlyError(err)
 	}
-	k8sOperation, dbOperation, err := operations.CreateOperation(ctx, waitForOperation, dbOperationInput, clusterUser.Clusteruser_id,
+	k8sOperation, dbOperation, err := operations.CreateOperationWithDirectMode(ctx, a.directMode, waitForOperation, dbOperationInput, clusterUser.Clusteruser_id,
 		engineInstance.Namespace_name, dbQueries, gitopsEngineClient, log)
 	if err != nil {
 		log.Error(err, "could not create operation")
@@ -700,9 +1017,16 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 
 }
 
+// cleanOldGitOpsDeploymentEntry cleans up the database entries of a GitOpsDeployment that no longer exists, and informs
+// the cluster-agent so that it deletes the Argo CD Application.
+//
+// If softDelete is true, the Application is instead soft-deleted: see handleSoftDeletedGitOpsDeploymentEntry.
+//
+// gitopsDepl is the GitOpsDeployment that is being deleted, if it still exists: if the DTAM is that of gitopsDepl, its
+// deletion policy is applied to the Argo CD Application before it is deleted.
 func (a applicationEventLoopRunner_Action) cleanOldGitOpsDeploymentEntry(ctx context.Context,
-	deplToAppMapping *db.DeploymentToApplicationMapping, clusterUser *db.ClusterUser,
-	apiNamespace corev1.Namespace, dbQueries db.ApplicationScopedQueries) (bool, error) {
+	deplToAppMapping *db.DeploymentToApplicationMapping, gitopsDepl *managedgitopsv1alpha1.GitOpsDeployment, clusterUser *db.ClusterUser,
+	apiNamespace corev1.Namespace, softDelete bool, dbQueries db.TenantScopedQueries) (bool, error) {
 
 	dbApplicationFound := true
 
@@ -724,6 +1048,19 @@ func (a applicationEventLoopRunner_Action) cleanOldGitOpsDeploymentEntry(ctx con
 
 	log := a.log.WithValues("applicationID", deplToAppMapping.Application_id)
 
+	if softDelete && dbApplicationFound {
+		return a.handleSoftDeletedGitOpsDeploymentEntry(ctx, dbApplication, clusterUser, apiNamespace, dbQueries, log)
+	}
+
+	// 0) If the GitOpsDeployment of the Application still exists (because it has the deletion finalizer), ensure that its
+	// deletion policy has been applied to the Argo CD Application, before the Application is deleted.
+	if dbApplicationFound && gitopsDepl != nil && string(gitopsDepl.UID) == deplToAppMapping.Deploymenttoapplicationmapping_uid_id {
+		if err := a.applyDeletionPolicyToApplication(ctx, dbApplication, gitopsDepl.Spec.DeletionPolicy, clusterUser, apiNamespace,
+			dbQueries, log); err != nil {
+			return false, err
+		}
+	}
+
 	// 1) Remove the ApplicationState from the database
 	rowsDeleted, err := dbQueries.DeleteApplicationStateById(ctx, deplToAppMapping.Application_id)
 	if err != nil {
@@ -820,7 +1157,7 @@ func (a applicationEventLoopRunner_Action) cleanOldGitOpsDeploymentEntry(ctx con
 		err = fmt.Errorf("gitopsengineinstance namespace is nil, expected non-nil:  %s", gitopsEngineInstance.Gitopsengineinstance_id)
 		return false, err
 	}
-	k8sOperation, dbOperation, err := operations.CreateOperation(ctx, waitForOperation, dbOperationInput,
+	k8sOperation, dbOperation, err := operations.CreateOperationWithDirectMode(ctx, a.directMode, waitForOperation, dbOperationInput,
 		clusterUser.Clusteruser_id, gitopsEngineInstance.Namespace_name, dbQueries, gitopsEngineClient, log)
 	if err != nil {
 		log.Error(err, "unable to create operation", "operation", dbOperationInput.ShortString())
@@ -837,6 +1174,200 @@ func (a applicationEventLoopRunner_Action) cleanOldGitOpsDeploymentEntry(ctx con
 
 }
 
+// handleSoftDeletedGitOpsDeploymentEntry soft-deletes the Application of a deleted GitOpsDeployment.
+//
+// The Application row, the DeploymentToApplicationMapping row, and the Argo CD Application are all kept: if a
+// GitOpsDeployment with the same name is created in the namespace before the soft-delete grace period has elapsed,
+// the Application is restored (see restoreSoftDeletedApplication). Otherwise, the database reconciler deletes the
+// Application (and the Argo CD Application) once the grace period has elapsed.
+//
+// While the Application is soft-deleted, automated sync is disabled on the Argo CD Application, so that Argo CD stops
+// deploying from Git on behalf of a GitOpsDeployment that no longer exists. On restore, the spec field is regenerated
+// from the new GitOpsDeployment by handleUpdatedGitOpsDeplEvent, which re-enables automated sync (if requested).
+func (a applicationEventLoopRunner_Action) handleSoftDeletedGitOpsDeploymentEntry(ctx context.Context, dbApplication db.Application,
+	clusterUser *db.ClusterUser, apiNamespace corev1.Namespace, dbQueries db.TenantScopedQueries, log logr.Logger) (bool, error) {
+
+	if dbApplication.IsSoftDeleted() {
+		// Our work is done
+		return true, nil
+	}
+
+	specField, err := disableAutomatedSyncInSpecField(dbApplication.Spec_field)
+	if err != nil {
+		log.Error(err, "unable to disable automated sync in spec field of application")
+		return false, err
+	}
+
+	if specField != dbApplication.Spec_field {
+
+		dbApplication.Spec_field = specField
+		if err := dbQueries.UpdateApplication(ctx, &dbApplication); err != nil {
+			log.Error(err, "unable to disable automated sync of application")
+			return false, err
+		}
+
+		// Inform the cluster-agent, so that it disables automated sync on the Argo CD Application
+		if err := a.createApplicationOperationAndWait(ctx, dbApplication, clusterUser, apiNamespace, dbQueries, log); err != nil {
+			return false, err
+		}
+	}
+
+	rowsUpdated, err := dbQueries.SoftDeleteApplicationById(ctx, dbApplication.Application_id)
+	if err != nil {
+		log.Error(err, "unable to soft-delete application")
+		return false, err
+	}
+
+	log.Info("GitOpsDeployment was deleted, so soft-deleted Application row in database", "rowsUpdated", rowsUpdated)
+
+	return true, nil
+}
+
+// applyDeletionPolicyToApplication ensures that the deletion policy of a GitOpsDeployment that is being deleted has been
+// applied to its Argo CD Application, before the Application is deleted: the cluster-agent determines whether to delete
+// the resources of the Argo CD Application from its deletion policy annotation (see DeleteArgoCDApplication).
+//
+// This is required when the deletion policy was changed shortly before the GitOpsDeployment was deleted, in which case
+// the change may not yet have been applied to the Application.
+func (a applicationEventLoopRunner_Action) applyDeletionPolicyToApplication(ctx context.Context, dbApplication db.Application,
+	deletionPolicy managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy, clusterUser *db.ClusterUser, apiNamespace corev1.Namespace,
+	dbQueries db.TenantScopedQueries, log logr.Logger) error {
+
+	specField, err := setDeletionPolicyInSpecField(dbApplication.Spec_field, deletionPolicy)
+	if err != nil {
+		log.Error(err, "unable to set deletion policy in spec field of application")
+		return err
+	}
+
+	if specField == dbApplication.Spec_field {
+		return nil
+	}
+
+	dbApplication.Spec_field = specField
+	if err := dbQueries.UpdateApplication(ctx, &dbApplication); err != nil {
+		log.Error(err, "unable to update deletion policy of application")
+		return err
+	}
+
+	log.Info("Updated the deletion policy of the Application, before deleting it", "deletionPolicy", deletionPolicy)
+
+	// Wait for the cluster-agent to update the Argo CD Application, before the Application is deleted
+	return a.createApplicationOperationAndWait(ctx, dbApplication, clusterUser, apiNamespace, dbQueries, log)
+}
+
+// createApplicationOperationAndWait informs the cluster-agent that the Application row has been modified, by creating an
+// Operation for it, and then waits for the Operation to complete (except in unit tests).
+func (a applicationEventLoopRunner_Action) createApplicationOperationAndWait(ctx context.Context, dbApplication db.Application,
+	clusterUser *db.ClusterUser, apiNamespace corev1.Namespace, dbQueries db.TenantScopedQueries, log logr.Logger) error {
+
+	gitopsEngineInstance, err := a.sharedResourceEventLoop.GetGitopsEngineInstanceById(ctx, dbApplication.Engine_instance_inst_id, a.workspaceClient, apiNamespace, a.log)
+	if err != nil {
+		log.Error(err, "unable to retrieve gitops engine instance of application", "gitopsEngineID", dbApplication.Engine_instance_inst_id)
+		return err
+	}
+
+	if gitopsEngineInstance.Namespace_name == "" {
+		return fmt.Errorf("gitopsengineinstance namespace is nil, expected non-nil:  %s", gitopsEngineInstance.Gitopsengineinstance_id)
+	}
+
+	gitopsEngineClient, err := a.k8sClientFactory.GetK8sClientForGitOpsEngineInstance(ctx, gitopsEngineInstance)
+	if err != nil {
+		log.Error(err, "could not retrieve client for gitops engine instance", "instance", gitopsEngineInstance.Gitopsengineinstance_id)
+		return err
+	}
+
+	dbOperationInput := db.Operation{
+		Instance_id:   dbApplication.Engine_instance_inst_id,
+		Resource_id:   dbApplication.Application_id,
+		Resource_type: db.OperationResourceType_Application,
+	}
+
+	waitForOperation := !a.testOnlySkipCreateOperation // if it's for a unit test, we don't wait for the operation
+	k8sOperation, dbOperation, err := operations.CreateOperationWithDirectMode(ctx, a.directMode, waitForOperation, dbOperationInput,
+		clusterUser.Clusteruser_id, gitopsEngineInstance.Namespace_name, dbQueries, gitopsEngineClient, log)
+	if err != nil {
+		log.Error(err, "unable to create operation", "operation", dbOperationInput.ShortString())
+		return err
+	}
+
+	if err := operations.CleanupOperation(ctx, *dbOperation, *k8sOperation, dbQueries, gitopsEngineClient, !a.testOnlySkipCreateOperation, log); err != nil {
+		log.Error(err, "unable to cleanup operation", "operation", dbOperationInput.ShortString())
+		return err
+	}
+
+	return nil
+}
+
+// restoreSoftDeletedApplication looks for a soft-deleted Application in the DTAMs of deleted GitOpsDeployments which had
+// the same name/namespace as gitopsDeployment. If one is found, the Application is restored, and its DTAM is replaced
+// with a DTAM that points to gitopsDeployment.
+//
+// Returns:
+// - the new DTAM, or nil if no soft-deleted Application was found
+// - the remaining DTAMs of deleted GitOpsDeployments, which should be cleaned up
+func (a applicationEventLoopRunner_Action) restoreSoftDeletedApplication(ctx context.Context,
+	gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment, oldDeplToAppMappings []db.DeploymentToApplicationMapping,
+	dbQueries db.TenantScopedQueries) (*db.DeploymentToApplicationMapping, []db.DeploymentToApplicationMapping, error) {
+
+	for idx := range oldDeplToAppMappings {
+
+		oldDeplToAppMapping := oldDeplToAppMappings[idx]
+
+		application := db.Application{Application_id: oldDeplToAppMapping.Application_id}
+		if err := dbQueries.GetApplicationById(ctx, &application); err != nil {
+			if db.IsResultNotFoundError(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+
+		if !application.IsSoftDeleted() {
+			continue
+		}
+
+		log := a.log.WithValues("applicationID", application.Application_id)
+
+		if _, err := dbQueries.RestoreApplicationById(ctx, application.Application_id); err != nil {
+			log.Error(err, "unable to restore soft-deleted application")
+			return nil, nil, err
+		}
+
+		if _, err := dbQueries.DeleteDeploymentToApplicationMappingByDeplId(ctx, oldDeplToAppMapping.Deploymenttoapplicationmapping_uid_id); err != nil {
+			log.Error(err, "unable to delete deplToAppMapping of restored application", "deplToAppMapUid", oldDeplToAppMapping.Deploymenttoapplicationmapping_uid_id)
+			return nil, nil, err
+		}
+
+		// The name of the Argo CD Application was generated from the UID of the GitOpsDeployment that created it, which
+		// may itself have been restored.
+		restoredFromDeploymentUID := oldDeplToAppMapping.RestoredFromDeploymentUID
+		if restoredFromDeploymentUID == "" {
+			restoredFromDeploymentUID = oldDeplToAppMapping.Deploymenttoapplicationmapping_uid_id
+		}
+
+		newDeplToAppMapping := db.DeploymentToApplicationMapping{
+			Deploymenttoapplicationmapping_uid_id: string(gitopsDeployment.UID),
+			DeploymentName:                        oldDeplToAppMapping.DeploymentName,
+			DeploymentNamespace:                   oldDeplToAppMapping.DeploymentNamespace,
+			NamespaceUID:                          oldDeplToAppMapping.NamespaceUID,
+			Application_id:                        application.Application_id,
+			RestoredFromDeploymentUID:             restoredFromDeploymentUID,
+		}
+		if err := dbQueries.CreateDeploymentToApplicationMapping(ctx, &newDeplToAppMapping); err != nil {
+			log.Error(err, "unable to create deplToAppMapping for restored application")
+			return nil, nil, err
+		}
+
+		log.Info("Restored soft-deleted Application of previously deleted GitOpsDeployment", "previousGitOpsDeploymentUID",
+			oldDeplToAppMapping.Deploymenttoapplicationmapping_uid_id)
+
+		remaining := append(append([]db.DeploymentToApplicationMapping{}, oldDeplToAppMappings[:idx]...), oldDeplToAppMappings[idx+1:]...)
+
+		return &newDeplToAppMapping, remaining, nil
+	}
+
+	return nil, oldDeplToAppMappings, nil
+}
+
 // applicationEventRunner_handleUpdateDeploymentStatusTick updates the status field of all the GitOpsDeploymentCRs in the workspace.
 func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateDeploymentStatusTick(ctx context.Context,
 	resourceName string, namespaceName string, dbQueries db.ApplicationScopedQueries) (bool, error) {
@@ -870,11 +1401,17 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 	// Copy of the GitOpsDeployment we retrieved, before its modified below
 	originalGitOpsDeployment := *gitopsDeployment.DeepCopy()
 
+	// Scope the database queries to the Applications of the namespace: the Applications of other namespaces are not accessible.
+	tenantDBQueries, err := db.NewTenantScopedQueries(dbQueries, db.Tenant{NamespaceUID: a.workspaceID})
+	if err != nil {
+		return crUpdated_false, err
+	}
+
 	// 2) Retrieve the DTAM for the GitOpsDeployment, if it exists.
 	mapping := db.DeploymentToApplicationMapping{
 		Deploymenttoapplicationmapping_uid_id: string(gitopsDeployment.UID),
 	}
-	if err := dbQueries.GetDeploymentToApplicationMappingByDeplId(ctx, &mapping); err != nil {
+	if err := tenantDBQueries.GetDeploymentToApplicationMappingByDeplId(ctx, &mapping); err != nil {
 
 		if db.IsResultNotFoundError(err) {
 			// No Application associated with this GitOpsDeployment, so no work to do
@@ -887,7 +1424,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 
 	// 3) Retrieve the application state for the application pointed to by the DTAM
 	applicationState := db.ApplicationState{Applicationstate_application_id: mapping.Application_id}
-	if err := dbQueries.GetApplicationStateById(ctx, &applicationState); err != nil {
+	if err := tenantDBQueries.GetApplicationStateById(ctx, &applicationState); err != nil {
 
 		if db.IsResultNotFoundError(err) {
 			log.Info("ApplicationState not found for application, on deploymentStatusTick: " + applicationState.Applicationstate_application_id)
@@ -900,9 +1437,18 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 	// 4) Update the health and status field of the GitOpsDepl CR
 
 	// Update the gitopsDeployment instance with health and status values (fetched from the database)
-	gitopsDeployment.Status.Health.Status = managedgitopsv1alpha1.HealthStatusCode(applicationState.Health)
+	health, err := db.ParseApplicationStateHealth(applicationState.Health)
+	if err != nil {
+		log.V(logutil.LogLevel_Warn).Info("ApplicationState contains an unrecognized health value, using Unknown", "error", err.Error())
+	}
+	syncStatus, err := db.ParseApplicationStateSyncStatus(applicationState.Sync_Status)
+	if err != nil {
+		log.V(logutil.LogLevel_Warn).Info("ApplicationState contains an unrecognized sync status value, using Unknown", "error", err.Error())
+	}
+
+	gitopsDeployment.Status.Health.Status = managedgitopsv1alpha1.HealthStatusCode(health)
 	gitopsDeployment.Status.Health.Message = applicationState.Message
-	gitopsDeployment.Status.Sync.Status = managedgitopsv1alpha1.SyncStatusCode(applicationState.Sync_Status)
+	gitopsDeployment.Status.Sync.Status = managedgitopsv1alpha1.SyncStatusCode(syncStatus)
 	gitopsDeployment.Status.Sync.Revision = applicationState.Revision
 
 	// We update the GitopsDeployment .status.conditions with SyncError condition, if the sync_error column of ApplicationState row is non empty
@@ -923,14 +1469,58 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 		}
 	}
 
+	// Likewise, we update the .status.conditions with an InvalidTargetRevision condition, if the cluster-agent was unable
+	// to find the target revision in the Git repository (for example, a typo in the name of a branch).
+	gitopsDeployment.Status.ResolvedRevision = applicationState.ResolvedRevision
+
+	if applicationState.RevisionError != "" {
+		condition.NewConditionManager().SetCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionInvalidTargetRevision,
+			managedgitopsv1alpha1.GitOpsConditionStatusTrue, managedgitopsv1alpha1.GitopsDeploymentReasonInvalidTargetRevision, applicationState.RevisionError)
+	} else {
+		conditionManager := condition.NewConditionManager()
+		if conditionManager.HasCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionInvalidTargetRevision) {
+			reason := managedgitopsv1alpha1.GitopsDeploymentReasonInvalidTargetRevision + "Resolved"
+			if cond, _ := conditionManager.FindCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionInvalidTargetRevision); cond.Reason != reason {
+				conditionManager.SetCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionInvalidTargetRevision, managedgitopsv1alpha1.GitOpsConditionStatusFalse, reason, "")
+			}
+		}
+	}
+
 	// Fetch the list of resources created by deployment from table and update local gitopsDeployment instance.
-	var err error
 	gitopsDeployment.Status.Resources, err = decompressResourceData(applicationState.Resources)
 	if err != nil {
 		log.Error(err, "unable to decompress byte array received from table.")
 		return crUpdated_false, err
 	}
 
+	// Warn the user, via an UnsupportedAPIGroups condition, if the GitOpsDeployment is deploying resources of API groups
+	// that are not available on the target cluster of its environment.
+	unsupportedAPIGroups, err := getUnsupportedAPIGroupsOfApplication(ctx, mapping.Application_id, gitopsDeployment.Status.Resources, tenantDBQueries)
+	if err != nil {
+		log.Error(err, "unable to determine the unsupported API groups of the GitOpsDeployment")
+		return crUpdated_false, err
+	}
+
+	if len(unsupportedAPIGroups) > 0 {
+		message := fmt.Sprintf("the following API groups are not available on the target cluster of environment '%s': %s",
+			gitopsDeployment.Spec.Destination.Environment, strings.Join(unsupportedAPIGroups, ", "))
+
+		conditionManager := condition.NewConditionManager()
+		if cond, exists := conditionManager.FindCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionUnsupportedAPIGroups); !exists || cond.Message != message {
+			log.V(logutil.LogLevel_Warn).Info("GitOpsDeployment references API groups that are not available on the target cluster", "apiGroups", unsupportedAPIGroups)
+		}
+		conditionManager.SetCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionUnsupportedAPIGroups,
+			managedgitopsv1alpha1.GitOpsConditionStatusTrue, managedgitopsv1alpha1.GitopsDeploymentReasonUnsupportedAPIGroups, message)
+	} else {
+		conditionManager := condition.NewConditionManager()
+		if conditionManager.HasCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionUnsupportedAPIGroups) {
+			reason := managedgitopsv1alpha1.GitopsDeploymentReasonUnsupportedAPIGroups + "Resolved"
+			if cond, _ := conditionManager.FindCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionUnsupportedAPIGroups); cond.Reason != reason {
+				conditionManager.SetCondition(&gitopsDeployment.Status.Conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionUnsupportedAPIGroups, managedgitopsv1alpha1.GitOpsConditionStatusFalse, reason, "")
+			}
+		}
+	}
+
 	var comparedTo fauxargocd.FauxComparedTo
 	comparedTo, err = retrieveComparedToFieldInApplicationState(applicationState.ReconciledState)
 	if err != nil {
@@ -947,7 +1537,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 			DBRelationKey:   comparedTo.Destination.Name,
 		}
 
-		err = dbQueries.GetAPICRForDatabaseUID(ctx, apiCRToDBMapping)
+		err = tenantDBQueries.GetAPICRForDatabaseUID(ctx, apiCRToDBMapping)
 		if err != nil {
 			// If any error occurs while we're trying to retrieve the value of this field, we just report the
 			// value as empty: if necessary, it will be updated on the next tick.
@@ -964,6 +1554,49 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 	gitopsDeployment.Status.ReconciledState.Destination.Name = comparedTo.Destination.Name
 	gitopsDeployment.Status.ReconciledState.Destination.Namespace = comparedTo.Destination.Namespace
 
+	// Update gitopsDeployment status with the details of the last sync operation
+	gitopsDeployment.Status.LastSync, err = retrieveLastSyncFieldInApplicationState(applicationState.LastSync)
+	if err != nil {
+		log.Error(err, "SEVERE: unable to retrieve lastSync field in ApplicationState")
+		return crUpdated_false, err
+	}
+
+	// Update the GitOpsDeploymentSyncRun that requested the last sync operation (if any) with the status of its hooks
+	if err := updateSyncRunHookStatus(ctx, a.workspaceClient, *gitopsDeployment, applicationState.SyncHooks); err != nil {
+		log.Error(err, "unable to update the hooks of the GitOpsDeploymentSyncRun of the last sync operation")
+		return crUpdated_false, err
+	}
+
+	// Update gitopsDeployment status with the most recently requested diff preview, if any
+	gitopsDeployment.Status.DiffPreview, err = retrieveDiffPreviewFieldInApplicationState(applicationState.DiffPreview)
+	if err != nil {
+		log.Error(err, "SEVERE: unable to retrieve diffPreview field in ApplicationState")
+		return crUpdated_false, err
+	}
+
+	// Update gitopsDeployment status with the most recent resource estimate, if any
+	gitopsDeployment.Status.ResourceEstimate, err = retrieveResourceEstimateFieldInApplicationState(applicationState.ResourceEstimate)
+	if err != nil {
+		log.Error(err, "SEVERE: unable to retrieve resourceEstimate field in ApplicationState")
+		return crUpdated_false, err
+	}
+
+	// Update gitopsDeployment status with the operation that is in progress, if any
+	gitopsDeployment.Status.OperationState, err = retrieveOperationStateFieldInApplicationState(applicationState.OperationState)
+	if err != nil {
+		log.Error(err, "SEVERE: unable to retrieve operationState field in ApplicationState")
+		return crUpdated_false, err
+	}
+
+	// Update gitopsDeployment status with the revisions that were most recently deployed by the Application
+	var deploymentHistory []db.DeploymentHistory
+	if err := tenantDBQueries.ListDeploymentHistoryByApplicationID(ctx, mapping.Application_id,
+		managedgitopsv1alpha1.GitOpsDeploymentHistoryLimit, &deploymentHistory); err != nil {
+		log.Error(err, "unable to retrieve the deployment history of the Application")
+		return crUpdated_false, err
+	}
+	gitopsDeployment.Status.History = convertDeploymentHistoryToStatus(deploymentHistory)
+
 	// If nothing has changed in the status field, our work is done.
 	if reflect.DeepEqual(gitopsDeployment.Status, originalGitOpsDeployment.Status) {
 		return crUpdated_false, nil
@@ -1066,25 +1699,47 @@ func checkValidSyncOption(syncOptions []managedgitopsv1alpha1.SyncOption) gitops
 
 	for _, syncOptionString := range syncOptions {
 
-		match := true
-
-		// Check for SyncOption string
-		if !(syncOptionString == managedgitopsv1alpha1.SyncOptions_CreateNamespace_true ||
-			syncOptionString == managedgitopsv1alpha1.SyncOptions_CreateNamespace_false) {
-			match = false
-		}
-
-		// If at least one of the options don't match, return a user  error
-		if !match {
+		// If at least one of the options is not supported, return a user error
+		if !syncOptionString.IsSupported() {
 			userError := "the specified sync option in .spec.syncPolicy.syncOptions is either mispelled or is not supported by GitOpsDeployment"
 			devError := fmt.Errorf("invalid SyncOption : %s", syncOptionString)
 
 			return gitopserrors.NewUserDevError(userError, devError)
 		}
 	}
+
+	// The same option should not be specified with different values (e.g. both 'Validate=true' and 'Validate=false')
+	if conflicting := managedgitopsv1alpha1.SyncOptions(syncOptions).ConflictingSyncOption(); conflicting != "" {
+		userError := fmt.Sprintf("the sync option '%s' in .spec.syncPolicy.syncOptions conflicts with another value of the same option", conflicting.Name())
+		devError := fmt.Errorf("conflicting SyncOption : %s", conflicting)
+
+		return gitopserrors.NewUserDevError(userError, devError)
+	}
+
 	return nil
 }
 
+// removeUnsupportedSyncOptions returns the sync options, without those that are not supported by the version of Argo CD
+// of the GitopsEngineInstance (as detected by the cluster-agent). The Argo CD Application is still created, but the
+// unsupported options are skipped (and logged), rather than being rejected (or silently ignored) by Argo CD.
+func removeUnsupportedSyncOptions(syncOptions []managedgitopsv1alpha1.SyncOption, argoCDVersion string, log logr.Logger) []managedgitopsv1alpha1.SyncOption {
+
+	capabilities := argosharedutil.GetArgoCDCapabilities(argoCDVersion)
+
+	res := []managedgitopsv1alpha1.SyncOption{}
+	for _, syncOption := range syncOptions {
+
+		if !capabilities.ServerSideApply && syncOption.Name() == managedgitopsv1alpha1.SyncOptions_ServerSideApply_true.Name() {
+			log.Info("Skipping sync option, as it is not supported by the version of Argo CD", "syncOption", string(syncOption), "argoCDVersion", argoCDVersion)
+			continue
+		}
+
+		res = append(res, syncOption)
+	}
+
+	return res
+}
+
 type argoCDSpecInput struct {
 	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
 	crName      string
@@ -1100,9 +1755,77 @@ type argoCDSpecInput struct {
 	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
 	automated bool
 
+	// disablePrune/disableSelfHeal disable the pruning and self-healing of automated sync (see .spec.syncPolicy.automated)
+	disablePrune    bool
+	disableSelfHeal bool
+
+	// annotations are set on the generated Argo CD Application (Argo CD Notifications subscriptions and sync wave)
+	annotations map[string]string
+	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
+
+	// commonLabels/commonAnnotations are added to every resource deployed by the Application, via Kustomize
+	commonLabels      map[string]string
+	commonAnnotations map[string]string
+	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
+
+	// managedNamespaceLabels/managedNamespaceAnnotations are set on the destination namespace, when it is created by Argo CD
+	managedNamespaceLabels      map[string]string
+	managedNamespaceAnnotations map[string]string
+	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
+
+	// helmValueFiles/helmValues are the Helm options of the source, for example, environment-specific values.
+	// - Note: helmValues is a (multi-line) YAML block, and so is not sanitized: it is only ever set as a string scalar
+	//   of the generated Application, which the YAML marshaller quotes.
+	helmValueFiles []string
+	helmValues     string
+	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
+
+	// directory holds the options of a source that is a plain directory of manifests (recurse, include/exclude and Jsonnet).
+	// - Note: the values of Jsonnet variables may be Jsonnet code, and so are not sanitized (see note on helmValues).
+	directory *fauxargocd.ApplicationSourceDirectory
+	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
+
+	// project is the Argo CD AppProject of the Application: 'default', unless the repository credentials of the
+	// GitOpsDeployment are scoped (see selectArgoCDProjectForGitOpsDeployment). If empty, 'default' is used.
+	project string
+	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
+
 	// Hopefully you are getting the message, here :)
 }
 
+// convertSourceDirectoryToArgoCD converts the directory options of a GitOpsDeployment source to the equivalent Argo CD
+// Application source options. nil is returned if the options are not set.
+func convertSourceDirectoryToArgoCD(directory *managedgitopsv1alpha1.ApplicationSourceDirectory) *fauxargocd.ApplicationSourceDirectory {
+
+	if directory == nil {
+		return nil
+	}
+
+	convertJsonnetVars := func(vars []managedgitopsv1alpha1.JsonnetVar) []fauxargocd.JsonnetVar {
+		var res []fauxargocd.JsonnetVar
+		for _, jsonnetVar := range vars {
+			res = append(res, fauxargocd.JsonnetVar{Name: jsonnetVar.Name, Value: jsonnetVar.Value, Code: jsonnetVar.Code})
+		}
+		return res
+	}
+
+	res := &fauxargocd.ApplicationSourceDirectory{
+		Recurse: directory.Recurse,
+		Include: directory.Include,
+		Exclude: directory.Exclude,
+	}
+
+	if directory.Jsonnet != nil {
+		res.Jsonnet = &fauxargocd.ApplicationSourceJsonnet{
+			ExtVars: convertJsonnetVars(directory.Jsonnet.ExtVars),
+			TLAs:    convertJsonnetVars(directory.Jsonnet.TLAs),
+			Libs:    directory.Jsonnet.Libs,
+		}
+	}
+
+	return res
+}
+
 func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 
 	sanitize := func(input string) string {
@@ -1126,6 +1849,49 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 		return res
 	}
 
+	sanitizeMap := func(input map[string]string) map[string]string {
+		if len(input) == 0 {
+			return nil
+		}
+		res := map[string]string{}
+		for key, value := range input {
+			// Argo CD Notifications uses ';' to separate recipients, so preserve it in annotation values
+			res[sanitize(key)] = strings.Join(sanitizeArray(strings.Split(value, ";")), ";")
+		}
+		return res
+	}
+
+	sanitizeJsonnetVars := func(input []fauxargocd.JsonnetVar) []fauxargocd.JsonnetVar {
+		var res []fauxargocd.JsonnetVar
+		for _, jsonnetVar := range input {
+			res = append(res, fauxargocd.JsonnetVar{
+				Name:  sanitize(jsonnetVar.Name),
+				Value: jsonnetVar.Value, // See note on the 'directory' field
+				Code:  jsonnetVar.Code,
+			})
+		}
+		return res
+	}
+
+	sanitizeDirectory := func(input *fauxargocd.ApplicationSourceDirectory) *fauxargocd.ApplicationSourceDirectory {
+		if input == nil {
+			return nil
+		}
+		res := &fauxargocd.ApplicationSourceDirectory{
+			Recurse: input.Recurse,
+			Include: sanitize(input.Include),
+			Exclude: sanitize(input.Exclude),
+		}
+		if input.Jsonnet != nil {
+			res.Jsonnet = &fauxargocd.ApplicationSourceJsonnet{
+				ExtVars: sanitizeJsonnetVars(input.Jsonnet.ExtVars),
+				TLAs:    sanitizeJsonnetVars(input.Jsonnet.TLAs),
+				Libs:    sanitizeArray(input.Jsonnet.Libs),
+			}
+		}
+		return res
+	}
+
 	fields := argoCDSpecInput{
 		// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
 		crName:               sanitize(fieldsParam.crName),
@@ -1138,6 +1904,18 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 		sourceTargetRevision: sanitize(fieldsParam.sourceTargetRevision),
 		syncOptions:          sanitizeArray(fieldsParam.syncOptions),
 		automated:            fieldsParam.automated,
+		disablePrune:         fieldsParam.disablePrune,
+		disableSelfHeal:      fieldsParam.disableSelfHeal,
+		annotations:          sanitizeMap(fieldsParam.annotations),
+		commonLabels:         sanitizeMap(fieldsParam.commonLabels),
+		commonAnnotations:    sanitizeMap(fieldsParam.commonAnnotations),
+		helmValueFiles:       sanitizeArray(fieldsParam.helmValueFiles),
+		helmValues:           fieldsParam.helmValues, // See note on the field
+		directory:            sanitizeDirectory(fieldsParam.directory),
+		project:              sanitize(fieldsParam.project),
+
+		managedNamespaceLabels:      sanitizeMap(fieldsParam.managedNamespaceLabels),
+		managedNamespaceAnnotations: sanitizeMap(fieldsParam.managedNamespaceAnnotations),
 		// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
 
 		// Hopefully you are getting the message, here :)
@@ -1149,8 +1927,9 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 			APIVersion: "argoproj.io/v1alpha1",
 		},
 		FauxObjectMeta: fauxargocd.FauxObjectMeta{
-			Name:      fields.crName,
-			Namespace: fields.crNamespace,
+			Name:        fields.crName,
+			Namespace:   fields.crNamespace,
+			Annotations: fields.annotations,
 		},
 		Spec: fauxargocd.FauxApplicationSpec{
 			Source: fauxargocd.ApplicationSource{
@@ -1162,15 +1941,35 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 				Name:      fields.destinationName,
 				Namespace: fields.destinationNamespace,
 			},
-			Project: "default",
+			Project: defaultArgoCDProject,
 		},
 	}
 
+	if fields.project != "" {
+		application.Spec.Project = fields.project
+	}
+
+	if len(fields.commonLabels) > 0 || len(fields.commonAnnotations) > 0 {
+		application.Spec.Source.Kustomize = &fauxargocd.ApplicationSourceKustomize{
+			CommonLabels:      fields.commonLabels,
+			CommonAnnotations: fields.commonAnnotations,
+		}
+	}
+
+	if len(fields.helmValueFiles) > 0 || fields.helmValues != "" {
+		application.Spec.Source.Helm = &fauxargocd.ApplicationSourceHelm{
+			ValueFiles: fields.helmValueFiles,
+			Values:     fields.helmValues,
+		}
+	}
+
+	application.Spec.Source.Directory = fields.directory
+
 	if fields.automated {
 		application.Spec.SyncPolicy = &fauxargocd.SyncPolicy{
 			Automated: &fauxargocd.SyncPolicyAutomated{
-				Prune:      true,
-				SelfHeal:   true,
+				Prune:      !fields.disablePrune,
+				SelfHeal:   !fields.disableSelfHeal,
 				AllowEmpty: true,
 			},
 			SyncOptions: fauxargocd.SyncOptions{
@@ -1207,8 +2006,83 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 		}
 	}
 
+	if len(fields.managedNamespaceLabels) > 0 || len(fields.managedNamespaceAnnotations) > 0 {
+
+		if application.Spec.SyncPolicy == nil {
+			application.Spec.SyncPolicy = &fauxargocd.SyncPolicy{}
+		}
+
+		application.Spec.SyncPolicy.ManagedNamespaceMetadata = &fauxargocd.ManagedNamespaceMetadata{
+			Labels:      fields.managedNamespaceLabels,
+			Annotations: fields.managedNamespaceAnnotations,
+		}
+	}
+
+	resBytes, err := goyaml.Marshal(application)
+
+	if err != nil {
+		return "", err
+	}
+	return string(resBytes), nil
+}
+
+// generateArgoCDApplicationAnnotations returns the annotations of the Argo CD Application of the GitOpsDeployment: those
+// generated from the annotations of the GitOpsDeployment, along with its deletion policy and resource health checks.
+func generateArgoCDApplicationAnnotations(gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment) (map[string]string, error) {
+	annotations := argosharedutil.SetDeletionPolicyAnnotation(argosharedutil.GenerateArgoCDApplicationAnnotations(gitopsDeployment.Annotations),
+		string(gitopsDeployment.Spec.DeletionPolicy))
+
+	healthChecks := []argosharedutil.ResourceHealthCheck{}
+	for _, healthCheck := range gitopsDeployment.Spec.ResourceHealthChecks {
+		healthChecks = append(healthChecks, argosharedutil.ResourceHealthCheck{
+			Group: healthCheck.Group,
+			Kind:  healthCheck.Kind,
+			Check: healthCheck.Check,
+		})
+	}
+
+	return argosharedutil.SetResourceHealthChecksAnnotation(annotations, healthChecks)
+}
+
+// setDeletionPolicyInSpecField returns the spec field, with the deletion policy annotation of the Argo CD Application set to
+// the given deletion policy.
+func setDeletionPolicyInSpecField(specField string, deletionPolicy managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy) (string, error) {
+
+	var application fauxargocd.FauxApplication
+	if err := goyaml.Unmarshal([]byte(specField), &application); err != nil {
+		return "", err
+	}
+
+	if application.FauxObjectMeta.Annotations[argosharedutil.DeletionPolicyAnnotation] == string(deletionPolicy) {
+		return specField, nil
+	}
+
+	application.FauxObjectMeta.Annotations = argosharedutil.SetDeletionPolicyAnnotation(application.FauxObjectMeta.Annotations,
+		string(deletionPolicy))
+
 	resBytes, err := goyaml.Marshal(application)
+	if err != nil {
+		return "", err
+	}
+	return string(resBytes), nil
+}
+
+// disableAutomatedSyncInSpecField returns the given Application spec field (as generated by createSpecField), with
+// automated sync disabled. The spec field is returned unchanged if automated sync is not enabled.
+func disableAutomatedSyncInSpecField(specField string) (string, error) {
+
+	var application fauxargocd.FauxApplication
+	if err := goyaml.Unmarshal([]byte(specField), &application); err != nil {
+		return "", err
+	}
+
+	if application.Spec.SyncPolicy == nil || application.Spec.SyncPolicy.Automated == nil {
+		return specField, nil
+	}
 
+	application.Spec.SyncPolicy.Automated = nil
+
+	resBytes, err := goyaml.Marshal(application)
 	if err != nil {
 		return "", err
 	}
@@ -1219,6 +2093,11 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 func decompressResourceData(resourceData []byte) ([]managedgitopsv1alpha1.ResourceStatus, error) {
 	var resourceList []managedgitopsv1alpha1.ResourceStatus
 
+	// The column is empty if the cluster-agent has not yet written the resources of the Application
+	if len(resourceData) == 0 {
+		return []managedgitopsv1alpha1.ResourceStatus{}, nil
+	}
+
 	// Decompress data to get actual resource string
 	bufferIn := bytes.NewBuffer(resourceData)
 	gzipReader, err := gzip.NewReader(bufferIn)
@@ -1254,6 +2133,63 @@ func decompressResourceData(resourceData []byte) ([]managedgitopsv1alpha1.Resour
 	return resourceList, nil
 }
 
+// getUnsupportedAPIGroupsOfApplication returns the sorted list of API groups of the given resources (of the Application)
+// that are not available on the target cluster of the Application's managed environment.
+//
+// Returns nil if the API groups of the cluster have not (yet) been discovered.
+func getUnsupportedAPIGroupsOfApplication(ctx context.Context, applicationID string, resources []managedgitopsv1alpha1.ResourceStatus,
+	dbQueries db.ApplicationScopedQueries) ([]string, error) {
+
+	if len(resources) == 0 {
+		return nil, nil
+	}
+
+	application := db.Application{Application_id: applicationID}
+	if err := dbQueries.GetApplicationById(ctx, &application); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to retrieve Application '%s': %w", applicationID, err)
+	}
+
+	if application.Managed_environment_id == "" {
+		return nil, nil
+	}
+
+	managedEnv := db.ManagedEnvironment{Managedenvironment_id: application.Managed_environment_id}
+	if err := dbQueries.GetManagedEnvironmentById(ctx, &managedEnv); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to retrieve ManagedEnvironment '%s': %w", application.Managed_environment_id, err)
+	}
+
+	if managedEnv.Api_groups == "" {
+		return nil, nil
+	}
+
+	availableAPIGroups := map[string]bool{}
+	for _, apiGroup := range strings.Split(managedEnv.Api_groups, ",") {
+		availableAPIGroups[apiGroup] = true
+	}
+
+	unsupportedAPIGroups := map[string]bool{}
+	for _, resource := range resources {
+		// The core API group (empty) is always available
+		if resource.Group != "" && !availableAPIGroups[resource.Group] {
+			unsupportedAPIGroups[resource.Group] = true
+		}
+	}
+
+	var res []string
+	for apiGroup := range unsupportedAPIGroups {
+		res = append(res, apiGroup)
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
+
 func retrieveComparedToFieldInApplicationState(reconciledState string) (fauxargocd.FauxComparedTo, error) {
 	comparedTo := &fauxargocd.FauxComparedTo{}
 
@@ -1265,6 +2201,163 @@ func retrieveComparedToFieldInApplicationState(reconciledState string) (fauxargo
 	return *comparedTo, err
 }
 
+// retrieveLastSyncFieldInApplicationState converts the last_sync field of an ApplicationState row into the
+// 'lastSync' field of the GitOpsDeployment status. Returns nil if Argo CD has not yet synced the Application.
+func retrieveLastSyncFieldInApplicationState(lastSyncField string) (*managedgitopsv1alpha1.LastSyncStatus, error) {
+	if lastSyncField == "" {
+		return nil, nil
+	}
+
+	lastSync := &fauxargocd.FauxLastSync{}
+	if err := json.Unmarshal([]byte(lastSyncField), lastSync); err != nil {
+		return nil, fmt.Errorf("unable to Unmarshal lastSync field: %v", err)
+	}
+
+	res := &managedgitopsv1alpha1.LastSyncStatus{
+		Initiator: lastSync.Initiator,
+		Revision:  lastSync.Revision,
+	}
+
+	// Times are converted to local time, to match the metav1.Time values of the existing GitOpsDeployment status (which
+	// are unmarshalled as local time), so that the status is not needlessly updated.
+	if lastSync.StartedAt != nil {
+		startedAt := metav1.NewTime(lastSync.StartedAt.Local())
+		res.StartedAt = &startedAt
+	}
+
+	if lastSync.FinishedAt != nil {
+		finishedAt := metav1.NewTime(lastSync.FinishedAt.Local())
+		res.FinishedAt = &finishedAt
+	}
+
+	return res, nil
+}
+
+// retrieveDiffPreviewFieldInApplicationState converts the diff_preview field of an ApplicationState row into the
+// 'diffPreview' field of the GitOpsDeployment status. Returns nil if no diff preview has been requested.
+func retrieveDiffPreviewFieldInApplicationState(diffPreviewField string) (*managedgitopsv1alpha1.DiffPreviewStatus, error) {
+	if diffPreviewField == "" {
+		return nil, nil
+	}
+
+	diffPreview := &fauxargocd.FauxDiffPreview{}
+	if err := json.Unmarshal([]byte(diffPreviewField), diffPreview); err != nil {
+		return nil, fmt.Errorf("unable to Unmarshal diffPreview field: %v", err)
+	}
+
+	res := &managedgitopsv1alpha1.DiffPreviewStatus{
+		RequestID:      diffPreview.RequestID,
+		TotalResources: diffPreview.TotalResources,
+		Error:          diffPreview.Error,
+	}
+
+	// As with lastSync, the time is converted to local time, so that the status is not needlessly updated.
+	if diffPreview.GeneratedAt != nil {
+		generatedAt := metav1.NewTime(diffPreview.GeneratedAt.Local())
+		res.GeneratedAt = &generatedAt
+	}
+
+	for _, resource := range diffPreview.Resources {
+		res.Resources = append(res.Resources, managedgitopsv1alpha1.ResourceDiffSummary{
+			Group:         resource.Group,
+			Kind:          resource.Kind,
+			Name:          resource.Name,
+			Namespace:     resource.Namespace,
+			Action:        resource.Action,
+			ChangedFields: resource.ChangedFields,
+		})
+	}
+
+	return res, nil
+}
+
+// retrieveResourceEstimateFieldInApplicationState converts the resource_estimate field of an ApplicationState row into
+// the 'resourceEstimate' field of the GitOpsDeployment status. Returns nil if no estimate has been collected.
+func retrieveResourceEstimateFieldInApplicationState(resourceEstimateField string) (*managedgitopsv1alpha1.ResourceEstimateStatus, error) {
+	if resourceEstimateField == "" {
+		return nil, nil
+	}
+
+	resourceEstimate := &fauxargocd.FauxResourceEstimate{}
+	if err := json.Unmarshal([]byte(resourceEstimateField), resourceEstimate); err != nil {
+		return nil, fmt.Errorf("unable to Unmarshal resourceEstimate field: %v", err)
+	}
+
+	res := &managedgitopsv1alpha1.ResourceEstimateStatus{
+		CPURequests:    resourceEstimate.CPURequests,
+		MemoryRequests: resourceEstimate.MemoryRequests,
+		Pods:           resourceEstimate.Pods,
+		TotalPods:      resourceEstimate.TotalPods,
+		Error:          resourceEstimate.Error,
+	}
+
+	// The estimate is periodically re-collected, so the time is also truncated to the precision of the unmarshalled
+	// GitOpsDeployment status (seconds): otherwise the status would be updated on every tick.
+	if resourceEstimate.CollectedAt != nil {
+		collectedAt := metav1.NewTime(resourceEstimate.CollectedAt.Local().Truncate(time.Second))
+		res.CollectedAt = &collectedAt
+	}
+
+	return res, nil
+}
+
+// retrieveOperationStateFieldInApplicationState converts the operation_state field of an ApplicationState row into the
+// 'operationState' field of the GitOpsDeployment status. Returns nil if no operation is in progress.
+func retrieveOperationStateFieldInApplicationState(operationStateField string) (*managedgitopsv1alpha1.OperationStateStatus, error) {
+	if operationStateField == "" {
+		return nil, nil
+	}
+
+	operationState := &fauxargocd.FauxOperationState{}
+	if err := json.Unmarshal([]byte(operationStateField), operationState); err != nil {
+		return nil, fmt.Errorf("unable to Unmarshal operationState field: %v", err)
+	}
+
+	res := &managedgitopsv1alpha1.OperationStateStatus{
+		Type:    managedgitopsv1alpha1.OperationStateType(operationState.Type),
+		Message: operationState.Message,
+	}
+
+	// The times are truncated to the precision of the unmarshalled GitOpsDeployment status (seconds): otherwise the
+	// status would be updated on every tick.
+	if operationState.StartedAt != nil {
+		startedAt := metav1.NewTime(operationState.StartedAt.Local().Truncate(time.Second))
+		res.StartedAt = &startedAt
+	}
+
+	if operationState.EstimatedCompletionAt != nil {
+		estimatedCompletionAt := metav1.NewTime(operationState.EstimatedCompletionAt.Local().Truncate(time.Second))
+		res.EstimatedCompletionAt = &estimatedCompletionAt
+	}
+
+	return res, nil
+}
+
+// convertDeploymentHistoryToStatus converts DeploymentHistory rows into the 'history' field of the GitOpsDeployment
+// status, preserving their order. Returns nil if there are no rows.
+func convertDeploymentHistoryToStatus(deploymentHistory []db.DeploymentHistory) []managedgitopsv1alpha1.DeploymentHistoryEntry {
+
+	var res []managedgitopsv1alpha1.DeploymentHistoryEntry
+
+	for _, entry := range deploymentHistory {
+
+		historyEntry := managedgitopsv1alpha1.DeploymentHistoryEntry{
+			Revision:  entry.Revision,
+			Initiator: entry.Initiator,
+			// As with lastSync, the time is converted to local time to match the unmarshalled GitOpsDeployment status.
+			DeployedAt: metav1.NewTime(entry.DeployedAt.Local()),
+		}
+
+		if entry.Images != "" {
+			historyEntry.Images = strings.Split(entry.Images, ",")
+		}
+
+		res = append(res, historyEntry)
+	}
+
+	return res
+}
+
 func getInt64Pointer(i int) *int64 {
 	i64 := int64(i)
 	return &i64
@@ -3,6 +3,7 @@ package application_event_loop
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -11,7 +12,9 @@ import (
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
 	"gopkg.in/yaml.v2"
@@ -19,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -118,6 +122,141 @@ var _ = Describe("Application Event Runner Deployments", func() {
 			Expect(err).To(BeNil())
 			Expect(application).To(Equal(getValidApplication(true)))
 		})
+
+		It("Input spec with notification annotations should set them on the Application metadata", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.annotations = map[string]string{
+				"notifications.argoproj.io/subscribe.on-sync-failed.slack": "channel-a;channel-`b",
+			}
+
+			application, err := createSpecField(input)
+			Expect(err).To(BeNil())
+
+			fauxApplication := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(application), &fauxApplication)).To(Succeed())
+			Expect(fauxApplication.FauxObjectMeta.Annotations).To(Equal(map[string]string{
+				"notifications.argoproj.io/subscribe.on-sync-failed.slack": "channel-a;channel-b",
+			}))
+		})
+
+		It("Input spec with common labels and annotations should set them in the Kustomize options of the Application source", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.commonLabels = map[string]string{
+				"appstudio.redhat.com/workspace": "my-workspace",
+			}
+			input.commonAnnotations = map[string]string{
+				"cost-center": "team-'a'",
+			}
+
+			application, err := createSpecField(input)
+			Expect(err).To(BeNil())
+
+			fauxApplication := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(application), &fauxApplication)).To(Succeed())
+			Expect(fauxApplication.Spec.Source.Kustomize).ToNot(BeNil())
+			Expect(fauxApplication.Spec.Source.Kustomize.CommonLabels).To(Equal(map[string]string{
+				"appstudio.redhat.com/workspace": "my-workspace",
+			}))
+			Expect(fauxApplication.Spec.Source.Kustomize.CommonAnnotations).To(Equal(map[string]string{
+				"cost-center": "team-a",
+			}))
+		})
+
+		It("Input spec without common labels and annotations should not set Kustomize options", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			application, err := createSpecField(input)
+			Expect(err).To(BeNil())
+			Expect(application).ToNot(ContainSubstring("kustomize"))
+		})
+
+		It("Input spec with managed namespace metadata should set it in the sync policy of the Application", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.syncOptions = []string{string(managedgitopsv1alpha1.SyncOptions_CreateNamespace_true)}
+			input.managedNamespaceLabels = map[string]string{
+				"pod-security.kubernetes.io/enforce": "restricted",
+			}
+			input.managedNamespaceAnnotations = map[string]string{
+				"example.com/owner": "team-'a'",
+			}
+
+			application, err := createSpecField(input)
+			Expect(err).To(BeNil())
+
+			fauxApplication := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(application), &fauxApplication)).To(Succeed())
+			Expect(fauxApplication.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(fauxApplication.Spec.SyncPolicy.ManagedNamespaceMetadata).To(Equal(&fauxargocd.ManagedNamespaceMetadata{
+				Labels:      map[string]string{"pod-security.kubernetes.io/enforce": "restricted"},
+				Annotations: map[string]string{"example.com/owner": "team-a"},
+			}))
+		})
+
+		It("Input spec with pruning disabled should set an automated sync policy which self-heals, but does not prune", func() {
+			input := getFakeArgoCDSpecInput(true, false)
+			input.disablePrune = true
+
+			application, err := createSpecField(input)
+			Expect(err).To(BeNil())
+
+			fauxApplication := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(application), &fauxApplication)).To(Succeed())
+			Expect(fauxApplication.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(fauxApplication.Spec.SyncPolicy.Automated.Prune).To(BeFalse())
+			Expect(fauxApplication.Spec.SyncPolicy.Automated.SelfHeal).To(BeTrue())
+		})
+
+		It("Input spec with Helm values should set them, unsanitized, in the Helm options of the Application source", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.helmValueFiles = []string{"values-staging.yaml"}
+			input.helmValues = "replicaCount: 3\ningress:\n  host: \"staging.example.com\"\n"
+
+			application, err := createSpecField(input)
+			Expect(err).To(BeNil())
+
+			fauxApplication := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(application), &fauxApplication)).To(Succeed())
+			Expect(fauxApplication.Spec.Source.Helm).ToNot(BeNil())
+			Expect(fauxApplication.Spec.Source.Helm.ValueFiles).To(Equal([]string{"values-staging.yaml"}))
+			Expect(fauxApplication.Spec.Source.Helm.Values).To(Equal(input.helmValues))
+		})
+
+		It("Input spec with directory options should set them in the directory options of the Application source", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.directory = convertSourceDirectoryToArgoCD(&managedgitopsv1alpha1.ApplicationSourceDirectory{
+				Recurse: true,
+				Include: "{*.yaml,*.jsonnet}",
+				Exclude: "test/*",
+				Jsonnet: &managedgitopsv1alpha1.ApplicationSourceJsonnet{
+					ExtVars: []managedgitopsv1alpha1.JsonnetVar{{Name: "env", Value: "staging"}},
+					TLAs:    []managedgitopsv1alpha1.JsonnetVar{{Name: "replicas'", Value: "{\"count\": 3}", Code: true}},
+					Libs:    []string{"vendor"},
+				},
+			})
+
+			application, err := createSpecField(input)
+			Expect(err).To(BeNil())
+
+			fauxApplication := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(application), &fauxApplication)).To(Succeed())
+			Expect(fauxApplication.Spec.Source.Directory).To(Equal(&fauxargocd.ApplicationSourceDirectory{
+				Recurse: true,
+				Include: "{*.yaml,*.jsonnet}",
+				Exclude: "test/*",
+				Jsonnet: &fauxargocd.ApplicationSourceJsonnet{
+					ExtVars: []fauxargocd.JsonnetVar{{Name: "env", Value: "staging"}},
+					// The name is sanitized, but the value (which is Jsonnet code) is not
+					TLAs: []fauxargocd.JsonnetVar{{Name: "replicas", Value: "{\"count\": 3}", Code: true}},
+					Libs: []string{"vendor"},
+				},
+			}))
+		})
+
+		It("Input spec without directory options should not set them", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			application, err := createSpecField(input)
+			Expect(err).To(BeNil())
+			Expect(application).ToNot(ContainSubstring("directory"))
+		})
 	})
 })
 
@@ -299,6 +438,161 @@ var _ = Describe("Application Event Runner Deployments to check SyncPolicy.SyncO
 		})
 
 	})
+
+	Context("Test checkValidSyncOption function", func() {
+
+		DescribeTable("should only accept supported, non-conflicting sync options",
+			func(syncOptions managedgitopsv1alpha1.SyncOptions, expectValid bool) {
+				userErr := checkValidSyncOption(syncOptions)
+				Expect(userErr == nil).To(Equal(expectValid))
+			},
+			Entry("no sync options", managedgitopsv1alpha1.SyncOptions{}, true),
+			Entry("all supported sync options", managedgitopsv1alpha1.SyncOptions{
+				managedgitopsv1alpha1.SyncOptions_CreateNamespace_true,
+				managedgitopsv1alpha1.SyncOptions_ServerSideApply_true,
+				managedgitopsv1alpha1.SyncOptions_Validate_false,
+				managedgitopsv1alpha1.SyncOptions_ApplyOutOfSyncOnly_true,
+			}, true),
+			Entry("the same sync option specified twice", managedgitopsv1alpha1.SyncOptions{
+				managedgitopsv1alpha1.SyncOptions_Validate_false,
+				managedgitopsv1alpha1.SyncOptions_Validate_false,
+			}, true),
+			Entry("unsupported sync option", managedgitopsv1alpha1.SyncOptions{"PruneLast=true"}, false),
+			Entry("unsupported sync option value", managedgitopsv1alpha1.SyncOptions{"ServerSideApply=maybe"}, false),
+			Entry("conflicting sync options", managedgitopsv1alpha1.SyncOptions{
+				managedgitopsv1alpha1.SyncOptions_ServerSideApply_true,
+				managedgitopsv1alpha1.SyncOptions_ServerSideApply_false,
+			}, false),
+		)
+
+		It("should propagate the sync options to the spec field of the Argo CD Application", func() {
+			specField, err := createSpecField(argoCDSpecInput{
+				crName:               "sample-depl",
+				crNamespace:          "workspace",
+				destinationNamespace: "prod",
+				destinationName:      "in-cluster",
+				sourceRepoURL:        "https://github.com/test/test",
+				sourcePath:           "environments/prod",
+				automated:            true,
+				syncOptions: managedgitopsv1alpha1.SyncOptionToStringSlice(managedgitopsv1alpha1.SyncOptions{
+					managedgitopsv1alpha1.SyncOptions_ServerSideApply_true,
+					managedgitopsv1alpha1.SyncOptions_ApplyOutOfSyncOnly_true,
+				}),
+			})
+			Expect(err).To(BeNil())
+
+			app := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(specField), &app)).To(Succeed())
+			Expect(app.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(app.Spec.SyncPolicy.SyncOptions).To(ContainElements(
+				string(managedgitopsv1alpha1.SyncOptions_ServerSideApply_true),
+				string(managedgitopsv1alpha1.SyncOptions_ApplyOutOfSyncOnly_true)))
+		})
+	})
+
+	Context("Testing the Argo CD project of GitOpsDeployments with scoped repository credentials", func() {
+
+		const repoURL = "https://github.com/test/private-repo"
+
+		newRepoCred := func(name string, namespace string, scope *managedgitopsv1alpha1.RepositoryCredentialScope) managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential {
+			return managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					UID:       types.UID(name + "-uid"),
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialSpec{
+					Repository: repoURL,
+					Secret:     "private-repo-secret",
+					Scope:      scope,
+				},
+			}
+		}
+
+		gitopsDepl := managedgitopsv1alpha1.GitOpsDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-gitops-depl",
+				Namespace: "tenant-a",
+				Labels:    map[string]string{"team": "frontend"},
+			},
+			Spec: managedgitopsv1alpha1.GitOpsDeploymentSpec{
+				Source: managedgitopsv1alpha1.ApplicationSource{RepoURL: repoURL + ".git"},
+			},
+		}
+
+		projectOf := func(name string) string {
+			return argosharedutil.GenerateArgoCDRepositoryCredentialProjectName(name + "-uid")
+		}
+
+		It("should use the default project if there are no scoped credentials for the GitOpsDeployment", func() {
+			Expect(selectArgoCDProjectForGitOpsDeployment(gitopsDepl, nil)).To(Equal(defaultArgoCDProject))
+
+			Expect(selectArgoCDProjectForGitOpsDeployment(gitopsDepl, []managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+				newRepoCred("unscoped", "tenant-a", nil),
+				newRepoCred("other-tenant", "tenant-b", &managedgitopsv1alpha1.RepositoryCredentialScope{}),
+			})).To(Equal(defaultArgoCDProject))
+		})
+
+		It("should use the project of a scoped credential whose deployment selector matches the GitOpsDeployment", func() {
+			Expect(selectArgoCDProjectForGitOpsDeployment(gitopsDepl, []managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+				newRepoCred("backend-only", "tenant-a", &managedgitopsv1alpha1.RepositoryCredentialScope{
+					DeploymentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "backend"}},
+				}),
+				newRepoCred("frontend-only", "tenant-a", &managedgitopsv1alpha1.RepositoryCredentialScope{
+					DeploymentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "frontend"}},
+				}),
+			})).To(Equal(projectOf("frontend-only")))
+		})
+
+		It("should prefer the credentials of the namespace of the GitOpsDeployment, over scoped credentials of other namespaces", func() {
+			sharedRepoCred := newRepoCred("shared", "tenant-b", &managedgitopsv1alpha1.RepositoryCredentialScope{
+				Namespaces: []string{"tenant-a", "tenant-b"},
+			})
+
+			Expect(selectArgoCDProjectForGitOpsDeployment(gitopsDepl, []managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+				sharedRepoCred,
+			})).To(Equal(projectOf("shared")))
+
+			Expect(selectArgoCDProjectForGitOpsDeployment(gitopsDepl, []managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+				sharedRepoCred,
+				newRepoCred("unscoped", "tenant-a", nil),
+			})).To(Equal(defaultArgoCDProject))
+		})
+
+		It("should set the project in the spec field of the Argo CD Application", func() {
+			specField, err := createSpecField(argoCDSpecInput{
+				crName:               "sample-depl",
+				crNamespace:          "workspace",
+				destinationNamespace: "prod",
+				destinationName:      "in-cluster",
+				sourceRepoURL:        repoURL,
+				project:              projectOf("frontend-only"),
+			})
+			Expect(err).To(BeNil())
+
+			app := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(specField), &app)).To(Succeed())
+			Expect(app.Spec.Project).To(Equal(projectOf("frontend-only")))
+		})
+	})
+
+	Context("removeUnsupportedSyncOptions should skip the sync options that the version of Argo CD does not support", func() {
+
+		syncOptions := []managedgitopsv1alpha1.SyncOption{
+			managedgitopsv1alpha1.SyncOptions_CreateNamespace_true,
+			managedgitopsv1alpha1.SyncOptions_ServerSideApply_true,
+		}
+
+		It("should skip the ServerSideApply sync option if Argo CD is older than v2.5", func() {
+			Expect(removeUnsupportedSyncOptions(syncOptions, "v2.4.14", log.FromContext(context.Background()))).
+				To(Equal([]managedgitopsv1alpha1.SyncOption{managedgitopsv1alpha1.SyncOptions_CreateNamespace_true}))
+		})
+
+		It("should keep all sync options if Argo CD supports them, or if the version of Argo CD is not known", func() {
+			Expect(removeUnsupportedSyncOptions(syncOptions, "v2.6.3", log.FromContext(context.Background()))).To(Equal(syncOptions))
+			Expect(removeUnsupportedSyncOptions(syncOptions, "", log.FromContext(context.Background()))).To(Equal(syncOptions))
+		})
+	})
 })
 
 var _ = Describe("ApplicationEventLoop Handle deployment modified Test", func() {
@@ -769,6 +1063,14 @@ var _ = Describe("ApplicationEventLoop Handle deployment modified Test", func()
 			err = dbQueries.GetApplicationById(context.Background(), &application)
 			Expect(err).To(BeNil())
 
+			By("verifying the user of the GitOpsDeployment's namespace is an owner of the Application")
+			applicationOwner := db.ApplicationOwner{
+				ApplicationID: application.Application_id,
+				ClusterUserID: clusterUser.Clusteruser_id,
+			}
+			err = dbQueries.GetApplicationOwnerByPrimaryKey(ctx, &applicationOwner)
+			Expect(err).To(BeNil())
+
 			gitopsEngineInstance := db.GitopsEngineInstance{
 				Gitopsengineinstance_id: application.Engine_instance_inst_id,
 			}
@@ -795,6 +1097,10 @@ var _ = Describe("ApplicationEventLoop Handle deployment modified Test", func()
 			Expect(err).ToNot(BeNil())
 			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
 
+			// ApplicationOwner should be removed with the Application
+			err = dbQueries.GetApplicationOwnerByPrimaryKey(ctx, &applicationOwner)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
 			// DeploymentToApplicationMapping should be removed, too
 			var appMappings []db.DeploymentToApplicationMapping
 			err = dbQueries.ListDeploymentToApplicationMappingByNamespaceAndName(context.Background(), gitopsDepl.Name, gitopsDepl.Namespace, workspaceID, &appMappings)
@@ -825,6 +1131,82 @@ var _ = Describe("ApplicationEventLoop Handle deployment modified Test", func()
 
 		})
 
+		It("should soft-delete the Application of a deleted GitOpsDeployment, and restore it when the GitOpsDeployment is recreated", func() {
+
+			Expect(os.Setenv(sharedutil.ApplicationSoftDeleteGracePeriodEnvVar, "60")).To(Succeed())
+			defer func() {
+				Expect(os.Unsetenv(sharedutil.ApplicationSoftDeleteGracePeriodEnvVar)).To(Succeed())
+			}()
+
+			countOperationsCreated := func() int {
+				res := 0
+				for _, event := range informer.Events {
+					if event.Action == sharedutil.Create && event.ObjectTypeOf() == "Operation" {
+						res++
+					}
+				}
+				return res
+			}
+
+			By("creating the GitOpsDeployment")
+			_, application, _, result, userDevErr := appEventLoopRunnerAction.applicationEventRunner_handleDeploymentModified(ctx, dbQueries)
+			Expect(userDevErr).To(BeNil())
+			Expect(result).To(Equal(deploymentModifiedResult_Created))
+			operationsCreated := countOperationsCreated()
+
+			By("deleting the GitOpsDeployment, and verifying the Application is soft-deleted")
+			Expect(k8sClient.Delete(ctx, gitopsDepl)).To(Succeed())
+
+			_, _, _, _, userDevErr = appEventLoopRunnerAction.applicationEventRunner_handleDeploymentModified(ctx, dbQueries)
+			Expect(userDevErr).To(BeNil())
+
+			softDeletedApplication := db.Application{Application_id: application.Application_id}
+			Expect(dbQueries.GetApplicationById(ctx, &softDeletedApplication)).To(Succeed())
+			Expect(softDeletedApplication.IsSoftDeleted()).To(BeTrue())
+			Expect(application.Spec_field).To(ContainSubstring("automated"))
+			Expect(softDeletedApplication.Spec_field).ToNot(ContainSubstring("automated"),
+				"automated sync should be disabled while the Application is soft-deleted")
+
+			var appMappings []db.DeploymentToApplicationMapping
+			err = dbQueries.ListDeploymentToApplicationMappingByNamespaceAndName(ctx, gitopsDepl.Name, gitopsDepl.Namespace, workspaceID, &appMappings)
+			Expect(err).To(BeNil())
+			Expect(appMappings).To(HaveLen(1))
+			Expect(appMappings[0].Application_id).To(Equal(application.Application_id))
+
+			Expect(countOperationsCreated()).To(Equal(operationsCreated+1),
+				"a single operation should be created, to disable automated sync on the Argo CD Application")
+			operationsCreated = countOperationsCreated()
+
+			By("recreating the GitOpsDeployment, and verifying the Application is restored")
+			recreatedGitOpsDepl := gitopsDepl.DeepCopy()
+			recreatedGitOpsDepl.ResourceVersion = ""
+			recreatedGitOpsDepl.UID = uuid.NewUUID()
+			Expect(k8sClient.Create(ctx, recreatedGitOpsDepl)).To(Succeed())
+
+			_, restoredApplication, _, result, userDevErr := appEventLoopRunnerAction.applicationEventRunner_handleDeploymentModified(ctx, dbQueries)
+			Expect(userDevErr).To(BeNil())
+			Expect(result).To(Equal(deploymentModifiedResult_Updated), "automated sync should be re-enabled")
+			Expect(restoredApplication.Application_id).To(Equal(application.Application_id))
+			Expect(restoredApplication.Name).To(Equal(application.Name))
+			Expect(restoredApplication.Spec_field).To(Equal(application.Spec_field))
+			Expect(restoredApplication.IsSoftDeleted()).To(BeFalse())
+			Expect(countOperationsCreated()).To(Equal(operationsCreated + 1))
+
+			appMappings = []db.DeploymentToApplicationMapping{}
+			err = dbQueries.ListDeploymentToApplicationMappingByNamespaceAndName(ctx, gitopsDepl.Name, gitopsDepl.Namespace, workspaceID, &appMappings)
+			Expect(err).To(BeNil())
+			Expect(appMappings).To(HaveLen(1))
+			Expect(appMappings[0].Deploymenttoapplicationmapping_uid_id).To(Equal(string(recreatedGitOpsDepl.UID)))
+			Expect(appMappings[0].Application_id).To(Equal(application.Application_id))
+			Expect(appMappings[0].RestoredFromDeploymentUID).To(Equal(string(gitopsDepl.UID)))
+
+			By("processing another event for the recreated GitOpsDeployment, which should accept the name of the restored Application")
+			_, restoredApplication, _, result, userDevErr = appEventLoopRunnerAction.applicationEventRunner_handleDeploymentModified(ctx, dbQueries)
+			Expect(userDevErr).To(BeNil())
+			Expect(result).To(Equal(deploymentModifiedResult_NoChange))
+			Expect(restoredApplication.Application_id).To(Equal(application.Application_id))
+		})
+
 		It("create an invalid deployment and ensure it fails.", func() {
 			ctx := context.Background()
 
@@ -927,4 +1309,100 @@ var _ = Describe("ApplicationEventLoop Handle deployment modified Test", func()
 				"since the Namespace is being deleted, the request should not be acted upon")
 		})
 	})
+
+	Context("Test verifyRepositoryIsAllowedByManagedEnvironment", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+		var gitopsDepl managedgitopsv1alpha1.GitOpsDeployment
+		var managedEnvCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment
+		var action applicationEventLoopRunner_Action
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			scheme, argocdNamespace, kubesystemNamespace, workspace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			managedEnvCR = managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-managed-env",
+					Namespace: workspace.Name,
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec{
+					APIURL:              "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443",
+					AllowedRepositories: []string{"https://github.com/my-org/*", "https://github.com/other-org/my-repo.git"},
+				},
+			}
+
+			gitopsDepl = managedgitopsv1alpha1.GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-gitops-depl",
+					Namespace: workspace.Name,
+					UID:       uuid.NewUUID(),
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentSpec{
+					Source: managedgitopsv1alpha1.ApplicationSource{
+						RepoURL: "https://github.com/my-org/my-repo",
+						Path:    "resources",
+					},
+					Destination: managedgitopsv1alpha1.ApplicationDestination{
+						Environment: managedEnvCR.Name,
+						Namespace:   "my-namespace",
+					},
+				},
+			}
+
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(workspace, argocdNamespace, kubesystemNamespace, &managedEnvCR).Build()
+
+			action = applicationEventLoopRunner_Action{
+				workspaceClient:        k8sClient,
+				eventResourceNamespace: workspace.Name,
+				log:                    log.FromContext(ctx),
+			}
+		})
+
+		It("should allow repositories that match an allowed repository of the managed environment", func() {
+
+			for _, repoURL := range []string{"https://github.com/my-org/my-repo", "https://github.com/My-Org/my-repo.git/",
+				"https://github.com/other-org/my-repo"} {
+
+				gitopsDepl.Spec.Source.RepoURL = repoURL
+				Expect(action.verifyRepositoryIsAllowedByManagedEnvironment(ctx, gitopsDepl)).To(BeNil(), repoURL)
+			}
+		})
+
+		It("should reject repositories that do not match an allowed repository of the managed environment", func() {
+
+			for _, repoURL := range []string{"https://github.com/other-org/other-repo", "https://github.com/my-org/my-repo/subdir",
+				"https://gitlab.com/my-org/my-repo"} {
+
+				gitopsDepl.Spec.Source.RepoURL = repoURL
+				userErr := action.verifyRepositoryIsAllowedByManagedEnvironment(ctx, gitopsDepl)
+				Expect(userErr).ToNot(BeNil(), repoURL)
+				Expect(userErr.UserError()).To(ContainSubstring("is not allowed by the managed environment 'my-managed-env'"))
+
+				conditionErr, ok := userErr.(gitopserrors.ConditionError)
+				Expect(ok).To(BeTrue())
+				Expect(conditionErr.ConditionReason()).To(Equal(string(managedgitopsv1alpha1.GitopsDeploymentReasonRepositoryNotAllowed)))
+			}
+		})
+
+		It("should allow all repositories, if the managed environment does not restrict them, or the workspace is targeted", func() {
+
+			gitopsDepl.Spec.Source.RepoURL = "https://github.com/other-org/other-repo"
+
+			By("targeting the workspace, rather than the managed environment")
+			gitopsDepl.Spec.Destination.Environment = ""
+			Expect(action.verifyRepositoryIsAllowedByManagedEnvironment(ctx, gitopsDepl)).To(BeNil())
+
+			By("removing the allowed repositories of the managed environment")
+			gitopsDepl.Spec.Destination.Environment = managedEnvCR.Name
+			managedEnvCR.Spec.AllowedRepositories = nil
+			err := k8sClient.Update(ctx, &managedEnvCR)
+			Expect(err).To(BeNil())
+			Expect(action.verifyRepositoryIsAllowedByManagedEnvironment(ctx, gitopsDepl)).To(BeNil())
+		})
+	})
 })
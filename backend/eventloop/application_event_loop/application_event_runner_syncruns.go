@@ -2,13 +2,17 @@ package application_event_loop
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
@@ -24,6 +28,10 @@ const (
 	ErrDeploymentNameIsImmutable = "deployment name field is immutable: changing it from its initial value is not supported"
 
 	ErrRevisionIsImmutable = "revision change is not supported: changing it from its initial value is not supported"
+
+	ErrResourcesAreImmutable = "resources field is immutable: changing it from its initial value is not supported"
+
+	ErrRetryPolicyIsImmutable = "retry policy field is immutable: changing it from its initial value is not supported"
 )
 
 // This file is responsible for processing events related to GitOpsDeploymentSyncRun CR.
@@ -144,6 +152,15 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 		return gitopserrors.NewUserDevError(userError, devError)
 	}
 
+	// Scope the database queries to the Applications of the namespace: the Applications of other namespaces are not accessible.
+	tenantDBQueries, err := db.NewTenantScopedQueries(dbQueries, db.Tenant{
+		NamespaceUID:  string(namespace.UID),
+		ClusterUserID: clusterUser.Clusteruser_id,
+	})
+	if err != nil {
+		return gitopserrors.NewDevOnlyError(err)
+	}
+
 	// Retrieve the GitOpsDeploymentSyncRun from the namespace
 	syncRunCRExists := true // True if the GitOpsDeployment resource exists in the namespace, false otherwise
 	syncRunCR := &managedgitopsv1alpha1.GitOpsDeploymentSyncRun{}
@@ -159,6 +176,12 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 				log.Error(err, "unable to locate object in handleSyncRunModified", "request", syncRunKey)
 				return gitopserrors.NewUserDevError(userError, err)
 			}
+		} else {
+			// Include the correlation ID of the GitOpsDeploymentSyncRun in the logs of the work we do for it, and in the Operations we create
+			correlationID := logutil.CorrelationIDOfObject(syncRunCR)
+			ctx = logutil.ContextWithCorrelationID(ctx, correlationID)
+			a.log = a.log.WithValues(logutil.LogCorrelationIDKey, correlationID)
+			log = a.log
 		}
 	}
 
@@ -182,7 +205,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 			DBRelationType:  db.APICRToDatabaseMapping_DBRelationType_SyncOperation,
 		}
 
-		if err := dbQueries.GetDatabaseMappingForAPICR(ctx, &mapping); err != nil {
+		if err := tenantDBQueries.GetDatabaseMappingForAPICR(ctx, &mapping); err != nil {
 			if db.IsResultNotFoundError(err) {
 				// No corresponding entry
 				dbEntryExists = false
@@ -203,7 +226,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 		// The CR no longer exists (it was likely deleted), so instead we retrieve the UID of the SyncRun from
 		// the APICRToDatabaseMapping table, by combination of (name/namespace/namespace uid).
 
-		if err := dbQueries.ListAPICRToDatabaseMappingByAPINamespaceAndName(ctx, db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun,
+		if err := tenantDBQueries.ListAPICRToDatabaseMappingByAPINamespaceAndName(ctx, db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun,
 			a.eventResourceName, a.eventResourceNamespace, eventlooptypes.GetWorkspaceIDFromNamespaceID(namespace),
 			db.APICRToDatabaseMapping_DBRelationType_SyncOperation, &apiCRToDBList); err != nil {
 			userError := "unable to retrive data related to previous GitOpsDeploymentSyncRun in the namespace, due to an unknown error"
@@ -251,7 +274,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 
 		syncOperation = db.SyncOperation{SyncOperation_id: apiCRToDBMapping.DBRelationKey}
 
-		if err := dbQueries.GetSyncOperationById(ctx, &syncOperation); err != nil {
+		if err := tenantDBQueries.GetSyncOperationById(ctx, &syncOperation); err != nil {
 
 			log.Error(err, "unable to retrieve sync operation by id on modified", "operationID", syncOperation.SyncOperation_id)
 			return gitopserrors.NewDevOnlyError(err)
@@ -288,6 +311,15 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 			return gitopserrors.NewDevOnlyError(fmt.Errorf("SEVERE - All cases should be handled by above if statements"))
 		}
 
+		// If the GitOpsDeployment doesn't specify its type, use the GitOpsDeployment defaults of the namespace
+		if gitopsDepl.Spec.Type == "" {
+			defaults, userErr := getGitOpsDeploymentDefaults(ctx, a.workspaceClient, gitopsDepl.Namespace)
+			if userErr != nil {
+				return userErr
+			}
+			defaults.applyDefaultsToSpec(gitopsDepl)
+		}
+
 		// return an error if 'Automated' sync policy is enabled. Argo CD doesn't allow syncing an Application with automated sync policy.
 		if gitopsDepl.Spec.Type != managedgitopsv1alpha1.GitOpsDeploymentSpecType_Manual {
 			userErr := fmt.Sprintf("invalid GitOpsDeploymentSyncRun '%s'. Syncing a GitOpsDeployment with Automated sync policy is not allowed", syncRunCR.Name)
@@ -299,13 +331,13 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 		// The GitopsDepl CR exists, so use the UID of the CR to retrieve the database entry, if possible
 		deplToAppMapping := &db.DeploymentToApplicationMapping{Deploymenttoapplicationmapping_uid_id: string(gitopsDepl.UID)}
 
-		if err = dbQueries.GetDeploymentToApplicationMappingByDeplId(ctx, deplToAppMapping); err != nil {
+		if err = tenantDBQueries.GetDeploymentToApplicationMappingByDeplId(ctx, deplToAppMapping); err != nil {
 			log.Error(err, "unable to retrieve deployment to application mapping, on sync run modified", "uid", string(gitopsDepl.UID))
 			return gitopserrors.NewDevOnlyError(err)
 		}
 
 		application = &db.Application{Application_id: deplToAppMapping.Application_id}
-		if err := dbQueries.GetApplicationById(ctx, application); err != nil {
+		if err := tenantDBQueries.GetApplicationById(ctx, application); err != nil {
 			log.Error(err, "unable to retrieve application, on sync run modified", "applicationId", string(deplToAppMapping.Application_id))
 			return gitopserrors.NewDevOnlyError(err)
 		}
@@ -321,14 +353,14 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 			// Handle update:
 			// If both GitOpsDeploymentSyncRun CR and the DB entry exists, then the CR is being updated.
 			// Validate and return an error if the immutable fields are updated.
-			return a.handleUpdatedGitOpsDeplSyncRunEvent(ctx, syncRunCR, dbQueries, syncOperation)
+			return a.handleUpdatedGitOpsDeplSyncRunEvent(ctx, syncRunCR, tenantDBQueries, syncOperation)
 		} else {
 			// Handle create:
 			// If the gitopsdeplsyncrun CR exists, but the database entry doesn't, then this is the first time we
 			// have seen the GitOpsDeplSyncRun CR.
 			// Create it in the DB and create the operation.
 
-			return a.handleNewGitOpsDeplSyncRunEvent(ctx, syncRunCR, dbQueries, application, gitopsEngineInstance, namespace, *clusterUser)
+			return a.handleNewGitOpsDeplSyncRunEvent(ctx, syncRunCR, tenantDBQueries, application, gitopsEngineInstance, namespace, *clusterUser)
 		}
 
 	}
@@ -337,7 +369,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 		// Handle delete:
 		// If the gitopsdeplsyncrun CR doesn't exist, but database row does, then the CR has been deleted, so handle it.
 
-		return a.handleDeletedGitOpsDeplSyncRunEvent(ctx, dbQueries, syncOperation, apiCRToDBList, namespace, clusterUser)
+		return a.handleDeletedGitOpsDeplSyncRunEvent(ctx, tenantDBQueries, syncOperation, apiCRToDBList, namespace, clusterUser)
 	}
 
 	return nil
@@ -351,7 +383,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 //
 // Returns:
 // - error is non-nil, if an error occurred
-func (a *applicationEventLoopRunner_Action) handleDeletedGitOpsDeplSyncRunEvent(ctx context.Context, dbQueries db.ApplicationScopedQueries, syncOperation db.SyncOperation, apiCRToDBList []db.APICRToDatabaseMapping, namespace corev1.Namespace, clusterUser *db.ClusterUser) gitopserrors.UserError {
+func (a *applicationEventLoopRunner_Action) handleDeletedGitOpsDeplSyncRunEvent(ctx context.Context, dbQueries db.TenantScopedQueries, syncOperation db.SyncOperation, apiCRToDBList []db.APICRToDatabaseMapping, namespace corev1.Namespace, clusterUser *db.ClusterUser) gitopserrors.UserError {
 
 	// Deleting the CR should terminate a sync operation, if it was previously in progress.
 
@@ -485,7 +517,7 @@ func (a *applicationEventLoopRunner_Action) handleDeletedGitOpsDeplSyncRunEvent(
 //
 // Returns:
 // - error is non-nil, if an error occurred
-func (a *applicationEventLoopRunner_Action) handleNewGitOpsDeplSyncRunEvent(ctx context.Context, syncRunCRParam *managedgitopsv1alpha1.GitOpsDeploymentSyncRun, dbQueries db.ApplicationScopedQueries, application *db.Application, gitopsEngineInstance *db.GitopsEngineInstance, namespace corev1.Namespace, clusterUser db.ClusterUser) gitopserrors.UserError {
+func (a *applicationEventLoopRunner_Action) handleNewGitOpsDeplSyncRunEvent(ctx context.Context, syncRunCRParam *managedgitopsv1alpha1.GitOpsDeploymentSyncRun, dbQueries db.TenantScopedQueries, application *db.Application, gitopsEngineInstance *db.GitopsEngineInstance, namespace corev1.Namespace, clusterUser db.ClusterUser) gitopserrors.UserError {
 
 	log := a.log
 	log.Info("Received GitOpsDeploymentSyncRun event for a new GitOpsDeploymentSyncRun resource")
@@ -500,17 +532,32 @@ func (a *applicationEventLoopRunner_Action) handleNewGitOpsDeplSyncRunEvent(ctx
 		return gitopserrors.NewDevOnlyError(err)
 	}
 
+	if err := syncRunCRParam.Spec.ValidateRetryPolicy(); err != nil {
+		log.Error(err, "GitOpsDeploymentSyncRun has an invalid retry policy")
+		return gitopserrors.NewUserDevError(err.Error(), err)
+	}
+
 	// createdResources is a list of database entries created in this function; if an error occurs, we delete them
 	// in reverse order.
 	var createdResources []db.AppScopedDisposableResource
 
+	syncResources, err := convertSyncRunResourcesToJSON(syncRunCRParam.Spec.Resources)
+	if err != nil {
+		log.Error(err, "unable to convert the resources of GitOpsDeploymentSyncRun to JSON")
+		return gitopserrors.NewDevOnlyError(err)
+	}
+
 	// Create sync operation
 	syncOperation := &db.SyncOperation{
 		Application_id:      application.Application_id,
 		DeploymentNameField: syncRunCRParam.Spec.GitopsDeploymentName,
 		Revision:            syncRunCRParam.Spec.RevisionID,
 		DesiredState:        db.SyncOperation_DesiredState_Running,
+		Initiator:           db.SyncOperation_Initiator_SyncRunPrefix + syncRunCRParam.Name,
+		Resources:           syncResources,
 	}
+	setSyncOperationRetryPolicy(syncOperation, syncRunCRParam.Spec.RetryPolicy)
+
 	if err := dbQueries.CreateSyncOperation(ctx, syncOperation); err != nil {
 		log.Error(err, "unable to create sync operation in database")
 
@@ -622,11 +669,11 @@ outer_for:
 }
 
 // handleUpdatedGitOpsDeplSyncRunEvent handles GitOpsDeploymentSyncRun events where the user has just updated an existing GitOpsDeploymentSyncRun resource.
-// In this case, we need to ensure that the immutable fields GitOpsDeploymentName and RevisionID are not updated.
+// In this case, we need to ensure that the immutable fields GitOpsDeploymentName, RevisionID, Resources and RetryPolicy are not updated.
 //
 // Returns:
 // - error is non-nil, if an error occurred
-func (a *applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplSyncRunEvent(ctx context.Context, syncRunCR *managedgitopsv1alpha1.GitOpsDeploymentSyncRun, dbQueries db.ApplicationScopedQueries, syncOperation db.SyncOperation) gitopserrors.UserError {
+func (a *applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplSyncRunEvent(ctx context.Context, syncRunCR *managedgitopsv1alpha1.GitOpsDeploymentSyncRun, dbQueries db.TenantScopedQueries, syncOperation db.SyncOperation) gitopserrors.UserError {
 	log := a.log
 	log.Info("Received GitOpsDeploymentSyncRun event for an existing GitOpsDeploymentSyncRun resource")
 
@@ -642,11 +689,86 @@ func (a *applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplSyncRunEvent(
 		return gitopserrors.NewUserDevError(ErrRevisionIsImmutable, err)
 	}
 
+	syncResources, err := convertSyncRunResourcesToJSON(syncRunCR.Spec.Resources)
+	if err != nil {
+		log.Error(err, "unable to convert the resources of GitOpsDeploymentSyncRun to JSON")
+		return gitopserrors.NewDevOnlyError(err)
+	}
+
+	if syncOperation.Resources != syncResources {
+		err := fmt.Errorf(ErrResourcesAreImmutable)
+		log.Error(err, ErrResourcesAreImmutable)
+		return gitopserrors.NewUserDevError(ErrResourcesAreImmutable, err)
+	}
+
+	retryPolicySyncOperation := db.SyncOperation{}
+	setSyncOperationRetryPolicy(&retryPolicySyncOperation, syncRunCR.Spec.RetryPolicy)
+
+	if syncOperation.RetryLimit != retryPolicySyncOperation.RetryLimit ||
+		syncOperation.RetryBackoffDuration != retryPolicySyncOperation.RetryBackoffDuration ||
+		syncOperation.RetryBackoffFactor != retryPolicySyncOperation.RetryBackoffFactor ||
+		syncOperation.RetryBackoffMaxDuration != retryPolicySyncOperation.RetryBackoffMaxDuration {
+		err := fmt.Errorf(ErrRetryPolicyIsImmutable)
+		log.Error(err, ErrRetryPolicyIsImmutable)
+		return gitopserrors.NewUserDevError(ErrRetryPolicyIsImmutable, err)
+	}
+
 	return nil
 }
 
+// setSyncOperationRetryPolicy sets the retry fields of the SyncOperation from the retry policy of a GitOpsDeploymentSyncRun.
+// If the retry policy is nil, the retry fields are cleared, which indicates that the sync operation should not be retried.
+func setSyncOperationRetryPolicy(syncOperation *db.SyncOperation, retryPolicy *managedgitopsv1alpha1.SyncRunRetryPolicy) {
+
+	syncOperation.RetryLimit = 0
+	syncOperation.RetryBackoffDuration = ""
+	syncOperation.RetryBackoffFactor = 0
+	syncOperation.RetryBackoffMaxDuration = ""
+
+	if retryPolicy == nil {
+		return
+	}
+
+	syncOperation.RetryLimit = retryPolicy.Limit
+
+	if retryPolicy.Backoff != nil {
+		syncOperation.RetryBackoffDuration = retryPolicy.Backoff.Duration
+		syncOperation.RetryBackoffMaxDuration = retryPolicy.Backoff.MaxDuration
+		if retryPolicy.Backoff.Factor != nil {
+			syncOperation.RetryBackoffFactor = *retryPolicy.Backoff.Factor
+		}
+	}
+}
+
+// convertSyncRunResourcesToJSON converts the resources of a GitOpsDeploymentSyncRun into the JSON string that is stored
+// in the 'resources' field of the SyncOperation. An empty string is returned if no resources are specified, which
+// indicates that all the resources of the Application should be synced.
+func convertSyncRunResourcesToJSON(resources []managedgitopsv1alpha1.SyncRunResource) (string, error) {
+
+	if len(resources) == 0 {
+		return "", nil
+	}
+
+	syncResources := make([]fauxargocd.FauxSyncOperationResource, 0, len(resources))
+	for _, resource := range resources {
+		syncResources = append(syncResources, fauxargocd.FauxSyncOperationResource{
+			Group:     resource.Group,
+			Kind:      resource.Kind,
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+		})
+	}
+
+	jsonBytes, err := json.Marshal(syncResources)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonBytes), nil
+}
+
 func (a *applicationEventLoopRunner_Action) cleanupOldSyncDBEntry(ctx context.Context, apiCRToDB *db.APICRToDatabaseMapping,
-	clusterUser db.ClusterUser, dbQueries db.ApplicationScopedQueries) error {
+	clusterUser db.ClusterUser, dbQueries db.TenantScopedQueries) error {
 
 	log := a.log
 
@@ -704,3 +826,74 @@ func (a *applicationEventLoopRunner_Action) cleanupOldSyncDBEntry(ctx context.Co
 
 	return nil
 }
+
+// updateSyncRunHookStatus sets .status.hooks of the GitOpsDeploymentSyncRun that requested the last sync operation of the
+// GitOpsDeployment, from the sync_hooks field of the ApplicationState of the GitOpsDeployment.
+//
+// This is a no-op if the last sync operation was not requested by a GitOpsDeploymentSyncRun of the GitOpsDeployment, or
+// if that GitOpsDeploymentSyncRun no longer exists (or was recreated after the sync operation started).
+func updateSyncRunHookStatus(ctx context.Context, k8sClient client.Client, gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment,
+	syncHooksField string) error {
+
+	if syncHooksField == "" {
+		return nil
+	}
+
+	syncHooks := fauxargocd.FauxSyncHooks{}
+	if err := json.Unmarshal([]byte(syncHooksField), &syncHooks); err != nil {
+		return fmt.Errorf("unable to Unmarshal syncHooks field: %v", err)
+	}
+
+	if !strings.HasPrefix(syncHooks.Initiator, db.SyncOperation_Initiator_SyncRunPrefix) {
+		return nil
+	}
+	syncRunName := strings.TrimPrefix(syncHooks.Initiator, db.SyncOperation_Initiator_SyncRunPrefix)
+
+	syncRunCR, err := getGitOpsDeploymentSyncRun(ctx, k8sClient, syncRunName, gitopsDeployment.Namespace)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve GitOpsDeploymentSyncRun '%s': %v", syncRunName, err)
+	}
+
+	if syncRunCR.Spec.GitopsDeploymentName != gitopsDeployment.Name {
+		return nil
+	}
+
+	// A GitOpsDeploymentSyncRun that was created after the sync operation started did not request it
+	if syncHooks.StartedAt != nil && syncRunCR.CreationTimestamp.Time.After(*syncHooks.StartedAt) {
+		return nil
+	}
+
+	hooks := convertSyncHooksToStatus(syncHooks.Hooks)
+	if reflect.DeepEqual(hooks, syncRunCR.Status.Hooks) {
+		return nil
+	}
+
+	syncRunCR.Status.Hooks = hooks
+
+	return k8sClient.Status().Update(ctx, syncRunCR)
+}
+
+// convertSyncHooksToStatus converts the hooks of the sync_hooks field of an ApplicationState row into the 'hooks' field
+// of the GitOpsDeploymentSyncRun status, preserving their order. Returns nil if there are no hooks.
+func convertSyncHooksToStatus(syncHooks []fauxargocd.FauxSyncHook) []managedgitopsv1alpha1.SyncRunHookStatus {
+
+	var res []managedgitopsv1alpha1.SyncRunHookStatus
+
+	for _, hook := range syncHooks {
+		res = append(res, managedgitopsv1alpha1.SyncRunHookStatus{
+			Group:     hook.Group,
+			Kind:      hook.Kind,
+			Name:      hook.Name,
+			Namespace: hook.Namespace,
+			HookType:  hook.HookType,
+			Phase:     hook.Phase,
+			Message:   hook.Message,
+			LogTail:   hook.LogTail,
+		})
+	}
+
+	return res
+}
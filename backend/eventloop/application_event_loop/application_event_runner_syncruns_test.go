@@ -2,7 +2,9 @@ package application_event_loop
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -10,6 +12,7 @@ import (
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -115,6 +118,9 @@ var _ = Describe("Application Event Runner SyncRuns", func() {
 			Expect(err).To(BeNil())
 			Expect(syncOperation.DeploymentNameField).Should(Equal(gitopsDeplSyncRun.Spec.GitopsDeploymentName))
 			Expect(syncOperation.Revision).Should(Equal(gitopsDeplSyncRun.Spec.RevisionID))
+			Expect(syncOperation.Initiator).Should(Equal(db.SyncOperation_Initiator_SyncRunPrefix + gitopsDeplSyncRun.Name))
+			Expect(syncOperation.Resources).Should(BeEmpty())
+			Expect(syncOperation.RetryLimit).Should(BeZero())
 
 			By("verify if an Operation CR is created")
 			operationCreated, operationDeleted := false, false
@@ -179,6 +185,87 @@ var _ = Describe("Application Event Runner SyncRuns", func() {
 			userDevErr = applicationAction.applicationEventRunner_handleSyncRunModifiedInternal(ctx, dbQueries)
 			Expect(userDevErr.DevError().Error()).Should(Equal(ErrRevisionIsImmutable))
 			Expect(userDevErr.UserError()).Should(Equal(ErrRevisionIsImmutable))
+
+			gitopsDeplSyncRun.Spec.RevisionID = "HEAD"
+			err = k8sClient.Update(ctx, gitopsDeplSyncRun)
+			Expect(err).To(BeNil())
+
+			By("verify if the field .spec.resources of GitOpsDeploymentSyncRun is immutable")
+			gitopsDeplSyncRun.Spec.Resources = []managedgitopsv1alpha1.SyncRunResource{
+				{Group: "apps", Kind: "Deployment", Name: "my-deployment", Namespace: gitopsDepl.Namespace},
+			}
+			err = k8sClient.Update(ctx, gitopsDeplSyncRun)
+			Expect(err).To(BeNil())
+			userDevErr = applicationAction.applicationEventRunner_handleSyncRunModifiedInternal(ctx, dbQueries)
+			Expect(userDevErr.DevError().Error()).Should(Equal(ErrResourcesAreImmutable))
+			Expect(userDevErr.UserError()).Should(Equal(ErrResourcesAreImmutable))
+
+			gitopsDeplSyncRun.Spec.Resources = nil
+			err = k8sClient.Update(ctx, gitopsDeplSyncRun)
+			Expect(err).To(BeNil())
+
+			By("verify if the field .spec.retryPolicy of GitOpsDeploymentSyncRun is immutable")
+			gitopsDeplSyncRun.Spec.RetryPolicy = &managedgitopsv1alpha1.SyncRunRetryPolicy{Limit: 3}
+			err = k8sClient.Update(ctx, gitopsDeplSyncRun)
+			Expect(err).To(BeNil())
+			userDevErr = applicationAction.applicationEventRunner_handleSyncRunModifiedInternal(ctx, dbQueries)
+			Expect(userDevErr.DevError().Error()).Should(Equal(ErrRetryPolicyIsImmutable))
+			Expect(userDevErr.UserError()).Should(Equal(ErrRetryPolicyIsImmutable))
+		})
+
+		It("should store the retry policy of the SyncRun CR in the SyncOperation", func() {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDeplSyncRun), gitopsDeplSyncRun)
+			Expect(err).To(BeNil())
+
+			By("delete the SyncRun without a retry policy, and re-create it with a retry policy")
+			err = k8sClient.Delete(ctx, gitopsDeplSyncRun)
+			Expect(err).To(BeNil())
+			userDevErr := applicationAction.applicationEventRunner_handleSyncRunModifiedInternal(ctx, dbQueries)
+			Expect(userDevErr).To(BeNil())
+
+			factor := int64(3)
+			gitopsDeplSyncRun = &managedgitopsv1alpha1.GitOpsDeploymentSyncRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      gitopsDeplSyncRun.Name,
+					Namespace: gitopsDeplSyncRun.Namespace,
+					UID:       uuid.NewUUID(),
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentSyncRunSpec{
+					GitopsDeploymentName: gitopsDepl.Name,
+					RevisionID:           "HEAD",
+					RetryPolicy: &managedgitopsv1alpha1.SyncRunRetryPolicy{
+						Limit: 5,
+						Backoff: &managedgitopsv1alpha1.SyncRunRetryBackoff{
+							Duration:    "10s",
+							Factor:      &factor,
+							MaxDuration: "5m",
+						},
+					},
+				},
+			}
+			err = k8sClient.Create(ctx, gitopsDeplSyncRun)
+			Expect(err).To(BeNil())
+
+			userDevErr = applicationAction.applicationEventRunner_handleSyncRunModifiedInternal(ctx, dbQueries)
+			Expect(userDevErr).To(BeNil())
+
+			mapping := db.APICRToDatabaseMapping{
+				APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun,
+				APIResourceName:      gitopsDeplSyncRun.Name,
+				APIResourceNamespace: gitopsDeplSyncRun.Namespace,
+				APIResourceUID:       string(gitopsDeplSyncRun.UID),
+				DBRelationType:       db.APICRToDatabaseMapping_DBRelationType_SyncOperation,
+			}
+			err = dbQueries.GetDatabaseMappingForAPICR(ctx, &mapping)
+			Expect(err).To(BeNil())
+
+			syncOperation := db.SyncOperation{SyncOperation_id: mapping.DBRelationKey}
+			err = dbQueries.GetSyncOperationById(ctx, &syncOperation)
+			Expect(err).To(BeNil())
+			Expect(syncOperation.RetryLimit).Should(Equal(int64(5)))
+			Expect(syncOperation.RetryBackoffDuration).Should(Equal("10s"))
+			Expect(syncOperation.RetryBackoffFactor).Should(Equal(int64(3)))
+			Expect(syncOperation.RetryBackoffMaxDuration).Should(Equal("5m"))
 		})
 
 		It("should terminate the SyncOperation and create an Operation when the SyncRun CR is deleted", func() {
@@ -504,4 +591,93 @@ var _ = Describe("Application Event Runner SyncRuns", func() {
 			Expect(syncRunCR).Should(SatisfyAll(haveErrOccurredConditionSet(expectedSyncRunStatus)))
 		})
 	})
+	Context("Update the hooks of a GitOpsDeploymentSyncRun", func() {
+
+		var (
+			ctx              context.Context
+			k8sClient        client.Client
+			syncRunCR        *managedgitopsv1alpha1.GitOpsDeploymentSyncRun
+			gitopsDeployment managedgitopsv1alpha1.GitOpsDeployment
+			startedAt        time.Time
+		)
+
+		BeforeEach(func() {
+			scheme, _, _, workspace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+
+			gitopsDeployment = managedgitopsv1alpha1.GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-deployment",
+					Namespace: workspace.Name,
+				},
+			}
+
+			syncRunCR = &managedgitopsv1alpha1.GitOpsDeploymentSyncRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-syncrun",
+					Namespace:         workspace.Name,
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second)),
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentSyncRunSpec{
+					GitopsDeploymentName: gitopsDeployment.Name,
+				},
+			}
+
+			startedAt = time.Now().Truncate(time.Second)
+
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(syncRunCR).Build()
+		})
+
+		syncHooksField := func(initiator string) string {
+			syncHooksBytes, err := json.Marshal(fauxargocd.FauxSyncHooks{
+				Initiator: initiator,
+				StartedAt: &startedAt,
+				Hooks: []fauxargocd.FauxSyncHook{
+					{Group: "batch", Kind: "Job", Namespace: "guestbook", Name: "db-migration", HookType: "PreSync", Phase: "Failed",
+						Message: "Job has reached the specified backoff limit", LogTail: "ERROR: relation \"users\" already exists"},
+				},
+			})
+			Expect(err).To(BeNil())
+			return string(syncHooksBytes)
+		}
+
+		It("should set the hooks of the GitOpsDeploymentSyncRun that requested the sync operation", func() {
+
+			err := updateSyncRunHookStatus(ctx, k8sClient, gitopsDeployment, syncHooksField(db.SyncOperation_Initiator_SyncRunPrefix+syncRunCR.Name))
+			Expect(err).To(BeNil())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(syncRunCR), syncRunCR)).To(Succeed())
+			Expect(syncRunCR.Status.Hooks).To(Equal([]managedgitopsv1alpha1.SyncRunHookStatus{
+				{Group: "batch", Kind: "Job", Namespace: "guestbook", Name: "db-migration", HookType: "PreSync", Phase: "Failed",
+					Message: "Job has reached the specified backoff limit", LogTail: "ERROR: relation \"users\" already exists"},
+			}))
+		})
+
+		It("should not modify a GitOpsDeploymentSyncRun that did not request the sync operation", func() {
+
+			By("using a sync operation that was not requested by a GitOpsDeploymentSyncRun")
+			Expect(updateSyncRunHookStatus(ctx, k8sClient, gitopsDeployment, syncHooksField(db.SyncOperation_Initiator_Automated))).To(Succeed())
+
+			By("using a GitOpsDeploymentSyncRun of another GitOpsDeployment")
+			otherDeployment := *gitopsDeployment.DeepCopy()
+			otherDeployment.Name = "other-deployment"
+			Expect(updateSyncRunHookStatus(ctx, k8sClient, otherDeployment, syncHooksField(db.SyncOperation_Initiator_SyncRunPrefix+syncRunCR.Name))).To(Succeed())
+
+			By("using a GitOpsDeploymentSyncRun that no longer exists")
+			Expect(updateSyncRunHookStatus(ctx, k8sClient, gitopsDeployment, syncHooksField(db.SyncOperation_Initiator_SyncRunPrefix+"deleted-syncrun"))).To(Succeed())
+
+			By("using a sync operation that started before the GitOpsDeploymentSyncRun was created")
+			startedAt = syncRunCR.CreationTimestamp.Add(-time.Minute)
+			Expect(updateSyncRunHookStatus(ctx, k8sClient, gitopsDeployment, syncHooksField(db.SyncOperation_Initiator_SyncRunPrefix+syncRunCR.Name))).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(syncRunCR), syncRunCR)).To(Succeed())
+			Expect(syncRunCR.Status.Hooks).To(BeEmpty())
+		})
+
+		It("should return an error if the sync_hooks field is invalid", func() {
+			Expect(updateSyncRunHookStatus(ctx, k8sClient, gitopsDeployment, "{invalid")).ToNot(Succeed())
+		})
+	})
 })
@@ -8,6 +8,7 @@ import (
 
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
 
@@ -414,6 +415,35 @@ var _ = Describe("ApplicationEventLoop Test", func() {
 			Expect(matchingCondition).ToNot(BeNil())
 			Expect(matchingCondition.Status).To(Equal(managedgitopsv1alpha1.GitOpsConditionStatusFalse))
 
+			By("recording two deployed revisions of the Application, and verifying they are reported in the status, newest first")
+			firstDeployedAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+			for i, revision := range []string{"abcdefg", "hijklmn"} {
+				deploymentHistory := db.DeploymentHistory{
+					ApplicationID:   deplToAppMapping.Application_id,
+					ArgoCDHistoryID: int64(i),
+					Revision:        revision,
+					Images:          "quay.io/org/app:" + revision,
+					Initiator:       db.SyncOperation_Initiator_Automated,
+					DeployedAt:      firstDeployedAt.Add(time.Duration(i) * time.Hour),
+				}
+				err = dbQueries.CreateDeploymentHistory(ctx, &deploymentHistory)
+				Expect(err).To(BeNil())
+			}
+
+			updated, err = a.applicationEventRunner_handleUpdateDeploymentStatusTick(ctx, gitopsDepl.Name, gitopsDepl.Namespace, dbQueries)
+			Expect(err).To(BeNil())
+			Expect(updated).To(BeTrue())
+
+			clientErr = a.workspaceClient.Get(ctx, gitopsDeploymentKey, gitopsDeployment)
+			Expect(clientErr).To(BeNil())
+
+			Expect(gitopsDeployment.Status.History).To(HaveLen(2))
+			Expect(gitopsDeployment.Status.History[0].Revision).To(Equal("hijklmn"))
+			Expect(gitopsDeployment.Status.History[0].Images).To(Equal([]string{"quay.io/org/app:hijklmn"}))
+			Expect(gitopsDeployment.Status.History[0].Initiator).To(Equal(db.SyncOperation_Initiator_Automated))
+			Expect(gitopsDeployment.Status.History[0].DeployedAt.Time.Equal(firstDeployedAt.Add(time.Hour))).To(BeTrue())
+			Expect(gitopsDeployment.Status.History[1].Revision).To(Equal("abcdefg"))
+
 			By("attempting to update the deployment status tick, even though nothing has changed.")
 			updated, err = a.applicationEventRunner_handleUpdateDeploymentStatusTick(ctx, gitopsDepl.Name, gitopsDepl.Namespace, dbQueries)
 			Expect(err).To(BeNil())
@@ -957,6 +987,210 @@ var _ = Describe("ApplicationEventLoop Test", func() {
 
 	})
 
+	Context("Check retrieveLastSyncFieldInApplicationState function.", func() {
+		It("should return nil if the Application has not been synced", func() {
+			lastSync, err := retrieveLastSyncFieldInApplicationState("")
+			Expect(err).To(BeNil())
+			Expect(lastSync).To(BeNil())
+		})
+
+		It("should convert the last_sync field into the lastSync status field", func() {
+			startedAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+			lastSyncBytes, err := json.Marshal(fauxargocd.FauxLastSync{
+				Initiator: db.SyncOperation_Initiator_SyncRunPrefix + "my-sync-run",
+				StartedAt: &startedAt,
+				Revision:  "abc123",
+			})
+			Expect(err).To(BeNil())
+
+			lastSync, err := retrieveLastSyncFieldInApplicationState(string(lastSyncBytes))
+			Expect(err).To(BeNil())
+			Expect(lastSync.Initiator).To(Equal("GitOpsDeploymentSyncRun/my-sync-run"))
+			Expect(lastSync.Revision).To(Equal("abc123"))
+			Expect(lastSync.StartedAt.Equal(&metav1.Time{Time: startedAt})).To(BeTrue())
+			Expect(lastSync.FinishedAt).To(BeNil())
+		})
+
+		It("should return an error if the last_sync field is invalid", func() {
+			_, err := retrieveLastSyncFieldInApplicationState("{invalid")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("Check retrieveDiffPreviewFieldInApplicationState function.", func() {
+		It("should return nil if no diff preview has been requested", func() {
+			diffPreview, err := retrieveDiffPreviewFieldInApplicationState("")
+			Expect(err).To(BeNil())
+			Expect(diffPreview).To(BeNil())
+		})
+
+		It("should convert the diff_preview field into the diffPreview status field", func() {
+			generatedAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+			diffPreviewBytes, err := json.Marshal(fauxargocd.FauxDiffPreview{
+				RequestID:   "request-1",
+				GeneratedAt: &generatedAt,
+				Resources: []fauxargocd.FauxResourceDiff{
+					{Group: "apps", Kind: "Deployment", Name: "my-deployment", Namespace: "my-namespace", Action: "Update", ChangedFields: 2},
+				},
+				TotalResources: 1,
+			})
+			Expect(err).To(BeNil())
+
+			diffPreview, err := retrieveDiffPreviewFieldInApplicationState(string(diffPreviewBytes))
+			Expect(err).To(BeNil())
+			Expect(diffPreview.RequestID).To(Equal("request-1"))
+			Expect(diffPreview.GeneratedAt.Equal(&metav1.Time{Time: generatedAt})).To(BeTrue())
+			Expect(diffPreview.TotalResources).To(Equal(1))
+			Expect(diffPreview.Resources).To(Equal([]managedgitopsv1alpha1.ResourceDiffSummary{
+				{Group: "apps", Kind: "Deployment", Name: "my-deployment", Namespace: "my-namespace", Action: "Update", ChangedFields: 2},
+			}))
+		})
+
+		It("should return an error if the diff_preview field is invalid", func() {
+			_, err := retrieveDiffPreviewFieldInApplicationState("{invalid")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("Check retrieveResourceEstimateFieldInApplicationState function.", func() {
+		It("should return nil if no resource estimate has been collected", func() {
+			resourceEstimate, err := retrieveResourceEstimateFieldInApplicationState("")
+			Expect(err).To(BeNil())
+			Expect(resourceEstimate).To(BeNil())
+		})
+
+		It("should convert the resource_estimate field into the resourceEstimate status field, truncating the time to seconds", func() {
+			collectedAt := time.Date(2023, 1, 1, 10, 0, 0, 123456789, time.UTC)
+
+			resourceEstimateBytes, err := json.Marshal(fauxargocd.FauxResourceEstimate{
+				CollectedAt:    &collectedAt,
+				Revision:       "my-revision",
+				CPURequests:    "1500m",
+				MemoryRequests: "2Gi",
+				Pods:           3,
+				TotalPods:      3,
+			})
+			Expect(err).To(BeNil())
+
+			resourceEstimate, err := retrieveResourceEstimateFieldInApplicationState(string(resourceEstimateBytes))
+			Expect(err).To(BeNil())
+			Expect(resourceEstimate.CollectedAt.Equal(&metav1.Time{Time: collectedAt.Truncate(time.Second)})).To(BeTrue())
+			Expect(resourceEstimate.CPURequests).To(Equal("1500m"))
+			Expect(resourceEstimate.MemoryRequests).To(Equal("2Gi"))
+			Expect(resourceEstimate.Pods).To(Equal(3))
+			Expect(resourceEstimate.TotalPods).To(Equal(3))
+			Expect(resourceEstimate.Error).To(BeEmpty())
+		})
+
+		It("should return an error if the resource_estimate field is invalid", func() {
+			_, err := retrieveResourceEstimateFieldInApplicationState("{invalid")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("Check retrieveOperationStateFieldInApplicationState function.", func() {
+		It("should return nil if no operation is in progress", func() {
+			operationState, err := retrieveOperationStateFieldInApplicationState("")
+			Expect(err).To(BeNil())
+			Expect(operationState).To(BeNil())
+		})
+
+		It("should convert the operation_state field into the operationState status field, truncating the times to seconds", func() {
+			startedAt := time.Date(2023, 1, 1, 10, 0, 0, 123456789, time.UTC)
+			estimatedCompletionAt := startedAt.Add(90 * time.Second)
+
+			operationStateBytes, err := json.Marshal(fauxargocd.FauxOperationState{
+				Type:                  string(managedgitopsv1alpha1.OperationStateTypeSyncRun),
+				StartedAt:             &startedAt,
+				Message:               "waiting for healthy state of apps/Deployment/my-deployment",
+				EstimatedCompletionAt: &estimatedCompletionAt,
+			})
+			Expect(err).To(BeNil())
+
+			operationState, err := retrieveOperationStateFieldInApplicationState(string(operationStateBytes))
+			Expect(err).To(BeNil())
+			Expect(operationState.Type).To(Equal(managedgitopsv1alpha1.OperationStateTypeSyncRun))
+			Expect(operationState.Message).To(Equal("waiting for healthy state of apps/Deployment/my-deployment"))
+			Expect(operationState.StartedAt.Equal(&metav1.Time{Time: startedAt.Truncate(time.Second)})).To(BeTrue())
+			Expect(operationState.EstimatedCompletionAt.Equal(&metav1.Time{Time: estimatedCompletionAt.Truncate(time.Second)})).To(BeTrue())
+		})
+
+		It("should return an error if the operation_state field is invalid", func() {
+			_, err := retrieveOperationStateFieldInApplicationState("{invalid")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("Check getUnsupportedAPIGroupsOfApplication function.", func() {
+
+		var ctx context.Context
+		var dbQueries db.AllDatabaseQueries
+		var managedEnvironment *db.ManagedEnvironment
+		var application db.Application
+
+		resources := []managedgitopsv1alpha1.ResourceStatus{
+			{Group: "", Version: "v1", Kind: "Service", Name: "my-service"},
+			{Group: "apps", Version: "v1", Kind: "Deployment", Name: "my-deployment"},
+			{Group: "route.openshift.io", Version: "v1", Kind: "Route", Name: "my-route"},
+			{Group: "tekton.dev", Version: "v1beta1", Kind: "Pipeline", Name: "my-pipeline"},
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			dbQueries, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			var engineInstance *db.GitopsEngineInstance
+			_, managedEnvironment, _, engineInstance, _, err = db.CreateSampleData(dbQueries)
+			Expect(err).To(BeNil())
+
+			application = db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "my-application",
+				Spec_field:              "{}",
+				Engine_instance_inst_id: engineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbQueries.CreateApplication(ctx, &application)
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			dbQueries.CloseDatabase()
+		})
+
+		It("should return nil if the API groups of the cluster have not been discovered", func() {
+			unsupportedAPIGroups, err := getUnsupportedAPIGroupsOfApplication(ctx, application.Application_id, resources, dbQueries)
+			Expect(err).To(BeNil())
+			Expect(unsupportedAPIGroups).To(BeNil())
+		})
+
+		It("should return the sorted API groups of the resources that are not available on the cluster", func() {
+			managedEnvironment.Api_groups = "apps,batch"
+			err := dbQueries.UpdateManagedEnvironment(ctx, managedEnvironment)
+			Expect(err).To(BeNil())
+
+			unsupportedAPIGroups, err := getUnsupportedAPIGroupsOfApplication(ctx, application.Application_id, resources, dbQueries)
+			Expect(err).To(BeNil())
+			Expect(unsupportedAPIGroups).To(Equal([]string{"route.openshift.io", "tekton.dev"}))
+
+			By("verifying nil is returned once all the API groups are available")
+			managedEnvironment.Api_groups = "apps,route.openshift.io,tekton.dev"
+			err = dbQueries.UpdateManagedEnvironment(ctx, managedEnvironment)
+			Expect(err).To(BeNil())
+
+			unsupportedAPIGroups, err = getUnsupportedAPIGroupsOfApplication(ctx, application.Application_id, resources, dbQueries)
+			Expect(err).To(BeNil())
+			Expect(unsupportedAPIGroups).To(BeNil())
+		})
+	})
+
 	Context("Check decompressResourceData function.", func() {
 		It("Should decompress resource data and return actual Array of ResourceStatus objects.", func() {
 			// ----------------------------------------------------------------------------
@@ -1811,6 +2045,10 @@ func (f MockSRLK8sClientFactory) GetK8sClientForServiceWorkspace() (client.Clien
 	return f.fakeClient, nil
 }
 
+func (f MockSRLK8sClientFactory) DiscoverClusterCapabilities(restConfig *rest.Config) (shared_resource_loop.ClusterCapabilities, error) {
+	return shared_resource_loop.ClusterCapabilities{}, nil
+}
+
 var _ = Describe("Miscellaneous application_event_runner.go tests", func() {
 
 	Context("Test handleManagedEnvironmentModified", func() {
@@ -0,0 +1,43 @@
+package eventloop
+
+import (
+	"sync"
+
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+)
+
+// The background tasks of the backend (the periodic reconciliation/cleanup of the database, the generation of usage
+// reports, and the resumption of namespace offboardings/migrations that were interrupted by a restart) are run by a
+// shared task queue, rather than each on its own goroutine: this bounds the number of background tasks that run at once, recovers from panics, and reports
+// the state of the tasks as metrics (see sharedutil.TaskQueue).
+//
+// Each background task has a fixed key, so a task that is (re)started while it is already waiting is de-duplicated.
+
+const (
+	backgroundTaskQueueName    = "backend-background-tasks"
+	backgroundTaskQueueWorkers = 4
+
+	databaseReconcilerTaskKey          = "database-reconciler"
+	repoCredReconcilerTaskKey          = "repocred-reconciler"
+	databaseMetricsReconcilerTaskKey   = "database-metrics-reconciler"
+	operationBacklogMonitorTaskKey     = "operation-backlog-monitor"
+	namespaceOffboardingResumerTaskKey = "namespace-offboarding-resumer"
+	namespaceMigrationResumerTaskKey   = "namespace-migration-resumer"
+	usageReportGeneratorTaskKey        = "usage-report-generator"
+
+	// clusterUserOffboardingTaskKeyPrefix is followed by the ID of the ClusterUser whose data is being deleted
+	clusterUserOffboardingTaskKeyPrefix = "clusteruser-offboarding-"
+)
+
+var (
+	backgroundTaskQueue     *sharedutil.TaskQueue
+	backgroundTaskQueueOnce sync.Once
+)
+
+// getBackgroundTaskQueue returns the task queue of the background tasks of the backend, starting it on first use.
+func getBackgroundTaskQueue() *sharedutil.TaskQueue {
+	backgroundTaskQueueOnce.Do(func() {
+		backgroundTaskQueue = sharedutil.NewTaskQueue(backgroundTaskQueueName, backgroundTaskQueueWorkers)
+	})
+	return backgroundTaskQueue
+}
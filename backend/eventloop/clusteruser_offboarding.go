@@ -0,0 +1,190 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+// ClusterUser offboarding removes a user, and the data of that user, from the GitOps Service (for example, to fulfil a
+// GDPR erasure request). It is triggered by an administrator (see the admin routes of the backend), in two phases:
+//
+// - Deactivate: the ClusterUser is marked as deactivated, and its ClusterAccess rows are deleted, so that the user no
+//   longer has access to any ManagedEnvironment. The deletion of the RepositoryCredentials of the user, and of the
+//   Applications that are owned only by the user, is then scheduled in the background task queue: Operations are
+//   created to inform the cluster-agent, so that it deletes the corresponding Argo CD resources.
+//
+// - Purge: the data of the user is deleted (again, in case the background task failed), and then the ClusterUser row is
+//   deleted, along with the rows that still reference it (KeyStores, ApplicationOwners and Operations).
+//
+// The backend does not create resources for a deactivated ClusterUser: the API resources of the user are no longer
+// reconciled, and the ClusterUser is not recreated once it has been purged unless the user creates new API resources.
+
+// ClusterUserOffboarder deactivates and purges ClusterUsers.
+type ClusterUserOffboarder struct {
+	client.Client
+	DB db.DatabaseQueries
+
+	// mutex ensures that the data of a user is not deleted by the background task and by a purge at the same time.
+	mutex sync.Mutex
+}
+
+// DeactivateClusterUser deactivates the ClusterUser with the given user name, revoking its ClusterAccess, and schedules
+// the deletion of its RepositoryCredentials and Applications in the background task queue.
+func (o *ClusterUserOffboarder) DeactivateClusterUser(ctx context.Context, userName string, l logr.Logger) (db.ClusterUser, error) {
+
+	clusterUser := db.ClusterUser{User_name: userName}
+	if err := o.DB.GetClusterUserByUsername(ctx, &clusterUser); err != nil {
+		return db.ClusterUser{}, err
+	}
+
+	log := l.WithValues(clusterUser.GetAsLogKeyValues()...)
+
+	clusterAccessesDeleted, err := o.DB.DeactivateClusterUser(ctx, clusterUser.Clusteruser_id)
+	if err != nil {
+		return db.ClusterUser{}, fmt.Errorf("unable to deactivate ClusterUser: %v", err)
+	}
+
+	if err := o.DB.GetClusterUserById(ctx, &clusterUser); err != nil {
+		return db.ClusterUser{}, err
+	}
+
+	log.Info("Deactivated ClusterUser", "clusterAccessesDeleted", clusterAccessesDeleted)
+
+	getBackgroundTaskQueue().Add(clusterUserOffboardingTaskKeyPrefix+clusterUser.Clusteruser_id, func(ctx context.Context) error {
+		return o.deleteClusterUserData(ctx, clusterUser, log)
+	})
+
+	return clusterUser, nil
+}
+
+// PurgeClusterUser deletes the data of a deactivated ClusterUser, and then the ClusterUser itself. Purging a user that
+// doesn't exist (for example, one that was already purged) is not an error.
+func (o *ClusterUserOffboarder) PurgeClusterUser(ctx context.Context, userName string, l logr.Logger) error {
+
+	clusterUser := db.ClusterUser{User_name: userName}
+	if err := o.DB.GetClusterUserByUsername(ctx, &clusterUser); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+
+	log := l.WithValues(clusterUser.GetAsLogKeyValues()...)
+
+	if clusterUser.Deactivated_on == nil {
+		return fmt.Errorf("ClusterUser '%s' must be deactivated before it is purged", userName)
+	}
+
+	if err := o.deleteClusterUserData(ctx, clusterUser, log); err != nil {
+		return err
+	}
+
+	if _, err := o.DB.PurgeClusterUser(ctx, clusterUser.Clusteruser_id); err != nil {
+		return fmt.Errorf("unable to purge ClusterUser: %v", err)
+	}
+
+	log.Info("Purged ClusterUser")
+
+	return nil
+}
+
+// deleteClusterUserData deletes the RepositoryCredentials of the user, and the Applications that are owned only by the
+// user, creating Operations to inform the cluster-agent of their deletion.
+func (o *ClusterUserOffboarder) deleteClusterUserData(ctx context.Context, clusterUser db.ClusterUser, log logr.Logger) error {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	// 1) Delete the RepositoryCredentials of the user, and the mappings from their API resources
+	var repositoryCredentials []db.RepositoryCredentials
+	if err := o.DB.ListRepositoryCredentialsByClusterUserID(ctx, clusterUser.Clusteruser_id, &repositoryCredentials); err != nil {
+		return err
+	}
+
+	for _, repoCredential := range repositoryCredentials {
+
+		if err := deleteDbEntry(ctx, o.DB, repoCredential.RepositoryCredentialsID, dbType_RespositoryCredential, log, repoCredential); err != nil {
+			return err
+		}
+
+		createOperationInEngineInstanceNamespace(ctx, o.DB, o.Client, repoCredential.EngineClusterID, repoCredential.RepositoryCredentialsID,
+			db.OperationResourceType_RepositoryCredentials, log)
+
+		apiResourceType := db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential
+		if repoCredential.IsTemplate {
+			apiResourceType = db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate
+		}
+
+		apiCRToDBMapping := db.APICRToDatabaseMapping{
+			APIResourceType: apiResourceType,
+			DBRelationType:  db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential,
+			DBRelationKey:   repoCredential.RepositoryCredentialsID,
+		}
+		if err := o.DB.GetAPICRForDatabaseUID(ctx, &apiCRToDBMapping); err != nil {
+			if !db.IsResultNotFoundError(err) {
+				return err
+			}
+		} else if err := deleteDbEntry(ctx, o.DB, apiCRToDBMapping.DBRelationKey, dbType_APICRToDatabaseMapping, log, apiCRToDBMapping); err != nil {
+			return err
+		}
+	}
+
+	// 2) Delete the Applications that are owned only by the user: Applications that are shared with other users are
+	// kept, and only the ApplicationOwner row of the user is deleted (when the user is purged).
+	var applicationOwners []db.ApplicationOwner
+	if err := o.DB.ListApplicationOwnersByClusterUserID(ctx, clusterUser.Clusteruser_id, &applicationOwners); err != nil {
+		return err
+	}
+
+	// The number of Applications that were deleted, and the number that were skipped (as they are shared with other users,
+	// or are left to the database reconciler)
+	deletedApplications, skippedApplications := 0, 0
+
+	for _, applicationOwner := range applicationOwners {
+
+		var owners []db.ApplicationOwner
+		if err := o.DB.ListApplicationOwnersByApplicationID(ctx, applicationOwner.ApplicationID, &owners); err != nil {
+			return err
+		}
+
+		sharedWithOtherUsers := false
+		for _, owner := range owners {
+			if owner.ClusterUserID != clusterUser.Clusteruser_id {
+				sharedWithOtherUsers = true
+				break
+			}
+		}
+		if sharedWithOtherUsers {
+			log.Info("Application is owned by other users, so it is not deleted", "applicationID", applicationOwner.ApplicationID)
+			skippedApplications++
+			continue
+		}
+
+		deplToAppMapping := db.DeploymentToApplicationMapping{Application_id: applicationOwner.ApplicationID}
+		if err := o.DB.GetDeploymentToApplicationMappingByApplicationId(ctx, &deplToAppMapping); err != nil {
+			if !db.IsResultNotFoundError(err) {
+				return err
+			}
+			// An Application without a DeploymentToApplicationMapping is cleaned up by the database reconciler
+			skippedApplications++
+			continue
+		}
+
+		if err := offboardApplication(ctx, o.DB, o.Client, deplToAppMapping, log); err != nil {
+			return err
+		}
+		deletedApplications++
+	}
+
+	log.Info("Deleted the RepositoryCredentials and Applications of ClusterUser",
+		"repositoryCredentials", len(repositoryCredentials), "deletedApplications", deletedApplications,
+		"skippedApplications", skippedApplications)
+
+	return nil
+}
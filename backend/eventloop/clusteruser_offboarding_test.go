@@ -0,0 +1,187 @@
+package eventloop
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("ClusterUser Offboarding Tests", func() {
+
+	Context("Testing DeactivateClusterUser and PurgeClusterUser functions.", func() {
+
+		var log logr.Logger
+		var ctx context.Context
+		var dbq db.AllDatabaseQueries
+		var offboarder *ClusterUserOffboarder
+		var clusterUser db.ClusterUser
+		var clusterAccess db.ClusterAccess
+		var ownedApplication db.Application
+		var sharedApplication db.Application
+		var repoCredential db.RepositoryCredentials
+
+		createApplication := func(name string, gitopsEngineInstance *db.GitopsEngineInstance, managedEnvironment *db.ManagedEnvironment,
+			owners ...string) db.Application {
+
+			application := db.Application{
+				Application_id:          "test-" + name,
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(dbq.CreateApplication(ctx, &application)).To(Succeed())
+
+			Expect(dbq.CreateDeploymentToApplicationMapping(ctx, &db.DeploymentToApplicationMapping{
+				Deploymenttoapplicationmapping_uid_id: "test-" + string(uuid.NewUUID()),
+				Application_id:                        application.Application_id,
+				DeploymentName:                        name,
+				DeploymentNamespace:                   "test-namespace",
+				NamespaceUID:                          "test-" + string(uuid.NewUUID()),
+			})).To(Succeed())
+
+			for _, owner := range owners {
+				Expect(dbq.CreateApplicationOwner(ctx, &db.ApplicationOwner{
+					ApplicationID: application.Application_id,
+					ClusterUserID: owner,
+				})).To(Succeed())
+			}
+
+			return application
+		}
+
+		BeforeEach(func() {
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				apiNamespace,
+				err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace).
+				Build()
+
+			err = db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+			log = logger.FromContext(ctx)
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			offboarder = &ClusterUserOffboarder{
+				Client: k8sClient,
+				DB:     dbq,
+			}
+
+			_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			clusterUser = db.ClusterUser{
+				Clusteruser_id: "test-offboarded-user-id",
+				User_name:      "test-offboarded-user",
+			}
+			Expect(dbq.CreateClusterUser(ctx, &clusterUser)).To(Succeed())
+
+			otherClusterUser := db.ClusterUser{
+				Clusteruser_id: "test-other-user-id",
+				User_name:      "test-other-user",
+			}
+			Expect(dbq.CreateClusterUser(ctx, &otherClusterUser)).To(Succeed())
+
+			clusterAccess = db.ClusterAccess{
+				Clusteraccess_user_id:                   clusterUser.Clusteruser_id,
+				Clusteraccess_managed_environment_id:    managedEnvironment.Managedenvironment_id,
+				Clusteraccess_gitops_engine_instance_id: gitopsEngineInstance.Gitopsengineinstance_id,
+			}
+			Expect(dbq.CreateClusterAccess(ctx, &clusterAccess)).To(Succeed())
+
+			By("creating an Application owned only by the user, and an Application shared with another user")
+			ownedApplication = createApplication("owned-application", gitopsEngineInstance, managedEnvironment,
+				clusterUser.Clusteruser_id)
+			sharedApplication = createApplication("shared-application", gitopsEngineInstance, managedEnvironment,
+				clusterUser.Clusteruser_id, otherClusterUser.Clusteruser_id)
+
+			By("creating a RepositoryCredentials of the user, and the APICRToDatabaseMapping of its GitOpsDeploymentRepositoryCredential")
+			repoCredential = db.RepositoryCredentials{
+				RepositoryCredentialsID: "test-repo-" + string(uuid.NewUUID()),
+				UserID:                  clusterUser.Clusteruser_id,
+				PrivateURL:              "https://test-private-url",
+				AuthUsername:            "test-auth-username",
+				AuthPassword:            "test-auth-password",
+				SecretObj:               "test-secret-obj",
+				EngineClusterID:         gitopsEngineInstance.Gitopsengineinstance_id,
+			}
+			Expect(dbq.CreateRepositoryCredentials(ctx, &repoCredential)).To(Succeed())
+
+			Expect(dbq.CreateAPICRToDatabaseMapping(ctx, &db.APICRToDatabaseMapping{
+				APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential,
+				APIResourceUID:       "test-" + string(uuid.NewUUID()),
+				APIResourceName:      "test-repocred",
+				APIResourceNamespace: "test-namespace",
+				NamespaceUID:         "test-" + string(uuid.NewUUID()),
+				DBRelationType:       db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential,
+				DBRelationKey:        repoCredential.RepositoryCredentialsID,
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			dbq.CloseDatabase()
+		})
+
+		It("should not purge a ClusterUser that has not been deactivated", func() {
+			err := offboarder.PurgeClusterUser(ctx, clusterUser.User_name, log)
+			Expect(err).NotTo(BeNil())
+
+			Expect(dbq.GetClusterUserById(ctx, &clusterUser)).To(Succeed())
+			Expect(dbq.GetClusterAccessByPrimaryKey(ctx, &clusterAccess)).To(Succeed())
+		})
+
+		It("should revoke the ClusterAccess of a deactivated ClusterUser, and delete its data when it is purged", func() {
+
+			By("deactivating the user")
+			deactivatedUser, err := offboarder.DeactivateClusterUser(ctx, clusterUser.User_name, log)
+			Expect(err).To(BeNil())
+			Expect(deactivatedUser.Deactivated_on).NotTo(BeNil())
+
+			Expect(db.IsResultNotFoundError(dbq.GetClusterAccessByPrimaryKey(ctx, &clusterAccess))).To(BeTrue())
+
+			By("purging the user")
+			Expect(offboarder.PurgeClusterUser(ctx, clusterUser.User_name, log)).To(Succeed())
+
+			Expect(db.IsResultNotFoundError(dbq.GetClusterUserById(ctx, &clusterUser))).To(BeTrue())
+
+			_, err = dbq.GetRepositoryCredentialsByID(ctx, repoCredential.RepositoryCredentialsID)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			apiCRToDBMapping := db.APICRToDatabaseMapping{
+				APIResourceType: db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential,
+				DBRelationType:  db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential,
+				DBRelationKey:   repoCredential.RepositoryCredentialsID,
+			}
+			Expect(db.IsResultNotFoundError(dbq.GetAPICRForDatabaseUID(ctx, &apiCRToDBMapping))).To(BeTrue())
+
+			By("verifying that only the Application owned solely by the user was deleted")
+			Expect(db.IsResultNotFoundError(dbq.GetApplicationById(ctx, &ownedApplication))).To(BeTrue())
+			Expect(dbq.GetApplicationById(ctx, &sharedApplication)).To(Succeed())
+
+			var owners []db.ApplicationOwner
+			Expect(dbq.ListApplicationOwnersByApplicationID(ctx, sharedApplication.Application_id, &owners)).To(Succeed())
+			Expect(owners).To(HaveLen(1))
+			Expect(owners[0].ClusterUserID).To(Equal("test-other-user-id"))
+
+			By("purging the user again, which should succeed, as it was already purged")
+			Expect(offboarder.PurgeClusterUser(ctx, clusterUser.User_name, log)).To(Succeed())
+		})
+	})
+})
@@ -26,10 +26,10 @@ type ControllerEventLoop struct {
 	EventLoopInputChannel chan eventlooptypes.EventLoopEvent
 }
 
-func NewControllerEventLoop() *ControllerEventLoop {
+func NewControllerEventLoop(directMode bool) *ControllerEventLoop {
 
 	channel := make(chan eventlooptypes.EventLoopEvent)
-	go controllerEventLoopRouter(channel, defaultWorkspaceEventLoopRouterFactory{})
+	go controllerEventLoopRouter(channel, defaultWorkspaceEventLoopRouterFactory{directMode: directMode})
 
 	res := &ControllerEventLoop{
 		EventLoopInputChannel: channel,
@@ -113,12 +113,14 @@ type workspaceEventLoopRouterFactory interface {
 }
 
 type defaultWorkspaceEventLoopRouterFactory struct {
+	// directMode is passed to the application event loops: see NewPreprocessEventLoop
+	directMode bool
 }
 
 var _ workspaceEventLoopRouterFactory = defaultWorkspaceEventLoopRouterFactory{}
 
 func (d defaultWorkspaceEventLoopRouterFactory) startWorkspaceEventLoopRouter(workspaceID string) WorkspaceEventLoopRouterStruct {
 
-	return newWorkspaceEventLoopRouter(workspaceID)
+	return newWorkspaceEventLoopRouter(workspaceID, d.directMode)
 
 }
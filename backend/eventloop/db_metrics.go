@@ -9,7 +9,6 @@ import (
 
 	"github.com/go-logr/logr"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
-	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
 )
@@ -25,26 +24,20 @@ type MetricsReconciler struct {
 }
 
 func (r *MetricsReconciler) StartDBMetricsReconcilerForMetrics() {
-	go func() {
-		// Timer to trigger Reconciler
-		timer := time.NewTimer(time.Duration(databaseMetricsReconcilerInterval))
-		<-timer.C
+	getBackgroundTaskQueue().AddAfter(databaseMetricsReconcilerTaskKey, databaseMetricsReconcilerInterval, func(ctx context.Context) error {
+
+		// Kick off the timer again, once the old task runs (even if it panics).
+		// This ensures that at least 'databaseMetricsReconcilerInterval' time elapses from the end of one run to the beginning of another.
+		defer r.StartDBMetricsReconcilerForMetrics()
 
-		ctx := context.Background()
 		log := log.FromContext(ctx).
 			WithName(logutil.LogLogger_managed_gitops).
 			WithValues("component", "database-metrics-reconciler")
 
-		_, _ = sharedutil.CatchPanic(func() error {
-			operationDbReconcile(ctx, r.DB, r.Client, log)
-			return nil
-		})
-
-		// Kick off the timer again, once the old task runs.
-		// This ensures that at least 'databaseMetricsReconcilerInterval' time elapses from the end of one run to the beginning of another.
-		r.StartDBMetricsReconcilerForMetrics()
-	}()
+		operationDbReconcile(ctx, r.DB, r.Client, log)
 
+		return nil
+	})
 }
 
 // operationDbReconcile counts the total number of operation rows from database
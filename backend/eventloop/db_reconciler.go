@@ -14,11 +14,13 @@ import (
 
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
 	sharedresourceloop "github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
+	corev1 "k8s.io/api/core/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -28,8 +30,33 @@ const (
 	defaultDatabaseReconcilerInterval = 30 * time.Minute // Interval in Minutes to reconcile Database.
 	sleepIntervalsOfBatches           = 1 * time.Second  // Interval in Millisecond between each batch.
 	waitTimeforRowDelete              = 1 * time.Hour    // Number of hours to wait before deleting DB row
+
+	// resumeDatabaseReconcilerDelay is the delay after startup before a database reconciliation that was interrupted
+	// (for example, by a restart of the backend) is resumed, which allows the informer caches to sync.
+	resumeDatabaseReconcilerDelay = 1 * time.Minute
 )
 
+// The steps of a database reconciliation cycle, in the order in which they are run. The step that is in progress (and,
+// for the steps that process a table in batches, the offset of the next batch) is persisted as a TaskCheckpoint after
+// each batch, so that a cycle that is interrupted is resumed from where it stopped, rather than from the beginning.
+const (
+	databaseReconcilerStep_DTAM              = "dtam"
+	databaseReconcilerStep_ACTDM             = "actdm"
+	databaseReconcilerStep_Tables            = "tables"
+	databaseReconcilerStep_Application       = "application"
+	databaseReconcilerStep_Operation         = "operation"
+	databaseReconcilerStep_ClusterCredential = "clustercredential"
+)
+
+var databaseReconcilerSteps = []string{
+	databaseReconcilerStep_DTAM,
+	databaseReconcilerStep_ACTDM,
+	databaseReconcilerStep_Tables,
+	databaseReconcilerStep_Application,
+	databaseReconcilerStep_Operation,
+	databaseReconcilerStep_ClusterCredential,
+}
+
 // A 'dangling' DB entry (for lack of a better term) is a row in the database that points to a K8s resource that no longer exists
 // or a row in database which is missing required entries in other tables.
 //
@@ -57,44 +84,149 @@ func (r *DatabaseReconciler) StartDatabaseReconciler() {
 		WithValues("component", "database-reconciler")
 	databaseReconcilerInterval := sharedutil.SelfHealInterval(defaultDatabaseReconcilerInterval, log)
 	if databaseReconcilerInterval > 0 {
-		r.startTimerForNextCycle(ctx, databaseReconcilerInterval, log)
+
+		// If the previous cycle was interrupted, resume it shortly after startup, rather than after a full interval
+		firstCycleDelay := databaseReconcilerInterval
+		if checkpointer, err := dbutil.NewTaskCheckpointer(ctx, r.DB, databaseReconcilerTaskKey); err != nil {
+			log.Error(err, "unable to retrieve the checkpoint of the previous database reconciliation")
+		} else if checkpointer.IsResumed() && resumeDatabaseReconcilerDelay < firstCycleDelay {
+			log.Info("The previous database reconciliation was interrupted, and will be resumed", "step", checkpointer.Progress().Step)
+			firstCycleDelay = resumeDatabaseReconcilerDelay
+		}
+
+		r.startTimerForNextCycle(ctx, firstCycleDelay, databaseReconcilerInterval, log)
 		log.Info(fmt.Sprintf("Database reconciliation has been scheduled every %s", databaseReconcilerInterval.String()))
 	} else {
 		log.Info("Database reconciliation has been disabled")
 	}
 }
 
-func (r *DatabaseReconciler) startTimerForNextCycle(ctx context.Context, databaseReconcilerInterval time.Duration, log logr.Logger) {
-	go func() {
-		// Timer to trigger Reconciler
-		timer := time.NewTimer(databaseReconcilerInterval)
-		<-timer.C
+func (r *DatabaseReconciler) startTimerForNextCycle(ctx context.Context, delay time.Duration, databaseReconcilerInterval time.Duration, log logr.Logger) {
+	getBackgroundTaskQueue().AddAfter(databaseReconcilerTaskKey, delay, func(context.Context) error {
 
-		_, _ = sharedutil.CatchPanic(func() error {
+		// Kick off the timer again, once the old task runs (even if it panics).
+		// This ensures that at least 'databaseReconcilerInterval' time elapses from the end of one run to the beginning of another.
+		defer r.startTimerForNextCycle(ctx, databaseReconcilerInterval, databaseReconcilerInterval, log)
+
+		// No database rows are deleted while the GitOps Service is in read-only mode
+		if readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, r.Client); err != nil {
+			log.Error(err, "unable to determine whether read-only mode is enabled, skipping database reconciliation")
+			return nil
+		} else if readOnlyModeEnabled {
+			log.Info("Skipping database reconciliation, as the GitOps Service is in read-only mode")
+			return nil
+		}
+
+		runDatabaseReconcilerCycle(ctx, r.DB, r.Client, r.K8sClientFactory, false, log)
+
+		return nil
+	})
+}
+
+// runDatabaseReconcilerCycle runs each step of a database reconciliation cycle, resuming from the checkpoint of the
+// previous cycle if it was interrupted. The checkpoint is deleted once every step has run.
+func runDatabaseReconcilerCycle(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client,
+	k8sClientFactory sharedresourceloop.SRLK8sClientFactory, skipDelay bool, log logr.Logger) {
+
+	checkpointer, err := dbutil.NewTaskCheckpointer(ctx, dbQueries, databaseReconcilerTaskKey)
+	if err != nil {
+		log.Error(err, "unable to retrieve the checkpoint of the database reconciliation, skipping database reconciliation")
+		return
+	}
+
+	progress := checkpointer.Progress()
+	if checkpointer.IsResumed() {
+		log.Info("Resuming database reconciliation from checkpoint", "step", progress.Step, "cursor", progress.Cursor)
+	}
+
+	started := progress.Step == ""
+	for _, step := range databaseReconcilerSteps {
+
+		if !started && step != progress.Step {
+			// The step completed before the previous cycle was interrupted
+			continue
+		}
+
+		offSet := 0
+		if !started {
+			offSet = int(progress.Cursor)
+		}
+		started = true
+
+		// saveProgress persists the offset of the next batch of the step: errors are logged, but otherwise ignored, as
+		// the checkpoint is only used to avoid repeating work if the cycle is interrupted.
+		saveProgress := func(nextOffSet int) {
+			if err := checkpointer.Save(ctx, dbutil.TaskProgress{Step: step, Cursor: int64(nextOffSet)}); err != nil {
+				log.Error(err, "unable to save the checkpoint of the database reconciliation", "step", step)
+			}
+		}
+		saveProgress(offSet)
 
+		switch step {
+		case databaseReconcilerStep_DTAM:
 			// Clean orphaned entries from DTAM table and other table they relate to (i.e ApplicationState, Application).
-			cleanOrphanedEntriesfromTable_DTAM(ctx, r.DB, r.Client, false, log)
+			cleanOrphanedEntriesfromTable_DTAM_FromOffset(ctx, dbQueries, client, offSet, saveProgress, skipDelay, log)
 
+		case databaseReconcilerStep_ACTDM:
 			// Clean orphaned entries from ACTDM table and other table they relate to (i.e ManagedEnvironment, RepositoryCredential, GitOpsDeploymentSync).
-			cleanOrphanedEntriesfromTable_ACTDM(ctx, r.DB, r.Client, r.K8sClientFactory, false, log)
+			cleanOrphanedEntriesfromTable_ACTDM_FromOffset(ctx, dbQueries, client, k8sClientFactory, offSet, saveProgress, skipDelay, log)
 
+		case databaseReconcilerStep_Tables:
 			// Clean orphaned entries from RepositoryCredential, SyncOperation, ManagedEnvironment tables if they dont have related entries in DTAM table.
-			cleanOrphanedEntriesfromTable(ctx, r.DB, r.Client, r.K8sClientFactory, false, log)
+			cleanOrphanedEntriesfromTable(ctx, dbQueries, client, k8sClientFactory, skipDelay, log)
 
+		case databaseReconcilerStep_Application:
 			// Clean orphaned entries from Application table if they dont have related entries in ACTDM table.
-			cleanOrphanedEntriesfromTable_Application(ctx, r.DB, r.Client, false, log)
+			cleanOrphanedEntriesfromTable_Application(ctx, dbQueries, client, skipDelay, log)
 
+		case databaseReconcilerStep_Operation:
 			// Clean orphaned entries from Operation table.
-			cleanOrphanedEntriesfromTable_Operation(ctx, r.DB, r.Client, false, log)
+			cleanOrphanedEntriesfromTable_Operation(ctx, dbQueries, client, skipDelay, log)
+
+		case databaseReconcilerStep_ClusterCredential:
+			// Clean ClusterCredentials that are no longer referenced by any ManagedEnvironment or GitopsEngineCluster.
+			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbQueries, client, skipDelay, log)
+		}
+	}
+
+	if !started {
+		// The checkpoint is deleted below, so the next cycle starts from the beginning
+		log.Error(nil, "SEVERE: unrecognized database reconciliation step in checkpoint", "step", progress.Step)
+	}
+
+	if err := checkpointer.Complete(ctx); err != nil {
+		log.Error(err, "unable to delete the checkpoint of the database reconciliation")
+	}
+}
 
+// CleanOrphanedEntriesCreatedBeforeStartup cleans up the APICRToDatabaseMappings (and the rows they point to) of the API CRs
+// that were deleted while the backend was not running. It is meant to be run once, on startup, after the informer caches
+// have synced: see cleanOrphanedEntriesfromTable_ACTDM_CreatedBefore.
+func (r *DatabaseReconciler) CleanOrphanedEntriesCreatedBeforeStartup(ctx context.Context) error {
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("component", "database-reconciler")
+
+	startupTime := time.Now()
+
+	_, _ = sharedutil.CatchPanic(func() error {
+
+		// No database rows are deleted while the GitOps Service is in read-only mode: the periodic database reconciliation
+		// will clean up the mappings, once read-only mode is disabled.
+		if readOnlyModeEnabled, err := sharedutil.IsReadOnlyModeEnabled(ctx, r.Client); err != nil {
+			log.Error(err, "unable to determine whether read-only mode is enabled, skipping startup database cleanup")
 			return nil
-		})
+		} else if readOnlyModeEnabled {
+			log.Info("Skipping startup database cleanup, as the GitOps Service is in read-only mode")
+			return nil
+		}
 
-		// Kick off the timer again, once the old task runs.
-		// This ensures that at least 'databaseReconcilerInterval' time elapses from the end of one run to the beginning of another.
-		r.startTimerForNextCycle(ctx, databaseReconcilerInterval, log)
-	}()
+		cleanOrphanedEntriesfromTable_ACTDM_CreatedBefore(ctx, r.DB, r.Client, startupTime, false, log)
+
+		return nil
+	})
 
+	return nil
 }
 
 ///////////////
@@ -105,10 +237,19 @@ func (r *DatabaseReconciler) startTimerForNextCycle(ctx context.Context, databas
 // cleanOrphanedEntriesfromTable_DTAM loops through the DTAMs in a database and verifies they are still valid. If not, the resources are deleted.
 // - The skipDelay can be used to skip the time.Sleep(), but this should true when called from a unit test.
 func cleanOrphanedEntriesfromTable_DTAM(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client, skipDelay bool, l logr.Logger) {
-	offSet := 0
+	cleanOrphanedEntriesfromTable_DTAM_FromOffset(ctx, dbQueries, client, 0, nil, skipDelay, l)
+}
+
+// cleanOrphanedEntriesfromTable_DTAM_FromOffset is cleanOrphanedEntriesfromTable_DTAM, starting from the batch at the
+// given offset. If non-nil, batchProcessed is called with the offset of the next batch, after each batch is processed.
+func cleanOrphanedEntriesfromTable_DTAM_FromOffset(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client,
+	offSet int, batchProcessed func(nextOffSet int), skipDelay bool, l logr.Logger) {
 
 	log := l.WithValues("job", "cleanOrphanedEntriesfromTable_DTAM")
 
+	// The UIDs of the deleted namespaces whose DTAMs were all cleaned up by this run
+	cleanedNamespaceUIDs := map[string]bool{}
+
 	// Continuously iterate and fetch batches until all entries of DeploymentToApplicationMapping table are processed.
 	for {
 		if offSet != 0 && !skipDelay {
@@ -133,6 +274,11 @@ func cleanOrphanedEntriesfromTable_DTAM(ctx context.Context, dbQueries db.Databa
 		// Iterate over batch received above.
 		for i := range listOfdeplToAppMapping {
 			deplToAppMappingFromDB := listOfdeplToAppMapping[i] // To avoid "Implicit memory aliasing in for loop." error.
+
+			if cleanedNamespaceUIDs[deplToAppMappingFromDB.NamespaceUID] {
+				continue
+			}
+
 			gitOpsDeployment := managedgitopsv1alpha1.GitOpsDeployment{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      deplToAppMappingFromDB.DeploymentName,
@@ -147,6 +293,20 @@ func cleanOrphanedEntriesfromTable_DTAM(ctx context.Context, dbQueries db.Databa
 					log.Info("GitOpsDeployment " + gitOpsDeployment.Name + " not found in Cluster, probably user deleted it, " +
 						"but It still exists in DB, hence deleting related database entries.")
 
+					// If the namespace of the GitOpsDeployment was deleted, then every GitOpsDeployment of the namespace is
+					// orphaned, so the database entries of the namespace are cleaned up together.
+					if namespaceDeleted, err := isNamespaceOfDTAMDeleted(ctx, client, deplToAppMappingFromDB); err != nil {
+						log.Error(err, "Error occurred in DTAM Reconciler while fetching Namespace from cluster: "+deplToAppMappingFromDB.DeploymentNamespace)
+
+					} else if namespaceDeleted {
+						cleanedNamespaceUIDs[deplToAppMappingFromDB.NamespaceUID] = true
+
+						if err := cleanOrphanedEntriesfromTable_DTAM_OfNamespace(ctx, dbQueries, deplToAppMappingFromDB.NamespaceUID, skipDelay, log); err != nil {
+							log.Error(err, "Error occurred in DTAM Reconciler while cleaning entries of deleted namespace from DB: "+deplToAppMappingFromDB.DeploymentNamespace)
+						}
+						continue
+					}
+
 					if err := cleanOrphanedEntriesfromTable_DTAM_DeleteEntry(ctx, &deplToAppMappingFromDB, dbQueries, log); err != nil {
 						log.Error(err, "Error occurred in DTAM Reconciler while cleaning gitOpsDeployment entries from DB: "+gitOpsDeployment.Name)
 					}
@@ -171,6 +331,64 @@ func cleanOrphanedEntriesfromTable_DTAM(ctx context.Context, dbQueries db.Databa
 
 		// Skip processed entries in next iteration
 		offSet += rowBatchSize
+
+		if batchProcessed != nil {
+			batchProcessed(offSet)
+		}
+	}
+}
+
+// isNamespaceOfDTAMDeleted returns true if the namespace of the DTAM no longer exists, or was deleted and then recreated
+// with the same name (and thus has a different UID).
+func isNamespaceOfDTAMDeleted(ctx context.Context, k8sClient client.Client, deplToAppMapping db.DeploymentToApplicationMapping) (bool, error) {
+
+	namespace := corev1.Namespace{}
+	if err := k8sClient.Get(ctx, types.NamespacedName{Name: deplToAppMapping.DeploymentNamespace}, &namespace); err != nil {
+		if apierr.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return string(namespace.UID) != deplToAppMapping.NamespaceUID, nil
+}
+
+// cleanOrphanedEntriesfromTable_DTAM_OfNamespace deletes the database entries related to every GitOpsDeployment of the
+// (deleted) namespace with the given UID. The DTAMs of the namespace are processed in batches, so that the DTAMs of
+// namespaces with thousands of GitOpsDeployments are not all loaded into memory.
+func cleanOrphanedEntriesfromTable_DTAM_OfNamespace(ctx context.Context, dbQueries db.DatabaseQueries, namespaceUID string,
+	skipDelay bool, l logr.Logger) error {
+
+	log := l.WithValues("namespaceUID", namespaceUID)
+
+	log.Info("Namespace was deleted, but its GitOpsDeployments still exist in DB, hence deleting their database entries.")
+
+	var afterSeqID int64
+	for {
+		if afterSeqID != 0 && !skipDelay {
+			time.Sleep(sleepIntervalsOfBatches)
+		}
+
+		var listOfdeplToAppMapping []db.DeploymentToApplicationMapping
+		if err := dbQueries.ListDeploymentToApplicationMappingsForNamespaceUID(ctx, namespaceUID, afterSeqID, rowBatchSize,
+			&listOfdeplToAppMapping); err != nil {
+			return err
+		}
+
+		if len(listOfdeplToAppMapping) == 0 {
+			return nil
+		}
+
+		for i := range listOfdeplToAppMapping {
+			deplToAppMappingFromDB := listOfdeplToAppMapping[i]
+
+			if err := cleanOrphanedEntriesfromTable_DTAM_DeleteEntry(ctx, &deplToAppMappingFromDB, dbQueries, log); err != nil {
+				log.Error(err, "Error occurred in DTAM Reconciler while cleaning gitOpsDeployment entries from DB: "+deplToAppMappingFromDB.DeploymentName)
+			}
+		}
+
+		// DTAMs that could not be deleted (for example, of soft-deleted Applications) are not returned again
+		afterSeqID = listOfdeplToAppMapping[len(listOfdeplToAppMapping)-1].SeqID
 	}
 }
 
@@ -197,6 +415,12 @@ func cleanOrphanedEntriesfromTable_DTAM_DeleteEntry(ctx context.Context, deplToA
 
 	log := logger.WithValues("applicationID", deplToAppMapping.Application_id)
 
+	// Soft-deleted Applications keep their DTAM until they are deleted by cleanOrphanedEntriesfromTable_Application.
+	if dbApplicationFound && dbApplication.IsSoftDeleted() {
+		log.Info("Skipping DTAM of soft-deleted Application")
+		return nil
+	}
+
 	// 1) Remove the ApplicationState from the database
 	if err := deleteDbEntry(ctx, dbQueries, deplToAppMapping.Application_id, dbType_ApplicationState, log, deplToAppMapping); err != nil {
 		return err
@@ -242,7 +466,14 @@ func cleanOrphanedEntriesfromTable_DTAM_DeleteEntry(ctx context.Context, deplToA
 
 // cleanOrphanedEntriesfromTable_ACTDM loops through the ACTDM in a database and verifies they are still valid. If not, the resources are deleted.
 func cleanOrphanedEntriesfromTable_ACTDM(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client, k8sClientFactory sharedresourceloop.SRLK8sClientFactory, skipDelay bool, l logr.Logger) {
-	offSet := 0
+	cleanOrphanedEntriesfromTable_ACTDM_FromOffset(ctx, dbQueries, client, k8sClientFactory, 0, nil, skipDelay, l)
+}
+
+// cleanOrphanedEntriesfromTable_ACTDM_FromOffset is cleanOrphanedEntriesfromTable_ACTDM, starting from the batch at the
+// given offset. If non-nil, batchProcessed is called with the offset of the next batch, after each batch is processed.
+func cleanOrphanedEntriesfromTable_ACTDM_FromOffset(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client, k8sClientFactory sharedresourceloop.SRLK8sClientFactory,
+	offSet int, batchProcessed func(nextOffSet int), skipDelay bool, l logr.Logger) {
+
 	log := l.WithValues("job", "cleanOrphanedEntriesfromTable_ACTDM")
 
 	// Continuously iterate and fetch batches until all entries of ACTDM table are processed.
@@ -284,6 +515,10 @@ func cleanOrphanedEntriesfromTable_ACTDM(ctx context.Context, dbQueries db.Datab
 
 				// Process if CR is of GitOpsDeploymentRepositoryCredential type.
 				cleanOrphanedEntriesfromTable_ACTDM_RepositoryCredential(ctx, client, dbQueries, apiCrToDbMappingFromDB, objectMeta, log)
+			} else if db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate == apiCrToDbMappingFromDB.APIResourceType {
+
+				// Process if CR is of GitOpsDeploymentRepositoryCredentialTemplate type.
+				cleanOrphanedEntriesfromTable_ACTDM_RepositoryCredentialTemplate(ctx, client, dbQueries, apiCrToDbMappingFromDB, objectMeta, log)
 			} else if db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun == apiCrToDbMappingFromDB.APIResourceType {
 
 				// Process if CR is of GitOpsDeploymentSyncRun type.
@@ -297,6 +532,10 @@ func cleanOrphanedEntriesfromTable_ACTDM(ctx context.Context, dbQueries db.Datab
 
 		// Skip processed entries in next iteration
 		offSet += rowBatchSize
+
+		if batchProcessed != nil {
+			batchProcessed(offSet)
+		}
 	}
 }
 
@@ -345,6 +584,29 @@ func cleanOrphanedEntriesfromTable_ACTDM_RepositoryCredential(ctx context.Contex
 		return
 	}
 
+	deleteOrphanedEntries_ACTDM_RepositoryCredential(ctx, client, dbQueries, apiCrToDbMappingFromDB, objectMeta, log)
+}
+
+func cleanOrphanedEntriesfromTable_ACTDM_RepositoryCredentialTemplate(ctx context.Context, client client.Client, dbQueries db.DatabaseQueries, apiCrToDbMappingFromDB db.APICRToDatabaseMapping, objectMeta metav1.ObjectMeta, log logr.Logger) {
+
+	// Process if CR is of GitOpsDeploymentRepositoryCredentialTemplate type.
+	repoCredTemplateK8s := managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate{ObjectMeta: objectMeta}
+
+	// Check if required CR is present in cluster
+	if isOrphaned := isRowOrphaned(ctx, client, &apiCrToDbMappingFromDB, &repoCredTemplateK8s, log); !isOrphaned {
+		return
+	}
+
+	// The RepositoryCredentials row of a template is deleted in the same way as the row of a GitOpsDeploymentRepositoryCredential
+	deleteOrphanedEntries_ACTDM_RepositoryCredential(ctx, client, dbQueries, apiCrToDbMappingFromDB, objectMeta, log)
+}
+
+// deleteOrphanedEntries_ACTDM_RepositoryCredential deletes the APICRToDatabaseMapping of a GitOpsDeploymentRepositoryCredential
+// (or GitOpsDeploymentRepositoryCredentialTemplate) that no longer exists, and the RepositoryCredentials row it points to.
+func deleteOrphanedEntries_ACTDM_RepositoryCredential(ctx context.Context, client client.Client, dbQueries db.DatabaseQueries, apiCrToDbMappingFromDB db.APICRToDatabaseMapping, objectMeta metav1.ObjectMeta, log logr.Logger) {
+
+	repoCredentialK8s := managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{ObjectMeta: objectMeta}
+
 	// If CR is not present in cluster clean ACTDM entry
 	if err := deleteDbEntry(ctx, dbQueries, apiCrToDbMappingFromDB.DBRelationKey, dbType_APICRToDatabaseMapping, log, apiCrToDbMappingFromDB); err != nil {
 		log.Error(err, "Error occurred in cleanOrphanedEntriesfromTable_ACTDM_RepositoryCredential while deleting APICRToDatabaseMapping entry : "+apiCrToDbMappingFromDB.DBRelationKey+" from DB.")
@@ -378,6 +640,15 @@ func cleanOrphanedEntriesfromTable_ACTDM_GitOpsDeploymentSyncRun(ctx context.Con
 		return
 	}
 
+	deleteOrphanedEntries_ACTDM_GitOpsDeploymentSyncRun(ctx, client, dbQueries, apiCrToDbMappingFromDB, objectMeta, log)
+}
+
+// deleteOrphanedEntries_ACTDM_GitOpsDeploymentSyncRun deletes the APICRToDatabaseMapping of a GitOpsDeploymentSyncRun that
+// no longer exists, and the SyncOperation row it points to.
+func deleteOrphanedEntries_ACTDM_GitOpsDeploymentSyncRun(ctx context.Context, client client.Client, dbQueries db.DatabaseQueries, apiCrToDbMappingFromDB db.APICRToDatabaseMapping, objectMeta metav1.ObjectMeta, log logr.Logger) {
+
+	syncRunK8s := managedgitopsv1alpha1.GitOpsDeploymentSyncRun{ObjectMeta: objectMeta}
+
 	// If CR is not present in cluster clean ACTDM entry
 	if err := deleteDbEntry(ctx, dbQueries, apiCrToDbMappingFromDB.DBRelationKey, dbType_APICRToDatabaseMapping, log, apiCrToDbMappingFromDB); err != nil {
 		log.Error(err, "Error occurred in cleanOrphanedEntriesfromTable_ACTDM_GitOpsDeploymentSyncRun while deleting APICRToDatabaseMapping entry : "+apiCrToDbMappingFromDB.DBRelationKey+" from DB.")
@@ -420,6 +691,112 @@ func cleanOrphanedEntriesfromTable_ACTDM_GitOpsDeploymentSyncRun(ctx context.Con
 	createOperation(ctx, applicationDb.Engine_instance_inst_id, syncOperationDb.SyncOperation_id, syncRunK8s.Namespace, db.OperationResourceType_SyncOperation, dbQueries, client, log)
 }
 
+// cleanOrphanedEntriesfromTable_ACTDM_CreatedBefore deletes the APICRToDatabaseMappings of GitOpsDeploymentRepositoryCredentials,
+// GitOpsDeploymentRepositoryCredentialTemplates and GitOpsDeploymentSyncRuns that were created before 'createdBefore', but whose API CRs no longer exist, along with the
+// RepositoryCredentials/SyncOperation rows they point to.
+//
+// This is run once the backend has started (with 'createdBefore' set to the time of startup), so that the API CRs that were
+// deleted while the backend was down are cleaned up without waiting for the next database reconciliation cycle.
+func cleanOrphanedEntriesfromTable_ACTDM_CreatedBefore(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client, createdBefore time.Time, skipDelay bool, l logr.Logger) {
+	log := l.WithValues("job", "cleanOrphanedEntriesfromTable_ACTDM_CreatedBefore")
+
+	for _, resourceType := range []db.APICRToDatabaseMapping_ResourceType{
+		db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential,
+		db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate,
+		db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun} {
+
+		var afterSeqID int64
+		orphaned := 0
+
+		// Continuously iterate and fetch batches until all the mappings of the type are processed.
+		for {
+			if afterSeqID != 0 && !skipDelay {
+				time.Sleep(sleepIntervalsOfBatches)
+			}
+
+			orphanedMappings, lastSeqID, err := listAPICRToDatabaseMappingsOlderThanWithoutCR(ctx, dbQueries, client, resourceType, createdBefore, afterSeqID, log)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Error occurred in cleanOrphanedEntriesfromTable_ACTDM_CreatedBefore while fetching batch after seq_id: %d", afterSeqID),
+					"resourceType", resourceType)
+				break
+			}
+
+			// Break the loop if no entries are left in table to be processed.
+			if lastSeqID == afterSeqID {
+				log.Info("All APICRToDatabaseMapping entries created before startup are processed.", "resourceType", resourceType, "orphaned", orphaned)
+				break
+			}
+
+			for i := range orphanedMappings {
+				apiCrToDbMappingFromDB := orphanedMappings[i] // To avoid "Implicit memory aliasing in for loop." error.
+
+				objectMeta := metav1.ObjectMeta{
+					Name:      apiCrToDbMappingFromDB.APIResourceName,
+					Namespace: apiCrToDbMappingFromDB.APIResourceNamespace,
+				}
+
+				if resourceType == db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential ||
+					resourceType == db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate {
+					deleteOrphanedEntries_ACTDM_RepositoryCredential(ctx, client, dbQueries, apiCrToDbMappingFromDB, objectMeta, log)
+				} else {
+					deleteOrphanedEntries_ACTDM_GitOpsDeploymentSyncRun(ctx, client, dbQueries, apiCrToDbMappingFromDB, objectMeta, log)
+				}
+				orphaned++
+			}
+
+			// Skip processed entries in next iteration
+			afterSeqID = lastSeqID
+		}
+	}
+}
+
+// listAPICRToDatabaseMappingsOlderThanWithoutCR returns the APICRToDatabaseMappings (of a batch of up to 'rowBatchSize' rows)
+// of the given type, created before 'createdBefore' and with a seq_id greater than 'afterSeqID', whose API CR no longer
+// exists in the cluster (or has a different UID). The seq_id of the last row of the batch is also returned, to retrieve the
+// next batch: it is equal to 'afterSeqID' if there are no rows left.
+func listAPICRToDatabaseMappingsOlderThanWithoutCR(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client,
+	resourceType db.APICRToDatabaseMapping_ResourceType, createdBefore time.Time, afterSeqID int64, log logr.Logger) ([]db.APICRToDatabaseMapping, int64, error) {
+
+	var listOfApiCrToDbMapping []db.APICRToDatabaseMapping
+	if err := dbQueries.ListAPICRToDatabaseMappingsOlderThan(ctx, resourceType, createdBefore, afterSeqID, rowBatchSize, &listOfApiCrToDbMapping); err != nil {
+		return nil, afterSeqID, err
+	}
+
+	if len(listOfApiCrToDbMapping) == 0 {
+		return nil, afterSeqID, nil
+	}
+
+	res := []db.APICRToDatabaseMapping{}
+	for i := range listOfApiCrToDbMapping {
+		apiCrToDbMapping := listOfApiCrToDbMapping[i]
+
+		objectMeta := metav1.ObjectMeta{
+			Name:      apiCrToDbMapping.APIResourceName,
+			Namespace: apiCrToDbMapping.APIResourceNamespace,
+		}
+
+		var obj client.Object
+		switch resourceType {
+		case db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential:
+			obj = &managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{ObjectMeta: objectMeta}
+		case db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate:
+			obj = &managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate{ObjectMeta: objectMeta}
+		case db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun:
+			obj = &managedgitopsv1alpha1.GitOpsDeploymentSyncRun{ObjectMeta: objectMeta}
+		case db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment:
+			obj = &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{ObjectMeta: objectMeta}
+		default:
+			return nil, afterSeqID, fmt.Errorf("SEVERE: unrecognized APIResourceType: %s", resourceType)
+		}
+
+		if isRowOrphaned(ctx, k8sClient, &apiCrToDbMapping, obj, log) {
+			res = append(res, apiCrToDbMapping)
+		}
+	}
+
+	return res, listOfApiCrToDbMapping[len(listOfApiCrToDbMapping)-1].SeqID, nil
+}
+
 // isRowOrphaned function checks if the given CR pointed by APICRToDBMapping is present in the cluster.
 func isRowOrphaned(ctx context.Context, k8sClient client.Client, apiCrToDbMapping *db.APICRToDatabaseMapping, obj client.Object, logger logr.Logger) bool {
 
@@ -457,7 +834,6 @@ const (
 	dbType_Operation                      dbTableName = "Operation"
 	dbType_ClusterAccess                  dbTableName = "ClusterAccess"
 	dbType_ClusterUser                    dbTableName = "ClusterUser"
-	dbType_GitopsEngineCluster            dbTableName = "GitopsEngineCluster"
 	dbType_ClusterCredentials             dbTableName = "ClusterCredentials"
 )
 
@@ -749,6 +1125,9 @@ func cleanOrphanedEntriesfromTable_Application(ctx context.Context, dbQueries db
 
 	log := l.WithValues("job", "cleanOrphanedEntriesfromTable_Application")
 
+	// Soft-deleted Applications are deleted once the grace period has elapsed (immediately, if soft-delete is disabled).
+	softDeleteGracePeriod := sharedutil.ApplicationSoftDeleteGracePeriod(log)
+
 	// Get list of Applications having entry in DTAM table
 	listOfAppsIdsInDTAM := getListOfCRIdsFromTable(ctx, dbQueries, dbType_Application, skipDelay, log)
 
@@ -777,6 +1156,13 @@ func cleanOrphanedEntriesfromTable_Application(ctx context.Context, dbQueries db
 		// Iterate over batch received above.
 		for _, appDB := range listOfApplicationsFromDB {
 
+			if appDB.IsSoftDeleted() {
+				if time.Since(*appDB.Deleted_on) > softDeleteGracePeriod {
+					cleanOrphanedEntriesfromTable_Application_DeleteSoftDeleted(ctx, appDB, dbQueries, client, log)
+				}
+				continue
+			}
+
 			// Check if application has entry in DTAM table, if not then delete the application
 			// If created time is less than waitTimeforRowDelete then ignore dont delete even if ACTDM entry is missing.
 			if !slices.Contains(listOfAppsIdsInDTAM[dbType_Application], appDB.Application_id) &&
@@ -811,6 +1197,54 @@ func cleanOrphanedEntriesfromTable_Application(ctx context.Context, dbQueries db
 	}
 }
 
+// cleanOrphanedEntriesfromTable_Application_DeleteSoftDeleted deletes an Application whose soft-delete grace period has
+// elapsed, along with the database entries that reference it, and then creates an Operation to delete the Argo CD Application.
+func cleanOrphanedEntriesfromTable_Application_DeleteSoftDeleted(ctx context.Context, appDB db.Application, dbQueries db.DatabaseQueries,
+	client client.Client, l logr.Logger) {
+
+	log := l.WithValues("applicationID", appDB.Application_id)
+
+	// 1) Delete the DTAM row that points to this Application (kept while the Application was soft-deleted)
+	deplToAppMapping := db.DeploymentToApplicationMapping{Application_id: appDB.Application_id}
+	if err := dbQueries.GetDeploymentToApplicationMappingByApplicationId(ctx, &deplToAppMapping); err != nil {
+		if !db.IsResultNotFoundError(err) {
+			log.Error(err, "Error occurred in cleanOrphanedEntriesfromTable_Application while retrieving DTAM of soft-deleted Application")
+			return
+		}
+	} else if err := deleteDbEntry(ctx, dbQueries, deplToAppMapping.Deploymenttoapplicationmapping_uid_id, dbType_DeploymentToApplicationMapping, log, deplToAppMapping); err != nil {
+		return
+	}
+
+	// 2) Remove the ApplicationState from the database
+	if err := deleteDbEntry(ctx, dbQueries, appDB.Application_id, dbType_ApplicationState, log, appDB); err != nil {
+		return
+	}
+
+	// 3) Set the application field of SyncOperations to nil, for all SyncOperations that point to this Application
+	if _, err := dbQueries.UpdateSyncOperationRemoveApplicationField(ctx, appDB.Application_id); err != nil {
+		log.Error(err, "Error occurred in cleanOrphanedEntriesfromTable_Application while updating SyncOperations of soft-deleted Application")
+		return
+	}
+
+	// 4) Delete the Application, and create an Operation to inform the cluster-agent to delete the Argo CD Application
+	var appArgo fauxargocd.FauxApplication
+	isApplicationPresent := true
+	if err := yaml.Unmarshal([]byte(appDB.Spec_field), &appArgo); err != nil {
+		log.Error(err, "Error occurred in cleanOrphanedEntriesfromTable_Application while unmarshalling application: "+appDB.Application_id)
+		isApplicationPresent = false
+	}
+
+	if err := deleteDbEntry(ctx, dbQueries, appDB.Application_id, dbType_Application, log, appDB); err != nil {
+		return
+	}
+
+	log.Info("Soft-deleted Application entry: " + appDB.Application_id + " is successfully deleted by cleanOrphanedEntriesfromTable_Application, as the grace period has elapsed.")
+
+	if isApplicationPresent {
+		createOperation(ctx, appDB.Engine_instance_inst_id, appDB.Application_id, appArgo.Namespace, db.OperationResourceType_Application, dbQueries, client, log)
+	}
+}
+
 // cleanOrphanedEntriesfromTable_Operation loops through Operations in database and verifies they are still valid (Having CR in Cluster). If not, the resources are deleted/Creates.
 func cleanOrphanedEntriesfromTable_Operation(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client, skipDelay bool, l logr.Logger) {
 
@@ -961,56 +1395,50 @@ func cleanOrphanedEntriesfromTable_ClusterUser(ctx context.Context, dbQueries db
 	}
 }
 
-// cleanOrphanedEntriesfromTable_ClusterCredential loops through ClusterCredentials in database and verifies they are still in use. If not, the credentials are deleted.
+// cleanOrphanedEntriesfromTable_ClusterCredential deletes the ClusterCredentials that are no longer referenced by any ManagedEnvironment
+// or GitopsEngineCluster, and that were created more than 'waitTimeforRowDelete' ago. Unreferenced ClusterCredentials would
+// otherwise accumulate, and they contain secrets (kubeconfigs and ServiceAccount tokens) of the clusters.
+// - The grace period ensures that ClusterCredentials that were just created, but are not yet referenced, are not deleted.
 func cleanOrphanedEntriesfromTable_ClusterCredential(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client, skipDelay bool, l logr.Logger) {
 	log := l.WithValues("job", "cleanOrphanedEntriesfromTable_ClusterCredential")
 
-	// Retrieve a list of cluster credentials from the other tables that reference ClusterCredential table
+	createdBefore := time.Now().Add(-waitTimeforRowDelete)
 
-	listOfClusterCredsFromManagedEnv := getListOfClusterCredentialIDsfromManagedEnvironmenTable(ctx, dbQueries, skipDelay, log)
+	var afterSeqID int64
+	deleted := 0
 
-	listOfClusterCredsFromGitOpsEngine := getListOfClusterCredentialIDsFromGitopsEngineTable(ctx, dbQueries, skipDelay, log)
-
-	offSet := 0
-	// Continuously iterate and fetch batches until all entries of ClusterCredentials table are processed.
+	// Continuously iterate and fetch batches until all unreferenced ClusterCredentials are processed.
 	for {
-		if offSet != 0 && !skipDelay {
+		if afterSeqID != 0 && !skipDelay {
 			time.Sleep(sleepIntervalsOfBatches)
 		}
 
 		var listOfClusterCredentialsFromDB []db.ClusterCredentials
 
-		// Fetch ClusterCredentials table entries in batch size as configured above.​
-		if err := dbQueries.GetClusterCredentialsBatch(ctx, &listOfClusterCredentialsFromDB, rowBatchSize, offSet); err != nil {
-			log.Error(err, fmt.Sprintf("Error occurred in cleanOrphanedEntriesfromTable_ClusterCredential while fetching batch from Offset: %d to %d: ",
-				offSet, offSet+rowBatchSize))
+		if err := dbQueries.ListUnreferencedClusterCredentials(ctx, createdBefore, afterSeqID, rowBatchSize, &listOfClusterCredentialsFromDB); err != nil {
+			log.Error(err, fmt.Sprintf("Error occurred in cleanOrphanedEntriesfromTable_ClusterCredential while fetching batch after seq_id: %d", afterSeqID))
 			break
 		}
 
 		// Break the loop if no entries are left in table to be processed.
 		if len(listOfClusterCredentialsFromDB) == 0 {
-			log.Info("All ClusterCredentials entries are processed by cleanOrphanedEntriesfromTable_ClusterCredential.")
+			log.Info("All ClusterCredentials entries are processed by cleanOrphanedEntriesfromTable_ClusterCredential.", "deleted", deleted)
 			break
 		}
 
-		// Iterate over batch received above.
 		for _, clusterCred := range listOfClusterCredentialsFromDB {
-			// Check if ClusterCredentials don't have entry in ManagedEnvironment or GitopsEngineCluster tables,
-			// and if created time is more than waitTimeforRowDelete
-			// if not, then delete the entry
-			if !(slices.Contains(listOfClusterCredsFromManagedEnv[dbType_ManagedEnvironment], clusterCred.Clustercredentials_cred_id) ||
-				slices.Contains(listOfClusterCredsFromGitOpsEngine[dbType_GitopsEngineCluster], clusterCred.Clustercredentials_cred_id)) &&
-				time.Since(clusterCred.Created_on) > waitTimeforRowDelete {
-
-				// 1) Remove the ClusterCredentials from the database
-				if err := deleteDbEntry(ctx, dbQueries, clusterCred.Clustercredentials_cred_id, dbType_ClusterCredentials, log, nil); err != nil {
-					log.Error(err, "Error occurred in cleanOrphanedEntriesfromTable_ClusterCredential while deleting ClusterCredentials entry : "+clusterCred.Clustercredentials_cred_id+" from DB.")
-				}
+
+			// A ManagedEnvironment/GitopsEngineCluster that references the ClusterCredentials may have been created since
+			// the batch was retrieved: in this case, the foreign key constraint prevents the deletion.
+			if err := deleteDbEntry(ctx, dbQueries, clusterCred.Clustercredentials_cred_id, dbType_ClusterCredentials, log, nil); err != nil {
+				log.Error(err, "Error occurred in cleanOrphanedEntriesfromTable_ClusterCredential while deleting ClusterCredentials entry : "+clusterCred.Clustercredentials_cred_id+" from DB.")
+				continue
 			}
+			deleted++
 		}
 
 		// Skip processed entries in next iteration
-		offSet += rowBatchSize
+		afterSeqID = listOfClusterCredentialsFromDB[len(listOfClusterCredentialsFromDB)-1].SeqID
 	}
 }
 
@@ -1196,82 +1624,6 @@ func getListOfUserIDsFromRespositoryCredentialsTable(ctx context.Context, dbQuer
 	return crIdMap
 }
 
-// getListOfClusterCredentialIDsfromManagedEnvironmenTable loops through ManagedEnvironments in database and returns list of resource IDs.
-func getListOfClusterCredentialIDsfromManagedEnvironmenTable(ctx context.Context, dbQueries db.DatabaseQueries, skipDelay bool, log logr.Logger) map[dbTableName][]string {
-
-	offSet := 0
-
-	// Create Map to store resource IDs according to type, Ex: {"ManagedEnvironment" : []}
-	crIdMap := make(map[dbTableName][]string)
-
-	// Continuously iterate and fetch batches until all entries of table processed.
-	for {
-		if offSet != 0 && !skipDelay {
-			time.Sleep(sleepIntervalsOfBatches)
-		}
-
-		var tempList []db.ManagedEnvironment
-
-		// Fetch ManagedEnvironment table entries in batch size as configured above.​
-		if err := dbQueries.GetManagedEnvironmentBatch(ctx, &tempList, rowBatchSize, offSet); err != nil {
-			log.Error(err, fmt.Sprintf("Error occurred in cleanOrphanedEntriesfromTable_ClusterUser while fetching batch from Offset: %d to %d: ",
-				offSet, offSet+rowBatchSize))
-			break
-		}
-
-		// Break the loop if no entries are left in table to be processed.
-		if len(tempList) == 0 {
-			break
-		}
-
-		for _, managedEnvironment := range tempList {
-			crIdMap[dbType_ManagedEnvironment] = append(crIdMap[dbType_ManagedEnvironment], managedEnvironment.Clustercredentials_id)
-		}
-
-		// Skip processed entries in next iteration
-		offSet += rowBatchSize
-	}
-	return crIdMap
-}
-
-// getListOfClusterCredentialIDsFromGitopsEngineTable loops through GitopsEngineCluster and returns list of resource IDs.
-func getListOfClusterCredentialIDsFromGitopsEngineTable(ctx context.Context, dbQueries db.DatabaseQueries, skipDelay bool, log logr.Logger) map[dbTableName][]string {
-
-	offSet := 0
-
-	// Create Map to store resource IDs according to type, Ex: {"GitopsEngineCluster" : []}
-	crIdMap := make(map[dbTableName][]string)
-
-	// Continuously iterate and fetch batches until all entries of table processed.
-	for {
-		if offSet != 0 && !skipDelay {
-			time.Sleep(sleepIntervalsOfBatches)
-		}
-
-		var tempList []db.GitopsEngineCluster
-
-		// Fetch GitopsEngineCluster table entries in batch size as configured above.​
-		if err := dbQueries.GetGitopsEngineClusterBatch(ctx, &tempList, rowBatchSize, offSet); err != nil {
-			log.Error(err, fmt.Sprintf("Error occurred in cleanOrphanedEntriesfromTable_ClusterUser while fetching batch from Offset: %d to %d: ",
-				offSet, offSet+rowBatchSize))
-			break
-		}
-
-		// Break the loop if no entries are left in table to be processed.
-		if len(tempList) == 0 {
-			break
-		}
-
-		for _, gitopsEngineCluster := range tempList {
-			crIdMap[dbType_GitopsEngineCluster] = append(crIdMap[dbType_GitopsEngineCluster], gitopsEngineCluster.Clustercredentials_id)
-		}
-
-		// Skip processed entries in next iteration
-		offSet += rowBatchSize
-	}
-	return crIdMap
-}
-
 // getListOfUserIDsfromOperationTable loops through Operation in database and returns list of resource IDs.
 func getListOfUserIDsfromOperationTable(ctx context.Context, dbQueries db.DatabaseQueries, skipDelay bool, log logr.Logger) map[dbTableName][]string {
 
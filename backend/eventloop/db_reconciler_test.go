@@ -2,6 +2,7 @@ package eventloop
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -19,6 +20,8 @@ import (
 
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	sharedoperations "github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -48,10 +51,18 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 				err := tests.GenericTestSetup()
 			Expect(err).To(BeNil())
 
+			// The namespace of the GitOpsDeployment
+			gitopsDeplNamespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-namespace",
+					UID:  "demo-namespace",
+				},
+			}
+
 			// Create fake client
 			k8sClient = fake.NewClientBuilder().
 				WithScheme(scheme).
-				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace, gitopsDeplNamespace).
 				Build()
 
 			err = db.SetupForTestingDBGinkgo()
@@ -244,6 +255,83 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
 
 		})
+
+		It("Should delete the database entries of every GitOpsDeployment of a namespace that was deleted", func() {
+			defer dbq.CloseDatabase()
+
+			By("creating DTAMs of a namespace that no longer exists, spanning more than one batch")
+			var deletedNamespaceDTAMs []db.DeploymentToApplicationMapping
+			for i := 0; i < rowBatchSize+2; i++ {
+				deletedNamespaceApplication := application
+				deletedNamespaceApplication.Application_id = "test-" + string(uuid.NewUUID())
+				Expect(dbq.CreateApplication(ctx, &deletedNamespaceApplication)).To(Succeed())
+
+				dtam := db.DeploymentToApplicationMapping{
+					Deploymenttoapplicationmapping_uid_id: "test-" + string(uuid.NewUUID()),
+					Application_id:                        deletedNamespaceApplication.Application_id,
+					DeploymentName:                        "test-deployment-" + string(uuid.NewUUID()),
+					DeploymentNamespace:                   "test-deleted-namespace",
+					NamespaceUID:                          "test-deleted-namespace-uid",
+				}
+				Expect(dbq.CreateDeploymentToApplicationMapping(ctx, &dtam)).To(Succeed())
+				deletedNamespaceDTAMs = append(deletedNamespaceDTAMs, dtam)
+			}
+
+			cleanOrphanedEntriesfromTable_DTAM(ctx, dbq, k8sClient, true, log)
+
+			By("verifying the entries of the deleted namespace were deleted")
+			var remainingDTAMs []db.DeploymentToApplicationMapping
+			err := dbq.ListDeploymentToApplicationMappingByNamespaceUID(ctx, "test-deleted-namespace-uid", &remainingDTAMs)
+			Expect(err).To(BeNil())
+			Expect(remainingDTAMs).To(BeEmpty())
+
+			for _, dtam := range deletedNamespaceDTAMs {
+				err = dbq.GetApplicationById(ctx, &db.Application{Application_id: dtam.Application_id})
+				Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+			}
+
+			By("verifying the entries of the GitOpsDeployment that still exists were not deleted")
+			err = dbq.GetDeploymentToApplicationMappingByApplicationId(ctx, &deploymentToApplicationMapping)
+			Expect(err).To(BeNil())
+
+			err = dbq.GetApplicationById(ctx, &application)
+			Expect(err).To(BeNil())
+		})
+
+		It("Should resume the database reconciliation from its checkpoint, and delete the checkpoint once the cycle completes", func() {
+			defer dbq.CloseDatabase()
+
+			_, err := dbq.DeleteTaskCheckpointByTaskName(ctx, databaseReconcilerTaskKey)
+			Expect(err).To(BeNil())
+
+			By("deleting the GitOpsDeployment, so that its DTAM is orphaned")
+			err = k8sClient.Delete(ctx, &gitopsDepl)
+			Expect(err).To(BeNil())
+
+			By("simulating a previous cycle that was interrupted after the DTAM step completed")
+			checkpointer, err := dbutil.NewTaskCheckpointer(ctx, dbq, databaseReconcilerTaskKey)
+			Expect(err).To(BeNil())
+			Expect(checkpointer.Save(ctx, dbutil.TaskProgress{Step: databaseReconcilerStep_ACTDM})).To(Succeed())
+
+			runDatabaseReconcilerCycle(ctx, dbq, k8sClient, MockSRLK8sClientFactory{fakeClient: k8sClient}, true, log)
+
+			By("verifying the DTAM step was skipped")
+			err = dbq.GetDeploymentToApplicationMappingByApplicationId(ctx, &deploymentToApplicationMapping)
+			Expect(err).To(BeNil())
+
+			By("verifying the checkpoint was deleted, so the next cycle runs every step")
+			checkpointer, err = dbutil.NewTaskCheckpointer(ctx, dbq, databaseReconcilerTaskKey)
+			Expect(err).To(BeNil())
+			Expect(checkpointer.IsResumed()).To(BeFalse())
+
+			runDatabaseReconcilerCycle(ctx, dbq, k8sClient, MockSRLK8sClientFactory{fakeClient: k8sClient}, true, log)
+
+			err = dbq.GetDeploymentToApplicationMappingByApplicationId(ctx, &deploymentToApplicationMapping)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			_, err = dbq.DeleteTaskCheckpointByTaskName(ctx, databaseReconcilerTaskKey)
+			Expect(err).To(BeNil())
+		})
 	})
 
 	Context("Testing cleanOrphanedEntriesfromTable_ACTDM function.", func() {
@@ -967,6 +1055,68 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 				Expect(err).To(BeNil())
 				Expect(len(operationList.Items)).To(Equal(0))
 			})
+
+			It("should only delete the entries of GitOpsDeploymentSyncRun CRs that are not present in cluster, if the APICRToDatabaseMapping was created before startup", func() {
+				defer dbq.CloseDatabase()
+
+				err := dbq.CreateAPICRToDatabaseMapping(ctx, &apiCRToDatabaseMappingDb)
+				Expect(err).To(BeNil())
+
+				createOrphanedEntries := func() (db.SyncOperation, db.APICRToDatabaseMapping) {
+					syncOperation := syncOperationDb
+					syncOperation.SyncOperation_id = "test-sync-" + string(uuid.NewUUID())
+					err := dbq.CreateSyncOperation(ctx, &syncOperation)
+					Expect(err).To(BeNil())
+
+					apiCRToDatabaseMapping := apiCRToDatabaseMappingDb
+					apiCRToDatabaseMapping.DBRelationKey = syncOperation.SyncOperation_id
+					apiCRToDatabaseMapping.APIResourceUID = "test-" + string(uuid.NewUUID())
+					apiCRToDatabaseMapping.APIResourceName = "test-" + string(uuid.NewUUID())
+					err = dbq.CreateAPICRToDatabaseMapping(ctx, &apiCRToDatabaseMapping)
+					Expect(err).To(BeNil())
+
+					return syncOperation, apiCRToDatabaseMapping
+				}
+
+				orphanedSyncOperation, orphanedMapping := createOrphanedEntries()
+
+				startupTime := time.Now()
+
+				newSyncOperation, newMapping := createOrphanedEntries()
+
+				By("Call cleanOrphanedEntriesfromTable_ACTDM_CreatedBefore function.")
+				cleanOrphanedEntriesfromTable_ACTDM_CreatedBefore(ctx, dbq, k8sClient, startupTime, true, log)
+
+				By("Verify that entries for the GitOpsDeploymentSyncRun which is not available in cluster, and was created before startup, are deleted from DB.")
+				err = dbq.GetSyncOperationById(ctx, &orphanedSyncOperation)
+				Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+				err = dbq.GetAPICRForDatabaseUID(ctx, &orphanedMapping)
+				Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+				var specialClusterUser db.ClusterUser
+				err = dbq.GetOrCreateSpecialClusterUser(context.Background(), &specialClusterUser)
+				Expect(err).To(BeNil())
+
+				var operationlist []db.Operation
+				err = dbq.ListOperationsByResourceIdAndTypeAndOwnerId(ctx, orphanedSyncOperation.SyncOperation_id, db.OperationResourceType_SyncOperation, &operationlist, specialClusterUser.Clusteruser_id)
+				Expect(err).To(BeNil())
+				Expect(operationlist).To(HaveLen(1))
+
+				By("Verify that entries for the GitOpsDeploymentSyncRun which is available in cluster, are not deleted from DB.")
+				err = dbq.GetSyncOperationById(ctx, &syncOperationDb)
+				Expect(err).To(BeNil())
+
+				err = dbq.GetAPICRForDatabaseUID(ctx, &apiCRToDatabaseMappingDb)
+				Expect(err).To(BeNil())
+
+				By("Verify that entries which were created after startup are not deleted from DB.")
+				err = dbq.GetSyncOperationById(ctx, &newSyncOperation)
+				Expect(err).To(BeNil())
+
+				err = dbq.GetAPICRForDatabaseUID(ctx, &newMapping)
+				Expect(err).To(BeNil())
+			})
 		})
 	})
 
@@ -1127,6 +1277,45 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 			err = dbq.GetApplicationById(ctx, &application)
 			Expect(err).To(BeNil())
 		})
+
+		It("Should delete a soft-deleted application entry, and its DTAM entry, only once the soft-delete grace period has elapsed.", func() {
+			defer dbq.CloseDatabase()
+
+			Expect(os.Setenv(sharedutil.ApplicationSoftDeleteGracePeriodEnvVar, "60")).To(Succeed())
+			defer func() {
+				Expect(os.Unsetenv(sharedutil.ApplicationSoftDeleteGracePeriodEnvVar)).To(Succeed())
+			}()
+
+			By("soft-deleting the application, which has a DTAM entry.")
+
+			_, err := dbq.SoftDeleteApplicationById(ctx, application.Application_id)
+			Expect(err).To(BeNil())
+
+			By("Call clean-up function, and verify the application is not deleted within the grace period.")
+
+			cleanOrphanedEntriesfromTable_Application(ctx, dbq, k8sClient, true, log)
+
+			err = dbq.GetApplicationById(ctx, &application)
+			Expect(err).To(BeNil())
+			Expect(application.IsSoftDeleted()).To(BeTrue())
+
+			By("Set 'Deleted_on' field to before the grace period.")
+
+			deletedOn := time.Now().Add(-61 * time.Minute)
+			application.Deleted_on = &deletedOn
+			err = dbq.UpdateApplication(ctx, &application)
+			Expect(err).To(BeNil())
+
+			By("Call clean-up function, and verify the application and DTAM entries are deleted.")
+
+			cleanOrphanedEntriesfromTable_Application(ctx, dbq, k8sClient, true, log)
+
+			err = dbq.GetApplicationById(ctx, &application)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			err = dbq.GetDeploymentToApplicationMappingByDeplId(ctx, &deploymentToApplicationMapping)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+		})
 	})
 
 	Context("Testing cleanOrphanedEntriesfromTable function for RepositoryCredentials table entries.", func() {
@@ -2327,3 +2516,7 @@ func (f MockSRLK8sClientFactory) GetK8sClientForGitOpsEngineInstance(ctx context
 func (f MockSRLK8sClientFactory) GetK8sClientForServiceWorkspace() (client.Client, error) {
 	return f.fakeClient, nil
 }
+
+func (f MockSRLK8sClientFactory) DiscoverClusterCapabilities(restConfig *rest.Config) (shared_resource_loop.ClusterCapabilities, error) {
+	return shared_resource_loop.ClusterCapabilities{}, nil
+}
@@ -17,11 +17,12 @@ import (
 type EventLoopEventType string
 
 const (
-	DeploymentModified           EventLoopEventType = "DeploymentModified"
-	RepositoryCredentialModified EventLoopEventType = "RepositoryCredentialModified"
-	ManagedEnvironmentModified   EventLoopEventType = "ManagedEnvironmentModified"
-	SyncRunModified              EventLoopEventType = "SyncRunModified"
-	UpdateDeploymentStatusTick   EventLoopEventType = "UpdateDeploymentStatusTick"
+	DeploymentModified                   EventLoopEventType = "DeploymentModified"
+	RepositoryCredentialModified         EventLoopEventType = "RepositoryCredentialModified"
+	RepositoryCredentialTemplateModified EventLoopEventType = "RepositoryCredentialTemplateModified"
+	ManagedEnvironmentModified           EventLoopEventType = "ManagedEnvironmentModified"
+	SyncRunModified                      EventLoopEventType = "SyncRunModified"
+	UpdateDeploymentStatusTick           EventLoopEventType = "UpdateDeploymentStatusTick"
 )
 
 const KubeSystemNamespace = "kube-system"
@@ -74,6 +75,13 @@ func (ele *EventLoopEvent) GetReqResourceAsSimpleClientObject() (client.Object,
 				Namespace: ele.Request.Namespace,
 			},
 		}
+	} else if ele.ReqResource == GitOpsDeploymentRepositoryCredentialTemplateTypeName {
+		resource = &gitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ele.Request.Name,
+				Namespace: ele.Request.Namespace,
+			},
+		}
 	} else {
 		return nil, fmt.Errorf("SEVERE - unexpected request resource type: %v", string(ele.ReqResource))
 	}
@@ -121,10 +129,11 @@ func StringEventLoopEvent(obj *EventLoopEvent) string {
 type GitOpsResourceType string
 
 const (
-	GitOpsDeploymentTypeName                     GitOpsResourceType = "GitOpsDeployment"
-	GitOpsDeploymentSyncRunTypeName              GitOpsResourceType = "GitOpsDeploymentSyncRun"
-	GitOpsDeploymentRepositoryCredentialTypeName GitOpsResourceType = "GitOpsDeploymentRepositoryCredential"
-	GitOpsDeploymentManagedEnvironmentTypeName   GitOpsResourceType = "GitOpsDeploymentManagedEnvironmentTypeName"
+	GitOpsDeploymentTypeName                             GitOpsResourceType = "GitOpsDeployment"
+	GitOpsDeploymentSyncRunTypeName                      GitOpsResourceType = "GitOpsDeploymentSyncRun"
+	GitOpsDeploymentRepositoryCredentialTypeName         GitOpsResourceType = "GitOpsDeploymentRepositoryCredential"
+	GitOpsDeploymentRepositoryCredentialTemplateTypeName GitOpsResourceType = "GitOpsDeploymentRepositoryCredentialTemplate"
+	GitOpsDeploymentManagedEnvironmentTypeName           GitOpsResourceType = "GitOpsDeploymentManagedEnvironmentTypeName"
 )
 
 func GetWorkspaceIDFromNamespaceID(namespace corev1.Namespace) string {
@@ -0,0 +1,526 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	goyaml "gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Namespace migration moves all the Applications of a namespace (workspace) from one GitopsEngineInstance (Argo CD
+// instance) to another. It is triggered by an administrator, by annotating the namespace with the ID of the target
+// instance (see sharedutil.NamespaceMigrateToGitOpsEngineInstanceAnnotation).
+//
+// The Applications are migrated without interrupting the deployment of their resources: the Argo CD Applications are
+// first created on the target instance, then the migration waits for them to be healthy, and only then are the Argo CD
+// Applications deleted from the source instance (orphaning, rather than deleting, the resources they deployed).
+//
+// As with namespace offboarding, the step that is in progress is persisted, in the MigrationOperation table, so that a
+// migration which is interrupted (for example, by a restart of the backend) is resumed from that step on startup.
+//
+// Note: the RepositoryCredentials of the namespace are not migrated, as they are shared by the Argo CD instance of the
+// GitopsEngineCluster: private repositories must already be accessible from the target instance.
+
+const (
+	MigrationOperationStep_CreateOnTarget   = "createontarget"
+	MigrationOperationStep_WaitForHealthy   = "waitforhealthy"
+	MigrationOperationStep_DeleteFromSource = "deletefromsource"
+
+	// migrationWaitForHealthyTimeout is the maximum amount of time that the migration waits for the Argo CD Applications
+	// on the target instance to be healthy. Once expired, the migration continues: an Application that is unhealthy
+	// on the target instance would (most likely) be equally unhealthy on the source instance.
+	migrationWaitForHealthyTimeout = 15 * time.Minute
+
+	// migrationResumeInterval is the interval between attempts to resume the migrations that are in progress
+	migrationResumeInterval = 30 * time.Second
+
+	argoCDHealthStatusHealthy = "Healthy"
+)
+
+// migrationOperationSteps is the order in which the migration steps are run.
+var migrationOperationSteps = []string{
+	MigrationOperationStep_CreateOnTarget,
+	MigrationOperationStep_WaitForHealthy,
+	MigrationOperationStep_DeleteFromSource,
+}
+
+// NamespaceMigrator migrates the Applications of a namespace from one GitopsEngineInstance to another.
+type NamespaceMigrator struct {
+	client.Client
+	DB db.DatabaseQueries
+
+	// DirectMode is true if the Argo CD Applications of migrated Application rows are applied by the backend, rather than
+	// by the cluster-agent (see the description of direct mode in the operations package).
+	DirectMode bool
+
+	// mutex ensures that only one namespace is migrated at a time, so that a migration that is being resumed does not
+	// race with the same migration being triggered by the namespace controller.
+	mutex sync.Mutex
+}
+
+// StartNamespaceMigrationResumer resumes, in the background task queue, the migration of any namespaces whose migration
+// was interrupted before it completed (for example, by a restart of the backend).
+func (m *NamespaceMigrator) StartNamespaceMigrationResumer() {
+	m.startNamespaceMigrationResumerAfter(0)
+}
+
+// startNamespaceMigrationResumerAfter adds the resumer task to the background task queue, once the delay has elapsed:
+// the task adds itself back to the queue until all the migrations have completed.
+func (m *NamespaceMigrator) startNamespaceMigrationResumerAfter(delay time.Duration) {
+	getBackgroundTaskQueue().AddAfter(namespaceMigrationResumerTaskKey, delay, func(ctx context.Context) error {
+		log := log.FromContext(ctx).
+			WithName(logutil.LogLogger_managed_gitops).
+			WithValues("component", "namespace-migration")
+
+		if m.ResumeNamespaceMigrations(ctx, log) {
+			m.startNamespaceMigrationResumerAfter(migrationResumeInterval)
+		}
+		return nil
+	})
+}
+
+// ResumeNamespaceMigrations resumes the migration of every namespace that has a row in the MigrationOperation table.
+// Returns true if any of the migrations have not yet completed (for example, they are waiting for the Applications to be
+// healthy), and thus should be resumed again later.
+func (m *NamespaceMigrator) ResumeNamespaceMigrations(ctx context.Context, log logr.Logger) bool {
+
+	var migrationOperations []db.MigrationOperation
+	if err := m.DB.ListMigrationOperations(ctx, &migrationOperations); err != nil {
+		log.Error(err, "unable to list namespace migrations to resume")
+		return true
+	}
+
+	remaining := false
+	for _, migrationOperation := range migrationOperations {
+		log.Info("Resuming namespace migration", migrationOperation.GetAsLogKeyValues()...)
+
+		complete, err := m.MigrateNamespace(ctx, migrationOperation.NamespaceUID, migrationOperation.NamespaceName,
+			migrationOperation.TargetGitopsEngineInstanceID, log)
+		if err != nil {
+			log.Error(err, "unable to resume namespace migration", migrationOperation.GetAsLogKeyValues()...)
+		}
+		if err != nil || !complete {
+			remaining = true
+		}
+	}
+
+	return remaining
+}
+
+// MigrateNamespace migrates the Applications of the namespace with the given UID to the target GitopsEngineInstance,
+// resuming from the persisted step if the migration of the namespace was previously started.
+//
+// Returns true once all of the Applications of the namespace are on the target instance. Returns false if the migration
+// is waiting (for example, for the Argo CD Applications to be healthy on the target instance), in which case
+// MigrateNamespace should be called again later.
+func (m *NamespaceMigrator) MigrateNamespace(ctx context.Context, namespaceUID string, namespaceName string,
+	targetGitopsEngineInstanceID string, l logr.Logger) (bool, error) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	log := l.WithValues("namespaceUID", namespaceUID, "namespaceName", namespaceName)
+
+	// The Applications of a namespace may be on more than one source instance: they are migrated from one source
+	// instance at a time, until they are all on the target instance.
+	for {
+		// 1) Retrieve the migration in progress, or create it if there are Applications left to migrate
+		migrationOperation := db.MigrationOperation{NamespaceUID: namespaceUID}
+		if err := m.DB.GetMigrationOperationByNamespaceUID(ctx, &migrationOperation); err != nil {
+
+			if !db.IsResultNotFoundError(err) {
+				return false, fmt.Errorf("unable to retrieve namespace migration: %v", err)
+			}
+
+			sourceGitopsEngineInstanceID, err := m.findApplicationSourceInstance(ctx, namespaceUID, targetGitopsEngineInstanceID)
+			if err != nil {
+				return false, err
+			}
+
+			if sourceGitopsEngineInstanceID == "" {
+				// All the Applications of the namespace are on the target instance
+				return true, nil
+			}
+
+			migrationOperation = db.MigrationOperation{
+				NamespaceUID:                 namespaceUID,
+				NamespaceName:                namespaceName,
+				SourceGitopsEngineInstanceID: sourceGitopsEngineInstanceID,
+				TargetGitopsEngineInstanceID: targetGitopsEngineInstanceID,
+				Step:                         migrationOperationSteps[0],
+			}
+			if err := m.DB.CreateMigrationOperation(ctx, &migrationOperation); err != nil {
+				return false, fmt.Errorf("unable to create namespace migration: %v", err)
+			}
+			log.Info("Started namespace migration", migrationOperation.GetAsLogKeyValues()...)
+
+		} else if migrationOperation.TargetGitopsEngineInstanceID != targetGitopsEngineInstanceID {
+			// A migration to a different target is already in progress: it must complete before another is started.
+			log.Info("Waiting for the namespace migration in progress to complete, before migrating to a different GitOpsEngineInstance",
+				append(migrationOperation.GetAsLogKeyValues(), "requestedTargetGitopsEngineInstanceID", targetGitopsEngineInstanceID)...)
+			targetGitopsEngineInstanceID = migrationOperation.TargetGitopsEngineInstanceID
+		}
+
+		// 2) Run each step, starting from the step that was in progress
+		complete, err := m.runMigrationSteps(ctx, &migrationOperation, log)
+		if err != nil || !complete {
+			return false, err
+		}
+
+		// 3) All the steps have completed, so the migration from the source instance is complete
+		if _, err := m.DB.DeleteMigrationOperationByNamespaceUID(ctx, namespaceUID); err != nil {
+			return false, fmt.Errorf("unable to delete namespace migration: %v", err)
+		}
+
+		log.Info("Namespace migration complete", migrationOperation.GetAsLogKeyValues()...)
+	}
+}
+
+// runMigrationSteps runs the steps of the migration, starting from the persisted step. Returns false if a step is
+// waiting, and has not yet completed.
+func (m *NamespaceMigrator) runMigrationSteps(ctx context.Context, migrationOperation *db.MigrationOperation, log logr.Logger) (bool, error) {
+
+	started := false
+	for _, step := range migrationOperationSteps {
+
+		if !started && step != migrationOperation.Step {
+			// The step completed before the migration was interrupted
+			continue
+		}
+
+		if started {
+			migrationOperation.Step = step
+			if err := m.DB.UpdateMigrationOperation(ctx, migrationOperation); err != nil {
+				return false, fmt.Errorf("unable to update namespace migration step to '%s': %v", step, err)
+			}
+		}
+		started = true
+
+		log.Info("Running namespace migration step", "step", step)
+
+		complete, err := m.runMigrationStep(ctx, step, *migrationOperation, log)
+		if err != nil {
+			return false, fmt.Errorf("unable to complete namespace migration step '%s': %v", step, err)
+		}
+		if !complete {
+			return false, nil
+		}
+	}
+
+	if !started {
+		// Sanity check that the persisted step is one that we recognize
+		return false, fmt.Errorf("SEVERE: unrecognized namespace migration step '%s'", migrationOperation.Step)
+	}
+
+	return true, nil
+}
+
+func (m *NamespaceMigrator) runMigrationStep(ctx context.Context, step string, migrationOperation db.MigrationOperation, log logr.Logger) (bool, error) {
+
+	sourceInstance := db.GitopsEngineInstance{Gitopsengineinstance_id: migrationOperation.SourceGitopsEngineInstanceID}
+	if err := m.DB.GetGitopsEngineInstanceById(ctx, &sourceInstance); err != nil {
+		return false, fmt.Errorf("unable to retrieve source GitOpsEngineInstance: %v", err)
+	}
+
+	targetInstance := db.GitopsEngineInstance{Gitopsengineinstance_id: migrationOperation.TargetGitopsEngineInstanceID}
+	if err := m.DB.GetGitopsEngineInstanceById(ctx, &targetInstance); err != nil {
+		return false, fmt.Errorf("unable to retrieve target GitOpsEngineInstance: %v", err)
+	}
+
+	switch step {
+	case MigrationOperationStep_CreateOnTarget:
+		return true, m.createApplicationsOnTarget(ctx, migrationOperation, sourceInstance, targetInstance, log)
+
+	case MigrationOperationStep_WaitForHealthy:
+		return m.waitForApplicationsHealthyOnTarget(ctx, migrationOperation, targetInstance, log)
+
+	case MigrationOperationStep_DeleteFromSource:
+		return true, m.deleteApplicationsFromSource(ctx, migrationOperation, sourceInstance, log)
+
+	default:
+		return false, fmt.Errorf("SEVERE: unrecognized namespace migration step '%s'", step)
+	}
+}
+
+// createApplicationsOnTarget moves the Application rows of the namespace from the source instance to the target
+// instance, and creates Operations to inform the cluster-agent to create the Argo CD Applications on the target instance.
+func (m *NamespaceMigrator) createApplicationsOnTarget(ctx context.Context, migrationOperation db.MigrationOperation,
+	sourceInstance db.GitopsEngineInstance, targetInstance db.GitopsEngineInstance, log logr.Logger) error {
+
+	applications, err := m.listApplicationsOfNamespace(ctx, migrationOperation.NamespaceUID)
+	if err != nil {
+		return err
+	}
+
+	for i := range applications {
+		if i != 0 && i%rowBatchSize == 0 {
+			time.Sleep(sleepIntervalsOfBatches)
+		}
+
+		application := applications[i]
+
+		if application.Engine_instance_inst_id == sourceInstance.Gitopsengineinstance_id {
+			if err := m.moveApplicationToTarget(ctx, &application, targetInstance, log); err != nil {
+				return err
+			}
+		}
+
+		// An Operation is (re)created for every Application that is now on the target instance, in case the step was
+		// previously interrupted after the Application was moved.
+		if application.Engine_instance_inst_id == targetInstance.Gitopsengineinstance_id {
+			if err := m.createApplicationOperation(ctx, application.Application_id, targetInstance, log); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// moveApplicationToTarget grants the owners of the Application access to its ManagedEnvironment from the target
+// instance, and then updates the Application row to reference the target instance.
+func (m *NamespaceMigrator) moveApplicationToTarget(ctx context.Context, application *db.Application,
+	targetInstance db.GitopsEngineInstance, l logr.Logger) error {
+
+	log := l.WithValues("applicationID", application.Application_id)
+
+	if application.Managed_environment_id != "" {
+		var applicationOwners []db.ApplicationOwner
+		if err := m.DB.ListApplicationOwnersByApplicationID(ctx, application.Application_id, &applicationOwners); err != nil {
+			return err
+		}
+
+		for _, applicationOwner := range applicationOwners {
+			clusterAccess := db.ClusterAccess{
+				Clusteraccess_user_id:                   applicationOwner.ClusterUserID,
+				Clusteraccess_managed_environment_id:    application.Managed_environment_id,
+				Clusteraccess_gitops_engine_instance_id: targetInstance.Gitopsengineinstance_id,
+			}
+
+			if err := m.DB.GetClusterAccessByPrimaryKey(ctx, &clusterAccess); err == nil {
+				continue
+			} else if !db.IsResultNotFoundError(err) {
+				return err
+			}
+
+			if err := m.DB.CreateClusterAccess(ctx, &clusterAccess); err != nil {
+				return fmt.Errorf("unable to create ClusterAccess on target GitOpsEngineInstance: %v", err)
+			}
+			log.Info("Created ClusterAccess on target GitOpsEngineInstance", clusterAccess.GetAsLogKeyValues()...)
+		}
+	}
+
+	specField, err := setNamespaceInSpecField(application.Spec_field, targetInstance.Namespace_name)
+	if err != nil {
+		return fmt.Errorf("unable to update the namespace of the spec field of Application '%s': %v", application.Application_id, err)
+	}
+
+	application.Engine_instance_inst_id = targetInstance.Gitopsengineinstance_id
+	application.Spec_field = specField
+
+	// The Application row may be concurrently updated by the application event loop: if so, the migration step is retried.
+	if err := m.DB.CompareAndSwapApplication(ctx, application); err != nil {
+		return fmt.Errorf("unable to move Application '%s' to target GitOpsEngineInstance: %v", application.Application_id, err)
+	}
+
+	log.Info("Moved Application to target GitOpsEngineInstance", "gitopsEngineInstanceID", targetInstance.Gitopsengineinstance_id)
+
+	return nil
+}
+
+// waitForApplicationsHealthyOnTarget returns true once all the Argo CD Applications of the namespace on the target
+// instance are healthy, or once migrationWaitForHealthyTimeout has expired.
+func (m *NamespaceMigrator) waitForApplicationsHealthyOnTarget(ctx context.Context, migrationOperation db.MigrationOperation,
+	targetInstance db.GitopsEngineInstance, log logr.Logger) (bool, error) {
+
+	applications, err := m.listApplicationsOfNamespace(ctx, migrationOperation.NamespaceUID)
+	if err != nil {
+		return false, err
+	}
+
+	var unhealthyApplications []string
+	for _, application := range applications {
+
+		// Argo CD Applications are not created for Applications that do not have a ManagedEnvironment
+		if application.Engine_instance_inst_id != targetInstance.Gitopsengineinstance_id || application.Managed_environment_id == "" {
+			continue
+		}
+
+		healthStatus, err := m.getArgoCDApplicationHealthStatus(ctx, application.Name, targetInstance.Namespace_name)
+		if err != nil {
+			return false, err
+		}
+
+		if healthStatus != argoCDHealthStatusHealthy {
+			unhealthyApplications = append(unhealthyApplications, application.Name)
+		}
+	}
+
+	if len(unhealthyApplications) == 0 {
+		return true, nil
+	}
+
+	if time.Since(migrationOperation.Step_started_on) > migrationWaitForHealthyTimeout {
+		log.V(logutil.LogLevel_Warn).Info("Argo CD Applications were not healthy on the target GitOpsEngineInstance before the timeout expired: the namespace migration will continue",
+			"unhealthyApplications", unhealthyApplications)
+		return true, nil
+	}
+
+	log.Info("Waiting for Argo CD Applications to be healthy on the target GitOpsEngineInstance", "unhealthyApplications", unhealthyApplications)
+
+	return false, nil
+}
+
+// deleteApplicationsFromSource creates Operations to inform the cluster-agent to delete the Argo CD Applications from
+// the source instance: as the Application rows now reference the target instance, the cluster-agent deletes the Argo CD
+// Applications of the source instance without deleting the resources they deployed.
+func (m *NamespaceMigrator) deleteApplicationsFromSource(ctx context.Context, migrationOperation db.MigrationOperation,
+	sourceInstance db.GitopsEngineInstance, log logr.Logger) error {
+
+	applications, err := m.listApplicationsOfNamespace(ctx, migrationOperation.NamespaceUID)
+	if err != nil {
+		return err
+	}
+
+	for i, application := range applications {
+		if i != 0 && i%rowBatchSize == 0 {
+			time.Sleep(sleepIntervalsOfBatches)
+		}
+
+		if application.Engine_instance_inst_id != migrationOperation.TargetGitopsEngineInstanceID {
+			continue
+		}
+
+		if err := m.createApplicationOperation(ctx, application.Application_id, sourceInstance, log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findApplicationSourceInstance returns the ID of a GitopsEngineInstance, other than the target, that an Application of
+// the namespace is on, or "" if all the Applications of the namespace are on the target instance.
+func (m *NamespaceMigrator) findApplicationSourceInstance(ctx context.Context, namespaceUID string, targetGitopsEngineInstanceID string) (string, error) {
+
+	targetInstance := db.GitopsEngineInstance{Gitopsengineinstance_id: targetGitopsEngineInstanceID}
+	if err := m.DB.GetGitopsEngineInstanceById(ctx, &targetInstance); err != nil {
+		return "", fmt.Errorf("unable to retrieve target GitOpsEngineInstance '%s': %v", targetGitopsEngineInstanceID, err)
+	}
+
+	applications, err := m.listApplicationsOfNamespace(ctx, namespaceUID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, application := range applications {
+		if application.Engine_instance_inst_id != targetGitopsEngineInstanceID {
+			return application.Engine_instance_inst_id, nil
+		}
+	}
+
+	return "", nil
+}
+
+// listApplicationsOfNamespace returns the Application rows of the GitOpsDeployments of the namespace.
+func (m *NamespaceMigrator) listApplicationsOfNamespace(ctx context.Context, namespaceUID string) ([]db.Application, error) {
+
+	var deplToAppMappings []db.DeploymentToApplicationMapping
+	if err := m.DB.ListDeploymentToApplicationMappingByNamespaceUID(ctx, namespaceUID, &deplToAppMappings); err != nil {
+		return nil, err
+	}
+
+	var applications []db.Application
+	for _, deplToAppMapping := range deplToAppMappings {
+
+		application := db.Application{Application_id: deplToAppMapping.Application_id}
+		if err := m.DB.GetApplicationById(ctx, &application); err != nil {
+			if db.IsResultNotFoundError(err) {
+				// The Application was deleted since the mapping was listed
+				continue
+			}
+			return nil, err
+		}
+
+		applications = append(applications, application)
+	}
+
+	return applications, nil
+}
+
+// getArgoCDApplicationHealthStatus returns the health status of the Argo CD Application, or "" if the Argo CD Application
+// does not exist (yet) or its health has not yet been assessed.
+func (m *NamespaceMigrator) getArgoCDApplicationHealthStatus(ctx context.Context, name string, namespace string) (string, error) {
+
+	// The Argo CD types are not registered in the scheme of the backend, so the Application is retrieved as unstructured.
+	argoCDApplication := &unstructured.Unstructured{}
+	argoCDApplication.SetGroupVersionKind(schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"})
+
+	if err := m.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, argoCDApplication); err != nil {
+		if apierr.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to retrieve Argo CD Application '%s' in '%s': %v", name, namespace, err)
+	}
+
+	healthStatus, _, err := unstructured.NestedString(argoCDApplication.Object, "status", "health", "status")
+	if err != nil {
+		return "", fmt.Errorf("unable to read the health of Argo CD Application '%s' in '%s': %v", name, namespace, err)
+	}
+
+	return healthStatus, nil
+}
+
+// createApplicationOperation creates an Operation for the Application, in the namespace of the GitopsEngineInstance.
+func (m *NamespaceMigrator) createApplicationOperation(ctx context.Context, applicationID string,
+	gitopsEngineInstance db.GitopsEngineInstance, log logr.Logger) error {
+
+	var specialClusterUser db.ClusterUser
+	if err := m.DB.GetOrCreateSpecialClusterUser(ctx, &specialClusterUser); err != nil {
+		return fmt.Errorf("unable to fetch special cluster user: %v", err)
+	}
+
+	operationDb := db.Operation{
+		Instance_id:   gitopsEngineInstance.Gitopsengineinstance_id,
+		Resource_id:   applicationID,
+		Resource_type: db.OperationResourceType_Application,
+	}
+
+	if _, _, err := operations.CreateOperationWithDirectMode(ctx, m.DirectMode, false, operationDb, specialClusterUser.Clusteruser_id,
+		gitopsEngineInstance.Namespace_name, m.DB, m.Client, log); err != nil {
+		return fmt.Errorf("unable to create operation: %v", err)
+	}
+
+	return nil
+}
+
+// setNamespaceInSpecField returns the spec field, with the namespace of the Argo CD Application set to the given
+// namespace (that is, the namespace of the Argo CD instance that the Application is on).
+func setNamespaceInSpecField(specField string, namespace string) (string, error) {
+
+	var application fauxargocd.FauxApplication
+	if err := goyaml.Unmarshal([]byte(specField), &application); err != nil {
+		return "", err
+	}
+
+	application.Namespace = namespace
+
+	resBytes, err := goyaml.Marshal(application)
+	if err != nil {
+		return "", err
+	}
+	return string(resBytes), nil
+}
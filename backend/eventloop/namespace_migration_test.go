@@ -0,0 +1,210 @@
+package eventloop
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Namespace Migration Tests", func() {
+
+	Context("Testing MigrateNamespace function.", func() {
+
+		var log logr.Logger
+		var ctx context.Context
+		var dbq db.AllDatabaseQueries
+		var k8sClient client.Client
+		var migrator *NamespaceMigrator
+		var namespaceUID string
+		var clusterUser db.ClusterUser
+		var application db.Application
+		var sourceInstance *db.GitopsEngineInstance
+		var targetInstance db.GitopsEngineInstance
+
+		BeforeEach(func() {
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				apiNamespace,
+				err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace).
+				Build()
+
+			err = db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+			log = logger.FromContext(ctx)
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			migrator = &NamespaceMigrator{
+				Client: k8sClient,
+				DB:     dbq,
+			}
+
+			var managedEnvironment *db.ManagedEnvironment
+			var engineCluster *db.GitopsEngineCluster
+			_, managedEnvironment, engineCluster, sourceInstance, _, err = db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			targetInstance = db.GitopsEngineInstance{
+				Gitopsengineinstance_id: "test-target-engine-instance-id",
+				Namespace_name:          "gitops-service-argocd-2",
+				Namespace_uid:           "test-target-namespace-uid",
+				EngineCluster_id:        engineCluster.Gitopsenginecluster_id,
+			}
+			Expect(dbq.CreateGitopsEngineInstance(ctx, &targetInstance)).To(Succeed())
+
+			clusterUser = db.ClusterUser{
+				Clusteruser_id: "test-migration-user-id",
+				User_name:      "test-migration-user",
+			}
+			Expect(dbq.CreateClusterUser(ctx, &clusterUser)).To(Succeed())
+
+			namespaceUID = "test-" + string(uuid.NewUUID())
+
+			By("creating an Application on the source instance, and its DeploymentToApplicationMapping, in the namespace")
+			application = db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "my-application",
+				Spec_field:              "{}",
+				Engine_instance_inst_id: sourceInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(dbq.CreateApplication(ctx, &application)).To(Succeed())
+
+			Expect(dbq.CreateApplicationOwner(ctx, &db.ApplicationOwner{
+				ApplicationID: application.Application_id,
+				ClusterUserID: clusterUser.Clusteruser_id,
+			})).To(Succeed())
+
+			Expect(dbq.CreateDeploymentToApplicationMapping(ctx, &db.DeploymentToApplicationMapping{
+				Deploymenttoapplicationmapping_uid_id: "test-" + string(uuid.NewUUID()),
+				Application_id:                        application.Application_id,
+				DeploymentName:                        "test-deployment",
+				DeploymentNamespace:                   "test-namespace",
+				NamespaceUID:                          namespaceUID,
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			dbq.CloseDatabase()
+		})
+
+		// createArgoCDApplication creates the Argo CD Application of the Application on the target instance, with the given health
+		createArgoCDApplication := func(health string) {
+			argoCDApplication := &unstructured.Unstructured{}
+			argoCDApplication.SetAPIVersion("argoproj.io/v1alpha1")
+			argoCDApplication.SetKind("Application")
+			argoCDApplication.SetName(application.Name)
+			argoCDApplication.SetNamespace(targetInstance.Namespace_name)
+			Expect(unstructured.SetNestedField(argoCDApplication.Object, health, "status", "health", "status")).To(Succeed())
+
+			Expect(k8sClient.Create(ctx, argoCDApplication)).To(Succeed())
+		}
+
+		It("should create the Applications on the target instance, wait for them to be healthy, and then delete them from the source instance", func() {
+
+			By("verifying the migration waits for the Argo CD Application to be healthy on the target instance")
+			complete, err := migrator.MigrateNamespace(ctx, namespaceUID, "test-namespace", targetInstance.Gitopsengineinstance_id, log)
+			Expect(err).To(BeNil())
+			Expect(complete).To(BeFalse())
+
+			migrationOperation := db.MigrationOperation{NamespaceUID: namespaceUID}
+			Expect(dbq.GetMigrationOperationByNamespaceUID(ctx, &migrationOperation)).To(Succeed())
+			Expect(migrationOperation.Step).To(Equal(MigrationOperationStep_WaitForHealthy))
+			Expect(migrationOperation.SourceGitopsEngineInstanceID).To(Equal(sourceInstance.Gitopsengineinstance_id))
+
+			By("verifying the Application was moved to the target instance")
+			Expect(dbq.GetApplicationById(ctx, &application)).To(Succeed())
+			Expect(application.Engine_instance_inst_id).To(Equal(targetInstance.Gitopsengineinstance_id))
+			Expect(application.Spec_field).To(ContainSubstring(targetInstance.Namespace_name))
+
+			Expect(dbq.GetClusterAccessByPrimaryKey(ctx, &db.ClusterAccess{
+				Clusteraccess_user_id:                   clusterUser.Clusteruser_id,
+				Clusteraccess_managed_environment_id:    application.Managed_environment_id,
+				Clusteraccess_gitops_engine_instance_id: targetInstance.Gitopsengineinstance_id,
+			})).To(Succeed())
+
+			var operationList managedgitopsv1alpha1.OperationList
+			Expect(k8sClient.List(ctx, &operationList, client.InNamespace(targetInstance.Namespace_name))).To(Succeed())
+			Expect(operationList.Items).To(HaveLen(1))
+
+			By("verifying the migration does not continue while the Argo CD Application is not healthy")
+			createArgoCDApplication("Progressing")
+
+			complete, err = migrator.MigrateNamespace(ctx, namespaceUID, "test-namespace", targetInstance.Gitopsengineinstance_id, log)
+			Expect(err).To(BeNil())
+			Expect(complete).To(BeFalse())
+
+			By("verifying the migration completes once the Argo CD Application is healthy")
+			argoCDApplication := &unstructured.Unstructured{}
+			argoCDApplication.SetAPIVersion("argoproj.io/v1alpha1")
+			argoCDApplication.SetKind("Application")
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: targetInstance.Namespace_name, Name: application.Name}, argoCDApplication)).To(Succeed())
+			Expect(unstructured.SetNestedField(argoCDApplication.Object, "Healthy", "status", "health", "status")).To(Succeed())
+			Expect(k8sClient.Update(ctx, argoCDApplication)).To(Succeed())
+
+			complete, err = migrator.MigrateNamespace(ctx, namespaceUID, "test-namespace", targetInstance.Gitopsengineinstance_id, log)
+			Expect(err).To(BeNil())
+			Expect(complete).To(BeTrue())
+
+			By("verifying an Operation was created to delete the Argo CD Application from the source instance")
+			Expect(k8sClient.List(ctx, &operationList, client.InNamespace(sourceInstance.Namespace_name))).To(Succeed())
+			Expect(operationList.Items).To(HaveLen(1))
+
+			By("verifying the progress of the migration was deleted")
+			err = dbq.GetMigrationOperationByNamespaceUID(ctx, &db.MigrationOperation{NamespaceUID: namespaceUID})
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+		})
+
+		It("should resume an interrupted migration, and continue once the wait for healthy has timed out", func() {
+
+			By("persisting a migration that was interrupted during the wait for healthy step")
+			application.Engine_instance_inst_id = targetInstance.Gitopsengineinstance_id
+			Expect(dbq.UpdateApplication(ctx, &application)).To(Succeed())
+
+			Expect(dbq.CreateMigrationOperation(ctx, &db.MigrationOperation{
+				NamespaceUID:                 namespaceUID,
+				NamespaceName:                "test-namespace",
+				SourceGitopsEngineInstanceID: sourceInstance.Gitopsengineinstance_id,
+				TargetGitopsEngineInstanceID: targetInstance.Gitopsengineinstance_id,
+				Step:                         MigrationOperationStep_WaitForHealthy,
+			})).To(Succeed())
+
+			createArgoCDApplication("Degraded")
+
+			Expect(migrator.ResumeNamespaceMigrations(ctx, log)).To(BeTrue())
+
+			By("verifying the steps that completed before the interruption were not run again")
+			var operationList managedgitopsv1alpha1.OperationList
+			Expect(k8sClient.List(ctx, &operationList, client.InNamespace(targetInstance.Namespace_name))).To(Succeed())
+			Expect(operationList.Items).To(BeEmpty())
+
+			migrationOperation := db.MigrationOperation{NamespaceUID: namespaceUID}
+			Expect(dbq.GetMigrationOperationByNamespaceUID(ctx, &migrationOperation)).To(Succeed())
+			Expect(migrationOperation.Step).To(Equal(MigrationOperationStep_WaitForHealthy))
+
+			By("verifying the wait for healthy step completes once the timeout has expired")
+			migrationOperation.Step_started_on = time.Now().Add(-migrationWaitForHealthyTimeout - time.Minute)
+			Expect(migrator.waitForApplicationsHealthyOnTarget(ctx, migrationOperation, targetInstance, log)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,364 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	sharedresourceloop "github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
+)
+
+// Namespace offboarding deletes all the database rows of a namespace (workspace) in bulk: the Applications,
+// SyncOperations, RepositoryCredentials and ManagedEnvironments of the namespace, and the rows that map them to API
+// resources. Operations are created to inform the cluster-agent, so that it deletes the corresponding Argo CD resources.
+//
+// Offboarding is performed as a sequence of steps, and the step that is in progress is persisted in the
+// NamespaceOffboarding table: if the backend is restarted mid-offboarding, the offboarding is resumed from that step
+// on startup. Each step is idempotent: the resource is deleted before the row that maps it to an API resource, so a
+// step that is interrupted can simply be run again.
+
+const (
+	NamespaceOffboardingStep_Applications          = "applications"
+	NamespaceOffboardingStep_SyncOperations        = "syncoperations"
+	NamespaceOffboardingStep_RepositoryCredentials = "repositorycredentials"
+	NamespaceOffboardingStep_ManagedEnvironments   = "managedenvironments"
+)
+
+// namespaceOffboardingSteps is the order in which the offboarding steps are run: Applications must be deleted first, as
+// they reference the ManagedEnvironments, and are referenced by the SyncOperations.
+var namespaceOffboardingSteps = []string{
+	NamespaceOffboardingStep_Applications,
+	NamespaceOffboardingStep_SyncOperations,
+	NamespaceOffboardingStep_RepositoryCredentials,
+	NamespaceOffboardingStep_ManagedEnvironments,
+}
+
+// NamespaceOffboarder deletes the database rows of namespaces that are being offboarded.
+type NamespaceOffboarder struct {
+	client.Client
+	DB               db.DatabaseQueries
+	K8sClientFactory sharedresourceloop.SRLK8sClientFactory
+
+	// mutex ensures that only one namespace is offboarded at a time, so that an offboarding that is being resumed
+	// does not race with the same offboarding being triggered by the namespace controller.
+	mutex sync.Mutex
+}
+
+// StartNamespaceOffboardingResumer resumes, in the background task queue, the offboarding of any namespaces whose
+// offboarding was interrupted before it completed (for example, by a restart of the backend).
+func (o *NamespaceOffboarder) StartNamespaceOffboardingResumer() {
+	getBackgroundTaskQueue().Add(namespaceOffboardingResumerTaskKey, func(ctx context.Context) error {
+		log := log.FromContext(ctx).
+			WithName(logutil.LogLogger_managed_gitops).
+			WithValues("component", "namespace-offboarding")
+
+		o.ResumeNamespaceOffboardings(ctx, log)
+		return nil
+	})
+}
+
+// ResumeNamespaceOffboardings resumes the offboarding of every namespace that has a row in the NamespaceOffboarding table.
+func (o *NamespaceOffboarder) ResumeNamespaceOffboardings(ctx context.Context, log logr.Logger) {
+
+	var namespaceOffboardings []db.NamespaceOffboarding
+	if err := o.DB.ListNamespaceOffboardings(ctx, &namespaceOffboardings); err != nil {
+		log.Error(err, "unable to list namespace offboardings to resume")
+		return
+	}
+
+	for _, namespaceOffboarding := range namespaceOffboardings {
+		log.Info("Resuming namespace offboarding", namespaceOffboarding.GetAsLogKeyValues()...)
+
+		if err := o.OffboardNamespace(ctx, namespaceOffboarding.NamespaceUID, namespaceOffboarding.NamespaceName, log); err != nil {
+			log.Error(err, "unable to resume namespace offboarding", namespaceOffboarding.GetAsLogKeyValues()...)
+		}
+	}
+}
+
+// OffboardNamespace deletes all the database rows of the namespace with the given UID, resuming from the persisted step
+// if the offboarding of the namespace was previously started. On success, the progress row of the namespace is deleted.
+func (o *NamespaceOffboarder) OffboardNamespace(ctx context.Context, namespaceUID string, namespaceName string, l logr.Logger) error {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	log := l.WithValues("namespaceUID", namespaceUID, "namespaceName", namespaceName)
+
+	// 1) Retrieve the progress of the offboarding, or create it if this is a new offboarding
+	namespaceOffboarding := db.NamespaceOffboarding{NamespaceUID: namespaceUID}
+	if err := o.DB.GetNamespaceOffboardingByNamespaceUID(ctx, &namespaceOffboarding); err != nil {
+
+		if !db.IsResultNotFoundError(err) {
+			return fmt.Errorf("unable to retrieve namespace offboarding: %v", err)
+		}
+
+		namespaceOffboarding = db.NamespaceOffboarding{
+			NamespaceUID:  namespaceUID,
+			NamespaceName: namespaceName,
+			Step:          namespaceOffboardingSteps[0],
+		}
+		if err := o.DB.CreateNamespaceOffboarding(ctx, &namespaceOffboarding); err != nil {
+			return fmt.Errorf("unable to create namespace offboarding: %v", err)
+		}
+		log.Info("Started namespace offboarding", namespaceOffboarding.GetAsLogKeyValues()...)
+	}
+
+	// 2) Run each step, starting from the step that was in progress
+	started := false
+	for _, step := range namespaceOffboardingSteps {
+
+		if !started && step != namespaceOffboarding.Step {
+			// The step completed before the offboarding was interrupted
+			continue
+		}
+
+		if started {
+			namespaceOffboarding.Step = step
+			if err := o.DB.UpdateNamespaceOffboarding(ctx, &namespaceOffboarding); err != nil {
+				return fmt.Errorf("unable to update namespace offboarding step to '%s': %v", step, err)
+			}
+		}
+		started = true
+
+		log.Info("Running namespace offboarding step", "step", step)
+
+		if err := o.runOffboardingStep(ctx, step, namespaceOffboarding, log); err != nil {
+			return fmt.Errorf("unable to complete namespace offboarding step '%s': %v", step, err)
+		}
+	}
+
+	if !started {
+		// Sanity check that the persisted step is one that we recognize
+		return fmt.Errorf("SEVERE: unrecognized namespace offboarding step '%s'", namespaceOffboarding.Step)
+	}
+
+	// 3) All the steps have completed, so the offboarding is complete
+	if _, err := o.DB.DeleteNamespaceOffboardingByNamespaceUID(ctx, namespaceUID); err != nil {
+		return fmt.Errorf("unable to delete namespace offboarding: %v", err)
+	}
+
+	log.Info("Namespace offboarding complete")
+
+	return nil
+}
+
+func (o *NamespaceOffboarder) runOffboardingStep(ctx context.Context, step string, namespaceOffboarding db.NamespaceOffboarding, log logr.Logger) error {
+
+	if step == NamespaceOffboardingStep_Applications {
+		// The DTAMs of the namespace are listed in batches, so that the DTAMs of namespaces with thousands of
+		// GitOpsDeployments are not all loaded into memory.
+		var afterSeqID int64
+		for {
+			if afterSeqID != 0 {
+				time.Sleep(sleepIntervalsOfBatches)
+			}
+
+			var deplToAppMappings []db.DeploymentToApplicationMapping
+			if err := o.DB.ListDeploymentToApplicationMappingsForNamespaceUID(ctx, namespaceOffboarding.NamespaceUID, afterSeqID,
+				rowBatchSize, &deplToAppMappings); err != nil {
+				return err
+			}
+
+			if len(deplToAppMappings) == 0 {
+				return nil
+			}
+
+			for i := range deplToAppMappings {
+				if err := offboardApplication(ctx, o.DB, o.Client, deplToAppMappings[i], log); err != nil {
+					return err
+				}
+			}
+
+			afterSeqID = deplToAppMappings[len(deplToAppMappings)-1].SeqID
+		}
+	}
+
+	var relationType db.APICRToDatabaseMapping_DBRelationType
+	switch step {
+	case NamespaceOffboardingStep_SyncOperations:
+		relationType = db.APICRToDatabaseMapping_DBRelationType_SyncOperation
+	case NamespaceOffboardingStep_RepositoryCredentials:
+		relationType = db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential
+	case NamespaceOffboardingStep_ManagedEnvironments:
+		relationType = db.APICRToDatabaseMapping_DBRelationType_ManagedEnvironment
+	default:
+		return fmt.Errorf("SEVERE: unrecognized namespace offboarding step '%s'", step)
+	}
+
+	var apiCRToDBMappings []db.APICRToDatabaseMapping
+	if err := o.DB.ListAPICRToDatabaseMappingByNamespaceUID(ctx, namespaceOffboarding.NamespaceUID, &apiCRToDBMappings); err != nil {
+		return err
+	}
+
+	processed := 0
+	for i := range apiCRToDBMappings {
+		apiCRToDBMapping := apiCRToDBMappings[i]
+
+		if apiCRToDBMapping.DBRelationType != relationType {
+			continue
+		}
+
+		if processed != 0 && processed%rowBatchSize == 0 {
+			time.Sleep(sleepIntervalsOfBatches)
+		}
+		processed++
+
+		var err error
+		switch relationType {
+		case db.APICRToDatabaseMapping_DBRelationType_SyncOperation:
+			err = o.offboardSyncOperation(ctx, apiCRToDBMapping, log)
+		case db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential:
+			err = o.offboardRepositoryCredential(ctx, apiCRToDBMapping, log)
+		case db.APICRToDatabaseMapping_DBRelationType_ManagedEnvironment:
+			err = o.offboardManagedEnvironment(ctx, apiCRToDBMapping, log)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// offboardApplication deletes the Application of a DeploymentToApplicationMapping, along with the database entries that
+// reference it, and then creates an Operation to inform the cluster-agent to delete the Argo CD Application.
+func offboardApplication(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client, deplToAppMapping db.DeploymentToApplicationMapping, l logr.Logger) error {
+
+	log := l.WithValues("applicationID", deplToAppMapping.Application_id)
+
+	dbApplicationFound := true
+	dbApplication := db.Application{Application_id: deplToAppMapping.Application_id}
+	if err := dbQueries.GetApplicationById(ctx, &dbApplication); err != nil {
+		if !db.IsResultNotFoundError(err) {
+			return err
+		}
+		dbApplicationFound = false
+	}
+
+	// 1) Remove the ApplicationState from the database
+	if err := deleteDbEntry(ctx, dbQueries, deplToAppMapping.Application_id, dbType_ApplicationState, log, dbApplication); err != nil {
+		return err
+	}
+
+	// 2) Set the application field of SyncOperations to nil, for all SyncOperations that point to this Application
+	if _, err := dbQueries.UpdateSyncOperationRemoveApplicationField(ctx, deplToAppMapping.Application_id); err != nil {
+		return err
+	}
+
+	// 3) Delete the DTAM row that points to this Application
+	if err := deleteDbEntry(ctx, dbQueries, deplToAppMapping.Deploymenttoapplicationmapping_uid_id, dbType_DeploymentToApplicationMapping, log, deplToAppMapping); err != nil {
+		return err
+	}
+
+	if !dbApplicationFound {
+		return nil
+	}
+
+	// 4) Delete the Application, and create an Operation to inform the cluster-agent to delete the Argo CD Application.
+	// - If the offboarding is interrupted between the deletion of the DTAM and of the Application, the Application
+	//   is instead cleaned up by the database reconciler.
+	if err := deleteDbEntry(ctx, dbQueries, dbApplication.Application_id, dbType_Application, log, dbApplication); err != nil {
+		return err
+	}
+
+	createOperationInEngineInstanceNamespace(ctx, dbQueries, k8sClient, dbApplication.Engine_instance_inst_id, dbApplication.Application_id, db.OperationResourceType_Application, log)
+
+	return nil
+}
+
+// offboardSyncOperation deletes the SyncOperation of a GitOpsDeploymentSyncRun APICRToDatabaseMapping, and then the mapping.
+func (o *NamespaceOffboarder) offboardSyncOperation(ctx context.Context, apiCRToDBMapping db.APICRToDatabaseMapping, log logr.Logger) error {
+
+	syncOperation := db.SyncOperation{SyncOperation_id: apiCRToDBMapping.DBRelationKey}
+	if err := o.DB.GetSyncOperationById(ctx, &syncOperation); err != nil {
+		if !db.IsResultNotFoundError(err) {
+			return err
+		}
+	} else {
+		if err := deleteDbEntry(ctx, o.DB, syncOperation.SyncOperation_id, dbType_SyncOperation, log, syncOperation); err != nil {
+			return err
+		}
+
+		// The Applications of the namespace were deleted by the previous step, which removed them from their SyncOperations,
+		// so an Operation is only needed if the SyncOperation still references an Application.
+		if syncOperation.Application_id != "" {
+			application := db.Application{Application_id: syncOperation.Application_id}
+			if err := o.DB.GetApplicationById(ctx, &application); err != nil {
+				if !db.IsResultNotFoundError(err) {
+					return err
+				}
+			} else {
+				createOperationInEngineInstanceNamespace(ctx, o.DB, o.Client, application.Engine_instance_inst_id, syncOperation.SyncOperation_id, db.OperationResourceType_SyncOperation, log)
+			}
+		}
+	}
+
+	return deleteDbEntry(ctx, o.DB, apiCRToDBMapping.DBRelationKey, dbType_APICRToDatabaseMapping, log, apiCRToDBMapping)
+}
+
+// offboardRepositoryCredential deletes the RepositoryCredentials of a GitOpsDeploymentRepositoryCredential (or
+// GitOpsDeploymentRepositoryCredentialTemplate) APICRToDatabaseMapping, creates an Operation to inform the cluster-agent to delete the Argo CD repository Secret, and
+// then deletes the mapping.
+func (o *NamespaceOffboarder) offboardRepositoryCredential(ctx context.Context, apiCRToDBMapping db.APICRToDatabaseMapping, log logr.Logger) error {
+
+	repoCredential, err := o.DB.GetRepositoryCredentialsByID(ctx, apiCRToDBMapping.DBRelationKey)
+	if err != nil {
+		if !db.IsResultNotFoundError(err) {
+			return err
+		}
+	} else {
+		if err := deleteDbEntry(ctx, o.DB, repoCredential.RepositoryCredentialsID, dbType_RespositoryCredential, log, repoCredential); err != nil {
+			return err
+		}
+
+		createOperationInEngineInstanceNamespace(ctx, o.DB, o.Client, repoCredential.EngineClusterID, repoCredential.RepositoryCredentialsID, db.OperationResourceType_RepositoryCredentials, log)
+	}
+
+	return deleteDbEntry(ctx, o.DB, apiCRToDBMapping.DBRelationKey, dbType_APICRToDatabaseMapping, log, apiCRToDBMapping)
+}
+
+// offboardManagedEnvironment deletes the ManagedEnvironment of a GitOpsDeploymentManagedEnvironment
+// APICRToDatabaseMapping (along with the resources that reference it), and then deletes the mapping.
+func (o *NamespaceOffboarder) offboardManagedEnvironment(ctx context.Context, apiCRToDBMapping db.APICRToDatabaseMapping, log logr.Logger) error {
+
+	managedEnv := db.ManagedEnvironment{Managedenvironment_id: apiCRToDBMapping.DBRelationKey}
+	if err := o.DB.GetManagedEnvironmentById(ctx, &managedEnv); err != nil {
+		if !db.IsResultNotFoundError(err) {
+			return err
+		}
+	} else {
+		var specialClusterUser db.ClusterUser
+		if err := o.DB.GetOrCreateSpecialClusterUser(ctx, &specialClusterUser); err != nil {
+			return err
+		}
+
+		if err := sharedresourceloop.DeleteManagedEnvironmentResources(ctx, managedEnv.Managedenvironment_id, &managedEnv,
+			specialClusterUser, o.K8sClientFactory, o.DB, log); err != nil {
+			return err
+		}
+	}
+
+	return deleteDbEntry(ctx, o.DB, apiCRToDBMapping.DBRelationKey, dbType_APICRToDatabaseMapping, log, apiCRToDBMapping)
+}
+
+// createOperationInEngineInstanceNamespace creates an Operation for the given resource, in the namespace of the
+// GitOpsEngineInstance. Errors are logged, but otherwise ignored: the database reconciler will clean up any Argo CD
+// resources that are left behind.
+func createOperationInEngineInstanceNamespace(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client,
+	gitopsEngineInstanceID string, resourceID string, resourceType db.OperationResourceType, log logr.Logger) {
+
+	gitopsEngineInstance := db.GitopsEngineInstance{Gitopsengineinstance_id: gitopsEngineInstanceID}
+	if err := dbQueries.GetGitopsEngineInstanceById(ctx, &gitopsEngineInstance); err != nil {
+		log.Error(err, "unable to retrieve GitOpsEngineInstance of offboarded resource", "gitopsEngineInstanceID", gitopsEngineInstanceID)
+		return
+	}
+
+	createOperation(ctx, gitopsEngineInstanceID, resourceID, gitopsEngineInstance.Namespace_name, resourceType, dbQueries, k8sClient, log)
+}
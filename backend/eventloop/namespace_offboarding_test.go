@@ -0,0 +1,202 @@
+package eventloop
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Namespace Offboarding Tests", func() {
+
+	Context("Testing OffboardNamespace function.", func() {
+
+		var log logr.Logger
+		var ctx context.Context
+		var dbq db.AllDatabaseQueries
+		var offboarder *NamespaceOffboarder
+		var namespaceUID string
+		var application db.Application
+		var deploymentToApplicationMapping db.DeploymentToApplicationMapping
+		var syncOperation db.SyncOperation
+		var repoCredential db.RepositoryCredentials
+
+		BeforeEach(func() {
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				apiNamespace,
+				err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace).
+				Build()
+
+			err = db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+			log = logger.FromContext(ctx)
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			offboarder = &NamespaceOffboarder{
+				Client: k8sClient,
+				DB:     dbq,
+			}
+
+			_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			clusterUser := db.ClusterUser{
+				Clusteruser_id: "test-offboarding-user-id",
+				User_name:      "test-offboarding-user",
+			}
+			Expect(dbq.CreateClusterUser(ctx, &clusterUser)).To(Succeed())
+
+			namespaceUID = "test-" + string(uuid.NewUUID())
+
+			By("creating an Application, with an ApplicationState and DeploymentToApplicationMapping, in the namespace")
+			application = db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "my-application",
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(dbq.CreateApplication(ctx, &application)).To(Succeed())
+
+			Expect(dbq.CreateApplicationState(ctx, &db.ApplicationState{
+				Applicationstate_application_id: application.Application_id,
+				Health:                          "Healthy",
+				Sync_Status:                     "Synced",
+				ReconciledState:                 "Healthy",
+			})).To(Succeed())
+
+			deploymentToApplicationMapping = db.DeploymentToApplicationMapping{
+				Deploymenttoapplicationmapping_uid_id: "test-" + string(uuid.NewUUID()),
+				Application_id:                        application.Application_id,
+				DeploymentName:                        "test-deployment",
+				DeploymentNamespace:                   "test-namespace",
+				NamespaceUID:                          namespaceUID,
+			}
+			Expect(dbq.CreateDeploymentToApplicationMapping(ctx, &deploymentToApplicationMapping)).To(Succeed())
+
+			By("creating a SyncOperation, and the APICRToDatabaseMapping of its GitOpsDeploymentSyncRun, in the namespace")
+			syncOperation = db.SyncOperation{
+				SyncOperation_id:    "test-syncOperation",
+				Application_id:      application.Application_id,
+				Revision:            "master",
+				DeploymentNameField: deploymentToApplicationMapping.DeploymentName,
+				DesiredState:        "Synced",
+			}
+			Expect(dbq.CreateSyncOperation(ctx, &syncOperation)).To(Succeed())
+
+			syncRunMapping := db.APICRToDatabaseMapping{
+				APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun,
+				APIResourceUID:       "test-" + string(uuid.NewUUID()),
+				APIResourceName:      "test-syncrun",
+				APIResourceNamespace: "test-namespace",
+				NamespaceUID:         namespaceUID,
+				DBRelationType:       db.APICRToDatabaseMapping_DBRelationType_SyncOperation,
+				DBRelationKey:        syncOperation.SyncOperation_id,
+			}
+			Expect(dbq.CreateAPICRToDatabaseMapping(ctx, &syncRunMapping)).To(Succeed())
+
+			By("creating a RepositoryCredentials, and the APICRToDatabaseMapping of its GitOpsDeploymentRepositoryCredential, in the namespace")
+			repoCredential = db.RepositoryCredentials{
+				RepositoryCredentialsID: "test-repo-" + string(uuid.NewUUID()),
+				UserID:                  clusterUser.Clusteruser_id,
+				PrivateURL:              "https://test-private-url",
+				AuthUsername:            "test-auth-username",
+				AuthPassword:            "test-auth-password",
+				AuthSSHKey:              "test-auth-ssh-key",
+				SecretObj:               "test-secret-obj",
+				EngineClusterID:         gitopsEngineInstance.Gitopsengineinstance_id,
+			}
+			Expect(dbq.CreateRepositoryCredentials(ctx, &repoCredential)).To(Succeed())
+
+			repoCredentialMapping := db.APICRToDatabaseMapping{
+				APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential,
+				APIResourceUID:       "test-" + string(uuid.NewUUID()),
+				APIResourceName:      "test-repocred",
+				APIResourceNamespace: "test-namespace",
+				NamespaceUID:         namespaceUID,
+				DBRelationType:       db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential,
+				DBRelationKey:        repoCredential.RepositoryCredentialsID,
+			}
+			Expect(dbq.CreateAPICRToDatabaseMapping(ctx, &repoCredentialMapping)).To(Succeed())
+		})
+
+		It("should delete all the database rows of the namespace, and then the progress of the offboarding", func() {
+			defer dbq.CloseDatabase()
+
+			Expect(offboarder.OffboardNamespace(ctx, namespaceUID, "test-namespace", log)).To(Succeed())
+
+			By("verifying the Application, and the rows that reference it, were deleted")
+			err := dbq.GetApplicationById(ctx, &db.Application{Application_id: application.Application_id})
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			err = dbq.GetApplicationStateById(ctx, &db.ApplicationState{Applicationstate_application_id: application.Application_id})
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			err = dbq.GetDeploymentToApplicationMappingByDeplId(ctx, &db.DeploymentToApplicationMapping{
+				Deploymenttoapplicationmapping_uid_id: deploymentToApplicationMapping.Deploymenttoapplicationmapping_uid_id})
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			By("verifying the SyncOperation and RepositoryCredentials, and their mappings, were deleted")
+			err = dbq.GetSyncOperationById(ctx, &db.SyncOperation{SyncOperation_id: syncOperation.SyncOperation_id})
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			_, err = dbq.GetRepositoryCredentialsByID(ctx, repoCredential.RepositoryCredentialsID)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			var mappings []db.APICRToDatabaseMapping
+			Expect(dbq.ListAPICRToDatabaseMappingByNamespaceUID(ctx, namespaceUID, &mappings)).To(Succeed())
+			Expect(mappings).To(BeEmpty())
+
+			By("verifying the progress of the offboarding was deleted")
+			err = dbq.GetNamespaceOffboardingByNamespaceUID(ctx, &db.NamespaceOffboarding{NamespaceUID: namespaceUID})
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+		})
+
+		It("should resume an interrupted offboarding from the step that was in progress", func() {
+			defer dbq.CloseDatabase()
+
+			By("persisting an offboarding that was interrupted during the RepositoryCredentials step")
+			Expect(dbq.CreateNamespaceOffboarding(ctx, &db.NamespaceOffboarding{
+				NamespaceUID:  namespaceUID,
+				NamespaceName: "test-namespace",
+				Step:          NamespaceOffboardingStep_RepositoryCredentials,
+			})).To(Succeed())
+
+			offboarder.ResumeNamespaceOffboardings(ctx, log)
+
+			By("verifying the steps that completed before the interruption were not run again")
+			Expect(dbq.GetApplicationById(ctx, &db.Application{Application_id: application.Application_id})).To(Succeed())
+			Expect(dbq.GetSyncOperationById(ctx, &db.SyncOperation{SyncOperation_id: syncOperation.SyncOperation_id})).To(Succeed())
+
+			By("verifying the RepositoryCredentials and its mapping were deleted")
+			_, err := dbq.GetRepositoryCredentialsByID(ctx, repoCredential.RepositoryCredentialsID)
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+
+			var mappings []db.APICRToDatabaseMapping
+			Expect(dbq.ListAPICRToDatabaseMappingByNamespaceUID(ctx, namespaceUID, &mappings)).To(Succeed())
+			Expect(mappings).To(HaveLen(1))
+			Expect(mappings[0].DBRelationType).To(Equal(db.APICRToDatabaseMapping_DBRelationType_SyncOperation))
+
+			By("verifying the progress of the offboarding was deleted")
+			err = dbq.GetNamespaceOffboardingByNamespaceUID(ctx, &db.NamespaceOffboarding{NamespaceUID: namespaceUID})
+			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,166 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
+)
+
+// The operation backlog monitor periodically counts the Operations that are in the Waiting state, for each
+// GitopsEngineInstance. Operations are processed by the cluster-agent that manages the Argo CD instance, so if the
+// Waiting backlog of an instance stays above a threshold for longer than the alert duration, the cluster-agent is
+// likely stuck (or not running). When this happens, an alert is raised on the 'operation_waiting_backlog_alert'
+// metric, and an error is logged. The alert is cleared once the backlog falls back to (or below) the threshold.
+
+const (
+	operationBacklogMonitorInterval = 1 * time.Minute // Interval between runs of the operation backlog monitor.
+
+	// OperationBacklogAlertThresholdEnvVar may be set to the number of Waiting Operations of a GitopsEngineInstance,
+	// above which the backlog is considered to be growing.
+	OperationBacklogAlertThresholdEnvVar = "OPERATION_BACKLOG_ALERT_THRESHOLD"
+
+	// OperationBacklogAlertMinutesEnvVar may be set to the number of minutes that the backlog of a GitopsEngineInstance
+	// must be above the threshold, before an alert is raised.
+	OperationBacklogAlertMinutesEnvVar = "OPERATION_BACKLOG_ALERT_MINUTES"
+
+	defaultOperationBacklogAlertThreshold = 100
+	defaultOperationBacklogAlertMinutes   = 10
+)
+
+// OperationBacklogMonitor raises an alert when the Waiting backlog of a GitopsEngineInstance exceeds a threshold for
+// longer than the alert duration.
+type OperationBacklogMonitor struct {
+	DB    db.DatabaseQueries
+	Clock sharedutil.Clock
+
+	// threshold is the number of Waiting Operations of a GitopsEngineInstance, above which the backlog is growing
+	threshold int
+
+	// alertDuration is how long the backlog must be above the threshold, before an alert is raised
+	alertDuration time.Duration
+
+	// backlogs contains the state of each GitopsEngineInstance that has Waiting Operations, by instance ID.
+	// It is only accessed by the monitor task, which never runs concurrently with itself.
+	backlogs map[string]*operationBacklog
+}
+
+// operationBacklog is the state of the Waiting backlog of a single GitopsEngineInstance
+type operationBacklog struct {
+	// overThresholdSince is when the backlog was first seen above the threshold, or zero if it is not above the threshold
+	overThresholdSince time.Time
+
+	// alertRaised is true if the backlog has been above the threshold for longer than the alert duration
+	alertRaised bool
+}
+
+// NewOperationBacklogMonitor returns a monitor configured from the OperationBacklogAlert* env vars.
+func NewOperationBacklogMonitor(dbQueries db.DatabaseQueries, l logr.Logger) *OperationBacklogMonitor {
+	return &OperationBacklogMonitor{
+		DB:            dbQueries,
+		Clock:         sharedutil.NewClock(),
+		threshold:     getPositiveIntEnvVar(OperationBacklogAlertThresholdEnvVar, defaultOperationBacklogAlertThreshold, l),
+		alertDuration: time.Duration(getPositiveIntEnvVar(OperationBacklogAlertMinutesEnvVar, defaultOperationBacklogAlertMinutes, l)) * time.Minute,
+		backlogs:      map[string]*operationBacklog{},
+	}
+}
+
+// getPositiveIntEnvVar returns the value of the given env var, or the default value if it is not set or not a positive int.
+func getPositiveIntEnvVar(envVar string, defaultValue int, l logr.Logger) int {
+	strValue := os.Getenv(envVar)
+	if strValue == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(strValue)
+	if err != nil || value <= 0 {
+		l.Error(err, fmt.Sprintf("value of env var %s must be a positive int, using the default value", envVar), "default", defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+func (m *OperationBacklogMonitor) StartOperationBacklogMonitor() {
+	getBackgroundTaskQueue().AddAfter(operationBacklogMonitorTaskKey, operationBacklogMonitorInterval, func(ctx context.Context) error {
+
+		// Kick off the timer again, once the old task runs (even if it panics).
+		// This ensures that at least 'operationBacklogMonitorInterval' time elapses from the end of one run to the beginning of another.
+		defer m.StartOperationBacklogMonitor()
+
+		log := log.FromContext(ctx).
+			WithName(logutil.LogLogger_managed_gitops).
+			WithValues("component", "operation-backlog-monitor")
+
+		m.checkOperationBacklogs(ctx, log)
+
+		return nil
+	})
+}
+
+// checkOperationBacklogs updates the backlog metrics of each GitopsEngineInstance, and raises (or clears) its alert.
+func (m *OperationBacklogMonitor) checkOperationBacklogs(ctx context.Context, log logr.Logger) {
+
+	counts, err := m.DB.CountOperationsByStateForInstance(ctx, db.OperationState_Waiting)
+	if err != nil {
+		log.Error(err, "unable to count the waiting operations of each GitopsEngineInstance")
+		return
+	}
+
+	now := m.Clock.Now()
+
+	instancesWithBacklog := map[string]bool{}
+
+	for _, count := range counts {
+
+		instancesWithBacklog[count.InstanceID] = true
+
+		backlog, exists := m.backlogs[count.InstanceID]
+		if !exists {
+			backlog = &operationBacklog{}
+			m.backlogs[count.InstanceID] = backlog
+		}
+
+		instanceLog := log.WithValues("gitopsEngineInstanceID", count.InstanceID, "waitingOperations", count.RowCount)
+
+		if count.RowCount <= m.threshold {
+			if backlog.alertRaised {
+				instanceLog.Info("Waiting operation backlog of GitopsEngineInstance is no longer above the alert threshold", "threshold", m.threshold)
+			}
+			backlog.overThresholdSince = time.Time{}
+			backlog.alertRaised = false
+
+		} else {
+			if backlog.overThresholdSince.IsZero() {
+				backlog.overThresholdSince = now
+			}
+
+			if !backlog.alertRaised && now.Sub(backlog.overThresholdSince) >= m.alertDuration {
+				backlog.alertRaised = true
+				instanceLog.Error(nil, "Waiting operation backlog of GitopsEngineInstance has been above the alert threshold for longer than the alert duration: the cluster-agent may be stuck",
+					"threshold", m.threshold, "overThresholdSince", backlog.overThresholdSince, "alertDuration", m.alertDuration)
+			}
+		}
+
+		metrics.SetOperationWaitingBacklog(count.InstanceID, count.RowCount, backlog.alertRaised)
+	}
+
+	// Instances that no longer have any waiting operations have no backlog
+	for instanceID, backlog := range m.backlogs {
+		if instancesWithBacklog[instanceID] {
+			continue
+		}
+		if backlog.alertRaised {
+			log.Info("Waiting operation backlog of GitopsEngineInstance has drained", "gitopsEngineInstanceID", instanceID)
+		}
+		delete(m.backlogs, instanceID)
+		metrics.DeleteOperationWaitingBacklog(instanceID)
+	}
+}
@@ -0,0 +1,152 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
+)
+
+var _ = Describe("Operation backlog monitor Test", func() {
+	Context("Testing checkOperationBacklogs", func() {
+
+		var log logr.Logger
+		var ctx context.Context
+		var dbq db.AllDatabaseQueries
+		var gitopsEngineInstance *db.GitopsEngineInstance
+		var clusterAccess *db.ClusterAccess
+		var monitor *OperationBacklogMonitor
+		var start time.Time
+
+		BeforeEach(func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+			log = logger.FromContext(ctx)
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			_, _, _, gitopsEngineInstance, clusterAccess, err = db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			metrics.ClearDBMetrics()
+
+			start = time.Now()
+			monitor = &OperationBacklogMonitor{
+				DB:            dbq,
+				Clock:         sharedutil.NewMockClock(start),
+				threshold:     2,
+				alertDuration: 10 * time.Minute,
+				backlogs:      map[string]*operationBacklog{},
+			}
+		})
+
+		AfterEach(func() {
+			dbq.CloseDatabase()
+		})
+
+		createWaitingOperations := func(count int) []db.Operation {
+			var res []db.Operation
+			for i := 0; i < count; i++ {
+				operation := db.Operation{
+					Operation_id:            fmt.Sprintf("test-operation-%d", i),
+					Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+					Resource_id:             "test-fake-resource-id",
+					Resource_type:           "GitopsEngineInstance",
+					State:                   db.OperationState_Waiting,
+					Operation_owner_user_id: clusterAccess.Clusteraccess_user_id,
+				}
+				err := dbq.CreateOperation(ctx, &operation, operation.Operation_owner_user_id)
+				Expect(err).To(BeNil())
+				res = append(res, operation)
+			}
+			return res
+		}
+
+		backlogMetric := func() float64 {
+			return testutil.ToFloat64(metrics.OperationWaitingBacklog.WithLabelValues(gitopsEngineInstance.Gitopsengineinstance_id))
+		}
+
+		alertMetric := func() float64 {
+			return testutil.ToFloat64(metrics.OperationWaitingBacklogAlert.WithLabelValues(gitopsEngineInstance.Gitopsengineinstance_id))
+		}
+
+		It("should raise an alert only once the backlog has been above the threshold for longer than the alert duration, and clear it once the backlog drains", func() {
+
+			operations := createWaitingOperations(3)
+
+			By("verifying no alert is raised when the backlog first exceeds the threshold")
+			monitor.checkOperationBacklogs(ctx, log)
+			Expect(backlogMetric()).To(Equal(3.0))
+			Expect(alertMetric()).To(Equal(0.0))
+
+			monitor.Clock = sharedutil.NewMockClock(start.Add(5 * time.Minute))
+			monitor.checkOperationBacklogs(ctx, log)
+			Expect(alertMetric()).To(Equal(0.0))
+
+			By("verifying the alert is raised once the alert duration has elapsed")
+			monitor.Clock = sharedutil.NewMockClock(start.Add(10 * time.Minute))
+			monitor.checkOperationBacklogs(ctx, log)
+			Expect(alertMetric()).To(Equal(1.0))
+
+			By("verifying the alert is cleared once the backlog is no longer above the threshold")
+			operations[0].State = db.OperationState_Completed
+			err := dbq.UpdateOperation(ctx, &operations[0])
+			Expect(err).To(BeNil())
+
+			monitor.checkOperationBacklogs(ctx, log)
+			Expect(backlogMetric()).To(Equal(2.0))
+			Expect(alertMetric()).To(Equal(0.0))
+			Expect(monitor.backlogs[gitopsEngineInstance.Gitopsengineinstance_id].overThresholdSince.IsZero()).To(BeTrue())
+
+			By("verifying the backlog is no longer tracked once there are no waiting operations")
+			for i := 1; i < len(operations); i++ {
+				operations[i].State = db.OperationState_Completed
+				err := dbq.UpdateOperation(ctx, &operations[i])
+				Expect(err).To(BeNil())
+			}
+
+			monitor.checkOperationBacklogs(ctx, log)
+			Expect(monitor.backlogs).To(BeEmpty())
+		})
+
+		It("should restart the alert duration if the backlog falls below the threshold before the alert is raised", func() {
+
+			operations := createWaitingOperations(3)
+
+			monitor.checkOperationBacklogs(ctx, log)
+
+			operations[0].State = db.OperationState_Completed
+			err := dbq.UpdateOperation(ctx, &operations[0])
+			Expect(err).To(BeNil())
+
+			monitor.Clock = sharedutil.NewMockClock(start.Add(5 * time.Minute))
+			monitor.checkOperationBacklogs(ctx, log)
+
+			operations[0].State = db.OperationState_Waiting
+			err = dbq.UpdateOperation(ctx, &operations[0])
+			Expect(err).To(BeNil())
+
+			monitor.Clock = sharedutil.NewMockClock(start.Add(6 * time.Minute))
+			monitor.checkOperationBacklogs(ctx, log)
+
+			monitor.Clock = sharedutil.NewMockClock(start.Add(12 * time.Minute))
+			monitor.checkOperationBacklogs(ctx, log)
+			Expect(alertMetric()).To(Equal(0.0))
+
+			monitor.Clock = sharedutil.NewMockClock(start.Add(16 * time.Minute))
+			monitor.checkOperationBacklogs(ctx, log)
+			Expect(alertMetric()).To(Equal(1.0))
+		})
+	})
+})
@@ -2,11 +2,13 @@ package preprocess_event_loop
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/go-logr/logr"
 
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop"
+	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/application_event_loop"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,6 +25,11 @@ import (
 func (evl *PreprocessEventLoop) EventReceived(req ctrl.Request, reqResource eventlooptypes.GitOpsResourceType,
 	client client.Client, eventType eventlooptypes.EventLoopEventType, namespaceID string) {
 
+	// Once draining, new events are dropped: the controllers of the next backend replica reconcile every resource on startup.
+	if atomic.LoadInt32(&evl.draining) == 1 {
+		return
+	}
+
 	event := eventlooptypes.EventLoopEvent{Request: req, EventType: eventType, WorkspaceID: namespaceID,
 		Client: client, ReqResource: reqResource}
 
@@ -32,14 +39,29 @@ func (evl *PreprocessEventLoop) EventReceived(req ctrl.Request, reqResource even
 type PreprocessEventLoop struct {
 	eventLoopInputChannel chan eventlooptypes.EventLoopEvent
 	nextStep              *eventloop.ControllerEventLoop
+
+	// draining is set to 1 (via atomic) once Drain is called
+	draining int32
+}
+
+// Drain is called on shutdown of the backend: new events are no longer accepted, and the events that are being
+// processed by application event runners are given until the context is cancelled to complete.
+func (evl *PreprocessEventLoop) Drain(ctx context.Context) error {
+
+	atomic.StoreInt32(&evl.draining, 1)
+
+	return application_event_loop.DrainApplicationEventRunners(ctx)
 }
 
-func NewPreprocessEventLoop() *PreprocessEventLoop {
+// NewPreprocessEventLoop starts the preprocess event loop, and the controller event loop after it. If directMode is true,
+// the Argo CD Applications of GitOpsDeployments are applied by the backend, rather than by the cluster-agent (see the
+// description of direct mode in the operations package).
+func NewPreprocessEventLoop(directMode bool) *PreprocessEventLoop {
 	channel := make(chan eventlooptypes.EventLoopEvent)
 
 	res := &PreprocessEventLoop{}
 	res.eventLoopInputChannel = channel
-	res.nextStep = eventloop.NewControllerEventLoop()
+	res.nextStep = eventloop.NewControllerEventLoop(directMode)
 
 	go preprocessEventLoopRouter(channel, res.nextStep)
 
@@ -14,7 +14,6 @@ import (
 	"github.com/go-logr/logr"
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
-	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	sharedresourceloop "github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
 )
@@ -37,29 +36,21 @@ func (r *RepoCredReconciler) StartRepoCredReconciler() {
 }
 
 func (r *RepoCredReconciler) startTimerForNextCycle() {
-	go func() {
-		// Timer to trigger Reconciler
-		timer := time.NewTimer(time.Duration(repocredReconcilerInterval))
-		<-timer.C
+	getBackgroundTaskQueue().AddAfter(repoCredReconcilerTaskKey, repocredReconcilerInterval, func(ctx context.Context) error {
+
+		// Kick off the timer again, once the old task runs (even if it panics).
+		// This ensures that at least 'repocredReconcilerInterval' time elapses from the end of one run to the beginning of another.
+		defer r.startTimerForNextCycle()
 
-		ctx := context.Background()
 		log := log.FromContext(ctx).
 			WithName(logutil.LogLogger_managed_gitops).
 			WithValues("component", "repocred-reconciler")
 
-		_, _ = sharedutil.CatchPanic(func() error {
-
-			// Reconcile RepositoryCredentials here
-			reconcileRepositoryCredentials(ctx, r.DB, r.Client, log)
-
-			return nil
-		})
-
-		// Kick off the timer again, once the old task runs.
-		// This ensures that at least 'repocredReconcilerInterval' time elapses from the end of one run to the beginning of another.
-		r.startTimerForNextCycle()
-	}()
+		// Reconcile RepositoryCredentials here
+		reconcileRepositoryCredentials(ctx, r.DB, r.Client, log)
 
+		return nil
+	})
 }
 
 // /////////////
@@ -3,6 +3,7 @@ package shared_resource_loop
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	corev1 "k8s.io/api/core/v1"
@@ -33,6 +35,7 @@ import (
 // - clusteruser
 // - gitopsengineinstance
 // - repositorycredential
+// - repositorycredentialtemplate
 //
 // Ultimately the goal of this file is to avoid this issue:
 // - In the same moment of time, both these actions happen simultaneously:
@@ -213,6 +216,50 @@ func (srEventLoop *SharedResourceEventLoop) ReconcileRepositoryCredential(ctx co
 
 }
 
+func (srEventLoop *SharedResourceEventLoop) ReconcileRepositoryCredentialTemplate(ctx context.Context,
+	workspaceClient client.Client, workspaceNamespace corev1.Namespace,
+	repositoryCredentialTemplateCRName string, k8sClientFactory SRLK8sClientFactory) (*db.RepositoryCredentials, error) {
+
+	request := sharedResourceLoopMessage_reconcileRepositoryCredentialRequest{
+		repositoryCredentialCRName:      repositoryCredentialTemplateCRName,
+		repositoryCredentialCRNamespace: workspaceNamespace.Name,
+		k8sClientFactory:                k8sClientFactory,
+	}
+
+	responseChannel := make(chan any)
+
+	// Create a logger with context
+	l := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops)
+
+	msg := sharedResourceLoopMessage{
+		log:                l,
+		ctx:                ctx,
+		workspaceClient:    workspaceClient,
+		workspaceNamespace: workspaceNamespace,
+		messageType:        sharedResourceLoopMessage_reconcileRepositoryCredentialTemplate,
+		responseChannel:    responseChannel,
+		payload:            request,
+	}
+
+	srEventLoop.inputChannel <- msg
+
+	var rawResponse any
+
+	select {
+	case rawResponse = <-responseChannel:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("context cancelled in ReconcileRepositoryCredentialTemplate")
+	}
+
+	response, ok := rawResponse.(sharedResourceLoopMessage_reconcileRepositoryCredentialResponse)
+	if !ok {
+		return nil, fmt.Errorf("SEVERE: unexpected response type")
+	}
+	return response.repositoryCredential, response.err
+
+}
+
 func NewSharedResourceLoop() *SharedResourceEventLoop {
 
 	sharedResourceEventLoop := &SharedResourceEventLoop{
@@ -227,10 +274,12 @@ func NewSharedResourceLoop() *SharedResourceEventLoop {
 type sharedResourceLoopMessageType string
 
 const (
-	sharedResourceLoopMessage_getOrCreateSharedManagedEnv          sharedResourceLoopMessageType = "getOrCreateSharedManagedEnv"
-	sharedResourceLoopMessage_getOrCreateClusterUserByNamespaceUID sharedResourceLoopMessageType = "getOrCreateClusterUserByNamespaceUID"
-	sharedResourceLoopMessage_getGitopsEngineInstanceById          sharedResourceLoopMessageType = "getGitopsEngineInstanceById"
-	sharedResourceLoopMessage_reconcileRepositoryCredential        sharedResourceLoopMessageType = "reconcileRepositoryCredential"
+	sharedResourceLoopMessage_getOrCreateSharedManagedEnv           sharedResourceLoopMessageType = "getOrCreateSharedManagedEnv"
+	sharedResourceLoopMessage_getOrCreateClusterUserByNamespaceUID  sharedResourceLoopMessageType = "getOrCreateClusterUserByNamespaceUID"
+	sharedResourceLoopMessage_getGitopsEngineInstanceById           sharedResourceLoopMessageType = "getGitopsEngineInstanceById"
+	sharedResourceLoopMessage_reconcileRepositoryCredential         sharedResourceLoopMessageType = "reconcileRepositoryCredential"
+	sharedResourceLoopMessage_reconcileRepositoryCredentialTemplate sharedResourceLoopMessageType = "reconcileRepositoryCredentialTemplate"
+	sharedResourceLoopMessage_assignGitopsEngineInstance            sharedResourceLoopMessageType = "assignGitopsEngineInstance"
 )
 
 type sharedResourceLoopMessage struct {
@@ -434,6 +483,56 @@ func processSharedResourceMessage(ctx context.Context, msg sharedResourceLoopMes
 			msg.responseChannel <- response
 		}()
 
+	} else if msg.messageType == sharedResourceLoopMessage_reconcileRepositoryCredentialTemplate {
+
+		var err error
+		var repositoryCredential *db.RepositoryCredentials
+
+		payload, ok := (msg.payload).(sharedResourceLoopMessage_reconcileRepositoryCredentialRequest)
+		if ok {
+
+			repositoryCredential, err = internalProcessMessage_ReconcileRepositoryCredentialTemplate(ctx,
+				payload.repositoryCredentialCRName, msg.workspaceNamespace, msg.workspaceClient, dbQueries, true, l)
+
+		} else {
+			err = fmt.Errorf("SEVERE - unexpected cast in internalSharedResourceEventLoop")
+			l.Error(err, err.Error())
+		}
+
+		response := sharedResourceLoopMessage_reconcileRepositoryCredentialResponse{
+			repositoryCredential: repositoryCredential,
+			err:                  err,
+		}
+
+		// Reply on a separate goroutine so cancelled callers don't block the event loop
+		go func() {
+			msg.responseChannel <- response
+		}()
+
+	} else if msg.messageType == sharedResourceLoopMessage_assignGitopsEngineInstance {
+
+		var uerr gitopserrors.UserError
+		var gitopsEngineInstance *db.GitopsEngineInstance
+
+		payload, ok := (msg.payload).(sharedResourceLoopMessage_assignGitopsEngineInstanceRequest)
+		if ok {
+			gitopsEngineInstance, uerr = internalProcessMessage_AssignGitopsEngineInstance(ctx, payload, dbQueries, l)
+		} else {
+			err := fmt.Errorf("SEVERE - unexpected cast in internalSharedResourceEventLoop")
+			l.Error(err, err.Error())
+			uerr = gitopserrors.NewDevOnlyError(err)
+		}
+
+		response := sharedResourceLoopMessage_assignGitopsEngineInstanceResponse{
+			gitopsEngineInstance: gitopsEngineInstance,
+			err:                  uerr,
+		}
+
+		// Reply on a separate goroutine so cancelled callers don't block the event loop
+		go func() {
+			msg.responseChannel <- response
+		}()
+
 	} else {
 		l.Error(nil, "SEVERE: unrecognized sharedResourceLoopMessageType: "+string(msg.messageType))
 	}
@@ -505,8 +604,25 @@ func compareAndModifyClusterResourceWithDatabaseRow(cr managedgitopsv1alpha1.Git
 		isAuthSSHKeyUpdateNeeded = true
 	}
 
+	var isProjectUpdateNeeded bool
+	if project := generateRepositoryCredentialProject(cr); project != dbr.Project {
+		l.Info("Scope changed", "old project", dbr.Project, "new project", project)
+		dbr.Project = project
+		isProjectUpdateNeeded = true
+	}
+
 	return isSecretUpdateNeeded || isRepoUpdateNeeded || isAuthUsernameUpdateNeeded ||
-		isAuthPasswordUpdateNeeded || isAuthSSHKeyUpdateNeeded
+		isAuthPasswordUpdateNeeded || isAuthSSHKeyUpdateNeeded || isProjectUpdateNeeded
+}
+
+// generateRepositoryCredentialProject returns the name of the Argo CD AppProject that the repository credentials of the
+// GitOpsDeploymentRepositoryCredential are restricted to, or "" if the credential is not scoped (and thus may be used
+// by any Argo CD Application of the Argo CD instance).
+func generateRepositoryCredentialProject(cr managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential) string {
+	if cr.Spec.Scope == nil {
+		return ""
+	}
+	return argosharedutil.GenerateArgoCDRepositoryCredentialProjectName(string(cr.UID))
 }
 
 func internalProcessMessage_GetGitopsEngineInstanceById(ctx context.Context, id string, dbq db.DatabaseQueries) (*db.GitopsEngineInstance, error) {
@@ -542,6 +658,10 @@ func internalProcessMessage_GetOrCreateClusterUserByNamespaceUID(ctx context.Con
 		}
 	}
 
+	if err := checkClusterUserIsActive(clusterUser); err != nil {
+		return nil, false, err
+	}
+
 	return &clusterUser, isNewUser, nil
 }
 
@@ -694,5 +814,18 @@ func internalGetOrCreateClusterUserByNamespaceUID(ctx context.Context, namespace
 		}
 	}
 
+	if err := checkClusterUserIsActive(clusterUser); err != nil {
+		return nil, false, err
+	}
+
 	return &clusterUser, isNewUser, nil
 }
+
+// checkClusterUserIsActive returns an error if the ClusterUser has been deactivated (offboarded): resources are not
+// created on behalf of a deactivated user, as its data is being deleted.
+func checkClusterUserIsActive(clusterUser db.ClusterUser) error {
+	if clusterUser.Deactivated_on != nil {
+		return fmt.Errorf("ClusterUser '%s' was deactivated on %s", clusterUser.User_name, clusterUser.Deactivated_on.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
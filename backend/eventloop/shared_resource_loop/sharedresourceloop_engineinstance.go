@@ -0,0 +1,163 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type sharedResourceLoopMessage_assignGitopsEngineInstanceRequest struct {
+	preferredInstance    db.GitopsEngineInstance
+	clusterUser          db.ClusterUser
+	managedEnvironmentID string
+}
+
+type sharedResourceLoopMessage_assignGitopsEngineInstanceResponse struct {
+	err                  gitopserrors.UserError
+	gitopsEngineInstance *db.GitopsEngineInstance
+}
+
+// AssignGitopsEngineInstanceForNewApplication returns the Argo CD instance that a new Application should be assigned to:
+// this is the preferred instance (the instance of the ManagedEnvironment of the Application), unless that instance has
+// reached its capacity (see GitopsEngineInstance.Max_applications). In that case, the instance with the fewest
+// Applications, of the other instances on the same cluster that have capacity, is returned.
+//
+// A ConditionError with the InstanceCapacityExceeded reason is returned if no instance has capacity.
+func (srEventLoop *SharedResourceEventLoop) AssignGitopsEngineInstanceForNewApplication(ctx context.Context,
+	preferredInstance db.GitopsEngineInstance, clusterUser db.ClusterUser, managedEnvironmentID string,
+	workspaceClient client.Client, workspaceNamespace corev1.Namespace, l logr.Logger) (*db.GitopsEngineInstance, gitopserrors.UserError) {
+
+	responseChannel := make(chan any)
+
+	msg := sharedResourceLoopMessage{
+		log:                l,
+		workspaceClient:    workspaceClient,
+		workspaceNamespace: workspaceNamespace,
+		messageType:        sharedResourceLoopMessage_assignGitopsEngineInstance,
+		responseChannel:    responseChannel,
+		payload: sharedResourceLoopMessage_assignGitopsEngineInstanceRequest{
+			preferredInstance:    preferredInstance,
+			clusterUser:          clusterUser,
+			managedEnvironmentID: managedEnvironmentID,
+		},
+		ctx: ctx,
+	}
+
+	srEventLoop.inputChannel <- msg
+
+	var rawResponse any
+
+	select {
+	case rawResponse = <-responseChannel:
+	case <-ctx.Done():
+		return nil, gitopserrors.NewDevOnlyError(fmt.Errorf("context cancelled in assignGitopsEngineInstanceForNewApplication"))
+	}
+
+	response, ok := rawResponse.(sharedResourceLoopMessage_assignGitopsEngineInstanceResponse)
+	if !ok {
+		return nil, gitopserrors.NewDevOnlyError(fmt.Errorf("SEVERE: unexpected response type"))
+	}
+
+	return response.gitopsEngineInstance, response.err
+}
+
+// internalProcessMessage_AssignGitopsEngineInstance is called from the shared resource event loop, so that Applications
+// are assigned to instances one at a time. However, the Application is created by the caller (after the instance has been
+// assigned), so the capacity is a soft limit: concurrently created Applications may slightly exceed it.
+func internalProcessMessage_AssignGitopsEngineInstance(ctx context.Context, request sharedResourceLoopMessage_assignGitopsEngineInstanceRequest,
+	dbq db.DatabaseQueries, l logr.Logger) (*db.GitopsEngineInstance, gitopserrors.UserError) {
+
+	preferredInstance := request.preferredInstance
+
+	hasCapacity, applicationCount, err := hasGitopsEngineInstanceCapacity(ctx, preferredInstance, dbq)
+	if err != nil {
+		return nil, gitopserrors.NewDevOnlyError(err)
+	}
+	if hasCapacity {
+		return &preferredInstance, nil
+	}
+
+	l.Info("GitOpsEngineInstance has reached its capacity, looking for another instance on the same cluster",
+		"gitopsEngineInstance", preferredInstance.Gitopsengineinstance_id, "applications", applicationCount,
+		"maxApplications", preferredInstance.Max_applications)
+
+	instances := []db.GitopsEngineInstance{}
+	if err := dbq.ListGitopsEngineInstancesForCluster(ctx, db.GitopsEngineCluster{Gitopsenginecluster_id: preferredInstance.EngineCluster_id}, &instances); err != nil {
+		return nil, gitopserrors.NewDevOnlyError(fmt.Errorf("unable to list the GitOpsEngineInstances of cluster '%s': %v", preferredInstance.EngineCluster_id, err))
+	}
+
+	// Sort by ID, so that the same instance is chosen between instances with an equal number of Applications
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Gitopsengineinstance_id < instances[j].Gitopsengineinstance_id
+	})
+
+	var assignedInstance *db.GitopsEngineInstance
+	assignedInstanceApplicationCount := 0
+
+	for i := range instances {
+		instance := instances[i]
+
+		if instance.Gitopsengineinstance_id == preferredInstance.Gitopsengineinstance_id {
+			continue
+		}
+
+		hasCapacity, applicationCount, err := hasGitopsEngineInstanceCapacity(ctx, instance, dbq)
+		if err != nil {
+			return nil, gitopserrors.NewDevOnlyError(err)
+		}
+
+		if hasCapacity && (assignedInstance == nil || applicationCount < assignedInstanceApplicationCount) {
+			assignedInstance = &instance
+			assignedInstanceApplicationCount = applicationCount
+		}
+	}
+
+	if assignedInstance == nil {
+		userError := "unable to deploy the GitOpsDeployment: the Argo CD instances of the GitOps Service have reached their capacity. Please contact the administrator of the GitOps Service"
+		devError := fmt.Errorf("GitOpsEngineInstance '%s' has reached its capacity of %d Applications, and no other instance on cluster '%s' has capacity",
+			preferredInstance.Gitopsengineinstance_id, preferredInstance.Max_applications, preferredInstance.EngineCluster_id)
+
+		return nil, gitopserrors.NewUserConditionError(userError, devError, string(managedgitopsv1alpha1.GitopsDeploymentReasonInstanceCapacityExceeded))
+	}
+
+	// The user requires a ClusterAccess to the ManagedEnvironment from the assigned instance, as they have from the preferred instance
+	if request.managedEnvironmentID != "" {
+		ca := db.ClusterAccess{
+			Clusteraccess_user_id:                   request.clusterUser.Clusteruser_id,
+			Clusteraccess_managed_environment_id:    request.managedEnvironmentID,
+			Clusteraccess_gitops_engine_instance_id: assignedInstance.Gitopsengineinstance_id,
+		}
+		if _, err := internalGetOrCreateClusterAccess(ctx, &ca, dbq, l); err != nil {
+			return nil, gitopserrors.NewDevOnlyError(fmt.Errorf("unable to create cluster access for assigned GitOpsEngineInstance: %v", err))
+		}
+	}
+
+	l.Info("Assigned new Application to GitOpsEngineInstance with capacity", "gitopsEngineInstance", assignedInstance.Gitopsengineinstance_id,
+		"applications", assignedInstanceApplicationCount, "maxApplications", assignedInstance.Max_applications)
+
+	return assignedInstance, nil
+}
+
+// hasGitopsEngineInstanceCapacity returns true if another Application may be assigned to the instance, and the number
+// of Applications that are currently assigned to it.
+func hasGitopsEngineInstanceCapacity(ctx context.Context, instance db.GitopsEngineInstance, dbq db.DatabaseQueries) (bool, int, error) {
+
+	applicationCount, err := dbq.CountApplicationsForGitopsEngineInstance(ctx, instance.Gitopsengineinstance_id)
+	if err != nil {
+		return false, 0, fmt.Errorf("unable to count the Applications of GitOpsEngineInstance '%s': %v", instance.Gitopsengineinstance_id, err)
+	}
+
+	if instance.Max_applications == 0 {
+		// The instance has no limit
+		return true, applicationCount, nil
+	}
+
+	return applicationCount < instance.Max_applications, applicationCount, nil
+}
@@ -0,0 +1,116 @@
+package shared_resource_loop
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("SharedResourceEventLoop GitOpsEngineInstance assignment test", func() {
+
+	Context("internalProcessMessage_AssignGitopsEngineInstance", func() {
+
+		var ctx context.Context
+		var log logr.Logger
+		var dbQueries db.AllDatabaseQueries
+
+		var managedEnvironment *db.ManagedEnvironment
+		var preferredInstance *db.GitopsEngineInstance
+		var otherInstance *db.GitopsEngineInstance
+		var clusterUser db.ClusterUser
+
+		createApplication := func(applicationID string, instance *db.GitopsEngineInstance) {
+			Expect(dbQueries.CreateApplication(ctx, &db.Application{
+				Application_id:          applicationID,
+				Name:                    applicationID,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: instance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			})).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+			log = logf.FromContext(ctx)
+
+			dbQueries, err = db.NewUnsafePostgresDBQueries(false, true)
+			Expect(err).To(BeNil())
+
+			var engineCluster *db.GitopsEngineCluster
+			var clusterAccess *db.ClusterAccess
+			_, managedEnvironment, engineCluster, preferredInstance, clusterAccess, err = db.CreateSampleData(dbQueries)
+			Expect(err).To(BeNil())
+
+			clusterUser = db.ClusterUser{Clusteruser_id: clusterAccess.Clusteraccess_user_id}
+
+			otherInstance = &db.GitopsEngineInstance{
+				Gitopsengineinstance_id: "test-other-instance-id",
+				Namespace_name:          "gitops-service-argocd-2",
+				Namespace_uid:           "test-other-instance-namespace-uid",
+				EngineCluster_id:        engineCluster.Gitopsenginecluster_id,
+			}
+			Expect(dbQueries.CreateGitopsEngineInstance(ctx, otherInstance)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			dbQueries.CloseDatabase()
+		})
+
+		It("should assign the preferred instance, when it has no capacity limit", func() {
+			createApplication("test-application-1", preferredInstance)
+
+			instance, uerr := internalProcessMessage_AssignGitopsEngineInstance(ctx, sharedResourceLoopMessage_assignGitopsEngineInstanceRequest{
+				preferredInstance:    *preferredInstance,
+				clusterUser:          clusterUser,
+				managedEnvironmentID: managedEnvironment.Managedenvironment_id,
+			}, dbQueries, log)
+			Expect(uerr).To(BeNil())
+			Expect(instance.Gitopsengineinstance_id).To(Equal(preferredInstance.Gitopsengineinstance_id))
+		})
+
+		It("should assign another instance with capacity when the preferred instance is full, and return a capacity condition when all instances are full", func() {
+			Expect(dbQueries.UpdateGitopsEngineInstanceMaxApplications(ctx, preferredInstance.Gitopsengineinstance_id, 1)).To(Succeed())
+			Expect(dbQueries.GetGitopsEngineInstanceById(ctx, preferredInstance)).To(Succeed())
+			createApplication("test-application-1", preferredInstance)
+
+			request := sharedResourceLoopMessage_assignGitopsEngineInstanceRequest{
+				preferredInstance:    *preferredInstance,
+				clusterUser:          clusterUser,
+				managedEnvironmentID: managedEnvironment.Managedenvironment_id,
+			}
+
+			By("assigning the other instance, and creating a ClusterAccess for it")
+			instance, uerr := internalProcessMessage_AssignGitopsEngineInstance(ctx, request, dbQueries, log)
+			Expect(uerr).To(BeNil())
+			Expect(instance.Gitopsengineinstance_id).To(Equal(otherInstance.Gitopsengineinstance_id))
+
+			clusterAccess := db.ClusterAccess{
+				Clusteraccess_user_id:                   clusterUser.Clusteruser_id,
+				Clusteraccess_managed_environment_id:    managedEnvironment.Managedenvironment_id,
+				Clusteraccess_gitops_engine_instance_id: otherInstance.Gitopsengineinstance_id,
+			}
+			Expect(dbQueries.GetClusterAccessByPrimaryKey(ctx, &clusterAccess)).To(Succeed())
+
+			By("filling the other instance, and ensuring the InstanceCapacityExceeded reason is returned")
+			Expect(dbQueries.UpdateGitopsEngineInstanceMaxApplications(ctx, otherInstance.Gitopsengineinstance_id, 1)).To(Succeed())
+			createApplication("test-application-2", otherInstance)
+
+			instance, uerr = internalProcessMessage_AssignGitopsEngineInstance(ctx, request, dbQueries, log)
+			Expect(instance).To(BeNil())
+			Expect(uerr).ToNot(BeNil())
+
+			conditionError, ok := uerr.(gitopserrors.ConditionError)
+			Expect(ok).To(BeTrue())
+			Expect(conditionError.ConditionReason()).To(Equal(string(managedgitopsv1alpha1.GitopsDeploymentReasonInstanceCapacityExceeded)))
+		})
+	})
+})
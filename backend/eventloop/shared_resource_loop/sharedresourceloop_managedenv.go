@@ -2,24 +2,31 @@ package shared_resource_loop
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
 	corev1 "k8s.io/api/core/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -51,6 +58,12 @@ func internalProcessMessage_ReconcileSharedManagedEnv(ctx context.Context, works
 
 	}
 
+	if err == nil && container.ManagedEnv != nil && condition.status == metav1.ConditionTrue && condition.managedEnvCR.Name != "" {
+
+		// Now that we know we are able to connect to the cluster, record its version and available API groups.
+		reconcileManagedEnvironmentClusterCapabilities(ctx, container.ManagedEnv, condition.managedEnvCR, workspaceClient, k8sClientFactory, dbQueries, log)
+	}
+
 	return container, err
 
 }
@@ -92,6 +105,15 @@ func internalProcessMessage_internalReconcileSharedManagedEnv(ctx context.Contex
 		return newSharedResourceManagedEnvContainer(), createUnknownErrorEnvInitCondition(), nil
 	}
 
+	deletionBlocked, err := reconcileManagedEnvironmentDeletionProtection(ctx, &managedEnvironmentCR, workspaceClient, log)
+	if err != nil {
+		return newSharedResourceManagedEnvContainer(), createUnknownErrorEnvInitCondition(), err
+	}
+	if !deletionBlocked && managedEnvironmentCR.DeletionTimestamp != nil {
+		// The managed environment is being deleted: the database entries will be cleaned up once the CR no longer exists.
+		return newSharedResourceManagedEnvContainer(), createUnknownErrorEnvInitCondition(), nil
+	}
+
 	if strings.Contains(managedEnvironmentCR.Spec.APIURL, "?") || strings.Contains(managedEnvironmentCR.Spec.APIURL, "&") {
 
 		return newSharedResourceManagedEnvContainer(), connectionInitializedCondition{
@@ -102,6 +124,12 @@ func internalProcessMessage_internalReconcileSharedManagedEnv(ctx context.Contex
 		}, nil
 	}
 
+	if err := managedEnvironmentCR.Spec.ValidateClusterLabels(); err != nil {
+		return newSharedResourceManagedEnvContainer(),
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidClusterLabels, err, managedEnvironmentCR),
+			err
+	}
+
 	// After this point in the code, the API CR necessarily exists.
 
 	// Retrieve all existing APICRToDatabaseMappings for this resource name/namespace, and clean up the ones that don't match the UID
@@ -162,6 +190,25 @@ func internalProcessMessage_internalReconcileSharedManagedEnv(ctx context.Contex
 		return constructNewManagedEnv(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, workspaceNamespace, k8sClientFactory, dbQueries, log)
 	}
 
+	// The cluster labels don't affect the connection to the cluster, so they are updated in place, if they have changed.
+	if clusterLabels := convertManagedEnvClusterLabelsToManagedEnvironmentField(managedEnvironmentCR.Spec); !reflect.DeepEqual(managedEnv.Cluster_labels, clusterLabels) {
+
+		managedEnv.Cluster_labels = clusterLabels
+		if err := dbQueries.UpdateManagedEnvironment(ctx, managedEnv); err != nil {
+			return newSharedResourceManagedEnvContainer(),
+				createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+				fmt.Errorf("unable to update cluster labels of managed environment '%s': %w", managedEnv.Managedenvironment_id, err)
+		}
+		log.Info("Updated cluster labels of ManagedEnvironment", managedEnv.GetAsLogKeyValues()...)
+	}
+
+	// If the user has disconnected the managed environment, don't connect to the cluster: instead, ensure the Argo CD cluster
+	// secret of the managed environment is removed, while retaining the Applications that target it.
+	if managedEnvironmentCR.Spec.Disconnected {
+		return disconnectExistingManagedEnv(ctx, gitopsEngineClient, *clusterUser, isNewUser, managedEnvironmentCR, *managedEnv,
+			workspaceNamespace, k8sClientFactory, dbQueries, log)
+	}
+
 	clusterCreds := &db.ClusterCredentials{
 		Clustercredentials_cred_id: managedEnv.Clustercredentials_id,
 	}
@@ -194,11 +241,58 @@ func internalProcessMessage_internalReconcileSharedManagedEnv(ctx context.Contex
 			}, errors.New(msg)
 	}
 
+	namespaceQuotaCPU, namespaceQuotaMemory, err := convertManagedEnvNamespaceQuotaToClusterCredentialsFields(managedEnvironmentCR.Spec)
+	if err != nil {
+		return newSharedResourceManagedEnvContainer(),
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidNamespaceQuota, err, managedEnvironmentCR),
+			err
+	}
+
+	// If the managed environment was previously disconnected, its credentials may have been revoked (and replaced) in the meantime:
+	// so, as with a rotation, replace the cluster credentials with new ones read from the Secret, and ensure Argo CD is updated
+	// to use them (which recreates the Argo CD cluster secret).
+	if managedEnv.Disconnected {
+		log.Info("Reconnecting managed environment, as .spec.disconnected is no longer set", managedEnv.GetAsLogKeyValues()...)
+
+		managedEnv.Disconnected = false
+		return rotateExistingManagedEnvCredentials(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, *managedEnv,
+			workspaceNamespace, k8sClientFactory, dbQueries, log)
+	}
+
+	// If the user has requested that the credentials be rotated (by setting/updating .spec.rotateCredentialsRequestedAt), then
+	// replace the cluster credentials with new ones read from the Secret, and ensure Argo CD is updated to use them.
+	if rotationRequestedAt := convertManagedEnvRotateCredentialsRequestedAtToClusterCredentialsField(managedEnvironmentCR.Spec); clusterCreds.Rotation_requested_at != rotationRequestedAt {
+		log.Info("Rotating cluster credentials of managed environment, as requested by .spec.rotateCredentialsRequestedAt",
+			"rotateCredentialsRequestedAt", rotationRequestedAt, "previousRotateCredentialsRequestedAt", clusterCreds.Rotation_requested_at,
+			"clusterCreds", clusterCreds.Clustercredentials_cred_id)
+
+		return rotateExistingManagedEnvCredentials(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, *managedEnv,
+			workspaceNamespace, k8sClientFactory, dbQueries, log)
+	}
+
+	// If the data of the Secret has changed since the cluster credentials were read from it (for example, the token of a
+	// DeploymentTarget was refreshed), then rotate the credentials, so that Argo CD is updated to use the new token.
+	// - Cluster credentials that were created before the hash was recorded have no hash: these are only replaced if they
+	//   no longer work (see below).
+	if secretDataHash := sharedutil.HashSecretData(secretCR.Data); managedEnvironmentCR.Spec.ArgoCDClusterSecret == "" &&
+		clusterCreds.Secret_data_hash != "" && clusterCreds.Secret_data_hash != secretDataHash {
+
+		log.Info("Rotating cluster credentials of managed environment, as the data of its Secret has changed",
+			"secret", secretCR.Name, "clusterCreds", clusterCreds.Clustercredentials_cred_id)
+
+		return rotateExistingManagedEnvCredentials(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, *managedEnv,
+			workspaceNamespace, k8sClientFactory, dbQueries, log)
+	}
+
 	// We found the managed env, now verify that the ManagedEnv's .spec values match the corresponding fields in the ClusterCredentials row
 	if clusterCreds.Host != managedEnvironmentCR.Spec.APIURL ||
 		clusterCreds.AllowInsecureSkipTLSVerify != managedEnvironmentCR.Spec.AllowInsecureSkipTLSVerify ||
 		clusterCreds.ClusterResources != managedEnvironmentCR.Spec.ClusterResources ||
-		clusterCreds.Namespaces != managedEnvNamespaceSliceList {
+		clusterCreds.Namespaces != managedEnvNamespaceSliceList ||
+		clusterCreds.Namespace_quota_cpu != namespaceQuotaCPU ||
+		clusterCreds.Namespace_quota_memory != namespaceQuotaMemory ||
+		clusterCreds.Argocd_cluster_secret != managedEnvironmentCR.Spec.ArgoCDClusterSecret ||
+		clusterCreds.Proxy_url != managedEnvironmentCR.Spec.ProxyURL {
 		// C) If at least one of the fields in the managed env CR has changed, then replace the cluster credentials of the managed environment
 		return replaceExistingManagedEnv(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, *managedEnv,
 			workspaceNamespace, k8sClientFactory, dbQueries, log)
@@ -209,8 +303,8 @@ func internalProcessMessage_internalReconcileSharedManagedEnv(ctx context.Contex
 	if !validClusterCreds || err != nil {
 		log.Info("was unable to connect using provided cluster credentials, so acquiring new ones.", "clusterCreds", clusterCreds.Clustercredentials_cred_id)
 		// D) If the cluster credentials appear to no longer be valid (we're no longer able to connect), then reacquire using the
-		// Secret.
-		return replaceExistingManagedEnv(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, *managedEnv,
+		// Secret, and ensure Argo CD is updated to use them (as the Argo CD cluster secret contains the same credentials).
+		return rotateExistingManagedEnvCredentials(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, *managedEnv,
 			workspaceNamespace, k8sClientFactory, dbQueries, log)
 	}
 
@@ -283,6 +377,12 @@ func getManagedEnvironmentCRs(ctx context.Context,
 			fmt.Errorf("managed environment '%s' in '%s', could not be retrieved: %v", managedEnvironmentCR.Name, managedEnvironmentCR.Namespace, err)
 	}
 
+	if managedEnvironmentCR.Spec.ArgoCDClusterSecret != "" && managedEnvironmentCR.Spec.ClusterCredentialsSecret == "" {
+		// The cluster credentials are read from an existing Argo CD cluster secret (see getBearerTokenFromArgoCDClusterSecret),
+		// so there is no Secret to retrieve from the workspace.
+		return managedEnvironmentCR, corev1.Secret{}, resourceExists, nil
+	}
+
 	if managedEnvironmentCR.Spec.ClusterCredentialsSecret == "" {
 		return managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{}, corev1.Secret{}, resourceExists,
 			fmt.Errorf("secret '%s' referenced by managed environment '%s' in '%s', is invalid",
@@ -305,6 +405,154 @@ func getManagedEnvironmentCRs(ctx context.Context,
 	return managedEnvironmentCR, secretCR, resourceExists, nil
 }
 
+// reconcileManagedEnvironmentDeletionProtection ensures that the deletion protection finalizer is present on the managed
+// environment CR if (and only if) .spec.deletionProtection is set.
+//
+// If the managed environment CR has been deleted, the finalizer is only removed once there are no longer any GitOpsDeployments
+// that target the managed environment. Until then, the blocking GitOpsDeployments are reported in the DeletionBlocked condition.
+//
+// Returns true if deletion of the managed environment is blocked, false otherwise.
+func reconcileManagedEnvironmentDeletionProtection(ctx context.Context, managedEnvironmentCR *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	workspaceClient client.Client, log logr.Logger) (bool, error) {
+
+	hasFinalizer := false
+	for _, finalizer := range managedEnvironmentCR.Finalizers {
+		if finalizer == managedgitopsv1alpha1.ManagedEnvironmentDeletionProtectionFinalizer {
+			hasFinalizer = true
+			break
+		}
+	}
+
+	if managedEnvironmentCR.DeletionTimestamp == nil {
+
+		if managedEnvironmentCR.Spec.DeletionProtection && !hasFinalizer {
+
+			managedEnvironmentCR.Finalizers = append(managedEnvironmentCR.Finalizers, managedgitopsv1alpha1.ManagedEnvironmentDeletionProtectionFinalizer)
+			if err := workspaceClient.Update(ctx, managedEnvironmentCR); err != nil {
+				return false, fmt.Errorf("unable to add deletion protection finalizer to managed environment '%s': %w", managedEnvironmentCR.Name, err)
+			}
+			log.Info("Added deletion protection finalizer to managed environment", "managedEnv", managedEnvironmentCR.Name)
+
+		} else if !managedEnvironmentCR.Spec.DeletionProtection && hasFinalizer {
+
+			if err := removeManagedEnvironmentDeletionProtectionFinalizer(ctx, managedEnvironmentCR, workspaceClient, log); err != nil {
+				return false, err
+			}
+		}
+
+		return false, nil
+	}
+
+	// The managed environment CR has been deleted.
+
+	if !hasFinalizer {
+		return false, nil
+	}
+
+	if managedEnvironmentCR.Spec.DeletionProtection {
+
+		blockingGitOpsDeployments, err := getGitOpsDeploymentsTargetingManagedEnvironment(ctx, *managedEnvironmentCR, workspaceClient)
+		if err != nil {
+			return false, err
+		}
+
+		if len(blockingGitOpsDeployments) > 0 {
+
+			message := "managed environment deletion is blocked, as it is still targeted by GitOpsDeployments: " + strings.Join(blockingGitOpsDeployments, ", ")
+			log.Info("Deletion of managed environment is blocked by deletion protection", "managedEnv", managedEnvironmentCR.Name,
+				"gitopsDeployments", blockingGitOpsDeployments)
+
+			if err := updateManagedEnvironmentDeletionBlockedStatus(ctx, managedEnvironmentCR, workspaceClient, message); err != nil {
+				return true, err
+			}
+
+			return true, nil
+		}
+	}
+
+	if err := removeManagedEnvironmentDeletionProtectionFinalizer(ctx, managedEnvironmentCR, workspaceClient, log); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// getGitOpsDeploymentsTargetingManagedEnvironment returns the sorted names of the GitOpsDeployments in the namespace of the managed
+// environment, that target the managed environment.
+func getGitOpsDeploymentsTargetingManagedEnvironment(ctx context.Context, managedEnvironmentCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	workspaceClient client.Client) ([]string, error) {
+
+	var gitopsDeplList managedgitopsv1alpha1.GitOpsDeploymentList
+	if err := workspaceClient.List(ctx, &gitopsDeplList, &client.ListOptions{Namespace: managedEnvironmentCR.Namespace}); err != nil {
+		return nil, fmt.Errorf("unable to list GitOpsDeployments in '%s': %w", managedEnvironmentCR.Namespace, err)
+	}
+
+	res := []string{}
+	for _, gitopsDepl := range gitopsDeplList.Items {
+		if gitopsDepl.Spec.Destination.Environment == managedEnvironmentCR.Name {
+			res = append(res, gitopsDepl.Name)
+		}
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
+
+func removeManagedEnvironmentDeletionProtectionFinalizer(ctx context.Context, managedEnvironmentCR *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	workspaceClient client.Client, log logr.Logger) error {
+
+	finalizers := []string{}
+	for _, finalizer := range managedEnvironmentCR.Finalizers {
+		if finalizer != managedgitopsv1alpha1.ManagedEnvironmentDeletionProtectionFinalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	managedEnvironmentCR.Finalizers = finalizers
+
+	if err := workspaceClient.Update(ctx, managedEnvironmentCR); err != nil {
+		return fmt.Errorf("unable to remove deletion protection finalizer from managed environment '%s': %w", managedEnvironmentCR.Name, err)
+	}
+	log.Info("Removed deletion protection finalizer from managed environment", "managedEnv", managedEnvironmentCR.Name)
+
+	return nil
+}
+
+// updateManagedEnvironmentDeletionBlockedStatus sets the DeletionBlocked condition of the managed environment to the given message.
+// As with updateManagedEnvironmentConnectionStatus, no update is made if the condition is unchanged.
+func updateManagedEnvironmentDeletionBlockedStatus(ctx context.Context, managedEnvironmentCR *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	workspaceClient client.Client, message string) error {
+
+	const conditionType = managedgitopsv1alpha1.ManagedEnvironmentStatusDeletionBlocked
+	const reason = managedgitopsv1alpha1.ConditionReasonTargetedByGitOpsDeployments
+
+	var condition *metav1.Condition = nil
+	for i := range managedEnvironmentCR.Status.Conditions {
+		if managedEnvironmentCR.Status.Conditions[i].Type == conditionType {
+			condition = &managedEnvironmentCR.Status.Conditions[i]
+			break
+		}
+	}
+	if condition == nil {
+		managedEnvironmentCR.Status.Conditions = append(managedEnvironmentCR.Status.Conditions, metav1.Condition{Type: conditionType})
+		condition = &managedEnvironmentCR.Status.Conditions[len(managedEnvironmentCR.Status.Conditions)-1]
+	}
+
+	if condition.Reason == string(reason) && condition.Message == message && condition.Status == metav1.ConditionTrue {
+		return nil
+	}
+
+	condition.Reason = string(reason)
+	condition.Message = message
+	condition.LastTransitionTime = metav1.Now()
+	condition.Status = metav1.ConditionTrue
+
+	if err := workspaceClient.Status().Update(ctx, managedEnvironmentCR); err != nil {
+		return fmt.Errorf("unable to update deletion blocked condition of managed environment '%s': %w", managedEnvironmentCR.Name, err)
+	}
+
+	return nil
+}
+
 // deleteManagedEnvironmentDBByAPINameAndNamespace will delete all the Managed Environments DB resources (plus related DB resources) that
 // have the given name/namespace (that don't match 'skipResourcesWithK8sUID')
 // Parameters:
@@ -446,6 +694,97 @@ func replaceExistingManagedEnv(ctx context.Context,
 	return res, createSuccessEnvInitCondition(managedEnvironmentCR), nil
 }
 
+// rotateExistingManagedEnvCredentials replaces the credentials of an existing managed environment (as with replaceExistingManagedEnv),
+// then creates a ManagedEnvironment Operation for each Argo CD instance that has Applications targeting the managed environment,
+// so that the cluster-agent updates the Argo CD cluster secret of the managed environment to the new credentials.
+//
+// The Argo CD cluster secret is updated in a single write by the Operation, independently of the Applications: the
+// Applications (and any in-progress syncs of them) are not modified.
+func rotateExistingManagedEnvCredentials(ctx context.Context,
+	gitopsEngineClient client.Client,
+	workspaceClient client.Client,
+	clusterUser db.ClusterUser, isNewUser bool,
+	managedEnvironmentCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	secret corev1.Secret,
+	managedEnvironmentDB db.ManagedEnvironment,
+	workspaceNamespace corev1.Namespace,
+	k8sClientFactory SRLK8sClientFactory,
+	dbQueries db.DatabaseQueries,
+	log logr.Logger) (SharedResourceManagedEnvContainer, connectionInitializedCondition, error) {
+
+	res, connInitCondition, err := replaceExistingManagedEnv(ctx, gitopsEngineClient, workspaceClient, clusterUser, isNewUser, managedEnvironmentCR,
+		secret, managedEnvironmentDB, workspaceNamespace, k8sClientFactory, dbQueries, log)
+	if err != nil || res.ManagedEnv == nil {
+		return res, connInitCondition, err
+	}
+
+	applications := []db.Application{}
+	if _, err := dbQueries.ListApplicationsForManagedEnvironment(ctx, managedEnvironmentDB.Managedenvironment_id, &applications); err != nil {
+		return newSharedResourceManagedEnvContainer(),
+			createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+			fmt.Errorf("unable to list applications of managed environment '%s', after rotating credentials: %w", managedEnvironmentDB.Managedenvironment_id, err)
+	}
+
+	// Each Argo CD instance has its own cluster secret for the managed environment
+	processedInstances := map[string]bool{}
+
+	for idx := range applications {
+		app := applications[idx]
+
+		if processedInstances[app.Engine_instance_inst_id] {
+			continue
+		}
+		processedInstances[app.Engine_instance_inst_id] = true
+
+		gitopsEngineInstance := &db.GitopsEngineInstance{
+			Gitopsengineinstance_id: app.Engine_instance_inst_id,
+		}
+		if err := dbQueries.GetGitopsEngineInstanceById(ctx, gitopsEngineInstance); err != nil {
+			return newSharedResourceManagedEnvContainer(), createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+				fmt.Errorf("unable to retrieve gitopsengineinstance '%s' while rotating credentials of managed environment '%s': %w",
+					gitopsEngineInstance.Gitopsengineinstance_id, managedEnvironmentDB.Managedenvironment_id, err)
+		}
+
+		if err := createRefreshManagedEnvClusterSecretOperation(ctx, *res.ManagedEnv, *gitopsEngineInstance, clusterUser,
+			k8sClientFactory, dbQueries, log); err != nil {
+			return newSharedResourceManagedEnvContainer(), createUnknownErrorEnvInitCondition(), err
+		}
+	}
+
+	return res, connInitCondition, nil
+}
+
+// createRefreshManagedEnvClusterSecretOperation creates an Operation which causes the cluster-agent to update the Argo CD
+// cluster secret of a (connected) managed environment, in the namespace of 'gitopsEngineInstance', to its current credentials.
+func createRefreshManagedEnvClusterSecretOperation(ctx context.Context, managedEnvironmentDB db.ManagedEnvironment,
+	gitopsEngineInstance db.GitopsEngineInstance, clusterUser db.ClusterUser, k8sClientFactory SRLK8sClientFactory,
+	dbQueries db.DatabaseQueries, log logr.Logger) error {
+
+	client, err := k8sClientFactory.GetK8sClientForGitOpsEngineInstance(ctx, &gitopsEngineInstance)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve k8s client for engine instance '%s': %w", gitopsEngineInstance.Gitopsengineinstance_id, err)
+	}
+
+	operation := db.Operation{
+		Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+		Operation_owner_user_id: clusterUser.Clusteruser_id,
+		Resource_type:           db.OperationResourceType_ManagedEnvironment,
+		Resource_id:             managedEnvironmentDB.Managedenvironment_id,
+	}
+
+	log.Info("Creating Operation to update Argo CD cluster secret, after rotating managed environment credentials",
+		"gitopsEngineInstanceID", gitopsEngineInstance.Gitopsengineinstance_id)
+
+	// Don't wait for the Operation to complete
+	if _, _, err := operations.CreateOperation(ctx, false, operation, clusterUser.Clusteruser_id,
+		gitopsEngineInstance.Namespace_name, dbQueries, client, log); err != nil {
+		return fmt.Errorf("unable to create operation for managed environment '%s', after rotating credentials: %w",
+			managedEnvironmentDB.Managedenvironment_id, err)
+	}
+
+	return nil
+}
+
 // constructNewManagedEnv creates a new ManagedEnvironment using the provided parameters, then creates ClusterAccess/GitOpsEngineInstance,
 // and returns those all created resources in a SharedResourceContainer
 func constructNewManagedEnv(ctx context.Context,
@@ -488,9 +827,106 @@ func constructNewManagedEnv(ctx context.Context,
 		GitopsEngineCluster:  engineCluster,
 	}
 
+	if managedEnvDB.Disconnected {
+		return res, createDisconnectedEnvInitCondition(managedEnvironment), nil
+	}
+
 	return res, createSuccessEnvInitCondition(managedEnvironment), nil
 }
 
+// disconnectExistingManagedEnv marks an existing managed environment as disconnected, then creates an Operation to instruct
+// the cluster-agent to remove the Argo CD cluster secret of the managed environment. The Applications that target the managed
+// environment are not modified, and so the resources they deployed are not deleted from the cluster.
+func disconnectExistingManagedEnv(ctx context.Context,
+	gitopsEngineClient client.Client,
+	clusterUser db.ClusterUser, isNewUser bool,
+	managedEnvironmentCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	managedEnvironmentDB db.ManagedEnvironment,
+	workspaceNamespace corev1.Namespace,
+	k8sClientFactory SRLK8sClientFactory,
+	dbQueries db.DatabaseQueries,
+	log logr.Logger) (SharedResourceManagedEnvContainer, connectionInitializedCondition, error) {
+
+	engineInstance, isNewEngineInstance, clusterAccess,
+		isNewClusterAccess, engineCluster, uerr := wrapManagedEnv(ctx,
+		managedEnvironmentDB, workspaceNamespace, clusterUser, gitopsEngineClient, dbQueries, log)
+
+	if uerr != nil {
+		return newSharedResourceManagedEnvContainer(),
+			convertConditionErrorToConnInitCondition(uerr, managedEnvironmentCR),
+			fmt.Errorf("unable to wrap managed environment, on disconnected managed env, for %s: %w", managedEnvironmentCR.UID, uerr.DevError())
+	}
+
+	if !managedEnvironmentDB.Disconnected {
+
+		// 1) Mark the managed environment as disconnected: from this point on, the cluster-agent will not (re)create the
+		//    Argo CD cluster secret of the managed environment.
+		managedEnvironmentDB.Disconnected = true
+		if err := dbQueries.UpdateManagedEnvironment(ctx, &managedEnvironmentDB); err != nil {
+			log.Error(err, "Unable to update ManagedEnvironment as disconnected", managedEnvironmentDB.GetAsLogKeyValues()...)
+
+			return newSharedResourceManagedEnvContainer(),
+				createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+				fmt.Errorf("unable to update managed environment as disconnected: %w", err)
+		}
+		log.Info("Updated ManagedEnvironment as disconnected", managedEnvironmentDB.GetAsLogKeyValues()...)
+
+		// 2) Create an Operation to instruct the cluster-agent to remove the Argo CD cluster secret
+		if err := createDisconnectManagedEnvOperation(ctx, managedEnvironmentDB, *engineInstance, clusterUser, k8sClientFactory, dbQueries, log); err != nil {
+
+			// Revert the managed environment to connected, so that disconnecting it is retried on the next reconcile.
+			managedEnvironmentDB.Disconnected = false
+			if revertErr := dbQueries.UpdateManagedEnvironment(ctx, &managedEnvironmentDB); revertErr != nil {
+				log.Error(revertErr, "Unable to revert disconnected ManagedEnvironment", managedEnvironmentDB.GetAsLogKeyValues()...)
+			}
+
+			return newSharedResourceManagedEnvContainer(), createUnknownErrorEnvInitCondition(), err
+		}
+	}
+
+	res := SharedResourceManagedEnvContainer{
+		ClusterUser:          &clusterUser,
+		IsNewUser:            isNewUser,
+		ManagedEnv:           &managedEnvironmentDB,
+		IsNewManagedEnv:      false,
+		GitopsEngineInstance: engineInstance,
+		IsNewInstance:        isNewEngineInstance,
+		ClusterAccess:        clusterAccess,
+		IsNewClusterAccess:   isNewClusterAccess,
+		GitopsEngineCluster:  engineCluster,
+	}
+
+	return res, createDisconnectedEnvInitCondition(managedEnvironmentCR), nil
+}
+
+// createDisconnectManagedEnvOperation creates an Operation, targeting the disconnected managed environment, which instructs
+// the cluster-agent to remove the Argo CD cluster secret of the managed environment from the given Argo CD instance.
+func createDisconnectManagedEnvOperation(ctx context.Context, managedEnvironmentDB db.ManagedEnvironment, gitopsEngineInstance db.GitopsEngineInstance,
+	clusterUser db.ClusterUser, k8sClientFactory SRLK8sClientFactory, dbQueries db.DatabaseQueries, log logr.Logger) error {
+
+	client, err := k8sClientFactory.GetK8sClientForGitOpsEngineInstance(ctx, &gitopsEngineInstance)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve k8s client for engine instance '%s': %w", gitopsEngineInstance.Gitopsengineinstance_id, err)
+	}
+
+	operation := db.Operation{
+		Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+		Operation_owner_user_id: clusterUser.Clusteruser_id,
+		Resource_type:           db.OperationResourceType_ManagedEnvironment,
+		Resource_id:             managedEnvironmentDB.Managedenvironment_id,
+	}
+
+	log.Info("Creating Operation to remove Argo CD cluster secret, of disconnected managed environment")
+
+	// Don't wait for the Operation to complete
+	if _, _, err := operations.CreateOperation(ctx, false, operation, clusterUser.Clusteruser_id,
+		gitopsEngineInstance.Namespace_name, dbQueries, client, log); err != nil {
+		return fmt.Errorf("unable to create operation for disconnected managed environment '%s': %w", managedEnvironmentDB.Managedenvironment_id, err)
+	}
+
+	return nil
+}
+
 // wrapManagedEnv creates (or gets) a GitOpsEngineInstance, GitOpsEngineCluster, and ClusterAccess, for the provided 'managedEnv' param
 func wrapManagedEnv(ctx context.Context, managedEnv db.ManagedEnvironment, workspaceNamespace corev1.Namespace,
 	clusterUser db.ClusterUser, gitopsEngineClient client.Client, dbQueries db.DatabaseQueries, log logr.Logger) (*db.GitopsEngineInstance,
@@ -541,6 +977,9 @@ func createNewManagedEnv(ctx context.Context, managedEnvironment managedgitopsv1
 	managedEnv := &db.ManagedEnvironment{
 		Name:                  managedEnvironment.Name,
 		Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+		// If the managed environment is created disconnected, the Argo CD cluster secret is not created until it is reconnected.
+		Disconnected:   managedEnvironment.Spec.Disconnected,
+		Cluster_labels: convertManagedEnvClusterLabelsToManagedEnvironmentField(managedEnvironment.Spec),
 	}
 
 	if err := dbQueries.CreateManagedEnvironment(ctx, managedEnv); err != nil {
@@ -627,27 +1066,13 @@ func DeleteManagedEnvironmentResources(ctx context.Context, managedEnvID string,
 	}
 
 	// 3) Delete all cluster accesses that reference this managed env
-	clusterAccesses := []db.ClusterAccess{}
-	if err := dbQueries.ListClusterAccessesByManagedEnvironmentID(ctx, managedEnvID, &clusterAccesses); err != nil {
+	affectedUserIDs, err := dbQueries.DeleteClusterAccessesByManagedEnvironmentID(ctx, managedEnvID)
+	if err != nil {
 		// We exit here, because if this doesn't succeed, we won't be able to do any of the other next steps, due to database foreign keys
-		return fmt.Errorf("unable to list cluster accesses by managed id '%s': %v", managedEnvID, err)
-	}
-	for idx := range clusterAccesses {
-		clusterAccess := clusterAccesses[idx]
-
-		rowsDeleted, err := dbQueries.DeleteClusterAccessById(ctx, clusterAccess.Clusteraccess_user_id, managedEnvID, clusterAccess.Clusteraccess_gitops_engine_instance_id)
-		if err != nil {
-			log.Error(err, "Unable to delete ClusterAccess row that referenced to ManagedEnvironment", "userID", clusterAccess.Clusteraccess_user_id, "gitopsEngineInstanceID", clusterAccess.Clusteraccess_gitops_engine_instance_id)
-			return fmt.Errorf("unable to delete cluster access while deleting managed environment '%s': %v", clusterAccess.Clusteraccess_managed_environment_id, err)
-		}
-		if rowsDeleted != 1 {
-			// It's POSSIBLE (but unlikely) the cluster access was deleted while this for loop was in progress, so it's a WARN.
-			log.V(logutil.LogLevel_Warn).Info("Unexpected number of cluster accesses rows deleted, when deleting managed env.",
-				"rowsDeleted", rowsDeleted, "cluster-access", clusterAccess)
-		}
-		log.Info("Deleted ClusterAccess row that referenced to ManagedEnvironment", "userID", clusterAccess.Clusteraccess_user_id, "gitopsEngineInstanceID", clusterAccess.Clusteraccess_gitops_engine_instance_id)
-
+		log.Error(err, "Unable to delete ClusterAccess rows that referenced the ManagedEnvironment")
+		return fmt.Errorf("unable to delete cluster accesses while deleting managed environment '%s': %v", managedEnvID, err)
 	}
+	log.Info("Deleted ClusterAccess rows that referenced the ManagedEnvironment", "affectedUserIDs", affectedUserIDs)
 
 	// 4) Delete the ManagedEnvironment entry
 	rowsDeleted, err := dbQueries.DeleteManagedEnvironmentById(ctx, managedEnvID)
@@ -715,6 +1140,18 @@ type SRLK8sClientFactory interface {
 
 	// Create a client.Client which can access the cluster where GitOps Service is running
 	GetK8sClientForServiceWorkspace() (client.Client, error)
+
+	// Discover the Kubernetes version and available API groups of the cluster, using the given restconfig
+	DiscoverClusterCapabilities(restConfig *rest.Config) (ClusterCapabilities, error)
+}
+
+// ClusterCapabilities is the Kubernetes version and the API groups that are available on a cluster
+type ClusterCapabilities struct {
+	// KubernetesVersion is the git version of the cluster, for example 'v1.25.3'
+	KubernetesVersion string
+
+	// APIGroups is the list of API groups available on the cluster, not including the core API group
+	APIGroups []string
 }
 
 var _ SRLK8sClientFactory = DefaultK8sClientFactory{}
@@ -742,10 +1179,62 @@ func (DefaultK8sClientFactory) BuildK8sClient(restConfig *rest.Config) (client.C
 
 }
 
+func (DefaultK8sClientFactory) DiscoverClusterCapabilities(restConfig *rest.Config) (ClusterCapabilities, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return ClusterCapabilities{}, err
+	}
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return ClusterCapabilities{}, fmt.Errorf("unable to retrieve server version: %w", err)
+	}
+
+	serverGroups, err := discoveryClient.ServerGroups()
+	if err != nil {
+		return ClusterCapabilities{}, fmt.Errorf("unable to retrieve server groups: %w", err)
+	}
+
+	res := ClusterCapabilities{KubernetesVersion: serverVersion.GitVersion}
+	for _, group := range serverGroups.Groups {
+		// The core API group has an empty name, and is always available
+		if group.Name != "" {
+			res.APIGroups = append(res.APIGroups, group.Name)
+		}
+	}
+
+	return res, nil
+}
+
 func createNewClusterCredentials(ctx context.Context, managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
 	secret corev1.Secret, k8sClientFactory SRLK8sClientFactory, dbQueries db.DatabaseQueries, log logr.Logger,
 	workspaceClient client.Client) (db.ClusterCredentials, connectionInitializedCondition, error) {
 
+	namespaceQuotaCPU, namespaceQuotaMemory, err := convertManagedEnvNamespaceQuotaToClusterCredentialsFields(managedEnvironment.Spec)
+	if err != nil {
+		return db.ClusterCredentials{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidNamespaceQuota, err, managedEnvironment),
+			err
+	}
+
+	if err := managedEnvironment.Spec.ValidateProxyURL(); err != nil {
+		return db.ClusterCredentials{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidProxyURL, err, managedEnvironment),
+			err
+	}
+
+	serviceAccountNamespace, serviceAccountName, err := convertManagedEnvServiceAccountAnnotationToServiceAccount(managedEnvironment)
+	if err != nil {
+		return db.ClusterCredentials{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidServiceAccountAnnotation, err, managedEnvironment),
+			err
+	}
+
+	if managedEnvironment.Spec.ArgoCDClusterSecret != "" {
+		return createNewClusterCredentialsFromArgoCDClusterSecret(ctx, managedEnvironment, namespaceQuotaCPU, namespaceQuotaMemory,
+			k8sClientFactory, dbQueries, log)
+	}
+
 	if secret.Type != sharedutil.ManagedEnvironmentSecretType {
 		err := fmt.Errorf("invalid secret type: %s", secret.Type)
 		return db.ClusterCredentials{},
@@ -791,6 +1280,11 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 			err
 	}
 
+	// The proxy of the managed environment (if any) takes precedence over a proxy specified in the kubeconfig
+	if managedEnvironment.Spec.ProxyURL != "" {
+		restConfig.Proxy = argosharedutil.ProxyFuncForURL(managedEnvironment.Spec.ProxyURL)
+	}
+
 	k8sClient, err := k8sClientFactory.BuildK8sClient(restConfig)
 	if err != nil {
 		err := fmt.Errorf("unable to create k8s client from restConfig from managed environment secret: %w", err)
@@ -801,8 +1295,26 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 	}
 
 	var saBearerToken string
+	serviceAccountNS := serviceAccountNamespaceKubeSystem
 	log.Info("createNewServiceAccount is ", "CreateNewServiceAccount", managedEnvironment.Spec.CreateNewServiceAccount)
-	if managedEnvironment.Spec.CreateNewServiceAccount {
+	if serviceAccountName != "" {
+		// The user has specified the ServiceAccount to create via annotation: create it (and grant it access to the
+		// cluster, or to .spec.namespaces), using the credentials provided by the user in the secret, as with 'argocd cluster add'.
+		log.Info("Installing the service account of the managed environment annotation", "serviceAccount", serviceAccountName,
+			"serviceAccountNamespace", serviceAccountNamespace)
+
+		saBearerToken, _, err = sharedutil.InstallNamedServiceAccount(ctx, k8sClient, string(managedEnvironment.UID), serviceAccountName,
+			serviceAccountNamespace, managedEnvironment.Spec.Namespaces, log)
+		if err != nil {
+			err2 := fmt.Errorf("unable to install service account '%s/%s' from secret '%s': %w", serviceAccountNamespace, serviceAccountName, secret.Name, err)
+
+			return db.ClusterCredentials{},
+				convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonUnableToInstallServiceAccount, err, managedEnvironment),
+				err2
+		}
+		serviceAccountNS = serviceAccountNamespace
+
+	} else if managedEnvironment.Spec.CreateNewServiceAccount {
 		// This is the original behaviour, where we create a new service account
 		saBearerToken, _, err = sharedutil.InstallServiceAccount(ctx, k8sClient, string(managedEnvironment.UID), serviceAccountNamespaceKubeSystem, log)
 		if err != nil {
@@ -866,13 +1378,29 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 		Kube_config:                 "",
 		Kube_config_context:         "",
 		Serviceaccount_bearer_token: saBearerToken,
-		Serviceaccount_ns:           serviceAccountNamespaceKubeSystem,
+		Serviceaccount_ns:           serviceAccountNS,
 		AllowInsecureSkipTLSVerify:  insecureVerifyTLS,
 		Namespaces:                  namespacesField,
 		ClusterResources:            managedEnvironment.Spec.ClusterResources,
+		Rotation_requested_at:       convertManagedEnvRotateCredentialsRequestedAtToClusterCredentialsField(managedEnvironment.Spec),
+		Namespace_quota_cpu:         namespaceQuotaCPU,
+		Namespace_quota_memory:      namespaceQuotaMemory,
+		Proxy_url:                   managedEnvironment.Spec.ProxyURL,
+		Secret_data_hash:            sharedutil.HashSecretData(secret.Data),
 	}
+
+	return verifyAndCreateClusterCredentials(ctx, clusterCredentials, managedEnvironment, k8sClientFactory, dbQueries, log)
+}
+
+// verifyAndCreateClusterCredentials verifies that the cluster can be connected to with 'clusterCredentials' (unless a new
+// ServiceAccount was created), then creates the ClusterCredentials row.
+func verifyAndCreateClusterCredentials(ctx context.Context, clusterCredentials db.ClusterCredentials,
+	managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment, k8sClientFactory SRLK8sClientFactory,
+	dbQueries db.DatabaseQueries, log logr.Logger) (db.ClusterCredentials, connectionInitializedCondition, error) {
+
 	// If an existing service account is used instead, we should verify the cluster credentials based on the provided token
-	if !managedEnvironment.Spec.CreateNewServiceAccount {
+	_, serviceAccountAnnotationExists := managedEnvironment.Annotations[managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation]
+	if !managedEnvironment.Spec.CreateNewServiceAccount && !serviceAccountAnnotationExists {
 		validClusterCreds, err := verifyClusterCredentialsWithNamespaceList(ctx, clusterCredentials, managedEnvironment, k8sClientFactory)
 
 		if !validClusterCreds || err != nil {
@@ -910,6 +1438,116 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 
 }
 
+// createNewClusterCredentialsFromArgoCDClusterSecret creates the ClusterCredentials of a managed environment that references
+// an existing Argo CD cluster secret via .spec.argoCDClusterSecret, rather than a kubeconfig Secret.
+//
+// The cluster-agent still generates its own Argo CD cluster secret for the managed environment (from these ClusterCredentials,
+// as it does for any other managed environment): the existing Argo CD cluster secret is only read, and never modified.
+func createNewClusterCredentialsFromArgoCDClusterSecret(ctx context.Context, managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	namespaceQuotaCPU string, namespaceQuotaMemory string, k8sClientFactory SRLK8sClientFactory, dbQueries db.DatabaseQueries, log logr.Logger) (db.ClusterCredentials, connectionInitializedCondition, error) {
+
+	saBearerToken, err := getBearerTokenFromArgoCDClusterSecret(ctx, managedEnvironment, k8sClientFactory)
+	if err != nil {
+		return db.ClusterCredentials{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidArgoCDClusterSecret, err, managedEnvironment),
+			err
+	}
+
+	namespacesField, err := convertManagedEnvNamespacesFieldToCommaSeparatedList(managedEnvironment.Spec.Namespaces)
+	if err != nil {
+		return db.ClusterCredentials{},
+			connectionInitializedCondition{
+				managedEnvCR: managedEnvironment,
+				status:       metav1.ConditionUnknown,
+				reason:       managedgitopsv1alpha1.ConditionReasonInvalidNamespaceList,
+				message:      err.Error(),
+			}, fmt.Errorf("user specified an invalid namespace: %v", err)
+	}
+
+	clusterCredentials := db.ClusterCredentials{
+		Host:                        managedEnvironment.Spec.APIURL,
+		Serviceaccount_bearer_token: saBearerToken,
+		AllowInsecureSkipTLSVerify:  managedEnvironment.Spec.AllowInsecureSkipTLSVerify,
+		Namespaces:                  namespacesField,
+		ClusterResources:            managedEnvironment.Spec.ClusterResources,
+		Rotation_requested_at:       convertManagedEnvRotateCredentialsRequestedAtToClusterCredentialsField(managedEnvironment.Spec),
+		Namespace_quota_cpu:         namespaceQuotaCPU,
+		Namespace_quota_memory:      namespaceQuotaMemory,
+		Argocd_cluster_secret:       managedEnvironment.Spec.ArgoCDClusterSecret,
+		Proxy_url:                   managedEnvironment.Spec.ProxyURL,
+	}
+
+	return verifyAndCreateClusterCredentials(ctx, clusterCredentials, managedEnvironment, k8sClientFactory, dbQueries, log)
+}
+
+// getBearerTokenFromArgoCDClusterSecret retrieves the Argo CD cluster secret referenced by .spec.argoCDClusterSecret from
+// the Argo CD namespace, verifies that it may be referenced by the managed environment, and returns its bearer token.
+func getBearerTokenFromArgoCDClusterSecret(ctx context.Context, managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	k8sClientFactory SRLK8sClientFactory) (string, error) {
+
+	spec := managedEnvironment.Spec
+
+	if spec.ClusterCredentialsSecret != "" {
+		return "", fmt.Errorf("ManagedEnvironment argoCDClusterSecret cannot be used with credentialsSecret")
+	}
+	if spec.CreateNewServiceAccount {
+		return "", fmt.Errorf("ManagedEnvironment argoCDClusterSecret cannot be used with createNewServiceAccount")
+	}
+	if _, exists := managedEnvironment.Annotations[managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation]; exists {
+		return "", fmt.Errorf("ManagedEnvironment argoCDClusterSecret cannot be used with the %s annotation",
+			managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation)
+	}
+
+	// The cluster secrets that are generated by the GitOps Service (and 'in-cluster') belong to other managed environments,
+	// and so may not be referenced.
+	if _, _, err := argosharedutil.ConvertArgoCDClusterSecretNameToManagedIdDatabaseRowId(spec.ArgoCDClusterSecret); err == nil {
+		return "", fmt.Errorf("Argo CD cluster secret '%s' is managed by the GitOps Service, and cannot be referenced", spec.ArgoCDClusterSecret)
+	}
+
+	gitopsEngineClient, err := k8sClientFactory.GetK8sClientForGitOpsEngineInstance(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve client for GitOps engine instance: %w", err)
+	}
+
+	clusterSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      spec.ArgoCDClusterSecret,
+			Namespace: dbutil.GetGitOpsEngineSingleInstanceNamespace(),
+		},
+	}
+	if err := gitopsEngineClient.Get(ctx, client.ObjectKeyFromObject(&clusterSecret), &clusterSecret); err != nil {
+		if apierr.IsNotFound(err) {
+			return "", fmt.Errorf("Argo CD cluster secret '%s' does not exist", spec.ArgoCDClusterSecret)
+		}
+		return "", fmt.Errorf("unable to retrieve Argo CD cluster secret '%s': %w", spec.ArgoCDClusterSecret, err)
+	}
+
+	if clusterSecret.Labels[sharedutil.ArgoCDSecretTypeIdentifierKey] != sharedutil.ArgoCDSecretClusterTypeValue {
+		return "", fmt.Errorf("Secret '%s' is not an Argo CD cluster secret", spec.ArgoCDClusterSecret)
+	}
+
+	if clusterSecret.Labels[managedgitopsv1alpha1.ManagedEnvironmentShareableClusterSecretLabel] != "true" {
+		return "", fmt.Errorf("Argo CD cluster secret '%s' does not have the '%s' label, and so cannot be referenced by a ManagedEnvironment",
+			spec.ArgoCDClusterSecret, managedgitopsv1alpha1.ManagedEnvironmentShareableClusterSecretLabel)
+	}
+
+	if server := string(clusterSecret.Data["server"]); !strings.EqualFold(server, spec.APIURL) {
+		return "", fmt.Errorf("the server of Argo CD cluster secret '%s' does not match the API URL of the ManagedEnvironment", spec.ArgoCDClusterSecret)
+	}
+
+	var clusterSecretConfig argosharedutil.ClusterSecretConfigJSON
+	if err := json.Unmarshal(clusterSecret.Data["config"], &clusterSecretConfig); err != nil {
+		return "", fmt.Errorf("unable to parse the config of Argo CD cluster secret '%s': %w", spec.ArgoCDClusterSecret, err)
+	}
+
+	if clusterSecretConfig.BearerToken == "" {
+		return "", fmt.Errorf("Argo CD cluster secret '%s' must contain a bearer token: other forms of authentication are not supported at this time",
+			spec.ArgoCDClusterSecret)
+	}
+
+	return clusterSecretConfig.BearerToken, nil
+}
+
 // locateContextThatMatchesAPIURL examines a kubeconfig (Config struct), and looks for the context that
 // matches the cluster with the given API URL.
 // See 'sharedresourceloop_managedend_test.go' for an example of a kubeconfig.
@@ -959,12 +1597,7 @@ func sanityTestCredentials(clusterCreds db.ClusterCredentials) (*rest.Config, bo
 		return nil, false, fmt.Errorf("cluster credentials is missing service account bearer token")
 	}
 
-	configParam := &rest.Config{
-		Host:        clusterCreds.Host,
-		BearerToken: clusterCreds.Serviceaccount_bearer_token,
-	}
-
-	configParam.Insecure = clusterCreds.AllowInsecureSkipTLSVerify
+	configParam := argosharedutil.GenerateRestConfigForClusterCredentials(clusterCreds)
 
 	configParam.ServerName = ""
 
@@ -1030,6 +1663,15 @@ func createSuccessEnvInitCondition(managedEnvironmentCR managedgitopsv1alpha1.Gi
 	}
 }
 
+func createDisconnectedEnvInitCondition(managedEnvironmentCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) connectionInitializedCondition {
+	return connectionInitializedCondition{
+		managedEnvCR: managedEnvironmentCR,
+		status:       metav1.ConditionFalse,
+		reason:       managedgitopsv1alpha1.ConditionReasonDisconnected,
+		message:      "the managed environment has been disconnected (.spec.disconnected is true): Argo CD will not connect to the cluster until it is reconnected",
+	}
+}
+
 func createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) connectionInitializedCondition {
 	return connectionInitializedCondition{
 		managedEnvCR: managedEnvironmentCR,
@@ -1080,6 +1722,83 @@ func updateManagedEnvironmentConnectionStatus(ctx context.Context,
 	}
 }
 
+// reconcileManagedEnvironmentClusterCapabilities discovers the Kubernetes version and the API groups of the target cluster
+// of the managed environment, and records them in the ManagedEnvironment row, and in the .status of the
+// GitOpsDeploymentManagedEnvironment.
+//
+// Discovery is best effort: on failure, the error is logged, and the previously discovered values are kept.
+func reconcileManagedEnvironmentClusterCapabilities(ctx context.Context, managedEnv *db.ManagedEnvironment,
+	managedEnvironmentCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment, workspaceClient client.Client,
+	k8sClientFactory SRLK8sClientFactory, dbQueries db.DatabaseQueries, log logr.Logger) {
+
+	log = log.WithValues(managedEnv.GetAsLogKeyValues()...)
+
+	clusterCreds := db.ClusterCredentials{Clustercredentials_cred_id: managedEnv.Clustercredentials_id}
+	if err := dbQueries.GetClusterCredentialsById(ctx, &clusterCreds); err != nil {
+		log.Error(err, "unable to retrieve cluster credentials, to discover cluster capabilities")
+		return
+	}
+
+	restConfig, _, err := sanityTestCredentials(clusterCreds)
+	if err != nil {
+		log.Error(err, "unable to discover cluster capabilities using cluster credentials")
+		return
+	}
+
+	capabilities, err := k8sClientFactory.DiscoverClusterCapabilities(restConfig)
+	if err != nil {
+		log.V(logutil.LogLevel_Warn).Info("unable to discover cluster capabilities of managed environment", "error", err.Error())
+		return
+	}
+
+	// Copy the list before sorting it, leaving it nil if the cluster has no (non-core) API groups
+	var apiGroups []string
+	apiGroups = append(apiGroups, capabilities.APIGroups...)
+	sort.Strings(apiGroups)
+
+	apiGroupsField := strings.Join(apiGroups, ",")
+	if len(apiGroupsField) > db.ManagedEnvironmentApiGroupsLength {
+		// Rather than storing a partial list, which would cause false warnings, store that the API groups are unknown.
+		log.V(logutil.LogLevel_Warn).Info("list of API groups of the cluster is too long to store, so it will not be recorded",
+			"length", len(apiGroupsField))
+		apiGroupsField = ""
+		apiGroups = nil
+	}
+
+	kubernetesVersion := capabilities.KubernetesVersion
+	if len(kubernetesVersion) > db.ManagedEnvironmentKubernetesVersionLength {
+		kubernetesVersion = kubernetesVersion[:db.ManagedEnvironmentKubernetesVersionLength]
+	}
+
+	// 1) Update the ManagedEnvironment row, if the capabilities have changed
+	if managedEnv.Kubernetes_version != kubernetesVersion || managedEnv.Api_groups != apiGroupsField {
+		managedEnv.Kubernetes_version = kubernetesVersion
+		managedEnv.Api_groups = apiGroupsField
+
+		if err := dbQueries.UpdateManagedEnvironment(ctx, managedEnv); err != nil {
+			log.Error(err, "unable to update ManagedEnvironment with discovered cluster capabilities")
+			return
+		}
+		log.Info("Updated ManagedEnvironment with discovered cluster capabilities", "kubernetesVersion", kubernetesVersion)
+	}
+
+	// 2) Update the .status of the GitOpsDeploymentManagedEnvironment, if the capabilities have changed
+	if err := workspaceClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvironmentCR), &managedEnvironmentCR); err != nil {
+		log.Error(err, "unable to retrieve managed environment, to update cluster capabilities in status")
+		return
+	}
+
+	if managedEnvironmentCR.Status.KubernetesVersion == kubernetesVersion && reflect.DeepEqual(managedEnvironmentCR.Status.APIGroups, apiGroups) {
+		return
+	}
+
+	managedEnvironmentCR.Status.KubernetesVersion = kubernetesVersion
+	managedEnvironmentCR.Status.APIGroups = apiGroups
+	if err := workspaceClient.Status().Update(ctx, &managedEnvironmentCR); err != nil {
+		log.Error(err, "unable to update managed environment status with discovered cluster capabilities")
+	}
+}
+
 // verifyClusterCredentialsWithNamespaceList returns true if we were able to successfully connect with the credentials, false otherwise.
 func verifyClusterCredentialsWithNamespaceList(ctx context.Context, clusterCreds db.ClusterCredentials, managedEnvCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
 	k8sClientFactory SRLK8sClientFactory) (bool, error) {
@@ -1138,6 +1857,82 @@ func convertManagedEnvNamespacesFieldToCommaSeparatedList(namespaces []string) (
 
 }
 
+// convertManagedEnvServiceAccountAnnotationToServiceAccount returns the namespace and name of the ServiceAccount of the
+// 'managed-gitops.redhat.com/service-account' annotation of the managed environment, or empty strings if it is not set.
+func convertManagedEnvServiceAccountAnnotationToServiceAccount(managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) (string, string, error) {
+
+	serviceAccount, exists := managedEnvironment.Annotations[managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation]
+	if !exists {
+		return "", "", nil
+	}
+
+	return managedgitopsv1alpha1.ParseManagedEnvironmentServiceAccountAnnotation(serviceAccount)
+}
+
+// convertManagedEnvClusterLabelsToManagedEnvironmentField converts the .spec.clusterLabels field to the corresponding
+// ManagedEnvironment field: a copy of the labels, or nil if there are none (so that nil and empty labels are stored identically).
+func convertManagedEnvClusterLabelsToManagedEnvironmentField(spec managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec) map[string]string {
+
+	if len(spec.ClusterLabels) == 0 {
+		return nil
+	}
+
+	res := make(map[string]string, len(spec.ClusterLabels))
+	for key, value := range spec.ClusterLabels {
+		res[key] = value
+	}
+
+	return res
+}
+
+// convertManagedEnvNamespaceQuotaToClusterCredentialsFields converts the .spec.namespaceQuota field to the corresponding
+// ClusterCredentials fields: the CPU and memory quantities, in canonical form, or "" if not set.
+func convertManagedEnvNamespaceQuotaToClusterCredentialsFields(spec managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec) (string, string, error) {
+
+	if spec.NamespaceQuota == nil {
+		return "", "", nil
+	}
+
+	cpu, err := canonicalizeNamespaceQuotaValue("cpu", spec.NamespaceQuota.CPU)
+	if err != nil {
+		return "", "", err
+	}
+
+	memory, err := canonicalizeNamespaceQuotaValue("memory", spec.NamespaceQuota.Memory)
+	if err != nil {
+		return "", "", err
+	}
+
+	return cpu, memory, nil
+}
+
+// canonicalizeNamespaceQuotaValue returns the canonical form of a namespace quota quantity (so that equivalent values,
+// such as '1000m' and '1', are stored identically), or "" if the value is empty.
+func canonicalizeNamespaceQuotaValue(fieldName string, value string) (string, error) {
+
+	if value == "" {
+		return "", nil
+	}
+
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil || quantity.Sign() <= 0 {
+		return "", fmt.Errorf("ManagedEnvironment contains an invalid namespace quota %s value: '%s'", fieldName, value)
+	}
+
+	return quantity.String(), nil
+}
+
+// convertManagedEnvRotateCredentialsRequestedAtToClusterCredentialsField converts the .spec.rotateCredentialsRequestedAt field
+// to the value of the corresponding ClusterCredentials field: the time in RFC3339 format, or "" if not set.
+func convertManagedEnvRotateCredentialsRequestedAtToClusterCredentialsField(spec managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec) string {
+
+	if spec.RotateCredentialsRequestedAt == nil {
+		return ""
+	}
+
+	return spec.RotateCredentialsRequestedAt.UTC().Format(time.RFC3339)
+}
+
 // A namespace is valid if it conforms to RFC 1123 DNS label standard
 func isValidNamespaceName(namespaceName string) bool {
 	return len(validation.IsDNS1123Label(namespaceName)) == 0
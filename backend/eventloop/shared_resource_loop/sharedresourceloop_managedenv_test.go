@@ -378,6 +378,54 @@ var _ = Describe("SharedResourceEventLoop ManagedEnvironment-related Test", func
 			Expect(managedEnv.Status.Conditions[0].Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonSucceeded)))
 		})
 
+		It("should record the Kubernetes version and API groups of the cluster, in the database and in the managed environment status", func() {
+			managedEnv, secret := buildManagedEnvironmentForSRL()
+			managedEnv.UID = "test-" + uuid.NewUUID()
+			secret.UID = "test-" + uuid.NewUUID()
+			eventloop_test_util.StartServiceAccountListenerOnFakeClient(ctx, string(managedEnv.UID), k8sClient)
+
+			err := k8sClient.Create(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			mockFactory.capabilities = ClusterCapabilities{
+				KubernetesVersion: "v1.25.3",
+				APIGroups:         []string{"route.openshift.io", "apps", "batch"},
+			}
+
+			By("calling ReconcileSharedManagedEnv")
+			src, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(src.ManagedEnv).To(Not(BeNil()))
+
+			By("verifying the capabilities are recorded in the ManagedEnvironment row")
+			managedEnvRow := db.ManagedEnvironment{Managedenvironment_id: src.ManagedEnv.Managedenvironment_id}
+			err = dbQueries.GetManagedEnvironmentById(ctx, &managedEnvRow)
+			Expect(err).To(BeNil())
+			Expect(managedEnvRow.Kubernetes_version).To(Equal("v1.25.3"))
+			Expect(managedEnvRow.Api_groups).To(Equal("apps,batch,route.openshift.io"))
+
+			By("verifying the capabilities are recorded in the managed environment status")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(err).To(BeNil())
+			Expect(managedEnv.Status.KubernetesVersion).To(Equal("v1.25.3"))
+			Expect(managedEnv.Status.APIGroups).To(Equal([]string{"apps", "batch", "route.openshift.io"}))
+
+			By("verifying the previously discovered capabilities are kept, if discovery fails")
+			mockFactory.capabilitiesErr = fmt.Errorf("simulated discovery failure")
+			_, err = internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+
+			err = dbQueries.GetManagedEnvironmentById(ctx, &managedEnvRow)
+			Expect(err).To(BeNil())
+			Expect(managedEnvRow.Kubernetes_version).To(Equal("v1.25.3"))
+			Expect(managedEnvRow.Api_groups).To(Equal("apps,batch,route.openshift.io"))
+		})
+
 		It("should set the condition ConnectionInitializationSucceeded status to False when the connection fails for new environment", func() {
 			managedEnv, secret := buildManagedEnvironmentForSRL()
 			managedEnv.UID = "test-" + uuid.NewUUID()
@@ -828,6 +876,566 @@ var _ = Describe("SharedResourceEventLoop ManagedEnvironment-related Test", func
 
 		})
 
+		It("should rotate the cluster credentials, and create an operation to update the Argo CD cluster secret, when .spec.rotateCredentialsRequestedAt is updated", func() {
+
+			_, _, engineCluster, _, _, err := db.CreateSampleData(dbQueries)
+			Expect(err).To(BeNil())
+			instance := &db.GitopsEngineInstance{
+				Gitopsengineinstance_id: "test-fake-instance-id",
+				Namespace_name:          "gitops-service-argocd",
+				Namespace_uid:           "test-fake-instance-namespace-914",
+				EngineCluster_id:        engineCluster.Gitopsenginecluster_id,
+			}
+			err = dbQueries.CreateGitopsEngineInstance(ctx, instance)
+			Expect(err).To(BeNil())
+
+			managedEnv, secret := buildManagedEnvironmentForSRL()
+			managedEnv.UID = "test-" + uuid.NewUUID()
+			secret.UID = "test-" + uuid.NewUUID()
+			eventloop_test_util.StartServiceAccountListenerOnFakeClient(ctx, string(managedEnv.UID), k8sClient)
+
+			err = k8sClient.Create(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			By("calling reconcile to create database entries for new managed env")
+			createRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(createRC.ManagedEnv).ToNot(BeNil())
+			oldClusterCredentialsID := createRC.ManagedEnv.Clustercredentials_id
+
+			applicationRow := &db.Application{
+				Application_id:          "test-fake-application-id",
+				Spec_field:              "{}",
+				Name:                    "app-name",
+				Engine_instance_inst_id: instance.Gitopsengineinstance_id,
+				Managed_environment_id:  createRC.ManagedEnv.Managedenvironment_id,
+			}
+			err = dbQueries.CreateApplication(ctx, applicationRow)
+			Expect(err).To(BeNil())
+
+			By("requesting a credential rotation via .spec.rotateCredentialsRequestedAt")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(err).To(BeNil())
+
+			rotateCredentialsRequestedAt := metav1.NewTime(time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC))
+			managedEnv.Spec.RotateCredentialsRequestedAt = &rotateCredentialsRequestedAt
+
+			err = k8sClient.Update(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			rotateRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(rotateRC.ManagedEnv).ToNot(BeNil())
+			Expect(rotateRC.ManagedEnv.Managedenvironment_id).To(Equal(createRC.ManagedEnv.Managedenvironment_id))
+			Expect(rotateRC.ManagedEnv.Clustercredentials_id).ToNot(Equal(oldClusterCredentialsID))
+
+			By("ensuring the old cluster credentials were replaced by new ones, that record the rotation request")
+			oldClusterCredentials := &db.ClusterCredentials{Clustercredentials_cred_id: oldClusterCredentialsID}
+			Expect(db.IsResultNotFoundError(dbQueries.GetClusterCredentialsById(ctx, oldClusterCredentials))).To(BeTrue())
+
+			clusterCredentials := &db.ClusterCredentials{Clustercredentials_cred_id: rotateRC.ManagedEnv.Clustercredentials_id}
+			err = dbQueries.GetClusterCredentialsById(ctx, clusterCredentials)
+			Expect(err).To(BeNil())
+			Expect(clusterCredentials.Rotation_requested_at).To(Equal("2023-03-01T12:00:00Z"))
+
+			By("ensuring a ManagedEnvironment Operation was created, so that the Argo CD cluster secret is updated, and the Application was not modified")
+			managedEnvOperations := getAllOperationsForResourceID(ctx, rotateRC.ManagedEnv.Managedenvironment_id, dbQueries)
+			Expect(managedEnvOperations).To(HaveLen(1))
+			Expect(managedEnvOperations[0].Resource_type).To(Equal(db.OperationResourceType_ManagedEnvironment))
+			Expect(managedEnvOperations[0].Instance_id).To(Equal(instance.Gitopsengineinstance_id))
+			err = verifyOperationCRsExist(ctx, managedEnvOperations, k8sClient)
+			Expect(err).To(BeNil())
+			Expect(getAllOperationsForResourceID(ctx, applicationRow.Application_id, dbQueries)).To(BeEmpty())
+
+			By("calling reconcile again, and ensuring the credentials are not rotated a second time")
+			secondRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(secondRC.ManagedEnv).ToNot(BeNil())
+			Expect(secondRC.ManagedEnv.Clustercredentials_id).To(Equal(rotateRC.ManagedEnv.Clustercredentials_id))
+			Expect(getAllOperationsForResourceID(ctx, rotateRC.ManagedEnv.Managedenvironment_id, dbQueries)).To(HaveLen(1))
+		})
+
+		It("should rotate the cluster credentials, and create an operation to update the Argo CD cluster secret, when the data of the Secret changes", func() {
+
+			_, _, engineCluster, _, _, err := db.CreateSampleData(dbQueries)
+			Expect(err).To(BeNil())
+			instance := &db.GitopsEngineInstance{
+				Gitopsengineinstance_id: "test-fake-instance-id",
+				Namespace_name:          "gitops-service-argocd",
+				Namespace_uid:           "test-fake-instance-namespace-914",
+				EngineCluster_id:        engineCluster.Gitopsenginecluster_id,
+			}
+			err = dbQueries.CreateGitopsEngineInstance(ctx, instance)
+			Expect(err).To(BeNil())
+
+			managedEnv, secret := buildManagedEnvironmentForSRL()
+			managedEnv.UID = "test-" + uuid.NewUUID()
+			secret.UID = "test-" + uuid.NewUUID()
+			eventloop_test_util.StartServiceAccountListenerOnFakeClient(ctx, string(managedEnv.UID), k8sClient)
+
+			err = k8sClient.Create(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			By("calling reconcile to create database entries for new managed env, and ensuring the hash of the Secret is recorded")
+			createRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(createRC.ManagedEnv).ToNot(BeNil())
+
+			clusterCredentials := &db.ClusterCredentials{Clustercredentials_cred_id: createRC.ManagedEnv.Clustercredentials_id}
+			err = dbQueries.GetClusterCredentialsById(ctx, clusterCredentials)
+			Expect(err).To(BeNil())
+			Expect(clusterCredentials.Secret_data_hash).To(Equal(sharedutil.HashSecretData(secret.Data)))
+
+			applicationRow := &db.Application{
+				Application_id:          "test-fake-application-id",
+				Spec_field:              "{}",
+				Name:                    "app-name",
+				Engine_instance_inst_id: instance.Gitopsengineinstance_id,
+				Managed_environment_id:  createRC.ManagedEnv.Managedenvironment_id,
+			}
+			err = dbQueries.CreateApplication(ctx, applicationRow)
+			Expect(err).To(BeNil())
+
+			By("calling reconcile with an unchanged Secret, and ensuring the credentials are not rotated")
+			unchangedRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(unchangedRC.ManagedEnv.Clustercredentials_id).To(Equal(createRC.ManagedEnv.Clustercredentials_id))
+			Expect(getAllOperationsForResourceID(ctx, createRC.ManagedEnv.Managedenvironment_id, dbQueries)).To(BeEmpty())
+
+			By("simulating a refresh of the token in the Secret")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret)
+			Expect(err).To(BeNil())
+			secret.Data["refreshed-at"] = []byte("2023-03-01T12:00:00Z")
+			err = k8sClient.Update(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			rotateRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(rotateRC.ManagedEnv).ToNot(BeNil())
+			Expect(rotateRC.ManagedEnv.Clustercredentials_id).ToNot(Equal(createRC.ManagedEnv.Clustercredentials_id))
+
+			clusterCredentials = &db.ClusterCredentials{Clustercredentials_cred_id: rotateRC.ManagedEnv.Clustercredentials_id}
+			err = dbQueries.GetClusterCredentialsById(ctx, clusterCredentials)
+			Expect(err).To(BeNil())
+			Expect(clusterCredentials.Secret_data_hash).To(Equal(sharedutil.HashSecretData(secret.Data)))
+
+			By("ensuring a single ManagedEnvironment Operation was created to update the Argo CD cluster secret")
+			managedEnvOperations := getAllOperationsForResourceID(ctx, rotateRC.ManagedEnv.Managedenvironment_id, dbQueries)
+			Expect(managedEnvOperations).To(HaveLen(1))
+			Expect(managedEnvOperations[0].Resource_type).To(Equal(db.OperationResourceType_ManagedEnvironment))
+			Expect(getAllOperationsForResourceID(ctx, applicationRow.Application_id, dbQueries)).To(BeEmpty())
+		})
+
+		It("should remove the cluster secret of a managed environment, without modifying its Applications, when .spec.disconnected is set, and restore it when unset", func() {
+
+			_, _, engineCluster, _, _, err := db.CreateSampleData(dbQueries)
+			Expect(err).To(BeNil())
+			instance := &db.GitopsEngineInstance{
+				Gitopsengineinstance_id: "test-fake-instance-id",
+				Namespace_name:          "gitops-service-argocd",
+				Namespace_uid:           "test-fake-instance-namespace-914",
+				EngineCluster_id:        engineCluster.Gitopsenginecluster_id,
+			}
+			err = dbQueries.CreateGitopsEngineInstance(ctx, instance)
+			Expect(err).To(BeNil())
+
+			managedEnv, secret := buildManagedEnvironmentForSRL()
+			managedEnv.UID = "test-" + uuid.NewUUID()
+			secret.UID = "test-" + uuid.NewUUID()
+			eventloop_test_util.StartServiceAccountListenerOnFakeClient(ctx, string(managedEnv.UID), k8sClient)
+
+			err = k8sClient.Create(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			By("calling reconcile to create database entries for new managed env")
+			createRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(createRC.ManagedEnv).ToNot(BeNil())
+			Expect(createRC.ManagedEnv.Disconnected).To(BeFalse())
+
+			applicationRow := &db.Application{
+				Application_id:          "test-fake-application-id",
+				Spec_field:              "{}",
+				Name:                    "app-name",
+				Engine_instance_inst_id: instance.Gitopsengineinstance_id,
+				Managed_environment_id:  createRC.ManagedEnv.Managedenvironment_id,
+			}
+			err = dbQueries.CreateApplication(ctx, applicationRow)
+			Expect(err).To(BeNil())
+
+			By("disconnecting the managed environment via .spec.disconnected")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(err).To(BeNil())
+			managedEnv.Spec.Disconnected = true
+			err = k8sClient.Update(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			disconnectRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(disconnectRC.ManagedEnv).ToNot(BeNil())
+			Expect(disconnectRC.ManagedEnv.Managedenvironment_id).To(Equal(createRC.ManagedEnv.Managedenvironment_id))
+
+			managedEnvRow := &db.ManagedEnvironment{Managedenvironment_id: createRC.ManagedEnv.Managedenvironment_id}
+			err = dbQueries.GetManagedEnvironmentById(ctx, managedEnvRow)
+			Expect(err).To(BeNil())
+			Expect(managedEnvRow.Disconnected).To(BeTrue())
+
+			By("ensuring an Operation was created to remove the Argo CD cluster secret, and the Application was not modified")
+			managedEnvOperations := getAllOperationsForResourceID(ctx, managedEnvRow.Managedenvironment_id, dbQueries)
+			Expect(managedEnvOperations).To(HaveLen(1))
+			Expect(managedEnvOperations[0].Resource_type).To(Equal(db.OperationResourceType_ManagedEnvironment))
+			err = verifyOperationCRsExist(ctx, managedEnvOperations, k8sClient)
+			Expect(err).To(BeNil())
+
+			err = dbQueries.GetApplicationById(ctx, applicationRow)
+			Expect(err).To(BeNil())
+			Expect(getAllOperationsForResourceID(ctx, applicationRow.Application_id, dbQueries)).To(BeEmpty())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(err).To(BeNil())
+			Expect(managedEnv.Status.Conditions).To(HaveLen(1))
+			Expect(managedEnv.Status.Conditions[0].Status).To(Equal(metav1.ConditionFalse))
+			Expect(managedEnv.Status.Conditions[0].Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonDisconnected)))
+
+			By("calling reconcile again, and ensuring a second Operation is not created")
+			_, err = internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(getAllOperationsForResourceID(ctx, managedEnvRow.Managedenvironment_id, dbQueries)).To(HaveLen(1))
+
+			By("simulating the completion of the Operation by the cluster-agent")
+			managedEnvOperations[0].State = db.OperationState_Completed
+			err = dbQueries.UpdateOperation(ctx, &managedEnvOperations[0])
+			Expect(err).To(BeNil())
+
+			By("reconnecting the managed environment, by unsetting .spec.disconnected")
+			managedEnv.Spec.Disconnected = false
+			err = k8sClient.Update(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			reconnectRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(reconnectRC.ManagedEnv).ToNot(BeNil())
+			Expect(reconnectRC.ManagedEnv.Disconnected).To(BeFalse())
+			Expect(reconnectRC.ManagedEnv.Clustercredentials_id).ToNot(Equal(createRC.ManagedEnv.Clustercredentials_id))
+
+			err = dbQueries.GetManagedEnvironmentById(ctx, managedEnvRow)
+			Expect(err).To(BeNil())
+			Expect(managedEnvRow.Disconnected).To(BeFalse())
+
+			By("ensuring a second ManagedEnvironment Operation was created, so that the Argo CD cluster secret is recreated")
+			managedEnvOperations = getAllOperationsForResourceID(ctx, managedEnvRow.Managedenvironment_id, dbQueries)
+			Expect(managedEnvOperations).To(HaveLen(2))
+			err = verifyOperationCRsExist(ctx, managedEnvOperations, k8sClient)
+			Expect(err).To(BeNil())
+			Expect(getAllOperationsForResourceID(ctx, applicationRow.Application_id, dbQueries)).To(BeEmpty())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(err).To(BeNil())
+			Expect(managedEnv.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should store the .spec.clusterLabels of a managed environment in the database, and update them when they change", func() {
+
+			managedEnv, secret := buildManagedEnvironmentForSRL()
+			managedEnv.UID = "test-" + uuid.NewUUID()
+			secret.UID = "test-" + uuid.NewUUID()
+			managedEnv.Spec.ClusterLabels = map[string]string{
+				managedgitopsv1alpha1.ManagedEnvironmentClusterLabelRegion: "us-east-1",
+				managedgitopsv1alpha1.ManagedEnvironmentClusterLabelCloud:  "aws",
+			}
+			eventloop_test_util.StartServiceAccountListenerOnFakeClient(ctx, string(managedEnv.UID), k8sClient)
+
+			err := k8sClient.Create(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			By("calling reconcile to create database entries for new managed env")
+			createRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(createRC.ManagedEnv).ToNot(BeNil())
+
+			managedEnvRow := &db.ManagedEnvironment{Managedenvironment_id: createRC.ManagedEnv.Managedenvironment_id}
+			err = dbQueries.GetManagedEnvironmentById(ctx, managedEnvRow)
+			Expect(err).To(BeNil())
+			Expect(managedEnvRow.Cluster_labels).To(Equal(managedEnv.Spec.ClusterLabels))
+
+			By("updating the cluster labels, and ensuring the managed environment is updated in place")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(err).To(BeNil())
+			managedEnv.Spec.ClusterLabels = map[string]string{
+				managedgitopsv1alpha1.ManagedEnvironmentClusterLabelRegion: "eu-west-1",
+				managedgitopsv1alpha1.ManagedEnvironmentClusterLabelTier:   "production",
+			}
+			err = k8sClient.Update(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			updateRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+			Expect(updateRC.ManagedEnv).ToNot(BeNil())
+			Expect(updateRC.ManagedEnv.Managedenvironment_id).To(Equal(createRC.ManagedEnv.Managedenvironment_id))
+			Expect(updateRC.ManagedEnv.Clustercredentials_id).To(Equal(createRC.ManagedEnv.Clustercredentials_id))
+
+			err = dbQueries.GetManagedEnvironmentById(ctx, managedEnvRow)
+			Expect(err).To(BeNil())
+			Expect(managedEnvRow.Cluster_labels).To(Equal(managedEnv.Spec.ClusterLabels))
+
+			By("removing the cluster labels, and ensuring they are removed from the database")
+			managedEnv.Spec.ClusterLabels = nil
+			err = k8sClient.Update(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			_, err = internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).To(BeNil())
+
+			err = dbQueries.GetManagedEnvironmentById(ctx, managedEnvRow)
+			Expect(err).To(BeNil())
+			Expect(managedEnvRow.Cluster_labels).To(BeEmpty())
+		})
+
+		It("should set an InvalidClusterLabels condition, and not create a managed environment, when .spec.clusterLabels is invalid", func() {
+
+			managedEnv, secret := buildManagedEnvironmentForSRL()
+			managedEnv.Spec.ClusterLabels = map[string]string{
+				managedgitopsv1alpha1.ManagedEnvironmentClusterLabelTier: "not a valid label value",
+			}
+			managedEnv.UID = "test-" + uuid.NewUUID()
+			secret.UID = "test-" + uuid.NewUUID()
+
+			err := k8sClient.Create(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			rc, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).ToNot(BeNil())
+			Expect(rc.ManagedEnv).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(err).To(BeNil())
+			Expect(managedEnv.Status.Conditions).To(HaveLen(1))
+			Expect(managedEnv.Status.Conditions[0].Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonInvalidClusterLabels)))
+		})
+
+		It("should set an InvalidServiceAccountAnnotation condition, and not create a managed environment, when the service account annotation is invalid", func() {
+
+			managedEnv, secret := buildManagedEnvironmentForSRL()
+			managedEnv.Annotations = map[string]string{
+				managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation: "my-namespace/my-deployer/invalid",
+			}
+			managedEnv.UID = "test-" + uuid.NewUUID()
+			secret.UID = "test-" + uuid.NewUUID()
+
+			err := k8sClient.Create(ctx, &managedEnv)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Create(ctx, &secret)
+			Expect(err).To(BeNil())
+
+			rc, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+				false, *namespace, mockFactory, dbQueries, log)
+			Expect(err).ToNot(BeNil())
+			Expect(rc.ManagedEnv).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(err).To(BeNil())
+			Expect(managedEnv.Status.Conditions).To(HaveLen(1))
+			Expect(managedEnv.Status.Conditions[0].Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonInvalidServiceAccountAnnotation)))
+		})
+
+		DescribeTable("Tests convertManagedEnvServiceAccountAnnotationToServiceAccount",
+			func(annotations map[string]string, expectedNamespace string, expectedName string, expectError bool) {
+
+				managedEnv := managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+					ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+				}
+
+				namespace, name, err := convertManagedEnvServiceAccountAnnotationToServiceAccount(managedEnv)
+				if expectError {
+					Expect(err).ToNot(BeNil())
+					return
+				}
+				Expect(err).To(BeNil())
+				Expect(namespace).To(Equal(expectedNamespace))
+				Expect(name).To(Equal(expectedName))
+			},
+			Entry("no annotation", nil, "", "", false),
+			Entry("name only, which defaults to kube-system",
+				map[string]string{managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation: "my-deployer"}, "kube-system", "my-deployer", false),
+			Entry("namespace and name",
+				map[string]string{managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation: "my-namespace/my-deployer"}, "my-namespace", "my-deployer", false),
+			Entry("empty name",
+				map[string]string{managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation: "my-namespace/"}, "", "", true),
+			Entry("invalid namespace",
+				map[string]string{managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation: "My_Namespace/my-deployer"}, "", "", true),
+			Entry("too many segments",
+				map[string]string{managedgitopsv1alpha1.ManagedEnvironmentServiceAccountAnnotation: "my-namespace/my-deployer/invalid"}, "", "", true),
+		)
+
+		DescribeTable("Tests convertManagedEnvNamespaceQuotaToClusterCredentialsFields",
+			func(namespaceQuota *managedgitopsv1alpha1.ManagedEnvironmentNamespaceQuota, expectedCPU string, expectedMemory string, expectError bool) {
+
+				cpu, memory, err := convertManagedEnvNamespaceQuotaToClusterCredentialsFields(managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec{
+					NamespaceQuota: namespaceQuota,
+				})
+				if expectError {
+					Expect(err).ToNot(BeNil())
+					return
+				}
+				Expect(err).To(BeNil())
+				Expect(cpu).To(Equal(expectedCPU))
+				Expect(memory).To(Equal(expectedMemory))
+			},
+			Entry("no namespace quota", nil, "", "", false),
+			Entry("cpu and memory", &managedgitopsv1alpha1.ManagedEnvironmentNamespaceQuota{CPU: "500m", Memory: "1Gi"}, "500m", "1Gi", false),
+			Entry("equivalent values are canonicalized", &managedgitopsv1alpha1.ManagedEnvironmentNamespaceQuota{CPU: "2000m"}, "2", "", false),
+			Entry("invalid quantity", &managedgitopsv1alpha1.ManagedEnvironmentNamespaceQuota{Memory: "lots"}, "", "", true),
+			Entry("negative quantity", &managedgitopsv1alpha1.ManagedEnvironmentNamespaceQuota{CPU: "-1"}, "", "", true),
+		)
+
+		Context("ManagedEnvironments that reference an existing Argo CD cluster secret", func() {
+
+			const apiURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+
+			var argoCDClusterSecret *corev1.Secret
+			var managedEnv *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment
+
+			BeforeEach(func() {
+				argoCDClusterSecret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-cluster-added-by-argocd-cli",
+						Namespace: dbutil.GetGitOpsEngineSingleInstanceNamespace(),
+						Labels: map[string]string{
+							sharedutil.ArgoCDSecretTypeIdentifierKey:                            sharedutil.ArgoCDSecretClusterTypeValue,
+							managedgitopsv1alpha1.ManagedEnvironmentShareableClusterSecretLabel: "true",
+						},
+					},
+					Data: map[string][]byte{
+						"name":   ([]byte)("my-cluster"),
+						"server": ([]byte)(apiURL),
+						"config": ([]byte)(`{"bearerToken":"my-bearer-token","tlsClientConfig":{"insecure":false}}`),
+					},
+				}
+
+				managedEnv = &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-my-managed-env",
+						Namespace: "test-k8s-namespace",
+						UID:       "test-" + uuid.NewUUID(),
+					},
+					Spec: managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec{
+						APIURL:              apiURL,
+						ArgoCDClusterSecret: argoCDClusterSecret.Name,
+					},
+				}
+			})
+
+			It("should create ClusterCredentials from the bearer token of the Argo CD cluster secret", func() {
+
+				err := k8sClient.Create(ctx, argoCDClusterSecret)
+				Expect(err).To(BeNil())
+
+				err = k8sClient.Create(ctx, managedEnv)
+				Expect(err).To(BeNil())
+
+				By("calling reconcile to create database entries for new managed env")
+				createRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+					false, *namespace, mockFactory, dbQueries, log)
+				Expect(err).To(BeNil())
+				Expect(createRC.ManagedEnv).ToNot(BeNil())
+
+				clusterCredentials := db.ClusterCredentials{
+					Clustercredentials_cred_id: createRC.ManagedEnv.Clustercredentials_id,
+				}
+				err = dbQueries.GetClusterCredentialsById(ctx, &clusterCredentials)
+				Expect(err).To(BeNil())
+				Expect(clusterCredentials.Host).To(Equal(apiURL))
+				Expect(clusterCredentials.Serviceaccount_bearer_token).To(Equal("my-bearer-token"))
+				Expect(clusterCredentials.Argocd_cluster_secret).To(Equal(argoCDClusterSecret.Name))
+
+				By("verifying the Argo CD cluster secret is not modified")
+				clusterSecret := &corev1.Secret{}
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(argoCDClusterSecret), clusterSecret)
+				Expect(err).To(BeNil())
+				Expect(clusterSecret.Data).To(Equal(argoCDClusterSecret.Data))
+
+				By("calling reconcile again, and verifying the existing ClusterCredentials are reused")
+				secondRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+					false, *namespace, mockFactory, dbQueries, log)
+				Expect(err).To(BeNil())
+				Expect(secondRC.ManagedEnv.Clustercredentials_id).To(Equal(createRC.ManagedEnv.Clustercredentials_id))
+			})
+
+			DescribeTable("should not create ClusterCredentials for an Argo CD cluster secret that cannot be referenced",
+				func(modifyResources func(*corev1.Secret, *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment), expectedError string) {
+
+					modifyResources(argoCDClusterSecret, managedEnv)
+
+					err := k8sClient.Create(ctx, argoCDClusterSecret)
+					Expect(err).To(BeNil())
+
+					err = k8sClient.Create(ctx, managedEnv)
+					Expect(err).To(BeNil())
+
+					createRC, err := internalProcessMessage_ReconcileSharedManagedEnv(ctx, k8sClient, managedEnv.Name, managedEnv.Namespace,
+						false, *namespace, mockFactory, dbQueries, log)
+					Expect(createRC.ManagedEnv).To(BeNil())
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(ContainSubstring(expectedError))
+
+					By("verifying the error is reported in the status of the managed environment")
+					err = k8sClient.Get(ctx, client.ObjectKeyFromObject(managedEnv), managedEnv)
+					Expect(err).To(BeNil())
+					Expect(managedEnv.Status.Conditions).To(HaveLen(1))
+					Expect(managedEnv.Status.Conditions[0].Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonInvalidArgoCDClusterSecret)))
+				},
+				Entry("missing the shareable label", func(secret *corev1.Secret, _ *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) {
+					delete(secret.Labels, managedgitopsv1alpha1.ManagedEnvironmentShareableClusterSecretLabel)
+				}, "does not have the 'managed-gitops.redhat.com/shareable-cluster-secret' label"),
+				Entry("not an Argo CD cluster secret", func(secret *corev1.Secret, _ *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) {
+					delete(secret.Labels, sharedutil.ArgoCDSecretTypeIdentifierKey)
+				}, "is not an Argo CD cluster secret"),
+				Entry("generated by the GitOps Service", func(secret *corev1.Secret, managedEnv *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) {
+					secret.Name = "managed-env-test-some-other-managed-env"
+					managedEnv.Spec.ArgoCDClusterSecret = secret.Name
+				}, "is managed by the GitOps Service"),
+				Entry("server that does not match the API URL", func(secret *corev1.Secret, _ *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) {
+					secret.Data["server"] = ([]byte)("https://api.some-other-cluster.com:6443")
+				}, "does not match the API URL"),
+				Entry("no bearer token", func(secret *corev1.Secret, _ *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) {
+					secret.Data["config"] = ([]byte)(`{"tlsClientConfig":{"insecure":false}}`)
+				}, "must contain a bearer token"),
+				Entry("used with createNewServiceAccount", func(_ *corev1.Secret, managedEnv *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) {
+					managedEnv.Spec.CreateNewServiceAccount = true
+				}, "cannot be used with createNewServiceAccount"),
+			)
+		})
+
 		It("should produce a useful error message if the user in the kubeconfig doesn't have a token", func() {
 			By("creating ManagedEnvironment/Secret, without creating a new ServiceAccount")
 
@@ -931,6 +1539,99 @@ var _ = Describe("SharedResourceEventLoop ManagedEnvironment-related Test", func
 		)
 	})
 
+	Context("Test reconcileManagedEnvironmentDeletionProtection", func() {
+
+		var ctx context.Context
+		var k8sClient client.WithWatch
+		var log logr.Logger
+		var managedEnv managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			log = logf.FromContext(ctx)
+
+			scheme, argocdNamespace, kubesystemNamespace, namespace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			managedEnv, _ = buildManagedEnvironmentForSRL()
+			managedEnv.Namespace = namespace.Name
+			managedEnv.Spec.DeletionProtection = true
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, argocdNamespace, kubesystemNamespace, &managedEnv).
+				Build()
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+		})
+
+		It("should add the finalizer when deletion protection is enabled, and remove it when disabled", func() {
+
+			blocked, err := reconcileManagedEnvironmentDeletionProtection(ctx, &managedEnv, k8sClient, log)
+			Expect(err).To(BeNil())
+			Expect(blocked).To(BeFalse())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+			Expect(managedEnv.Finalizers).To(ContainElement(managedgitopsv1alpha1.ManagedEnvironmentDeletionProtectionFinalizer))
+
+			managedEnv.Spec.DeletionProtection = false
+			Expect(k8sClient.Update(ctx, &managedEnv)).To(Succeed())
+
+			blocked, err = reconcileManagedEnvironmentDeletionProtection(ctx, &managedEnv, k8sClient, log)
+			Expect(err).To(BeNil())
+			Expect(blocked).To(BeFalse())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+			Expect(managedEnv.Finalizers).ToNot(ContainElement(managedgitopsv1alpha1.ManagedEnvironmentDeletionProtectionFinalizer))
+		})
+
+		It("should block deletion while GitOpsDeployments target the managed environment, and allow it once they are deleted", func() {
+
+			_, err := reconcileManagedEnvironmentDeletionProtection(ctx, &managedEnv, k8sClient, log)
+			Expect(err).To(BeNil())
+
+			gitopsDepl := managedgitopsv1alpha1.GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-gitops-depl",
+					Namespace: managedEnv.Namespace,
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentSpec{
+					Destination: managedgitopsv1alpha1.ApplicationDestination{
+						Environment: managedEnv.Name,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &gitopsDepl)).To(Succeed())
+
+			By("deleting the managed environment, which should be blocked by the GitOpsDeployment")
+			Expect(k8sClient.Delete(ctx, &managedEnv)).To(Succeed())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+			Expect(managedEnv.DeletionTimestamp).ToNot(BeNil())
+
+			blocked, err := reconcileManagedEnvironmentDeletionProtection(ctx, &managedEnv, k8sClient, log)
+			Expect(err).To(BeNil())
+			Expect(blocked).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)).To(Succeed())
+			Expect(managedEnv.Status.Conditions).To(HaveLen(1))
+			condition := managedEnv.Status.Conditions[0]
+			Expect(condition.Type).To(Equal(managedgitopsv1alpha1.ManagedEnvironmentStatusDeletionBlocked))
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonTargetedByGitOpsDeployments)))
+			Expect(condition.Message).To(ContainSubstring(gitopsDepl.Name))
+
+			By("deleting the GitOpsDeployment, which should unblock deletion of the managed environment")
+			Expect(k8sClient.Delete(ctx, &gitopsDepl)).To(Succeed())
+
+			blocked, err = reconcileManagedEnvironmentDeletionProtection(ctx, &managedEnv, k8sClient, log)
+			Expect(err).To(BeNil())
+			Expect(blocked).To(BeFalse())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnv), &managedEnv)
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
 	Context("Unit tests for individual pure functions", func() {
 
 		DescribeTable("Verify that isValidNamespaceName conforms to K8s namespace requirements",
@@ -1007,6 +1708,10 @@ func getAllOperationsForResourceID(ctx context.Context, resourceID string, dbQue
 
 type MockSRLK8sClientFactory struct {
 	fakeClient client.Client
+
+	// capabilities are returned by DiscoverClusterCapabilities, unless capabilitiesErr is set
+	capabilities    ClusterCapabilities
+	capabilitiesErr error
 }
 
 func (f MockSRLK8sClientFactory) BuildK8sClient(restConfig *rest.Config) (client.Client, error) {
@@ -1021,6 +1726,10 @@ func (f MockSRLK8sClientFactory) GetK8sClientForServiceWorkspace() (client.Clien
 	return f.fakeClient, nil
 }
 
+func (f MockSRLK8sClientFactory) DiscoverClusterCapabilities(restConfig *rest.Config) (ClusterCapabilities, error) {
+	return f.capabilities, f.capabilitiesErr
+}
+
 type SimulateFailingClientMockSRLK8sClientFactory struct {
 	limit          int
 	count          int
@@ -1045,6 +1754,10 @@ func (f *SimulateFailingClientMockSRLK8sClientFactory) GetK8sClientForServiceWor
 	return f.realFakeClient, nil
 }
 
+func (f *SimulateFailingClientMockSRLK8sClientFactory) DiscoverClusterCapabilities(restConfig *rest.Config) (ClusterCapabilities, error) {
+	return ClusterCapabilities{}, nil
+}
+
 // Build a managed environment object for shared resource loop (SRL) test
 func buildManagedEnvironmentForSRL() (managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment, corev1.Secret) {
 	return buildManagedEnvironmentForSRLWithOptionalSA(true)
@@ -230,6 +230,7 @@ func internalProcessMessage_ReconcileRepositoryCredential(ctx context.Context,
 			AuthSSHKey:      authSSHKey,
 			SecretObj:       secretObj,
 			EngineClusterID: gitopsEngineInstance.Gitopsengineinstance_id, // comply with the constraint 'fk_gitopsengineinstance_id',
+			Project:         generateRepositoryCredentialProject(*gitopsDeploymentRepositoryCredentialCR),
 		}
 
 		err = dbQueries.CreateRepositoryCredentials(ctx, &dbRepoCred)
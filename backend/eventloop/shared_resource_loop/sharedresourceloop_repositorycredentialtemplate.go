@@ -0,0 +1,332 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// internalProcessMessage_ReconcileRepositoryCredentialTemplate reconciles a GitOpsDeploymentRepositoryCredentialTemplate
+// with its RepositoryCredentials row, in the same way as internalProcessMessage_ReconcileRepositoryCredential does for a
+// GitOpsDeploymentRepositoryCredential. The RepositoryCredentials row of a template has IsTemplate set, and its
+// PrivateURL is the repository prefix of the template: the cluster-agent then creates an Argo CD credential template
+// (rather than an Argo CD repository) from it.
+//
+// Unlike a GitOpsDeploymentRepositoryCredential, the credentials of a template are not validated against a Git repository,
+// as the template does not reference a single repository.
+func internalProcessMessage_ReconcileRepositoryCredentialTemplate(ctx context.Context,
+	repoCredTemplateCRName string,
+	repoCredTemplateCRNamespace corev1.Namespace,
+	apiNamespaceClient client.Client,
+	dbQueries db.DatabaseQueries, shouldWait bool, l logr.Logger) (*db.RepositoryCredentials, error) {
+
+	resourceNS := repoCredTemplateCRNamespace.Name
+
+	clusterUser, _, err := internalGetOrCreateClusterUserByNamespaceUID(ctx, string(repoCredTemplateCRNamespace.UID), dbQueries, l)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve cluster user while processing GitOpsDeploymentRepositoryCredentialTemplate: '%s' in namespace: '%s': %v",
+			repoCredTemplateCRName, string(repoCredTemplateCRNamespace.UID), err)
+	}
+
+	gitopsEngineInstance, _, _, uerr := internalDetermineGitOpsEngineInstance(ctx, *clusterUser, apiNamespaceClient, dbQueries, l)
+	if uerr != nil {
+		return nil, fmt.Errorf("unable to retrieve GitOpsEngineInstance while processing GitOpsDeploymentRepositoryCredentialTemplate: '%s' in namespace: '%s': Error: %w",
+			repoCredTemplateCRName, string(repoCredTemplateCRNamespace.UID), uerr.DevError())
+	}
+
+	// Note: this may be nil in some if-else branches
+	repoCredTemplateCR := &managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate{}
+
+	// 1) Attempt to get the GitOpsDeploymentRepositoryCredentialTemplate from the namespace
+	if err := apiNamespaceClient.Get(ctx, types.NamespacedName{Namespace: resourceNS, Name: repoCredTemplateCRName},
+		repoCredTemplateCR); err != nil {
+
+		if !apierr.IsNotFound(err) {
+			// Something went wrong, retry
+			vErr := fmt.Errorf("unexpected error in retrieving repository credential template: %v", err)
+			l.Error(err, vErr.Error(), "DebugErr", errGenericCR, "CR Name", repoCredTemplateCRName, "Namespace", resourceNS)
+			return nil, vErr
+		}
+
+		repoCredTemplateCR = nil
+	}
+
+	// 2) Look for any APICRToDBMappings that point(ed) to a K8s resource with the same name and namespace
+	var apiCRToDBMappingList []db.APICRToDatabaseMapping
+	if err := dbQueries.ListAPICRToDatabaseMappingByAPINamespaceAndName(
+		ctx, db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate,
+		repoCredTemplateCRName,
+		repoCredTemplateCRNamespace.Name,
+		string(repoCredTemplateCRNamespace.GetUID()),
+		db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential,
+		&apiCRToDBMappingList); err != nil {
+		l.Error(err, "Error listing APICRToDatabaseMapping for GitOpsDeploymentRepositoryCredentialTemplate", "CR Name", repoCredTemplateCRName, "Namespace", resourceNS)
+
+		return nil, fmt.Errorf("unable to list APICRs for repository credential templates: %v", err)
+	}
+
+	// APICRToDBMapping that matches the current resource UID (or nil if the current resource UID doesn't exist)
+	var currentAPICRToDBMapping *db.APICRToDatabaseMapping
+
+	// 3) Clean up the RepositoryCredentials rows of previously deleted templates with the same name/namespace as this resource
+	for idx := range apiCRToDBMappingList {
+
+		apiCRToDBMapping := apiCRToDBMappingList[idx]
+
+		if repoCredTemplateCR != nil && apiCRToDBMapping.APIResourceUID == string(repoCredTemplateCR.UID) {
+			currentAPICRToDBMapping = &apiCRToDBMapping
+			continue
+		}
+
+		if err := deleteRepoCredTemplateRows(ctx, apiCRToDBMapping, clusterUser, resourceNS, dbQueries, apiNamespaceClient, shouldWait, l); err != nil {
+			return nil, err
+		}
+	}
+
+	if repoCredTemplateCR == nil {
+		// If the GitOpsDeploymentRepositoryCredentialTemplate doesn't exist, then our work is done.
+		return nil, nil
+	}
+
+	// 4) Retrieve the credentials from the Secret of the template
+	if repoCredTemplateCR.Spec.Secret == "" {
+		updateRepoCredTemplateStatus(ctx, repoCredTemplateCR, apiNamespaceClient, managedgitopsv1alpha1.RepositoryCredentialReasonSecretNotSpecified,
+			"Secret field is missing value", l)
+		return nil, fmt.Errorf("secret cannot be empty")
+	}
+
+	secret := &corev1.Secret{}
+	if err := apiNamespaceClient.Get(ctx, client.ObjectKey{Name: repoCredTemplateCR.Spec.Secret, Namespace: resourceNS}, secret); err != nil {
+		if apierr.IsNotFound(err) {
+			updateRepoCredTemplateStatus(ctx, repoCredTemplateCR, apiNamespaceClient, managedgitopsv1alpha1.RepositoryCredentialReasonSecretNotFound,
+				"Secret specified not found", l)
+			return nil, fmt.Errorf("secret not found: %v", err)
+		}
+		// Something went wrong, retry
+		return nil, fmt.Errorf("error retrieving secret: %v", err)
+	}
+
+	updateRepoCredTemplateStatus(ctx, repoCredTemplateCR, apiNamespaceClient, managedgitopsv1alpha1.RepositoryCredentialReasonCredentialsUpToDate, "", l)
+
+	// 5) If there is no existing APICRToDBMapping for this CR, then create the RepositoryCredentials row, and the mapping
+	if currentAPICRToDBMapping == nil {
+		dbRepoCred := db.RepositoryCredentials{
+			UserID:          clusterUser.Clusteruser_id,
+			PrivateURL:      repoCredTemplateCR.Spec.RepositoryPrefix,
+			AuthUsername:    string(secret.Data["username"]),
+			AuthPassword:    string(secret.Data["password"]),
+			AuthSSHKey:      string(secret.Data["sshPrivateKey"]),
+			SecretObj:       secret.Name,
+			EngineClusterID: gitopsEngineInstance.Gitopsengineinstance_id,
+			IsTemplate:      true,
+		}
+
+		if err := dbQueries.CreateRepositoryCredentials(ctx, &dbRepoCred); err != nil {
+			l.Error(err, "Error creating RepositoryCredential row in DB", "DebugErr", errCreateDBRepoCred, "CR Name", repoCredTemplateCRName, "Namespace", resourceNS)
+			return nil, fmt.Errorf("unable to create repository credential in the database: %v", err)
+		}
+		l.Info("Created RepositoryCredential of template in the DB", "repositoryCredential", dbRepoCred.RepositoryCredentialsID)
+
+		newApiCRToDBMapping := db.APICRToDatabaseMapping{
+			APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate,
+			APIResourceUID:       string(repoCredTemplateCR.UID),
+			APIResourceName:      repoCredTemplateCRName,
+			APIResourceNamespace: resourceNS,
+			NamespaceUID:         string(repoCredTemplateCRNamespace.GetUID()),
+
+			DBRelationType: db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential,
+			DBRelationKey:  dbRepoCred.RepositoryCredentialsID,
+		}
+
+		if err := dbQueries.CreateAPICRToDatabaseMapping(ctx, &newApiCRToDBMapping); err != nil {
+			l.Error(err, "unable to create api to db mapping in database", "mapping", newApiCRToDBMapping.APIResourceUID)
+
+			// If we were unable to create api to db mapping in database, delete the newly created repository credential
+			if _, err := dbQueries.DeleteRepositoryCredentialsByID(ctx, dbRepoCred.RepositoryCredentialsID); err != nil {
+				l.Error(err, "unable to delete repository credential from database")
+				return nil, fmt.Errorf("unable to delete repository credential from database: %v", err)
+			}
+
+			return nil, err
+		}
+
+		if err := createAndCleanRepoCredOperation(ctx, dbRepoCred, clusterUser, resourceNS, dbQueries, apiNamespaceClient, shouldWait, l); err != nil {
+			return nil, err
+		}
+
+		return &dbRepoCred, nil
+	}
+
+	// 6) If the APICRToDBMapping already exists in the database, and already points to the CR, then it is instead an update
+	dbRepoCred, err := dbQueries.GetRepositoryCredentialsByID(ctx, currentAPICRToDBMapping.DBRelationKey)
+	if err != nil {
+
+		if db.IsResultNotFoundError(err) {
+			// If the APICRToDBMapping points to a RepositoryCredential that doesn't exist, delete the APICRToDBMapping
+			// and return an error, so that the template is reconciled again.
+			if _, err := dbQueries.DeleteAPICRToDatabaseMapping(ctx, currentAPICRToDBMapping); err != nil {
+				l.Error(err, "unable to delete apiCRToDBmapping", "mapping", currentAPICRToDBMapping.APIResourceUID)
+				return nil, err
+			}
+			l.Info("Deleted APICRToDBMapping that points to an invalid repository credential", "mapping", currentAPICRToDBMapping.APIResourceUID)
+			return nil, fmt.Errorf("APICRToDBMapping pointed to a RepositoryCredential that didn't exist")
+		}
+
+		return nil, fmt.Errorf("error retrieving repository credentials from DB: %v", err)
+	}
+
+	if !compareAndModifyRepoCredTemplateWithDatabaseRow(*repoCredTemplateCR, &dbRepoCred, secret, l) {
+		return &dbRepoCred, nil
+	}
+
+	l.Info("Syncing data between the RepositoryCredentialTemplate CR and its related DB row",
+		"CR", repoCredTemplateCR.Name, "Namespace", repoCredTemplateCR.Namespace, "DB Row", dbRepoCred.RepositoryCredentialsID)
+
+	if err := dbQueries.UpdateRepositoryCredentials(ctx, &dbRepoCred); err != nil {
+		l.Error(err, errUpdateDBRepoCred)
+		return nil, err
+	}
+
+	if err := createAndCleanRepoCredOperation(ctx, dbRepoCred, clusterUser, resourceNS, dbQueries, apiNamespaceClient, shouldWait, l); err != nil {
+		return nil, err
+	}
+
+	return &dbRepoCred, nil
+}
+
+// deleteRepoCredTemplateRows deletes the RepositoryCredentials row that the APICRToDatabaseMapping of a (deleted)
+// GitOpsDeploymentRepositoryCredentialTemplate points to, informing the cluster-agent, and then deletes the mapping.
+func deleteRepoCredTemplateRows(ctx context.Context, apiCRToDBMapping db.APICRToDatabaseMapping, clusterUser *db.ClusterUser,
+	resourceNS string, dbQueries db.DatabaseQueries, apiNamespaceClient client.Client, shouldWait bool, l logr.Logger) error {
+
+	repositoryCredentialPrimaryKey := apiCRToDBMapping.DBRelationKey
+
+	if dbRepoCred, err := dbQueries.GetRepositoryCredentialsByID(ctx, repositoryCredentialPrimaryKey); err != nil {
+
+		if !db.IsResultNotFoundError(err) {
+			l.Error(err, "unable to retrieve repository credential from the database")
+			return fmt.Errorf("unable to retrieve repository credential from the database: %v", err)
+		}
+		// Otherwise, the row is already deleted
+
+	} else {
+		if _, err := deleteRepoCredFromDB(ctx, dbQueries, repositoryCredentialPrimaryKey, l); err != nil {
+			return err
+		}
+
+		if err := createAndCleanRepoCredOperation(ctx, dbRepoCred, clusterUser, resourceNS, dbQueries, apiNamespaceClient, shouldWait, l); err != nil {
+			return err
+		}
+	}
+
+	if rowsDeleted, err := dbQueries.DeleteAPICRToDatabaseMapping(ctx, &apiCRToDBMapping); err != nil {
+		l.Error(err, "unable to delete apiCRToDBmapping", "mapping", apiCRToDBMapping.APIResourceUID)
+		return err
+	} else if rowsDeleted == 0 {
+		l.Info("unexpected number of rows deleted of apiCRToDBmapping", "mapping", apiCRToDBMapping.APIResourceUID)
+	} else {
+		l.Info("deleted APICRToDatabaseMapping", "mapping", apiCRToDBMapping.APIResourceUID)
+	}
+
+	return nil
+}
+
+// createAndCleanRepoCredOperation creates an Operation for the RepositoryCredentials row, so that the cluster-agent
+// reconciles it, and then cleans up the Operation once it has completed.
+func createAndCleanRepoCredOperation(ctx context.Context, dbRepoCred db.RepositoryCredentials, clusterUser *db.ClusterUser, ns string,
+	dbQueries db.DatabaseQueries, apiNamespaceClient client.Client, shouldWait bool, l logr.Logger) error {
+
+	operationDBID, err := createRepoCredOperation(ctx, dbRepoCred, clusterUser, ns, dbQueries, apiNamespaceClient, shouldWait, l)
+	if err != nil {
+		return err
+	}
+
+	if err := CleanRepoCredOperation(ctx, dbRepoCred, clusterUser, ns, dbQueries, apiNamespaceClient, operationDBID, l); err != nil {
+		l.Error(err, "unable to clean up operation", "Operation ID", operationDBID)
+		return err
+	}
+
+	return nil
+}
+
+// compareAndModifyRepoCredTemplateWithDatabaseRow updates the RepositoryCredentials row to match the template and its
+// Secret, returning true if the row was modified.
+func compareAndModifyRepoCredTemplateWithDatabaseRow(cr managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate,
+	dbr *db.RepositoryCredentials, secret *corev1.Secret, l logr.Logger) bool {
+
+	isUpdateNeeded := false
+
+	if cr.Spec.Secret != dbr.SecretObj {
+		l.Info("Secret name changed", "old", dbr.SecretObj, "new", cr.Spec.Secret)
+		dbr.SecretObj = cr.Spec.Secret
+		isUpdateNeeded = true
+	}
+
+	if cr.Spec.RepositoryPrefix != dbr.PrivateURL {
+		l.Info("Repository prefix changed", "old", dbr.PrivateURL, "new", cr.Spec.RepositoryPrefix)
+		dbr.PrivateURL = cr.Spec.RepositoryPrefix
+		isUpdateNeeded = true
+	}
+
+	if authUsername := string(secret.Data["username"]); authUsername != dbr.AuthUsername {
+		l.Info("AuthUsername changed")
+		dbr.AuthUsername = authUsername
+		isUpdateNeeded = true
+	}
+
+	if authPassword := string(secret.Data["password"]); authPassword != dbr.AuthPassword {
+		l.Info("AuthPassword changed")
+		dbr.AuthPassword = authPassword
+		isUpdateNeeded = true
+	}
+
+	if authSSHKey := string(secret.Data["sshPrivateKey"]); authSSHKey != dbr.AuthSSHKey {
+		l.Info("AuthSSHKey changed")
+		dbr.AuthSSHKey = authSSHKey
+		isUpdateNeeded = true
+	}
+
+	if !dbr.IsTemplate {
+		dbr.IsTemplate = true
+		isUpdateNeeded = true
+	}
+
+	return isUpdateNeeded
+}
+
+// updateRepoCredTemplateStatus sets the ErrorOccurred condition of the GitOpsDeploymentRepositoryCredentialTemplate: the
+// condition is true, with the given reason and message, unless the reason is RepositoryCredentialReasonCredentialsUpToDate.
+// Errors are logged, but not returned, as a failure to update the status should not prevent the reconciliation.
+func updateRepoCredTemplateStatus(ctx context.Context, repoCredTemplate *managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate,
+	k8sClient client.Client, reason string, message string, l logr.Logger) {
+
+	condition := metav1.Condition{
+		Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplateConditionErrorOccurred,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	}
+	if reason == managedgitopsv1alpha1.RepositoryCredentialReasonCredentialsUpToDate {
+		condition.Status = metav1.ConditionFalse
+		condition.Message = "RepositoryCredentialTemplate is up to date"
+	}
+
+	existing := meta.FindStatusCondition(repoCredTemplate.Status.Conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+		return
+	}
+
+	meta.SetStatusCondition(&repoCredTemplate.Status.Conditions, condition)
+	if err := k8sClient.Status().Update(ctx, repoCredTemplate); err != nil {
+		l.Error(err, "unable to update status of GitOpsDeploymentRepositoryCredentialTemplate", "name", repoCredTemplate.Name)
+	}
+}
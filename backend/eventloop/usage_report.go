@@ -0,0 +1,276 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/go-logr/logr"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+)
+
+// The usage report generator periodically writes a ConfigMap to each namespace that contains GitOps Service API
+// resources, containing statistics on how the namespace uses the GitOps Service (for example, the number of
+// GitOpsDeployments, and the number of syncs in the last 24 hours). This gives tenants visibility into their usage, and
+// may be used for chargeback.
+
+const (
+	usageReportGeneratorInterval = 1 * time.Hour // Interval between runs of the usage report generator.
+
+	// usageReportSyncWindow is the period over which the number of syncs of a namespace are counted.
+	usageReportSyncWindow = 24 * time.Hour
+
+	// UsageReportConfigMapName is the name of the ConfigMap that the usage report of a namespace is written to.
+	UsageReportConfigMapName = "gitops-service-usage-report"
+
+	// UsageReportConfigMapLabel is set on the usage report ConfigMaps, to distinguish them from ConfigMaps created by users.
+	UsageReportConfigMapLabel = "managed-gitops.redhat.com/usage-report"
+
+	// Keys of the usage report ConfigMap
+	UsageReportKeyDeployments           = "deployments"
+	UsageReportKeyFailingDeployments    = "failingDeployments"
+	UsageReportKeyFailureRate           = "failureRate"
+	UsageReportKeySyncsLast24h          = "syncsLast24h"
+	UsageReportKeyEnvironments          = "environments"
+	UsageReportKeyRepositoryCredentials = "repositoryCredentials"
+	UsageReportKeyResourcesStorageBytes = "resourcesStorageBytes"
+	UsageReportKeyGeneratedAt           = "generatedAt"
+)
+
+// UsageReportGenerator writes the usage report of each namespace that contains GitOps Service API resources.
+type UsageReportGenerator struct {
+	client.Client
+	DB db.DatabaseQueries
+}
+
+func (g *UsageReportGenerator) StartUsageReportGenerator() {
+	getBackgroundTaskQueue().AddAfter(usageReportGeneratorTaskKey, usageReportGeneratorInterval, func(ctx context.Context) error {
+
+		// Kick off the timer again, once the old task runs (even if it panics).
+		// This ensures that at least 'usageReportGeneratorInterval' time elapses from the end of one run to the beginning of another.
+		defer g.StartUsageReportGenerator()
+
+		log := log.FromContext(ctx).
+			WithName(logutil.LogLogger_managed_gitops).
+			WithValues("component", "usage-report-generator")
+
+		generateUsageReports(ctx, g.DB, g.Client, time.Now(), log)
+
+		return nil
+	})
+}
+
+// namespaceUsage contains the usage statistics of a single namespace
+type namespaceUsage struct {
+	deployments           int
+	failingDeployments    int
+	syncs                 int
+	environments          int
+	repositoryCredentials int
+	resourcesStorageBytes int
+}
+
+// toConfigMapData returns the usage statistics as the data of the usage report ConfigMap (excluding the generation time).
+func (usage namespaceUsage) toConfigMapData() map[string]string {
+
+	failureRate := 0.0
+	if usage.deployments > 0 {
+		failureRate = float64(usage.failingDeployments) / float64(usage.deployments)
+	}
+
+	return map[string]string{
+		UsageReportKeyDeployments:           strconv.Itoa(usage.deployments),
+		UsageReportKeyFailingDeployments:    strconv.Itoa(usage.failingDeployments),
+		UsageReportKeyFailureRate:           strconv.FormatFloat(failureRate, 'f', 2, 64),
+		UsageReportKeySyncsLast24h:          strconv.Itoa(usage.syncs),
+		UsageReportKeyEnvironments:          strconv.Itoa(usage.environments),
+		UsageReportKeyRepositoryCredentials: strconv.Itoa(usage.repositoryCredentials),
+		UsageReportKeyResourcesStorageBytes: strconv.Itoa(usage.resourcesStorageBytes),
+	}
+}
+
+// generateUsageReports writes the usage report of each namespace that contains GitOps Service API resources.
+func generateUsageReports(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client, now time.Time, l logr.Logger) {
+
+	log := l.WithValues("job", "generateUsageReports")
+
+	usageByNamespace, err := countAPIResourcesByNamespace(ctx, k8sClient)
+	if err != nil {
+		log.Error(err, "unable to count the GitOps Service API resources of each namespace")
+		return
+	}
+
+	// Process the namespaces in a consistent order, to make the logs easier to follow
+	namespaceNames := make([]string, 0, len(usageByNamespace))
+	for namespaceName := range usageByNamespace {
+		namespaceNames = append(namespaceNames, namespaceName)
+	}
+	sort.Strings(namespaceNames)
+
+	for _, namespaceName := range namespaceNames {
+
+		usage := usageByNamespace[namespaceName]
+
+		if err := generateNamespaceUsageReport(ctx, dbQueries, k8sClient, namespaceName, usage, now, log); err != nil {
+			log.Error(err, "unable to generate usage report of namespace", "namespace", namespaceName)
+		}
+	}
+}
+
+// countAPIResourcesByNamespace returns the number of each GitOps Service API resource, by namespace. Only namespaces that
+// contain at least one API resource are returned.
+func countAPIResourcesByNamespace(ctx context.Context, k8sClient client.Client) (map[string]*namespaceUsage, error) {
+
+	usageByNamespace := map[string]*namespaceUsage{}
+
+	getUsage := func(namespace string) *namespaceUsage {
+		usage, exists := usageByNamespace[namespace]
+		if !exists {
+			usage = &namespaceUsage{}
+			usageByNamespace[namespace] = usage
+		}
+		return usage
+	}
+
+	var gitopsDeployments managedgitopsv1alpha1.GitOpsDeploymentList
+	if err := k8sClient.List(ctx, &gitopsDeployments); err != nil {
+		return nil, fmt.Errorf("unable to list GitOpsDeployments: %v", err)
+	}
+	for _, gitopsDeployment := range gitopsDeployments.Items {
+		usage := getUsage(gitopsDeployment.Namespace)
+		usage.deployments++
+
+		for _, condition := range gitopsDeployment.Status.Conditions {
+			if condition.Type == managedgitopsv1alpha1.GitOpsDeploymentConditionErrorOccurred &&
+				condition.Status == managedgitopsv1alpha1.GitOpsConditionStatusTrue {
+				usage.failingDeployments++
+				break
+			}
+		}
+	}
+
+	var managedEnvironments managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentList
+	if err := k8sClient.List(ctx, &managedEnvironments); err != nil {
+		return nil, fmt.Errorf("unable to list GitOpsDeploymentManagedEnvironments: %v", err)
+	}
+	for _, managedEnvironment := range managedEnvironments.Items {
+		getUsage(managedEnvironment.Namespace).environments++
+	}
+
+	var repositoryCredentials managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialList
+	if err := k8sClient.List(ctx, &repositoryCredentials); err != nil {
+		return nil, fmt.Errorf("unable to list GitOpsDeploymentRepositoryCredentials: %v", err)
+	}
+	for _, repositoryCredential := range repositoryCredentials.Items {
+		getUsage(repositoryCredential.Namespace).repositoryCredentials++
+	}
+
+	return usageByNamespace, nil
+}
+
+// generateNamespaceUsageReport adds the database statistics of the namespace to 'usage', and writes the usage report
+// ConfigMap of the namespace.
+func generateNamespaceUsageReport(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client, namespaceName string,
+	usage *namespaceUsage, now time.Time, log logr.Logger) error {
+
+	namespace := corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespaceName,
+		},
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&namespace), &namespace); err != nil {
+		if apierr.IsNotFound(err) {
+			// The namespace was deleted after its API resources were listed
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve namespace: %v", err)
+	}
+
+	// The database statistics are of the Applications of the GitOpsDeployments of the namespace
+	var dtams []db.DeploymentToApplicationMapping
+	if err := dbQueries.ListDeploymentToApplicationMappingByNamespaceUID(ctx, string(namespace.UID), &dtams); err != nil {
+		return fmt.Errorf("unable to list DeploymentToApplicationMappings: %v", err)
+	}
+
+	applicationIDs := make([]string, 0, len(dtams))
+	for _, dtam := range dtams {
+		applicationIDs = append(applicationIDs, dtam.Application_id)
+	}
+
+	var err error
+	if usage.syncs, err = dbQueries.CountDeploymentHistoryByApplicationIDsSince(ctx, applicationIDs, now.Add(-usageReportSyncWindow)); err != nil {
+		return fmt.Errorf("unable to count syncs: %v", err)
+	}
+
+	if usage.resourcesStorageBytes, err = dbQueries.GetApplicationStateResourcesSize(ctx, applicationIDs); err != nil {
+		return fmt.Errorf("unable to retrieve size of resources: %v", err)
+	}
+
+	return writeUsageReportConfigMap(ctx, k8sClient, namespaceName, usage.toConfigMapData(), now, log)
+}
+
+// writeUsageReportConfigMap creates or updates the usage report ConfigMap of the namespace. The ConfigMap is not
+// updated if the usage is unchanged.
+func writeUsageReportConfigMap(ctx context.Context, k8sClient client.Client, namespaceName string, data map[string]string,
+	now time.Time, log logr.Logger) error {
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      UsageReportConfigMapName,
+			Namespace: namespaceName,
+		},
+	}
+
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(configMap), configMap); err != nil {
+
+		if !apierr.IsNotFound(err) {
+			return fmt.Errorf("unable to retrieve usage report ConfigMap: %v", err)
+		}
+
+		configMap.Labels = map[string]string{UsageReportConfigMapLabel: "true"}
+		configMap.Data = data
+		configMap.Data[UsageReportKeyGeneratedAt] = now.UTC().Format(time.RFC3339)
+
+		if err := k8sClient.Create(ctx, configMap); err != nil {
+			return fmt.Errorf("unable to create usage report ConfigMap: %v", err)
+		}
+		logutil.LogAPIResourceChangeEvent(configMap.Namespace, configMap.Name, configMap, logutil.ResourceCreated, log)
+
+		return nil
+	}
+
+	existingData := map[string]string{}
+	for key, value := range configMap.Data {
+		if key != UsageReportKeyGeneratedAt {
+			existingData[key] = value
+		}
+	}
+	if reflect.DeepEqual(existingData, data) {
+		return nil
+	}
+
+	if configMap.Labels == nil {
+		configMap.Labels = map[string]string{}
+	}
+	configMap.Labels[UsageReportConfigMapLabel] = "true"
+	configMap.Data = data
+	configMap.Data[UsageReportKeyGeneratedAt] = now.UTC().Format(time.RFC3339)
+
+	if err := k8sClient.Update(ctx, configMap); err != nil {
+		return fmt.Errorf("unable to update usage report ConfigMap: %v", err)
+	}
+	logutil.LogAPIResourceChangeEvent(configMap.Namespace, configMap.Name, configMap, logutil.ResourceModified, log)
+
+	return nil
+}
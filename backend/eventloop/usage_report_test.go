@@ -0,0 +1,176 @@
+package eventloop
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+)
+
+var _ = Describe("Usage Report Generator Test", func() {
+	Context("Testing generation of the usage report of each namespace", func() {
+
+		var log logr.Logger
+		var ctx context.Context
+		var dbq db.AllDatabaseQueries
+		var k8sClient client.Client
+		var apiNamespace *corev1.Namespace
+		var now time.Time
+
+		BeforeEach(func() {
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				namespace,
+				err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+			apiNamespace = namespace
+
+			newGitOpsDeployment := func(name string, failing bool) *managedgitopsv1alpha1.GitOpsDeployment {
+				gitopsDepl := &managedgitopsv1alpha1.GitOpsDeployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: apiNamespace.Name,
+						UID:       uuid.NewUUID(),
+					},
+				}
+				if failing {
+					gitopsDepl.Status.Conditions = []managedgitopsv1alpha1.GitOpsDeploymentCondition{{
+						Type:   managedgitopsv1alpha1.GitOpsDeploymentConditionErrorOccurred,
+						Status: managedgitopsv1alpha1.GitOpsConditionStatusTrue,
+					}}
+				}
+				return gitopsDepl
+			}
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace,
+					newGitOpsDeployment("test-deployment", false),
+					newGitOpsDeployment("test-failing-deployment", true),
+					&managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-managed-env", Namespace: apiNamespace.Name},
+					},
+					&managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-repo-cred", Namespace: apiNamespace.Name},
+					}).
+				Build()
+
+			err = db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx = context.Background()
+			log = logger.FromContext(ctx)
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			application := db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "my-application",
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbq.CreateApplication(ctx, &application)
+			Expect(err).To(BeNil())
+
+			err = dbq.CreateApplicationState(ctx, &db.ApplicationState{
+				Applicationstate_application_id: application.Application_id,
+				Health:                          "Healthy",
+				Sync_Status:                     "Synced",
+				ReconciledState:                 "Healthy",
+				Resources:                       []byte(`[{"kind": "Deployment", "name": "my-deployment"}]`),
+			})
+			Expect(err).To(BeNil())
+
+			err = dbq.CreateDeploymentToApplicationMapping(ctx, &db.DeploymentToApplicationMapping{
+				Deploymenttoapplicationmapping_uid_id: "test-" + string(uuid.NewUUID()),
+				Application_id:                        application.Application_id,
+				DeploymentName:                        "test-deployment",
+				DeploymentNamespace:                   apiNamespace.Name,
+				NamespaceUID:                          string(apiNamespace.UID),
+			})
+			Expect(err).To(BeNil())
+
+			// Only the syncs of the last 24 hours should be counted
+			now = time.Now()
+			for i, deployedAt := range []time.Time{now.Add(-1 * time.Hour), now.Add(-2 * time.Hour), now.Add(-48 * time.Hour)} {
+				err = dbq.CreateDeploymentHistory(ctx, &db.DeploymentHistory{
+					ApplicationID:   application.Application_id,
+					ArgoCDHistoryID: int64(i + 1),
+					DeployedAt:      deployedAt,
+				})
+				Expect(err).To(BeNil())
+			}
+		})
+
+		AfterEach(func() {
+			dbq.CloseDatabase()
+		})
+
+		getUsageReport := func() *corev1.ConfigMap {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: UsageReportConfigMapName, Namespace: apiNamespace.Name},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)
+			Expect(err).To(BeNil())
+			return configMap
+		}
+
+		It("should write the usage report ConfigMap of a namespace with GitOps Service API resources", func() {
+
+			generateUsageReports(ctx, dbq, k8sClient, now, log)
+
+			configMap := getUsageReport()
+			Expect(configMap.Labels).To(HaveKeyWithValue(UsageReportConfigMapLabel, "true"))
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeyDeployments, "2"))
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeyFailingDeployments, "1"))
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeyFailureRate, "0.50"))
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeySyncsLast24h, "2"))
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeyEnvironments, "1"))
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeyRepositoryCredentials, "1"))
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeyGeneratedAt, now.UTC().Format(time.RFC3339)))
+			Expect(configMap.Data[UsageReportKeyResourcesStorageBytes]).ToNot(Equal("0"))
+
+			By("verifying that a usage report is not written to namespaces without GitOps Service API resources")
+			configMapList := &corev1.ConfigMapList{}
+			err := k8sClient.List(ctx, configMapList)
+			Expect(err).To(BeNil())
+			Expect(configMapList.Items).To(HaveLen(1))
+		})
+
+		It("should only update the usage report ConfigMap if the usage has changed", func() {
+
+			generateUsageReports(ctx, dbq, k8sClient, now, log)
+			generatedAt := getUsageReport().Data[UsageReportKeyGeneratedAt]
+
+			By("generating the report again, with no change in usage")
+			later := now.Add(time.Hour)
+			generateUsageReports(ctx, dbq, k8sClient, later, log)
+			Expect(getUsageReport().Data[UsageReportKeyGeneratedAt]).To(Equal(generatedAt))
+
+			By("generating the report again, once a sync is older than 24 hours")
+			later = now.Add(22*time.Hour + 30*time.Minute)
+			generateUsageReports(ctx, dbq, k8sClient, later, log)
+
+			configMap := getUsageReport()
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeySyncsLast24h, "1"))
+			Expect(configMap.Data).To(HaveKeyWithValue(UsageReportKeyGeneratedAt, later.UTC().Format(time.RFC3339)))
+		})
+	})
+})
@@ -38,13 +38,13 @@ import (
 
 // Start a workspace event loop router go routine, which is responsible for handling API namespace events and
 // then passing them to the controller loop.
-func newWorkspaceEventLoopRouter(workspaceID string) WorkspaceEventLoopRouterStruct {
+func newWorkspaceEventLoopRouter(workspaceID string, directMode bool) WorkspaceEventLoopRouterStruct {
 
 	res := WorkspaceEventLoopRouterStruct{
 		channel: make(chan workspaceEventLoopMessage),
 	}
 
-	internalStartWorkspaceEventLoopRouter(res.channel, workspaceID, defaultApplicationEventLoopFactory{})
+	internalStartWorkspaceEventLoopRouter(res.channel, workspaceID, defaultApplicationEventLoopFactory{directMode: directMode})
 
 	return res
 }
@@ -280,6 +280,12 @@ func handleWorkspaceEventLoopMessage(ctx context.Context, event eventlooptypes.E
 		return
 	}
 
+	// Repository Credential Templates: Handle, then return
+	if event.Event.ReqResource == eventlooptypes.GitOpsDeploymentRepositoryCredentialTemplateTypeName {
+		state.workspaceResourceLoop.processRepositoryCredentialTemplate(ctx, event.Event.Request, event.Event.Client)
+		return
+	}
+
 	// Managed Environment: Handle, then return
 	if event.Event.ReqResource == eventlooptypes.GitOpsDeploymentManagedEnvironmentTypeName {
 
@@ -463,12 +469,16 @@ type applicationEventQueueLoopFactory interface {
 }
 
 type defaultApplicationEventLoopFactory struct {
+	// directMode is passed to the application event loops that are started: see NewPreprocessEventLoop
+	directMode bool
 }
 
 // The default implementation of startApplicationEventQueueLoop is just a simple wrapper around a call to
 // StartApplicationEventQueueLoop
 func (d defaultApplicationEventLoopFactory) startApplicationEventQueueLoop(ctx context.Context, aeqlParam application_event_loop.ApplicationEventQueueLoop) error {
 
+	aeqlParam.DirectMode = d.directMode
+
 	application_event_loop.StartApplicationEventQueueLoop(ctx, aeqlParam)
 
 	return nil
@@ -42,8 +42,9 @@ type workspaceResourceLoopMessage struct {
 type workspaceResourceLoopMessageType string
 
 const (
-	workspaceResourceLoopMessageType_processRepositoryCredential workspaceResourceLoopMessageType = "processRepositoryCredential"
-	workspaceResourceLoopMessageType_processManagedEnvironment   workspaceResourceLoopMessageType = "processManagedEnvironment"
+	workspaceResourceLoopMessageType_processRepositoryCredential         workspaceResourceLoopMessageType = "processRepositoryCredential"
+	workspaceResourceLoopMessageType_processRepositoryCredentialTemplate workspaceResourceLoopMessageType = "processRepositoryCredentialTemplate"
+	workspaceResourceLoopMessageType_processManagedEnvironment           workspaceResourceLoopMessageType = "processManagedEnvironment"
 )
 
 func (werl *workspaceResourceEventLoop) processRepositoryCredential(ctx context.Context, req ctrl.Request, apiNamespaceClient client.Client) {
@@ -59,6 +60,19 @@ func (werl *workspaceResourceEventLoop) processRepositoryCredential(ctx context.
 	// This function is async: we don't wait for a return value from the loop.
 }
 
+func (werl *workspaceResourceEventLoop) processRepositoryCredentialTemplate(ctx context.Context, req ctrl.Request, apiNamespaceClient client.Client) {
+
+	msg := workspaceResourceLoopMessage{
+		apiNamespaceClient: apiNamespaceClient,
+		messageType:        workspaceResourceLoopMessageType_processRepositoryCredentialTemplate,
+		payload:            req,
+	}
+
+	werl.inputChannel <- msg
+
+	// This function is async: we don't wait for a return value from the loop.
+}
+
 func (werl *workspaceResourceEventLoop) processManagedEnvironment(ctx context.Context, eventLoopMessage eventlooptypes.EventLoopMessage,
 	apiNamespaceClient client.Client) {
 
@@ -117,6 +131,16 @@ func internalWorkspaceResourceEventLoop(inputChan chan workspaceResourceLoopMess
 
 			mapKey = "repo-cred-" + repoCred.Namespace + "-" + repoCred.Name
 
+		} else if msg.messageType == workspaceResourceLoopMessageType_processRepositoryCredentialTemplate {
+
+			repoCredTemplate, ok := (msg.payload).(ctrl.Request)
+			if !ok {
+				l.Error(nil, "SEVERE: Unexpected payload type in workspace resource event loop")
+				continue
+			}
+
+			mapKey = "repo-cred-template-" + repoCredTemplate.Namespace + "-" + repoCredTemplate.Name
+
 		} else if msg.messageType == workspaceResourceLoopMessageType_processManagedEnvironment {
 
 			evlMsg, ok := (msg.payload).(eventlooptypes.EventLoopMessage)
@@ -209,6 +233,37 @@ func internalProcessWorkspaceResourceMessage(ctx context.Context, msg workspaceR
 			return retry, fmt.Errorf("unable to reconcile repository credential. Error: %v", err)
 		}
 
+		return noRetry, nil
+	} else if msg.messageType == workspaceResourceLoopMessageType_processRepositoryCredentialTemplate {
+		req, ok := (msg.payload).(ctrl.Request)
+		if !ok {
+			return noRetry, fmt.Errorf("invalid payload in processWorkspaceResourceMessage")
+		}
+
+		// Retrieve the namespace that the repository credential template is contained within
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: req.Namespace,
+			},
+		}
+		if err := msg.apiNamespaceClient.Get(ctx, client.ObjectKeyFromObject(namespace), namespace); err != nil {
+
+			if !apierr.IsNotFound(err) {
+				return retry, fmt.Errorf("unexpected error in retrieving namespace of repo credential template: %v", err)
+			}
+
+			log.V(logutil.LogLevel_Warn).Info("Received a message for a repository credential template in a namespace that doesn't exist", "namespace", namespace)
+			return noRetry, nil
+		}
+
+		// Request that the shared resource loop handle the GitOpsDeploymentRepositoryCredentialTemplate resource, in the
+		// same way as a GitOpsDeploymentRepositoryCredential (see above).
+		_, err := sharedResourceLoop.ReconcileRepositoryCredentialTemplate(ctx, msg.apiNamespaceClient, *namespace, req.Name, shared_resource_loop.DefaultK8sClientFactory{})
+
+		if err != nil {
+			return retry, fmt.Errorf("unable to reconcile repository credential template. Error: %v", err)
+		}
+
 		return noRetry, nil
 	} else if msg.messageType == workspaceResourceLoopMessageType_processManagedEnvironment {
 
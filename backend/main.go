@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"net/http"
@@ -28,24 +29,26 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"github.com/redhat-appstudio/managed-gitops/utilities/db-migration/migrate"
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	managedgitopsv1beta1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1beta1"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/health"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	managedgitopscontrollers "github.com/redhat-appstudio/managed-gitops/backend/controllers/managed-gitops"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/preprocess_event_loop"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
 	"github.com/redhat-appstudio/managed-gitops/backend/routes"
+	admin "github.com/redhat-appstudio/managed-gitops/backend/routes/admin"
 	crzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -58,6 +61,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(managedgitopsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(managedgitopsv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -66,25 +70,35 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var profilerAddr string
+	var directMode bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":18080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":18081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&profilerAddr, "profiler-address", ":6060", "The address for serving pprof profiles")
+	flag.BoolVar(&directMode, "direct-mode", false,
+		"Apply Argo CD Applications directly, rather than via Operations processed by the cluster-agent. "+
+			"Only intended for local development, against a single cluster on which Argo CD is installed.")
 
-	opts := crzap.Options{
-		TimeEncoder: zapcore.ISO8601TimeEncoder,
-		ZapOpts: []zap.Option{
-			zap.WithCaller(true),
-		},
-	}
+	opts := logutil.NewJSONLoggerOptions()
 
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(crzap.New(crzap.UseFlagOptions(&opts)))
 
+	if err := logutil.ConfigureAuditSinksFromEnv(setupLog); err != nil {
+		setupLog.Error(err, "unable to configure audit sinks")
+		os.Exit(1)
+	}
+
+	metrics.SetNamespaceLabelLimit(metrics.NamespaceLabelLimitFromEnv(setupLog))
+
+	if directMode {
+		setupLog.Info("Direct mode is enabled: Argo CD Applications are applied by the backend, rather than by the cluster-agent")
+	}
+
 	if sharedutil.IsProfilingEnabled() {
 		setupLog.Info("Starting pprof profiler server", "address", profilerAddr)
 		go sharedutil.StartProfilers(profilerAddr)
@@ -105,6 +119,19 @@ func main() {
 		setupLog.Error(err, "Fatal Error: Unsuccessful Migration")
 		os.Exit(1)
 	}
+
+	schemaDBQueries, err := db.NewSharedProductionPostgresDBQueries(false)
+	if err != nil {
+		setupLog.Error(err, "never able to connect to database")
+		os.Exit(1)
+	}
+
+	// Fail fast if the schema of the database does not match the database types, rather than failing on each query
+	if err := db.VerifyDatabaseSchema(ctx, schemaDBQueries); err != nil {
+		setupLog.Error(err, "Fatal Error: database schema drift")
+		os.Exit(1)
+	}
+
 	go initializeRoutes()
 
 	restConfig, err := sharedutil.GetRESTConfig()
@@ -118,16 +145,29 @@ func main() {
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
+		HealthProbeBindAddress: "0", // served by healthServer, below
+		NewClient:              sharedutil.ReconcileTimingNewClientFunc(),
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "5a3f596c.redhat.com",
+		// Allow in-flight events to be drained on shutdown
+		GracefulShutdownTimeout: sharedutil.ManagerGracefulShutdownTimeout(setupLog),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	preprocessEventLoop := preprocess_event_loop.NewPreprocessEventLoop()
+	preprocessEventLoop := preprocess_event_loop.NewPreprocessEventLoop(directMode)
+
+	// On shutdown, stop accepting new events, and wait for the events in progress to be processed
+	if err := mgr.Add(&sharedutil.ShutdownDrainer{
+		Name:  "event-loop",
+		Drain: preprocessEventLoop.Drain,
+		Log:   setupLog,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up graceful shutdown")
+		os.Exit(1)
+	}
 
 	if err = (&managedgitopscontrollers.GitOpsDeploymentReconciler{
 		PreprocessEventLoop: preprocessEventLoop,
@@ -145,6 +185,13 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "GitOpsDeploymentSyncRun")
 		os.Exit(1)
 	}
+	if err = (&managedgitopscontrollers.GitOpsDeploymentBundleReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GitOpsDeploymentBundle")
+		os.Exit(1)
+	}
 	if err = (&managedgitopscontrollers.GitOpsDeploymentRepositoryCredentialReconciler{
 		Client:              mgr.GetClient(),
 		Scheme:              mgr.GetScheme(),
@@ -153,6 +200,14 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "GitOpsDeploymentRepositoryCredential")
 		os.Exit(1)
 	}
+	if err = (&managedgitopscontrollers.GitOpsDeploymentRepositoryCredentialTemplateReconciler{
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		PreprocessEventLoop: preprocessEventLoop,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GitOpsDeploymentRepositoryCredentialTemplate")
+		os.Exit(1)
+	}
 	if err = (&managedgitopscontrollers.GitOpsDeploymentManagedEnvironmentReconciler{
 		Client:                       mgr.GetClient(),
 		Scheme:                       mgr.GetScheme(),
@@ -170,11 +225,24 @@ func main() {
 		os.Exit(1)
 	}
 
+	namespaceOffboarder := newNamespaceOffboarder(mgr)
+	namespaceMigrator := newNamespaceMigrator(mgr, directMode)
+	if err = (&managedgitopscontrollers.NamespaceReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Offboarder: namespaceOffboarder,
+		Migrator:   namespaceMigrator,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Namespace")
+		os.Exit(1)
+	}
+
 	// If the webhook is not disabled, start listening on the webhook URL
 	if !strings.EqualFold(os.Getenv("DISABLE_APPSTUDIO_WEBHOOK"), "true") {
 
 		setupLog.Info("setting up webhooks")
 
+		// As v1beta1 is in the scheme, this also serves the GitOpsDeployment conversion webhook, at '/convert'
 		if err = (&managedgitopsv1alpha1.GitOpsDeployment{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "GitOpsDeployment")
 			os.Exit(1)
@@ -183,6 +251,10 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "GitOpsDeploymentRepositoryCredential")
 			os.Exit(1)
 		}
+		if err = (&managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "GitOpsDeploymentRepositoryCredentialTemplate")
+			os.Exit(1)
+		}
 		if err = (&managedgitopsv1alpha1.GitOpsDeploymentSyncRun{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "GitOpsDeploymentSyncRun")
 			os.Exit(1)
@@ -191,6 +263,10 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "GitOpsDeploymentManagedEnvironment")
 			os.Exit(1)
 		}
+		if err = (&managedgitopsv1alpha1.GitOpsDeploymentBundle{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "GitOpsDeploymentBundle")
+			os.Exit(1)
+		}
 
 	}
 
@@ -199,12 +275,36 @@ func main() {
 	startDBReconciler(mgr)
 	startRepoCredReconciler(mgr)
 	startDBMetricsReconciler(mgr)
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
+	startUsageReportGenerator(mgr)
+
+	// Resume the offboarding of any Namespaces that was interrupted by a restart
+	namespaceOffboarder.StartNamespaceOffboardingResumer()
+
+	// Resume the migration of any Namespaces that was interrupted by a restart
+	namespaceMigrator.StartNamespaceMigrationResumer()
+
+	// If an admin API token is configured, serve the admin endpoints (for example, to offboard ClusterUsers)
+	if adminAPIToken := os.Getenv("ADMIN_API_TOKEN"); adminAPIToken != "" {
+		go initializeAdminRoutes(admin.AdminResource{
+			Offboarder: newClusterUserOffboarder(mgr),
+			Token:      adminAPIToken,
+		})
+	}
+
+	healthDBQueries, err := db.NewSharedProductionPostgresDBQueries(false)
+	if err != nil {
+		setupLog.Error(err, "never able to connect to database")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
+
+	// The health probe endpoints are served by healthServer (rather than by the manager), as they report a JSON body
+	// describing the result of each check.
+	healthServer := health.NewServer(probeAddr, setupLog.WithName("health"))
+	healthServer.Healthz.AddCheck("ping", func(context.Context) error { return nil })
+	healthServer.Readyz.AddCheck("database", health.DatabaseCheck(healthDBQueries))
+	healthServer.Readyz.AddCheck("informer-cache", health.CacheSyncCheck(mgr.GetCache()))
+	if err := mgr.Add(healthServer); err != nil {
+		setupLog.Error(err, "unable to set up health checks")
 		os.Exit(1)
 	}
 
@@ -237,6 +337,12 @@ func startDBReconciler(mgr ctrl.Manager) {
 
 	// Start goroutine for database reconciler
 	databaseReconciler.StartDatabaseReconciler()
+
+	// Once the informer caches have synced, clean up the database rows of API CRs that were deleted while the backend was down.
+	if err := mgr.Add(manager.RunnableFunc(databaseReconciler.CleanOrphanedEntriesCreatedBeforeStartup)); err != nil {
+		setupLog.Error(err, "unable to set up startup database cleanup")
+		os.Exit(1)
+	}
 }
 
 func startRepoCredReconciler(mgr ctrl.Manager) {
@@ -271,6 +377,71 @@ func startDBMetricsReconciler(mgr ctrl.Manager) {
 
 	// Start goroutine for database metrics reconciler
 	databaseReconciler.StartDBMetricsReconcilerForMetrics()
+
+	// Start goroutine for the monitor of the waiting operation backlog of each GitopsEngineInstance
+	operationBacklogMonitor := eventloop.NewOperationBacklogMonitor(dbQueries, setupLog)
+	operationBacklogMonitor.StartOperationBacklogMonitor()
+}
+
+func startUsageReportGenerator(mgr ctrl.Manager) {
+
+	dbQueries, err := db.NewSharedProductionPostgresDBQueries(false)
+	if err != nil {
+		setupLog.Error(err, "never able to connect to database")
+		os.Exit(1)
+	}
+
+	usageReportGenerator := eventloop.UsageReportGenerator{
+		DB:     dbQueries,
+		Client: mgr.GetClient(),
+	}
+
+	// Start goroutine for the usage report generator
+	usageReportGenerator.StartUsageReportGenerator()
+}
+
+func newNamespaceOffboarder(mgr ctrl.Manager) *eventloop.NamespaceOffboarder {
+
+	dbQueries, err := db.NewSharedProductionPostgresDBQueries(false)
+	if err != nil {
+		setupLog.Error(err, "never able to connect to database")
+		os.Exit(1)
+	}
+
+	return &eventloop.NamespaceOffboarder{
+		DB:               dbQueries,
+		Client:           mgr.GetClient(),
+		K8sClientFactory: shared_resource_loop.DefaultK8sClientFactory{},
+	}
+}
+
+func newClusterUserOffboarder(mgr ctrl.Manager) *eventloop.ClusterUserOffboarder {
+
+	dbQueries, err := db.NewSharedProductionPostgresDBQueries(false)
+	if err != nil {
+		setupLog.Error(err, "never able to connect to database")
+		os.Exit(1)
+	}
+
+	return &eventloop.ClusterUserOffboarder{
+		DB:     dbQueries,
+		Client: mgr.GetClient(),
+	}
+}
+
+func newNamespaceMigrator(mgr ctrl.Manager, directMode bool) *eventloop.NamespaceMigrator {
+
+	dbQueries, err := db.NewSharedProductionPostgresDBQueries(false)
+	if err != nil {
+		setupLog.Error(err, "never able to connect to database")
+		os.Exit(1)
+	}
+
+	return &eventloop.NamespaceMigrator{
+		DB:         dbQueries,
+		Client:     mgr.GetClient(),
+		DirectMode: directMode,
+	}
 }
 
 func initializeRoutes() {
@@ -283,3 +454,12 @@ func initializeRoutes() {
 	}
 
 }
+
+func initializeAdminRoutes(adminResource admin.AdminResource) {
+
+	router := routes.AdminRouteInit(adminResource)
+	err := router.ListenAndServe()
+	if err != http.ErrServerClosed {
+		log.Println("Error on ListenAndServe of admin server:", err)
+	}
+}
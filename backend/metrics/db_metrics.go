@@ -105,4 +105,44 @@ func ClearDBMetrics() {
 	OperationDBRowsInErrorState.Set(0)
 	TotalOperationDBRowsInCompletedState.Set(0)
 	TotalOperationDBRowsInNonCompleteState.Set(0)
+	OperationWaitingBacklog.Reset()
+	OperationWaitingBacklogAlert.Reset()
+}
+
+const gitopsEngineInstanceLabel = "gitopsEngineInstance"
+
+var (
+	OperationWaitingBacklog = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "operation_waiting_backlog",
+			Help: "Number of operation DB rows in waiting state, by GitopsEngineInstance",
+		},
+		[]string{gitopsEngineInstanceLabel},
+	)
+
+	OperationWaitingBacklogAlert = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "operation_waiting_backlog_alert",
+			Help: "1 if the waiting backlog of a GitopsEngineInstance has exceeded the alert threshold for longer than the alert duration (indicating the cluster-agent is stuck), 0 otherwise",
+		},
+		[]string{gitopsEngineInstanceLabel},
+	)
+)
+
+// SetOperationWaitingBacklog sets the number of operation DB rows in waiting state, of the given GitopsEngineInstance,
+// and whether an alert is raised on that backlog.
+func SetOperationWaitingBacklog(gitopsEngineInstanceID string, count int, alertRaised bool) {
+	OperationWaitingBacklog.WithLabelValues(gitopsEngineInstanceID).Set(float64(count))
+
+	alert := 0.0
+	if alertRaised {
+		alert = 1.0
+	}
+	OperationWaitingBacklogAlert.WithLabelValues(gitopsEngineInstanceID).Set(alert)
+}
+
+// DeleteOperationWaitingBacklog removes the backlog metrics of a GitopsEngineInstance that no longer has waiting operations.
+func DeleteOperationWaitingBacklog(gitopsEngineInstanceID string) {
+	OperationWaitingBacklog.DeleteLabelValues(gitopsEngineInstanceID)
+	OperationWaitingBacklogAlert.DeleteLabelValues(gitopsEngineInstanceID)
 }
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const eventTypeLabel = "eventType"
+
+var (
+	ApplicationEventsWaiting = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "application_event_loop_waiting_events",
+			Help: "Number of events that are waiting to be processed, across all application event loops",
+		},
+	)
+
+	ApplicationEventsCoalesced = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "application_event_loop_coalesced_events_total",
+			Help: "Number of events that were coalesced into an equivalent waiting event by an application event loop, by event type",
+		},
+		[]string{eventTypeLabel},
+	)
+)
+
+// AddApplicationEventsWaiting adds 'delta' (which may be negative) to the number of events waiting in the application event loops
+func AddApplicationEventsWaiting(delta int) {
+	ApplicationEventsWaiting.Add(float64(delta))
+}
+
+// IncreaseApplicationEventsCoalesced is called when an application event loop drops an event of the given type, because
+// an equivalent event was already waiting
+func IncreaseApplicationEventsCoalesced(eventType string) {
+	ApplicationEventsCoalesced.WithLabelValues(eventType).Inc()
+}
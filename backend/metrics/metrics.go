@@ -2,8 +2,12 @@ package metrics
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"sync"
 
+	"github.com/go-logr/logr"
 	metric "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -26,9 +30,28 @@ var (
 		},
 	)
 
+	// GitopsdeplByNamespace and GitopsdeplFailuresByNamespace break down the above metrics by namespace: see
+	// 'namespaceLabelLimit' for how the number of namespace label values is limited.
+	GitopsdeplByNamespace = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "active_gitopsDeployments_by_namespace",
+			Help: "Number of active GitopsDeployments, by namespace",
+		},
+		[]string{namespaceLabel},
+	)
+	GitopsdeplFailuresByNamespace = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gitopsDeployments_failures_by_namespace",
+			Help: "Number of GitOpsDeployments with an error status, by namespace",
+		},
+		[]string{namespaceLabel},
+	)
+
 	activeGitOpsDeployments = activeGitOpsDeploymentSet{
 		mutex:             sync.Mutex{},
 		gitOpsDeployments: map[string]bool{},
+		namespaces:        map[string]*namespaceDeploymentCount{},
+		namespaceLabels:   map[string]bool{},
 	}
 )
 
@@ -40,6 +63,18 @@ type activeGitOpsDeploymentSet struct {
 	// - key: string: (resource name)-(resource namespace)-(resource namespace uid)
 	// - value: whether the GitOpsDeployment is in an error state; true if in error state, otherwise false.
 	gitOpsDeployments map[string]bool
+
+	// namespaces contains the number of tracked GitOpsDeployments (and those in an error state) of each namespace
+	// - key: namespace name
+	namespaces map[string]*namespaceDeploymentCount
+
+	// namespaceLabels contains the namespace label values that the by-namespace metrics are currently reported with.
+	namespaceLabels map[string]bool
+}
+
+type namespaceDeploymentCount struct {
+	total    int
+	failures int
 }
 
 const (
@@ -48,8 +83,48 @@ const (
 	// Assuming 400 bytes per string, this would be ~2MB of memory.
 	// If/when the GitOps Service has a larger number of users, this should be increased.
 	maxTrackedDeployments = 5000
+
+	namespaceLabel = "namespace"
+
+	// OtherNamespacesLabelValue is the namespace label value of the by-namespace metrics, for the GitOpsDeployments of the
+	// namespaces that are not in the top 'namespaceLabelLimit' namespaces.
+	OtherNamespacesLabelValue = "other"
+
+	// NamespaceLabelLimitEnvVar is the maximum number of namespaces that are reported with their own label value, in the
+	// by-namespace metrics. Defaults to defaultNamespaceLabelLimit.
+	NamespaceLabelLimitEnvVar = "METRICS_NAMESPACE_LABEL_LIMIT"
+
+	defaultNamespaceLabelLimit = 20
 )
 
+// namespaceLabelLimit is the maximum number of namespaces that are reported with their own namespace label value: these are the
+// namespaces with the most GitOpsDeployments. The GitOpsDeployments of all other namespaces are reported under the 'other' label
+// value, which bounds the cardinality of the by-namespace metrics. Acquire the activeGitOpsDeployments mutex before reading/writing.
+var namespaceLabelLimit = defaultNamespaceLabelLimit
+
+// SetNamespaceLabelLimit sets the maximum number of namespaces that are reported with their own label value, in the by-namespace metrics.
+func SetNamespaceLabelLimit(limit int) {
+	activeGitOpsDeployments.mutex.Lock()
+	defer activeGitOpsDeployments.mutex.Unlock()
+
+	namespaceLabelLimit = limit
+	activeGitOpsDeployments.updateNamespaceMetrics()
+}
+
+// NamespaceLabelLimitFromEnv returns the value of the NamespaceLabelLimitEnvVar env var, or the default if it is not set (or invalid).
+func NamespaceLabelLimitFromEnv(logger logr.Logger) int {
+	limit := os.Getenv(NamespaceLabelLimitEnvVar)
+	if limit == "" {
+		return defaultNamespaceLabelLimit
+	}
+	value, err := strconv.Atoi(limit)
+	if err != nil || value < 0 {
+		logger.Error(err, fmt.Sprintf("value of env var %s must be a non-negative int", NamespaceLabelLimitEnvVar))
+		return defaultNamespaceLabelLimit
+	}
+	return value
+}
+
 func generateMapKey(resourceName string, resourceNamespace string, resourceNamespaceUID string) string {
 	// TODO: GITOPSRVCE-68: PERF - Use a more memory efficient key
 	return fmt.Sprintf("(%s)-(%s)-(%s)", resourceName, resourceNamespace, resourceNamespaceUID)
@@ -65,6 +140,8 @@ func AddOrUpdateGitOpsDeployment(resourceName string, resourceNamespace string,
 	if len(activeGitOpsDeployments.gitOpsDeployments) <= maxTrackedDeployments {
 		if _, exists := activeGitOpsDeployments.gitOpsDeployments[mapKey]; !exists {
 			activeGitOpsDeployments.gitOpsDeployments[mapKey] = false
+			activeGitOpsDeployments.namespaceCount(resourceNamespace).total++
+			activeGitOpsDeployments.updateNamespaceMetrics()
 		}
 	}
 
@@ -84,7 +161,15 @@ func RemoveGitOpsDeployment(resourceName string, resourceNamespace string, resou
 	if exists {
 		if inErrorState {
 			GitopsdeplFailures.Dec()
+			activeGitOpsDeployments.namespaceCount(resourceNamespace).failures--
 		}
+
+		namespaceCount := activeGitOpsDeployments.namespaceCount(resourceNamespace)
+		namespaceCount.total--
+		if namespaceCount.total <= 0 {
+			delete(activeGitOpsDeployments.namespaces, resourceNamespace)
+		}
+		activeGitOpsDeployments.updateNamespaceMetrics()
 	}
 
 	delete(activeGitOpsDeployments.gitOpsDeployments, mapKey)
@@ -112,11 +197,69 @@ func SetErrorState(resourceName string, resourceNamespace string, resourceNamesp
 	if inErrorState && !newInErrorState {
 		// An error has converted to a non-error, so decrement
 		GitopsdeplFailures.Dec()
+		activeGitOpsDeployments.namespaceCount(resourceNamespace).failures--
+		activeGitOpsDeployments.updateNamespaceMetrics()
 	} else if !inErrorState && newInErrorState {
 		// A non-error has converted to an error, so increment
 		GitopsdeplFailures.Inc()
+		activeGitOpsDeployments.namespaceCount(resourceNamespace).failures++
+		activeGitOpsDeployments.updateNamespaceMetrics()
+	}
+
+}
+
+// namespaceCount returns the counts of the given namespace, adding the namespace if it is not yet tracked.
+// NOTE: Before calling this function, acquire the mutex.
+func (set *activeGitOpsDeploymentSet) namespaceCount(namespace string) *namespaceDeploymentCount {
+	count, exists := set.namespaces[namespace]
+	if !exists {
+		count = &namespaceDeploymentCount{}
+		set.namespaces[namespace] = count
+	}
+	return count
+}
+
+// updateNamespaceMetrics updates the by-namespace metrics from the tracked counts: the 'namespaceLabelLimit' namespaces with
+// the most GitOpsDeployments are reported under their own label value, and all other namespaces under the 'other' label value.
+// NOTE: Before calling this function, acquire the mutex.
+func (set *activeGitOpsDeploymentSet) updateNamespaceMetrics() {
+
+	namespaces := make([]string, 0, len(set.namespaces))
+	for namespace := range set.namespaces {
+		namespaces = append(namespaces, namespace)
 	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		if set.namespaces[namespaces[i]].total != set.namespaces[namespaces[j]].total {
+			return set.namespaces[namespaces[i]].total > set.namespaces[namespaces[j]].total
+		}
+		return namespaces[i] < namespaces[j]
+	})
 
+	totals := map[string]int{}
+	failures := map[string]int{}
+	for idx, namespace := range namespaces {
+		labelValue := namespace
+		if idx >= namespaceLabelLimit {
+			labelValue = OtherNamespacesLabelValue
+		}
+		totals[labelValue] += set.namespaces[namespace].total
+		failures[labelValue] += set.namespaces[namespace].failures
+	}
+
+	// Remove the label values that are no longer reported, for example because the namespace is no longer in the top namespaces
+	for labelValue := range set.namespaceLabels {
+		if _, exists := totals[labelValue]; !exists {
+			GitopsdeplByNamespace.DeleteLabelValues(labelValue)
+			GitopsdeplFailuresByNamespace.DeleteLabelValues(labelValue)
+			delete(set.namespaceLabels, labelValue)
+		}
+	}
+
+	for labelValue, total := range totals {
+		GitopsdeplByNamespace.WithLabelValues(labelValue).Set(float64(total))
+		GitopsdeplFailuresByNamespace.WithLabelValues(labelValue).Set(float64(failures[labelValue]))
+		set.namespaceLabels[labelValue] = true
+	}
 }
 
 func ClearMetrics() {
@@ -126,9 +269,16 @@ func ClearMetrics() {
 	defer activeGitOpsDeployments.mutex.Unlock()
 
 	activeGitOpsDeployments.gitOpsDeployments = map[string]bool{}
+	activeGitOpsDeployments.namespaces = map[string]*namespaceDeploymentCount{}
+	activeGitOpsDeployments.namespaceLabels = map[string]bool{}
+	GitopsdeplByNamespace.Reset()
+	GitopsdeplFailuresByNamespace.Reset()
+	ApplicationEventsWaiting.Set(0)
+	ApplicationEventsCoalesced.Reset()
 }
 
 func init() {
-	metric.Registry.MustRegister(Gitopsdepl, GitopsdeplFailures, OperationDBRows, OperationDBRowsInWaitingState, OperationDBRowsIn_InProgressState,
-		OperationDBRowsInCompletedState, OperationDBRowsInErrorState, TotalOperationDBRowsInCompletedState, TotalOperationDBRowsInNonCompleteState)
+	metric.Registry.MustRegister(Gitopsdepl, GitopsdeplFailures, GitopsdeplByNamespace, GitopsdeplFailuresByNamespace, OperationDBRows, OperationDBRowsInWaitingState, OperationDBRowsIn_InProgressState,
+		OperationDBRowsInCompletedState, OperationDBRowsInErrorState, TotalOperationDBRowsInCompletedState, TotalOperationDBRowsInNonCompleteState,
+		ApplicationEventsWaiting, ApplicationEventsCoalesced)
 }
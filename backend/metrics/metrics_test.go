@@ -85,4 +85,33 @@ var _ = Describe("Test for Gitopsdeployment metrics counter", func() {
 			Expect(activeGitOpsDeployments.gitOpsDeployments[key]).To(BeTrue())
 		})
 	})
+
+	Context("Prometheus metrics report the count of GitOpsDeployments by namespace, with a limit on the number of namespaces", func() {
+		It("reports the namespaces with the most GitOpsDeployments under their own label, and the rest under 'other'", func() {
+
+			ClearMetrics()
+			SetNamespaceLabelLimit(1)
+			defer SetNamespaceLabelLimit(defaultNamespaceLabelLimit)
+
+			By("adding two GitOpsDeployments in one namespace, and one in another")
+			AddOrUpdateGitOpsDeployment("depl-1", "namespace-a", "namespace-a-uid")
+			AddOrUpdateGitOpsDeployment("depl-2", "namespace-a", "namespace-a-uid")
+			AddOrUpdateGitOpsDeployment("depl-3", "namespace-b", "namespace-b-uid")
+			SetErrorState("depl-3", "namespace-b", "namespace-b-uid", true)
+
+			Expect(testutil.ToFloat64(GitopsdeplByNamespace.WithLabelValues("namespace-a"))).To(Equal(float64(2)))
+			Expect(testutil.ToFloat64(GitopsdeplByNamespace.WithLabelValues(OtherNamespacesLabelValue))).To(Equal(float64(1)))
+			Expect(testutil.ToFloat64(GitopsdeplFailuresByNamespace.WithLabelValues("namespace-a"))).To(Equal(float64(0)))
+			Expect(testutil.ToFloat64(GitopsdeplFailuresByNamespace.WithLabelValues(OtherNamespacesLabelValue))).To(Equal(float64(1)))
+			Expect(testutil.CollectAndCount(GitopsdeplByNamespace)).To(Equal(2))
+
+			By("removing the GitOpsDeployments of the first namespace, which should remove its label")
+			RemoveGitOpsDeployment("depl-1", "namespace-a", "namespace-a-uid")
+			RemoveGitOpsDeployment("depl-2", "namespace-a", "namespace-a-uid")
+
+			Expect(testutil.CollectAndCount(GitopsdeplByNamespace)).To(Equal(1))
+			Expect(testutil.ToFloat64(GitopsdeplByNamespace.WithLabelValues("namespace-b"))).To(Equal(float64(1)))
+			Expect(testutil.ToFloat64(GitopsdeplFailuresByNamespace.WithLabelValues("namespace-b"))).To(Equal(float64(1)))
+		})
+	})
 })
@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+)
+
+/*
+Admin
+
+The admin endpoints are only served if an admin API token is configured, and every request must provide that token
+as a bearer token (Authorization: Bearer <token>).
+
+/api/v1/admin/clusteruser/(user-name)/deactivate
+POST: Deactivate the ClusterUser (revoking its ClusterAccess), and schedule the deletion of its RepositoryCredentials and Applications
+
+/api/v1/admin/clusteruser/(user-name)/purge
+POST: Delete the data of a deactivated ClusterUser, and then the ClusterUser itself
+*/
+
+// ClusterUserOffboarder deactivates and purges ClusterUsers (see eventloop.ClusterUserOffboarder).
+type ClusterUserOffboarder interface {
+	DeactivateClusterUser(ctx context.Context, userName string, log logr.Logger) (db.ClusterUser, error)
+	PurgeClusterUser(ctx context.Context, userName string, log logr.Logger) error
+}
+
+// ClusterUserResponse is the response of the ClusterUser admin endpoints.
+type ClusterUserResponse struct {
+	UserName      string     `json:"userName"`
+	DeactivatedOn *time.Time `json:"deactivatedOn,omitempty"`
+	Purged        bool       `json:"purged,omitempty"`
+}
+
+// AdminResource serves the admin endpoints of the backend.
+type AdminResource struct {
+	Offboarder ClusterUserOffboarder
+
+	// Token is the bearer token that must be provided by every request
+	Token string
+}
+
+// Register adds the admin endpoints to the container
+func (a AdminResource) Register(container *restful.Container) {
+	ws := new(restful.WebService)
+	ws.
+		Path("/api/v1/admin").
+		Produces(restful.MIME_JSON).
+		Filter(a.authenticate)
+
+	ws.Route(ws.POST("/clusteruser/{user-name}/deactivate").To(a.deactivateClusterUser))
+	ws.Route(ws.POST("/clusteruser/{user-name}/purge").To(a.purgeClusterUser))
+	container.Add(ws)
+}
+
+// authenticate rejects requests that do not provide the admin API token
+func (a AdminResource) authenticate(request *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+
+	token := strings.TrimPrefix(request.HeaderParameter("Authorization"), "Bearer ")
+
+	if a.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) != 1 {
+		writeError(response, http.StatusUnauthorized, "a valid admin API token is required")
+		return
+	}
+
+	chain.ProcessFilter(request, response)
+}
+
+func (a AdminResource) deactivateClusterUser(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
+	userName := request.PathParameter("user-name")
+
+	clusterUser, err := a.Offboarder.DeactivateClusterUser(ctx, userName, getLogger(ctx, userName))
+	if err != nil {
+		if db.IsResultNotFoundError(err) {
+			writeError(response, http.StatusNotFound, "ClusterUser not found")
+		} else {
+			writeError(response, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeEntity(response, ClusterUserResponse{UserName: clusterUser.User_name, DeactivatedOn: clusterUser.Deactivated_on})
+}
+
+func (a AdminResource) purgeClusterUser(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
+	userName := request.PathParameter("user-name")
+
+	if err := a.Offboarder.PurgeClusterUser(ctx, userName, getLogger(ctx, userName)); err != nil {
+		writeError(response, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeEntity(response, ClusterUserResponse{UserName: userName, Purged: true})
+}
+
+func getLogger(ctx context.Context, userName string) logr.Logger {
+	return log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("component", "admin-api", "user_name", userName)
+}
+
+func writeEntity(response *restful.Response, entity interface{}) {
+	if err := response.WriteEntity(entity); err != nil {
+		log.Log.Error(err, "unable to write admin API response")
+	}
+}
+
+func writeError(response *restful.Response, status int, message string) {
+	response.AddHeader("Content-Type", "text/plain")
+	if err := response.WriteErrorString(status, message); err != nil {
+		log.Log.Error(err, "unable to write admin API error response")
+	}
+}
@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+type fakeClusterUserOffboarder struct {
+	deactivated []string
+	purged      []string
+}
+
+func (f *fakeClusterUserOffboarder) DeactivateClusterUser(ctx context.Context, userName string, log logr.Logger) (db.ClusterUser, error) {
+	if userName == "unknown-user" {
+		return db.ClusterUser{}, db.NewResultNotFoundError("no results found for GetClusterUserByUsername")
+	}
+	f.deactivated = append(f.deactivated, userName)
+
+	now := time.Now()
+	return db.ClusterUser{User_name: userName, Deactivated_on: &now}, nil
+}
+
+func (f *fakeClusterUserOffboarder) PurgeClusterUser(ctx context.Context, userName string, log logr.Logger) error {
+	if userName == "active-user" {
+		return fmt.Errorf("ClusterUser '%s' must be deactivated before it is purged", userName)
+	}
+	f.purged = append(f.purged, userName)
+	return nil
+}
+
+func TestAdminClusterUserRoutes(t *testing.T) {
+
+	offboarder := &fakeClusterUserOffboarder{}
+
+	container := restful.NewContainer()
+	container.Router(restful.CurlyRouter{})
+	AdminResource{Offboarder: offboarder, Token: "admin-token"}.Register(container)
+
+	post := func(path string, token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, path, nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		response := httptest.NewRecorder()
+		container.ServeHTTP(response, request)
+		return response
+	}
+
+	// Requests without a valid token should be rejected
+	assert.Equal(t, http.StatusUnauthorized, post("/api/v1/admin/clusteruser/my-user/deactivate", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, post("/api/v1/admin/clusteruser/my-user/purge", "wrong-token").Code)
+	assert.Empty(t, offboarder.deactivated)
+	assert.Empty(t, offboarder.purged)
+
+	response := post("/api/v1/admin/clusteruser/my-user/deactivate", "admin-token")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "deactivatedOn")
+	assert.Equal(t, []string{"my-user"}, offboarder.deactivated)
+
+	assert.Equal(t, http.StatusNotFound, post("/api/v1/admin/clusteruser/unknown-user/deactivate", "admin-token").Code)
+
+	assert.Equal(t, http.StatusOK, post("/api/v1/admin/clusteruser/my-user/purge", "admin-token").Code)
+	assert.Equal(t, []string{"my-user"}, offboarder.purged)
+
+	assert.Equal(t, http.StatusInternalServerError, post("/api/v1/admin/clusteruser/active-user/purge", "admin-token").Code)
+}
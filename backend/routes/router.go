@@ -7,6 +7,7 @@ import (
 
 	restful "github.com/emicklei/go-restful/v3"
 
+	admin "github.com/redhat-appstudio/managed-gitops/backend/routes/admin"
 	webhooks "github.com/redhat-appstudio/managed-gitops/backend/routes/webhooks"
 )
 
@@ -52,3 +53,17 @@ func RouteInit() *http.Server {
 
 	return server
 }
+
+// AdminRouteInit returns the server of the admin endpoints. The admin endpoints are served on a separate port from the
+// other endpoints, so that they are not exposed by the Service of the backend.
+func AdminRouteInit(adminResource admin.AdminResource) *http.Server {
+	wsContainer := restful.NewContainer()
+	wsContainer.Router(restful.CurlyRouter{})
+
+	adminResource.Register(wsContainer)
+
+	log.Print("Main: the admin server is up, and listening to port 8091 on your host.")
+	server := &http.Server{Addr: ":8091", Handler: wsContainer, ReadHeaderTimeout: time.Second * 30}
+
+	return server
+}
@@ -21,16 +21,20 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 
 	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
 	"github.com/go-logr/logr"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
@@ -38,8 +42,10 @@ import (
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers/argoproj.io/application_info_cache"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/utils"
 	"gopkg.in/yaml.v2"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -57,6 +63,157 @@ type ApplicationReconciler struct {
 	Cache *application_info_cache.ApplicationInfoCache
 
 	DB db.DatabaseQueries
+
+	// RevisionResolver validates that the target revision of each Argo CD Application exists in its Git repository.
+	// If nil, target revisions are not validated.
+	RevisionResolver utils.RevisionResolver
+
+	// DiffPreviewer retrieves the diff of an Argo CD Application from Argo CD, when a diff preview is requested via the
+	// diff preview annotation. If nil, diff previews are not generated.
+	DiffPreviewer utils.DiffPreviewer
+
+	// ResourceEstimator retrieves the resources requested by the Pods of an Argo CD Application from Argo CD. If nil,
+	// resource estimates are not collected.
+	ResourceEstimator utils.ResourceEstimator
+
+	// HookLogRetriever retrieves the logs of the failed hook Jobs of an Argo CD Application from Argo CD, for sync
+	// operations requested by a GitOpsDeploymentSyncRun. If nil, the logs of failed hooks are not retrieved.
+	HookLogRetriever utils.HookLogRetriever
+
+	webhookRefreshes webhookRefreshTracker
+
+	deploymentHistories deploymentHistoryTracker
+}
+
+// revisionResolutionRequeueDelay is how long to wait before reconciling an Application again, when the refs of its Git
+// repository are being retrieved in the background by the RevisionResolver.
+const revisionResolutionRequeueDelay = 5 * time.Second
+
+// webhookSyncWindow is the maximum time between an Application being refreshed by the Argo CD Git webhook, and an
+// automated sync starting, for the sync to be considered initiated by the webhook.
+const webhookSyncWindow = time.Minute
+
+const (
+	// diffPreviewResourceLimit is the maximum number of resources that are reported in a diff preview
+	diffPreviewResourceLimit = 100
+
+	// diffPreviewTimeout is the maximum amount of time to wait for Argo CD to return the diff of an Application
+	diffPreviewTimeout = 30 * time.Second
+
+	// diffPreviewErrorLength is the maximum length of the error of a diff preview
+	diffPreviewErrorLength = 1024
+)
+
+const (
+	// resourceEstimateRefreshInterval is how often the resource estimate of an Application is collected, if its synced
+	// revision has not changed
+	resourceEstimateRefreshInterval = 5 * time.Minute
+
+	// resourceEstimateTimeout is the maximum amount of time to wait for Argo CD to return the Pods of an Application
+	resourceEstimateTimeout = 30 * time.Second
+
+	// resourceEstimateErrorLength is the maximum length of the error of a resource estimate
+	resourceEstimateErrorLength = 1024
+)
+
+const (
+	// hookLogLimit is the maximum number of failed hook Jobs whose logs are retrieved, for a single sync operation
+	hookLogLimit = 5
+
+	// hookLogTailLines is the number of lines of the logs of a failed hook Job that are retrieved
+	hookLogTailLines = 20
+
+	// hookLogTailLength is the maximum length of the logs of a failed hook Job
+	hookLogTailLength = 2048
+
+	// hookLogTimeout is the maximum amount of time to wait for Argo CD to return the logs of a hook Job
+	hookLogTimeout = 30 * time.Second
+
+	// hookMessageLength is the maximum length of the message of a hook
+	hookMessageLength = 1024
+)
+
+// webhookRefreshTracker records when each Argo CD Application was last observed with the refresh annotation.
+//
+// Argo CD's Git webhook handler sets the refresh annotation on each Application that references the pushed repository,
+// and Argo CD removes it once the Application is refreshed. The GitOps Service only sets the annotation itself before a
+// (non-automated) sync of a GitOpsDeploymentSyncRun, so an automated sync that follows the refresh was caused by the webhook.
+type webhookRefreshTracker struct {
+	mutex       sync.Mutex
+	lastRefresh map[types.NamespacedName]time.Time
+}
+
+// observe records the current time as the last refresh of the Application, if it has the refresh annotation, and
+// returns the time of the last observed refresh (or nil if none has been observed).
+func (w *webhookRefreshTracker) observe(name types.NamespacedName, app appv1.Application) *time.Time {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, exists := app.Annotations[appv1.AnnotationKeyRefresh]; exists {
+		if w.lastRefresh == nil {
+			w.lastRefresh = map[types.NamespacedName]time.Time{}
+		}
+		w.lastRefresh[name] = time.Now()
+	}
+
+	lastRefresh, exists := w.lastRefresh[name]
+	if !exists {
+		return nil
+	}
+	return &lastRefresh
+}
+
+// forget removes the last refresh of a deleted Application.
+func (w *webhookRefreshTracker) forget(name types.NamespacedName) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	delete(w.lastRefresh, name)
+}
+
+// deploymentHistoryTracker records the Argo CD history ID of the most recent DeploymentHistory row of each Argo CD
+// Application, so that the DeploymentHistory table is only queried when the '.status.history' field of the Application
+// has a new entry (or when the Application is first reconciled).
+type deploymentHistoryTracker struct {
+	mutex        sync.Mutex
+	lastRecorded map[types.NamespacedName]recordedDeploymentHistory
+}
+
+type recordedDeploymentHistory struct {
+	applicationID   string
+	argoCDHistoryID int64
+}
+
+// get returns the history ID of the most recent DeploymentHistory row of the Application row, and true, or false if
+// it is not known.
+func (d *deploymentHistoryTracker) get(name types.NamespacedName, applicationID string) (int64, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	recorded, exists := d.lastRecorded[name]
+	if !exists || recorded.applicationID != applicationID {
+		return 0, false
+	}
+	return recorded.argoCDHistoryID, true
+}
+
+// set records the history ID of the most recent DeploymentHistory row of the Application row.
+func (d *deploymentHistoryTracker) set(name types.NamespacedName, applicationID string, argoCDHistoryID int64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.lastRecorded == nil {
+		d.lastRecorded = map[types.NamespacedName]recordedDeploymentHistory{}
+	}
+	d.lastRecorded[name] = recordedDeploymentHistory{applicationID: applicationID, argoCDHistoryID: argoCDHistoryID}
+}
+
+// forget removes the recorded history ID of a deleted Application.
+func (d *deploymentHistoryTracker) forget(name types.NamespacedName) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.lastRecorded, name)
 }
 
 //+kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;list;watch;create;update;patch;delete
@@ -79,6 +236,8 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err := r.Get(ctx, req.NamespacedName, &app); err != nil {
 		if apierr.IsNotFound(err) {
 			log.Info("Application deleted '" + req.NamespacedName.String() + "'")
+			r.webhookRefreshes.forget(req.NamespacedName)
+			r.deploymentHistories.forget(req.NamespacedName)
 			return ctrl.Result{}, nil
 		} else {
 			log.Error(err, "Unexpected error on retrieving Application '"+req.NamespacedName.String()+"'")
@@ -86,6 +245,10 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// Argo CD's Git webhook handler requests a refresh of the Application when a commit is pushed: note when this
+	// occurs, so that an automated sync caused by the webhook can be reported as such.
+	webhookRefreshedAt := r.webhookRefreshes.observe(req.NamespacedName, app)
+
 	// 2) Retrieve the Application DB entry, using the 'databaseID' field of the Application.
 	// - This is a field we add ourselves to every Application we create, which references the
 	//   corresponding Application row primary key.
@@ -126,7 +289,8 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		Applicationstate_application_id: applicationDB.Application_id,
 	}
 
-	if _, _, errGet := r.Cache.GetApplicationStateById(ctx, applicationState.Applicationstate_application_id); errGet != nil {
+	existingApplicationState, _, errGet := r.Cache.GetApplicationStateById(ctx, applicationState.Applicationstate_application_id)
+	if errGet != nil {
 		if db.IsResultNotFoundError(errGet) {
 
 			// 3a) ApplicationState doesn't exist: so create it
@@ -165,12 +329,49 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			}
 			applicationState.SyncError = syncErrorMessageFromArgoApplication
 
+			// Store the details of the last sync operation of the Argo CD Application (who/what initiated it, and when)
+			applicationState.LastSync, err = convertOperationStateToLastSync(app, webhookRefreshedAt)
+			if err != nil {
+				log.Error(err, "unable to store the last sync operation in ApplicationState")
+				return ctrl.Result{}, err
+			}
+
+			// Store the details of the sync operation that is in progress, if any, so that the GitOpsDeployment does not look idle
+			applicationState.OperationState, err = convertOperationStateToOperationInProgress(app)
+			if err != nil {
+				log.Error(err, "unable to store the operation in progress in ApplicationState")
+				return ctrl.Result{}, err
+			}
+
+			revisionPending := r.resolveTargetRevision(ctx, app, applicationState, nil, log)
+
+			if err := r.previewDiff(ctx, app, applicationState, nil, log); err != nil {
+				log.Error(err, "unable to store the diff preview in ApplicationState")
+				return ctrl.Result{}, err
+			}
+
+			if err := r.estimateResources(ctx, app, applicationState, nil, log); err != nil {
+				log.Error(err, "unable to store the resource estimate in ApplicationState")
+				return ctrl.Result{}, err
+			}
+
+			if err := r.recordSyncHooks(ctx, app, applicationState, nil, log); err != nil {
+				log.Error(err, "unable to store the status of the sync hooks in ApplicationState")
+				return ctrl.Result{}, err
+			}
+
 			if errCreate := r.Cache.CreateApplicationState(ctx, *applicationState); errCreate != nil {
 				log.Error(errCreate, "unexpected error on writing new application state")
 				return ctrl.Result{}, errCreate
 			}
+
+			if err := r.recordDeploymentHistory(ctx, app, applicationDB.Application_id, webhookRefreshedAt, log); err != nil {
+				log.Error(err, "unable to record the deployment history of the Application")
+				return ctrl.Result{}, err
+			}
+
 			// Successfully created ApplicationState
-			return ctrl.Result{}, nil
+			return revisionResolutionResult(app, revisionPending, log), nil
 		} else {
 			log.Error(errGet, "Unable to retrieve ApplicationState from database: "+applicationDB.Application_id)
 			return ctrl.Result{}, errGet
@@ -213,31 +414,476 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 	applicationState.SyncError = syncErrorMessageFromArgoApplication
 
-	if err := r.Cache.UpdateApplicationState(ctx, *applicationState); err != nil {
+	// Store the details of the last sync operation of the Argo CD Application (who/what initiated it, and when)
+	applicationState.LastSync, err = convertOperationStateToLastSync(app, webhookRefreshedAt)
+	if err != nil {
+		log.Error(err, "unable to store the last sync operation in ApplicationState")
+		return ctrl.Result{}, err
+	}
 
-		if strings.Contains(err.Error(), db.ErrorUnexpectedNumberOfRowsAffected) {
-			log.V(logutil.LogLevel_Warn).Error(err, "unexpected error on updating existing application state (but the Application might have been deleted)")
-		} else {
-			log.Error(err, "unexpected error on updating existing application state")
+	// Store the details of the sync operation that is in progress, if any, so that the GitOpsDeployment does not look idle
+	applicationState.OperationState, err = convertOperationStateToOperationInProgress(app)
+	if err != nil {
+		log.Error(err, "unable to store the operation in progress in ApplicationState")
+		return ctrl.Result{}, err
+	}
+
+	revisionPending := r.resolveTargetRevision(ctx, app, applicationState, &existingApplicationState, log)
+
+	if err := r.previewDiff(ctx, app, applicationState, &existingApplicationState, log); err != nil {
+		log.Error(err, "unable to store the diff preview in ApplicationState")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.estimateResources(ctx, app, applicationState, &existingApplicationState, log); err != nil {
+		log.Error(err, "unable to store the resource estimate in ApplicationState")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.recordSyncHooks(ctx, app, applicationState, &existingApplicationState, log); err != nil {
+		log.Error(err, "unable to store the status of the sync hooks in ApplicationState")
+		return ctrl.Result{}, err
+	}
+
+	if r.Cache.BatchedWritesEnabled() {
+
+		// The ApplicationState is written to the database with the next batch, along with the ApplicationStates of
+		// other Applications. (The upsert only rewrites the resources column of the row if it has changed.)
+		if err := r.Cache.QueueApplicationStateUpsert(ctx, *applicationState); err != nil {
+			log.Error(err, "unexpected error on queueing update of existing application state")
+			return ctrl.Result{}, err
+		}
+
+	} else {
+
+		// If only the health of the Application has changed, avoid rewriting the (potentially large) resources column of the row.
+		updateApplicationState := r.Cache.UpdateApplicationState
+		if isApplicationStateHealthOnlyChange(existingApplicationState, *applicationState) {
+			updateApplicationState = r.Cache.UpdateApplicationStateHealthOnly
 		}
 
+		if err := updateApplicationState(ctx, *applicationState); err != nil {
+
+			if strings.Contains(err.Error(), db.ErrorUnexpectedNumberOfRowsAffected) {
+				log.V(logutil.LogLevel_Warn).Error(err, "unexpected error on updating existing application state (but the Application might have been deleted)")
+			} else {
+				log.Error(err, "unexpected error on updating existing application state")
+			}
+
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.recordDeploymentHistory(ctx, app, applicationDB.Application_id, webhookRefreshedAt, log); err != nil {
+		log.Error(err, "unable to record the deployment history of the Application")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return revisionResolutionResult(app, revisionPending, log), nil
 
 }
 
-func sanitizeHealthAndStatus(applicationState *db.ApplicationState) {
+// recordDeploymentHistory creates a DeploymentHistory row for each entry of the '.status.history' field of the Argo CD
+// Application that has not yet been recorded in the database. Argo CD adds an entry to the history on each successful sync.
+// - The deployed images and the sync initiator are only known for the most recent entry: older entries that have not
+// yet been recorded (for example, syncs that occurred before this feature was enabled) are recorded without them.
+func (r *ApplicationReconciler) recordDeploymentHistory(ctx context.Context, app appv1.Application, applicationID string,
+	webhookRefreshedAt *time.Time, log logr.Logger) error {
 
-	if applicationState.Health == "" {
-		applicationState.Health = "Unknown"
+	if len(app.Status.History) == 0 {
+		return nil
 	}
 
-	if applicationState.Sync_Status == "" {
-		applicationState.Sync_Status = "Unknown"
+	name := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+	newestHistory := app.Status.History[len(app.Status.History)-1]
+
+	// Argo CD history IDs increase with each sync, so only entries with a greater ID than the most recently recorded
+	// entry need to be recorded.
+	latestRecordedID, hasRecorded := r.deploymentHistories.get(name, applicationID)
+
+	if hasRecorded && newestHistory.ID <= latestRecordedID {
+		// No new entries since the last reconcile, so there is no need to query the database
+		return nil
 	}
 
+	if !hasRecorded {
+		var latestRecorded []db.DeploymentHistory
+		if err := r.DB.ListDeploymentHistoryByApplicationID(ctx, applicationID, 1, &latestRecorded); err != nil {
+			return err
+		}
+
+		if len(latestRecorded) > 0 {
+			latestRecordedID, hasRecorded = latestRecorded[0].ArgoCDHistoryID, true
+			r.deploymentHistories.set(name, applicationID, latestRecordedID)
+		}
+	}
+
+	for _, history := range app.Status.History {
+
+		if hasRecorded && history.ID <= latestRecordedID {
+			continue
+		}
+
+		deploymentHistory := db.DeploymentHistory{
+			ApplicationID:   applicationID,
+			ArgoCDHistoryID: history.ID,
+			Revision:        db.TruncateVarchar(history.Revision, db.DeploymentHistoryRevisionLength),
+			DeployedAt:      history.DeployedAt.Time,
+		}
+
+		if deploymentHistory.DeployedAt.IsZero() {
+			deploymentHistory.DeployedAt = time.Now()
+		}
+
+		if history.ID == newestHistory.ID {
+			deploymentHistory.Images = db.TruncateVarchar(strings.Join(app.Status.Summary.Images, ","), db.DeploymentHistoryImagesLength)
+
+			if operationState := app.Status.OperationState; operationState != nil && operationState.SyncResult != nil &&
+				operationState.SyncResult.Revision == history.Revision {
+
+				deploymentHistory.Initiator = db.TruncateVarchar(getSyncInitiator(*operationState, webhookRefreshedAt), db.DeploymentHistoryInitiatorLength)
+			}
+		}
+
+		if err := r.DB.CreateDeploymentHistory(ctx, &deploymentHistory); err != nil {
+			return err
+		}
+		r.deploymentHistories.set(name, applicationID, history.ID)
+
+		log.Info("Recorded deployment history of Application", deploymentHistory.GetAsLogKeyValues()...)
+	}
+
+	return nil
+}
+
+// resolveTargetRevision validates that the .spec.source.targetRevision of the Argo CD Application exists in its Git
+// repository, and stores the commit SHA that it resolves to (or the reason it could not be resolved) in 'applicationState'.
+// - The Git repository is not queried by Reconcile: only the cached refs of the repository are used. If they are not
+// cached, they are retrieved in the background, and true is returned, to indicate the Application should be requeued.
+// - If the repository could not be queried (for example, due to a network error), the values of
+// 'previousApplicationState' (if non-nil) are kept.
+func (r *ApplicationReconciler) resolveTargetRevision(ctx context.Context, app appv1.Application,
+	applicationState *db.ApplicationState, previousApplicationState *db.ApplicationState, log logr.Logger) bool {
+
+	// Helm chart repositories are not Git repositories, so their revisions cannot be resolved.
+	if r.RevisionResolver == nil || app.Spec.Source.Chart != "" {
+		return false
+	}
+
+	repoURL := app.Spec.Source.RepoURL
+
+	resolvedRevision, err := r.RevisionResolver.ResolveCachedRevision(ctx, r.Client, app.Namespace, repoURL, app.Spec.Source.TargetRevision)
+	if err != nil && !errors.Is(err, utils.ErrRevisionNotFound) {
+
+		revisionPending := errors.Is(err, utils.ErrRefsNotCached)
+		if !revisionPending {
+			log.V(logutil.LogLevel_Warn).Info("unable to resolve target revision of Application", "repoURL", repoURL, "error", err.Error())
+		}
+
+		if previousApplicationState != nil {
+			applicationState.ResolvedRevision = previousApplicationState.ResolvedRevision
+			applicationState.RevisionError = previousApplicationState.RevisionError
+		}
+		return revisionPending
+	}
+
+	applicationState.ResolvedRevision = db.TruncateVarchar(resolvedRevision, db.ApplicationStateResolvedRevisionLength)
+	applicationState.RevisionError = ""
+
+	if err != nil {
+		applicationState.RevisionError = db.TruncateVarchar(fmt.Sprintf("unable to resolve target revision in repository '%s': %v",
+			repoURL, err), db.ApplicationStateRevisionErrorLength)
+	}
+
+	return false
+}
+
+// previewDiff stores a summary of the diff of the Argo CD Application in 'applicationState', if one has been requested
+// via the diff preview annotation of the Application.
+//
+// The diff is only retrieved from Argo CD when the request ID of the annotation changes: otherwise, the diff preview of
+// 'previousApplicationState' (if non-nil) is kept. If the diff could not be retrieved, the error is stored in the diff
+// preview, and a new request ID is required to retry. The diff preview is removed when the annotation is removed.
+func (r *ApplicationReconciler) previewDiff(ctx context.Context, app appv1.Application,
+	applicationState *db.ApplicationState, previousApplicationState *db.ApplicationState, log logr.Logger) error {
+
+	requestID := app.Annotations[argosharedutil.DiffPreviewAnnotation]
+	if requestID == "" || r.DiffPreviewer == nil {
+		applicationState.DiffPreview = ""
+		return nil
+	}
+
+	if previousApplicationState != nil && previousApplicationState.DiffPreview != "" {
+		previousDiffPreview := fauxargocd.FauxDiffPreview{}
+		if err := json.Unmarshal([]byte(previousApplicationState.DiffPreview), &previousDiffPreview); err == nil &&
+			previousDiffPreview.RequestID == requestID {
+
+			applicationState.DiffPreview = previousApplicationState.DiffPreview
+			return nil
+		}
+	}
+
+	generatedAt := time.Now()
+	diffPreview := fauxargocd.FauxDiffPreview{
+		RequestID:   requestID,
+		GeneratedAt: &generatedAt,
+	}
+
+	diffCtx, cancel := context.WithTimeout(ctx, diffPreviewTimeout)
+	defer cancel()
+
+	resourceDiffs, err := r.DiffPreviewer.GetResourceDiffs(diffCtx, r.Client, app.Namespace, app.Name)
+	if err != nil {
+		log.V(logutil.LogLevel_Warn).Info("unable to retrieve the diff of Application", "requestID", requestID, "error", err.Error())
+		diffPreview.Error = db.TruncateVarchar(err.Error(), diffPreviewErrorLength)
+	} else {
+		diffPreview.TotalResources = len(resourceDiffs)
+		if len(resourceDiffs) > diffPreviewResourceLimit {
+			resourceDiffs = resourceDiffs[:diffPreviewResourceLimit]
+		}
+		diffPreview.Resources = resourceDiffs
+	}
+
+	// Reduce the number of reported resources until the diff preview fits in the database column
+	for {
+		diffPreviewBytes, err := json.Marshal(diffPreview)
+		if err != nil {
+			return fmt.Errorf("unable to marshal diff preview: %v", err)
+		}
+
+		if len(diffPreviewBytes) <= db.ApplicationStateDiffPreviewLength || len(diffPreview.Resources) == 0 {
+			applicationState.DiffPreview = string(diffPreviewBytes)
+			break
+		}
+
+		diffPreview.Resources = diffPreview.Resources[:len(diffPreview.Resources)/2]
+	}
+
+	log.Info("Generated diff preview of Application", "requestID", requestID, "totalResources", diffPreview.TotalResources)
+
+	return nil
+}
+
+// estimateResources stores an estimate of the CPU and memory requested by the Pods of the Argo CD Application in
+// 'applicationState', if a ResourceEstimator is configured.
+//
+// The estimate of 'previousApplicationState' (if non-nil) is kept, unless the synced revision of the Application has
+// changed, or the estimate is older than resourceEstimateRefreshInterval. If the estimate could not be collected, the
+// error is stored in the estimate (and collection is retried after the same interval).
+func (r *ApplicationReconciler) estimateResources(ctx context.Context, app appv1.Application,
+	applicationState *db.ApplicationState, previousApplicationState *db.ApplicationState, log logr.Logger) error {
+
+	if r.ResourceEstimator == nil {
+		applicationState.ResourceEstimate = ""
+		return nil
+	}
+
+	if previousApplicationState != nil && previousApplicationState.ResourceEstimate != "" {
+		previousEstimate := fauxargocd.FauxResourceEstimate{}
+		if err := json.Unmarshal([]byte(previousApplicationState.ResourceEstimate), &previousEstimate); err == nil &&
+			previousEstimate.Revision == app.Status.Sync.Revision && previousEstimate.CollectedAt != nil &&
+			time.Since(*previousEstimate.CollectedAt) < resourceEstimateRefreshInterval {
+
+			applicationState.ResourceEstimate = previousApplicationState.ResourceEstimate
+			return nil
+		}
+	}
+
+	collectedAt := time.Now()
+	resourceEstimate := fauxargocd.FauxResourceEstimate{
+		CollectedAt: &collectedAt,
+		Revision:    app.Status.Sync.Revision,
+	}
+
+	estimateCtx, cancel := context.WithTimeout(ctx, resourceEstimateTimeout)
+	defer cancel()
+
+	podRequests, err := r.ResourceEstimator.GetPodResourceRequests(estimateCtx, r.Client, app.Namespace, app.Name)
+	if err != nil {
+		log.V(logutil.LogLevel_Warn).Info("unable to estimate the resources of Application", "error", err.Error())
+		resourceEstimate.Error = db.TruncateVarchar(err.Error(), resourceEstimateErrorLength)
+	} else {
+		resourceEstimate.CPURequests = podRequests.CPU.String()
+		resourceEstimate.MemoryRequests = podRequests.Memory.String()
+		resourceEstimate.Pods = podRequests.Pods
+		resourceEstimate.TotalPods = podRequests.TotalPods
+	}
+
+	resourceEstimateBytes, err := json.Marshal(resourceEstimate)
+	if err != nil {
+		return fmt.Errorf("unable to marshal resource estimate: %v", err)
+	}
+	applicationState.ResourceEstimate = string(resourceEstimateBytes)
+
+	return nil
+}
+
+// recordSyncHooks stores the status of the resource hooks that were run by the last sync operation of the Argo CD
+// Application in 'applicationState', if the sync operation was requested by a GitOpsDeploymentSyncRun.
+//
+// The tail of the logs of hook Jobs that failed is retrieved from Argo CD (if a HookLogRetriever is configured). The logs
+// are only retrieved once per hook: the logs of 'previousApplicationState' (if non-nil) are kept for the hooks of the
+// same sync operation whose phase has not changed.
+func (r *ApplicationReconciler) recordSyncHooks(ctx context.Context, app appv1.Application,
+	applicationState *db.ApplicationState, previousApplicationState *db.ApplicationState, log logr.Logger) error {
+
+	applicationState.SyncHooks = ""
+
+	operationState := app.Status.OperationState
+	if operationState == nil || operationState.SyncResult == nil {
+		return nil
+	}
+
+	initiator := getSyncInitiator(*operationState, nil)
+	if !strings.HasPrefix(initiator, db.SyncOperation_Initiator_SyncRunPrefix) {
+		return nil
+	}
+
+	syncHooks := fauxargocd.FauxSyncHooks{
+		Initiator: db.TruncateVarchar(initiator, db.SyncOperationInitiatorLength),
+	}
+	if !operationState.StartedAt.IsZero() {
+		startedAt := operationState.StartedAt.Time
+		syncHooks.StartedAt = &startedAt
+	}
+
+	// The hooks of the previous ApplicationState, if it was for the same sync operation
+	previousHooks := map[string]fauxargocd.FauxSyncHook{}
+	if previousApplicationState != nil && previousApplicationState.SyncHooks != "" {
+		previousSyncHooks := fauxargocd.FauxSyncHooks{}
+		if err := json.Unmarshal([]byte(previousApplicationState.SyncHooks), &previousSyncHooks); err == nil &&
+			previousSyncHooks.Initiator == syncHooks.Initiator && previousSyncHooks.StartedAt != nil &&
+			syncHooks.StartedAt != nil && previousSyncHooks.StartedAt.Equal(*syncHooks.StartedAt) {
+
+			for _, hook := range previousSyncHooks.Hooks {
+				previousHooks[getSyncHookKey(hook)] = hook
+			}
+		}
+	}
+
+	logsRetrieved := 0
+	for _, resource := range operationState.SyncResult.Resources {
+
+		if resource == nil || resource.HookType == "" {
+			continue
+		}
+
+		hook := fauxargocd.FauxSyncHook{
+			Group:     resource.Group,
+			Kind:      resource.Kind,
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+			HookType:  string(resource.HookType),
+			Phase:     string(resource.HookPhase),
+			Message:   db.TruncateVarchar(resource.Message, hookMessageLength),
+		}
+
+		if isFailedHookJob(*resource) {
+			if previousHook, exists := previousHooks[getSyncHookKey(hook)]; exists && previousHook.Phase == hook.Phase {
+				hook.LogTail = previousHook.LogTail
+
+			} else if r.HookLogRetriever != nil && logsRetrieved < hookLogLimit {
+				logsRetrieved++
+				hook.LogTail = r.retrieveHookLogTail(ctx, app, hook, log)
+			}
+		}
+
+		syncHooks.Hooks = append(syncHooks.Hooks, hook)
+	}
+
+	if len(syncHooks.Hooks) == 0 {
+		return nil
+	}
+
+	// Reduce the number of reported hooks until the status of the hooks fits in the database column
+	for {
+		syncHooksBytes, err := json.Marshal(syncHooks)
+		if err != nil {
+			return fmt.Errorf("unable to marshal sync hooks: %v", err)
+		}
+
+		if len(syncHooksBytes) <= db.ApplicationStateSyncHooksLength || len(syncHooks.Hooks) == 0 {
+			applicationState.SyncHooks = string(syncHooksBytes)
+			break
+		}
+
+		syncHooks.Hooks = syncHooks.Hooks[:len(syncHooks.Hooks)/2]
+	}
+
+	return nil
+}
+
+// retrieveHookLogTail returns the end of the logs of the given hook Job of the Application, or a message stating that the
+// logs could not be retrieved.
+func (r *ApplicationReconciler) retrieveHookLogTail(ctx context.Context, app appv1.Application, hook fauxargocd.FauxSyncHook,
+	log logr.Logger) string {
+
+	logCtx, cancel := context.WithTimeout(ctx, hookLogTimeout)
+	defer cancel()
+
+	logTail, err := r.HookLogRetriever.GetHookLogTail(logCtx, r.Client, app.Namespace, app.Name,
+		hook.Group, hook.Kind, hook.Namespace, hook.Name, hookLogTailLines)
+	if err != nil {
+		// The error may contain details of the Argo CD instance, which are not shown to the user, so it is only logged
+		log.V(logutil.LogLevel_Warn).Info("unable to retrieve the logs of a failed hook of Application", "hook", hook.Name, "error", err.Error())
+		return "(unable to retrieve the logs of the hook)"
+	}
+
+	// Keep the end of the logs, which is the most likely to contain the cause of the failure
+	if len(logTail) > hookLogTailLength {
+		logTail = logTail[len(logTail)-hookLogTailLength:]
+	}
+
+	return logTail
+}
+
+// isFailedHookJob returns true if the result is of a hook Job that failed
+func isFailedHookJob(resource appv1.ResourceResult) bool {
+	return resource.Group == "batch" && resource.Kind == "Job" &&
+		(resource.HookPhase == synccommon.OperationFailed || resource.HookPhase == synccommon.OperationError)
+}
+
+// getSyncHookKey returns a key that identifies a hook within a sync operation
+func getSyncHookKey(hook fauxargocd.FauxSyncHook) string {
+	return hook.Group + "/" + hook.Kind + "/" + hook.Namespace + "/" + hook.Name + "/" + hook.HookType
+}
+
+// revisionResolutionResult returns the result of Reconcile: the Application is requeued if the refs of its Git repository
+// are being retrieved in the background (see resolveTargetRevision).
+func revisionResolutionResult(app appv1.Application, revisionPending bool, log logr.Logger) ctrl.Result {
+	if revisionPending {
+		return logutil.RequeueWithReason(log, ctrl.Result{RequeueAfter: revisionResolutionRequeueDelay},
+			logutil.RequeueReasonRevisionResolutionPending, "Waiting for the refs of the Git repository of the Application to be retrieved")
+	}
+
+	if app.Status.OperationState != nil && !app.Status.OperationState.Phase.Completed() {
+		// The Application is reconciled again when Argo CD updates the state of the operation
+		return logutil.RequeueWithReason(log, ctrl.Result{}, logutil.RequeueReasonArgoOperationInProgress,
+			"Waiting for the Argo CD operation on the Application to complete", "phase", app.Status.OperationState.Phase)
+	}
+
+	return ctrl.Result{}
+}
+
+// sanitizeHealthAndStatus replaces empty (or unrecognized) health and sync status values with 'Unknown', since the
+// database will reject any value that is not a known Argo CD health/sync status code.
+func sanitizeHealthAndStatus(applicationState *db.ApplicationState) {
+
+	health, _ := db.ParseApplicationStateHealth(applicationState.Health)
+	applicationState.Health = string(health)
+
+	syncStatus, _ := db.ParseApplicationStateSyncStatus(applicationState.Sync_Status)
+	applicationState.Sync_Status = string(syncStatus)
+
+}
+
+// isApplicationStateHealthOnlyChange returns true if the ApplicationStates differ only by their health and message fields
+// (or not at all), false otherwise.
+func isApplicationStateHealthOnlyChange(existing db.ApplicationState, updated db.ApplicationState) bool {
+
+	existing.Health, existing.Message = updated.Health, updated.Message
+
+	return reflect.DeepEqual(existing, updated)
 }
 
 type applicationDeleteTask struct {
@@ -262,7 +908,7 @@ func (adt *applicationDeleteTask) PerformTask(taskContext context.Context) (bool
 func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appv1.Application{}).
-		Complete(r)
+		Complete(logutil.NewCorrelationIDMiddleware(mgr.GetClient(), func() client.Object { return &appv1.Application{} }, r))
 }
 
 // Convert ResourceStatus Array into String and then compress it into Byte Array​
@@ -297,6 +943,8 @@ func compressResourceData(resources []appv1.ResourceStatus) ([]byte, error) {
 
 // storeInComparedToFieldInApplicationState will read 'comparedTo' field of an Argo CD Application, and write the
 // correct value into 'applicationState'.
+// storeInComparedToFieldInApplicationState will read 'comparedTo' field of an Argo CD Application, and return the
+// JSON value that should be stored in the 'reconciledState' field of 'applicationState'.
 func storeInComparedToFieldInApplicationState(argoCDAppParam appv1.Application, applicationState db.ApplicationState) (string, error) {
 
 	comparedToParam := argoCDAppParam.Status.Sync.ComparedTo
@@ -347,3 +995,146 @@ func storeInComparedToFieldInApplicationState(argoCDAppParam appv1.Application,
 
 	return applicationState.ReconciledState, nil
 }
+
+// convertOperationStateToLastSync reads the '.status.operationState' field of an Argo CD Application, and returns the
+// details of the last sync operation as a JSON string (see fauxargocd.FauxLastSync), for storage in the database.
+// - Returns "" if Argo CD has not yet synced the Application.
+// - webhookRefreshedAt is the time at which the Application was last refreshed by the Argo CD Git webhook, if known (see
+// webhookRefreshTracker).
+func convertOperationStateToLastSync(argoCDApp appv1.Application, webhookRefreshedAt *time.Time) (string, error) {
+
+	operationState := argoCDApp.Status.OperationState
+	if operationState == nil {
+		return "", nil
+	}
+
+	lastSync := fauxargocd.FauxLastSync{
+		Initiator: db.TruncateVarchar(getSyncInitiator(*operationState, webhookRefreshedAt), db.SyncOperationInitiatorLength),
+	}
+
+	if !operationState.StartedAt.IsZero() {
+		startedAt := operationState.StartedAt.Time
+		lastSync.StartedAt = &startedAt
+	}
+
+	if operationState.FinishedAt != nil {
+		finishedAt := operationState.FinishedAt.Time
+		lastSync.FinishedAt = &finishedAt
+	}
+
+	if operationState.SyncResult != nil {
+		lastSync.Revision = operationState.SyncResult.Revision
+	} else if operationState.Operation.Sync != nil {
+		lastSync.Revision = operationState.Operation.Sync.Revision
+	}
+	lastSync.Revision = db.TruncateVarchar(lastSync.Revision, db.SyncOperationRevisionLength)
+
+	lastSyncBytes, err := json.Marshal(lastSync)
+	if err != nil {
+		return "", fmt.Errorf("SEVERE: unable to convert lastSync to JSON")
+	}
+
+	return string(lastSyncBytes), nil
+}
+
+// convertOperationStateToOperationInProgress reads the '.status.operationState' field of an Argo CD Application, and
+// returns the details of the sync operation that is in progress as a JSON string (see fauxargocd.FauxOperationState),
+// or an empty string if no sync operation is in progress.
+// - The estimated completion time is based on the duration of the most recent sync in the '.status.history' field.
+func convertOperationStateToOperationInProgress(argoCDApp appv1.Application) (string, error) {
+
+	operationState := argoCDApp.Status.OperationState
+	if operationState == nil || operationState.Phase.Completed() {
+		return "", nil
+	}
+
+	operationInProgress := fauxargocd.FauxOperationState{
+		Type:    string(managedgitopsv1alpha1.OperationStateTypeSync),
+		Message: db.TruncateVarchar(operationState.Message, db.ApplicationStateMessageLength),
+	}
+
+	// Sync operations requested by a GitOpsDeploymentSyncRun are started by the cluster-agent, on processing the
+	// Operation of the backend
+	if strings.HasPrefix(getSyncInitiator(*operationState, nil), db.SyncOperation_Initiator_SyncRunPrefix) {
+		operationInProgress.Type = string(managedgitopsv1alpha1.OperationStateTypeSyncRun)
+	}
+
+	if !operationState.StartedAt.IsZero() {
+		startedAt := operationState.StartedAt.Time
+		operationInProgress.StartedAt = &startedAt
+
+		if previousSyncDuration := getPreviousSyncDuration(argoCDApp); previousSyncDuration > 0 {
+			estimatedCompletionAt := startedAt.Add(previousSyncDuration)
+			operationInProgress.EstimatedCompletionAt = &estimatedCompletionAt
+		}
+	}
+
+	operationInProgressBytes, err := json.Marshal(operationInProgress)
+	if err != nil {
+		return "", fmt.Errorf("SEVERE: unable to convert operation in progress to JSON")
+	}
+
+	// The message may grow when escaped as JSON: rather than exceed the length of the column, drop it.
+	if len(operationInProgressBytes) > db.ApplicationStateOperationStateLength {
+		operationInProgress.Message = ""
+		if operationInProgressBytes, err = json.Marshal(operationInProgress); err != nil {
+			return "", fmt.Errorf("SEVERE: unable to convert operation in progress to JSON")
+		}
+	}
+
+	return string(operationInProgressBytes), nil
+}
+
+// getPreviousSyncDuration returns how long the most recent sync in the '.status.history' field of the Argo CD
+// Application took, or 0 if it is not known.
+func getPreviousSyncDuration(argoCDApp appv1.Application) time.Duration {
+
+	history := argoCDApp.Status.History
+	if len(history) == 0 {
+		return 0
+	}
+
+	// Argo CD appends new entries to the end of the history
+	previousSync := history[len(history)-1]
+	if previousSync.DeployStartedAt == nil || previousSync.DeployedAt.IsZero() {
+		return 0
+	}
+
+	return previousSync.DeployedAt.Sub(previousSync.DeployStartedAt.Time)
+}
+
+// getSyncInitiator returns who/what initiated the given Argo CD operation. See db.SyncOperation_Initiator_* constants.
+// - An automated sync that started shortly before/after the Application was refreshed by the Argo CD Git webhook
+// (webhookRefreshedAt) is reported as initiated by the webhook.
+func getSyncInitiator(operationState appv1.OperationState, webhookRefreshedAt *time.Time) string {
+
+	operation := operationState.Operation
+
+	if operation.InitiatedBy.Automated {
+		if webhookRefreshedAt != nil && !operationState.StartedAt.IsZero() &&
+			absDuration(operationState.StartedAt.Sub(*webhookRefreshedAt)) <= webhookSyncWindow {
+			return db.SyncOperation_Initiator_Webhook
+		}
+		return db.SyncOperation_Initiator_Automated
+	}
+
+	// Sync operations requested by the GitOps Service contain the initiator as an operation info item
+	for _, info := range operation.Info {
+		if info != nil && info.Name == utils.SyncInitiatorInfoName {
+			return info.Value
+		}
+	}
+
+	if operation.InitiatedBy.Username != "" {
+		return db.SyncOperation_Initiator_UserPrefix + operation.InitiatedBy.Username
+	}
+
+	return ""
+}
+
+func absDuration(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return -duration
+	}
+	return duration
+}
@@ -3,17 +3,21 @@ package argoprojio
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers/argoproj.io/application_info_cache"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -21,6 +25,8 @@ import (
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -180,6 +186,384 @@ var _ = Describe("Application Controller", func() {
 
 		})
 
+		It("should store the resolved target revision of the Argo CD Application, or the reason it could not be resolved, in the ApplicationState", func() {
+			defer dbQueries.CloseDatabase()
+			defer testTeardown()
+
+			ctx = context.Background()
+
+			applicationDB := &db.Application{
+				Application_id:          guestbookApp.Labels[dbID],
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(reconciler.DB.CreateApplication(ctx, applicationDB)).To(Succeed())
+			Expect(reconciler.Create(ctx, guestbookApp)).To(Succeed())
+
+			resolver := &mockRevisionResolver{resolvedRevision: "0a46d37fc6af9fe0aa963bdd845e3d799aa0320d"}
+			reconciler.RevisionResolver = resolver
+
+			By("reconciling an Application with a valid target revision")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			applicationState := &db.ApplicationState{Applicationstate_application_id: applicationDB.Application_id}
+			Expect(reconciler.DB.GetApplicationStateById(ctx, applicationState)).To(Succeed())
+			Expect(applicationState.ResolvedRevision).To(Equal(resolver.resolvedRevision))
+			Expect(applicationState.RevisionError).To(BeEmpty())
+			Expect(resolver.repoURL).To(Equal(guestbookApp.Spec.Source.RepoURL))
+
+			By("reconciling an Application with a target revision that does not exist in the repository")
+			resolver.err = fmt.Errorf("%w: 'mian' is not a branch, tag, or commit SHA of the repository", utils.ErrRevisionNotFound)
+
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			Expect(reconciler.DB.GetApplicationStateById(ctx, applicationState)).To(Succeed())
+			Expect(applicationState.ResolvedRevision).To(BeEmpty())
+			Expect(applicationState.RevisionError).To(ContainSubstring("'mian' is not a branch, tag, or commit SHA"))
+
+			By("keeping the previous values if the repository could not be reached")
+			resolver.err = fmt.Errorf("connection refused")
+
+			result, err := reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			Expect(reconciler.DB.GetApplicationStateById(ctx, applicationState)).To(Succeed())
+			Expect(applicationState.RevisionError).To(ContainSubstring("'mian' is not a branch, tag, or commit SHA"))
+
+			By("keeping the previous values, and requeueing the Application, if the refs of the repository are not yet cached")
+			resolver.err = fmt.Errorf("%w: '%s'", utils.ErrRefsNotCached, guestbookApp.Spec.Source.RepoURL)
+
+			result, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(Equal(revisionResolutionRequeueDelay))
+
+			Expect(reconciler.DB.GetApplicationStateById(ctx, applicationState)).To(Succeed())
+			Expect(applicationState.RevisionError).To(ContainSubstring("'mian' is not a branch, tag, or commit SHA"))
+		})
+
+		It("should store a diff preview in the ApplicationState, only when a new diff preview is requested via annotation", func() {
+			defer dbQueries.CloseDatabase()
+			defer testTeardown()
+
+			ctx = context.Background()
+
+			applicationDB := &db.Application{
+				Application_id:          guestbookApp.Labels[dbID],
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(reconciler.DB.CreateApplication(ctx, applicationDB)).To(Succeed())
+
+			guestbookApp.Annotations = map[string]string{argosharedutil.DiffPreviewAnnotation: "request-1"}
+			Expect(reconciler.Create(ctx, guestbookApp)).To(Succeed())
+
+			previewer := &mockDiffPreviewer{resourceDiffs: []fauxargocd.FauxResourceDiff{
+				{Kind: "Service", Namespace: "guestbook", Name: "guestbook-ui", Action: utils.DiffPreviewAction_Create, ChangedFields: 4},
+			}}
+			reconciler.DiffPreviewer = previewer
+
+			getDiffPreview := func() fauxargocd.FauxDiffPreview {
+				applicationState := &db.ApplicationState{Applicationstate_application_id: applicationDB.Application_id}
+				Expect(reconciler.DB.GetApplicationStateById(ctx, applicationState)).To(Succeed())
+
+				diffPreview := fauxargocd.FauxDiffPreview{}
+				if applicationState.DiffPreview != "" {
+					Expect(json.Unmarshal([]byte(applicationState.DiffPreview), &diffPreview)).To(Succeed())
+				}
+				return diffPreview
+			}
+
+			By("reconciling an Application with a diff preview request")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			diffPreview := getDiffPreview()
+			Expect(diffPreview.RequestID).To(Equal("request-1"))
+			Expect(diffPreview.GeneratedAt).ToNot(BeNil())
+			Expect(diffPreview.TotalResources).To(Equal(1))
+			Expect(diffPreview.Resources).To(Equal(previewer.resourceDiffs))
+			Expect(previewer.calls).To(Equal(1))
+
+			By("reconciling again with the same request, which should not retrieve the diff again")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+			Expect(getDiffPreview().RequestID).To(Equal("request-1"))
+			Expect(previewer.calls).To(Equal(1))
+
+			By("requesting a new diff preview, for which the diff cannot be retrieved")
+			previewer.err = fmt.Errorf("connection refused")
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(guestbookApp), guestbookApp)).To(Succeed())
+			guestbookApp.Annotations[argosharedutil.DiffPreviewAnnotation] = "request-2"
+			Expect(reconciler.Update(ctx, guestbookApp)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			diffPreview = getDiffPreview()
+			Expect(diffPreview.RequestID).To(Equal("request-2"))
+			Expect(diffPreview.Error).To(ContainSubstring("connection refused"))
+			Expect(diffPreview.Resources).To(BeEmpty())
+			Expect(previewer.calls).To(Equal(2))
+
+			By("removing the annotation, which should remove the diff preview")
+			delete(guestbookApp.Annotations, argosharedutil.DiffPreviewAnnotation)
+			Expect(reconciler.Update(ctx, guestbookApp)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+			Expect(getDiffPreview().RequestID).To(BeEmpty())
+		})
+
+		It("should store a resource estimate in the ApplicationState, and only collect it again when the synced revision changes", func() {
+			defer dbQueries.CloseDatabase()
+			defer testTeardown()
+
+			ctx = context.Background()
+
+			applicationDB := &db.Application{
+				Application_id:          guestbookApp.Labels[dbID],
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(reconciler.DB.CreateApplication(ctx, applicationDB)).To(Succeed())
+
+			guestbookApp.Status.Sync.Revision = "first-revision"
+			Expect(reconciler.Create(ctx, guestbookApp)).To(Succeed())
+
+			estimator := &mockResourceEstimator{podRequests: utils.PodResourceRequests{
+				CPU:       resource.MustParse("1500m"),
+				Memory:    resource.MustParse("2Gi"),
+				Pods:      3,
+				TotalPods: 3,
+			}}
+			reconciler.ResourceEstimator = estimator
+
+			getResourceEstimate := func() fauxargocd.FauxResourceEstimate {
+				applicationState := &db.ApplicationState{Applicationstate_application_id: applicationDB.Application_id}
+				Expect(reconciler.DB.GetApplicationStateById(ctx, applicationState)).To(Succeed())
+
+				resourceEstimate := fauxargocd.FauxResourceEstimate{}
+				Expect(json.Unmarshal([]byte(applicationState.ResourceEstimate), &resourceEstimate)).To(Succeed())
+				return resourceEstimate
+			}
+
+			By("reconciling an Application, which should collect a resource estimate")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			resourceEstimate := getResourceEstimate()
+			Expect(resourceEstimate.CollectedAt).ToNot(BeNil())
+			Expect(resourceEstimate.Revision).To(Equal("first-revision"))
+			Expect(resourceEstimate.CPURequests).To(Equal("1500m"))
+			Expect(resourceEstimate.MemoryRequests).To(Equal("2Gi"))
+			Expect(resourceEstimate.Pods).To(Equal(3))
+			Expect(resourceEstimate.TotalPods).To(Equal(3))
+			Expect(estimator.calls).To(Equal(1))
+
+			By("reconciling again with the same synced revision, which should keep the previous estimate")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+			Expect(getResourceEstimate().CollectedAt.Equal(*resourceEstimate.CollectedAt)).To(BeTrue())
+			Expect(estimator.calls).To(Equal(1))
+
+			By("syncing a new revision, for which the estimate cannot be collected")
+			estimator.err = fmt.Errorf("connection refused")
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(guestbookApp), guestbookApp)).To(Succeed())
+			guestbookApp.Status.Sync.Revision = "second-revision"
+			Expect(reconciler.Update(ctx, guestbookApp)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			resourceEstimate = getResourceEstimate()
+			Expect(resourceEstimate.Revision).To(Equal("second-revision"))
+			Expect(resourceEstimate.Error).To(ContainSubstring("connection refused"))
+			Expect(resourceEstimate.CPURequests).To(BeEmpty())
+			Expect(estimator.calls).To(Equal(2))
+		})
+
+		It("should store the status of the hooks of a sync operation requested by a GitOpsDeploymentSyncRun, retrieving the logs of failed hook Jobs once", func() {
+			defer dbQueries.CloseDatabase()
+			defer testTeardown()
+
+			ctx = context.Background()
+
+			applicationDB := &db.Application{
+				Application_id:          guestbookApp.Labels[dbID],
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(reconciler.DB.CreateApplication(ctx, applicationDB)).To(Succeed())
+
+			startedAt := metav1.NewTime(time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC))
+			guestbookApp.Status.OperationState = &appv1.OperationState{
+				Operation: appv1.Operation{
+					Info: []*appv1.Info{{Name: utils.SyncInitiatorInfoName, Value: db.SyncOperation_Initiator_SyncRunPrefix + "my-sync-run"}},
+				},
+				StartedAt: startedAt,
+				SyncResult: &appv1.SyncOperationResult{
+					Revision: "abc123",
+					Resources: appv1.ResourceResults{
+						{Group: "batch", Kind: "Job", Namespace: "guestbook", Name: "db-migration", HookType: synccommon.HookTypePreSync,
+							HookPhase: synccommon.OperationFailed, Message: "Job has reached the specified backoff limit"},
+						{Group: "batch", Kind: "Job", Namespace: "guestbook", Name: "smoke-test", HookType: synccommon.HookTypePostSync,
+							HookPhase: synccommon.OperationSucceeded},
+						{Kind: "Service", Namespace: "guestbook", Name: "guestbook-ui"},
+					},
+				},
+			}
+			Expect(reconciler.Create(ctx, guestbookApp)).To(Succeed())
+
+			retriever := &mockHookLogRetriever{logTail: "ERROR: relation \"users\" already exists"}
+			reconciler.HookLogRetriever = retriever
+
+			getSyncHooks := func() fauxargocd.FauxSyncHooks {
+				applicationState := &db.ApplicationState{Applicationstate_application_id: applicationDB.Application_id}
+				Expect(reconciler.DB.GetApplicationStateById(ctx, applicationState)).To(Succeed())
+
+				syncHooks := fauxargocd.FauxSyncHooks{}
+				if applicationState.SyncHooks != "" {
+					Expect(json.Unmarshal([]byte(applicationState.SyncHooks), &syncHooks)).To(Succeed())
+				}
+				return syncHooks
+			}
+
+			By("reconciling the Application, which should retrieve the logs of the failed hook Job only")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			syncHooks := getSyncHooks()
+			Expect(syncHooks.Initiator).To(Equal(db.SyncOperation_Initiator_SyncRunPrefix + "my-sync-run"))
+			Expect(syncHooks.Hooks).To(Equal([]fauxargocd.FauxSyncHook{
+				{Group: "batch", Kind: "Job", Namespace: "guestbook", Name: "db-migration", HookType: "PreSync", Phase: "Failed",
+					Message: "Job has reached the specified backoff limit", LogTail: "ERROR: relation \"users\" already exists"},
+				{Group: "batch", Kind: "Job", Namespace: "guestbook", Name: "smoke-test", HookType: "PostSync", Phase: "Succeeded"},
+			}))
+			Expect(retriever.calls).To(Equal(1))
+
+			By("reconciling again, which should not retrieve the logs again")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+			Expect(getSyncHooks().Hooks[0].LogTail).To(Equal("ERROR: relation \"users\" already exists"))
+			Expect(retriever.calls).To(Equal(1))
+
+			By("syncing the Application without a GitOpsDeploymentSyncRun, which should remove the status of the hooks")
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(guestbookApp), guestbookApp)).To(Succeed())
+			guestbookApp.Status.OperationState.Operation = appv1.Operation{InitiatedBy: appv1.OperationInitiator{Automated: true}}
+			Expect(reconciler.Update(ctx, guestbookApp)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+			Expect(getSyncHooks().Hooks).To(BeEmpty())
+		})
+
+		It("should record each entry of the Argo CD Application's sync history as DeploymentHistory, once", func() {
+			defer dbQueries.CloseDatabase()
+			defer testTeardown()
+
+			ctx = context.Background()
+
+			applicationDB := &db.Application{
+				Application_id:          guestbookApp.Labels[dbID],
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(reconciler.DB.CreateApplication(ctx, applicationDB)).To(Succeed())
+
+			firstDeployedAt := metav1.NewTime(time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC))
+			secondDeployedAt := metav1.NewTime(time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC))
+
+			guestbookApp.Status.History = appv1.RevisionHistories{
+				{ID: 0, Revision: "first-revision", DeployedAt: firstDeployedAt},
+				{ID: 1, Revision: "second-revision", DeployedAt: secondDeployedAt},
+			}
+			guestbookApp.Status.Summary.Images = []string{"quay.io/guestbook/ui:v2", "quay.io/guestbook/redis:v1"}
+			guestbookApp.Status.OperationState = &appv1.OperationState{
+				Operation:  appv1.Operation{InitiatedBy: appv1.OperationInitiator{Automated: true}},
+				StartedAt:  secondDeployedAt,
+				FinishedAt: &secondDeployedAt,
+				SyncResult: &appv1.SyncOperationResult{Revision: "second-revision"},
+			}
+			Expect(reconciler.Create(ctx, guestbookApp)).To(Succeed())
+
+			By("reconciling the Application, which should record both entries")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			var deploymentHistory []db.DeploymentHistory
+			Expect(reconciler.DB.ListDeploymentHistoryByApplicationID(ctx, applicationDB.Application_id, 0, &deploymentHistory)).To(Succeed())
+			Expect(deploymentHistory).To(HaveLen(2))
+
+			Expect(deploymentHistory[0].Revision).To(Equal("second-revision"))
+			Expect(deploymentHistory[0].Images).To(Equal("quay.io/guestbook/ui:v2,quay.io/guestbook/redis:v1"))
+			Expect(deploymentHistory[0].Initiator).To(Equal(db.SyncOperation_Initiator_Automated))
+			Expect(deploymentHistory[0].DeployedAt.Equal(secondDeployedAt.Time)).To(BeTrue())
+
+			By("verifying the images and initiator are not known for the older entry")
+			Expect(deploymentHistory[1].Revision).To(Equal("first-revision"))
+			Expect(deploymentHistory[1].Images).To(BeEmpty())
+			Expect(deploymentHistory[1].Initiator).To(BeEmpty())
+
+			By("reconciling again, which should not record the entries a second time")
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			deploymentHistory = []db.DeploymentHistory{}
+			Expect(reconciler.DB.ListDeploymentHistoryByApplicationID(ctx, applicationDB.Application_id, 0, &deploymentHistory)).To(Succeed())
+			Expect(deploymentHistory).To(HaveLen(2))
+
+			By("syncing a new revision, which should record only the new entry")
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(guestbookApp), guestbookApp)).To(Succeed())
+			guestbookApp.Status.History = append(guestbookApp.Status.History,
+				appv1.RevisionHistory{ID: 2, Revision: "third-revision", DeployedAt: metav1.Now()})
+			Expect(reconciler.Update(ctx, guestbookApp)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			deploymentHistory = []db.DeploymentHistory{}
+			Expect(reconciler.DB.ListDeploymentHistoryByApplicationID(ctx, applicationDB.Application_id, 0, &deploymentHistory)).To(Succeed())
+			Expect(deploymentHistory).To(HaveLen(3))
+			Expect(deploymentHistory[0].Revision).To(Equal("third-revision"))
+			Expect(deploymentHistory[0].Initiator).To(BeEmpty())
+
+			By("deleting the rows, and reconciling again, which should not query the database, as there are no new entries")
+			for _, entry := range deploymentHistory {
+				_, err := reconciler.DB.DeleteDeploymentHistoryById(ctx, entry.DeploymentHistoryID)
+				Expect(err).To(BeNil())
+			}
+
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			deploymentHistory = []db.DeploymentHistory{}
+			Expect(reconciler.DB.ListDeploymentHistoryByApplicationID(ctx, applicationDB.Application_id, 0, &deploymentHistory)).To(Succeed())
+			Expect(deploymentHistory).To(BeEmpty())
+
+			By("forgetting the recorded history ID, which should cause the database to be queried, and the entries recorded again")
+			reconciler.deploymentHistories.forget(types.NamespacedName{Namespace: namespace, Name: name})
+
+			_, err = reconciler.Reconcile(ctx, newRequest(namespace, name))
+			Expect(err).To(BeNil())
+
+			deploymentHistory = []db.DeploymentHistory{}
+			Expect(reconciler.DB.ListDeploymentHistoryByApplicationID(ctx, applicationDB.Application_id, 0, &deploymentHistory)).To(Succeed())
+			Expect(deploymentHistory).To(HaveLen(3))
+		})
+
 		It("Update an existing Application table in the database, call Reconcile on the Argo CD Application, and verify an existing ApplicationState DB entry is updated", func() {
 			By("Close database connection")
 			defer dbQueries.CloseDatabase()
@@ -837,6 +1221,219 @@ var _ = Describe("Application Controller", func() {
 			Expect(byteArr).NotTo(BeEmpty())
 		})
 	})
+
+	Context("Test isApplicationStateHealthOnlyChange function", func() {
+
+		existing := db.ApplicationState{
+			Applicationstate_application_id: "test-app",
+			Health:                          string(db.ApplicationStateHealth_Healthy),
+			Sync_Status:                     string(db.ApplicationStateSyncStatus_Synced),
+			Resources:                       []byte("resources"),
+			ReconciledState:                 "reconciled-state",
+		}
+
+		It("should return true if only the health and message have changed", func() {
+			updated := existing
+			updated.Health = string(db.ApplicationStateHealth_Degraded)
+			updated.Message = "a message"
+			Expect(isApplicationStateHealthOnlyChange(existing, updated)).To(BeTrue())
+		})
+
+		It("should return true if nothing has changed", func() {
+			Expect(isApplicationStateHealthOnlyChange(existing, existing)).To(BeTrue())
+		})
+
+		It("should return false if any other field has changed", func() {
+			updated := existing
+			updated.Health = string(db.ApplicationStateHealth_Degraded)
+			updated.Resources = []byte("other-resources")
+			Expect(isApplicationStateHealthOnlyChange(existing, updated)).To(BeFalse())
+
+			updated = existing
+			updated.Sync_Status = string(db.ApplicationStateSyncStatus_OutOfSync)
+			Expect(isApplicationStateHealthOnlyChange(existing, updated)).To(BeFalse())
+		})
+	})
+
+	Context("Test convertOperationStateToLastSync function", func() {
+
+		startedAt := metav1.NewTime(time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC))
+		finishedAt := metav1.NewTime(time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC))
+
+		var webhookRefreshedAt *time.Time
+
+		BeforeEach(func() {
+			webhookRefreshedAt = nil
+		})
+
+		convert := func(operationState *appv1.OperationState) fauxargocd.FauxLastSync {
+			app := appv1.Application{Status: appv1.ApplicationStatus{OperationState: operationState}}
+
+			lastSyncStr, err := convertOperationStateToLastSync(app, webhookRefreshedAt)
+			Expect(err).To(BeNil())
+
+			var lastSync fauxargocd.FauxLastSync
+			Expect(json.Unmarshal([]byte(lastSyncStr), &lastSync)).To(Succeed())
+			return lastSync
+		}
+
+		It("should return an empty string if the Application has not been synced", func() {
+			lastSyncStr, err := convertOperationStateToLastSync(appv1.Application{}, nil)
+			Expect(err).To(BeNil())
+			Expect(lastSyncStr).To(BeEmpty())
+		})
+
+		It("should report the GitOps Service initiator, timestamps and revision of a completed sync", func() {
+			lastSync := convert(&appv1.OperationState{
+				Operation: appv1.Operation{
+					InitiatedBy: appv1.OperationInitiator{Username: "admin"},
+					Info:        []*appv1.Info{{Name: utils.SyncInitiatorInfoName, Value: "GitOpsDeploymentSyncRun/my-sync-run"}},
+				},
+				StartedAt:  startedAt,
+				FinishedAt: &finishedAt,
+				SyncResult: &appv1.SyncOperationResult{Revision: "abc123"},
+			})
+
+			Expect(lastSync.Initiator).To(Equal("GitOpsDeploymentSyncRun/my-sync-run"))
+			Expect(lastSync.StartedAt.Equal(startedAt.Time)).To(BeTrue())
+			Expect(lastSync.FinishedAt.Equal(finishedAt.Time)).To(BeTrue())
+			Expect(lastSync.Revision).To(Equal("abc123"))
+		})
+
+		It("should report an automated sync that is still in progress", func() {
+			lastSync := convert(&appv1.OperationState{
+				Operation: appv1.Operation{
+					InitiatedBy: appv1.OperationInitiator{Automated: true},
+					Sync:        &appv1.SyncOperation{Revision: "def456"},
+				},
+				StartedAt: startedAt,
+			})
+
+			Expect(lastSync.Initiator).To(Equal(db.SyncOperation_Initiator_Automated))
+			Expect(lastSync.FinishedAt).To(BeNil())
+			Expect(lastSync.Revision).To(Equal("def456"))
+		})
+
+		It("should report the user that initiated a sync outside of the GitOps Service", func() {
+			lastSync := convert(&appv1.OperationState{
+				Operation: appv1.Operation{InitiatedBy: appv1.OperationInitiator{Username: "admin"}},
+				StartedAt: startedAt,
+			})
+
+			Expect(lastSync.Initiator).To(Equal(db.SyncOperation_Initiator_UserPrefix + "admin"))
+		})
+
+		It("should report an automated sync that started shortly after a webhook refresh as initiated by the webhook", func() {
+			refreshedAt := startedAt.Add(-10 * time.Second)
+			webhookRefreshedAt = &refreshedAt
+
+			lastSync := convert(&appv1.OperationState{
+				Operation: appv1.Operation{InitiatedBy: appv1.OperationInitiator{Automated: true}},
+				StartedAt: startedAt,
+			})
+			Expect(lastSync.Initiator).To(Equal(db.SyncOperation_Initiator_Webhook))
+
+			By("verifying that an automated sync long after the webhook refresh is not reported as initiated by the webhook")
+			refreshedAt = startedAt.Add(-time.Hour)
+			lastSync = convert(&appv1.OperationState{
+				Operation: appv1.Operation{InitiatedBy: appv1.OperationInitiator{Automated: true}},
+				StartedAt: startedAt,
+			})
+			Expect(lastSync.Initiator).To(Equal(db.SyncOperation_Initiator_Automated))
+		})
+	})
+
+	Context("Test convertOperationStateToOperationInProgress function", func() {
+
+		startedAt := metav1.NewTime(time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC))
+		finishedAt := metav1.NewTime(time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC))
+
+		convert := func(app appv1.Application) *fauxargocd.FauxOperationState {
+			operationStateStr, err := convertOperationStateToOperationInProgress(app)
+			Expect(err).To(BeNil())
+
+			if operationStateStr == "" {
+				return nil
+			}
+
+			var operationState fauxargocd.FauxOperationState
+			Expect(json.Unmarshal([]byte(operationStateStr), &operationState)).To(Succeed())
+			return &operationState
+		}
+
+		It("should return an empty string if no sync operation is in progress", func() {
+			Expect(convert(appv1.Application{})).To(BeNil())
+
+			Expect(convert(appv1.Application{Status: appv1.ApplicationStatus{OperationState: &appv1.OperationState{
+				Phase:      synccommon.OperationSucceeded,
+				StartedAt:  startedAt,
+				FinishedAt: &finishedAt,
+			}}})).To(BeNil())
+		})
+
+		It("should report an automated sync that is in progress, with an estimated completion time based on the previous sync", func() {
+			previousSyncStartedAt := metav1.NewTime(startedAt.Add(-time.Hour))
+
+			operationState := convert(appv1.Application{Status: appv1.ApplicationStatus{
+				OperationState: &appv1.OperationState{
+					Operation: appv1.Operation{InitiatedBy: appv1.OperationInitiator{Automated: true}},
+					Phase:     synccommon.OperationRunning,
+					Message:   "waiting for healthy state of apps/Deployment/my-deployment",
+					StartedAt: startedAt,
+				},
+				History: appv1.RevisionHistories{{
+					Revision:        "abc123",
+					DeployStartedAt: &previousSyncStartedAt,
+					DeployedAt:      metav1.NewTime(previousSyncStartedAt.Add(90 * time.Second)),
+				}},
+			}})
+
+			Expect(operationState).NotTo(BeNil())
+			Expect(operationState.Type).To(Equal(string(managedgitopsv1alpha1.OperationStateTypeSync)))
+			Expect(operationState.Message).To(Equal("waiting for healthy state of apps/Deployment/my-deployment"))
+			Expect(operationState.StartedAt.Equal(startedAt.Time)).To(BeTrue())
+			Expect(operationState.EstimatedCompletionAt.Equal(startedAt.Add(90 * time.Second))).To(BeTrue())
+		})
+
+		It("should report a sync requested by a GitOpsDeploymentSyncRun, without an estimate if there is no previous sync", func() {
+			operationState := convert(appv1.Application{Status: appv1.ApplicationStatus{
+				OperationState: &appv1.OperationState{
+					Operation: appv1.Operation{
+						InitiatedBy: appv1.OperationInitiator{Username: "admin"},
+						Info:        []*appv1.Info{{Name: utils.SyncInitiatorInfoName, Value: "GitOpsDeploymentSyncRun/my-sync-run"}},
+					},
+					Phase:     synccommon.OperationRunning,
+					StartedAt: startedAt,
+				},
+			}})
+
+			Expect(operationState).NotTo(BeNil())
+			Expect(operationState.Type).To(Equal(string(managedgitopsv1alpha1.OperationStateTypeSyncRun)))
+			Expect(operationState.EstimatedCompletionAt).To(BeNil())
+		})
+	})
+
+	Context("Test webhookRefreshTracker", func() {
+
+		It("should return the time the Application was last observed with the refresh annotation", func() {
+			tracker := webhookRefreshTracker{}
+			name := types.NamespacedName{Namespace: "argocd", Name: "my-app"}
+
+			Expect(tracker.observe(name, appv1.Application{})).To(BeNil())
+
+			refreshedApp := appv1.Application{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{appv1.AnnotationKeyRefresh: string(appv1.RefreshTypeNormal)},
+			}}
+			refreshedAt := tracker.observe(name, refreshedApp)
+			Expect(refreshedAt).ToNot(BeNil())
+
+			By("verifying the refresh is still returned once Argo CD has removed the annotation")
+			Expect(tracker.observe(name, appv1.Application{})).To(Equal(refreshedAt))
+
+			tracker.forget(name)
+			Expect(tracker.observe(name, appv1.Application{})).To(BeNil())
+		})
+	})
 })
 
 var _ = Describe("Namespace Reconciler Tests.", func() {
@@ -1235,3 +1832,81 @@ func convertReconciledStateStringToObject(reconciledState string) (fauxargocd.Fa
 	}
 	return comparedTo, nil
 }
+
+// mockRevisionResolver returns the configured resolved revision (or error), and records the repository it was called with.
+type mockRevisionResolver struct {
+	resolvedRevision string
+	err              error
+	repoURL          string
+}
+
+func (m *mockRevisionResolver) ResolveRevision(ctx context.Context, k8sClient client.Client, argoCDNamespace string,
+	repoURL string, targetRevision string) (string, error) {
+
+	m.repoURL = repoURL
+
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.resolvedRevision, nil
+}
+
+func (m *mockRevisionResolver) ResolveCachedRevision(ctx context.Context, k8sClient client.Client, argoCDNamespace string,
+	repoURL string, targetRevision string) (string, error) {
+
+	return m.ResolveRevision(ctx, k8sClient, argoCDNamespace, repoURL, targetRevision)
+}
+
+// mockDiffPreviewer returns the configured resource diffs (or error), and records the number of times it was called.
+type mockDiffPreviewer struct {
+	resourceDiffs []fauxargocd.FauxResourceDiff
+	err           error
+	calls         int
+}
+
+func (m *mockDiffPreviewer) GetResourceDiffs(ctx context.Context, k8sClient client.Client, argoCDNamespace string,
+	appName string) ([]fauxargocd.FauxResourceDiff, error) {
+
+	m.calls++
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.resourceDiffs, nil
+}
+
+// mockResourceEstimator returns the configured Pod resource requests (or error), and records the number of times it was called.
+type mockResourceEstimator struct {
+	podRequests utils.PodResourceRequests
+	err         error
+	calls       int
+}
+
+func (m *mockResourceEstimator) GetPodResourceRequests(ctx context.Context, k8sClient client.Client, argoCDNamespace string,
+	appName string) (utils.PodResourceRequests, error) {
+
+	m.calls++
+
+	if m.err != nil {
+		return utils.PodResourceRequests{}, m.err
+	}
+	return m.podRequests, nil
+}
+
+// mockHookLogRetriever returns the configured log tail (or error), and records the number of times it was called.
+type mockHookLogRetriever struct {
+	logTail string
+	err     error
+	calls   int
+}
+
+func (m *mockHookLogRetriever) GetHookLogTail(ctx context.Context, k8sClient client.Client, argoCDNamespace string, appName string,
+	group string, kind string, namespace string, name string, tailLines int64) (string, error) {
+
+	m.calls++
+
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.logTail, nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"os"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -25,16 +28,55 @@ import (
 // - in contrast, the cache will return a value for an Application that is at most 60 seconds old
 //   (the Application in the cache state will be eventually consistent with the database)
 //     - since it is eventually consistent, the calling code needs to be aware of this in its logic.
+//
+// If batched writes are enabled (see NewApplicationInfoCacheWithBatchedWrites), ApplicationState updates may instead
+// be queued with QueueApplicationStateUpsert: queued ApplicationStates are immediately returned by the cache, and are
+// written to the database together (with a single upsert) once every flush interval. On clusters with thousands of
+// Applications, this significantly reduces the number of database writes.
+
+const (
+	// ApplicationStateFlushIntervalEnvVar is the interval in milliseconds at which batched ApplicationState updates
+	// are written to the database. Set to 0 to disable batching, and write each update immediately.
+	ApplicationStateFlushIntervalEnvVar = "APPLICATION_STATE_FLUSH_INTERVAL_MS"
+
+	defaultApplicationStateFlushInterval = 1 * time.Second
+)
+
+// ApplicationStateFlushInterval returns the flush interval of batched ApplicationState updates, from the
+// APPLICATION_STATE_FLUSH_INTERVAL_MS env var (if set).
+func ApplicationStateFlushInterval(log logr.Logger) time.Duration {
+
+	interval := os.Getenv(ApplicationStateFlushIntervalEnvVar)
+	if interval == "" {
+		return defaultApplicationStateFlushInterval
+	}
+
+	value, err := strconv.Atoi(interval)
+	if err != nil || value < 0 {
+		log.Error(err, fmt.Sprintf("value of env var %s can't be converted to a non-negative int", ApplicationStateFlushIntervalEnvVar))
+		return defaultApplicationStateFlushInterval
+	}
+
+	return time.Duration(value) * time.Millisecond
+}
 
 // A wrapper over the ApplicationStateCache entries of the database
 // Note: This should only be used by cluster-agent's application controller.
 func NewApplicationInfoCache() *ApplicationInfoCache {
+	return NewApplicationInfoCacheWithBatchedWrites(0)
+}
+
+// NewApplicationInfoCacheWithBatchedWrites returns an ApplicationInfoCache which writes the ApplicationStates queued
+// with QueueApplicationStateUpsert to the database once every 'flushInterval'. If 'flushInterval' is 0, batched
+// writes are disabled.
+func NewApplicationInfoCacheWithBatchedWrites(flushInterval time.Duration) *ApplicationInfoCache {
 
 	res := &ApplicationInfoCache{
-		channel: make(chan applicationInfoCacheRequest),
+		channel:       make(chan applicationInfoCacheRequest),
+		flushInterval: flushInterval,
 	}
 
-	go applicationInfoCacheLoop(res.channel)
+	go applicationInfoCacheLoop(res.channel, flushInterval)
 
 	return res
 }
@@ -44,8 +86,11 @@ const (
 	ApplicationCacheMessage_Get
 	ApplicationStateCacheMessage_Create
 	ApplicationStateCacheMessage_Update
+	ApplicationStateCacheMessage_UpdateHealthOnly
 	ApplicationStateCacheMessage_Delete
+	ApplicationStateCacheMessage_QueueUpsert
 	ApplicationInfoCacheMessage_ExpireCacheEntries
+	ApplicationInfoCacheMessage_FlushApplicationStates
 	ApplicationInfoCacheMessage_DebugOnly_Shutdown
 )
 
@@ -154,6 +199,73 @@ func (asc *ApplicationInfoCache) UpdateApplicationState(ctx context.Context, app
 	return nil
 }
 
+// UpdateApplicationStateHealthOnly updates only the health and message fields of the ApplicationState in the database.
+//
+// The caller should only call this function when the other fields of 'appState' are unchanged from the current
+// ApplicationState, as 'appState' will be stored in the cache as is.
+func (asc *ApplicationInfoCache) UpdateApplicationStateHealthOnly(ctx context.Context, appState db.ApplicationState) error {
+
+	responseChannel := make(chan applicationInfoCacheResponse)
+
+	asc.channel <- applicationInfoCacheRequest{
+		ctx:                          ctx,
+		createOrUpdateAppStateObject: appState,
+		msgType:                      ApplicationStateCacheMessage_UpdateHealthOnly,
+		responseChannel:              responseChannel,
+	}
+
+	var response applicationInfoCacheResponse
+
+	select {
+	case response = <-responseChannel:
+	case <-ctx.Done():
+		return fmt.Errorf("context cancelled in UpdateApplicationStateHealthOnly")
+	}
+
+	if response.err != nil {
+		return response.err
+	}
+
+	return nil
+}
+
+// BatchedWritesEnabled returns true if ApplicationStates may be queued with QueueApplicationStateUpsert.
+func (asc *ApplicationInfoCache) BatchedWritesEnabled() bool {
+	return asc.flushInterval > 0
+}
+
+// QueueApplicationStateUpsert stores the ApplicationState in the cache, and queues it to be written to the database
+// with the next batch. If the ApplicationState is queued again before the batch is written, only the most recent
+// value is written.
+//
+// As the ApplicationState is written asynchronously, database errors are not returned to the caller: instead, they
+// are logged, and the cache entry is invalidated (so that it will be re-read from the database).
+func (asc *ApplicationInfoCache) QueueApplicationStateUpsert(ctx context.Context, appState db.ApplicationState) error {
+
+	if !asc.BatchedWritesEnabled() {
+		return fmt.Errorf("batched writes are not enabled for this ApplicationInfoCache")
+	}
+
+	responseChannel := make(chan applicationInfoCacheResponse)
+
+	asc.channel <- applicationInfoCacheRequest{
+		ctx:                          ctx,
+		createOrUpdateAppStateObject: appState,
+		msgType:                      ApplicationStateCacheMessage_QueueUpsert,
+		responseChannel:              responseChannel,
+	}
+
+	var response applicationInfoCacheResponse
+
+	select {
+	case response = <-responseChannel:
+	case <-ctx.Done():
+		return fmt.Errorf("context cancelled in QueueApplicationStateUpsert")
+	}
+
+	return response.err
+}
+
 func (asc *ApplicationInfoCache) DeleteApplicationStateById(ctx context.Context, id string) (int, error) {
 	responseChannel := make(chan applicationInfoCacheResponse)
 
@@ -179,7 +291,8 @@ func (asc *ApplicationInfoCache) DeleteApplicationStateById(ctx context.Context,
 	return response.rowsAffectedForDelete, nil
 }
 
-// DebugOnly_Shutdown should only be called in unit tests. This function terminates the cache loop.
+// DebugOnly_Shutdown should only be called in unit tests. This function writes any queued ApplicationStates to the
+// database, then terminates the cache loop.
 func (asc *ApplicationInfoCache) DebugOnly_Shutdown(ctx context.Context) {
 
 	responseChannel := make(chan applicationInfoCacheResponse)
@@ -194,18 +307,25 @@ func (asc *ApplicationInfoCache) DebugOnly_Shutdown(ctx context.Context) {
 
 }
 
-func applicationInfoCacheLoop(inputChan chan applicationInfoCacheRequest) {
+func applicationInfoCacheLoop(inputChan chan applicationInfoCacheRequest, flushInterval time.Duration) {
 
 	startTimer(&ApplicationInfoCache{
 		channel: inputChan,
 	})
 
+	if flushInterval > 0 {
+		startFlushTimer(inputChan, flushInterval)
+	}
+
 	log := log.FromContext(context.Background()).
 		WithName(logutil.LogLogger_managed_gitops)
 
 	cacheAppState := map[string]applicationStateCacheEntry{}
 	cacheApp := map[string]applicationCacheEntry{}
 
+	// pendingAppStates contains the ApplicationStates that are queued to be written to the database, by ID
+	pendingAppStates := map[string]db.ApplicationState{}
+
 	dbQueries, err := db.NewSharedProductionPostgresDBQueries(false)
 	if err != nil {
 		log.Error(err, "SEVERE: unexpected error in calling dbQueries")
@@ -225,16 +345,28 @@ outer_for_loop:
 		} else if request.msgType == ApplicationStateCacheMessage_Update {
 			processUpdateAppStateMessage(dbQueries, request, cacheApp, cacheAppState, log)
 
+		} else if request.msgType == ApplicationStateCacheMessage_UpdateHealthOnly {
+			processUpdateAppStateHealthOnlyMessage(dbQueries, request, cacheApp, cacheAppState)
+
+		} else if request.msgType == ApplicationStateCacheMessage_QueueUpsert {
+			processQueueAppStateUpsertMessage(request, cacheAppState, pendingAppStates)
+
 		} else if request.msgType == ApplicationStateCacheMessage_Delete {
+			delete(pendingAppStates, request.primaryKey)
 			processDeleteAppStateMessage(dbQueries, request, cacheApp, cacheAppState, log)
 
 		} else if request.msgType == ApplicationCacheMessage_Get {
 			processGetAppMessage(dbQueries, request, cacheApp, cacheAppState, log)
 
 		} else if request.msgType == ApplicationInfoCacheMessage_ExpireCacheEntries {
-			processExpireCacheEntriesMessage(cacheApp, cacheAppState, inputChan)
+			processExpireCacheEntriesMessage(cacheApp, cacheAppState, pendingAppStates, inputChan)
+
+		} else if request.msgType == ApplicationInfoCacheMessage_FlushApplicationStates {
+			flushPendingAppStates(dbQueries, cacheApp, cacheAppState, pendingAppStates, log)
+			startFlushTimer(inputChan, flushInterval)
 
 		} else if request.msgType == ApplicationInfoCacheMessage_DebugOnly_Shutdown {
+			flushPendingAppStates(dbQueries, cacheApp, cacheAppState, pendingAppStates, log)
 			processDebugOnlyShutdownMessage(request, log)
 			break outer_for_loop
 
@@ -311,6 +443,102 @@ func processUpdateAppStateMessage(dbQueries db.DatabaseQueries, req applicationI
 
 }
 
+func processUpdateAppStateHealthOnlyMessage(dbQueries db.DatabaseQueries, req applicationInfoCacheRequest, cacheApp map[string]applicationCacheEntry, cacheAppState map[string]applicationStateCacheEntry) {
+
+	err := dbQueries.UpdateApplicationStateHealthOnly(req.ctx, &req.createOrUpdateAppStateObject)
+
+	if err == nil {
+
+		// Update the cache on success: the caller guarantees the other fields are unchanged from the database row
+		var appState db.ApplicationState = req.createOrUpdateAppStateObject
+
+		newCacheEntry := applicationStateCacheEntry{
+			appState:        appState,
+			cacheExpireTime: time.Now().Add(1 * time.Minute),
+		}
+
+		cacheAppState[appState.Applicationstate_application_id] = newCacheEntry
+
+	} else {
+		// Invalidate the cache on database error, and return the error back to the caller
+		delete(cacheApp, req.createOrUpdateAppStateObject.Applicationstate_application_id)
+		delete(cacheAppState, req.createOrUpdateAppStateObject.Applicationstate_application_id)
+	}
+
+	req.responseChannel <- applicationInfoCacheResponse{
+		err: err,
+	}
+
+}
+
+func processQueueAppStateUpsertMessage(req applicationInfoCacheRequest, cacheAppState map[string]applicationStateCacheEntry,
+	pendingAppStates map[string]db.ApplicationState) {
+
+	appState := req.createOrUpdateAppStateObject
+
+	if db.IsEmpty(appState.Applicationstate_application_id) {
+		req.responseChannel <- applicationInfoCacheResponse{
+			err: fmt.Errorf("SEVERE: PrimaryKey should not be nil"),
+		}
+		return
+	}
+
+	// The cache returns the queued value until it is written, so that callers see their own writes
+	cacheAppState[appState.Applicationstate_application_id] = applicationStateCacheEntry{
+		appState:        appState,
+		cacheExpireTime: time.Now().Add(1 * time.Minute),
+	}
+
+	pendingAppStates[appState.Applicationstate_application_id] = appState
+
+	req.responseChannel <- applicationInfoCacheResponse{}
+}
+
+// flushPendingAppStates writes the queued ApplicationStates to the database, with a single upsert. If the upsert fails,
+// each ApplicationState is upserted individually, so that a single invalid ApplicationState (for example, of an
+// Application that has since been deleted) does not prevent the others from being written.
+func flushPendingAppStates(dbQueries db.DatabaseQueries, cacheApp map[string]applicationCacheEntry,
+	cacheAppState map[string]applicationStateCacheEntry, pendingAppStates map[string]db.ApplicationState, log logr.Logger) {
+
+	if len(pendingAppStates) == 0 {
+		return
+	}
+
+	appStates := make([]db.ApplicationState, 0, len(pendingAppStates))
+	for id := range pendingAppStates {
+		appStates = append(appStates, pendingAppStates[id])
+		delete(pendingAppStates, id)
+	}
+
+	// Sort by primary key, so that concurrent upserts lock the rows in the same order
+	sort.Slice(appStates, func(i, j int) bool {
+		return appStates[i].Applicationstate_application_id < appStates[j].Applicationstate_application_id
+	})
+
+	ctx := context.Background()
+
+	err := dbQueries.UpsertApplicationStates(ctx, appStates)
+	if err == nil {
+		log.V(logutil.LogLevel_Debug).Info("wrote batch of ApplicationStates", "applicationStates", len(appStates))
+		return
+	}
+
+	log.V(logutil.LogLevel_Warn).Info("unable to write batch of ApplicationStates, writing them individually", "error", err.Error())
+
+	for _, appState := range appStates {
+
+		if err := dbQueries.UpsertApplicationStates(ctx, []db.ApplicationState{appState}); err != nil {
+
+			log.V(logutil.LogLevel_Warn).Error(err, "unable to write ApplicationState (but the Application might have been deleted)",
+				"applicationID", appState.Applicationstate_application_id)
+
+			// Invalidate the cache, so that the ApplicationState is re-read from the database
+			delete(cacheApp, appState.Applicationstate_application_id)
+			delete(cacheAppState, appState.Applicationstate_application_id)
+		}
+	}
+}
+
 func processDeleteAppStateMessage(dbQueries db.DatabaseQueries, req applicationInfoCacheRequest, cacheApp map[string]applicationCacheEntry, cacheAppState map[string]applicationStateCacheEntry, log logr.Logger) {
 
 	if db.IsEmpty(req.primaryKey) {
@@ -459,7 +687,8 @@ func processGetAppMessage(dbQueries db.DatabaseQueries, req applicationInfoCache
 
 }
 
-func processExpireCacheEntriesMessage(cacheApp map[string]applicationCacheEntry, cacheAppState map[string]applicationStateCacheEntry, inputChan chan applicationInfoCacheRequest) {
+func processExpireCacheEntriesMessage(cacheApp map[string]applicationCacheEntry, cacheAppState map[string]applicationStateCacheEntry,
+	pendingAppStates map[string]db.ApplicationState, inputChan chan applicationInfoCacheRequest) {
 
 	for key, elements := range cacheApp {
 		if time.Now().After(elements.cacheExpireTime) {
@@ -468,6 +697,11 @@ func processExpireCacheEntriesMessage(cacheApp map[string]applicationCacheEntry,
 	}
 
 	for key, elements := range cacheAppState {
+		// Entries that have not yet been written to the database are not expired, as the database value is older
+		if _, pending := pendingAppStates[key]; pending {
+			continue
+		}
+
 		if time.Now().After(elements.cacheExpireTime) {
 			delete(cacheAppState, key)
 		}
@@ -497,3 +731,16 @@ func startTimer(asc *ApplicationInfoCache) {
 
 	}()
 }
+
+// startFlushTimer sends a message to the cache loop, once the flush interval has elapsed, indicating that it is time
+// to write the queued ApplicationStates to the database.
+func startFlushTimer(inputChan chan applicationInfoCacheRequest, flushInterval time.Duration) {
+	go func() {
+		flushTimer := time.NewTimer(flushInterval)
+		<-flushTimer.C
+
+		inputChan <- applicationInfoCacheRequest{
+			msgType: ApplicationInfoCacheMessage_FlushApplicationStates,
+		}
+	}()
+}
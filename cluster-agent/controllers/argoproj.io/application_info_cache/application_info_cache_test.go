@@ -2,6 +2,7 @@ package application_info_cache
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -59,7 +60,7 @@ var _ = Describe("application_info_cache Test", func() {
 			Expect(err).To(BeNil())
 			Expect(fromCache).To(BeTrue())
 
-			testAppState.Health = "Unhealthy"
+			testAppState.Health = "Degraded"
 			errUpdate := aic.UpdateApplicationState(ctx, testAppState)
 			Expect(errUpdate).To(BeNil())
 
@@ -68,6 +69,20 @@ var _ = Describe("application_info_cache Test", func() {
 			Expect(isFromCache).To(BeTrue())
 			Expect(testAppState).To(Equal(appState))
 
+			testAppState.Health = "Progressing"
+			testAppState.Message = "waiting for rollout to finish"
+			errUpdate = aic.UpdateApplicationStateHealthOnly(ctx, testAppState)
+			Expect(errUpdate).To(BeNil())
+
+			appState, isFromCache, errGet = aic.GetApplicationStateById(ctx, testAppState.Applicationstate_application_id)
+			Expect(errGet).To(BeNil())
+			Expect(isFromCache).To(BeTrue())
+			Expect(testAppState).To(Equal(appState))
+
+			errGet = dbq.GetApplicationStateById(ctx, &dbAppStateObj)
+			Expect(errGet).To(BeNil())
+			Expect(testAppState).To(Equal(dbAppStateObj))
+
 			testDeleteAppState := db.ApplicationState{
 				Applicationstate_application_id: testAppState.Applicationstate_application_id,
 			}
@@ -200,6 +215,79 @@ var _ = Describe("application_info_cache Test", func() {
 			Expect(getApp).To(Equal(testapplication))
 		})
 
+		It("Tests QueueApplicationStateUpsert writes the queued ApplicationStates to the database in a batch", func() {
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			ctx := context.Background()
+			aic := NewApplicationInfoCacheWithBatchedWrites(100 * time.Millisecond)
+			defer aic.DebugOnly_Shutdown(ctx)
+			Expect(aic.BatchedWritesEnabled()).To(BeTrue())
+			Expect(NewApplicationInfoCache().BatchedWritesEnabled()).To(BeFalse())
+
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+
+			_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			application := &db.Application{
+				Application_id:          "test-batched-application",
+				Name:                    "my-application",
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbq.CreateApplication(ctx, application)
+			Expect(err).To(BeNil())
+
+			testAppState := db.ApplicationState{
+				Applicationstate_application_id: application.Application_id,
+				Health:                          "Progressing",
+				Sync_Status:                     "OutOfSync",
+				ReconciledState:                 "test-reconciledState",
+			}
+			err = aic.QueueApplicationStateUpsert(ctx, testAppState)
+			Expect(err).To(BeNil())
+
+			By("queueing a second update before the batch is written, which should replace the first")
+			testAppState.Health = "Healthy"
+			testAppState.Sync_Status = "Synced"
+			err = aic.QueueApplicationStateUpsert(ctx, testAppState)
+			Expect(err).To(BeNil())
+
+			By("verifying the queued ApplicationState is returned from the cache, before it is written")
+			appState, isFromCache, err := aic.GetApplicationStateById(ctx, testAppState.Applicationstate_application_id)
+			Expect(err).To(BeNil())
+			Expect(isFromCache).To(BeTrue())
+			Expect(appState).To(Equal(testAppState))
+
+			By("verifying the most recent ApplicationState is written to the database once the flush interval has elapsed")
+			Eventually(func() db.ApplicationState {
+				dbAppState := db.ApplicationState{Applicationstate_application_id: testAppState.Applicationstate_application_id}
+				if err := dbq.GetApplicationStateById(ctx, &dbAppState); err != nil {
+					return db.ApplicationState{}
+				}
+				return dbAppState
+			}, "5s", "50ms").Should(Equal(testAppState))
+
+			By("verifying an ApplicationState that can't be written is invalidated in the cache")
+			invalidAppState := db.ApplicationState{
+				Applicationstate_application_id: "test-application-does-not-exist",
+				Health:                          "Healthy",
+				Sync_Status:                     "Synced",
+				ReconciledState:                 "test-reconciledState",
+			}
+			err = aic.QueueApplicationStateUpsert(ctx, invalidAppState)
+			Expect(err).To(BeNil())
+
+			Eventually(func() bool {
+				_, isFromCache, _ := aic.GetApplicationStateById(ctx, invalidAppState.Applicationstate_application_id)
+				return isFromCache
+			}, "5s", "50ms").Should(BeFalse())
+		})
+
 	})
 
 })
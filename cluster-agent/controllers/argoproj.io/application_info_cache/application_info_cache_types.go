@@ -23,6 +23,10 @@ type applicationCacheEntry struct {
 
 type ApplicationInfoCache struct {
 	channel chan applicationInfoCacheRequest
+
+	// flushInterval is the interval at which queued ApplicationStates are written to the database: 0 if batched
+	// writes are disabled.
+	flushInterval time.Duration
 }
 
 type applicationInfoCacheRequest struct {
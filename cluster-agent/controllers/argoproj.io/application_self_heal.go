@@ -0,0 +1,199 @@
+package argoprojio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ApplicationSelfHealIntervalEnvVar is the interval in minutes between runs of the Application self-heal loop. Set to 0 to disable it.
+	ApplicationSelfHealIntervalEnvVar = "APPLICATION_SELF_HEAL_INTERVAL"
+
+	defaultApplicationSelfHealInterval = 3 * time.Minute // Interval in Minutes between runs of the Application self-heal loop.
+)
+
+// Application self-heal loop:
+//
+// The Argo CD Applications generated by the GitOps Service should only be modified by the cluster-agent, based on the
+// contents of the Application table. If the Argo CD Application is modified directly (for example, by a user with access
+// to the Argo CD namespace), that change would otherwise not be reverted until the next Operation on the Application.
+//
+// The self-heal loop periodically compares the Argo CD Applications with their Application rows, and reverts any changes.
+// - It is more frequent and lighter weight than the Namespace Reconciler: the Argo CD Application is updated directly,
+//   rather than via an Operation.
+// - Argo CD Applications that do not exist, or that don't have an Application row, are left to the Namespace Reconciler.
+
+// StartApplicationSelfHeal starts a goroutine that periodically reverts out-of-band changes to Argo CD Applications.
+func (r *ApplicationReconciler) StartApplicationSelfHeal() {
+	ctx := context.Background()
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("job", "applicationSelfHeal")
+
+	selfHealInterval := applicationSelfHealInterval(log)
+	if selfHealInterval > 0 {
+		r.startApplicationSelfHealTimer(ctx, selfHealInterval, log)
+		log.Info(fmt.Sprintf("Application self-heal has been scheduled every %s", selfHealInterval.String()))
+	} else {
+		log.Info("Application self-heal has been disabled")
+	}
+}
+
+func (r *ApplicationReconciler) startApplicationSelfHealTimer(ctx context.Context, selfHealInterval time.Duration, log logr.Logger) {
+	go func() {
+		timer := time.NewTimer(selfHealInterval)
+		<-timer.C
+
+		_, _ = sharedutil.CatchPanic(func() error {
+			corrected := selfHealArgoCDApplications(ctx, r.DB, r.Client, log)
+			log.V(logutil.LogLevel_Debug).Info("Application self-heal finished an iteration", "correctedApplications", corrected)
+			return nil
+		})
+
+		// Kick off the timer again, once the old task runs.
+		r.startApplicationSelfHealTimer(ctx, selfHealInterval, log)
+	}()
+}
+
+// applicationSelfHealInterval returns the interval of the self-heal loop, from the APPLICATION_SELF_HEAL_INTERVAL env var (if set).
+func applicationSelfHealInterval(log logr.Logger) time.Duration {
+
+	interval := os.Getenv(ApplicationSelfHealIntervalEnvVar)
+	if interval == "" {
+		return defaultApplicationSelfHealInterval
+	}
+
+	value, err := strconv.Atoi(interval)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("value of env var %s can't be converted to int", ApplicationSelfHealIntervalEnvVar))
+		return defaultApplicationSelfHealInterval
+	}
+
+	return time.Duration(value) * time.Minute
+}
+
+// selfHealArgoCDApplications reverts any differences between the Argo CD Applications generated by the GitOps Service,
+// and the Application rows they were generated from.
+//
+// Returns the number of Argo CD Applications that were reverted.
+func selfHealArgoCDApplications(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client, log logr.Logger) int {
+
+	argoApplicationList := appv1.ApplicationList{}
+	if err := k8sClient.List(ctx, &argoApplicationList, client.HasLabels{controllers.ArgoCDApplicationDatabaseIDLabel}); err != nil {
+		log.Error(err, "unable to list Argo CD Applications in Application self-heal")
+		return 0
+	}
+
+	corrected := 0
+	argoCDNamespaces := map[string]bool{}
+
+	for idx := range argoApplicationList.Items {
+		app := argoApplicationList.Items[idx] // To avoid "Implicit memory aliasing in for loop." error.
+
+		argoCDNamespaces[app.Namespace] = true
+
+		if app.DeletionTimestamp != nil {
+			continue
+		}
+
+		reverted, err := selfHealArgoCDApplication(ctx, &app, dbQueries, k8sClient, log)
+		if err != nil {
+			log.Error(err, "unable to self-heal Argo CD Application", "name", app.Name, "namespace", app.Namespace)
+			continue
+		}
+
+		if reverted {
+			corrected++
+			metrics.IncreaseApplicationSelfHealCorrections()
+		}
+	}
+
+	// Ensure the resource health checks of the Argo CD instances match those of their Applications: this also removes
+	// the health checks of deleted Applications.
+	for argoCDNamespace := range argoCDNamespaces {
+		if err := controllers.ReconcileResourceHealthChecks(ctx, k8sClient, argoCDNamespace, log); err != nil {
+			log.Error(err, "unable to reconcile resource health checks of Argo CD instance", "namespace", argoCDNamespace)
+		}
+	}
+
+	return corrected
+}
+
+// selfHealArgoCDApplication reverts the Argo CD Application to the contents of its Application row, if they differ.
+//
+// Returns true if the Argo CD Application was reverted, false otherwise.
+func selfHealArgoCDApplication(ctx context.Context, app *appv1.Application, dbQueries db.DatabaseQueries,
+	k8sClient client.Client, log logr.Logger) (bool, error) {
+
+	databaseID := app.Labels[controllers.ArgoCDApplicationDatabaseIDLabel]
+	if databaseID == "" {
+		return false, nil
+	}
+
+	dbApplication := db.Application{Application_id: databaseID}
+	if err := dbQueries.GetApplicationById(ctx, &dbApplication); err != nil {
+		if db.IsResultNotFoundError(err) {
+			// The Argo CD Application is orphaned: it will be deleted by the Namespace Reconciler.
+			return false, nil
+		}
+		return false, err
+	}
+
+	if dbApplication.Managed_environment_id == "" {
+		// The Application is invalid until the user fixes the GitOpsDeployment, and is handled by the Namespace Reconciler.
+		return false, nil
+	}
+
+	specDiff, err := controllers.CompareApplication(*app, dbApplication, log)
+	if err != nil {
+		return false, err
+	}
+
+	managedAnnotations, err := controllers.GetNotificationAnnotationsFromSpecField(dbApplication.Spec_field)
+	if err != nil {
+		return false, fmt.Errorf("unable to read managed annotations from spec field of Application row '%s': %v", dbApplication.Application_id, err)
+	}
+
+	if specDiff == "" && controllers.ReconcileNotificationAnnotations(app, managedAnnotations) {
+		specDiff = "managed annotations differ"
+	}
+
+	if specDiff == "" {
+		return false, nil
+	}
+
+	specFieldApp := &appv1.Application{}
+	if err := yaml.Unmarshal([]byte(dbApplication.Spec_field), specFieldApp); err != nil {
+		return false, fmt.Errorf("unable to unmarshal spec field of Application row '%s': %v", dbApplication.Application_id, err)
+	}
+
+	app.Spec.Destination = specFieldApp.Spec.Destination
+	app.Spec.Source = specFieldApp.Spec.Source
+	app.Spec.Project = specFieldApp.Spec.Project
+	app.Spec.SyncPolicy = specFieldApp.Spec.SyncPolicy
+	controllers.ReconcileNotificationAnnotations(app, managedAnnotations)
+
+	if err := k8sClient.Update(ctx, app); err != nil {
+		return false, err
+	}
+	logutil.LogAPIResourceChangeEvent(app.Namespace, app.Name, app, logutil.ResourceModified, log)
+
+	log.Info("Reverted out-of-band change to Argo CD Application", "name", app.Name, "namespace", app.Namespace,
+		"applicationID", dbApplication.Application_id, "specDiff", specDiff)
+
+	return true, nil
+}
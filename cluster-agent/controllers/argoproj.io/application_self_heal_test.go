@@ -0,0 +1,122 @@
+package argoprojio
+
+import (
+	"context"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Application self-heal tests", func() {
+
+	Context("Testing selfHealArgoCDApplications function", func() {
+
+		var (
+			ctx        context.Context
+			dbQueries  db.AllDatabaseQueries
+			k8sClient  client.Client
+			argoCdApp  appv1.Application
+			dbAppRow   db.Application
+			getArgoApp func() appv1.Application
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			dbQueries, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			_, managedEnvironment, _, gitopsEngineInstance, _, err := db.CreateSampleData(dbQueries)
+			Expect(err).To(BeNil())
+
+			scheme, argocdNamespace, kubesystemNamespace, workspace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+			Expect(appv1.AddToScheme(scheme)).To(Succeed())
+
+			var dummyApplicationSpec string
+			_, dummyApplicationSpec, argoCdApp, err = createDummyApplicationData()
+			Expect(err).To(BeNil())
+
+			dbAppRow = db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "test-my-application",
+				Spec_field:              dummyApplicationSpec,
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(dbQueries.CreateApplication(ctx, &dbAppRow)).To(Succeed())
+
+			argoCdApp.Labels = map[string]string{controllers.ArgoCDApplicationDatabaseIDLabel: dbAppRow.Application_id}
+
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(workspace, argocdNamespace, kubesystemNamespace).Build()
+			Expect(k8sClient.Create(ctx, &argoCdApp)).To(Succeed())
+
+			getArgoApp = func() appv1.Application {
+				app := appv1.Application{}
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&argoCdApp), &app)).To(Succeed())
+				return app
+			}
+		})
+
+		AfterEach(func() {
+			dbQueries.CloseDatabase()
+		})
+
+		It("should not modify an Argo CD Application that matches its Application row", func() {
+			log := logger.FromContext(ctx)
+
+			correctionsBefore := testutil.ToFloat64(metrics.ApplicationSelfHealCorrections)
+
+			Expect(selfHealArgoCDApplications(ctx, dbQueries, k8sClient, log)).To(Equal(0))
+			Expect(testutil.ToFloat64(metrics.ApplicationSelfHealCorrections)).To(Equal(correctionsBefore))
+		})
+
+		It("should revert an Argo CD Application that was modified outside of the GitOps Service", func() {
+			log := logger.FromContext(ctx)
+
+			app := getArgoApp()
+			app.Spec.Source.Path = "some/other/path"
+			app.Spec.SyncPolicy = nil
+			Expect(k8sClient.Update(ctx, &app)).To(Succeed())
+
+			correctionsBefore := testutil.ToFloat64(metrics.ApplicationSelfHealCorrections)
+
+			Expect(selfHealArgoCDApplications(ctx, dbQueries, k8sClient, log)).To(Equal(1))
+			Expect(testutil.ToFloat64(metrics.ApplicationSelfHealCorrections)).To(Equal(correctionsBefore + 1))
+
+			app = getArgoApp()
+			Expect(app.Spec.Source.Path).To(Equal(argoCdApp.Spec.Source.Path))
+			Expect(app.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(app.Spec.SyncPolicy.Automated).ToNot(BeNil())
+
+			By("verifying that a second run has nothing to correct")
+			Expect(selfHealArgoCDApplications(ctx, dbQueries, k8sClient, log)).To(Equal(0))
+		})
+
+		It("should not modify an Argo CD Application that no longer has an Application row", func() {
+			log := logger.FromContext(ctx)
+
+			app := getArgoApp()
+			app.Spec.Source.Path = "some/other/path"
+			Expect(k8sClient.Update(ctx, &app)).To(Succeed())
+
+			_, err := dbQueries.DeleteApplicationById(ctx, dbAppRow.Application_id)
+			Expect(err).To(BeNil())
+
+			Expect(selfHealArgoCDApplications(ctx, dbQueries, k8sClient, log)).To(Equal(0))
+			Expect(getArgoApp().Spec.Source.Path).To(Equal("some/other/path"))
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package argoprojio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ArgoCDVersionCheckIntervalEnvVar is the interval in minutes between runs of the Argo CD version check. Set to 0 to only run it on startup.
+	ArgoCDVersionCheckIntervalEnvVar = "ARGOCD_VERSION_CHECK_INTERVAL"
+
+	defaultArgoCDVersionCheckInterval = 30 * time.Minute // Interval in Minutes between runs of the Argo CD version check.
+)
+
+// Argo CD version check:
+//
+// On startup, and periodically thereafter (Argo CD may be upgraded while the cluster-agent is running), the cluster-agent
+// detects the version of the Argo CD instance in the Argo CD namespace:
+// - The version is recorded in the GitopsEngineInstance row of the namespace, so that the backend can skip features
+//   that the version does not support, when generating Argo CD Applications (see argocd.GetArgoCDCapabilities).
+// - If the version is older than the minimum supported version, an error is logged, and the 'argocd_version_unsupported'
+//   metric is set to 1 (so that an alert can be defined on it).
+
+// StartArgoCDVersionCheck checks the version of Argo CD, and then starts a goroutine that periodically checks it again.
+func (r *ApplicationReconciler) StartArgoCDVersionCheck() {
+	ctx := context.Background()
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("job", "argoCDVersionCheck")
+
+	go func() {
+		_, _ = sharedutil.CatchPanic(func() error {
+			runArgoCDVersionCheck(ctx, r.DB, r.Client, log)
+			return nil
+		})
+	}()
+
+	versionCheckInterval := argoCDVersionCheckInterval(log)
+	if versionCheckInterval > 0 {
+		r.startArgoCDVersionCheckTimer(ctx, versionCheckInterval, log)
+		log.Info(fmt.Sprintf("Argo CD version check has been scheduled every %s", versionCheckInterval.String()))
+	} else {
+		log.Info("Argo CD version check will only run on startup")
+	}
+}
+
+func (r *ApplicationReconciler) startArgoCDVersionCheckTimer(ctx context.Context, versionCheckInterval time.Duration, log logr.Logger) {
+	go func() {
+		timer := time.NewTimer(versionCheckInterval)
+		<-timer.C
+
+		_, _ = sharedutil.CatchPanic(func() error {
+			runArgoCDVersionCheck(ctx, r.DB, r.Client, log)
+			return nil
+		})
+
+		// Kick off the timer again, once the old task runs.
+		r.startArgoCDVersionCheckTimer(ctx, versionCheckInterval, log)
+	}()
+}
+
+// argoCDVersionCheckInterval returns the interval of the version check, from the ARGOCD_VERSION_CHECK_INTERVAL env var (if set).
+func argoCDVersionCheckInterval(log logr.Logger) time.Duration {
+
+	interval := os.Getenv(ArgoCDVersionCheckIntervalEnvVar)
+	if interval == "" {
+		return defaultArgoCDVersionCheckInterval
+	}
+
+	value, err := strconv.Atoi(interval)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("value of env var %s can't be converted to int", ArgoCDVersionCheckIntervalEnvVar))
+		return defaultArgoCDVersionCheckInterval
+	}
+
+	return time.Duration(value) * time.Minute
+}
+
+// runArgoCDVersionCheck detects the version of the Argo CD instance in the Argo CD namespace, records it in the
+// GitopsEngineInstance row of the namespace (if the row exists), and updates the Argo CD version metric.
+//
+// Returns the detected version, or an empty string if it could not be detected.
+func runArgoCDVersionCheck(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client, log logr.Logger) string {
+
+	argoCDNamespace := dbutil.GetGitOpsEngineSingleInstanceNamespace()
+
+	version, err := utils.DetectArgoCDVersion(ctx, k8sClient, argoCDNamespace)
+	if err != nil {
+		log.Error(err, "unable to detect the version of Argo CD", "namespace", argoCDNamespace)
+		return ""
+	}
+
+	if version == "" {
+		log.Info("The version of Argo CD could not be detected: all features will be assumed to be available", "namespace", argoCDNamespace)
+	}
+
+	supported := argosharedutil.IsArgoCDVersionSupported(version)
+	metrics.SetArgoCDVersionCheckResult(argoCDNamespace, version, supported)
+
+	if !supported {
+		log.Error(nil, fmt.Sprintf("The version of Argo CD is not supported: the minimum supported version is %s",
+			argosharedutil.MinimumSupportedArgoCDVersion.String()), "namespace", argoCDNamespace, "version", version)
+	}
+
+	gitopsEngineInstance, err := getGitopsEngineInstanceForArgoCDNamespace(ctx, dbQueries, k8sClient)
+	if err != nil {
+		log.Error(err, "unable to retrieve the GitopsEngineInstance of the Argo CD namespace")
+		return version
+	}
+
+	if gitopsEngineInstance == nil || gitopsEngineInstance.Argocd_version == version {
+		return version
+	}
+
+	if err := dbQueries.UpdateGitopsEngineInstanceArgoCDVersion(ctx, gitopsEngineInstance.Gitopsengineinstance_id, version); err != nil {
+		log.Error(err, "unable to update the Argo CD version of the GitopsEngineInstance", "gitopsEngineInstanceID", gitopsEngineInstance.Gitopsengineinstance_id)
+		return version
+	}
+
+	log.Info("Updated the Argo CD version of the GitopsEngineInstance", "gitopsEngineInstanceID", gitopsEngineInstance.Gitopsengineinstance_id,
+		"previousVersion", gitopsEngineInstance.Argocd_version, "version", version)
+
+	return version
+}
@@ -0,0 +1,113 @@
+package argoprojio
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Argo CD version check tests", func() {
+
+	Context("Testing runArgoCDVersionCheck function", func() {
+
+		var (
+			ctx                  context.Context
+			dbQueries            db.AllDatabaseQueries
+			k8sClient            client.Client
+			gitopsEngineInstance *db.GitopsEngineInstance
+		)
+
+		// createApplicationController creates an Argo CD application controller StatefulSet with the given image
+		createApplicationController := func(image string) {
+			statefulSet := appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "argocd-application-controller",
+					Namespace: dbutil.GetGitOpsEngineSingleInstanceNamespace(),
+					Labels:    map[string]string{"app.kubernetes.io/component": "application-controller"},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "argocd-application-controller", Image: image}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &statefulSet)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			dbQueries, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			scheme, argocdNamespace, kubesystemNamespace, workspace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+			Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(workspace, argocdNamespace, kubesystemNamespace).Build()
+
+			By("creating the GitOpsEngineInstance of the Argo CD namespace")
+			gitopsEngineInstance, _, _, err = dbutil.GetOrCreateGitopsEngineInstanceByInstanceNamespaceUID(ctx, *argocdNamespace,
+				string(kubesystemNamespace.UID), dbQueries, logger.FromContext(ctx))
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			dbQueries.CloseDatabase()
+		})
+
+		It("should record a supported version in the GitopsEngineInstance, and report it in the metrics", func() {
+
+			createApplicationController("quay.io/argoproj/argocd:v2.6.3")
+
+			version := runArgoCDVersionCheck(ctx, dbQueries, k8sClient, logger.FromContext(ctx))
+			Expect(version).To(Equal("v2.6.3"))
+
+			Expect(dbQueries.GetGitopsEngineInstanceById(ctx, gitopsEngineInstance)).To(Succeed())
+			Expect(gitopsEngineInstance.Argocd_version).To(Equal("v2.6.3"))
+
+			Expect(testutil.ToFloat64(metrics.ArgoCDVersionUnsupported.WithLabelValues(dbutil.GetGitOpsEngineSingleInstanceNamespace(), "v2.6.3"))).
+				To(Equal(float64(0)))
+		})
+
+		It("should report an unsupported version in the metrics", func() {
+
+			createApplicationController("quay.io/argoproj/argocd:v2.1.0")
+
+			version := runArgoCDVersionCheck(ctx, dbQueries, k8sClient, logger.FromContext(ctx))
+			Expect(version).To(Equal("v2.1.0"))
+
+			Expect(dbQueries.GetGitopsEngineInstanceById(ctx, gitopsEngineInstance)).To(Succeed())
+			Expect(gitopsEngineInstance.Argocd_version).To(Equal("v2.1.0"))
+
+			Expect(testutil.ToFloat64(metrics.ArgoCDVersionUnsupported.WithLabelValues(dbutil.GetGitOpsEngineSingleInstanceNamespace(), "v2.1.0"))).
+				To(Equal(float64(1)))
+		})
+
+		It("should not modify the GitopsEngineInstance if the version can't be detected", func() {
+
+			version := runArgoCDVersionCheck(ctx, dbQueries, k8sClient, logger.FromContext(ctx))
+			Expect(version).To(BeEmpty())
+
+			Expect(dbQueries.GetGitopsEngineInstanceById(ctx, gitopsEngineInstance)).To(Succeed())
+			Expect(gitopsEngineInstance.Argocd_version).To(BeEmpty())
+		})
+	})
+})
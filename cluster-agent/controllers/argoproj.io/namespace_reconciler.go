@@ -130,112 +130,117 @@ func syncCRsWithDB_Applications(ctx context.Context, dbQueries db.DatabaseQuerie
 
 		// Iterate over batch received above.
 		for _, applicationRowFromDB := range listOfApplicationsFromDB {
-			var applicationFromDB fauxargocd.FauxApplication
 
 			processedApplicationIds[applicationRowFromDB.Application_id] = false
 
-			// Fetch the Application object from DB
-			if err := yaml.Unmarshal([]byte(applicationRowFromDB.Spec_field), &applicationFromDB); err != nil {
-				log.Error(err, "Error occurred in Namespace Reconciler while unmarshalling application: "+applicationRowFromDB.Application_id)
-				continue // Skip to next iteration instead of stopping the entire loop.
-			}
+			reconcileApplicationRowWithArgoCD(ctx, applicationRowFromDB, specialClusterUser, dbQueries, client, log)
+		}
 
-			// Fetch the Application object from k8s
-			applicationFromArgoCD := appv1.Application{}
-			namespacedName := types.NamespacedName{
-				Name:      applicationFromDB.Name,
-				Namespace: applicationFromDB.Namespace}
-
-			if err := client.Get(ctx, namespacedName, &applicationFromArgoCD); err != nil {
-				if apierr.IsNotFound(err) {
-
-					if applicationRowFromDB.Managed_environment_id == "" {
-						// We shouldn't recreate any Argo CD Application resources that have a nil managed environment row
-						// - A nil managed environment row means the Application is currently invalid (until the user fixes it, by updating the GitOpsDeployment)
-
-						// So we just continue to the next Application
-						continue
-					}
-
-					log.Info("Application " + applicationRowFromDB.Application_id + " not found in ArgoCD, probably user deleted it, " +
-						"but it still exists in DB, hence recreating application in ArgoCD.")
-
-					// We need to recreate ArgoCD Application, to do that create Operation to inform ArgoCD about it.
-					dbOperationInput := db.Operation{
-						Instance_id:   applicationRowFromDB.Engine_instance_inst_id,
-						Resource_id:   applicationRowFromDB.Application_id,
-						Resource_type: db.OperationResourceType_Application,
-					}
-					engineInstanceDB := db.GitopsEngineInstance{
-						Gitopsengineinstance_id: dbOperationInput.Instance_id,
-					}
-					if err = dbQueries.GetGitopsEngineInstanceById(ctx, &engineInstanceDB); err != nil {
-						log.Error(err, "Unable to fetch GitopsEngineInstance")
-						continue
-					}
-					_, _, err = operations.CreateOperation(ctx, false, dbOperationInput,
-						specialClusterUser.Clusteruser_id, engineInstanceDB.Namespace_name, dbQueries, client, log)
-					if err != nil {
-						log.Error(err, "Namespace Reconciler is unable to create operation: "+dbOperationInput.ShortString())
-					}
-					log.Info("Operation is created to recreateArgoCD  Application " + applicationRowFromDB.Application_id)
-				} else {
-					log.Error(err, "Error occurred in Namespace Reconciler while fetching application from cluster: "+applicationRowFromDB.Application_id)
-				}
-				continue
-			}
+		// Skip processed entries in next iteration
+		offSet += appRowBatchSize
+	}
+
+	// Start a goroutine, because DeleteArgoCDApplication() function from cluster-agent/controllers may take some time to delete application.
+	go cleanOrphanedCRsfromCluster_Applications(argoApplications, processedApplicationIds, ctx, client, log)
+}
 
-			// If the managed_environment_id row is not empty, then it depends on whether the DB spec field matches the
-			if applicationRowFromDB.Managed_environment_id != "" {
+// Values returned by reconcileApplicationRowWithArgoCD, describing how the Argo CD Application was reconciled
+const (
+	// applicationReconcileResult_Created: the Argo CD Application did not exist, and an Operation was created to create it
+	applicationReconcileResult_Created = "created"
+	// applicationReconcileResult_Updated: the Argo CD Application differed from the Application row, and an Operation was created to update it
+	applicationReconcileResult_Updated = "updated"
+	// applicationReconcileResult_Unchanged: the Argo CD Application is consistent with the Application row (or should not exist yet)
+	applicationReconcileResult_Unchanged = "unchanged"
+	// applicationReconcileResult_Failed: an error occurred while reconciling the Argo CD Application
+	applicationReconcileResult_Failed = "failed"
+)
 
-				// At this point we have the applications from ArgoCD and DB, now compare them to check if they are not in Sync.
+// reconcileApplicationRowWithArgoCD compares an Application row with its Argo CD Application, and creates an Operation
+// to create/update the Argo CD Application if it is missing, or is not in sync with the Application row.
+func reconcileApplicationRowWithArgoCD(ctx context.Context, applicationRowFromDB db.Application, specialClusterUser db.ClusterUser,
+	dbQueries db.DatabaseQueries, client client.Client, log logr.Logger) string {
 
-				if compare, err := controllers.CompareApplication(applicationFromArgoCD, applicationRowFromDB, log); err != nil {
-					log.Error(err, "unable to compare application contents")
-					continue
-				} else if compare != "" {
-					log.Info("Argo application is not in Sync with DB, updating Argo CD App. Application:" + applicationRowFromDB.Application_id)
-				} else {
-					log.V(logutil.LogLevel_Debug).Info("Argo application is in Sync with DB, Application:" + applicationRowFromDB.Application_id)
-					continue
-				}
-			}
+	var applicationFromDB fauxargocd.FauxApplication
 
-			// else { if the managed_enviroment_id row is empty, then continue executing below as we should always create an Operation in this case }
+	// Fetch the Application object from DB
+	if err := yaml.Unmarshal([]byte(applicationRowFromDB.Spec_field), &applicationFromDB); err != nil {
+		log.Error(err, "Error occurred in Namespace Reconciler while unmarshalling application: "+applicationRowFromDB.Application_id)
+		return applicationReconcileResult_Failed
+	}
 
-			// At this point application from ArgoCD and DB are not in Sync (or the managed env is empty),
-			// so need to update Argo CD Application resource according to DB entry.
+	// Fetch the Application object from k8s
+	applicationFromArgoCD := appv1.Application{}
+	namespacedName := types.NamespacedName{
+		Name:      applicationFromDB.Name,
+		Namespace: applicationFromDB.Namespace}
 
-			// ArgoCD application and DB entry are not in Sync,
-			// ArgoCD should use the state of resources present in the database should
-			// Create Operation to inform Argo CD to get in Sync with database entry.
-			dbOperationInput := db.Operation{
-				Instance_id:   applicationRowFromDB.Engine_instance_inst_id,
-				Resource_id:   applicationRowFromDB.Application_id,
-				Resource_type: db.OperationResourceType_Application,
-			}
-			engineInstanceDB := db.GitopsEngineInstance{
-				Gitopsengineinstance_id: dbOperationInput.Instance_id,
-			}
-			if err := dbQueries.GetGitopsEngineInstanceById(ctx, &engineInstanceDB); err != nil {
-				log.Error(err, "Unable to fetch GitopsEngineInstance")
-				continue
-			}
-			if _, _, err := operations.CreateOperation(ctx, false, dbOperationInput,
-				specialClusterUser.Clusteruser_id, engineInstanceDB.Namespace_name, dbQueries, client, log); err != nil {
-				log.Error(err, "Namespace Reconciler is unable to create operation: "+dbOperationInput.ShortString())
-				continue
-			}
+	result := applicationReconcileResult_Updated
 
-			log.Info("Namespace Reconcile processed application: " + applicationRowFromDB.Application_id)
+	if err := client.Get(ctx, namespacedName, &applicationFromArgoCD); err != nil {
+		if !apierr.IsNotFound(err) {
+			log.Error(err, "Error occurred in Namespace Reconciler while fetching application from cluster: "+applicationRowFromDB.Application_id)
+			return applicationReconcileResult_Failed
 		}
 
-		// Skip processed entries in next iteration
-		offSet += appRowBatchSize
+		if applicationRowFromDB.Managed_environment_id == "" {
+			// We shouldn't recreate any Argo CD Application resources that have a nil managed environment row
+			// - A nil managed environment row means the Application is currently invalid (until the user fixes it, by updating the GitOpsDeployment)
+
+			// So we just continue to the next Application
+			return applicationReconcileResult_Unchanged
+		}
+
+		log.Info("Application " + applicationRowFromDB.Application_id + " not found in ArgoCD, probably user deleted it, " +
+			"but it still exists in DB, hence recreating application in ArgoCD.")
+
+		result = applicationReconcileResult_Created
+
+	} else if applicationRowFromDB.Managed_environment_id != "" {
+		// If the managed_environment_id row is not empty, then it depends on whether the DB spec field matches the
+		// Argo CD Application.
+
+		// At this point we have the applications from ArgoCD and DB, now compare them to check if they are not in Sync.
+
+		if compare, err := controllers.CompareApplication(applicationFromArgoCD, applicationRowFromDB, log); err != nil {
+			log.Error(err, "unable to compare application contents")
+			return applicationReconcileResult_Failed
+		} else if compare != "" {
+			log.Info("Argo application is not in Sync with DB, updating Argo CD App. Application:" + applicationRowFromDB.Application_id)
+		} else {
+			log.V(logutil.LogLevel_Debug).Info("Argo application is in Sync with DB, Application:" + applicationRowFromDB.Application_id)
+			return applicationReconcileResult_Unchanged
+		}
 	}
 
-	// Start a goroutine, because DeleteArgoCDApplication() function from cluster-agent/controllers may take some time to delete application.
-	go cleanOrphanedCRsfromCluster_Applications(argoApplications, processedApplicationIds, ctx, client, log)
+	// else { if the managed_enviroment_id row is empty, then continue executing below as we should always create an Operation in this case }
+
+	// At this point the application from ArgoCD doesn't exist, or the application from ArgoCD and DB are not in Sync (or
+	// the managed env is empty), so need to create/update Argo CD Application resource according to DB entry.
+
+	// ArgoCD should use the state of resources present in the database:
+	// Create Operation to inform Argo CD to get in Sync with database entry.
+	dbOperationInput := db.Operation{
+		Instance_id:   applicationRowFromDB.Engine_instance_inst_id,
+		Resource_id:   applicationRowFromDB.Application_id,
+		Resource_type: db.OperationResourceType_Application,
+	}
+	engineInstanceDB := db.GitopsEngineInstance{
+		Gitopsengineinstance_id: dbOperationInput.Instance_id,
+	}
+	if err := dbQueries.GetGitopsEngineInstanceById(ctx, &engineInstanceDB); err != nil {
+		log.Error(err, "Unable to fetch GitopsEngineInstance")
+		return applicationReconcileResult_Failed
+	}
+	if _, _, err := operations.CreateOperation(ctx, false, dbOperationInput,
+		specialClusterUser.Clusteruser_id, engineInstanceDB.Namespace_name, dbQueries, client, log); err != nil {
+		log.Error(err, "Namespace Reconciler is unable to create operation: "+dbOperationInput.ShortString())
+		return applicationReconcileResult_Failed
+	}
+
+	log.Info("Namespace Reconcile processed application: "+applicationRowFromDB.Application_id, "result", result)
+
+	return result
 }
 
 func syncCRsWithDB_Applications_Delete_Operations(ctx context.Context, dbq db.DatabaseQueries, client client.Client, log logr.Logger) {
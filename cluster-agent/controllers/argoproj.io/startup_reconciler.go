@@ -0,0 +1,167 @@
+package argoprojio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// applicationReconcileResult_Deleted: the Argo CD Application no longer had an Application row, and was deleted
+	applicationReconcileResult_Deleted = "deleted"
+
+	// Number of Application rows between each progress log message of the startup reconciliation
+	startupReconciliationProgressInterval = 50
+)
+
+// Startup reconciliation:
+//
+// The backend informs the cluster-agent of database changes via Operations. If the cluster-agent misses an Operation
+// (for example, if the Operation was cleaned up while the cluster-agent was down, or during an upgrade), the Argo CD
+// Applications would otherwise not be consistent with the database until the first run of the Namespace Reconciler.
+//
+// So, on startup, the cluster-agent does a full reconciliation pass of the Application rows of its GitOpsEngineInstance:
+// - Argo CD Applications that are missing, or not in sync with their Application row, are created/updated via an Operation.
+// - Argo CD Applications that no longer have an Application row are deleted.
+
+// RunStartupReconciliation reconciles the Argo CD Applications of the GitOpsEngineInstance with the Application table.
+//
+// This function is expected to be added to the controller manager (as a manager.RunnableFunc), so that it runs once the
+// manager's caches have started.
+func (r *ApplicationReconciler) RunStartupReconciliation(ctx context.Context) error {
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("job", "startupReconciliation")
+
+	_, _ = sharedutil.CatchPanic(func() error {
+		if err := runStartupReconciliation(ctx, r.DB, r.Client, log); err != nil {
+			log.Error(err, "Startup reconciliation failed: the Namespace Reconciler will reconcile the Argo CD Applications on its next run")
+		}
+		return nil
+	})
+
+	// Errors are not returned, as they would stop the manager: the Namespace Reconciler will eventually catch up.
+	return nil
+}
+
+// runStartupReconciliation reconciles the Application rows of the GitOpsEngineInstance of the Argo CD namespace, and
+// records the number of Application rows processed (by result) in the startup reconciliation metrics.
+func runStartupReconciliation(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client, log logr.Logger) error {
+
+	start := time.Now()
+
+	// 1) Locate the GitOpsEngineInstance of the Argo CD namespace
+	gitopsEngineInstance, err := getGitopsEngineInstanceForArgoCDNamespace(ctx, dbQueries, k8sClient)
+	if err != nil {
+		return err
+	}
+	if gitopsEngineInstance == nil {
+		log.Info("Startup reconciliation skipped: no GitOpsEngineInstance exists for the Argo CD namespace")
+		return nil
+	}
+
+	log = log.WithValues("gitopsEngineInstanceID", gitopsEngineInstance.Gitopsengineinstance_id)
+
+	// We need a ClusterUser for creating Operations, so use the special cluster user (as with the Namespace Reconciler)
+	var specialClusterUser db.ClusterUser
+	if err := dbQueries.GetOrCreateSpecialClusterUser(ctx, &specialClusterUser); err != nil {
+		return fmt.Errorf("unable to retrieve special cluster user: %v", err)
+	}
+
+	// 2) List the Argo CD Applications before the Application rows, so that an Application row that is created in the
+	// meantime will not cause its (new) Argo CD Application to be seen as orphaned.
+	var argoApplicationList appv1.ApplicationList
+	if err := k8sClient.List(ctx, &argoApplicationList, client.InNamespace(gitopsEngineInstance.Namespace_name)); err != nil {
+		return fmt.Errorf("unable to list Argo CD Applications: %v", err)
+	}
+
+	var applications []db.Application
+	if _, err := dbQueries.ListApplicationsForGitopsEngineInstance(ctx, gitopsEngineInstance.Gitopsengineinstance_id, &applications); err != nil {
+		return err
+	}
+
+	log.Info("Startup reconciliation started", "applications", len(applications), "argoCDApplications", len(argoApplicationList.Items))
+
+	results := map[string]int{
+		applicationReconcileResult_Created:   0,
+		applicationReconcileResult_Updated:   0,
+		applicationReconcileResult_Deleted:   0,
+		applicationReconcileResult_Unchanged: 0,
+		applicationReconcileResult_Failed:    0,
+	}
+
+	// 3) Create/update the Argo CD Applications of each Application row
+	processedApplicationIds := make(map[string]any)
+	for i, application := range applications {
+
+		processedApplicationIds[application.Application_id] = false
+
+		result := reconcileApplicationRowWithArgoCD(ctx, application, specialClusterUser, dbQueries, k8sClient, log)
+		results[result]++
+
+		if (i+1)%startupReconciliationProgressInterval == 0 {
+			log.Info("Startup reconciliation in progress", "processed", i+1, "total", len(applications))
+		}
+	}
+
+	// 4) Delete the Argo CD Applications that no longer have an Application row
+	deletedApplications := cleanOrphanedCRsfromCluster_Applications(argoApplicationList.Items, processedApplicationIds, ctx, k8sClient, log)
+	results[applicationReconcileResult_Deleted] = len(deletedApplications)
+
+	duration := time.Since(start)
+	metrics.SetStartupReconciliationResults(results, duration)
+
+	log.Info("Startup reconciliation completed", "duration", duration.String(),
+		"created", results[applicationReconcileResult_Created],
+		"updated", results[applicationReconcileResult_Updated],
+		"deleted", results[applicationReconcileResult_Deleted],
+		"unchanged", results[applicationReconcileResult_Unchanged],
+		"failed", results[applicationReconcileResult_Failed])
+
+	return nil
+}
+
+// getGitopsEngineInstanceForArgoCDNamespace returns the GitopsEngineInstance row of the Argo CD namespace, or nil if
+// it does not exist (for example, if no GitOpsDeployments have been deployed yet).
+func getGitopsEngineInstanceForArgoCDNamespace(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client) (*db.GitopsEngineInstance, error) {
+
+	argoCDNamespace := corev1.Namespace{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: dbutil.GetGitOpsEngineSingleInstanceNamespace()}, &argoCDNamespace); err != nil {
+		return nil, fmt.Errorf("unable to retrieve Argo CD namespace: %v", err)
+	}
+
+	dbResourceMapping := db.KubernetesToDBResourceMapping{
+		KubernetesResourceType: db.K8sToDBMapping_Namespace,
+		KubernetesResourceUID:  string(argoCDNamespace.UID),
+		DBRelationType:         db.K8sToDBMapping_GitopsEngineInstance,
+	}
+	if err := dbQueries.GetDBResourceMappingForKubernetesResource(ctx, &dbResourceMapping); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to retrieve GitOpsEngineInstance mapping of Argo CD namespace: %v", err)
+	}
+
+	gitopsEngineInstance := db.GitopsEngineInstance{
+		Gitopsengineinstance_id: dbResourceMapping.DBRelationKey,
+	}
+	if err := dbQueries.GetGitopsEngineInstanceById(ctx, &gitopsEngineInstance); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to retrieve GitOpsEngineInstance of Argo CD namespace: %v", err)
+	}
+
+	return &gitopsEngineInstance, nil
+}
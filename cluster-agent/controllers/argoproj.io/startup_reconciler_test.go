@@ -0,0 +1,145 @@
+package argoprojio
+
+import (
+	"context"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Startup reconciliation tests", func() {
+
+	Context("Testing runStartupReconciliation function", func() {
+
+		var (
+			ctx                  context.Context
+			dbQueries            db.AllDatabaseQueries
+			k8sClient            client.Client
+			managedEnvironment   *db.ManagedEnvironment
+			gitopsEngineInstance *db.GitopsEngineInstance
+			dummyApplicationSpec string
+			argoCdApp            appv1.Application
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			dbQueries, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			_, managedEnvironment, _, _, _, err = db.CreateSampleData(dbQueries)
+			Expect(err).To(BeNil())
+
+			scheme, argocdNamespace, kubesystemNamespace, workspace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+			Expect(appv1.AddToScheme(scheme)).To(Succeed())
+
+			_, dummyApplicationSpec, argoCdApp, err = createDummyApplicationData()
+			Expect(err).To(BeNil())
+
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(workspace, argocdNamespace, kubesystemNamespace).Build()
+
+			By("creating the GitOpsEngineInstance of the Argo CD namespace")
+			gitopsEngineInstance, _, _, err = dbutil.GetOrCreateGitopsEngineInstanceByInstanceNamespaceUID(ctx, *argocdNamespace,
+				string(kubesystemNamespace.UID), dbQueries, logger.FromContext(ctx))
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			dbQueries.CloseDatabase()
+		})
+
+		listOperations := func() []managedgitopsv1alpha1.Operation {
+			operationList := managedgitopsv1alpha1.OperationList{}
+			Expect(k8sClient.List(ctx, &operationList)).To(Succeed())
+			return operationList.Items
+		}
+
+		It("should create an Operation for an Application row with a missing Argo CD Application, and report it in the metrics", func() {
+
+			applicationRow := db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "test-my-application",
+				Spec_field:              dummyApplicationSpec,
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(dbQueries.CreateApplication(ctx, &applicationRow)).To(Succeed())
+
+			err := runStartupReconciliation(ctx, dbQueries, k8sClient, logger.FromContext(ctx))
+			Expect(err).To(BeNil())
+
+			operations := listOperations()
+			Expect(operations).To(HaveLen(1))
+
+			dbOperation := db.Operation{Operation_id: operations[0].Spec.OperationID}
+			Expect(dbQueries.GetOperationById(ctx, &dbOperation)).To(Succeed())
+			Expect(dbOperation.Resource_id).To(Equal(applicationRow.Application_id))
+			Expect(dbOperation.Resource_type).To(Equal(db.OperationResourceType_Application))
+
+			Expect(testutil.ToFloat64(metrics.StartupReconciliationApplications.WithLabelValues(applicationReconcileResult_Created))).To(Equal(float64(1)))
+			Expect(testutil.ToFloat64(metrics.StartupReconciliationApplications.WithLabelValues(applicationReconcileResult_Unchanged))).To(Equal(float64(0)))
+		})
+
+		It("should not create an Operation for an Argo CD Application that is in sync with its Application row", func() {
+
+			applicationRow := db.Application{
+				Application_id:          "test-my-application",
+				Name:                    "test-my-application",
+				Spec_field:              dummyApplicationSpec,
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			Expect(dbQueries.CreateApplication(ctx, &applicationRow)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &argoCdApp)).To(Succeed())
+
+			err := runStartupReconciliation(ctx, dbQueries, k8sClient, logger.FromContext(ctx))
+			Expect(err).To(BeNil())
+
+			Expect(listOperations()).To(BeEmpty())
+			Expect(testutil.ToFloat64(metrics.StartupReconciliationApplications.WithLabelValues(applicationReconcileResult_Unchanged))).To(Equal(float64(1)))
+		})
+
+		It("should not delete Argo CD Applications that were not created by the GitOps Service", func() {
+
+			nonGitOpsServiceApp := appv1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "not-a-gitops-service-app",
+					Namespace: dbutil.GetGitOpsEngineSingleInstanceNamespace(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, &nonGitOpsServiceApp)).To(Succeed())
+
+			err := runStartupReconciliation(ctx, dbQueries, k8sClient, logger.FromContext(ctx))
+			Expect(err).To(BeNil())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&nonGitOpsServiceApp), &nonGitOpsServiceApp)).To(Succeed())
+			Expect(testutil.ToFloat64(metrics.StartupReconciliationApplications.WithLabelValues(applicationReconcileResult_Deleted))).To(Equal(float64(0)))
+		})
+
+		It("should skip reconciliation if there is no GitOpsEngineInstance for the Argo CD namespace", func() {
+
+			_, err := dbQueries.DeleteGitopsEngineInstanceById(ctx, gitopsEngineInstance.Gitopsengineinstance_id)
+			Expect(err).To(BeNil())
+
+			err = runStartupReconciliation(ctx, dbQueries, k8sClient, logger.FromContext(ctx))
+			Expect(err).To(BeNil())
+
+			Expect(listOperations()).To(BeEmpty())
+		})
+	})
+})
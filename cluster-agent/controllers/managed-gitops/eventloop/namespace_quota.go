@@ -0,0 +1,195 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EnableNamespaceResourceQuotasEnvVar may be set to 'true' to enable the creation of ResourceQuotas in the destination
+	// namespaces of Argo CD Applications, from the namespace quota hints of their managed environment
+	// (.spec.namespaceQuota of the GitOpsDeploymentManagedEnvironment). Disabled by default.
+	EnableNamespaceResourceQuotasEnvVar = "ENABLE_NAMESPACE_RESOURCE_QUOTAS"
+
+	// NamespaceResourceQuotaName is the name of the ResourceQuota that is created in each destination namespace
+	NamespaceResourceQuotaName = "gitops-service-namespace-quota"
+
+	// namespaceResourceQuotaManagedEnvLabel is set on ResourceQuotas created by the cluster-agent, and contains the
+	// database ID of the managed environment whose namespace quota hints the ResourceQuota was generated from.
+	namespaceResourceQuotaManagedEnvLabel = "managed-gitops.redhat.com/managed-environment-id"
+)
+
+// newManagedEnvironmentClient returns a K8s client for the cluster of the cluster credentials, connecting in the same way
+// as Argo CD does from the cluster secret of the credentials (replaced by unit tests)
+var newManagedEnvironmentClient = func(clusterCreds db.ClusterCredentials) (client.Client, error) {
+	return client.New(argosharedutil.GenerateRestConfigForClusterCredentials(clusterCreds), client.Options{Scheme: scheme.Scheme})
+}
+
+func isNamespaceResourceQuotaEnabled() bool {
+	return os.Getenv(EnableNamespaceResourceQuotasEnvVar) == "true"
+}
+
+// reconcileNamespaceResourceQuota ensures that the destination namespace of an Application contains a ResourceQuota
+// matching the namespace quota hints of the managed environment of the Application.
+//
+// If the managed environment has no namespace quota hints, the destination namespace is not modified (and no client is
+// created for the managed environment): a ResourceQuota created before the hints were removed is left as is.
+//
+// This is a no-op unless enabled via EnableNamespaceResourceQuotasEnvVar. If the destination namespace does not exist
+// yet (for example, if it will be created by Argo CD on sync), the ResourceQuota is created on a subsequent Operation.
+func reconcileNamespaceResourceQuota(ctx context.Context, application db.Application, destinationNamespace string, opConfig operationConfig) error {
+
+	if !isNamespaceResourceQuotaEnabled() || application.Managed_environment_id == "" || destinationNamespace == "" {
+		return nil
+	}
+
+	managedEnv := &db.ManagedEnvironment{
+		Managedenvironment_id: application.Managed_environment_id,
+	}
+	if err := opConfig.dbQueries.GetManagedEnvironmentById(ctx, managedEnv); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to get managed environment '%s': %v", managedEnv.Managedenvironment_id, err)
+	}
+
+	clusterCreds := &db.ClusterCredentials{
+		Clustercredentials_cred_id: managedEnv.Clustercredentials_id,
+	}
+	if err := opConfig.dbQueries.GetClusterCredentialsById(ctx, clusterCreds); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to get cluster credentials '%s': %v", clusterCreds.Clustercredentials_cred_id, err)
+	}
+
+	expectedHard, err := generateNamespaceResourceQuotaHard(*clusterCreds)
+	if err != nil {
+		return err
+	}
+
+	if len(expectedHard) == 0 {
+		return nil
+	}
+
+	managedEnvClient, err := newManagedEnvironmentClient(*clusterCreds)
+	if err != nil {
+		return fmt.Errorf("unable to create client for managed environment '%s': %v", managedEnv.Managedenvironment_id, err)
+	}
+
+	log := opConfig.log.WithValues("resourceQuotaName", NamespaceResourceQuotaName, "resourceQuotaNamespace", destinationNamespace)
+
+	existingQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NamespaceResourceQuotaName,
+			Namespace: destinationNamespace,
+		},
+	}
+
+	quotaExists := true
+	if err := managedEnvClient.Get(ctx, client.ObjectKeyFromObject(existingQuota), existingQuota); err != nil {
+		if !apierr.IsNotFound(err) {
+			return fmt.Errorf("unable to retrieve ResourceQuota in namespace '%s': %v", destinationNamespace, err)
+		}
+		quotaExists = false
+	}
+
+	// A) The ResourceQuota doesn't exist, so create it
+	if !quotaExists {
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      NamespaceResourceQuotaName,
+				Namespace: destinationNamespace,
+				Labels: map[string]string{
+					namespaceResourceQuotaManagedEnvLabel: managedEnv.Managedenvironment_id,
+				},
+			},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard: expectedHard,
+			},
+		}
+
+		if err := managedEnvClient.Create(ctx, quota); err != nil {
+			if apierr.IsNotFound(err) {
+				log.Info("Destination namespace does not exist yet, so the ResourceQuota was not created")
+				return nil
+			}
+			return fmt.Errorf("unable to create ResourceQuota in namespace '%s': %v", destinationNamespace, err)
+		}
+		logutil.LogAPIResourceChangeEvent(quota.Namespace, quota.Name, quota, logutil.ResourceCreated, log)
+
+		return nil
+	}
+
+	// B) The ResourceQuota exists, so update it if it differs from the namespace quota hints
+	if resourceListsEqual(existingQuota.Spec.Hard, expectedHard) &&
+		existingQuota.Labels[namespaceResourceQuotaManagedEnvLabel] == managedEnv.Managedenvironment_id {
+		return nil
+	}
+
+	existingQuota.Spec.Hard = expectedHard
+	if existingQuota.Labels == nil {
+		existingQuota.Labels = map[string]string{}
+	}
+	existingQuota.Labels[namespaceResourceQuotaManagedEnvLabel] = managedEnv.Managedenvironment_id
+
+	if err := managedEnvClient.Update(ctx, existingQuota); err != nil {
+		return fmt.Errorf("unable to update ResourceQuota in namespace '%s': %v", destinationNamespace, err)
+	}
+	logutil.LogAPIResourceChangeEvent(existingQuota.Namespace, existingQuota.Name, existingQuota, logutil.ResourceModified, log)
+
+	return nil
+}
+
+// generateNamespaceResourceQuotaHard returns the hard limits of the ResourceQuota, from the namespace quota hints of the
+// cluster credentials. Returns an empty list if there are no hints.
+func generateNamespaceResourceQuotaHard(clusterCreds db.ClusterCredentials) (corev1.ResourceList, error) {
+
+	res := corev1.ResourceList{}
+
+	if clusterCreds.Namespace_quota_cpu != "" {
+		quantity, err := resource.ParseQuantity(clusterCreds.Namespace_quota_cpu)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace quota cpu value '%s': %v", clusterCreds.Namespace_quota_cpu, err)
+		}
+		res[corev1.ResourceRequestsCPU] = quantity
+	}
+
+	if clusterCreds.Namespace_quota_memory != "" {
+		quantity, err := resource.ParseQuantity(clusterCreds.Namespace_quota_memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace quota memory value '%s': %v", clusterCreds.Namespace_quota_memory, err)
+		}
+		res[corev1.ResourceRequestsMemory] = quantity
+	}
+
+	return res, nil
+}
+
+// resourceListsEqual returns true if the lists contain the same resources, with equal quantities, false otherwise.
+func resourceListsEqual(a corev1.ResourceList, b corev1.ResourceList) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, quantity := range a {
+		other, exists := b[name]
+		if !exists || quantity.Cmp(other) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
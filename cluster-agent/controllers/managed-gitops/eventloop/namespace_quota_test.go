@@ -0,0 +1,158 @@
+package eventloop
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("Namespace ResourceQuota Tests", func() {
+
+	const destinationNamespace = "my-destination-namespace"
+
+	var (
+		ctx                         context.Context
+		dbQueries                   db.AllDatabaseQueries
+		managedEnvClient            client.Client
+		opConfig                    operationConfig
+		managedEnv                  db.ManagedEnvironment
+		application                 db.Application
+		originalNewManagedEnvClient func(db.ClusterCredentials) (client.Client, error)
+	)
+
+	// replaceClusterCredentials points the managed environment to new cluster credentials with the given namespace
+	// quota hints, as cluster credentials are replaced (rather than updated) when the managed environment changes.
+	replaceClusterCredentials := func(cpu string, memory string) {
+		clusterCredentials := db.ClusterCredentials{
+			Host:                        "https://my-api-url",
+			Serviceaccount_bearer_token: "token",
+			Namespace_quota_cpu:         cpu,
+			Namespace_quota_memory:      memory,
+		}
+		Expect(dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)).To(Succeed())
+
+		managedEnv.Clustercredentials_id = clusterCredentials.Clustercredentials_cred_id
+		Expect(dbQueries.UpdateManagedEnvironment(ctx, &managedEnv)).To(Succeed())
+	}
+
+	getQuota := func() (*corev1.ResourceQuota, error) {
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: NamespaceResourceQuotaName, Namespace: destinationNamespace},
+		}
+		err := managedEnvClient.Get(ctx, client.ObjectKeyFromObject(quota), quota)
+		return quota, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		dbQueries, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		managedEnvClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: destinationNamespace},
+		}).Build()
+
+		originalNewManagedEnvClient = newManagedEnvironmentClient
+		newManagedEnvironmentClient = func(db.ClusterCredentials) (client.Client, error) {
+			return managedEnvClient, nil
+		}
+
+		Expect(os.Setenv(EnableNamespaceResourceQuotasEnvVar, "true")).To(Succeed())
+
+		clusterCredentials := db.ClusterCredentials{
+			Host:                        "https://my-api-url",
+			Serviceaccount_bearer_token: "token",
+			Namespace_quota_cpu:         "2",
+			Namespace_quota_memory:      "4Gi",
+		}
+		Expect(dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)).To(Succeed())
+
+		managedEnv = db.ManagedEnvironment{
+			Name:                  "my-managed-env",
+			Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+		}
+		Expect(dbQueries.CreateManagedEnvironment(ctx, &managedEnv)).To(Succeed())
+
+		application = db.Application{
+			Application_id:         "test-my-application",
+			Managed_environment_id: managedEnv.Managedenvironment_id,
+		}
+
+		opConfig = operationConfig{
+			dbQueries: dbQueries,
+			log:       log.FromContext(ctx),
+		}
+	})
+
+	AfterEach(func() {
+		newManagedEnvironmentClient = originalNewManagedEnvClient
+		Expect(os.Unsetenv(EnableNamespaceResourceQuotasEnvVar)).To(Succeed())
+		dbQueries.CloseDatabase()
+	})
+
+	It("should create a ResourceQuota from the namespace quota hints of the managed environment", func() {
+		Expect(reconcileNamespaceResourceQuota(ctx, application, destinationNamespace, opConfig)).To(Succeed())
+
+		quota, err := getQuota()
+		Expect(err).To(BeNil())
+		Expect(quota.Labels[namespaceResourceQuotaManagedEnvLabel]).To(Equal(application.Managed_environment_id))
+		Expect(quota.Spec.Hard).To(HaveLen(2))
+		Expect(quota.Spec.Hard.Name(corev1.ResourceRequestsCPU, resource.DecimalSI).Cmp(resource.MustParse("2"))).To(Equal(0))
+		Expect(quota.Spec.Hard.Name(corev1.ResourceRequestsMemory, resource.BinarySI).Cmp(resource.MustParse("4Gi"))).To(Equal(0))
+	})
+
+	It("should update the ResourceQuota when the namespace quota hints change, and leave it as is when they are removed", func() {
+		Expect(reconcileNamespaceResourceQuota(ctx, application, destinationNamespace, opConfig)).To(Succeed())
+
+		By("updating the namespace quota hints")
+		replaceClusterCredentials("500m", "")
+
+		Expect(reconcileNamespaceResourceQuota(ctx, application, destinationNamespace, opConfig)).To(Succeed())
+
+		quota, err := getQuota()
+		Expect(err).To(BeNil())
+		Expect(quota.Spec.Hard).To(HaveLen(1))
+		Expect(quota.Spec.Hard.Name(corev1.ResourceRequestsCPU, resource.DecimalSI).Cmp(resource.MustParse("500m"))).To(Equal(0))
+
+		By("removing the namespace quota hints, which should not create a client for the managed environment")
+		replaceClusterCredentials("", "")
+
+		newManagedEnvironmentClient = func(db.ClusterCredentials) (client.Client, error) {
+			Fail("a client should not be created when there are no namespace quota hints")
+			return nil, nil
+		}
+
+		Expect(reconcileNamespaceResourceQuota(ctx, application, destinationNamespace, opConfig)).To(Succeed())
+
+		quota, err = getQuota()
+		Expect(err).To(BeNil())
+		Expect(quota.Spec.Hard).To(HaveLen(1))
+	})
+
+	It("should not modify the destination namespace if ResourceQuotas are not enabled", func() {
+		Expect(os.Unsetenv(EnableNamespaceResourceQuotasEnvVar)).To(Succeed())
+
+		Expect(reconcileNamespaceResourceQuota(ctx, application, destinationNamespace, opConfig)).To(Succeed())
+
+		_, err := getQuota()
+		Expect(apierr.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,76 @@
+package eventloop
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Interval at which the Operation backlog is read from the database. This is shorter than the other metric
+	// intervals, so that alerts on the backlog are timely.
+	operationBacklogMetricInterval = 1 * time.Minute
+)
+
+// OperationBacklogMetricUpdater periodically updates the Operation backlog metric, from the Operation rows of the database.
+type OperationBacklogMetricUpdater struct {
+	DB db.DatabaseQueries
+}
+
+func (r *OperationBacklogMetricUpdater) StartOperationBacklogMetricUpdater() {
+	go func() {
+
+		// Timer to trigger update
+		timer := time.NewTimer(operationBacklogMetricInterval)
+		<-timer.C
+
+		ctx := context.Background()
+		log := log.FromContext(ctx).
+			WithName(logutil.LogLogger_managed_gitops).
+			WithValues("component", "operation-backlog-metric-updater")
+
+		_, _ = sharedutil.CatchPanic(func() error {
+			updateOperationBacklogMetrics(ctx, r.DB, log)
+			return nil
+		})
+
+		// Kick off the timer again, once the old task runs.
+		// This ensures that at least 'operationBacklogMetricInterval' time elapses from the end of one run to the beginning of another.
+		r.StartOperationBacklogMetricUpdater()
+	}()
+}
+
+// updateOperationBacklogMetrics sets the number of Waiting and In_Progress Operation rows in the backlog metric.
+func updateOperationBacklogMetrics(ctx context.Context, dbQueries db.DatabaseQueries, log logr.Logger) {
+
+	var operationDB db.Operation
+
+	operationStateCounts, err := dbQueries.CountOperationDBRowsByState(ctx, &operationDB)
+	if err != nil {
+		log.Error(err, "unable to count the Operation rows by state, for the Operation backlog metric")
+		return
+	}
+
+	// States with no rows are not returned by the query, so default them to 0
+	backlog := map[db.OperationState]int{
+		db.OperationState_Waiting:     0,
+		db.OperationState_In_Progress: 0,
+	}
+
+	for _, stateCount := range operationStateCounts {
+		state := db.OperationState(stateCount.State)
+		if _, exists := backlog[state]; exists {
+			backlog[state] = stateCount.RowCount
+		}
+	}
+
+	for state, count := range backlog {
+		metrics.SetOperationBacklog(state, count)
+	}
+}
@@ -0,0 +1,58 @@
+package eventloop
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Test for Operation backlog metric", func() {
+	Context("Prometheus metrics responds to the number of Waiting/In_Progress operation rows in the database", func() {
+
+		It("should set the backlog of Waiting and In_Progress operations, ignoring completed operations", func() {
+			ctx := context.Background()
+			log := logger.FromContext(ctx)
+
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			dbq, err := db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+			defer dbq.CloseDatabase()
+
+			_, managedEnvironment, _, gitopsEngineInstance, clusterAccess, err := db.CreateSampleData(dbq)
+			Expect(err).To(BeNil())
+
+			metrics.ClearOperationMetrics()
+
+			By("creating operation rows in different states")
+			for i, state := range []db.OperationState{db.OperationState_Waiting, db.OperationState_Waiting,
+				db.OperationState_In_Progress, db.OperationState_Completed, db.OperationState_Failed} {
+
+				operationDB := &db.Operation{
+					Operation_id:            "test-operation-" + string(rune('a'+i)),
+					Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+					Resource_id:             managedEnvironment.Managedenvironment_id,
+					Resource_type:           db.OperationResourceType_ManagedEnvironment,
+					State:                   state,
+					Operation_owner_user_id: clusterAccess.Clusteraccess_user_id,
+				}
+				err = dbq.CreateOperation(ctx, operationDB, operationDB.Operation_owner_user_id)
+				Expect(err).To(BeNil())
+			}
+
+			updateOperationBacklogMetrics(ctx, dbq, log)
+
+			Expect(testutil.ToFloat64(metrics.OperationBacklog.WithLabelValues(string(db.OperationState_Waiting)))).To(Equal(float64(2)))
+			Expect(testutil.ToFloat64(metrics.OperationBacklog.WithLabelValues(string(db.OperationState_In_Progress)))).To(Equal(float64(1)))
+
+			By("verifying that completed states are not reported as backlog")
+			Expect(testutil.CollectAndCount(metrics.OperationBacklog)).To(Equal(2))
+		})
+	})
+})
@@ -3,9 +3,11 @@ package eventloop
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
@@ -15,6 +17,7 @@ import (
 	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
@@ -48,6 +51,12 @@ import (
 // https://docs.google.com/document/d/1e1UwCbwK-Ew5ODWedqp_jZmhiZzYWaxEvIL-tqebMzo/edit#heading=h.9vyguee8vhow
 type OperationEventLoop struct {
 	eventLoopInputChannel chan operationEventLoopEvent
+
+	// taskRetryLoop processes the Operations
+	taskRetryLoop *sharedutil.TaskRetryLoop
+
+	// draining is set to 1 (via atomic) once Drain is called: new events are then dropped.
+	draining int32
 }
 
 // Functions that return a boolean indicating whether the request should be retried, should use these constants
@@ -57,13 +66,16 @@ const (
 	shouldRetryFalse = false
 )
 
-func NewOperationEventLoop() *OperationEventLoop {
+// NewOperationEventLoop creates a new OperationEventLoop. 'processingOwner' is the identity of this cluster-agent replica,
+// which is recorded on the Operations that it is processing: see ReleaseInProgressOperationsOfGitopsEngineCluster.
+func NewOperationEventLoop(processingOwner string) *OperationEventLoop {
 	channel := make(chan operationEventLoopEvent)
 
 	res := &OperationEventLoop{}
 	res.eventLoopInputChannel = channel
+	res.taskRetryLoop = sharedutil.NewTaskRetryLoop("cluster-agent")
 
-	go operationEventLoopRouter(channel)
+	go operationEventLoopRouter(channel, res.taskRetryLoop, processingOwner)
 
 	return res
 
@@ -76,19 +88,33 @@ type operationEventLoopEvent struct {
 
 func (evl *OperationEventLoop) EventReceived(req ctrl.Request, client client.Client) {
 
+	// Once draining, new Operations are not processed by this replica: the Operation controller of the next replica
+	// processes every Operation CR when it starts.
+	if atomic.LoadInt32(&evl.draining) == 1 {
+		return
+	}
+
 	event := operationEventLoopEvent{request: req, client: client}
 	evl.eventLoopInputChannel <- event
 }
 
-func operationEventLoopRouter(input chan operationEventLoopEvent) {
+// Drain is called on shutdown: it stops the event loop from processing new Operations, and waits for the Operations that
+// are being processed to complete (or for the context to be cancelled). Operations that are still In_Progress afterwards
+// should be checkpointed via CheckpointInProgressOperationsOfProcessingOwner.
+func (evl *OperationEventLoop) Drain(ctx context.Context) error {
+
+	atomic.StoreInt32(&evl.draining, 1)
+
+	return evl.taskRetryLoop.Drain(ctx)
+}
+
+func operationEventLoopRouter(input chan operationEventLoopEvent, taskRetryLoop *sharedutil.TaskRetryLoop, processingOwner string) {
 
 	ctx := context.Background()
 
 	log := log.FromContext(ctx).
 		WithName(logutil.LogLogger_managed_gitops)
 
-	taskRetryLoop := sharedutil.NewTaskRetryLoop("cluster-agent")
-
 	log.Info("controllerEventLoopRouter started")
 
 	dbQueries, err := db.NewSharedProductionPostgresDBQueries(false)
@@ -117,6 +143,13 @@ func operationEventLoopRouter(input chan operationEventLoopEvent) {
 				return nil
 			}
 
+			// Let the user know that their change is queued: the state is mirrored again once the Operation is processed
+			if dbOperation.State == db.OperationState_Waiting {
+				if err := mirrorOperationStatus(ctx, *dbOperation, newEvent.request.Namespace, newEvent.client, dbQueries); err != nil {
+					log.Error(err, "unable to mirror Operation state into the namespace of its resource", "operationID", dbOperation.Operation_id)
+				}
+			}
+
 			// If multiple operations exist that target the same Application/SyncOperation, we should only process those
 			// operations one at a time (i.e. non-concurrently)
 			mapKey = dbOperation.Instance_id + "-" + string(dbOperation.Resource_type) + "-" + dbOperation.Resource_id
@@ -138,11 +171,13 @@ func operationEventLoopRouter(input chan operationEventLoopEvent) {
 		task := &processOperationEventTask{
 			event: operationEventLoopEvent{
 				request: newEvent.request,
-				client:  newEvent.client,
+				// Calls to the K8s API server are rate limited, so that a large number of Operations does not overwhelm it
+				client: utils.NewAPICallLimiterClient(newEvent.client, utils.KubernetesAPICallLimiter()),
 			},
 			log:               log,
 			credentialService: credentialService,
 			syncFuncs:         defaultSyncFuncs(),
+			processingOwner:   processingOwner,
 		}
 		taskRetryLoop.AddTaskIfNotPresent(mapKey, task, sharedutil.ExponentialBackoff{Factor: 2, Min: time.Millisecond * 200, Max: time.Second * 10, Jitter: true})
 
@@ -224,6 +259,9 @@ type processOperationEventTask struct {
 	log               logr.Logger
 	credentialService *utils.CredentialService
 	syncFuncs         *syncFuncs
+
+	// processingOwner is the identity of this cluster-agent replica, which is set on Operations while they are In_Progress
+	processingOwner string
 }
 
 // PerformTask takes as input an Operation resource event, and processes it based on the contents of that event.
@@ -256,6 +294,7 @@ func (task *processOperationEventTask) PerformTask(taskContext context.Context)
 		if shouldRetry {
 			// Not complete, still (re)trying.
 			dbOperation.State = db.OperationState_In_Progress
+			dbOperation.Processing_owner = task.processingOwner
 		} else {
 
 			// Complete (but complete doesn't mean successful: it could be complete due to a fatal error)
@@ -265,6 +304,7 @@ func (task *processOperationEventTask) PerformTask(taskContext context.Context)
 				dbOperation.State = db.OperationState_Failed
 			}
 			metrics.IncreaseOperationDBState(dbOperation.State)
+			metrics.ObserveOperationProcessingDuration(dbOperation.Resource_type, dbOperation.State, time.Since(dbOperation.Created_on))
 		}
 		dbOperation.Last_state_update = time.Now()
 
@@ -280,6 +320,11 @@ func (task *processOperationEventTask) PerformTask(taskContext context.Context)
 		}
 
 		task.log.Info("Updated Operation state", "operationID", dbOperation.Operation_id, "new operationState", string(dbOperation.State))
+
+		if err := mirrorOperationStatus(taskContext, *dbOperation, task.event.request.Namespace, task.event.client, dbQueries); err != nil {
+			// The mirror is informational only, so a failure to update it does not cause the Operation to be retried
+			task.log.Error(err, "unable to mirror Operation state into the namespace of its resource", "operationID", dbOperation.Operation_id)
+		}
 	}
 
 	return shouldRetry, err
@@ -311,7 +356,10 @@ func (task *processOperationEventTask) internalPerformTask(taskContext context.C
 		}
 	}
 
-	log = log.WithValues("operationID", operationCR.Spec.OperationID)
+	// Log using the correlation ID that the backend set on the Operation, so that the logs of both components can be correlated.
+	correlationID := logutil.CorrelationIDOfObject(operationCR)
+	taskContext = logutil.ContextWithCorrelationID(taskContext, correlationID)
+	log = log.WithValues("operationID", operationCR.Spec.OperationID, logutil.LogCorrelationIDKey, correlationID)
 
 	// 2) Retrieve the database entry that corresponds to the Operation CR.
 	dbOperation := db.Operation{
@@ -363,6 +411,13 @@ func (task *processOperationEventTask) internalPerformTask(taskContext context.C
 	// If the operation is in waiting state, update it to in-progress before we start processing it.
 	if dbOperation.State == db.OperationState_Waiting {
 		dbOperation.State = db.OperationState_In_Progress
+		dbOperation.Processing_owner = task.processingOwner
+
+		// The operation was checkpointed during the shutdown of the replica that was previously processing it
+		if dbOperation.Resume_token != "" {
+			log.Info("Resuming Operation that was checkpointed on shutdown", "resumeToken", dbOperation.Resume_token)
+			dbOperation.Resume_token = ""
+		}
 
 		if err := dbQueries.UpdateOperation(taskContext, &dbOperation); err != nil {
 			log.Error(err, "Unable to update Operation state")
@@ -464,14 +519,14 @@ func (task *processOperationEventTask) internalPerformTask(taskContext context.C
 
 	} else if dbOperation.Resource_type == db.OperationResourceType_GitOpsEngineInstance {
 
-		// Process a SyncOperation event
+		// Process a GitOpsEngineInstance event
 		shouldRetry, err := processOperation_GitOpsEngineInstance(taskContext, dbOperation, *operationCR, operationConfigParams)
 
 		if err != nil {
 			log.Error(err, "error occurred on processing the gitopsengine instance operation")
 		}
 
-		return nil, shouldRetry, err
+		return &dbOperation, shouldRetry, err
 
 	} else {
 		log.Error(nil, "SEVERE: unrecognized resource type: "+string(dbOperation.Resource_type))
@@ -552,6 +607,16 @@ func processOperation_SyncOperation(ctx context.Context, dbOperation db.Operatio
 
 	// 3) Process the event, based on whether the SyncOperation is requesting an app sync, or a terminate.
 	if dbSyncOperation.DesiredState == db.SyncOperation_DesiredState_Running {
+
+		// Verify that the revision exists in the Git repository before attempting the sync, so that (for example) a
+		// typo in a branch name is reported immediately, rather than as a failed sync.
+		if err := validateSyncOperationRevision(ctx, *dbSyncOperation, dbApplication, opConfig); err != nil {
+			if errors.Is(err, utils.ErrRevisionNotFound) {
+				return shouldRetryFalse, err
+			}
+			return shouldRetryTrue, err
+		}
+
 		// refresh the Application before syncing to make sure that the latest revision is deployed.
 		if err := opConfig.syncFuncs.refreshApp(ctx, opConfig.eventClient, dbApplication.Name, opConfig.argoCDNamespace.Name); err != nil {
 			return shouldRetryTrue, err
@@ -569,6 +634,40 @@ func processOperation_SyncOperation(ctx context.Context, dbOperation db.Operatio
 	}
 }
 
+// validateSyncOperationRevision returns an error wrapping utils.ErrRevisionNotFound if the revision of the SyncOperation
+// (or, if not specified, the target revision of the Application) does not exist in the Git repository of the Application.
+func validateSyncOperationRevision(ctx context.Context, dbSyncOperation db.SyncOperation, dbApplication db.Application,
+	opConfig operationConfig) error {
+
+	if opConfig.syncFuncs.resolveRevision == nil {
+		return nil
+	}
+
+	specFieldApp := &appv1.Application{}
+	if err := yaml.Unmarshal([]byte(dbApplication.Spec_field), specFieldApp); err != nil {
+		// The spec field is validated when the Application is created/updated, so skip validation of the revision.
+		opConfig.log.Error(err, "unable to unmarshal application spec field, on validating SyncOperation revision")
+		return nil
+	}
+
+	// Helm chart repositories are not Git repositories, so their revisions cannot be resolved.
+	if specFieldApp.Spec.Source.Chart != "" {
+		return nil
+	}
+
+	revision := dbSyncOperation.Revision
+	if revision == "" {
+		revision = specFieldApp.Spec.Source.TargetRevision
+	}
+
+	if _, err := opConfig.syncFuncs.resolveRevision(ctx, opConfig.eventClient, opConfig.argoCDNamespace.Name,
+		specFieldApp.Spec.Source.RepoURL, revision); err != nil {
+		return fmt.Errorf("unable to resolve revision of SyncOperation in repository '%s': %w", specFieldApp.Spec.Source.RepoURL, err)
+	}
+
+	return nil
+}
+
 func refreshApplication(ctx context.Context, k8sClient client.Client, appName, appNS string) error {
 	appCR := &appv1.Application{
 		ObjectMeta: metav1.ObjectMeta{
@@ -672,18 +771,78 @@ func isOperationRunning(ctx context.Context, k8sClient client.Client, appName, a
 
 // syncFuncs is a wrapper over sync and terminate functions and is used in unit testing different sync scenarios
 type syncFuncs struct {
-	appSync            func(context.Context, string, string, string, client.Client, *utils.CredentialService, bool) error
+	appSync            func(context.Context, string, string, string, client.Client, *utils.CredentialService, bool, string, []appv1.SyncOperationResource, *appv1.RetryStrategy) error
 	terminateOperation func(context.Context, string, corev1.Namespace, *utils.CredentialService, client.Client, time.Duration, logr.Logger) error
 
 	refreshApp func(context.Context, client.Client, string, string) error
+
+	// resolveRevision resolves the revision of a Git repository to a commit SHA (see utils.RevisionResolver). If nil,
+	// the revision of a SyncOperation is not validated before syncing.
+	resolveRevision func(context.Context, client.Client, string, string, string) (string, error)
 }
 
+// defaultRevisionResolver is shared between operations, so that the refs of Git repositories are cached between syncs.
+var defaultRevisionResolver = utils.NewRevisionResolver()
+
 func defaultSyncFuncs() *syncFuncs {
 	return &syncFuncs{
 		appSync:            utils.AppSync,
 		terminateOperation: utils.TerminateOperation,
 		refreshApp:         refreshApplication,
+		resolveRevision:    defaultRevisionResolver.ResolveRevision,
+	}
+}
+
+// getSyncOperationResources returns the resources that should be synced by the SyncOperation, or nil if all the resources
+// of the Application should be synced.
+func getSyncOperationResources(dbSyncOperation db.SyncOperation) ([]appv1.SyncOperationResource, error) {
+
+	if dbSyncOperation.Resources == "" {
+		return nil, nil
 	}
+
+	var fauxResources []fauxargocd.FauxSyncOperationResource
+	if err := json.Unmarshal([]byte(dbSyncOperation.Resources), &fauxResources); err != nil {
+		return nil, err
+	}
+
+	var res []appv1.SyncOperationResource
+	for _, fauxResource := range fauxResources {
+		res = append(res, appv1.SyncOperationResource{
+			Group:     fauxResource.Group,
+			Kind:      fauxResource.Kind,
+			Name:      fauxResource.Name,
+			Namespace: fauxResource.Namespace,
+		})
+	}
+
+	return res, nil
+}
+
+// getSyncOperationRetryStrategy returns the Argo CD retry strategy of the SyncOperation, or nil if the sync should not be
+// retried. Backoff fields that are not set in the SyncOperation are left empty, so that the Argo CD defaults are used.
+func getSyncOperationRetryStrategy(dbSyncOperation db.SyncOperation) *appv1.RetryStrategy {
+
+	if dbSyncOperation.RetryLimit <= 0 {
+		return nil
+	}
+
+	res := &appv1.RetryStrategy{
+		Limit: dbSyncOperation.RetryLimit,
+	}
+
+	if dbSyncOperation.RetryBackoffDuration != "" || dbSyncOperation.RetryBackoffFactor > 0 || dbSyncOperation.RetryBackoffMaxDuration != "" {
+		res.Backoff = &appv1.Backoff{
+			Duration:    dbSyncOperation.RetryBackoffDuration,
+			MaxDuration: dbSyncOperation.RetryBackoffMaxDuration,
+		}
+		if dbSyncOperation.RetryBackoffFactor > 0 {
+			factor := dbSyncOperation.RetryBackoffFactor
+			res.Backoff.Factor = &factor
+		}
+	}
+
+	return res
 }
 
 // returns shouldRetry, error
@@ -692,9 +851,14 @@ func runAppSync(ctx context.Context, dbOperation db.Operation, dbSyncOperation d
 
 	log := opConfig.log
 
-	completeChan := make(chan bool)
+	syncResources, err := getSyncOperationResources(dbSyncOperation)
+	if err != nil {
+		// The resources field will not change for an existing SyncOperation, so there is no point in retrying.
+		log.Error(err, "unable to unmarshal resources field of SyncOperation", "syncOperationID", dbSyncOperation.SyncOperation_id)
+		return shouldRetryFalse, err
+	}
 
-	var err error
+	completeChan := make(chan bool)
 
 	cancellableCtx, cancelFunc := context.WithCancel(ctx)
 
@@ -703,7 +867,7 @@ func runAppSync(ctx context.Context, dbOperation db.Operation, dbSyncOperation d
 	// Start the AppSync operation in a separate thread.
 	go func() {
 		err = opConfig.syncFuncs.appSync(cancellableCtx, dbApplication.Name, dbSyncOperation.Revision, opConfig.argoCDNamespace.Name, opConfig.eventClient,
-			opConfig.credentialService, false)
+			opConfig.credentialService, false, dbSyncOperation.Initiator, syncResources, getSyncOperationRetryStrategy(dbSyncOperation))
 
 		var failed bool
 		if err != nil {
@@ -765,10 +929,13 @@ outer:
 func processOperation_ManagedEnvironment(ctx context.Context, dbOperation db.Operation, crOperation operation.Operation,
 	opConfig operationConfig) (bool, error) {
 
-	// The only operation we currently support for managed environment is deletion (creation is handled by Application operations).
-	// Thus, we expect the ManagedEnvironment database entry here to not be found.
+	// The operations we currently support for managed environment are (creation is handled by Application operations):
+	// - deletion and disconnection: both remove the Argo CD cluster secret of the managed environment, in which case the
+	//   ManagedEnvironment database entry is either not found, or is disconnected.
+	// - credential rotation: the Argo CD cluster secret is updated to the current cluster credentials of the managed
+	//   environment, in which case the ManagedEnvironment database entry exists, and is connected.
 
-	// 1) Make sure the managed environment db entry DOESN'T exist (see above)
+	// 1) If the managed environment db entry exists, and is connected, then update its Argo CD cluster secret (see above)
 	{
 		managedEnv := &db.ManagedEnvironment{
 			Managedenvironment_id: dbOperation.Resource_id, // managed env id referencing managed env row
@@ -777,16 +944,22 @@ func processOperation_ManagedEnvironment(ctx context.Context, dbOperation db.Ope
 			if !db.IsResultNotFoundError(err) {
 				return shouldRetryTrue, fmt.Errorf("an unexpected error occcurred on retrieving managed env: %v", err)
 			}
-		} else {
-			// The database entry still exists, so return an error
-			return shouldRetryFalse, fmt.Errorf("managed environment still exists in the database")
+		} else if !managedEnv.Disconnected {
+			// The Argo CD cluster secret is generated from the managed environment, in the same way as for an Application
+			// that targets it: the secret is updated in a single write, without modifying the Applications.
+			if err := ensureManagedEnvironmentExists(ctx, db.Application{Managed_environment_id: managedEnv.Managedenvironment_id}, opConfig); err != nil {
+				return shouldRetryTrue, fmt.Errorf("unable to update Argo CD cluster secret of managed environment: %v", err)
+			}
+			return shouldRetryFalse, nil
 		}
 	}
 
 	// 2) Delete the Argo CD cluster secret corresponding to the managed environment.
 	// - the cluster secret has a specific name format, so it is easy to locate by the name.
+	// - the Argo CD Applications that target the managed environment are not modified: Argo CD will report them as
+	//   unable to connect to the cluster, but the resources they deployed are not deleted.
 
-	// We have confirmed the database entry doesn't exist, now locate the Argo CD Cluster Secret that
+	// We have confirmed the database entry doesn't exist (or is disconnected), now locate the Argo CD Cluster Secret that
 	// corresponds to the managed environment.
 	expectedSecretName := argosharedutil.GenerateArgoCDClusterSecretName(db.ManagedEnvironment{Managedenvironment_id: dbOperation.Resource_id})
 
@@ -843,6 +1016,12 @@ func processOperation_Application(ctx context.Context, dbOperation db.Operation,
 		}
 	}
 
+	if dbApplication.Engine_instance_inst_id != dbOperation.Instance_id {
+		// The Application has been migrated to another GitOpsEngineInstance (see the namespace migration of the
+		// backend), so delete the Argo CD Application from the instance of the Operation.
+		return deleteArgoCDApplicationOfMigratedApplicationRow(ctx, dbApplication.Application_id, opConfig, log)
+	}
+
 	log = log.WithValues("argoCDApplicationName", dbApplication.Name)
 
 	app := &appv1.Application{
@@ -874,6 +1053,17 @@ func processOperation_Application(ctx context.Context, dbOperation db.Operation,
 			// Add databaseID label
 			app.ObjectMeta.Labels = map[string]string{controllers.ArgoCDApplicationDatabaseIDLabel: dbApplication.Application_id}
 
+			// Add the managed annotations (Argo CD Notifications subscriptions and sync wave)
+			managedAnnotations, err := controllers.GetNotificationAnnotationsFromSpecField(dbApplication.Spec_field)
+			if err != nil {
+				log.Error(err, "SEVERE: unable to read managed annotations from application spec field on creating Application CR.")
+				return shouldRetryFalse, nil
+			}
+			controllers.ReconcileNotificationAnnotations(app, managedAnnotations)
+
+			// The Application controller logs using the correlation ID of the Operation that created/updated the Application
+			logutil.SetCorrelationIDAnnotation(app, logutil.CorrelationIDFromContext(ctx))
+
 			// Before we create the application, make sure that the managed environment exists that the application points to
 			if app.Spec.Destination.Name != argosharedutil.ArgoCDDefaultDestinationInCluster {
 				if err := ensureManagedEnvironmentExists(ctx, *dbApplication, opConfig); err != nil {
@@ -889,6 +1079,21 @@ func processOperation_Application(ctx context.Context, dbOperation db.Operation,
 			}
 			logutil.LogAPIResourceChangeEvent(app.Namespace, app.Name, app, logutil.ResourceCreated, log)
 
+			// Add the resource health checks of the Application to the Argo CD instance: failure to do so does not block the
+			// deployment, and is retried by the Application self-heal loop
+			if _, exists := app.Annotations[argosharedutil.ResourceHealthChecksAnnotation]; exists {
+				if err := controllers.ReconcileResourceHealthChecks(ctx, opConfig.eventClient, app.Namespace, log); err != nil {
+					log.Error(err, "unable to reconcile resource health checks of Argo CD instance")
+				}
+			}
+
+			// Optionally create a ResourceQuota in the destination namespace: failure to do so does not block the deployment
+			if app.Spec.Destination.Name != argosharedutil.ArgoCDDefaultDestinationInCluster {
+				if err := reconcileNamespaceResourceQuota(ctx, *dbApplication, app.Spec.Destination.Namespace, opConfig); err != nil {
+					log.Error(err, "unable to reconcile ResourceQuota of destination namespace")
+				}
+			}
+
 			// Success
 			return shouldRetryFalse, nil
 
@@ -905,12 +1110,24 @@ func processOperation_Application(ctx context.Context, dbOperation db.Operation,
 
 	// Before we create the application, make sure that the managed environment that the application points to exists
 
+	previousHealthChecks := app.Annotations[argosharedutil.ResourceHealthChecksAnnotation]
+
 	specDiff, err := controllers.CompareApplication(*app, *dbApplication, log)
 	if err != nil {
 		log.Error(err, "unable to compare Argo CD Application with DB row")
 		return shouldRetryFalse, err
 	}
 
+	managedAnnotations, err := controllers.GetNotificationAnnotationsFromSpecField(dbApplication.Spec_field)
+	if err != nil {
+		log.Error(err, "SEVERE: unable to read managed annotations from DB application spec field, on updating existing Application CR: "+app.Name)
+		return shouldRetryFalse, nil
+	}
+
+	if specDiff == "" && controllers.ReconcileNotificationAnnotations(app, managedAnnotations) {
+		specDiff = "managed annotations differ"
+	}
+
 	if specDiff != "" {
 		specFieldApp := &appv1.Application{}
 
@@ -925,6 +1142,8 @@ func processOperation_Application(ctx context.Context, dbOperation db.Operation,
 		app.Spec.Source = specFieldApp.Spec.Source
 		app.Spec.Project = specFieldApp.Spec.Project
 		app.Spec.SyncPolicy = specFieldApp.Spec.SyncPolicy
+		controllers.ReconcileNotificationAnnotations(app, managedAnnotations)
+		logutil.SetCorrelationIDAnnotation(app, logutil.CorrelationIDFromContext(ctx))
 
 		if err := opConfig.eventClient.Update(ctx, app); err != nil {
 			log.Error(err, "unable to update application after difference detected.")
@@ -935,6 +1154,14 @@ func processOperation_Application(ctx context.Context, dbOperation db.Operation,
 
 		log.Info("Updated Argo CD Application CR", "specDiff", specDiff)
 
+		// Update the resource health checks of the Argo CD instance, if those of the Application changed: failure to do so
+		// does not block the deployment, and is retried by the Application self-heal loop
+		if app.Annotations[argosharedutil.ResourceHealthChecksAnnotation] != previousHealthChecks {
+			if err := controllers.ReconcileResourceHealthChecks(ctx, opConfig.eventClient, app.Namespace, log); err != nil {
+				log.Error(err, "unable to reconcile resource health checks of Argo CD instance")
+			}
+		}
+
 	} else {
 		log.Info("no changes detected in application, so no update needed")
 	}
@@ -945,6 +1172,11 @@ func processOperation_Application(ctx context.Context, dbOperation db.Operation,
 			log.Error(err, "unable to ensure that managed environment exists")
 			return shouldRetryTrue, err
 		}
+
+		// Optionally ensure the ResourceQuota of the destination namespace is up to date: failure to do so does not block the deployment
+		if err := reconcileNamespaceResourceQuota(ctx, *dbApplication, app.Spec.Destination.Namespace, opConfig); err != nil {
+			log.Error(err, "unable to reconcile ResourceQuota of destination namespace")
+		}
 	}
 
 	return shouldRetryFalse, nil
@@ -1001,6 +1233,50 @@ func deleteArgoCDApplicationOfDeletedApplicationRow(ctx context.Context, dbAppli
 	return shouldRetryFalse, nil
 }
 
+// deleteArgoCDApplicationOfMigratedApplicationRow deletes the Argo CD Application of an Application row that has been
+// migrated to another GitOpsEngineInstance. The resources deployed by the Argo CD Application are now managed by the
+// Argo CD Application of the other instance, so they are orphaned, rather than deleted.
+func deleteArgoCDApplicationOfMigratedApplicationRow(ctx context.Context, dbApplicationID string, opConfig operationConfig, log logr.Logger) (bool, error) {
+
+	list := appv1.ApplicationList{}
+	if err := opConfig.eventClient.List(ctx, &list, &client.ListOptions{
+		Namespace:     opConfig.argoCDNamespace.Name,
+		LabelSelector: labels.SelectorFromSet(labels.Set{controllers.ArgoCDApplicationDatabaseIDLabel: dbApplicationID}),
+	}); err != nil {
+		log.Error(err, "unable to complete Argo CD Application list")
+		return shouldRetryTrue, err
+	}
+
+	for i := range list.Items {
+		app := &list.Items[i]
+
+		if app.DeletionTimestamp != nil || app.Annotations[argosharedutil.DeletionPolicyAnnotation] == string(operation.GitOpsDeploymentDeletionPolicy_Orphan) {
+			continue
+		}
+
+		if app.Annotations == nil {
+			app.Annotations = map[string]string{}
+		}
+		app.Annotations[argosharedutil.DeletionPolicyAnnotation] = string(operation.GitOpsDeploymentDeletionPolicy_Orphan)
+
+		if err := opConfig.eventClient.Update(ctx, app); err != nil {
+			log.Error(err, "unable to set orphan deletion policy on Argo CD Application of migrated Application")
+			return shouldRetryTrue, err
+		}
+		logutil.LogAPIResourceChangeEvent(app.Namespace, app.Name, app, logutil.ResourceModified, log)
+	}
+
+	// DeleteArgoCDApplication removes the resources finalizer, based on the (orphan) deletion policy annotation
+	return deleteArgoCDApplicationOfDeletedApplicationRow(ctx, dbApplicationID, opConfig, log)
+}
+
+// argoCDInstanceReadyTimeout is the maximum amount of time, from the creation of a GitOpsEngineInstance Operation, for the
+// Argo CD instance to be installed by the OpenShift GitOps operator, before the Operation is failed.
+const argoCDInstanceReadyTimeout = 15 * time.Minute
+
+// processOperation_GitOpsEngineInstance provisions the Argo CD instance of the GitopsEngineInstance targeted by the Operation,
+// by creating (or updating) an ArgoCD CR in the namespace of the instance. The ArgoCD CR is installed by the OpenShift
+// GitOps operator: until Argo CD has started, the Operation remains In_Progress, and is retried.
 func processOperation_GitOpsEngineInstance(ctx context.Context, dbOperation db.Operation, crOperation operation.Operation, opConfig operationConfig) (bool, error) {
 
 	if dbOperation.Resource_id == "" {
@@ -1013,22 +1289,37 @@ func processOperation_GitOpsEngineInstance(ctx context.Context, dbOperation db.O
 
 	log := opConfig.log.WithValues("Gitopsengineinstance_id", dbGitopsEngineInstance.Gitopsengineinstance_id)
 
-	err := opConfig.dbQueries.GetGitopsEngineInstanceById(ctx, dbGitopsEngineInstance)
-	if err != nil {
+	if err := opConfig.dbQueries.GetGitopsEngineInstanceById(ctx, dbGitopsEngineInstance); err != nil {
 		log.Error(err, "Unable to retrieve database GitopsEngineInstance row from database")
 		return shouldRetryTrue, err
-	} else {
-		// The ArgoCD CR name is given the name of the namespace it is being created in
-		err := utils.ReconcileNamespaceScopedArgoCD(ctx, dbGitopsEngineInstance.Namespace_name, dbGitopsEngineInstance.Namespace_name, opConfig.eventClient, log)
-		if err != nil {
-			log.Error(err, "Unable to create namespace scoped ArgoCD for GitopsEngineInstance")
-			return shouldRetryTrue, err
+	}
+
+	// The ArgoCD CR name is given the name of the namespace it is being created in
+	if err := utils.CreateOrUpdateNamespaceScopedArgoCD(ctx, dbGitopsEngineInstance.Namespace_name, dbGitopsEngineInstance.Namespace_name,
+		opConfig.eventClient, log); err != nil {
+		log.Error(err, "Unable to create namespace scoped ArgoCD for GitopsEngineInstance")
+		return shouldRetryTrue, err
+	}
+
+	ready, err := utils.IsNamespaceScopedArgoCDReady(ctx, dbGitopsEngineInstance.Namespace_name, opConfig.eventClient)
+	if err != nil {
+		log.Error(err, "Unable to determine whether the Argo CD instance of GitopsEngineInstance is ready")
+		return shouldRetryTrue, err
+	}
+
+	if !ready {
+		if time.Since(dbOperation.Created_on) > argoCDInstanceReadyTimeout {
+			return shouldRetryFalse, fmt.Errorf("argo CD instance in namespace '%s' was not ready within %s",
+				dbGitopsEngineInstance.Namespace_name, argoCDInstanceReadyTimeout.String())
 		}
 
+		log.V(logutil.LogLevel_Debug).Info("Waiting for Argo CD instance to be installed by the OpenShift GitOps operator")
+		return shouldRetryTrue, nil
 	}
 
-	return shouldRetryFalse, nil
+	log.Info("Argo CD instance of GitopsEngineInstance is ready")
 
+	return shouldRetryFalse, nil
 }
 
 const (
@@ -1053,7 +1344,7 @@ func ensureManagedEnvironmentExists(ctx context.Context, application db.Applicat
 
 	log := opConfig.log.WithValues("expectedSecretName", expectedSecret.Name, "expectedSecretNamespace", expectedSecret.Namespace)
 
-	// If we detected that the managed environment row was deleted, ensure the secret is deleted.
+	// If we detected that the managed environment row was deleted (or disconnected), ensure the secret is deleted.
 	if shouldDeleteSecret {
 		secretName := argosharedutil.GenerateArgoCDClusterSecretName(db.ManagedEnvironment{Managedenvironment_id: application.Managed_environment_id})
 		secret := &corev1.Secret{
@@ -1122,6 +1413,40 @@ func ensureManagedEnvironmentExists(ctx context.Context, application db.Applicat
 
 }
 
+// getRotatedClusterCredentials reads the managed environment again, and returns its cluster credentials if they have
+// changed since 'managedEnv' was read (that is, if they were rotated), or nil if they have not changed (or no longer exist).
+func getRotatedClusterCredentials(ctx context.Context, managedEnv db.ManagedEnvironment, opConfig operationConfig) (*db.ClusterCredentials, error) {
+
+	currentManagedEnv := &db.ManagedEnvironment{
+		Managedenvironment_id: managedEnv.Managedenvironment_id,
+	}
+	if err := opConfig.dbQueries.GetManagedEnvironmentById(ctx, currentManagedEnv); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get managed environment '%s': %v", managedEnv.Managedenvironment_id, err)
+	}
+
+	if currentManagedEnv.Disconnected || currentManagedEnv.Clustercredentials_id == managedEnv.Clustercredentials_id {
+		return nil, nil
+	}
+
+	clusterCredentials := &db.ClusterCredentials{
+		Clustercredentials_cred_id: currentManagedEnv.Clustercredentials_id,
+	}
+	if err := opConfig.dbQueries.GetClusterCredentialsById(ctx, clusterCredentials); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get cluster credentials '%s': %v", clusterCredentials.Clustercredentials_cred_id, err)
+	}
+
+	opConfig.log.Info("Cluster credentials of managed environment were rotated while processing the Operation, so using the new credentials",
+		"managedEnvironmentID", managedEnv.Managedenvironment_id)
+
+	return clusterCredentials, nil
+}
+
 // generateExpectedClusterSecret generates (but does apply) an Argo CD cluster secret for the environment of the application.
 // returns:
 // - argo cd cluster secret based on managed environment
@@ -1149,19 +1474,35 @@ func generateExpectedClusterSecret(ctx context.Context, application db.Applicati
 		}
 	}
 
+	if managedEnv.Disconnected {
+		// The managed environment has been disconnected by the user, so Argo CD should no longer be able to connect to it.
+		// Return true to indicate that the managed environment cluster secret should be deleted.
+		return corev1.Secret{}, deleteSecret_true, nil
+	}
+
 	clusterCredentials := &db.ClusterCredentials{
 		Clustercredentials_cred_id: managedEnv.Clustercredentials_id,
 	}
 
 	if err := opConfig.dbQueries.GetClusterCredentialsById(ctx, clusterCredentials); err != nil {
-		if db.IsResultNotFoundError(err) {
+		if !db.IsResultNotFoundError(err) {
+			return corev1.Secret{}, deleteSecret_false,
+				fmt.Errorf("unable to get cluster credentials '%s': %v", clusterCredentials.Clustercredentials_cred_id, err)
+		}
+
+		// The cluster credentials may have been rotated by the backend since the managed environment was read: the old
+		// credentials are deleted once the managed environment points to the new ones. In this case, the cluster secret
+		// must be updated (not deleted), so read the managed environment again, to get the new credentials.
+		rotated, err := getRotatedClusterCredentials(ctx, *managedEnv, opConfig)
+		if err != nil {
+			return corev1.Secret{}, deleteSecret_false, err
+		}
+		if rotated == nil {
 			// Managed environment refers to cluster credentials which no longer exist: no more work to do.
 			// Return true to indicate that the managed environment cluster secret should be deleted.
 			return corev1.Secret{}, deleteSecret_true, nil
-		} else {
-			return corev1.Secret{}, deleteSecret_false,
-				fmt.Errorf("unable to get cluster credentials '%s': %v", clusterCredentials.Clustercredentials_cred_id, err)
 		}
+		clusterCredentials = rotated
 	}
 
 	if strings.Contains(clusterCredentials.Host, "?") || strings.Contains(clusterCredentials.Host, "&") {
@@ -1179,6 +1520,7 @@ func generateExpectedClusterSecret(ctx context.Context, application db.Applicati
 		TLSClientConfig: argosharedutil.ClusterSecretTLSClientConfigJSON{
 			Insecure: insecureVerifyTLS,
 		},
+		ProxyUrl: clusterCredentials.Proxy_url,
 	}
 
 	jsonString, err := json.Marshal(clusterSecretConfigJSON)
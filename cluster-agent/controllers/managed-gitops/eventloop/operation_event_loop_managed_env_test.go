@@ -95,10 +95,12 @@ var _ = Describe("Managed Environment Operation Tests", func() {
 
 		})
 
-		It("reconciles an operation that points to a managed environment that still exists, to ensure no action is taken in this case", func() {
+		It("reconciles an operation that points to a managed environment that still exists, to ensure its Argo CD cluster secret is updated to the current credentials", func() {
 
 			clusterCredentials := db.ClusterCredentials{
-				Clustercredentials_cred_id: string(uuid.NewUUID()),
+				Clustercredentials_cred_id:  string(uuid.NewUUID()),
+				Host:                        "https://api.fake-cluster.com:6443",
+				Serviceaccount_bearer_token: "rotated-token",
 			}
 
 			err = dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)
@@ -139,7 +141,7 @@ var _ = Describe("Managed Environment Operation Tests", func() {
 			err = task.event.client.Create(ctx, operationCR)
 			Expect(err).To(BeNil())
 
-			By("creating an Argo CD Cluster secret, which we will will test to make sure it has not been deleted.")
+			By("creating an Argo CD Cluster secret with the old credentials, which we will test to make sure it is updated, not deleted.")
 			clusterSecretName := argosharedutil.GenerateArgoCDClusterSecretName(db.ManagedEnvironment{Managedenvironment_id: managedEnvRow.Managedenvironment_id})
 			secret := &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
@@ -150,21 +152,79 @@ var _ = Describe("Managed Environment Operation Tests", func() {
 						controllers.ArgoCDClusterSecretDatabaseIDLabel: managedEnvRow.Managedenvironment_id,
 					},
 				},
-				Data: map[string][]byte{},
+				Data: map[string][]byte{
+					"config": ([]byte)(`{"bearerToken":"old-token"}`),
+				},
 			}
 
 			err = task.event.client.Create(ctx, secret)
 			Expect(err).To(BeNil())
 
 			retry, err := task.PerformTask(ctx)
-			Expect(err).ToNot(BeNil(), "an error is expected here, because an operation on a managedenvironment is only supported if the managed environment doesn't exist")
+			Expect(err).To(BeNil())
 			Expect(retry).To(BeFalse())
 
+			err = expectOperationIsComplete(ctx, operationDB.Operation_id, dbQueries)
+			Expect(err).To(BeNil())
+
 			err = task.event.client.Get(ctx, client.ObjectKeyFromObject(secret), secret)
 			Expect(err).To(BeNil(), "the Argo CD cluster secret should not have been deleted.")
+			Expect(string(secret.Data["config"])).To(ContainSubstring("rotated-token"))
+			Expect(string(secret.Data["server"])).To(HavePrefix(clusterCredentials.Host))
 
 		})
 
+		It("generates the Argo CD cluster secret from the new credentials, when the credentials were rotated after the managed environment was read", func() {
+			defer dbQueries.CloseDatabase()
+
+			oldClusterCredentials := db.ClusterCredentials{
+				Clustercredentials_cred_id: string(uuid.NewUUID()),
+			}
+			err = dbQueries.CreateClusterCredentials(ctx, &oldClusterCredentials)
+			Expect(err).To(BeNil())
+
+			managedEnvRow := db.ManagedEnvironment{
+				Managedenvironment_id: "test-fake-managed-env",
+				Clustercredentials_id: oldClusterCredentials.Clustercredentials_cred_id,
+				Name:                  "my-managed-env",
+			}
+			err = dbQueries.CreateManagedEnvironment(ctx, &managedEnvRow)
+			Expect(err).To(BeNil())
+			staleManagedEnvRow := managedEnvRow
+
+			By("simulating a rotation of the credentials by the backend: the managed env is updated, then the old credentials are deleted")
+			newClusterCredentials := db.ClusterCredentials{
+				Clustercredentials_cred_id:  string(uuid.NewUUID()),
+				Host:                        "https://api.fake-cluster.com:6443",
+				Serviceaccount_bearer_token: "rotated-token",
+			}
+			err = dbQueries.CreateClusterCredentials(ctx, &newClusterCredentials)
+			Expect(err).To(BeNil())
+
+			managedEnvRow.Clustercredentials_id = newClusterCredentials.Clustercredentials_cred_id
+			err = dbQueries.UpdateManagedEnvironment(ctx, &managedEnvRow)
+			Expect(err).To(BeNil())
+
+			_, err = dbQueries.DeleteClusterCredentialsById(ctx, oldClusterCredentials.Clustercredentials_cred_id)
+			Expect(err).To(BeNil())
+
+			rotated, err := getRotatedClusterCredentials(ctx, staleManagedEnvRow, operationConfig{
+				dbQueries: dbQueries,
+				log:       logger,
+			})
+			Expect(err).To(BeNil())
+			Expect(rotated).ToNot(BeNil())
+			Expect(rotated.Serviceaccount_bearer_token).To(Equal("rotated-token"))
+
+			By("ensuring nil is returned if the credentials were not rotated")
+			rotated, err = getRotatedClusterCredentials(ctx, managedEnvRow, operationConfig{
+				dbQueries: dbQueries,
+				log:       logger,
+			})
+			Expect(err).To(BeNil())
+			Expect(rotated).To(BeNil())
+		})
+
 		It("Reconciling a deleted managed environment, to ensure the corresponding Argo CD cluster secret is deleted", func() {
 			defer dbQueries.CloseDatabase()
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -199,6 +200,70 @@ var _ = Describe("Operation Controller", func() {
 
 		})
 
+		It("EnsureManagedEnvironment should delete the Secret of a ManagedEnvironment, if the ManagedEnvironment is disconnected", func() {
+
+			clusterCredentials := db.ClusterCredentials{
+				Clustercredentials_cred_id:  "test-cluster-creds-test",
+				Host:                        "https://my-cluster-url.com",
+				Kube_config:                 "kube-config",
+				Kube_config_context:         "kube-config-context",
+				Serviceaccount_bearer_token: "serviceaccount_bearer_token",
+				Serviceaccount_ns:           "Serviceaccount_ns",
+			}
+			err := dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)
+			Expect(err).To(BeNil())
+
+			managedEnvironment := db.ManagedEnvironment{
+				Managedenvironment_id: "test-managed-env",
+				Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+				Name:                  "my env",
+			}
+			err = dbQueries.CreateManagedEnvironment(ctx, &managedEnvironment)
+			Expect(err).To(BeNil())
+
+			applicationDB := &db.Application{
+				Application_id:          "test-my-application",
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbQueries.CreateApplication(ctx, applicationDB)
+			Expect(err).To(BeNil())
+
+			By("creating the Secret of the connected ManagedEnvironment")
+			err = ensureManagedEnvironmentExists(ctx, *applicationDB, opConfigVal)
+			Expect(err).To(BeNil())
+
+			managedEnvironmentSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      argosharedutil.GenerateArgoCDClusterSecretName(managedEnvironment),
+					Namespace: argoCDNamespace.Name,
+				},
+			}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvironmentSecret), &managedEnvironmentSecret)
+			Expect(err).To(BeNil())
+
+			By("disconnecting the ManagedEnvironment")
+			managedEnvironment.Disconnected = true
+			err = dbQueries.UpdateManagedEnvironment(ctx, &managedEnvironment)
+			Expect(err).To(BeNil())
+
+			_, shouldDelete, err := generateExpectedClusterSecret(ctx, *applicationDB, opConfigVal)
+			Expect(err).To(BeNil())
+			Expect(shouldDelete).To(BeTrue())
+
+			err = ensureManagedEnvironmentExists(ctx, *applicationDB, opConfigVal)
+			Expect(err).To(BeNil())
+
+			By("verifying the Secret was deleted, but the Application still exists")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvironmentSecret), &managedEnvironmentSecret)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			err = dbQueries.GetApplicationById(ctx, applicationDB)
+			Expect(err).To(BeNil())
+		})
+
 		It("EnsureManagedEnvironment should create a Secret for ManagedEnvironment, if the Secret doesn't exist", func() {
 
 			clusterCredentials := db.ClusterCredentials{
@@ -357,6 +422,53 @@ var _ = Describe("Operation Controller", func() {
 			Expect(apierr.IsNotFound(err)).To(BeTrue())
 
 		})
+
+		It("processOperation_Application should delete the Argo CD Application, orphaning its resources, if the Application was migrated to another GitOpsEngineInstance", func() {
+
+			Expect(appv1.AddToScheme(scheme)).To(Succeed())
+
+			By("creating an Application row that has been migrated to another GitOpsEngineInstance")
+			targetGitopsEngineInstance := &db.GitopsEngineInstance{
+				Gitopsengineinstance_id: "test-fake-target-engine-instance",
+				Namespace_name:          "gitops-service-argocd-2",
+				Namespace_uid:           "test-fake-target-namespace-uid",
+				EngineCluster_id:        gitopsEngineInstance.EngineCluster_id,
+			}
+			Expect(dbQueries.CreateGitopsEngineInstance(ctx, targetGitopsEngineInstance)).To(Succeed())
+
+			applicationDB := &db.Application{
+				Application_id:          "test-my-migrated-application",
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: targetGitopsEngineInstance.Gitopsengineinstance_id,
+			}
+			Expect(dbQueries.CreateApplication(ctx, applicationDB)).To(Succeed())
+
+			By("creating the Argo CD Application of the Application on the source GitOpsEngineInstance")
+			argoCDApplication := &appv1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       name,
+					Namespace:  argoCDNamespace.Name,
+					Labels:     map[string]string{dbID: applicationDB.Application_id},
+					Finalizers: []string{"resources-finalizer.argocd.argoproj.io"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, argoCDApplication)).To(Succeed())
+
+			dbOperation := db.Operation{
+				Instance_id:   gitopsEngineInstance.Gitopsengineinstance_id,
+				Resource_id:   applicationDB.Application_id,
+				Resource_type: db.OperationResourceType_Application,
+			}
+
+			retry, err := processOperation_Application(ctx, dbOperation, managedgitopsv1alpha1.Operation{}, opConfigVal)
+			Expect(err).To(BeNil())
+			Expect(retry).To(BeFalse())
+
+			By("verifying the Argo CD Application was deleted from the source GitOpsEngineInstance")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(argoCDApplication), argoCDApplication)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+		})
 	})
 
 	Context("Operation Controller Test", func() {
@@ -549,50 +661,100 @@ var _ = Describe("Operation Controller", func() {
 			err = task.event.client.Create(ctx, operationCR)
 			Expect(err).To(BeNil())
 
-			// Wait for cluster agent to create the ArgoCD operand. Since Argo CD is not actually running,
-			// we simulate Argo CD creating the 'default' AppProject
-			go func() {
-				defer GinkgoRecover() // Allow Ginkgo to catch the Expects
+			By("processing the Operation, which should create the ArgoCD CR, and wait for Argo CD to start")
+			retry, err := task.PerformTask(ctx)
+			Expect(err).To(BeNil())
+			Expect(retry).To(BeTrue())
 
-			outer_for:
-				for {
+			argoCD := &argocdoperatorv1alph1.ArgoCD{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      newArgoCDNamespace.Name,
+					Namespace: newArgoCDNamespace.Name,
+				},
+			}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(argoCD), argoCD)).To(Succeed())
 
-					var argoCDList argocdoperatorv1alph1.ArgoCDList
+			err = dbQueries.GetOperationById(ctx, operationDB)
+			Expect(err).To(BeNil())
+			Expect(operationDB.State).To(Equal(db.OperationState_In_Progress))
 
-					err := k8sClient.List(context.Background(), &argoCDList)
-					Expect(err).To(BeNil())
+			By("simulating Argo CD creating the default AppProject, once it has started")
+			appProject := appv1.AppProject{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default",
+					Namespace: newArgoCDNamespace.Name,
+				},
+				Spec: appv1.AppProjectSpec{},
+			}
+			Expect(k8sClient.Create(ctx, &appProject)).To(Succeed())
 
-					for _, argoCDItem := range argoCDList.Items {
+			retry, err = task.PerformTask(ctx)
+			Expect(err).To(BeNil())
+			Expect(retry).To(BeFalse())
 
-						By("Simulating Argo CD creating a default AppProject, as soon as the ArgoCD CR exists")
-						appProject := appv1.AppProject{
-							ObjectMeta: metav1.ObjectMeta{
-								Name:      "default",
-								Namespace: argoCDItem.Namespace,
-							},
-							Spec: appv1.AppProjectSpec{},
-						}
+			By("check if the Operation state is updated to Completed")
+			err = dbQueries.GetOperationById(ctx, operationDB)
+			Expect(err).To(BeNil())
+			Expect(operationDB.State).To(Equal(db.OperationState_Completed))
+		})
 
-						err := k8sClient.Create(context.Background(), &appProject)
-						Expect(err).To(BeNil())
+		It("ensures that a GitOpsEngineInstance Operation is failed, if Argo CD does not start within the timeout", func() {
+			By("Close database connection")
+			defer dbQueries.CloseDatabase()
+			defer testTeardown()
 
-						break outer_for
+			gitopsEngineCluster, _, err := dbutil.GetOrCreateGitopsEngineClusterByKubeSystemNamespaceUID(ctx, string(kubesystemNamespace.UID), dbQueries, logger)
+			Expect(err).To(BeNil())
 
-					}
+			newArgoCDNamespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-new-argocd-namespace",
+					UID:  "test-new-argocd-namespace-uuid",
+				},
+			}
+			Expect(k8sClient.Create(ctx, newArgoCDNamespace)).To(Succeed())
+
+			task.event.request.Namespace = newArgoCDNamespace.Name
 
-					time.Sleep(100 * time.Millisecond)
+			gitopsEngineInstance := db.GitopsEngineInstance{
+				Gitopsengineinstance_id: "test-fake-engine-instance-id-1",
+				Namespace_name:          newArgoCDNamespace.Name,
+				Namespace_uid:           string(newArgoCDNamespace.UID),
+				EngineCluster_id:        gitopsEngineCluster.Gitopsenginecluster_id,
+			}
+			Expect(dbQueries.CreateGitopsEngineInstance(ctx, &gitopsEngineInstance)).To(Succeed())
 
-				}
-			}()
+			operationDB := &db.Operation{
+				Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+				Resource_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+				Resource_type:           db.OperationResourceType_GitOpsEngineInstance,
+				State:                   db.OperationState_Waiting,
+				Operation_owner_user_id: testClusterUser.Clusteruser_id,
+			}
+			Expect(dbQueries.CreateOperation(ctx, operationDB, operationDB.Operation_owner_user_id)).To(Succeed())
+
+			By("simulating an Operation that was created longer ago than the timeout")
+			operationDB.Created_on = time.Now().Add(-(argoCDInstanceReadyTimeout + time.Minute))
+			Expect(dbQueries.UpdateOperation(ctx, operationDB)).To(Succeed())
+
+			operationCR := &managedgitopsv1alpha1.Operation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: gitopsEngineInstance.Namespace_name,
+				},
+				Spec: managedgitopsv1alpha1.OperationSpec{
+					OperationID: operationDB.Operation_id,
+				},
+			}
+			Expect(task.event.client.Create(ctx, operationCR)).To(Succeed())
 
 			retry, err := task.PerformTask(ctx)
-			Expect(err).To(BeNil())
+			Expect(err).ToNot(BeNil())
 			Expect(retry).To(BeFalse())
 
-			By("check if the Operation state is updated to InProgress")
-			err = dbQueries.GetOperationById(ctx, operationDB)
-			Expect(err).To(BeNil())
-			Expect(operationDB.State).To(Equal(db.OperationState_In_Progress))
+			Expect(dbQueries.GetOperationById(ctx, operationDB)).To(Succeed())
+			Expect(operationDB.State).To(Equal(db.OperationState_Failed))
+			Expect(operationDB.Human_readable_state).To(ContainSubstring("was not ready within"))
 		})
 
 		It("ensures that if the kube-system namespace does not having a matching namespace uid, an error is not returned, but retry is true", func() {
@@ -1359,6 +1521,7 @@ var _ = Describe("Operation Controller", func() {
 					AllowInsecureSkipTLSVerify:  true,
 					Namespaces:                  "a,b,c",
 					ClusterResources:            true,
+					Proxy_url:                   "http://my-proxy.example.com:3128",
 				}
 				err = dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)
 				Expect(err).To(BeNil())
@@ -1465,6 +1628,7 @@ var _ = Describe("Operation Controller", func() {
 
 				Expect(secretJSON.BearerToken).To(Equal(clusterCredentials.Serviceaccount_bearer_token))
 				Expect(secretJSON.TLSClientConfig.Insecure).To(Equal(clusterCredentials.AllowInsecureSkipTLSVerify))
+				Expect(secretJSON.ProxyUrl).To(Equal(clusterCredentials.Proxy_url))
 
 				By("creating new cluster credentials for that managed env, containing different namespaces/clusterresource fields")
 				clusterCredentials = db.ClusterCredentials{
@@ -1810,6 +1974,7 @@ var _ = Describe("Operation Controller", func() {
 					DeploymentNameField: "test",
 					Revision:            "main",
 					DesiredState:        db.SyncOperation_DesiredState_Running,
+					Initiator:           db.SyncOperation_Initiator_SyncRunPrefix + "my-sync-run",
 				}
 				err = dbQueries.CreateSyncOperation(ctx, &syncOperation)
 				Expect(err).To(BeNil())
@@ -1817,9 +1982,95 @@ var _ = Describe("Operation Controller", func() {
 				By("create Operation DB row and CR for the SyncOperation")
 				createOperationDBAndCR(syncOperation.SyncOperation_id, gitopsEngineInstanceID)
 
-				By("verify there is no retry for a successful sync")
+				By("verify there is no retry for a successful sync, and that the initiator is passed to Argo CD")
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool, initiator string, resources []appv1.SyncOperationResource, retryStrategy *appv1.RetryStrategy) error {
+						if initiator != syncOperation.Initiator {
+							return fmt.Errorf("unexpected initiator: %s", initiator)
+						}
+						return nil
+					},
+					refreshApp: refreshApplication,
+				}
+
+				retry, err := task.PerformTask(ctx)
+				Expect(err).Should(BeNil())
+				Expect(retry).To(BeFalse())
+
+				By("verify if the refresh annotation was added")
+				Expect(<-refreshAnnotationFound).To(Equal(struct{}{}))
+			})
+
+			It("should pass the resources of a selective SyncOperation to Argo CD", func() {
+
+				By("create a SyncOperation with resources in the database")
+				syncOperation := db.SyncOperation{
+					SyncOperation_id:    "test-syncoperation",
+					Application_id:      applicationDB.Application_id,
+					DeploymentNameField: "test",
+					Revision:            "main",
+					DesiredState:        db.SyncOperation_DesiredState_Running,
+					Resources:           `[{"group":"apps","kind":"Deployment","name":"my-deployment","namespace":"my-namespace"}]`,
+				}
+				err = dbQueries.CreateSyncOperation(ctx, &syncOperation)
+				Expect(err).To(BeNil())
+
+				By("create Operation DB row and CR for the SyncOperation")
+				createOperationDBAndCR(syncOperation.SyncOperation_id, gitopsEngineInstanceID)
+
+				By("verify that only the resources of the SyncOperation are synced")
+				task.syncFuncs = &syncFuncs{
+					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool, s4 string, resources []appv1.SyncOperationResource, retryStrategy *appv1.RetryStrategy) error {
+						expected := []appv1.SyncOperationResource{
+							{Group: "apps", Kind: "Deployment", Name: "my-deployment", Namespace: "my-namespace"},
+						}
+						if !reflect.DeepEqual(resources, expected) {
+							return fmt.Errorf("unexpected resources: %v", resources)
+						}
+						return nil
+					},
+					refreshApp: refreshApplication,
+				}
+
+				retry, err := task.PerformTask(ctx)
+				Expect(err).Should(BeNil())
+				Expect(retry).To(BeFalse())
+
+				By("verify if the refresh annotation was added")
+				Expect(<-refreshAnnotationFound).To(Equal(struct{}{}))
+			})
+
+			It("should pass the retry policy of the SyncOperation to Argo CD, as the retry strategy of the sync", func() {
+
+				By("create a SyncOperation with a retry policy in the database")
+				syncOperation := db.SyncOperation{
+					SyncOperation_id:        "test-syncoperation",
+					Application_id:          applicationDB.Application_id,
+					DeploymentNameField:     "test",
+					Revision:                "main",
+					DesiredState:            db.SyncOperation_DesiredState_Running,
+					RetryLimit:              3,
+					RetryBackoffDuration:    "10s",
+					RetryBackoffFactor:      2,
+					RetryBackoffMaxDuration: "2m",
+				}
+				err = dbQueries.CreateSyncOperation(ctx, &syncOperation)
+				Expect(err).To(BeNil())
+
+				By("create Operation DB row and CR for the SyncOperation")
+				createOperationDBAndCR(syncOperation.SyncOperation_id, gitopsEngineInstanceID)
+
+				By("verify that the retry strategy is passed to the sync")
+				task.syncFuncs = &syncFuncs{
+					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool, s4 string, resources []appv1.SyncOperationResource, retryStrategy *appv1.RetryStrategy) error {
+						factor := int64(2)
+						expected := &appv1.RetryStrategy{
+							Limit:   3,
+							Backoff: &appv1.Backoff{Duration: "10s", Factor: &factor, MaxDuration: "2m"},
+						}
+						if !reflect.DeepEqual(retryStrategy, expected) {
+							return fmt.Errorf("unexpected retry strategy: %v", retryStrategy)
+						}
 						return nil
 					},
 					refreshApp: refreshApplication,
@@ -1852,7 +2103,7 @@ var _ = Describe("Operation Controller", func() {
 				By("check if the sync failed error is returned with retry")
 				expectedErr := "sync failed due to xyz reason"
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool, s4 string, resources []appv1.SyncOperationResource, retryStrategy *appv1.RetryStrategy) error {
 						return fmt.Errorf(expectedErr)
 					},
 					refreshApp: refreshApplication,
@@ -1894,6 +2145,41 @@ var _ = Describe("Operation Controller", func() {
 				Expect(retry).To(BeTrue())
 			})
 
+			It("should fail the SyncOperation, without attempting a sync, if the revision does not exist in the repository", func() {
+				By("create a SyncOperation in the database, with a typo in the revision")
+				syncOperation := db.SyncOperation{
+					SyncOperation_id:    "test-syncoperation",
+					Application_id:      applicationDB.Application_id,
+					DeploymentNameField: "test",
+					Revision:            "mian",
+					DesiredState:        db.SyncOperation_DesiredState_Running,
+				}
+				err = dbQueries.CreateSyncOperation(ctx, &syncOperation)
+				Expect(err).To(BeNil())
+
+				By("create Operation DB row and CR for the SyncOperation")
+				createOperationDBAndCR(syncOperation.SyncOperation_id, gitopsEngineInstanceID)
+
+				appSyncCalled := false
+				task.syncFuncs = &syncFuncs{
+					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool, s4 string, resources []appv1.SyncOperationResource, retryStrategy *appv1.RetryStrategy) error {
+						appSyncCalled = true
+						return nil
+					},
+					refreshApp: refreshApplication,
+					resolveRevision: func(ctx context.Context, c client.Client, argoCDNamespace, repoURL, revision string) (string, error) {
+						return "", fmt.Errorf("%w: '%s' is not a branch, tag, or commit SHA of the repository", utils.ErrRevisionNotFound, revision)
+					},
+				}
+
+				By("verifying the operation is not retried, and that Argo CD was not asked to sync")
+				retry, err := task.PerformTask(ctx)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("'mian' is not a branch, tag, or commit SHA"))
+				Expect(retry).To(BeFalse())
+				Expect(appSyncCalled).To(BeFalse())
+			})
+
 			It("should handle conflicts while refreshing application", func() {
 				By("create a SyncOperation in the database")
 				syncOperation := db.SyncOperation{
@@ -1926,7 +2212,7 @@ var _ = Describe("Operation Controller", func() {
 				Expect(apierr.IsConflict(err)).To(BeTrue())
 
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool, s4 string, resources []appv1.SyncOperationResource, retryStrategy *appv1.RetryStrategy) error {
 						return nil
 					},
 					refreshApp: refreshApplication,
@@ -1948,7 +2234,7 @@ var _ = Describe("Operation Controller", func() {
 
 				By("check if SyncOperation not found error is handled")
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool, s4 string, resources []appv1.SyncOperationResource, retryStrategy *appv1.RetryStrategy) error {
 						return nil
 					},
 				}
@@ -1976,7 +2262,7 @@ var _ = Describe("Operation Controller", func() {
 				createOperationDBAndCR(syncOperation.SyncOperation_id, gitopsEngineInstanceID)
 
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool, s4 string, resources []appv1.SyncOperationResource, retryStrategy *appv1.RetryStrategy) error {
 						return nil
 					},
 				}
@@ -0,0 +1,203 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EnableOperationStatusMirrorEnvVar may be set to 'true' to mirror the state of each Operation into a
+	// GitOpsDeploymentOperationStatus, in the namespace of the API resource that the Operation was created for.
+	// Disabled by default.
+	EnableOperationStatusMirrorEnvVar = "ENABLE_OPERATION_STATUS_MIRROR"
+
+	// redactedValue replaces the internal details of an Operation in the message of a GitOpsDeploymentOperationStatus
+	redactedValue = "(redacted)"
+)
+
+func isOperationStatusMirrorEnabled() bool {
+	return os.Getenv(EnableOperationStatusMirrorEnvVar) == "true"
+}
+
+// operationOwnerResource is the API resource that an Operation was created for
+type operationOwnerResource struct {
+	kind      string
+	name      string
+	namespace string
+	uid       string
+}
+
+// mirrorOperationStatus creates or updates the GitOpsDeploymentOperationStatus of the API resource that the Operation was
+// created for, from the state of the Operation. The GitOpsDeploymentOperationStatus is owned by the API resource, and is
+// thus deleted with it.
+//
+// Operations that were not created for an API resource (for example, for a GitOpsEngineInstance) are not mirrored. This
+// is a no-op unless enabled via EnableOperationStatusMirrorEnvVar.
+func mirrorOperationStatus(ctx context.Context, dbOperation db.Operation, argoCDNamespace string, k8sClient client.Client, dbQueries db.DatabaseQueries) error {
+
+	if !isOperationStatusMirrorEnabled() {
+		return nil
+	}
+
+	owner, err := getOperationOwnerResource(ctx, dbOperation, dbQueries)
+	if err != nil {
+		return err
+	} else if owner == nil {
+		return nil
+	}
+
+	operationStatus := &managedgitopsv1alpha1.GitOpsDeploymentOperationStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.ToLower(owner.kind) + "-" + owner.name,
+			Namespace: owner.namespace,
+		},
+	}
+
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(operationStatus), operationStatus); err != nil {
+		if !apierr.IsNotFound(err) {
+			return fmt.Errorf("unable to retrieve GitOpsDeploymentOperationStatus '%s': %v", operationStatus.Name, err)
+		}
+
+		operationStatus.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: managedgitopsv1alpha1.GroupVersion.String(),
+			Kind:       owner.kind,
+			Name:       owner.name,
+			UID:        types.UID(owner.uid),
+		}}
+		setOperationStatusFields(operationStatus, owner, dbOperation, argoCDNamespace)
+
+		if err := k8sClient.Create(ctx, operationStatus); err != nil {
+			return fmt.Errorf("unable to create GitOpsDeploymentOperationStatus '%s': %v", operationStatus.Name, err)
+		}
+		return nil
+	}
+
+	// Don't replace the state of a newer Operation with the state of an older one
+	if operationStatus.CreatedOn.Time.After(dbOperation.Created_on) {
+		return nil
+	}
+
+	original := operationStatus.DeepCopy()
+	setOperationStatusFields(operationStatus, owner, dbOperation, argoCDNamespace)
+
+	if original.ResourceRef == operationStatus.ResourceRef && original.State == operationStatus.State &&
+		original.Message == operationStatus.Message && original.CreatedOn.Equal(&operationStatus.CreatedOn) &&
+		original.LastStateUpdate.Equal(&operationStatus.LastStateUpdate) {
+		return nil
+	}
+
+	if err := k8sClient.Update(ctx, operationStatus); err != nil {
+		return fmt.Errorf("unable to update GitOpsDeploymentOperationStatus '%s': %v", operationStatus.Name, err)
+	}
+
+	return nil
+}
+
+// setOperationStatusFields sets the fields of the GitOpsDeploymentOperationStatus from the Operation, redacting the
+// details of the Operation that are internal to the GitOps Service from its message.
+func setOperationStatusFields(operationStatus *managedgitopsv1alpha1.GitOpsDeploymentOperationStatus, owner *operationOwnerResource,
+	dbOperation db.Operation, argoCDNamespace string) {
+
+	message := dbOperation.Human_readable_state
+	for _, internalValue := range []string{argoCDNamespace, dbOperation.Operation_id, dbOperation.Instance_id,
+		dbOperation.Resource_id, dbOperation.Operation_owner_user_id} {
+
+		if internalValue != "" {
+			message = strings.ReplaceAll(message, internalValue, redactedValue)
+		}
+	}
+
+	operationStatus.ResourceRef = managedgitopsv1alpha1.OperationResourceRef{
+		Kind: owner.kind,
+		Name: owner.name,
+	}
+	operationStatus.State = string(dbOperation.State)
+	operationStatus.Message = message
+	// The times are truncated to seconds, which is the precision of a metav1.Time once it is stored
+	operationStatus.CreatedOn = metav1.NewTime(dbOperation.Created_on.Truncate(time.Second))
+	operationStatus.LastStateUpdate = metav1.NewTime(dbOperation.Last_state_update.Truncate(time.Second))
+}
+
+// getOperationOwnerResource returns the API resource that the Operation was created for, or nil if the Operation was not
+// created for an API resource (or that resource no longer exists).
+func getOperationOwnerResource(ctx context.Context, dbOperation db.Operation, dbQueries db.DatabaseQueries) (*operationOwnerResource, error) {
+
+	var apiCRMapping db.APICRToDatabaseMapping
+
+	switch dbOperation.Resource_type {
+	case db.OperationResourceType_Application:
+		dtam := db.DeploymentToApplicationMapping{Application_id: dbOperation.Resource_id}
+		if err := dbQueries.GetDeploymentToApplicationMappingByApplicationId(ctx, &dtam); err != nil {
+			if db.IsResultNotFoundError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		return &operationOwnerResource{
+			kind:      "GitOpsDeployment",
+			name:      dtam.DeploymentName,
+			namespace: dtam.DeploymentNamespace,
+			uid:       dtam.Deploymenttoapplicationmapping_uid_id,
+		}, nil
+
+	case db.OperationResourceType_SyncOperation:
+		apiCRMapping = db.APICRToDatabaseMapping{
+			APIResourceType: db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun,
+			DBRelationType:  db.APICRToDatabaseMapping_DBRelationType_SyncOperation,
+		}
+
+	case db.OperationResourceType_ManagedEnvironment:
+		apiCRMapping = db.APICRToDatabaseMapping{
+			APIResourceType: db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment,
+			DBRelationType:  db.APICRToDatabaseMapping_DBRelationType_ManagedEnvironment,
+		}
+
+	case db.OperationResourceType_RepositoryCredentials:
+		repoCred, err := dbQueries.GetRepositoryCredentialsByID(ctx, dbOperation.Resource_id)
+		if err != nil {
+			if db.IsResultNotFoundError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		apiCRMapping = db.APICRToDatabaseMapping{
+			APIResourceType: db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredential,
+			DBRelationType:  db.APICRToDatabaseMapping_DBRelationType_RepositoryCredential,
+		}
+
+		// RepositoryCredentials rows of a template are owned by a GitOpsDeploymentRepositoryCredentialTemplate
+		if repoCred.IsTemplate {
+			apiCRMapping.APIResourceType = db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentRepositoryCredentialTemplate
+		}
+
+	default:
+		return nil, nil
+	}
+
+	apiCRMapping.DBRelationKey = dbOperation.Resource_id
+	if err := dbQueries.GetAPICRForDatabaseUID(ctx, &apiCRMapping); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &operationOwnerResource{
+		kind:      string(apiCRMapping.APIResourceType),
+		name:      apiCRMapping.APIResourceName,
+		namespace: apiCRMapping.APIResourceNamespace,
+		uid:       apiCRMapping.APIResourceUID,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package eventloop
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+)
+
+var _ = Describe("Operation status mirror Tests", func() {
+
+	const (
+		argoCDNamespace = "gitops-service-argocd"
+		userNamespace   = "my-user-namespace"
+	)
+
+	var (
+		ctx          context.Context
+		dbQueries    db.AllDatabaseQueries
+		k8sClient    client.Client
+		managedEnvID string
+		apiCRMapping db.APICRToDatabaseMapping
+	)
+
+	getOperationStatus := func() (*managedgitopsv1alpha1.GitOpsDeploymentOperationStatus, error) {
+		operationStatus := &managedgitopsv1alpha1.GitOpsDeploymentOperationStatus{
+			ObjectMeta: metav1.ObjectMeta{Name: "gitopsdeploymentmanagedenvironment-my-managed-env", Namespace: userNamespace},
+		}
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(operationStatus), operationStatus)
+		return operationStatus, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		err := db.SetupForTestingDBGinkgo()
+		Expect(err).To(BeNil())
+
+		dbQueries, err = db.NewUnsafePostgresDBQueries(true, true)
+		Expect(err).To(BeNil())
+
+		scheme := runtime.NewScheme()
+		Expect(managedgitopsv1alpha1.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		Expect(os.Setenv(EnableOperationStatusMirrorEnvVar, "true")).To(Succeed())
+
+		managedEnvID = "test-" + string(uuid.NewUUID())
+		apiCRMapping = db.APICRToDatabaseMapping{
+			APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment,
+			APIResourceUID:       "test-" + string(uuid.NewUUID()),
+			APIResourceName:      "my-managed-env",
+			APIResourceNamespace: userNamespace,
+			NamespaceUID:         "test-" + string(uuid.NewUUID()),
+			DBRelationType:       db.APICRToDatabaseMapping_DBRelationType_ManagedEnvironment,
+			DBRelationKey:        managedEnvID,
+		}
+		Expect(dbQueries.CreateAPICRToDatabaseMapping(ctx, &apiCRMapping)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv(EnableOperationStatusMirrorEnvVar)).To(Succeed())
+		dbQueries.CloseDatabase()
+	})
+
+	It("should mirror the state of an Operation into the namespace of its resource, with internal details redacted", func() {
+		operation := db.Operation{
+			Operation_id:            "test-operation-id",
+			Instance_id:             "test-instance-id",
+			Resource_id:             managedEnvID,
+			Resource_type:           db.OperationResourceType_ManagedEnvironment,
+			Operation_owner_user_id: "test-user-id",
+			State:                   db.OperationState_Waiting,
+			Created_on:              time.Now().Add(-time.Minute),
+			Last_state_update:       time.Now().Add(-time.Minute),
+		}
+
+		By("mirroring the Operation while it is queued")
+		Expect(mirrorOperationStatus(ctx, operation, argoCDNamespace, k8sClient, dbQueries)).To(Succeed())
+
+		operationStatus, err := getOperationStatus()
+		Expect(err).To(BeNil())
+		Expect(operationStatus.ResourceRef).To(Equal(managedgitopsv1alpha1.OperationResourceRef{
+			Kind: "GitOpsDeploymentManagedEnvironment", Name: "my-managed-env"}))
+		Expect(operationStatus.State).To(Equal(string(db.OperationState_Waiting)))
+		Expect(operationStatus.OwnerReferences).To(HaveLen(1))
+		Expect(string(operationStatus.OwnerReferences[0].UID)).To(Equal(apiCRMapping.APIResourceUID))
+
+		By("mirroring the Operation once it has failed")
+		operation.State = db.OperationState_Failed
+		operation.Last_state_update = time.Now()
+		operation.Human_readable_state = "unable to create cluster secret in namespace " + argoCDNamespace + " for " + managedEnvID
+
+		Expect(mirrorOperationStatus(ctx, operation, argoCDNamespace, k8sClient, dbQueries)).To(Succeed())
+
+		operationStatus, err = getOperationStatus()
+		Expect(err).To(BeNil())
+		Expect(operationStatus.State).To(Equal(string(db.OperationState_Failed)))
+		Expect(operationStatus.Message).To(Equal("unable to create cluster secret in namespace (redacted) for (redacted)"))
+
+		By("verifying the state of an older Operation does not replace the state of the newer Operation")
+		olderOperation := operation
+		olderOperation.Operation_id = "test-older-operation-id"
+		olderOperation.State = db.OperationState_Completed
+		olderOperation.Created_on = operation.Created_on.Add(-time.Hour)
+
+		Expect(mirrorOperationStatus(ctx, olderOperation, argoCDNamespace, k8sClient, dbQueries)).To(Succeed())
+
+		operationStatus, err = getOperationStatus()
+		Expect(err).To(BeNil())
+		Expect(operationStatus.State).To(Equal(string(db.OperationState_Failed)))
+	})
+
+	It("should not mirror the state of an Operation, if the mirror is not enabled", func() {
+		Expect(os.Unsetenv(EnableOperationStatusMirrorEnvVar)).To(Succeed())
+
+		operation := db.Operation{
+			Operation_id:  "test-operation-id",
+			Resource_id:   managedEnvID,
+			Resource_type: db.OperationResourceType_ManagedEnvironment,
+			State:         db.OperationState_Waiting,
+		}
+		Expect(mirrorOperationStatus(ctx, operation, argoCDNamespace, k8sClient, dbQueries)).To(Succeed())
+
+		_, err := getOperationStatus()
+		Expect(apierr.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not mirror the state of an Operation that was not created for an API resource", func() {
+		operation := db.Operation{
+			Operation_id:  "test-operation-id",
+			Resource_id:   "test-instance-id",
+			Resource_type: db.OperationResourceType_GitOpsEngineInstance,
+			State:         db.OperationState_Waiting,
+		}
+		Expect(mirrorOperationStatus(ctx, operation, argoCDNamespace, k8sClient, dbQueries)).To(Succeed())
+
+		operationStatusList := managedgitopsv1alpha1.GitOpsDeploymentOperationStatusList{}
+		Expect(k8sClient.List(ctx, &operationStatusList)).To(Succeed())
+		Expect(operationStatusList.Items).To(BeEmpty())
+	})
+})
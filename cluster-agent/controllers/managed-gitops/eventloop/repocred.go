@@ -3,8 +3,10 @@ package eventloop
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/argoproj/argo-cd/v2/common"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/go-logr/logr"
 	operation "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
@@ -33,6 +35,11 @@ const (
 	// #nosec G101
 	errSecretLabelList        = "unable to complete Argo CD Secret list"
 	errSevereNumOfItemsInList = "SEVERE: unexpected number (more than one) of related ArgoCD secrets"
+	errAppProjectLabelList    = "unable to complete Argo CD AppProject list"
+	errGetAppProject          = "unexpected error on retrieve Argo CD AppProject"
+	errCreateAppProject       = "unable to create Argo CD AppProject"
+	errUpdateAppProject       = "unable to update Argo CD AppProject"
+	errDeleteAppProject       = "unable to delete Argo CD AppProject"
 )
 
 // deleteArgoCDSecretLeftovers best effort attempt to clean up ArgoCD Secret leftovers.
@@ -91,9 +98,125 @@ func deleteArgoCDSecretLeftovers(ctx context.Context, databaseID string, argoCDN
 		return retry, firstDeletionErr
 	}
 
+	// The AppProject of the repository credentials (if they were scoped) is also a leftover
+	if err := deleteRepositoryCredentialAppProjects(ctx, databaseID, "", argoCDNamespace, eventClient, l); err != nil {
+		return retry, err
+	}
+
 	return noRetry, nil
 }
 
+// reconcileRepositoryCredentialAppProject ensures that the Argo CD AppProject, that scoped repository credentials are
+// restricted to, exists and allows deployment from the repository of the credentials. If the repository credentials
+// are not scoped, any AppProject that was previously created for them is deleted.
+//
+// Argo CD only uses repository credentials that specify a 'project' for the Argo CD Applications of that AppProject:
+// thus only the Applications of the GitOpsDeployments that are in scope (which are moved into the AppProject by the
+// backend) may use the credentials.
+func reconcileRepositoryCredentialAppProject(ctx context.Context, dbRepositoryCredentials db.RepositoryCredentials,
+	argoCDNamespace corev1.Namespace, eventClient client.Client, l logr.Logger) error {
+
+	// Delete the AppProjects of the repository credentials other than the current one (if any)
+	if err := deleteRepositoryCredentialAppProjects(ctx, dbRepositoryCredentials.RepositoryCredentialsID,
+		dbRepositoryCredentials.Project, argoCDNamespace, eventClient, l); err != nil {
+		return err
+	}
+
+	if dbRepositoryCredentials.Project == "" {
+		return nil
+	}
+
+	expectedSpec := appv1.AppProjectSpec{
+		Description: "Restricts the use of the repository credentials of a scoped GitOpsDeploymentRepositoryCredential",
+		SourceRepos: []string{dbRepositoryCredentials.PrivateURL},
+		Destinations: []appv1.ApplicationDestination{{
+			Server:    "*",
+			Namespace: "*",
+			Name:      "*",
+		}},
+		ClusterResourceWhitelist: []metav1.GroupKind{{Group: "*", Kind: "*"}},
+	}
+
+	appProject := &appv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dbRepositoryCredentials.Project,
+			Namespace: argoCDNamespace.Name,
+		},
+	}
+
+	if err := eventClient.Get(ctx, client.ObjectKeyFromObject(appProject), appProject); err != nil {
+		if !apierr.IsNotFound(err) {
+			l.Error(err, errGetAppProject, "appProject", appProject.Name)
+			return err
+		}
+
+		appProject.Labels = map[string]string{controllers.RepoCredDatabaseIDLabel: dbRepositoryCredentials.RepositoryCredentialsID}
+		appProject.Spec = expectedSpec
+
+		if err := eventClient.Create(ctx, appProject); err != nil {
+			l.Error(err, errCreateAppProject, "appProject", appProject.Name)
+			return err
+		}
+		logutil.LogAPIResourceChangeEvent(appProject.Namespace, appProject.Name, appProject, logutil.ResourceCreated, l)
+
+		return nil
+	}
+
+	if reflect.DeepEqual(appProject.Spec, expectedSpec) &&
+		appProject.Labels[controllers.RepoCredDatabaseIDLabel] == dbRepositoryCredentials.RepositoryCredentialsID {
+		return nil
+	}
+
+	if appProject.Labels == nil {
+		appProject.Labels = map[string]string{}
+	}
+	appProject.Labels[controllers.RepoCredDatabaseIDLabel] = dbRepositoryCredentials.RepositoryCredentialsID
+	appProject.Spec = expectedSpec
+
+	if err := eventClient.Update(ctx, appProject); err != nil {
+		l.Error(err, errUpdateAppProject, "appProject", appProject.Name)
+		return err
+	}
+	logutil.LogAPIResourceChangeEvent(appProject.Namespace, appProject.Name, appProject, logutil.ResourceModified, l)
+
+	return nil
+}
+
+// deleteRepositoryCredentialAppProjects deletes the Argo CD AppProjects that were created for the repository credentials
+// with the given database ID, except for the AppProject with name 'exceptName' (if non-empty).
+func deleteRepositoryCredentialAppProjects(ctx context.Context, databaseID string, exceptName string,
+	argoCDNamespace corev1.Namespace, eventClient client.Client, l logr.Logger) error {
+
+	list := appv1.AppProjectList{}
+	if err := eventClient.List(ctx, &list, &client.ListOptions{
+		Namespace:     argoCDNamespace.Name,
+		LabelSelector: labels.SelectorFromSet(labels.Set{controllers.RepoCredDatabaseIDLabel: databaseID}),
+	}); err != nil {
+		l.Error(err, errAppProjectLabelList)
+		return err
+	}
+
+	for idx := range list.Items {
+
+		item := list.Items[idx]
+
+		if item.Name == exceptName {
+			continue
+		}
+
+		if err := eventClient.Delete(ctx, &item); err != nil {
+			if apierr.IsNotFound(err) {
+				continue
+			}
+			l.Error(err, errDeleteAppProject, "appProject", item.Name, "namespace", item.Namespace)
+			return err
+		}
+		logutil.LogAPIResourceChangeEvent(item.Namespace, item.Name, item, logutil.ResourceDeleted, l)
+	}
+
+	return nil
+}
+
 // processOperation_RepositoryCredentials processes the given operation as a RepositoryCredentials operation.
 // It returns true if the operation should be retried, and false otherwise.
 // It returns an error if there was an error processing the operation.
@@ -180,16 +303,22 @@ func processOperation_RepositoryCredentials(ctx context.Context, dbOperation db.
 
 	}
 
+	// 5. Ensure the AppProject that the repository credentials are scoped to (if any) exists
+	if err := reconcileRepositoryCredentialAppProject(ctx, dbRepositoryCredentials, opConfig.argoCDNamespace, opConfig.eventClient, l); err != nil {
+		return retry, err
+	}
+
 	return noRetry, nil
 }
 
 func compareClusterResourceWithDatabaseRow(dbRepositoryCredentials db.RepositoryCredentials, argoCDSecret *corev1.Secret, l logr.Logger, decodedSecret *db.RepositoryCredentials) bool {
 	labelDatabaseIDPrivateRepoSecret := fmt.Sprintf("%s: %s", controllers.RepoCredDatabaseIDLabel, dbRepositoryCredentials.RepositoryCredentialsID)
-	labelArgoCDPrivateRepoSecret := fmt.Sprintf("%s: %s", common.LabelKeySecretType, common.LabelValueSecretTypeRepository)
+	secretType := getArgoCDSecretType(dbRepositoryCredentials)
+	labelArgoCDPrivateRepoSecret := fmt.Sprintf("%s: %s", common.LabelKeySecretType, secretType)
 	annotationArgoCDPrivateRepoSecret := fmt.Sprintf("%s: %s", common.AnnotationKeyManagedBy, common.AnnotationValueManagedByArgoCD)
 	var argoCDLabelFound, repoCredLabelFound, repoCredAnnotationFound bool
 
-	if keyValue, isKeyExists := argoCDSecret.Labels[common.LabelKeySecretType]; isKeyExists && keyValue == common.LabelValueSecretTypeRepository {
+	if keyValue, isKeyExists := argoCDSecret.Labels[common.LabelKeySecretType]; isKeyExists && keyValue == secretType {
 		argoCDLabelFound = true
 	}
 
@@ -204,7 +333,7 @@ func compareClusterResourceWithDatabaseRow(dbRepositoryCredentials db.Repository
 	var isArgoCDLabelUpdateNeeded bool
 	if !argoCDLabelFound {
 		l.Info("Secret is missing ArgoCD label! Syncing with database...", "AddLabel", labelArgoCDPrivateRepoSecret)
-		addSecretArgoCDMetadata(argoCDSecret, common.LabelValueSecretTypeRepository)
+		addSecretArgoCDMetadata(argoCDSecret, secretType)
 		isArgoCDLabelUpdateNeeded = true
 	}
 
@@ -257,10 +386,21 @@ func compareClusterResourceWithDatabaseRow(dbRepositoryCredentials db.Repository
 		isSSHKeyUpdateNeeded = true
 	}
 
+	var isProjectUpdateNeeded bool
+	if decodedSecret.Project != dbRepositoryCredentials.Project {
+		l.Info("Secret has wrong project! Syncing with database...", "UpdateFrom", decodedSecret.Project, "UpdateTo", dbRepositoryCredentials.Project)
+		if dbRepositoryCredentials.Project == "" {
+			delete(argoCDSecret.Data, "project")
+		} else {
+			argoCDSecret.Data["project"] = []byte(dbRepositoryCredentials.Project)
+		}
+		isProjectUpdateNeeded = true
+	}
+
 	// If any of the above steps have been performed, then we need to update the cluster secret resource.
 	isUpdateNeeded := isArgoCDLabelUpdateNeeded || isRepoCredLabelUpdateNeeded || isRepoCredAnnotationUpdateNeeded ||
 		isPrivateURLUpdateNeeded || isPasswordUpdateNeeded || isUsernameUpdateNeeded || isSSHKeyUpdateNeeded ||
-		isSecretNameUpdateNeeded
+		isSecretNameUpdateNeeded || isProjectUpdateNeeded
 
 	return isUpdateNeeded
 }
@@ -275,12 +415,12 @@ func convertRepoCredToSecret(repoCred db.RepositoryCredentials, secret *corev1.S
 	updateSecretString(secret, "username", repoCred.AuthUsername)
 	updateSecretString(secret, "password", repoCred.AuthPassword)
 	updateSecretString(secret, "sshPrivateKey", repoCred.AuthSSHKey)
-	addSecretArgoCDMetadata(secret, common.LabelValueSecretTypeRepository) // adds the ArgoCD Label
-	addSecretRepoCredMetadata(secret, repoCred.RepositoryCredentialsID)    // adds the DatabaseID Label
+	updateSecretString(secret, "project", repoCred.Project)
+	addSecretArgoCDMetadata(secret, getArgoCDSecretType(repoCred))      // adds the ArgoCD Label
+	addSecretRepoCredMetadata(secret, repoCred.RepositoryCredentialsID) // adds the DatabaseID Label
 
 	// Values Supported by ArgoCD but not yet part of GitOps Repository Credentials as part of the MVP
 	// -----------------------------------------------------------------------------------------------
-	//updateSecretString(secret, "project", "") not supported yet
 	//updateSecretBool(secret, "enableOCI", repository.EnableOCI)
 	//updateSecretString(secret, "tlsClientCertData", repository.TLSClientCertData)
 	//updateSecretString(secret, "tlsClientCertKey", repository.TLSClientCertKey)
@@ -295,6 +435,16 @@ func convertRepoCredToSecret(repoCred db.RepositoryCredentials, secret *corev1.S
 	//updateSecretString(secret, "proxy", repository.Proxy)
 }
 
+// getArgoCDSecretType returns the type of the Argo CD Secret of the repository credentials: a credential template
+// (whose URL is a prefix of the URLs of the repositories it is used for), or a repository.
+// https://argo-cd.readthedocs.io/en/stable/operator-manual/declarative-setup/#repository-credentials
+func getArgoCDSecretType(repoCred db.RepositoryCredentials) string {
+	if repoCred.IsTemplate {
+		return common.LabelValueSecretTypeRepoCreds
+	}
+	return common.LabelValueSecretTypeRepository
+}
+
 func updateSecretString(secret *corev1.Secret, key, value string) {
 	if _, present := secret.Data[key]; present || value != "" {
 		secret.Data[key] = []byte(value)
@@ -339,5 +489,6 @@ func secretToRepoCred(secret *corev1.Secret) (repoCred *db.RepositoryCredentials
 		AuthPassword: string(secret.Data["password"]),
 		AuthSSHKey:   string(secret.Data["sshPrivateKey"]),
 		SecretObj:    secret.Name,
+		Project:      string(secret.Data["project"]),
 	}
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers"
 	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -510,4 +511,126 @@ var _ = Describe("Testing Repository Credentials Operation", func() {
 			})
 		})
 	})
+
+	// Scenario 6
+	Describe("Creating an ArgoCD secret and AppProject for scoped RepositoryCredentials", func() {
+		When("the RepositoryCredentials DB row has a project", func() {
+			var operationDB *db.Operation
+			var operationCR *operation.Operation
+			var repositoryCredential db.RepositoryCredentials
+
+			BeforeEach(func() {
+				By(" --- creating an Operation DB row and CR with a valid Resource_id ---")
+				dbOperationInput := db.Operation{
+					Operation_id:            "test-operation-6",
+					Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+					Resource_id:             "test-my-repo-creds-6", // this needs to be unique for this testcase!
+					Resource_type:           db.OperationResourceType_RepositoryCredentials,
+					State:                   db.OperationState_Waiting,
+					Operation_owner_user_id: clusterUser.Clusteruser_id,
+				}
+
+				operationCR, operationDB, err = operations.CreateOperation(ctx, false, dbOperationInput, clusterUser.Clusteruser_id, namespace, dbq, k8sClient, logger)
+				Expect(err).To(BeNil())
+				task.event.request.Name = operationCR.Name // Correct the task's Operation CR name request
+
+				By(" --- creating a scoped RepositoryCredentials DB row with Resource_id as its PrimaryKey ---")
+				repositoryCredential = db.RepositoryCredentials{
+					RepositoryCredentialsID: operationDB.Resource_id,
+					UserID:                  clusterUser.Clusteruser_id, // comply with the constraint 'fk_clusteruser_id'
+					PrivateURL:              "https://github.com/test/private-repo",
+					AuthUsername:            "test-fake-auth-username",
+					AuthPassword:            "test-fake-auth-password",
+					SecretObj:               "test-fake-scoped-secret-obj",
+					EngineClusterID:         gitopsEngineInstance.Gitopsengineinstance_id, // comply with the constraint 'fk_gitopsengineinstance_id'
+					Project:                 "gitops-repo-cred-test-uid",
+				}
+				err = dbq.CreateRepositoryCredentials(ctx, &repositoryCredential)
+				Expect(err).To(BeNil())
+			})
+
+			It("Should create the AppProject, and delete it once the RepositoryCredentials are no longer scoped", func() {
+
+				By(" --- calling processOperation_RepositoryCredentials() ---")
+				retry, err := task.PerformTask(ctx)
+				Expect(err).To(BeNil())
+				Expect(retry).To(BeFalse())
+
+				By(" --- checking the secret is scoped to the project ---")
+				secret := &corev1.Secret{}
+				err = task.event.client.Get(ctx, types.NamespacedName{Name: repositoryCredential.SecretObj, Namespace: namespace}, secret)
+				Expect(err).To(BeNil())
+				Expect(string(secret.Data["project"])).Should(Equal(repositoryCredential.Project))
+
+				By(" --- checking the AppProject only allows the repository of the credentials ---")
+				appProject := &appv1.AppProject{}
+				err = task.event.client.Get(ctx, types.NamespacedName{Name: repositoryCredential.Project, Namespace: namespace}, appProject)
+				Expect(err).To(BeNil())
+				Expect(appProject.Spec.SourceRepos).Should(Equal([]string{repositoryCredential.PrivateURL}))
+				Expect(appProject.Labels[controllers.RepoCredDatabaseIDLabel]).Should(Equal(repositoryCredential.RepositoryCredentialsID))
+
+				By(" --- removing the scope of the RepositoryCredentials ---")
+				repositoryCredential.Project = ""
+				err = dbq.UpdateRepositoryCredentials(ctx, &repositoryCredential)
+				Expect(err).To(BeNil())
+
+				dbOperationInput := db.Operation{
+					Operation_id:            "test-operation-6b",
+					Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+					Resource_id:             repositoryCredential.RepositoryCredentialsID,
+					Resource_type:           db.OperationResourceType_RepositoryCredentials,
+					State:                   db.OperationState_Waiting,
+					Operation_owner_user_id: clusterUser.Clusteruser_id,
+				}
+				operationCR, operationDB, err = operations.CreateOperation(ctx, false, dbOperationInput, clusterUser.Clusteruser_id, namespace, dbq, k8sClient, logger)
+				Expect(err).To(BeNil())
+				task.event.request.Name = operationCR.Name // Correct the task's Operation CR name request
+
+				By(" --- calling processOperation_RepositoryCredentials() again ---")
+				retry, err = task.PerformTask(ctx)
+				Expect(err).To(BeNil())
+				Expect(retry).To(BeFalse())
+
+				By(" --- checking the secret is no longer scoped, and the AppProject was deleted ---")
+				secret = &corev1.Secret{}
+				err = task.event.client.Get(ctx, types.NamespacedName{Name: repositoryCredential.SecretObj, Namespace: namespace}, secret)
+				Expect(err).To(BeNil())
+				Expect(secret.Data).ShouldNot(HaveKey("project"))
+
+				err = task.event.client.Get(ctx, types.NamespacedName{Name: appProject.Name, Namespace: namespace}, &appv1.AppProject{})
+				Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+				By(" --- checking Operation DB status ---")
+				err = dbq.GetOperationById(ctx, operationDB)
+				Expect(err).To(BeNil())
+				Expect(operationDB.State).Should(Equal(db.OperationState_Completed))
+			})
+		})
+	})
+
+	Describe("Converting RepositoryCredentials DB rows to ArgoCD secrets", func() {
+		It("Should label the ArgoCD Secret as a credential template, only for the RepositoryCredentials of a template", func() {
+			repoCred := db.RepositoryCredentials{
+				RepositoryCredentialsID: "test-repo-cred-id",
+				PrivateURL:              "https://github.com/my-org/",
+				AuthUsername:            "test-user",
+				AuthPassword:            "test-pass",
+				SecretObj:               "test-secret",
+			}
+
+			By("converting the RepositoryCredentials of a single repository")
+			secret := &corev1.Secret{}
+			convertRepoCredToSecret(repoCred, secret)
+			Expect(secret.Labels[common.LabelKeySecretType]).To(Equal(common.LabelValueSecretTypeRepository))
+			Expect(string(secret.Data["url"])).To(Equal(repoCred.PrivateURL))
+
+			By("converting the RepositoryCredentials of a template")
+			repoCred.IsTemplate = true
+			secret = &corev1.Secret{}
+			convertRepoCredToSecret(repoCred, secret)
+			Expect(secret.Labels[common.LabelKeySecretType]).To(Equal(common.LabelValueSecretTypeRepoCreds))
+			Expect(string(secret.Data["url"])).To(Equal(repoCred.PrivateURL))
+			Expect(secret.Labels[controllers.RepoCredDatabaseIDLabel]).To(Equal(repoCred.RepositoryCredentialsID))
+		})
+	})
 })
@@ -0,0 +1,126 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+)
+
+// OperationHandoff takes over the Operations of the previous leader, when this cluster-agent replica becomes the leader.
+//
+// Operations are only processed by the leader, which records its identity on each Operation that it sets to In_Progress.
+// If the leader dies while processing Operations (for example, because it crashed, or lost its lease), those Operations
+// would remain In_Progress. So, on becoming the leader, the In_Progress Operations of this cluster that were not set
+// by this replica are returned to Waiting. The Operation controller processes every Operation CR when it starts, so the
+// Operations are then processed again by the new leader.
+type OperationHandoff struct {
+	Client client.Client
+	DB     db.DatabaseQueries
+
+	// ProcessingOwner is the identity of this cluster-agent replica: see NewProcessingOwnerIdentity.
+	ProcessingOwner string
+}
+
+// CheckpointOperations is called on shutdown of this replica, once its Operation event loop has been drained: the
+// Operations that are still In_Progress are returned to Waiting, with a resume token, so that the next leader resumes
+// them immediately, rather than only releasing them once it becomes the leader.
+func (h *OperationHandoff) CheckpointOperations(ctx context.Context) error {
+
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("component", "operation-handoff", "processingOwner", h.ProcessingOwner)
+
+	resumeToken := newOperationResumeToken(h.ProcessingOwner, time.Now())
+
+	checkpointed, err := h.DB.CheckpointInProgressOperationsOfProcessingOwner(ctx, h.ProcessingOwner, resumeToken)
+	if err != nil {
+		return fmt.Errorf("unable to checkpoint In_Progress Operations: %v", err)
+	}
+
+	log.Info("Checkpointed the In_Progress Operations of this replica on shutdown", "checkpointed", checkpointed, "resumeToken", resumeToken)
+
+	return nil
+}
+
+// newOperationResumeToken returns the resume token of the Operations that are checkpointed by a replica: '(processing owner)_(time)'
+func newOperationResumeToken(processingOwner string, now time.Time) string {
+	return processingOwner + "_" + now.UTC().Format("20060102T150405Z")
+}
+
+// NewProcessingOwnerIdentity returns an identity for this cluster-agent replica, which is unique across restarts.
+func NewProcessingOwnerIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "cluster-agent"
+	}
+
+	return db.TruncateVarchar(hostname, db.OperationProcessingOwnerLength-37) + "_" + string(uuid.NewUUID())
+}
+
+// Start is called by the manager once this replica has become the leader, and releases the Operations of the previous leader.
+func (h *OperationHandoff) Start(ctx context.Context) error {
+
+	log := log.FromContext(ctx).
+		WithName(logutil.LogLogger_managed_gitops).
+		WithValues("component", "operation-handoff", "processingOwner", h.ProcessingOwner)
+
+	released, err := h.releaseOperationsOfPreviousLeader(ctx)
+	if err != nil {
+		// The manager would exit if an error was returned, so the error is logged instead: the Operations of the
+		// previous leader are still processed, but only when their Operation CR is next reconciled.
+		log.Error(err, "unable to release the Operations of the previous leader")
+		return nil
+	}
+
+	log.Info("Released the In_Progress Operations of the previous leader", "released", released)
+
+	return nil
+}
+
+func (h *OperationHandoff) releaseOperationsOfPreviousLeader(ctx context.Context) (int, error) {
+
+	log := log.FromContext(ctx)
+
+	// Find the GitOpsEngineCluster that this cluster-agent is running on, by kube-system namespace UID
+	kubeSystemNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}}
+	if err := h.Client.Get(ctx, client.ObjectKeyFromObject(kubeSystemNamespace), kubeSystemNamespace); err != nil {
+		return 0, fmt.Errorf("unable to retrieve kube-system namespace: %v", err)
+	}
+
+	gitopsEngineCluster, err := dbutil.GetGitopsEngineClusterByKubeSystemNamespaceUID(ctx, string(kubeSystemNamespace.UID), h.DB, log)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve GitOpsEngineCluster: %v", err)
+	} else if gitopsEngineCluster == nil {
+		// No Operations can target this cluster yet, so there are none to release
+		return 0, nil
+	}
+
+	return h.DB.ReleaseInProgressOperationsOfGitopsEngineCluster(ctx, gitopsEngineCluster.Gitopsenginecluster_id, h.ProcessingOwner)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Operation Handoff", func() {
+	Context("Release the Operations of the previous leader", func() {
+		var (
+			ctx      context.Context
+			dbq      db.AllDatabaseQueries
+			handoff  *OperationHandoff
+			instance *db.GitopsEngineInstance
+			user     *db.ClusterUser
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			log := logger.FromContext(ctx)
+
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).To(BeNil())
+
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).To(BeNil())
+
+			scheme, argoCDNamespace, kubesystemNamespace, _, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubesystemNamespace).Build()
+
+			gitopsEngineCluster, _, err := util.GetOrCreateGitopsEngineClusterByKubeSystemNamespaceUID(ctx, string(kubesystemNamespace.UID), dbq, log)
+			Expect(err).To(BeNil())
+
+			instance = &db.GitopsEngineInstance{
+				Gitopsengineinstance_id: "test-fake-engine-instance",
+				Namespace_name:          argoCDNamespace.Name,
+				Namespace_uid:           string(argoCDNamespace.UID),
+				EngineCluster_id:        gitopsEngineCluster.Gitopsenginecluster_id,
+			}
+			Expect(dbq.CreateGitopsEngineInstance(ctx, instance)).To(Succeed())
+
+			user = &db.ClusterUser{
+				Clusteruser_id: "test-user-id",
+				User_name:      "test-user",
+			}
+			Expect(dbq.CreateClusterUser(ctx, user)).To(Succeed())
+
+			handoff = &OperationHandoff{
+				Client:          k8sClient,
+				DB:              dbq,
+				ProcessingOwner: NewProcessingOwnerIdentity(),
+			}
+		})
+
+		AfterEach(func() {
+			dbq.CloseDatabase()
+		})
+
+		It("should return the In_Progress Operations of the previous leader to Waiting, on becoming the leader", func() {
+			By("creating an Operation that the previous leader was processing")
+			operation := db.Operation{
+				Operation_id:            "test-operation-1",
+				Instance_id:             instance.Gitopsengineinstance_id,
+				Resource_id:             "test-fake-resource-id",
+				Resource_type:           "GitopsEngineInstance",
+				Operation_owner_user_id: user.Clusteruser_id,
+			}
+			Expect(dbq.CreateOperation(ctx, &operation, operation.Operation_owner_user_id)).To(Succeed())
+
+			operation.State = db.OperationState_In_Progress
+			operation.Processing_owner = NewProcessingOwnerIdentity()
+			Expect(dbq.UpdateOperation(ctx, &operation)).To(Succeed())
+
+			Expect(handoff.Start(ctx)).To(Succeed())
+
+			Expect(dbq.GetOperationById(ctx, &operation)).To(Succeed())
+			Expect(operation.State).To(Equal(db.OperationState_Waiting))
+			Expect(operation.Processing_owner).To(BeEmpty())
+		})
+
+		It("should return the In_Progress Operations of this replica to Waiting with a resume token, on shutdown", func() {
+			By("creating an Operation that this replica is processing")
+			operation := db.Operation{
+				Operation_id:            "test-operation-1",
+				Instance_id:             instance.Gitopsengineinstance_id,
+				Resource_id:             "test-fake-resource-id",
+				Resource_type:           "GitopsEngineInstance",
+				Operation_owner_user_id: user.Clusteruser_id,
+			}
+			Expect(dbq.CreateOperation(ctx, &operation, operation.Operation_owner_user_id)).To(Succeed())
+
+			operation.State = db.OperationState_In_Progress
+			operation.Processing_owner = handoff.ProcessingOwner
+			Expect(dbq.UpdateOperation(ctx, &operation)).To(Succeed())
+
+			Expect(handoff.CheckpointOperations(ctx)).To(Succeed())
+
+			Expect(dbq.GetOperationById(ctx, &operation)).To(Succeed())
+			Expect(operation.State).To(Equal(db.OperationState_Waiting))
+			Expect(operation.Processing_owner).To(BeEmpty())
+			Expect(operation.Resume_token).To(HavePrefix(handoff.ProcessingOwner + "_"))
+		})
+	})
+})
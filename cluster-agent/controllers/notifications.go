@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"reflect"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+	goyaml "gopkg.in/yaml.v2"
+)
+
+// GetNotificationAnnotationsFromSpecField returns the Argo CD Notifications subscription annotations (along with the Argo CD
+// sync wave annotation) that the backend has stored in the metadata of the spec field of an Application row.
+//
+// The spec field is generated by the backend from a fauxargocd.FauxApplication (using yaml.v2), so we must use the same
+// types to read the metadata back: the metadata is not read when unmarshalling into an appv1.Application.
+func GetNotificationAnnotationsFromSpecField(specField string) (map[string]string, error) {
+
+	fauxApplication := fauxargocd.FauxApplication{}
+	if err := goyaml.Unmarshal([]byte(specField), &fauxApplication); err != nil {
+		return nil, err
+	}
+
+	res := map[string]string{}
+	for key, value := range fauxApplication.FauxObjectMeta.Annotations {
+		if argosharedutil.IsManagedArgoCDApplicationAnnotation(key) {
+			res[key] = value
+		}
+	}
+
+	return res, nil
+}
+
+// ReconcileNotificationAnnotations ensures that the Argo CD Notifications subscription annotations (along with the Argo CD
+// sync wave annotation) of the Argo CD Application match the expected annotations. Other annotations are left untouched.
+//
+// Returns true if the Application was modified, false otherwise.
+func ReconcileNotificationAnnotations(app *appv1.Application, expectedAnnotations map[string]string) bool {
+
+	existingAnnotations := map[string]string{}
+	for key, value := range app.Annotations {
+		if argosharedutil.IsManagedArgoCDApplicationAnnotation(key) {
+			existingAnnotations[key] = value
+		}
+	}
+
+	if expectedAnnotations == nil {
+		expectedAnnotations = map[string]string{}
+	}
+
+	if reflect.DeepEqual(existingAnnotations, expectedAnnotations) {
+		return false
+	}
+
+	for key := range existingAnnotations {
+		delete(app.Annotations, key)
+	}
+
+	if len(expectedAnnotations) > 0 && app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+
+	for key, value := range expectedAnnotations {
+		app.Annotations[key] = value
+	}
+
+	return true
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+	goyaml "gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Tests for the Argo CD Notifications utility functions in cluster-agent/controllers", func() {
+
+	Context("Testing GetNotificationAnnotationsFromSpecField", func() {
+
+		It("should only return the managed annotations of the spec field", func() {
+			fauxApplication := fauxargocd.FauxApplication{
+				FauxObjectMeta: fauxargocd.FauxObjectMeta{
+					Name: "my-app",
+					Annotations: map[string]string{
+						"notifications.argoproj.io/subscribe.on-sync-failed.slack": "my-channel",
+						"argocd.argoproj.io/sync-wave":                             "1",
+						"some-other-annotation":                                    "value",
+					},
+				},
+			}
+			specField, err := goyaml.Marshal(fauxApplication)
+			Expect(err).To(BeNil())
+
+			res, err := GetNotificationAnnotationsFromSpecField(string(specField))
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(map[string]string{
+				"notifications.argoproj.io/subscribe.on-sync-failed.slack": "my-channel",
+				"argocd.argoproj.io/sync-wave":                             "1",
+			}))
+		})
+
+		It("should return an empty map when the spec field has no annotations", func() {
+			specField, err := goyaml.Marshal(fauxargocd.FauxApplication{})
+			Expect(err).To(BeNil())
+
+			res, err := GetNotificationAnnotationsFromSpecField(string(specField))
+			Expect(err).To(BeNil())
+			Expect(res).To(BeEmpty())
+		})
+	})
+
+	Context("Testing ReconcileNotificationAnnotations", func() {
+
+		It("should add, update and remove only the managed annotations", func() {
+			app := &appv1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"argocd.argoproj.io/refresh":                                   "normal",
+						"notifications.argoproj.io/subscribe.on-sync-failed.slack":     "old-channel",
+						"notifications.argoproj.io/subscribe.on-health-degraded.slack": "old-channel",
+					},
+				},
+			}
+
+			modified := ReconcileNotificationAnnotations(app, map[string]string{
+				"notifications.argoproj.io/subscribe.on-sync-failed.slack": "new-channel",
+			})
+			Expect(modified).To(BeTrue())
+			Expect(app.Annotations).To(Equal(map[string]string{
+				"argocd.argoproj.io/refresh":                               "normal",
+				"notifications.argoproj.io/subscribe.on-sync-failed.slack": "new-channel",
+			}))
+
+			By("calling it again with the same annotations, which should not modify the Application")
+			Expect(ReconcileNotificationAnnotations(app, map[string]string{
+				"notifications.argoproj.io/subscribe.on-sync-failed.slack": "new-channel",
+			})).To(BeFalse())
+		})
+
+		It("should not modify an Application without annotations, when none are expected", func() {
+			app := &appv1.Application{}
+			Expect(ReconcileNotificationAnnotations(app, nil)).To(BeFalse())
+			Expect(app.Annotations).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	argocdoperator "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Resource health checks:
+//
+// A GitOpsDeployment may define custom health checks (in Lua) for the custom resources it deploys, via
+// .spec.resourceHealthChecks. The backend stores these in the ResourceHealthChecksAnnotation of the Argo CD Application.
+//
+// Argo CD only supports health checks at the level of the Argo CD instance, so the cluster-agent merges the health checks
+// of all the Argo CD Applications of an Argo CD namespace into the .spec.resourceHealthChecks of its ArgoCD CR:
+// - If multiple Applications define a health check for the same group/kind, the health check of the oldest Application
+//   is used, and the conflicting health checks are logged.
+// - Health checks that were not added by the cluster-agent (for example, by an administrator) are never modified: a
+//   health check of an Application for the same group/kind is ignored.
+// - The group/kinds added by the cluster-agent are tracked via the ManagedResourceHealthChecksAnnotation of the ArgoCD CR,
+//   so that they can be removed once no Application defines them.
+
+// ManagedResourceHealthChecksAnnotation is set on the ArgoCD CR, and contains the comma-separated group/kinds of the
+// health checks of .spec.resourceHealthChecks that were added by the cluster-agent.
+const ManagedResourceHealthChecksAnnotation = "managed-gitops.redhat.com/managed-resource-health-checks"
+
+// ReconcileResourceHealthChecks ensures that the .spec.resourceHealthChecks of the ArgoCD CR(s) in the Argo CD namespace
+// contain the health checks of the Argo CD Applications (generated by the GitOps Service) in that namespace.
+func ReconcileResourceHealthChecks(ctx context.Context, k8sClient client.Client, argoCDNamespace string, log logr.Logger) error {
+
+	argoCDList := argocdoperator.ArgoCDList{}
+	if err := k8sClient.List(ctx, &argoCDList, client.InNamespace(argoCDNamespace)); err != nil {
+		return fmt.Errorf("unable to list ArgoCD CRs in namespace '%s': %v", argoCDNamespace, err)
+	}
+
+	if len(argoCDList.Items) == 0 {
+		log.V(logutil.LogLevel_Debug).Info("No ArgoCD CR found in namespace, so resource health checks were not reconciled",
+			"namespace", argoCDNamespace)
+		return nil
+	}
+
+	argoApplicationList := appv1.ApplicationList{}
+	if err := k8sClient.List(ctx, &argoApplicationList, client.InNamespace(argoCDNamespace),
+		client.HasLabels{ArgoCDApplicationDatabaseIDLabel}); err != nil {
+		return fmt.Errorf("unable to list Argo CD Applications in namespace '%s': %v", argoCDNamespace, err)
+	}
+
+	expectedHealthChecks := mergeResourceHealthChecksOfApplications(argoApplicationList.Items, log)
+
+	for idx := range argoCDList.Items {
+		argoCD := argoCDList.Items[idx] // To avoid "Implicit memory aliasing in for loop." error.
+
+		if argoCD.DeletionTimestamp != nil {
+			continue
+		}
+
+		if !reconcileArgoCDResourceHealthChecks(&argoCD, expectedHealthChecks, log) {
+			continue
+		}
+
+		if err := k8sClient.Update(ctx, &argoCD); err != nil {
+			return fmt.Errorf("unable to update resource health checks of ArgoCD CR '%s': %v", argoCD.Name, err)
+		}
+		logutil.LogAPIResourceChangeEvent(argoCD.Namespace, argoCD.Name, argoCD, logutil.ResourceModified, log)
+	}
+
+	return nil
+}
+
+// mergeResourceHealthChecksOfApplications returns the health checks of the Argo CD Applications, by group/kind. If
+// multiple Applications define a health check for the same group/kind, that of the oldest Application is returned.
+func mergeResourceHealthChecksOfApplications(applications []appv1.Application, log logr.Logger) map[string]argosharedutil.ResourceHealthCheck {
+
+	sortedApplications := append([]appv1.Application{}, applications...)
+	sort.SliceStable(sortedApplications, func(i, j int) bool {
+		if !sortedApplications[i].CreationTimestamp.Equal(&sortedApplications[j].CreationTimestamp) {
+			return sortedApplications[i].CreationTimestamp.Before(&sortedApplications[j].CreationTimestamp)
+		}
+		return sortedApplications[i].Name < sortedApplications[j].Name
+	})
+
+	res := map[string]argosharedutil.ResourceHealthCheck{}
+	definedBy := map[string]string{}
+
+	for _, app := range sortedApplications {
+
+		if app.DeletionTimestamp != nil {
+			continue
+		}
+
+		healthChecks, err := argosharedutil.GetResourceHealthChecksFromAnnotations(app.Annotations)
+		if err != nil {
+			log.Error(err, "unable to read resource health checks of Argo CD Application", "name", app.Name, "namespace", app.Namespace)
+			continue
+		}
+
+		for _, healthCheck := range healthChecks {
+			groupKind := healthCheck.GroupKind()
+
+			existing, exists := res[groupKind]
+			if !exists {
+				res[groupKind] = healthCheck
+				definedBy[groupKind] = app.Name
+				continue
+			}
+
+			if existing.Check != healthCheck.Check {
+				log.Info("Ignoring resource health check of Argo CD Application, which conflicts with that of an older Application",
+					"name", app.Name, "namespace", app.Namespace, "groupKind", groupKind, "definedBy", definedBy[groupKind])
+			}
+		}
+	}
+
+	return res
+}
+
+// reconcileArgoCDResourceHealthChecks updates the .spec.resourceHealthChecks of the ArgoCD CR to contain the expected
+// health checks, without modifying the health checks that were not added by the cluster-agent.
+//
+// Returns true if the ArgoCD CR was modified, false otherwise.
+func reconcileArgoCDResourceHealthChecks(argoCD *argocdoperator.ArgoCD,
+	expectedHealthChecks map[string]argosharedutil.ResourceHealthCheck, log logr.Logger) bool {
+
+	previouslyManaged := map[string]bool{}
+	for _, groupKind := range strings.Split(argoCD.Annotations[ManagedResourceHealthChecksAnnotation], ",") {
+		if groupKind != "" {
+			previouslyManaged[groupKind] = true
+		}
+	}
+
+	newHealthChecks := []argocdoperator.ResourceHealthCheck{}
+	unmanagedGroupKinds := map[string]bool{}
+
+	// Keep the health checks that were not added by the cluster-agent
+	for _, healthCheck := range argoCD.Spec.ResourceHealthChecks {
+		groupKind := argosharedutil.ResourceHealthCheck{Group: healthCheck.Group, Kind: healthCheck.Kind}.GroupKind()
+		if !previouslyManaged[groupKind] {
+			newHealthChecks = append(newHealthChecks, healthCheck)
+			unmanagedGroupKinds[groupKind] = true
+		}
+	}
+
+	expectedGroupKinds := []string{}
+	for groupKind := range expectedHealthChecks {
+		expectedGroupKinds = append(expectedGroupKinds, groupKind)
+	}
+	sort.Strings(expectedGroupKinds)
+
+	managedGroupKinds := []string{}
+	for _, groupKind := range expectedGroupKinds {
+
+		if unmanagedGroupKinds[groupKind] {
+			log.Info("Ignoring resource health check of Argo CD Applications, as the ArgoCD CR already defines a health check for it",
+				"argoCD", argoCD.Name, "namespace", argoCD.Namespace, "groupKind", groupKind)
+			continue
+		}
+
+		healthCheck := expectedHealthChecks[groupKind]
+		newHealthChecks = append(newHealthChecks, argocdoperator.ResourceHealthCheck{
+			Group: healthCheck.Group,
+			Kind:  healthCheck.Kind,
+			Check: healthCheck.Check,
+		})
+		managedGroupKinds = append(managedGroupKinds, groupKind)
+	}
+
+	if len(newHealthChecks) == 0 {
+		newHealthChecks = nil
+	}
+
+	newManagedAnnotation := strings.Join(managedGroupKinds, ",")
+
+	if reflect.DeepEqual(argoCD.Spec.ResourceHealthChecks, newHealthChecks) &&
+		argoCD.Annotations[ManagedResourceHealthChecksAnnotation] == newManagedAnnotation {
+		return false
+	}
+
+	argoCD.Spec.ResourceHealthChecks = newHealthChecks
+
+	if newManagedAnnotation == "" {
+		delete(argoCD.Annotations, ManagedResourceHealthChecksAnnotation)
+	} else {
+		if argoCD.Annotations == nil {
+			argoCD.Annotations = map[string]string{}
+		}
+		argoCD.Annotations[ManagedResourceHealthChecksAnnotation] = newManagedAnnotation
+	}
+
+	return true
+}
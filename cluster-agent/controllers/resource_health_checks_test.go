@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	argocdoperator "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Tests for the resource health checks of Argo CD instances", func() {
+
+	Context("Testing ReconcileResourceHealthChecks", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+		var logger logr.Logger
+		var argoCD *argocdoperator.ArgoCD
+		var argoCDNamespace string
+
+		widgetCheck := argosharedutil.ResourceHealthCheck{Group: "example.com", Kind: "Widget", Check: "return {status = 'Healthy'}"}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			logger = log.FromContext(ctx)
+
+			scheme, argocdNamespace, kubesystemNamespace, workspace, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+
+			Expect(appv1.AddToScheme(scheme)).To(Succeed())
+			Expect(argocdoperator.AddToScheme(scheme)).To(Succeed())
+
+			argoCDNamespace = argocdNamespace.Name
+
+			argoCD = &argocdoperator.ArgoCD{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gitops-service-argocd",
+					Namespace: argoCDNamespace,
+				},
+				Spec: argocdoperator.ArgoCDSpec{
+					ResourceHealthChecks: []argocdoperator.ResourceHealthCheck{
+						{Group: "admin.example.com", Kind: "Gadget", Check: "admin-defined"},
+					},
+				},
+			}
+
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(workspace, argocdNamespace, kubesystemNamespace, argoCD).Build()
+		})
+
+		createApplication := func(name string, creationTimestamp time.Time, healthChecks ...argosharedutil.ResourceHealthCheck) *appv1.Application {
+			annotations, err := argosharedutil.SetResourceHealthChecksAnnotation(nil, healthChecks)
+			Expect(err).To(BeNil())
+
+			app := &appv1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         argoCDNamespace,
+					Labels:            map[string]string{ArgoCDApplicationDatabaseIDLabel: name},
+					Annotations:       annotations,
+					CreationTimestamp: metav1.NewTime(creationTimestamp),
+				},
+			}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			return app
+		}
+
+		It("should add the health checks of the Applications, preferring the oldest Application on conflict", func() {
+
+			now := time.Now()
+			createApplication("newer-app", now, argosharedutil.ResourceHealthCheck{
+				Group: "example.com", Kind: "Widget", Check: "conflicting",
+			})
+			createApplication("older-app", now.Add(-time.Hour), widgetCheck)
+			createApplication("no-health-checks", now)
+
+			Expect(ReconcileResourceHealthChecks(ctx, k8sClient, argoCDNamespace, logger)).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(argoCD), argoCD)).To(Succeed())
+			Expect(argoCD.Spec.ResourceHealthChecks).To(Equal([]argocdoperator.ResourceHealthCheck{
+				{Group: "admin.example.com", Kind: "Gadget", Check: "admin-defined"},
+				{Group: "example.com", Kind: "Widget", Check: widgetCheck.Check},
+			}))
+			Expect(argoCD.Annotations[ManagedResourceHealthChecksAnnotation]).To(Equal("example.com_Widget"))
+		})
+
+		It("should not override health checks that were not added by the cluster-agent", func() {
+
+			createApplication("my-app", time.Now(), argosharedutil.ResourceHealthCheck{
+				Group: "admin.example.com", Kind: "Gadget", Check: "from-application",
+			})
+
+			Expect(ReconcileResourceHealthChecks(ctx, k8sClient, argoCDNamespace, logger)).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(argoCD), argoCD)).To(Succeed())
+			Expect(argoCD.Spec.ResourceHealthChecks).To(Equal([]argocdoperator.ResourceHealthCheck{
+				{Group: "admin.example.com", Kind: "Gadget", Check: "admin-defined"},
+			}))
+			Expect(argoCD.Annotations).ToNot(HaveKey(ManagedResourceHealthChecksAnnotation))
+		})
+
+		It("should remove the health checks that are no longer defined by any Application", func() {
+
+			app := createApplication("my-app", time.Now(), widgetCheck)
+
+			Expect(ReconcileResourceHealthChecks(ctx, k8sClient, argoCDNamespace, logger)).To(Succeed())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(argoCD), argoCD)).To(Succeed())
+			Expect(argoCD.Spec.ResourceHealthChecks).To(HaveLen(2))
+
+			Expect(k8sClient.Delete(ctx, app)).To(Succeed())
+
+			Expect(ReconcileResourceHealthChecks(ctx, k8sClient, argoCDNamespace, logger)).To(Succeed())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(argoCD), argoCD)).To(Succeed())
+			Expect(argoCD.Spec.ResourceHealthChecks).To(Equal([]argocdoperator.ResourceHealthCheck{
+				{Group: "admin.example.com", Kind: "Gadget", Check: "admin-defined"},
+			}))
+			Expect(argoCD.Annotations).ToNot(HaveKey(ManagedResourceHealthChecksAnnotation))
+		})
+
+		It("should do nothing if there is no ArgoCD CR in the namespace", func() {
+
+			Expect(k8sClient.Delete(ctx, argoCD)).To(Succeed())
+			createApplication("my-app", time.Now(), widgetCheck)
+
+			Expect(ReconcileResourceHealthChecks(ctx, k8sClient, argoCDNamespace, logger)).To(Succeed())
+		})
+	})
+})
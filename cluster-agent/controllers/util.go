@@ -9,6 +9,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,14 +22,10 @@ const (
 	WaitForArgoCDToPerformNonfinalizerDeleteTimeout = time.Minute * 2
 )
 
-const (
-	argoCDResourcesFinalizer = "resources-finalizer.argocd.argoproj.io/background"
-)
-
 const (
 	// ArgoCDApplicationDatabaseIDLabel is the label that is added to Argo CD Applications generated by cluster-agent's Operation controller
 	ArgoCDClusterSecretDatabaseIDLabel = "databaseID"
-	ArgoCDApplicationDatabaseIDLabel   = "databaseID"
+	ArgoCDApplicationDatabaseIDLabel   = argosharedutil.ArgoCDApplicationDatabaseIDLabel
 	RepoCredDatabaseIDLabel            = "databaseID"
 )
 
@@ -67,18 +64,13 @@ func DeleteArgoCDApplication(ctx context.Context, appFromList appv1.Application,
 
 	if app.DeletionTimestamp == nil {
 
-		// Ensure finalizer is set
+		// Ensure the resources finalizer of the deletion policy of the Application is set (and no other)
 		{
-			containsFinalizer := false
-			for _, finalizer := range app.Finalizers {
-				if finalizer == argoCDResourcesFinalizer {
-					containsFinalizer = true
-					break
-				}
-			}
+			finalizers := argosharedutil.SetResourcesFinalizer(app.Finalizers,
+				argosharedutil.GetResourcesFinalizerOfDeletionPolicy(app.Annotations))
 
-			if !containsFinalizer {
-				app.Finalizers = append(app.Finalizers, argoCDResourcesFinalizer)
+			if !reflect.DeepEqual(finalizers, app.Finalizers) {
+				app.Finalizers = finalizers
 				if err := eventClient.Update(ctx, app); err != nil {
 					log.Error(err, "unable to update application with finalizer")
 					return err
@@ -197,21 +189,11 @@ func DeleteArgoCDApplication(ctx context.Context, appFromList appv1.Application,
 
 // CompareApplication compares an Argo CD Application and the spec field of a DB Application row, returning "" if the same,
 // otherwise returning the specific difference.
+//
+// The fields are compared once normalized (see argosharedutil.AreApplicationFieldsEquivalent), so that fields which are
+// set to their default value in one, and omitted from the other, are not reported as a difference.
 func CompareApplication(argoCDApp appv1.Application, dbApplication db.Application, log logr.Logger) (string, error) {
 
-	// reflect.DeepEqual will treat empty slices differently depending on how they are defined, so we ensure that
-	// in every case, an empty slice is defined as a appv1.SyncOptions{}
-	sanitizeApp := func(input appv1.Application) appv1.Application {
-		if input.Spec.SyncPolicy != nil {
-
-			if len(input.Spec.SyncPolicy.SyncOptions) == 0 {
-				input.Spec.SyncPolicy.SyncOptions = appv1.SyncOptions{}
-			}
-		}
-		return input
-	}
-	argoCDApp = sanitizeApp(*argoCDApp.DeepCopy())
-
 	specFieldAppFromDB := appv1.Application{}
 
 	if err := yaml.Unmarshal([]byte(dbApplication.Spec_field), &specFieldAppFromDB); err != nil {
@@ -221,19 +203,27 @@ func CompareApplication(argoCDApp appv1.Application, dbApplication db.Applicatio
 		return "", nil
 	}
 
-	specFieldAppFromDB = sanitizeApp(specFieldAppFromDB)
-
-	var specDiff string
-	if !reflect.DeepEqual(specFieldAppFromDB.Spec.Source, argoCDApp.Spec.Source) {
-		specDiff = "spec.source fields differ"
-	} else if !reflect.DeepEqual(specFieldAppFromDB.Spec.Destination, argoCDApp.Spec.Destination) {
-		specDiff = "spec.destination fields differ"
-	} else if specFieldAppFromDB.Spec.Project != argoCDApp.Spec.Project {
-		specDiff = "spec project fields differ"
-	} else if !reflect.DeepEqual(specFieldAppFromDB.Spec.SyncPolicy, argoCDApp.Spec.SyncPolicy) {
-		specDiff = "sync policy fields differ"
+	fieldsToCompare := []struct {
+		fromDB   interface{}
+		fromArgo interface{}
+		specDiff string
+	}{
+		{specFieldAppFromDB.Spec.Source, argoCDApp.Spec.Source, "spec.source fields differ"},
+		{specFieldAppFromDB.Spec.Destination, argoCDApp.Spec.Destination, "spec.destination fields differ"},
+		{specFieldAppFromDB.Spec.Project, argoCDApp.Spec.Project, "spec project fields differ"},
+		{specFieldAppFromDB.Spec.SyncPolicy, argoCDApp.Spec.SyncPolicy, "sync policy fields differ"},
+	}
+
+	for _, field := range fieldsToCompare {
+		equivalent, err := argosharedutil.AreApplicationFieldsEquivalent(field.fromDB, field.fromArgo)
+		if err != nil {
+			return "", err
+		}
+		if !equivalent {
+			return field.specDiff, nil
+		}
 	}
 
-	return specDiff, nil
+	return "", nil
 
 }
@@ -7,10 +7,13 @@ import (
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,7 +47,7 @@ var _ = Describe("Tests for the small number of utility functions in cluster-age
 				// Wait for the finalizer to be added by 'DeleteArgoCDApplication'
 				finalizerFound := false
 				for _, finalizer := range goApplication.Finalizers {
-					if finalizer == argoCDResourcesFinalizer {
+					if finalizer == argosharedutil.ArgoCDResourcesFinalizer {
 						finalizerFound = true
 					}
 				}
@@ -166,7 +169,7 @@ var _ = Describe("Tests for the small number of utility functions in cluster-age
 						ArgoCDApplicationDatabaseIDLabel: "test-my-database-id-label",
 					},
 					Finalizers: []string{
-						argoCDResourcesFinalizer,
+						argosharedutil.ArgoCDResourcesFinalizer,
 					},
 				},
 			}
@@ -188,6 +191,35 @@ var _ = Describe("Tests for the small number of utility functions in cluster-age
 
 		})
 
+		It("should delete an Argo CD Application with the Orphan deletion policy, without a resources finalizer", func() {
+
+			By("creating an Argo CD Application with the Orphan deletion policy, and a resources finalizer")
+			application := appv1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-name",
+					Namespace: "my-namespace",
+					Labels: map[string]string{
+						ArgoCDApplicationDatabaseIDLabel: "test-my-database-id-label",
+					},
+					Annotations: map[string]string{
+						argosharedutil.DeletionPolicyAnnotation: string(managedgitopsv1alpha1.GitOpsDeploymentDeletionPolicy_Orphan),
+					},
+					Finalizers: []string{
+						argosharedutil.ArgoCDResourcesFinalizer,
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, &application)
+			Expect(err).To(BeNil())
+
+			By("calling the DeleteArgoCDApplication function: the finalizer should be removed, so no simulation of Argo CD is required")
+			err = DeleteArgoCDApplication(ctx, application, k8sClient, logger)
+			Expect(err).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&application), &application)
+			Expect(apierr.IsNotFound(err)).To(BeTrue(), "Application should not exist: it should have been deleted")
+		})
+
 	})
 
 	Context("Testing for CompareApplications function.", func() {
@@ -439,6 +471,38 @@ var _ = Describe("Tests for the small number of utility functions in cluster-age
 
 			Expect(result).To(BeEmpty())
 		})
+
+		It("Should not report a difference, if fields are set to their default value in one application, and omitted from the other.", func() {
+
+			appDB, _, appArgo, err := createDummyApplicationData()
+			Expect(err).To(BeNil())
+
+			var ctx context.Context
+			log := log.FromContext(ctx)
+
+			By("setting fields of the Argo CD Application to their default values, which are omitted from the DB entry")
+			appDB.Spec.Source.Helm = &fauxargocd.ApplicationSourceHelm{ValueFiles: []string{"values.yaml"}}
+			appArgo.Spec.Source.Helm = &appv1.ApplicationSourceHelm{
+				ValueFiles: []string{"values.yaml"},
+				Parameters: []appv1.HelmParameter{},
+				Values:     "",
+			}
+			appArgo.Spec.SyncPolicy.SyncOptions = appv1.SyncOptions{}
+
+			bytes, err := yaml.Marshal(&appDB)
+			Expect(err).To(BeNil())
+
+			result, err := CompareApplication(appArgo, db.Application{Spec_field: string(bytes)}, log)
+			Expect(err).To(BeNil())
+			Expect(result).To(BeEmpty())
+
+			By("setting a field of the Argo CD Application to a non-default value")
+			appArgo.Spec.SyncPolicy.Retry = &appv1.RetryStrategy{Limit: 3}
+
+			result, err = CompareApplication(appArgo, db.Application{Spec_field: string(bytes)}, log)
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal("sync policy fields differ"))
+		})
 	})
 
 })
@@ -17,6 +17,7 @@ import (
 	"context"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -25,21 +26,24 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/health"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	argoprojiocontrollers "github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers/argoproj.io"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers/argoproj.io/application_info_cache"
 	controllers "github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers/managed-gitops"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers/managed-gitops/eventloop"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics"
 	argocdmetrics "github.com/redhat-appstudio/managed-gitops/cluster-agent/metrics/argocd"
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/utils"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	crzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
 	//+kubebuilder:scaffold:imports
@@ -50,6 +54,14 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+// The leader election lease is kept short, so that if the leader dies, another replica takes over (and processes its
+// Operations: see OperationHandoff) quickly.
+const (
+	leaderElectionLeaseDuration = 8 * time.Second
+	leaderElectionRenewDeadline = 5 * time.Second
+	leaderElectionRetryPeriod   = 1 * time.Second
+)
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(argocdoperator.AddToScheme(scheme))
@@ -72,17 +84,17 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&profilerAddr, "profiler-address", ":6061", "The address for serving pprof profiles")
-	opts := crzap.Options{
-		TimeEncoder: zapcore.ISO8601TimeEncoder,
-		ZapOpts: []zap.Option{
-			zap.WithCaller(true),
-		},
-	}
+	opts := logutil.NewJSONLoggerOptions()
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(crzap.New(crzap.UseFlagOptions(&opts)))
 
+	if err := logutil.ConfigureAuditSinksFromEnv(setupLog); err != nil {
+		setupLog.Error(err, "unable to configure audit sinks")
+		os.Exit(1)
+	}
+
 	if sharedutil.IsProfilingEnabled() {
 		setupLog.Info("Starting pprof profiler server", "address", profilerAddr)
 		go sharedutil.StartProfilers(profilerAddr)
@@ -99,9 +111,16 @@ func main() {
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
+		HealthProbeBindAddress: "0", // served by healthServer, below
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "11d017ea.redhat.com",
+		// Release the lease on shutdown, so that the next leader doesn't need to wait for it to expire
+		LeaderElectionReleaseOnCancel: true,
+		LeaseDuration:                 durationPtr(leaderElectionLeaseDuration),
+		RenewDeadline:                 durationPtr(leaderElectionRenewDeadline),
+		RetryPeriod:                   durationPtr(leaderElectionRetryPeriod),
+		// Allow in-flight Operations to be drained (and checkpointed) on shutdown
+		GracefulShutdownTimeout: sharedutil.ManagerGracefulShutdownTimeout(setupLog),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -114,24 +133,67 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Fail fast if the schema of the database does not match the database types, rather than failing on each query
+	if err := db.VerifyDatabaseSchema(context.Background(), dbQueries); err != nil {
+		setupLog.Error(err, "Fatal Error: database schema drift")
+		os.Exit(1)
+	}
+
+	// The identity of this replica, which is recorded on the Operations that it processes
+	processingOwner := controllers.NewProcessingOwnerIdentity()
+	setupLog.Info("cluster-agent replica identity", "processingOwner", processingOwner)
+
+	operationEventLoop := eventloop.NewOperationEventLoop(processingOwner)
+
 	if err = (&controllers.OperationReconciler{
 		Client:              mgr.GetClient(),
 		Scheme:              mgr.GetScheme(),
-		ControllerEventLoop: eventloop.NewOperationEventLoop(),
+		ControllerEventLoop: operationEventLoop,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Operation")
 		os.Exit(1)
 	}
 
+	// On becoming the leader, return the Operations that the previous leader was processing to Waiting
+	operationHandoff := &controllers.OperationHandoff{
+		Client:          mgr.GetClient(),
+		DB:              dbQueries,
+		ProcessingOwner: processingOwner,
+	}
+	if err := mgr.Add(operationHandoff); err != nil {
+		setupLog.Error(err, "unable to set up operation handoff")
+		os.Exit(1)
+	}
+
+	// On shutdown, stop processing new Operations, wait for the Operations in progress to complete, and checkpoint
+	// those that did not complete in time, so that they are resumed by the next leader.
+	if err := mgr.Add(&sharedutil.ShutdownDrainer{
+		Name:       "operation-event-loop",
+		Drain:      operationEventLoop.Drain,
+		Checkpoint: operationHandoff.CheckpointOperations,
+		Log:        setupLog,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up graceful shutdown")
+		os.Exit(1)
+	}
+
 	operationsGC := controllers.NewGarbageCollector(dbQueries, mgr.GetClient())
-	operationsGC.StartGarbageCollector()
+
+	var resourceEstimator utils.ResourceEstimator
+	if utils.IsResourceEstimationEnabled() {
+		resourceEstimator = utils.NewResourceEstimator(utils.NewCredentialService(nil, false))
+	}
 
 	if err = (&argoprojiocontrollers.ApplicationReconciler{
 		Client:                mgr.GetClient(),
 		Scheme:                mgr.GetScheme(),
 		DB:                    dbQueries,
 		DeletionTaskRetryLoop: sharedutil.NewTaskRetryLoop("application-reconciler"),
-		Cache:                 application_info_cache.NewApplicationInfoCache(),
+		Cache:                 application_info_cache.NewApplicationInfoCacheWithBatchedWrites(application_info_cache.ApplicationStateFlushInterval(setupLog)),
+		RevisionResolver:      utils.NewRevisionResolver(),
+		DiffPreviewer:         utils.NewDiffPreviewer(utils.NewCredentialService(nil, false)),
+		ResourceEstimator:     resourceEstimator,
+		HookLogRetriever:      utils.NewHookLogRetriever(utils.NewCredentialService(nil, false)),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Application")
 		os.Exit(1)
@@ -153,8 +215,31 @@ func main() {
 		Client: mgr.GetClient(),
 	}
 
-	// Trigger goroutine for workSpace/NameSpace reconciler
-	namespacesReconciler.StartNamespaceReconciler()
+	// The garbage collector and reconcilers modify the database and Argo CD, so they are only started on the leader
+	if err := mgr.Add(manager.RunnableFunc(func(context.Context) error {
+		operationsGC.StartGarbageCollector()
+
+		// Trigger goroutine for workSpace/NameSpace reconciler
+		namespacesReconciler.StartNamespaceReconciler()
+
+		// Trigger goroutine to revert out-of-band changes to Argo CD Applications
+		namespacesReconciler.StartApplicationSelfHeal()
+
+		// Trigger goroutine to detect the version of Argo CD, and record it in the GitopsEngineInstance
+		namespacesReconciler.StartArgoCDVersionCheck()
+
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to set up leader-only reconcilers")
+		os.Exit(1)
+	}
+
+	// Reconcile the Argo CD Applications with the database once the manager has started, to recover from any Operations
+	// that were missed while the cluster-agent was not running.
+	if err := mgr.Add(manager.RunnableFunc(namespacesReconciler.RunStartupReconciliation)); err != nil {
+		setupLog.Error(err, "unable to set up startup reconciliation")
+		os.Exit(1)
+	}
 
 	//==============================================
 
@@ -167,17 +252,28 @@ func main() {
 	}
 	operationCRMetricUpdater.StartOperationCRMetricUpdater()
 
+	// Trigger goroutine for counting Waiting/In_Progress operation rows, to update the operation backlog metric
+	operationBacklogMetricUpdater := eventloop.OperationBacklogMetricUpdater{
+		DB: dbQueries,
+	}
+	operationBacklogMetricUpdater.StartOperationBacklogMetricUpdater()
+
 	reconciliationMetricsUpdater := argocdmetrics.ReconciliationMetricsUpdater{
 		Client: mgr.GetClient(),
 	}
 	reconciliationMetricsUpdater.Start()
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
-	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
+	// The health probe endpoints are served by healthServer (rather than by the manager), as they report a JSON body
+	// describing the result of each check.
+	healthServer := health.NewServer(probeAddr, setupLog.WithName("health"))
+	healthServer.Healthz.AddCheck("ping", func(context.Context) error { return nil })
+	healthServer.Readyz.AddCheck("database", health.DatabaseCheck(dbQueries))
+	healthServer.Readyz.AddCheck("informer-cache", health.CacheSyncCheck(mgr.GetCache()))
+	healthServer.Readyz.AddCheck("argocd-api", health.ListCheck(mgr.GetAPIReader(),
+		func() client.ObjectList { return &appv1.ApplicationList{} },
+		client.InNamespace(dbutil.GetGitOpsEngineSingleInstanceNamespace())))
+	if err := mgr.Add(healthServer); err != nil {
+		setupLog.Error(err, "unable to set up health checks")
 		os.Exit(1)
 	}
 
@@ -187,3 +283,7 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ApplicationSelfHealCorrections = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name:        "argocd_application_self_heal_corrections",
+			Help:        "Number of Argo CD Applications that were modified outside of the GitOps Service, and reverted by the Application self-heal loop",
+			ConstLabels: map[string]string{"name": "argocd_application_self_heal_corrections"},
+		},
+	)
+
+	StartupReconciliationApplications = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_application_startup_reconciliation",
+			Help: "Number of Application rows processed by the last startup reconciliation of the cluster-agent, by result (created, updated, deleted, unchanged, failed)",
+		},
+		[]string{"result"},
+	)
+
+	StartupReconciliationDuration = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "argocd_application_startup_reconciliation_duration_seconds",
+			Help: "Time taken by the last startup reconciliation of the cluster-agent, in seconds",
+		},
+	)
+
+	ArgoCDVersionUnsupported = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_version_unsupported",
+			Help: "1 if the version of the Argo CD instance in the namespace is older than the minimum version supported by the GitOps Service, 0 otherwise",
+		},
+		[]string{"namespace", "version"},
+	)
+)
+
+// IncreaseApplicationSelfHealCorrections increments the number of Argo CD Applications that were reverted to the contents of their Application row
+func IncreaseApplicationSelfHealCorrections() {
+	ApplicationSelfHealCorrections.Inc()
+}
+
+// SetStartupReconciliationResults sets the number of Application rows processed by the startup reconciliation (by
+// result), and how long it took.
+func SetStartupReconciliationResults(results map[string]int, duration time.Duration) {
+	StartupReconciliationApplications.Reset()
+	for result, count := range results {
+		StartupReconciliationApplications.WithLabelValues(result).Set(float64(count))
+	}
+	StartupReconciliationDuration.Set(duration.Seconds())
+}
+
+// SetArgoCDVersionCheckResult records whether the version of the Argo CD instance in the namespace is supported. Only
+// the most recently detected version of the namespace is reported.
+func SetArgoCDVersionCheckResult(namespace string, version string, supported bool) {
+	ArgoCDVersionUnsupported.DeletePartialMatch(prometheus.Labels{"namespace": namespace})
+
+	value := 0.0
+	if !supported {
+		value = 1
+	}
+	ArgoCDVersionUnsupported.WithLabelValues(namespace, version).Set(value)
+}
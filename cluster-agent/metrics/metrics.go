@@ -92,7 +92,9 @@ func clearOperationMetricsCount() {
 }
 
 func init() {
-	metric.Registry.MustRegister(OperationStateCompleted, OperationStateFailed, OperationCR)
+	metric.Registry.MustRegister(OperationStateCompleted, OperationStateFailed, OperationCR, ApplicationSelfHealCorrections,
+		OperationProcessingDuration, OperationBacklog, StartupReconciliationApplications, StartupReconciliationDuration,
+		ArgoCDVersionUnsupported)
 }
 
 // TestOnly_runCollectOperationMetrics should only be called from unit tests
@@ -50,3 +50,18 @@ var _ = Describe("Test for Operation DB metrics counter", func() {
 
 	})
 })
+
+var _ = Describe("Test for Argo CD version metrics", func() {
+
+	It("should only report the most recently detected version of the Argo CD instance in a namespace", func() {
+
+		ArgoCDVersionUnsupported.Reset()
+
+		SetArgoCDVersionCheckResult("argocd", "v2.3.0", false)
+		Expect(testutil.ToFloat64(ArgoCDVersionUnsupported.WithLabelValues("argocd", "v2.3.0"))).To(Equal(float64(1)))
+
+		SetArgoCDVersionCheckResult("argocd", "v2.6.3", true)
+		Expect(testutil.CollectAndCount(ArgoCDVersionUnsupported)).To(Equal(1))
+		Expect(testutil.ToFloat64(ArgoCDVersionUnsupported.WithLabelValues("argocd", "v2.6.3"))).To(Equal(float64(0)))
+	})
+})
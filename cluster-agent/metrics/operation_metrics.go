@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 )
 
 var (
@@ -13,6 +16,26 @@ var (
 			ConstLabels: map[string]string{"name": "total_operations_CR_on_cluster"},
 		},
 	)
+
+	// OperationProcessingDuration is the time from when an Operation DB row was created, to when the cluster-agent
+	// finished processing it (either successfully or not), by the resource type of the Operation.
+	OperationProcessingDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "operationDB_processing_duration_seconds",
+			Help:    "Time from Operation DB row creation to completion, in seconds",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 30, 60, 120, 300, 600, 1800},
+		},
+		[]string{"resourceType", "state"},
+	)
+
+	// OperationBacklog is the number of Operation DB rows that have not yet been processed, by state (Waiting/In_Progress)
+	OperationBacklog = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "operationDB_backlog",
+			Help: "Number of Operation DB rows that are Waiting or In_Progress",
+		},
+		[]string{"state"},
+	)
 )
 
 // SetNumberOfOperationsCR sets total number of operation CRs on cluster
@@ -20,6 +43,19 @@ func SetNumberOfOperationsCR(count int) {
 	OperationCR.Set(float64(count))
 }
 
+// ObserveOperationProcessingDuration records how long an Operation took to be processed, from creation to the given
+// terminal state (Completed/Failed).
+func ObserveOperationProcessingDuration(resourceType db.OperationResourceType, state db.OperationState, duration time.Duration) {
+	OperationProcessingDuration.WithLabelValues(string(resourceType), string(state)).Observe(duration.Seconds())
+}
+
+// SetOperationBacklog sets the number of Operation DB rows in the given (non-terminal) state
+func SetOperationBacklog(state db.OperationState, count int) {
+	OperationBacklog.WithLabelValues(string(state)).Set(float64(count))
+}
+
 func ClearOperationMetrics() {
 	SetNumberOfOperationsCR(0)
+	OperationProcessingDuration.Reset()
+	OperationBacklog.Reset()
 }
@@ -1,9 +1,12 @@
 package metrics
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 )
 
 var _ = Describe("Test for Operation CR metrics counter", func() {
@@ -23,5 +26,17 @@ var _ = Describe("Test for Operation CR metrics counter", func() {
 			Expect(newNumberOfOperationsCRMetrics).To(Equal(numberOfOperationsCRMetrics + 2))
 
 		})
+
+		It("Test ObserveOperationProcessingDuration function", func() {
+
+			ClearOperationMetrics()
+
+			ObserveOperationProcessingDuration(db.OperationResourceType_Application, db.OperationState_Completed, 3*time.Second)
+			ObserveOperationProcessingDuration(db.OperationResourceType_Application, db.OperationState_Completed, 40*time.Second)
+			ObserveOperationProcessingDuration(db.OperationResourceType_ManagedEnvironment, db.OperationState_Failed, time.Second)
+
+			By("verifying a histogram is reported for each resource type and state")
+			Expect(testutil.CollectAndCount(OperationProcessingDuration)).To(Equal(2))
+		})
 	})
 })
@@ -0,0 +1,417 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// An APICallLimiter protects an API server (the Argo CD API server, or the K8s API server of the Argo CD namespace) from
+// being overwhelmed by the cluster-agent, for example when a large number of Operations are created at once:
+//
+// - Rate limiting: calls are delayed so that they do not exceed the configured rate (with bursts up to the configured
+//   burst size). A call waits until it is allowed, or until its context is cancelled.
+//
+// - Circuit breaking: if a number of consecutive calls fail with an error that indicates the API server is unhealthy
+//   or overloaded (for example, a timeout, or a 429/503 response), the circuit 'opens': subsequent calls fail immediately
+//   (with ErrCircuitBreakerOpen), rather than adding load to the API server. Once the open duration has elapsed, a
+//   single trial call is allowed ('half-open'): if it succeeds the circuit closes, otherwise it opens again.
+//
+// Calls that fail for other reasons (for example, a resource that does not exist) do not count as failures, as they
+// do not indicate a problem with the API server.
+//
+// The limits may be configured via the environment variables below.
+
+const (
+	// APICallLimiterArgoCD is the name of the limiter of calls to the Argo CD API server
+	APICallLimiterArgoCD = "argocd"
+
+	// APICallLimiterKubernetes is the name of the limiter of calls to the K8s API server, made while processing Operations
+	APICallLimiterKubernetes = "kubernetes"
+
+	// Rate limit of the Argo CD API server calls, in calls per second, and the burst size.
+	ArgoCDAPIQPSEnvVar   = "ARGOCD_API_QPS"
+	ArgoCDAPIBurstEnvVar = "ARGOCD_API_BURST"
+
+	// Rate limit of the K8s API server calls, in calls per second, and the burst size.
+	KubernetesAPIQPSEnvVar   = "KUBERNETES_API_QPS"
+	KubernetesAPIBurstEnvVar = "KUBERNETES_API_BURST"
+
+	// APICircuitBreakerFailureThresholdEnvVar is the number of consecutive failures after which the circuit opens.
+	APICircuitBreakerFailureThresholdEnvVar = "API_CIRCUIT_BREAKER_FAILURE_THRESHOLD"
+
+	// APICircuitBreakerOpenDurationEnvVar is the number of seconds that the circuit stays open, before a trial call is allowed.
+	APICircuitBreakerOpenDurationEnvVar = "API_CIRCUIT_BREAKER_OPEN_DURATION_SECONDS"
+
+	defaultArgoCDAPIQPS                      = 20
+	defaultArgoCDAPIBurst                    = 40
+	defaultKubernetesAPIQPS                  = 50
+	defaultKubernetesAPIBurst                = 100
+	defaultAPICircuitBreakerFailureThreshold = 5
+	defaultAPICircuitBreakerOpenDuration     = 30 * time.Second
+)
+
+// States of the circuit breaker, as reported by the 'cluster_agent_api_circuit_breaker_state' metric
+const (
+	circuitBreakerClosed   = 0
+	circuitBreakerOpen     = 1
+	circuitBreakerHalfOpen = 2
+)
+
+// ErrCircuitBreakerOpen is returned (wrapped) by APICallLimiter.Do while the circuit is open
+var ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
+
+const apiCallLimiterLabel = "api"
+
+var (
+	APICallsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_agent_api_calls_total",
+			Help: "Number of calls to the Argo CD/K8s API servers, by API and result ('success', 'failure', 'error' or 'rejected')",
+		},
+		[]string{apiCallLimiterLabel, "result"},
+	)
+
+	APICallRateLimitWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cluster_agent_api_rate_limit_wait_seconds",
+			Help:    "Time that calls to the Argo CD/K8s API servers waited for the rate limiter, by API",
+			Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+		},
+		[]string{apiCallLimiterLabel},
+	)
+
+	APICircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_agent_api_circuit_breaker_state",
+			Help: "State of the circuit breaker of the Argo CD/K8s API servers, by API: 0 is closed, 1 is open, 2 is half-open",
+		},
+		[]string{apiCallLimiterLabel},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(APICallsTotal, APICallRateLimitWait, APICircuitBreakerState)
+}
+
+// APICallLimiter rate limits calls to an API server, and stops calling it while it is failing: see above.
+type APICallLimiter struct {
+	name string
+
+	rateLimiter flowcontrol.RateLimiter
+
+	failureThreshold int
+	openDuration     time.Duration
+
+	mutex sync.Mutex
+
+	// state is the state of the circuit breaker: one of the circuitBreaker* constants
+	state int
+
+	// consecutiveFailures is the number of consecutive calls that failed, while the circuit is closed
+	consecutiveFailures int
+
+	// openedAt is the time at which the circuit was last opened
+	openedAt time.Time
+
+	// trialInProgress is true while the circuit is half-open, and the trial call has not yet completed
+	trialInProgress bool
+}
+
+// NewAPICallLimiter returns an APICallLimiter that allows 'qps' calls per second (with bursts of up to 'burst' calls),
+// and opens the circuit for 'openDuration' after 'failureThreshold' consecutive failures.
+func NewAPICallLimiter(name string, qps float32, burst int, failureThreshold int, openDuration time.Duration) *APICallLimiter {
+
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	APICircuitBreakerState.WithLabelValues(name).Set(circuitBreakerClosed)
+
+	return &APICallLimiter{
+		name:             name,
+		rateLimiter:      flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		state:            circuitBreakerClosed,
+	}
+}
+
+var (
+	defaultAPICallLimitersMutex sync.Mutex
+	defaultAPICallLimiters      = map[string]*APICallLimiter{}
+)
+
+// ArgoCDAPICallLimiter returns the APICallLimiter that is shared by all calls to the Argo CD API server.
+func ArgoCDAPICallLimiter() *APICallLimiter {
+	return getDefaultAPICallLimiter(APICallLimiterArgoCD, ArgoCDAPIQPSEnvVar, defaultArgoCDAPIQPS, ArgoCDAPIBurstEnvVar, defaultArgoCDAPIBurst)
+}
+
+// KubernetesAPICallLimiter returns the APICallLimiter that is shared by all calls to the K8s API server, made while processing Operations.
+func KubernetesAPICallLimiter() *APICallLimiter {
+	return getDefaultAPICallLimiter(APICallLimiterKubernetes, KubernetesAPIQPSEnvVar, defaultKubernetesAPIQPS,
+		KubernetesAPIBurstEnvVar, defaultKubernetesAPIBurst)
+}
+
+func getDefaultAPICallLimiter(name string, qpsEnvVar string, defaultQPS int, burstEnvVar string, defaultBurst int) *APICallLimiter {
+
+	defaultAPICallLimitersMutex.Lock()
+	defer defaultAPICallLimitersMutex.Unlock()
+
+	if limiter, exists := defaultAPICallLimiters[name]; exists {
+		return limiter
+	}
+
+	limiter := NewAPICallLimiter(name, float32(getPositiveIntFromEnv(qpsEnvVar, defaultQPS)), getPositiveIntFromEnv(burstEnvVar, defaultBurst),
+		getPositiveIntFromEnv(APICircuitBreakerFailureThresholdEnvVar, defaultAPICircuitBreakerFailureThreshold),
+		time.Duration(getPositiveIntFromEnv(APICircuitBreakerOpenDurationEnvVar, int(defaultAPICircuitBreakerOpenDuration/time.Second)))*time.Second)
+
+	defaultAPICallLimiters[name] = limiter
+
+	return limiter
+}
+
+// getPositiveIntFromEnv returns the value of the environment variable, or the default if it is not set, or is not a positive integer.
+func getPositiveIntFromEnv(envVar string, defaultValue int) int {
+
+	value, err := strconv.Atoi(os.Getenv(envVar))
+	if err != nil || value < 1 {
+		return defaultValue
+	}
+
+	return value
+}
+
+// Do calls 'fn' once the rate limiter allows it, and records whether it failed in the circuit breaker. If the circuit
+// is open, 'fn' is not called, and an error wrapping ErrCircuitBreakerOpen is returned.
+func (l *APICallLimiter) Do(ctx context.Context, fn func() error) error {
+
+	if err := l.acquire(); err != nil {
+		APICallsTotal.WithLabelValues(l.name, "rejected").Inc()
+		return err
+	}
+
+	start := time.Now()
+	if err := l.rateLimiter.Wait(ctx); err != nil {
+		// The call never reached the API server, so it doesn't count towards the circuit breaker
+		l.release(nil)
+		APICallsTotal.WithLabelValues(l.name, "rejected").Inc()
+		return fmt.Errorf("unable to call the %s API: rate limiter wait failed: %w", l.name, err)
+	}
+	APICallRateLimitWait.WithLabelValues(l.name).Observe(time.Since(start).Seconds())
+
+	err := fn()
+
+	l.release(err)
+
+	switch {
+	case err == nil:
+		APICallsTotal.WithLabelValues(l.name, "success").Inc()
+	case IsAPIServerFailure(err):
+		APICallsTotal.WithLabelValues(l.name, "failure").Inc()
+	default:
+		APICallsTotal.WithLabelValues(l.name, "error").Inc()
+	}
+
+	return err
+}
+
+// acquire returns an error if the circuit is open. If the open duration has elapsed, the circuit becomes half-open,
+// and the caller is allowed to make the trial call.
+func (l *APICallLimiter) acquire() error {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	switch l.state {
+	case circuitBreakerOpen:
+		if time.Since(l.openedAt) < l.openDuration {
+			return fmt.Errorf("unable to call the %s API: %w", l.name, ErrCircuitBreakerOpen)
+		}
+		l.setState(circuitBreakerHalfOpen)
+		l.trialInProgress = true
+
+	case circuitBreakerHalfOpen:
+		// Only a single trial call is allowed while half-open
+		if l.trialInProgress {
+			return fmt.Errorf("unable to call the %s API: %w", l.name, ErrCircuitBreakerOpen)
+		}
+		l.trialInProgress = true
+	}
+
+	return nil
+}
+
+// release records the result of a call in the circuit breaker.
+func (l *APICallLimiter) release(err error) {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	failed := err != nil && IsAPIServerFailure(err)
+
+	if l.state == circuitBreakerHalfOpen {
+		l.trialInProgress = false
+		if failed {
+			l.open()
+		} else {
+			l.consecutiveFailures = 0
+			l.setState(circuitBreakerClosed)
+		}
+		return
+	}
+
+	if !failed {
+		l.consecutiveFailures = 0
+		return
+	}
+
+	l.consecutiveFailures++
+	if l.state == circuitBreakerClosed && l.consecutiveFailures >= l.failureThreshold {
+		l.open()
+	}
+}
+
+// open opens the circuit: the caller must hold the mutex.
+func (l *APICallLimiter) open() {
+	l.openedAt = time.Now()
+	l.consecutiveFailures = 0
+	l.setState(circuitBreakerOpen)
+}
+
+// setState sets the state of the circuit breaker, and the corresponding metric: the caller must hold the mutex.
+func (l *APICallLimiter) setState(state int) {
+	l.state = state
+	APICircuitBreakerState.WithLabelValues(l.name).Set(float64(state))
+}
+
+// IsAPIServerFailure returns true if the error indicates that the API server is unhealthy or overloaded (rather than,
+// for example, that the request was invalid, or the resource doesn't exist).
+func IsAPIServerFailure(err error) bool {
+
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitBreakerOpen) {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// Errors returned by the K8s API server
+	if apierr.IsServerTimeout(err) || apierr.IsTimeout(err) || apierr.IsTooManyRequests(err) ||
+		apierr.IsServiceUnavailable(err) || apierr.IsInternalError(err) || apierr.IsUnexpectedServerError(err) {
+		return true
+	}
+
+	// Errors returned by the Argo CD API server (over GRPC)
+	if grpcStatus, ok := status.FromError(err); ok {
+		switch grpcStatus.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal:
+			return true
+		}
+	}
+
+	// Unable to connect to the API server
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+var _ client.Client = &APICallLimiterClient{}
+
+// APICallLimiterClient is a client.Client which makes all its calls via an APICallLimiter.
+type APICallLimiterClient struct {
+	InnerClient client.Client
+	Limiter     *APICallLimiter
+}
+
+// NewAPICallLimiterClient returns a client that makes the calls of 'innerClient' via the limiter.
+func NewAPICallLimiterClient(innerClient client.Client, limiter *APICallLimiter) client.Client {
+	return &APICallLimiterClient{InnerClient: innerClient, Limiter: limiter}
+}
+
+func (c *APICallLimiterClient) Get(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+	return c.Limiter.Do(ctx, func() error {
+		return c.InnerClient.Get(ctx, key, obj, opts...)
+	})
+}
+
+func (c *APICallLimiterClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.Limiter.Do(ctx, func() error {
+		return c.InnerClient.List(ctx, list, opts...)
+	})
+}
+
+func (c *APICallLimiterClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.Limiter.Do(ctx, func() error {
+		return c.InnerClient.Create(ctx, obj, opts...)
+	})
+}
+
+func (c *APICallLimiterClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.Limiter.Do(ctx, func() error {
+		return c.InnerClient.Delete(ctx, obj, opts...)
+	})
+}
+
+func (c *APICallLimiterClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return c.Limiter.Do(ctx, func() error {
+		return c.InnerClient.Update(ctx, obj, opts...)
+	})
+}
+
+func (c *APICallLimiterClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.Limiter.Do(ctx, func() error {
+		return c.InnerClient.Patch(ctx, obj, patch, opts...)
+	})
+}
+
+func (c *APICallLimiterClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return c.Limiter.Do(ctx, func() error {
+		return c.InnerClient.DeleteAllOf(ctx, obj, opts...)
+	})
+}
+
+func (c *APICallLimiterClient) Status() client.StatusWriter {
+	return &apiCallLimiterStatusWriter{innerWriter: c.InnerClient.Status(), limiter: c.Limiter}
+}
+
+func (c *APICallLimiterClient) Scheme() *runtime.Scheme {
+	return c.InnerClient.Scheme()
+}
+
+func (c *APICallLimiterClient) RESTMapper() meta.RESTMapper {
+	return c.InnerClient.RESTMapper()
+}
+
+type apiCallLimiterStatusWriter struct {
+	innerWriter client.StatusWriter
+	limiter     *APICallLimiter
+}
+
+func (w *apiCallLimiterStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return w.limiter.Do(ctx, func() error {
+		return w.innerWriter.Update(ctx, obj, opts...)
+	})
+}
+
+func (w *apiCallLimiterStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return w.limiter.Do(ctx, func() error {
+		return w.innerWriter.Patch(ctx, obj, patch, opts...)
+	})
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("API Call Limiter Unit Tests", func() {
+
+	Context("Rate limiting", func() {
+
+		It("should delay calls that exceed the rate limit, until they are allowed", func() {
+
+			limiter := NewAPICallLimiter("test-rate-limit", 10, 1, 5, time.Minute)
+
+			start := time.Now()
+			for i := 0; i < 3; i++ {
+				Expect(limiter.Do(context.Background(), func() error { return nil })).To(Succeed())
+			}
+
+			// The first call uses the burst, and each following call waits 100ms (10 QPS)
+			Expect(time.Since(start)).To(BeNumerically(">=", 150*time.Millisecond))
+			Expect(testutil.ToFloat64(APICallsTotal.WithLabelValues("test-rate-limit", "success"))).To(Equal(float64(3)))
+		})
+
+		It("should not call the function if the context is cancelled while waiting for the rate limiter", func() {
+
+			limiter := NewAPICallLimiter("test-rate-limit-cancel", 0.1, 1, 5, time.Minute)
+			Expect(limiter.Do(context.Background(), func() error { return nil })).To(Succeed())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			called := false
+			err := limiter.Do(ctx, func() error {
+				called = true
+				return nil
+			})
+			Expect(err).ToNot(BeNil())
+			Expect(called).To(BeFalse())
+			Expect(testutil.ToFloat64(APICallsTotal.WithLabelValues("test-rate-limit-cancel", "rejected"))).To(Equal(float64(1)))
+		})
+	})
+
+	Context("Circuit breaking", func() {
+
+		serverErr := apierr.NewServiceUnavailable("unavailable")
+
+		It("should open the circuit after the failure threshold, and then allow a single trial call once the open duration has elapsed", func() {
+
+			limiter := NewAPICallLimiter("test-circuit-breaker", 1000, 1000, 3, 200*time.Millisecond)
+
+			calls := 0
+			failingCall := func() error {
+				calls++
+				return serverErr
+			}
+
+			for i := 0; i < 3; i++ {
+				Expect(limiter.Do(context.Background(), failingCall)).To(Equal(serverErr))
+			}
+			Expect(testutil.ToFloat64(APICircuitBreakerState.WithLabelValues("test-circuit-breaker"))).To(Equal(float64(circuitBreakerOpen)))
+
+			By("verifying calls are rejected while the circuit is open")
+			err := limiter.Do(context.Background(), failingCall)
+			Expect(errors.Is(err, ErrCircuitBreakerOpen)).To(BeTrue())
+			Expect(calls).To(Equal(3))
+			Expect(testutil.ToFloat64(APICallsTotal.WithLabelValues("test-circuit-breaker", "rejected"))).To(Equal(float64(1)))
+
+			By("verifying a failed trial call opens the circuit again")
+			time.Sleep(200 * time.Millisecond)
+			Expect(limiter.Do(context.Background(), failingCall)).To(Equal(serverErr))
+			Expect(calls).To(Equal(4))
+			err = limiter.Do(context.Background(), failingCall)
+			Expect(errors.Is(err, ErrCircuitBreakerOpen)).To(BeTrue())
+
+			By("verifying a successful trial call closes the circuit")
+			time.Sleep(200 * time.Millisecond)
+			Expect(limiter.Do(context.Background(), func() error { return nil })).To(Succeed())
+			Expect(testutil.ToFloat64(APICircuitBreakerState.WithLabelValues("test-circuit-breaker"))).To(Equal(float64(circuitBreakerClosed)))
+			Expect(limiter.Do(context.Background(), func() error { return nil })).To(Succeed())
+		})
+
+		It("should only count consecutive failures towards the threshold", func() {
+
+			limiter := NewAPICallLimiter("test-consecutive", 1000, 1000, 2, time.Minute)
+
+			Expect(limiter.Do(context.Background(), func() error { return serverErr })).ToNot(Succeed())
+			Expect(limiter.Do(context.Background(), func() error { return nil })).To(Succeed())
+			Expect(limiter.Do(context.Background(), func() error { return serverErr })).ToNot(Succeed())
+
+			Expect(testutil.ToFloat64(APICircuitBreakerState.WithLabelValues("test-consecutive"))).To(Equal(float64(circuitBreakerClosed)))
+		})
+
+		It("should not open the circuit for errors that do not indicate an API server failure", func() {
+
+			limiter := NewAPICallLimiter("test-not-failure", 1000, 1000, 1, time.Minute)
+
+			notFoundErr := apierr.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "my-config-map")
+			for i := 0; i < 3; i++ {
+				Expect(limiter.Do(context.Background(), func() error { return notFoundErr })).To(Equal(notFoundErr))
+			}
+
+			Expect(testutil.ToFloat64(APICircuitBreakerState.WithLabelValues("test-not-failure"))).To(Equal(float64(circuitBreakerClosed)))
+			Expect(testutil.ToFloat64(APICallsTotal.WithLabelValues("test-not-failure", "error"))).To(Equal(float64(3)))
+		})
+	})
+
+	DescribeTable("IsAPIServerFailure should only return true for errors that indicate the API server is unhealthy or overloaded",
+		func(err error, expected bool) {
+			Expect(IsAPIServerFailure(err)).To(Equal(expected))
+		},
+		Entry("no error", nil, false),
+		Entry("K8s service unavailable", apierr.NewServiceUnavailable("unavailable"), true),
+		Entry("K8s too many requests", apierr.NewTooManyRequests("slow down", 1), true),
+		Entry("K8s not found", apierr.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "my-config-map"), false),
+		Entry("GRPC unavailable", status.Error(codes.Unavailable, "unavailable"), true),
+		Entry("GRPC resource exhausted", status.Error(codes.ResourceExhausted, "exhausted"), true),
+		Entry("GRPC not found", status.Error(codes.NotFound, "not found"), false),
+		Entry("deadline exceeded", fmt.Errorf("wrapped: %w", context.DeadlineExceeded), true),
+		Entry("context cancelled", context.Canceled, false),
+		Entry("other error", errors.New("some error"), false),
+	)
+
+	Context("APICallLimiterClient", func() {
+
+		It("should make the calls of the inner client via the limiter", func() {
+
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-config-map", Namespace: "my-namespace"},
+			}
+
+			k8sClient := NewAPICallLimiterClient(fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(configMap).Build(),
+				NewAPICallLimiter("test-client", 1000, 1000, 5, time.Minute))
+
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(configMap), configMap)).To(Succeed())
+			configMap.Data = map[string]string{"key": "value"}
+			Expect(k8sClient.Update(context.Background(), configMap)).To(Succeed())
+			Expect(k8sClient.List(context.Background(), &corev1.ConfigMapList{})).To(Succeed())
+
+			Expect(testutil.ToFloat64(APICallsTotal.WithLabelValues("test-client", "success"))).To(Equal(float64(3)))
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"reflect"
 	"time"
 
@@ -41,8 +42,68 @@ const (
 	argoCDReconciliationTimeoutEnvValue = "60s"
 )
 
-// ReconcileNamespaceScopedArgoCD will create/update an ArgoCD operand within the specified namespace.
+// proxyEnvVarNames are the standard proxy environment variables, which are passed through from the cluster-agent to
+// the Argo CD application controller, so that both connect to the managed environment clusters via the same proxy.
+var proxyEnvVarNames = []string{"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY"}
+
+// proxyEnvVars returns the proxy environment variables that are set in the environment of the cluster-agent.
+func proxyEnvVars() []corev1.EnvVar {
+	var res []corev1.EnvVar
+	for _, name := range proxyEnvVarNames {
+		if value := os.Getenv(name); value != "" {
+			res = append(res, corev1.EnvVar{Name: name, Value: value})
+		}
+	}
+	return res
+}
+
+// ReconcileNamespaceScopedArgoCD will create/update an ArgoCD operand within the specified namespace, and then wait for
+// Argo CD to be installed by the OpenShift GitOps operator.
 func ReconcileNamespaceScopedArgoCD(ctx context.Context, argocdCRName string, namespace string, k8sClient client.Client, log logr.Logger) error {
+
+	if err := CreateOrUpdateNamespaceScopedArgoCD(ctx, argocdCRName, namespace, k8sClient, log); err != nil {
+		return err
+	}
+
+	// Wait for Argo CD to be installed by gitops operator.
+	err := wait.PollImmediate(1*time.Second, 3*time.Minute, func() (bool, error) {
+		ready, err := IsNamespaceScopedArgoCDReady(ctx, namespace, k8sClient)
+		if err != nil {
+			log.Error(err, "unable to retrieve AppProject")
+			return false, err
+		}
+		if !ready {
+			log.V(logutil.LogLevel_Debug).Info("Waiting for AppProject to exist in namespace " + namespace)
+		}
+		return ready, nil
+	})
+
+	return err
+}
+
+// IsNamespaceScopedArgoCDReady returns true if the Argo CD instance in the namespace has started: the 'default' AppProject
+// is created by Argo CD once it has successfully started.
+func IsNamespaceScopedArgoCDReady(ctx context.Context, namespace string, k8sClient client.Client) (bool, error) {
+
+	appProject := &appv1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultAppProject,
+			Namespace: namespace,
+		},
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(appProject), appProject); err != nil {
+		if apierr.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CreateOrUpdateNamespaceScopedArgoCD will create/update an ArgoCD operand within the specified namespace (creating the
+// namespace if needed), without waiting for Argo CD to be installed by the OpenShift GitOps operator.
+func CreateOrUpdateNamespaceScopedArgoCD(ctx context.Context, argocdCRName string, namespace string, k8sClient client.Client, log logr.Logger) error {
 	policy := "g, system:authenticated, role:admin"
 	scopes := "[groups]"
 
@@ -81,12 +142,12 @@ func ReconcileNamespaceScopedArgoCD(ctx context.Context, argocdCRName string, na
 					},
 				},
 				Sharding: argocdoperator.ArgoCDApplicationControllerShardSpec{},
-				Env: []corev1.EnvVar{
+				Env: append([]corev1.EnvVar{
 					{
 						Name:  argoCDReconciliationTimeoutEnvName,
 						Value: argoCDReconciliationTimeoutEnvValue,
 					},
-				},
+				}, proxyEnvVars()...),
 			},
 			Grafana: argocdoperator.ArgoCDGrafanaSpec{
 				Enabled: false,
@@ -263,7 +324,7 @@ func ReconcileNamespaceScopedArgoCD(ctx context.Context, argocdCRName string, na
 
 	} else {
 		// B) The existing ArgoCD resource already exists, so make sure it is up to date
-		if !reflect.DeepEqual(existingArgoCDOperand.Spec, expectedArgoCDOperand) {
+		if !reflect.DeepEqual(existingArgoCDOperand.Spec, expectedArgoCDOperand.Spec) {
 			existingArgoCDOperand.Spec = expectedArgoCDOperand.Spec
 			if err := k8sClient.Update(ctx, existingArgoCDOperand); err != nil {
 				log.Error(err, "unexpected error on updating existing ArgoCD operand")
@@ -275,31 +336,7 @@ func ReconcileNamespaceScopedArgoCD(ctx context.Context, argocdCRName string, na
 		}
 	}
 
-	// Wait for Argo CD to be installed by gitops operator.
-	err := wait.PollImmediate(1*time.Second, 3*time.Minute, func() (bool, error) {
-
-		// 'default' AppProject will be created by Argo CD if Argo CD is successfully started.
-		appProject := &appv1.AppProject{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      DefaultAppProject,
-				Namespace: namespace,
-			},
-		}
-		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(appProject), appProject); err != nil {
-			if apierr.IsNotFound(err) {
-				log.V(logutil.LogLevel_Debug).Info("Waiting for AppProject to exist in namespace " + namespace)
-				return false, nil
-			} else {
-				log.Error(err, "unable to retrieve AppProject")
-				return false, err
-			}
-		} else {
-			return true, nil
-		}
-	})
-
-	return err
-
+	return nil
 }
 
 func SetupArgoCD(ctx context.Context, apiHost string, argoCDNamespace string, k8sClient client.Client, log logr.Logger) error {
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	argoio "github.com/argoproj/argo-cd/v2/util/io"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// This file is loosely based on the 'argocd app diff' CLI command (https://github.com/argoproj/argo-cd/blob/0a46d37fc6af9fe0aa963bdd845e3d799aa0320d/cmd/argocd/commands/app.go#L1025)
+
+const (
+	// DiffPreviewAction_* are the values of the 'action' field of a resource of a diff preview
+	DiffPreviewAction_Create = "Create"
+	DiffPreviewAction_Update = "Update"
+	DiffPreviewAction_Delete = "Delete"
+)
+
+// DiffPreviewer retrieves the changes that a sync of an Argo CD Application would make, from the Argo CD API.
+type DiffPreviewer interface {
+
+	// GetResourceDiffs returns a summary of each resource of the Application that would be created, updated or deleted by a sync,
+	// sorted by group, kind, namespace and name.
+	GetResourceDiffs(ctx context.Context, k8sClient client.Client, argoCDNamespace string, appName string) ([]fauxargocd.FauxResourceDiff, error)
+}
+
+// NewDiffPreviewer returns a DiffPreviewer that logs in to Argo CD using the given CredentialService.
+func NewDiffPreviewer(credentialService *CredentialService) DiffPreviewer {
+	return &argoCDDiffPreviewer{credentialService: credentialService}
+}
+
+type argoCDDiffPreviewer struct {
+	credentialService *CredentialService
+}
+
+func (d *argoCDDiffPreviewer) GetResourceDiffs(ctx context.Context, k8sClient client.Client, argoCDNamespace string,
+	appName string) ([]fauxargocd.FauxResourceDiff, error) {
+
+	namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: argoCDNamespace}}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&namespace), &namespace); err != nil {
+		return nil, fmt.Errorf("unable to retrieve Argo CD namespace '%s': %v", argoCDNamespace, err)
+	}
+
+	_, acdClient, err := d.credentialService.GetArgoCDLoginCredentials(ctx, namespace.Name, string(namespace.UID), false, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, appIf, err := acdClient.NewApplicationClient()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create application client for diff preview: %v", err)
+	}
+	defer argoio.Close(conn)
+
+	var managedResources *applicationpkg.ManagedResourcesResponse
+	err = ArgoCDAPICallLimiter().Do(ctx, func() error {
+		managedResources, err = appIf.ManagedResources(ctx, &applicationpkg.ResourcesQuery{ApplicationName: &appName})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the managed resources of Application '%s': %v", appName, err)
+	}
+
+	return summarizeResourceDiffs(managedResources.Items)
+}
+
+// summarizeResourceDiffs returns a summary of each modified resource, sorted by group, kind, namespace and name.
+func summarizeResourceDiffs(resourceDiffs []*appv1.ResourceDiff) ([]fauxargocd.FauxResourceDiff, error) {
+
+	res := []fauxargocd.FauxResourceDiff{}
+
+	for _, resourceDiff := range resourceDiffs {
+
+		// Hooks are run on each sync, so are not part of the diff
+		if resourceDiff == nil || resourceDiff.Hook || !resourceDiff.Modified {
+			continue
+		}
+
+		// Argo CD's diff compares the normalized live state with the predicted state (the live state, once the target
+		// state has been applied)
+		liveState, err := unmarshalResourceState(resourceDiff.NormalizedLiveState)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal live state of %s '%s': %v", resourceDiff.Kind, resourceDiff.Name, err)
+		}
+		predictedState, err := unmarshalResourceState(resourceDiff.PredictedLiveState)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal predicted state of %s '%s': %v", resourceDiff.Kind, resourceDiff.Name, err)
+		}
+
+		action := DiffPreviewAction_Update
+		if liveState == nil {
+			action = DiffPreviewAction_Create
+		} else if targetState, err := unmarshalResourceState(resourceDiff.TargetState); err == nil && targetState == nil {
+			// A resource with no target state is no longer in Git, and so would be pruned
+			action = DiffPreviewAction_Delete
+			predictedState = nil
+		}
+
+		res = append(res, fauxargocd.FauxResourceDiff{
+			Group:         resourceDiff.Group,
+			Kind:          resourceDiff.Kind,
+			Name:          resourceDiff.Name,
+			Namespace:     resourceDiff.Namespace,
+			Action:        action,
+			ChangedFields: countChangedFields(liveState, predictedState),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Group != res[j].Group {
+			return res[i].Group < res[j].Group
+		}
+		if res[i].Kind != res[j].Kind {
+			return res[i].Kind < res[j].Kind
+		}
+		if res[i].Namespace != res[j].Namespace {
+			return res[i].Namespace < res[j].Namespace
+		}
+		return res[i].Name < res[j].Name
+	})
+
+	return res, nil
+}
+
+// unmarshalResourceState unmarshals a resource state JSON string from the Argo CD API. Returns nil if the resource does
+// not exist in that state (Argo CD uses the JSON value 'null').
+func unmarshalResourceState(state string) (interface{}, error) {
+	if state == "" {
+		return nil, nil
+	}
+
+	var res interface{}
+	if err := json.Unmarshal([]byte(state), &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// countChangedFields returns the number of (leaf) fields that differ between the two values: fields that are only in
+// one of the values are counted as changed. Lists are compared as a single field.
+func countChangedFields(live interface{}, predicted interface{}) int {
+
+	if reflect.DeepEqual(live, predicted) {
+		return 0
+	}
+
+	liveMap, liveIsMap := live.(map[string]interface{})
+	predictedMap, predictedIsMap := predicted.(map[string]interface{})
+
+	switch {
+	case liveIsMap && predictedIsMap:
+		count := 0
+		for key, liveValue := range liveMap {
+			count += countChangedFields(liveValue, predictedMap[key])
+		}
+		for key, predictedValue := range predictedMap {
+			if _, exists := liveMap[key]; !exists {
+				count += countChangedFields(nil, predictedValue)
+			}
+		}
+		return count
+
+	case liveIsMap && predicted == nil:
+		return countChangedFields(liveMap, map[string]interface{}{})
+
+	case predictedIsMap && live == nil:
+		return countChangedFields(map[string]interface{}{}, predictedMap)
+
+	default:
+		return 1
+	}
+}
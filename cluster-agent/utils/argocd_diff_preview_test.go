@@ -0,0 +1,61 @@
+package utils
+
+import (
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
+)
+
+var _ = Describe("Diff preview of Argo CD Application", func() {
+	Context("summarizeResourceDiffs", func() {
+
+		liveDeployment := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"my-deployment"},"spec":{"replicas":1,"template":{"spec":{"containers":[{"image":"my-image:v1"}]}}}}`
+		predictedDeployment := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"my-deployment","labels":{"app":"my-app"}},"spec":{"replicas":3,"template":{"spec":{"containers":[{"image":"my-image:v2"}]}}}}`
+		service := `{"apiVersion":"v1","kind":"Service","metadata":{"name":"my-service"},"spec":{"type":"ClusterIP"}}`
+
+		It("should summarize the created, updated and deleted resources, and ignore unmodified resources and hooks", func() {
+			res, err := summarizeResourceDiffs([]*appv1.ResourceDiff{
+				{
+					Group: "apps", Kind: "Deployment", Namespace: "my-namespace", Name: "my-deployment",
+					TargetState: predictedDeployment, NormalizedLiveState: liveDeployment, PredictedLiveState: predictedDeployment,
+					Modified: true,
+				},
+				{
+					Kind: "Service", Namespace: "my-namespace", Name: "my-service",
+					TargetState: service, NormalizedLiveState: "null", PredictedLiveState: service,
+					Modified: true,
+				},
+				{
+					Kind: "ConfigMap", Namespace: "my-namespace", Name: "old-config",
+					TargetState: "null", NormalizedLiveState: `{"kind":"ConfigMap","data":{"a":"b"}}`, PredictedLiveState: `{"kind":"ConfigMap","data":{"a":"b"}}`,
+					Modified: true,
+				},
+				{
+					Kind: "ServiceAccount", Namespace: "my-namespace", Name: "unchanged",
+					TargetState: "{}", NormalizedLiveState: "{}", PredictedLiveState: "{}",
+					Modified: false,
+				},
+				{
+					Group: "batch", Kind: "Job", Namespace: "my-namespace", Name: "my-hook",
+					Hook: true, Modified: true,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			Expect(res).To(Equal([]fauxargocd.FauxResourceDiff{
+				{Kind: "ConfigMap", Namespace: "my-namespace", Name: "old-config", Action: DiffPreviewAction_Delete, ChangedFields: 2},
+				{Kind: "Service", Namespace: "my-namespace", Name: "my-service", Action: DiffPreviewAction_Create, ChangedFields: 4},
+				// the new label, the replicas, and the containers list
+				{Group: "apps", Kind: "Deployment", Namespace: "my-namespace", Name: "my-deployment", Action: DiffPreviewAction_Update, ChangedFields: 3},
+			}))
+		})
+
+		It("should return an error if a resource state is not valid JSON", func() {
+			_, err := summarizeResourceDiffs([]*appv1.ResourceDiff{
+				{Kind: "Service", Name: "my-service", NormalizedLiveState: "{invalid", PredictedLiveState: service, Modified: true},
+			})
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	argoio "github.com/argoproj/argo-cd/v2/util/io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// This file is loosely based on the 'argocd app logs' CLI command (https://github.com/argoproj/argo-cd/blob/0a46d37fc6af9fe0aa963bdd845e3d799aa0320d/cmd/argocd/commands/app.go#L263)
+
+// HookLogRetriever retrieves the logs of the resource hooks of an Argo CD Application (for example, a PreSync Job), from
+// the Argo CD API. Argo CD retrieves the logs from the cluster that the Application is deployed to.
+type HookLogRetriever interface {
+
+	// GetHookLogTail returns the last 'tailLines' lines of the logs of the Pods of the given resource of the Application.
+	GetHookLogTail(ctx context.Context, k8sClient client.Client, argoCDNamespace string, appName string,
+		group string, kind string, namespace string, name string, tailLines int64) (string, error)
+}
+
+// NewHookLogRetriever returns a HookLogRetriever that logs in to Argo CD using the given CredentialService.
+func NewHookLogRetriever(credentialService *CredentialService) HookLogRetriever {
+	return &argoCDHookLogRetriever{credentialService: credentialService}
+}
+
+type argoCDHookLogRetriever struct {
+	credentialService *CredentialService
+}
+
+func (h *argoCDHookLogRetriever) GetHookLogTail(ctx context.Context, k8sClient client.Client, argoCDNamespace string, appName string,
+	group string, kind string, namespace string, name string, tailLines int64) (string, error) {
+
+	argoCDNamespaceObj := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: argoCDNamespace}}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&argoCDNamespaceObj), &argoCDNamespaceObj); err != nil {
+		return "", fmt.Errorf("unable to retrieve Argo CD namespace '%s': %v", argoCDNamespace, err)
+	}
+
+	_, acdClient, err := h.credentialService.GetArgoCDLoginCredentials(ctx, argoCDNamespaceObj.Name, string(argoCDNamespaceObj.UID), false, k8sClient)
+	if err != nil {
+		return "", err
+	}
+
+	conn, appIf, err := acdClient.NewApplicationClient()
+	if err != nil {
+		return "", fmt.Errorf("unable to create application client for hook logs: %v", err)
+	}
+	defer argoio.Close(conn)
+
+	follow := false
+	var lines []string
+
+	err = ArgoCDAPICallLimiter().Do(ctx, func() error {
+		stream, err := appIf.PodLogs(ctx, &applicationpkg.ApplicationPodLogsQuery{
+			Name:         &appName,
+			Namespace:    &namespace,
+			Group:        &group,
+			Kind:         &kind,
+			ResourceName: &name,
+			TailLines:    &tailLines,
+			Follow:       &follow,
+		})
+		if err != nil {
+			return err
+		}
+
+		lines, err = readLogEntries(stream)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve the logs of %s '%s' of Application '%s': %v", kind, name, appName, err)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// logEntryReceiver receives the log entries of a PodLogs request to the Argo CD API
+type logEntryReceiver interface {
+	Recv() (*applicationpkg.LogEntry, error)
+}
+
+// readLogEntries returns the content of each log entry received from the stream, until the last entry is received (or
+// the stream ends).
+func readLogEntries(stream logEntryReceiver) ([]string, error) {
+
+	res := []string{}
+
+	for {
+		entry, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return res, nil
+			}
+			return nil, err
+		}
+
+		if entry.GetLast() {
+			return res, nil
+		}
+
+		res = append(res, entry.GetContent())
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+
+	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// mockLogEntryStream returns the configured log entries, followed by the configured error (or io.EOF)
+type mockLogEntryStream struct {
+	entries []*applicationpkg.LogEntry
+	err     error
+}
+
+func (m *mockLogEntryStream) Recv() (*applicationpkg.LogEntry, error) {
+	if len(m.entries) == 0 {
+		if m.err != nil {
+			return nil, m.err
+		}
+		return nil, io.EOF
+	}
+
+	entry := m.entries[0]
+	m.entries = m.entries[1:]
+	return entry, nil
+}
+
+var _ = Describe("Logs of the resource hooks of Argo CD Application", func() {
+
+	Context("readLogEntries", func() {
+
+		logEntry := func(content string, last bool) *applicationpkg.LogEntry {
+			return &applicationpkg.LogEntry{Content: &content, Last: &last}
+		}
+
+		It("should return the content of each entry, until the last entry is received", func() {
+			lines, err := readLogEntries(&mockLogEntryStream{entries: []*applicationpkg.LogEntry{
+				logEntry("running migration 1", false),
+				logEntry("ERROR: relation \"users\" already exists", false),
+				logEntry("", true),
+				logEntry("not returned", false),
+			}})
+			Expect(err).To(BeNil())
+			Expect(lines).To(Equal([]string{"running migration 1", "ERROR: relation \"users\" already exists"}))
+		})
+
+		It("should return the entries received before the end of the stream", func() {
+			lines, err := readLogEntries(&mockLogEntryStream{entries: []*applicationpkg.LogEntry{
+				logEntry("running migration 1", false),
+			}})
+			Expect(err).To(BeNil())
+			Expect(lines).To(Equal([]string{"running migration 1"}))
+		})
+
+		It("should return an error if the stream fails", func() {
+			_, err := readLogEntries(&mockLogEntryStream{err: fmt.Errorf("connection reset")})
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	argoio "github.com/argoproj/argo-cd/v2/util/io"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EnableResourceEstimatesEnvVar may be set to 'true' to collect an estimate of the CPU and memory requested by the
+	// Pods of each Argo CD Application, which is reported in the status of the GitOpsDeployment. Disabled by default.
+	EnableResourceEstimatesEnvVar = "ENABLE_RESOURCE_ESTIMATES"
+
+	// resourceEstimatePodLimit is the maximum number of Pods whose manifests are retrieved for a single estimate
+	resourceEstimatePodLimit = 100
+)
+
+// IsResourceEstimationEnabled returns true if resource estimates are enabled, via EnableResourceEstimatesEnvVar.
+func IsResourceEstimationEnabled() bool {
+	return os.Getenv(EnableResourceEstimatesEnvVar) == "true"
+}
+
+// PodResourceRequests is the sum of the compute resources requested by the Pods of an Argo CD Application.
+type PodResourceRequests struct {
+	CPU    resource.Quantity
+	Memory resource.Quantity
+
+	// Pods is the number of Pods that were included in the sum
+	Pods int
+
+	// TotalPods is the number of running Pods of the Application, which exceeds Pods if there were more than
+	// resourceEstimatePodLimit Pods
+	TotalPods int
+}
+
+// ResourceEstimator estimates the compute resources requested by the workloads of an Argo CD Application, from the
+// Argo CD API.
+type ResourceEstimator interface {
+
+	// GetPodResourceRequests returns the sum of the resource requests of the running Pods in the resource tree of the
+	// Application.
+	GetPodResourceRequests(ctx context.Context, k8sClient client.Client, argoCDNamespace string, appName string) (PodResourceRequests, error)
+}
+
+// NewResourceEstimator returns a ResourceEstimator that logs in to Argo CD using the given CredentialService.
+func NewResourceEstimator(credentialService *CredentialService) ResourceEstimator {
+	return &argoCDResourceEstimator{credentialService: credentialService}
+}
+
+type argoCDResourceEstimator struct {
+	credentialService *CredentialService
+}
+
+func (e *argoCDResourceEstimator) GetPodResourceRequests(ctx context.Context, k8sClient client.Client, argoCDNamespace string,
+	appName string) (PodResourceRequests, error) {
+
+	namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: argoCDNamespace}}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&namespace), &namespace); err != nil {
+		return PodResourceRequests{}, fmt.Errorf("unable to retrieve Argo CD namespace '%s': %v", argoCDNamespace, err)
+	}
+
+	_, acdClient, err := e.credentialService.GetArgoCDLoginCredentials(ctx, namespace.Name, string(namespace.UID), false, k8sClient)
+	if err != nil {
+		return PodResourceRequests{}, err
+	}
+
+	conn, appIf, err := acdClient.NewApplicationClient()
+	if err != nil {
+		return PodResourceRequests{}, fmt.Errorf("unable to create application client for resource estimate: %v", err)
+	}
+	defer argoio.Close(conn)
+
+	var resourceTree *appv1.ApplicationTree
+	err = ArgoCDAPICallLimiter().Do(ctx, func() error {
+		resourceTree, err = appIf.ResourceTree(ctx, &applicationpkg.ResourcesQuery{ApplicationName: &appName})
+		return err
+	})
+	if err != nil {
+		return PodResourceRequests{}, fmt.Errorf("unable to retrieve the resource tree of Application '%s': %v", appName, err)
+	}
+
+	podNodes := findPodNodes(resourceTree.Nodes)
+
+	pods := []corev1.Pod{}
+	for _, podNode := range podNodes {
+
+		if len(pods) == resourceEstimatePodLimit {
+			break
+		}
+
+		podNode := podNode
+		var resourceResponse *applicationpkg.ApplicationResourceResponse
+		err := ArgoCDAPICallLimiter().Do(ctx, func() error {
+			var err error
+			resourceResponse, err = appIf.GetResource(ctx, &applicationpkg.ApplicationResourceRequest{
+				Name:         &appName,
+				Namespace:    &podNode.Namespace,
+				ResourceName: &podNode.Name,
+				Group:        &podNode.Group,
+				Version:      &podNode.Version,
+				Kind:         &podNode.Kind,
+			})
+			return err
+		})
+		if err != nil {
+			return PodResourceRequests{}, fmt.Errorf("unable to retrieve Pod '%s' of Application '%s': %v", podNode.Name, appName, err)
+		}
+
+		pod := corev1.Pod{}
+		if err := json.Unmarshal([]byte(resourceResponse.GetManifest()), &pod); err != nil {
+			return PodResourceRequests{}, fmt.Errorf("unable to unmarshal Pod '%s' of Application '%s': %v", podNode.Name, appName, err)
+		}
+
+		pods = append(pods, pod)
+	}
+
+	res := sumPodResourceRequests(pods)
+
+	// Pods beyond the limit were not retrieved, so it is not known whether they are running: assume they are.
+	res.TotalPods += len(podNodes) - len(pods)
+
+	return res, nil
+}
+
+// findPodNodes returns the Pods of an Application's resource tree, sorted by namespace and name (so that the same Pods
+// are retrieved on each estimate, when there are more than resourceEstimatePodLimit).
+func findPodNodes(nodes []appv1.ResourceNode) []appv1.ResourceNode {
+
+	res := []appv1.ResourceNode{}
+	for _, node := range nodes {
+		if node.Group == "" && node.Kind == "Pod" {
+			res = append(res, node)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Namespace != res[j].Namespace {
+			return res[i].Namespace < res[j].Namespace
+		}
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+// sumPodResourceRequests returns the sum of the CPU and memory requests of the given Pods. Pods that have completed
+// (succeeded or failed) are ignored, as they no longer consume the resources they requested.
+func sumPodResourceRequests(pods []corev1.Pod) PodResourceRequests {
+
+	res := PodResourceRequests{}
+
+	for _, pod := range pods {
+
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		podRequests := getPodResourceRequests(pod)
+
+		res.CPU.Add(podRequests[corev1.ResourceCPU])
+		res.Memory.Add(podRequests[corev1.ResourceMemory])
+		res.Pods++
+		res.TotalPods++
+	}
+
+	return res
+}
+
+// getPodResourceRequests returns the effective resource requests of a Pod, in the same way as the Kubernetes scheduler:
+// init containers run one at a time, before the containers, so the Pod requests the larger of the sum of the requests
+// of its containers, and the largest request of any of its init containers.
+func getPodResourceRequests(pod corev1.Pod) corev1.ResourceList {
+
+	res := corev1.ResourceList{}
+
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			sum := res[name]
+			sum.Add(quantity)
+			res[name] = sum
+		}
+	}
+
+	for _, initContainer := range pod.Spec.InitContainers {
+		for name, quantity := range initContainer.Resources.Requests {
+			if current, exists := res[name]; !exists || quantity.Cmp(current) > 0 {
+				res[name] = quantity.DeepCopy()
+			}
+		}
+	}
+
+	// The overhead of the Pod's runtime class (for example, of a sandboxed runtime) is requested in addition
+	for name, quantity := range pod.Spec.Overhead {
+		sum := res[name]
+		sum.Add(quantity)
+		res[name] = sum
+	}
+
+	return res
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Resource estimate of Argo CD Application", func() {
+
+	container := func(cpu string, memory string) corev1.Container {
+		return corev1.Container{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+
+	Context("sumPodResourceRequests", func() {
+
+		It("should sum the requests of the containers of running Pods, and ignore completed Pods", func() {
+			res := sumPodResourceRequests([]corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-a"},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{container("250m", "256Mi"), container("250m", "256Mi")}},
+					Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-b"},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{container("1", "1Gi")}},
+					Status:     corev1.PodStatus{Phase: corev1.PodPending},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "completed-job"},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{container("4", "4Gi")}},
+					Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+				},
+			})
+
+			Expect(res.CPU.String()).To(Equal("1500m"))
+			Expect(res.Memory.String()).To(Equal("1536Mi"))
+			Expect(res.Pods).To(Equal(2))
+			Expect(res.TotalPods).To(Equal(2))
+		})
+
+		It("should use the largest init container request of a Pod, if it exceeds the sum of its containers", func() {
+			res := sumPodResourceRequests([]corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-a"},
+					Spec: corev1.PodSpec{
+						InitContainers: []corev1.Container{container("2", "64Mi"), container("100m", "128Mi")},
+						Containers:     []corev1.Container{container("500m", "512Mi")},
+					},
+				},
+			})
+
+			Expect(res.CPU.String()).To(Equal("2"))
+			Expect(res.Memory.String()).To(Equal("512Mi"))
+			Expect(res.Pods).To(Equal(1))
+		})
+
+		It("should return zero requests, when there are no Pods", func() {
+			res := sumPodResourceRequests(nil)
+			Expect(res.CPU.IsZero()).To(BeTrue())
+			Expect(res.Memory.IsZero()).To(BeTrue())
+			Expect(res.Pods).To(Equal(0))
+		})
+	})
+
+	Context("findPodNodes", func() {
+
+		It("should return only the Pods of the resource tree, sorted by namespace and name", func() {
+			res := findPodNodes([]appv1.ResourceNode{
+				{ResourceRef: appv1.ResourceRef{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "ns-a", Name: "my-deployment"}},
+				{ResourceRef: appv1.ResourceRef{Version: "v1", Kind: "Pod", Namespace: "ns-b", Name: "pod-a"}},
+				{ResourceRef: appv1.ResourceRef{Version: "v1", Kind: "Pod", Namespace: "ns-a", Name: "pod-b"}},
+				{ResourceRef: appv1.ResourceRef{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "Pod", Namespace: "ns-a", Name: "pod-c"}},
+			})
+
+			Expect(res).To(HaveLen(2))
+			Expect(res[0].Name).To(Equal("pod-b"))
+			Expect(res[1].Name).To(Equal("pod-a"))
+		})
+	})
+})
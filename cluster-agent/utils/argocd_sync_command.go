@@ -18,16 +18,23 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // This contents of this file are loosely based on the 'argocd app sync' CLI command:
 // https://github.com/argoproj/argo-cd/blob/0a46d37fc6af9fe0aa963bdd845e3d799aa0320d/cmd/argocd/commands/app.go#L1333
 
+// SyncInitiatorInfoName is the name of the Argo CD sync operation info item that contains the initiator of a sync
+// operation that was requested by the GitOps Service (for example, 'GitOpsDeploymentSyncRun/my-sync-run')
+const SyncInitiatorInfoName = "gitops-service-initiator"
+
 // AppSync will trigger a synchronize application on the given Argo CD appliatication, in the given namespace.
+// - initiator (optional) describes who/what requested the sync: it is stored in the sync operation info of the Application.
+// - resources (optional) are the resources of the Application to sync: if empty, all resources of the Application are synced.
+// - retryStrategy (optional) is how Argo CD retries the sync, if it fails: if nil, the sync is not retried.
 func AppSync(ctx context.Context, appName string, revision string, namespaceName string, k8sClient client.Client,
-	credentialsService *CredentialService, skipTLSTest bool) error {
+	credentialsService *CredentialService, skipTLSTest bool, initiator string, resources []argoappv1.SyncOperationResource,
+	retryStrategy *argoappv1.RetryStrategy) error {
 
 	namespace := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -45,7 +52,12 @@ func AppSync(ctx context.Context, appName string, revision string, namespaceName
 		return err
 	}
 
-	err = appSync(ctx, acdClient, appName, false, false, revision, false, "", false, false, 0, 0, 0, 0, 0)
+	infos := []*argoappv1.Info{}
+	if initiator != "" {
+		infos = append(infos, &argoappv1.Info{Name: SyncInitiatorInfoName, Value: initiator})
+	}
+
+	err = appSync(ctx, acdClient, appName, false, false, revision, false, "", false, false, 0, retryStrategy, infos, resources)
 	if err != nil {
 		return err
 	}
@@ -55,8 +67,8 @@ func AppSync(ctx context.Context, appName string, revision string, namespaceName
 }
 
 func appSync(ctx context.Context, acdClient argocdclient.Client, appName string, dryRun bool, replace bool, revision string, prune bool,
-	strategy string, force bool, async bool, timeout uint, retryLimit int64, retryBackoffDuration time.Duration,
-	retryBackoffMaxDuration time.Duration, retryBackoffFactor int64) error {
+	strategy string, force bool, async bool, timeout uint, retryStrategy *argoappv1.RetryStrategy, infos []*argoappv1.Info,
+	selectedResources []argoappv1.SyncOperationResource) error {
 
 	conn, appIf, err := acdClient.NewApplicationClient()
 	if err != nil {
@@ -79,15 +91,21 @@ func appSync(ctx context.Context, acdClient argocdclient.Client, appName string,
 		return &syncOptions
 	}
 
+	var syncResources []*argoappv1.SyncOperationResource
+	for i := range selectedResources {
+		syncResources = append(syncResources, &selectedResources[i])
+	}
+
 	syncReq := applicationpkg.ApplicationSyncRequest{
-		Name:        &appName,
-		DryRun:      &dryRun,
-		Revision:    &revision,
-		Resources:   nil,
-		Prune:       &prune,
-		Manifests:   nil,
-		Infos:       []*argoappv1.Info{},
-		SyncOptions: syncOptionsFactory(),
+		Name:          &appName,
+		DryRun:        &dryRun,
+		Revision:      &revision,
+		Resources:     syncResources,
+		Prune:         &prune,
+		Manifests:     nil,
+		Infos:         infos,
+		SyncOptions:   syncOptionsFactory(),
+		RetryStrategy: retryStrategy,
 	}
 
 	switch strategy {
@@ -100,23 +118,16 @@ func appSync(ctx context.Context, acdClient argocdclient.Client, appName string,
 	default:
 		return fmt.Errorf("unknown sync strategy: '%s'", strategy)
 	}
-	if retryLimit > 0 {
-		syncReq.RetryStrategy = &argoappv1.RetryStrategy{
-			Limit: retryLimit,
-			Backoff: &argoappv1.Backoff{
-				Duration:    retryBackoffDuration.String(),
-				MaxDuration: retryBackoffMaxDuration.String(),
-				Factor:      pointer.Int64Ptr(retryBackoffFactor),
-			},
-		}
-	}
-	_, err = appIf.Sync(ctx, &syncReq)
+	err = ArgoCDAPICallLimiter().Do(ctx, func() error {
+		_, err := appIf.Sync(ctx, &syncReq)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
 	if !async {
-		app, err := waitOnApplicationStatus(ctx, acdClient, appName, timeout, false, false, true, false, []argoappv1.SyncOperationResource{})
+		app, err := waitOnApplicationStatus(ctx, acdClient, appName, timeout, false, false, true, false, selectedResources)
 		if err != nil {
 			return err
 		}
@@ -125,7 +136,7 @@ func appSync(ctx context.Context, acdClient argocdclient.Client, appName string,
 			operationState := app.Status.OperationState
 			if !operationState.Phase.Successful() {
 				return fmt.Errorf("operation has completed with phase: %s and message: %s", operationState.Phase, operationState.Message)
-			} else if /*len(selectedResources) == 0 &&*/ app.Status.Sync.Status != argoappv1.SyncStatusCodeSynced {
+			} else if len(selectedResources) == 0 && app.Status.Sync.Status != argoappv1.SyncStatusCodeSynced {
 				// Only get resources to be pruned if sync was application-wide and final status is not synced
 				pruningRequired := operationState.SyncResult.Resources.PruningRequired()
 				if pruningRequired > 0 {
@@ -194,7 +205,10 @@ func waitOnApplicationStatus(parentContext context.Context, acdClient argocdclie
 			}
 
 			refreshType := string(argoappv1.RefreshTypeNormal)
-			app, err = appClient.Get(context.Background(), &applicationpkg.ApplicationQuery{Name: &appName, Refresh: &refreshType})
+			err = ArgoCDAPICallLimiter().Do(context.Background(), func() error {
+				app, err = appClient.Get(context.Background(), &applicationpkg.ApplicationQuery{Name: &appName, Refresh: &refreshType})
+				return err
+			})
 			if err != nil {
 				return nil, err
 			}
@@ -216,7 +230,11 @@ func waitOnApplicationStatus(parentContext context.Context, acdClient argocdclie
 		return nil, err
 	}
 	defer argoio.Close(conn)
-	app, err := appClient.Get(ctx, &applicationpkg.ApplicationQuery{Name: &appName})
+	var app *argoappv1.Application
+	err = ArgoCDAPICallLimiter().Do(ctx, func() error {
+		app, err = appClient.Get(ctx, &applicationpkg.ApplicationQuery{Name: &appName})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +100,8 @@ var _ = Describe("ArgoCD AppSync Command", func() {
 			mockAppClient.On("NewApplicationClient").Return(mockCloser{}, mockAppServiceClient, nil)
 			appName := "my-app"
 			mockAppServiceClient.On("Sync", mock.Anything, mock.MatchedBy(func(asr *applicationpkg.ApplicationSyncRequest) bool {
-				return *asr.Name == appName && *asr.Revision == "master" && !*asr.Prune
+				return *asr.Name == appName && *asr.Revision == "master" && !*asr.Prune &&
+					len(asr.Infos) == 1 && asr.Infos[0].Name == SyncInitiatorInfoName && asr.Infos[0].Value == "GitOpsDeploymentSyncRun/my-sync-run"
 			})).Return(nil, nil)
 
 			By(" 3) After Sync, a Get occurs for the app, then a watch is setup to wait for the sync operation to finish. We provide the post-sync version of the app, to both")
@@ -124,7 +125,7 @@ var _ = Describe("ArgoCD AppSync Command", func() {
 			}
 
 			cs := NewCredentialService(&clientGenerator, true)
-			err = AppSync(context.Background(), appName, "master", "openshift-gitops", k8sClient, cs, true)
+			err = AppSync(context.Background(), appName, "master", "openshift-gitops", k8sClient, cs, true, "GitOpsDeploymentSyncRun/my-sync-run", nil, nil)
 			Expect(err).To(BeNil())
 		})
 	})
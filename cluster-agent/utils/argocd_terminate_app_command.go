@@ -44,7 +44,10 @@ func terminateOperation(ctx context.Context, appName string, argocdNamespace cor
 	}
 
 	defer argoio.Close(conn)
-	_, err = appIf.TerminateOperation(ctx, &applicationpkg.OperationTerminateRequest{Name: &appName})
+	err = ArgoCDAPICallLimiter().Do(ctx, func() error {
+		_, err := appIf.TerminateOperation(ctx, &applicationpkg.OperationTerminateRequest{Name: &appName})
+		return err
+	})
 	if err != nil {
 		return err
 	}
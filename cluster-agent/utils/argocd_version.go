@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// argoCDComponentLabel is the label of the Argo CD components, as set by the Argo CD install manifests and operator
+	argoCDComponentLabel = "app.kubernetes.io/component"
+
+	// argoCDComponentApplicationController is the value of argoCDComponentLabel on the Argo CD application controller
+	argoCDComponentApplicationController = "application-controller"
+
+	// argoCDVersionLabel is the label that may contain the version of an Argo CD component
+	argoCDVersionLabel = "app.kubernetes.io/version"
+)
+
+// DetectArgoCDVersion returns the version of the Argo CD instance in the given namespace (for example, 'v2.6.3'), from
+// the StatefulSet of its application controller:
+// - The 'app.kubernetes.io/version' label of the StatefulSet is used, if it contains a valid version.
+// - Otherwise, the tag of the image of the application controller container is used.
+//
+// An empty string is returned if the version could not be detected (for example, if the image is referenced by digest).
+func DetectArgoCDVersion(ctx context.Context, k8sClient client.Client, namespace string) (string, error) {
+
+	statefulSetList := appsv1.StatefulSetList{}
+	if err := k8sClient.List(ctx, &statefulSetList, client.InNamespace(namespace),
+		client.MatchingLabels{argoCDComponentLabel: argoCDComponentApplicationController}); err != nil {
+		return "", fmt.Errorf("unable to list StatefulSets in namespace '%s': %v", namespace, err)
+	}
+
+	for _, statefulSet := range statefulSetList.Items {
+
+		if version, err := argosharedutil.ParseArgoCDVersion(statefulSet.Labels[argoCDVersionLabel]); err == nil {
+			return version.String(), nil
+		}
+
+		for _, container := range statefulSet.Spec.Template.Spec.Containers {
+			if version, err := argosharedutil.ParseArgoCDVersion(imageTag(container.Image)); err == nil {
+				return version.String(), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// imageTag returns the tag of a container image reference, or an empty string if the image has no tag (or is referenced
+// by digest).
+func imageTag(image string) string {
+
+	if strings.Contains(image, "@") {
+		return ""
+	}
+
+	// A ':' may also separate the registry host from its port, so only a ':' after the last '/' starts the tag
+	lastSlash := strings.LastIndex(image, "/")
+	lastColon := strings.LastIndex(image, ":")
+	if lastColon <= lastSlash {
+		return ""
+	}
+
+	return image[lastColon+1:]
+}
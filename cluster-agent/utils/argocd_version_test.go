@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Argo CD version detection", func() {
+
+	Context("DetectArgoCDVersion", func() {
+
+		const namespace = "argocd"
+
+		applicationController := func(labels map[string]string, image string) *appsv1.StatefulSet {
+			return &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "argocd-application-controller",
+					Namespace: namespace,
+					Labels:    labels,
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "argocd-application-controller", Image: image}},
+						},
+					},
+				},
+			}
+		}
+
+		detectVersion := func(statefulSets ...*appsv1.StatefulSet) string {
+			scheme, _, _, _, err := tests.GenericTestSetup()
+			Expect(err).To(BeNil())
+			Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+
+			clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+			for _, statefulSet := range statefulSets {
+				clientBuilder = clientBuilder.WithObjects(statefulSet)
+			}
+
+			version, err := DetectArgoCDVersion(context.Background(), clientBuilder.Build(), namespace)
+			Expect(err).To(BeNil())
+			return version
+		}
+
+		It("should detect the version from the image tag of the application controller", func() {
+			Expect(detectVersion(applicationController(map[string]string{argoCDComponentLabel: argoCDComponentApplicationController},
+				"registry.example.com:5000/argoproj/argocd:v2.6.3"))).To(Equal("v2.6.3"))
+		})
+
+		It("should prefer the version label of the application controller", func() {
+			Expect(detectVersion(applicationController(map[string]string{
+				argoCDComponentLabel: argoCDComponentApplicationController,
+				argoCDVersionLabel:   "v2.4.1",
+			}, "quay.io/argoproj/argocd:v2.6.3"))).To(Equal("v2.4.1"))
+		})
+
+		It("should return an empty version if it can't be detected", func() {
+			By("referencing the image by digest")
+			Expect(detectVersion(applicationController(map[string]string{argoCDComponentLabel: argoCDComponentApplicationController},
+				"quay.io/argoproj/argocd@sha256:0123456789abcdef"))).To(BeEmpty())
+
+			By("not having an application controller")
+			Expect(detectVersion(applicationController(map[string]string{}, "quay.io/argoproj/argocd:v2.6.3"))).To(BeEmpty())
+		})
+	})
+
+	DescribeTable("imageTag should return the tag of an image reference",
+		func(image string, expected string) {
+			Expect(imageTag(image)).To(Equal(expected))
+		},
+		Entry("image with tag", "quay.io/argoproj/argocd:v2.6.3", "v2.6.3"),
+		Entry("image with registry port and tag", "localhost:5000/argocd:v2.5.0", "v2.5.0"),
+		Entry("image with registry port and no tag", "localhost:5000/argocd", ""),
+		Entry("image with digest", "quay.io/argoproj/argocd@sha256:abcdef", ""),
+		Entry("image with no tag", "argocd", ""),
+	)
+})
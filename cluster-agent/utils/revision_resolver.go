@@ -0,0 +1,312 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrRevisionNotFound is returned (wrapped) by a RevisionResolver when the target revision is not a branch, tag, or
+// commit SHA of the Git repository.
+var ErrRevisionNotFound = errors.New("target revision not found")
+
+// ErrRefsNotCached is returned (wrapped) by RevisionResolver.ResolveCachedRevision when the refs of the Git repository
+// are not cached: they are retrieved in the background, and the revision can be resolved once they have been.
+var ErrRefsNotCached = errors.New("refs of repository are not cached")
+
+const (
+	// revisionResolverCacheTTL is how long the list of refs of a Git repository is cached by the RevisionResolver.
+	revisionResolverCacheTTL = 3 * time.Minute
+
+	// revisionResolverFailureCacheTTL is how long a failure to list the refs of a Git repository is cached by the
+	// RevisionResolver, so that an unreachable repository is not queried on every request.
+	revisionResolverFailureCacheTTL = 30 * time.Second
+
+	// revisionResolverTimeout is the maximum amount of time to wait for a Git repository to list its refs.
+	revisionResolverTimeout = 30 * time.Second
+)
+
+// commitSHARegex matches full, and truncated, Git commit SHAs
+var commitSHARegex = regexp.MustCompile("^[0-9A-Fa-f]{7,40}$")
+
+// RevisionResolver resolves the target revision (branch, tag, or commit SHA) of a Git repository to a commit SHA, in the
+// same way as 'git ls-remote'.
+type RevisionResolver interface {
+
+	// ResolveRevision returns the commit SHA that 'targetRevision' currently refers to, in the Git repository at 'repoURL'.
+	// - The credentials of the repository are read from the Argo CD repository Secrets in 'argoCDNamespace'.
+	// - Returns an error wrapping ErrRevisionNotFound if the revision does not exist in the repository.
+	// - Returns "", nil if the repository cannot be queried by the resolver (for example, repositories accessed via SSH).
+	ResolveRevision(ctx context.Context, k8sClient client.Client, argoCDNamespace string, repoURL string, targetRevision string) (string, error)
+
+	// ResolveCachedRevision is a non-blocking variant of ResolveRevision, which only uses the cached refs of the repository.
+	// - If the refs of the repository are not cached, they are retrieved in the background, and an error wrapping
+	//   ErrRefsNotCached is returned: the caller should try again later.
+	ResolveCachedRevision(ctx context.Context, k8sClient client.Client, argoCDNamespace string, repoURL string, targetRevision string) (string, error)
+}
+
+// NewRevisionResolver returns a RevisionResolver that lists the refs of Git repositories over HTTP(S), caching the
+// results for a short period of time.
+func NewRevisionResolver() RevisionResolver {
+	return &gitRevisionResolver{
+		cache:    map[refsCacheKey]refsCacheEntry{},
+		pending:  map[refsCacheKey]bool{},
+		listRefs: listRemoteRefs,
+	}
+}
+
+type gitRevisionResolver struct {
+	mutex sync.Mutex
+
+	// cache is a map: (Argo CD namespace, repository URL) -> refs of that repository, or the error from listing them.
+	// The credentials used to list the refs are read from the Argo CD namespace, and so the refs are cached per namespace.
+	cache map[refsCacheKey]refsCacheEntry
+
+	// pending contains the repositories whose refs are being retrieved in the background, by ResolveCachedRevision
+	pending map[refsCacheKey]bool
+
+	// listRefs returns the refs of a Git repository (replaced by unit tests)
+	listRefs func(ctx context.Context, repoURL string, auth transport.AuthMethod) ([]*plumbing.Reference, error)
+}
+
+type refsCacheKey struct {
+	argoCDNamespace string
+	repoURL         string
+}
+
+type refsCacheEntry struct {
+	refs    []*plumbing.Reference
+	err     error
+	expires time.Time
+}
+
+func (r *gitRevisionResolver) ResolveRevision(ctx context.Context, k8sClient client.Client, argoCDNamespace string,
+	repoURL string, targetRevision string) (string, error) {
+
+	if !isRevisionResolvable(repoURL) {
+		return "", nil
+	}
+
+	key := refsCacheKey{argoCDNamespace: argoCDNamespace, repoURL: repoURL}
+
+	entry, exists := r.getCacheEntry(key)
+	if !exists {
+		entry = r.retrieveRefs(ctx, k8sClient, key)
+	}
+
+	if entry.err != nil {
+		return "", entry.err
+	}
+
+	return ResolveRevisionFromRefs(entry.refs, targetRevision)
+}
+
+func (r *gitRevisionResolver) ResolveCachedRevision(ctx context.Context, k8sClient client.Client, argoCDNamespace string,
+	repoURL string, targetRevision string) (string, error) {
+
+	if !isRevisionResolvable(repoURL) {
+		return "", nil
+	}
+
+	key := refsCacheKey{argoCDNamespace: argoCDNamespace, repoURL: repoURL}
+
+	entry, exists := r.getCacheEntry(key)
+	if !exists {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		if !r.pending[key] {
+			r.pending[key] = true
+
+			// The context of the caller may be cancelled once it returns, so the refs are retrieved with a new context
+			// (listRemoteRefs applies its own timeout).
+			go func() {
+				r.retrieveRefs(context.Background(), k8sClient, key)
+
+				r.mutex.Lock()
+				defer r.mutex.Unlock()
+				delete(r.pending, key)
+			}()
+		}
+
+		return "", fmt.Errorf("%w: '%s'", ErrRefsNotCached, repoURL)
+	}
+
+	if entry.err != nil {
+		return "", entry.err
+	}
+
+	return ResolveRevisionFromRefs(entry.refs, targetRevision)
+}
+
+// isRevisionResolvable returns true if the revisions of the repository can be resolved by the gitRevisionResolver.
+// Only HTTP(S) repositories are supported: SSH repositories require the known hosts of the Argo CD instance.
+func isRevisionResolvable(repoURL string) bool {
+	return strings.HasPrefix(repoURL, "https://") || strings.HasPrefix(repoURL, "http://")
+}
+
+// getCacheEntry returns the cached refs (or error) of the repository, if they have not expired.
+func (r *gitRevisionResolver) getCacheEntry(key refsCacheKey) (refsCacheEntry, bool) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.cache[key]
+	if !exists || time.Now().After(entry.expires) {
+		return refsCacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// retrieveRefs lists the refs of the repository, and caches the result: failures are cached for a shorter period of time.
+func (r *gitRevisionResolver) retrieveRefs(ctx context.Context, k8sClient client.Client, key refsCacheKey) refsCacheEntry {
+
+	entry := refsCacheEntry{expires: time.Now().Add(revisionResolverCacheTTL)}
+
+	auth, err := getRepositoryAuth(ctx, k8sClient, key.argoCDNamespace, key.repoURL)
+	if err == nil {
+		entry.refs, err = r.listRefs(ctx, key.repoURL, auth)
+		if err != nil {
+			err = fmt.Errorf("unable to list refs of repository '%s': %w", key.repoURL, err)
+		}
+	}
+
+	if err != nil {
+		entry = refsCacheEntry{err: err, expires: time.Now().Add(revisionResolverFailureCacheTTL)}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Remove expired entries, so that the cache does not grow without bound
+	for cacheKey, value := range r.cache {
+		if time.Now().After(value.expires) {
+			delete(r.cache, cacheKey)
+		}
+	}
+	r.cache[key] = entry
+
+	return entry
+}
+
+// ResolveRevisionFromRefs returns the commit SHA of the target revision, from the list of refs of a Git repository.
+// This matches the behaviour of Argo CD:
+// - an empty revision, or 'HEAD', refers to the default branch of the repository
+// - otherwise, the revision may be a full ref name, a branch name, or a tag name
+// - a (possibly truncated) commit SHA is returned as is, as commits cannot be verified without cloning the repository
+func ResolveRevisionFromRefs(refs []*plumbing.Reference, targetRevision string) (string, error) {
+
+	if targetRevision == "" {
+		targetRevision = string(plumbing.HEAD)
+	}
+
+	// map: ref name -> ref
+	refsByName := map[plumbing.ReferenceName]*plumbing.Reference{}
+	for _, ref := range refs {
+		refsByName[ref.Name()] = ref
+	}
+
+	candidates := []plumbing.ReferenceName{
+		plumbing.ReferenceName(targetRevision),
+		plumbing.NewBranchReferenceName(targetRevision),
+		plumbing.NewTagReferenceName(targetRevision),
+	}
+
+	for _, candidate := range candidates {
+		ref, exists := refsByName[candidate]
+
+		// Follow symbolic refs (for example, HEAD -> refs/heads/main), with a limit in case of a cycle
+		for i := 0; exists && ref.Type() == plumbing.SymbolicReference && i < 5; i++ {
+			ref, exists = refsByName[ref.Target()]
+		}
+
+		if exists && ref.Type() == plumbing.HashReference {
+			return ref.Hash().String(), nil
+		}
+	}
+
+	if commitSHARegex.MatchString(targetRevision) {
+		return strings.ToLower(targetRevision), nil
+	}
+
+	return "", fmt.Errorf("%w: '%s' is not a branch, tag, or commit SHA of the repository", ErrRevisionNotFound, targetRevision)
+}
+
+// getRepositoryAuth returns the credentials of the repository, from the Argo CD repository Secrets of the namespace.
+// - Returns nil if there are no credentials for the repository (for example, a public repository).
+func getRepositoryAuth(ctx context.Context, k8sClient client.Client, argoCDNamespace string, repoURL string) (transport.AuthMethod, error) {
+
+	var secretList corev1.SecretList
+	if err := k8sClient.List(ctx, &secretList, client.InNamespace(argoCDNamespace),
+		client.MatchingLabels{common.LabelKeySecretType: common.LabelValueSecretTypeRepository}); err != nil {
+		return nil, fmt.Errorf("unable to list repository secrets in namespace '%s': %w", argoCDNamespace, err)
+	}
+
+	for _, secret := range secretList.Items {
+		if normalizeRepoURL(string(secret.Data["url"])) != normalizeRepoURL(repoURL) {
+			continue
+		}
+
+		username, password := string(secret.Data["username"]), string(secret.Data["password"])
+		if username == "" && password == "" {
+			continue
+		}
+
+		return &githttp.BasicAuth{Username: username, Password: password}, nil
+	}
+
+	return nil, nil
+}
+
+// normalizeRepoURL removes the optional parts of a repository URL, so that equivalent URLs can be compared.
+func normalizeRepoURL(repoURL string) string {
+	repoURL = strings.ToLower(strings.TrimSuffix(repoURL, "/"))
+	return strings.TrimSuffix(repoURL, ".git")
+}
+
+// listRemoteRefs lists the refs of a Git repository, as with 'git ls-remote'.
+func listRemoteRefs(ctx context.Context, repoURL string, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, revisionResolverTimeout)
+	defer cancel()
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repoURL},
+	})
+
+	type result struct {
+		refs []*plumbing.Reference
+		err  error
+	}
+
+	// Buffered, so that the goroutine can exit if the context is cancelled before the refs are listed
+	resultChan := make(chan result, 1)
+
+	go func() {
+		refs, err := remote.List(&git.ListOptions{Auth: auth})
+		resultChan <- result{refs: refs, err: err}
+	}()
+
+	select {
+	case res := <-resultChan:
+		return res.refs, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Revision resolver", func() {
+
+	const (
+		mainSHA    = "0a46d37fc6af9fe0aa963bdd845e3d799aa0320d"
+		releaseSHA = "98ccd3d43fd9a2f0d9d4ac77b5b29d8f45f9a1b2"
+		tagSHA     = "cdbe64fb0c91b7b6e2d0b6d48ad7d3f1f1e2c3a4"
+	)
+
+	refs := []*plumbing.Reference{
+		plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main")),
+		plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.NewHash(mainSHA)),
+		plumbing.NewHashReference(plumbing.NewBranchReferenceName("release-1.0"), plumbing.NewHash(releaseSHA)),
+		plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.0.0"), plumbing.NewHash(tagSHA)),
+	}
+
+	DescribeTable("should resolve the target revision to a commit SHA",
+		func(targetRevision string, expectedSHA string) {
+			resolved, err := ResolveRevisionFromRefs(refs, targetRevision)
+			Expect(err).To(BeNil())
+			Expect(resolved).To(Equal(expectedSHA))
+		},
+		Entry("empty revision resolves to the default branch", "", mainSHA),
+		Entry("HEAD resolves to the default branch", "HEAD", mainSHA),
+		Entry("branch name", "release-1.0", releaseSHA),
+		Entry("full branch ref name", "refs/heads/main", mainSHA),
+		Entry("tag name", "v1.0.0", tagSHA),
+		Entry("commit SHA that is not the tip of a branch", "b5b29d8f45f9a1b2", "b5b29d8f45f9a1b2"),
+	)
+
+	It("should return ErrRevisionNotFound if the revision is not a branch, tag, or commit SHA", func() {
+		_, err := ResolveRevisionFromRefs(refs, "mian")
+		Expect(errors.Is(err, ErrRevisionNotFound)).To(BeTrue())
+	})
+
+	Context("gitRevisionResolver", func() {
+
+		var resolver *gitRevisionResolver
+		var listRefsCalls int32
+		var listRefsAuth transport.AuthMethod
+		var listRefsErr error
+		var k8sClient *fake.ClientBuilder
+
+		BeforeEach(func() {
+			listRefsCalls = 0
+			listRefsAuth = nil
+			listRefsErr = nil
+
+			resolver = &gitRevisionResolver{
+				cache:   map[refsCacheKey]refsCacheEntry{},
+				pending: map[refsCacheKey]bool{},
+				listRefs: func(ctx context.Context, repoURL string, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+					atomic.AddInt32(&listRefsCalls, 1)
+					listRefsAuth = auth
+					if listRefsErr != nil {
+						return nil, listRefsErr
+					}
+					return refs, nil
+				},
+			}
+
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme)
+		})
+
+		It("should cache the refs of a repository", func() {
+			c := k8sClient.Build()
+
+			resolved, err := resolver.ResolveRevision(context.Background(), c, "argocd", "https://github.com/test/repo", "main")
+			Expect(err).To(BeNil())
+			Expect(resolved).To(Equal(mainSHA))
+
+			_, err = resolver.ResolveRevision(context.Background(), c, "argocd", "https://github.com/test/repo", "mian")
+			Expect(errors.Is(err, ErrRevisionNotFound)).To(BeTrue())
+
+			Expect(atomic.LoadInt32(&listRefsCalls)).To(Equal(int32(1)))
+
+			By("listing the refs again for a different Argo CD namespace, as its credentials may differ")
+			_, err = resolver.ResolveRevision(context.Background(), c, "other-argocd", "https://github.com/test/repo", "main")
+			Expect(err).To(BeNil())
+			Expect(atomic.LoadInt32(&listRefsCalls)).To(Equal(int32(2)))
+		})
+
+		It("should cache failures to list the refs of a repository", func() {
+			c := k8sClient.Build()
+			listRefsErr = fmt.Errorf("connection refused")
+
+			_, err := resolver.ResolveRevision(context.Background(), c, "argocd", "https://github.com/test/repo", "main")
+			Expect(err).ToNot(BeNil())
+			Expect(errors.Is(err, ErrRevisionNotFound)).To(BeFalse())
+
+			_, err = resolver.ResolveRevision(context.Background(), c, "argocd", "https://github.com/test/repo", "main")
+			Expect(err).To(MatchError(ContainSubstring("connection refused")))
+
+			Expect(atomic.LoadInt32(&listRefsCalls)).To(Equal(int32(1)))
+		})
+
+		It("should retrieve the refs of a repository in the background, with ResolveCachedRevision", func() {
+			c := k8sClient.Build()
+
+			_, err := resolver.ResolveCachedRevision(context.Background(), c, "argocd", "https://github.com/test/repo", "main")
+			Expect(errors.Is(err, ErrRefsNotCached)).To(BeTrue())
+
+			Eventually(func() (string, error) {
+				return resolver.ResolveCachedRevision(context.Background(), c, "argocd", "https://github.com/test/repo", "main")
+			}, "5s", "10ms").Should(Equal(mainSHA))
+
+			Expect(atomic.LoadInt32(&listRefsCalls)).To(Equal(int32(1)))
+		})
+
+		It("should use the credentials of the Argo CD repository Secret of the repository", func() {
+			c := k8sClient.WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "private-repo",
+					Namespace: "argocd",
+					Labels:    map[string]string{common.LabelKeySecretType: common.LabelValueSecretTypeRepository},
+				},
+				Data: map[string][]byte{
+					"url":      []byte("https://github.com/test/private-repo.git"),
+					"username": []byte("my-user"),
+					"password": []byte("my-password"),
+				},
+			}).Build()
+
+			_, err := resolver.ResolveRevision(context.Background(), c, "argocd", "https://github.com/test/private-repo", "main")
+			Expect(err).To(BeNil())
+			Expect(listRefsAuth).To(Equal(&githttp.BasicAuth{Username: "my-user", Password: "my-password"}))
+		})
+
+		It("should not validate the revision of repositories that are not accessed via HTTP(S)", func() {
+			resolved, err := resolver.ResolveRevision(context.Background(), k8sClient.Build(), "argocd", "git@github.com:test/repo.git", "mian")
+			Expect(err).To(BeNil())
+			Expect(resolved).To(BeEmpty())
+			Expect(atomic.LoadInt32(&listRefsCalls)).To(Equal(int32(0)))
+		})
+	})
+})
@@ -49,7 +49,29 @@ CREATE TABLE ClusterCredentials (
 
 	-- Whether or not Argo CD is able to deploy cluster-scoped resources using these cluster credentials
 	-- - This corresponds to the Argo CD cluster secret field of the same name.
-	cluster_resources BOOLEAN DEFAULT FALSE
+	cluster_resources BOOLEAN DEFAULT FALSE,
+
+	-- Optional: the value of .spec.rotateCredentialsRequestedAt of the GitOpsDeploymentManagedEnvironment (in RFC3339 format),
+	-- at the time these cluster credentials were created. Used to detect when the user has requested a credential rotation.
+	rotation_requested_at VARCHAR (64),
+
+	-- Optional: hints for the CPU/memory quota of the namespaces deployed to with these cluster credentials (in Kubernetes
+	-- quantity format). Corresponds to .spec.namespaceQuota of the GitOpsDeploymentManagedEnvironment.
+	namespace_quota_cpu VARCHAR (64),
+	namespace_quota_memory VARCHAR (64),
+
+	-- Optional: the name of the existing Argo CD cluster secret that these cluster credentials were read from, if the
+	-- GitOpsDeploymentManagedEnvironment references one via .spec.argoCDClusterSecret (rather than a kubeconfig Secret).
+	argocd_cluster_secret VARCHAR (253),
+
+	-- Optional: the URL of the HTTP(S) proxy to connect to the cluster through. If empty, the proxy environment variables
+	-- (HTTPS_PROXY/NO_PROXY) of the component connecting to the cluster are used.
+	-- Corresponds to .spec.proxyURL of the GitOpsDeploymentManagedEnvironment.
+	proxy_url VARCHAR (512),
+
+	-- Optional: a hash of the data of the Secret that these cluster credentials were read from. Used to detect when the
+	-- contents of the Secret have changed (for example, when a token is refreshed), so that the credentials may be rotated.
+	secret_data_hash VARCHAR (64)
 
 );
 
@@ -91,7 +113,15 @@ CREATE TABLE GitopsEngineInstance (
 	-- Reference to the Argo CD cluster containing the instance
 	-- Foreign key to: GitopsEngineCluster.gitopsenginecluster_id
 	enginecluster_id VARCHAR(48) NOT NULL,
-	CONSTRAINT fk_gitopsengine_cluster FOREIGN KEY (enginecluster_id) REFERENCES GitopsEngineCluster(gitopsenginecluster_id) ON DELETE NO ACTION ON UPDATE NO ACTION
+	CONSTRAINT fk_gitopsengine_cluster FOREIGN KEY (enginecluster_id) REFERENCES GitopsEngineCluster(gitopsenginecluster_id) ON DELETE NO ACTION ON UPDATE NO ACTION,
+
+	-- The maximum number of Applications that may be assigned to the instance: once reached, new Applications are
+	-- assigned to another instance on the same cluster (if one has capacity). 0 if the instance has no limit.
+	max_applications INTEGER NOT NULL DEFAULT 0,
+
+	-- The version of Argo CD of the instance (for example, 'v2.6.3'), as last detected by the cluster-agent.
+	-- NULL/empty if the version is not known.
+	argocd_version VARCHAR (64)
 	
 );
 
@@ -113,7 +143,27 @@ CREATE TABLE ManagedEnvironment (
 	CONSTRAINT fk_cluster_credential FOREIGN KEY (clustercredentials_id) REFERENCES ClusterCredentials(clustercredentials_cred_id) ON DELETE NO ACTION ON UPDATE NO ACTION,
 
     -- When ManagedEnvironment was created, which allow us to tell how old the resources are
-	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	-- Incremented on every update of the row: used to detect concurrent modification (optimistic locking)
+	version BIGINT NOT NULL DEFAULT 0,
+
+	-- Optional: the Kubernetes version of the cluster (for example, 'v1.25.3'), as discovered when the GitOps Service
+	-- last connected to it. Empty if not yet discovered.
+	kubernetes_version VARCHAR (64),
+
+	-- Optional: a sorted, comma-separated list of the API groups that are available on the cluster (not including the
+	-- core API group), as discovered when the GitOps Service last connected to it. Empty if not yet discovered.
+	api_groups VARCHAR (8192),
+
+	-- True if the user has disconnected the managed environment (.spec.disconnected of the GitOpsDeploymentManagedEnvironment):
+	-- the Argo CD cluster secret of the managed environment is removed, but the Applications that target it are retained.
+	disconnected BOOLEAN NOT NULL DEFAULT FALSE,
+
+	-- Optional: a JSON object of the labels that describe the topology of the cluster, for example:
+	-- '{"region": "us-east-1", "cloud": "aws", "tier": "production"}' (.spec.clusterLabels of the GitOpsDeploymentManagedEnvironment).
+	-- Managed environments may be queried by label, with the JSONB containment operator ('@>').
+	cluster_labels JSONB
 );
 
 
@@ -133,7 +183,11 @@ CREATE TABLE ClusterUser (
 	seq_id serial,
 
 	 -- When ClusterUser was created, which allow us to tell how old the resources are
-	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	-- When the ClusterUser was deactivated (offboarded), or NULL if it is active. A deactivated user has no ClusterAccess,
+	-- and may be purged once its RepositoryCredentials and Applications have been deleted.
+	deactivated_on TIMESTAMP
 );
 
 
@@ -231,7 +285,15 @@ CREATE TABLE Operation (
 	human_readable_state VARCHAR ( 1024 ),
 
 	-- Amount of time to wait in seconds after last_state_update for a completed/failed operation to be garbage collected.
-	gc_expiration_time INT
+	gc_expiration_time INT,
+
+	-- The identity of the cluster-agent replica that is processing the operation, while it is In_Progress.
+	-- If the replica stops being the leader before the operation completes, the next leader returns the operation to Waiting.
+	processing_owner VARCHAR (128),
+
+	-- Set when an In_Progress operation is returned to Waiting during the graceful shutdown of the cluster-agent replica
+	-- that was processing it, as '(processing owner)_(time)'. It is cleared once the operation is resumed.
+	resume_token VARCHAR (160)
 
 );
 
@@ -262,7 +324,15 @@ CREATE TABLE Application (
 	seq_id serial,
     
 	-- When Application was created, which allow us to tell how old the resources are
-	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	-- When the Application was soft-deleted (its GitOpsDeployment was deleted), or NULL if it has not been deleted.
+	-- Soft-deleted Applications may be restored until the soft-delete grace period has elapsed, after which they are
+	-- deleted by the database reconciler.
+	deleted_on TIMESTAMP,
+
+	-- Incremented on every update of the row: used to detect concurrent modification (optimistic locking)
+	version BIGINT NOT NULL DEFAULT 0
 
 );
 
@@ -297,6 +367,10 @@ CREATE TABLE ApplicationState (
 	sync_status VARCHAR (30) NOT NULL,
 
 	-- resources field comes directly from Argo CD Application CR's .Status.Resources field
+	-- - The field is stored as a gzip-compressed YAML string (see 'compressResourceData' in the cluster-agent)
+	-- - As it may be large, it should only be rewritten when the resources have changed: health-only changes should
+	--   use the 'UpdateApplicationStateHealthOnly' query, which only updates the health/message columns. The
+	--   'UpsertApplicationStates' query only rewrites it if it has changed.
 	resources bytea,
 
 	-- reconciled_state is a JSON string, which contains the contents of the Argo CD Application's .status.sync.comparedTo, but
@@ -305,7 +379,37 @@ CREATE TABLE ApplicationState (
 	reconciled_state VARCHAR (4096),
 
 	-- sync_error is a string, which contains the Argo CD Application's .status.conditions.message which is of type SyncError
-	sync_error VARCHAR (4096)
+	sync_error VARCHAR (4096),
+
+	-- last_sync is a JSON string, which contains details of the last sync operation of the Argo CD Application (from
+	-- .status.operationState): who/what initiated it, when it started/finished, and the revision that was synced.
+	last_sync VARCHAR (2048),
+
+	-- resolved_revision is the commit SHA that the Argo CD Application's .spec.source.targetRevision resolved to
+	resolved_revision VARCHAR (1024),
+
+	-- revision_error is non-empty if the .spec.source.targetRevision could not be found in the Git repository
+	revision_error VARCHAR (4096),
+
+	-- diff_preview is a JSON string, which contains a summary of the changes that a sync of the Argo CD Application would
+	-- make (the resources that would be created/updated/deleted, and the number of changed fields of each). It is only
+	-- generated when requested, via the 'managed-gitops.redhat.com/diff-preview' annotation of the GitOpsDeployment.
+	diff_preview VARCHAR (16384),
+
+	-- resource_estimate is a JSON string, which contains the sum of the CPU and memory requests of the running Pods of the
+	-- Argo CD Application (collected from the resource tree of the Application). It is only collected when resource
+	-- estimates are enabled in the cluster-agent (via the ENABLE_RESOURCE_ESTIMATES environment variable).
+	resource_estimate VARCHAR (2048),
+
+	-- sync_hooks is a JSON string, which contains the status of the resource hooks (for example, PreSync and PostSync Jobs)
+	-- that were run by the last sync operation of the Argo CD Application, including the tail of the logs of failed hook
+	-- Jobs. It is only stored for sync operations that were requested by a GitOpsDeploymentSyncRun.
+	sync_hooks VARCHAR (16384),
+
+	-- operation_state is a JSON string, which contains the details of the sync operation of the Argo CD Application that
+	-- is currently in progress (from .status.operationState), including an estimate of when it will complete. It is empty
+	-- if no sync operation is in progress.
+	operation_state VARCHAR (2048)
 );
 
 -- Represents the relationship from GitOpsDeployment CR in the API namespace, to an Application table row.
@@ -334,10 +438,18 @@ CREATE TABLE DeploymentToApplicationMapping (
 	application_id VARCHAR ( 48 ) NOT NULL UNIQUE,
 	CONSTRAINT fk_app_id FOREIGN KEY (application_id) REFERENCES Application(application_id) ON DELETE NO ACTION ON UPDATE NO ACTION,
 
+	-- The uid of the deleted GitOpsDeployment CR whose soft-deleted Application was restored for this GitOpsDeployment CR,
+	-- or NULL if the Application was created for this GitOpsDeployment CR. The name of the Argo CD Application is
+	-- generated from this uid.
+	restored_from_uid VARCHAR ( 48 ),
+
 	seq_id serial
 
 );
 
+-- Used to list the DeploymentToApplicationMappings of a namespace in pages, ordered by seq_id (keyset pagination)
+CREATE INDEX idx_dtam_namespace_uid_seq_id ON DeploymentToApplicationMapping(namespace_uid, seq_id);
+
 -- Represents a generic relationship between: Kubernetes CR <->  Database table
 -- The Kubernetes CR can be either in the API namespace, or in/on a GitOpsEngine cluster namespace.
 --
@@ -435,6 +547,10 @@ CREATE TABLE APICRToDatabaseMapping  (
 
 	seq_id serial,
 
+	-- When the mapping was created: mappings that were created before the GitOps Service was (re)started may
+	-- reference API CRs that were deleted while the service was down.
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
 	PRIMARY KEY(api_resource_type, api_resource_uid, db_relation_type, db_relation_key)
 
 );
@@ -462,6 +578,22 @@ CREATE TABLE SyncOperation (
 	-- values: Running, Terminated
 	desired_state VARCHAR(16) NOT NULL,	
 
+	-- Who/what requested the sync operation, for example: 'GitOpsDeploymentSyncRun/(name of the SyncRun)'
+	initiator VARCHAR(512),
+
+	-- resources is a JSON string, which contains the list of resources to sync (see fauxargocd.FauxSyncOperationResource).
+	-- If empty, all the resources of the Application are synced.
+	resources VARCHAR(4096),
+
+	-- The 'retryPolicy' field of the GitOpsDeploymentSyncRun CR, which is passed to Argo CD as the retry strategy of the sync.
+	-- retry_limit is the maximum number of times a failed sync is retried: 0 if the sync should not be retried.
+	retry_limit INTEGER NOT NULL DEFAULT 0,
+
+	-- The backoff of the retries, for example: '5s' and '3m'. If empty/0, the Argo CD defaults are used.
+	retry_backoff_duration VARCHAR(32),
+	retry_backoff_factor INTEGER NOT NULL DEFAULT 0,
+	retry_backoff_max_duration VARCHAR(32),
+
 	seq_id serial,
 
     -- When SyncOperation was created, which allow us to tell how old the resources are
@@ -488,10 +620,12 @@ CREATE TABLE RepositoryCredentials (
     repo_cred_user VARCHAR (256),
 
     -- Authorized password login for accessing the private Git repo
-    repo_cred_pass VARCHAR (1024),
+    -- (encrypted with the data key of the user, from the KeyStore table, if a master key is configured)
+    repo_cred_pass VARCHAR (2048),
 
     -- Alternative authentication method using an authorized private SSH key
-    repo_cred_ssh VARCHAR (1024),
+    -- (encrypted with the data key of the user, from the KeyStore table, if a master key is configured)
+    repo_cred_ssh VARCHAR (2048),
 
     -- The name of the Secret resource in the Argo CD Repository, in the GitOps Engine instance
     repo_cred_secret VARCHAR(48) NOT NULL,
@@ -502,6 +636,14 @@ CREATE TABLE RepositoryCredentials (
     repo_cred_engine_id VARCHAR(48) NOT NULL,
     CONSTRAINT fk_gitopsengineinstance_id FOREIGN KEY (repo_cred_engine_id) REFERENCES GitopsEngineInstance(gitopsengineinstance_id) ON DELETE NO ACTION ON UPDATE NO ACTION,
 
+    -- Optional: the name of the Argo CD AppProject that the credentials are scoped to (if the GitOpsDeploymentRepositoryCredential
+    -- has a .spec.scope). The credentials are then only used by the Argo CD Applications of that AppProject.
+    repo_cred_project VARCHAR(128),
+
+    -- True if the row is a credential template (from a GitOpsDeploymentRepositoryCredentialTemplate): 'repo_cred_url' is
+    -- then a URL prefix, and the credentials are used for every Git repository whose URL starts with that prefix.
+    repo_cred_template BOOLEAN NOT NULL DEFAULT FALSE,
+
     seq_id serial,
 
     -- When RepositoryCredentials was created, which allow us to tell how old the resources are
@@ -509,6 +651,196 @@ CREATE TABLE RepositoryCredentials (
 
 );
 
+-- KeyStore contains the data keys of each ClusterUser, which are used to encrypt the sensitive columns of the
+-- RepositoryCredentials rows owned by that user. Each data key is encrypted ('wrapped') with the master key.
+-- The most recently created row of a user is the active data key of that user.
+CREATE TABLE KeyStore (
+
+	-- Primary Key, that is an auto-generated UID
+	keystore_id VARCHAR ( 48 ) NOT NULL UNIQUE PRIMARY KEY,
+
+	-- User that owns the data key
+	-- Foreign key to: ClusterUser.Clusteruser_id
+	keystore_user_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_keystore_clusteruser_id FOREIGN KEY (keystore_user_id) REFERENCES ClusterUser(clusteruser_id) ON DELETE CASCADE ON UPDATE NO ACTION,
+
+	-- The base64-encoded data key, encrypted with the master key
+	wrapped_key VARCHAR (256) NOT NULL,
+
+	-- Identifies the master key that the data key was encrypted with
+	master_key_id VARCHAR (64) NOT NULL,
+
+	seq_id serial,
+
+	-- When the KeyStore was created, which allow us to tell how old the resources are
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_keystore_user_id ON KeyStore(keystore_user_id);
+
+-- ApplicationOwner associates a ClusterUser with an Application that the user owns. An Application may have more than
+-- one owner: for example, the members of a shared workspace that all deploy (and view) the same GitOpsDeployment.
+-- ApplicationOwner rows are used both for access checks, and for attributing changes to an Application to its owners.
+CREATE TABLE ApplicationOwner (
+
+	-- The Application that is owned
+	-- Foreign key to: Application.application_id
+	applicationowner_application_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_applicationowner_application_id FOREIGN KEY (applicationowner_application_id) REFERENCES Application(application_id) ON DELETE CASCADE ON UPDATE NO ACTION,
+
+	-- The ClusterUser that owns the Application
+	-- Foreign key to: ClusterUser.clusteruser_id
+	applicationowner_user_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_applicationowner_clusteruser_id FOREIGN KEY (applicationowner_user_id) REFERENCES ClusterUser(clusteruser_id) ON DELETE CASCADE ON UPDATE NO ACTION,
+
+	seq_id serial,
+
+	-- When the ApplicationOwner was created, which allow us to tell how old the resources are
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	PRIMARY KEY(applicationowner_application_id, applicationowner_user_id)
+);
+
+CREATE INDEX idx_applicationowner_user_id ON ApplicationOwner(applicationowner_user_id);
+
+-- FeatureFlag allows behaviours of the GitOps Service components to be toggled at runtime, without redeploying them.
+-- A flag may be set for the whole installation (featureflag_namespace is empty), or for a single namespace: a
+-- namespace-scoped row takes precedence over the installation-wide row of the same flag.
+-- See 'backend-shared/util/featureflags' for the client that is used to consult the flags.
+CREATE TABLE FeatureFlag (
+
+	-- Primary key for the FeatureFlag (UID), is a random UUID
+	featureflag_id VARCHAR (48) NOT NULL PRIMARY KEY,
+
+	-- The name of the flag, for example: 'batched-operations'
+	featureflag_name VARCHAR (64) NOT NULL,
+
+	-- The namespace that the flag applies to, or empty if the flag applies to the whole installation
+	featureflag_namespace VARCHAR (63) NOT NULL DEFAULT '',
+
+	-- Whether the behaviour is enabled
+	enabled BOOLEAN NOT NULL DEFAULT FALSE,
+
+	seq_id serial,
+
+	-- When the FeatureFlag was created, which allow us to tell how old the resources are
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	UNIQUE (featureflag_name, featureflag_namespace)
+);
+
+-- DeploymentHistory records each revision that was successfully deployed (synced) by Argo CD for an Application,
+-- allowing users to determine what has been running in an environment over time.
+-- - Rows are written by the cluster-agent, from the '.status.history' field of the Argo CD Application.
+-- - The most recent rows of an Application are reported in the '.status.history' field of the GitOpsDeployment.
+CREATE TABLE DeploymentHistory (
+
+	-- Primary key for the DeploymentHistory (UID), is a random UUID
+	deploymenthistory_id VARCHAR (48) NOT NULL PRIMARY KEY,
+
+	-- The Application that deployed the revision
+	-- Foreign key to: Application.application_id
+	deploymenthistory_application_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_deploymenthistory_application_id FOREIGN KEY (deploymenthistory_application_id) REFERENCES Application(application_id) ON DELETE CASCADE ON UPDATE NO ACTION,
+
+	-- The ID of the corresponding entry in the Argo CD Application's .status.history field (IDs increase with each sync)
+	argocd_history_id BIGINT NOT NULL,
+
+	-- The Git commit SHA that was deployed
+	revision VARCHAR (256),
+
+	-- Comma-separated list of the container images that were deployed by the revision, if known
+	images VARCHAR (4096),
+
+	-- Who/what initiated the sync that deployed the revision, if known (see SyncOperation.initiator)
+	initiator VARCHAR (512),
+
+	-- When the sync that deployed the revision completed
+	deployed_at TIMESTAMP NOT NULL,
+
+	seq_id serial,
+
+	-- When the DeploymentHistory was created, which allow us to tell how old the resources are
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	UNIQUE (deploymenthistory_application_id, argocd_history_id)
+);
+
+-- NamespaceOffboarding records the progress of the offboarding of a namespace: the deletion of all the database rows
+-- (Applications, SyncOperations, RepositoryCredentials, ManagedEnvironments, and the rows that map them to API resources)
+-- of the namespace.
+-- - The row is created when offboarding begins, and deleted once it is complete: a row that still exists on startup
+--   indicates an offboarding that was interrupted, and which is resumed by the backend.
+CREATE TABLE NamespaceOffboarding (
+
+	-- The UID of the namespace that is being offboarded
+	namespace_uid VARCHAR (48) NOT NULL PRIMARY KEY,
+
+	-- The name of the namespace that is being offboarded
+	namespace_name VARCHAR (63) NOT NULL,
+
+	-- The offboarding step that is currently in progress: the steps before it have completed.
+	step VARCHAR (32) NOT NULL,
+
+	seq_id serial,
+
+	-- When the offboarding began
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- MigrationOperation records the progress of the migration of the Applications of a namespace (workspace), from one
+-- GitopsEngineInstance (Argo CD instance) to another.
+-- - The row is created when the migration begins, and deleted once it is complete: a row that still exists on startup
+--   indicates a migration that was interrupted, and which is resumed by the backend.
+CREATE TABLE MigrationOperation (
+
+	-- The UID of the namespace whose Applications are being migrated
+	namespace_uid VARCHAR (48) NOT NULL PRIMARY KEY,
+
+	-- The name of the namespace whose Applications are being migrated
+	namespace_name VARCHAR (63) NOT NULL,
+
+	-- The GitopsEngineInstance that the Applications are being migrated from
+	source_gitopsengineinstance_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_source_gitopsengineinstance_id FOREIGN KEY (source_gitopsengineinstance_id) REFERENCES GitopsEngineInstance(gitopsengineinstance_id) ON DELETE NO ACTION ON UPDATE NO ACTION,
+
+	-- The GitopsEngineInstance that the Applications are being migrated to
+	target_gitopsengineinstance_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_target_gitopsengineinstance_id FOREIGN KEY (target_gitopsengineinstance_id) REFERENCES GitopsEngineInstance(gitopsengineinstance_id) ON DELETE NO ACTION ON UPDATE NO ACTION,
+
+	-- The migration step that is currently in progress: the steps before it have completed.
+	step VARCHAR (32) NOT NULL,
+
+	-- When the step that is currently in progress began
+	step_started_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	seq_id serial,
+
+	-- When the migration began
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- TaskCheckpoint records the progress of a long-running maintenance task (for example, a cleanup of orphaned database
+-- rows), so that a task that was interrupted can resume from where it stopped.
+-- - The row is created when the task first records its progress, and deleted once the task is complete: a row that
+--   still exists on startup indicates a task that was interrupted, and which is resumed by the backend.
+CREATE TABLE TaskCheckpoint (
+
+	-- The unique name of the task
+	task_name VARCHAR (128) NOT NULL PRIMARY KEY,
+
+	-- The progress of the task: the format is defined by the task
+	checkpoint VARCHAR (1024) NOT NULL,
+
+	-- When the progress of the task was last recorded
+	last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	seq_id serial,
+
+	-- When the task began
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 /*
 -------------------------------------------------------------------------------
 
@@ -551,6 +883,8 @@ SyncOperation -> Application
 
 ApplicationState ->  Application
 
+DeploymentHistory -> Application
+
 DeploymentToApplicationMapping -> Application
 
 Operation -> ClusterUser
@@ -563,6 +897,8 @@ ClusterAccess -> ClusterUser
 ClusterAccess -> ManagedEnvironment
 ClusterAccess -> GitopsEngineInstance
 
+MigrationOperation -> GitopsEngineInstance
+
 GitopsEngineInstance -> GitopsEngineCluster
 
 GitopsEngineCluster -> ClusterCredentials
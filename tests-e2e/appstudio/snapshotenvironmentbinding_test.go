@@ -45,13 +45,13 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler E2E tests", func() {
 				application := buildApplication("new-demo-app", fixture.GitOpsServiceE2ENamespace, "https://github.com/redhat-appstudio/managed-gitops")
 				err = k8s.Create(&application, k8sClient)
 				Expect(err).To(Succeed())
-
-				// Create a snapshot for the application
-				snapshot := buildSnapshot("my-snapshot", fixture.GitOpsServiceE2ENamespace, "new-demo-app")
-				err = k8s.Create(&snapshot, k8sClient)
-				Expect(err).To(Succeed())
 			}
 
+			By("creating the Snapshot referenced by the bindings, which the binding preflight checks require")
+			snapshot := buildSnapshot("my-snapshot", fixture.GitOpsServiceE2ENamespace, "new-demo-app", "component-a", "component-b")
+			err = k8s.Create(&snapshot, k8sClient)
+			Expect(err).To(Succeed())
+
 		})
 
 		// This test is to verify the scenario when a user creates an SnapshotEnvironmentBinding CR in Cluster.
@@ -560,21 +560,37 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler E2E tests", func() {
 		})
 
 		It("should create a GitOpsDeployment that references cluster credentials specified in Environment", func() {
-			// ToDo: solve GITOPSRVC-217, and remove this constraint
 
 			k8sClient, err := fixture.GetE2ETestUserWorkspaceKubeClient()
 			Expect(err).To(Succeed())
 
-			By("creating second managed environment Secret")
+			By("creating a ServiceAccount, and a managed environment Secret containing its bearer token")
+			serviceAccount := corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gitops-binding-test-service-account",
+					Namespace: fixture.GitOpsServiceE2ENamespace,
+				},
+			}
+			err = k8s.Create(&serviceAccount, k8sClient)
+			Expect(err).To(Succeed())
+
+			err = k8s.CreateOrUpdateClusterRoleAndRoleBinding(context.Background(), "123", k8sClient, serviceAccount.Name, serviceAccount.Namespace, k8s.ArgoCDManagerNamespacePolicyRules)
+			Expect(err).To(BeNil())
+
+			tokenSecret, err := k8s.CreateServiceAccountBearerToken(context.Background(), k8sClient, serviceAccount.Name, serviceAccount.Namespace)
+			Expect(err).To(BeNil())
+			Expect(tokenSecret).NotTo(BeNil())
+
+			_, apiServerURL, err := fixture.ExtractKubeConfigValues()
+			Expect(err).To(BeNil())
+
 			secret := corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "my-secret",
 					Namespace: environment.Namespace,
 				},
-				Type: sharedutil.ManagedEnvironmentSecretType,
-				Data: map[string][]byte{
-					"kubeconfig": ([]byte)("{}"),
-				},
+				Type:       sharedutil.ManagedEnvironmentSecretType,
+				StringData: map[string]string{"kubeconfig": k8s.GenerateKubeConfig(apiServerURL, fixture.GitOpsServiceE2ENamespace, tokenSecret)},
 			}
 			err = k8s.Create(&secret, k8sClient)
 			Expect(err).To(BeNil())
@@ -584,9 +600,10 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler E2E tests", func() {
 
 			environment.Spec.UnstableConfigurationFields = &appstudiosharedv1.UnstableEnvironmentConfiguration{
 				KubernetesClusterCredentials: appstudiosharedv1.KubernetesClusterCredentials{
-					TargetNamespace:          fixture.GitOpsServiceE2ENamespace,
-					APIURL:                   "https://api-url",
-					ClusterCredentialsSecret: "my-secret",
+					TargetNamespace:            fixture.GitOpsServiceE2ENamespace,
+					APIURL:                     apiServerURL,
+					ClusterCredentialsSecret:   secret.Name,
+					AllowInsecureSkipTLSVerify: true,
 				},
 			}
 
@@ -605,6 +622,11 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler E2E tests", func() {
 				[]string{"resources/test-data/sample-gitops-repository/components/componentA/overlays/staging"}, &binding)
 			Expect(err).To(Succeed())
 
+			By("waiting for the binding to report that the ManagedEnvironment of the Environment is connected")
+			Eventually(binding, "2m", "1s").Should(bindingFixture.HaveBindingCondition(
+				appstudiocontroller.SnapshotEnvironmentBindingConditionManagedEnvironmentConnected, metav1.ConditionTrue,
+				appstudiocontroller.SnapshotEnvironmentBindingReasonManagedEnvironmentConnected))
+
 			By("waiting for the the controller to Reconcile the GitOpsDeplyoment")
 			gitOpsDeploymentName := appstudiocontroller.GenerateBindingGitOpsDeploymentName(binding, binding.Spec.Components[0].Name)
 
@@ -627,6 +649,69 @@ var _ = Describe("SnapshotEnvironmentBinding Reconciler E2E tests", func() {
 					"the namespace of the GitOpsDeployment should come from the Environment")
 		})
 
+		It("should not create a GitOpsDeployment while the cluster credentials specified in Environment are unable to connect", func() {
+
+			k8sClient, err := fixture.GetE2ETestUserWorkspaceKubeClient()
+			Expect(err).To(Succeed())
+
+			By("creating a managed environment Secret with invalid credentials")
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-secret",
+					Namespace: environment.Namespace,
+				},
+				Type: sharedutil.ManagedEnvironmentSecretType,
+				Data: map[string][]byte{
+					"kubeconfig": ([]byte)("{}"),
+				},
+			}
+			err = k8s.Create(&secret, k8sClient)
+			Expect(err).To(BeNil())
+
+			err = k8s.Get(&environment, k8sClient)
+			Expect(err).To(BeNil())
+
+			environment.Spec.UnstableConfigurationFields = &appstudiosharedv1.UnstableEnvironmentConfiguration{
+				KubernetesClusterCredentials: appstudiosharedv1.KubernetesClusterCredentials{
+					TargetNamespace:          fixture.GitOpsServiceE2ENamespace,
+					APIURL:                   "https://api-url",
+					ClusterCredentialsSecret: "my-secret",
+				},
+			}
+
+			err = k8s.Update(&environment, k8sClient)
+			Expect(err).To(BeNil())
+
+			By("generating the Binding")
+
+			binding := buildSnapshotEnvironmentBindingResource("appa-staging-binding", "new-demo-app", "staging", "my-snapshot", 3, []string{"component-a"})
+			err = k8s.Create(&binding, k8sClient)
+			Expect(err).To(BeNil())
+
+			// Update the status field
+			err = buildAndUpdateBindingStatus(binding.Spec.Components,
+				"https://github.com/redhat-appstudio/managed-gitops", "main", "adcda66",
+				[]string{"resources/test-data/sample-gitops-repository/components/componentA/overlays/staging"}, &binding)
+			Expect(err).To(Succeed())
+
+			By("waiting for the binding to report that the ManagedEnvironment of the Environment is not connected, as the credentials are invalid")
+			Eventually(binding, "60s", "1s").Should(bindingFixture.HaveBindingCondition(
+				appstudiocontroller.SnapshotEnvironmentBindingConditionManagedEnvironmentConnected, metav1.ConditionFalse,
+				appstudiocontroller.SnapshotEnvironmentBindingReasonManagedEnvironmentNotConnected))
+
+			By("verifying the GitOpsDeployment is not generated while the preflight checks fail")
+			gitOpsDeploymentName := appstudiocontroller.GenerateBindingGitOpsDeploymentName(binding, binding.Spec.Components[0].Name)
+
+			gitopsDeployment := managedgitopsv1alpha1.GitOpsDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      gitOpsDeploymentName,
+					Namespace: binding.Namespace,
+				},
+			}
+
+			Consistently(&gitopsDeployment, "20s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
+		})
+
 		It("Should ensure the associated GitOpsDeployment has labels identifying the application, component and environment", func() {
 			By("Create SnapshotEnvironmentBindingResource")
 
@@ -988,8 +1073,8 @@ func buildEnvironment(name, displayName string) appstudiosharedv1.Environment {
 	return environment
 }
 
-// buildSnapshot creates an instance of Snapshot CR
-func buildSnapshot(name, namespace, appName string) appstudiosharedv1.Snapshot {
+// buildSnapshot creates an instance of Snapshot CR, containing an image for each of the given components
+func buildSnapshot(name, namespace, appName string, componentNames ...string) appstudiosharedv1.Snapshot {
 	snapshot := appstudiosharedv1.Snapshot{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -1000,6 +1085,12 @@ func buildSnapshot(name, namespace, appName string) appstudiosharedv1.Snapshot {
 			DisplayName: name,
 		},
 	}
+	for _, componentName := range componentNames {
+		snapshot.Spec.Components = append(snapshot.Spec.Components, appstudiosharedv1.SnapshotComponent{
+			Name:           componentName,
+			ContainerImage: "quay.io/jgwest-redhat/sample-workload:latest",
+		})
+	}
 	return snapshot
 }
 
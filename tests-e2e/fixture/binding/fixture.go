@@ -242,3 +242,20 @@ func HaveComponentDeploymentCondition(expected metav1.Condition) matcher.GomegaM
 
 	}, BeTrue())
 }
+
+// HaveBindingCondition waits for a SnapshotEnvironmentBinding to have a .status.bindingConditions entry of the given type,
+// with the given status and reason.
+func HaveBindingCondition(conditionType string, status metav1.ConditionStatus, reason string) matcher.GomegaMatcher {
+	return expectedCondition(func(binding appstudiosharedv1.SnapshotEnvironmentBinding) bool {
+
+		for _, condition := range binding.Status.BindingConditions {
+			if condition.Type == conditionType {
+				GinkgoWriter.Println("HaveBindingCondition:", "expected: ", status, reason, "actual: ", condition)
+				return condition.Status == status && condition.Reason == reason
+			}
+		}
+
+		GinkgoWriter.Println("HaveBindingCondition: condition not found:", conditionType)
+		return false
+	})
+}
@@ -162,6 +162,7 @@ var _ = Describe("Test to verify that the data added to database is still presen
 			err = dbq.GetAPICRForDatabaseUID(ctx, &apiCRToDatabaseMappingDb)
 			Expect(err).To(BeNil())
 			addtestvalues.AddTest_PreAPICRToDatabaseMapping.SeqID = apiCRToDatabaseMappingDb.SeqID
+			addtestvalues.AddTest_PreAPICRToDatabaseMapping.Created_on = apiCRToDatabaseMappingDb.Created_on
 			Expect(addtestvalues.AddTest_PreAPICRToDatabaseMapping).To(Equal(apiCRToDatabaseMappingDb))
 
 			By("Get SyncOperation pointing to the Application")
@@ -0,0 +1,2 @@
+ALTER TABLE ApplicationState DROP COLUMN last_sync;
+ALTER TABLE SyncOperation DROP COLUMN initiator;
\ No newline at end of file
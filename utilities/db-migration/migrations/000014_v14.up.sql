@@ -0,0 +1,2 @@
+ALTER TABLE ApplicationState ADD COLUMN last_sync VARCHAR (2048);
+ALTER TABLE SyncOperation ADD COLUMN initiator VARCHAR (512);
\ No newline at end of file
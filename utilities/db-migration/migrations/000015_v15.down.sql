@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials DROP COLUMN rotation_requested_at;
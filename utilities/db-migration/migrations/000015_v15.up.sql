@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ADD COLUMN rotation_requested_at VARCHAR (64);
@@ -0,0 +1,2 @@
+ALTER TABLE ApplicationState DROP COLUMN resolved_revision;
+ALTER TABLE ApplicationState DROP COLUMN revision_error;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE ApplicationState ADD COLUMN resolved_revision VARCHAR (1024);
+ALTER TABLE ApplicationState ADD COLUMN revision_error VARCHAR (4096);
\ No newline at end of file
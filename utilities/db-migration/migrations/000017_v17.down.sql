@@ -0,0 +1,4 @@
+ALTER TABLE RepositoryCredentials ALTER COLUMN repo_cred_ssh TYPE VARCHAR (1024);
+ALTER TABLE RepositoryCredentials ALTER COLUMN repo_cred_pass TYPE VARCHAR (1024);
+DROP INDEX idx_keystore_user_id;
+DROP TABLE KeyStore;
\ No newline at end of file
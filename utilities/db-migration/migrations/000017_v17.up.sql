@@ -0,0 +1,12 @@
+CREATE TABLE KeyStore (
+	keystore_id VARCHAR ( 48 ) NOT NULL UNIQUE PRIMARY KEY,
+	keystore_user_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_keystore_clusteruser_id FOREIGN KEY (keystore_user_id) REFERENCES ClusterUser(clusteruser_id) ON DELETE CASCADE ON UPDATE NO ACTION,
+	wrapped_key VARCHAR (256) NOT NULL,
+	master_key_id VARCHAR (64) NOT NULL,
+	seq_id serial,
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_keystore_user_id ON KeyStore(keystore_user_id);
+ALTER TABLE RepositoryCredentials ALTER COLUMN repo_cred_pass TYPE VARCHAR (2048);
+ALTER TABLE RepositoryCredentials ALTER COLUMN repo_cred_ssh TYPE VARCHAR (2048);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE ClusterCredentials DROP COLUMN namespace_quota_memory;
+ALTER TABLE ClusterCredentials DROP COLUMN namespace_quota_cpu;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE ClusterCredentials ADD COLUMN namespace_quota_cpu VARCHAR (64);
+ALTER TABLE ClusterCredentials ADD COLUMN namespace_quota_memory VARCHAR (64);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE Application DROP COLUMN deleted_on;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE Application ADD COLUMN deleted_on TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE SyncOperation DROP COLUMN resources;
\ No newline at end of file
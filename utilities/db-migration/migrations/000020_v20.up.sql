@@ -0,0 +1 @@
+ALTER TABLE SyncOperation ADD COLUMN resources VARCHAR (4096);
\ No newline at end of file
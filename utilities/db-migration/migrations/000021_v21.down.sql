@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ApplicationOwner;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE ApplicationOwner (
+	applicationowner_application_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_applicationowner_application_id FOREIGN KEY (applicationowner_application_id) REFERENCES Application(application_id) ON DELETE CASCADE ON UPDATE NO ACTION,
+	applicationowner_user_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_applicationowner_clusteruser_id FOREIGN KEY (applicationowner_user_id) REFERENCES ClusterUser(clusteruser_id) ON DELETE CASCADE ON UPDATE NO ACTION,
+	seq_id serial,
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(applicationowner_application_id, applicationowner_user_id)
+);
+
+CREATE INDEX idx_applicationowner_user_id ON ApplicationOwner(applicationowner_user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS FeatureFlag;
\ No newline at end of file
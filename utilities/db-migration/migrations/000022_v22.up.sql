@@ -0,0 +1,9 @@
+CREATE TABLE FeatureFlag (
+	featureflag_id VARCHAR (48) NOT NULL PRIMARY KEY,
+	featureflag_name VARCHAR (64) NOT NULL,
+	featureflag_namespace VARCHAR (63) NOT NULL DEFAULT '',
+	enabled BOOLEAN NOT NULL DEFAULT FALSE,
+	seq_id serial,
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (featureflag_name, featureflag_namespace)
+);
\ No newline at end of file
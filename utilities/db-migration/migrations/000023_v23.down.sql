@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials DROP COLUMN argocd_cluster_secret;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ADD COLUMN argocd_cluster_secret VARCHAR (253);
\ No newline at end of file
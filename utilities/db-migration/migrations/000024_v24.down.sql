@@ -0,0 +1 @@
+DROP TABLE IF EXISTS DeploymentHistory;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE DeploymentHistory (
+	deploymenthistory_id VARCHAR (48) NOT NULL PRIMARY KEY,
+	deploymenthistory_application_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_deploymenthistory_application_id FOREIGN KEY (deploymenthistory_application_id) REFERENCES Application(application_id) ON DELETE CASCADE ON UPDATE NO ACTION,
+	argocd_history_id BIGINT NOT NULL,
+	revision VARCHAR (256),
+	images VARCHAR (4096),
+	initiator VARCHAR (512),
+	deployed_at TIMESTAMP NOT NULL,
+	seq_id serial,
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (deploymenthistory_application_id, argocd_history_id)
+);
\ No newline at end of file
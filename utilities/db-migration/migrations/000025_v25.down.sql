@@ -0,0 +1 @@
+ALTER TABLE DeploymentToApplicationMapping DROP COLUMN restored_from_uid;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE DeploymentToApplicationMapping ADD COLUMN restored_from_uid VARCHAR (48);
\ No newline at end of file
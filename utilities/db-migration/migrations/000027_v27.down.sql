@@ -0,0 +1 @@
+ALTER TABLE ManagedEnvironment DROP COLUMN version;ALTER TABLE Application DROP COLUMN version;
\ No newline at end of file
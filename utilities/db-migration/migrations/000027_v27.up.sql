@@ -0,0 +1 @@
+ALTER TABLE ManagedEnvironment ADD COLUMN version BIGINT NOT NULL DEFAULT 0;ALTER TABLE Application ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS NamespaceOffboarding;
\ No newline at end of file
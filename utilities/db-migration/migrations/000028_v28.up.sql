@@ -0,0 +1,7 @@
+CREATE TABLE NamespaceOffboarding (
+	namespace_uid VARCHAR (48) NOT NULL PRIMARY KEY,
+	namespace_name VARCHAR (63) NOT NULL,
+	step VARCHAR (32) NOT NULL,
+	seq_id serial,
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE Operation DROP COLUMN processing_owner;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE Operation ADD COLUMN processing_owner VARCHAR (128);
\ No newline at end of file
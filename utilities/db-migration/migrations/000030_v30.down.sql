@@ -0,0 +1 @@
+ALTER TABLE ApplicationState DROP COLUMN diff_preview;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ApplicationState ADD COLUMN diff_preview VARCHAR (16384);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ManagedEnvironment DROP COLUMN kubernetes_version, DROP COLUMN api_groups;
\ No newline at end of file
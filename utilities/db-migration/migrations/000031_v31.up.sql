@@ -0,0 +1 @@
+ALTER TABLE ManagedEnvironment ADD COLUMN kubernetes_version VARCHAR (64), ADD COLUMN api_groups VARCHAR (8192);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ManagedEnvironment DROP COLUMN disconnected;
\ No newline at end of file
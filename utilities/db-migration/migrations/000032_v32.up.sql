@@ -0,0 +1 @@
+ALTER TABLE ManagedEnvironment ADD COLUMN disconnected BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ApplicationState DROP COLUMN resource_estimate;
\ No newline at end of file
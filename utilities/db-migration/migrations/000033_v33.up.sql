@@ -0,0 +1 @@
+ALTER TABLE ApplicationState ADD COLUMN resource_estimate VARCHAR (2048);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE GitopsEngineInstance DROP COLUMN max_applications;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE GitopsEngineInstance ADD COLUMN max_applications INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials DROP COLUMN secret_data_hash;
\ No newline at end of file
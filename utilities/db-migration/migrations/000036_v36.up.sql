@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ADD COLUMN secret_data_hash VARCHAR (64);
\ No newline at end of file
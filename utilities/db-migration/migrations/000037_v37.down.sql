@@ -0,0 +1 @@
+ALTER TABLE APICRToDatabaseMapping DROP COLUMN created_on;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE APICRToDatabaseMapping ADD COLUMN created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
\ No newline at end of file
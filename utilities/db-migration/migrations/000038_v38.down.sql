@@ -0,0 +1 @@
+DROP TABLE IF EXISTS MigrationOperation;
\ No newline at end of file
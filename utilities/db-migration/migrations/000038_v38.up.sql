@@ -0,0 +1,12 @@
+CREATE TABLE MigrationOperation (
+	namespace_uid VARCHAR (48) NOT NULL PRIMARY KEY,
+	namespace_name VARCHAR (63) NOT NULL,
+	source_gitopsengineinstance_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_source_gitopsengineinstance_id FOREIGN KEY (source_gitopsengineinstance_id) REFERENCES GitopsEngineInstance(gitopsengineinstance_id) ON DELETE NO ACTION ON UPDATE NO ACTION,
+	target_gitopsengineinstance_id VARCHAR (48) NOT NULL,
+	CONSTRAINT fk_target_gitopsengineinstance_id FOREIGN KEY (target_gitopsengineinstance_id) REFERENCES GitopsEngineInstance(gitopsengineinstance_id) ON DELETE NO ACTION ON UPDATE NO ACTION,
+	step VARCHAR (32) NOT NULL,
+	step_started_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	seq_id serial,
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ManagedEnvironment DROP COLUMN cluster_labels;
\ No newline at end of file
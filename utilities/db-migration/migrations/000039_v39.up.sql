@@ -0,0 +1 @@
+ALTER TABLE ManagedEnvironment ADD COLUMN cluster_labels JSONB;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE SyncOperation DROP COLUMN retry_backoff_max_duration;
+ALTER TABLE SyncOperation DROP COLUMN retry_backoff_factor;
+ALTER TABLE SyncOperation DROP COLUMN retry_backoff_duration;
+ALTER TABLE SyncOperation DROP COLUMN retry_limit;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE SyncOperation ADD COLUMN retry_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE SyncOperation ADD COLUMN retry_backoff_duration VARCHAR(32);
+ALTER TABLE SyncOperation ADD COLUMN retry_backoff_factor INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE SyncOperation ADD COLUMN retry_backoff_max_duration VARCHAR(32);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE Operation DROP COLUMN resume_token;
\ No newline at end of file
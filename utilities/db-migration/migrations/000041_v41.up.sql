@@ -0,0 +1 @@
+ALTER TABLE Operation ADD COLUMN resume_token VARCHAR (160);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ApplicationState DROP COLUMN sync_hooks;
\ No newline at end of file
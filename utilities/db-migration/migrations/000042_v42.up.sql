@@ -0,0 +1 @@
+ALTER TABLE ApplicationState ADD COLUMN sync_hooks VARCHAR (16384);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_project VARCHAR(128);
\ No newline at end of file
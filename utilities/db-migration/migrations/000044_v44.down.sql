@@ -0,0 +1 @@
+ALTER TABLE GitopsEngineInstance DROP COLUMN argocd_version;
\ No newline at end of file
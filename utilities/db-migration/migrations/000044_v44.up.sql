@@ -0,0 +1 @@
+ALTER TABLE GitopsEngineInstance ADD COLUMN argocd_version VARCHAR(64);
\ No newline at end of file
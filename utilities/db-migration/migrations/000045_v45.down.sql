@@ -0,0 +1 @@
+DROP TABLE IF EXISTS TaskCheckpoint;
\ No newline at end of file
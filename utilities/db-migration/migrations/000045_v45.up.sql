@@ -0,0 +1,7 @@
+CREATE TABLE TaskCheckpoint (
+	task_name VARCHAR (128) NOT NULL PRIMARY KEY,
+	checkpoint VARCHAR (1024) NOT NULL,
+	last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	seq_id serial,
+	created_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
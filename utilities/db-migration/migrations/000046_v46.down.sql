@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_dtam_namespace_uid_seq_id;
\ No newline at end of file
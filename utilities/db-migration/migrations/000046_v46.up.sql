@@ -0,0 +1 @@
+CREATE INDEX idx_dtam_namespace_uid_seq_id ON DeploymentToApplicationMapping(namespace_uid, seq_id);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ClusterUser DROP COLUMN deactivated_on;
\ No newline at end of file
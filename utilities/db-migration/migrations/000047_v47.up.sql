@@ -0,0 +1 @@
+ALTER TABLE ClusterUser ADD COLUMN deactivated_on TIMESTAMP;
\ No newline at end of file
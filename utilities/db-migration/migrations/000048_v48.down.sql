@@ -0,0 +1 @@
+ALTER TABLE ApplicationState DROP COLUMN operation_state;
\ No newline at end of file
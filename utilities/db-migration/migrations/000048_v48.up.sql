@@ -0,0 +1 @@
+ALTER TABLE ApplicationState ADD COLUMN operation_state VARCHAR (2048);
\ No newline at end of file